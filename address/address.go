@@ -0,0 +1,148 @@
+// Package address normalizes warehouse addresses and checks them with an
+// optional validation provider
+package address
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	// ErrUnknownCountry is returned for a country that is no ISO 3166-1
+	// code or name
+	ErrUnknownCountry = errors.New("address: unknown country")
+	// ErrInvalid is returned when the validation provider rejects an address
+	ErrInvalid = errors.New("address: invalid address")
+)
+
+// Address is a postal address as warehouses store it
+type Address struct {
+	Street   string `json:"address"`
+	Ward     string `json:"ward"`
+	District string `json:"district"`
+	City     string `json:"city"`
+	Country  string `json:"country"`
+}
+
+// Normalize trims the parts of a and collapses their inner spaces, cases
+// the city in title case and replaces the country by its ISO 3166-1 alpha-2
+// code
+func Normalize(a Address) (Address, error) {
+	country, err := Country(a.Country)
+	if err != nil {
+		return Address{}, err
+	}
+	return Address{
+		Street:   collapseSpaces(a.Street),
+		Ward:     collapseSpaces(a.Ward),
+		District: collapseSpaces(a.District),
+		City:     City(a.City),
+		Country:  country,
+	}, nil
+}
+
+// City returns a city name trimmed and in title case: each word, and each
+// part of a hyphenated word, starts upper case and goes on lower case, so
+// "HO CHI MINH city" and "ho chi minh City" both become "Ho Chi Minh City"
+func City(s string) string {
+	s = collapseSpaces(s)
+	var b strings.Builder
+	b.Grow(len(s))
+	start := true
+	for _, r := range s {
+		if start {
+			b.WriteRune(unicode.ToTitle(r))
+		} else {
+			b.WriteRune(unicode.ToLower(r))
+		}
+		start = r == ' ' || r == '-'
+	}
+	return b.String()
+}
+
+func collapseSpaces(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// Validator checks a normalized address with an address validation service.
+// It returns the address as the service corrected it, or an error wrapping
+// ErrInvalid when the service rejects it. Other errors mean the service
+// could not be asked.
+type Validator interface {
+	Validate(ctx context.Context, a Address) (Address, error)
+}
+
+// HTTPValidator asks a validation service over HTTP: the address is POSTed
+// as JSON and the service answers with whether it is valid and its
+// corrected form
+type HTTPValidator struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewHTTPValidator returns a validator for the service at url. A non-empty
+// token is sent as a bearer token.
+func NewHTTPValidator(url, token string) *HTTPValidator {
+	return &HTTPValidator{
+		url:    url,
+		token:  token,
+		client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+type validationResponse struct {
+	Valid   bool    `json:"valid"`
+	Address Address `json:"address"`
+	Message string  `json:"message"`
+}
+
+func (v *HTTPValidator) Validate(ctx context.Context, a Address) (Address, error) {
+	body, err := json.Marshal(a)
+	if err != nil {
+		return Address{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.url, bytes.NewReader(body))
+	if err != nil {
+		return Address{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if v.token != "" {
+		req.Header.Set("Authorization", "Bearer "+v.token)
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return Address{}, fmt.Errorf("address validation: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Address{}, fmt.Errorf("address validation: unexpected status %d", resp.StatusCode)
+	}
+
+	var result validationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return Address{}, fmt.Errorf("address validation: decode response: %w", err)
+	}
+	if !result.Valid {
+		if result.Message == "" {
+			return Address{}, ErrInvalid
+		}
+		return Address{}, fmt.Errorf("%w: %s", ErrInvalid, result.Message)
+	}
+	// A service confirming the address without correcting it may leave it
+	// out. Its corrections go through the same normalization, the country
+	// may come back as a name.
+	if result.Address == (Address{}) {
+		return a, nil
+	}
+	return Normalize(result.Address)
+}
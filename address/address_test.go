@@ -0,0 +1,97 @@
+package address
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCountry(t *testing.T) {
+	tests := []struct {
+		in, want string
+		fails    bool
+	}{
+		{in: "", want: ""},
+		{in: "vn", want: "VN"},
+		{in: " VNM ", want: "VN"},
+		{in: "Viet Nam", want: "VN"},
+		{in: "vietnam", want: "VN"},
+		{in: "United States of America", want: "US"},
+		{in: "cote d'ivoire", want: "CI"},
+		{in: "Guinea-Bissau", want: "GW"},
+		{in: "XX", fails: true},
+		{in: "Test", fails: true},
+	}
+	for _, tt := range tests {
+		got, err := Country(tt.in)
+		if (err != nil) != tt.fails || got != tt.want {
+			t.Errorf("Country(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+		if tt.fails && !errors.Is(err, ErrUnknownCountry) {
+			t.Errorf("Country(%q) error %v is not ErrUnknownCountry", tt.in, err)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	got, err := Normalize(Address{
+		Street:   "  12  Tran Hung Dao ",
+		Ward:     "Ward 1",
+		District: " District  5",
+		City:     "HO CHI  MINH city",
+		Country:  "viet nam",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Address{Street: "12 Tran Hung Dao", Ward: "Ward 1", District: "District 5", City: "Ho Chi Minh City", Country: "VN"}
+	if got != want {
+		t.Errorf("Normalize = %+v, want %+v", got, want)
+	}
+	if got := City("saint-DENIS"); got != "Saint-Denis" {
+		t.Errorf("City = %q, want Saint-Denis", got)
+	}
+}
+
+func TestHTTPValidator(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var a Address
+		if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch a.Street {
+		case "nowhere":
+			json.NewEncoder(w).Encode(validationResponse{Message: "no such street"})
+		case "as is":
+			json.NewEncoder(w).Encode(validationResponse{Valid: true})
+		default:
+			a.Ward, a.Country = "Ben Nghe", "Vietnam"
+			json.NewEncoder(w).Encode(validationResponse{Valid: true, Address: a})
+		}
+	}))
+	defer srv.Close()
+	v := NewHTTPValidator(srv.URL, "secret")
+
+	got, err := v.Validate(context.Background(), Address{Street: "1 Le Loi", City: "Ho Chi Minh City", Country: "VN"})
+	if err != nil || got.Ward != "Ben Nghe" || got.Country != "VN" {
+		t.Errorf("corrected %+v, %v", got, err)
+	}
+	in := Address{Street: "as is", Country: "VN"}
+	if got, err := v.Validate(context.Background(), in); err != nil || got != in {
+		t.Errorf("confirmed %+v, %v", got, err)
+	}
+	if _, err := v.Validate(context.Background(), Address{Street: "nowhere"}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("rejected with %v, want ErrInvalid", err)
+	}
+	if _, err := NewHTTPValidator(srv.URL, "").Validate(context.Background(), in); err == nil || errors.Is(err, ErrInvalid) {
+		t.Errorf("unauthorized gave %v, want a service error", err)
+	}
+}
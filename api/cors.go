@@ -0,0 +1,118 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"warehouse-service/config"
+	"warehouse-service/handlers"
+	"warehouse-service/middlewares"
+
+	"github.com/gin-contrib/cors"
+)
+
+var corsAllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}
+
+// originMatcher decides which origins may call the API. "*" allows every
+// origin, origins containing "*" elsewhere are wildcards and patterns are
+// anchored regexes.
+type originMatcher struct {
+	any      bool
+	exact    map[string]bool
+	patterns []*regexp.Regexp
+}
+
+func newOriginMatcher(cfg config.Config) (*originMatcher, error) {
+	m := &originMatcher{exact: make(map[string]bool)}
+	for _, origin := range cfg.CORSAllowOrigins {
+		origin = strings.TrimSpace(origin)
+		switch {
+		case origin == "":
+			continue
+		case origin == "*":
+			if cfg.CORSAllowCredentials {
+				// Reflecting any origin with credentials would let every site
+				// make authenticated calls
+				return nil, fmt.Errorf("CORS origin * can't be combined with credentials")
+			}
+			m.any = true
+		case strings.Contains(origin, "*"):
+			parts := strings.Split(origin, "*")
+			for i, part := range parts {
+				parts[i] = regexp.QuoteMeta(part)
+			}
+			m.patterns = append(m.patterns, regexp.MustCompile("^"+strings.Join(parts, "[^/]*")+"$"))
+		default:
+			m.exact[origin] = true
+		}
+	}
+	for _, pattern := range cfg.CORSAllowOriginPatterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		re, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid CORS origin pattern %q: %w", pattern, err)
+		}
+		m.patterns = append(m.patterns, re)
+	}
+	if !m.any && len(m.exact) == 0 && len(m.patterns) == 0 {
+		return nil, fmt.Errorf("no CORS origins configured")
+	}
+	return m, nil
+}
+
+func (m *originMatcher) allow(origin string) bool {
+	if m.any || m.exact[origin] {
+		return true
+	}
+	for _, re := range m.patterns {
+		if re.MatchString(origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// corsPolicy keeps the allowed origins swappable at runtime, the remaining
+// CORS settings are fixed when the middleware is built
+type corsPolicy struct {
+	origins atomic.Pointer[originMatcher]
+}
+
+// newCORSPolicy builds the policy from the service config, falling back to
+// the local frontend when the configured origins are invalid
+func newCORSPolicy(cfg config.Config) (*corsPolicy, error) {
+	p := &corsPolicy{}
+	m, err := newOriginMatcher(cfg)
+	if err != nil {
+		m = &originMatcher{exact: map[string]bool{"http://localhost:3000": true}}
+	}
+	p.origins.Store(m)
+	return p, err
+}
+
+// update replaces the allowed origins, keeping the current ones on error
+func (p *corsPolicy) update(cfg config.Config) error {
+	m, err := newOriginMatcher(cfg)
+	if err != nil {
+		return err
+	}
+	p.origins.Store(m)
+	return nil
+}
+
+func (p *corsPolicy) middlewareConfig(cfg config.Config) cors.Config {
+	return cors.Config{
+		AllowOriginFunc: func(origin string) bool {
+			return p.origins.Load().allow(origin)
+		},
+		AllowMethods:     corsAllowMethods,
+		AllowHeaders:     cfg.CORSAllowHeaders,
+		ExposeHeaders:    []string{middlewares.RequestIDHeader, "Preference-Applied", "ETag", handlers.TotalCountHeader},
+		AllowCredentials: cfg.CORSAllowCredentials,
+		MaxAge:           cfg.CORSMaxAge,
+	}
+}
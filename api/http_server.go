@@ -0,0 +1,49 @@
+package api
+
+import (
+	"net/http"
+	"time"
+	"warehouse-service/config"
+)
+
+// httpSettings are the connection limits every listener is served with.
+// Without the timeouts a client sending its request slowly holds a
+// connection forever.
+type httpSettings struct {
+	readHeaderTimeout time.Duration
+	readTimeout       time.Duration
+	writeTimeout      time.Duration
+	idleTimeout       time.Duration
+	maxHeaderBytes    int
+	http2             *http.HTTP2Config
+}
+
+func newHTTPSettings(cfg config.Config) httpSettings {
+	return httpSettings{
+		readHeaderTimeout: cfg.ServerReadHeaderTimeout,
+		readTimeout:       cfg.ServerReadTimeout,
+		writeTimeout:      cfg.ServerWriteTimeout,
+		idleTimeout:       cfg.ServerIdleTimeout,
+		maxHeaderBytes:    cfg.ServerMaxHeaderBytes,
+		// Used for HTTP/2 over TLS and h2c alike
+		http2: &http.HTTP2Config{
+			MaxConcurrentStreams: cfg.ServerHTTP2MaxConcurrentStreams,
+			MaxReadFrameSize:     cfg.ServerHTTP2MaxReadFrameSize,
+			SendPingTimeout:      cfg.ServerHTTP2PingTimeout,
+		},
+	}
+}
+
+// server returns an http.Server for addr with the settings applied
+func (s httpSettings) server(addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: s.readHeaderTimeout,
+		ReadTimeout:       s.readTimeout,
+		WriteTimeout:      s.writeTimeout,
+		IdleTimeout:       s.idleTimeout,
+		MaxHeaderBytes:    s.maxHeaderBytes,
+		HTTP2:             s.http2,
+	}
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"warehouse-service/config"
+	"warehouse-service/middlewares"
+	"warehouse-service/routes"
+
+	"github.com/gin-gonic/gin"
+)
+
+// internalRouteGroups registers each group of config.InternalRouteGroups
+var internalRouteGroups = map[string]func(*routes.Route, *gin.Engine){
+	"warehouse":   (*routes.Route).AddWarehouseRoutes,
+	"storageroom": (*routes.Route).AddStorageRoomRoutes,
+	"search":      (*routes.Route).AddSearchRoutes,
+	"ledger":      (*routes.Route).AddLedgerRoutes,
+	"events":      (*routes.Route).AddEventRoutes,
+	"labels":      (*routes.Route).AddLabelRoutes,
+	"receiving":   (*routes.Route).AddReceivingRoutes,
+	"items":       (*routes.Route).AddItemRoutes,
+	"partners":    (*routes.Route).AddPartnerRoutes,
+	"serials":     (*routes.Route).AddSerialRoutes,
+	"picklists":   (*routes.Route).AddPickListRoutes,
+	"transfers":   (*routes.Route).AddTransferRoutes,
+	"scan":        (*routes.Route).AddScanRoutes,
+	"printing":    (*routes.Route).AddPrintRoutes,
+	"edi":         (*routes.Route).AddEDIRoutes,
+	"counts":      (*routes.Route).AddCountRoutes,
+	"jobs":        (*routes.Route).AddJobRoutes,
+	"telemetry":   (*routes.Route).AddTelemetryRoutes,
+	"usage":       (*routes.Route).AddUsageRoutes,
+	"reports":     (*routes.Route).AddReportRoutes,
+	"v2":          (*routes.Route).AddV2Routes,
+	"attachments": (*routes.Route).AddAttachmentRoutes,
+	"inbox":       (*routes.Route).AddInboxRoutes,
+}
+
+// internalListener serves route groups to backend services over mutual
+// TLS, they authenticate with their client certificate instead of a Clerk
+// token
+type internalListener struct {
+	addr     string
+	certFile string
+	keyFile  string
+	tls      *tls.Config
+	router   *gin.Engine
+	groups   []string
+	server   *http.Server
+}
+
+func newInternalListener(cfg config.Config, middleware ...gin.HandlerFunc) (*internalListener, error) {
+	pem, err := os.ReadFile(cfg.InternalClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("read INTERNAL_CLIENT_CA_FILE: %w", err)
+	}
+	clientCAs := x509.NewCertPool()
+	if !clientCAs.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates in %s", cfg.InternalClientCAFile)
+	}
+
+	router := gin.New()
+	router.HandleMethodNotAllowed = true
+	router.NoMethod(middlewares.Options())
+	router.Use(middleware...)
+	router.Use(middlewares.ServiceAuth(cfg.InternalAllowedIdentities))
+	return &internalListener{
+		addr:     cfg.InternalAddr,
+		certFile: cfg.InternalTLSCertFile,
+		keyFile:  cfg.InternalTLSKeyFile,
+		tls: &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			ClientAuth: tls.RequireAndVerifyClientCert,
+			ClientCAs:  clientCAs,
+		},
+		router: router,
+		groups: cfg.InternalRouteGroups,
+	}, nil
+}
+
+// addRoutes registers the configured groups. attachments says whether the
+// attachment routes exist at all.
+func (l *internalListener) addRoutes(r *routes.Route, attachments bool) {
+	l.router.SetTrustedProxies(nil)
+	for _, group := range l.groups {
+		if group == "attachments" && !attachments {
+			slog.Warn("Attachments are disabled, not serving them on the internal listener")
+			continue
+		}
+		// Unknown groups are rejected by config validation
+		if add, ok := internalRouteGroups[group]; ok {
+			add(r, l.router)
+		}
+	}
+}
+
+// start serves in the background until Shutdown
+func (l *internalListener) start(settings httpSettings) {
+	l.server = settings.server(l.addr, l.router.Handler())
+	l.server.TLSConfig = l.tls
+	go func() {
+		slog.Info("Serving backend services over mutual TLS",
+			slog.String("address", l.addr),
+			slog.Any("route_groups", l.groups))
+		if err := l.server.ListenAndServeTLS(l.certFile, l.keyFile); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Internal listener stopped", slog.Any("error", err))
+		}
+	}()
+}
@@ -2,31 +2,98 @@ package api
 
 import (
 	"context"
+	"errors"
+	"io"
 	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
 	"time"
+	"warehouse-service/address"
+	"warehouse-service/changefeed"
+	"warehouse-service/config"
+	"warehouse-service/dbroute"
+	"warehouse-service/edi"
+	"warehouse-service/exchange"
+	"warehouse-service/geocode"
+	"warehouse-service/handlers"
+	"warehouse-service/jobs"
+	"warehouse-service/middlewares"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/objectstore"
 	"warehouse-service/observability"
+	"warehouse-service/outbox"
+	"warehouse-service/policy"
+	"warehouse-service/quota"
 	routes "warehouse-service/routes"
+	"warehouse-service/scheduler"
+	"warehouse-service/services"
+	"warehouse-service/sftp"
+	"warehouse-service/slo"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	"github.com/jackc/pgx/v5"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
+	"golang.org/x/crypto/ssh"
 )
 
 type Server struct {
 	router            *gin.Engine
 	routes            *routes.Route
-	db                *pgx.Conn
+	db                *dbroute.Router
 	otelShutdown      func(context.Context) error
 	metrics           *observability.AppMetrics
 	prometheusMetrics *observability.PrometheusMetrics
+	jobs              *jobs.Runner
+	changes           *changefeed.Feed
+	outbox            *outbox.Relay
+	scheduler         *scheduler.Scheduler
+	cors              *corsPolicy
+	tlsCertFile       string
+	tlsKeyFile        string
+	redirectAddr      string
+	seedEnabled       bool
+	adminUserIDs      []string
+	httpServer        *http.Server
+	redirectServer    *http.Server
+	http              httpSettings
+	// Second listener for backend services, nil without INTERNAL_ADDR
+	internal *internalListener
+	// Connections to the sibling services, closed on shutdown
+	serviceConns []io.Closer
+
+	// Attachment routes are left out without a bucket
+	attachmentsEnabled bool
 }
 
-func NewServer(db *pgx.Conn, serviceName, serviceVersion, otelEndpoint, otelHeaders string) *Server {
+// attachmentUploadRoute is bound by ATTACHMENT_MAX_SIZE rather than
+// MAX_REQUEST_BODY_SIZE
+const attachmentUploadRoute = "/v1/warehouse/:id/attachments"
+
+func NewServer(db *dbroute.Router, serviceName, serviceVersion, otelEndpoint, otelHeaders string, cfg config.Config) *Server {
+	histograms := observability.HistogramConfig{
+		HTTPBuckets:      cfg.MetricsHTTPBuckets,
+		DBBuckets:        cfg.MetricsDBBuckets,
+		OperationBuckets: cfg.MetricsOperationBuckets,
+		Native:           cfg.MetricsNativeHistograms,
+		OTelAggregation:  cfg.OTELMetricsHistogramAggregation,
+	}
+
 	// Setup OpenTelemetry
 	ctx := context.Background()
-	otelShutdown, err := observability.SetupOTelSDK(ctx, serviceName, serviceVersion, otelEndpoint, otelHeaders)
+	otelShutdown, err := observability.SetupOTelSDK(ctx, serviceName, serviceVersion, observability.OTLPExporterConfig{
+		Endpoint:    otelEndpoint,
+		Headers:     otelHeaders,
+		Protocol:    cfg.OTELExporterOTLPProtocol,
+		Insecure:    cfg.OTELExporterOTLPInsecure,
+		CAFile:      cfg.OTELExporterOTLPCertificate,
+		CertFile:    cfg.OTELExporterOTLPClientCertificate,
+		KeyFile:     cfg.OTELExporterOTLPClientKey,
+		Compression: cfg.OTELExporterOTLPCompression,
+		URLPath:     cfg.OTELExporterOTLPURLPath,
+	}, cfg.OTELResourceAttributes, histograms)
 	if err != nil {
 		slog.Error("Failed to setup OpenTelemetry", slog.Any("error", err))
 		// Continue without OpenTelemetry
@@ -40,12 +107,43 @@ func NewServer(db *pgx.Conn, serviceName, serviceVersion, otelEndpoint, otelHead
 	}
 
 	// Create Prometheus metrics
-	prometheusMetrics := observability.NewPrometheusMetrics(serviceName)
+	prometheusMetrics := observability.NewPrometheusMetrics(serviceName, histograms)
+	db.Instrument(prometheusMetrics)
+	err = middlewares.ConfigureClerkJWKS(middlewares.JWKSConfig{
+		RefreshInterval:  cfg.ClerkJWKSRefreshInterval,
+		FailureThreshold: cfg.ClerkJWKSFailureThreshold,
+		Cooldown:         cfg.ClerkJWKSCooldown,
+		ClockSkew:        cfg.ClerkClockSkew,
+		StaticKey:        cfg.ClerkJWTKey,
+	}, prometheusMetrics)
+	if err != nil {
+		slog.Error("Invalid Clerk key configuration, fetching the JWKS instead", slog.Any("error", err))
+	}
 
-	router := gin.Default()
+	// gin.Default's logger and recovery only print to stdout, so we wire our own
+	gin.SetMode(cfg.GinModeOrDefault())
+	router := gin.New()
+	router.UseH2C = cfg.ServerH2C
+	// OPTIONS is answered from the 405 lookup, which finds the methods a
+	// path allows
+	router.HandleMethodNotAllowed = true
+	router.NoMethod(middlewares.Options())
+	router.Use(middlewares.RequestID(), middlewares.AccessLog(), middlewares.Tracing())
+	// Before everything that may answer, recovery included, so that every
+	// message is translated
+	router.Use(middlewares.Localize())
 
 	// Add Prometheus middleware
 	router.Use(prometheusMetrics.PrometheusMiddleware())
+	slos := slo.New(slo.Objectives{
+		Availability:     cfg.SLOAvailabilityTarget,
+		Latency:          cfg.SLOLatencyTarget,
+		LatencyThreshold: cfg.SLOLatencyThreshold,
+		ExcludedRoutes:   cfg.SLOExcludedRoutes,
+	})
+	prometheus.MustRegister(slos)
+	prometheus.MustRegister(observability.NewLoggingCollector())
+	router.Use(slos.Middleware())
 
 	// Add metrics middleware
 	server := &Server{
@@ -54,30 +152,313 @@ func NewServer(db *pgx.Conn, serviceName, serviceVersion, otelEndpoint, otelHead
 		otelShutdown:      otelShutdown,
 		metrics:           metrics,
 		prometheusMetrics: prometheusMetrics,
+		jobs: jobs.NewRunner(db.Primary(), prometheusMetrics, jobs.Config{
+			Workers:      cfg.JobWorkers,
+			PollInterval: cfg.JobPollInterval,
+		}),
+		changes: changefeed.New(db.Primary(), prometheusMetrics),
+		outbox: outbox.NewRelay(db.Primary(), newOutboxPublisher(cfg), prometheusMetrics, outbox.Config{
+			PollInterval: cfg.OutboxPollInterval,
+			BatchSize:    cfg.OutboxBatchSize,
+			MaxAttempts:  int32(cfg.OutboxMaxAttempts),
+		}),
+		scheduler:    scheduler.New(),
+		http:         newHTTPSettings(cfg),
+		seedEnabled:  cfg.SeedEndpointEnabled,
+		adminUserIDs: cfg.AdminUserIDs,
+	}
+	if cfg.TLSEnabled() {
+		server.tlsCertFile = cfg.TLSCertFile
+		server.tlsKeyFile = cfg.TLSKeyFile
+		server.redirectAddr = cfg.HTTPRedirectAddr
 	}
 
 	// Add middleware
 	router.Use(server.metricsMiddleware())
-	server.router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"http://localhost:3000"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"},
-		AllowHeaders:     []string{"Origins", "Content-Type", "Authorization", "Bearer"},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
-	}))
+	// Compressing outside of recovery so the 500 of a recovered panic is
+	// not cut short
+	compress := func(*gin.Context) {}
+	if cfg.CompressionEnabled {
+		compress = middlewares.Compress(cfg.CompressionMinSize, cfg.CompressionLevel)
+	}
+	router.Use(compress)
+	// Registered after the metrics middlewares so recovered panics are still
+	// counted as 500 responses
+	router.Use(middlewares.Recovery(prometheusMetrics))
+	bodyLimit := middlewares.BodyLimit(cfg.MaxRequestBodySize, attachmentUploadRoute)
+	if cfg.InternalAddr != "" {
+		// Same instrumentation as the public router, without CORS
+		server.internal, err = newInternalListener(cfg,
+			middlewares.RequestID(), middlewares.AccessLog(), middlewares.Tracing(), middlewares.Localize(),
+			prometheusMetrics.PrometheusMiddleware(), server.metricsMiddleware(),
+			compress, middlewares.Recovery(prometheusMetrics), bodyLimit)
+		if err != nil {
+			slog.Error("Failed to set up the internal listener, backend services can't connect", slog.Any("error", err))
+		}
+	}
+	server.cors, err = newCORSPolicy(cfg)
+	if err != nil {
+		slog.Error("Invalid CORS configuration, falling back to defaults", slog.Any("error", err))
+	}
+	server.router.Use(cors.New(server.cors.middlewareConfig(cfg)))
+	// After CORS so a 413 still carries the CORS headers
+	server.router.Use(bodyLimit)
 	// Setup routes
-	server.routes = routes.NewRoute(db, prometheusMetrics)
+	var geocoder geocode.Geocoder
+	if cfg.GeocoderURL != "" {
+		geocoder = geocode.NewNominatim(cfg.GeocoderURL, serviceName)
+	}
+	var addressValidator address.Validator
+	if cfg.AddressValidatorURL != "" {
+		addressValidator = address.NewHTTPValidator(cfg.AddressValidatorURL, cfg.AddressValidatorToken)
+	}
+	attachments := newAttachments(ctx, cfg)
+	server.attachmentsEnabled = attachments.Store != nil
+	var siblings handlers.Services
+	siblings, server.serviceConns = newServices(cfg)
+	server.routes = routes.NewRoute(db, prometheusMetrics, server.scheduler, geocoder, addressValidator, server.changes, quota.Limits{
+		Warehouses:               cfg.QuotaMaxWarehouses,
+		StorageRoomsPerWarehouse: cfg.QuotaMaxStorageRoomsPerWarehouse,
+		APICallsPerDay:           cfg.QuotaMaxAPICallsPerDay,
+		APICallsPerUserPerDay:    cfg.QuotaMaxAPICallsPerUserPerDay,
+	}, attachments, siblings, cfg.ServiceAccountUserIDs, middlewares.Authorize(newPolicyEngine(cfg), cfg.PolicyDecisionLog, prometheusMetrics))
+	// SFTP drops and file exchanges authenticate with the same key
+	signer := newSFTPSigner(cfg)
+	opener := exchange.Opener{
+		Signer: signer,
+		S3: exchange.S3Config{
+			Region:    cfg.ExchangeS3Region,
+			Endpoint:  cfg.ExchangeS3Endpoint,
+			PathStyle: cfg.ExchangeS3PathStyle,
+		},
+	}
+	server.scheduleTasks(cfg, signer, opener)
+	server.jobs.Register(handlers.JobKindPrintLabel, server.routes.Handlers().RunPrintJob)
+	server.jobs.Register(handlers.JobKindRunFileExchange, func(ctx context.Context, job models.Job) error {
+		return server.routes.Handlers().RunFileExchangeJob(ctx, job, opener)
+	})
+	server.jobs.Register(handlers.JobKindSyncIntegration, server.routes.Handlers().SyncIntegrationJob)
+	server.jobs.Register(handlers.JobKindAdvanceSaga, server.routes.Handlers().AdvanceSagaJob)
+	server.jobs.Register(handlers.JobKindExportTenant, server.routes.Handlers().ExportTenantJob)
+	server.jobs.Register(handlers.JobKindDeleteTenant, server.routes.Handlers().DeleteTenantJob)
 
 	return server
 }
 
-func (s *Server) Run(addr string, serviceName string) error {
-	slog.Info("Starting warehouse service server",
-		slog.String("address", addr),
-		slog.String("service", serviceName))
+// ApplyConfig applies the settings that are safe to change on a running
+// server: log level, trace sampling ratio and CORS origins
+func (s *Server) ApplyConfig(cfg config.Config) {
+	observability.SetLogLevel(cfg.SlogLevel())
+	observability.SetTraceSampleRatio(cfg.TraceSampleRatio)
+	middlewares.SetAccessLogSampling(middlewares.AccessLogSampling{
+		Ratio:         cfg.AccessLogSampleRatio,
+		SlowThreshold: cfg.AccessLogSlowThreshold,
+	})
+	if err := s.cors.update(cfg); err != nil {
+		slog.Error("Keeping previous CORS origins", slog.Any("error", err))
+	}
+	slog.Info("Applied runtime config",
+		slog.String("log_level", cfg.SlogLevel().String()),
+		slog.Float64("trace_sample_ratio", cfg.TraceSampleRatio),
+		slog.Float64("access_log_sample_ratio", cfg.AccessLogSampleRatio),
+		slog.Duration("access_log_slow_threshold", cfg.AccessLogSlowThreshold),
+		slog.Any("cors_allow_origins", cfg.CORSAllowOrigins))
+}
 
-	s.router.SetTrustedProxies(nil)
+// newPolicyEngine evaluates the authorization policy with OPA or the rules
+// of POLICY_FILE. Every request is allowed without either, a policy that
+// can't be loaded denies every request.
+func newPolicyEngine(cfg config.Config) policy.Engine {
+	switch {
+	case cfg.PolicyEngine == "opa":
+		return policy.NewOPA(cfg.OPAURL, cfg.OPAPolicyPath)
+	case cfg.PolicyFile != "":
+		rules, err := policy.LoadRules(cfg.PolicyFile)
+		if err != nil {
+			slog.Error("Failed to load POLICY_FILE, denying every request", slog.Any("error", err))
+			return &policy.Rules{Default: policy.EffectDeny}
+		}
+		return rules
+	}
+	return policy.AllowAll
+}
+
+// newOutboxPublisher publishes to the configured broker, events are only
+// logged without one
+func newOutboxPublisher(cfg config.Config) outbox.Publisher {
+	if cfg.OutboxBrokerURL == "" {
+		return outbox.LogPublisher{}
+	}
+	publisher := outbox.NewHTTPPublisher(cfg.OutboxBrokerURL)
+	if cfg.SchemaRegistryURL != "" {
+		publisher.UseSchemaIDs(registerSchemas(cfg))
+	}
+	return publisher
+}
+
+// registerSchemas registers the payload schemas with the schema registry.
+// Messages are published without schema IDs when it fails, an
+// incompatible schema is for the deployment to sort out.
+func registerSchemas(cfg config.Config) map[string]int {
+	registry, err := outbox.NewRegistry(cfg.SchemaRegistryURL)
+	if err != nil {
+		slog.Error("Invalid schema registry, publishing without schema IDs", slog.Any("error", err))
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	ids, err := outbox.RegisterSchemas(ctx, registry, cfg.SchemaRegistryCompatibility)
+	if err != nil {
+		slog.Error("Failed to register event schemas, publishing without schema IDs", slog.Any("error", err))
+		return nil
+	}
+	slog.Info("Registered event schemas", slog.Int("topics", len(ids)))
+	return ids
+}
+
+// newAttachments configures attachment storage, which stays disabled
+// without ATTACHMENT_BUCKET or when the store can't be set up
+func newAttachments(ctx context.Context, cfg config.Config) handlers.Attachments {
+	if cfg.AttachmentBucket == "" {
+		return handlers.Attachments{}
+	}
+	store, err := objectstore.NewS3(ctx, objectstore.S3Config{
+		Bucket:    cfg.AttachmentBucket,
+		Region:    cfg.AttachmentRegion,
+		Endpoint:  cfg.AttachmentEndpoint,
+		PathStyle: cfg.AttachmentPathStyle,
+	})
+	if err != nil {
+		slog.Error("Failed to set up attachment storage, attachments are disabled", slog.Any("error", err))
+		return handlers.Attachments{}
+	}
+	attachments := handlers.Attachments{
+		Store:        store,
+		MaxSize:      cfg.AttachmentMaxSize,
+		ContentTypes: cfg.AttachmentContentTypes,
+		URLTTL:       cfg.AttachmentURLTTL,
+	}
+	if cfg.AttachmentScanURL != "" {
+		attachments.Scanner = objectstore.NewHTTPScanner(cfg.AttachmentScanURL)
+	}
+	return attachments
+}
+
+// newServices connects the clients of the sibling services that have an
+// address. A client that can't be set up is logged and left out, which
+// turns off the workflows calling its service.
+func newServices(cfg config.Config) (handlers.Services, []io.Closer) {
+	settings := func(addr string) services.Config {
+		return services.Config{
+			Addr:             addr,
+			Insecure:         cfg.ServicesInsecure,
+			CAFile:           cfg.ServicesTLSCAFile,
+			CertFile:         cfg.ServicesTLSCertFile,
+			KeyFile:          cfg.ServicesTLSKeyFile,
+			Timeout:          cfg.ServicesTimeout,
+			MaxAttempts:      cfg.ServicesMaxAttempts,
+			FailureThreshold: cfg.ServicesBreakerFailures,
+			Cooldown:         cfg.ServicesBreakerCooldown,
+		}
+	}
+	var siblings handlers.Services
+	var conns []io.Closer
+	if cfg.InventoryServiceAddr != "" {
+		client, err := services.NewInventoryClient(settings(cfg.InventoryServiceAddr))
+		if err != nil {
+			slog.Error("Failed to set up the inventory-service client, reservations are not shared", slog.Any("error", err))
+		} else {
+			siblings.Inventory = client
+			conns = append(conns, client)
+		}
+	}
+	if cfg.OrderServiceAddr != "" {
+		client, err := services.NewOrderClient(settings(cfg.OrderServiceAddr))
+		if err != nil {
+			slog.Error("Failed to set up the order-service client, shipments are not confirmed", slog.Any("error", err))
+		} else {
+			siblings.Orders = client
+			conns = append(conns, client)
+		}
+	}
+	return siblings, conns
+}
+
+// newSFTPSigner loads the key SFTP connections authenticate with. Without
+// EDI_SFTP_KEY_FILE or when the key can't be read it returns nil, which
+// leaves inventory advice uploads and SFTP folders off.
+func newSFTPSigner(cfg config.Config) ssh.Signer {
+	if cfg.EDISFTPKeyFile == "" {
+		return nil
+	}
+	signer, err := sftp.LoadKey(cfg.EDISFTPKeyFile)
+	if err != nil {
+		slog.Error("Failed to load the EDI SFTP key, SFTP uploads and folders are disabled", slog.Any("error", err))
+		return nil
+	}
+	return signer
+}
 
+// scheduleTasks registers the periodic maintenance tasks. A task with an
+// invalid schedule is logged and left out rather than stopping the service.
+func (s *Server) scheduleTasks(cfg config.Config, signer ssh.Signer, opener exchange.Opener) {
+	h := s.routes.Handlers()
+	// Inventory advice can't be uploaded without the SFTP key
+	drop := edi.NewDrop(signer)
+	exportSpec := cfg.ScheduleExportInventoryAdvice
+	if signer == nil {
+		exportSpec = ""
+	}
+	tasks := []struct {
+		name string
+		spec string
+		fn   scheduler.TaskFunc
+	}{
+		{"expire_pick_lists", cfg.ScheduleExpirePickLists, func(ctx context.Context) error {
+			return h.ExpireStalePickLists(ctx, cfg.PickListAllocationTTL)
+		}},
+		{"refresh_gauges", cfg.ScheduleRefreshGauges, h.RefreshInventoryGauges},
+		{"refresh_dashboard_stats", cfg.ScheduleRefreshDashboardStats, h.RefreshDashboardStats},
+		{"refresh_sku_consumption", cfg.ScheduleRefreshSkuConsumption, h.RefreshSkuConsumption},
+		{"prune_audit_logs", cfg.SchedulePruneAuditLogs, func(ctx context.Context) error {
+			return h.PruneAuditLogs(ctx, cfg.AuditRetention)
+		}},
+		{"temperature_partitions", cfg.ScheduleTemperaturePartitions, func(ctx context.Context) error {
+			return h.MaintainTemperaturePartitions(ctx, cfg.TemperatureRetention)
+		}},
+		{"prune_change_events", cfg.SchedulePruneChangeEvents, func(ctx context.Context) error {
+			return h.PruneChangeEvents(ctx, cfg.ChangeEventRetention)
+		}},
+		{"prune_outbox", cfg.SchedulePruneOutbox, func(ctx context.Context) error {
+			return h.PruneOutbox(ctx, cfg.OutboxRetention)
+		}},
+		{"prune_inbox", cfg.SchedulePruneInbox, func(ctx context.Context) error {
+			return h.PruneProcessedMessages(ctx, cfg.InboxRetention)
+		}},
+		{"prune_api_usage", cfg.SchedulePruneAPIUsage, func(ctx context.Context) error {
+			return h.PruneAPIUsage(ctx, cfg.APIUsageRetention)
+		}},
+		{"aggregate_metering", cfg.ScheduleAggregateMetering, h.AggregateMetering},
+		{"export_inventory_advice", exportSpec, func(ctx context.Context) error {
+			return h.UploadInventoryAdvice(ctx, drop)
+		}},
+		{"file_exchange", cfg.ScheduleFileExchange, func(ctx context.Context) error {
+			return h.PollFileExchanges(ctx, opener)
+		}},
+		{"file_exchange_extract", cfg.ScheduleFileExchangeExtract, func(ctx context.Context) error {
+			return h.WriteFileExchangeExtracts(ctx, opener)
+		}},
+		{"sync_integrations", cfg.ScheduleSyncIntegrations, h.SyncIntegrations},
+	}
+	for _, task := range tasks {
+		if err := s.scheduler.Add(task.name, task.spec, task.fn); err != nil {
+			slog.Error("Failed to schedule task", slog.String("task", task.name), slog.Any("error", err))
+		}
+	}
+}
+
+// addRoutes registers every route on the router
+func (s *Server) addRoutes() {
 	// Add health check routes (no auth required)
 	s.routes.AddHealthRoutes(s.router)
 
@@ -86,14 +467,130 @@ func (s *Server) Run(addr string, serviceName string) error {
 
 	// Add business logic routes
 	s.routes.AddWarehouseRoutes(s.router)
+	s.routes.AddStorageRoomRoutes(s.router)
+	s.routes.AddSearchRoutes(s.router)
+	s.routes.AddLedgerRoutes(s.router)
+	s.routes.AddEventRoutes(s.router)
+	s.routes.AddLabelRoutes(s.router)
+	s.routes.AddReceivingRoutes(s.router)
+	s.routes.AddItemRoutes(s.router)
+	s.routes.AddPartnerRoutes(s.router)
+	s.routes.AddSerialRoutes(s.router)
+	s.routes.AddPickListRoutes(s.router)
+	s.routes.AddTransferRoutes(s.router)
+	s.routes.AddScanRoutes(s.router)
+	s.routes.AddPrintRoutes(s.router)
+	s.routes.AddEDIRoutes(s.router)
+	s.routes.AddCountRoutes(s.router)
+	s.routes.AddJobRoutes(s.router)
+	s.routes.AddTelemetryRoutes(s.router)
+	s.routes.AddUsageRoutes(s.router)
+	s.routes.AddReportRoutes(s.router)
+	s.routes.AddAdminRoutes(s.router)
+	s.routes.AddV2Routes(s.router)
+	if s.attachmentsEnabled {
+		s.routes.AddAttachmentRoutes(s.router)
+	}
+	if s.seedEnabled {
+		slog.Warn("Seed endpoint is enabled")
+		s.routes.AddSeedRoutes(s.router)
+	}
+	if len(s.adminUserIDs) > 0 {
+		s.routes.AddOperatorRoutes(s.router, s.adminUserIDs)
+	}
+	if s.internal != nil {
+		s.internal.addRoutes(s.routes, s.attachmentsEnabled)
+	}
+}
+
+// Handler registers the routes and returns the router without starting
+// the listener or the background workers. Integration tests serve it in
+// place of Run.
+func (s *Server) Handler() http.Handler {
+	s.addRoutes()
+	return s.router.Handler()
+}
+
+func (s *Server) Run(addr string, serviceName string) error {
+	slog.Info("Starting warehouse service server",
+		slog.String("address", addr),
+		slog.String("service", serviceName))
+
+	s.router.SetTrustedProxies(nil)
 
-	return s.router.Run(addr)
+	s.addRoutes()
+
+	// Start background workers
+	s.db.Start(context.Background())
+	s.changes.Start(context.Background())
+	s.outbox.Start(context.Background())
+	s.jobs.Start(context.Background())
+	s.scheduler.Start()
+	middlewares.StartJWKSRefresh(context.Background())
+	if s.internal != nil {
+		s.internal.start(s.http)
+	}
+
+	s.httpServer = s.http.server(addr, s.router.Handler())
+	if s.tlsCertFile == "" {
+		return s.httpServer.ListenAndServe()
+	}
+
+	if s.redirectAddr != "" {
+		s.redirectServer = s.http.server(s.redirectAddr, httpsRedirect(addr))
+		go func() {
+			slog.Info("Redirecting HTTP to HTTPS", slog.String("address", s.redirectAddr))
+			if err := s.redirectServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("HTTP redirect server stopped", slog.Any("error", err))
+			}
+		}()
+	}
+	// ListenAndServeTLS negotiates HTTP/2 through ALPN
+	return s.httpServer.ListenAndServeTLS(s.tlsCertFile, s.tlsKeyFile)
+}
+
+// httpsRedirect sends every request to the same host and path on the TLS
+// listener, keeping the port unless it is the HTTPS default
+func httpsRedirect(tlsAddr string) http.Handler {
+	_, port, _ := net.SplitHostPort(tlsAddr)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if port != "" && port != "443" {
+			host = net.JoinHostPort(host, port)
+		}
+		target := url.URL{Scheme: "https", Host: host, Path: r.URL.Path, RawQuery: r.URL.RawQuery}
+		http.Redirect(w, r, target.String(), http.StatusPermanentRedirect)
+	})
 }
 
 // Shutdown gracefully shuts down the server and OpenTelemetry
 func (s *Server) Shutdown(ctx context.Context) error {
 	slog.Info("Shutting down warehouse service server")
 
+	servers := []*http.Server{s.redirectServer, s.httpServer}
+	if s.internal != nil {
+		servers = append(servers, s.internal.server)
+	}
+	for _, srv := range servers {
+		if srv == nil {
+			continue
+		}
+		if err := srv.Shutdown(ctx); err != nil {
+			slog.Error("Failed to shutdown HTTP server", slog.Any("error", err))
+		}
+	}
+
+	s.scheduler.Stop()
+	s.jobs.Stop()
+	s.outbox.Stop()
+	s.changes.Stop()
+	for _, conn := range s.serviceConns {
+		conn.Close()
+	}
+
 	if s.otelShutdown != nil {
 		if err := s.otelShutdown(ctx); err != nil {
 			slog.Error("Failed to shutdown OpenTelemetry", slog.Any("error", err))
@@ -102,7 +599,7 @@ func (s *Server) Shutdown(ctx context.Context) error {
 	}
 
 	if s.db != nil {
-		s.db.Close(ctx)
+		s.db.Close()
 	}
 
 	return nil
@@ -0,0 +1,57 @@
+package authz
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		subject Subject
+		owner   string
+		want    error
+	}{
+		{"same organization", Subject{OrgID: "org_a"}, "org_a", nil},
+		{"other organization", Subject{OrgID: "org_a"}, "org_b", ErrOtherTenant},
+		{"caller without organization", Subject{}, "org_a", ErrNoTenant},
+		{"caller without organization and unowned entity", Subject{}, "", ErrNoTenant},
+		{"unowned entity", Subject{OrgID: "org_a"}, "", ErrUnowned},
+		{"service account on other organization", Subject{OrgID: "org_a", ServiceAccount: true}, "org_b", nil},
+		{"service account without organization", Subject{ServiceAccount: true}, "org_b", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Check(tt.subject, tt.owner); !errors.Is(err, tt.want) {
+				t.Errorf("Check(%+v, %q) = %v, want %v", tt.subject, tt.owner, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	type row struct {
+		id    int
+		owner string
+	}
+	rows := []row{{1, "org_a"}, {2, "org_b"}, {3, "org_a"}, {4, ""}}
+	owner := func(r row) string { return r.owner }
+
+	allowed, dropped := Filter(Subject{OrgID: "org_a"}, rows, owner)
+	if len(allowed) != 2 || allowed[0].id != 1 || allowed[1].id != 3 || dropped != 2 {
+		t.Errorf("Filter for org_a = %v, %d dropped", allowed, dropped)
+	}
+	if rows[1].id != 2 {
+		t.Errorf("Filter modified its input: %v", rows)
+	}
+
+	allowed, dropped = Filter(Subject{ServiceAccount: true}, rows, owner)
+	if len(allowed) != len(rows) || dropped != 0 {
+		t.Errorf("Filter for a service account = %v, %d dropped", allowed, dropped)
+	}
+
+	allowed, _ = Filter(Subject{OrgID: "org_c"}, rows, owner)
+	if allowed == nil || len(allowed) != 0 {
+		t.Errorf("Filter without matches = %#v, want an empty slice", allowed)
+	}
+}
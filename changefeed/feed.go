@@ -0,0 +1,267 @@
+// Package changefeed follows the change_event table, which triggers fill
+// on every warehouse, storage room and stock level write, and fans the
+// events out to subscribers. Postgres NOTIFY wakes the feed, so every
+// replica of the service sees every change without polling.
+package changefeed
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Channel is the NOTIFY channel of the change_event trigger
+const Channel = "change_event"
+
+// Entities whose changes are recorded
+const (
+	EntityWarehouse   = "warehouse"
+	EntityStorageRoom = "storage_room"
+	EntityStockLevel  = "stock_level"
+)
+
+const (
+	pageSize = 500
+	// Catch up even without a notification. Events held back by a running
+	// transaction that ends without writing to the feed wait for this.
+	safetyPoll = 5 * time.Second
+	maxBackoff = 30 * time.Second
+)
+
+// Event is one committed change. Operation is insert, update or delete and
+// Data holds a few identifying fields of the row, see the
+// 000015_change_feed migration.
+type Event struct {
+	ID        int64           `json:"id"`
+	Txid      int64           `json:"-"`
+	OrgID     string          `json:"-"`
+	Entity    string          `json:"entity"`
+	EntityID  int64           `json:"entity_id"`
+	Operation string          `json:"operation"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// FromModel converts a change_event row
+func FromModel(e models.ChangeEvent) Event {
+	return Event{
+		ID:        e.ID,
+		Txid:      e.Txid,
+		OrgID:     e.OrgID,
+		Entity:    e.Entity,
+		EntityID:  e.EntityID,
+		Operation: e.Operation,
+		Data:      e.Data,
+		CreatedAt: e.CreatedAt.Time,
+	}
+}
+
+// Position is a place in the feed. Events are ordered by the transaction
+// that wrote them, then by ID.
+type Position struct {
+	Txid int64
+	ID   int64
+}
+
+// After reports whether p comes after q in the feed
+func (p Position) After(q Position) bool {
+	return p.Txid > q.Txid || (p.Txid == q.Txid && p.ID > q.ID)
+}
+
+// Position returns the place of e in the feed
+func (e Event) Position() Position {
+	return Position{Txid: e.Txid, ID: e.ID}
+}
+
+// Subscription receives events in feed order on C. C is closed when the
+// subscriber falls a full buffer behind or the feed stops; the subscriber
+// must then resync, e.g. by reading change_event after its last event.
+type Subscription struct {
+	C    <-chan Event
+	c    chan Event
+	feed *Feed
+	once sync.Once
+}
+
+// Close unsubscribes
+func (s *Subscription) Close() {
+	s.feed.mu.Lock()
+	defer s.feed.mu.Unlock()
+	s.feed.drop(s)
+}
+
+// Feed listens for change_event notifications on a dedicated connection
+type Feed struct {
+	pool              *pgxpool.Pool
+	queries           *models.Queries
+	prometheusMetrics *observability.PrometheusMetrics
+
+	mu          sync.Mutex
+	subscribers map[*Subscription]struct{}
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func New(pool *pgxpool.Pool, prometheusMetrics *observability.PrometheusMetrics) *Feed {
+	return &Feed{
+		pool:              pool,
+		queries:           models.New(pool),
+		prometheusMetrics: prometheusMetrics,
+		subscribers:       make(map[*Subscription]struct{}),
+	}
+}
+
+// Subscribe returns a subscription to events committed from now on, with
+// room for buffer undelivered events
+func (f *Feed) Subscribe(buffer int) *Subscription {
+	c := make(chan Event, buffer)
+	s := &Subscription{C: c, c: c, feed: f}
+	f.mu.Lock()
+	f.subscribers[s] = struct{}{}
+	f.mu.Unlock()
+	return s
+}
+
+// drop removes s and closes its channel, f.mu must be held
+func (f *Feed) drop(s *Subscription) {
+	delete(f.subscribers, s)
+	s.once.Do(func() { close(s.c) })
+}
+
+func (f *Feed) publish(e Event) {
+	if f.prometheusMetrics != nil {
+		f.prometheusMetrics.RecordChangeEvent(e.Entity, e.Operation)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for s := range f.subscribers {
+		select {
+		case s.c <- e:
+		default:
+			slog.Warn("Dropping change feed subscriber that fell behind", slog.Int64("event_id", e.ID))
+			f.drop(s)
+		}
+	}
+}
+
+// Start follows the feed from the latest event until Stop
+func (f *Feed) Start(ctx context.Context) {
+	ctx, f.cancel = context.WithCancel(ctx)
+	f.done = make(chan struct{})
+	go f.run(ctx)
+}
+
+// Stop stops listening and closes every subscription
+func (f *Feed) Stop() {
+	if f.cancel == nil {
+		return
+	}
+	f.cancel()
+	<-f.done
+	f.mu.Lock()
+	for s := range f.subscribers {
+		f.drop(s)
+	}
+	f.mu.Unlock()
+	slog.Info("Change feed stopped")
+}
+
+func (f *Feed) run(ctx context.Context) {
+	defer close(f.done)
+
+	var pos Position
+	started := false
+	backoff := time.Second
+	for ctx.Err() == nil {
+		if !started {
+			horizon, err := f.queries.GetChangeFeedHorizon(ctx)
+			if err == nil {
+				// Every event of a transaction that is still running or has
+				// not started yet sorts after this
+				pos, started = Position{Txid: horizon - 1, ID: math.MaxInt64}, true
+				slog.Info("Following change feed", slog.Int64("horizon", horizon))
+			} else if ctx.Err() == nil {
+				slog.Error("Failed to read the change feed horizon", slog.Any("error", err))
+			}
+		}
+		if started {
+			listening, err := f.listen(ctx, &pos)
+			if ctx.Err() != nil {
+				return
+			}
+			if listening {
+				backoff = time.Second
+			}
+			slog.Error("Change feed listener failed, reconnecting",
+				slog.Duration("backoff", backoff), slog.Any("error", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, maxBackoff)
+	}
+}
+
+// listen holds a connection out of the pool, LISTENs on Channel and
+// publishes the events after pos whenever it is notified. It only returns
+// on an error and reports whether LISTEN had succeeded.
+func (f *Feed) listen(ctx context.Context, pos *Position) (bool, error) {
+	pooled, err := f.pool.Acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{Channel}.Sanitize()); err != nil {
+		return false, err
+	}
+	for {
+		// Events committed while the listener was down are read here too
+		if err := f.catchUp(ctx, pos); err != nil {
+			return true, err
+		}
+		waitCtx, cancel := context.WithTimeout(ctx, safetyPoll)
+		_, err := conn.WaitForNotification(waitCtx)
+		cancel()
+		if err != nil && (ctx.Err() != nil || waitCtx.Err() == nil) {
+			return true, err
+		}
+	}
+}
+
+// catchUp publishes the final events after pos. Events of transactions
+// that were running when a notification arrived are picked up by a later
+// notification or the safety poll.
+func (f *Feed) catchUp(ctx context.Context, pos *Position) error {
+	for {
+		events, err := f.queries.ListChangeEventsAfter(ctx, models.ListChangeEventsAfterParams{
+			AfterTxid: pos.Txid,
+			AfterID:   pos.ID,
+			PageLimit: pageSize,
+		})
+		if err != nil {
+			return err
+		}
+		for _, e := range events {
+			event := FromModel(e)
+			f.publish(event)
+			*pos = event.Position()
+		}
+		if len(events) < pageSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Generate synthetic data and load test a running instance",
+	Long: `Generate synthetic data and load test a running instance.
+
+  warehouse-service bench generate --org org_bench --warehouses 1000
+  warehouse-service bench run --url http://localhost:7450 --token whs_... --duration 1m
+
+bench run authenticates with an API key of the generated organization,
+see create-apikey.`,
+}
+
+func init() {
+	benchCmd.AddCommand(benchGenerateCmd, benchRunCmd)
+}
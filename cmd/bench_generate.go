@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/spf13/cobra"
+)
+
+var (
+	benchOrg           string
+	benchWarehouses    int
+	benchRoomsPerWH    int
+	benchSkus          int
+	benchStockPerRoom  int
+	benchSeed          uint64
+	benchGenerateForce bool
+)
+
+var benchGenerateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Bulk insert synthetic warehouses, storage rooms and stock into an organization",
+	Long: `Bulk insert synthetic warehouses, storage rooms and stock into an
+organization with COPY, in one transaction. Warehouse names carry a run tag
+so generate can be repeated into the same organization. The same --seed
+produces the same names, coordinates and quantities.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch {
+		case benchWarehouses < 1:
+			return errors.New("--warehouses must be at least 1")
+		case benchRoomsPerWH < 0 || benchStockPerRoom < 0:
+			return errors.New("--rooms-per-warehouse and --stock-per-room must not be negative")
+		case benchStockPerRoom > benchSkus:
+			return errors.New("--stock-per-room must not exceed --skus")
+		}
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		if cfg.Environment == "production" && !benchGenerateForce {
+			return errors.New("refusing to generate data in a production database without --force")
+		}
+
+		ctx := context.Background()
+		conn, err := connectDB(ctx, cfg, 1)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		tx, err := conn.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx) // This will be ignored if tx.Commit() succeeds
+		// Millions of rows would flood the change feed
+		if _, err := tx.Exec(ctx, `SET LOCAL inventium.skip_change_feed = 'on'`); err != nil {
+			return err
+		}
+
+		start := time.Now()
+		g := benchGenerator{
+			tx:    tx,
+			orgID: benchOrg,
+			tag:   strconv.FormatInt(time.Now().Unix(), 36),
+			rng:   rand.New(rand.NewPCG(benchSeed, benchSeed)),
+		}
+		warehouseIDs, err := g.warehouses(ctx, benchWarehouses)
+		if err != nil {
+			return fmt.Errorf("generate warehouses: %w", err)
+		}
+		roomIDs, err := g.storageRooms(ctx, warehouseIDs, benchRoomsPerWH)
+		if err != nil {
+			return fmt.Errorf("generate storage rooms: %w", err)
+		}
+		stock, err := g.stock(ctx, roomIDs, benchSkus, benchStockPerRoom)
+		if err != nil {
+			return fmt.Errorf("generate stock: %w", err)
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+
+		slog.Info("Generated benchmark data",
+			slog.String("org_id", benchOrg),
+			slog.String("tag", g.tag),
+			slog.Int("warehouses", len(warehouseIDs)),
+			slog.Int("storage_rooms", len(roomIDs)),
+			slog.Int64("stock_levels", stock),
+			slog.Duration("took", time.Since(start)),
+		)
+		return nil
+	},
+}
+
+func init() {
+	flags := benchGenerateCmd.Flags()
+	flags.StringVar(&benchOrg, "org", "", "Clerk organization ID to fill")
+	flags.IntVar(&benchWarehouses, "warehouses", 100, "number of warehouses")
+	flags.IntVar(&benchRoomsPerWH, "rooms-per-warehouse", 10, "storage rooms per warehouse")
+	flags.IntVar(&benchSkus, "skus", 1000, "size of the SKU catalogue stock is drawn from")
+	flags.IntVar(&benchStockPerRoom, "stock-per-room", 20, "distinct SKUs stocked in each room")
+	flags.Uint64Var(&benchSeed, "seed", 1, "random seed")
+	flags.BoolVar(&benchGenerateForce, "force", false, "allow generating when ENVIRONMENT is production")
+	_ = benchGenerateCmd.MarkFlagRequired("org")
+}
+
+type benchGenerator struct {
+	tx    pgx.Tx
+	orgID string
+	tag   string
+	rng   *rand.Rand
+}
+
+// benchCities spreads generated warehouses over a few real places so the
+// nearby search has something to find
+var benchCities = []struct {
+	name     string
+	lat, lng float64
+}{
+	{"Ho Chi Minh City", 10.7769, 106.7009},
+	{"Hanoi", 21.0278, 105.8342},
+	{"Da Nang", 16.0544, 108.2022},
+	{"Singapore", 1.3521, 103.8198},
+	{"Bangkok", 13.7563, 100.5018},
+}
+
+func (g benchGenerator) warehouses(ctx context.Context, n int) ([]int64, error) {
+	rows := make([][]any, n)
+	for i := range rows {
+		city := benchCities[g.rng.IntN(len(benchCities))]
+		rows[i] = []any{
+			fmt.Sprintf("bench-%s-%06d", g.tag, i),
+			fmt.Sprintf("%d Bench Rd", i+1),
+			strconv.Itoa(g.rng.IntN(20) + 1),
+			strconv.Itoa(g.rng.IntN(12) + 1),
+			city.name,
+			"Bench",
+			g.orgID,
+			// Within about 30km of the city centre
+			city.lat + (g.rng.Float64()-0.5)*0.5,
+			city.lng + (g.rng.Float64()-0.5)*0.5,
+		}
+	}
+	_, err := g.tx.CopyFrom(ctx, pgx.Identifier{"warehouse"},
+		[]string{"name", "address", "ward", "district", "city", "country", "org_id", "latitude", "longitude"},
+		pgx.CopyFromRows(rows))
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := g.tx.Query(ctx,
+		`SELECT id FROM warehouse WHERE org_id = $1 AND name LIKE $2 ORDER BY id`,
+		g.orgID, "bench-"+g.tag+"-%")
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(result, pgx.RowTo[int64])
+}
+
+// storageRooms inserts rooms for every warehouse. storage_room has no ID
+// sequence, so IDs continue from the current maximum under a table lock.
+func (g benchGenerator) storageRooms(ctx context.Context, warehouseIDs []int64, perWarehouse int) ([]int32, error) {
+	if perWarehouse == 0 {
+		return nil, nil
+	}
+	if _, err := g.tx.Exec(ctx, `LOCK TABLE storage_room IN EXCLUSIVE MODE`); err != nil {
+		return nil, err
+	}
+	var next int64
+	if err := g.tx.QueryRow(ctx, `SELECT COALESCE(max(id), 0) + 1 FROM storage_room`).Scan(&next); err != nil {
+		return nil, err
+	}
+	if next+int64(len(warehouseIDs)*perWarehouse) > math.MaxInt32 {
+		return nil, errors.New("storage room IDs would overflow")
+	}
+
+	zones := []string{"ambient", "ambient", "chilled", "frozen"}
+	ids := make([]int32, 0, len(warehouseIDs)*perWarehouse)
+	rows := make([][]any, 0, cap(ids))
+	for _, warehouseID := range warehouseIDs {
+		if warehouseID > math.MaxInt32 {
+			return nil, fmt.Errorf("warehouse %d cannot have storage rooms", warehouseID)
+		}
+		for i := range perWarehouse {
+			id := int32(next)
+			next++
+			ids = append(ids, id)
+			rows = append(rows, []any{
+				id,
+				fmt.Sprintf("Room %d", i+1),
+				fmt.Sprintf("R-%03d", i+1),
+				int32(warehouseID),
+				g.orgID,
+				zones[g.rng.IntN(len(zones))],
+			})
+		}
+	}
+	_, err := g.tx.CopyFrom(ctx, pgx.Identifier{"storage_room"},
+		[]string{"id", "name", "number", "warehouse_id", "org_id", "zone_type"},
+		pgx.CopyFromRows(rows))
+	return ids, err
+}
+
+func (g benchGenerator) stock(ctx context.Context, roomIDs []int32, skus, perRoom int) (int64, error) {
+	if perRoom == 0 || len(roomIDs) == 0 {
+		return 0, nil
+	}
+	rows := make([][]any, 0, len(roomIDs)*perRoom)
+	for _, roomID := range roomIDs {
+		for _, sku := range g.rng.Perm(skus)[:perRoom] {
+			rows = append(rows, []any{
+				g.orgID,
+				roomID,
+				fmt.Sprintf("BENCH-SKU-%05d", sku),
+				int32(g.rng.IntN(1000) + 1),
+			})
+		}
+	}
+	return g.tx.CopyFrom(ctx, pgx.Identifier{"stock_level"},
+		[]string{"org_id", "storage_room_id", "sku", "quantity"},
+		pgx.CopyFromRows(rows))
+}
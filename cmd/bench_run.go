@@ -0,0 +1,378 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	benchURL         string
+	benchToken       string
+	benchMix         string
+	benchDuration    time.Duration
+	benchConcurrency int
+	benchMaxP99      time.Duration
+	benchJSON        bool
+)
+
+// benchDefaultMix is read heavy, like the dashboard traffic the service sees
+const benchDefaultMix = "get_warehouse=40,list_warehouses=20,list_warehouses_v2=10,batch_get=10,search=5,nearby=5,list_stock=10"
+
+var benchRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Drive a weighted request mix against a running instance and report latency percentiles",
+	Long: `Drive a weighted request mix against a running instance and report
+latency percentiles per operation. Operations:
+
+  ` + strings.Join(benchOperationNames(), ", ") + `
+
+With --max-p99 the command fails when the overall p99 latency exceeds it,
+so it can gate a release.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if benchToken == "" {
+			benchToken = os.Getenv("BENCH_TOKEN")
+		}
+		if benchToken == "" {
+			return errors.New("--token or BENCH_TOKEN is required")
+		}
+		if benchConcurrency < 1 {
+			return errors.New("--concurrency must be at least 1")
+		}
+		mix, err := parseBenchMix(benchMix)
+		if err != nil {
+			return err
+		}
+
+		b := &benchRunner{
+			baseURL: strings.TrimRight(benchURL, "/"),
+			token:   benchToken,
+			client:  &http.Client{Timeout: 30 * time.Second},
+		}
+		ctx := context.Background()
+		if err := b.discover(ctx); err != nil {
+			return err
+		}
+		slog.Info("Starting benchmark",
+			slog.String("url", b.baseURL),
+			slog.Int("warehouses", len(b.warehouseIDs)),
+			slog.Int("concurrency", benchConcurrency),
+			slog.Duration("duration", benchDuration),
+		)
+
+		report := b.run(ctx, mix, benchConcurrency, benchDuration)
+		if benchJSON {
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(report); err != nil {
+				return err
+			}
+		} else {
+			report.print(cmd.OutOrStdout())
+		}
+		if benchMaxP99 > 0 && report.Total.P99 > benchMaxP99 {
+			return fmt.Errorf("p99 latency %s exceeds --max-p99 %s", report.Total.P99, benchMaxP99)
+		}
+		return nil
+	},
+}
+
+func init() {
+	flags := benchRunCmd.Flags()
+	flags.StringVar(&benchURL, "url", "http://localhost:7450", "base URL of the instance")
+	flags.StringVar(&benchToken, "token", "", "API key or session token, defaults to BENCH_TOKEN")
+	flags.StringVar(&benchMix, "mix", benchDefaultMix, "comma separated operation=weight pairs")
+	flags.DurationVar(&benchDuration, "duration", 30*time.Second, "how long to send requests")
+	flags.IntVar(&benchConcurrency, "concurrency", 8, "concurrent clients")
+	flags.DurationVar(&benchMaxP99, "max-p99", 0, "fail when the overall p99 latency exceeds this")
+	flags.BoolVar(&benchJSON, "json", false, "print the report as JSON")
+}
+
+// benchOperation builds one request of an operation
+type benchOperation func(b *benchRunner, rng *rand.Rand) (method, path string, body any)
+
+var benchOperations = map[string]benchOperation{
+	"get_warehouse": func(b *benchRunner, rng *rand.Rand) (string, string, any) {
+		return http.MethodGet, fmt.Sprintf("/v1/warehouse/%d", b.randomWarehouse(rng)), nil
+	},
+	"list_warehouses": func(b *benchRunner, rng *rand.Rand) (string, string, any) {
+		return http.MethodGet, "/v1/warehouse/list", nil
+	},
+	"list_warehouses_v2": func(b *benchRunner, rng *rand.Rand) (string, string, any) {
+		offset := rng.IntN(max(len(b.warehouseIDs)-20, 1))
+		return http.MethodGet, fmt.Sprintf("/v2/warehouses?limit=20&offset=%d", offset), nil
+	},
+	"batch_get": func(b *benchRunner, rng *rand.Rand) (string, string, any) {
+		ids := make([]int64, 20)
+		for i := range ids {
+			ids[i] = b.randomWarehouse(rng)
+		}
+		return http.MethodPost, "/v1/warehouse/batch-get", map[string]any{"ids": ids}
+	},
+	"search": func(b *benchRunner, rng *rand.Rand) (string, string, any) {
+		return http.MethodGet, "/v1/search?q=" + strconv.Itoa(rng.IntN(1000)+1) + "+bench", nil
+	},
+	"nearby": func(b *benchRunner, rng *rand.Rand) (string, string, any) {
+		city := benchCities[rng.IntN(len(benchCities))]
+		return http.MethodGet, fmt.Sprintf("/v1/warehouse/nearby?lat=%f&lng=%f&radius=20000", city.lat, city.lng), nil
+	},
+	"list_stock": func(b *benchRunner, rng *rand.Rand) (string, string, any) {
+		return http.MethodGet, fmt.Sprintf("/v1/stock?sku=BENCH-SKU-%05d", rng.IntN(1000)), nil
+	},
+}
+
+func benchOperationNames() []string {
+	names := make([]string, 0, len(benchOperations))
+	for name := range benchOperations {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+type benchWeight struct {
+	name   string
+	weight int
+}
+
+func parseBenchMix(mix string) ([]benchWeight, error) {
+	var weights []benchWeight
+	for _, pair := range strings.Split(mix, ",") {
+		name, raw, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return nil, fmt.Errorf("mix entry %q must look like operation=weight", pair)
+		}
+		if _, known := benchOperations[name]; !known {
+			return nil, fmt.Errorf("unknown operation %q, expected one of %s", name, strings.Join(benchOperationNames(), ", "))
+		}
+		weight, err := strconv.Atoi(raw)
+		if err != nil || weight < 0 {
+			return nil, fmt.Errorf("weight of %s must be a non-negative integer", name)
+		}
+		if weight > 0 {
+			weights = append(weights, benchWeight{name, weight})
+		}
+	}
+	if len(weights) == 0 {
+		return nil, errors.New("the mix has no operation with a positive weight")
+	}
+	return weights, nil
+}
+
+func pickBenchOperation(weights []benchWeight, total int, rng *rand.Rand) string {
+	n := rng.IntN(total)
+	for _, w := range weights {
+		if n < w.weight {
+			return w.name
+		}
+		n -= w.weight
+	}
+	return weights[len(weights)-1].name
+}
+
+type benchRunner struct {
+	baseURL      string
+	token        string
+	client       *http.Client
+	warehouseIDs []int64
+}
+
+// discover pages through the organization's warehouses so requests hit
+// rows that exist
+func (b *benchRunner) discover(ctx context.Context) error {
+	const pageSize, maxIDs = 100, 10_000
+	for offset := 0; len(b.warehouseIDs) < maxIDs; offset += pageSize {
+		status, body, err := b.do(ctx, http.MethodGet, fmt.Sprintf("/v2/warehouses?limit=%d&offset=%d", pageSize, offset), nil)
+		if err != nil {
+			return fmt.Errorf("list warehouses: %w", err)
+		}
+		if status != http.StatusOK {
+			return fmt.Errorf("list warehouses: status %d: %s", status, body)
+		}
+		var page struct {
+			Data []struct {
+				ID int64 `json:"id"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return fmt.Errorf("list warehouses: %w", err)
+		}
+		for _, w := range page.Data {
+			b.warehouseIDs = append(b.warehouseIDs, w.ID)
+		}
+		if len(page.Data) < pageSize {
+			break
+		}
+	}
+	if len(b.warehouseIDs) == 0 {
+		return errors.New("the organization has no warehouses, run bench generate first")
+	}
+	return nil
+}
+
+func (b *benchRunner) randomWarehouse(rng *rand.Rand) int64 {
+	return b.warehouseIDs[rng.IntN(len(b.warehouseIDs))]
+}
+
+func (b *benchRunner) do(ctx context.Context, method, path string, body any) (int, []byte, error) {
+	var reader io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return 0, nil, err
+		}
+		reader = bytes.NewReader(raw)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, b.baseURL+path, reader)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+b.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	return resp.StatusCode, respBody, err
+}
+
+// benchSamples holds the latencies one worker measured
+type benchSamples struct {
+	latencies map[string][]time.Duration
+	errors    map[string]int
+}
+
+func (b *benchRunner) run(ctx context.Context, weights []benchWeight, concurrency int, duration time.Duration) benchReport {
+	total := 0
+	for _, w := range weights {
+		total += w.weight
+	}
+	ctx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	results := make([]benchSamples, concurrency)
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := range concurrency {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			rng := rand.New(rand.NewPCG(uint64(worker), uint64(start.UnixNano())))
+			samples := benchSamples{latencies: map[string][]time.Duration{}, errors: map[string]int{}}
+			for ctx.Err() == nil {
+				name := pickBenchOperation(weights, total, rng)
+				method, path, body := benchOperations[name](b, rng)
+				opStart := time.Now()
+				status, _, err := b.do(ctx, method, path, body)
+				elapsed := time.Since(opStart)
+				if ctx.Err() != nil {
+					// Requests cut off by the deadline are not measured
+					break
+				}
+				samples.latencies[name] = append(samples.latencies[name], elapsed)
+				if err != nil || status >= 400 {
+					samples.errors[name]++
+				}
+			}
+			results[worker] = samples
+		}(i)
+	}
+	wg.Wait()
+	return newBenchReport(results, time.Since(start))
+}
+
+// benchStats summarises the latencies of one operation
+type benchStats struct {
+	Operation  string        `json:"operation"`
+	Requests   int           `json:"requests"`
+	Errors     int           `json:"errors"`
+	Throughput float64       `json:"requests_per_second"`
+	P50        time.Duration `json:"p50_ns"`
+	P90        time.Duration `json:"p90_ns"`
+	P99        time.Duration `json:"p99_ns"`
+	Max        time.Duration `json:"max_ns"`
+}
+
+type benchReport struct {
+	Duration   time.Duration `json:"duration_ns"`
+	Operations []benchStats  `json:"operations"`
+	Total      benchStats    `json:"total"`
+}
+
+func newBenchReport(results []benchSamples, elapsed time.Duration) benchReport {
+	byOp := map[string][]time.Duration{}
+	errs := map[string]int{}
+	var all []time.Duration
+	totalErrors := 0
+	for _, r := range results {
+		for name, latencies := range r.latencies {
+			byOp[name] = append(byOp[name], latencies...)
+			all = append(all, latencies...)
+		}
+		for name, n := range r.errors {
+			errs[name] += n
+			totalErrors += n
+		}
+	}
+
+	report := benchReport{Duration: elapsed}
+	for _, name := range benchOperationNames() {
+		if latencies, ok := byOp[name]; ok {
+			report.Operations = append(report.Operations, newBenchStats(name, latencies, errs[name], elapsed))
+		}
+	}
+	report.Total = newBenchStats("total", all, totalErrors, elapsed)
+	return report
+}
+
+func newBenchStats(name string, latencies []time.Duration, errs int, elapsed time.Duration) benchStats {
+	slices.Sort(latencies)
+	stats := benchStats{Operation: name, Requests: len(latencies), Errors: errs}
+	if len(latencies) == 0 {
+		return stats
+	}
+	stats.Throughput = float64(len(latencies)) / elapsed.Seconds()
+	stats.P50 = percentile(latencies, 0.50)
+	stats.P90 = percentile(latencies, 0.90)
+	stats.P99 = percentile(latencies, 0.99)
+	stats.Max = latencies[len(latencies)-1]
+	return stats
+}
+
+// percentile returns the nearest rank percentile of sorted latencies
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(p*float64(len(sorted))+0.5) - 1
+	return sorted[min(max(rank, 0), len(sorted)-1)]
+}
+
+func (r benchReport) print(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "operation\trequests\terrors\treq/s\tp50\tp90\tp99\tmax\t")
+	for _, s := range append(r.Operations, r.Total) {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f\t%s\t%s\t%s\t%s\t\n",
+			s.Operation, s.Requests, s.Errors, s.Throughput,
+			s.P50.Round(time.Microsecond), s.P90.Round(time.Microsecond),
+			s.P99.Round(time.Microsecond), s.Max.Round(time.Microsecond))
+	}
+	tw.Flush()
+}
@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	models "warehouse-service/models/sqlc"
+
+	"github.com/spf13/cobra"
+)
+
+// exportPageSize is how many rows each export query reads
+const exportPageSize = 500
+
+var exportEntities = []string{"warehouse", "storage_room"}
+
+var (
+	exportOrg    string
+	exportEntity string
+	exportOutput string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export an organization's warehouses and storage rooms as NDJSON",
+	Long: `Export an organization's warehouses and storage rooms as newline
+delimited JSON, one {"type": ..., "data": ...} object per line.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entities := exportEntities
+		if exportEntity != "" {
+			if !slices.Contains(exportEntities, exportEntity) {
+				return fmt.Errorf("--entity must be one of %v", exportEntities)
+			}
+			entities = []string{exportEntity}
+		}
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		ctx := context.Background()
+		conn, err := connectDB(ctx, cfg, 1)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		out := cmd.OutOrStdout()
+		if exportOutput != "" && exportOutput != "-" {
+			f, err := os.Create(exportOutput)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			out = f
+		}
+		w := bufio.NewWriter(out)
+		e := exporter{queries: models.New(conn), enc: json.NewEncoder(w), orgID: exportOrg}
+		for _, entity := range entities {
+			var n int
+			switch entity {
+			case "warehouse":
+				n, err = e.warehouses(ctx)
+			case "storage_room":
+				n, err = e.storageRooms(ctx)
+			}
+			if err != nil {
+				return fmt.Errorf("export %s: %w", entity, err)
+			}
+			slog.Info("Exported rows", slog.String("entity", entity), slog.Int("count", n))
+		}
+		return w.Flush()
+	},
+}
+
+func init() {
+	exportCmd.Flags().StringVar(&exportOrg, "org", "", "Clerk organization ID to export")
+	exportCmd.Flags().StringVar(&exportEntity, "entity", "", "export only warehouse or storage_room")
+	exportCmd.Flags().StringVar(&exportOutput, "output", "", "file to write, stdout when empty")
+	_ = exportCmd.MarkFlagRequired("org")
+}
+
+type exportLine struct {
+	Type string `json:"type"`
+	Data any    `json:"data"`
+}
+
+type warehouseExport struct {
+	ID             int64           `json:"id"`
+	Name           string          `json:"name"`
+	Address        string          `json:"address"`
+	Ward           string          `json:"ward"`
+	District       string          `json:"district"`
+	City           string          `json:"city"`
+	Country        string          `json:"country"`
+	Latitude       *float64        `json:"latitude"`
+	Longitude      *float64        `json:"longitude"`
+	TimeZone       string          `json:"time_zone"`
+	OperatingHours json.RawMessage `json:"operating_hours,omitempty"`
+	ContactEmail   *string         `json:"contact_email"`
+	ContactPhone   *string         `json:"contact_phone"`
+	Tags           []string        `json:"tags"`
+	Attributes     json.RawMessage `json:"attributes"`
+}
+
+type storageRoomExport struct {
+	ID          int32           `json:"id"`
+	Name        string          `json:"name"`
+	Number      string          `json:"number"`
+	WarehouseID int32           `json:"warehouse_id"`
+	ZoneType    string          `json:"zone_type"`
+	Tags        []string        `json:"tags"`
+	Attributes  json.RawMessage `json:"attributes"`
+}
+
+type exporter struct {
+	queries *models.Queries
+	enc     *json.Encoder
+	orgID   string
+}
+
+func (e exporter) warehouses(ctx context.Context) (int, error) {
+	n := 0
+	for offset := int32(0); ; offset += exportPageSize {
+		rows, err := e.queries.ListWarehouse(ctx, models.ListWarehouseParams{
+			OrgID:  e.orgID,
+			Limit:  exportPageSize,
+			Offset: offset,
+		})
+		if err != nil {
+			return n, err
+		}
+		for _, w := range rows {
+			data := warehouseExport{
+				ID:             w.ID,
+				Name:           w.Name,
+				Address:        w.Address,
+				Ward:           w.Ward,
+				District:       w.District,
+				City:           w.City,
+				Country:        w.Country,
+				TimeZone:       w.TimeZone,
+				OperatingHours: w.OperatingHours,
+				Tags:           w.Tags,
+				Attributes:     rawOrEmpty(w.Attributes),
+			}
+			if w.Latitude.Valid {
+				data.Latitude = &w.Latitude.Float64
+			}
+			if w.Longitude.Valid {
+				data.Longitude = &w.Longitude.Float64
+			}
+			if w.ContactEmail.Valid {
+				data.ContactEmail = &w.ContactEmail.String
+			}
+			if w.ContactPhone.Valid {
+				data.ContactPhone = &w.ContactPhone.String
+			}
+			if err := e.enc.Encode(exportLine{Type: "warehouse", Data: data}); err != nil {
+				return n, err
+			}
+			n++
+		}
+		if len(rows) < exportPageSize {
+			return n, nil
+		}
+	}
+}
+
+func (e exporter) storageRooms(ctx context.Context) (int, error) {
+	n := 0
+	for offset := int32(0); ; offset += exportPageSize {
+		rows, err := e.queries.ListStorageRoom(ctx, models.ListStorageRoomParams{
+			OrgID:  e.orgID,
+			Limit:  exportPageSize,
+			Offset: offset,
+		})
+		if err != nil {
+			return n, err
+		}
+		for _, r := range rows {
+			data := storageRoomExport{
+				ID:          r.ID,
+				Name:        r.Name,
+				Number:      r.Number,
+				WarehouseID: r.WarehouseID,
+				ZoneType:    r.ZoneType,
+				Tags:        r.Tags,
+				Attributes:  rawOrEmpty(r.Attributes),
+			}
+			if err := e.enc.Encode(exportLine{Type: "storage_room", Data: data}); err != nil {
+				return n, err
+			}
+			n++
+		}
+		if len(rows) < exportPageSize {
+			return n, nil
+		}
+	}
+}
+
+func rawOrEmpty(b []byte) json.RawMessage {
+	if len(b) == 0 {
+		return json.RawMessage("{}")
+	}
+	return b
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	healthcheckURL     string
+	healthcheckPath    string
+	healthcheckTimeout time.Duration
+)
+
+var healthcheckCmd = &cobra.Command{
+	Use:   "healthcheck",
+	Short: "Probe the running service, for container health checks",
+	Long: `Probe the service on SERVER_PORT of this host and exit non-zero unless
+it answers 200. Useful as a Docker HEALTHCHECK in images without curl.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		url := healthcheckURL
+		client := &http.Client{Timeout: healthcheckTimeout}
+		if url == "" {
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			scheme := "http"
+			if cfg.TLSEnabled() {
+				scheme = "https"
+				// The certificate is issued for the public name, not 127.0.0.1
+				client.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+			}
+			url = fmt.Sprintf("%s://127.0.0.1:%d%s", scheme, cfg.ServerPort, healthcheckPath)
+		}
+
+		resp, err := client.Get(url)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("%s returned %s", url, resp.Status)
+		}
+		return nil
+	},
+}
+
+func init() {
+	healthcheckCmd.Flags().StringVar(&healthcheckURL, "url", "", "URL to probe instead of the local server")
+	healthcheckCmd.Flags().StringVar(&healthcheckPath, "path", "/healthz", "path to probe on the local server")
+	healthcheckCmd.Flags().DurationVar(&healthcheckTimeout, "timeout", 3*time.Second, "request timeout")
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"warehouse-service/models/migration"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/spf13/cobra"
+)
+
+var migrateAll bool
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Apply or roll back the database migrations embedded in the binary",
+}
+
+var migrateUpCmd = &cobra.Command{
+	Use:   "up [N]",
+	Short: "Apply all pending migrations, or the next N",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withMigrate(func(m *migrate.Migrate) error {
+			if len(args) == 0 {
+				return m.Up()
+			}
+			n, err := stepsArg(args[0])
+			if err != nil {
+				return err
+			}
+			return m.Steps(n)
+		})
+	},
+}
+
+var migrateDownCmd = &cobra.Command{
+	Use:   "down N",
+	Short: "Roll back the last N migrations, or all of them with --all",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if migrateAll == (len(args) == 1) {
+			return errors.New("pass either a number of migrations or --all")
+		}
+		return withMigrate(func(m *migrate.Migrate) error {
+			if migrateAll {
+				return m.Down()
+			}
+			n, err := stepsArg(args[0])
+			if err != nil {
+				return err
+			}
+			return m.Steps(-n)
+		})
+	},
+}
+
+var migrateVersionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the current migration version",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withMigrate(func(m *migrate.Migrate) error {
+			version, dirty, err := m.Version()
+			if errors.Is(err, migrate.ErrNilVersion) {
+				fmt.Fprintln(cmd.OutOrStdout(), "no migrations applied")
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "version %d, dirty %t\n", version, dirty)
+			return nil
+		})
+	},
+}
+
+var migrateForceCmd = &cobra.Command{
+	Use:   "force VERSION",
+	Short: "Mark VERSION as applied and clear the dirty flag after a failed migration was fixed by hand",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		version, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("version must be a number: %w", err)
+		}
+		return withMigrate(func(m *migrate.Migrate) error {
+			return m.Force(version)
+		})
+	},
+}
+
+func init() {
+	migrateDownCmd.Flags().BoolVar(&migrateAll, "all", false, "roll back every migration")
+	migrateCmd.AddCommand(migrateUpCmd, migrateDownCmd, migrateVersionCmd, migrateForceCmd)
+}
+
+func stepsArg(arg string) (int, error) {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("N must be a positive number, got %q", arg)
+	}
+	return n, nil
+}
+
+// withMigrate runs fn with a migrator on DB_SOURCE. Having nothing to do is
+// not an error.
+func withMigrate(fn func(*migrate.Migrate) error) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	m, err := migration.New(cfg.DBSource)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	err = fn(m)
+	if errors.Is(err, migrate.ErrNoChange) {
+		slog.Info("Database is up to date")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if version, dirty, err := m.Version(); err == nil {
+		slog.Info("Migrated database", slog.Uint64("version", uint64(version)), slog.Bool("dirty", dirty))
+	}
+	return nil
+}
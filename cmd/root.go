@@ -0,0 +1,113 @@
+// Package cmd holds the command line interface of the service. serve runs
+// the API, the other commands are one-off admin tasks for operators.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+	"warehouse-service/config"
+	"warehouse-service/dbroute"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spf13/cobra"
+)
+
+var configPath string
+
+var rootCmd = &cobra.Command{
+	Use:   "warehouse-service",
+	Short: "Warehouse and storage room inventory service",
+	// Without a subcommand the service is served, as before the CLI existed
+	RunE:          runServe,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", ".", "directory holding app.env")
+	rootCmd.AddCommand(serveCmd, migrateCmd, seedCmd, assignTenantCmd, createAPIKeyCmd, exportCmd, healthcheckCmd, benchCmd, schemasCmd)
+}
+
+// Execute runs the command named on the command line and exits non-zero
+// when it fails
+func Execute() {
+	if err := rootCmd.Execute(); err != nil {
+		slog.Error("Command failed", slog.String("command", commandName()), slog.Any("error", err))
+		os.Exit(1)
+	}
+}
+
+func commandName() string {
+	if cmd, _, err := rootCmd.Find(os.Args[1:]); err == nil {
+		return cmd.Name()
+	}
+	return rootCmd.Name()
+}
+
+func loadConfig() (config.Config, error) {
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		return cfg, fmt.Errorf("load config: %w", err)
+	}
+	return cfg, nil
+}
+
+// connectDB opens a pool on DB_SOURCE, retrying with exponential backoff
+// up to attempts times since the pool only connects on first use
+func connectDB(ctx context.Context, cfg config.Config, attempts int) (*pgxpool.Pool, error) {
+	slog.Info("Connecting to database", slog.String("db_source", cfg.RedactedDBSource()))
+	poolConfig, err := newPoolConfig(cfg, cfg.DBSource)
+	if err != nil {
+		return nil, fmt.Errorf("parse DB_SOURCE: %w", err)
+	}
+	for attempt := 1; ; attempt++ {
+		var pool *pgxpool.Pool
+		pool, err = pgxpool.NewWithConfig(ctx, poolConfig)
+		if err == nil {
+			if err = pool.Ping(ctx); err == nil {
+				slog.Info("Connected to database successfully")
+				return pool, nil
+			}
+			pool.Close()
+		}
+		slog.Error("Failed to connect to database",
+			slog.Int("attempt", attempt),
+			slog.Int("maxAttempts", attempts),
+			slog.Any("error", err),
+		)
+		if attempt >= attempts {
+			return nil, fmt.Errorf("connect to database: %w", err)
+		}
+
+		backoffDuration := time.Duration(1<<(attempt-1)) * time.Second
+		slog.Info("Retrying connection",
+			slog.Int("attempt", attempt+1),
+			slog.Duration("backoff", backoffDuration),
+		)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoffDuration):
+		}
+	}
+}
+
+// newPoolConfig parses dsn and applies the query exec mode and statement
+// cache settings
+func newPoolConfig(cfg config.Config, dsn string) (*pgxpool.Config, error) {
+	poolConfig, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+	connConfig := poolConfig.ConnConfig
+	connConfig.DefaultQueryExecMode = cfg.QueryExecMode()
+	connConfig.StatementCacheCapacity = cfg.DBStatementCacheCapacity
+	connConfig.DescriptionCacheCapacity = cfg.DBDescriptionCacheCapacity
+	if cfg.DBPrepareHotQueries {
+		poolConfig.AfterConnect = dbroute.PrepareHotQueries
+	}
+	return poolConfig, nil
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"warehouse-service/outbox"
+
+	"github.com/spf13/cobra"
+)
+
+var schemasRegistryURL string
+
+var schemasCmd = &cobra.Command{
+	Use:   "schemas",
+	Short: "List and check the payload schemas of the published events",
+}
+
+var schemasListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the topics with their schema subject and version",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		schemas, err := outbox.Schemas()
+		if err != nil {
+			return err
+		}
+		for _, s := range schemas {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\tv%d\t%s\n", s.Topic, s.Subject, s.Version, s.File)
+		}
+		return nil
+	},
+}
+
+var schemasCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check the schemas against the versions in the schema registry",
+	Long: `Check every payload schema against the latest version of its subject
+in the schema registry, without registering anything, and exit non-zero
+when one breaks the compatibility level. Run it in CI before a release.
+The registry is SCHEMA_REGISTRY_URL unless --registry is given.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		registryURL := schemasRegistryURL
+		if registryURL == "" {
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			registryURL = cfg.SchemaRegistryURL
+		}
+		if registryURL == "" {
+			return errors.New("no schema registry, set SCHEMA_REGISTRY_URL or --registry")
+		}
+		registry, err := outbox.NewRegistry(registryURL)
+		if err != nil {
+			return err
+		}
+		schemas, err := outbox.Schemas()
+		if err != nil {
+			return err
+		}
+
+		ctx := context.Background()
+		incompatible := 0
+		for _, s := range schemas {
+			problems, err := registry.Check(ctx, s)
+			if err != nil {
+				return fmt.Errorf("check %s: %w", s.Subject, err)
+			}
+			for _, problem := range problems {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s: %s\n", s.Subject, problem)
+			}
+			if len(problems) > 0 {
+				incompatible++
+			}
+		}
+		if incompatible > 0 {
+			return fmt.Errorf("%d of %d schemas are %w", incompatible, len(schemas), outbox.ErrIncompatible)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%d schemas are compatible\n", len(schemas))
+		return nil
+	},
+}
+
+func init() {
+	schemasCheckCmd.Flags().StringVar(&schemasRegistryURL, "registry", "", "schema registry URL instead of SCHEMA_REGISTRY_URL")
+	schemasCmd.AddCommand(schemasListCmd, schemasCheckCmd)
+}
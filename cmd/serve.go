@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"log/syslog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	"warehouse-service/api"
+	"warehouse-service/config"
+	"warehouse-service/dbroute"
+	"warehouse-service/middlewares"
+	"warehouse-service/observability"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spf13/cobra"
+)
+
+const attemptThreshold = 5
+
+// shutdownTimeout bounds draining requests, stopping the workers and
+// flushing the logs after SIGINT or SIGTERM
+const shutdownTimeout = 30 * time.Second
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run the HTTP API",
+	Args:  cobra.NoArgs,
+	RunE:  runServe,
+}
+
+// setupLogging configures logging based on environment variables
+func setupLogging(cfg config.Config) error {
+	// Priority order: OTLP > Loki > Syslog > File > Stdout
+	failover := observability.LogFailover{
+		FailureThreshold: cfg.LogSinkFailureThreshold,
+		ProbeInterval:    cfg.LogSinkProbeInterval,
+		FilePath:         cfg.LogFilePath,
+	}
+
+	// Option 1: Direct OTLP Logs (recommended for OpenTelemetry), which are
+	// only sent over http/protobuf
+	if cfg.OTELExporterOTLPEndpoint != "" && cfg.OTELExporterOTLPProtocol == observability.OTLPProtocolHTTP {
+		endpoint := "https://" + cfg.OTELExporterOTLPEndpoint
+		if cfg.OTELExporterOTLPInsecure {
+			endpoint = "http://" + cfg.OTELExporterOTLPEndpoint
+		}
+		if err := observability.SetupOTLPLogging(endpoint, cfg.ServiceName, failover); err == nil {
+			slog.Info("Using OTLP logging", slog.String("endpoint", endpoint))
+			return nil
+		}
+		slog.Warn("OTLP logging failed, trying next option")
+	}
+
+	// Option 2: Direct Loki HTTP (no file needed)
+	if cfg.LokiURL != "" {
+		if err := observability.SetupDirectLokiLogging(cfg.LokiURL, cfg.ServiceName, failover); err == nil {
+			slog.Info("Using direct Loki logging", slog.String("url", cfg.LokiURL))
+			return nil
+		}
+		slog.Warn("Direct Loki logging failed, trying next option")
+	}
+
+	// Option 3: Syslog (for traditional setups)
+	if cfg.SyslogAddress != "" {
+		syslogConfig := observability.SyslogConfig{
+			Network:      cfg.SyslogNetwork,
+			Address:      cfg.SyslogAddress,
+			Facility:     syslog.LOG_LOCAL0,
+			Tag:          cfg.ServiceName,
+			CAFile:       cfg.SyslogTLSCAFile,
+			CertFile:     cfg.SyslogTLSCertFile,
+			KeyFile:      cfg.SyslogTLSKeyFile,
+			EnterpriseID: cfg.SyslogEnterpriseID,
+			BufferSize:   cfg.SyslogBufferSize,
+		}
+		if err := observability.SetupSyslogLogging(syslogConfig); err == nil {
+			slog.Info("Using syslog logging", slog.String("address", cfg.SyslogAddress))
+			return nil
+		}
+		slog.Warn("Syslog logging failed, trying next option")
+	}
+
+	// Option 4: File logging (fallback)
+	if cfg.LogFilePath != "" {
+		logConfig := observability.LogConfig{
+			FilePath:      cfg.LogFilePath,
+			MaxSizeMB:     100,
+			MaxBackups:    5,
+			MaxAgeDays:    30,
+			Compress:      true,
+			BufferSize:    cfg.LogFileBufferSize,
+			FlushInterval: cfg.LogFileFlushInterval,
+			Fsync:         cfg.LogFileFsync,
+		}
+		if err := observability.SetupAdvancedFileLogger(logConfig); err == nil {
+			slog.Info("Using file logging", slog.String("path", cfg.LogFilePath))
+			return nil
+		}
+		slog.Warn("File logging failed, using stdout")
+	}
+
+	// Option 5: Default stdout JSON logging
+	observability.SetupStdoutLogging()
+	slog.Info("Using default stdout logging")
+	return nil
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	observability.SetLogLevel(cfg.SlogLevel())
+	observability.SetTraceSampleRatio(cfg.TraceSampleRatio)
+	middlewares.SetAccessLogSampling(middlewares.AccessLogSampling{
+		Ratio:         cfg.AccessLogSampleRatio,
+		SlowThreshold: cfg.AccessLogSlowThreshold,
+	})
+	slog.Info("Loaded config", slog.Any("config", cfg))
+
+	if len(cfg.LogRedactFields) > 0 || len(cfg.LogRedactPatterns) > 0 {
+		// Validated with the config
+		redactor, _ := observability.NewRedactor(cfg.LogRedactFields, cfg.LogRedactPatterns)
+		observability.SetLogRedaction(redactor)
+	}
+
+	slog.Info("Set Up Logging.....")
+	// Setup logging based on configuration
+	if err := setupLogging(cfg); err != nil {
+		slog.Error("Failed to setup logging", slog.Any("error", err))
+		// Continue with stdout logging if setup fails
+	}
+
+	middlewares.SetClerkKey(cfg.ClerKKey)
+	config.WatchSecret(context.Background(), "CLERK_KEY", cfg.SecretRefreshInterval, middlewares.SetClerkKey)
+
+	conn, err := connectDB(context.Background(), cfg, attemptThreshold)
+	if err != nil {
+		return err
+	}
+	replicas, err := openReplicas(context.Background(), cfg)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	db := dbroute.New(conn, replicas, cfg.DBReplicaCheckInterval)
+
+	// Create server with warehouse-specific service name
+	router := api.NewServer(db, cfg.ServiceName, "1.0.0", cfg.OTELExporterOTLPEndpoint, cfg.OTELExporterOTLPHeaders, cfg)
+
+	// Log level, trace sampling and CORS origins follow app.env and SIGHUP
+	config.Watch(context.Background(), router.ApplyConfig)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	errc := make(chan error, 1)
+	go func() { errc <- router.Run(cfg.ListenAddr(), cfg.ServiceName) }()
+	var runErr error
+	select {
+	case runErr = <-errc:
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	shutdownErr := router.Shutdown(shutdownCtx)
+	slog.Info("Stopped warehouse service server")
+	return errors.Join(runErr, shutdownErr, observability.FlushLogs(shutdownCtx))
+}
+
+// openReplicas opens a pool per DB_REPLICA_SOURCES entry. They connect on
+// first use, an unreachable replica is skipped by the router until its lag
+// check succeeds.
+func openReplicas(ctx context.Context, cfg config.Config) ([]*pgxpool.Pool, error) {
+	var pools []*pgxpool.Pool
+	closeAll := func() {
+		for _, pool := range pools {
+			pool.Close()
+		}
+	}
+	for i, source := range cfg.ReplicaSources() {
+		poolConfig, err := newPoolConfig(cfg, source)
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("parse read replica %d: %w", i, err)
+		}
+		pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("open read replica %d: %w", i, err)
+		}
+		slog.Info("Opened read replica pool",
+			slog.Int("replica", i),
+			slog.String("db_source", config.RedactDSN(source)))
+		pools = append(pools, pool)
+	}
+	return pools, nil
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	models "warehouse-service/models/sqlc"
+
+	"github.com/spf13/cobra"
+)
+
+var assignTenantOrg string
+
+var assignTenantCmd = &cobra.Command{
+	Use:   "assign-tenant",
+	Short: "Give the warehouses and storage rooms without an organization to one",
+	Long: `Give the warehouses and storage rooms created before tenancy, whose org_id
+is empty, to an organization. Until then no request can see them. Storage
+rooms go to the organization of their warehouse, so a database holding the
+data of several organizations is assigned by setting the org_id of its
+warehouses by hand and running the command for the rest.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if strings.TrimSpace(assignTenantOrg) == "" {
+			return errors.New("--org must name an organization")
+		}
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		ctx := context.Background()
+		conn, err := connectDB(ctx, cfg, 1)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		tx, err := conn.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx) // This will be ignored if tx.Commit() succeeds
+
+		qtx := models.New(tx)
+		warehouses, err := qtx.AssignUnownedWarehouses(ctx, assignTenantOrg)
+		if err != nil {
+			return fmt.Errorf("assign warehouses: %w", err)
+		}
+		storageRooms, err := qtx.AssignUnownedStorageRooms(ctx)
+		if err != nil {
+			return fmt.Errorf("assign storage rooms: %w", err)
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+		slog.Info("Assigned rows without an organization",
+			slog.String("org_id", assignTenantOrg),
+			slog.Int64("warehouses", warehouses),
+			slog.Int64("storage_rooms", storageRooms),
+		)
+		return nil
+	},
+}
+
+func init() {
+	assignTenantCmd.Flags().StringVar(&assignTenantOrg, "org", "", "Clerk organization ID to give the rows to")
+	_ = assignTenantCmd.MarkFlagRequired("org")
+}
@@ -1,18 +1,400 @@
 package config
 
-import "github.com/spf13/viper"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/spf13/viper"
+)
 
 type Config struct {
 	ServiceName              string `mapstructure:"SERVICE_NAME"`
 	OTELExporterOTLPEndpoint string `mapstructure:"OTEL_EXPORTER_OTLP_ENDPOINT"`
 	OTELExporterOTLPHeaders  string `mapstructure:"OTEL_EXPORTER_OTLP_HEADERS"`
-	OTELResourceAttreibutes  string `mapstructure:"OTEL_RESOURCE_ATTRIBUTES"`
+	OTELResourceAttributes   string `mapstructure:"OTEL_RESOURCE_ATTRIBUTES"`
 	DBSource                 string `mapstructure:"DB_SOURCE"`
 	ClerKKey                 string `mapstructure:"CLERK_KEY"`
 	LogFilePath              string `mapstructure:"LOG_FILE_PATH"`
 	LokiURL                  string `mapstructure:"LOKI_URL"`
-	SyslogAddress            string `mapstructure:"SYSLOG_ADDRESS"`
-	SyslogNetwork            string `mapstructure:"SYSLOG_NETWORK"`
+	// Up to LOG_FILE_BUFFER_SIZE bytes of records are buffered before the
+	// log file is written, at least every LOG_FILE_FLUSH_INTERVAL and on
+	// shutdown; 0 writes each record. LOG_FILE_FSYNC syncs the file never,
+	// after every flush (interval) or on every record (always).
+	LogFileBufferSize    int           `mapstructure:"LOG_FILE_BUFFER_SIZE"`
+	LogFileFlushInterval time.Duration `mapstructure:"LOG_FILE_FLUSH_INTERVAL"`
+	LogFileFsync         string        `mapstructure:"LOG_FILE_FSYNC"`
+	SyslogAddress        string        `mapstructure:"SYSLOG_ADDRESS"`
+	SyslogNetwork        string        `mapstructure:"SYSLOG_NETWORK"`
+	// Syslog messages are RFC 5424 over udp, tcp or tls (RFC 5425), which
+	// trusts the system roots or SYSLOG_TLS_CA_FILE, with an optional client
+	// certificate. Up to SYSLOG_BUFFER_SIZE messages wait while the receiver
+	// is down. SYSLOG_ENTERPRISE_ID is the private enterprise number of the
+	// structured data IDs; the default 32473 is reserved for examples.
+	SyslogTLSCAFile    string `mapstructure:"SYSLOG_TLS_CA_FILE"`
+	SyslogTLSCertFile  string `mapstructure:"SYSLOG_TLS_CERT_FILE"`
+	SyslogTLSKeyFile   string `mapstructure:"SYSLOG_TLS_KEY_FILE"`
+	SyslogBufferSize   int    `mapstructure:"SYSLOG_BUFFER_SIZE"`
+	SyslogEnterpriseID string `mapstructure:"SYSLOG_ENTERPRISE_ID"`
+	// Consecutive failed sends that take the OTLP or Loki log sink down, its
+	// records then going to LOG_FILE_PATH until a probe every
+	// LOG_SINK_PROBE_INTERVAL reaches it again
+	LogSinkFailureThreshold int           `mapstructure:"LOG_SINK_FAILURE_THRESHOLD"`
+	LogSinkProbeInterval    time.Duration `mapstructure:"LOG_SINK_PROBE_INTERVAL"`
+	JobWorkers              int           `mapstructure:"JOB_WORKERS"`
+	JobPollInterval         time.Duration `mapstructure:"JOB_POLL_INTERVAL"`
+
+	// OTLP trace export over http/protobuf or grpc. TLS is used unless
+	// OTEL_EXPORTER_OTLP_INSECURE, trusting the system roots or
+	// OTEL_EXPORTER_OTLP_CERTIFICATE, with an optional client certificate.
+	// OTEL_EXPORTER_OTLP_URL_PATH replaces /v1/traces over http, e.g.
+	// /otlp/v1/traces for Grafana Cloud.
+	OTELExporterOTLPProtocol          string `mapstructure:"OTEL_EXPORTER_OTLP_PROTOCOL"`
+	OTELExporterOTLPInsecure          bool   `mapstructure:"OTEL_EXPORTER_OTLP_INSECURE"`
+	OTELExporterOTLPCertificate       string `mapstructure:"OTEL_EXPORTER_OTLP_CERTIFICATE"`
+	OTELExporterOTLPClientCertificate string `mapstructure:"OTEL_EXPORTER_OTLP_CLIENT_CERTIFICATE"`
+	OTELExporterOTLPClientKey         string `mapstructure:"OTEL_EXPORTER_OTLP_CLIENT_KEY"`
+	OTELExporterOTLPCompression       string `mapstructure:"OTEL_EXPORTER_OTLP_COMPRESSION"`
+	OTELExporterOTLPURLPath           string `mapstructure:"OTEL_EXPORTER_OTLP_URL_PATH"`
+
+	// Cron specs for scheduled tasks, an empty spec disables the task
+	ScheduleExpirePickLists string        `mapstructure:"SCHEDULE_EXPIRE_PICK_LISTS"`
+	ScheduleRefreshGauges   string        `mapstructure:"SCHEDULE_REFRESH_GAUGES"`
+	SchedulePruneAuditLogs  string        `mapstructure:"SCHEDULE_PRUNE_AUDIT_LOGS"`
+	PickListAllocationTTL   time.Duration `mapstructure:"PICK_LIST_ALLOCATION_TTL"`
+	AuditRetention          time.Duration `mapstructure:"AUDIT_RETENTION"`
+
+	// Monthly temperature reading partitions are created ahead and dropped
+	// once they end more than TEMPERATURE_RETENTION ago
+	ScheduleTemperaturePartitions string        `mapstructure:"SCHEDULE_TEMPERATURE_PARTITIONS"`
+	TemperatureRetention          time.Duration `mapstructure:"TEMPERATURE_RETENTION"`
+	// Change feed events are kept this long for clients that resume
+	SchedulePruneChangeEvents string        `mapstructure:"SCHEDULE_PRUNE_CHANGE_EVENTS"`
+	ChangeEventRetention      time.Duration `mapstructure:"CHANGE_EVENT_RETENTION"`
+	// Dashboard stats are at most this stale
+	ScheduleRefreshDashboardStats string `mapstructure:"SCHEDULE_REFRESH_DASHBOARD_STATS"`
+	// Consumption behind reorder suggestions, nightly by default
+	ScheduleRefreshSkuConsumption string `mapstructure:"SCHEDULE_REFRESH_SKU_CONSUMPTION"`
+
+	// Outbox events are POSTed to OUTBOX_BROKER_URL, or only logged when it
+	// is empty. Delivered events are kept for OUTBOX_RETENTION.
+	OutboxBrokerURL     string        `mapstructure:"OUTBOX_BROKER_URL"`
+	OutboxPollInterval  time.Duration `mapstructure:"OUTBOX_POLL_INTERVAL"`
+	OutboxBatchSize     int           `mapstructure:"OUTBOX_BATCH_SIZE"`
+	OutboxMaxAttempts   int           `mapstructure:"OUTBOX_MAX_ATTEMPTS"`
+	SchedulePruneOutbox string        `mapstructure:"SCHEDULE_PRUNE_OUTBOX"`
+	OutboxRetention     time.Duration `mapstructure:"OUTBOX_RETENTION"`
+	// The payload schemas of the published topics are registered with the
+	// Confluent compatible registry at SCHEMA_REGISTRY_URL on start, with
+	// the compatibility level SCHEMA_REGISTRY_COMPATIBILITY
+	SchemaRegistryURL           string `mapstructure:"SCHEMA_REGISTRY_URL"`
+	SchemaRegistryCompatibility string `mapstructure:"SCHEMA_REGISTRY_COMPATIBILITY"`
+	// Consumed messages are remembered for INBOX_RETENTION, a message
+	// delivered again within it is dropped as a duplicate
+	SchedulePruneInbox string        `mapstructure:"SCHEDULE_PRUNE_INBOX"`
+	InboxRetention     time.Duration `mapstructure:"INBOX_RETENTION"`
+
+	// Tenant quotas, zero is unlimited. API calls are counted per UTC day
+	// for the tenant and for each user or API key.
+	QuotaMaxWarehouses               int64         `mapstructure:"QUOTA_MAX_WAREHOUSES"`
+	QuotaMaxStorageRoomsPerWarehouse int64         `mapstructure:"QUOTA_MAX_STORAGE_ROOMS_PER_WAREHOUSE"`
+	QuotaMaxAPICallsPerDay           int64         `mapstructure:"QUOTA_MAX_API_CALLS_PER_DAY"`
+	QuotaMaxAPICallsPerUserPerDay    int64         `mapstructure:"QUOTA_MAX_API_CALLS_PER_USER_PER_DAY"`
+	SchedulePruneAPIUsage            string        `mapstructure:"SCHEDULE_PRUNE_API_USAGE"`
+	APIUsageRetention                time.Duration `mapstructure:"API_USAGE_RETENTION"`
+	// Daily usage per tenant for billing, aggregated from the API call
+	// counts before they are pruned
+	ScheduleAggregateMetering string `mapstructure:"SCHEDULE_AGGREGATE_METERING"`
+
+	// Nominatim compatible geocoding API, geocoding is off when empty
+	GeocoderURL string `mapstructure:"GEOCODER_URL"`
+	// Address validation service warehouse addresses are checked with on
+	// create and update, off when empty; the token is sent as a bearer token
+	AddressValidatorURL   string `mapstructure:"ADDRESS_VALIDATOR_URL"`
+	AddressValidatorToken string `mapstructure:"ADDRESS_VALIDATOR_TOKEN"`
+
+	// gRPC addresses, host:port, of the sibling inventory-service and
+	// order-service; the workflows calling a service are off without its
+	// address. Calls use TLS trusting the system roots or
+	// SERVICES_TLS_CA_FILE, presenting SERVICES_TLS_CERT_FILE when set, or
+	// plain text with SERVICES_INSECURE. An attempt is bounded by
+	// SERVICES_TIMEOUT and a call is tried SERVICES_MAX_ATTEMPTS times while
+	// the service is unavailable. SERVICES_BREAKER_FAILURES failed calls in a
+	// row pause calls to the service for SERVICES_BREAKER_COOLDOWN.
+	InventoryServiceAddr    string        `mapstructure:"INVENTORY_SERVICE_ADDR"`
+	OrderServiceAddr        string        `mapstructure:"ORDER_SERVICE_ADDR"`
+	ServicesInsecure        bool          `mapstructure:"SERVICES_INSECURE"`
+	ServicesTLSCAFile       string        `mapstructure:"SERVICES_TLS_CA_FILE"`
+	ServicesTLSCertFile     string        `mapstructure:"SERVICES_TLS_CERT_FILE"`
+	ServicesTLSKeyFile      string        `mapstructure:"SERVICES_TLS_KEY_FILE"`
+	ServicesTimeout         time.Duration `mapstructure:"SERVICES_TIMEOUT"`
+	ServicesMaxAttempts     int           `mapstructure:"SERVICES_MAX_ATTEMPTS"`
+	ServicesBreakerFailures int           `mapstructure:"SERVICES_BREAKER_FAILURES"`
+	ServicesBreakerCooldown time.Duration `mapstructure:"SERVICES_BREAKER_COOLDOWN"`
+
+	// Warehouse attachments are stored in this S3 compatible bucket and are
+	// off when it is empty. MinIO needs ATTACHMENT_ENDPOINT and path style
+	// addressing, credentials come from the AWS default chain.
+	AttachmentBucket       string        `mapstructure:"ATTACHMENT_BUCKET"`
+	AttachmentRegion       string        `mapstructure:"ATTACHMENT_REGION"`
+	AttachmentEndpoint     string        `mapstructure:"ATTACHMENT_ENDPOINT"`
+	AttachmentPathStyle    bool          `mapstructure:"ATTACHMENT_PATH_STYLE"`
+	AttachmentMaxSize      int64         `mapstructure:"ATTACHMENT_MAX_SIZE"`
+	AttachmentContentTypes []string      `mapstructure:"ATTACHMENT_CONTENT_TYPES"`
+	AttachmentURLTTL       time.Duration `mapstructure:"ATTACHMENT_URL_TTL"`
+	// Uploads are POSTed here before they are stored, empty skips scanning
+	AttachmentScanURL string `mapstructure:"ATTACHMENT_SCAN_URL"`
+
+	// 846 inventory advice is uploaded to suppliers' SFTP drops on this
+	// schedule, authenticating with the PEM private key in
+	// EDI_SFTP_KEY_FILE. Uploads are off without the key.
+	ScheduleExportInventoryAdvice string `mapstructure:"SCHEDULE_EXPORT_INVENTORY_ADVICE"`
+	EDISFTPKeyFile                string `mapstructure:"EDI_SFTP_KEY_FILE"`
+
+	// File exchanges pick up inbound files on SCHEDULE_FILE_EXCHANGE and
+	// write their extracts on SCHEDULE_FILE_EXCHANGE_EXTRACT. SFTP folders
+	// authenticate with EDI_SFTP_KEY_FILE, s3:// folders are buckets of the
+	// store of EXCHANGE_S3_REGION or EXCHANGE_S3_ENDPOINT with credentials
+	// from the AWS default chain.
+	ScheduleFileExchange        string `mapstructure:"SCHEDULE_FILE_EXCHANGE"`
+	ScheduleFileExchangeExtract string `mapstructure:"SCHEDULE_FILE_EXCHANGE_EXTRACT"`
+	ExchangeS3Region            string `mapstructure:"EXCHANGE_S3_REGION"`
+	ExchangeS3Endpoint          string `mapstructure:"EXCHANGE_S3_ENDPOINT"`
+	ExchangeS3PathStyle         bool   `mapstructure:"EXCHANGE_S3_PATH_STYLE"`
+
+	// Integrations that are due are synced with their channel on this
+	// schedule: orders are pulled and reserved, available stock is pushed
+	ScheduleSyncIntegrations string `mapstructure:"SCHEDULE_SYNC_INTEGRATIONS"`
+
+	// CORS, origins accept "*" for any origin and wildcards such as
+	// https://*.example.com, patterns are regular expressions matched
+	// against the full origin
+	CORSAllowOrigins        []string      `mapstructure:"CORS_ALLOW_ORIGINS"`
+	CORSAllowOriginPatterns []string      `mapstructure:"CORS_ALLOW_ORIGIN_PATTERNS"`
+	CORSAllowHeaders        []string      `mapstructure:"CORS_ALLOW_HEADERS"`
+	CORSAllowCredentials    bool          `mapstructure:"CORS_ALLOW_CREDENTIALS"`
+	CORSMaxAge              time.Duration `mapstructure:"CORS_MAX_AGE"`
+
+	// ENVIRONMENT is development, staging or production, GIN_MODE overrides
+	// the gin mode derived from it
+	Environment string `mapstructure:"ENVIRONMENT"`
+	GinMode     string `mapstructure:"GIN_MODE"`
+	// POST /v1/admin/seed loads fixture sets, never allowed in production
+	SeedEndpointEnabled bool `mapstructure:"SEED_ENDPOINT_ENABLED"`
+	// Clerk user IDs allowed on the operator /admin endpoints, which are not
+	// registered while the list is empty
+	AdminUserIDs []string `mapstructure:"ADMIN_USER_IDS"`
+	// Clerk user IDs of internal service accounts, which skip the ownership
+	// checks and act for the organization in X-Tenant-ID. Organization
+	// roles are granted by each tenant's admins and never make one.
+	ServiceAccountUserIDs []string `mapstructure:"SERVICE_ACCOUNT_USER_IDS"`
+
+	// Redaction applied to every log sink. LOG_REDACT_FIELDS are regular
+	// expressions of attribute keys whose values are dropped, matched
+	// case-insensitively; LOG_REDACT_PATTERNS names the patterns scrubbed
+	// from messages and other values: dsn, token, secret and email.
+	LogRedactFields   []string `mapstructure:"LOG_REDACT_FIELDS"`
+	LogRedactPatterns []string `mapstructure:"LOG_REDACT_PATTERNS"`
+
+	// Reloadable at runtime through SIGHUP or an app.env change, together
+	// with the CORS origins
+	LogLevel         string  `mapstructure:"LOG_LEVEL"`
+	TraceSampleRatio float64 `mapstructure:"TRACE_SAMPLE_RATIO"`
+	// Fraction of successful requests written to the access log. Errors and
+	// requests taking at least ACCESS_LOG_SLOW_THRESHOLD are always logged,
+	// 0 turns the threshold off.
+	AccessLogSampleRatio   float64       `mapstructure:"ACCESS_LOG_SAMPLE_RATIO"`
+	AccessLogSlowThreshold time.Duration `mapstructure:"ACCESS_LOG_SLOW_THRESHOLD"`
+
+	// SERVER_ADDR takes precedence over SERVER_PORT when both are set
+	ServerAddr string `mapstructure:"SERVER_ADDR"`
+	ServerPort int    `mapstructure:"SERVER_PORT"`
+	// TLS is served when both files are set, HTTP/2 is negotiated over TLS
+	// and SERVER_H2C enables cleartext HTTP/2 otherwise
+	TLSCertFile string `mapstructure:"TLS_CERT_FILE"`
+	TLSKeyFile  string `mapstructure:"TLS_KEY_FILE"`
+	ServerH2C   bool   `mapstructure:"SERVER_H2C"`
+	// Plain HTTP listener redirecting to HTTPS, empty disables it
+	HTTPRedirectAddr string `mapstructure:"HTTP_REDIRECT_ADDR"`
+
+	// Connection limits of every listener. Zero read, write and idle
+	// timeouts disable them, streaming endpoints lift the write timeout
+	// themselves. SERVER_HTTP2_PING_TIMEOUT pings idle HTTP/2 connections
+	// and closes those that don't answer, zero disables it.
+	ServerReadHeaderTimeout         time.Duration `mapstructure:"SERVER_READ_HEADER_TIMEOUT"`
+	ServerReadTimeout               time.Duration `mapstructure:"SERVER_READ_TIMEOUT"`
+	ServerWriteTimeout              time.Duration `mapstructure:"SERVER_WRITE_TIMEOUT"`
+	ServerIdleTimeout               time.Duration `mapstructure:"SERVER_IDLE_TIMEOUT"`
+	ServerMaxHeaderBytes            int           `mapstructure:"SERVER_MAX_HEADER_BYTES"`
+	ServerHTTP2MaxConcurrentStreams int           `mapstructure:"SERVER_HTTP2_MAX_CONCURRENT_STREAMS"`
+	ServerHTTP2MaxReadFrameSize     int           `mapstructure:"SERVER_HTTP2_MAX_READ_FRAME_SIZE"`
+	ServerHTTP2PingTimeout          time.Duration `mapstructure:"SERVER_HTTP2_PING_TIMEOUT"`
+
+	// Request bodies over MAX_REQUEST_BODY_SIZE bytes are answered with 413,
+	// zero disables the limit. Attachment uploads are bound by
+	// ATTACHMENT_MAX_SIZE instead.
+	MaxRequestBodySize int64 `mapstructure:"MAX_REQUEST_BODY_SIZE"`
+	// Responses of at least COMPRESSION_MIN_SIZE bytes are sent gzip or
+	// deflate encoded to clients accepting it, at COMPRESSION_LEVEL 1 to 9
+	CompressionEnabled bool `mapstructure:"COMPRESSION_ENABLED"`
+	CompressionMinSize int  `mapstructure:"COMPRESSION_MIN_SIZE"`
+	CompressionLevel   int  `mapstructure:"COMPRESSION_LEVEL"`
+
+	// Mutual TLS listener for backend services without Clerk tokens, empty
+	// disables it. Clients need a certificate signed by
+	// INTERNAL_CLIENT_CA_FILE whose SPIFFE ID, or common name, is in
+	// INTERNAL_ALLOWED_IDENTITIES when that is set. Only the route groups of
+	// INTERNAL_ROUTE_GROUPS are served.
+	InternalAddr              string   `mapstructure:"INTERNAL_ADDR"`
+	InternalTLSCertFile       string   `mapstructure:"INTERNAL_TLS_CERT_FILE"`
+	InternalTLSKeyFile        string   `mapstructure:"INTERNAL_TLS_KEY_FILE"`
+	InternalClientCAFile      string   `mapstructure:"INTERNAL_CLIENT_CA_FILE"`
+	InternalAllowedIdentities []string `mapstructure:"INTERNAL_ALLOWED_IDENTITIES"`
+	InternalRouteGroups       []string `mapstructure:"INTERNAL_ROUTE_GROUPS"`
+
+	// How often rotating secrets such as CLERK_KEY are re-read from their
+	// file or secret manager, zero disables the refresh
+	SecretRefreshInterval time.Duration `mapstructure:"SECRET_REFRESH_INTERVAL"`
+
+	// Clerk session tokens are checked against a cached JWKS refreshed every
+	// CLERK_JWKS_REFRESH_INTERVAL. After CLERK_JWKS_FAILURE_THRESHOLD failed
+	// fetches in a row Clerk is left alone for CLERK_JWKS_COOLDOWN while the
+	// cached keys keep serving. CLERK_JWT_KEY, the PEM public key from the
+	// Clerk dashboard, verifies tokens without ever calling Clerk.
+	ClerkJWKSRefreshInterval  time.Duration `mapstructure:"CLERK_JWKS_REFRESH_INTERVAL"`
+	ClerkJWKSFailureThreshold int           `mapstructure:"CLERK_JWKS_FAILURE_THRESHOLD"`
+	ClerkJWKSCooldown         time.Duration `mapstructure:"CLERK_JWKS_COOLDOWN"`
+	ClerkClockSkew            time.Duration `mapstructure:"CLERK_CLOCK_SKEW"`
+	ClerkJWTKey               string        `mapstructure:"CLERK_JWT_KEY"`
+
+	// Authorization policy evaluated before every authenticated handler:
+	// rules, the JSON rules of POLICY_FILE which allow everything when
+	// unset, or opa, the document OPA_POLICY_PATH of the OPA server at
+	// OPA_URL. POLICY_DECISION_LOG is none, deny or all.
+	PolicyEngine      string `mapstructure:"POLICY_ENGINE"`
+	PolicyFile        string `mapstructure:"POLICY_FILE"`
+	OPAURL            string `mapstructure:"OPA_URL"`
+	OPAPolicyPath     string `mapstructure:"OPA_POLICY_PATH"`
+	PolicyDecisionLog string `mapstructure:"POLICY_DECISION_LOG"`
+
+	// How pgx sends queries, one of cache_statement, cache_describe,
+	// describe_exec, exec or simple_protocol. Behind a transaction pooling
+	// PgBouncer without prepared statement support use exec or
+	// simple_protocol and turn DB_PREPARE_HOT_QUERIES off.
+	DBQueryExecMode            string `mapstructure:"DB_QUERY_EXEC_MODE"`
+	DBStatementCacheCapacity   int    `mapstructure:"DB_STATEMENT_CACHE_CAPACITY"`
+	DBDescriptionCacheCapacity int    `mapstructure:"DB_DESCRIPTION_CACHE_CAPACITY"`
+	// Prepares GetWarehouse and ListWarehouse on every new connection, so
+	// they skip parsing and planning whatever DB_QUERY_EXEC_MODE is
+	DBPrepareHotQueries bool `mapstructure:"DB_PREPARE_HOT_QUERIES"`
+
+	// Comma separated read replica connection strings, resolved like
+	// DB_SOURCE. Endpoints that tolerate stale reads use a replica whose lag,
+	// measured every DB_REPLICA_CHECK_INTERVAL, is within their tolerance.
+	DBReplicaSources       string        `mapstructure:"DB_REPLICA_SOURCES"`
+	DBReplicaCheckInterval time.Duration `mapstructure:"DB_REPLICA_CHECK_INTERVAL"`
+
+	// Per-route objectives behind the slo_* metrics: the share of requests
+	// without a 5xx, and of those the share answered within
+	// SLO_LATENCY_THRESHOLD. Streaming routes are left out.
+	SLOAvailabilityTarget float64       `mapstructure:"SLO_AVAILABILITY_TARGET"`
+	SLOLatencyTarget      float64       `mapstructure:"SLO_LATENCY_TARGET"`
+	SLOLatencyThreshold   time.Duration `mapstructure:"SLO_LATENCY_THRESHOLD"`
+	SLOExcludedRoutes     []string      `mapstructure:"SLO_EXCLUDED_ROUTES"`
+
+	// Upper bounds in seconds of the buckets of http_request_duration_seconds,
+	// database_operation_duration_seconds and operation_duration_seconds,
+	// comma separated. METRICS_NATIVE_HISTOGRAMS exposes them as Prometheus
+	// native histograms as well. OTel histograms use the same buckets, or an
+	// exponential aggregation with
+	// OTEL_EXPORTER_OTLP_METRICS_DEFAULT_HISTOGRAM_AGGREGATION set to
+	// base2_exponential_bucket_histogram.
+	MetricsHTTPBuckets              []float64 `mapstructure:"METRICS_HTTP_BUCKETS"`
+	MetricsDBBuckets                []float64 `mapstructure:"METRICS_DB_BUCKETS"`
+	MetricsOperationBuckets         []float64 `mapstructure:"METRICS_OPERATION_BUCKETS"`
+	MetricsNativeHistograms         bool      `mapstructure:"METRICS_NATIVE_HISTOGRAMS"`
+	OTELMetricsHistogramAggregation string    `mapstructure:"OTEL_EXPORTER_OTLP_METRICS_DEFAULT_HISTOGRAM_AGGREGATION"`
+}
+
+// ReplicaSources splits DB_REPLICA_SOURCES into connection strings
+func (c Config) ReplicaSources() []string {
+	var sources []string
+	for _, source := range strings.Split(c.DBReplicaSources, ",") {
+		if source = strings.TrimSpace(source); source != "" {
+			sources = append(sources, source)
+		}
+	}
+	return sources
+}
+
+var queryExecModes = map[string]pgx.QueryExecMode{
+	"cache_statement": pgx.QueryExecModeCacheStatement,
+	"cache_describe":  pgx.QueryExecModeCacheDescribe,
+	"describe_exec":   pgx.QueryExecModeDescribeExec,
+	"exec":            pgx.QueryExecModeExec,
+	"simple_protocol": pgx.QueryExecModeSimpleProtocol,
+}
+
+// QueryExecMode parses DB_QUERY_EXEC_MODE, unknown values fall back to
+// statement caching
+func (c Config) QueryExecMode() pgx.QueryExecMode {
+	if mode, ok := queryExecModes[c.DBQueryExecMode]; ok {
+		return mode
+	}
+	return pgx.QueryExecModeCacheStatement
+}
+
+// ListenAddr returns the address the API server binds to
+func (c Config) ListenAddr() string {
+	if c.ServerAddr != "" {
+		return c.ServerAddr
+	}
+	return fmt.Sprintf(":%d", c.ServerPort)
+}
+
+// GinModeOrDefault returns GIN_MODE when set, otherwise release mode in
+// production and debug mode everywhere else
+func (c Config) GinModeOrDefault() string {
+	if c.GinMode != "" {
+		return c.GinMode
+	}
+	if c.Environment == "production" {
+		return gin.ReleaseMode
+	}
+	return gin.DebugMode
+}
+
+// SlogLevel parses LOG_LEVEL, unknown values fall back to info
+func (c Config) SlogLevel() slog.Level {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		return slog.LevelInfo
+	}
+	return level
+}
+
+// InternalRouteGroups names the route groups INTERNAL_ROUTE_GROUPS can
+// serve on the internal listener. Tenant admin, operator and seed routes
+// are not among them, they need a Clerk role or user.
+var InternalRouteGroups = []string{
+	"warehouse", "storageroom", "search", "ledger", "events", "labels", "receiving",
+	"items", "partners", "serials", "picklists", "transfers", "scan", "printing", "edi", "counts", "jobs", "telemetry",
+	"usage", "reports", "v2", "attachments", "inbox",
+}
+
+// SchemaCompatibilityLevels are the values of SCHEMA_REGISTRY_COMPATIBILITY
+var SchemaCompatibilityLevels = []string{
+	"NONE", "BACKWARD", "BACKWARD_TRANSITIVE", "FORWARD", "FORWARD_TRANSITIVE", "FULL", "FULL_TRANSITIVE",
+}
+
+// TLSEnabled reports whether a certificate and key were configured
+func (c Config) TLSEnabled() bool {
+	return c.TLSCertFile != "" && c.TLSKeyFile != ""
 }
 
 func LoadConfig(path string) (config Config, err error) {
@@ -21,10 +403,182 @@ func LoadConfig(path string) (config Config, err error) {
 	viper.SetConfigType("env")
 	viper.AutomaticEnv()
 
-	err = viper.ReadInConfig()
-	if err != nil {
-		return
+	// AutomaticEnv only fills keys viper already knows about
+	viper.SetDefault("SERVICE_NAME", "warehouse-service")
+	viper.SetDefault("JOB_WORKERS", 4)
+	viper.SetDefault("JOB_POLL_INTERVAL", time.Second)
+	viper.SetDefault("SCHEDULE_EXPIRE_PICK_LISTS", "@every 15m")
+	viper.SetDefault("SCHEDULE_REFRESH_GAUGES", "@every 1m")
+	viper.SetDefault("SCHEDULE_REFRESH_DASHBOARD_STATS", "@every 5m")
+	viper.SetDefault("SCHEDULE_REFRESH_SKU_CONSUMPTION", "0 2 * * *")
+	viper.SetDefault("SCHEDULE_PRUNE_AUDIT_LOGS", "@daily")
+	viper.SetDefault("PICK_LIST_ALLOCATION_TTL", 24*time.Hour)
+	viper.SetDefault("AUDIT_RETENTION", 90*24*time.Hour)
+	viper.SetDefault("SCHEDULE_TEMPERATURE_PARTITIONS", "@daily")
+	viper.SetDefault("TEMPERATURE_RETENTION", 365*24*time.Hour)
+	viper.SetDefault("SCHEDULE_PRUNE_CHANGE_EVENTS", "@hourly")
+	viper.SetDefault("CHANGE_EVENT_RETENTION", 24*time.Hour)
+	viper.SetDefault("OUTBOX_BROKER_URL", "")
+	viper.SetDefault("OUTBOX_POLL_INTERVAL", time.Second)
+	viper.SetDefault("OUTBOX_BATCH_SIZE", 100)
+	viper.SetDefault("OUTBOX_MAX_ATTEMPTS", 10)
+	viper.SetDefault("SCHEDULE_PRUNE_OUTBOX", "@hourly")
+	viper.SetDefault("OUTBOX_RETENTION", 72*time.Hour)
+	viper.SetDefault("SCHEMA_REGISTRY_URL", "")
+	viper.SetDefault("SCHEMA_REGISTRY_COMPATIBILITY", "BACKWARD")
+	viper.SetDefault("SCHEDULE_PRUNE_INBOX", "@hourly")
+	viper.SetDefault("INBOX_RETENTION", 7*24*time.Hour)
+	viper.SetDefault("QUOTA_MAX_WAREHOUSES", 0)
+	viper.SetDefault("QUOTA_MAX_STORAGE_ROOMS_PER_WAREHOUSE", 0)
+	viper.SetDefault("QUOTA_MAX_API_CALLS_PER_DAY", 0)
+	viper.SetDefault("QUOTA_MAX_API_CALLS_PER_USER_PER_DAY", 0)
+	viper.SetDefault("SCHEDULE_PRUNE_API_USAGE", "@daily")
+	viper.SetDefault("API_USAGE_RETENTION", 90*24*time.Hour)
+	viper.SetDefault("SCHEDULE_AGGREGATE_METERING", "@hourly")
+	viper.SetDefault("GEOCODER_URL", "")
+	viper.SetDefault("ADDRESS_VALIDATOR_URL", "")
+	viper.SetDefault("ADDRESS_VALIDATOR_TOKEN", "")
+	viper.SetDefault("INVENTORY_SERVICE_ADDR", "")
+	viper.SetDefault("ORDER_SERVICE_ADDR", "")
+	viper.SetDefault("SERVICES_INSECURE", false)
+	viper.SetDefault("SERVICES_TLS_CA_FILE", "")
+	viper.SetDefault("SERVICES_TLS_CERT_FILE", "")
+	viper.SetDefault("SERVICES_TLS_KEY_FILE", "")
+	viper.SetDefault("SERVICES_TIMEOUT", 5*time.Second)
+	viper.SetDefault("SERVICES_MAX_ATTEMPTS", 3)
+	viper.SetDefault("SERVICES_BREAKER_FAILURES", 5)
+	viper.SetDefault("SERVICES_BREAKER_COOLDOWN", 30*time.Second)
+	viper.SetDefault("ATTACHMENT_BUCKET", "")
+	viper.SetDefault("ATTACHMENT_REGION", "us-east-1")
+	viper.SetDefault("ATTACHMENT_ENDPOINT", "")
+	viper.SetDefault("ATTACHMENT_PATH_STYLE", false)
+	viper.SetDefault("ATTACHMENT_MAX_SIZE", 25<<20)
+	viper.SetDefault("ATTACHMENT_CONTENT_TYPES", []string{"application/pdf", "image/png", "image/jpeg", "image/webp"})
+	viper.SetDefault("ATTACHMENT_URL_TTL", 15*time.Minute)
+	viper.SetDefault("ATTACHMENT_SCAN_URL", "")
+	viper.SetDefault("SCHEDULE_EXPORT_INVENTORY_ADVICE", "0 5 * * *")
+	viper.SetDefault("EDI_SFTP_KEY_FILE", "")
+	viper.SetDefault("SCHEDULE_FILE_EXCHANGE", "*/5 * * * *")
+	viper.SetDefault("SCHEDULE_FILE_EXCHANGE_EXTRACT", "0 5 * * *")
+	viper.SetDefault("EXCHANGE_S3_REGION", "us-east-1")
+	viper.SetDefault("EXCHANGE_S3_ENDPOINT", "")
+	viper.SetDefault("EXCHANGE_S3_PATH_STYLE", false)
+	viper.SetDefault("SCHEDULE_SYNC_INTEGRATIONS", "*/5 * * * *")
+	viper.SetDefault("CORS_ALLOW_ORIGINS", []string{"http://localhost:3000"})
+	viper.SetDefault("CORS_ALLOW_ORIGIN_PATTERNS", []string{})
+	viper.SetDefault("CORS_ALLOW_HEADERS", []string{"Origin", "Content-Type", "Authorization", "Bearer", "Prefer", "If-Match", "If-None-Match"})
+	viper.SetDefault("CORS_ALLOW_CREDENTIALS", true)
+	viper.SetDefault("CORS_MAX_AGE", 12*time.Hour)
+	viper.SetDefault("ENVIRONMENT", "development")
+	viper.SetDefault("GIN_MODE", "")
+	viper.SetDefault("SEED_ENDPOINT_ENABLED", false)
+	viper.SetDefault("ADMIN_USER_IDS", []string{})
+	viper.SetDefault("SERVICE_ACCOUNT_USER_IDS", []string{})
+	viper.SetDefault("INTERNAL_ADDR", "")
+	viper.SetDefault("INTERNAL_TLS_CERT_FILE", "")
+	viper.SetDefault("INTERNAL_TLS_KEY_FILE", "")
+	viper.SetDefault("INTERNAL_CLIENT_CA_FILE", "")
+	viper.SetDefault("INTERNAL_ALLOWED_IDENTITIES", []string{})
+	viper.SetDefault("INTERNAL_ROUTE_GROUPS", []string{"warehouse", "storageroom", "v2"})
+	viper.SetDefault("LOG_REDACT_FIELDS", []string{"password", "passwd", "secret", "token", "authorization", "cookie", "api_?key", "credential", "email", "phone", "street_address"})
+	viper.SetDefault("LOG_REDACT_PATTERNS", []string{"dsn", "token", "secret", "email"})
+	viper.SetDefault("LOG_LEVEL", "info")
+	viper.SetDefault("LOG_SINK_FAILURE_THRESHOLD", 3)
+	viper.SetDefault("LOG_FILE_BUFFER_SIZE", 256*1024)
+	viper.SetDefault("LOG_FILE_FLUSH_INTERVAL", "1s")
+	viper.SetDefault("LOG_FILE_FSYNC", "never")
+	viper.SetDefault("SYSLOG_NETWORK", "udp")
+	viper.SetDefault("SYSLOG_TLS_CA_FILE", "")
+	viper.SetDefault("SYSLOG_TLS_CERT_FILE", "")
+	viper.SetDefault("SYSLOG_TLS_KEY_FILE", "")
+	viper.SetDefault("SYSLOG_BUFFER_SIZE", 1000)
+	viper.SetDefault("SYSLOG_ENTERPRISE_ID", "32473")
+	viper.SetDefault("LOG_SINK_PROBE_INTERVAL", "30s")
+	viper.SetDefault("TRACE_SAMPLE_RATIO", 1.0)
+	viper.SetDefault("ACCESS_LOG_SAMPLE_RATIO", 1.0)
+	viper.SetDefault("ACCESS_LOG_SLOW_THRESHOLD", "1s")
+	viper.SetDefault("SLO_AVAILABILITY_TARGET", 0.999)
+	viper.SetDefault("SLO_LATENCY_TARGET", 0.99)
+	viper.SetDefault("SLO_LATENCY_THRESHOLD", "500ms")
+	viper.SetDefault("SLO_EXCLUDED_ROUTES", []string{"/ws", "/v1/events/stream", "/admin/debug/pprof/*profile"})
+	viper.SetDefault("METRICS_HTTP_BUCKETS", []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10})
+	viper.SetDefault("METRICS_DB_BUCKETS", []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5})
+	viper.SetDefault("METRICS_OPERATION_BUCKETS", []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60})
+	viper.SetDefault("METRICS_NATIVE_HISTOGRAMS", false)
+	viper.SetDefault("OTEL_EXPORTER_OTLP_METRICS_DEFAULT_HISTOGRAM_AGGREGATION", "explicit_bucket_histogram")
+	viper.SetDefault("SERVER_ADDR", "")
+	viper.SetDefault("SERVER_PORT", 7450)
+	viper.SetDefault("TLS_CERT_FILE", "")
+	viper.SetDefault("TLS_KEY_FILE", "")
+	viper.SetDefault("SERVER_H2C", false)
+	viper.SetDefault("HTTP_REDIRECT_ADDR", "")
+	viper.SetDefault("SERVER_READ_HEADER_TIMEOUT", 10*time.Second)
+	viper.SetDefault("SERVER_READ_TIMEOUT", 30*time.Second)
+	viper.SetDefault("SERVER_WRITE_TIMEOUT", time.Minute)
+	viper.SetDefault("SERVER_IDLE_TIMEOUT", 2*time.Minute)
+	viper.SetDefault("SERVER_MAX_HEADER_BYTES", 1<<20)
+	viper.SetDefault("SERVER_HTTP2_MAX_CONCURRENT_STREAMS", 250)
+	viper.SetDefault("SERVER_HTTP2_MAX_READ_FRAME_SIZE", 1<<20)
+	viper.SetDefault("SERVER_HTTP2_PING_TIMEOUT", 0)
+	viper.SetDefault("MAX_REQUEST_BODY_SIZE", 1<<20)
+	viper.SetDefault("COMPRESSION_ENABLED", true)
+	viper.SetDefault("COMPRESSION_MIN_SIZE", 1024)
+	viper.SetDefault("COMPRESSION_LEVEL", 6)
+	viper.SetDefault("DB_SOURCE_FILE", "")
+	viper.SetDefault("CLERK_KEY_FILE", "")
+	viper.SetDefault("OTEL_EXPORTER_OTLP_HEADERS_FILE", "")
+	viper.SetDefault("OTEL_RESOURCE_ATTRIBUTES", "")
+	viper.SetDefault("OTEL_EXPORTER_OTLP_PROTOCOL", "http/protobuf")
+	viper.SetDefault("OTEL_EXPORTER_OTLP_INSECURE", true)
+	viper.SetDefault("OTEL_EXPORTER_OTLP_CERTIFICATE", "")
+	viper.SetDefault("OTEL_EXPORTER_OTLP_CLIENT_CERTIFICATE", "")
+	viper.SetDefault("OTEL_EXPORTER_OTLP_CLIENT_KEY", "")
+	viper.SetDefault("OTEL_EXPORTER_OTLP_COMPRESSION", "none")
+	viper.SetDefault("OTEL_EXPORTER_OTLP_URL_PATH", "")
+	viper.SetDefault("VAULT_ADDR", "")
+	viper.SetDefault("VAULT_TOKEN", "")
+	viper.SetDefault("VAULT_TOKEN_FILE", "")
+	viper.SetDefault("SECRET_REFRESH_INTERVAL", 5*time.Minute)
+	viper.SetDefault("CLERK_JWKS_REFRESH_INTERVAL", time.Hour)
+	viper.SetDefault("CLERK_JWKS_FAILURE_THRESHOLD", 3)
+	viper.SetDefault("CLERK_JWKS_COOLDOWN", 30*time.Second)
+	viper.SetDefault("CLERK_CLOCK_SKEW", 5*time.Second)
+	viper.SetDefault("CLERK_JWT_KEY", "")
+	viper.SetDefault("POLICY_ENGINE", "rules")
+	viper.SetDefault("POLICY_FILE", "")
+	viper.SetDefault("OPA_URL", "")
+	viper.SetDefault("OPA_POLICY_PATH", "warehouse/authz")
+	viper.SetDefault("POLICY_DECISION_LOG", "deny")
+	viper.SetDefault("DB_QUERY_EXEC_MODE", "cache_statement")
+	viper.SetDefault("DB_STATEMENT_CACHE_CAPACITY", 512)
+	viper.SetDefault("DB_DESCRIPTION_CACHE_CAPACITY", 512)
+	viper.SetDefault("DB_PREPARE_HOT_QUERIES", true)
+	viper.SetDefault("DB_REPLICA_SOURCES", "")
+	viper.SetDefault("DB_REPLICA_SOURCES_FILE", "")
+	viper.SetDefault("DB_REPLICA_CHECK_INTERVAL", 5*time.Second)
+
+	// app.env is optional, the environment alone is enough to run
+	if err = viper.ReadInConfig(); err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if !errors.As(err, &notFound) {
+			return config, fmt.Errorf("read config: %w", err)
+		}
+	}
+	return decode()
+}
+
+// decode unmarshals the current viper state and validates it
+func decode() (config Config, err error) {
+	if err = viper.Unmarshal(&config); err != nil {
+		return config, fmt.Errorf("decode config: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err = resolveSecrets(ctx, &config); err != nil {
+		return config, fmt.Errorf("resolve secrets: %w", err)
+	}
+	if err = config.Validate(); err != nil {
+		return config, fmt.Errorf("invalid config:\n%w", err)
 	}
-	viper.Unmarshal(&config)
 	return config, nil
 }
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// reloadMu serialises reloads coming from SIGHUP and the file watcher
+var reloadMu sync.Mutex
+
+// Reload re-reads app.env and the environment. Callers decide which of the
+// returned settings are safe to apply to a running process.
+func Reload() (Config, error) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	if err := viper.ReadInConfig(); err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if !errors.As(err, &notFound) {
+			return Config{}, err
+		}
+	}
+	return decode()
+}
+
+// Watch calls apply with the new configuration when app.env changes or the
+// process receives SIGHUP, until ctx is done. An invalid configuration is
+// logged and skipped so the running settings stay in place.
+func Watch(ctx context.Context, apply func(Config)) {
+	reload := func(trigger string) {
+		cfg, err := Reload()
+		if err != nil {
+			slog.Error("Ignoring config reload", slog.String("trigger", trigger), slog.Any("error", err))
+			return
+		}
+		slog.Info("Reloaded config", slog.String("trigger", trigger))
+		apply(cfg)
+	}
+
+	// WatchConfig needs a config file to watch, env only setups rely on SIGHUP
+	if viper.ConfigFileUsed() != "" {
+		viper.OnConfigChange(func(e fsnotify.Event) {
+			reload("file")
+		})
+		viper.WatchConfig()
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(hup)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hup:
+				reload("sighup")
+			}
+		}
+	}()
+}
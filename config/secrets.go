@@ -0,0 +1,262 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/spf13/viper"
+)
+
+// Secret settings can be given directly, through a <KEY>_FILE path (Docker
+// and Kubernetes secret mounts) or as a reference to a secret manager:
+//
+//	vault:secret/data/warehouse#db_source
+//	awssm:prod/warehouse-service#clerk_key
+//
+// The part after # selects a field of a JSON secret, without it the whole
+// secret value is used.
+const (
+	vaultPrefix = "vault:"
+	awsSMPrefix = "awssm:"
+)
+
+// secretKeys lists the settings resolved through files and secret managers
+var secretKeys = []string{"DB_SOURCE", "DB_REPLICA_SOURCES", "CLERK_KEY", "OTEL_EXPORTER_OTLP_HEADERS"}
+
+// SecretFetcher reads a secret from an external secret manager
+type SecretFetcher interface {
+	Fetch(ctx context.Context, ref string) (string, error)
+}
+
+// resolveSecrets replaces the secret settings with their resolved values
+func resolveSecrets(ctx context.Context, config *Config) error {
+	targets := map[string]*string{
+		"DB_SOURCE":                  &config.DBSource,
+		"DB_REPLICA_SOURCES":         &config.DBReplicaSources,
+		"CLERK_KEY":                  &config.ClerKKey,
+		"OTEL_EXPORTER_OTLP_HEADERS": &config.OTELExporterOTLPHeaders,
+	}
+	for _, key := range secretKeys {
+		value, err := ResolveSecret(ctx, key)
+		if err != nil {
+			return err
+		}
+		*targets[key] = value
+	}
+	return nil
+}
+
+// ResolveSecret reads the current value of a secret setting from its
+// source, <KEY>_FILE wins over the plain setting
+func ResolveSecret(ctx context.Context, key string) (string, error) {
+	value := viper.GetString(key)
+	if path := viper.GetString(key + "_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("read %s_FILE: %w", key, err)
+		}
+		value = strings.TrimSpace(string(data))
+	}
+
+	var fetcher SecretFetcher
+	switch {
+	case strings.HasPrefix(value, vaultPrefix):
+		fetcher = vaultFetcher()
+	case strings.HasPrefix(value, awsSMPrefix):
+		fetcher = awsFetcher()
+	default:
+		return value, nil
+	}
+	secret, err := fetcher.Fetch(ctx, value)
+	if err != nil {
+		return "", fmt.Errorf("fetch %s: %w", key, err)
+	}
+	return secret, nil
+}
+
+// WatchSecret re-resolves a secret every interval and calls apply when the
+// value changed, so rotated credentials are picked up without a restart
+func WatchSecret(ctx context.Context, key string, interval time.Duration, apply func(string)) {
+	if interval <= 0 {
+		return
+	}
+	current, _ := ResolveSecret(ctx, key)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				value, err := ResolveSecret(ctx, key)
+				if err != nil {
+					slog.Error("Failed to refresh secret", slog.String("key", key), slog.Any("error", err))
+					continue
+				}
+				if value == "" || value == current {
+					continue
+				}
+				current = value
+				slog.Info("Secret rotated", slog.String("key", key))
+				apply(value)
+			}
+		}
+	}()
+}
+
+// splitRef splits "prefix:path#field" into path and field
+func splitRef(ref, prefix string) (path, field string) {
+	path, field, _ = strings.Cut(strings.TrimPrefix(ref, prefix), "#")
+	return path, field
+}
+
+// jsonField picks a field from a JSON object, an empty field returns raw
+func jsonField(raw []byte, field string) (string, error) {
+	if field == "" {
+		return strings.TrimSpace(string(raw)), nil
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return "", fmt.Errorf("secret is not a JSON object: %w", err)
+	}
+	value, ok := fields[field]
+	if !ok {
+		return "", fmt.Errorf("secret has no field %q", field)
+	}
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("secret field %q is not a string", field)
+	}
+	return s, nil
+}
+
+// VaultFetcher reads KV secrets through the Vault HTTP API. Both KV v1 and
+// v2 mounts work, for v2 the path includes the data/ segment.
+type VaultFetcher struct {
+	Addr   string
+	Token  string
+	Client *http.Client
+}
+
+func (f *VaultFetcher) Fetch(ctx context.Context, ref string) (string, error) {
+	if f.Addr == "" {
+		return "", fmt.Errorf("VAULT_ADDR is not set")
+	}
+	path, field := splitRef(ref, vaultPrefix)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(f.Addr, "/")+"/v1/"+strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", f.Token)
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned %s for %s", resp.Status, path)
+	}
+
+	var body struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("decode vault response: %w", err)
+	}
+	data := body.Data
+	// KV v2 nests the secret under data.data
+	if nested, ok := data["data"]; ok && len(data["metadata"]) > 0 {
+		if err := json.Unmarshal(nested, &data); err != nil {
+			return "", fmt.Errorf("decode vault response: %w", err)
+		}
+	}
+	if field == "" {
+		return "", fmt.Errorf("vault reference %q needs a #field", ref)
+	}
+	raw, ok := data[field]
+	if !ok {
+		return "", fmt.Errorf("secret has no field %q", field)
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", fmt.Errorf("secret field %q is not a string", field)
+	}
+	return value, nil
+}
+
+// AWSSecretsFetcher reads secrets from AWS Secrets Manager using the
+// default credential chain (env, shared config, IRSA, instance role)
+type AWSSecretsFetcher struct {
+	once   sync.Once
+	client *secretsmanager.Client
+	err    error
+}
+
+func (f *AWSSecretsFetcher) Fetch(ctx context.Context, ref string) (string, error) {
+	f.once.Do(func() {
+		cfg, err := awsconfig.LoadDefaultConfig(ctx)
+		if err != nil {
+			f.err = fmt.Errorf("load AWS config: %w", err)
+			return
+		}
+		f.client = secretsmanager.NewFromConfig(cfg)
+	})
+	if f.err != nil {
+		return "", f.err
+	}
+
+	id, field := splitRef(ref, awsSMPrefix)
+	out, err := f.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: &id})
+	if err != nil {
+		return "", err
+	}
+	if out.SecretString != nil {
+		return jsonField([]byte(*out.SecretString), field)
+	}
+	return jsonField(out.SecretBinary, field)
+}
+
+var (
+	fetchersOnce sync.Once
+	vault        *VaultFetcher
+	awsSM        *AWSSecretsFetcher
+)
+
+func initFetchers() {
+	fetchersOnce.Do(func() {
+		token := viper.GetString("VAULT_TOKEN")
+		if path := viper.GetString("VAULT_TOKEN_FILE"); path != "" {
+			if data, err := os.ReadFile(path); err == nil {
+				token = strings.TrimSpace(string(data))
+			} else {
+				slog.Error("Failed to read VAULT_TOKEN_FILE", slog.Any("error", err))
+			}
+		}
+		vault = &VaultFetcher{
+			Addr:   viper.GetString("VAULT_ADDR"),
+			Token:  token,
+			Client: &http.Client{Timeout: 10 * time.Second},
+		}
+		awsSM = &AWSSecretsFetcher{}
+	})
+}
+
+func vaultFetcher() SecretFetcher {
+	initFetchers()
+	return vault
+}
+
+func awsFetcher() SecretFetcher {
+	initFetchers()
+	return awsSM
+}
@@ -0,0 +1,609 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"warehouse-service/observability"
+	"warehouse-service/policy"
+
+	"github.com/clerk/clerk-sdk-go/v2"
+	"github.com/gin-gonic/gin"
+)
+
+const redacted = "[REDACTED]"
+
+// maxClerkClockSkew keeps CLERK_CLOCK_SKEW from accepting long expired
+// session tokens, which Clerk issues for a minute
+const maxClerkClockSkew = time.Minute
+
+// enterpriseIDPattern matches a private enterprise number, optionally
+// followed by dotted sub-identifiers
+var enterpriseIDPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)*$`)
+
+// Validate checks the loaded configuration and reports every problem at
+// once, so a bad deploy fails at startup with a readable message
+func (c Config) Validate() error {
+	var errs []error
+	required := func(name, value string) {
+		if value == "" {
+			errs = append(errs, fmt.Errorf("%s is required", name))
+		}
+	}
+	positive := func(name string, value time.Duration) {
+		if value <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be a positive duration, got %s", name, value))
+		}
+	}
+
+	required("SERVICE_NAME", c.ServiceName)
+	required("DB_SOURCE", c.DBSource)
+	required("CLERK_KEY", c.ClerKKey)
+
+	if c.ServerAddr == "" && (c.ServerPort < 1 || c.ServerPort > 65535) {
+		errs = append(errs, fmt.Errorf("SERVER_PORT must be between 1 and 65535, got %d", c.ServerPort))
+	}
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		errs = append(errs, errors.New("TLS_CERT_FILE and TLS_KEY_FILE must be set together"))
+	}
+	if c.InternalAddr != "" {
+		required("INTERNAL_TLS_CERT_FILE", c.InternalTLSCertFile)
+		required("INTERNAL_TLS_KEY_FILE", c.InternalTLSKeyFile)
+		required("INTERNAL_CLIENT_CA_FILE", c.InternalClientCAFile)
+		if len(c.InternalRouteGroups) == 0 {
+			errs = append(errs, errors.New("INTERNAL_ROUTE_GROUPS must name at least one route group"))
+		}
+		for _, group := range c.InternalRouteGroups {
+			if !slices.Contains(InternalRouteGroups, group) {
+				errs = append(errs, fmt.Errorf("INTERNAL_ROUTE_GROUPS must hold %s, got %q", strings.Join(InternalRouteGroups, ", "), group))
+			}
+		}
+	}
+	if c.HTTPRedirectAddr != "" && !c.TLSEnabled() {
+		errs = append(errs, errors.New("HTTP_REDIRECT_ADDR requires TLS_CERT_FILE and TLS_KEY_FILE"))
+	}
+	positive("SERVER_READ_HEADER_TIMEOUT", c.ServerReadHeaderTimeout)
+	for _, timeout := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"SERVER_READ_TIMEOUT", c.ServerReadTimeout},
+		{"SERVER_WRITE_TIMEOUT", c.ServerWriteTimeout},
+		{"SERVER_IDLE_TIMEOUT", c.ServerIdleTimeout},
+		{"SERVER_HTTP2_PING_TIMEOUT", c.ServerHTTP2PingTimeout},
+	} {
+		if timeout.value < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative, got %s", timeout.name, timeout.value))
+		}
+	}
+	if c.ServerMaxHeaderBytes < 4<<10 {
+		errs = append(errs, fmt.Errorf("SERVER_MAX_HEADER_BYTES must be at least 4096, got %d", c.ServerMaxHeaderBytes))
+	}
+	if c.ServerHTTP2MaxConcurrentStreams < 1 {
+		errs = append(errs, fmt.Errorf("SERVER_HTTP2_MAX_CONCURRENT_STREAMS must be positive, got %d", c.ServerHTTP2MaxConcurrentStreams))
+	}
+	// The frame size limits of RFC 9113
+	if c.ServerHTTP2MaxReadFrameSize < 16<<10 || c.ServerHTTP2MaxReadFrameSize > 1<<24-1 {
+		errs = append(errs, fmt.Errorf("SERVER_HTTP2_MAX_READ_FRAME_SIZE must be between 16384 and 16777215, got %d", c.ServerHTTP2MaxReadFrameSize))
+	}
+	if c.MaxRequestBodySize < 0 {
+		errs = append(errs, fmt.Errorf("MAX_REQUEST_BODY_SIZE must not be negative, got %d", c.MaxRequestBodySize))
+	}
+	if c.CompressionEnabled {
+		if c.CompressionMinSize < 0 {
+			errs = append(errs, fmt.Errorf("COMPRESSION_MIN_SIZE must not be negative, got %d", c.CompressionMinSize))
+		}
+		if c.CompressionLevel < 1 || c.CompressionLevel > 9 {
+			errs = append(errs, fmt.Errorf("COMPRESSION_LEVEL must be between 1 and 9, got %d", c.CompressionLevel))
+		}
+	}
+
+	switch c.Environment {
+	case "development", "staging", "production":
+	default:
+		errs = append(errs, fmt.Errorf("ENVIRONMENT must be development, staging or production, got %q", c.Environment))
+	}
+	if c.SeedEndpointEnabled && c.Environment == "production" {
+		errs = append(errs, errors.New("SEED_ENDPOINT_ENABLED must not be set in production"))
+	}
+	for _, id := range c.AdminUserIDs {
+		if !strings.HasPrefix(id, "user_") {
+			errs = append(errs, fmt.Errorf("ADMIN_USER_IDS must hold Clerk user IDs such as user_2abc, got %q", id))
+		}
+	}
+	for _, id := range c.ServiceAccountUserIDs {
+		if !strings.HasPrefix(id, "user_") {
+			errs = append(errs, fmt.Errorf("SERVICE_ACCOUNT_USER_IDS must hold Clerk user IDs such as user_2abc, got %q", id))
+		}
+	}
+	switch c.GinMode {
+	case "", gin.DebugMode, gin.ReleaseMode, gin.TestMode:
+	default:
+		errs = append(errs, fmt.Errorf("GIN_MODE must be debug, release or test, got %q", c.GinMode))
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		errs = append(errs, fmt.Errorf("LOG_LEVEL must be debug, info, warn or error, got %q", c.LogLevel))
+	}
+	if _, err := observability.NewRedactor(c.LogRedactFields, c.LogRedactPatterns); err != nil {
+		errs = append(errs, fmt.Errorf("LOG_REDACT_FIELDS and LOG_REDACT_PATTERNS: %w", err))
+	}
+	switch c.OTELExporterOTLPProtocol {
+	case observability.OTLPProtocolHTTP, observability.OTLPProtocolGRPC:
+	default:
+		errs = append(errs, fmt.Errorf("OTEL_EXPORTER_OTLP_PROTOCOL must be http/protobuf or grpc, got %q", c.OTELExporterOTLPProtocol))
+	}
+	switch c.OTELExporterOTLPCompression {
+	case "none", "gzip":
+	default:
+		errs = append(errs, fmt.Errorf("OTEL_EXPORTER_OTLP_COMPRESSION must be none or gzip, got %q", c.OTELExporterOTLPCompression))
+	}
+	if (c.OTELExporterOTLPClientCertificate == "") != (c.OTELExporterOTLPClientKey == "") {
+		errs = append(errs, errors.New("OTEL_EXPORTER_OTLP_CLIENT_CERTIFICATE and OTEL_EXPORTER_OTLP_CLIENT_KEY must be set together"))
+	}
+	if c.OTELExporterOTLPInsecure && (c.OTELExporterOTLPCertificate != "" || c.OTELExporterOTLPClientCertificate != "") {
+		errs = append(errs, errors.New("OTEL_EXPORTER_OTLP_CERTIFICATE and OTEL_EXPORTER_OTLP_CLIENT_CERTIFICATE need OTEL_EXPORTER_OTLP_INSECURE=false"))
+	}
+	if _, err := observability.ParseResourceAttributes(c.OTELResourceAttributes); err != nil {
+		errs = append(errs, fmt.Errorf("OTEL_RESOURCE_ATTRIBUTES %w", err))
+	}
+	if c.TraceSampleRatio < 0 || c.TraceSampleRatio > 1 {
+		errs = append(errs, fmt.Errorf("TRACE_SAMPLE_RATIO must be between 0 and 1, got %g", c.TraceSampleRatio))
+	}
+	if c.AccessLogSampleRatio < 0 || c.AccessLogSampleRatio > 1 {
+		errs = append(errs, fmt.Errorf("ACCESS_LOG_SAMPLE_RATIO must be between 0 and 1, got %g", c.AccessLogSampleRatio))
+	}
+	if c.AccessLogSlowThreshold < 0 {
+		errs = append(errs, fmt.Errorf("ACCESS_LOG_SLOW_THRESHOLD must not be negative, got %s", c.AccessLogSlowThreshold))
+	}
+	if c.LogFileBufferSize < 0 {
+		errs = append(errs, fmt.Errorf("LOG_FILE_BUFFER_SIZE must not be negative, got %d", c.LogFileBufferSize))
+	}
+	positive("LOG_FILE_FLUSH_INTERVAL", c.LogFileFlushInterval)
+	switch c.LogFileFsync {
+	case observability.FsyncNever, observability.FsyncInterval, observability.FsyncAlways:
+	default:
+		errs = append(errs, fmt.Errorf("LOG_FILE_FSYNC must be never, interval or always, got %q", c.LogFileFsync))
+	}
+	switch c.SyslogNetwork {
+	case observability.SyslogUDP, observability.SyslogTCP, observability.SyslogTLS:
+	default:
+		errs = append(errs, fmt.Errorf("SYSLOG_NETWORK must be udp, tcp or tls, got %q", c.SyslogNetwork))
+	}
+	if (c.SyslogTLSCertFile == "") != (c.SyslogTLSKeyFile == "") {
+		errs = append(errs, errors.New("SYSLOG_TLS_CERT_FILE and SYSLOG_TLS_KEY_FILE must be set together"))
+	}
+	if c.SyslogNetwork != observability.SyslogTLS && (c.SyslogTLSCAFile != "" || c.SyslogTLSCertFile != "") {
+		errs = append(errs, errors.New("SYSLOG_TLS_CA_FILE and SYSLOG_TLS_CERT_FILE need SYSLOG_NETWORK=tls"))
+	}
+	if c.SyslogBufferSize < 1 {
+		errs = append(errs, fmt.Errorf("SYSLOG_BUFFER_SIZE must be at least 1, got %d", c.SyslogBufferSize))
+	}
+	if !enterpriseIDPattern.MatchString(c.SyslogEnterpriseID) {
+		errs = append(errs, fmt.Errorf("SYSLOG_ENTERPRISE_ID must be a private enterprise number such as 32473, got %q", c.SyslogEnterpriseID))
+	}
+	if c.LogSinkFailureThreshold < 1 {
+		errs = append(errs, fmt.Errorf("LOG_SINK_FAILURE_THRESHOLD must be at least 1, got %d", c.LogSinkFailureThreshold))
+	}
+	if c.LogSinkProbeInterval <= 0 {
+		errs = append(errs, fmt.Errorf("LOG_SINK_PROBE_INTERVAL must be positive, got %s", c.LogSinkProbeInterval))
+	}
+
+	for _, target := range []struct {
+		name  string
+		value float64
+	}{
+		{"SLO_AVAILABILITY_TARGET", c.SLOAvailabilityTarget},
+		{"SLO_LATENCY_TARGET", c.SLOLatencyTarget},
+	} {
+		if target.value <= 0 || target.value >= 1 {
+			errs = append(errs, fmt.Errorf("%s must be between 0 and 1 exclusive, got %g", target.name, target.value))
+		}
+	}
+	positive("SLO_LATENCY_THRESHOLD", c.SLOLatencyThreshold)
+	for _, buckets := range []struct {
+		name   string
+		bounds []float64
+	}{
+		{"METRICS_HTTP_BUCKETS", c.MetricsHTTPBuckets},
+		{"METRICS_DB_BUCKETS", c.MetricsDBBuckets},
+		{"METRICS_OPERATION_BUCKETS", c.MetricsOperationBuckets},
+	} {
+		if err := checkBuckets(buckets.bounds); err != nil {
+			errs = append(errs, fmt.Errorf("%s %w", buckets.name, err))
+		}
+	}
+	switch c.OTELMetricsHistogramAggregation {
+	case observability.HistogramExplicitBuckets, observability.HistogramExponential:
+	default:
+		errs = append(errs, fmt.Errorf("OTEL_EXPORTER_OTLP_METRICS_DEFAULT_HISTOGRAM_AGGREGATION must be %s or %s, got %q",
+			observability.HistogramExplicitBuckets, observability.HistogramExponential, c.OTELMetricsHistogramAggregation))
+	}
+
+	if c.JobWorkers < 1 {
+		errs = append(errs, fmt.Errorf("JOB_WORKERS must be at least 1, got %d", c.JobWorkers))
+	}
+	positive("JOB_POLL_INTERVAL", c.JobPollInterval)
+	positive("CLERK_JWKS_REFRESH_INTERVAL", c.ClerkJWKSRefreshInterval)
+	positive("CLERK_JWKS_COOLDOWN", c.ClerkJWKSCooldown)
+	if c.ClerkJWKSFailureThreshold < 1 {
+		errs = append(errs, fmt.Errorf("CLERK_JWKS_FAILURE_THRESHOLD must be at least 1, got %d", c.ClerkJWKSFailureThreshold))
+	}
+	if c.ClerkClockSkew < 0 || c.ClerkClockSkew > maxClerkClockSkew {
+		errs = append(errs, fmt.Errorf("CLERK_CLOCK_SKEW must be between 0 and %s, got %s", maxClerkClockSkew, c.ClerkClockSkew))
+	}
+	switch c.PolicyEngine {
+	case "rules":
+		if c.PolicyFile != "" {
+			if _, err := policy.LoadRules(c.PolicyFile); err != nil {
+				errs = append(errs, fmt.Errorf("POLICY_FILE is invalid: %w", err))
+			}
+		}
+	case "opa":
+		if u, err := url.Parse(c.OPAURL); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, errors.New("OPA_URL must be an absolute URL when POLICY_ENGINE is opa"))
+		}
+		required("OPA_POLICY_PATH", c.OPAPolicyPath)
+	default:
+		errs = append(errs, fmt.Errorf("POLICY_ENGINE must be rules or opa, got %q", c.PolicyEngine))
+	}
+	switch c.PolicyDecisionLog {
+	case "none", "deny", "all":
+	default:
+		errs = append(errs, fmt.Errorf("POLICY_DECISION_LOG must be none, deny or all, got %q", c.PolicyDecisionLog))
+	}
+	if c.ClerkJWTKey != "" {
+		if _, err := clerk.JSONWebKeyFromPEM(c.ClerkJWTKey); err != nil {
+			errs = append(errs, fmt.Errorf("CLERK_JWT_KEY must be a PEM public key: %w", err))
+		}
+	}
+	positive("PICK_LIST_ALLOCATION_TTL", c.PickListAllocationTTL)
+	positive("AUDIT_RETENTION", c.AuditRetention)
+	positive("TEMPERATURE_RETENTION", c.TemperatureRetention)
+	positive("CHANGE_EVENT_RETENTION", c.ChangeEventRetention)
+	positive("OUTBOX_POLL_INTERVAL", c.OutboxPollInterval)
+	positive("OUTBOX_RETENTION", c.OutboxRetention)
+	positive("INBOX_RETENTION", c.InboxRetention)
+	if c.OutboxBatchSize < 1 {
+		errs = append(errs, fmt.Errorf("OUTBOX_BATCH_SIZE must be at least 1, got %d", c.OutboxBatchSize))
+	}
+	if c.OutboxMaxAttempts < 1 {
+		errs = append(errs, fmt.Errorf("OUTBOX_MAX_ATTEMPTS must be at least 1, got %d", c.OutboxMaxAttempts))
+	}
+	if c.OutboxBrokerURL != "" {
+		if u, err := url.Parse(c.OutboxBrokerURL); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, errors.New("OUTBOX_BROKER_URL must be an absolute URL"))
+		}
+	}
+	if c.SchemaRegistryURL != "" {
+		if u, err := url.Parse(c.SchemaRegistryURL); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, errors.New("SCHEMA_REGISTRY_URL must be an absolute URL"))
+		}
+	}
+	if !slices.Contains(SchemaCompatibilityLevels, c.SchemaRegistryCompatibility) {
+		errs = append(errs, fmt.Errorf("SCHEMA_REGISTRY_COMPATIBILITY must be one of %s, got %q", strings.Join(SchemaCompatibilityLevels, ", "), c.SchemaRegistryCompatibility))
+	}
+
+	quotas := []struct {
+		name  string
+		limit int64
+	}{
+		{"QUOTA_MAX_WAREHOUSES", c.QuotaMaxWarehouses},
+		{"QUOTA_MAX_STORAGE_ROOMS_PER_WAREHOUSE", c.QuotaMaxStorageRoomsPerWarehouse},
+		{"QUOTA_MAX_API_CALLS_PER_DAY", c.QuotaMaxAPICallsPerDay},
+		{"QUOTA_MAX_API_CALLS_PER_USER_PER_DAY", c.QuotaMaxAPICallsPerUserPerDay},
+	}
+	for _, q := range quotas {
+		if q.limit < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative, zero is unlimited, got %d", q.name, q.limit))
+		}
+	}
+	positive("API_USAGE_RETENTION", c.APIUsageRetention)
+
+	if c.GeocoderURL != "" {
+		if u, err := url.Parse(c.GeocoderURL); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("GEOCODER_URL must be an absolute URL, got %q", c.GeocoderURL))
+		}
+	}
+	if c.AddressValidatorURL != "" {
+		if u, err := url.Parse(c.AddressValidatorURL); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("ADDRESS_VALIDATOR_URL must be an absolute URL, got %q", c.AddressValidatorURL))
+		}
+	}
+
+	positive("SERVICES_TIMEOUT", c.ServicesTimeout)
+	positive("SERVICES_BREAKER_COOLDOWN", c.ServicesBreakerCooldown)
+	if c.ServicesMaxAttempts < 1 {
+		errs = append(errs, fmt.Errorf("SERVICES_MAX_ATTEMPTS must be at least 1, got %d", c.ServicesMaxAttempts))
+	}
+	if c.ServicesBreakerFailures < 1 {
+		errs = append(errs, fmt.Errorf("SERVICES_BREAKER_FAILURES must be at least 1, got %d", c.ServicesBreakerFailures))
+	}
+	if (c.ServicesTLSCertFile == "") != (c.ServicesTLSKeyFile == "") {
+		errs = append(errs, errors.New("SERVICES_TLS_CERT_FILE and SERVICES_TLS_KEY_FILE must be set together"))
+	}
+	if c.ExchangeS3Endpoint != "" {
+		if u, err := url.Parse(c.ExchangeS3Endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("EXCHANGE_S3_ENDPOINT must be an absolute URL, got %q", c.ExchangeS3Endpoint))
+		}
+	}
+	if c.AttachmentBucket != "" {
+		if c.AttachmentMaxSize <= 0 {
+			errs = append(errs, fmt.Errorf("ATTACHMENT_MAX_SIZE must be positive, got %d", c.AttachmentMaxSize))
+		}
+		if len(c.AttachmentContentTypes) == 0 {
+			errs = append(errs, errors.New("ATTACHMENT_CONTENT_TYPES must list at least one type"))
+		}
+		// Presigned URLs are valid for at most a week
+		if c.AttachmentURLTTL <= 0 || c.AttachmentURLTTL > 7*24*time.Hour {
+			errs = append(errs, fmt.Errorf("ATTACHMENT_URL_TTL must be between 1s and 168h, got %s", c.AttachmentURLTTL))
+		}
+		urls := []struct {
+			name  string
+			value string
+		}{
+			{"ATTACHMENT_ENDPOINT", c.AttachmentEndpoint},
+			{"ATTACHMENT_SCAN_URL", c.AttachmentScanURL},
+		}
+		for _, v := range urls {
+			if v.value == "" {
+				continue
+			}
+			if u, err := url.Parse(v.value); err != nil || u.Scheme == "" || u.Host == "" {
+				errs = append(errs, fmt.Errorf("%s must be an absolute URL, got %q", v.name, v.value))
+			}
+		}
+	}
+	if len(c.CORSAllowOrigins) == 0 && len(c.CORSAllowOriginPatterns) == 0 {
+		errs = append(errs, errors.New("CORS_ALLOW_ORIGINS or CORS_ALLOW_ORIGIN_PATTERNS must be set"))
+	}
+	for _, pattern := range c.CORSAllowOriginPatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			errs = append(errs, fmt.Errorf("CORS_ALLOW_ORIGIN_PATTERNS has an invalid pattern %q: %w", pattern, err))
+		}
+	}
+
+	if _, ok := queryExecModes[c.DBQueryExecMode]; !ok {
+		errs = append(errs, fmt.Errorf("DB_QUERY_EXEC_MODE must be cache_statement, cache_describe, describe_exec, exec or simple_protocol, got %q", c.DBQueryExecMode))
+	}
+	if c.DBStatementCacheCapacity < 0 || c.DBDescriptionCacheCapacity < 0 {
+		errs = append(errs, errors.New("DB_STATEMENT_CACHE_CAPACITY and DB_DESCRIPTION_CACHE_CAPACITY must not be negative"))
+	}
+	// pgx refuses queries in a caching mode whose cache is disabled
+	if c.DBQueryExecMode == "cache_statement" && c.DBStatementCacheCapacity == 0 {
+		errs = append(errs, errors.New("DB_QUERY_EXEC_MODE cache_statement needs a positive DB_STATEMENT_CACHE_CAPACITY"))
+	}
+	if c.DBQueryExecMode == "cache_describe" && c.DBDescriptionCacheCapacity == 0 {
+		errs = append(errs, errors.New("DB_QUERY_EXEC_MODE cache_describe needs a positive DB_DESCRIPTION_CACHE_CAPACITY"))
+	}
+
+	if len(c.ReplicaSources()) > 0 {
+		positive("DB_REPLICA_CHECK_INTERVAL", c.DBReplicaCheckInterval)
+	}
+
+	return errors.Join(errs...)
+}
+
+// LogValue prints the effective configuration with secrets redacted
+func (c Config) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("service_name", c.ServiceName),
+		slog.String("environment", c.Environment),
+		slog.String("gin_mode", c.GinModeOrDefault()),
+		slog.Bool("seed_endpoint_enabled", c.SeedEndpointEnabled),
+		slog.Any("admin_user_ids", c.AdminUserIDs),
+		slog.Any("service_account_user_ids", c.ServiceAccountUserIDs),
+		slog.String("db_source", c.RedactedDBSource()),
+		slog.String("db_query_exec_mode", c.DBQueryExecMode),
+		slog.Int("db_statement_cache_capacity", c.DBStatementCacheCapacity),
+		slog.Int("db_description_cache_capacity", c.DBDescriptionCacheCapacity),
+		slog.Bool("db_prepare_hot_queries", c.DBPrepareHotQueries),
+		slog.Any("db_replica_sources", c.RedactedReplicaSources()),
+		slog.Duration("db_replica_check_interval", c.DBReplicaCheckInterval),
+		slog.String("clerk_key", redact(c.ClerKKey)),
+		slog.Duration("clerk_jwks_refresh_interval", c.ClerkJWKSRefreshInterval),
+		slog.Int("clerk_jwks_failure_threshold", c.ClerkJWKSFailureThreshold),
+		slog.Duration("clerk_jwks_cooldown", c.ClerkJWKSCooldown),
+		slog.Duration("clerk_clock_skew", c.ClerkClockSkew),
+		slog.Bool("clerk_jwt_key", c.ClerkJWTKey != ""),
+		slog.String("policy_engine", c.PolicyEngine),
+		slog.String("policy_file", c.PolicyFile),
+		slog.String("opa_url", c.OPAURL),
+		slog.String("opa_policy_path", c.OPAPolicyPath),
+		slog.String("policy_decision_log", c.PolicyDecisionLog),
+		slog.String("otel_endpoint", c.OTELExporterOTLPEndpoint),
+		slog.String("otel_headers", redact(c.OTELExporterOTLPHeaders)),
+		slog.String("otel_resource_attributes", c.OTELResourceAttributes),
+		slog.String("otel_protocol", c.OTELExporterOTLPProtocol),
+		slog.Bool("otel_insecure", c.OTELExporterOTLPInsecure),
+		slog.String("otel_certificate", c.OTELExporterOTLPCertificate),
+		slog.String("otel_client_certificate", c.OTELExporterOTLPClientCertificate),
+		slog.String("otel_compression", c.OTELExporterOTLPCompression),
+		slog.String("otel_url_path", c.OTELExporterOTLPURLPath),
+		slog.String("log_level", c.LogLevel),
+		slog.Float64("trace_sample_ratio", c.TraceSampleRatio),
+		slog.Float64("access_log_sample_ratio", c.AccessLogSampleRatio),
+		slog.Duration("access_log_slow_threshold", c.AccessLogSlowThreshold),
+		slog.Float64("slo_availability_target", c.SLOAvailabilityTarget),
+		slog.Float64("slo_latency_target", c.SLOLatencyTarget),
+		slog.Duration("slo_latency_threshold", c.SLOLatencyThreshold),
+		slog.Any("slo_excluded_routes", c.SLOExcludedRoutes),
+		slog.Any("metrics_http_buckets", c.MetricsHTTPBuckets),
+		slog.Any("metrics_db_buckets", c.MetricsDBBuckets),
+		slog.Any("metrics_operation_buckets", c.MetricsOperationBuckets),
+		slog.Bool("metrics_native_histograms", c.MetricsNativeHistograms),
+		slog.String("otel_metrics_histogram_aggregation", c.OTELMetricsHistogramAggregation),
+		slog.String("listen_addr", c.ListenAddr()),
+		slog.Bool("tls", c.TLSEnabled()),
+		slog.Bool("h2c", c.ServerH2C),
+		slog.String("http_redirect_addr", c.HTTPRedirectAddr),
+		slog.Duration("server_read_header_timeout", c.ServerReadHeaderTimeout),
+		slog.Duration("server_read_timeout", c.ServerReadTimeout),
+		slog.Duration("server_write_timeout", c.ServerWriteTimeout),
+		slog.Duration("server_idle_timeout", c.ServerIdleTimeout),
+		slog.Int("server_max_header_bytes", c.ServerMaxHeaderBytes),
+		slog.Int("server_http2_max_concurrent_streams", c.ServerHTTP2MaxConcurrentStreams),
+		slog.Int("server_http2_max_read_frame_size", c.ServerHTTP2MaxReadFrameSize),
+		slog.Duration("server_http2_ping_timeout", c.ServerHTTP2PingTimeout),
+		slog.Int64("max_request_body_size", c.MaxRequestBodySize),
+		slog.Bool("compression_enabled", c.CompressionEnabled),
+		slog.Int("compression_min_size", c.CompressionMinSize),
+		slog.Int("compression_level", c.CompressionLevel),
+		slog.String("internal_addr", c.InternalAddr),
+		slog.String("internal_client_ca_file", c.InternalClientCAFile),
+		slog.Any("internal_allowed_identities", c.InternalAllowedIdentities),
+		slog.Any("internal_route_groups", c.InternalRouteGroups),
+		slog.String("log_file_path", c.LogFilePath),
+		slog.Int("log_file_buffer_size", c.LogFileBufferSize),
+		slog.Duration("log_file_flush_interval", c.LogFileFlushInterval),
+		slog.String("log_file_fsync", c.LogFileFsync),
+		slog.Int("log_sink_failure_threshold", c.LogSinkFailureThreshold),
+		slog.Duration("log_sink_probe_interval", c.LogSinkProbeInterval),
+		slog.Any("log_redact_fields", c.LogRedactFields),
+		slog.Any("log_redact_patterns", c.LogRedactPatterns),
+		slog.String("loki_url", c.LokiURL),
+		slog.String("syslog_address", c.SyslogAddress),
+		slog.String("syslog_network", c.SyslogNetwork),
+		slog.String("syslog_tls_ca_file", c.SyslogTLSCAFile),
+		slog.String("syslog_tls_cert_file", c.SyslogTLSCertFile),
+		slog.Int("syslog_buffer_size", c.SyslogBufferSize),
+		slog.String("syslog_enterprise_id", c.SyslogEnterpriseID),
+		slog.Int("job_workers", c.JobWorkers),
+		slog.Duration("job_poll_interval", c.JobPollInterval),
+		slog.String("schedule_expire_pick_lists", c.ScheduleExpirePickLists),
+		slog.String("schedule_refresh_gauges", c.ScheduleRefreshGauges),
+		slog.String("schedule_refresh_dashboard_stats", c.ScheduleRefreshDashboardStats),
+		slog.String("schedule_refresh_sku_consumption", c.ScheduleRefreshSkuConsumption),
+		slog.String("schedule_prune_audit_logs", c.SchedulePruneAuditLogs),
+		slog.Duration("pick_list_allocation_ttl", c.PickListAllocationTTL),
+		slog.Duration("audit_retention", c.AuditRetention),
+		slog.String("schedule_temperature_partitions", c.ScheduleTemperaturePartitions),
+		slog.Duration("temperature_retention", c.TemperatureRetention),
+		slog.String("schedule_prune_change_events", c.SchedulePruneChangeEvents),
+		slog.Duration("change_event_retention", c.ChangeEventRetention),
+		slog.String("outbox_broker_url", c.RedactedOutboxBrokerURL()),
+		slog.Duration("outbox_poll_interval", c.OutboxPollInterval),
+		slog.Int("outbox_batch_size", c.OutboxBatchSize),
+		slog.Int("outbox_max_attempts", c.OutboxMaxAttempts),
+		slog.String("schedule_prune_outbox", c.SchedulePruneOutbox),
+		slog.Duration("outbox_retention", c.OutboxRetention),
+		slog.String("schema_registry_url", redactURL(c.SchemaRegistryURL)),
+		slog.String("schema_registry_compatibility", c.SchemaRegistryCompatibility),
+		slog.String("schedule_prune_inbox", c.SchedulePruneInbox),
+		slog.Duration("inbox_retention", c.InboxRetention),
+		slog.Int64("quota_max_warehouses", c.QuotaMaxWarehouses),
+		slog.Int64("quota_max_storage_rooms_per_warehouse", c.QuotaMaxStorageRoomsPerWarehouse),
+		slog.Int64("quota_max_api_calls_per_day", c.QuotaMaxAPICallsPerDay),
+		slog.Int64("quota_max_api_calls_per_user_per_day", c.QuotaMaxAPICallsPerUserPerDay),
+		slog.String("schedule_prune_api_usage", c.SchedulePruneAPIUsage),
+		slog.Duration("api_usage_retention", c.APIUsageRetention),
+		slog.String("schedule_aggregate_metering", c.ScheduleAggregateMetering),
+		slog.String("geocoder_url", c.GeocoderURL),
+		slog.String("address_validator_url", redactURL(c.AddressValidatorURL)),
+		slog.String("address_validator_token", redact(c.AddressValidatorToken)),
+		slog.String("inventory_service_addr", c.InventoryServiceAddr),
+		slog.String("order_service_addr", c.OrderServiceAddr),
+		slog.Bool("services_insecure", c.ServicesInsecure),
+		slog.String("services_tls_ca_file", c.ServicesTLSCAFile),
+		slog.Duration("services_timeout", c.ServicesTimeout),
+		slog.Int("services_max_attempts", c.ServicesMaxAttempts),
+		slog.Int("services_breaker_failures", c.ServicesBreakerFailures),
+		slog.Duration("services_breaker_cooldown", c.ServicesBreakerCooldown),
+		slog.String("attachment_bucket", c.AttachmentBucket),
+		slog.String("attachment_region", c.AttachmentRegion),
+		slog.String("attachment_endpoint", c.AttachmentEndpoint),
+		slog.Bool("attachment_path_style", c.AttachmentPathStyle),
+		slog.Int64("attachment_max_size", c.AttachmentMaxSize),
+		slog.Any("attachment_content_types", c.AttachmentContentTypes),
+		slog.Duration("attachment_url_ttl", c.AttachmentURLTTL),
+		slog.String("attachment_scan_url", c.AttachmentScanURL),
+		slog.String("schedule_export_inventory_advice", c.ScheduleExportInventoryAdvice),
+		slog.String("edi_sftp_key_file", c.EDISFTPKeyFile),
+		slog.String("schedule_file_exchange", c.ScheduleFileExchange),
+		slog.String("schedule_file_exchange_extract", c.ScheduleFileExchangeExtract),
+		slog.String("exchange_s3_region", c.ExchangeS3Region),
+		slog.String("exchange_s3_endpoint", c.ExchangeS3Endpoint),
+		slog.Bool("exchange_s3_path_style", c.ExchangeS3PathStyle),
+		slog.String("schedule_sync_integrations", c.ScheduleSyncIntegrations),
+		slog.Any("cors_allow_origins", c.CORSAllowOrigins),
+		slog.Any("cors_allow_origin_patterns", c.CORSAllowOriginPatterns),
+		slog.Bool("cors_allow_credentials", c.CORSAllowCredentials),
+	)
+}
+
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return redacted
+}
+
+var dsnPassword = regexp.MustCompile(`(password\s*=\s*)('[^']*'|\S+)`)
+
+// RedactedDBSource returns DB_SOURCE safe for logging
+func (c Config) RedactedDBSource() string {
+	return RedactDSN(c.DBSource)
+}
+
+// RedactedReplicaSources returns the read replica connection strings safe
+// for logging
+func (c Config) RedactedReplicaSources() []string {
+	sources := c.ReplicaSources()
+	for i, source := range sources {
+		sources[i] = RedactDSN(source)
+	}
+	return sources
+}
+
+// RedactDSN hides the password of a postgres URL or key/value connection
+// string
+func RedactDSN(dsn string) string {
+	if u, err := url.Parse(dsn); err == nil && u.Scheme != "" {
+		return u.Redacted()
+	}
+	return dsnPassword.ReplaceAllString(dsn, "${1}"+redacted)
+}
+
+// RedactedOutboxBrokerURL returns OUTBOX_BROKER_URL safe for logging, its
+// credentials and query are hidden
+func (c Config) RedactedOutboxBrokerURL() string {
+	return redactURL(c.OutboxBrokerURL)
+}
+
+// redactURL hides the credentials and query of a URL
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || raw == "" {
+		return redact(raw)
+	}
+	if u.User != nil {
+		u.User = url.User(redacted)
+	}
+	if u.RawQuery != "" {
+		u.RawQuery = redacted
+	}
+	return u.String()
+}
+
+// checkBuckets reports bucket bounds that are missing, not positive or not
+// increasing
+func checkBuckets(bounds []float64) error {
+	if len(bounds) == 0 {
+		return errors.New("must list at least one bucket")
+	}
+	for i, bound := range bounds {
+		if bound <= 0 {
+			return fmt.Errorf("must be positive, got %g", bound)
+		}
+		if i > 0 && bound <= bounds[i-1] {
+			return fmt.Errorf("must be increasing, got %g after %g", bound, bounds[i-1])
+		}
+	}
+	return nil
+}
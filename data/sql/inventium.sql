@@ -1,9 +1,9 @@
 -- Sample data for local development - Milk, Condensed Milk, and Coffee products
-INSERT INTO warehouse (name, address, ward, district, city, country) VALUES
+INSERT INTO warehouse (name, address, ward, district, city, country, slug, org_id) VALUES
 -- Sample datas
-('Wahouse 1', 'Test Rd', '4', '2', 'Test', 'test' ),
-('Wahouse 2', 'Test Rd', '4', '2', 'Test', 'test' ),
-('Wahouse 3', 'Test Rd', '4', '2', 'Test', 'test' ),
-('Wahouse 4', 'Test Rd', '4', '2', 'Test', 'test' ),
-('Wahouse 5', 'Test Rd', '4', '2', 'Test', 'test' ),
-('Wahouse 6', 'Test Rd', '4', '2', 'Test', 'test' );
+('Wahouse 1', 'Test Rd', '4', '2', 'Test', 'test', 'wahouse-1', ''),
+('Wahouse 2', 'Test Rd', '4', '2', 'Test', 'test', 'wahouse-2', ''),
+('Wahouse 3', 'Test Rd', '4', '2', 'Test', 'test', 'wahouse-3', ''),
+('Wahouse 4', 'Test Rd', '4', '2', 'Test', 'test', 'wahouse-4', ''),
+('Wahouse 5', 'Test Rd', '4', '2', 'Test', 'test', 'wahouse-5', ''),
+('Wahouse 6', 'Test Rd', '4', '2', 'Test', 'test', 'wahouse-6', '');
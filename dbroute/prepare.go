@@ -0,0 +1,28 @@
+package dbroute
+
+import (
+	"context"
+	models "warehouse-service/models/sqlc"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// HotQueries are the statements behind the busiest read endpoints
+var HotQueries = map[string]string{
+	"GetCurrentVersion":    models.GetCurrentVersion,
+	"GetWarehouse":         models.GetWarehouse,
+	"GetWarehouseIDBySlug": models.GetWarehouseIDBySlug,
+	"ListWarehouse":        models.ListWarehouse,
+}
+
+// PrepareHotQueries prepares HotQueries on conn, keyed by their SQL so
+// models.Queries uses the prepared statements whatever the connection's
+// default query exec mode is. It fits pgxpool.Config.AfterConnect.
+func PrepareHotQueries(ctx context.Context, conn *pgx.Conn) error {
+	for _, sql := range HotQueries {
+		if _, err := conn.Prepare(ctx, sql, sql); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+// Package dbroute sends reads that tolerate stale data to read replicas and
+// everything else to the primary. Endpoints opt in with WithStaleness, a
+// replica only serves them while its replication lag is within the
+// tolerance.
+package dbroute
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+	"warehouse-service/observability"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PrimaryTarget is the target label of the primary in metrics, replicas
+// are replica_0, replica_1, ... in configuration order
+const PrimaryTarget = "primary"
+
+// lagQuery reports how far a replica's replay is behind, zero when it has
+// replayed everything it received. NULL means the lag is unknown.
+const lagQuery = `SELECT CASE
+  WHEN NOT pg_is_in_recovery() OR pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+  ELSE GREATEST(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+END::float8`
+
+type stalenessKey struct{}
+
+// WithStaleness marks reads made with ctx as tolerating data up to maxAge
+// old. Without it reads go to the primary.
+func WithStaleness(ctx context.Context, maxAge time.Duration) context.Context {
+	return context.WithValue(ctx, stalenessKey{}, maxAge)
+}
+
+// Staleness returns the tolerance set by WithStaleness, zero when unset
+func Staleness(ctx context.Context) time.Duration {
+	maxAge, _ := ctx.Value(stalenessKey{}).(time.Duration)
+	return maxAge
+}
+
+type replica struct {
+	name string
+	pool *pgxpool.Pool
+	// Replication lag in nanoseconds, negative while unknown or unreachable
+	lag atomic.Int64
+}
+
+// Router picks the pool a query runs on
+type Router struct {
+	primary       *pgxpool.Pool
+	replicas      []*replica
+	next          atomic.Uint64
+	checkInterval time.Duration
+	metrics       *observability.PrometheusMetrics
+	cancel        context.CancelFunc
+}
+
+// New returns a router over primary and replicas. Replicas are not used
+// until Start has measured their lag.
+func New(primary *pgxpool.Pool, replicas []*pgxpool.Pool, checkInterval time.Duration) *Router {
+	r := &Router{primary: primary, checkInterval: checkInterval}
+	for i, pool := range replicas {
+		rep := &replica{name: fmt.Sprintf("replica_%d", i), pool: pool}
+		rep.lag.Store(-1)
+		r.replicas = append(r.replicas, rep)
+	}
+	return r
+}
+
+// Instrument records routing decisions, pool statistics and replica lag in
+// m. Call it before the router serves queries.
+func (r *Router) Instrument(m *observability.PrometheusMetrics) {
+	r.metrics = m
+}
+
+// Primary returns the pool of the primary, for writes and transactions
+func (r *Router) Primary() *pgxpool.Pool {
+	return r.primary
+}
+
+// Read returns the pool for a read-only query made with ctx: the next
+// replica in turn whose lag is within the Staleness of ctx, otherwise the
+// primary
+func (r *Router) Read(ctx context.Context) *pgxpool.Pool {
+	maxAge := Staleness(ctx)
+	if maxAge > 0 && len(r.replicas) > 0 {
+		start := r.next.Add(1)
+		for i := range uint64(len(r.replicas)) {
+			rep := r.replicas[(start+i)%uint64(len(r.replicas))]
+			if lag := rep.lag.Load(); lag >= 0 && time.Duration(lag) <= maxAge {
+				r.recordRoute(rep.name)
+				return rep.pool
+			}
+		}
+	}
+	r.recordRoute(PrimaryTarget)
+	return r.primary
+}
+
+func (r *Router) recordRoute(target string) {
+	if r.metrics != nil {
+		r.metrics.RecordDBRoute(target)
+	}
+}
+
+// Start measures replica lag and pool statistics every check interval
+// until Close
+func (r *Router) Start(ctx context.Context) {
+	ctx, r.cancel = context.WithCancel(ctx)
+	r.check(ctx)
+	go func() {
+		ticker := time.NewTicker(r.checkInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.check(ctx)
+			}
+		}
+	}()
+}
+
+func (r *Router) check(ctx context.Context) {
+	for _, rep := range r.replicas {
+		checkCtx, cancel := context.WithTimeout(ctx, r.checkInterval)
+		var seconds *float64
+		err := rep.pool.QueryRow(checkCtx, lagQuery).Scan(&seconds)
+		cancel()
+		switch {
+		case err != nil:
+			if rep.lag.Swap(-1) >= 0 {
+				slog.Warn("Read replica unavailable, reading from the primary",
+					slog.String("target", rep.name), slog.Any("error", err))
+			}
+		case seconds == nil:
+			rep.lag.Store(-1)
+		default:
+			if rep.lag.Swap(int64(*seconds*float64(time.Second))) < 0 {
+				slog.Info("Read replica available", slog.String("target", rep.name))
+			}
+		}
+		if r.metrics != nil {
+			seconds := -1.0
+			if lag := rep.lag.Load(); lag >= 0 {
+				seconds = time.Duration(lag).Seconds()
+			}
+			r.metrics.UpdateReplicaLag(rep.name, seconds)
+		}
+	}
+	if r.metrics != nil {
+		r.recordPoolStats(PrimaryTarget, r.primary)
+		for _, rep := range r.replicas {
+			r.recordPoolStats(rep.name, rep.pool)
+		}
+	}
+}
+
+func (r *Router) recordPoolStats(target string, pool *pgxpool.Pool) {
+	stat := pool.Stat()
+	r.metrics.UpdateDBPoolStats(target, stat.AcquiredConns(), stat.IdleConns(), stat.TotalConns(), stat.MaxConns())
+}
+
+// Reset closes every idle connection of every pool and the others once
+// they are released, dropping their prepared statement and description
+// caches. New connections are opened on demand.
+func (r *Router) Reset() {
+	r.primary.Reset()
+	for _, rep := range r.replicas {
+		rep.pool.Reset()
+	}
+}
+
+// Close stops the lag checks and closes every pool
+func (r *Router) Close() {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.primary.Close()
+	for _, rep := range r.replicas {
+		rep.pool.Close()
+	}
+}
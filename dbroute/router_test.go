@@ -0,0 +1,47 @@
+package dbroute
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestRead(t *testing.T) {
+	// Pools connect lazily, none of these is ever dialled
+	newPool := func() *pgxpool.Pool {
+		pool, err := pgxpool.New(context.Background(), "postgres://localhost:1/test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(pool.Close)
+		return pool
+	}
+	primary, fresh, behind := newPool(), newPool(), newPool()
+	r := New(primary, []*pgxpool.Pool{fresh, behind}, time.Second)
+	r.replicas[0].lag.Store(int64(time.Second))
+	r.replicas[1].lag.Store(int64(time.Minute))
+
+	ctx := context.Background()
+	if got := r.Read(ctx); got != primary {
+		t.Error("reads without a staleness tolerance must use the primary")
+	}
+	for range 4 {
+		if got := r.Read(WithStaleness(ctx, 5*time.Second)); got != fresh {
+			t.Error("a 5s tolerance must only use the replica 1s behind")
+		}
+	}
+	seen := map[*pgxpool.Pool]bool{}
+	for range 4 {
+		seen[r.Read(WithStaleness(ctx, time.Hour))] = true
+	}
+	if !seen[fresh] || !seen[behind] {
+		t.Error("an hour of tolerance must spread reads over both replicas")
+	}
+
+	r.replicas[0].lag.Store(-1)
+	if got := r.Read(WithStaleness(ctx, 5*time.Second)); got != primary {
+		t.Error("with no replica within the tolerance reads must fall back to the primary")
+	}
+}
@@ -0,0 +1,30 @@
+package edi
+
+import (
+	"strconv"
+	"time"
+)
+
+// AdviceLine is the stock of an item reported by an 846
+type AdviceLine struct {
+	Sku       string
+	OnHand    int64
+	Available int64
+}
+
+// InventoryAdvice builds the segments of an 846 inventory inquiry/advice
+// reporting lines as of date, with reference as its BIA03
+func InventoryAdvice(reference string, date time.Time, lines []AdviceLine) []Segment {
+	segments := []Segment{
+		// Original report of quantities on hand, in eaches
+		{"BIA", "00", "DD", reference, date.Format("20060102"), date.Format("1504")},
+	}
+	for i, l := range lines {
+		segments = append(segments,
+			Segment{"LIN", strconv.Itoa(i + 1), "SK", l.Sku},
+			Segment{"QTY", "33", strconv.FormatInt(l.Available, 10), "EA"},
+			Segment{"QTY", "17", strconv.FormatInt(l.OnHand, 10), "EA"},
+		)
+	}
+	return append(segments, Segment{"CTT", strconv.Itoa(len(lines))})
+}
@@ -0,0 +1,85 @@
+package edi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ASN is an 856 advanced ship notice
+type ASN struct {
+	// Shipment identification of BSN02
+	ShipmentID string
+	Lines      []ASNLine
+}
+
+// ASNLine is an item shipped: a LIN segment and the SN1 that follows it
+type ASNLine struct {
+	Sku string
+	// Shipped quantity of SN102, in Unit
+	Quantity int32
+	// Unit of measure code of SN103, e.g. "EA" or "CA"
+	Unit string
+}
+
+// Product ID qualifiers of LIN segments, in the order SKUs are taken from
+var skuQualifiers = []string{"SK", "BP", "VP", "UP"}
+
+// ParseASN reads an 856 transaction set. Hierarchical levels are flattened:
+// every item level's LIN is paired with the next SN1.
+func ParseASN(t Transaction) (ASN, error) {
+	if t.Code != "856" {
+		return ASN{}, fmt.Errorf("transaction %s is a %s, not an 856", t.ControlNumber, t.Code)
+	}
+	var asn ASN
+	sku := ""
+	for _, s := range t.Segments {
+		switch s.ID() {
+		case "BSN":
+			asn.ShipmentID = strings.TrimSpace(s.Element(2))
+		case "LIN":
+			sku = linSku(s)
+			if sku == "" {
+				return ASN{}, fmt.Errorf("transaction %s: LIN without a product ID", t.ControlNumber)
+			}
+		case "SN1":
+			if sku == "" {
+				return ASN{}, fmt.Errorf("transaction %s: SN1 without a LIN", t.ControlNumber)
+			}
+			qty, err := strconv.ParseFloat(s.Element(2), 64)
+			if err != nil || qty <= 0 || qty != float64(int32(qty)) {
+				return ASN{}, fmt.Errorf("transaction %s: quantity %q of %s is not a positive whole number", t.ControlNumber, s.Element(2), sku)
+			}
+			asn.Lines = append(asn.Lines, ASNLine{
+				Sku:      sku,
+				Quantity: int32(qty),
+				Unit:     strings.ToUpper(strings.TrimSpace(s.Element(3))),
+			})
+			sku = ""
+		}
+	}
+	if asn.ShipmentID == "" {
+		return ASN{}, fmt.Errorf("transaction %s has no BSN shipment identification", t.ControlNumber)
+	}
+	if len(asn.Lines) == 0 {
+		return ASN{}, fmt.Errorf("transaction %s ships no items", t.ControlNumber)
+	}
+	return asn, nil
+}
+
+// linSku picks the product ID of a LIN segment, whose elements after the
+// assigned identification are qualifier and ID pairs
+func linSku(s Segment) string {
+	ids := map[string]string{}
+	for i := 2; i+1 < len(s); i += 2 {
+		if _, ok := ids[s[i]]; !ok {
+			ids[s[i]] = strings.TrimSpace(s[i+1])
+		}
+	}
+	for _, q := range skuQualifiers {
+		if id := ids[q]; id != "" {
+			return id
+		}
+	}
+	return ""
+}
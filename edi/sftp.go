@@ -0,0 +1,35 @@
+package edi
+
+import (
+	"context"
+	"fmt"
+	"warehouse-service/sftp"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Drop uploads documents to partners' SFTP servers
+type Drop struct {
+	signer ssh.Signer
+}
+
+// NewDrop authenticates uploads with signer, see sftp.LoadKey
+func NewDrop(signer ssh.Signer) *Drop {
+	return &Drop{signer: signer}
+}
+
+// Upload writes data as name into the directory of the drop URL, an
+// sftp://user@host/directory URL. The server must present hostKey.
+func (d *Drop) Upload(ctx context.Context, dropURL, hostKey, name string, data []byte) error {
+	client, err := sftp.Dial(ctx, dropURL, hostKey, d.signer)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	return client.WriteFile(name, data)
+}
+
+// FileName names an uploaded document, e.g. "846_000000042.edi"
+func FileName(code string, controlNumber int64) string {
+	return fmt.Sprintf("%s_%09d.edi", code, controlNumber%1_000_000_000)
+}
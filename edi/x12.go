@@ -0,0 +1,185 @@
+package edi
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Segment is an X12 segment: its ID followed by its elements
+type Segment []string
+
+// ID is the segment identifier, e.g. "LIN"
+func (s Segment) ID() string {
+	if len(s) == 0 {
+		return ""
+	}
+	return s[0]
+}
+
+// Element returns element i, counted from 1 like BSN02, or "" when the
+// segment has fewer elements
+func (s Segment) Element(i int) string {
+	if i <= 0 || i >= len(s) {
+		return ""
+	}
+	return s[i]
+}
+
+// Transaction is one transaction set of an interchange, the segments
+// between its ST and SE
+type Transaction struct {
+	// Transaction set identifier, e.g. "856"
+	Code          string
+	ControlNumber string
+	Segments      []Segment
+}
+
+// Interchange is a parsed X12 interchange with the transaction sets of all
+// its functional groups
+type Interchange struct {
+	SenderQualifier   string
+	SenderID          string
+	ReceiverQualifier string
+	ReceiverID        string
+	ControlNumber     string
+	Transactions      []Transaction
+}
+
+// The ISA segment has a fixed width, which is how its delimiters are found
+const isaLength = 106
+
+// Parse reads an X12 interchange. The delimiters are taken from the ISA
+// segment; line breaks after segment terminators are ignored.
+func Parse(data []byte) (Interchange, error) {
+	data = bytes.TrimLeft(bytes.TrimPrefix(data, []byte("\ufeff")), " \t\r\n")
+	if len(data) < isaLength || string(data[:3]) != "ISA" {
+		return Interchange{}, fmt.Errorf("not an X12 interchange: it must start with an ISA segment")
+	}
+	elementSep := string(data[3])
+	terminator := string(data[isaLength-1])
+
+	var segments []Segment
+	for _, raw := range strings.Split(string(data), terminator) {
+		raw = strings.Trim(raw, "\r\n")
+		if raw == "" {
+			continue
+		}
+		segments = append(segments, Segment(strings.Split(raw, elementSep)))
+	}
+
+	isa := segments[0]
+	if len(isa) != 17 {
+		return Interchange{}, fmt.Errorf("ISA segment has %d elements, want 16", len(isa)-1)
+	}
+	ic := Interchange{
+		SenderQualifier:   strings.TrimSpace(isa.Element(5)),
+		SenderID:          strings.TrimSpace(isa.Element(6)),
+		ReceiverQualifier: strings.TrimSpace(isa.Element(7)),
+		ReceiverID:        strings.TrimSpace(isa.Element(8)),
+		ControlNumber:     strings.TrimSpace(isa.Element(13)),
+	}
+
+	var current *Transaction
+	closed := false
+	for i, s := range segments[1:] {
+		n := i + 2
+		switch s.ID() {
+		case "GS", "GE":
+		case "ST":
+			if current != nil {
+				return Interchange{}, fmt.Errorf("segment %d: ST before the SE of transaction %s", n, current.ControlNumber)
+			}
+			current = &Transaction{Code: s.Element(1), ControlNumber: s.Element(2)}
+		case "SE":
+			if current == nil {
+				return Interchange{}, fmt.Errorf("segment %d: SE without ST", n)
+			}
+			// The count includes the ST and SE segments
+			if count, err := strconv.Atoi(s.Element(1)); err != nil || count != len(current.Segments)+2 {
+				return Interchange{}, fmt.Errorf("segment %d: SE counts %s segments, transaction %s has %d", n, s.Element(1), current.ControlNumber, len(current.Segments)+2)
+			}
+			ic.Transactions = append(ic.Transactions, *current)
+			current = nil
+		case "IEA":
+			closed = true
+		default:
+			if current == nil {
+				return Interchange{}, fmt.Errorf("segment %d: %s outside a transaction set", n, s.ID())
+			}
+			current.Segments = append(current.Segments, s)
+		}
+	}
+	if current != nil || !closed {
+		return Interchange{}, fmt.Errorf("interchange %s is incomplete", ic.ControlNumber)
+	}
+	return ic, nil
+}
+
+// Envelope addresses an interchange written by Encode
+type Envelope struct {
+	SenderQualifier   string
+	SenderID          string
+	ReceiverQualifier string
+	ReceiverID        string
+	// Interchange and group control number, at most 9 digits
+	ControlNumber int64
+	// Functional identifier of the group, e.g. "IB" for 846
+	FunctionalID string
+	Date         time.Time
+}
+
+// Delimiters of written interchanges
+const (
+	elementSeparator   = "*"
+	componentSeparator = ">"
+	segmentTerminator  = "~"
+)
+
+// Encode writes a version 004010 interchange with one functional group
+// holding one transaction set of code with segments between ST and SE.
+// Separator characters in element values are replaced with spaces.
+func Encode(env Envelope, code string, segments []Segment) []byte {
+	var buf bytes.Buffer
+	write := func(s Segment) {
+		clean := make([]string, len(s))
+		for i, e := range s {
+			clean[i] = strings.NewReplacer(elementSeparator, " ", componentSeparator, " ", segmentTerminator, " ", "\n", " ", "\r", " ").Replace(e)
+		}
+		buf.WriteString(strings.Join(clean, elementSeparator))
+		buf.WriteString(segmentTerminator + "\n")
+	}
+
+	control := fmt.Sprintf("%09d", env.ControlNumber%1_000_000_000)
+	// ISA elements are fixed width, the component separator is its last
+	buf.WriteString(strings.Join([]string{
+		"ISA", "00", pad("", 10), "00", pad("", 10),
+		pad(env.SenderQualifier, 2), pad(env.SenderID, 15),
+		pad(env.ReceiverQualifier, 2), pad(env.ReceiverID, 15),
+		env.Date.Format("060102"), env.Date.Format("1504"),
+		"U", "00401", control, "0", "P", componentSeparator,
+	}, elementSeparator))
+	buf.WriteString(segmentTerminator + "\n")
+
+	groupControl := strconv.FormatInt(env.ControlNumber%1_000_000_000, 10)
+	write(Segment{"GS", env.FunctionalID, env.SenderID, env.ReceiverID,
+		env.Date.Format("20060102"), env.Date.Format("1504"), groupControl, "X", "004010"})
+	write(Segment{"ST", code, "0001"})
+	for _, s := range segments {
+		write(s)
+	}
+	write(Segment{"SE", strconv.Itoa(len(segments) + 2), "0001"})
+	write(Segment{"GE", "1", groupControl})
+	write(Segment{"IEA", "1", control})
+	return buf.Bytes()
+}
+
+// pad fits s into an ISA element of width n
+func pad(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s + strings.Repeat(" ", n-len(s))
+}
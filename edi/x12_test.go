@@ -0,0 +1,126 @@
+package edi
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const asn856 = `ISA*00*          *00*          *ZZ*ACMESUPPLY     *ZZ*WAREHOUSE      *261015*0930*U*00401*000000017*0*P*>~
+GS*SH*ACMESUPPLY*WAREHOUSE*20261015*0930*17*X*004010~
+ST*856*0001~
+BSN*00*SHIP-9001*20261015*0930~
+HL*1**S~
+HL*2*1*O~
+HL*3*2*I~
+LIN**UP*012345678905*VP*ACME-1~
+SN1**12*EA~
+HL*4*2*I~
+LIN**SK*SKU-2~
+SN1**3*CA~
+SE*11*0001~
+GE*1*17~
+IEA*1*000000017~
+`
+
+func TestParseASN(t *testing.T) {
+	ic, err := Parse([]byte(asn856))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ic.SenderQualifier != "ZZ" || ic.SenderID != "ACMESUPPLY" || ic.ControlNumber != "000000017" {
+		t.Fatalf("interchange %+v", ic)
+	}
+	if len(ic.Transactions) != 1 || ic.Transactions[0].Code != "856" {
+		t.Fatalf("transactions %+v", ic.Transactions)
+	}
+	asn, err := ParseASN(ic.Transactions[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ASNLine{{Sku: "ACME-1", Quantity: 12, Unit: "EA"}, {Sku: "SKU-2", Quantity: 3, Unit: "CA"}}
+	if asn.ShipmentID != "SHIP-9001" || len(asn.Lines) != len(want) {
+		t.Fatalf("asn %+v", asn)
+	}
+	for i, l := range want {
+		if asn.Lines[i] != l {
+			t.Errorf("line %d is %+v, want %+v", i, asn.Lines[i], l)
+		}
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	cases := map[string]string{
+		"not X12":       "BSN*00*1~",
+		"segment count": strings.Replace(asn856, "SE*11*", "SE*10*", 1),
+		"no IEA":        strings.Replace(asn856, "IEA*1*000000017~\n", "", 1),
+		"outside ST":    strings.Replace(asn856, "ST*856*0001~\n", "", 1),
+		"short ISA":     strings.Replace(asn856, "*0*P*>", "*P*>", 1),
+		"nested ST":     strings.Replace(asn856, "BSN*", "ST*856*0002~\nBSN*", 1),
+	}
+	for name, data := range cases {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("%s: parsed", name)
+		}
+	}
+}
+
+func TestParseASNRejects(t *testing.T) {
+	cases := map[string][]Segment{
+		"no BSN":          {{"LIN", "", "SK", "A"}, {"SN1", "", "1", "EA"}},
+		"no lines":        {{"BSN", "00", "S1"}},
+		"no product ID":   {{"BSN", "00", "S1"}, {"LIN", "", "XX", "A"}},
+		"SN1 without LIN": {{"BSN", "00", "S1"}, {"SN1", "", "1", "EA"}},
+		"fraction":        {{"BSN", "00", "S1"}, {"LIN", "", "SK", "A"}, {"SN1", "", "1.5", "EA"}},
+		"zero":            {{"BSN", "00", "S1"}, {"LIN", "", "SK", "A"}, {"SN1", "", "0", "EA"}},
+	}
+	for name, segments := range cases {
+		if _, err := ParseASN(Transaction{Code: "856", ControlNumber: "1", Segments: segments}); err == nil {
+			t.Errorf("%s: parsed", name)
+		}
+	}
+	if _, err := ParseASN(Transaction{Code: "846"}); err == nil {
+		t.Error("parsed an 846 as an ASN")
+	}
+}
+
+func TestEncodeInventoryAdvice(t *testing.T) {
+	date := time.Date(2026, 10, 15, 5, 0, 0, 0, time.UTC)
+	data := Encode(Envelope{
+		SenderQualifier:   "ZZ",
+		SenderID:          "WAREHOUSE",
+		ReceiverQualifier: "01",
+		ReceiverID:        "123456789",
+		ControlNumber:     42,
+		FunctionalID:      "IB",
+		Date:              date,
+	}, "846", InventoryAdvice("42", date, []AdviceLine{
+		{Sku: "A*1", OnHand: 10, Available: 7},
+		{Sku: "B", OnHand: 0, Available: 0},
+	}))
+
+	if isa := strings.SplitN(string(data), "\n", 2)[0]; len(isa) != isaLength {
+		t.Fatalf("ISA of %d characters: %q", len(isa), isa)
+	}
+	ic, err := Parse(data)
+	if err != nil {
+		t.Fatalf("%v\n%s", err, data)
+	}
+	if ic.SenderID != "WAREHOUSE" || ic.ReceiverQualifier != "01" || ic.ReceiverID != "123456789" || ic.ControlNumber != "000000042" {
+		t.Fatalf("interchange %+v", ic)
+	}
+	segments := ic.Transactions[0].Segments
+	if len(segments) != 8 || segments[0].ID() != "BIA" || segments[7].ID() != "CTT" || segments[7].Element(1) != "2" {
+		t.Fatalf("segments %v", segments)
+	}
+	// Separators in values must not split elements
+	if lin := segments[1]; lin.Element(3) != "A 1" {
+		t.Errorf("LIN %v", lin)
+	}
+	if qty := segments[2]; qty.Element(1) != "33" || qty.Element(2) != "7" {
+		t.Errorf("available QTY %v", qty)
+	}
+	if qty := segments[3]; qty.Element(1) != "17" || qty.Element(2) != "10" {
+		t.Errorf("on hand QTY %v", qty)
+	}
+}
@@ -0,0 +1,148 @@
+// Package exchange opens the folders files are exchanged with partners
+// through: a directory of an SFTP server or a prefix of an S3 bucket.
+package exchange
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"net/url"
+	"path"
+	"strings"
+	"warehouse-service/objectstore"
+	"warehouse-service/sftp"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// MaxFileSize is the largest file read from a folder
+const MaxFileSize = sftp.MaxFileSize
+
+// Folder is a flat folder of files. Its operations are bounded by the
+// context it was opened with.
+type Folder interface {
+	// List returns the names of the files in the folder
+	List() ([]string, error)
+	ReadFile(name string) ([]byte, error)
+	// WriteFile creates or replaces a file, the other side never sees it
+	// half written
+	WriteFile(name string, data []byte) error
+	Remove(name string) error
+	Close() error
+}
+
+// ParseURL validates the URL of a folder, sftp://user@host/directory or
+// s3://bucket/prefix
+func ParseURL(raw string) (*url.URL, error) {
+	if strings.HasPrefix(raw, "sftp://") {
+		return sftp.ParseURL(raw)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "s3" || u.Host == "" || u.User != nil || u.RawQuery != "" {
+		return nil, errors.New("folder URL must look like sftp://user@host/directory or s3://bucket/prefix")
+	}
+	return u, nil
+}
+
+// S3Config locates the S3 compatible store of s3:// folders, credentials
+// come from the AWS default chain
+type S3Config struct {
+	Region string
+	// Endpoint is the store's base URL, empty for AWS S3 in Region
+	Endpoint  string
+	PathStyle bool
+}
+
+// Opener opens folders with the service's credentials
+type Opener struct {
+	// Signer authenticates with SFTP servers, SFTP folders can't be opened
+	// without it
+	Signer ssh.Signer
+	S3     S3Config
+}
+
+// Open opens the folder of rawURL. An SFTP server must present hostKey,
+// S3 folders ignore it.
+func (o Opener) Open(ctx context.Context, rawURL, hostKey string) (Folder, error) {
+	u, err := ParseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "sftp" {
+		if o.Signer == nil {
+			return nil, errors.New("no SFTP key is configured")
+		}
+		return sftp.Dial(ctx, rawURL, hostKey, o.Signer)
+	}
+
+	store, err := objectstore.NewS3(ctx, objectstore.S3Config{
+		Bucket:    u.Host,
+		Region:    o.S3.Region,
+		Endpoint:  o.S3.Endpoint,
+		PathStyle: o.S3.PathStyle,
+	})
+	if err != nil {
+		return nil, err
+	}
+	prefix := strings.Trim(u.Path, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+	return &s3Folder{ctx: ctx, store: store, prefix: prefix}, nil
+}
+
+// s3Folder is the objects directly under a prefix of a bucket
+type s3Folder struct {
+	ctx    context.Context
+	store  *objectstore.S3
+	prefix string
+}
+
+func (f *s3Folder) List() ([]string, error) {
+	keys, err := f.store.List(f.ctx, f.prefix)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(keys))
+	for _, key := range keys {
+		name := strings.TrimPrefix(key, f.prefix)
+		// Objects of "subfolders" are not files of the folder
+		if name != "" && !strings.Contains(name, "/") {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+func (f *s3Folder) ReadFile(name string) ([]byte, error) {
+	return f.store.Get(f.ctx, f.prefix+name, MaxFileSize)
+}
+
+// WriteFile puts the object in one request, which S3 applies atomically
+func (f *s3Folder) WriteFile(name string, data []byte) error {
+	sum := sha256.Sum256(data)
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	err := f.store.Put(f.ctx, f.prefix+name, bytes.NewReader(data), int64(len(data)), contentType, hex.EncodeToString(sum[:]))
+	if err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	return nil
+}
+
+func (f *s3Folder) Remove(name string) error {
+	return f.store.Delete(f.ctx, f.prefix+name)
+}
+
+func (f *s3Folder) Close() error {
+	return nil
+}
@@ -0,0 +1,26 @@
+package exchange
+
+import "testing"
+
+func TestParseURL(t *testing.T) {
+	for _, raw := range []string{
+		"sftp://edi@partner.example.com/outbound",
+		"s3://exchange-bucket",
+		"s3://exchange-bucket/acme/inbound",
+	} {
+		if _, err := ParseURL(raw); err != nil {
+			t.Errorf("%s: %v", raw, err)
+		}
+	}
+	for _, raw := range []string{
+		"",
+		"https://exchange-bucket.s3.amazonaws.com/inbound",
+		"s3:///inbound",
+		"s3://key:secret@exchange-bucket/inbound",
+		"sftp://partner.example.com/outbound",
+	} {
+		if _, err := ParseURL(raw); err == nil {
+			t.Errorf("accepted %q", raw)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+// Package fixtures loads sample warehouses, storage rooms and stock into an
+// organization for development, demos and integration tests. Fixture sets
+// are JSON files embedded in the binary and every row has a fixed ID, so
+// loading a set twice leaves the same data behind.
+package fixtures
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"time"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/slug"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:embed data/*.json
+var files embed.FS
+
+// ErrUnknownSet is returned when no embedded fixture set has the given name
+var ErrUnknownSet = errors.New("fixtures: unknown fixture set")
+
+// ErrIDTaken is returned when a fixture ID already belongs to another
+// organization. Fixture IDs are global, so a set can only be loaded into
+// one organization per database.
+var ErrIDTaken = errors.New("fixtures: fixture ID belongs to another organization")
+
+// Set is one fixture file
+type Set struct {
+	Description string      `json:"description"`
+	Warehouses  []Warehouse `json:"warehouses"`
+}
+
+type Warehouse struct {
+	ID           int64         `json:"id"`
+	Name         string        `json:"name"`
+	Address      string        `json:"address"`
+	Ward         string        `json:"ward"`
+	District     string        `json:"district"`
+	City         string        `json:"city"`
+	Country      string        `json:"country"`
+	Latitude     *float64      `json:"latitude"`
+	Longitude    *float64      `json:"longitude"`
+	TimeZone     string        `json:"time_zone"`
+	Tags         []string      `json:"tags"`
+	StorageRooms []StorageRoom `json:"storage_rooms"`
+}
+
+type StorageRoom struct {
+	ID       int32   `json:"id"`
+	Name     string  `json:"name"`
+	Number   string  `json:"number"`
+	ZoneType string  `json:"zone_type"`
+	Stock    []Stock `json:"stock"`
+}
+
+type Stock struct {
+	Sku       string     `json:"sku"`
+	Quantity  int32      `json:"quantity"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// Result counts the rows a load wrote
+type Result struct {
+	Set          string `json:"set"`
+	Warehouses   int    `json:"warehouses"`
+	StorageRooms int    `json:"storage_rooms"`
+	StockLevels  int    `json:"stock_levels"`
+}
+
+// Names lists the embedded fixture sets
+func Names() []string {
+	entries, _ := fs.ReadDir(files, "data")
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Get parses the embedded fixture set called name
+func Get(name string) (Set, error) {
+	var set Set
+	raw, err := files.ReadFile(path.Join("data", name+".json"))
+	if err != nil {
+		return set, fmt.Errorf("%w %q, available: %s", ErrUnknownSet, name, strings.Join(Names(), ", "))
+	}
+	if err := json.Unmarshal(raw, &set); err != nil {
+		return set, fmt.Errorf("fixtures: parse %s: %w", name, err)
+	}
+	return set, nil
+}
+
+// Load writes the fixture set called name into orgID in one transaction.
+// Rows that exist are updated to match the fixture, stock quantities are
+// set rather than added to.
+func Load(ctx context.Context, db *pgxpool.Pool, orgID, name string) (Result, error) {
+	set, err := Get(name)
+	if err != nil {
+		return Result{Set: name}, err
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return Result{Set: name}, err
+	}
+	defer tx.Rollback(ctx) // This will be ignored if tx.Commit() succeeds
+
+	result, err := load(ctx, tx, orgID, name, set)
+	if err != nil {
+		return result, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+func load(ctx context.Context, tx pgx.Tx, orgID, name string, set Set) (Result, error) {
+	result := Result{Set: name}
+	qtx := models.New(tx)
+	// Creating warehouses holds this lock while picking their slugs
+	if err := qtx.LockQuota(ctx, "warehouses:"+orgID); err != nil {
+		return result, err
+	}
+	for _, w := range set.Warehouses {
+		// The slug of a fixture warehouse that exists is left alone
+		base := slug.Make(w.Name)
+		taken, err := qtx.ListWarehouseSlugs(ctx, models.ListWarehouseSlugsParams{OrgID: orgID, Base: base})
+		if err != nil {
+			return result, fmt.Errorf("fixtures: warehouse %d: %w", w.ID, err)
+		}
+		n, err := qtx.SeedWarehouse(ctx, models.SeedWarehouseParams{
+			ID:        w.ID,
+			Name:      w.Name,
+			Address:   w.Address,
+			Ward:      w.Ward,
+			District:  w.District,
+			City:      w.City,
+			Country:   w.Country,
+			OrgID:     orgID,
+			Latitude:  float8(w.Latitude),
+			Longitude: float8(w.Longitude),
+			TimeZone:  orDefault(w.TimeZone, "UTC"),
+			Tags:      nonNilTags(w.Tags),
+			Slug:      slug.Next(base, taken),
+		})
+		if err != nil {
+			return result, fmt.Errorf("fixtures: warehouse %d: %w", w.ID, err)
+		}
+		if n == 0 {
+			return result, fmt.Errorf("%w: warehouse %d", ErrIDTaken, w.ID)
+		}
+		result.Warehouses++
+
+		for _, room := range w.StorageRooms {
+			n, err := qtx.SeedStorageRoom(ctx, models.SeedStorageRoomParams{
+				ID:          room.ID,
+				Name:        room.Name,
+				Number:      room.Number,
+				WarehouseID: int32(w.ID),
+				OrgID:       orgID,
+				ZoneType:    orDefault(room.ZoneType, "ambient"),
+			})
+			if err != nil {
+				return result, fmt.Errorf("fixtures: storage room %d: %w", room.ID, err)
+			}
+			if n == 0 {
+				return result, fmt.Errorf("%w: storage room %d", ErrIDTaken, room.ID)
+			}
+			result.StorageRooms++
+
+			for _, stock := range room.Stock {
+				params := models.SeedStockLevelParams{
+					OrgID:         orgID,
+					StorageRoomID: room.ID,
+					Sku:           stock.Sku,
+					Quantity:      stock.Quantity,
+				}
+				if stock.ExpiresAt != nil {
+					params.ExpiresAt = pgtype.Timestamptz{Time: *stock.ExpiresAt, Valid: true}
+				}
+				if err := qtx.SeedStockLevel(ctx, params); err != nil {
+					return result, fmt.Errorf("fixtures: stock %s in room %d: %w", stock.Sku, room.ID, err)
+				}
+				result.StockLevels++
+			}
+		}
+	}
+	if err := qtx.SyncWarehouseIDSequence(ctx); err != nil {
+		return result, fmt.Errorf("fixtures: sync warehouse sequence: %w", err)
+	}
+	return result, nil
+}
+
+func float8(v *float64) pgtype.Float8 {
+	if v == nil {
+		return pgtype.Float8{}
+	}
+	return pgtype.Float8{Float64: *v, Valid: true}
+}
+
+func orDefault(v, fallback string) string {
+	if v == "" {
+		return fallback
+	}
+	return v
+}
+
+// nonNilTags keeps tags non-null, the column is NOT NULL
+func nonNilTags(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	"warehouse-service/address"
+	"warehouse-service/authz"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+	"warehouse-service/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// normalizeAddress returns the address a warehouse is stored with: raw
+// normalized and, with an address validator, as the validator corrected it.
+// validated tells whether the validator confirmed it. An unknown country or
+// an address the validator rejects fail; a validator that can't be reached
+// leaves the address normalized only, the write doesn't wait on it.
+func (h *Handlers) normalizeAddress(ctx context.Context, raw address.Address) (address.Address, bool, error) {
+	normalized, err := address.Normalize(raw)
+	if err != nil {
+		return address.Address{}, false, err
+	}
+	if h.addressValidator == nil || normalized == (address.Address{}) {
+		return normalized, false, nil
+	}
+
+	spanCtx, span := h.tracer.Start(ctx, "ValidateAddress")
+	defer span.End()
+
+	validated, err := h.addressValidator.Validate(spanCtx, normalized)
+	if errors.Is(err, address.ErrInvalid) {
+		tracing.Result(span, "invalid")
+		return address.Address{}, false, err
+	}
+	if err != nil {
+		slog.Warn("Could not validate warehouse address", slog.String("country", normalized.Country), slog.Any("err", err.Error()))
+		span.RecordError(err)
+		return normalized, false, nil
+	}
+	tracing.Result(span, observability.StatusSuccess)
+	return validated, true, nil
+}
+
+// warehouseAddress is the address a warehouse is stored with
+func warehouseAddress(w models.Warehouse) address.Address {
+	return address.Address{
+		Street:   w.Address,
+		Ward:     w.Ward,
+		District: w.District,
+		City:     w.City,
+		Country:  w.Country,
+	}
+}
+
+// formAddress reads the address fields of a v1 form. v1 forms have no
+// District.
+func formAddress(ctx *gin.Context) address.Address {
+	return address.Address{
+		Street:  ctx.PostForm("Address"),
+		Ward:    ctx.PostForm("Ward"),
+		City:    ctx.PostForm("City"),
+		Country: ctx.PostForm("Country"),
+	}
+}
+
+// isAddressError tells whether err refuses the address of a write
+func isAddressError(err error) bool {
+	return errors.Is(err, address.ErrUnknownCountry) || errors.Is(err, address.ErrInvalid)
+}
+
+// addressField is the request field an address error is about
+func addressField(err error) string {
+	if errors.Is(err, address.ErrUnknownCountry) {
+		return "country"
+	}
+	return "address"
+}
+
+// respondAddressError answers a v1 write whose address was refused
+func respondAddressError(ctx *gin.Context, err error) {
+	ctx.JSON(http.StatusBadRequest, gin.H{
+		"error": err.Error(),
+		"field": addressField(err),
+	})
+}
+
+// respondAddressErrorV2 answers a v2 write whose address was refused
+func respondAddressErrorV2(ctx *gin.Context, err error) {
+	ctx.JSON(http.StatusBadRequest, envelope{Errors: []apiError{{
+		Code:    errCodeValidation,
+		Message: err.Error(),
+		Field:   addressField(err),
+	}}})
+}
+
+// rawAddressParams stores a as the raw address of the warehouse
+func rawAddressParams(id int64, orgID string, a address.Address, validated bool) models.UpsertWarehouseAddressParams {
+	text := func(s string) pgtype.Text { return pgtype.Text{String: s, Valid: true} }
+	return models.UpsertWarehouseAddressParams{
+		Address:     text(a.Street),
+		Ward:        text(a.Ward),
+		District:    text(a.District),
+		City:        text(a.City),
+		Country:     text(a.Country),
+		Validated:   validated,
+		WarehouseID: id,
+		OrgID:       orgID,
+	}
+}
+
+// saveRawAddress records the address of a write as it was sent, in the
+// write's transaction
+func (h *Handlers) saveRawAddress(ctx context.Context, qtx *models.Queries, arg models.UpsertWarehouseAddressParams) error {
+	dbStart := time.Now()
+	err := qtx.UpsertWarehouseAddress(ctx, arg)
+	h.recordDBOperation(ctx, "upsert", "warehouse_address", dbStart, err)
+	return err
+}
+
+// GetWarehouseAddress returns the address of a warehouse both normalized,
+// as it is stored, and as it was last sent
+func (h *Handlers) GetWarehouseAddress(ctx *gin.Context) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "GetWarehouseAddress")
+	defer span.End()
+
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "Invalid warehouse ID format"),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	traceOperation(ctx, span, observability.EntityWarehouse, id)
+
+	queries := h.readQueries(spanCtx)
+	dbStart := time.Now()
+	warehouse, err := queries.GetWarehouse(spanCtx, models.GetWarehouseParams{
+		ID:    id,
+		OrgID: orgID,
+	})
+	h.recordDBOperation(spanCtx, "get", "warehouse", dbStart, err)
+	if err == nil {
+		err = h.authorize(ctx, authz.Read, observability.EntityWarehouse, warehouse.OrgID)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		tracing.Result(span, observability.StatusNotFound)
+		h.recordOperation(orgID, observability.EntityWarehouse, "get_address", opStart, pgx.ErrNoRows)
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Warehouse not found"),
+		})
+		return
+	}
+
+	var raw *models.WarehouseAddress
+	if err == nil {
+		dbStart = time.Now()
+		var stored models.WarehouseAddress
+		stored, err = queries.GetWarehouseAddress(spanCtx, models.GetWarehouseAddressParams{
+			WarehouseID: id,
+			OrgID:       orgID,
+		})
+		h.recordDBOperation(spanCtx, "get", "warehouse_address", dbStart, err)
+		if err == nil {
+			raw = &stored
+		} else if errors.Is(err, pgx.ErrNoRows) {
+			err = nil
+		}
+	}
+	if err != nil {
+		slog.Error("Could not get warehouse address", slog.Any("err", err.Error()))
+		tracing.Failed(span, err)
+		h.recordOperation(orgID, observability.EntityWarehouse, "get_address", opStart, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to get warehouse address"),
+		})
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntityWarehouse, "get_address", opStart, nil)
+	tracing.Result(span, observability.StatusSuccess)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Get Warehouse Address Successfully"),
+		"data":    newWarehouseAddressResponse(warehouse, raw),
+	})
+}
@@ -0,0 +1,478 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"warehouse-service/listquery"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/objectstore"
+	"warehouse-service/observability"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Attachments configures warehouse document storage. A nil Store disables
+// the attachment endpoints, a nil Scanner skips the virus scan.
+type Attachments struct {
+	Store   objectstore.Store
+	Scanner objectstore.Scanner
+	// MaxSize is the largest accepted file in bytes
+	MaxSize int64
+	// ContentTypes lists the accepted types, detected from the file content
+	// rather than taken from the client
+	ContentTypes []string
+	// URLTTL is how long a download URL stays valid
+	URLTTL time.Duration
+}
+
+// Kinds of warehouse attachment
+var attachmentKinds = []string{"floor_plan", "certificate", "lease", "other"}
+
+const maxAttachmentFileName = 255
+
+// multipartOverhead is allowed on top of the file for the form fields and
+// part headers of an upload
+const multipartOverhead = 64 << 10
+
+// attachmentFileName keeps the base name of an uploaded file, clients send
+// full paths now and then
+func attachmentFileName(name string) (string, error) {
+	name = strings.TrimSpace(filepath.Base(strings.ReplaceAll(name, `\`, "/")))
+	if name == "" || name == "." || name == "/" {
+		return "", errors.New("file name must not be empty")
+	}
+	if len(name) > maxAttachmentFileName {
+		return "", fmt.Errorf("file name must be at most %d bytes", maxAttachmentFileName)
+	}
+	return name, nil
+}
+
+// sniffContentType detects the type of file from its first 512 bytes and
+// rewinds it
+func sniffContentType(file io.ReadSeeker) (string, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	contentType, _, _ := strings.Cut(http.DetectContentType(head[:n]), ";")
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return contentType, nil
+}
+
+// checksumFile returns the hex encoded SHA-256 of file and rewinds it
+func checksumFile(file io.ReadSeeker) (string, error) {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// attachmentIDs parses the warehouse and attachment IDs of the path,
+// writing the error response itself when it returns false
+func attachmentIDs(ctx *gin.Context) (warehouseID, attachmentID int64, ok bool) {
+	warehouseID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "Invalid warehouse ID format"),
+		})
+		return 0, 0, false
+	}
+	if ctx.Param("attachment_id") == "" {
+		return warehouseID, 0, true
+	}
+	attachmentID, err = strconv.ParseInt(ctx.Param("attachment_id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "Invalid attachment ID format"),
+		})
+		return 0, 0, false
+	}
+	return warehouseID, attachmentID, true
+}
+
+// UploadAttachment stores a document of a warehouse. The multipart form
+// carries the file in "file" and its kind in "kind". The file is checked
+// for size and type, handed to the scanner and written to the object store
+// before its row is inserted.
+func (h *Handlers) UploadAttachment(ctx *gin.Context) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "UploadAttachment")
+	defer span.End()
+
+	warehouseID, _, ok := attachmentIDs(ctx)
+	if !ok {
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.Int64("warehouse.id", warehouseID),
+		attribute.String("tenant.id", orgID),
+	)
+
+	// Limit the body before anything parses the form
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, h.attachments.MaxSize+multipartOverhead)
+	header, err := ctx.FormFile("file")
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) || (err == nil && header.Size > h.attachments.MaxSize) {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error": tr(ctx, "File must be at most %d bytes", h.attachments.MaxSize),
+		})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   tr(ctx, "Invalid attachment payload"),
+			"details": err.Error(),
+		})
+		return
+	}
+	kind := ctx.DefaultPostForm("kind", "other")
+	if !slices.Contains(attachmentKinds, kind) {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "kind must be one of %s", strings.Join(attachmentKinds, ", ")),
+		})
+		return
+	}
+
+	fileName, err := attachmentFileName(header.Filename)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	dbStart := time.Now()
+	_, err = h.queries.GetWarehouse(spanCtx, models.GetWarehouseParams{
+		ID:    warehouseID,
+		OrgID: orgID,
+	})
+	h.recordDBOperation(spanCtx, "get", "warehouse", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Warehouse not found"),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Got an error while getting warehouse: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to upload attachment"),
+		})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		slog.Error("Got an error while opening upload: ", slog.Any("err", err.Error()))
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "Invalid attachment payload"),
+		})
+		return
+	}
+	defer file.Close()
+
+	attachment, status, err := h.storeAttachment(spanCtx, file, models.CreateAttachmentParams{
+		OrgID:       orgID,
+		WarehouseID: warehouseID,
+		Kind:        kind,
+		FileName:    fileName,
+		SizeBytes:   header.Size,
+		ObjectKey:   fmt.Sprintf("%s/warehouses/%d/%s", orgID, warehouseID, uuid.NewString()),
+		UploadedBy:  actorID(ctx),
+	})
+	if err != nil {
+		h.recordOperation(orgID, observability.EntityAttachment, "create", opStart, err)
+		message := err.Error()
+		if status >= http.StatusInternalServerError {
+			slog.Error("Got an error while storing attachment: ", slog.Any("err", err.Error()))
+			span.RecordError(err)
+			message = "Failed to upload attachment"
+		}
+		ctx.JSON(status, gin.H{
+			"error": message,
+		})
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntityAttachment, "create", opStart, nil)
+
+	span.SetAttributes(
+		attribute.Int64("attachment.id", attachment.ID),
+		attribute.Int64("attachment.size", attachment.SizeBytes),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusCreated, gin.H{
+		"message": tr(ctx, "Upload Attachment Successfully"),
+		"data":    newAttachmentResponse(attachment),
+	})
+}
+
+// storeAttachment validates and scans file, puts it in the object store and
+// inserts its row. On failure it returns the response status with an error,
+// whose message is shown to the client for a 4xx status.
+func (h *Handlers) storeAttachment(ctx context.Context, file multipart.File, params models.CreateAttachmentParams) (models.Attachment, int, error) {
+	contentType, err := sniffContentType(file)
+	if err != nil {
+		return models.Attachment{}, http.StatusBadRequest, errors.New("Could not read the file")
+	}
+	if !slices.Contains(h.attachments.ContentTypes, contentType) {
+		return models.Attachment{}, http.StatusUnsupportedMediaType,
+			fmt.Errorf("File type %s is not allowed, allowed types are %s", contentType, strings.Join(h.attachments.ContentTypes, ", "))
+	}
+	params.ContentType = contentType
+
+	if params.Checksum, err = checksumFile(file); err != nil {
+		return models.Attachment{}, http.StatusBadRequest, errors.New("Could not read the file")
+	}
+
+	if h.attachments.Scanner != nil {
+		err := h.attachments.Scanner.Scan(ctx, params.FileName, file)
+		if errors.Is(err, objectstore.ErrRejected) {
+			slog.Warn("Upload rejected by scanner", slog.String("tenant_id", params.OrgID), slog.String("file_name", params.FileName), slog.Any("err", err.Error()))
+			return models.Attachment{}, http.StatusUnprocessableEntity, errors.New("File was rejected by the virus scan")
+		}
+		if err != nil {
+			return models.Attachment{}, http.StatusBadGateway, fmt.Errorf("Could not scan the file: %w", err)
+		}
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return models.Attachment{}, http.StatusInternalServerError, err
+		}
+	}
+
+	if err := h.attachments.Store.Put(ctx, params.ObjectKey, file, params.SizeBytes, contentType, params.Checksum); err != nil {
+		return models.Attachment{}, http.StatusBadGateway, fmt.Errorf("Failed to store the file: %w", err)
+	}
+
+	dbStart := time.Now()
+	attachment, err := h.queries.CreateAttachment(ctx, params)
+	h.recordDBOperation(ctx, "create", "attachment", dbStart, err)
+	if err != nil {
+		// Without its row nothing would ever remove the object
+		h.deleteObjects(ctx, params.ObjectKey)
+		return models.Attachment{}, dbErrorStatus(err), fmt.Errorf("Failed to create attachment: %w", err)
+	}
+	return attachment, http.StatusCreated, nil
+}
+
+// deleteObjects removes objects whose rows are gone. A failure leaves an
+// orphaned object behind, which is logged rather than failing the request.
+func (h *Handlers) deleteObjects(ctx context.Context, keys ...string) {
+	for _, key := range keys {
+		if err := h.attachments.Store.Delete(ctx, key); err != nil {
+			slog.Error("Could not delete attachment object: ", slog.String("object_key", key), slog.Any("err", err.Error()))
+		}
+	}
+}
+
+// attachmentListSpec sorts and filters the documents of ListAttachments
+var attachmentListSpec = &listquery.Spec{
+	Table: "attachment",
+	Sort: map[string]string{
+		"id":         "id",
+		"kind":       "kind",
+		"file_name":  "file_name",
+		"size_bytes": "size_bytes",
+		"created_at": "created_at",
+	},
+	Filters: map[string]listquery.Filter{
+		"kind":         {Column: "kind", Op: listquery.Eq, Parse: listquery.OneOf(attachmentKinds...)},
+		"content_type": {Column: "content_type", Op: listquery.Eq, Parse: listquery.Text},
+	},
+	Default: []listquery.Order{{Field: "id", Desc: true}},
+	Key:     "id",
+}
+
+// ListAttachments pages through the documents of a warehouse, newest
+// first, optionally filtered by ?kind=
+func (h *Handlers) ListAttachments(ctx *gin.Context) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "ListAttachments")
+	defer span.End()
+
+	warehouseID, _, ok := attachmentIDs(ctx)
+	if !ok {
+		return
+	}
+	q, err := listParams(ctx, attachmentListSpec)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	q.Where("org_id", listquery.Eq, orgID).Where("warehouse_id", listquery.Eq, warehouseID)
+	span.SetAttributes(
+		attribute.Int64("warehouse.id", warehouseID),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	attachments, err := queryPage[models.Attachment](ctx, spanCtx, h.router.Read(spanCtx), q)
+	h.recordDBOperation(spanCtx, "list", "attachment", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while listing attachments: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		h.recordOperation(orgID, observability.EntityAttachment, "list", opStart, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to list attachments"),
+		})
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntityAttachment, "list", opStart, nil)
+
+	span.SetAttributes(
+		attribute.Int("attachment.count", len(attachments)),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "List Attachments Successfully"),
+		"data":    mapSlice(attachments, newAttachmentResponse),
+	})
+}
+
+// GetAttachment returns a document with a presigned URL that downloads it
+// straight from the object store
+func (h *Handlers) GetAttachment(ctx *gin.Context) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "GetAttachment")
+	defer span.End()
+
+	warehouseID, attachmentID, ok := attachmentIDs(ctx)
+	if !ok {
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.Int64("warehouse.id", warehouseID),
+		attribute.Int64("attachment.id", attachmentID),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	attachment, err := h.readQueries(spanCtx).GetAttachment(spanCtx, models.GetAttachmentParams{
+		ID:          attachmentID,
+		WarehouseID: warehouseID,
+		OrgID:       orgID,
+	})
+	h.recordDBOperation(spanCtx, "get", "attachment", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		h.recordOperation(orgID, observability.EntityAttachment, "get", opStart, err)
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Attachment not found"),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Got an error while getting attachment: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		h.recordOperation(orgID, observability.EntityAttachment, "get", opStart, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to get attachment"),
+		})
+		return
+	}
+
+	expiresAt := time.Now().Add(h.attachments.URLTTL).UTC()
+	url, err := h.attachments.Store.PresignGet(spanCtx, attachment.ObjectKey, attachment.FileName, h.attachments.URLTTL)
+	if err != nil {
+		slog.Error("Got an error while presigning attachment URL: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		h.recordOperation(orgID, observability.EntityAttachment, "get", opStart, err)
+		ctx.JSON(http.StatusBadGateway, gin.H{
+			"error": tr(ctx, "Failed to create download URL"),
+		})
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntityAttachment, "get", opStart, nil)
+
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Get Attachment Successfully"),
+		"data": AttachmentDownloadResponse{
+			AttachmentResponse: newAttachmentResponse(attachment),
+			DownloadURL:        url,
+			ExpiresAt:          expiresAt,
+		},
+	})
+}
+
+// DeleteAttachment removes a document's row and then its object
+func (h *Handlers) DeleteAttachment(ctx *gin.Context) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "DeleteAttachment")
+	defer span.End()
+
+	warehouseID, attachmentID, ok := attachmentIDs(ctx)
+	if !ok {
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.Int64("warehouse.id", warehouseID),
+		attribute.Int64("attachment.id", attachmentID),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	attachment, err := h.queries.DeleteAttachment(spanCtx, models.DeleteAttachmentParams{
+		ID:          attachmentID,
+		WarehouseID: warehouseID,
+		OrgID:       orgID,
+	})
+	h.recordDBOperation(spanCtx, "delete", "attachment", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		h.recordOperation(orgID, observability.EntityAttachment, "delete", opStart, err)
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Attachment not found"),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Got an error while deleting attachment: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		h.recordOperation(orgID, observability.EntityAttachment, "delete", opStart, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to delete attachment"),
+		})
+		return
+	}
+	h.deleteObjects(spanCtx, attachment.ObjectKey)
+
+	h.recordOperation(orgID, observability.EntityAttachment, "delete", opStart, nil)
+
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": tr(ctx, "Delete Attachment Successfully"),
+	})
+}
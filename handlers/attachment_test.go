@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestAttachmentFileName(t *testing.T) {
+	tests := []struct {
+		in, want string
+		fails    bool
+	}{
+		{in: "plan.pdf", want: "plan.pdf"},
+		{in: `C:\plans\ground floor.pdf`, want: "ground floor.pdf"},
+		{in: "../../etc/passwd", want: "passwd"},
+		{in: "  ", fails: true},
+		{in: "/", fails: true},
+		{in: strings.Repeat("a", 256), fails: true},
+	}
+	for _, tt := range tests {
+		got, err := attachmentFileName(tt.in)
+		if (err != nil) != tt.fails || got != tt.want {
+			t.Errorf("attachmentFileName(%q) = %q, %v", tt.in, got, err)
+		}
+	}
+}
+
+func TestSniffContentType(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"%PDF-1.7\n", "application/pdf"},
+		{"\x89PNG\r\n\x1a\n", "image/png"},
+		{"plain text", "text/plain"},
+		{"", "text/plain"},
+	}
+	for _, tt := range tests {
+		file := strings.NewReader(tt.content)
+		got, err := sniffContentType(file)
+		if err != nil || got != tt.want {
+			t.Errorf("sniffContentType(%q) = %q, %v, want %q", tt.content, got, err, tt.want)
+		}
+		if rest, _ := io.ReadAll(file); string(rest) != tt.content {
+			t.Errorf("file not rewound, read %q", rest)
+		}
+	}
+}
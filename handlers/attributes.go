@@ -0,0 +1,486 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	maxAttributesSize      = 16 << 10
+	maxAttributeSchemaSize = 64 << 10
+	// attributeFilterPrefix marks list query parameters such as
+	// ?attr.dock_count=4 that filter on attribute values
+	attributeFilterPrefix = "attr."
+	// attributeSchemaURL names the tenant schema while it is compiled, it is
+	// never fetched
+	attributeSchemaURL = "urn:warehouse-service:attributes"
+)
+
+// attributeEntities lists the entity types that carry custom attributes
+var attributeEntities = []string{observability.EntityWarehouse, observability.EntityStorageRoom}
+
+func isAttributeEntity(entity string) bool {
+	for _, e := range attributeEntities {
+		if e == entity {
+			return true
+		}
+	}
+	return false
+}
+
+// attributesInvalidError lists why attributes do not match the tenant schema
+type attributesInvalidError struct {
+	problems []attributeProblem
+}
+
+// attributeProblem is one failed schema rule, Field is a JSON pointer into
+// the attributes object such as /dock_count
+type attributeProblem struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *attributesInvalidError) Error() string {
+	messages := make([]string, 0, len(e.problems))
+	for _, p := range e.problems {
+		messages = append(messages, p.Field+": "+p.Message)
+	}
+	return "attributes do not match the schema: " + strings.Join(messages, "; ")
+}
+
+// decodeAttributes checks that raw is a JSON object within the size limit
+// and returns it compacted for storage
+func decodeAttributes(raw []byte) ([]byte, error) {
+	if len(raw) > maxAttributesSize {
+		return nil, fmt.Errorf("attributes must be at most %d bytes", maxAttributesSize)
+	}
+	var object map[string]any
+	if err := json.Unmarshal(raw, &object); err != nil || object == nil {
+		return nil, errors.New("attributes must be a JSON object")
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, raw); err != nil {
+		return nil, errors.New("attributes must be a JSON object")
+	}
+	return compact.Bytes(), nil
+}
+
+// formAttributes reads the v1 Attributes form field, a JSON object. An empty
+// value clears the attributes, sent is false when the field is missing.
+func formAttributes(ctx *gin.Context) (attrs []byte, sent bool, err error) {
+	v, ok := ctx.GetPostForm("Attributes")
+	if !ok {
+		return nil, false, nil
+	}
+	if v == "" {
+		return []byte("{}"), true, nil
+	}
+	attrs, err = decodeAttributes([]byte(v))
+	return attrs, true, err
+}
+
+// compileAttributeSchema compiles a tenant JSON Schema. References are only
+// resolved within the document, nothing is loaded from files or the network.
+func compileAttributeSchema(raw []byte) (*jsonschema.Schema, error) {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+	if err != nil {
+		return nil, errors.New("schema must be valid JSON")
+	}
+	compiler := jsonschema.NewCompiler()
+	compiler.UseLoader(jsonschema.SchemeURLLoader{})
+	compiler.AssertFormat()
+	if err := compiler.AddResource(attributeSchemaURL, doc); err != nil {
+		return nil, err
+	}
+	return compiler.Compile(attributeSchemaURL)
+}
+
+// checkAttributes validates attrs against the tenant schema for entity. A
+// tenant without a schema accepts any object. Mismatches are reported as
+// *attributesInvalidError, other errors come from loading the schema.
+func (h *Handlers) checkAttributes(ctx context.Context, orgID, entity string, attrs []byte) error {
+	dbStart := time.Now()
+	stored, err := h.queries.GetAttributeSchema(ctx, models.GetAttributeSchemaParams{
+		OrgID:      orgID,
+		EntityType: entity,
+	})
+	h.recordDBOperation(ctx, "get", "attribute_schema", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	schema, err := compileAttributeSchema(stored.Schema)
+	if err != nil {
+		// Schemas are compiled before they are stored, so this only happens
+		// after an upgrade of the validator
+		return fmt.Errorf("compile %s attribute schema of %s: %w", entity, orgID, err)
+	}
+	value, err := jsonschema.UnmarshalJSON(bytes.NewReader(attrs))
+	if err != nil {
+		return err
+	}
+
+	var invalid *jsonschema.ValidationError
+	if err := schema.Validate(value); errors.As(err, &invalid) {
+		return &attributesInvalidError{problems: attributeProblems(invalid)}
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// attributeProblems flattens a validation error to the failed leaf rules
+func attributeProblems(invalid *jsonschema.ValidationError) []attributeProblem {
+	var problems []attributeProblem
+	for _, unit := range invalid.BasicOutput().Errors {
+		if unit.Error == nil {
+			continue
+		}
+		var message string
+		if raw, err := unit.Error.MarshalJSON(); err == nil {
+			_ = json.Unmarshal(raw, &message)
+		}
+		// The root unit only says that validation failed
+		if strings.HasPrefix(message, "validation failed") {
+			continue
+		}
+		field := unit.InstanceLocation
+		if field == "" {
+			field = "/"
+		}
+		problems = append(problems, attributeProblem{Field: field, Message: message})
+	}
+	if len(problems) == 0 {
+		problems = append(problems, attributeProblem{Field: "/", Message: invalid.Error()})
+	}
+	return problems
+}
+
+// respondAttributesInvalid writes the v1 response for attributes rejected by
+// the tenant schema
+func respondAttributesInvalid(ctx *gin.Context, err *attributesInvalidError) {
+	ctx.JSON(http.StatusBadRequest, gin.H{
+		"error":   tr(ctx, "Attributes do not match the attribute schema"),
+		"details": err.problems,
+	})
+}
+
+// respondAttributesError writes the v1 response for a failed checkAttributes
+func respondAttributesError(ctx *gin.Context, err error) {
+	var invalid *attributesInvalidError
+	if errors.As(err, &invalid) {
+		respondAttributesInvalid(ctx, invalid)
+		return
+	}
+	slog.Error("Could not validate attributes: ", slog.Any("err", err.Error()))
+	ctx.JSON(dbErrorStatus(err), gin.H{
+		"error": tr(ctx, "Failed to validate attributes"),
+	})
+}
+
+// respondAttributesErrorV2 writes the v2 response for a failed checkAttributes
+func respondAttributesErrorV2(ctx *gin.Context, err error) {
+	var invalid *attributesInvalidError
+	if errors.As(err, &invalid) {
+		respondAttributesInvalidV2(ctx, invalid)
+		return
+	}
+	slog.Error("Could not validate attributes: ", slog.Any("err", err.Error()))
+	respondV2Error(ctx, dbErrorStatus(err), dbErrorCode(err), "Failed to validate attributes")
+}
+
+// respondAttributesInvalidV2 reports each failed rule as its own v2 error
+func respondAttributesInvalidV2(ctx *gin.Context, err *attributesInvalidError) {
+	apiErrors := make([]apiError, 0, len(err.problems))
+	for _, p := range err.problems {
+		apiErrors = append(apiErrors, apiError{
+			Code:    errCodeValidation,
+			Message: p.Message,
+			Field:   "attributes" + strings.ReplaceAll(strings.TrimSuffix(p.Field, "/"), "/", "."),
+		})
+	}
+	ctx.JSON(http.StatusBadRequest, envelope{Errors: apiErrors})
+}
+
+// attributeFilter builds a containment filter from ?attr.<path>=<value>
+// query parameters. Dots in the path address nested objects. Values are
+// matched as JSON numbers, booleans or null when they parse as such and as
+// strings otherwise, a quoted value such as "4" always matches a string.
+func attributeFilter(ctx *gin.Context) ([]byte, error) {
+	filter := map[string]any{}
+	for key, values := range ctx.Request.URL.Query() {
+		path, ok := strings.CutPrefix(key, attributeFilterPrefix)
+		if !ok {
+			continue
+		}
+		if len(values) > 1 {
+			return nil, fmt.Errorf("%s can only be given once", key)
+		}
+		segments := strings.Split(path, ".")
+		node := filter
+		for i, segment := range segments {
+			if segment == "" {
+				return nil, fmt.Errorf("%s is not a valid attribute path", key)
+			}
+			if i == len(segments)-1 {
+				if _, exists := node[segment]; exists {
+					return nil, fmt.Errorf("%s conflicts with another attribute filter", key)
+				}
+				node[segment] = attributeFilterValue(values[0])
+				break
+			}
+			child, exists := node[segment]
+			if !exists {
+				child = map[string]any{}
+				node[segment] = child
+			}
+			next, isObject := child.(map[string]any)
+			if !isObject {
+				return nil, fmt.Errorf("%s conflicts with another attribute filter", key)
+			}
+			node = next
+		}
+	}
+	if len(filter) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(filter)
+}
+
+func attributeFilterValue(raw string) any {
+	var value any
+	if err := json.Unmarshal([]byte(raw), &value); err == nil {
+		switch value.(type) {
+		case float64, bool, nil, string:
+			return value
+		}
+	}
+	return raw
+}
+
+// attributesOrEmpty returns stored attributes for a response, rows written
+// before attributes existed hold the column default
+func attributesOrEmpty(raw []byte) json.RawMessage {
+	if len(raw) == 0 {
+		return json.RawMessage("{}")
+	}
+	return json.RawMessage(raw)
+}
+
+// parseAttributeEntity reads the :entity path parameter of the schema
+// endpoints, writing the error response itself when it returns false
+func parseAttributeEntity(ctx *gin.Context) (string, bool) {
+	entity := ctx.Param("entity")
+	if !isAttributeEntity(entity) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Unknown entity type, expected one of %s", strings.Join(attributeEntities, ", ")),
+		})
+		return "", false
+	}
+	return entity, true
+}
+
+// ListAttributeSchemas returns the attribute schemas of the tenant
+func (h *Handlers) ListAttributeSchemas(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "ListAttributeSchemas")
+	defer span.End()
+
+	orgID := tenantID(ctx)
+	span.SetAttributes(attribute.String("tenant.id", orgID))
+
+	dbStart := time.Now()
+	schemas, err := h.queries.ListAttributeSchemas(spanCtx, orgID)
+	h.recordDBOperation(spanCtx, "list", "attribute_schema", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while listing attribute schemas: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to list attribute schemas"),
+		})
+		return
+	}
+
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "List Attribute Schemas Successfully"),
+		"data":    mapSlice(schemas, newAttributeSchemaResponse),
+	})
+}
+
+// GetAttributeSchema returns the attribute schema of one entity type
+func (h *Handlers) GetAttributeSchema(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "GetAttributeSchema")
+	defer span.End()
+
+	entity, ok := parseAttributeEntity(ctx)
+	if !ok {
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.String("attribute_schema.entity_type", entity),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	schema, err := h.queries.GetAttributeSchema(spanCtx, models.GetAttributeSchemaParams{
+		OrgID:      orgID,
+		EntityType: entity,
+	})
+	h.recordDBOperation(spanCtx, "get", "attribute_schema", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "No attribute schema for %s", entity),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Got an error while getting attribute schema: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to get attribute schema"),
+		})
+		return
+	}
+
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Get Attribute Schema Successfully"),
+		"data":    newAttributeSchemaResponse(schema),
+	})
+}
+
+// PutAttributeSchema stores the JSON Schema in the body as the attribute
+// schema of an entity type. Only later writes are validated against it,
+// stored attributes are left as they are.
+func (h *Handlers) PutAttributeSchema(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "PutAttributeSchema")
+	defer span.End()
+
+	entity, ok := parseAttributeEntity(ctx)
+	if !ok {
+		return
+	}
+	raw, err := ctx.GetRawData()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "Invalid attribute schema payload"),
+		})
+		return
+	}
+	if len(raw) > maxAttributeSchemaSize {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error": tr(ctx, "Attribute schema must be at most %d bytes", maxAttributeSchemaSize),
+		})
+		return
+	}
+	if _, err := compileAttributeSchema(raw); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   tr(ctx, "Invalid attribute schema"),
+			"details": err.Error(),
+		})
+		return
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, raw); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "Invalid attribute schema payload"),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.String("attribute_schema.entity_type", entity),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	schema, err := h.queries.UpsertAttributeSchema(spanCtx, models.UpsertAttributeSchemaParams{
+		OrgID:      orgID,
+		EntityType: entity,
+		Schema:     compact.Bytes(),
+		UpdatedBy:  actorID(ctx),
+	})
+	h.recordDBOperation(spanCtx, "upsert", "attribute_schema", dbStart, err)
+	if err != nil {
+		slog.Error("Could not store attribute schema: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to store attribute schema"),
+		})
+		return
+	}
+
+	slog.Info("Attribute schema updated",
+		slog.String("tenant_id", orgID),
+		slog.String("entity_type", entity),
+		slog.String("user_id", schema.UpdatedBy),
+	)
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Update Attribute Schema Successfully"),
+		"data":    newAttributeSchemaResponse(schema),
+	})
+}
+
+// DeleteAttributeSchema removes the attribute schema of an entity type,
+// after which any attributes object is accepted
+func (h *Handlers) DeleteAttributeSchema(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "DeleteAttributeSchema")
+	defer span.End()
+
+	entity, ok := parseAttributeEntity(ctx)
+	if !ok {
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.String("attribute_schema.entity_type", entity),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	rows, err := h.queries.DeleteAttributeSchema(spanCtx, models.DeleteAttributeSchemaParams{
+		OrgID:      orgID,
+		EntityType: entity,
+	})
+	h.recordDBOperation(spanCtx, "delete", "attribute_schema", dbStart, err)
+	if err != nil {
+		slog.Error("Failed to delete attribute schema: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to delete attribute schema"),
+		})
+		return
+	}
+	if rows == 0 {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "No attribute schema for %s", entity),
+		})
+		return
+	}
+
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	ctx.JSON(http.StatusOK, gin.H{"message": tr(ctx, "Delete Attribute Schema Successfully")})
+}
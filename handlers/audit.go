@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"context"
+	"time"
+	models "warehouse-service/models/sqlc"
+)
+
+// auditEntry describes a state transition of a business entity
+type auditEntry struct {
+	OrgID      string
+	EntityType string
+	EntityID   int64
+	Action     string
+	FromStatus string
+	ToStatus   string
+	Actor      string
+}
+
+// recordAudit appends entry to the audit log. Pass transaction-bound queries
+// so the audit row commits together with the transition it describes.
+func (h *Handlers) recordAudit(ctx context.Context, qtx *models.Queries, entry auditEntry) error {
+	dbStart := time.Now()
+	_, err := qtx.CreateAuditLog(ctx, models.CreateAuditLogParams{
+		OrgID:      entry.OrgID,
+		EntityType: entry.EntityType,
+		EntityID:   entry.EntityID,
+		Action:     entry.Action,
+		FromStatus: entry.FromStatus,
+		ToStatus:   entry.ToStatus,
+		Actor:      entry.Actor,
+	})
+	h.recordDBOperation(ctx, "create", "audit_log", dbStart, err)
+	return err
+}
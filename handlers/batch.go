@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+	"warehouse-service/tracing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type batchGetRequest struct {
+	IDs []int64 `json:"ids" binding:"required,min=1,max=100,dive,gt=0"`
+}
+
+// uniqueIDs drops duplicate IDs, keeping the request order
+func uniqueIDs(ids []int64) []int64 {
+	seen := make(map[int64]bool, len(ids))
+	out := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// batchResult splits the requested IDs into found entities keyed by ID and
+// the IDs that do not exist for the tenant
+func batchResult[T any](ids []int64, found map[int64]T) gin.H {
+	byID := make(map[string]T, len(found))
+	missing := []int64{}
+	for _, id := range ids {
+		entity, ok := found[id]
+		if !ok {
+			missing = append(missing, id)
+			continue
+		}
+		byID[strconv.FormatInt(id, 10)] = entity
+	}
+	return gin.H{
+		"found":   byID,
+		"missing": missing,
+	}
+}
+
+// BatchGetWarehouses resolves up to 100 warehouse IDs with a single query
+func (h *Handlers) BatchGetWarehouses(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "BatchGetWarehouses")
+	defer span.End()
+
+	var req batchGetRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   tr(ctx, "Invalid batch get payload"),
+			"details": err.Error(),
+		})
+		return
+	}
+	ids := uniqueIDs(req.IDs)
+	orgID := tenantID(ctx)
+	tracing.Actor(span, orgID, actorID(ctx))
+	span.SetAttributes(attribute.Int("warehouse.requested", len(ids)))
+
+	dbStart := time.Now()
+	warehouses, err := h.readQueries(spanCtx).GetWarehousesByIDs(spanCtx, models.GetWarehousesByIDsParams{
+		OrgID: orgID,
+		ID:    ids,
+	})
+	h.recordDBOperation(spanCtx, "list", "warehouse", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while batch getting warehouses: ", slog.Any("err", err.Error()))
+		tracing.Failed(span, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to get warehouses"),
+		})
+		return
+	}
+
+	warehouses = authorizedRows(h, ctx, observability.EntityWarehouse, warehouses, warehouseOrg)
+	found := make(map[int64]WarehouseResponse, len(warehouses))
+	for _, warehouse := range warehouses {
+		found[warehouse.ID] = newWarehouseResponse(warehouse)
+	}
+
+	span.SetAttributes(attribute.Int("warehouse.count", len(found)))
+	tracing.Result(span, observability.StatusSuccess)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Batch Get Warehouses Successfully"),
+		"data":    batchResult(ids, found),
+	})
+}
+
+// BatchGetStorageRooms resolves up to 100 storage room IDs with a single query
+func (h *Handlers) BatchGetStorageRooms(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "BatchGetStorageRooms")
+	defer span.End()
+
+	var req batchGetRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   tr(ctx, "Invalid batch get payload"),
+			"details": err.Error(),
+		})
+		return
+	}
+	ids := uniqueIDs(req.IDs)
+	roomIDs := make([]int32, 0, len(ids))
+	for _, id := range ids {
+		if id > math.MaxInt32 {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": tr(ctx, "Invalid storage room ID %d", id),
+			})
+			return
+		}
+		roomIDs = append(roomIDs, int32(id))
+	}
+	orgID := tenantID(ctx)
+	tracing.Actor(span, orgID, actorID(ctx))
+	span.SetAttributes(attribute.Int("storage_room.requested", len(ids)))
+
+	dbStart := time.Now()
+	rooms, err := h.readQueries(spanCtx).GetStorageRoomsByIDs(spanCtx, models.GetStorageRoomsByIDsParams{
+		OrgID: orgID,
+		ID:    roomIDs,
+	})
+	h.recordDBOperation(spanCtx, "list", "storage_room", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while batch getting storage rooms: ", slog.Any("err", err.Error()))
+		tracing.Failed(span, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to get storage rooms"),
+		})
+		return
+	}
+
+	rooms = authorizedRows(h, ctx, observability.EntityStorageRoom, rooms, storageRoomOrg)
+	responses, err := h.storageRoomResponses(spanCtx, h.readQueries(spanCtx), orgID, rooms)
+	if err != nil {
+		slog.Error("Got an error while getting storage room occupancy: ", slog.Any("err", err.Error()))
+		tracing.Failed(span, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to get storage rooms"),
+		})
+		return
+	}
+	found := make(map[int64]StorageRoomResponse, len(responses))
+	for _, room := range responses {
+		found[int64(room.ID)] = room
+	}
+
+	span.SetAttributes(attribute.Int("storage_room.count", len(found)))
+	tracing.Result(span, observability.StatusSuccess)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Batch Get Storage Rooms Successfully"),
+		"data":    batchResult(ids, found),
+	})
+}
@@ -0,0 +1,723 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Count session lifecycle states
+const (
+	countStatusOpen   = "open"
+	countStatusPosted = "posted"
+)
+
+const auditEntityCountSession = "count_session"
+
+type openCountRequest struct {
+	WarehouseID   WarehouseRef `json:"warehouse_id" binding:"required"`
+	StorageRoomID int32        `json:"storage_room_id"`
+}
+
+type countLineRequest struct {
+	StorageRoomID   int32  `json:"storage_room_id" binding:"required"`
+	Sku             string `json:"sku" binding:"required"`
+	CountedQuantity int32  `json:"counted_quantity" binding:"gte=0"`
+	// Unit of the quantity, a registered unit of the item; base units when empty
+	Unit string `json:"unit"`
+}
+
+type recordCountRequest struct {
+	Lines []countLineRequest `json:"lines" binding:"required,min=1,dive"`
+}
+
+type postCountRequest struct {
+	// LineIDs selects the variances to approve, all counted variances when empty
+	LineIDs []int64 `json:"line_ids"`
+}
+
+// countVariance compares the counted quantity of a line against book stock
+type countVariance struct {
+	LineID          int64  `json:"line_id"`
+	StorageRoomID   int32  `json:"storage_room_id"`
+	Sku             string `json:"sku"`
+	BookQuantity    int32  `json:"book_quantity"`
+	CountedQuantity int32  `json:"counted_quantity"`
+	Variance        int32  `json:"variance"`
+	Approved        bool   `json:"approved"`
+}
+
+// countVariances returns the counted lines whose quantity differs from book stock
+func countVariances(lines []models.CountLine) []countVariance {
+	variances := []countVariance{}
+	for _, line := range lines {
+		if !line.CountedQuantity.Valid || line.CountedQuantity.Int32 == line.BookQuantity {
+			continue
+		}
+		variances = append(variances, countVariance{
+			LineID:          line.ID,
+			StorageRoomID:   line.StorageRoomID,
+			Sku:             line.Sku,
+			BookQuantity:    line.BookQuantity,
+			CountedQuantity: line.CountedQuantity.Int32,
+			Variance:        line.CountedQuantity.Int32 - line.BookQuantity,
+			Approved:        line.Approved,
+		})
+	}
+	return variances
+}
+
+func parseCountSessionID(ctx *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "Invalid count session ID format"),
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// OpenCountSession starts a stocktake for a warehouse, or a single storage
+// room of it, and snapshots the book stock the counts are compared against.
+func (h *Handlers) OpenCountSession(ctx *gin.Context) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "OpenCountSession")
+	defer span.End()
+
+	var req openCountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   tr(ctx, "Invalid count session payload"),
+			"details": err.Error(),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.String("warehouse.ref", string(req.WarehouseID)),
+		attribute.Int("storage_room.id", int(req.StorageRoomID)),
+		attribute.String("tenant.id", orgID),
+	)
+
+	tx, err := h.db.Begin(spanCtx)
+	if err != nil {
+		slog.Error("Failed to start transaction", slog.Any("err", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": tr(ctx, "Failed to start transaction"),
+		})
+		return
+	}
+	defer tx.Rollback(spanCtx) // This will be ignored if tx.Commit() succeeds
+
+	qtx := h.queries.WithTx(tx)
+
+	warehouseID, err := h.resolveWarehouse(spanCtx, qtx, orgID, req.WarehouseID)
+	if err == nil {
+		dbStart := time.Now()
+		_, err = qtx.GetWarehouse(spanCtx, models.GetWarehouseParams{
+			ID:    warehouseID,
+			OrgID: orgID,
+		})
+		h.recordDBOperation(spanCtx, "get", "warehouse", dbStart, err)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Warehouse not found"),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Got an error while getting warehouse: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to open count session"),
+		})
+		return
+	}
+	span.SetAttributes(attribute.Int64("warehouse.id", warehouseID))
+
+	if req.StorageRoomID != 0 {
+		dbStart := time.Now()
+		room, err := qtx.GetStorageRoom(spanCtx, models.GetStorageRoomParams{
+			ID:    req.StorageRoomID,
+			OrgID: orgID,
+		})
+		h.recordDBOperation(spanCtx, "get", "storage_room", dbStart, err)
+		if errors.Is(err, pgx.ErrNoRows) || (err == nil && int64(room.WarehouseID) != warehouseID) {
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error": tr(ctx, "Storage room not found in warehouse"),
+			})
+			return
+		}
+		if err != nil {
+			slog.Error("Got an error while getting storage room: ", slog.Any("err", err.Error()))
+			span.RecordError(err)
+			ctx.JSON(dbErrorStatus(err), gin.H{
+				"error": tr(ctx, "Failed to open count session"),
+			})
+			return
+		}
+	}
+
+	dbStart := time.Now()
+	session, err := qtx.CreateCountSession(spanCtx, models.CreateCountSessionParams{
+		OrgID:         orgID,
+		WarehouseID:   warehouseID,
+		StorageRoomID: pgtype.Int4{Int32: req.StorageRoomID, Valid: req.StorageRoomID != 0},
+	})
+	h.recordDBOperation(spanCtx, "create", "count_session", dbStart, err)
+	if err != nil {
+		slog.Error("Could not create count session: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to open count session"),
+		})
+		return
+	}
+
+	var snapshotted int64
+	dbStart = time.Now()
+	if session.StorageRoomID.Valid {
+		snapshotted, err = qtx.SnapshotRoomCountLines(spanCtx, models.SnapshotRoomCountLinesParams{
+			CountSessionID: session.ID,
+			OrgID:          orgID,
+			StorageRoomID:  session.StorageRoomID.Int32,
+		})
+	} else {
+		snapshotted, err = qtx.SnapshotWarehouseCountLines(spanCtx, models.SnapshotWarehouseCountLinesParams{
+			CountSessionID: session.ID,
+			OrgID:          orgID,
+			WarehouseID:    int32(session.WarehouseID),
+		})
+	}
+	h.recordDBOperation(spanCtx, "create", "count_line", dbStart, err)
+	if err == nil {
+		err = h.recordAudit(spanCtx, qtx, auditEntry{
+			OrgID:      orgID,
+			EntityType: auditEntityCountSession,
+			EntityID:   session.ID,
+			Action:     "open",
+			ToStatus:   session.Status,
+			Actor:      actorID(ctx),
+		})
+	}
+	if err != nil {
+		slog.Error("Could not snapshot book stock: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to open count session"),
+		})
+		return
+	}
+
+	if err := tx.Commit(spanCtx); err != nil {
+		slog.Error("Failed to commit transaction", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to commit transaction"),
+		})
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntityCountSession, "open", opStart, nil)
+
+	span.SetAttributes(
+		attribute.Int64("count_session.id", session.ID),
+		attribute.Int64("count_session.book_lines", snapshotted),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusCreated, gin.H{
+		"message": tr(ctx, "Open Count Session Successfully"),
+		"data":    newCountSessionResponse(session),
+	})
+}
+
+func (h *Handlers) ListCountSessions(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "ListCountSessions")
+	defer span.End()
+
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.Int("count_session.limit", 10),
+		attribute.Int("count_session.offset", 0),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	sessions, err := h.queries.ListCountSessions(spanCtx, models.ListCountSessionsParams{
+		OrgID:  orgID,
+		Limit:  10,
+		Offset: 0,
+	})
+	h.recordDBOperation(spanCtx, "list", "count_session", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while listing count sessions: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to list count sessions"),
+		})
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int("count_session.count", len(sessions)),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "List Count Sessions Successfully"),
+		"data":    mapSlice(sessions, newCountSessionResponse),
+	})
+}
+
+// loadCountSession fetches a tenant's count session with its lines, writing
+// the error response itself when it returns false.
+func (h *Handlers) loadCountSession(ctx *gin.Context, operation string) (models.CountSession, []models.CountLine, bool) {
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), operation)
+	defer span.End()
+
+	id, ok := parseCountSessionID(ctx)
+	if !ok {
+		return models.CountSession{}, nil, false
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.Int64("count_session.id", id),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	session, err := h.queries.GetCountSession(spanCtx, models.GetCountSessionParams{
+		ID:    id,
+		OrgID: orgID,
+	})
+	h.recordDBOperation(spanCtx, "get", "count_session", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Count session not found"),
+		})
+		return models.CountSession{}, nil, false
+	}
+
+	var lines []models.CountLine
+	if err == nil {
+		dbStart = time.Now()
+		lines, err = h.queries.ListCountLines(spanCtx, session.ID)
+		h.recordDBOperation(spanCtx, "list", "count_line", dbStart, err)
+	}
+	if err != nil {
+		slog.Error("Got an error while getting count session: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to get count session"),
+		})
+		return models.CountSession{}, nil, false
+	}
+
+	span.SetAttributes(
+		attribute.Int("count_session.lines", len(lines)),
+		attribute.String("operation.status", "success"),
+	)
+	return session, lines, true
+}
+
+func (h *Handlers) GetCountSession(ctx *gin.Context) {
+	session, lines, ok := h.loadCountSession(ctx, "GetCountSession")
+	if !ok {
+		return
+	}
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Get Count Session Successfully"),
+		"data": gin.H{
+			"session": newCountSessionResponse(session),
+			"lines":   mapSlice(lines, newCountLineResponse),
+		},
+	})
+}
+
+// GetCountVariance reports counted lines that differ from book stock, as
+// JSON or, with ?format=csv, as a CSV download.
+func (h *Handlers) GetCountVariance(ctx *gin.Context) {
+	session, lines, ok := h.loadCountSession(ctx, "GetCountVariance")
+	if !ok {
+		return
+	}
+	variances := countVariances(lines)
+
+	if ctx.Query("format") != "csv" {
+		respondV1(ctx, http.StatusOK, gin.H{
+			"message": tr(ctx, "Get Count Variance Successfully"),
+			"data": gin.H{
+				"session":   newCountSessionResponse(session),
+				"variances": variances,
+			},
+		})
+		return
+	}
+
+	ctx.Header("Content-Type", "text/csv")
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("count-%d-variance.csv", session.ID)))
+	ctx.Status(http.StatusOK)
+
+	w := csv.NewWriter(ctx.Writer)
+	w.Write([]string{"line_id", "storage_room_id", "sku", "book_quantity", "counted_quantity", "variance", "approved"})
+	for _, v := range variances {
+		w.Write([]string{
+			strconv.FormatInt(v.LineID, 10),
+			strconv.Itoa(int(v.StorageRoomID)),
+			v.Sku,
+			strconv.Itoa(int(v.BookQuantity)),
+			strconv.Itoa(int(v.CountedQuantity)),
+			strconv.Itoa(int(v.Variance)),
+			strconv.FormatBool(v.Approved),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		slog.Error("Could not write variance report: ", slog.Any("err", err.Error()))
+	}
+}
+
+// RecordCounts stores counted quantities for SKUs in storage rooms covered by
+// the session. Recounting a line overwrites the previous count.
+func (h *Handlers) RecordCounts(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "RecordCounts")
+	defer span.End()
+
+	id, ok := parseCountSessionID(ctx)
+	if !ok {
+		return
+	}
+	var req recordCountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   tr(ctx, "Invalid count payload"),
+			"details": err.Error(),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.Int64("count_session.id", id),
+		attribute.Int("count_session.counted_lines", len(req.Lines)),
+		attribute.String("tenant.id", orgID),
+	)
+
+	tx, err := h.db.Begin(spanCtx)
+	if err != nil {
+		slog.Error("Failed to start transaction", slog.Any("err", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": tr(ctx, "Failed to start transaction"),
+		})
+		return
+	}
+	defer tx.Rollback(spanCtx) // This will be ignored if tx.Commit() succeeds
+
+	qtx := h.queries.WithTx(tx)
+
+	dbStart := time.Now()
+	session, err := qtx.GetCountSessionForUpdate(spanCtx, models.GetCountSessionForUpdateParams{
+		ID:    id,
+		OrgID: orgID,
+	})
+	h.recordDBOperation(spanCtx, "get", "count_session", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Count session not found"),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Got an error while getting count session: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to record counts"),
+		})
+		return
+	}
+	if session.Status != countStatusOpen {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": tr(ctx, "Count session is %s", session.Status),
+		})
+		return
+	}
+
+	units := h.unitConverter(qtx, orgID)
+	lines := make([]models.CountLine, 0, len(req.Lines))
+	for _, l := range req.Lines {
+		if session.StorageRoomID.Valid && l.StorageRoomID != session.StorageRoomID.Int32 {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": tr(ctx, "Storage room %d is not covered by this count", l.StorageRoomID),
+			})
+			return
+		}
+		counted, err := units.toBase(spanCtx, l.Sku, l.Unit, l.CountedQuantity)
+		if rejected, ok := unitRejected(err); ok {
+			respondUnitError(ctx, rejected)
+			return
+		}
+		if err != nil {
+			slog.Error("Could not look up item: ", slog.Any("err", err.Error()))
+			span.RecordError(err)
+			ctx.JSON(dbErrorStatus(err), gin.H{
+				"error": tr(ctx, "Failed to record counts"),
+			})
+			return
+		}
+
+		dbStart = time.Now()
+		room, err := qtx.GetStorageRoom(spanCtx, models.GetStorageRoomParams{
+			ID:    l.StorageRoomID,
+			OrgID: orgID,
+		})
+		h.recordDBOperation(spanCtx, "get", "storage_room", dbStart, err)
+		if errors.Is(err, pgx.ErrNoRows) || (err == nil && int64(room.WarehouseID) != session.WarehouseID) {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": tr(ctx, "Storage room %d is not covered by this count", l.StorageRoomID),
+			})
+			return
+		}
+
+		var line models.CountLine
+		if err == nil {
+			dbStart = time.Now()
+			line, err = qtx.RecordCountLine(spanCtx, models.RecordCountLineParams{
+				CountSessionID:  session.ID,
+				StorageRoomID:   l.StorageRoomID,
+				Sku:             l.Sku,
+				CountedQuantity: pgtype.Int4{Int32: counted, Valid: true},
+			})
+			h.recordDBOperation(spanCtx, "upsert", "count_line", dbStart, err)
+		}
+		if err != nil {
+			slog.Error("Could not record count: ", slog.Any("err", err.Error()))
+			span.RecordError(err)
+			ctx.JSON(dbErrorStatus(err), gin.H{
+				"error": tr(ctx, "Failed to record counts"),
+			})
+			return
+		}
+		lines = append(lines, line)
+	}
+
+	if err := tx.Commit(spanCtx); err != nil {
+		slog.Error("Failed to commit transaction", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to commit transaction"),
+		})
+		return
+	}
+
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Record Counts Successfully"),
+		"data": gin.H{
+			"lines":     mapSlice(lines, newCountLineResponse),
+			"variances": countVariances(lines),
+		},
+	})
+}
+
+// PostCountSession approves variances and posts them as stock adjustments.
+// All adjustments and the session status change commit in one transaction.
+func (h *Handlers) PostCountSession(ctx *gin.Context) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "PostCountSession")
+	defer span.End()
+
+	id, ok := parseCountSessionID(ctx)
+	if !ok {
+		return
+	}
+	// The body is optional, posting without one approves every variance
+	var req postCountRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error":   tr(ctx, "Invalid post payload"),
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.Int64("count_session.id", id),
+		attribute.String("tenant.id", orgID),
+	)
+
+	tx, err := h.db.Begin(spanCtx)
+	if err != nil {
+		slog.Error("Failed to start transaction", slog.Any("err", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": tr(ctx, "Failed to start transaction"),
+		})
+		return
+	}
+	defer tx.Rollback(spanCtx) // This will be ignored if tx.Commit() succeeds
+
+	qtx := h.queries.WithTx(tx)
+
+	dbStart := time.Now()
+	session, err := qtx.GetCountSessionForUpdate(spanCtx, models.GetCountSessionForUpdateParams{
+		ID:    id,
+		OrgID: orgID,
+	})
+	h.recordDBOperation(spanCtx, "get", "count_session", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Count session not found"),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Got an error while getting count session: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to post count session"),
+		})
+		return
+	}
+	if session.Status != countStatusOpen {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": tr(ctx, "Count session is %s", session.Status),
+		})
+		return
+	}
+
+	dbStart = time.Now()
+	lines, err := qtx.ListCountLines(spanCtx, session.ID)
+	h.recordDBOperation(spanCtx, "list", "count_line", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while listing count lines: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to post count session"),
+		})
+		return
+	}
+
+	variances := countVariances(lines)
+	approved := variances
+	if len(req.LineIDs) > 0 {
+		byID := make(map[int64]countVariance, len(variances))
+		for _, v := range variances {
+			byID[v.LineID] = v
+		}
+		approved = make([]countVariance, 0, len(req.LineIDs))
+		for _, lineID := range req.LineIDs {
+			v, ok := byID[lineID]
+			if !ok {
+				ctx.JSON(http.StatusBadRequest, gin.H{
+					"error": tr(ctx, "Line %d has no counted variance in this session", lineID),
+				})
+				return
+			}
+			approved = append(approved, v)
+		}
+	}
+
+	reference := fmt.Sprintf("count_session:%d", session.ID)
+	for i, v := range approved {
+		dbStart = time.Now()
+		err := qtx.ApproveCountLine(spanCtx, models.ApproveCountLineParams{
+			ID:             v.LineID,
+			CountSessionID: session.ID,
+		})
+		h.recordDBOperation(spanCtx, "update", "count_line", dbStart, err)
+		if err == nil {
+			_, err = h.adjustStock(spanCtx, qtx, stockAdjustment{
+				OrgID:         orgID,
+				StorageRoomID: v.StorageRoomID,
+				Sku:           v.Sku,
+				Delta:         v.Variance,
+				Reason:        adjustmentReasonCycleCount,
+				Reference:     reference,
+				// The counted stock is already in the room
+				OverrideCapacity: true,
+			})
+		}
+		if isCheckViolation(err) {
+			// Stock moved out since the snapshot, the negative variance no longer fits
+			ctx.JSON(http.StatusConflict, gin.H{
+				"error": tr(ctx, "Line %d would drive stock below zero, recount it", v.LineID),
+			})
+			return
+		}
+		if err != nil {
+			slog.Error("Could not post count adjustment: ", slog.Any("err", err.Error()))
+			span.RecordError(err)
+			ctx.JSON(dbErrorStatus(err), gin.H{
+				"error": tr(ctx, "Failed to post count session"),
+			})
+			return
+		}
+		approved[i].Approved = true
+	}
+
+	dbStart = time.Now()
+	posted, err := qtx.UpdateCountSessionStatus(spanCtx, models.UpdateCountSessionStatusParams{
+		ID:     session.ID,
+		OrgID:  orgID,
+		Status: countStatusPosted,
+	})
+	h.recordDBOperation(spanCtx, "update", "count_session", dbStart, err)
+	if err == nil {
+		err = h.recordAudit(spanCtx, qtx, auditEntry{
+			OrgID:      orgID,
+			EntityType: auditEntityCountSession,
+			EntityID:   session.ID,
+			Action:     "post",
+			FromStatus: session.Status,
+			ToStatus:   posted.Status,
+			Actor:      actorID(ctx),
+		})
+	}
+	if err != nil {
+		slog.Error("Could not update count session status: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to post count session"),
+		})
+		return
+	}
+
+	if err := tx.Commit(spanCtx); err != nil {
+		slog.Error("Failed to commit transaction", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to commit transaction"),
+		})
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntityCountSession, "post", opStart, nil)
+
+	span.SetAttributes(
+		attribute.Int("count_session.adjustments", len(approved)),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Post Count Session Successfully"),
+		"data": gin.H{
+			"session":     newCountSessionResponse(posted),
+			"adjustments": approved,
+		},
+	})
+}
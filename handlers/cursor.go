@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var errInvalidCursor = errors.New("cursor is invalid")
+
+// pageCursor marks the last row of a keyset page. Clients get it as an
+// opaque string and send it back to fetch the next page.
+type pageCursor struct {
+	CreatedAt time.Time
+	ID        int64
+}
+
+func encodeCursor(c pageCursor) string {
+	var nanos int64
+	if !c.CreatedAt.IsZero() {
+		nanos = c.CreatedAt.UnixNano()
+	}
+	raw := fmt.Sprintf("%d:%d", nanos, c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(s string) (pageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return pageCursor{}, errInvalidCursor
+	}
+	nanos, id, found := strings.Cut(string(raw), ":")
+	if !found {
+		return pageCursor{}, errInvalidCursor
+	}
+	unixNano, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return pageCursor{}, errInvalidCursor
+	}
+	cursorID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return pageCursor{}, errInvalidCursor
+	}
+	return pageCursor{CreatedAt: time.Unix(0, unixNano).UTC(), ID: cursorID}, nil
+}
+
+// cursorParams reads the ?cursor= and ?limit= query parameters. The returned
+// cursor is nil on the first page.
+func cursorParams(ctx *gin.Context) (*pageCursor, int32, error) {
+	limit, err := limitParam(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	raw := ctx.Query("cursor")
+	if raw == "" {
+		return nil, limit, nil
+	}
+	cursor, err := decodeCursor(raw)
+	if err != nil {
+		return nil, 0, err
+	}
+	return &cursor, limit, nil
+}
+
+// before returns the keyset bound for a descending (created_at, id) query
+func (c *pageCursor) before() (pgtype.Timestamptz, int64) {
+	if c == nil {
+		return pgtype.Timestamptz{}, 0
+	}
+	return pgtype.Timestamptz{Time: c.CreatedAt, Valid: true}, c.ID
+}
+
+// nextCursor returns the cursor for the page after rows, or nil when rows is
+// the last page. Queries fetch limit+1 rows so a full page can tell whether
+// anything follows it; the extra row is dropped here.
+func nextCursor[T any](rows []T, limit int32, position func(T) pageCursor) ([]T, *string) {
+	if int32(len(rows)) <= limit {
+		return rows, nil
+	}
+	rows = rows[:limit]
+	next := encodeCursor(position(rows[len(rows)-1]))
+	return rows, &next
+}
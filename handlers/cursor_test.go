@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	want := pageCursor{CreatedAt: time.Date(2024, 3, 1, 9, 30, 0, 123456789, time.UTC), ID: 42}
+	got, err := decodeCursor(encodeCursor(want))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !got.CreatedAt.Equal(want.CreatedAt) || got.ID != want.ID {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if _, err := decodeCursor("not a cursor"); err == nil {
+		t.Error("expected an error for a malformed cursor")
+	}
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+	models "warehouse-service/models/sqlc"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const dashboardStatsView = "dashboard_stats"
+
+// errRefreshRunning is returned while another instance refreshes the view
+var errRefreshRunning = errors.New("a refresh of the view is already running")
+
+// DashboardStatsResponse holds the headline numbers of a tenant as of
+// RefreshedAt, when the materialized view was last refreshed
+type DashboardStatsResponse struct {
+	Warehouses          int64      `json:"warehouses" v1:"Warehouses"`
+	ActiveWarehouses    int64      `json:"active_warehouses" v1:"ActiveWarehouses"`
+	StorageRooms        int64      `json:"storage_rooms" v1:"StorageRooms"`
+	Skus                int64      `json:"skus" v1:"Skus"`
+	OnHandQuantity      int64      `json:"on_hand_quantity" v1:"OnHandQuantity"`
+	AllocatedQuantity   int64      `json:"allocated_quantity" v1:"AllocatedQuantity"`
+	OpenReceipts        int64      `json:"open_receipts" v1:"OpenReceipts"`
+	OpenPickLists       int64      `json:"open_pick_lists" v1:"OpenPickLists"`
+	MovementsLast30Days int64      `json:"movements_last_30_days" v1:"MovementsLast30Days"`
+	RefreshedAt         *time.Time `json:"refreshed_at" v1:"RefreshedAt"`
+}
+
+type ViewRefreshResponse struct {
+	Name        string     `json:"name" v1:"Name"`
+	RefreshedAt *time.Time `json:"refreshed_at" v1:"RefreshedAt"`
+	DurationMs  int64      `json:"duration_ms" v1:"DurationMs"`
+}
+
+func newDashboardStatsResponse(s models.DashboardStat, refresh models.MaterializedViewRefresh) DashboardStatsResponse {
+	return DashboardStatsResponse{
+		Warehouses:          s.Warehouses,
+		ActiveWarehouses:    s.ActiveWarehouses,
+		StorageRooms:        s.StorageRooms,
+		Skus:                s.Skus,
+		OnHandQuantity:      s.OnHandQuantity,
+		AllocatedQuantity:   s.AllocatedQuantity,
+		OpenReceipts:        s.OpenReceipts,
+		OpenPickLists:       s.OpenPickLists,
+		MovementsLast30Days: s.MovementsLast30Days,
+		RefreshedAt:         timePtr(refresh.RefreshedAt),
+	}
+}
+
+func newViewRefreshResponse(r models.MaterializedViewRefresh) ViewRefreshResponse {
+	return ViewRefreshResponse{
+		Name:        r.Name,
+		RefreshedAt: timePtr(r.RefreshedAt),
+		DurationMs:  r.DurationMs,
+	}
+}
+
+// refreshView rebuilds a materialized view with refresh without blocking
+// its readers. Instances share the schedule, so one that finds a refresh
+// running returns errRefreshRunning instead of queueing behind it.
+func (h *Handlers) refreshView(ctx context.Context, view string, refresh func(*models.Queries, context.Context) error) (models.MaterializedViewRefresh, error) {
+	var recorded models.MaterializedViewRefresh
+	err := pgx.BeginFunc(ctx, h.db, func(tx pgx.Tx) error {
+		qtx := h.queries.WithTx(tx)
+		locked, err := qtx.TryLockRefresh(ctx, "refresh:"+view)
+		if err != nil {
+			return err
+		}
+		if !locked {
+			return errRefreshRunning
+		}
+
+		dbStart := time.Now()
+		err = refresh(qtx, ctx)
+		h.recordDBOperation(ctx, "refresh", view, dbStart, err)
+		if err != nil {
+			return err
+		}
+		recorded, err = qtx.RecordViewRefresh(ctx, models.RecordViewRefreshParams{
+			Name:       view,
+			DurationMs: time.Since(dbStart).Milliseconds(),
+		})
+		return err
+	})
+	return recorded, err
+}
+
+// scheduledRefresh is refreshView run by the scheduler, a run skipped for
+// a refresh already running is not a failure
+func (h *Handlers) scheduledRefresh(ctx context.Context, span trace.Span, view string, refresh func(*models.Queries, context.Context) error) error {
+	recorded, err := h.refreshView(ctx, view, refresh)
+	if errors.Is(err, errRefreshRunning) {
+		span.SetAttributes(attribute.Bool("refresh.skipped", true))
+		return nil
+	}
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	span.SetAttributes(attribute.Int64("refresh.duration_ms", recorded.DurationMs))
+	return nil
+}
+
+func (h *Handlers) refreshDashboardStats(ctx context.Context) (models.MaterializedViewRefresh, error) {
+	return h.refreshView(ctx, dashboardStatsView, (*models.Queries).RefreshDashboardStats)
+}
+
+// RefreshDashboardStats is the scheduled refresh of the dashboard view
+func (h *Handlers) RefreshDashboardStats(ctx context.Context) error {
+	spanCtx, span := h.tracer.Start(ctx, "RefreshDashboardStats")
+	defer span.End()
+
+	return h.scheduledRefresh(spanCtx, span, dashboardStatsView, (*models.Queries).RefreshDashboardStats)
+}
+
+// GetDashboardStats returns the tenant's headline numbers from the
+// dashboard view, which lags behind the data by up to one refresh interval.
+// A tenant the view does not know yet gets zeros.
+func (h *Handlers) GetDashboardStats(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "GetDashboardStats")
+	defer span.End()
+
+	orgID := tenantID(ctx)
+	span.SetAttributes(attribute.String("tenant.id", orgID))
+	queries := h.readQueries(spanCtx)
+
+	dbStart := time.Now()
+	stats, err := queries.GetDashboardStats(spanCtx, orgID)
+	h.recordDBOperation(spanCtx, "get", dashboardStatsView, dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		stats, err = models.DashboardStat{OrgID: orgID}, nil
+	}
+	var refresh models.MaterializedViewRefresh
+	if err == nil {
+		dbStart = time.Now()
+		refresh, err = queries.GetViewRefresh(spanCtx, dashboardStatsView)
+		h.recordDBOperation(spanCtx, "get", "materialized_view_refresh", dbStart, err)
+	}
+	if err != nil {
+		slog.Error("Got an error while getting dashboard stats: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to get dashboard stats"),
+		})
+		return
+	}
+
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Get Dashboard Stats Successfully"),
+		"data":    newDashboardStatsResponse(stats, refresh),
+	})
+}
+
+// RefreshDashboardStatsNow refreshes the dashboard view for every tenant
+// without waiting for the schedule, 409 while a refresh is running
+func (h *Handlers) RefreshDashboardStatsNow(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "RefreshDashboardStatsNow")
+	defer span.End()
+
+	refresh, err := h.refreshDashboardStats(spanCtx)
+	if errors.Is(err, errRefreshRunning) {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": tr(ctx, "Dashboard stats are being refreshed already"),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Got an error while refreshing dashboard stats: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to refresh dashboard stats"),
+		})
+		return
+	}
+	slog.Info("Refreshed dashboard stats",
+		slog.String("actor", actorID(ctx)),
+		slog.Int64("duration_ms", refresh.DurationMs))
+
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Refresh Dashboard Stats Successfully"),
+		"data":    newViewRefreshResponse(refresh),
+	})
+}
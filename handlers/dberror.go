@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"warehouse-service/observability"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// uniqueConflict is returned with a 409 when a unique constraint rejects a write
+type uniqueConflict struct {
+	field   string
+	message string
+}
+
+// uniqueConflicts maps unique constraints and indexes to client messages
+var uniqueConflicts = map[string]uniqueConflict{
+	"warehouse_org_name_key":            {"name", "A warehouse with this name already exists"},
+	"warehouse_org_slug_key":            {"slug", "A warehouse with this slug already exists"},
+	"storage_room_warehouse_number_key": {"number", "A storage room with this number already exists in the warehouse"},
+	"item_org_sku_key":                  {"sku", "An item with this SKU already exists"},
+	"serial_org_number_key":             {"serial_numbers", "A serial with this number already exists"},
+	"wave_pick_list_pkey":               {"pick_list_ids", "A pick list is already in a wave"},
+	"partner_org_kind_name_key":         {"name", "A partner with this name already exists"},
+	"printer_org_name_key":              {"name", "A printer with this name already exists"},
+	"partner_org_edi_key":               {"edi_id", "A partner with this EDI ID already exists"},
+	"edi_document_inbound_key":          {"control_number", "This document was already imported"},
+	"file_exchange_org_name_key":        {"name", "A file exchange with this name already exists"},
+	"integration_org_name_key":          {"name", "An integration with this name already exists"},
+	"tenant_deletion_pending_key":       {"mode", "A deletion of the tenant is already pending"},
+}
+
+// conflictFor reports the conflict behind a unique violation. Violations of
+// constraints missing from uniqueConflicts still get a generic message.
+func conflictFor(err error) (uniqueConflict, bool) {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != "23505" {
+		return uniqueConflict{}, false
+	}
+	if conflict, ok := uniqueConflicts[pgErr.ConstraintName]; ok {
+		return conflict, true
+	}
+	return uniqueConflict{message: "A resource with the same unique values already exists"}, true
+}
+
+// dbErrorStatus maps a database error to the HTTP status for the client.
+// Errors that can't be attributed to the request stay a 500.
+func dbErrorStatus(err error) int {
+	switch observability.ClassifyDBError(err) {
+	case observability.DBErrNotFound:
+		return http.StatusNotFound
+	case observability.DBErrUniqueViolation, observability.DBErrFKViolation, observability.DBErrCheckViolation:
+		return http.StatusConflict
+	case observability.DBErrTimeout:
+		return http.StatusGatewayTimeout
+	case observability.DBErrConnection:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// dbErrorCode is the v2 error code matching dbErrorStatus
+func dbErrorCode(err error) string {
+	switch dbErrorStatus(err) {
+	case http.StatusNotFound:
+		return errCodeNotFound
+	case http.StatusConflict:
+		return errCodeConflict
+	case http.StatusGatewayTimeout, http.StatusServiceUnavailable:
+		return errCodeUnavailable
+	default:
+		return errCodeInternal
+	}
+}
@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	"warehouse-service/listquery"
+	models "warehouse-service/models/sqlc"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const auditEntityDeadLetter = "dead_letter"
+
+// ListDeadLetters returns the tenant's failed deliveries with their
+// payloads, newest first. ?topic= limits them to one topic and
+// ?pending=true to those not replayed yet.
+// deadLetterListSpec sorts and filters the messages of ListDeadLetters
+var deadLetterListSpec = &listquery.Spec{
+	Table: "dead_letter",
+	Sort: map[string]string{
+		"id":        "id",
+		"topic":     "topic",
+		"attempts":  "attempts",
+		"failed_at": "failed_at",
+	},
+	Filters: map[string]listquery.Filter{
+		"topic":  {Column: "topic", Op: listquery.Eq, Parse: listquery.Text},
+		"source": {Column: "source", Op: listquery.Eq, Parse: listquery.Text},
+	},
+	Default: []listquery.Order{{Field: "id", Desc: true}},
+	Key:     "id",
+}
+
+func (h *Handlers) ListDeadLetters(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "ListDeadLetters")
+	defer span.End()
+
+	q, err := listParams(ctx, deadLetterListSpec)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	q.Where("org_id", listquery.Eq, orgID)
+	var pendingOnly bool
+	if v := ctx.Query("pending"); v != "" {
+		if pendingOnly, err = strconv.ParseBool(v); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": tr(ctx, "pending must be true or false"),
+			})
+			return
+		}
+	}
+	if pendingOnly {
+		q.Where("replayed_at", listquery.IsNull, true)
+	}
+	span.SetAttributes(
+		attribute.Bool("dead_letter.pending_only", pendingOnly),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	deadLetters, err := queryPage[models.DeadLetter](ctx, spanCtx, h.db, q)
+	h.recordDBOperation(spanCtx, "list", "dead_letter", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while listing dead letters: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to list dead letters"),
+		})
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int("dead_letter.count", len(deadLetters)),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "List Dead Letters Successfully"),
+		"data":    mapSlice(deadLetters, newDeadLetterResponse),
+	})
+}
+
+func (h *Handlers) GetDeadLetter(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "GetDeadLetter")
+	defer span.End()
+
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "Invalid dead letter ID format"),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.Int64("dead_letter.id", id),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	deadLetter, err := h.queries.GetDeadLetter(spanCtx, models.GetDeadLetterParams{
+		ID:    id,
+		OrgID: orgID,
+	})
+	h.recordDBOperation(spanCtx, "get", "dead_letter", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Dead letter not found"),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Got an error while getting dead letter: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to get dead letter"),
+		})
+		return
+	}
+
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Get Dead Letter Successfully"),
+		"data":    newDeadLetterResponse(deadLetter),
+	})
+}
+
+// ReplayDeadLetter puts a failed delivery back in the outbox under its
+// original message ID. Each dead letter is replayed at most once, a replay
+// that fails again becomes a new dead letter.
+func (h *Handlers) ReplayDeadLetter(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "ReplayDeadLetter")
+	defer span.End()
+
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "Invalid dead letter ID format"),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	actor := actorID(ctx)
+	span.SetAttributes(
+		attribute.Int64("dead_letter.id", id),
+		attribute.String("tenant.id", orgID),
+	)
+
+	tx, err := h.db.Begin(spanCtx)
+	if err != nil {
+		slog.Error("Failed to start transaction", slog.Any("err", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": tr(ctx, "Failed to start transaction"),
+		})
+		return
+	}
+	defer tx.Rollback(spanCtx) // This will be ignored if tx.Commit() succeeds
+	qtx := h.queries.WithTx(tx)
+
+	dbStart := time.Now()
+	deadLetter, err := qtx.MarkDeadLetterReplayed(spanCtx, models.MarkDeadLetterReplayedParams{
+		ID:         id,
+		OrgID:      orgID,
+		ReplayedBy: actor,
+	})
+	h.recordDBOperation(spanCtx, "update", "dead_letter", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Either missing or replayed already
+		dbStart = time.Now()
+		_, err = qtx.GetDeadLetter(spanCtx, models.GetDeadLetterParams{ID: id, OrgID: orgID})
+		h.recordDBOperation(spanCtx, "get", "dead_letter", dbStart, err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error": tr(ctx, "Dead letter not found"),
+			})
+			return
+		}
+		if err == nil {
+			ctx.JSON(http.StatusConflict, gin.H{
+				"error": tr(ctx, "Dead letter was already replayed"),
+			})
+			return
+		}
+	}
+	if err == nil {
+		dbStart = time.Now()
+		err = qtx.RequeueOutboxMessage(spanCtx, models.RequeueOutboxMessageParams{
+			ID:        deadLetter.SourceID,
+			OrgID:     deadLetter.OrgID,
+			Topic:     deadLetter.Topic,
+			Key:       deadLetter.Key,
+			Payload:   deadLetter.Payload,
+			CreatedAt: deadLetter.CreatedAt,
+		})
+		h.recordDBOperation(spanCtx, "create", "outbox", dbStart, err)
+	}
+	if err == nil {
+		err = h.recordAudit(spanCtx, qtx, auditEntry{
+			OrgID:      orgID,
+			EntityType: auditEntityDeadLetter,
+			EntityID:   id,
+			Action:     "replay",
+			Actor:      actor,
+		})
+	}
+	if err == nil {
+		err = tx.Commit(spanCtx)
+	}
+	if err != nil {
+		slog.Error("Got an error while replaying dead letter: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to replay dead letter"),
+		})
+		return
+	}
+
+	slog.Info("Replaying dead letter",
+		slog.Int64("dead_letter_id", id),
+		slog.Int64("outbox_id", deadLetter.SourceID),
+		slog.String("topic", deadLetter.Topic),
+		slog.String("tenant_id", orgID),
+		slog.String("actor", actor),
+	)
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	respondV1(ctx, http.StatusAccepted, gin.H{
+		"message": tr(ctx, "Replay Dead Letter Successfully"),
+		"data":    newDeadLetterResponse(deadLetter),
+	})
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// processStart approximates the start of the process for the uptime
+var processStart = time.Now()
+
+type RuntimeStatsResponse struct {
+	GoVersion  string            `json:"go_version" v1:"GoVersion"`
+	Uptime     string            `json:"uptime" v1:"Uptime"`
+	NumCPU     int               `json:"num_cpu" v1:"NumCPU"`
+	GOMAXPROCS int               `json:"gomaxprocs" v1:"GOMAXPROCS"`
+	Goroutines int               `json:"goroutines" v1:"Goroutines"`
+	Heap       HeapStatsResponse `json:"heap" v1:"Heap"`
+	GC         GCStatsResponse   `json:"gc" v1:"GC"`
+	Build      BuildInfoResponse `json:"build" v1:"Build"`
+}
+
+type HeapStatsResponse struct {
+	AllocBytes    uint64 `json:"alloc_bytes" v1:"AllocBytes"`
+	InuseBytes    uint64 `json:"inuse_bytes" v1:"InuseBytes"`
+	IdleBytes     uint64 `json:"idle_bytes" v1:"IdleBytes"`
+	ReleasedBytes uint64 `json:"released_bytes" v1:"ReleasedBytes"`
+	SysBytes      uint64 `json:"sys_bytes" v1:"SysBytes"`
+	Objects       uint64 `json:"objects" v1:"Objects"`
+	NextGCBytes   uint64 `json:"next_gc_bytes" v1:"NextGCBytes"`
+}
+
+// GCStatsResponse holds the collections so far. PauseQuantiles are the
+// minimum, 25th, 50th and 75th percentile and maximum of the recent pauses,
+// RecentPauses the last ones newest first.
+type GCStatsResponse struct {
+	NumGC          int64      `json:"num_gc" v1:"NumGC"`
+	LastGC         *time.Time `json:"last_gc" v1:"LastGC"`
+	PauseTotal     string     `json:"pause_total" v1:"PauseTotal"`
+	PauseQuantiles []string   `json:"pause_quantiles" v1:"PauseQuantiles"`
+	RecentPauses   []string   `json:"recent_pauses" v1:"RecentPauses"`
+}
+
+type BuildInfoResponse struct {
+	Path     string `json:"path" v1:"Path"`
+	Version  string `json:"version" v1:"Version"`
+	Revision string `json:"revision" v1:"Revision"`
+	Time     string `json:"time" v1:"Time"`
+	Modified bool   `json:"modified" v1:"Modified"`
+}
+
+// recentPauses caps RecentPauses
+const recentPauses = 10
+
+func readRuntimeStats(now time.Time) RuntimeStatsResponse {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	gc := debug.GCStats{PauseQuantiles: make([]time.Duration, 5)}
+	debug.ReadGCStats(&gc)
+
+	stats := RuntimeStatsResponse{
+		GoVersion:  runtime.Version(),
+		Uptime:     now.Sub(processStart).Round(time.Second).String(),
+		NumCPU:     runtime.NumCPU(),
+		GOMAXPROCS: runtime.GOMAXPROCS(0),
+		Goroutines: runtime.NumGoroutine(),
+		Heap: HeapStatsResponse{
+			AllocBytes:    mem.HeapAlloc,
+			InuseBytes:    mem.HeapInuse,
+			IdleBytes:     mem.HeapIdle,
+			ReleasedBytes: mem.HeapReleased,
+			SysBytes:      mem.HeapSys,
+			Objects:       mem.HeapObjects,
+			NextGCBytes:   mem.NextGC,
+		},
+		GC: GCStatsResponse{
+			NumGC:          gc.NumGC,
+			PauseTotal:     gc.PauseTotal.String(),
+			PauseQuantiles: []string{},
+			RecentPauses:   []string{},
+		},
+	}
+	if gc.NumGC > 0 {
+		stats.GC.LastGC = &gc.LastGC
+		stats.GC.PauseQuantiles = mapSlice(gc.PauseQuantiles, time.Duration.String)
+		stats.GC.RecentPauses = mapSlice(gc.Pause[:min(len(gc.Pause), recentPauses)], time.Duration.String)
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		stats.Build.Path = info.Main.Path
+		stats.Build.Version = info.Main.Version
+		for _, s := range info.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				stats.Build.Revision = s.Value
+			case "vcs.time":
+				stats.Build.Time = s.Value
+			case "vcs.modified":
+				stats.Build.Modified = s.Value == "true"
+			}
+		}
+	}
+	return stats
+}
+
+// GetRuntimeStats reports goroutines, heap, garbage collection and build of
+// the instance serving the request
+func (h *Handlers) GetRuntimeStats(ctx *gin.Context) {
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Get Runtime Stats Successfully"),
+		"data":    readRuntimeStats(time.Now()),
+	})
+}
+
+// Pprof serves the net/http/pprof endpoints below /admin/debug/pprof.
+// pprof.Index only finds named profiles under its default /debug/pprof/
+// path, so they are looked up here.
+func (h *Handlers) Pprof(ctx *gin.Context) {
+	w, r := ctx.Writer, ctx.Request
+	switch name := strings.TrimPrefix(ctx.Param("profile"), "/"); name {
+	case "":
+		pprof.Index(w, r)
+	case "cmdline":
+		pprof.Cmdline(w, r)
+	case "profile":
+		pprof.Profile(w, r)
+	case "symbol":
+		pprof.Symbol(w, r)
+	case "trace":
+		pprof.Trace(w, r)
+	default:
+		pprof.Handler(name).ServeHTTP(w, r)
+	}
+}
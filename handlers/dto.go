@@ -0,0 +1,1291 @@
+package handlers
+
+import (
+	"encoding/json"
+	"time"
+	"warehouse-service/integrations"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/scheduler"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Response DTOs pin the JSON contract of the API so schema changes in the
+// sqlc models don't leak to clients. Fields are named in snake_case, the v1
+// types keep the names v1 has always returned in v1 tags, see respondV1;
+// tenant ownership (OrgID) is never serialized.
+
+type WarehouseResponse struct {
+	ID        int64    `json:"id" v1:"ID"`
+	Name      string   `json:"name" v1:"Name"`
+	Address   string   `json:"address" v1:"Address"`
+	Ward      string   `json:"ward" v1:"Ward"`
+	District  string   `json:"district" v1:"District"`
+	City      string   `json:"city" v1:"City"`
+	Country   string   `json:"country" v1:"Country"`
+	Latitude  *float64 `json:"latitude" v1:"Latitude"`
+	Longitude *float64 `json:"longitude" v1:"Longitude"`
+
+	TimeZone       string         `json:"time_zone" v1:"TimeZone"`
+	OperatingHours OperatingHours `json:"operating_hours" v1:"OperatingHours"`
+	ContactEmail   *string        `json:"contact_email" v1:"ContactEmail"`
+	ContactPhone   *string        `json:"contact_phone" v1:"ContactPhone"`
+	Tags           []string       `json:"tags" v1:"Tags"`
+
+	Attributes json.RawMessage `json:"attributes" v1:"Attributes"`
+	Status     string          `json:"status" v1:"Status"`
+	Slug       string          `json:"slug"`
+}
+
+// NearbyWarehouseResponse is a warehouse with its distance in meters from
+// the searched point
+type NearbyWarehouseResponse struct {
+	WarehouseResponse
+	Distance float64 `json:"distance" v1:"Distance"`
+}
+
+// DuplicateWarehouseResponse is an existing warehouse a new one looks like,
+// with the trigram similarities of the names and of the full addresses
+type DuplicateWarehouseResponse struct {
+	WarehouseResponse
+	NameSimilarity    float64 `json:"name_similarity" v1:"NameSimilarity"`
+	AddressSimilarity float64 `json:"address_similarity" v1:"AddressSimilarity"`
+}
+
+// AddressResponse is the postal address of a warehouse
+type AddressResponse struct {
+	Address  string `json:"address" v1:"Address"`
+	Ward     string `json:"ward" v1:"Ward"`
+	District string `json:"district" v1:"District"`
+	City     string `json:"city" v1:"City"`
+	Country  string `json:"country" v1:"Country"`
+}
+
+// WarehouseAddressResponse is the address of a warehouse as it is stored,
+// normalized, and as it was last sent. Raw is null for warehouses whose
+// address wasn't written since addresses are normalized.
+type WarehouseAddressResponse struct {
+	Normalized  AddressResponse  `json:"normalized" v1:"Normalized"`
+	Raw         *AddressResponse `json:"raw" v1:"Raw"`
+	ValidatedAt *time.Time       `json:"validated_at" v1:"ValidatedAt"`
+}
+
+type StorageRoomResponse struct {
+	ID          int32           `json:"id" v1:"ID"`
+	Name        string          `json:"name" v1:"Name"`
+	Number      string          `json:"number" v1:"Number"`
+	WarehouseID int32           `json:"warehouse_id" v1:"WarehouseID"`
+	ZoneType    string          `json:"zone_type" v1:"ZoneType"`
+	Tags        []string        `json:"tags" v1:"Tags"`
+	Attributes  json.RawMessage `json:"attributes" v1:"Attributes"`
+	// Units of stock the room holds, 0 is unlimited
+	Capacity int32 `json:"capacity" v1:"Capacity"`
+	// Units of stock in the room, left out where it isn't looked up
+	Occupancy *int64 `json:"occupancy,omitempty" v1:"Occupancy"`
+	// Location on the pick path, 0 is unknown
+	Aisle int32 `json:"aisle" v1:"Aisle"`
+	Bay   int32 `json:"bay" v1:"Bay"`
+}
+
+type ItemResponse struct {
+	ID          int64              `json:"id" v1:"ID"`
+	Sku         string             `json:"sku" v1:"Sku"`
+	Description string             `json:"description" v1:"Description"`
+	BaseUnit    string             `json:"base_unit" v1:"BaseUnit"`
+	LengthCm    *float64           `json:"length_cm" v1:"LengthCm"`
+	WidthCm     *float64           `json:"width_cm" v1:"WidthCm"`
+	HeightCm    *float64           `json:"height_cm" v1:"HeightCm"`
+	WeightKg    *float64           `json:"weight_kg" v1:"WeightKg"`
+	Units       []ItemUnitResponse `json:"units" v1:"Units"`
+	CreatedAt   *time.Time         `json:"created_at" v1:"CreatedAt"`
+	UpdatedAt   *time.Time         `json:"updated_at" v1:"UpdatedAt"`
+}
+
+// ItemUnitResponse is a unit of measure of an item, Factor base units each
+type ItemUnitResponse struct {
+	Unit   string `json:"unit" v1:"Unit"`
+	Factor int32  `json:"factor" v1:"Factor"`
+}
+
+type SerialResponse struct {
+	ID           int64  `json:"id" v1:"ID"`
+	SerialNumber string `json:"serial_number" v1:"SerialNumber"`
+	Sku          string `json:"sku" v1:"Sku"`
+	// Storage room the serial is in, nil once shipped
+	StorageRoomID *int32     `json:"storage_room_id" v1:"StorageRoomID"`
+	Status        string     `json:"status" v1:"Status"`
+	CreatedAt     *time.Time `json:"created_at" v1:"CreatedAt"`
+	UpdatedAt     *time.Time `json:"updated_at" v1:"UpdatedAt"`
+}
+
+type SerialMovementResponse struct {
+	ID                int64      `json:"id" v1:"ID"`
+	Action            string     `json:"action" v1:"Action"`
+	FromStorageRoomID *int32     `json:"from_storage_room_id" v1:"FromStorageRoomID"`
+	ToStorageRoomID   *int32     `json:"to_storage_room_id" v1:"ToStorageRoomID"`
+	Reference         string     `json:"reference" v1:"Reference"`
+	Actor             string     `json:"actor" v1:"Actor"`
+	CreatedAt         *time.Time `json:"created_at" v1:"CreatedAt"`
+}
+
+type TemperatureBreachResponse struct {
+	ID            int64      `json:"id" v1:"ID"`
+	StorageRoomID int32      `json:"storage_room_id" v1:"StorageRoomID"`
+	ZoneType      string     `json:"zone_type" v1:"ZoneType"`
+	MinCelsius    float64    `json:"min_celsius" v1:"MinCelsius"`
+	MaxCelsius    float64    `json:"max_celsius" v1:"MaxCelsius"`
+	PeakCelsius   float64    `json:"peak_celsius" v1:"PeakCelsius"`
+	StartedAt     *time.Time `json:"started_at" v1:"StartedAt"`
+	ResolvedAt    *time.Time `json:"resolved_at" v1:"ResolvedAt"`
+}
+
+type StockLevelResponse struct {
+	ID                int64      `json:"id" v1:"ID"`
+	StorageRoomID     int32      `json:"storage_room_id" v1:"StorageRoomID"`
+	Sku               string     `json:"sku" v1:"Sku"`
+	Quantity          int32      `json:"quantity" v1:"Quantity"`
+	AllocatedQuantity int32      `json:"allocated_quantity" v1:"AllocatedQuantity"`
+	ReceivedAt        *time.Time `json:"received_at" v1:"ReceivedAt"`
+	ExpiresAt         *time.Time `json:"expires_at" v1:"ExpiresAt"`
+	UpdatedAt         *time.Time `json:"updated_at" v1:"UpdatedAt"`
+}
+
+type StockAdjustmentResponse struct {
+	ID            int64      `json:"id" v1:"ID"`
+	StorageRoomID int32      `json:"storage_room_id" v1:"StorageRoomID"`
+	Sku           string     `json:"sku" v1:"Sku"`
+	QuantityDelta int32      `json:"quantity_delta" v1:"QuantityDelta"`
+	Reason        string     `json:"reason" v1:"Reason"`
+	Reference     string     `json:"reference" v1:"Reference"`
+	CreatedAt     *time.Time `json:"created_at" v1:"CreatedAt"`
+}
+
+type ReceiptResponse struct {
+	ID          int64      `json:"id" v1:"ID"`
+	WarehouseID int64      `json:"warehouse_id" v1:"WarehouseID"`
+	SupplierID  *int64     `json:"supplier_id" v1:"SupplierID"`
+	Reference   string     `json:"reference" v1:"Reference"`
+	Status      string     `json:"status" v1:"Status"`
+	CreatedAt   *time.Time `json:"created_at" v1:"CreatedAt"`
+	UpdatedAt   *time.Time `json:"updated_at" v1:"UpdatedAt"`
+}
+
+type ReceiptLineResponse struct {
+	ID               int64  `json:"id" v1:"ID"`
+	ReceiptID        int64  `json:"receipt_id" v1:"ReceiptID"`
+	Sku              string `json:"sku" v1:"Sku"`
+	ExpectedQuantity int32  `json:"expected_quantity" v1:"ExpectedQuantity"`
+	ReceivedQuantity int32  `json:"received_quantity" v1:"ReceivedQuantity"`
+	// Earliest expiry of the stock received on the line, null for none
+	ExpiresAt     *time.Time `json:"expires_at" v1:"ExpiresAt"`
+	UnitCostCents *int64     `json:"unit_cost_cents" v1:"UnitCostCents"`
+}
+
+type PickListResponse struct {
+	ID          int64      `json:"id" v1:"ID"`
+	WarehouseID int64      `json:"warehouse_id" v1:"WarehouseID"`
+	CarrierID   *int64     `json:"carrier_id" v1:"CarrierID"`
+	Reference   string     `json:"reference" v1:"Reference"`
+	Strategy    string     `json:"strategy" v1:"Strategy"`
+	Status      string     `json:"status" v1:"Status"`
+	CreatedAt   *time.Time `json:"created_at" v1:"CreatedAt"`
+	UpdatedAt   *time.Time `json:"updated_at" v1:"UpdatedAt"`
+}
+
+type PickListLineResponse struct {
+	ID             int64  `json:"id" v1:"ID"`
+	PickListID     int64  `json:"pick_list_id" v1:"PickListID"`
+	Sku            string `json:"sku" v1:"Sku"`
+	StorageRoomID  int32  `json:"storage_room_id" v1:"StorageRoomID"`
+	Quantity       int32  `json:"quantity" v1:"Quantity"`
+	PickedQuantity int32  `json:"picked_quantity" v1:"PickedQuantity"`
+}
+
+type WaveResponse struct {
+	ID          int64      `json:"id" v1:"ID"`
+	WarehouseID int64      `json:"warehouse_id" v1:"WarehouseID"`
+	Reference   string     `json:"reference" v1:"Reference"`
+	CreatedAt   *time.Time `json:"created_at" v1:"CreatedAt"`
+}
+
+// WaveStopResponse is a storage room on the pick path of a wave with the
+// picks to make there
+type WaveStopResponse struct {
+	Sequence      int                `json:"sequence" v1:"Sequence"`
+	StorageRoomID int32              `json:"storage_room_id" v1:"StorageRoomID"`
+	Number        string             `json:"number" v1:"Number"`
+	ZoneType      string             `json:"zone_type" v1:"ZoneType"`
+	Aisle         int32              `json:"aisle" v1:"Aisle"`
+	Bay           int32              `json:"bay" v1:"Bay"`
+	Picks         []WavePickResponse `json:"picks" v1:"Picks"`
+}
+
+// WavePickResponse is a pick list line with the quantity still to pick
+type WavePickResponse struct {
+	PickListID int64  `json:"pick_list_id" v1:"PickListID"`
+	LineID     int64  `json:"line_id" v1:"LineID"`
+	Sku        string `json:"sku" v1:"Sku"`
+	Quantity   int32  `json:"quantity" v1:"Quantity"`
+}
+
+// PartnerResponse is a supplier or carrier
+type PartnerResponse struct {
+	ID           int64      `json:"id" v1:"ID"`
+	Name         string     `json:"name" v1:"Name"`
+	ContactName  string     `json:"contact_name" v1:"ContactName"`
+	Email        string     `json:"email" v1:"Email"`
+	Phone        string     `json:"phone" v1:"Phone"`
+	LeadTimeDays int32      `json:"lead_time_days" v1:"LeadTimeDays"`
+	EdiQualifier string     `json:"edi_qualifier" v1:"EdiQualifier"`
+	EdiID        string     `json:"edi_id" v1:"EdiID"`
+	SftpURL      string     `json:"sftp_url" v1:"SftpURL"`
+	SftpHostKey  string     `json:"sftp_host_key" v1:"SftpHostKey"`
+	CreatedAt    *time.Time `json:"created_at" v1:"CreatedAt"`
+	UpdatedAt    *time.Time `json:"updated_at" v1:"UpdatedAt"`
+}
+
+// EdiDocumentResponse is an EDI document imported or exported
+type EdiDocumentResponse struct {
+	ID                 int64      `json:"id" v1:"ID"`
+	PartnerID          *int64     `json:"partner_id" v1:"PartnerID"`
+	Direction          string     `json:"direction" v1:"Direction"`
+	DocumentType       string     `json:"document_type" v1:"DocumentType"`
+	SenderID           string     `json:"sender_id" v1:"SenderID"`
+	ControlNumber      string     `json:"control_number" v1:"ControlNumber"`
+	TransactionControl string     `json:"transaction_control" v1:"TransactionControl"`
+	ReceiptID          *int64     `json:"receipt_id" v1:"ReceiptID"`
+	Status             string     `json:"status" v1:"Status"`
+	Error              string     `json:"error" v1:"Error"`
+	CreatedAt          *time.Time `json:"created_at" v1:"CreatedAt"`
+	UpdatedAt          *time.Time `json:"updated_at" v1:"UpdatedAt"`
+}
+
+type FileExchangeResponse struct {
+	ID            int64      `json:"id" v1:"ID"`
+	Name          string     `json:"name" v1:"Name"`
+	InboundURL    string     `json:"inbound_url" v1:"InboundURL"`
+	OutboundURL   string     `json:"outbound_url" v1:"OutboundURL"`
+	HostKey       string     `json:"host_key" v1:"HostKey"`
+	WarehouseID   int64      `json:"warehouse_id" v1:"WarehouseID"`
+	SupplierID    *int64     `json:"supplier_id" v1:"SupplierID"`
+	ExtractFormat string     `json:"extract_format" v1:"ExtractFormat"`
+	Enabled       bool       `json:"enabled" v1:"Enabled"`
+	CreatedAt     *time.Time `json:"created_at" v1:"CreatedAt"`
+	UpdatedAt     *time.Time `json:"updated_at" v1:"UpdatedAt"`
+}
+
+type FileExchangeRunResponse struct {
+	ID             int64      `json:"id" v1:"ID"`
+	ExchangeID     int64      `json:"exchange_id" v1:"ExchangeID"`
+	Trigger        string     `json:"trigger" v1:"Trigger"`
+	Status         string     `json:"status" v1:"Status"`
+	FilesProcessed int32      `json:"files_processed" v1:"FilesProcessed"`
+	FilesFailed    int32      `json:"files_failed" v1:"FilesFailed"`
+	Error          string     `json:"error" v1:"Error"`
+	StartedAt      *time.Time `json:"started_at" v1:"StartedAt"`
+	FinishedAt     *time.Time `json:"finished_at" v1:"FinishedAt"`
+}
+
+type FileExchangeFileResponse struct {
+	ID         int64      `json:"id" v1:"ID"`
+	ExchangeID int64      `json:"exchange_id" v1:"ExchangeID"`
+	RunID      int64      `json:"run_id" v1:"RunID"`
+	Direction  string     `json:"direction" v1:"Direction"`
+	Name       string     `json:"name" v1:"Name"`
+	Format     string     `json:"format" v1:"Format"`
+	Sha256     string     `json:"sha256" v1:"Sha256"`
+	Size       int32      `json:"size" v1:"Size"`
+	Status     string     `json:"status" v1:"Status"`
+	Error      string     `json:"error" v1:"Error"`
+	CreatedAt  *time.Time `json:"created_at" v1:"CreatedAt"`
+	UpdatedAt  *time.Time `json:"updated_at" v1:"UpdatedAt"`
+}
+
+type IntegrationResponse struct {
+	ID         int64  `json:"id" v1:"ID"`
+	Name       string `json:"name" v1:"Name"`
+	Kind       string `json:"kind" v1:"Kind"`
+	URL        string `json:"url" v1:"URL"`
+	OrdersURL  string `json:"orders_url" v1:"OrdersURL"`
+	LocationID string `json:"location_id" v1:"LocationID"`
+	// The token is never returned, only whether there is one
+	HasToken       bool       `json:"has_token" v1:"HasToken"`
+	WarehouseID    int64      `json:"warehouse_id" v1:"WarehouseID"`
+	Enabled        bool       `json:"enabled" v1:"Enabled"`
+	OrdersSyncedAt *time.Time `json:"orders_synced_at" v1:"OrdersSyncedAt"`
+	LastSyncedAt   *time.Time `json:"last_synced_at" v1:"LastSyncedAt"`
+	Failures       int32      `json:"failures" v1:"Failures"`
+	LastError      string     `json:"last_error" v1:"LastError"`
+	NextSyncAt     *time.Time `json:"next_sync_at" v1:"NextSyncAt"`
+	CreatedAt      *time.Time `json:"created_at" v1:"CreatedAt"`
+	UpdatedAt      *time.Time `json:"updated_at" v1:"UpdatedAt"`
+}
+
+type IntegrationOrderResponse struct {
+	ID                int64                    `json:"id" v1:"ID"`
+	IntegrationID     int64                    `json:"integration_id" v1:"IntegrationID"`
+	ExternalID        string                   `json:"external_id" v1:"ExternalID"`
+	Reference         string                   `json:"reference" v1:"Reference"`
+	Lines             []integrations.OrderLine `json:"lines" v1:"Lines"`
+	Status            string                   `json:"status" v1:"Status"`
+	PickListID        *int64                   `json:"pick_list_id" v1:"PickListID"`
+	Error             string                   `json:"error" v1:"Error"`
+	ExternalUpdatedAt *time.Time               `json:"external_updated_at" v1:"ExternalUpdatedAt"`
+	CreatedAt         *time.Time               `json:"created_at" v1:"CreatedAt"`
+	UpdatedAt         *time.Time               `json:"updated_at" v1:"UpdatedAt"`
+}
+
+type SagaResponse struct {
+	ID         int64  `json:"id" v1:"ID"`
+	Kind       string `json:"kind" v1:"Kind"`
+	PickListID int64  `json:"pick_list_id" v1:"PickListID"`
+	Status     string `json:"status" v1:"Status"`
+	Error      string `json:"error" v1:"Error"`
+	// Left out of lists
+	Steps     []SagaStepResponse `json:"steps,omitempty" v1:"Steps"`
+	CreatedAt *time.Time         `json:"created_at" v1:"CreatedAt"`
+	UpdatedAt *time.Time         `json:"updated_at" v1:"UpdatedAt"`
+}
+
+type SagaStepResponse struct {
+	Position  int32      `json:"position" v1:"Position"`
+	Name      string     `json:"name" v1:"Name"`
+	Status    string     `json:"status" v1:"Status"`
+	Attempts  int32      `json:"attempts" v1:"Attempts"`
+	Error     string     `json:"error" v1:"Error"`
+	UpdatedAt *time.Time `json:"updated_at" v1:"UpdatedAt"`
+}
+
+// TenantExportResponse is an export archive of the tenant's data, Tables
+// counts its rows by table once it completed
+type TenantExportResponse struct {
+	ID          int64           `json:"id" v1:"ID"`
+	Format      string          `json:"format" v1:"Format"`
+	Status      string          `json:"status" v1:"Status"`
+	SizeBytes   int64           `json:"size_bytes" v1:"SizeBytes"`
+	Checksum    string          `json:"checksum" v1:"Checksum"`
+	Tables      json.RawMessage `json:"tables" v1:"Tables"`
+	Error       string          `json:"error" v1:"Error"`
+	RequestedBy string          `json:"requested_by" v1:"RequestedBy"`
+	// Only on a completed export read by ID
+	DownloadURL string     `json:"download_url,omitempty" v1:"DownloadURL"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty" v1:"ExpiresAt"`
+	CreatedAt   *time.Time `json:"created_at" v1:"CreatedAt"`
+	CompletedAt *time.Time `json:"completed_at" v1:"CompletedAt"`
+}
+
+// TenantDeletionResponse is a deletion of the tenant's data, Report holds
+// the verification report once it ran
+type TenantDeletionResponse struct {
+	ID          int64           `json:"id" v1:"ID"`
+	Mode        string          `json:"mode" v1:"Mode"`
+	Status      string          `json:"status" v1:"Status"`
+	RunAfter    *time.Time      `json:"run_after" v1:"RunAfter"`
+	Pseudonym   string          `json:"pseudonym" v1:"Pseudonym"`
+	Report      json.RawMessage `json:"report" v1:"Report"`
+	Error       string          `json:"error" v1:"Error"`
+	RequestedBy string          `json:"requested_by" v1:"RequestedBy"`
+	CreatedAt   *time.Time      `json:"created_at" v1:"CreatedAt"`
+	CompletedAt *time.Time      `json:"completed_at" v1:"CompletedAt"`
+}
+
+type PrinterResponse struct {
+	ID        int64      `json:"id" v1:"ID"`
+	Name      string     `json:"name" v1:"Name"`
+	Address   string     `json:"address" v1:"Address"`
+	Dpi       int32      `json:"dpi" v1:"Dpi"`
+	CreatedAt *time.Time `json:"created_at" v1:"CreatedAt"`
+	UpdatedAt *time.Time `json:"updated_at" v1:"UpdatedAt"`
+}
+
+type TransferOrderResponse struct {
+	ID                     int64      `json:"id" v1:"ID"`
+	SourceWarehouseID      int64      `json:"source_warehouse_id" v1:"SourceWarehouseID"`
+	DestinationWarehouseID int64      `json:"destination_warehouse_id" v1:"DestinationWarehouseID"`
+	Reference              string     `json:"reference" v1:"Reference"`
+	Status                 string     `json:"status" v1:"Status"`
+	CreatedAt              *time.Time `json:"created_at" v1:"CreatedAt"`
+	UpdatedAt              *time.Time `json:"updated_at" v1:"UpdatedAt"`
+	ShippedAt              *time.Time `json:"shipped_at" v1:"ShippedAt"`
+	ReceivedAt             *time.Time `json:"received_at" v1:"ReceivedAt"`
+}
+
+// TransferOrderLineResponse is a line of a transfer order. InTransit is
+// shipped but not yet received.
+type TransferOrderLineResponse struct {
+	ID                  int64  `json:"id" v1:"ID"`
+	TransferOrderID     int64  `json:"transfer_order_id" v1:"TransferOrderID"`
+	Sku                 string `json:"sku" v1:"Sku"`
+	Quantity            int32  `json:"quantity" v1:"Quantity"`
+	SourceStorageRoomID *int32 `json:"source_storage_room_id" v1:"SourceStorageRoomID"`
+	ShippedQuantity     int32  `json:"shipped_quantity" v1:"ShippedQuantity"`
+	ReceivedQuantity    int32  `json:"received_quantity" v1:"ReceivedQuantity"`
+	InTransit           int32  `json:"in_transit" v1:"InTransit"`
+}
+
+type InTransitStockResponse struct {
+	DestinationWarehouseID int64  `json:"destination_warehouse_id" v1:"DestinationWarehouseID"`
+	Sku                    string `json:"sku" v1:"Sku"`
+	Quantity               int64  `json:"quantity" v1:"Quantity"`
+}
+
+type AuditLogResponse struct {
+	ID         int64      `json:"id" v1:"ID"`
+	EntityType string     `json:"entity_type" v1:"EntityType"`
+	EntityID   int64      `json:"entity_id" v1:"EntityID"`
+	Action     string     `json:"action" v1:"Action"`
+	FromStatus string     `json:"from_status" v1:"FromStatus"`
+	ToStatus   string     `json:"to_status" v1:"ToStatus"`
+	Actor      string     `json:"actor" v1:"Actor"`
+	CreatedAt  *time.Time `json:"created_at" v1:"CreatedAt"`
+}
+
+type AttachmentResponse struct {
+	ID          int64      `json:"id" v1:"ID"`
+	WarehouseID int64      `json:"warehouse_id" v1:"WarehouseID"`
+	Kind        string     `json:"kind" v1:"Kind"`
+	FileName    string     `json:"file_name" v1:"FileName"`
+	ContentType string     `json:"content_type" v1:"ContentType"`
+	SizeBytes   int64      `json:"size_bytes" v1:"SizeBytes"`
+	Checksum    string     `json:"checksum" v1:"Checksum"`
+	UploadedBy  string     `json:"uploaded_by" v1:"UploadedBy"`
+	CreatedAt   *time.Time `json:"created_at" v1:"CreatedAt"`
+}
+
+// AttachmentDownloadResponse is an attachment with a presigned URL that
+// downloads it until ExpiresAt
+type AttachmentDownloadResponse struct {
+	AttachmentResponse
+	DownloadURL string    `json:"download_url" v1:"DownloadURL"`
+	ExpiresAt   time.Time `json:"expires_at" v1:"ExpiresAt"`
+}
+
+type AttributeSchemaResponse struct {
+	EntityType string          `json:"entity_type" v1:"EntityType"`
+	Schema     json.RawMessage `json:"schema" v1:"Schema"`
+	UpdatedBy  string          `json:"updated_by" v1:"UpdatedBy"`
+	UpdatedAt  *time.Time      `json:"updated_at" v1:"UpdatedAt"`
+}
+
+type CountSessionResponse struct {
+	ID            int64      `json:"id" v1:"ID"`
+	WarehouseID   int64      `json:"warehouse_id" v1:"WarehouseID"`
+	StorageRoomID *int32     `json:"storage_room_id" v1:"StorageRoomID"`
+	Status        string     `json:"status" v1:"Status"`
+	CreatedAt     *time.Time `json:"created_at" v1:"CreatedAt"`
+	UpdatedAt     *time.Time `json:"updated_at" v1:"UpdatedAt"`
+}
+
+type CountLineResponse struct {
+	ID              int64  `json:"id" v1:"ID"`
+	CountSessionID  int64  `json:"count_session_id" v1:"CountSessionID"`
+	StorageRoomID   int32  `json:"storage_room_id" v1:"StorageRoomID"`
+	Sku             string `json:"sku" v1:"Sku"`
+	BookQuantity    int32  `json:"book_quantity" v1:"BookQuantity"`
+	CountedQuantity *int32 `json:"counted_quantity" v1:"CountedQuantity"`
+	Approved        bool   `json:"approved" v1:"Approved"`
+}
+
+type DeadLetterResponse struct {
+	ID         int64           `json:"id" v1:"ID"`
+	Source     string          `json:"source" v1:"Source"`
+	SourceID   int64           `json:"source_id" v1:"SourceID"`
+	Topic      string          `json:"topic" v1:"Topic"`
+	Key        string          `json:"key" v1:"Key"`
+	Payload    json.RawMessage `json:"payload" v1:"Payload"`
+	Attempts   int32           `json:"attempts" v1:"Attempts"`
+	LastError  string          `json:"last_error" v1:"LastError"`
+	CreatedAt  *time.Time      `json:"created_at" v1:"CreatedAt"`
+	FailedAt   *time.Time      `json:"failed_at" v1:"FailedAt"`
+	ReplayedAt *time.Time      `json:"replayed_at" v1:"ReplayedAt"`
+	ReplayedBy string          `json:"replayed_by" v1:"ReplayedBy"`
+}
+
+type JobResponse struct {
+	ID          int64           `json:"id" v1:"ID"`
+	Kind        string          `json:"kind" v1:"Kind"`
+	Payload     json.RawMessage `json:"payload" v1:"Payload"`
+	Status      string          `json:"status" v1:"Status"`
+	Attempts    int32           `json:"attempts" v1:"Attempts"`
+	MaxAttempts int32           `json:"max_attempts" v1:"MaxAttempts"`
+	LastError   string          `json:"last_error" v1:"LastError"`
+	RunAt       *time.Time      `json:"run_at" v1:"RunAt"`
+	CreatedAt   *time.Time      `json:"created_at" v1:"CreatedAt"`
+	UpdatedAt   *time.Time      `json:"updated_at" v1:"UpdatedAt"`
+}
+
+type SearchResultResponse struct {
+	Kind        string  `json:"kind" v1:"Kind"`
+	ID          int64   `json:"id" v1:"ID"`
+	WarehouseID int64   `json:"warehouse_id" v1:"WarehouseID"`
+	Name        string  `json:"name" v1:"Name"`
+	Detail      string  `json:"detail" v1:"Detail"`
+	Rank        float32 `json:"rank" v1:"Rank"`
+}
+
+// WarehouseV2 is the v2 representation of a warehouse
+type WarehouseV2 struct {
+	ID        int64    `json:"id"`
+	Name      string   `json:"name"`
+	Address   string   `json:"address"`
+	Ward      string   `json:"ward"`
+	District  string   `json:"district"`
+	City      string   `json:"city"`
+	Country   string   `json:"country"`
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+
+	TimeZone       string         `json:"time_zone"`
+	OperatingHours OperatingHours `json:"operating_hours"`
+	ContactEmail   *string        `json:"contact_email"`
+	ContactPhone   *string        `json:"contact_phone"`
+	Tags           []string       `json:"tags"`
+
+	Attributes json.RawMessage `json:"attributes"`
+	Status     string          `json:"status"`
+	Slug       string          `json:"slug"`
+}
+
+// mapSlice converts every element of in with fn. A nil slice stays nil so
+// empty v1 lists keep serializing as null.
+func mapSlice[T, R any](in []T, fn func(T) R) []R {
+	if in == nil {
+		return nil
+	}
+	out := make([]R, 0, len(in))
+	for _, v := range in {
+		out = append(out, fn(v))
+	}
+	return out
+}
+
+func timePtr(t pgtype.Timestamptz) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+func int32Ptr(i pgtype.Int4) *int32 {
+	if !i.Valid {
+		return nil
+	}
+	return &i.Int32
+}
+
+func int64Ptr(i pgtype.Int8) *int64 {
+	if !i.Valid {
+		return nil
+	}
+	return &i.Int64
+}
+
+func newWarehouseResponse(w models.Warehouse) WarehouseResponse {
+	return WarehouseResponse{
+		ID:             w.ID,
+		Name:           w.Name,
+		Address:        w.Address,
+		Ward:           w.Ward,
+		District:       w.District,
+		City:           w.City,
+		Country:        w.Country,
+		Latitude:       floatPtr(w.Latitude),
+		Longitude:      floatPtr(w.Longitude),
+		TimeZone:       w.TimeZone,
+		OperatingHours: decodeOperatingHours(w.OperatingHours),
+		ContactEmail:   textPtr(w.ContactEmail),
+		ContactPhone:   textPtr(w.ContactPhone),
+		Tags:           tagsOrEmpty(w.Tags),
+		Attributes:     attributesOrEmpty(w.Attributes),
+		Status:         w.Status,
+		Slug:           w.Slug,
+	}
+}
+
+func newNearbyWarehouseResponse(w models.ListNearbyWarehousesRow) NearbyWarehouseResponse {
+	return NearbyWarehouseResponse{
+		WarehouseResponse: newWarehouseResponse(models.Warehouse{
+			ID:             w.ID,
+			Name:           w.Name,
+			Address:        w.Address,
+			Ward:           w.Ward,
+			District:       w.District,
+			City:           w.City,
+			Country:        w.Country,
+			OrgID:          w.OrgID,
+			Latitude:       w.Latitude,
+			Longitude:      w.Longitude,
+			TimeZone:       w.TimeZone,
+			OperatingHours: w.OperatingHours,
+			ContactEmail:   w.ContactEmail,
+			ContactPhone:   w.ContactPhone,
+			Tags:           w.Tags,
+			Attributes:     w.Attributes,
+			Status:         w.Status,
+			Slug:           w.Slug,
+		}),
+		Distance: w.Distance,
+	}
+}
+
+func newDuplicateWarehouseResponse(w models.FindDuplicateWarehousesRow) DuplicateWarehouseResponse {
+	return DuplicateWarehouseResponse{
+		WarehouseResponse: newWarehouseResponse(models.Warehouse{
+			ID:             w.ID,
+			Name:           w.Name,
+			Address:        w.Address,
+			Ward:           w.Ward,
+			District:       w.District,
+			City:           w.City,
+			Country:        w.Country,
+			OrgID:          w.OrgID,
+			Latitude:       w.Latitude,
+			Longitude:      w.Longitude,
+			TimeZone:       w.TimeZone,
+			OperatingHours: w.OperatingHours,
+			ContactEmail:   w.ContactEmail,
+			ContactPhone:   w.ContactPhone,
+			Tags:           w.Tags,
+			Attributes:     w.Attributes,
+			Status:         w.Status,
+			Slug:           w.Slug,
+		}),
+		NameSimilarity:    w.NameSimilarity,
+		AddressSimilarity: w.AddressSimilarity,
+	}
+}
+
+func newWarehouseAddressResponse(w models.Warehouse, raw *models.WarehouseAddress) WarehouseAddressResponse {
+	resp := WarehouseAddressResponse{
+		Normalized: AddressResponse{
+			Address:  w.Address,
+			Ward:     w.Ward,
+			District: w.District,
+			City:     w.City,
+			Country:  w.Country,
+		},
+	}
+	if raw != nil {
+		resp.Raw = &AddressResponse{
+			Address:  raw.Address,
+			Ward:     raw.Ward,
+			District: raw.District,
+			City:     raw.City,
+			Country:  raw.Country,
+		}
+		resp.ValidatedAt = timePtr(raw.ValidatedAt)
+	}
+	return resp
+}
+
+func newStorageRoomResponse(r models.StorageRoom) StorageRoomResponse {
+	return StorageRoomResponse{
+		ID:          r.ID,
+		Name:        r.Name,
+		Number:      r.Number,
+		WarehouseID: r.WarehouseID,
+		ZoneType:    r.ZoneType,
+		Tags:        tagsOrEmpty(r.Tags),
+		Attributes:  attributesOrEmpty(r.Attributes),
+		Capacity:    r.Capacity,
+		Aisle:       r.Aisle,
+		Bay:         r.Bay,
+	}
+}
+
+func newItemResponse(i models.Item, units []models.ItemUnit) ItemResponse {
+	r := ItemResponse{
+		ID:          i.ID,
+		Sku:         i.Sku,
+		Description: i.Description,
+		BaseUnit:    i.BaseUnit,
+		LengthCm:    floatPtr(i.LengthCm),
+		WidthCm:     floatPtr(i.WidthCm),
+		HeightCm:    floatPtr(i.HeightCm),
+		WeightKg:    floatPtr(i.WeightKg),
+		Units:       []ItemUnitResponse{},
+		CreatedAt:   timePtr(i.CreatedAt),
+		UpdatedAt:   timePtr(i.UpdatedAt),
+	}
+	for _, u := range units {
+		r.Units = append(r.Units, ItemUnitResponse{Unit: u.Unit, Factor: u.Factor})
+	}
+	return r
+}
+
+func newSerialResponse(s models.Serial) SerialResponse {
+	return SerialResponse{
+		ID:            s.ID,
+		SerialNumber:  s.SerialNumber,
+		Sku:           s.Sku,
+		StorageRoomID: int32Ptr(s.StorageRoomID),
+		Status:        s.Status,
+		CreatedAt:     timePtr(s.CreatedAt),
+		UpdatedAt:     timePtr(s.UpdatedAt),
+	}
+}
+
+func newSerialMovementResponse(m models.SerialMovement) SerialMovementResponse {
+	return SerialMovementResponse{
+		ID:                m.ID,
+		Action:            m.Action,
+		FromStorageRoomID: int32Ptr(m.FromStorageRoomID),
+		ToStorageRoomID:   int32Ptr(m.ToStorageRoomID),
+		Reference:         m.Reference,
+		Actor:             m.Actor,
+		CreatedAt:         timePtr(m.CreatedAt),
+	}
+}
+
+func newTemperatureBreachResponse(b models.TemperatureBreach) TemperatureBreachResponse {
+	return TemperatureBreachResponse{
+		ID:            b.ID,
+		StorageRoomID: b.StorageRoomID,
+		ZoneType:      b.ZoneType,
+		MinCelsius:    b.MinCelsius,
+		MaxCelsius:    b.MaxCelsius,
+		PeakCelsius:   b.PeakCelsius,
+		StartedAt:     timePtr(b.StartedAt),
+		ResolvedAt:    timePtr(b.ResolvedAt),
+	}
+}
+
+func newStockLevelResponse(s models.StockLevel) StockLevelResponse {
+	return StockLevelResponse{
+		ID:                s.ID,
+		StorageRoomID:     s.StorageRoomID,
+		Sku:               s.Sku,
+		Quantity:          s.Quantity,
+		AllocatedQuantity: s.AllocatedQuantity,
+		ReceivedAt:        timePtr(s.ReceivedAt),
+		ExpiresAt:         timePtr(s.ExpiresAt),
+		UpdatedAt:         timePtr(s.UpdatedAt),
+	}
+}
+
+func newStockAdjustmentResponse(a models.StockAdjustment) StockAdjustmentResponse {
+	return StockAdjustmentResponse{
+		ID:            a.ID,
+		StorageRoomID: a.StorageRoomID,
+		Sku:           a.Sku,
+		QuantityDelta: a.QuantityDelta,
+		Reason:        a.Reason,
+		Reference:     a.Reference,
+		CreatedAt:     timePtr(a.CreatedAt),
+	}
+}
+
+func newReceiptResponse(r models.Receipt) ReceiptResponse {
+	return ReceiptResponse{
+		ID:          r.ID,
+		WarehouseID: r.WarehouseID,
+		SupplierID:  int64Ptr(r.SupplierID),
+		Reference:   r.Reference,
+		Status:      r.Status,
+		CreatedAt:   timePtr(r.CreatedAt),
+		UpdatedAt:   timePtr(r.UpdatedAt),
+	}
+}
+
+func newReceiptLineResponse(l models.ReceiptLine) ReceiptLineResponse {
+	return ReceiptLineResponse{
+		ID:               l.ID,
+		ReceiptID:        l.ReceiptID,
+		Sku:              l.Sku,
+		ExpectedQuantity: l.ExpectedQuantity,
+		ReceivedQuantity: l.ReceivedQuantity,
+		ExpiresAt:        timePtr(l.ExpiresAt),
+		UnitCostCents:    int64Ptr(l.UnitCostCents),
+	}
+}
+
+func newPickListResponse(p models.PickList) PickListResponse {
+	return PickListResponse{
+		ID:          p.ID,
+		WarehouseID: p.WarehouseID,
+		CarrierID:   int64Ptr(p.CarrierID),
+		Reference:   p.Reference,
+		Strategy:    p.Strategy,
+		Status:      p.Status,
+		CreatedAt:   timePtr(p.CreatedAt),
+		UpdatedAt:   timePtr(p.UpdatedAt),
+	}
+}
+
+func newPickListLineResponse(l models.PickListLine) PickListLineResponse {
+	return PickListLineResponse{
+		ID:             l.ID,
+		PickListID:     l.PickListID,
+		Sku:            l.Sku,
+		StorageRoomID:  l.StorageRoomID,
+		Quantity:       l.Quantity,
+		PickedQuantity: l.PickedQuantity,
+	}
+}
+
+func newPartnerResponse(p models.Partner) PartnerResponse {
+	return PartnerResponse{
+		ID:           p.ID,
+		Name:         p.Name,
+		ContactName:  p.ContactName,
+		Email:        p.Email,
+		Phone:        p.Phone,
+		LeadTimeDays: p.LeadTimeDays,
+		EdiQualifier: p.EdiQualifier,
+		EdiID:        p.EdiID,
+		SftpURL:      p.SftpUrl,
+		SftpHostKey:  p.SftpHostKey,
+		CreatedAt:    timePtr(p.CreatedAt),
+		UpdatedAt:    timePtr(p.UpdatedAt),
+	}
+}
+
+func newEdiDocumentResponse(d models.EdiDocument) EdiDocumentResponse {
+	return EdiDocumentResponse{
+		ID:                 d.ID,
+		PartnerID:          int64Ptr(d.PartnerID),
+		Direction:          d.Direction,
+		DocumentType:       d.DocumentType,
+		SenderID:           d.SenderID,
+		ControlNumber:      d.ControlNumber,
+		TransactionControl: d.TransactionControl,
+		ReceiptID:          int64Ptr(d.ReceiptID),
+		Status:             d.Status,
+		Error:              d.Error,
+		CreatedAt:          timePtr(d.CreatedAt),
+		UpdatedAt:          timePtr(d.UpdatedAt),
+	}
+}
+
+func newFileExchangeResponse(e models.FileExchange) FileExchangeResponse {
+	return FileExchangeResponse{
+		ID:            e.ID,
+		Name:          e.Name,
+		InboundURL:    e.InboundUrl,
+		OutboundURL:   e.OutboundUrl,
+		HostKey:       e.HostKey,
+		WarehouseID:   e.WarehouseID,
+		SupplierID:    int64Ptr(e.SupplierID),
+		ExtractFormat: e.ExtractFormat,
+		Enabled:       e.Enabled,
+		CreatedAt:     timePtr(e.CreatedAt),
+		UpdatedAt:     timePtr(e.UpdatedAt),
+	}
+}
+
+func newIntegrationResponse(in models.Integration) IntegrationResponse {
+	return IntegrationResponse{
+		ID:             in.ID,
+		Name:           in.Name,
+		Kind:           in.Kind,
+		URL:            in.Url,
+		OrdersURL:      in.OrdersUrl,
+		LocationID:     in.LocationID,
+		HasToken:       in.Token != "",
+		WarehouseID:    in.WarehouseID,
+		Enabled:        in.Enabled,
+		OrdersSyncedAt: timePtr(in.OrdersSyncedAt),
+		LastSyncedAt:   timePtr(in.LastSyncedAt),
+		Failures:       in.Failures,
+		LastError:      in.LastError,
+		NextSyncAt:     timePtr(in.NextSyncAt),
+		CreatedAt:      timePtr(in.CreatedAt),
+		UpdatedAt:      timePtr(in.UpdatedAt),
+	}
+}
+
+func newIntegrationOrderResponse(o models.IntegrationOrder) IntegrationOrderResponse {
+	// Lines were encoded by the service, they decode
+	lines := []integrations.OrderLine{}
+	_ = json.Unmarshal(o.Lines, &lines)
+	return IntegrationOrderResponse{
+		ID:                o.ID,
+		IntegrationID:     o.IntegrationID,
+		ExternalID:        o.ExternalID,
+		Reference:         o.Reference,
+		Lines:             lines,
+		Status:            o.Status,
+		PickListID:        int64Ptr(o.PickListID),
+		Error:             o.Error,
+		ExternalUpdatedAt: timePtr(o.ExternalUpdatedAt),
+		CreatedAt:         timePtr(o.CreatedAt),
+		UpdatedAt:         timePtr(o.UpdatedAt),
+	}
+}
+
+func newSagaResponse(s models.Saga) SagaResponse {
+	return SagaResponse{
+		ID:         s.ID,
+		Kind:       s.Kind,
+		PickListID: s.PickListID,
+		Status:     s.Status,
+		Error:      s.Error,
+		CreatedAt:  timePtr(s.CreatedAt),
+		UpdatedAt:  timePtr(s.UpdatedAt),
+	}
+}
+
+func newSagaStepResponse(s models.SagaStep) SagaStepResponse {
+	return SagaStepResponse{
+		Position:  s.Position,
+		Name:      s.Name,
+		Status:    s.Status,
+		Attempts:  s.Attempts,
+		Error:     s.Error,
+		UpdatedAt: timePtr(s.UpdatedAt),
+	}
+}
+
+func newTenantExportResponse(e models.TenantExport) TenantExportResponse {
+	return TenantExportResponse{
+		ID:          e.ID,
+		Format:      e.Format,
+		Status:      e.Status,
+		SizeBytes:   e.SizeBytes,
+		Checksum:    e.Checksum,
+		Tables:      e.Tables,
+		Error:       e.Error,
+		RequestedBy: e.RequestedBy,
+		CreatedAt:   timePtr(e.CreatedAt),
+		CompletedAt: timePtr(e.CompletedAt),
+	}
+}
+
+func newTenantDeletionResponse(d models.TenantDeletion) TenantDeletionResponse {
+	return TenantDeletionResponse{
+		ID:          d.ID,
+		Mode:        d.Mode,
+		Status:      d.Status,
+		RunAfter:    timePtr(d.RunAfter),
+		Pseudonym:   d.Pseudonym,
+		Report:      d.Report,
+		Error:       d.Error,
+		RequestedBy: d.RequestedBy,
+		CreatedAt:   timePtr(d.CreatedAt),
+		CompletedAt: timePtr(d.CompletedAt),
+	}
+}
+
+func newFileExchangeRunResponse(r models.FileExchangeRun) FileExchangeRunResponse {
+	return FileExchangeRunResponse{
+		ID:             r.ID,
+		ExchangeID:     r.ExchangeID,
+		Trigger:        r.Trigger,
+		Status:         r.Status,
+		FilesProcessed: r.FilesProcessed,
+		FilesFailed:    r.FilesFailed,
+		Error:          r.Error,
+		StartedAt:      timePtr(r.StartedAt),
+		FinishedAt:     timePtr(r.FinishedAt),
+	}
+}
+
+func newFileExchangeFileResponse(f models.FileExchangeFile) FileExchangeFileResponse {
+	return FileExchangeFileResponse{
+		ID:         f.ID,
+		ExchangeID: f.ExchangeID,
+		RunID:      f.RunID,
+		Direction:  f.Direction,
+		Name:       f.Name,
+		Format:     f.Format,
+		Sha256:     f.Sha256,
+		Size:       f.Size,
+		Status:     f.Status,
+		Error:      f.Error,
+		CreatedAt:  timePtr(f.CreatedAt),
+		UpdatedAt:  timePtr(f.UpdatedAt),
+	}
+}
+
+func newPrinterResponse(p models.Printer) PrinterResponse {
+	return PrinterResponse{
+		ID:        p.ID,
+		Name:      p.Name,
+		Address:   p.Address,
+		Dpi:       p.Dpi,
+		CreatedAt: timePtr(p.CreatedAt),
+		UpdatedAt: timePtr(p.UpdatedAt),
+	}
+}
+
+func newTransferOrderResponse(t models.TransferOrder) TransferOrderResponse {
+	return TransferOrderResponse{
+		ID:                     t.ID,
+		SourceWarehouseID:      t.SourceWarehouseID,
+		DestinationWarehouseID: t.DestinationWarehouseID,
+		Reference:              t.Reference,
+		Status:                 t.Status,
+		CreatedAt:              timePtr(t.CreatedAt),
+		UpdatedAt:              timePtr(t.UpdatedAt),
+		ShippedAt:              timePtr(t.ShippedAt),
+		ReceivedAt:             timePtr(t.ReceivedAt),
+	}
+}
+
+func newTransferOrderLineResponse(l models.TransferOrderLine) TransferOrderLineResponse {
+	return TransferOrderLineResponse{
+		ID:                  l.ID,
+		TransferOrderID:     l.TransferOrderID,
+		Sku:                 l.Sku,
+		Quantity:            l.Quantity,
+		SourceStorageRoomID: int32Ptr(l.SourceStorageRoomID),
+		ShippedQuantity:     l.ShippedQuantity,
+		ReceivedQuantity:    l.ReceivedQuantity,
+		InTransit:           l.ShippedQuantity - l.ReceivedQuantity,
+	}
+}
+
+func newInTransitStockResponse(r models.ListInTransitStockRow) InTransitStockResponse {
+	return InTransitStockResponse{
+		DestinationWarehouseID: r.DestinationWarehouseID,
+		Sku:                    r.Sku,
+		Quantity:               r.Quantity,
+	}
+}
+
+func newWaveResponse(w models.Wave) WaveResponse {
+	return WaveResponse{
+		ID:          w.ID,
+		WarehouseID: w.WarehouseID,
+		Reference:   w.Reference,
+		CreatedAt:   timePtr(w.CreatedAt),
+	}
+}
+
+func newAuditLogResponse(a models.AuditLog) AuditLogResponse {
+	return AuditLogResponse{
+		ID:         a.ID,
+		EntityType: a.EntityType,
+		EntityID:   a.EntityID,
+		Action:     a.Action,
+		FromStatus: a.FromStatus,
+		ToStatus:   a.ToStatus,
+		Actor:      a.Actor,
+		CreatedAt:  timePtr(a.CreatedAt),
+	}
+}
+
+func newAttachmentResponse(a models.Attachment) AttachmentResponse {
+	return AttachmentResponse{
+		ID:          a.ID,
+		WarehouseID: a.WarehouseID,
+		Kind:        a.Kind,
+		FileName:    a.FileName,
+		ContentType: a.ContentType,
+		SizeBytes:   a.SizeBytes,
+		Checksum:    a.Checksum,
+		UploadedBy:  a.UploadedBy,
+		CreatedAt:   timePtr(a.CreatedAt),
+	}
+}
+
+func newAttributeSchemaResponse(s models.AttributeSchema) AttributeSchemaResponse {
+	return AttributeSchemaResponse{
+		EntityType: s.EntityType,
+		Schema:     json.RawMessage(s.Schema),
+		UpdatedBy:  s.UpdatedBy,
+		UpdatedAt:  timePtr(s.UpdatedAt),
+	}
+}
+
+func newCountSessionResponse(s models.CountSession) CountSessionResponse {
+	return CountSessionResponse{
+		ID:            s.ID,
+		WarehouseID:   s.WarehouseID,
+		StorageRoomID: int32Ptr(s.StorageRoomID),
+		Status:        s.Status,
+		CreatedAt:     timePtr(s.CreatedAt),
+		UpdatedAt:     timePtr(s.UpdatedAt),
+	}
+}
+
+func newCountLineResponse(l models.CountLine) CountLineResponse {
+	return CountLineResponse{
+		ID:              l.ID,
+		CountSessionID:  l.CountSessionID,
+		StorageRoomID:   l.StorageRoomID,
+		Sku:             l.Sku,
+		BookQuantity:    l.BookQuantity,
+		CountedQuantity: int32Ptr(l.CountedQuantity),
+		Approved:        l.Approved,
+	}
+}
+
+func newDeadLetterResponse(d models.DeadLetter) DeadLetterResponse {
+	return DeadLetterResponse{
+		ID:         d.ID,
+		Source:     d.Source,
+		SourceID:   d.SourceID,
+		Topic:      d.Topic,
+		Key:        d.Key,
+		Payload:    json.RawMessage(d.Payload),
+		Attempts:   d.Attempts,
+		LastError:  d.LastError,
+		CreatedAt:  timePtr(d.CreatedAt),
+		FailedAt:   timePtr(d.FailedAt),
+		ReplayedAt: timePtr(d.ReplayedAt),
+		ReplayedBy: d.ReplayedBy,
+	}
+}
+
+func newJobResponse(j models.Job) JobResponse {
+	return JobResponse{
+		ID:          j.ID,
+		Kind:        j.Kind,
+		Payload:     json.RawMessage(j.Payload),
+		Status:      j.Status,
+		Attempts:    j.Attempts,
+		MaxAttempts: j.MaxAttempts,
+		LastError:   j.LastError,
+		RunAt:       timePtr(j.RunAt),
+		CreatedAt:   timePtr(j.CreatedAt),
+		UpdatedAt:   timePtr(j.UpdatedAt),
+	}
+}
+
+func newSearchResultResponse(r models.SearchInventoryRow) SearchResultResponse {
+	return SearchResultResponse{
+		Kind:        r.Kind,
+		ID:          r.ID,
+		WarehouseID: r.WarehouseID,
+		Name:        r.Name,
+		Detail:      r.Detail,
+		Rank:        r.Rank,
+	}
+}
+
+func newWarehouseV2(w models.Warehouse) WarehouseV2 {
+	return WarehouseV2{
+		ID:        w.ID,
+		Name:      w.Name,
+		Address:   w.Address,
+		Ward:      w.Ward,
+		District:  w.District,
+		City:      w.City,
+		Country:   w.Country,
+		Latitude:  floatPtr(w.Latitude),
+		Longitude: floatPtr(w.Longitude),
+
+		TimeZone:       w.TimeZone,
+		OperatingHours: decodeOperatingHours(w.OperatingHours),
+		ContactEmail:   textPtr(w.ContactEmail),
+		ContactPhone:   textPtr(w.ContactPhone),
+		Tags:           tagsOrEmpty(w.Tags),
+
+		Attributes: attributesOrEmpty(w.Attributes),
+		Status:     w.Status,
+		Slug:       w.Slug,
+	}
+}
+
+func newWarehousesV2(warehouses []models.Warehouse) []WarehouseV2 {
+	out := make([]WarehouseV2, 0, len(warehouses))
+	for _, w := range warehouses {
+		out = append(out, newWarehouseV2(w))
+	}
+	return out
+}
+
+// Operator DTOs cover every tenant and so do carry OrgID
+
+type TenantResponse struct {
+	OrgID                 string `json:"org_id" v1:"OrgID"`
+	Warehouses            int64  `json:"warehouses" v1:"Warehouses"`
+	ActiveAPIKeys         int64  `json:"active_api_keys" v1:"ActiveAPIKeys"`
+	PendingJobs           int64  `json:"pending_jobs" v1:"PendingJobs"`
+	PendingOutboxMessages int64  `json:"pending_outbox_messages" v1:"PendingOutboxMessages"`
+}
+
+// APIKeyResponse never includes the key, which is only returned once by
+// CreateAPIKey
+type APIKeyResponse struct {
+	ID         int64      `json:"id" v1:"ID"`
+	OrgID      string     `json:"org_id" v1:"OrgID"`
+	Name       string     `json:"name" v1:"Name"`
+	Prefix     string     `json:"prefix" v1:"Prefix"`
+	CreatedBy  string     `json:"created_by" v1:"CreatedBy"`
+	ExpiresAt  *time.Time `json:"expires_at" v1:"ExpiresAt"`
+	RevokedAt  *time.Time `json:"revoked_at" v1:"RevokedAt"`
+	LastUsedAt *time.Time `json:"last_used_at" v1:"LastUsedAt"`
+	CreatedAt  *time.Time `json:"created_at" v1:"CreatedAt"`
+}
+
+type OutboxMessageResponse struct {
+	ID            int64           `json:"id" v1:"ID"`
+	OrgID         string          `json:"org_id" v1:"OrgID"`
+	Topic         string          `json:"topic" v1:"Topic"`
+	Key           string          `json:"key" v1:"Key"`
+	Payload       json.RawMessage `json:"payload" v1:"Payload"`
+	Attempts      int32           `json:"attempts" v1:"Attempts"`
+	LastError     string          `json:"last_error" v1:"LastError"`
+	NextAttemptAt *time.Time      `json:"next_attempt_at" v1:"NextAttemptAt"`
+	CreatedAt     *time.Time      `json:"created_at" v1:"CreatedAt"`
+}
+
+// CreatedAPIKeyResponse is the only response including Key
+type CreatedAPIKeyResponse struct {
+	Key    string         `json:"key" v1:"Key"`
+	APIKey APIKeyResponse `json:"api_key" v1:"APIKey"`
+}
+
+// OutboxStatusResponse is the backlog of the outbox with its oldest
+// pending messages
+type OutboxStatusResponse struct {
+	Pending    int64                   `json:"pending" v1:"Pending"`
+	LagSeconds float64                 `json:"lag_seconds" v1:"LagSeconds"`
+	Messages   []OutboxMessageResponse `json:"messages" v1:"Messages"`
+}
+
+type ServiceStatusesResponse struct {
+	Inventory ServiceStatusResponse `json:"inventory" v1:"Inventory"`
+	Orders    ServiceStatusResponse `json:"orders" v1:"Orders"`
+}
+
+type LogLevelResponse struct {
+	Level string `json:"level" v1:"Level"`
+}
+
+// JobStatusResponse counts jobs by kind, then status. Scheduler is left out
+// on instances not running the scheduler.
+type JobStatusResponse struct {
+	Jobs      map[string]map[string]int64 `json:"jobs" v1:"Jobs"`
+	Scheduler []scheduler.TaskStatus      `json:"scheduler,omitempty" v1:"Scheduler"`
+}
+
+func newTenantResponse(t models.ListTenantsRow) TenantResponse {
+	return TenantResponse{
+		OrgID:                 t.OrgID,
+		Warehouses:            t.Warehouses,
+		ActiveAPIKeys:         t.ActiveApiKeys,
+		PendingJobs:           t.PendingJobs,
+		PendingOutboxMessages: t.PendingOutboxMessages,
+	}
+}
+
+func newAPIKeyResponse(k models.ApiKey) APIKeyResponse {
+	return APIKeyResponse{
+		ID:         k.ID,
+		OrgID:      k.OrgID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		CreatedBy:  k.CreatedBy,
+		ExpiresAt:  timePtr(k.ExpiresAt),
+		RevokedAt:  timePtr(k.RevokedAt),
+		LastUsedAt: timePtr(k.LastUsedAt),
+		CreatedAt:  timePtr(k.CreatedAt),
+	}
+}
+
+func newOutboxMessageResponse(m models.Outbox) OutboxMessageResponse {
+	return OutboxMessageResponse{
+		ID:            m.ID,
+		OrgID:         m.OrgID,
+		Topic:         m.Topic,
+		Key:           m.Key,
+		Payload:       json.RawMessage(m.Payload),
+		Attempts:      m.Attempts,
+		LastError:     m.LastError,
+		NextAttemptAt: timePtr(m.NextAttemptAt),
+		CreatedAt:     timePtr(m.CreatedAt),
+	}
+}
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+	models "warehouse-service/models/sqlc"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var testTime = time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+
+func testTimestamptz() pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: testTime, Valid: true}
+}
+
+// TestResponseContract pins the JSON emitted for each DTO, with the v1
+// names v1 responses use. A failure here means a client-visible change:
+// update the expectation only on purpose.
+func TestResponseContract(t *testing.T) {
+	tests := []struct {
+		name string
+		dto  any
+		want string
+	}{
+		{
+			name: "warehouse",
+			dto: newWarehouseResponse(models.Warehouse{
+				ID: 1, Name: "Main", Address: "1 Dock Rd", Ward: "W1", District: "D1",
+				City: "Hanoi", Country: "VN", OrgID: "org_1", TimeZone: "Asia/Ho_Chi_Minh",
+				OperatingHours: []byte(`{"monday":{"open":"08:00","close":"17:00"}}`),
+				ContactEmail:   pgtype.Text{String: "dock@example.com", Valid: true},
+				Tags:           []string{"cold-chain"},
+				Attributes:     []byte(`{"dock_count":4}`),
+				Status:         "active",
+				Slug:           "main",
+			}),
+			want: `{"ID":1,"Name":"Main","Address":"1 Dock Rd","Ward":"W1","District":"D1","City":"Hanoi","Country":"VN","Latitude":null,"Longitude":null,"TimeZone":"Asia/Ho_Chi_Minh","OperatingHours":{"monday":{"open":"08:00","close":"17:00"}},"ContactEmail":"dock@example.com","ContactPhone":null,"Tags":["cold-chain"],"Attributes":{"dock_count":4},"Status":"active","slug":"main"}`,
+		},
+		{
+			name: "nearby warehouse",
+			dto: newNearbyWarehouseResponse(models.ListNearbyWarehousesRow{
+				ID: 1, Name: "Main", Address: "1 Dock Rd", City: "Hanoi", Country: "VN", OrgID: "org_1",
+				Latitude: pgtype.Float8{Float64: 21.03, Valid: true}, Longitude: pgtype.Float8{Float64: 105.85, Valid: true},
+				TimeZone: "UTC", OperatingHours: []byte(`{}`), Tags: []string{}, Status: "maintenance", Slug: "main", Distance: 1250.5,
+			}),
+			want: `{"ID":1,"Name":"Main","Address":"1 Dock Rd","Ward":"","District":"","City":"Hanoi","Country":"VN","Latitude":21.03,"Longitude":105.85,"TimeZone":"UTC","OperatingHours":{},"ContactEmail":null,"ContactPhone":null,"Tags":[],"Attributes":{},"Status":"maintenance","slug":"main","Distance":1250.5}`,
+		},
+		{
+			name: "storage room",
+			dto: newStorageRoomResponse(models.StorageRoom{
+				ID: 7, Name: "Cold room", Number: "A-03-2", WarehouseID: 1, OrgID: "org_1", ZoneType: "chilled",
+				Tags: []string{"eu"}, Capacity: 500, Aisle: 3, Bay: 2,
+			}),
+			want: `{"ID":7,"Name":"Cold room","Number":"A-03-2","WarehouseID":1,"ZoneType":"chilled","Tags":["eu"],"Attributes":{},"Capacity":500,"Aisle":3,"Bay":2}`,
+		},
+		{
+			name: "stock level",
+			dto: newStockLevelResponse(models.StockLevel{
+				ID: 11, OrgID: "org_1", StorageRoomID: 7, Sku: "SKU-1", Quantity: 12, AllocatedQuantity: 2,
+				ReceivedAt: testTimestamptz(), UpdatedAt: testTimestamptz(),
+			}),
+			want: `{"ID":11,"StorageRoomID":7,"Sku":"SKU-1","Quantity":12,"AllocatedQuantity":2,"ReceivedAt":"2024-03-01T09:30:00Z","ExpiresAt":null,"UpdatedAt":"2024-03-01T09:30:00Z"}`,
+		},
+		{
+			name: "stock adjustment",
+			dto: newStockAdjustmentResponse(models.StockAdjustment{
+				ID: 12, OrgID: "org_1", StorageRoomID: 7, Sku: "SKU-1", QuantityDelta: -3,
+				Reason: "shipment", Reference: "pick_list:4", CreatedAt: testTimestamptz(),
+			}),
+			want: `{"ID":12,"StorageRoomID":7,"Sku":"SKU-1","QuantityDelta":-3,"Reason":"shipment","Reference":"pick_list:4","CreatedAt":"2024-03-01T09:30:00Z"}`,
+		},
+		{
+			name: "receipt",
+			dto: newReceiptResponse(models.Receipt{
+				ID: 2, OrgID: "org_1", WarehouseID: 1, Reference: "PO-1", Status: "open",
+				CreatedAt: testTimestamptz(), UpdatedAt: testTimestamptz(),
+			}),
+			want: `{"ID":2,"WarehouseID":1,"SupplierID":null,"Reference":"PO-1","Status":"open","CreatedAt":"2024-03-01T09:30:00Z","UpdatedAt":"2024-03-01T09:30:00Z"}`,
+		},
+		{
+			name: "receipt line",
+			dto: newReceiptLineResponse(models.ReceiptLine{
+				ID: 3, ReceiptID: 2, Sku: "SKU-1", ExpectedQuantity: 10, ReceivedQuantity: 4,
+				ExpiresAt: testTimestamptz(),
+			}),
+			want: `{"ID":3,"ReceiptID":2,"Sku":"SKU-1","ExpectedQuantity":10,"ReceivedQuantity":4,"ExpiresAt":"2024-03-01T09:30:00Z","UnitCostCents":null}`,
+		},
+		{
+			name: "pick list",
+			dto: newPickListResponse(models.PickList{
+				ID: 4, OrgID: "org_1", WarehouseID: 1, Reference: "SO-1", Strategy: "fefo", Status: "allocated",
+				CreatedAt: testTimestamptz(), UpdatedAt: testTimestamptz(),
+			}),
+			want: `{"ID":4,"WarehouseID":1,"CarrierID":null,"Reference":"SO-1","Strategy":"fefo","Status":"allocated","CreatedAt":"2024-03-01T09:30:00Z","UpdatedAt":"2024-03-01T09:30:00Z"}`,
+		},
+		{
+			name: "pick list line",
+			dto: newPickListLineResponse(models.PickListLine{
+				ID: 5, PickListID: 4, Sku: "SKU-1", StorageRoomID: 7, Quantity: 3, PickedQuantity: 1,
+			}),
+			want: `{"ID":5,"PickListID":4,"Sku":"SKU-1","StorageRoomID":7,"Quantity":3,"PickedQuantity":1}`,
+		},
+		{
+			name: "audit log",
+			dto: newAuditLogResponse(models.AuditLog{
+				ID: 6, OrgID: "org_1", EntityType: "pick_list", EntityID: 4, Action: "ship",
+				FromStatus: "picked", ToStatus: "shipped", Actor: "user_1", CreatedAt: testTimestamptz(),
+			}),
+			want: `{"ID":6,"EntityType":"pick_list","EntityID":4,"Action":"ship","FromStatus":"picked","ToStatus":"shipped","Actor":"user_1","CreatedAt":"2024-03-01T09:30:00Z"}`,
+		},
+		{
+			name: "attachment",
+			dto: newAttachmentResponse(models.Attachment{
+				ID: 7, OrgID: "org_1", WarehouseID: 1, Kind: "lease", FileName: "lease.pdf", ContentType: "application/pdf",
+				SizeBytes: 2048, Checksum: "9f86d0", ObjectKey: "org_1/warehouses/1/key", UploadedBy: "user_1", CreatedAt: testTimestamptz(),
+			}),
+			want: `{"ID":7,"WarehouseID":1,"Kind":"lease","FileName":"lease.pdf","ContentType":"application/pdf","SizeBytes":2048,"Checksum":"9f86d0","UploadedBy":"user_1","CreatedAt":"2024-03-01T09:30:00Z"}`,
+		},
+		{
+			name: "open temperature breach",
+			dto: newTemperatureBreachResponse(models.TemperatureBreach{
+				ID: 3, OrgID: "org_1", StorageRoomID: 7, ZoneType: "chilled", MinCelsius: 2, MaxCelsius: 8,
+				PeakCelsius: 11.5, StartedAt: testTimestamptz(), CreatedAt: testTimestamptz(),
+			}),
+			want: `{"ID":3,"StorageRoomID":7,"ZoneType":"chilled","MinCelsius":2,"MaxCelsius":8,"PeakCelsius":11.5,"StartedAt":"2024-03-01T09:30:00Z","ResolvedAt":null}`,
+		},
+		{
+			name: "count session without room",
+			dto: newCountSessionResponse(models.CountSession{
+				ID: 8, OrgID: "org_1", WarehouseID: 1, Status: "open",
+				CreatedAt: testTimestamptz(), UpdatedAt: testTimestamptz(),
+			}),
+			want: `{"ID":8,"WarehouseID":1,"StorageRoomID":null,"Status":"open","CreatedAt":"2024-03-01T09:30:00Z","UpdatedAt":"2024-03-01T09:30:00Z"}`,
+		},
+		{
+			name: "count line",
+			dto: newCountLineResponse(models.CountLine{
+				ID: 9, CountSessionID: 8, StorageRoomID: 7, Sku: "SKU-1", BookQuantity: 5,
+				CountedQuantity: pgtype.Int4{Int32: 4, Valid: true},
+			}),
+			want: `{"ID":9,"CountSessionID":8,"StorageRoomID":7,"Sku":"SKU-1","BookQuantity":5,"CountedQuantity":4,"Approved":false}`,
+		},
+		{
+			name: "job",
+			dto: newJobResponse(models.Job{
+				ID: 10, OrgID: "org_1", Kind: "report", Payload: []byte(`{"month":"2024-02"}`), Status: "queued",
+				Attempts: 0, MaxAttempts: 5, RunAt: testTimestamptz(), CreatedAt: testTimestamptz(), UpdatedAt: testTimestamptz(),
+			}),
+			want: `{"ID":10,"Kind":"report","Payload":{"month":"2024-02"},"Status":"queued","Attempts":0,"MaxAttempts":5,"LastError":"","RunAt":"2024-03-01T09:30:00Z","CreatedAt":"2024-03-01T09:30:00Z","UpdatedAt":"2024-03-01T09:30:00Z"}`,
+		},
+		{
+			name: "search result",
+			dto: newSearchResultResponse(models.SearchInventoryRow{
+				Kind: "storage_room", ID: 7, WarehouseID: 1, Name: "Cold room", Detail: "A-03-2", Rank: 0.5,
+			}),
+			want: `{"Kind":"storage_room","ID":7,"WarehouseID":1,"Name":"Cold room","Detail":"A-03-2","Rank":0.5}`,
+		},
+		{
+			name: "warehouse v2",
+			dto: newWarehouseV2(models.Warehouse{
+				ID: 1, Name: "Main", Address: "1 Dock Rd", Ward: "W1", District: "D1",
+				City: "Hanoi", Country: "VN", OrgID: "org_1", TimeZone: "UTC", Status: "draft", Slug: "main",
+			}),
+			want: `{"id":1,"name":"Main","address":"1 Dock Rd","ward":"W1","district":"D1","city":"Hanoi","country":"VN","latitude":null,"longitude":null,"time_zone":"UTC","operating_hours":{},"contact_email":null,"contact_phone":null,"tags":[],"attributes":{},"status":"draft","slug":"main"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(v1Names{value: tt.dto})
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("wire contract changed\n got: %s\nwant: %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMapSliceKeepsNil(t *testing.T) {
+	got, err := json.Marshal(mapSlice([]models.Warehouse(nil), newWarehouseResponse))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if string(got) != "null" {
+		t.Errorf("got %s, want null", got)
+	}
+}
@@ -0,0 +1,546 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"warehouse-service/edi"
+	"warehouse-service/listquery"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ISA ID qualifier of mutually defined IDs, the default of partners and
+// tenants
+const ediQualifierMutual = "ZZ"
+
+// Values of edi_document
+const (
+	ediInbound  = "inbound"
+	ediOutbound = "outbound"
+
+	ediStatusImported = "imported"
+	ediStatusPending  = "pending"
+	ediStatusSent     = "sent"
+	ediStatusFailed   = "failed"
+
+	ediASN                  = "856"
+	ediInventoryAdvice      = "846"
+	ediInventoryAdviceGroup = "IB"
+)
+
+var errNoTenantEdiID = errors.New("the tenant has no EDI ID, set edi_id in the settings")
+
+// x12Units maps X12 units of measure to the units of the item catalog.
+// Eaches are base units, other codes are looked up lowercased.
+var x12Units = map[string]string{
+	"EA": "",
+	"CA": "case",
+	"BX": "box",
+	"PK": "pack",
+	"PL": "pallet",
+}
+
+// asnReceiptLines turns the lines of an ASN into the lines of a receipt
+func asnReceiptLines(asn edi.ASN) []receiptLineRequest {
+	lines := make([]receiptLineRequest, 0, len(asn.Lines))
+	for _, l := range asn.Lines {
+		unit, ok := x12Units[l.Unit]
+		if !ok {
+			unit = strings.ToLower(l.Unit)
+		}
+		lines = append(lines, receiptLineRequest{
+			Sku:              l.Sku,
+			ExpectedQuantity: l.Quantity,
+			Unit:             unit,
+		})
+	}
+	return lines
+}
+
+// importError rejects an import with a status other than a database
+// error's, field names the offending field of a conflict
+type importError struct {
+	status  int
+	message string
+	field   string
+}
+
+func (e *importError) Error() string {
+	return e.message
+}
+
+// respondImportError answers an import that failed with err, logging
+// errors that are not the client's
+func respondImportError(ctx *gin.Context, span trace.Span, err error) {
+	var importErr *importError
+	if errors.As(err, &importErr) {
+		body := gin.H{
+			"error": tr(ctx, importErr.message),
+		}
+		if importErr.field != "" {
+			body["field"] = importErr.field
+		}
+		ctx.JSON(importErr.status, body)
+		return
+	}
+	if rejected, ok := unitRejected(err); ok {
+		respondUnitError(ctx, rejected)
+		return
+	}
+	slog.Error("Could not import receipts: ", slog.Any("err", err.Error()))
+	span.RecordError(err)
+	ctx.JSON(dbErrorStatus(err), gin.H{
+		"error": tr(ctx, "Failed to import receipts"),
+	})
+}
+
+// importTarget checks the warehouse receipts are imported into and the
+// supplier they are from, which may be left out
+func (h *Handlers) importTarget(ctx context.Context, qtx *models.Queries, orgID string, warehouseID int64, supplierParam *int64) (pgtype.Int8, error) {
+	dbStart := time.Now()
+	_, err := qtx.GetWarehouse(ctx, models.GetWarehouseParams{
+		ID:    warehouseID,
+		OrgID: orgID,
+	})
+	h.recordDBOperation(ctx, "get", "warehouse", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return pgtype.Int8{}, &importError{status: http.StatusNotFound, message: "Warehouse not found"}
+	}
+	if err != nil {
+		return pgtype.Int8{}, err
+	}
+	supplierID, err := h.partnerParam(ctx, qtx, orgID, supplierKind, supplierParam)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return supplierID, &importError{status: http.StatusNotFound, message: "Supplier not found"}
+	}
+	return supplierID, err
+}
+
+// parseASNInterchange reads the 856 advanced ship notices of an X12
+// interchange
+func parseASNInterchange(raw []byte) (edi.Interchange, []edi.ASN, error) {
+	interchange, err := edi.Parse(raw)
+	if err != nil {
+		return interchange, nil, err
+	}
+	notices := make([]edi.ASN, 0, len(interchange.Transactions))
+	for _, t := range interchange.Transactions {
+		asn, err := edi.ParseASN(t)
+		if err != nil {
+			return interchange, nil, err
+		}
+		notices = append(notices, asn)
+	}
+	if len(notices) == 0 {
+		return interchange, nil, errors.New("the interchange holds no transaction sets")
+	}
+	return interchange, notices, nil
+}
+
+// importASNs creates a receipt of warehouseID from each notice of an
+// interchange, referenced by its shipment ID, and logs the notices as
+// inbound documents. The supplier is supplierParam or the supplier whose
+// EDI ID sent the interchange.
+func (h *Handlers) importASNs(ctx context.Context, qtx *models.Queries, orgID string, warehouseID int64, supplierParam *int64, interchange edi.Interchange, notices []edi.ASN) ([]models.Receipt, []models.EdiDocument, error) {
+	supplierID, err := h.importTarget(ctx, qtx, orgID, warehouseID, supplierParam)
+	if err != nil {
+		return nil, nil, err
+	}
+	if supplierParam == nil {
+		dbStart := time.Now()
+		supplier, err := qtx.GetPartnerByEdiID(ctx, models.GetPartnerByEdiIDParams{
+			OrgID:        orgID,
+			EdiQualifier: interchange.SenderQualifier,
+			EdiID:        interchange.SenderID,
+		})
+		h.recordDBOperation(ctx, "get", "partner", dbStart, err)
+		if errors.Is(err, pgx.ErrNoRows) || (err == nil && supplier.Kind != supplierKind.name) {
+			return nil, nil, &importError{
+				status:  http.StatusNotFound,
+				message: fmt.Sprintf("No supplier has EDI ID %s:%s, set it on the supplier or pass supplier_id", interchange.SenderQualifier, interchange.SenderID),
+			}
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		supplierID = pgtype.Int8{Int64: supplier.ID, Valid: true}
+	}
+
+	receipts := make([]models.Receipt, 0, len(notices))
+	documents := make([]models.EdiDocument, 0, len(notices))
+	for i, asn := range notices {
+		dbStart := time.Now()
+		receipt, err := qtx.CreateReceipt(ctx, models.CreateReceiptParams{
+			OrgID:       orgID,
+			WarehouseID: warehouseID,
+			Reference:   asn.ShipmentID,
+			SupplierID:  supplierID,
+		})
+		h.recordDBOperation(ctx, "create", "receipt", dbStart, err)
+		if err == nil {
+			_, err = h.createReceiptLines(ctx, qtx, orgID, receipt.ID, asnReceiptLines(asn))
+		}
+		var document models.EdiDocument
+		if err == nil {
+			dbStart = time.Now()
+			document, err = qtx.CreateEdiDocument(ctx, models.CreateEdiDocumentParams{
+				OrgID:              orgID,
+				PartnerID:          supplierID,
+				Direction:          ediInbound,
+				DocumentType:       ediASN,
+				SenderID:           interchange.SenderID,
+				ControlNumber:      interchange.ControlNumber,
+				TransactionControl: interchange.Transactions[i].ControlNumber,
+				ReceiptID:          pgtype.Int8{Int64: receipt.ID, Valid: true},
+				Status:             ediStatusImported,
+			})
+			h.recordDBOperation(ctx, "create", "edi_document", dbStart, err)
+		}
+		if conflict, ok := conflictFor(err); ok {
+			return nil, nil, &importError{status: http.StatusConflict, message: conflict.message, field: conflict.field}
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		receipts = append(receipts, receipt)
+		documents = append(documents, document)
+	}
+	return receipts, documents, nil
+}
+
+// ImportASN creates a receipt from each 856 advanced ship notice of an X12
+// interchange, referenced by its shipment ID. The supplier is supplier_id
+// or the supplier whose EDI ID sent the interchange. An interchange is
+// imported once; receipts are created for all its notices or none.
+func (h *Handlers) ImportASN(ctx *gin.Context) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "ImportASN")
+	defer span.End()
+
+	warehouseID, err := strconv.ParseInt(ctx.Query("warehouse_id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "warehouse_id is required"),
+		})
+		return
+	}
+	var supplierParam *int64
+	if v := ctx.Query("supplier_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": tr(ctx, "Invalid supplier ID format"),
+			})
+			return
+		}
+		supplierParam = &id
+	}
+	raw, err := ctx.GetRawData()
+	var interchange edi.Interchange
+	var notices []edi.ASN
+	if err == nil {
+		interchange, notices, err = parseASNInterchange(raw)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   tr(ctx, "Invalid 856 interchange"),
+			"details": err.Error(),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.Int64("warehouse.id", warehouseID),
+		attribute.String("edi.sender", interchange.SenderID),
+		attribute.String("edi.control_number", interchange.ControlNumber),
+		attribute.Int("edi.transactions", len(notices)),
+		attribute.String("tenant.id", orgID),
+	)
+
+	tx, err := h.db.Begin(spanCtx)
+	if err != nil {
+		slog.Error("Failed to start transaction", slog.Any("err", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": tr(ctx, "Failed to start transaction"),
+		})
+		return
+	}
+	defer tx.Rollback(spanCtx) // This will be ignored if tx.Commit() succeeds
+
+	receipts, documents, err := h.importASNs(spanCtx, h.queries.WithTx(tx), orgID, warehouseID, supplierParam, interchange, notices)
+	if err == nil {
+		err = tx.Commit(spanCtx)
+	}
+	h.recordOperation(orgID, observability.EntityEDI, "import", opStart, err)
+	if err != nil {
+		respondImportError(ctx, span, err)
+		return
+	}
+
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	respondV1(ctx, http.StatusCreated, gin.H{
+		"message": tr(ctx, "Import ASN Successfully"),
+		"data": gin.H{
+			"documents": mapSlice(documents, newEdiDocumentResponse),
+			"receipts":  mapSlice(receipts, newReceiptResponse),
+		},
+	})
+}
+
+// inventoryAdvice writes an 846 with the stock of every SKU of the tenant
+// for partner and logs it with status. The document's ID is its control
+// number.
+func (h *Handlers) inventoryAdvice(ctx context.Context, partner models.Partner, status string) (models.EdiDocument, []byte, error) {
+	setting, err := h.tenantSetting(ctx, h.queries, partner.OrgID)
+	if err == nil && setting.EdiID == "" {
+		err = errNoTenantEdiID
+	}
+	var stock []models.ListInventoryAdviceRow
+	if err == nil {
+		dbStart := time.Now()
+		stock, err = h.queries.ListInventoryAdvice(ctx, partner.OrgID)
+		h.recordDBOperation(ctx, "report", "stock_level", dbStart, err)
+	}
+	var document models.EdiDocument
+	if err == nil {
+		dbStart := time.Now()
+		document, err = h.queries.CreateEdiDocument(ctx, models.CreateEdiDocumentParams{
+			OrgID:        partner.OrgID,
+			PartnerID:    pgtype.Int8{Int64: partner.ID, Valid: true},
+			Direction:    ediOutbound,
+			DocumentType: ediInventoryAdvice,
+			SenderID:     setting.EdiID,
+			Status:       status,
+		})
+		h.recordDBOperation(ctx, "create", "edi_document", dbStart, err)
+	}
+	if err != nil {
+		return models.EdiDocument{}, nil, err
+	}
+
+	now := time.Now().UTC()
+	lines := make([]edi.AdviceLine, 0, len(stock))
+	for _, s := range stock {
+		lines = append(lines, edi.AdviceLine{Sku: s.Sku, OnHand: s.OnHand, Available: s.Available})
+	}
+	data := edi.Encode(edi.Envelope{
+		SenderQualifier:   setting.EdiQualifier,
+		SenderID:          setting.EdiID,
+		ReceiverQualifier: partner.EdiQualifier,
+		ReceiverID:        partner.EdiID,
+		ControlNumber:     document.ID,
+		FunctionalID:      ediInventoryAdviceGroup,
+		Date:              now,
+	}, ediInventoryAdvice, edi.InventoryAdvice(strconv.FormatInt(document.ID, 10), now, lines))
+	return document, data, nil
+}
+
+// ExportInventoryAdvice downloads an 846 inventory advice for a supplier
+func (h *Handlers) ExportInventoryAdvice(ctx *gin.Context) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "ExportInventoryAdvice")
+	defer span.End()
+
+	supplierID, err := strconv.ParseInt(ctx.Query("supplier_id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "supplier_id is required"),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.Int64("supplier.id", supplierID),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	supplier, err := h.queries.GetPartner(spanCtx, models.GetPartnerParams{
+		ID:    supplierID,
+		OrgID: orgID,
+		Kind:  supplierKind.name,
+	})
+	h.recordDBOperation(spanCtx, "get", "partner", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Supplier not found"),
+		})
+		return
+	}
+	if err == nil && supplier.EdiID == "" {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": tr(ctx, "The supplier has no EDI ID"),
+			"field": "edi_id",
+		})
+		return
+	}
+	var document models.EdiDocument
+	var data []byte
+	if err == nil {
+		document, data, err = h.inventoryAdvice(spanCtx, supplier, ediStatusSent)
+	}
+	if errors.Is(err, errNoTenantEdiID) {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+			"field": "edi_id",
+		})
+		return
+	}
+	h.recordOperation(orgID, observability.EntityEDI, "export", opStart, err)
+	if err != nil {
+		slog.Error("Could not export inventory advice: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to export inventory advice"),
+		})
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int64("edi.document_id", document.ID),
+		attribute.String("operation.status", "success"),
+	)
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", edi.FileName(ediInventoryAdvice, document.ID)))
+	ctx.Data(http.StatusOK, "application/edi-x12", data)
+}
+
+// UploadInventoryAdvice uploads an 846 to the SFTP drop of every supplier
+// that has one. Each upload is logged as a document, sent or failed; a
+// failed upload doesn't hold up the other suppliers.
+func (h *Handlers) UploadInventoryAdvice(ctx context.Context, drop *edi.Drop) error {
+	opStart := time.Now()
+	spanCtx, span := h.tracer.Start(ctx, "UploadInventoryAdvice")
+	defer span.End()
+
+	dbStart := time.Now()
+	suppliers, err := h.queries.ListEdiDropPartners(spanCtx)
+	h.recordDBOperation(spanCtx, "list", "partner", dbStart, err)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	sent := 0
+	var errs []error
+	for _, supplier := range suppliers {
+		document, data, err := h.inventoryAdvice(spanCtx, supplier, ediStatusPending)
+		if errors.Is(err, errNoTenantEdiID) {
+			slog.Warn("Skipped inventory advice of a tenant without an EDI ID",
+				slog.String("tenant_id", supplier.OrgID),
+				slog.Int64("supplier_id", supplier.ID),
+			)
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("inventory advice for supplier %d: %w", supplier.ID, err))
+			continue
+		}
+
+		uploadErr := drop.Upload(spanCtx, supplier.SftpUrl, supplier.SftpHostKey, edi.FileName(ediInventoryAdvice, document.ID), data)
+		h.recordOperation(supplier.OrgID, observability.EntityEDI, "export", opStart, uploadErr)
+		status, message := ediStatusSent, ""
+		if uploadErr != nil {
+			status, message = ediStatusFailed, uploadErr.Error()
+			errs = append(errs, fmt.Errorf("upload inventory advice to supplier %d: %w", supplier.ID, uploadErr))
+		} else {
+			sent++
+		}
+		dbStart = time.Now()
+		_, err = h.queries.UpdateEdiDocumentStatus(spanCtx, models.UpdateEdiDocumentStatusParams{
+			ID:     document.ID,
+			OrgID:  supplier.OrgID,
+			Status: status,
+			Error:  message,
+		})
+		h.recordDBOperation(spanCtx, "update", "edi_document", dbStart, err)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	span.SetAttributes(attribute.Int("edi.sent", sent))
+	if sent > 0 {
+		slog.Info("Uploaded inventory advice", slog.Int("count", sent))
+	}
+	if err := errors.Join(errs...); err != nil {
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
+// ediDocumentListSpec sorts and filters the documents of ListEdiDocuments
+var ediDocumentListSpec = &listquery.Spec{
+	Table: "edi_document",
+	Sort: map[string]string{
+		"id":             "id",
+		"document_type":  "document_type",
+		"status":         "status",
+		"control_number": "control_number",
+		"created_at":     "created_at",
+	},
+	Filters: map[string]listquery.Filter{
+		"direction":     {Column: "direction", Op: listquery.Eq, Parse: listquery.OneOf(ediInbound, ediOutbound)},
+		"document_type": {Column: "document_type", Op: listquery.Eq, Parse: listquery.Text},
+		"status": {Column: "status", Op: listquery.Eq, Parse: listquery.OneOf(
+			ediStatusImported, ediStatusPending, ediStatusSent, ediStatusFailed)},
+		"partner_id": {Column: "partner_id", Op: listquery.Eq, Parse: listquery.Int},
+	},
+	Default: []listquery.Order{{Field: "id", Desc: true}},
+	Key:     "id",
+}
+
+// ListEdiDocuments lists the EDI documents of the tenant, newest first
+func (h *Handlers) ListEdiDocuments(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "ListEdiDocuments")
+	defer span.End()
+
+	q, err := listParams(ctx, ediDocumentListSpec)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	q.Where("org_id", listquery.Eq, orgID)
+	span.SetAttributes(
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	documents, err := queryPage[models.EdiDocument](ctx, spanCtx, h.db, q)
+	h.recordDBOperation(spanCtx, "list", "edi_document", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while listing EDI documents: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to list EDI documents"),
+		})
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int("edi_document.count", len(documents)),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "List EDI Documents Successfully"),
+		"data":    mapSlice(documents, newEdiDocumentResponse),
+	})
+}
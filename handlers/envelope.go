@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// Error codes used in v2 error envelopes
+const (
+	errCodeInvalidRequest = "invalid_request"
+	errCodeValidation     = "validation_failed"
+	errCodeNotFound       = "not_found"
+	errCodeConflict       = "conflict"
+	errCodePrecondition   = "precondition_failed"
+	errCodeDuplicate      = "duplicate"
+	errCodeInternal       = "internal_error"
+	errCodeUnavailable    = "unavailable"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// envelope is the body of every v2 response. Data is null when the request
+// failed and Errors is omitted when it succeeded.
+type envelope struct {
+	Data   any        `json:"data"`
+	Meta   *pageMeta  `json:"meta,omitempty"`
+	Errors []apiError `json:"errors,omitempty"`
+}
+
+// apiError describes one problem with a v2 request
+type apiError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Field   string `json:"field,omitempty"`
+}
+
+// pageMeta describes the page returned by a v2 list endpoint
+type pageMeta struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+	Count  int   `json:"count"`
+}
+
+func respondV2(ctx *gin.Context, status int, data any, meta *pageMeta) {
+	ctx.JSON(status, envelope{Data: data, Meta: meta})
+}
+
+func respondV2Error(ctx *gin.Context, status int, code, message string) {
+	ctx.JSON(status, envelope{Errors: []apiError{{Code: code, Message: tr(ctx, message)}}})
+}
+
+// respondV2BindError reports each failed validation rule as its own error,
+// falling back to a single error for malformed JSON
+func respondV2BindError(ctx *gin.Context, err error) {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		respondV2Error(ctx, http.StatusBadRequest, errCodeInvalidRequest, err.Error())
+		return
+	}
+
+	apiErrors := make([]apiError, 0, len(validationErrors))
+	for _, fieldErr := range validationErrors {
+		apiErrors = append(apiErrors, apiError{
+			Code:    errCodeValidation,
+			Message: "failed the " + fieldErr.Tag() + " rule",
+			Field:   snakeCase(fieldErr.Field()),
+		})
+	}
+	ctx.JSON(http.StatusBadRequest, envelope{Errors: apiErrors})
+}
+
+// limitParam reads the ?limit= query parameter of a list request
+func limitParam(ctx *gin.Context) (int32, error) {
+	limit, err := strconv.ParseInt(ctx.DefaultQuery("limit", strconv.Itoa(defaultPageLimit)), 10, 32)
+	if err != nil || limit < 1 || limit > maxPageLimit {
+		return 0, fmt.Errorf("limit must be between 1 and %d", maxPageLimit)
+	}
+	return int32(limit), nil
+}
+
+// pageParams reads the limit and offset query parameters of a list request
+func pageParams(ctx *gin.Context) (int32, int32, error) {
+	limit, err := limitParam(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	offset, err := strconv.ParseInt(ctx.DefaultQuery("offset", "0"), 10, 32)
+	if err != nil || offset < 0 {
+		return 0, 0, errors.New("offset must be a non-negative integer")
+	}
+	return limit, int32(offset), nil
+}
+
+// parsePage reads the page of a v2 list request, writing the error response
+// itself when it returns false
+func parsePage(ctx *gin.Context) (int32, int32, bool) {
+	limit, offset, err := pageParams(ctx)
+	if err != nil {
+		respondV2Error(ctx, http.StatusBadRequest, errCodeInvalidRequest, err.Error())
+		return 0, 0, false
+	}
+	return limit, offset, true
+}
+
+// snakeCase turns a Go field name such as WarehouseID into warehouse_id
+func snakeCase(name string) string {
+	var b strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		upper := unicode.IsUpper(r)
+		if upper && i > 0 && (!unicode.IsUpper(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"warehouse-service/changefeed"
+	models "warehouse-service/models/sqlc"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// Events a stream may fall behind by before it is closed, clients
+	// reconnect with Last-Event-ID and resume from the database
+	streamBuffer      = 256
+	streamHeartbeat   = 15 * time.Second
+	streamRetryMillis = 3000
+	// Sent instead of a resume when Last-Event-ID is unknown or pruned, the
+	// client has to reload what it shows
+	streamResetEvent = "reset"
+)
+
+var streamEntities = []string{changefeed.EntityWarehouse, changefeed.EntityStorageRoom, changefeed.EntityStockLevel}
+
+// eventFilter matches event types given as entity or entity.operation,
+// e.g. "warehouse" or "stock_level.update". An empty filter matches all.
+type eventFilter map[string]bool
+
+func parseEventFilter(types string) (eventFilter, error) {
+	filter := eventFilter{}
+	if types == "" {
+		return filter, nil
+	}
+	for _, eventType := range strings.Split(types, ",") {
+		eventType = strings.TrimSpace(eventType)
+		entity, operation, _ := strings.Cut(eventType, ".")
+		validEntity := false
+		for _, known := range streamEntities {
+			validEntity = validEntity || entity == known
+		}
+		switch {
+		case !validEntity:
+			return nil, fmt.Errorf("unknown event type %q, expected one of %s", eventType, strings.Join(streamEntities, ", "))
+		case operation != "" && operation != "insert" && operation != "update" && operation != "delete":
+			return nil, fmt.Errorf("unknown operation in event type %q, expected insert, update or delete", eventType)
+		}
+		filter[eventType] = true
+	}
+	return filter, nil
+}
+
+func (f eventFilter) match(e changefeed.Event) bool {
+	return len(f) == 0 || f[e.Entity] || f[e.Entity+"."+e.Operation]
+}
+
+// StreamEvents streams the tenant's warehouse, storage room and stock level
+// changes as Server-Sent Events of type <entity>.<operation>. ?types= is a
+// comma separated list of entities or event types to receive. A client
+// reconnecting with Last-Event-ID (or ?last_event_id=) first receives the
+// events it missed.
+func (h *Handlers) StreamEvents(ctx *gin.Context) {
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "StreamEvents")
+	defer span.End()
+
+	filter, err := parseEventFilter(ctx.Query("types"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	lastEventID := ctx.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = ctx.Query("last_event_id")
+	}
+	var resumeID int64
+	if lastEventID != "" {
+		if resumeID, err = strconv.ParseInt(lastEventID, 10, 64); err != nil || resumeID < 1 {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": tr(ctx, "Last-Event-ID must be a positive integer"),
+			})
+			return
+		}
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.String("events.types", ctx.Query("types")),
+		attribute.Int64("events.last_event_id", resumeID),
+		attribute.String("tenant.id", orgID),
+	)
+
+	// Subscribe before reading missed events so none falls in between
+	sub := h.changes.Subscribe(streamBuffer)
+	defer sub.Close()
+	if h.prometheusMetrics != nil {
+		defer h.prometheusMetrics.StreamClientConnected("sse")()
+	}
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	// Keep reverse proxies such as nginx from buffering the stream
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+	// The stream outlives SERVER_WRITE_TIMEOUT, heartbeats detect dead
+	// clients instead
+	if err := http.NewResponseController(ctx.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		slog.Warn("Could not lift the write deadline of the event stream", slog.Any("error", err))
+	}
+	w := ctx.Writer
+	fmt.Fprintf(w, "retry: %d\n\n", streamRetryMillis)
+	w.Flush()
+
+	var sent changefeed.Position
+	if resumeID > 0 {
+		sent, err = h.replayEvents(spanCtx, w, orgID, resumeID, filter)
+		if err != nil {
+			if ctx.Request.Context().Err() == nil {
+				slog.Error("Got an error while replaying change events: ", slog.Any("err", err.Error()))
+				span.RecordError(err)
+			}
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			w.Flush()
+		case event, ok := <-sub.C:
+			if !ok {
+				// Fell behind or shutting down, the client reconnects and resumes
+				return
+			}
+			if event.OrgID != orgID || !event.Position().After(sent) || !filter.match(event) {
+				continue
+			}
+			if err := writeSSE(w, event); err != nil {
+				return
+			}
+			w.Flush()
+		}
+	}
+}
+
+// replayEvents writes the tenant's events after resumeID and returns the
+// position of the last one. Without the event in the database a reset
+// event is written instead and the stream continues with live events.
+func (h *Handlers) replayEvents(ctx context.Context, w gin.ResponseWriter, orgID string, resumeID int64, filter eventFilter) (changefeed.Position, error) {
+	dbStart := time.Now()
+	last, err := h.queries.GetChangeEvent(ctx, models.GetChangeEventParams{ID: resumeID, OrgID: orgID})
+	h.recordDBOperation(ctx, "get", "change_event", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		fmt.Fprintf(w, "event: %s\ndata: {}\n\n", streamResetEvent)
+		w.Flush()
+		return changefeed.Position{}, nil
+	}
+	if err != nil {
+		return changefeed.Position{}, err
+	}
+
+	pos := changefeed.FromModel(last).Position()
+	for {
+		dbStart := time.Now()
+		events, err := h.queries.ListOrgChangeEventsAfter(ctx, models.ListOrgChangeEventsAfterParams{
+			OrgID:     orgID,
+			AfterTxid: pos.Txid,
+			AfterID:   pos.ID,
+			PageLimit: maxPageLimit,
+		})
+		h.recordDBOperation(ctx, "list", "change_event", dbStart, err)
+		if err != nil {
+			return pos, err
+		}
+		for _, e := range events {
+			event := changefeed.FromModel(e)
+			pos = event.Position()
+			if !filter.match(event) {
+				continue
+			}
+			if err := writeSSE(w, event); err != nil {
+				return pos, err
+			}
+		}
+		w.Flush()
+		if len(events) < maxPageLimit {
+			return pos, nil
+		}
+	}
+}
+
+func writeSSE(w io.Writer, event changefeed.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s.%s\ndata: %s\n\n", event.ID, event.Entity, event.Operation, data)
+	return err
+}
@@ -0,0 +1,619 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"warehouse-service/exchange"
+	"warehouse-service/jobs"
+	"warehouse-service/listquery"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+	"warehouse-service/sftp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type fileExchangeRequest struct {
+	Name string `json:"name" binding:"required"`
+	// Folder files are imported from, sftp://user@host/directory or
+	// s3://bucket/prefix
+	InboundURL string `json:"inbound_url"`
+	// Folder the extract is written to
+	OutboundURL string `json:"outbound_url"`
+	// Key the SFTP server of the folders must present, in authorized_keys
+	// format
+	HostKey string `json:"host_key"`
+	// Warehouse imported receipts are expected at, by ID or slug
+	WarehouseID WarehouseRef `json:"warehouse_id" binding:"required"`
+	// Supplier of imported receipts, by the sender's EDI ID when left out
+	SupplierID    *int64 `json:"supplier_id"`
+	ExtractFormat string `json:"extract_format" binding:"omitempty,oneof=csv 846"`
+	// Whether the scheduler runs the exchange, true when left out
+	Enabled *bool `json:"enabled"`
+
+	// ID of the warehouse, once bindFileExchangeRequest resolved it
+	warehouseID int64
+}
+
+// validate normalizes the folders of an exchange. An exchange needs a
+// folder, an extract and its folder go together and the SFTP folders of
+// an exchange are on one server with host_key.
+func (req *fileExchangeRequest) validate() error {
+	req.Name = strings.TrimSpace(req.Name)
+	req.InboundURL = strings.TrimSpace(req.InboundURL)
+	req.OutboundURL = strings.TrimSpace(req.OutboundURL)
+	req.HostKey = strings.TrimSpace(req.HostKey)
+	if req.Name == "" {
+		return errors.New("name is required")
+	}
+	if req.InboundURL == "" && req.OutboundURL == "" {
+		return errors.New("inbound_url or outbound_url is required")
+	}
+	if (req.OutboundURL == "") != (req.ExtractFormat == "") {
+		return errors.New("outbound_url and extract_format go together")
+	}
+	if req.ExtractFormat == ediInventoryAdvice && req.SupplierID == nil {
+		return errors.New("an 846 extract needs supplier_id")
+	}
+
+	sftpHost := ""
+	for _, raw := range []string{req.InboundURL, req.OutboundURL} {
+		if raw == "" {
+			continue
+		}
+		u, err := exchange.ParseURL(raw)
+		if err != nil {
+			return err
+		}
+		if u.Scheme != "sftp" {
+			continue
+		}
+		if sftpHost != "" && sftpHost != u.Host {
+			return errors.New("the SFTP folders of an exchange must be on one server")
+		}
+		sftpHost = u.Host
+	}
+	if sftpHost != "" {
+		if _, err := sftp.ParseHostKey(req.HostKey); err != nil {
+			return errors.New("host_key must be the SFTP server's key in authorized_keys format")
+		}
+	}
+	return nil
+}
+
+func parseFileExchangeID(ctx *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "Invalid file exchange ID format"),
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// bindFileExchangeRequest reads and validates an exchange, checking its
+// warehouse and supplier
+func (h *Handlers) bindFileExchangeRequest(ctx *gin.Context, orgID string) (fileExchangeRequest, pgtype.Int8, bool) {
+	var req fileExchangeRequest
+	err := ctx.ShouldBindJSON(&req)
+	if err == nil {
+		err = req.validate()
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   tr(ctx, "Invalid file exchange payload"),
+			"details": err.Error(),
+		})
+		return req, pgtype.Int8{}, false
+	}
+	var supplierID pgtype.Int8
+	req.warehouseID, err = h.resolveWarehouse(ctx.Request.Context(), h.queries, orgID, req.WarehouseID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = &importError{status: http.StatusNotFound, message: "Warehouse not found"}
+	}
+	if err == nil {
+		supplierID, err = h.importTarget(ctx.Request.Context(), h.queries, orgID, req.warehouseID, req.SupplierID)
+	}
+	var importErr *importError
+	if errors.As(err, &importErr) {
+		ctx.JSON(importErr.status, gin.H{
+			"error": tr(ctx, importErr.message),
+		})
+		return req, supplierID, false
+	}
+	if err != nil {
+		slog.Error("Got an error while checking file exchange: ", slog.Any("err", err.Error()))
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to save file exchange"),
+		})
+		return req, supplierID, false
+	}
+	return req, supplierID, true
+}
+
+// getFileExchange looks up the exchange of the :id parameter, writing the
+// error response when it can't
+func (h *Handlers) getFileExchange(ctx *gin.Context, orgID string) (models.FileExchange, bool) {
+	id, ok := parseFileExchangeID(ctx)
+	if !ok {
+		return models.FileExchange{}, false
+	}
+	spanCtx := ctx.Request.Context()
+	dbStart := time.Now()
+	ex, err := h.queries.GetFileExchange(spanCtx, models.GetFileExchangeParams{
+		ID:    id,
+		OrgID: orgID,
+	})
+	h.recordDBOperation(spanCtx, "get", "file_exchange", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "File exchange not found"),
+		})
+		return ex, false
+	}
+	if err != nil {
+		slog.Error("Got an error while getting file exchange: ", slog.Any("err", err.Error()))
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to get file exchange"),
+		})
+		return ex, false
+	}
+	return ex, true
+}
+
+func (h *Handlers) CreateFileExchange(ctx *gin.Context) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "CreateFileExchange")
+	defer span.End()
+
+	orgID := tenantID(ctx)
+	req, supplierID, ok := h.bindFileExchangeRequest(ctx, orgID)
+	if !ok {
+		return
+	}
+	span.SetAttributes(
+		attribute.String("file_exchange.name", req.Name),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	ex, err := h.queries.CreateFileExchange(spanCtx, models.CreateFileExchangeParams{
+		OrgID:         orgID,
+		Name:          req.Name,
+		InboundUrl:    req.InboundURL,
+		OutboundUrl:   req.OutboundURL,
+		HostKey:       req.HostKey,
+		WarehouseID:   req.warehouseID,
+		SupplierID:    supplierID,
+		ExtractFormat: req.ExtractFormat,
+		Enabled:       req.Enabled == nil || *req.Enabled,
+	})
+	h.recordDBOperation(spanCtx, "create", "file_exchange", dbStart, err)
+	if conflict, ok := conflictFor(err); ok {
+		h.recordOperation(orgID, observability.EntityFileExchange, "create", opStart, err)
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": tr(ctx, conflict.message),
+			"field": conflict.field,
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Could not create file exchange: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		h.recordOperation(orgID, observability.EntityFileExchange, "create", opStart, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to create file exchange"),
+		})
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntityFileExchange, "create", opStart, nil)
+
+	span.SetAttributes(
+		attribute.Int64("file_exchange.id", ex.ID),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusCreated, gin.H{
+		"message": tr(ctx, "Create File Exchange Successfully"),
+		"data":    newFileExchangeResponse(ex),
+	})
+}
+
+func (h *Handlers) GetFileExchange(ctx *gin.Context) {
+	// Start a new span for this operation
+	_, span := h.tracer.Start(ctx.Request.Context(), "GetFileExchange")
+	defer span.End()
+
+	orgID := tenantID(ctx)
+	span.SetAttributes(attribute.String("tenant.id", orgID))
+	ex, ok := h.getFileExchange(ctx, orgID)
+	if !ok {
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int64("file_exchange.id", ex.ID),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Get File Exchange Successfully"),
+		"data":    newFileExchangeResponse(ex),
+	})
+}
+
+func (h *Handlers) ListFileExchanges(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "ListFileExchanges")
+	defer span.End()
+
+	orgID := tenantID(ctx)
+	span.SetAttributes(attribute.String("tenant.id", orgID))
+
+	dbStart := time.Now()
+	exchanges, err := h.queries.ListFileExchanges(spanCtx, orgID)
+	h.recordDBOperation(spanCtx, "list", "file_exchange", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while listing file exchanges: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to list file exchanges"),
+		})
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int("file_exchange.count", len(exchanges)),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "List File Exchanges Successfully"),
+		"data":    mapSlice(exchanges, newFileExchangeResponse),
+	})
+}
+
+// UpdateFileExchange replaces the settings of an exchange. A run in
+// progress finishes with the previous settings.
+func (h *Handlers) UpdateFileExchange(ctx *gin.Context) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "UpdateFileExchange")
+	defer span.End()
+
+	id, ok := parseFileExchangeID(ctx)
+	if !ok {
+		return
+	}
+	orgID := tenantID(ctx)
+	req, supplierID, ok := h.bindFileExchangeRequest(ctx, orgID)
+	if !ok {
+		return
+	}
+	span.SetAttributes(
+		attribute.Int64("file_exchange.id", id),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	ex, err := h.queries.UpdateFileExchange(spanCtx, models.UpdateFileExchangeParams{
+		ID:            id,
+		OrgID:         orgID,
+		Name:          req.Name,
+		InboundUrl:    req.InboundURL,
+		OutboundUrl:   req.OutboundURL,
+		HostKey:       req.HostKey,
+		WarehouseID:   req.warehouseID,
+		SupplierID:    supplierID,
+		ExtractFormat: req.ExtractFormat,
+		Enabled:       req.Enabled == nil || *req.Enabled,
+	})
+	h.recordDBOperation(spanCtx, "update", "file_exchange", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "File exchange not found"),
+		})
+		return
+	}
+	if conflict, ok := conflictFor(err); ok {
+		h.recordOperation(orgID, observability.EntityFileExchange, "update", opStart, err)
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": tr(ctx, conflict.message),
+			"field": conflict.field,
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Could not update file exchange: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		h.recordOperation(orgID, observability.EntityFileExchange, "update", opStart, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to update file exchange"),
+		})
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntityFileExchange, "update", opStart, nil)
+
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Update File Exchange Successfully"),
+		"data":    newFileExchangeResponse(ex),
+	})
+}
+
+// DeleteFileExchange removes an exchange with its run history. Files left
+// in its folders stay there.
+func (h *Handlers) DeleteFileExchange(ctx *gin.Context) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "DeleteFileExchange")
+	defer span.End()
+
+	id, ok := parseFileExchangeID(ctx)
+	if !ok {
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.Int64("file_exchange.id", id),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	rows, err := h.queries.DeleteFileExchange(spanCtx, models.DeleteFileExchangeParams{
+		ID:    id,
+		OrgID: orgID,
+	})
+	h.recordDBOperation(spanCtx, "delete", "file_exchange", dbStart, err)
+	if err != nil {
+		slog.Error("Could not delete file exchange: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		h.recordOperation(orgID, observability.EntityFileExchange, "delete", opStart, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to delete file exchange"),
+		})
+		return
+	}
+	if rows == 0 {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "File exchange not found"),
+		})
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntityFileExchange, "delete", opStart, nil)
+
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": tr(ctx, "Delete File Exchange Successfully"),
+	})
+}
+
+// RunFileExchange queues a run of an exchange that imports its inbound
+// files and writes its extract, enabled or not
+func (h *Handlers) RunFileExchange(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "RunFileExchange")
+	defer span.End()
+
+	orgID := tenantID(ctx)
+	span.SetAttributes(attribute.String("tenant.id", orgID))
+	ex, ok := h.getFileExchange(ctx, orgID)
+	if !ok {
+		return
+	}
+
+	dbStart := time.Now()
+	job, err := jobs.Enqueue(spanCtx, h.queries, orgID, JobKindRunFileExchange, fileExchangeJob{
+		ExchangeID: ex.ID,
+	}, jobs.EnqueueOptions{})
+	h.recordDBOperation(spanCtx, "create", "job", dbStart, err)
+	if err != nil {
+		slog.Error("Could not queue file exchange run: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to run file exchange"),
+		})
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int64("file_exchange.id", ex.ID),
+		attribute.Int64("job.id", job.ID),
+		attribute.String("operation.status", "success"),
+	)
+	ctx.Header("Location", fmt.Sprintf("/v1/jobs/%d", job.ID))
+	respondV1(ctx, http.StatusAccepted, gin.H{
+		"message": tr(ctx, "Run File Exchange Successfully"),
+		"data":    newJobResponse(job),
+	})
+}
+
+// fileExchangeRunListSpec sorts and filters the runs of ListFileExchangeRuns
+var fileExchangeRunListSpec = &listquery.Spec{
+	Table: "file_exchange_run",
+	Sort: map[string]string{
+		"id":           "id",
+		"status":       "status",
+		"files_failed": "files_failed",
+		"started_at":   "started_at",
+		"finished_at":  "finished_at",
+	},
+	Filters: map[string]listquery.Filter{
+		"status":  {Column: "status", Op: listquery.Eq, Parse: listquery.Text},
+		"trigger": {Column: "trigger", Op: listquery.Eq, Parse: listquery.Text},
+	},
+	Default: []listquery.Order{{Field: "id", Desc: true}},
+	Key:     "id",
+}
+
+// ListFileExchangeRuns lists the runs of an exchange, newest first
+func (h *Handlers) ListFileExchangeRuns(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "ListFileExchangeRuns")
+	defer span.End()
+
+	id, ok := parseFileExchangeID(ctx)
+	if !ok {
+		return
+	}
+	q, err := listParams(ctx, fileExchangeRunListSpec)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	q.Where("org_id", listquery.Eq, orgID).Where("exchange_id", listquery.Eq, id)
+	span.SetAttributes(
+		attribute.Int64("file_exchange.id", id),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	runs, err := queryPage[models.FileExchangeRun](ctx, spanCtx, h.db, q)
+	h.recordDBOperation(spanCtx, "list", "file_exchange_run", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while listing file exchange runs: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to list file exchange runs"),
+		})
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int("file_exchange_run.count", len(runs)),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "List File Exchange Runs Successfully"),
+		"data":    mapSlice(runs, newFileExchangeRunResponse),
+	})
+}
+
+// fileExchangeFileListSpec sorts and filters the files of
+// ListFileExchangeFiles
+var fileExchangeFileListSpec = &listquery.Spec{
+	Table: "file_exchange_file",
+	Sort: map[string]string{
+		"id":         "id",
+		"name":       "name",
+		"status":     "status",
+		"created_at": "created_at",
+	},
+	Filters: map[string]listquery.Filter{
+		"direction": {Column: "direction", Op: listquery.Eq, Parse: listquery.Text},
+		"status":    {Column: "status", Op: listquery.Eq, Parse: listquery.Text},
+		"run_id":    {Column: "run_id", Op: listquery.Eq, Parse: listquery.Int},
+	},
+	Default: []listquery.Order{{Field: "id", Desc: true}},
+	Key:     "id",
+}
+
+// ListFileExchangeFiles lists the files an exchange picked up and wrote,
+// newest first
+func (h *Handlers) ListFileExchangeFiles(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "ListFileExchangeFiles")
+	defer span.End()
+
+	id, ok := parseFileExchangeID(ctx)
+	if !ok {
+		return
+	}
+	q, err := listParams(ctx, fileExchangeFileListSpec)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	q.Where("org_id", listquery.Eq, orgID).Where("exchange_id", listquery.Eq, id)
+	span.SetAttributes(
+		attribute.Int64("file_exchange.id", id),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	files, err := queryPage[models.FileExchangeFile](ctx, spanCtx, h.db, q)
+	h.recordDBOperation(spanCtx, "list", "file_exchange_file", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while listing file exchange files: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to list file exchange files"),
+		})
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int("file_exchange_file.count", len(files)),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "List File Exchange Files Successfully"),
+		"data":    mapSlice(files, newFileExchangeFileResponse),
+	})
+}
+
+// ReprocessFileExchangeFile imports a failed inbound file again, e.g. once
+// the SKUs or supplier it lacked were set up. The file is processed or
+// failed again with the new reason.
+func (h *Handlers) ReprocessFileExchangeFile(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "ReprocessFileExchangeFile")
+	defer span.End()
+
+	fileID, err := strconv.ParseInt(ctx.Param("file_id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "Invalid file ID format"),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.Int64("file_exchange_file.id", fileID),
+		attribute.String("tenant.id", orgID),
+	)
+	ex, ok := h.getFileExchange(ctx, orgID)
+	if !ok {
+		return
+	}
+
+	file, err := h.reprocessExchangeFile(spanCtx, ex, fileID)
+	var importErr *importError
+	if errors.As(err, &importErr) {
+		respondImportError(ctx, span, err)
+		return
+	}
+	if err != nil {
+		slog.Error("Could not reprocess file: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to reprocess file"),
+		})
+		return
+	}
+
+	span.SetAttributes(
+		attribute.String("file_exchange_file.status", file.Status),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Reprocess File Successfully"),
+		"data":    newFileExchangeFileResponse(file),
+	})
+}
@@ -0,0 +1,605 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"warehouse-service/edi"
+	"warehouse-service/exchange"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// JobKindRunFileExchange is the background job running a file exchange on
+// request
+const JobKindRunFileExchange = "run_file_exchange"
+
+// Values of the file_exchange tables
+const (
+	exchangeTriggerSchedule = "schedule"
+	exchangeTriggerManual   = "manual"
+
+	exchangeRunSucceeded = "succeeded"
+	exchangeRunFailed    = "failed"
+
+	exchangeFileReceived  = "received"
+	exchangeFileProcessed = "processed"
+	exchangeFileFailed    = "failed"
+	exchangeFileSent      = "sent"
+
+	exchangeFormatCSV = "csv"
+	exchangeFormatEDI = "edi"
+)
+
+var errFileExchangeRunning = errors.New("the file exchange is running")
+
+type fileExchangeJob struct {
+	ExchangeID int64 `json:"exchange_id"`
+}
+
+// csvReceipt is a receipt of a receipt CSV, the rows sharing a reference
+type csvReceipt struct {
+	Reference string
+	Lines     []receiptLineRequest
+}
+
+// parseReceiptCSV reads a CSV of receipt lines. The header names the
+// columns: reference, sku and quantity are required, unit and
+// unit_cost_cents optional and others ignored. Rows of a reference are
+// lines of one receipt, receipts come in the order of their first row.
+func parseReceiptCSV(data []byte) ([]csvReceipt, error) {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	r.TrimLeadingSpace = true
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, errors.New("the file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"reference", "sku", "quantity"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("the header lacks the %s column", name)
+		}
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var receipts []csvReceipt
+	index := map[string]int{}
+	r.FieldsPerRecord = len(header)
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := r.FieldPos(0)
+		reference, sku := field(record, "reference"), field(record, "sku")
+		if reference == "" || sku == "" {
+			return nil, fmt.Errorf("line %d: reference and sku are required", line)
+		}
+		quantity, err := strconv.ParseInt(field(record, "quantity"), 10, 32)
+		if err != nil || quantity <= 0 {
+			return nil, fmt.Errorf("line %d: quantity must be a positive integer", line)
+		}
+		receiptLine := receiptLineRequest{
+			Sku:              sku,
+			ExpectedQuantity: int32(quantity),
+			Unit:             field(record, "unit"),
+		}
+		if v := field(record, "unit_cost_cents"); v != "" {
+			cost, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || cost < 0 {
+				return nil, fmt.Errorf("line %d: unit_cost_cents must be a non-negative integer", line)
+			}
+			receiptLine.UnitCostCents = &cost
+		}
+
+		i, ok := index[reference]
+		if !ok {
+			i = len(receipts)
+			index[reference] = i
+			receipts = append(receipts, csvReceipt{Reference: reference})
+		}
+		receipts[i].Lines = append(receipts[i].Lines, receiptLine)
+	}
+	if len(receipts) == 0 {
+		return nil, errors.New("the file holds no receipt lines")
+	}
+	return receipts, nil
+}
+
+// exchangeFileFormat tells EDI from CSV files by their extension, or by
+// their content for other names
+func exchangeFileFormat(name string, data []byte) string {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".csv":
+		return exchangeFormatCSV
+	case ".edi", ".x12":
+		return exchangeFormatEDI
+	}
+	content := bytes.TrimLeft(bytes.TrimPrefix(data, []byte("\ufeff")), " \t\r\n")
+	if bytes.HasPrefix(content, []byte("ISA")) {
+		return exchangeFormatEDI
+	}
+	return exchangeFormatCSV
+}
+
+// stockExtract writes the stock of each SKU as a CSV
+func stockExtract(stock []models.ListInventoryAdviceRow) []byte {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"sku", "on_hand", "available"})
+	for _, s := range stock {
+		w.Write([]string{s.Sku, strconv.FormatInt(s.OnHand, 10), strconv.FormatInt(s.Available, 10)})
+	}
+	w.Flush()
+	return buf.Bytes()
+}
+
+// importReceipts creates the receipts of a receipt CSV
+func (h *Handlers) importReceipts(ctx context.Context, qtx *models.Queries, orgID string, warehouseID int64, supplierParam *int64, receipts []csvReceipt) ([]models.Receipt, error) {
+	supplierID, err := h.importTarget(ctx, qtx, orgID, warehouseID, supplierParam)
+	if err != nil {
+		return nil, err
+	}
+	created := make([]models.Receipt, 0, len(receipts))
+	for _, r := range receipts {
+		dbStart := time.Now()
+		receipt, err := qtx.CreateReceipt(ctx, models.CreateReceiptParams{
+			OrgID:       orgID,
+			WarehouseID: warehouseID,
+			Reference:   r.Reference,
+			SupplierID:  supplierID,
+		})
+		h.recordDBOperation(ctx, "create", "receipt", dbStart, err)
+		if err == nil {
+			_, err = h.createReceiptLines(ctx, qtx, orgID, receipt.ID, r.Lines)
+		}
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, receipt)
+	}
+	return created, nil
+}
+
+// withExchangeLock runs fn while holding the lock of an exchange, which
+// keeps replicas and reprocessing from working on the same files. It
+// returns errFileExchangeRunning when the lock is taken.
+func (h *Handlers) withExchangeLock(ctx context.Context, exchangeID int64, fn func() error) error {
+	// The lock's transaction does nothing else, fn records its work on
+	// connections of its own
+	return pgx.BeginFunc(ctx, h.db, func(tx pgx.Tx) error {
+		locked, err := h.queries.WithTx(tx).TryLockRefresh(ctx, fmt.Sprintf("file_exchange:%d", exchangeID))
+		if err != nil {
+			return err
+		}
+		if !locked {
+			return errFileExchangeRunning
+		}
+		return fn()
+	})
+}
+
+// processExchangeFile imports an inbound file in one transaction and
+// records the outcome on the file. An import that fails is rolled back and
+// leaves the file failed with the reason; only failing to record that is
+// an error.
+func (h *Handlers) processExchangeFile(ctx context.Context, ex models.FileExchange, file models.FileExchangeFile, data []byte) (models.FileExchangeFile, error) {
+	opStart := time.Now()
+	importErr := pgx.BeginFunc(ctx, h.db, func(tx pgx.Tx) error {
+		qtx := h.queries.WithTx(tx)
+		supplier := int64Ptr(ex.SupplierID)
+		if file.Format == exchangeFormatEDI {
+			interchange, notices, err := parseASNInterchange(data)
+			if err == nil {
+				_, _, err = h.importASNs(ctx, qtx, ex.OrgID, ex.WarehouseID, supplier, interchange, notices)
+			}
+			if err != nil {
+				return err
+			}
+		} else {
+			receipts, err := parseReceiptCSV(data)
+			if err == nil {
+				_, err = h.importReceipts(ctx, qtx, ex.OrgID, ex.WarehouseID, supplier, receipts)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		dbStart := time.Now()
+		processed, err := qtx.UpdateFileExchangeFileStatus(ctx, models.UpdateFileExchangeFileStatusParams{
+			ID:     file.ID,
+			OrgID:  ex.OrgID,
+			Status: exchangeFileProcessed,
+		})
+		h.recordDBOperation(ctx, "update", "file_exchange_file", dbStart, err)
+		file = processed
+		return err
+	})
+	h.recordOperation(ex.OrgID, observability.EntityFileExchange, "import", opStart, importErr)
+	if importErr == nil {
+		return file, nil
+	}
+
+	dbStart := time.Now()
+	failed, err := h.queries.UpdateFileExchangeFileStatus(ctx, models.UpdateFileExchangeFileStatusParams{
+		ID:     file.ID,
+		OrgID:  ex.OrgID,
+		Status: exchangeFileFailed,
+		Error:  importErr.Error(),
+	})
+	h.recordDBOperation(ctx, "update", "file_exchange_file", dbStart, err)
+	if err != nil {
+		return file, err
+	}
+	return failed, nil
+}
+
+// receiveExchangeFile records an inbound file with its content. It returns
+// pgx.ErrNoRows for a file whose content the exchange already picked up.
+func (h *Handlers) receiveExchangeFile(ctx context.Context, ex models.FileExchange, runID int64, name string, data []byte) (models.FileExchangeFile, error) {
+	sum := sha256.Sum256(data)
+	var file models.FileExchangeFile
+	err := pgx.BeginFunc(ctx, h.db, func(tx pgx.Tx) error {
+		qtx := h.queries.WithTx(tx)
+		dbStart := time.Now()
+		var err error
+		file, err = qtx.CreateFileExchangeFile(ctx, models.CreateFileExchangeFileParams{
+			OrgID:      ex.OrgID,
+			ExchangeID: ex.ID,
+			RunID:      runID,
+			Direction:  ediInbound,
+			Name:       name,
+			Format:     exchangeFileFormat(name, data),
+			Sha256:     hex.EncodeToString(sum[:]),
+			Size:       int32(len(data)),
+			Status:     exchangeFileReceived,
+		})
+		h.recordDBOperation(ctx, "create", "file_exchange_file", dbStart, err)
+		if err != nil {
+			return err
+		}
+		dbStart = time.Now()
+		err = qtx.CreateFileExchangeContent(ctx, models.CreateFileExchangeContentParams{
+			FileID:  file.ID,
+			Content: data,
+		})
+		h.recordDBOperation(ctx, "create", "file_exchange_content", dbStart, err)
+		return err
+	})
+	return file, err
+}
+
+// pollExchange imports the files of the inbound folder of an exchange.
+// A file is recorded before it is imported and removed from the folder
+// after, so it is picked up once; a file already picked up is removed
+// without importing it again. Partners' half uploaded .part files and
+// hidden files are left alone.
+func (h *Handlers) pollExchange(ctx context.Context, opener exchange.Opener, ex models.FileExchange, runID int64) (int32, int32, error) {
+	folder, err := opener.Open(ctx, ex.InboundUrl, ex.HostKey)
+	if err != nil {
+		return 0, 0, fmt.Errorf("open inbound folder: %w", err)
+	}
+	defer folder.Close()
+	names, err := folder.List()
+	if err != nil {
+		return 0, 0, fmt.Errorf("list inbound folder: %w", err)
+	}
+	slices.Sort(names)
+
+	var processed, failed int32
+	var errs []error
+	for _, name := range names {
+		if strings.HasPrefix(name, ".") || strings.HasSuffix(name, ".part") {
+			continue
+		}
+		data, err := folder.ReadFile(name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		file, err := h.receiveExchangeFile(ctx, ex, runID, name, data)
+		if err == nil {
+			file, err = h.processExchangeFile(ctx, ex, file, data)
+			if file.Status == exchangeFileProcessed {
+				processed++
+			} else {
+				failed++
+			}
+		} else if errors.Is(err, pgx.ErrNoRows) {
+			slog.Info("Removing a file picked up before",
+				slog.String("tenant_id", ex.OrgID),
+				slog.Int64("exchange_id", ex.ID),
+				slog.String("file", name),
+			)
+			err = nil
+		}
+		if err != nil {
+			// Left in the folder for the next run
+			errs = append(errs, fmt.Errorf("record %s: %w", name, err))
+			continue
+		}
+		if err := folder.Remove(name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return processed, failed, errors.Join(errs...)
+}
+
+// writeExtract writes the extract of an exchange to its outbound folder
+// and records it as an outbound file, sent or failed
+func (h *Handlers) writeExtract(ctx context.Context, opener exchange.Opener, ex models.FileExchange, runID int64) error {
+	opStart := time.Now()
+	var name, format string
+	var data []byte
+	var document models.EdiDocument
+	if ex.ExtractFormat == ediInventoryAdvice {
+		if !ex.SupplierID.Valid {
+			return errors.New("an 846 extract needs the exchange's supplier")
+		}
+		dbStart := time.Now()
+		supplier, err := h.queries.GetPartner(ctx, models.GetPartnerParams{
+			ID:    ex.SupplierID.Int64,
+			OrgID: ex.OrgID,
+			Kind:  supplierKind.name,
+		})
+		h.recordDBOperation(ctx, "get", "partner", dbStart, err)
+		if err == nil && supplier.EdiID == "" {
+			err = errors.New("the supplier has no EDI ID")
+		}
+		if err == nil {
+			document, data, err = h.inventoryAdvice(ctx, supplier, ediStatusPending)
+		}
+		if err != nil {
+			return err
+		}
+		name, format = edi.FileName(ediInventoryAdvice, document.ID), exchangeFormatEDI
+	} else {
+		dbStart := time.Now()
+		stock, err := h.queries.ListInventoryAdvice(ctx, ex.OrgID)
+		h.recordDBOperation(ctx, "report", "stock_level", dbStart, err)
+		if err != nil {
+			return err
+		}
+		name, format, data = fmt.Sprintf("stock_%09d.csv", runID%1_000_000_000), exchangeFormatCSV, stockExtract(stock)
+	}
+
+	folder, writeErr := opener.Open(ctx, ex.OutboundUrl, ex.HostKey)
+	if writeErr == nil {
+		writeErr = folder.WriteFile(name, data)
+		folder.Close()
+	}
+	h.recordOperation(ex.OrgID, observability.EntityFileExchange, "export", opStart, writeErr)
+	status, message := exchangeFileSent, ""
+	if writeErr != nil {
+		status, message = exchangeFileFailed, writeErr.Error()
+	}
+
+	sum := sha256.Sum256(data)
+	dbStart := time.Now()
+	_, err := h.queries.CreateFileExchangeFile(ctx, models.CreateFileExchangeFileParams{
+		OrgID:      ex.OrgID,
+		ExchangeID: ex.ID,
+		RunID:      runID,
+		Direction:  ediOutbound,
+		Name:       name,
+		Format:     format,
+		Sha256:     hex.EncodeToString(sum[:]),
+		Size:       int32(len(data)),
+		Status:     status,
+		Error:      message,
+	})
+	h.recordDBOperation(ctx, "create", "file_exchange_file", dbStart, err)
+	errs := []error{writeErr, err}
+	if document.ID != 0 {
+		if status == exchangeFileFailed {
+			status = ediStatusFailed
+		}
+		dbStart = time.Now()
+		_, err = h.queries.UpdateEdiDocumentStatus(ctx, models.UpdateEdiDocumentStatusParams{
+			ID:     document.ID,
+			OrgID:  ex.OrgID,
+			Status: status,
+			Error:  message,
+		})
+		h.recordDBOperation(ctx, "update", "edi_document", dbStart, err)
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// runFileExchange works on the folders of an exchange, importing the
+// inbound files when poll is set and writing the extract when extract is,
+// and records it as a run. A run fails when a folder can't be worked on;
+// files that fail to import are counted but don't fail the run.
+func (h *Handlers) runFileExchange(ctx context.Context, opener exchange.Opener, ex models.FileExchange, trigger string, poll, extract bool) error {
+	opStart := time.Now()
+	spanCtx, span := h.tracer.Start(ctx, "runFileExchange")
+	defer span.End()
+	span.SetAttributes(
+		attribute.Int64("file_exchange.id", ex.ID),
+		attribute.String("file_exchange.trigger", trigger),
+		attribute.String("tenant.id", ex.OrgID),
+	)
+
+	err := h.withExchangeLock(spanCtx, ex.ID, func() error {
+		dbStart := time.Now()
+		run, err := h.queries.CreateFileExchangeRun(spanCtx, models.CreateFileExchangeRunParams{
+			OrgID:      ex.OrgID,
+			ExchangeID: ex.ID,
+			Trigger:    trigger,
+		})
+		h.recordDBOperation(spanCtx, "create", "file_exchange_run", dbStart, err)
+		if err != nil {
+			return err
+		}
+
+		var processed, failed int32
+		var errs []error
+		if poll && ex.InboundUrl != "" {
+			var err error
+			processed, failed, err = h.pollExchange(spanCtx, opener, ex, run.ID)
+			errs = append(errs, err)
+		}
+		if extract && ex.OutboundUrl != "" && ex.ExtractFormat != "" {
+			errs = append(errs, h.writeExtract(spanCtx, opener, ex, run.ID))
+		}
+		runErr := errors.Join(errs...)
+		h.recordOperation(ex.OrgID, observability.EntityFileExchange, "run", opStart, runErr)
+		status, message := exchangeRunSucceeded, ""
+		if runErr != nil {
+			status, message = exchangeRunFailed, runErr.Error()
+		}
+
+		dbStart = time.Now()
+		_, err = h.queries.FinishFileExchangeRun(spanCtx, models.FinishFileExchangeRunParams{
+			ID:             run.ID,
+			Status:         status,
+			FilesProcessed: processed,
+			FilesFailed:    failed,
+			Error:          message,
+		})
+		h.recordDBOperation(spanCtx, "update", "file_exchange_run", dbStart, err)
+		span.SetAttributes(
+			attribute.Int64("file_exchange_run.id", run.ID),
+			attribute.Int("file_exchange_run.processed", int(processed)),
+			attribute.Int("file_exchange_run.failed", int(failed)),
+		)
+		return errors.Join(runErr, err)
+	})
+	if err != nil && !errors.Is(err, errFileExchangeRunning) {
+		span.RecordError(err)
+	}
+	return err
+}
+
+// runFileExchanges runs every enabled exchange that has work for the
+// scheduler, skipping exchanges that are already running. An exchange
+// that fails doesn't hold up the others.
+func (h *Handlers) runFileExchanges(ctx context.Context, opener exchange.Opener, poll, extract bool) error {
+	dbStart := time.Now()
+	exchanges, err := h.queries.ListEnabledFileExchanges(ctx)
+	h.recordDBOperation(ctx, "list", "file_exchange", dbStart, err)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, ex := range exchanges {
+		if !(poll && ex.InboundUrl != "") && !(extract && ex.OutboundUrl != "" && ex.ExtractFormat != "") {
+			continue
+		}
+		err := h.runFileExchange(ctx, opener, ex, exchangeTriggerSchedule, poll, extract)
+		if errors.Is(err, errFileExchangeRunning) {
+			slog.Debug("Skipped a file exchange that is running", slog.Int64("exchange_id", ex.ID))
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("file exchange %d: %w", ex.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// PollFileExchanges imports the files of the inbound folders of all
+// enabled exchanges
+func (h *Handlers) PollFileExchanges(ctx context.Context, opener exchange.Opener) error {
+	return h.runFileExchanges(ctx, opener, true, false)
+}
+
+// WriteFileExchangeExtracts writes the extracts of all enabled exchanges
+// to their outbound folders
+func (h *Handlers) WriteFileExchangeExtracts(ctx context.Context, opener exchange.Opener) error {
+	return h.runFileExchanges(ctx, opener, false, true)
+}
+
+// RunFileExchangeJob runs a run_file_exchange job, importing the inbound
+// files and writing the extract of the exchange whether it is enabled or
+// not. A run that fails is retried as the job.
+func (h *Handlers) RunFileExchangeJob(ctx context.Context, job models.Job, opener exchange.Opener) error {
+	var payload fileExchangeJob
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return fmt.Errorf("decode file exchange job: %w", err)
+	}
+
+	dbStart := time.Now()
+	ex, err := h.queries.GetFileExchange(ctx, models.GetFileExchangeParams{
+		ID:    payload.ExchangeID,
+		OrgID: job.OrgID,
+	})
+	h.recordDBOperation(ctx, "get", "file_exchange", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("file exchange %d was deleted", payload.ExchangeID)
+	}
+	if err != nil {
+		return err
+	}
+	return h.runFileExchange(ctx, opener, ex, exchangeTriggerManual, true, true)
+}
+
+// reprocessExchangeFile imports a failed inbound file again from its
+// recorded content
+func (h *Handlers) reprocessExchangeFile(ctx context.Context, ex models.FileExchange, fileID int64) (models.FileExchangeFile, error) {
+	var file models.FileExchangeFile
+	err := h.withExchangeLock(ctx, ex.ID, func() error {
+		dbStart := time.Now()
+		var err error
+		file, err = h.queries.GetFileExchangeFile(ctx, models.GetFileExchangeFileParams{
+			ID:         fileID,
+			ExchangeID: ex.ID,
+			OrgID:      ex.OrgID,
+		})
+		h.recordDBOperation(ctx, "get", "file_exchange_file", dbStart, err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &importError{status: http.StatusNotFound, message: "File not found"}
+		}
+		if err == nil && (file.Direction != ediInbound || file.Status != exchangeFileFailed) {
+			return &importError{status: http.StatusConflict, message: "Only failed inbound files can be reprocessed", field: "status"}
+		}
+		var data []byte
+		if err == nil {
+			dbStart = time.Now()
+			data, err = h.queries.GetFileExchangeContent(ctx, file.ID)
+			h.recordDBOperation(ctx, "get", "file_exchange_content", dbStart, err)
+		}
+		if err != nil {
+			return err
+		}
+		file, err = h.processExchangeFile(ctx, ex, file, data)
+		return err
+	})
+	if errors.Is(err, errFileExchangeRunning) {
+		err = &importError{status: http.StatusConflict, message: "The file exchange is running, try again when the run is over"}
+	}
+	return file, err
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseReceiptCSV(t *testing.T) {
+	receipts, err := parseReceiptCSV([]byte("\ufeffSKU, Reference ,quantity,unit_cost_cents,note\n" +
+		"A,PO-2,5,120,first\n" +
+		"B,PO-1,3,,\n" +
+		"C,PO-2,1,,\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(receipts) != 2 || receipts[0].Reference != "PO-2" || receipts[1].Reference != "PO-1" {
+		t.Fatalf("receipts %+v", receipts)
+	}
+	first := receipts[0].Lines
+	if len(first) != 2 || first[0].Sku != "A" || first[0].ExpectedQuantity != 5 || first[1].Sku != "C" {
+		t.Fatalf("lines %+v", first)
+	}
+	if first[0].UnitCostCents == nil || *first[0].UnitCostCents != 120 || first[1].UnitCostCents != nil {
+		t.Errorf("unit costs %v, %v", first[0].UnitCostCents, first[1].UnitCostCents)
+	}
+
+	for _, c := range []struct {
+		data string
+		want string
+	}{
+		{"", "empty"},
+		{"reference,sku\nPO-1,A\n", "quantity column"},
+		{"reference,sku,quantity\n", "no receipt lines"},
+		{"reference,sku,quantity\nPO-1,A,1\nPO-1,,2\n", "line 3"},
+		{"reference,sku,quantity\nPO-1,A,0\n", "positive"},
+		{"reference,sku,quantity,unit_cost_cents\nPO-1,A,1,-5\n", "non-negative"},
+		{"reference,sku,quantity\nPO-1,A\n", "wrong number of fields"},
+	} {
+		_, err := parseReceiptCSV([]byte(c.data))
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%q: got %v, want %q", c.data, err, c.want)
+		}
+	}
+}
+
+func TestExchangeFileFormat(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		data string
+		want string
+	}{
+		{"receipts.CSV", "ISA*00", exchangeFormatCSV},
+		{"asn.edi", "reference,sku", exchangeFormatEDI},
+		{"asn.x12", "", exchangeFormatEDI},
+		{"ASN0001", "\r\nISA*00*", exchangeFormatEDI},
+		{"receipts.txt", "reference,sku,quantity", exchangeFormatCSV},
+	} {
+		if got := exchangeFileFormat(c.name, []byte(c.data)); got != c.want {
+			t.Errorf("%s: got %s, want %s", c.name, got, c.want)
+		}
+	}
+}
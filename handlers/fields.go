@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fieldSelection is the ?fields= of a get or list request: the JSON names of
+// the response fields to keep, in the order of the DTO. nil keeps every
+// field.
+type fieldSelection []string
+
+// unknownFieldsError is a ?fields= naming fields the DTO doesn't have
+type unknownFieldsError struct {
+	unknown []string
+}
+
+func (e *unknownFieldsError) Error() string {
+	return "unknown fields " + strings.Join(e.unknown, ", ")
+}
+
+// parseFields reads a comma separated ?fields= against the fields of the
+// DTO T. Names match the JSON and the v1 names regardless of case, so
+// fields=name,city selects Name and City of a v1 response too.
+func parseFields[T any](raw string) (fieldSelection, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	known := dtoFields(reflect.TypeFor[T]())
+	wanted := map[string]bool{}
+	var unknown []string
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		found := false
+		for _, field := range known {
+			if strings.EqualFold(field.name, name) || strings.EqualFold(field.v1Name, name) {
+				wanted[field.name], found = true, true
+				break
+			}
+		}
+		if !found {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, &unknownFieldsError{unknown: unknown}
+	}
+
+	selection := make(fieldSelection, 0, len(wanted))
+	for _, field := range known {
+		if wanted[field.name] {
+			selection = append(selection, field.name)
+		}
+	}
+	return selection, nil
+}
+
+// fieldNames lists the fields of the DTO T by the names a response uses,
+// their v1 names with v1
+func fieldNames[T any](v1 bool) []string {
+	fields := dtoFields(reflect.TypeFor[T]())
+	names := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if v1 && f.v1Name != "" {
+			names = append(names, f.v1Name)
+		} else {
+			names = append(names, f.name)
+		}
+	}
+	return names
+}
+
+// bindFields reads the ?fields= of a v1 request for the DTO T, writing the
+// error response itself when it returns false
+func bindFields[T any](ctx *gin.Context) (fieldSelection, bool) {
+	fields, err := parseFields[T](ctx.Query("fields"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": fieldsErrorMessage(ctx, err, fieldNames[T](!prefersSnakeCase(ctx))),
+			"field": "fields",
+		})
+		return nil, false
+	}
+	return fields, true
+}
+
+// bindFieldsV2 is bindFields answering with a v2 error envelope
+func bindFieldsV2[T any](ctx *gin.Context) (fieldSelection, bool) {
+	fields, err := parseFields[T](ctx.Query("fields"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, envelope{Errors: []apiError{{
+			Code:    errCodeInvalidRequest,
+			Message: fieldsErrorMessage(ctx, err, fieldNames[T](false)),
+			Field:   "fields",
+		}}})
+		return nil, false
+	}
+	return fields, true
+}
+
+func fieldsErrorMessage(ctx *gin.Context, err error, known []string) string {
+	e := err.(*unknownFieldsError)
+	return tr(ctx, "Unknown fields %s, expected some of %s", strings.Join(e.unknown, ", "), strings.Join(known, ", "))
+}
+
+// one returns v, a DTO, serializing with the selected fields only
+func (s fieldSelection) one(v any) any {
+	if s == nil {
+		return v
+	}
+	return partialResponse{value: v, fields: s}
+}
+
+// selectFields returns vs serializing each element with the selected fields
+// only. A nil slice stays nil, as mapSlice leaves it.
+func selectFields[T any](s fieldSelection, vs []T) any {
+	if s == nil || vs == nil {
+		return vs
+	}
+	out := make([]partialResponse, 0, len(vs))
+	for _, v := range vs {
+		out = append(out, partialResponse{value: v, fields: s})
+	}
+	return out
+}
+
+// partialResponse serializes value keeping only fields, in their order
+type partialResponse struct {
+	value  any
+	fields fieldSelection
+}
+
+func (p partialResponse) MarshalJSON() ([]byte, error) {
+	full, err := json.Marshal(p.value)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(full, &values); err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for _, field := range p.fields {
+		value, ok := values[field]
+		if !ok {
+			continue
+		}
+		if b.Len() > 1 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(field)
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseFields(t *testing.T) {
+	tests := []struct {
+		raw   string
+		want  fieldSelection
+		fails bool
+	}{
+		{raw: "", want: nil},
+		{raw: "name,city,country", want: fieldSelection{"name", "city", "country"}},
+		{raw: " Country , name,,NAME", want: fieldSelection{"name", "country"}},
+		{raw: "TimeZone,time_zone", want: fieldSelection{"time_zone"}},
+		{raw: "id,distance", want: nil, fails: true},
+		{raw: "name,bogus", fails: true},
+	}
+	for _, tt := range tests {
+		got, err := parseFields[WarehouseResponse](tt.raw)
+		if (err != nil) != tt.fails || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseFields(%q) = %v, %v, want %v", tt.raw, got, err, tt.want)
+		}
+	}
+
+	got, err := parseFields[NearbyWarehouseResponse]("distance,name")
+	if err != nil || !reflect.DeepEqual(got, fieldSelection{"name", "distance"}) {
+		t.Errorf("embedded fields = %v, %v", got, err)
+	}
+	var unknown *unknownFieldsError
+	if _, err := parseFields[WarehouseV2]("name,Bogus"); !errors.As(err, &unknown) || !reflect.DeepEqual(unknown.unknown, []string{"Bogus"}) {
+		t.Errorf("unknown fields error %v", err)
+	}
+}
+
+func TestFieldSelection(t *testing.T) {
+	w := WarehouseV2{ID: 7, Name: "Central", City: "Hanoi", Tags: []string{"cold"}}
+	fields, err := parseFields[WarehouseV2]("tags,city,name")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := json.Marshal(fields.one(w))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"name":"Central","city":"Hanoi","tags":["cold"]}`; string(got) != want {
+		t.Errorf("one = %s, want %s", got, want)
+	}
+	got, _ = json.Marshal(selectFields(fields, []WarehouseV2{w, {ID: 8}}))
+	if want := `[{"name":"Central","city":"Hanoi","tags":["cold"]},{"name":"","city":"","tags":null}]`; string(got) != want {
+		t.Errorf("selectFields = %s, want %s", got, want)
+	}
+	if got, _ := json.Marshal(selectFields(fields, []WarehouseV2(nil))); string(got) != "null" {
+		t.Errorf("nil list = %s, want null", got)
+	}
+	if got := fieldSelection(nil).one(w); !reflect.DeepEqual(got, w) {
+		t.Errorf("no selection changed the value to %v", got)
+	}
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	"warehouse-service/geocode"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	defaultNearbyRadius = 50_000    // meters
+	maxNearbyRadius     = 1_000_000 // meters
+)
+
+func floatParam(f *float64) pgtype.Float8 {
+	if f == nil {
+		return pgtype.Float8{}
+	}
+	return pgtype.Float8{Float64: *f, Valid: true}
+}
+
+func floatPtr(f pgtype.Float8) *float64 {
+	if !f.Valid {
+		return nil
+	}
+	return &f.Float64
+}
+
+// validCoordinates checks that latitude and longitude are given together and in range
+func validCoordinates(lat, lng *float64) error {
+	if (lat == nil) != (lng == nil) {
+		return errors.New("latitude and longitude must be set together")
+	}
+	if lat == nil {
+		return nil
+	}
+	if *lat < -90 || *lat > 90 {
+		return errors.New("latitude must be between -90 and 90")
+	}
+	if *lng < -180 || *lng > 180 {
+		return errors.New("longitude must be between -180 and 180")
+	}
+	return nil
+}
+
+// formCoordinates reads the optional Latitude and Longitude form fields
+func formCoordinates(ctx *gin.Context) (*float64, *float64, error) {
+	parse := func(field string) (*float64, error) {
+		raw, ok := ctx.GetPostForm(field)
+		if !ok || raw == "" {
+			return nil, nil
+		}
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a number", field)
+		}
+		return &v, nil
+	}
+	lat, err := parse("Latitude")
+	if err != nil {
+		return nil, nil, err
+	}
+	lng, err := parse("Longitude")
+	if err != nil {
+		return nil, nil, err
+	}
+	return lat, lng, validCoordinates(lat, lng)
+}
+
+// coordinates returns the coordinates to store for a warehouse. Explicit
+// coordinates win, otherwise the address is geocoded when a geocoder is
+// configured. A failed lookup is logged and leaves the coordinates empty
+// rather than failing the write.
+func (h *Handlers) coordinates(ctx context.Context, lat, lng *float64, addressParts ...string) (pgtype.Float8, pgtype.Float8) {
+	if lat != nil && lng != nil {
+		return floatParam(lat), floatParam(lng)
+	}
+	if h.geocoder == nil {
+		return pgtype.Float8{}, pgtype.Float8{}
+	}
+	address := geocode.Address(addressParts...)
+	if address == "" {
+		return pgtype.Float8{}, pgtype.Float8{}
+	}
+
+	spanCtx, span := h.tracer.Start(ctx, "Geocode")
+	defer span.End()
+
+	geoLat, geoLng, err := h.geocoder.Geocode(spanCtx, address)
+	if err != nil {
+		slog.Warn("Could not geocode warehouse address", slog.String("street_address", address), slog.Any("err", err.Error()))
+		span.RecordError(err)
+		return pgtype.Float8{}, pgtype.Float8{}
+	}
+	return pgtype.Float8{Float64: geoLat, Valid: true}, pgtype.Float8{Float64: geoLng, Valid: true}
+}
+
+// NearbyWarehouses lists the tenant's warehouses within ?radius= meters of
+// ?lat= and ?lng=, closest first
+func (h *Handlers) NearbyWarehouses(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "NearbyWarehouses")
+	defer span.End()
+
+	lat, latErr := strconv.ParseFloat(ctx.Query("lat"), 64)
+	lng, lngErr := strconv.ParseFloat(ctx.Query("lng"), 64)
+	if latErr != nil || lngErr != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "Query parameters lat and lng are required numbers"),
+		})
+		return
+	}
+	if err := validCoordinates(&lat, &lng); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	radius, err := strconv.ParseFloat(ctx.DefaultQuery("radius", strconv.Itoa(defaultNearbyRadius)), 64)
+	if err != nil || radius <= 0 || radius > maxNearbyRadius {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "radius must be between 0 and %d meters", maxNearbyRadius),
+		})
+		return
+	}
+	limit, _, err := pageParams(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	fields, ok := bindFields[NearbyWarehouseResponse](ctx)
+	if !ok {
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.Float64("geo.lat", lat),
+		attribute.Float64("geo.lng", lng),
+		attribute.Float64("geo.radius", radius),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	warehouses, err := h.readQueries(spanCtx).ListNearbyWarehouses(spanCtx, models.ListNearbyWarehousesParams{
+		Lat:       lat,
+		Lng:       lng,
+		OrgID:     orgID,
+		Radius:    radius,
+		PageLimit: limit,
+	})
+	h.recordDBOperation(spanCtx, "list", "warehouse", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while listing nearby warehouses: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to list nearby warehouses"),
+		})
+		return
+	}
+
+	warehouses = authorizedRows(h, ctx, observability.EntityWarehouse, warehouses, func(w models.ListNearbyWarehousesRow) string { return w.OrgID })
+	span.SetAttributes(
+		attribute.Int("warehouse.count", len(warehouses)),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "List Nearby Warehouses Successfully"),
+		"data":    selectFields(fields, mapSlice(warehouses, newNearbyWarehouseResponse)),
+	})
+}
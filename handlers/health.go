@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"time"
+	"warehouse-service/observability"
 
 	"github.com/gin-gonic/gin"
 )
@@ -25,12 +26,22 @@ func (h *Handlers) ReadyzHandler(ctx *gin.Context) {
 			"status":    "not ready",
 			"timestamp": time.Now().UTC().Format(time.RFC3339),
 			"service":   "warehouse-service",
-			"error":     "database connection failed",
+			"error":     tr(ctx, "database connection failed"),
 			"details":   err.Error(),
 		})
 		return
 	}
 
+	// A log sink that is down fails over, so it degrades logging without
+	// making the service unready
+	sinks := observability.LogSinks()
+	logging := "ok"
+	for _, sink := range sinks {
+		if sink.State != observability.LogSinkUp {
+			logging = "degraded"
+		}
+	}
+
 	// All checks passed
 	ctx.JSON(http.StatusOK, gin.H{
 		"status":    "ready",
@@ -38,6 +49,8 @@ func (h *Handlers) ReadyzHandler(ctx *gin.Context) {
 		"service":   "warehouse-service",
 		"checks": gin.H{
 			"database": "ok",
+			"logging":  logging,
 		},
+		"log_sinks": sinks,
 	})
-}
\ No newline at end of file
+}
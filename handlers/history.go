@@ -0,0 +1,270 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+	"warehouse-service/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// WarehouseVersionResponse is a warehouse as it was from ValidFrom until
+// ValidTo, which is null for the current version. Operation is insert,
+// update, delete or snapshot for rows that existed when history started.
+type WarehouseVersionResponse struct {
+	Version   int64             `json:"version" v1:"Version"`
+	Operation string            `json:"operation" v1:"Operation"`
+	ValidFrom *time.Time        `json:"valid_from" v1:"ValidFrom"`
+	ValidTo   *time.Time        `json:"valid_to" v1:"ValidTo"`
+	Warehouse WarehouseResponse `json:"warehouse" v1:"Warehouse"`
+}
+
+type StorageRoomVersionResponse struct {
+	Version     int64               `json:"version" v1:"Version"`
+	Operation   string              `json:"operation" v1:"Operation"`
+	ValidFrom   *time.Time          `json:"valid_from" v1:"ValidFrom"`
+	ValidTo     *time.Time          `json:"valid_to" v1:"ValidTo"`
+	StorageRoom StorageRoomResponse `json:"storage_room" v1:"StorageRoom"`
+}
+
+func newWarehouseVersionResponse(v models.ListWarehouseHistoryRow) WarehouseVersionResponse {
+	return WarehouseVersionResponse{
+		Version:   v.Version,
+		Operation: v.Operation,
+		ValidFrom: timePtr(v.ValidFrom),
+		ValidTo:   timePtr(v.ValidTo),
+		Warehouse: newWarehouseResponse(models.Warehouse{
+			ID:             v.ID,
+			Name:           v.Name,
+			Address:        v.Address,
+			Ward:           v.Ward,
+			District:       v.District,
+			City:           v.City,
+			Country:        v.Country,
+			OrgID:          v.OrgID,
+			Latitude:       v.Latitude,
+			Longitude:      v.Longitude,
+			TimeZone:       v.TimeZone,
+			OperatingHours: v.OperatingHours,
+			ContactEmail:   v.ContactEmail,
+			ContactPhone:   v.ContactPhone,
+			Tags:           v.Tags,
+			Attributes:     v.Attributes,
+			Status:         v.Status,
+			Slug:           v.Slug,
+		}),
+	}
+}
+
+func newStorageRoomVersionResponse(v models.ListStorageRoomHistoryRow) StorageRoomVersionResponse {
+	return StorageRoomVersionResponse{
+		Version:   v.Version,
+		Operation: v.Operation,
+		ValidFrom: timePtr(v.ValidFrom),
+		ValidTo:   timePtr(v.ValidTo),
+		StorageRoom: newStorageRoomResponse(models.StorageRoom{
+			ID:          v.ID,
+			Name:        v.Name,
+			Number:      v.Number,
+			WarehouseID: v.WarehouseID,
+			OrgID:       v.OrgID,
+			Attributes:  v.Attributes,
+			ZoneType:    v.ZoneType,
+			Tags:        v.Tags,
+			Capacity:    v.Capacity,
+			Aisle:       v.Aisle,
+			Bay:         v.Bay,
+		}),
+	}
+}
+
+// asOfParam reads ?as_of=, null when missing. A date stands for the end of
+// that day in UTC, so it finds the last version of the day.
+func asOfParam(ctx *gin.Context) (pgtype.Timestamptz, error) {
+	value := ctx.Query("as_of")
+	if value == "" {
+		return pgtype.Timestamptz{}, nil
+	}
+	t, dateOnly, err := parseReportTime(value)
+	if err != nil {
+		return pgtype.Timestamptz{}, fmt.Errorf("as_of must be a date or an RFC 3339 time, got %q", value)
+	}
+	if dateOnly {
+		t = t.AddDate(0, 0, 1).Add(-time.Microsecond)
+	}
+	return pgtype.Timestamptz{Time: t, Valid: true}, nil
+}
+
+// historyParams reads the ?as_of= and page of a history request, writing
+// the error response itself when it returns false
+func historyParams(ctx *gin.Context) (asOf pgtype.Timestamptz, limit, offset int32, ok bool) {
+	asOf, err := asOfParam(ctx)
+	if err == nil {
+		limit, offset, err = pageParams(ctx)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return asOf, 0, 0, false
+	}
+	return asOf, limit, offset, true
+}
+
+// listWarehouseHistory reads the versions of a warehouse newest first. The
+// history outlives the warehouse, so deleted warehouses can be looked up.
+func (h *Handlers) listWarehouseHistory(ctx context.Context, params models.ListWarehouseHistoryParams) ([]models.ListWarehouseHistoryRow, error) {
+	dbStart := time.Now()
+	versions, err := h.readQueries(ctx).ListWarehouseHistory(ctx, params)
+	h.recordDBOperation(ctx, "list", "row_history", dbStart, err)
+	return versions, err
+}
+
+// getWarehouseAsOf answers GET /v1/warehouse/:id?as_of= with the warehouse
+// as it was then, 404 when it did not exist at the time
+func (h *Handlers) getWarehouseAsOf(ctx *gin.Context, spanCtx context.Context, id int64, fields fieldSelection) {
+	asOf, err := asOfParam(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	versions, err := h.listWarehouseHistory(spanCtx, models.ListWarehouseHistoryParams{
+		ID:    id,
+		OrgID: tenantID(ctx),
+		AsOf:  asOf,
+		Limit: 1,
+	})
+	if err != nil {
+		slog.Error("Got an error while getting warehouse history: ", slog.Any("err", err.Error()))
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to get warehouse"),
+		})
+		return
+	}
+	if len(versions) == 0 {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Warehouse not found"),
+		})
+		return
+	}
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Get Warehouse Successfully"),
+		"data":    fields.one(newWarehouseVersionResponse(versions[0]).Warehouse),
+	})
+}
+
+// GetWarehouseHistory lists the versions of a warehouse newest first, or
+// with ?as_of= only the version current at that time
+func (h *Handlers) GetWarehouseHistory(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "GetWarehouseHistory")
+	defer span.End()
+
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "Invalid warehouse ID format"),
+		})
+		return
+	}
+	asOf, limit, offset, ok := historyParams(ctx)
+	if !ok {
+		return
+	}
+	orgID := tenantID(ctx)
+	traceOperation(ctx, span, observability.EntityWarehouse, id)
+
+	versions, err := h.listWarehouseHistory(spanCtx, models.ListWarehouseHistoryParams{
+		ID:     id,
+		OrgID:  orgID,
+		AsOf:   asOf,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		slog.Error("Got an error while listing warehouse history: ", slog.Any("err", err.Error()))
+		tracing.Failed(span, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to get warehouse history"),
+		})
+		return
+	}
+	if len(versions) == 0 && offset == 0 {
+		tracing.Result(span, observability.StatusNotFound)
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Warehouse not found"),
+		})
+		return
+	}
+
+	span.SetAttributes(attribute.Int("warehouse.versions", len(versions)))
+	tracing.Result(span, observability.StatusSuccess)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Get Warehouse History Successfully"),
+		"data":    mapSlice(versions, newWarehouseVersionResponse),
+	})
+}
+
+// GetStorageRoomHistory lists the versions of a storage room newest first,
+// or with ?as_of= only the version current at that time
+func (h *Handlers) GetStorageRoomHistory(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "GetStorageRoomHistory")
+	defer span.End()
+
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "Invalid storage room ID format"),
+		})
+		return
+	}
+	asOf, limit, offset, ok := historyParams(ctx)
+	if !ok {
+		return
+	}
+	orgID := tenantID(ctx)
+	traceOperation(ctx, span, observability.EntityStorageRoom, id)
+
+	dbStart := time.Now()
+	versions, err := h.readQueries(spanCtx).ListStorageRoomHistory(spanCtx, models.ListStorageRoomHistoryParams{
+		ID:     id,
+		OrgID:  orgID,
+		AsOf:   asOf,
+		Limit:  limit,
+		Offset: offset,
+	})
+	h.recordDBOperation(spanCtx, "list", "row_history", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while listing storage room history: ", slog.Any("err", err.Error()))
+		tracing.Failed(span, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to get storage room history"),
+		})
+		return
+	}
+	if len(versions) == 0 && offset == 0 {
+		tracing.Result(span, observability.StatusNotFound)
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Storage room not found"),
+		})
+		return
+	}
+
+	span.SetAttributes(attribute.Int("storage_room.versions", len(versions)))
+	tracing.Result(span, observability.StatusSuccess)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Get Storage Room History Successfully"),
+		"data":    mapSlice(versions, newStorageRoomVersionResponse),
+	})
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAsOfParam(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+		fails bool
+	}{
+		{query: ""},
+		{query: "as_of=2024-03-01", want: "2024-03-01T23:59:59.999999Z"},
+		{query: "as_of=2024-03-01T06:00:00%2B07:00", want: "2024-03-01T06:00:00+07:00"},
+		{query: "as_of=yesterday", fails: true},
+	}
+	for _, tt := range tests {
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		ctx.Request = httptest.NewRequest("GET", "/v1/warehouse/1/history?"+tt.query, nil)
+		asOf, err := asOfParam(ctx)
+		if (err != nil) != tt.fails {
+			t.Errorf("%q: err = %v", tt.query, err)
+			continue
+		}
+		if tt.want == "" {
+			if asOf.Valid {
+				t.Errorf("%q: as of %v, want none", tt.query, asOf.Time)
+			}
+			continue
+		}
+		if got := asOf.Time.Format(time.RFC3339Nano); got != tt.want {
+			t.Errorf("%q: as of %s, want %s", tt.query, got, tt.want)
+		}
+	}
+}
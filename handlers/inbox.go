@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+	"warehouse-service/inbox"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// inboxActor is recorded in the audit log for changes made by consumed
+// messages
+const inboxActor = "system:inbox"
+
+// orderCreatedEvent is the payload of order.created, an order placed in
+// order-service to be shipped from a warehouse
+type orderCreatedEvent struct {
+	OrderID string `json:"order_id"`
+	// Order reference the pick list carries, OrderID when empty
+	Reference   string            `json:"reference"`
+	WarehouseID int64             `json:"warehouse_id"`
+	Lines       []pickItemRequest `json:"lines"`
+}
+
+func (e orderCreatedEvent) validate() error {
+	if e.OrderID == "" {
+		return errors.New("order_id is required")
+	}
+	if e.WarehouseID <= 0 {
+		return errors.New("warehouse_id is required")
+	}
+	if len(e.Lines) == 0 {
+		return errors.New("lines are required")
+	}
+	for _, line := range e.Lines {
+		if strings.TrimSpace(line.Sku) == "" || line.Quantity <= 0 {
+			return fmt.Errorf("line %+v needs a sku and a positive quantity", line)
+		}
+	}
+	return nil
+}
+
+// consumeOrderCreated reserves the stock of a new order as a pick list. An
+// order the warehouse can't fill in full reserves nothing and is rejected
+// with the shortages.
+func (h *Handlers) consumeOrderCreated(ctx context.Context, qtx *models.Queries, msg inbox.Message) error {
+	opStart := time.Now()
+	var event orderCreatedEvent
+	if err := json.Unmarshal(msg.Payload, &event); err != nil {
+		return inbox.Reject(fmt.Errorf("decode payload: %w", err))
+	}
+	if err := event.validate(); err != nil {
+		return inbox.Reject(err)
+	}
+	if event.Reference == "" {
+		event.Reference = event.OrderID
+	}
+
+	dbStart := time.Now()
+	_, err := qtx.GetWarehouse(ctx, models.GetWarehouseParams{
+		ID:    event.WarehouseID,
+		OrgID: msg.OrgID,
+	})
+	h.recordDBOperation(ctx, "get", "warehouse", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return inbox.Reject(fmt.Errorf("warehouse %d not found", event.WarehouseID))
+	}
+	if err != nil {
+		return err
+	}
+
+	pickList, _, shortages, err := h.allocatePickList(ctx, qtx, msg.OrgID, event.WarehouseID, event.Reference, pickStrategyFIFO, pgtype.Int8{}, event.Lines)
+	if rejected, ok := unitRejected(err); ok {
+		return inbox.Reject(rejected)
+	}
+	if err != nil {
+		return err
+	}
+	if len(shortages) > 0 {
+		return inbox.Reject(errors.New(shortageMessage(shortages)))
+	}
+	h.recordOperation(msg.OrgID, observability.EntityPickList, "create", opStart, nil)
+	return h.recordAudit(ctx, qtx, auditEntry{
+		OrgID:      msg.OrgID,
+		EntityType: auditEntityPickList,
+		EntityID:   pickList.ID,
+		Action:     "create",
+		ToStatus:   pickList.Status,
+		Actor:      inboxActor,
+	})
+}
+
+// ConsumeMessageResponse is what consuming a message came to, one of the
+// inbox outcomes
+type ConsumeMessageResponse struct {
+	Outcome string `json:"outcome" v1:"Outcome"`
+}
+
+// ConsumeMessage hands a message from the broker to the handler of its
+// topic, see inbox.Consumer.Consume
+func (h *Handlers) ConsumeMessage(ctx context.Context, msg inbox.Message) (string, error) {
+	return h.inbox.Consume(ctx, msg)
+}
+
+// ReceiveInboxMessage is where the broker bridge pushes messages. A 200
+// acknowledges the message, also a duplicate or a rejected one; a 503 asks
+// for it to be delivered again.
+func (h *Handlers) ReceiveInboxMessage(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "ReceiveInboxMessage")
+	defer span.End()
+
+	if !ctx.GetBool("service_account") {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": tr(ctx, "Only backend services deliver messages"),
+		})
+		return
+	}
+	var msg inbox.Message
+	if err := ctx.ShouldBindJSON(&msg); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   tr(ctx, "Invalid message"),
+			"details": err.Error(),
+		})
+		return
+	}
+	span.SetAttributes(
+		attribute.String("messaging.destination.name", msg.Topic),
+		attribute.String("tenant.id", msg.OrgID),
+	)
+
+	outcome, err := h.ConsumeMessage(spanCtx, msg)
+	if err != nil {
+		slog.Error("Could not consume message: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": tr(ctx, "Failed to consume message"),
+		})
+		return
+	}
+
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Consume Message Successfully"),
+		"data":    ConsumeMessageResponse{Outcome: outcome},
+	})
+}
+
+// PruneProcessedMessages forgets messages processed more than retention
+// ago. A message delivered again after that is handled again.
+func (h *Handlers) PruneProcessedMessages(ctx context.Context, retention time.Duration) error {
+	spanCtx, span := h.tracer.Start(ctx, "PruneProcessedMessages")
+	defer span.End()
+
+	dbStart := time.Now()
+	deleted, err := h.queries.DeleteProcessedMessagesBefore(spanCtx, pgtype.Timestamptz{Time: time.Now().Add(-retention), Valid: true})
+	h.recordDBOperation(spanCtx, "delete", "processed_message", dbStart, err)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	span.SetAttributes(attribute.Int64("processed_message.deleted", deleted))
+	if deleted > 0 {
+		slog.Info("Pruned processed messages", slog.Int64("deleted", deleted))
+	}
+	return nil
+}
@@ -0,0 +1,482 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"warehouse-service/integrations"
+	"warehouse-service/jobs"
+	"warehouse-service/listquery"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type integrationRequest struct {
+	Name string `json:"name" binding:"required"`
+	Kind string `json:"kind" binding:"required,oneof=shopify webhook"`
+	// Shop of a Shopify integration, endpoint levels are posted to for a
+	// webhook
+	URL string `json:"url" binding:"required"`
+	// Endpoint a webhook pulls orders from, none when left out
+	OrdersURL string `json:"orders_url"`
+	// Token authenticating with the channel, the current one is kept when
+	// left out of an update
+	Token *string `json:"token"`
+	// Shopify location levels are set at
+	LocationID string `json:"location_id"`
+	// Warehouse whose stock is pushed and where orders are reserved, by
+	// ID or slug
+	WarehouseID WarehouseRef `json:"warehouse_id" binding:"required"`
+	// Whether the scheduler syncs the integration, true when left out
+	Enabled *bool `json:"enabled"`
+
+	// ID of the warehouse, once bindIntegrationRequest resolved it
+	warehouseID int64
+}
+
+func parseIntegrationID(ctx *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "Invalid integration ID format"),
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// bindIntegrationRequest reads an integration, keeping the token of
+// current when the request leaves it out, and checks its settings and
+// warehouse
+func (h *Handlers) bindIntegrationRequest(ctx *gin.Context, orgID string, current models.Integration) (integrationRequest, string, bool) {
+	var req integrationRequest
+	token := current.Token
+	err := ctx.ShouldBindJSON(&req)
+	if err == nil {
+		req.Name = strings.TrimSpace(req.Name)
+		req.URL = strings.TrimSpace(req.URL)
+		req.OrdersURL = strings.TrimSpace(req.OrdersURL)
+		req.LocationID = strings.TrimSpace(req.LocationID)
+		if req.Token != nil {
+			token = strings.TrimSpace(*req.Token)
+		}
+		if req.Name == "" {
+			err = errors.New("name is required")
+		}
+	}
+	if err == nil {
+		_, err = integrations.New(integrations.Settings{
+			Kind:       req.Kind,
+			URL:        req.URL,
+			OrdersURL:  req.OrdersURL,
+			Token:      token,
+			LocationID: req.LocationID,
+		})
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   tr(ctx, "Invalid integration payload"),
+			"details": err.Error(),
+		})
+		return req, token, false
+	}
+
+	spanCtx := ctx.Request.Context()
+	req.warehouseID, err = h.resolveWarehouse(spanCtx, h.queries, orgID, req.WarehouseID)
+	if err == nil {
+		dbStart := time.Now()
+		_, err = h.queries.GetWarehouse(spanCtx, models.GetWarehouseParams{
+			ID:    req.warehouseID,
+			OrgID: orgID,
+		})
+		h.recordDBOperation(spanCtx, "get", "warehouse", dbStart, err)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Warehouse not found"),
+		})
+		return req, token, false
+	}
+	if err != nil {
+		slog.Error("Got an error while getting warehouse: ", slog.Any("err", err.Error()))
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to save integration"),
+		})
+		return req, token, false
+	}
+	return req, token, true
+}
+
+// getIntegration looks up the integration of the :id parameter, writing
+// the error response when it can't
+func (h *Handlers) getIntegration(ctx *gin.Context, orgID string) (models.Integration, bool) {
+	id, ok := parseIntegrationID(ctx)
+	if !ok {
+		return models.Integration{}, false
+	}
+	spanCtx := ctx.Request.Context()
+	dbStart := time.Now()
+	in, err := h.queries.GetIntegration(spanCtx, models.GetIntegrationParams{
+		ID:    id,
+		OrgID: orgID,
+	})
+	h.recordDBOperation(spanCtx, "get", "integration", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Integration not found"),
+		})
+		return in, false
+	}
+	if err != nil {
+		slog.Error("Got an error while getting integration: ", slog.Any("err", err.Error()))
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to get integration"),
+		})
+		return in, false
+	}
+	return in, true
+}
+
+// CreateIntegration connects a channel. Orders the channel updates from
+// now on are pulled; orders placed before are left alone.
+func (h *Handlers) CreateIntegration(ctx *gin.Context) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "CreateIntegration")
+	defer span.End()
+
+	orgID := tenantID(ctx)
+	req, token, ok := h.bindIntegrationRequest(ctx, orgID, models.Integration{})
+	if !ok {
+		return
+	}
+	span.SetAttributes(
+		attribute.String("integration.kind", req.Kind),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	in, err := h.queries.CreateIntegration(spanCtx, models.CreateIntegrationParams{
+		OrgID:       orgID,
+		Name:        req.Name,
+		Kind:        req.Kind,
+		Url:         req.URL,
+		OrdersUrl:   req.OrdersURL,
+		Token:       token,
+		LocationID:  req.LocationID,
+		WarehouseID: req.warehouseID,
+		Enabled:     req.Enabled == nil || *req.Enabled,
+	})
+	h.recordDBOperation(spanCtx, "create", "integration", dbStart, err)
+	if conflict, ok := conflictFor(err); ok {
+		h.recordOperation(orgID, observability.EntityIntegration, "create", opStart, err)
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": tr(ctx, conflict.message),
+			"field": conflict.field,
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Could not create integration: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		h.recordOperation(orgID, observability.EntityIntegration, "create", opStart, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to create integration"),
+		})
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntityIntegration, "create", opStart, nil)
+
+	span.SetAttributes(
+		attribute.Int64("integration.id", in.ID),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusCreated, gin.H{
+		"message": tr(ctx, "Create Integration Successfully"),
+		"data":    newIntegrationResponse(in),
+	})
+}
+
+// GetIntegration returns an integration with its sync state
+func (h *Handlers) GetIntegration(ctx *gin.Context) {
+	// Start a new span for this operation
+	_, span := h.tracer.Start(ctx.Request.Context(), "GetIntegration")
+	defer span.End()
+
+	orgID := tenantID(ctx)
+	span.SetAttributes(attribute.String("tenant.id", orgID))
+	in, ok := h.getIntegration(ctx, orgID)
+	if !ok {
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int64("integration.id", in.ID),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Get Integration Successfully"),
+		"data":    newIntegrationResponse(in),
+	})
+}
+
+func (h *Handlers) ListIntegrations(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "ListIntegrations")
+	defer span.End()
+
+	orgID := tenantID(ctx)
+	span.SetAttributes(attribute.String("tenant.id", orgID))
+
+	dbStart := time.Now()
+	list, err := h.queries.ListIntegrations(spanCtx, orgID)
+	h.recordDBOperation(spanCtx, "list", "integration", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while listing integrations: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to list integrations"),
+		})
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int("integration.count", len(list)),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "List Integrations Successfully"),
+		"data":    mapSlice(list, newIntegrationResponse),
+	})
+}
+
+// UpdateIntegration replaces the settings of an integration. Its sync
+// state is kept: stock already pushed isn't pushed again until it changes,
+// also after moving to another warehouse or shop.
+func (h *Handlers) UpdateIntegration(ctx *gin.Context) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "UpdateIntegration")
+	defer span.End()
+
+	orgID := tenantID(ctx)
+	span.SetAttributes(attribute.String("tenant.id", orgID))
+	current, ok := h.getIntegration(ctx, orgID)
+	if !ok {
+		return
+	}
+	req, token, ok := h.bindIntegrationRequest(ctx, orgID, current)
+	if !ok {
+		return
+	}
+	span.SetAttributes(attribute.Int64("integration.id", current.ID))
+
+	dbStart := time.Now()
+	in, err := h.queries.UpdateIntegration(spanCtx, models.UpdateIntegrationParams{
+		ID:          current.ID,
+		OrgID:       orgID,
+		Name:        req.Name,
+		Kind:        req.Kind,
+		Url:         req.URL,
+		OrdersUrl:   req.OrdersURL,
+		Token:       token,
+		LocationID:  req.LocationID,
+		WarehouseID: req.warehouseID,
+		Enabled:     req.Enabled == nil || *req.Enabled,
+	})
+	h.recordDBOperation(spanCtx, "update", "integration", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Integration not found"),
+		})
+		return
+	}
+	if conflict, ok := conflictFor(err); ok {
+		h.recordOperation(orgID, observability.EntityIntegration, "update", opStart, err)
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": tr(ctx, conflict.message),
+			"field": conflict.field,
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Could not update integration: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		h.recordOperation(orgID, observability.EntityIntegration, "update", opStart, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to update integration"),
+		})
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntityIntegration, "update", opStart, nil)
+
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Update Integration Successfully"),
+		"data":    newIntegrationResponse(in),
+	})
+}
+
+// DeleteIntegration disconnects a channel. The pick lists reserving its
+// orders stay.
+func (h *Handlers) DeleteIntegration(ctx *gin.Context) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "DeleteIntegration")
+	defer span.End()
+
+	id, ok := parseIntegrationID(ctx)
+	if !ok {
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.Int64("integration.id", id),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	rows, err := h.queries.DeleteIntegration(spanCtx, models.DeleteIntegrationParams{
+		ID:    id,
+		OrgID: orgID,
+	})
+	h.recordDBOperation(spanCtx, "delete", "integration", dbStart, err)
+	if err != nil {
+		slog.Error("Could not delete integration: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		h.recordOperation(orgID, observability.EntityIntegration, "delete", opStart, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to delete integration"),
+		})
+		return
+	}
+	if rows == 0 {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Integration not found"),
+		})
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntityIntegration, "delete", opStart, nil)
+
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": tr(ctx, "Delete Integration Successfully"),
+	})
+}
+
+// SyncIntegration queues a sync of an integration, enabled or not and
+// whether it is due or not
+func (h *Handlers) SyncIntegration(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "SyncIntegration")
+	defer span.End()
+
+	orgID := tenantID(ctx)
+	span.SetAttributes(attribute.String("tenant.id", orgID))
+	in, ok := h.getIntegration(ctx, orgID)
+	if !ok {
+		return
+	}
+
+	dbStart := time.Now()
+	job, err := jobs.Enqueue(spanCtx, h.queries, orgID, JobKindSyncIntegration, integrationJob{
+		IntegrationID: in.ID,
+	}, jobs.EnqueueOptions{})
+	h.recordDBOperation(spanCtx, "create", "job", dbStart, err)
+	if err != nil {
+		slog.Error("Could not queue integration sync: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to sync integration"),
+		})
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int64("integration.id", in.ID),
+		attribute.Int64("job.id", job.ID),
+		attribute.String("operation.status", "success"),
+	)
+	ctx.Header("Location", fmt.Sprintf("/v1/jobs/%d", job.ID))
+	respondV1(ctx, http.StatusAccepted, gin.H{
+		"message": tr(ctx, "Sync Integration Successfully"),
+		"data":    newJobResponse(job),
+	})
+}
+
+// integrationOrderListSpec sorts and filters the orders of
+// ListIntegrationOrders
+var integrationOrderListSpec = &listquery.Spec{
+	Table: "integration_order",
+	Sort: map[string]string{
+		"id":                  "id",
+		"reference":           "reference",
+		"status":              "status",
+		"external_updated_at": "external_updated_at",
+		"created_at":          "created_at",
+	},
+	Filters: map[string]listquery.Filter{
+		"status":      {Column: "status", Op: listquery.Eq, Parse: listquery.Text},
+		"external_id": {Column: "external_id", Op: listquery.Eq, Parse: listquery.Text},
+	},
+	Default: []listquery.Order{{Field: "id", Desc: true}},
+	Key:     "id",
+}
+
+// ListIntegrationOrders lists the orders pulled from an integration,
+// newest first
+func (h *Handlers) ListIntegrationOrders(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "ListIntegrationOrders")
+	defer span.End()
+
+	id, ok := parseIntegrationID(ctx)
+	if !ok {
+		return
+	}
+	q, err := listParams(ctx, integrationOrderListSpec)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	q.Where("org_id", listquery.Eq, orgID).Where("integration_id", listquery.Eq, id)
+	span.SetAttributes(
+		attribute.Int64("integration.id", id),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	orders, err := queryPage[models.IntegrationOrder](ctx, spanCtx, h.db, q)
+	h.recordDBOperation(spanCtx, "list", "integration_order", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while listing integration orders: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to list integration orders"),
+		})
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int("integration_order.count", len(orders)),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "List Integration Orders Successfully"),
+		"data":    mapSlice(orders, newIntegrationOrderResponse),
+	})
+}
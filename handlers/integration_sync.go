@@ -0,0 +1,514 @@
+package handlers
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+	"warehouse-service/integrations"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// JobKindSyncIntegration is the background job syncing an integration on
+// request
+const JobKindSyncIntegration = "sync_integration"
+
+// Statuses of orders pulled from integrations
+const (
+	integrationOrderReserved  = "reserved"
+	integrationOrderShort     = "short"
+	integrationOrderCancelled = "cancelled"
+	integrationOrderConflict  = "conflict"
+)
+
+// integrationActor is recorded in the audit log for pick lists of the
+// orders of integrations
+const integrationActor = "system:integration"
+
+const (
+	// integrationOrdersOverlap is how far before the last order pulled
+	// orders are pulled again, for orders the channel saved late
+	integrationOrdersOverlap = time.Minute
+	// The scheduler waits integrationRetryBase after a failed sync,
+	// doubling for each failure in a row up to integrationRetryMax
+	integrationRetryBase = time.Minute
+	integrationRetryMax  = time.Hour
+)
+
+var (
+	errIntegrationSyncing = errors.New("the integration is syncing")
+	errOrderShort         = errors.New("insufficient stock")
+)
+
+type integrationJob struct {
+	IntegrationID int64 `json:"integration_id"`
+}
+
+func integrationSettings(in models.Integration) integrations.Settings {
+	return integrations.Settings{
+		Kind:       in.Kind,
+		URL:        in.Url,
+		OrdersURL:  in.OrdersUrl,
+		Token:      in.Token,
+		LocationID: in.LocationID,
+	}
+}
+
+// integrationRetryDelay is the wait before syncing again after failures
+// failed syncs in a row, at least what a rate limit asked for
+func integrationRetryDelay(failures int32, err error) time.Duration {
+	delay := integrationRetryBase
+	for i := int32(1); i < failures && delay < integrationRetryMax; i++ {
+		delay *= 2
+	}
+	delay = min(delay, integrationRetryMax)
+	var limited *integrations.RateLimitError
+	if errors.As(err, &limited) {
+		delay = max(delay, limited.RetryAfter)
+	}
+	return delay
+}
+
+// availabilityChanges returns the levels that differ from the ones last
+// pushed. A SKU pushed before that has no stock left is pushed as 0.
+func availabilityChanges(current []models.ListAvailableStockRow, pushed []models.IntegrationStock) []integrations.Level {
+	last := make(map[string]int64, len(pushed))
+	for _, p := range pushed {
+		last[p.Sku] = p.Available
+	}
+	var levels []integrations.Level
+	for _, row := range current {
+		if before, ok := last[row.Sku]; !ok || before != row.Available {
+			levels = append(levels, integrations.Level{Sku: row.Sku, Available: row.Available})
+		}
+		delete(last, row.Sku)
+	}
+	for _, sku := range slices.Sorted(maps.Keys(last)) {
+		if last[sku] != 0 {
+			levels = append(levels, integrations.Level{Sku: sku, Available: 0})
+		}
+	}
+	return levels
+}
+
+// encodeOrderLines encodes lines sorted by SKU, so the lines of an order
+// compare equal however the channel orders them
+func encodeOrderLines(lines []integrations.OrderLine) ([]byte, error) {
+	sorted := append([]integrations.OrderLine{}, lines...)
+	slices.SortFunc(sorted, func(a, b integrations.OrderLine) int { return cmp.Compare(a.Sku, b.Sku) })
+	return json.Marshal(sorted)
+}
+
+func shortageMessage(shortages []pickShortage) string {
+	parts := make([]string, len(shortages))
+	for i, s := range shortages {
+		parts[i] = fmt.Sprintf("%s (%d of %d)", s.Sku, s.Allocated, s.Requested)
+	}
+	return "Insufficient stock for " + strings.Join(parts, ", ")
+}
+
+func (h *Handlers) withIntegrationLock(ctx context.Context, integrationID int64, fn func() error) error {
+	// The lock's transaction does nothing else, fn records its work on
+	// connections of its own
+	return pgx.BeginFunc(ctx, h.db, func(tx pgx.Tx) error {
+		locked, err := h.queries.WithTx(tx).TryLockRefresh(ctx, fmt.Sprintf("integration:%d", integrationID))
+		if err != nil {
+			return err
+		}
+		if !locked {
+			return errIntegrationSyncing
+		}
+		return fn()
+	})
+}
+
+// reserveOrderLines allocates a pick list for the lines of an order in a
+// savepoint of tx. When the warehouse is short of stock nothing is
+// allocated and the shortage is returned as the message.
+func (h *Handlers) reserveOrderLines(ctx context.Context, tx pgx.Tx, in models.Integration, reference string, lines []integrations.OrderLine) (pgtype.Int8, string, error) {
+	items := make([]pickItemRequest, len(lines))
+	for i, line := range lines {
+		items[i] = pickItemRequest{Sku: line.Sku, Quantity: line.Quantity}
+	}
+	var pickListID pgtype.Int8
+	var shortages []pickShortage
+	err := pgx.BeginFunc(ctx, tx, func(sp pgx.Tx) error {
+		qsp := h.queries.WithTx(sp)
+		pickList, _, short, err := h.allocatePickList(ctx, qsp, in.OrgID, in.WarehouseID, reference, pickStrategyFIFO, pgtype.Int8{}, items)
+		if err != nil {
+			return err
+		}
+		if len(short) > 0 {
+			shortages = short
+			return errOrderShort
+		}
+		pickListID = pgtype.Int8{Int64: pickList.ID, Valid: true}
+		return h.recordAudit(ctx, qsp, auditEntry{
+			OrgID:      in.OrgID,
+			EntityType: auditEntityPickList,
+			EntityID:   pickList.ID,
+			Action:     "create",
+			ToStatus:   pickList.Status,
+			Actor:      integrationActor,
+		})
+	})
+	if errors.Is(err, errOrderShort) {
+		return pgtype.Int8{}, shortageMessage(shortages), nil
+	}
+	return pickListID, "", err
+}
+
+// reviseOrder applies an order that changed in the channel. Its pick list
+// is cancelled and the order reserved again, unless the pick list was
+// picked already: stock on its way out can't be taken back, so the order
+// is a conflict for people to resolve.
+func (h *Handlers) reviseOrder(ctx context.Context, tx pgx.Tx, in models.Integration, existing models.IntegrationOrder, reference string, lines []integrations.OrderLine, closed bool) (string, pgtype.Int8, string, error) {
+	qtx := h.queries.WithTx(tx)
+	if existing.PickListID.Valid {
+		dbStart := time.Now()
+		pickList, err := qtx.GetPickListForUpdate(ctx, models.GetPickListForUpdateParams{
+			ID:    existing.PickListID.Int64,
+			OrgID: in.OrgID,
+		})
+		h.recordDBOperation(ctx, "get", "pick_list", dbStart, err)
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+		case err != nil:
+			return "", pgtype.Int8{}, "", err
+		case pickList.Status == pickListStatusAllocated:
+			if err := h.cancelPickList(ctx, qtx, pickList, "cancel", integrationActor); err != nil {
+				return "", pgtype.Int8{}, "", err
+			}
+		case pickList.Status != pickListStatusCancelled:
+			message := fmt.Sprintf("The order changed in the channel after pick list %d was %s", pickList.ID, pickList.Status)
+			return integrationOrderConflict, existing.PickListID, message, nil
+		}
+	}
+	if closed {
+		return integrationOrderCancelled, existing.PickListID, "", nil
+	}
+	pickListID, message, err := h.reserveOrderLines(ctx, tx, in, reference, lines)
+	if message != "" {
+		return integrationOrderShort, pickListID, message, err
+	}
+	return integrationOrderReserved, pickListID, "", err
+}
+
+// reserveOrder records an order pulled from the channel and reserves its
+// stock in one transaction. An order seen before is only applied when it
+// is newer, and only reserved again when its lines or cancellation
+// changed. Orders placed before the integration was set up are left
+// alone.
+func (h *Handlers) reserveOrder(ctx context.Context, in models.Integration, order integrations.Order) error {
+	return pgx.BeginFunc(ctx, h.db, func(tx pgx.Tx) error {
+		qtx := h.queries.WithTx(tx)
+		dbStart := time.Now()
+		existing, err := qtx.GetIntegrationOrderForUpdate(ctx, models.GetIntegrationOrderForUpdateParams{
+			IntegrationID: in.ID,
+			ExternalID:    order.ID,
+		})
+		h.recordDBOperation(ctx, "get", "integration_order", dbStart, err)
+		found := err == nil
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		closed := order.Cancelled || len(order.Lines) == 0
+		if !found && (closed || order.CreatedAt.Before(in.CreatedAt.Time)) {
+			return nil
+		}
+		if found && !order.UpdatedAt.After(existing.ExternalUpdatedAt.Time) {
+			return nil
+		}
+		lines, err := encodeOrderLines(order.Lines)
+		if err != nil {
+			return err
+		}
+		reference := cmp.Or(order.Reference, order.ID)
+		updatedAt := pgtype.Timestamptz{Time: order.UpdatedAt, Valid: true}
+
+		if !found {
+			pickListID, message, err := h.reserveOrderLines(ctx, tx, in, reference, order.Lines)
+			if err != nil {
+				return err
+			}
+			status := integrationOrderReserved
+			if message != "" {
+				status = integrationOrderShort
+			}
+			dbStart = time.Now()
+			_, err = qtx.CreateIntegrationOrder(ctx, models.CreateIntegrationOrderParams{
+				OrgID:             in.OrgID,
+				IntegrationID:     in.ID,
+				ExternalID:        order.ID,
+				Reference:         reference,
+				Lines:             lines,
+				Status:            status,
+				PickListID:        pickListID,
+				Error:             message,
+				ExternalUpdatedAt: updatedAt,
+			})
+			h.recordDBOperation(ctx, "create", "integration_order", dbStart, err)
+			return err
+		}
+
+		// An update that left the lines and cancellation alone, such as a
+		// payment or the fulfillment of the shipped order, changes nothing
+		status, pickListID, message := existing.Status, existing.PickListID, existing.Error
+		if !bytes.Equal(existing.Lines, lines) || closed != (existing.Status == integrationOrderCancelled) {
+			status, pickListID, message, err = h.reviseOrder(ctx, tx, in, existing, reference, order.Lines, closed)
+			if err != nil {
+				return err
+			}
+		}
+		dbStart = time.Now()
+		_, err = qtx.UpdateIntegrationOrder(ctx, models.UpdateIntegrationOrderParams{
+			ID:                existing.ID,
+			Reference:         reference,
+			Lines:             lines,
+			Status:            status,
+			PickListID:        pickListID,
+			Error:             message,
+			ExternalUpdatedAt: updatedAt,
+		})
+		h.recordDBOperation(ctx, "update", "integration_order", dbStart, err)
+		return err
+	})
+}
+
+// retryShortOrders tries again to reserve the orders the warehouse was
+// short of stock for, oldest first
+func (h *Handlers) retryShortOrders(ctx context.Context, in models.Integration) error {
+	dbStart := time.Now()
+	orders, err := h.queries.ListShortIntegrationOrders(ctx, in.ID)
+	h.recordDBOperation(ctx, "list", "integration_order", dbStart, err)
+	if err != nil {
+		return err
+	}
+	for _, order := range orders {
+		err := pgx.BeginFunc(ctx, h.db, func(tx pgx.Tx) error {
+			qtx := h.queries.WithTx(tx)
+			dbStart := time.Now()
+			current, err := qtx.GetIntegrationOrderForUpdate(ctx, models.GetIntegrationOrderForUpdateParams{
+				IntegrationID: in.ID,
+				ExternalID:    order.ExternalID,
+			})
+			h.recordDBOperation(ctx, "get", "integration_order", dbStart, err)
+			if err != nil || current.Status != integrationOrderShort {
+				return err
+			}
+			var lines []integrations.OrderLine
+			if err := json.Unmarshal(current.Lines, &lines); err != nil {
+				return err
+			}
+			pickListID, message, err := h.reserveOrderLines(ctx, tx, in, current.Reference, lines)
+			if err != nil || message == current.Error {
+				return err
+			}
+			status := integrationOrderReserved
+			if message != "" {
+				status = integrationOrderShort
+			}
+			dbStart = time.Now()
+			_, err = qtx.UpdateIntegrationOrder(ctx, models.UpdateIntegrationOrderParams{
+				ID:                current.ID,
+				Reference:         current.Reference,
+				Lines:             current.Lines,
+				Status:            status,
+				PickListID:        pickListID,
+				Error:             message,
+				ExternalUpdatedAt: current.ExternalUpdatedAt,
+			})
+			h.recordDBOperation(ctx, "update", "integration_order", dbStart, err)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("order %s: %w", order.ExternalID, err)
+		}
+	}
+	return nil
+}
+
+// pushAvailability pushes the available stock of the warehouse that
+// changed since the last push, returning how many levels were pushed
+func (h *Handlers) pushAvailability(ctx context.Context, connector integrations.Connector, in models.Integration) (int, error) {
+	dbStart := time.Now()
+	current, err := h.queries.ListAvailableStock(ctx, models.ListAvailableStockParams{
+		OrgID:       in.OrgID,
+		WarehouseID: int32(in.WarehouseID),
+	})
+	h.recordDBOperation(ctx, "list", "stock_level", dbStart, err)
+	if err != nil {
+		return 0, err
+	}
+	dbStart = time.Now()
+	pushed, err := h.queries.ListIntegrationStock(ctx, in.ID)
+	h.recordDBOperation(ctx, "list", "integration_stock", dbStart, err)
+	if err != nil {
+		return 0, err
+	}
+
+	levels := availabilityChanges(current, pushed)
+	if err := connector.PushAvailability(ctx, levels); err != nil {
+		return 0, err
+	}
+	for _, level := range levels {
+		dbStart = time.Now()
+		err := h.queries.UpsertIntegrationStock(ctx, models.UpsertIntegrationStockParams{
+			IntegrationID: in.ID,
+			Sku:           level.Sku,
+			Available:     level.Available,
+		})
+		h.recordDBOperation(ctx, "update", "integration_stock", dbStart, err)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(levels), nil
+}
+
+// runIntegrationSync pulls the orders of the channel and reserves them,
+// retries the orders short of stock and then pushes the stock left
+// available. It returns the time orders were pulled up to.
+func (h *Handlers) runIntegrationSync(ctx context.Context, in models.Integration) (time.Time, int, int, error) {
+	syncedAt := in.OrdersSyncedAt.Time
+	connector, err := integrations.New(integrationSettings(in))
+	if err != nil {
+		return syncedAt, 0, 0, err
+	}
+	orders, err := connector.PullOrders(ctx, syncedAt.Add(-integrationOrdersOverlap))
+	if err != nil {
+		return syncedAt, 0, 0, fmt.Errorf("pull orders: %w", err)
+	}
+	for i, order := range orders {
+		if err := h.reserveOrder(ctx, in, order); err != nil {
+			return syncedAt, i, 0, fmt.Errorf("order %s: %w", order.ID, err)
+		}
+		if order.UpdatedAt.After(syncedAt) {
+			syncedAt = order.UpdatedAt
+		}
+	}
+	if err := h.retryShortOrders(ctx, in); err != nil {
+		return syncedAt, len(orders), 0, err
+	}
+	pushed, err := h.pushAvailability(ctx, connector, in)
+	if err != nil {
+		return syncedAt, len(orders), 0, fmt.Errorf("push availability: %w", err)
+	}
+	return syncedAt, len(orders), pushed, nil
+}
+
+// syncIntegration syncs an integration and records the outcome on it. A
+// sync that fails holds the scheduler off the integration for a while,
+// longer for each failure in a row.
+func (h *Handlers) syncIntegration(ctx context.Context, in models.Integration) error {
+	opStart := time.Now()
+	spanCtx, span := h.tracer.Start(ctx, "syncIntegration")
+	defer span.End()
+	span.SetAttributes(
+		attribute.Int64("integration.id", in.ID),
+		attribute.String("integration.kind", in.Kind),
+		attribute.String("tenant.id", in.OrgID),
+	)
+
+	err := h.withIntegrationLock(spanCtx, in.ID, func() error {
+		// Another replica may have synced since in was read
+		dbStart := time.Now()
+		current, err := h.queries.GetIntegration(spanCtx, models.GetIntegrationParams{
+			ID:    in.ID,
+			OrgID: in.OrgID,
+		})
+		h.recordDBOperation(spanCtx, "get", "integration", dbStart, err)
+		if err != nil {
+			return err
+		}
+
+		syncedAt, pulled, pushed, syncErr := h.runIntegrationSync(spanCtx, current)
+		h.recordOperation(current.OrgID, observability.EntityIntegration, "sync", opStart, syncErr)
+		span.SetAttributes(
+			attribute.Int("integration.orders_pulled", pulled),
+			attribute.Int("integration.levels_pushed", pushed),
+		)
+
+		dbStart = time.Now()
+		if syncErr == nil {
+			err = h.queries.FinishIntegrationSync(spanCtx, models.FinishIntegrationSyncParams{
+				ID:             current.ID,
+				OrdersSyncedAt: pgtype.Timestamptz{Time: syncedAt, Valid: true},
+			})
+		} else {
+			err = h.queries.FailIntegrationSync(spanCtx, models.FailIntegrationSyncParams{
+				ID:         current.ID,
+				LastError:  syncErr.Error(),
+				NextSyncAt: pgtype.Timestamptz{Time: time.Now().Add(integrationRetryDelay(current.Failures+1, syncErr)), Valid: true},
+			})
+		}
+		h.recordDBOperation(spanCtx, "update", "integration", dbStart, err)
+		return errors.Join(syncErr, err)
+	})
+	if err != nil && !errors.Is(err, errIntegrationSyncing) {
+		span.RecordError(err)
+	}
+	return err
+}
+
+// SyncIntegrations syncs every enabled integration that is due, skipping
+// integrations that are syncing already. An integration that fails
+// doesn't hold up the others.
+func (h *Handlers) SyncIntegrations(ctx context.Context) error {
+	dbStart := time.Now()
+	due, err := h.queries.ListDueIntegrations(ctx)
+	h.recordDBOperation(ctx, "list", "integration", dbStart, err)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, in := range due {
+		err := h.syncIntegration(ctx, in)
+		if errors.Is(err, errIntegrationSyncing) {
+			slog.Debug("Skipped an integration that is syncing", slog.Int64("integration_id", in.ID))
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("integration %d: %w", in.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SyncIntegrationJob runs a sync_integration job, syncing the integration
+// whether it is enabled or due or not. A sync that fails is retried as the
+// job.
+func (h *Handlers) SyncIntegrationJob(ctx context.Context, job models.Job) error {
+	var payload integrationJob
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return fmt.Errorf("decode integration job: %w", err)
+	}
+
+	dbStart := time.Now()
+	in, err := h.queries.GetIntegration(ctx, models.GetIntegrationParams{
+		ID:    payload.IntegrationID,
+		OrgID: job.OrgID,
+	})
+	h.recordDBOperation(ctx, "get", "integration", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("integration %d was deleted", payload.IntegrationID)
+	}
+	if err != nil {
+		return err
+	}
+	return h.syncIntegration(ctx, in)
+}
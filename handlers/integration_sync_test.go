@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"warehouse-service/integrations"
+	models "warehouse-service/models/sqlc"
+)
+
+func TestAvailabilityChanges(t *testing.T) {
+	levels := availabilityChanges(
+		[]models.ListAvailableStockRow{{Sku: "A", Available: 5}, {Sku: "B", Available: 3}, {Sku: "C", Available: 0}},
+		[]models.IntegrationStock{{Sku: "A", Available: 5}, {Sku: "B", Available: 4}, {Sku: "E", Available: 2}, {Sku: "D", Available: 1}, {Sku: "F", Available: 0}},
+	)
+	want := []integrations.Level{{Sku: "B", Available: 3}, {Sku: "C", Available: 0}, {Sku: "D", Available: 0}, {Sku: "E", Available: 0}}
+	if len(levels) != len(want) {
+		t.Fatalf("levels %+v", levels)
+	}
+	for i := range want {
+		if levels[i] != want[i] {
+			t.Errorf("level %d is %+v, want %+v", i, levels[i], want[i])
+		}
+	}
+}
+
+func TestIntegrationRetryDelay(t *testing.T) {
+	for _, c := range []struct {
+		failures int32
+		err      error
+		want     time.Duration
+	}{
+		{1, errors.New("down"), time.Minute},
+		{3, errors.New("down"), 4 * time.Minute},
+		{20, errors.New("down"), time.Hour},
+		{1, &integrations.RateLimitError{RetryAfter: 10 * time.Minute}, 10 * time.Minute},
+		{3, &integrations.RateLimitError{RetryAfter: time.Second}, 4 * time.Minute},
+	} {
+		if got := integrationRetryDelay(c.failures, c.err); got != c.want {
+			t.Errorf("%d failures, %v: got %v, want %v", c.failures, c.err, got, c.want)
+		}
+	}
+}
+
+func TestEncodeOrderLines(t *testing.T) {
+	a, err := encodeOrderLines([]integrations.OrderLine{{Sku: "B", Quantity: 1}, {Sku: "A", Quantity: 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := encodeOrderLines([]integrations.OrderLine{{Sku: "A", Quantity: 2}, {Sku: "B", Quantity: 1}})
+	if string(a) != string(b) {
+		t.Errorf("%s != %s", a, b)
+	}
+}
+
+func TestShortageMessage(t *testing.T) {
+	got := shortageMessage([]pickShortage{{Sku: "A", Requested: 5, Allocated: 2}, {Sku: "B", Requested: 1}})
+	if want := "Insufficient stock for A (2 of 5), B (0 of 1)"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
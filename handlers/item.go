@@ -0,0 +1,626 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"warehouse-service/listquery"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// defaultBaseUnit is the unit stock of an item is kept in when none is given
+const defaultBaseUnit = "each"
+
+type itemUnitRequest struct {
+	Unit string `json:"unit" binding:"required,max=32"`
+	// Base units in one of this unit, e.g. 12 for a case of 12
+	Factor int32 `json:"factor" binding:"gt=1"`
+}
+
+// itemRequest is the body of creating and replacing an item. The SKU can't
+// be changed once the item exists.
+type itemRequest struct {
+	Sku         string            `json:"sku" binding:"max=64"`
+	Description string            `json:"description" binding:"max=500"`
+	BaseUnit    string            `json:"base_unit" binding:"max=32"`
+	LengthCm    *float64          `json:"length_cm" binding:"omitempty,gt=0"`
+	WidthCm     *float64          `json:"width_cm" binding:"omitempty,gt=0"`
+	HeightCm    *float64          `json:"height_cm" binding:"omitempty,gt=0"`
+	WeightKg    *float64          `json:"weight_kg" binding:"omitempty,gt=0"`
+	Units       []itemUnitRequest `json:"units" binding:"dive"`
+}
+
+// normalizeUnit makes unit names case insensitive
+func normalizeUnit(unit string) string {
+	return strings.ToLower(strings.TrimSpace(unit))
+}
+
+// itemUnits normalizes the base unit and the units of req, rejecting a unit
+// given twice or named like the base unit
+func (req *itemRequest) itemUnits() ([]string, []int32, error) {
+	req.BaseUnit = normalizeUnit(req.BaseUnit)
+	if req.BaseUnit == "" {
+		req.BaseUnit = defaultBaseUnit
+	}
+	units := make([]string, 0, len(req.Units))
+	factors := make([]int32, 0, len(req.Units))
+	for _, u := range req.Units {
+		unit := normalizeUnit(u.Unit)
+		switch {
+		case unit == "":
+			return nil, nil, errors.New("unit names must not be blank")
+		case unit == req.BaseUnit:
+			return nil, nil, fmt.Errorf("unit %q is the base unit", unit)
+		}
+		for _, seen := range units {
+			if seen == unit {
+				return nil, nil, fmt.Errorf("unit %q is given twice", unit)
+			}
+		}
+		units = append(units, unit)
+		factors = append(factors, u.Factor)
+	}
+	return units, factors, nil
+}
+
+// unitError is returned when a quantity is given in a unit that can't be
+// converted to the base unit of its SKU
+type unitError struct {
+	Sku  string
+	Unit string
+	// Why the unit was rejected
+	reason string
+}
+
+func (e *unitError) Error() string {
+	return fmt.Sprintf("SKU %s: %s", e.Sku, e.reason)
+}
+
+// unitRejected unwraps a unitError
+func unitRejected(err error) (*unitError, bool) {
+	var rejected *unitError
+	ok := errors.As(err, &rejected)
+	return rejected, ok
+}
+
+// respondUnitError answers a quantity in a unit the item catalog doesn't know
+func respondUnitError(ctx *gin.Context, rejected *unitError) {
+	ctx.JSON(http.StatusBadRequest, gin.H{
+		"error": rejected.Error(),
+		"sku":   rejected.Sku,
+		"unit":  rejected.Unit,
+	})
+}
+
+// catalogItem is what converting quantities needs to know of an item
+type catalogItem struct {
+	baseUnit string
+	factors  map[string]int32
+}
+
+func newCatalogItem(item models.Item, units []models.ItemUnit) *catalogItem {
+	c := &catalogItem{baseUnit: item.BaseUnit, factors: make(map[string]int32, len(units))}
+	for _, u := range units {
+		c.factors[u.Unit] = u.Factor
+	}
+	return c
+}
+
+// convertQuantity converts qty of unit to base units of sku. A nil item
+// means the SKU is not in the catalog, which only base quantities without
+// a unit are accepted for.
+func convertQuantity(item *catalogItem, sku, unit string, qty int32) (int32, error) {
+	unit = normalizeUnit(unit)
+	if unit == "" {
+		return qty, nil
+	}
+	if item == nil {
+		return 0, &unitError{Sku: sku, Unit: unit, reason: "not in the item catalog, give the quantity without a unit"}
+	}
+	if unit == item.baseUnit {
+		return qty, nil
+	}
+	factor, ok := item.factors[unit]
+	if !ok {
+		return 0, &unitError{Sku: sku, Unit: unit, reason: fmt.Sprintf("unit %q is not registered", unit)}
+	}
+	base := int64(qty) * int64(factor)
+	if base > math.MaxInt32 || base < math.MinInt32 {
+		return 0, &unitError{Sku: sku, Unit: unit, reason: fmt.Sprintf("%d %s is too many base units", qty, unit)}
+	}
+	return int32(base), nil
+}
+
+// unitConverter converts the quantities of a request to base units. Each
+// SKU's item is looked up once, with qtx so it reads in the caller's
+// transaction.
+type unitConverter struct {
+	h     *Handlers
+	qtx   *models.Queries
+	orgID string
+	items map[string]*catalogItem
+}
+
+func (h *Handlers) unitConverter(qtx *models.Queries, orgID string) *unitConverter {
+	return &unitConverter{h: h, qtx: qtx, orgID: orgID, items: map[string]*catalogItem{}}
+}
+
+// toBase converts qty of unit to base units of sku. Quantities without a
+// unit are already in base units and are not looked up. Fails with a
+// unitError for a unit the SKU doesn't have.
+func (c *unitConverter) toBase(ctx context.Context, sku, unit string, qty int32) (int32, error) {
+	if normalizeUnit(unit) == "" {
+		return qty, nil
+	}
+	item, ok := c.items[sku]
+	if !ok {
+		var err error
+		if item, err = c.lookup(ctx, sku); err != nil {
+			return 0, err
+		}
+		c.items[sku] = item
+	}
+	return convertQuantity(item, sku, unit, qty)
+}
+
+// lookup loads sku from the catalog, nil when it is not there
+func (c *unitConverter) lookup(ctx context.Context, sku string) (*catalogItem, error) {
+	dbStart := time.Now()
+	item, err := c.qtx.GetItemBySku(ctx, models.GetItemBySkuParams{
+		OrgID: c.orgID,
+		Sku:   sku,
+	})
+	c.h.recordDBOperation(ctx, "get", "item", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	dbStart = time.Now()
+	units, err := c.qtx.ListItemUnits(ctx, []int64{item.ID})
+	c.h.recordDBOperation(ctx, "list", "item_unit", dbStart, err)
+	if err != nil {
+		return nil, err
+	}
+	return newCatalogItem(item, units), nil
+}
+
+// itemResponses pairs items with their units
+func (h *Handlers) itemResponses(ctx context.Context, qtx *models.Queries, items []models.Item) ([]ItemResponse, error) {
+	ids := make([]int64, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	dbStart := time.Now()
+	units, err := qtx.ListItemUnits(ctx, ids)
+	h.recordDBOperation(ctx, "list", "item_unit", dbStart, err)
+	if err != nil {
+		return nil, err
+	}
+	byItem := make(map[int64][]models.ItemUnit, len(items))
+	for _, u := range units {
+		byItem[u.ItemID] = append(byItem[u.ItemID], u)
+	}
+	responses := make([]ItemResponse, len(items))
+	for i, item := range items {
+		responses[i] = newItemResponse(item, byItem[item.ID])
+	}
+	return responses, nil
+}
+
+func parseItemID(ctx *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "Invalid item ID format"),
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+func bindItemRequest(ctx *gin.Context) (itemRequest, []string, []int32, bool) {
+	var req itemRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   tr(ctx, "Invalid item payload"),
+			"details": err.Error(),
+		})
+		return req, nil, nil, false
+	}
+	units, factors, err := req.itemUnits()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   tr(ctx, "Invalid item payload"),
+			"details": err.Error(),
+		})
+		return req, nil, nil, false
+	}
+	return req, units, factors, true
+}
+
+// CreateItem adds an item with its units of measure to the catalog
+func (h *Handlers) CreateItem(ctx *gin.Context) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "CreateItem")
+	defer span.End()
+
+	req, units, factors, ok := bindItemRequest(ctx)
+	if !ok {
+		return
+	}
+	req.Sku = strings.TrimSpace(req.Sku)
+	if req.Sku == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   tr(ctx, "Invalid item payload"),
+			"details": "sku is required",
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.String("item.sku", req.Sku),
+		attribute.Int("item.units", len(units)),
+		attribute.String("tenant.id", orgID),
+	)
+
+	tx, err := h.db.Begin(spanCtx)
+	if err != nil {
+		slog.Error("Failed to start transaction", slog.Any("err", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": tr(ctx, "Failed to start transaction"),
+		})
+		return
+	}
+	defer tx.Rollback(spanCtx) // This will be ignored if tx.Commit() succeeds
+
+	qtx := h.queries.WithTx(tx)
+
+	dbStart := time.Now()
+	item, err := qtx.CreateItem(spanCtx, models.CreateItemParams{
+		OrgID:       orgID,
+		Sku:         req.Sku,
+		Description: req.Description,
+		BaseUnit:    req.BaseUnit,
+		LengthCm:    floatParam(req.LengthCm),
+		WidthCm:     floatParam(req.WidthCm),
+		HeightCm:    floatParam(req.HeightCm),
+		WeightKg:    floatParam(req.WeightKg),
+	})
+	h.recordDBOperation(spanCtx, "create", "item", dbStart, err)
+	if conflict, ok := conflictFor(err); ok {
+		h.recordOperation(orgID, observability.EntityItem, "create", opStart, err)
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": tr(ctx, conflict.message),
+			"field": conflict.field,
+		})
+		return
+	}
+	if err == nil && len(units) > 0 {
+		dbStart = time.Now()
+		err = qtx.CreateItemUnits(spanCtx, models.CreateItemUnitsParams{
+			ItemID:  item.ID,
+			Units:   units,
+			Factors: factors,
+		})
+		h.recordDBOperation(spanCtx, "create", "item_unit", dbStart, err)
+	}
+	if err != nil {
+		slog.Error("Could not create item: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		h.recordOperation(orgID, observability.EntityItem, "create", opStart, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to create item"),
+		})
+		return
+	}
+
+	responses, err := h.itemResponses(spanCtx, qtx, []models.Item{item})
+	if err == nil {
+		err = tx.Commit(spanCtx)
+	}
+	if err != nil {
+		slog.Error("Failed to commit transaction", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to commit transaction"),
+		})
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntityItem, "create", opStart, nil)
+
+	span.SetAttributes(
+		attribute.Int64("item.id", item.ID),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusCreated, gin.H{
+		"message": tr(ctx, "Create Item Successfully"),
+		"data":    responses[0],
+	})
+}
+
+func (h *Handlers) GetItem(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "GetItem")
+	defer span.End()
+
+	id, ok := parseItemID(ctx)
+	if !ok {
+		return
+	}
+	fields, ok := bindFields[ItemResponse](ctx)
+	if !ok {
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.Int64("item.id", id),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	item, err := h.queries.GetItem(spanCtx, models.GetItemParams{
+		ID:    id,
+		OrgID: orgID,
+	})
+	h.recordDBOperation(spanCtx, "get", "item", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Item not found"),
+		})
+		return
+	}
+	var responses []ItemResponse
+	if err == nil {
+		responses, err = h.itemResponses(spanCtx, h.queries, []models.Item{item})
+	}
+	if err != nil {
+		slog.Error("Got an error while getting item: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to get item"),
+		})
+		return
+	}
+
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Get Item Successfully"),
+		"data":    fields.one(responses[0]),
+	})
+}
+
+// itemListSpec sorts and filters the items of ListItems
+var itemListSpec = &listquery.Spec{
+	Table: "item",
+	Sort: map[string]string{
+		"sku":         "sku",
+		"description": "description",
+		"created_at":  "created_at",
+		"updated_at":  "updated_at",
+	},
+	Filters: map[string]listquery.Filter{
+		"base_unit":      {Column: "base_unit", Op: listquery.Eq, Parse: listquery.Text},
+		"updated_after":  {Column: "updated_at", Op: listquery.Gte, Parse: listquery.Time},
+		"updated_before": {Column: "updated_at", Op: listquery.Lt, Parse: listquery.Time},
+	},
+	Default: []listquery.Order{{Field: "sku"}},
+	Key:     "id",
+}
+
+func (h *Handlers) ListItems(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "ListItems")
+	defer span.End()
+
+	q, err := listParams(ctx, itemListSpec)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	fields, ok := bindFields[ItemResponse](ctx)
+	if !ok {
+		return
+	}
+	orgID := tenantID(ctx)
+	q.Where("org_id", listquery.Eq, orgID)
+	span.SetAttributes(
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	items, err := queryPage[models.Item](ctx, spanCtx, h.db, q)
+	h.recordDBOperation(spanCtx, "list", "item", dbStart, err)
+	var responses []ItemResponse
+	if err == nil {
+		responses, err = h.itemResponses(spanCtx, h.queries, items)
+	}
+	if err != nil {
+		slog.Error("Got an error while listing items: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to list items"),
+		})
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int("item.count", len(items)),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "List Items Successfully"),
+		"data":    selectFields(fields, responses),
+	})
+}
+
+// UpdateItem replaces the details and the units of an item. Stock already
+// on hand stays in base units, so changing a factor only affects
+// quantities given from now on.
+func (h *Handlers) UpdateItem(ctx *gin.Context) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "UpdateItem")
+	defer span.End()
+
+	id, ok := parseItemID(ctx)
+	if !ok {
+		return
+	}
+	req, units, factors, ok := bindItemRequest(ctx)
+	if !ok {
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.Int64("item.id", id),
+		attribute.Int("item.units", len(units)),
+		attribute.String("tenant.id", orgID),
+	)
+
+	tx, err := h.db.Begin(spanCtx)
+	if err != nil {
+		slog.Error("Failed to start transaction", slog.Any("err", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": tr(ctx, "Failed to start transaction"),
+		})
+		return
+	}
+	defer tx.Rollback(spanCtx) // This will be ignored if tx.Commit() succeeds
+
+	qtx := h.queries.WithTx(tx)
+
+	dbStart := time.Now()
+	item, err := qtx.UpdateItem(spanCtx, models.UpdateItemParams{
+		ID:          id,
+		OrgID:       orgID,
+		Description: req.Description,
+		BaseUnit:    req.BaseUnit,
+		LengthCm:    floatParam(req.LengthCm),
+		WidthCm:     floatParam(req.WidthCm),
+		HeightCm:    floatParam(req.HeightCm),
+		WeightKg:    floatParam(req.WeightKg),
+	})
+	h.recordDBOperation(spanCtx, "update", "item", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Item not found"),
+		})
+		return
+	}
+	if err == nil && req.Sku != "" && strings.TrimSpace(req.Sku) != item.Sku {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   tr(ctx, "Invalid item payload"),
+			"details": "sku can't be changed",
+		})
+		return
+	}
+	if err == nil {
+		dbStart = time.Now()
+		err = qtx.DeleteItemUnits(spanCtx, item.ID)
+		h.recordDBOperation(spanCtx, "delete", "item_unit", dbStart, err)
+	}
+	if err == nil && len(units) > 0 {
+		dbStart = time.Now()
+		err = qtx.CreateItemUnits(spanCtx, models.CreateItemUnitsParams{
+			ItemID:  item.ID,
+			Units:   units,
+			Factors: factors,
+		})
+		h.recordDBOperation(spanCtx, "create", "item_unit", dbStart, err)
+	}
+	var responses []ItemResponse
+	if err == nil {
+		responses, err = h.itemResponses(spanCtx, qtx, []models.Item{item})
+	}
+	if err != nil {
+		slog.Error("Could not update item: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		h.recordOperation(orgID, observability.EntityItem, "update", opStart, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to update item"),
+		})
+		return
+	}
+
+	if err := tx.Commit(spanCtx); err != nil {
+		slog.Error("Failed to commit transaction", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to commit transaction"),
+		})
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntityItem, "update", opStart, nil)
+
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Update Item Successfully"),
+		"data":    responses[0],
+	})
+}
+
+// DeleteItem removes an item and its units from the catalog. Stock of the
+// SKU is kept, its quantities can then only be given in base units.
+func (h *Handlers) DeleteItem(ctx *gin.Context) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "DeleteItem")
+	defer span.End()
+
+	id, ok := parseItemID(ctx)
+	if !ok {
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.Int64("item.id", id),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	rows, err := h.queries.DeleteItem(spanCtx, models.DeleteItemParams{
+		ID:    id,
+		OrgID: orgID,
+	})
+	h.recordDBOperation(spanCtx, "delete", "item", dbStart, err)
+	if err != nil {
+		slog.Error("Could not delete item: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		h.recordOperation(orgID, observability.EntityItem, "delete", opStart, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to delete item"),
+		})
+		return
+	}
+	if rows == 0 {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Item not found"),
+		})
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntityItem, "delete", opStart, nil)
+
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": tr(ctx, "Delete Item Successfully"),
+	})
+}
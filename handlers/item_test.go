@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"math"
+	"testing"
+	models "warehouse-service/models/sqlc"
+)
+
+func TestConvertQuantity(t *testing.T) {
+	item := newCatalogItem(models.Item{BaseUnit: "each"}, []models.ItemUnit{
+		{Unit: "case", Factor: 12},
+		{Unit: "pallet", Factor: 480},
+	})
+	tests := []struct {
+		name    string
+		item    *catalogItem
+		unit    string
+		qty     int32
+		want    int32
+		wantErr bool
+	}{
+		{"no unit", item, "", 5, 5, false},
+		{"base unit", item, "each", 5, 5, false},
+		{"registered unit", item, "case", 3, 36, false},
+		{"case insensitive", item, " Pallet ", 2, 960, false},
+		{"unknown unit", item, "box", 1, 0, true},
+		{"overflow", item, "pallet", math.MaxInt32 / 100, 0, true},
+		{"not in catalog without unit", nil, "", 7, 7, false},
+		{"not in catalog with unit", nil, "each", 7, 0, true},
+	}
+	for _, tt := range tests {
+		got, err := convertQuantity(tt.item, "SKU-1", tt.unit, tt.qty)
+		if tt.wantErr {
+			if _, ok := unitRejected(err); !ok {
+				t.Errorf("%s: err = %v, want a unitError", tt.name, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%s: convertQuantity = %d, %v, want %d", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestItemUnits(t *testing.T) {
+	req := itemRequest{BaseUnit: " Each ", Units: []itemUnitRequest{{Unit: "CASE", Factor: 12}}}
+	units, factors, err := req.itemUnits()
+	if err != nil || req.BaseUnit != "each" || len(units) != 1 || units[0] != "case" || factors[0] != 12 {
+		t.Errorf("itemUnits = %v, %v, %v, base %q", units, factors, err, req.BaseUnit)
+	}
+
+	req = itemRequest{}
+	if _, _, err := req.itemUnits(); err != nil || req.BaseUnit != defaultBaseUnit {
+		t.Errorf("empty base unit = %q, %v, want %q", req.BaseUnit, err, defaultBaseUnit)
+	}
+
+	for _, units := range [][]itemUnitRequest{
+		{{Unit: "each", Factor: 2}},
+		{{Unit: "case", Factor: 12}, {Unit: "Case", Factor: 6}},
+		{{Unit: " ", Factor: 2}},
+	} {
+		req := itemRequest{BaseUnit: "each", Units: units}
+		if _, _, err := req.itemUnits(); err == nil {
+			t.Errorf("itemUnits(%+v) succeeded", units)
+		}
+	}
+}
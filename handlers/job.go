@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	models "warehouse-service/models/sqlc"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func (h *Handlers) GetJob(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "GetJob")
+	defer span.End()
+
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "Invalid job ID format"),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.Int64("job.id", id),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	job, err := h.queries.GetJob(spanCtx, models.GetJobParams{
+		ID:    id,
+		OrgID: orgID,
+	})
+	h.recordDBOperation(spanCtx, "get", "job", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Job not found"),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Got an error while getting job: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to get job"),
+		})
+		return
+	}
+
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Get Job Successfully"),
+		"data":    newJobResponse(job),
+	})
+}
+
+func (h *Handlers) ListJobs(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "ListJobs")
+	defer span.End()
+
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.Int("job.limit", 10),
+		attribute.Int("job.offset", 0),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	jobs, err := h.queries.ListJobs(spanCtx, models.ListJobsParams{
+		OrgID:  orgID,
+		Limit:  10,
+		Offset: 0,
+	})
+	h.recordDBOperation(spanCtx, "list", "job", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while listing jobs: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to list jobs"),
+		})
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int("job.count", len(jobs)),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "List Jobs Successfully"),
+		"data":    mapSlice(jobs, newJobResponse),
+	})
+}
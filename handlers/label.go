@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"warehouse-service/labels"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// locationCode is the identifier printed on bin labels, e.g. "12-A-03-2"
+// for storage room number "A-03-2" in warehouse 12.
+func locationCode(warehouseID int32, number string) string {
+	return fmt.Sprintf("%d-%s", warehouseID, number)
+}
+
+// parseLocationCode splits a location code into warehouse ID and room number
+func parseLocationCode(code string) (int32, string, error) {
+	warehouse, number, found := strings.Cut(code, "-")
+	if !found || number == "" {
+		return 0, "", fmt.Errorf("location code %q must look like <warehouse>-<number>", code)
+	}
+	warehouseID, err := strconv.ParseInt(warehouse, 10, 32)
+	if err != nil {
+		return 0, "", fmt.Errorf("location code %q has an invalid warehouse: %w", code, err)
+	}
+	return int32(warehouseID), number, nil
+}
+
+func (h *Handlers) GetStorageRoomLabel(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "GetStorageRoomLabel")
+	defer span.End()
+
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "Invalid storage room ID format"),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.Int64("storage_room.id", id),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	room, err := h.queries.GetStorageRoomLabel(spanCtx, models.GetStorageRoomLabelParams{
+		ID:    int32(id),
+		OrgID: orgID,
+	})
+	dbDuration := time.Since(dbStart)
+
+	// Record database operation duration (Prometheus)
+	if h.prometheusMetrics != nil {
+		h.prometheusMetrics.RecordDBOperation(spanCtx, "get", "storage_room", dbDuration, err)
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Storage room not found"),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Got an error while getting storage room label: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to get storage room"),
+		})
+		return
+	}
+
+	h.writeLabel(ctx, models.GetStorageRoomLabelByLocationRow(room))
+}
+
+func (h *Handlers) GetLocationLabel(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "GetLocationLabel")
+	defer span.End()
+
+	code := ctx.Param("code")
+	warehouseID, number, err := parseLocationCode(code)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "Invalid location code format"),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.String("location.code", code),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	room, err := h.queries.GetStorageRoomLabelByLocation(spanCtx, models.GetStorageRoomLabelByLocationParams{
+		WarehouseID: warehouseID,
+		Number:      number,
+		OrgID:       orgID,
+	})
+	dbDuration := time.Since(dbStart)
+
+	// Record database operation duration (Prometheus)
+	if h.prometheusMetrics != nil {
+		h.prometheusMetrics.RecordDBOperation(spanCtx, "get", "storage_room", dbDuration, err)
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Location not found"),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Got an error while getting location label: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to get location"),
+		})
+		return
+	}
+
+	h.writeLabel(ctx, room)
+}
+
+// writeLabel renders the label for a storage room using the "symbology"
+// (qr, code128) and "format" (png, pdf, zpl) query parameters.
+func (h *Handlers) writeLabel(ctx *gin.Context, room models.GetStorageRoomLabelByLocationRow) {
+	opStart := time.Now()
+	code := locationCode(room.WarehouseID, room.Number)
+	symbology := labels.Symbology(ctx.DefaultQuery("symbology", string(labels.SymbologyQR)))
+	format := labels.Format(ctx.DefaultQuery("format", string(labels.FormatPNG)))
+
+	data, contentType, err := labels.Render(labels.Label{
+		Code:  code,
+		Lines: []string{code, room.WarehouseName + " / " + room.Name},
+	}, symbology, format)
+	if errors.Is(err, labels.ErrUnsupported) {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Could not render label: ", slog.Any("err", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": tr(ctx, "Failed to render label"),
+		})
+		return
+	}
+
+	h.recordOperation(tenantID(ctx), observability.EntityLabel, "print", opStart, nil)
+
+	ctx.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", "label-"+code+"."+string(format)))
+	ctx.Data(http.StatusOK, contentType, data)
+}
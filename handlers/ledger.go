@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+	"warehouse-service/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// queryText maps an optional query string filter to a nullable parameter
+func queryText(ctx *gin.Context, key string) pgtype.Text {
+	value := ctx.Query(key)
+	return pgtype.Text{String: value, Valid: value != ""}
+}
+
+// ListStockLevels pages through the tenant's stock levels in id order,
+// optionally filtered by ?sku=
+func (h *Handlers) ListStockLevels(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "ListStockLevels")
+	defer span.End()
+
+	cursor, limit, err := cursorParams(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	tracing.Actor(span, orgID, actorID(ctx))
+	span.SetAttributes(attribute.Int("stock_level.limit", int(limit)))
+
+	var afterID int64
+	if cursor != nil {
+		afterID = cursor.ID
+	}
+
+	dbStart := time.Now()
+	levels, err := h.readQueries(spanCtx).ListStockLevelsPage(spanCtx, models.ListStockLevelsPageParams{
+		OrgID:     orgID,
+		Sku:       queryText(ctx, "sku"),
+		AfterID:   afterID,
+		PageLimit: limit + 1,
+	})
+	h.recordDBOperation(spanCtx, "list", "stock_level", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while listing stock levels: ", slog.Any("err", err.Error()))
+		tracing.Failed(span, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to list stock levels"),
+		})
+		return
+	}
+
+	levels, next := nextCursor(levels, limit, func(l models.StockLevel) pageCursor {
+		return pageCursor{ID: l.ID}
+	})
+
+	span.SetAttributes(attribute.Int("stock_level.count", len(levels)))
+	tracing.Result(span, observability.StatusSuccess)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message":     tr(ctx, "List Stock Levels Successfully"),
+		"data":        mapSlice(levels, newStockLevelResponse),
+		"next_cursor": next,
+	})
+}
+
+// ListStockMovements pages through the stock adjustment ledger, newest
+// first, optionally filtered by ?sku=
+func (h *Handlers) ListStockMovements(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "ListStockMovements")
+	defer span.End()
+
+	cursor, limit, err := cursorParams(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	tracing.Actor(span, orgID, actorID(ctx))
+	span.SetAttributes(attribute.Int("stock_adjustment.limit", int(limit)))
+
+	beforeCreatedAt, beforeID := cursor.before()
+
+	dbStart := time.Now()
+	movements, err := h.readQueries(spanCtx).ListStockAdjustmentsPage(spanCtx, models.ListStockAdjustmentsPageParams{
+		OrgID:           orgID,
+		Sku:             queryText(ctx, "sku"),
+		BeforeCreatedAt: beforeCreatedAt,
+		BeforeID:        beforeID,
+		PageLimit:       limit + 1,
+	})
+	h.recordDBOperation(spanCtx, "list", "stock_adjustment", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while listing stock movements: ", slog.Any("err", err.Error()))
+		tracing.Failed(span, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to list stock movements"),
+		})
+		return
+	}
+
+	movements, next := nextCursor(movements, limit, func(a models.StockAdjustment) pageCursor {
+		return pageCursor{CreatedAt: a.CreatedAt.Time, ID: a.ID}
+	})
+
+	span.SetAttributes(attribute.Int("stock_adjustment.count", len(movements)))
+	tracing.Result(span, observability.StatusSuccess)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message":     tr(ctx, "List Stock Movements Successfully"),
+		"data":        mapSlice(movements, newStockAdjustmentResponse),
+		"next_cursor": next,
+	})
+}
+
+// ListAuditLogs pages through the tenant's audit log, newest first,
+// optionally filtered by ?entity_type=
+func (h *Handlers) ListAuditLogs(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "ListAuditLogs")
+	defer span.End()
+
+	cursor, limit, err := cursorParams(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	tracing.Actor(span, orgID, actorID(ctx))
+	span.SetAttributes(attribute.Int("audit_log.limit", int(limit)))
+
+	beforeCreatedAt, beforeID := cursor.before()
+
+	dbStart := time.Now()
+	entries, err := h.readQueries(spanCtx).ListAuditLogsPage(spanCtx, models.ListAuditLogsPageParams{
+		OrgID:           orgID,
+		EntityType:      queryText(ctx, "entity_type"),
+		BeforeCreatedAt: beforeCreatedAt,
+		BeforeID:        beforeID,
+		PageLimit:       limit + 1,
+	})
+	h.recordDBOperation(spanCtx, "list", "audit_log", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while listing audit logs: ", slog.Any("err", err.Error()))
+		tracing.Failed(span, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to list audit logs"),
+		})
+		return
+	}
+
+	entries, next := nextCursor(entries, limit, func(a models.AuditLog) pageCursor {
+		return pageCursor{CreatedAt: a.CreatedAt.Time, ID: a.ID}
+	})
+
+	span.SetAttributes(attribute.Int("audit_log.count", len(entries)))
+	tracing.Result(span, observability.StatusSuccess)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message":     tr(ctx, "List Audit Logs Successfully"),
+		"data":        mapSlice(entries, newAuditLogResponse),
+		"next_cursor": next,
+	})
+}
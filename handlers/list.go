@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+	"warehouse-service/listquery"
+	models "warehouse-service/models/sqlc"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// TotalCountHeader carries the number of rows of a list across every page
+const TotalCountHeader = "X-Total-Count"
+
+// listParams reads the limit, offset, sort and filter parameters of a list
+// request against spec. The caller scopes the query to the tenant.
+func listParams(ctx *gin.Context, spec *listquery.Spec) (*listquery.Query, error) {
+	limit, offset, err := pageParams(ctx)
+	if err != nil {
+		return nil, err
+	}
+	q, err := spec.Parse(ctx.Request.URL.Query())
+	if err != nil {
+		return nil, err
+	}
+	return q.Page(limit, offset), nil
+}
+
+// queryList runs q on db, scanning each row into T, the model of the
+// spec's table
+func queryList[T any](ctx context.Context, db models.DBTX, q *listquery.Query) ([]T, error) {
+	sql, args := q.SQL()
+	rows, err := db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[T])
+}
+
+// queryPage runs q like queryList and counts its rows across every page
+// into the X-Total-Count header of the response
+func queryPage[T any](ctx *gin.Context, spanCtx context.Context, db models.DBTX, q *listquery.Query) ([]T, error) {
+	page, err := queryList[T](spanCtx, db, q)
+	if err != nil {
+		return nil, err
+	}
+	sql, args := q.CountSQL()
+	var total int64
+	if err := db.QueryRow(spanCtx, sql, args...).Scan(&total); err != nil {
+		return nil, err
+	}
+	ctx.Header(TotalCountHeader, strconv.FormatInt(total, 10))
+	return page, nil
+}
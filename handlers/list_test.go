@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+	"warehouse-service/listquery"
+	models "warehouse-service/models/sqlc"
+)
+
+// TestListSpecColumns fails for a sort field or filter of a list endpoint
+// naming a column its table does not have
+func TestListSpecColumns(t *testing.T) {
+	for _, c := range []struct {
+		spec  *listquery.Spec
+		model any
+	}{
+		{warehouseListSpec, models.Warehouse{}},
+		{storageRoomListSpec, models.StorageRoom{}},
+		{itemListSpec, models.Item{}},
+		{transferOrderListSpec, models.TransferOrder{}},
+		{waveListSpec, models.Wave{}},
+		{sagaListSpec, models.Saga{}},
+		{attachmentListSpec, models.Attachment{}},
+		{deadLetterListSpec, models.DeadLetter{}},
+		{ediDocumentListSpec, models.EdiDocument{}},
+		{temperatureBreachListSpec, models.TemperatureBreach{}},
+		{tenantExportListSpec, models.TenantExport{}},
+		{integrationOrderListSpec, models.IntegrationOrder{}},
+		{fileExchangeFileListSpec, models.FileExchangeFile{}},
+		{fileExchangeRunListSpec, models.FileExchangeRun{}},
+	} {
+		columns := map[string]bool{}
+		typ := reflect.TypeOf(c.model)
+		for i := range typ.NumField() {
+			columns[snakeCase(typ.Field(i).Name)] = true
+		}
+		check := func(what, column string) {
+			if !columns[column] {
+				t.Errorf("%s %s: %s is not a column", c.spec.Table, what, column)
+			}
+		}
+		check("key", c.spec.Key)
+		for field, column := range c.spec.Sort {
+			check("sort field "+field, column)
+		}
+		for param, filter := range c.spec.Filters {
+			check("filter "+param, filter.Column)
+		}
+		for _, order := range c.spec.Default {
+			if _, ok := c.spec.Sort[order.Field]; !ok {
+				t.Errorf("%s sorts by default by %s, which is not a sort field", c.spec.Table, order.Field)
+			}
+		}
+	}
+}
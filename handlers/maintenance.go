@@ -0,0 +1,308 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+	"warehouse-service/quota"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// schedulerActor is recorded in the audit log for changes made by scheduled tasks
+const schedulerActor = "system:scheduler"
+
+// stalePickListBatch caps how many pick lists one expiry run cancels
+const stalePickListBatch = 100
+
+// ExpireStalePickLists cancels pick lists that have held their allocation
+// longer than ttl without being picked, returning the reserved stock.
+func (h *Handlers) ExpireStalePickLists(ctx context.Context, ttl time.Duration) error {
+	spanCtx, span := h.tracer.Start(ctx, "ExpireStalePickLists")
+	defer span.End()
+
+	dbStart := time.Now()
+	pickLists, err := h.queries.ListStalePickLists(spanCtx, models.ListStalePickListsParams{
+		UpdatedAt: pgtype.Timestamptz{Time: time.Now().Add(-ttl), Valid: true},
+		Limit:     stalePickListBatch,
+	})
+	h.recordDBOperation(spanCtx, "list", "pick_list", dbStart, err)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	expired := 0
+	for _, pickList := range pickLists {
+		ok, err := h.expirePickList(spanCtx, pickList)
+		if err != nil {
+			span.RecordError(err)
+			return fmt.Errorf("expire pick list %d: %w", pickList.ID, err)
+		}
+		if ok {
+			expired++
+		}
+	}
+
+	span.SetAttributes(attribute.Int("pick_list.expired", expired))
+	if expired > 0 {
+		slog.Info("Expired stale pick lists", slog.Int("count", expired))
+	}
+	return nil
+}
+
+// expirePickList cancels one pick list unless it moved on since it was listed
+func (h *Handlers) expirePickList(ctx context.Context, stale models.PickList) (bool, error) {
+	opStart := time.Now()
+	tx, err := h.db.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx) // This will be ignored if tx.Commit() succeeds
+
+	qtx := h.queries.WithTx(tx)
+
+	dbStart := time.Now()
+	pickList, err := qtx.GetPickListForUpdate(ctx, models.GetPickListForUpdateParams{
+		ID:    stale.ID,
+		OrgID: stale.OrgID,
+	})
+	h.recordDBOperation(ctx, "get", "pick_list", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if pickList.Status != pickListStatusAllocated || pickList.UpdatedAt != stale.UpdatedAt {
+		return false, nil
+	}
+
+	if err := h.cancelPickList(ctx, qtx, pickList, "expire", schedulerActor); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+
+	h.recordOperation(pickList.OrgID, observability.EntityPickList, "expire", opStart, nil)
+	return true, nil
+}
+
+// cancelPickList cancels an allocated pick list locked for update,
+// returning its reserved stock, and records action in the audit log
+func (h *Handlers) cancelPickList(ctx context.Context, qtx *models.Queries, pickList models.PickList, action, actor string) error {
+	dbStart := time.Now()
+	lines, err := qtx.ListPickListLines(ctx, pickList.ID)
+	h.recordDBOperation(ctx, "list", "pick_list_line", dbStart, err)
+	if err != nil {
+		return err
+	}
+	if err := h.releasePickListAllocations(ctx, qtx, pickList.OrgID, lines); err != nil {
+		return err
+	}
+
+	dbStart = time.Now()
+	_, err = qtx.UpdatePickListStatus(ctx, models.UpdatePickListStatusParams{
+		ID:     pickList.ID,
+		OrgID:  pickList.OrgID,
+		Status: pickListStatusCancelled,
+	})
+	h.recordDBOperation(ctx, "update", "pick_list", dbStart, err)
+	if err != nil {
+		return err
+	}
+	if err := h.advancePickListSaga(ctx, qtx, pickList); err != nil {
+		return err
+	}
+	return h.recordAudit(ctx, qtx, auditEntry{
+		OrgID:      pickList.OrgID,
+		EntityType: auditEntityPickList,
+		EntityID:   pickList.ID,
+		Action:     action,
+		FromStatus: pickList.Status,
+		ToStatus:   pickListStatusCancelled,
+		Actor:      actor,
+	})
+}
+
+// PruneAuditLogs deletes audit log entries older than retention
+func (h *Handlers) PruneAuditLogs(ctx context.Context, retention time.Duration) error {
+	spanCtx, span := h.tracer.Start(ctx, "PruneAuditLogs")
+	defer span.End()
+
+	dbStart := time.Now()
+	deleted, err := h.queries.DeleteAuditLogsBefore(spanCtx, pgtype.Timestamptz{Time: time.Now().Add(-retention), Valid: true})
+	h.recordDBOperation(spanCtx, "delete", "audit_log", dbStart, err)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	span.SetAttributes(attribute.Int64("audit_log.deleted", deleted))
+	if deleted > 0 {
+		slog.Info("Pruned audit log", slog.Int64("deleted", deleted))
+	}
+	return nil
+}
+
+// PruneChangeEvents deletes change feed events older than retention.
+// Clients resuming from an older event have to reload instead.
+func (h *Handlers) PruneChangeEvents(ctx context.Context, retention time.Duration) error {
+	spanCtx, span := h.tracer.Start(ctx, "PruneChangeEvents")
+	defer span.End()
+
+	dbStart := time.Now()
+	deleted, err := h.queries.DeleteChangeEventsBefore(spanCtx, pgtype.Timestamptz{Time: time.Now().Add(-retention), Valid: true})
+	h.recordDBOperation(spanCtx, "delete", "change_event", dbStart, err)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	span.SetAttributes(attribute.Int64("change_event.deleted", deleted))
+	if deleted > 0 {
+		slog.Info("Pruned change events", slog.Int64("deleted", deleted))
+	}
+	return nil
+}
+
+// RefreshInventoryGauges recomputes the per tenant active warehouse and
+// storage room gauges from row counts in the database
+func (h *Handlers) RefreshInventoryGauges(ctx context.Context) error {
+	if h.prometheusMetrics == nil {
+		return nil
+	}
+
+	spanCtx, span := h.tracer.Start(ctx, "RefreshInventoryGauges")
+	defer span.End()
+
+	dbStart := time.Now()
+	warehouseRows, err := h.queries.CountWarehousesByTenant(spanCtx)
+	h.recordDBOperation(spanCtx, "count", "warehouse", dbStart, err)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	dbStart = time.Now()
+	storageRoomRows, err := h.queries.CountStorageRoomsByTenant(spanCtx)
+	h.recordDBOperation(spanCtx, "count", "storage_room", dbStart, err)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	warehouses := make(map[string]int64, len(warehouseRows))
+	for _, row := range warehouseRows {
+		warehouses[row.OrgID] = row.Count
+	}
+	storageRooms := make(map[string]int64, len(storageRoomRows))
+	for _, row := range storageRoomRows {
+		storageRooms[row.OrgID] = row.Count
+	}
+	h.prometheusMetrics.UpdateInventoryCounts(warehouses, storageRooms)
+
+	dbStart = time.Now()
+	occupancyRows, err := h.queries.ListOccupancyByStorageRoom(spanCtx)
+	h.recordDBOperation(spanCtx, "list", "stock_level", dbStart, err)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	occupancy := make([]observability.StorageRoomOccupancy, len(occupancyRows))
+	for i, row := range occupancyRows {
+		occupancy[i] = observability.StorageRoomOccupancy{
+			Tenant:        row.OrgID,
+			StorageRoomID: row.ID,
+			Capacity:      row.Capacity,
+			Occupancy:     row.Occupancy,
+		}
+	}
+	h.prometheusMetrics.UpdateStorageRoomOccupancy(occupancy)
+
+	span.SetAttributes(attribute.Int("tenant.count", len(warehouses)))
+	return nil
+}
+
+// refreshWarehouseGauge recounts the warehouses of one tenant after a create
+// or delete so the gauge does not wait for the next scheduled refresh
+func (h *Handlers) refreshWarehouseGauge(ctx context.Context, orgID string) {
+	if h.prometheusMetrics == nil {
+		return
+	}
+
+	dbStart := time.Now()
+	count, err := h.queries.CountWarehousesForTenant(ctx, orgID)
+	h.recordDBOperation(ctx, "count", "warehouse", dbStart, err)
+	if err != nil {
+		slog.Error("Could not count warehouses: ", slog.Any("err", err.Error()))
+		return
+	}
+	h.prometheusMetrics.UpdateWarehouseCount(orgID, count)
+}
+
+// GetSchedulerStatus reports the last run of every scheduled task
+func (h *Handlers) GetSchedulerStatus(ctx *gin.Context) {
+	if h.scheduler == nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": tr(ctx, "Scheduler is not running"),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": tr(ctx, "Get Scheduler Status Successfully"),
+		"data":    h.scheduler.Status(),
+	})
+}
+
+// PruneOutbox deletes outbox messages delivered more than retention ago
+func (h *Handlers) PruneOutbox(ctx context.Context, retention time.Duration) error {
+	spanCtx, span := h.tracer.Start(ctx, "PruneOutbox")
+	defer span.End()
+
+	dbStart := time.Now()
+	deleted, err := h.queries.DeleteDeliveredOutboxBefore(spanCtx, pgtype.Timestamptz{Time: time.Now().Add(-retention), Valid: true})
+	h.recordDBOperation(spanCtx, "delete", "outbox", dbStart, err)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	span.SetAttributes(attribute.Int64("outbox.deleted", deleted))
+	if deleted > 0 {
+		slog.Info("Pruned delivered outbox messages", slog.Int64("deleted", deleted))
+	}
+	return nil
+}
+
+// PruneAPIUsage deletes the API call counts of days older than retention
+func (h *Handlers) PruneAPIUsage(ctx context.Context, retention time.Duration) error {
+	spanCtx, span := h.tracer.Start(ctx, "PruneAPIUsage")
+	defer span.End()
+
+	day, _ := quota.Day(time.Now().Add(-retention))
+	dbStart := time.Now()
+	deleted, err := h.queries.DeleteAPIUsageBefore(spanCtx, pgtype.Date{Time: day, Valid: true})
+	h.recordDBOperation(spanCtx, "delete", "api_usage", dbStart, err)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	span.SetAttributes(attribute.Int64("api_usage.deleted", deleted))
+	if deleted > 0 {
+		slog.Info("Pruned API usage", slog.Int64("deleted", deleted))
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+package handlers
+
+import (
+	"warehouse-service/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// tr returns a response message in the request's locale. message is the
+// English text, formatted with args as fmt.Sprintf does; see package i18n
+// for the catalogs.
+func tr(ctx *gin.Context, message string, args ...any) string {
+	return middlewares.Translate(ctx, message, args...)
+}
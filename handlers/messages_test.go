@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"warehouse-service/i18n"
+)
+
+// TestMessagesTranslated checks that every message literal the handlers and
+// middlewares answer with has a translation in each catalog, so a new
+// message isn't answered in English to a client asking for another locale
+func TestMessagesTranslated(t *testing.T) {
+	messages := map[string]token.Position{}
+	fset := token.NewFileSet()
+	for _, dir := range []string{".", "../middlewares"} {
+		files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, path := range files {
+			if strings.HasSuffix(path, "_test.go") {
+				continue
+			}
+			file, err := parser.ParseFile(fset, path, nil, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ast.Inspect(file, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				arg := -1
+				switch fun := call.Fun.(type) {
+				case *ast.Ident:
+					switch fun.Name {
+					case "tr", "Translate":
+						arg = 1
+					case "respondV2Error":
+						arg = 3
+					}
+				case *ast.SelectorExpr:
+					if fun.Sel.Name == "Translate" {
+						arg = 1
+					}
+				}
+				if arg < 0 || len(call.Args) <= arg {
+					return true
+				}
+				if lit, ok := call.Args[arg].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+					message, _ := strconv.Unquote(lit.Value)
+					messages[message] = fset.Position(lit.Pos())
+				}
+				return true
+			})
+		}
+	}
+	for _, conflict := range uniqueConflicts {
+		messages[conflict.message] = token.Position{Filename: "dberror.go"}
+	}
+	if len(messages) < 100 {
+		t.Fatalf("found only %d messages, is the scan broken?", len(messages))
+	}
+
+	for _, locale := range i18n.Supported() {
+		if locale == i18n.Default {
+			continue
+		}
+		for message, pos := range messages {
+			if i18n.Translate(locale, message) == message {
+				t.Errorf("%s: %q has no %s translation", pos, message, locale)
+			}
+		}
+	}
+}
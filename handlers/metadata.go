@@ -0,0 +1,310 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+	"warehouse-service/listquery"
+	models "warehouse-service/models/sqlc"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	// Embedded zone database so time zones validate in minimal images
+	_ "time/tzdata"
+)
+
+const (
+	defaultTimeZone = "UTC"
+	maxTags         = 20
+	maxTagLength    = 50
+)
+
+// errTooManyTags rejects tags that would leave an entity with more than
+// maxTags
+var errTooManyTags = fmt.Errorf("at most %d tags are allowed", maxTags)
+
+var weekdays = []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}
+
+var phonePattern = regexp.MustCompile(`^\+?[0-9][0-9 ()\-]{4,24}$`)
+
+// DayHours is the opening window of one weekday as HH:MM in the warehouse
+// time zone
+type DayHours struct {
+	Open  string `json:"open"`
+	Close string `json:"close"`
+}
+
+// OperatingHours maps lower case weekday names to opening hours, a missing
+// day means the warehouse is closed
+type OperatingHours map[string]DayHours
+
+func (o OperatingHours) validate() error {
+	for day, hours := range o {
+		if !isWeekday(day) {
+			return fmt.Errorf("operating hours: unknown weekday %q", day)
+		}
+		open, err := time.Parse("15:04", hours.Open)
+		if err != nil {
+			return fmt.Errorf("operating hours: %s open must be HH:MM", day)
+		}
+		closing, err := time.Parse("15:04", hours.Close)
+		if err != nil {
+			return fmt.Errorf("operating hours: %s close must be HH:MM", day)
+		}
+		if !open.Before(closing) {
+			return fmt.Errorf("operating hours: %s must open before it closes", day)
+		}
+	}
+	return nil
+}
+
+func isWeekday(day string) bool {
+	for _, d := range weekdays {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeOperatingHours reads the stored JSON, unreadable values are treated
+// as no opening hours
+func decodeOperatingHours(raw []byte) OperatingHours {
+	hours := OperatingHours{}
+	if len(raw) > 0 {
+		_ = json.Unmarshal(raw, &hours)
+	}
+	return hours
+}
+
+// warehouseMetadata holds the optional planning fields of a warehouse. A nil
+// field was not sent by the client.
+type warehouseMetadata struct {
+	TimeZone       *string         `json:"time_zone"`
+	OperatingHours *OperatingHours `json:"operating_hours"`
+	ContactEmail   *string         `json:"contact_email"`
+	ContactPhone   *string         `json:"contact_phone"`
+	Tags           *[]string       `json:"tags"`
+}
+
+func (m warehouseMetadata) empty() bool {
+	return m.TimeZone == nil && m.OperatingHours == nil && m.ContactEmail == nil && m.ContactPhone == nil && m.Tags == nil
+}
+
+// validate checks the sent fields and normalizes tags to trimmed, lower
+// case and unique values
+func (m *warehouseMetadata) validate() error {
+	if m.TimeZone != nil {
+		if *m.TimeZone == "" || *m.TimeZone == "Local" {
+			return errors.New("time_zone must be an IANA time zone such as Europe/Berlin")
+		}
+		if _, err := time.LoadLocation(*m.TimeZone); err != nil {
+			return fmt.Errorf("time_zone %q is not a known IANA time zone", *m.TimeZone)
+		}
+	}
+	if m.OperatingHours != nil {
+		if err := m.OperatingHours.validate(); err != nil {
+			return err
+		}
+	}
+	if m.ContactEmail != nil && *m.ContactEmail != "" {
+		addr, err := mail.ParseAddress(*m.ContactEmail)
+		if err != nil || addr.Address != *m.ContactEmail {
+			return errors.New("contact_email must be a plain email address")
+		}
+	}
+	if m.ContactPhone != nil && *m.ContactPhone != "" && !phonePattern.MatchString(*m.ContactPhone) {
+		return errors.New("contact_phone must contain digits, spaces, dashes or parentheses and may start with +")
+	}
+	if m.Tags != nil {
+		tags, err := normalizeTags(*m.Tags)
+		if err != nil {
+			return err
+		}
+		m.Tags = &tags
+	}
+	return nil
+}
+
+func normalizeTags(in []string) ([]string, error) {
+	seen := make(map[string]bool, len(in))
+	tags := make([]string, 0, len(in))
+	for _, tag := range in {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if len(tag) > maxTagLength {
+			return nil, fmt.Errorf("tags must be at most %d characters", maxTagLength)
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	if len(tags) > maxTags {
+		return nil, errTooManyTags
+	}
+	return tags, nil
+}
+
+// storedMetadata is the metadata as written by a full create or update
+type storedMetadata struct {
+	TimeZone       string
+	OperatingHours []byte
+	ContactEmail   pgtype.Text
+	ContactPhone   pgtype.Text
+	Tags           []string
+}
+
+// full resolves the metadata of a full write, omitted fields fall back to
+// base, which holds the defaults or the stored warehouse
+func (m warehouseMetadata) full(base storedMetadata) storedMetadata {
+	if m.TimeZone != nil {
+		base.TimeZone = *m.TimeZone
+	}
+	if m.OperatingHours != nil {
+		base.OperatingHours, _ = json.Marshal(*m.OperatingHours)
+	}
+	if m.ContactEmail != nil {
+		base.ContactEmail = nonEmptyText(*m.ContactEmail)
+	}
+	if m.ContactPhone != nil {
+		base.ContactPhone = nonEmptyText(*m.ContactPhone)
+	}
+	if m.Tags != nil {
+		base.Tags = *m.Tags
+	}
+	return base
+}
+
+// defaultMetadata is the metadata of a warehouse created without any
+func defaultMetadata() storedMetadata {
+	return storedMetadata{
+		TimeZone:       defaultTimeZone,
+		OperatingHours: []byte("{}"),
+		Tags:           []string{},
+	}
+}
+
+func metadataOf(w models.Warehouse) storedMetadata {
+	return storedMetadata{
+		TimeZone:       w.TimeZone,
+		OperatingHours: w.OperatingHours,
+		ContactEmail:   w.ContactEmail,
+		ContactPhone:   w.ContactPhone,
+		Tags:           w.Tags,
+	}
+}
+
+// patchParams maps the sent fields to the nullable PatchWarehouse
+// parameters. An empty contact clears it, the query stores it as NULL.
+func (m warehouseMetadata) patchParams(p *models.PatchWarehouseParams) {
+	p.TimeZone = textParam(m.TimeZone)
+	p.ContactEmail = textParam(m.ContactEmail)
+	p.ContactPhone = textParam(m.ContactPhone)
+	if m.OperatingHours != nil {
+		p.OperatingHours, _ = json.Marshal(*m.OperatingHours)
+	}
+	if m.Tags != nil {
+		p.Tags = *m.Tags
+	}
+}
+
+func nonEmptyText(s string) pgtype.Text {
+	return pgtype.Text{String: s, Valid: s != ""}
+}
+
+func textPtr(t pgtype.Text) *string {
+	if !t.Valid {
+		return nil
+	}
+	return &t.String
+}
+
+func tagsOrEmpty(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
+
+// formMetadata reads the v1 form fields TimeZone, OperatingHours (a JSON
+// object), ContactEmail, ContactPhone and Tags (repeated or comma separated)
+func formMetadata(ctx *gin.Context) (warehouseMetadata, error) {
+	var m warehouseMetadata
+	if v, ok := ctx.GetPostForm("TimeZone"); ok {
+		m.TimeZone = &v
+	}
+	if v, ok := ctx.GetPostForm("OperatingHours"); ok {
+		var hours OperatingHours
+		if v != "" {
+			if err := json.Unmarshal([]byte(v), &hours); err != nil {
+				return m, errors.New("OperatingHours must be a JSON object keyed by weekday")
+			}
+		}
+		m.OperatingHours = &hours
+	}
+	if v, ok := ctx.GetPostForm("ContactEmail"); ok {
+		m.ContactEmail = &v
+	}
+	if v, ok := ctx.GetPostForm("ContactPhone"); ok {
+		m.ContactPhone = &v
+	}
+	if values, ok := ctx.GetPostFormArray("Tags"); ok {
+		var tags []string
+		for _, v := range values {
+			tags = append(tags, strings.Split(v, ",")...)
+		}
+		m.Tags = &tags
+	}
+	return m, m.validate()
+}
+
+// warehouseListSpec sorts the warehouses of the warehouse list endpoints,
+// whose filters listFilters reads
+var warehouseListSpec = &listquery.Spec{
+	Table: "warehouse",
+	Sort: map[string]string{
+		"id":      "id",
+		"name":    "name",
+		"city":    "city",
+		"country": "country",
+		"status":  "status",
+	},
+	Default: []listquery.Order{{Field: "id"}},
+	Key:     "id",
+}
+
+// listFilters reads the ?tag= (repeatable, all must match), ?time_zone=,
+// ?status= and ?attr.<path>= filters of the warehouse list endpoints into q
+func listFilters(ctx *gin.Context, q *listquery.Query) error {
+	if values := ctx.QueryArray("tag"); len(values) > 0 {
+		tags, err := normalizeTags(values)
+		if err != nil {
+			return err
+		}
+		q.Where("tags", listquery.Contains, tags)
+	}
+	if tz := ctx.Query("time_zone"); tz != "" {
+		q.Where("time_zone", listquery.Eq, tz)
+	}
+	attrs, err := attributeFilter(ctx)
+	if err != nil {
+		return err
+	}
+	if attrs != nil {
+		q.Where("attributes", listquery.Contains, attrs)
+	}
+	status, err := statusFilter(ctx)
+	if err != nil {
+		return err
+	}
+	if status.Valid {
+		q.Where("status", listquery.Eq, status.String)
+	}
+	return nil
+}
@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/quota"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// maxMeteringDays caps the days one metering request covers
+const maxMeteringDays = 366
+
+// defaultMeteringDays is the metering range when ?from= is missing
+const defaultMeteringDays = 30
+
+// meteringCSVHeader is the header of the metering CSV, one row per tenant
+// and day
+var meteringCSVHeader = []string{"org_id", "day", "api_calls", "warehouses", "storage_rooms", "items", "events_delivered", "aggregated_at"}
+
+// MeteringResponse is the usage of a tenant on one UTC day. API calls and
+// events count over the day, entities are those stored at AggregatedAt.
+type MeteringResponse struct {
+	OrgID           string     `json:"org_id" v1:"OrgID"`
+	Day             string     `json:"day" v1:"Day"`
+	APICalls        int64      `json:"api_calls" v1:"APICalls"`
+	Warehouses      int64      `json:"warehouses" v1:"Warehouses"`
+	StorageRooms    int64      `json:"storage_rooms" v1:"StorageRooms"`
+	Items           int64      `json:"items" v1:"Items"`
+	EventsDelivered int64      `json:"events_delivered" v1:"EventsDelivered"`
+	AggregatedAt    *time.Time `json:"aggregated_at" v1:"AggregatedAt"`
+}
+
+// MeteringAggregateResponse reports an aggregation run, Rows counting the
+// tenants written over all Days
+type MeteringAggregateResponse struct {
+	Days []string `json:"days" v1:"Days"`
+	Rows int64    `json:"rows" v1:"Rows"`
+}
+
+func newMeteringResponse(m models.TenantMetering) MeteringResponse {
+	return MeteringResponse{
+		OrgID:           m.OrgID,
+		Day:             m.Day.Time.Format(time.DateOnly),
+		APICalls:        m.ApiCalls,
+		Warehouses:      m.Warehouses,
+		StorageRooms:    m.StorageRooms,
+		Items:           m.Items,
+		EventsDelivered: m.EventsDelivered,
+		AggregatedAt:    timePtr(m.AggregatedAt),
+	}
+}
+
+func meteringRecord(m MeteringResponse) []string {
+	var aggregatedAt string
+	if m.AggregatedAt != nil {
+		aggregatedAt = m.AggregatedAt.UTC().Format(time.RFC3339)
+	}
+	return []string{
+		m.OrgID,
+		m.Day,
+		strconv.FormatInt(m.APICalls, 10),
+		strconv.FormatInt(m.Warehouses, 10),
+		strconv.FormatInt(m.StorageRooms, 10),
+		strconv.FormatInt(m.Items, 10),
+		strconv.FormatInt(m.EventsDelivered, 10),
+		aggregatedAt,
+	}
+}
+
+// meteringRange reads the UTC days ?from= and ?to=, both included, as the
+// [from, to) range of days. A missing ?to= is today and a missing ?from=
+// goes back 30 days from it.
+func meteringRange(ctx *gin.Context, now time.Time) (from, to time.Time, err error) {
+	today, _ := quota.Day(now)
+	to = today
+	if v := ctx.Query("to"); v != "" {
+		if to, err = time.Parse(time.DateOnly, v); err != nil {
+			return from, to, fmt.Errorf("to must be a date, got %q", v)
+		}
+	}
+	to = to.AddDate(0, 0, 1)
+	from = to.AddDate(0, 0, -defaultMeteringDays)
+	if v := ctx.Query("from"); v != "" {
+		if from, err = time.Parse(time.DateOnly, v); err != nil {
+			return from, to, fmt.Errorf("from must be a date, got %q", v)
+		}
+	}
+	if !from.Before(to) {
+		return from, to, errors.New("from must not be after to")
+	}
+	if to.Sub(from) > maxMeteringDays*24*time.Hour {
+		return from, to, fmt.Errorf("the range must not span more than %d days", maxMeteringDays)
+	}
+	return from, to, nil
+}
+
+// aggregateMetering writes the usage of every tenant on yesterday and today.
+// Yesterday gets its final API calls and events on the first run after
+// midnight UTC, the entities are counted at each run.
+func (h *Handlers) aggregateMetering(ctx context.Context, now time.Time) (MeteringAggregateResponse, error) {
+	today, _ := quota.Day(now)
+	result := MeteringAggregateResponse{Days: []string{}}
+	for _, day := range []time.Time{today.AddDate(0, 0, -1), today} {
+		dbStart := time.Now()
+		rows, err := h.queries.AggregateMetering(ctx, models.AggregateMeteringParams{
+			Day:      pgtype.Date{Time: day, Valid: true},
+			DayStart: pgtype.Timestamptz{Time: day, Valid: true},
+			DayEnd:   pgtype.Timestamptz{Time: day.AddDate(0, 0, 1), Valid: true},
+		})
+		h.recordDBOperation(ctx, "aggregate", "tenant_metering", dbStart, err)
+		if err != nil {
+			return result, err
+		}
+		result.Days = append(result.Days, day.Format(time.DateOnly))
+		result.Rows += rows
+	}
+	return result, nil
+}
+
+// AggregateMetering is the scheduled aggregation of the tenants' usage
+func (h *Handlers) AggregateMetering(ctx context.Context) error {
+	spanCtx, span := h.tracer.Start(ctx, "AggregateMetering")
+	defer span.End()
+
+	result, err := h.aggregateMetering(spanCtx, time.Now())
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	span.SetAttributes(attribute.Int64("metering.rows", result.Rows))
+	return nil
+}
+
+// AggregateMeteringNow aggregates the usage of every tenant without waiting
+// for the schedule, before a billing export for instance
+func (h *Handlers) AggregateMeteringNow(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "AggregateMeteringNow")
+	defer span.End()
+
+	result, err := h.aggregateMetering(spanCtx, time.Now())
+	if err != nil {
+		slog.Error("Got an error while aggregating metering: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to aggregate metering"),
+		})
+		return
+	}
+	slog.Info("Aggregated metering",
+		slog.String("actor", actorID(ctx)),
+		slog.Int64("rows", result.Rows))
+
+	span.SetAttributes(
+		attribute.Int64("metering.rows", result.Rows),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Aggregate Metering Successfully"),
+		"data":    result,
+	})
+}
+
+// GetTenantMetering returns the tenant's daily usage between ?from= and
+// ?to=, as CSV with ?format=csv
+func (h *Handlers) GetTenantMetering(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "GetTenantMetering")
+	defer span.End()
+
+	asCSV, err := reportFormat(ctx)
+	var from, to time.Time
+	if err == nil {
+		from, to, err = meteringRange(ctx, time.Now())
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(attribute.String("tenant.id", orgID))
+
+	dbStart := time.Now()
+	rows, err := h.readQueries(spanCtx).ListTenantMetering(spanCtx, models.ListTenantMeteringParams{
+		OrgID:   orgID,
+		FromDay: pgtype.Date{Time: from, Valid: true},
+		ToDay:   pgtype.Date{Time: to, Valid: true},
+	})
+	h.recordDBOperation(spanCtx, "list", "tenant_metering", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while listing metering: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to get metering"),
+		})
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int("report.rows", len(rows)),
+		attribute.String("operation.status", "success"),
+	)
+	respondMetering(ctx, rows, asCSV)
+}
+
+// GetMetering returns the daily usage of every tenant between ?from= and
+// ?to=, for the billing system with ?format=csv
+func (h *Handlers) GetMetering(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "GetMetering")
+	defer span.End()
+
+	asCSV, err := reportFormat(ctx)
+	var from, to time.Time
+	if err == nil {
+		from, to, err = meteringRange(ctx, time.Now())
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	dbStart := time.Now()
+	rows, err := h.readQueries(spanCtx).ListMetering(spanCtx, models.ListMeteringParams{
+		FromDay: pgtype.Date{Time: from, Valid: true},
+		ToDay:   pgtype.Date{Time: to, Valid: true},
+	})
+	h.recordDBOperation(spanCtx, "list", "tenant_metering", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while listing metering: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to get metering"),
+		})
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int("report.rows", len(rows)),
+		attribute.String("operation.status", "success"),
+	)
+	respondMetering(ctx, rows, asCSV)
+}
+
+// respondMetering sends the metering rows as JSON or as a CSV download
+func respondMetering(ctx *gin.Context, rows []models.TenantMetering, asCSV bool) {
+	metering := make([]MeteringResponse, 0, len(rows))
+	for _, row := range rows {
+		metering = append(metering, newMeteringResponse(row))
+	}
+	if asCSV {
+		records := make([][]string, 0, len(metering))
+		for _, m := range metering {
+			records = append(records, meteringRecord(m))
+		}
+		writeCSV(ctx, "metering.csv", meteringCSVHeader, records)
+		return
+	}
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Get Metering Successfully"),
+		"data":    metering,
+	})
+}
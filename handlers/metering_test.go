@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMeteringRange(t *testing.T) {
+	now := time.Date(2024, 3, 10, 15, 0, 0, 0, time.UTC)
+	tests := []struct {
+		query    string
+		from, to string
+		fails    bool
+	}{
+		{query: "", from: "2024-02-10", to: "2024-03-11"},
+		{query: "from=2024-03-01&to=2024-03-07", from: "2024-03-01", to: "2024-03-08"},
+		{query: "from=2024-03-07&to=2024-03-07", from: "2024-03-07", to: "2024-03-08"},
+		{query: "to=2024-02-29", from: "2024-01-31", to: "2024-03-01"},
+		{query: "from=2023-03-11", from: "2023-03-11", to: "2024-03-11"},
+		{query: "from=2023-03-10", fails: true},
+		{query: "from=2024-03-08&to=2024-03-01", fails: true},
+		{query: "from=2024-03-01T00:00:00Z", fails: true},
+		{query: "to=03/01/2024", fails: true},
+	}
+	for _, tt := range tests {
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		ctx.Request = httptest.NewRequest("GET", "/v1/admin/metering?"+tt.query, nil)
+		from, to, err := meteringRange(ctx, now)
+		if (err != nil) != tt.fails {
+			t.Errorf("%q: err = %v", tt.query, err)
+			continue
+		}
+		if err == nil && (from.Format(time.DateOnly) != tt.from || to.Format(time.DateOnly) != tt.to) {
+			t.Errorf("%q: range %s - %s, want %s - %s", tt.query, from.Format(time.DateOnly), to.Format(time.DateOnly), tt.from, tt.to)
+		}
+	}
+}
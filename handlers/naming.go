@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DTO fields are named in snake_case. Names v1 returned before are kept in
+// a v1 tag next to the json one,
+//
+//	WarehouseID int32 `json:"warehouse_id" v1:"WarehouseID"`
+//
+// and v1 responses go out with them unless the client sends
+// Prefer: naming=snake_case. New fields get no v1 name, so v1 clients read
+// them in snake_case too. TestJSONNaming holds DTOs to this.
+const v1NameTag = "v1"
+
+// snakeCasePreference is the Prefer header preference of v1 clients
+// reading the snake_case names
+const snakeCasePreference = "naming=snake_case"
+
+// dtoField is a field of a DTO as encoding/json serializes it
+type dtoField struct {
+	name   string // json name
+	v1Name string // v1 name, empty when v1 uses name too
+	index  []int
+}
+
+var dtoFieldCache sync.Map // reflect.Type -> []dtoField
+
+// dtoFields returns the serialized fields of the struct type t in order.
+// Fields of embedded structs are promoted as encoding/json does.
+func dtoFields(t reflect.Type) []dtoField {
+	if cached, ok := dtoFieldCache.Load(t); ok {
+		return cached.([]dtoField)
+	}
+	fields := collectFields(t, nil)
+	dtoFieldCache.Store(t, fields)
+	return fields
+}
+
+func collectFields(t reflect.Type, index []int) []dtoField {
+	var fields []dtoField
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		fieldIndex := append(append([]int(nil), index...), i)
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			fields = append(fields, collectFields(f.Type, fieldIndex)...)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, dtoField{name: name, v1Name: f.Tag.Get(v1NameTag), index: fieldIndex})
+	}
+
+	// A field hides the fields of the same name in structs it embeds
+	kept := fields[:0]
+	for _, f := range fields {
+		hidden := false
+		for _, other := range fields {
+			if other.name == f.name && len(other.index) < len(f.index) {
+				hidden = true
+				break
+			}
+		}
+		if !hidden {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// prefersSnakeCase tells whether a v1 request asks for the snake_case names
+func prefersSnakeCase(ctx *gin.Context) bool {
+	for _, header := range ctx.Request.Header.Values("Prefer") {
+		for _, preference := range strings.Split(header, ",") {
+			preference, _, _ = strings.Cut(preference, ";")
+			if strings.EqualFold(strings.TrimSpace(preference), snakeCasePreference) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// respondV1 writes the body of a v1 response carrying DTOs, with their v1
+// names unless the client prefers snake_case
+func respondV1(ctx *gin.Context, status int, obj any) {
+	ctx.Writer.Header().Add("Vary", "Prefer")
+	if prefersSnakeCase(ctx) {
+		ctx.Header("Preference-Applied", snakeCasePreference)
+		ctx.JSON(status, obj)
+		return
+	}
+	ctx.JSON(status, v1Names{value: obj})
+}
+
+// v1Names serializes value with the v1 names of its fields
+type v1Names struct {
+	value any
+}
+
+func (n v1Names) MarshalJSON() ([]byte, error) {
+	raw, err := json.Marshal(n.value)
+	if err != nil {
+		return nil, err
+	}
+	return renameV1(raw, reflect.ValueOf(n.value))
+}
+
+var (
+	marshalerType       = reflect.TypeFor[json.Marshaler]()
+	partialResponseType = reflect.TypeFor[partialResponse]()
+)
+
+// renameV1 renames the members of raw, the JSON encoding of v, to their v1
+// names. Objects are matched to the structs they were encoded from, so map
+// keys and values that marshal themselves, such as attributes, are left
+// alone.
+func renameV1(raw []byte, v reflect.Value) ([]byte, error) {
+	if bytes.Equal(raw, []byte("null")) {
+		return raw, nil
+	}
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
+		if v.IsNil() || v.Type().Implements(marshalerType) {
+			return raw, nil
+		}
+		v = v.Elem()
+	}
+	if v.Type() == partialResponseType {
+		return renameV1(raw, v.Field(0))
+	}
+	if v.Type().Implements(marshalerType) {
+		return raw, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		fields := dtoFields(v.Type())
+		return renameMembers(raw, func(key string) (string, reflect.Value) {
+			for _, f := range fields {
+				if f.name == key {
+					name := key
+					if f.v1Name != "" {
+						name = f.v1Name
+					}
+					return name, v.FieldByIndex(f.index)
+				}
+			}
+			return key, reflect.Value{}
+		})
+	case reflect.Map:
+		values := map[string]reflect.Value{}
+		iter := v.MapRange()
+		for iter.Next() {
+			key, ok := mapKey(iter.Key())
+			if !ok {
+				return raw, nil
+			}
+			values[key] = iter.Value()
+		}
+		return renameMembers(raw, func(key string) (string, reflect.Value) {
+			return key, values[key]
+		})
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return raw, nil
+		}
+		var elems []json.RawMessage
+		if err := json.Unmarshal(raw, &elems); err != nil || len(elems) != v.Len() {
+			return raw, nil
+		}
+		var b bytes.Buffer
+		b.WriteByte('[')
+		for i, elem := range elems {
+			renamed, err := renameV1(elem, v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.Write(renamed)
+		}
+		b.WriteByte(']')
+		return b.Bytes(), nil
+	}
+	return raw, nil
+}
+
+// renameMembers rewrites the JSON object raw, naming each member and
+// renaming its value with what lookup returns for its key
+func renameMembers(raw []byte, lookup func(key string) (string, reflect.Value)) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return raw, nil
+	}
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := tok.(string)
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		name, field := lookup(key)
+		if field.IsValid() {
+			if value, err = renameV1(value, field); err != nil {
+				return nil, err
+			}
+		}
+		if b.Len() > 1 {
+			b.WriteByte(',')
+		}
+		encoded, _ := json.Marshal(name)
+		b.Write(encoded)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// mapKey is the member name encoding/json gives a map key
+func mapKey(k reflect.Value) (string, bool) {
+	switch k.Kind() {
+	case reflect.String:
+		return k.String(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(k.Uint(), 10), true
+	}
+	return "", false
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+var snakeCaseName = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+
+// TestJSONNaming holds the structs of the package that serialize to JSON to
+// the naming policy: every exported field is named explicitly, in
+// snake_case, and the v1 names are the ones listed in testdata/v1_names.txt.
+// New fields get no v1 name; a v1 name is never changed or dropped, v1
+// clients rely on it.
+func TestJSONNaming(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fset := token.NewFileSet()
+	var v1Names []string
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			spec, ok := n.(*ast.TypeSpec)
+			if !ok {
+				return true
+			}
+			st, ok := spec.Type.(*ast.StructType)
+			if !ok || !hasJSONTags(st) {
+				return true
+			}
+			for _, field := range st.Fields.List {
+				if len(field.Names) == 0 {
+					continue // embedded, its fields are checked where it is declared
+				}
+				tag := reflect.StructTag("")
+				if field.Tag != nil {
+					raw, _ := strconv.Unquote(field.Tag.Value)
+					tag = reflect.StructTag(raw)
+				}
+				for _, name := range field.Names {
+					if !name.IsExported() {
+						continue
+					}
+					where := fmt.Sprintf("%s: %s.%s", fset.Position(name.Pos()), spec.Name.Name, name.Name)
+					jsonName, _, _ := strings.Cut(tag.Get("json"), ",")
+					switch {
+					case jsonName == "-":
+						continue
+					case jsonName == "":
+						t.Errorf("%s has no json name, name it in snake_case", where)
+					case !snakeCaseName.MatchString(jsonName):
+						t.Errorf("%s is named %q, name it in snake_case", where, jsonName)
+					}
+					if v1, ok := tag.Lookup(v1NameTag); ok {
+						v1Names = append(v1Names, fmt.Sprintf("%s.%s %s", spec.Name.Name, name.Name, v1))
+					}
+				}
+			}
+			return true
+		})
+	}
+
+	golden, err := os.ReadFile(filepath.Join("testdata", "v1_names.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]bool{}
+	for _, line := range strings.Split(strings.TrimSpace(string(golden)), "\n") {
+		want[line] = true
+	}
+	sort.Strings(v1Names)
+	for _, name := range v1Names {
+		if !want[name] {
+			t.Errorf("v1 name %s is new, name new fields in snake_case only", name)
+		}
+		delete(want, name)
+	}
+	for name := range want {
+		t.Errorf("v1 name %s is gone, v1 clients still read it", name)
+	}
+}
+
+func hasJSONTags(st *ast.StructType) bool {
+	for _, field := range st.Fields.List {
+		if field.Tag != nil && strings.Contains(field.Tag.Value, `json:"`) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestV1Names(t *testing.T) {
+	room := StorageRoomResponse{ID: 7, WarehouseID: 1, Attributes: json.RawMessage(`{"ZoneType": "kept"}`)}
+	fields, err := parseFields[StorageRoomResponse]("WarehouseID,zone_type")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := gin.H{
+		"message": "ok",
+		"data": gin.H{
+			"rooms":   []StorageRoomResponse{room},
+			"by_id":   map[int32]StorageRoomResponse{7: room},
+			"partial": fields.one(room),
+			"none":    []StorageRoomResponse(nil),
+		},
+	}
+
+	got, err := json.Marshal(v1Names{value: body})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rendered := `{"ID":7,"Name":"","Number":"","WarehouseID":1,"ZoneType":"","Tags":null,"Attributes":{"ZoneType":"kept"},"Capacity":0,"Aisle":0,"Bay":0}`
+	want := `{"data":{"by_id":{"7":` + rendered + `},"none":null,"partial":{"WarehouseID":1,"ZoneType":""},"rooms":[` + rendered + `]},"message":"ok"}`
+	if string(got) != want {
+		t.Errorf("v1 names\n got: %s\nwant: %s", got, want)
+	}
+
+	for _, prefer := range []string{"", "return=minimal", "respond-async, naming=snake_case"} {
+		rec := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(rec)
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		if prefer != "" {
+			ctx.Request.Header.Set("Prefer", prefer)
+		}
+		respondV1(ctx, http.StatusOK, room)
+		snake := strings.Contains(prefer, snakeCasePreference)
+		if strings.Contains(rec.Body.String(), `"warehouse_id":1`) != snake || (rec.Header().Get("Preference-Applied") != "") != snake {
+			t.Errorf("Prefer %q answered %s with Preference-Applied %q", prefer, rec.Body, rec.Header().Get("Preference-Applied"))
+		}
+	}
+}
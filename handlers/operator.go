@@ -0,0 +1,468 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"warehouse-service/middlewares"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Operator handlers serve the /admin group. They act across tenants, so
+// the organization comes from the path or query rather than the session.
+
+const auditEntityAPIKey = "api_key"
+
+type createAPIKeyRequest struct {
+	Name string `json:"name" binding:"required"`
+	// Go duration such as 720h, empty never expires
+	ExpiresIn string `json:"expires_in"`
+}
+
+type logLevelRequest struct {
+	Level string `json:"level" binding:"required"`
+}
+
+// ListTenants returns the organizations that own warehouses or API keys
+// with a summary of each
+func (h *Handlers) ListTenants(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "ListTenants")
+	defer span.End()
+
+	limit, offset, err := pageParams(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	dbStart := time.Now()
+	tenants, err := h.queries.ListTenants(spanCtx, models.ListTenantsParams{
+		Limit:  limit,
+		Offset: offset,
+	})
+	h.recordDBOperation(spanCtx, "list", "tenant", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while listing tenants: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to list tenants"),
+		})
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int("tenant.count", len(tenants)),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "List Tenants Successfully"),
+		"data":    mapSlice(tenants, newTenantResponse),
+	})
+}
+
+// ListTenantAPIKeys returns the API keys of an organization, revoked and
+// expired ones included
+func (h *Handlers) ListTenantAPIKeys(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "ListTenantAPIKeys")
+	defer span.End()
+
+	limit, offset, err := pageParams(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	orgID := ctx.Param("org_id")
+	span.SetAttributes(attribute.String("tenant.id", orgID))
+
+	dbStart := time.Now()
+	keys, err := h.queries.ListAPIKeys(spanCtx, models.ListAPIKeysParams{
+		OrgID:  orgID,
+		Limit:  limit,
+		Offset: offset,
+	})
+	h.recordDBOperation(spanCtx, "list", "api_key", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while listing API keys: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to list API keys"),
+		})
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int("api_key.count", len(keys)),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "List API Keys Successfully"),
+		"data":    mapSlice(keys, newAPIKeyResponse),
+	})
+}
+
+// CreateTenantAPIKey issues an API key for an organization, like the
+// create-apikey command. The key is in the response only, just its hash is
+// stored.
+func (h *Handlers) CreateTenantAPIKey(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "CreateTenantAPIKey")
+	defer span.End()
+
+	var req createAPIKeyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	orgID := ctx.Param("org_id")
+	actor := actorID(ctx)
+	params := models.CreateAPIKeyParams{
+		OrgID:     orgID,
+		Name:      req.Name,
+		CreatedBy: "admin:" + actor,
+	}
+	if req.ExpiresIn != "" {
+		expiresIn, err := time.ParseDuration(req.ExpiresIn)
+		if err != nil || expiresIn <= 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": tr(ctx, "expires_in must be a positive duration such as 720h"),
+			})
+			return
+		}
+		params.ExpiresAt = pgtype.Timestamptz{Time: time.Now().Add(expiresIn), Valid: true}
+	}
+	span.SetAttributes(attribute.String("tenant.id", orgID))
+
+	key, prefix, secretHash, err := middlewares.GenerateAPIKey()
+	if err != nil {
+		slog.Error("Got an error while generating API key: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": tr(ctx, "Failed to create API key"),
+		})
+		return
+	}
+	params.Prefix = prefix
+	params.SecretHash = secretHash
+
+	var apiKey models.ApiKey
+	err = pgx.BeginFunc(spanCtx, h.db, func(tx pgx.Tx) error {
+		qtx := h.queries.WithTx(tx)
+		dbStart := time.Now()
+		var err error
+		apiKey, err = qtx.CreateAPIKey(spanCtx, params)
+		h.recordDBOperation(spanCtx, "create", "api_key", dbStart, err)
+		if err != nil {
+			return err
+		}
+		return h.recordAudit(spanCtx, qtx, auditEntry{
+			OrgID:      orgID,
+			EntityType: auditEntityAPIKey,
+			EntityID:   apiKey.ID,
+			Action:     "create",
+			Actor:      actor,
+		})
+	})
+	if err != nil {
+		slog.Error("Got an error while creating API key: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to create API key"),
+		})
+		return
+	}
+
+	slog.Info("Created API key",
+		slog.Int64("id", apiKey.ID),
+		slog.String("tenant_id", orgID),
+		slog.String("prefix", apiKey.Prefix),
+		slog.String("actor", actor),
+	)
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	respondV1(ctx, http.StatusCreated, gin.H{
+		"message": tr(ctx, "Create API Key Successfully"),
+		"data": CreatedAPIKeyResponse{
+			Key:    key,
+			APIKey: newAPIKeyResponse(apiKey),
+		},
+	})
+}
+
+// RevokeAPIKey stops an API key from authenticating. Revoking is final,
+// 409 when the key is revoked already.
+func (h *Handlers) RevokeAPIKey(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "RevokeAPIKey")
+	defer span.End()
+
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "Invalid API key ID format"),
+		})
+		return
+	}
+	actor := actorID(ctx)
+	span.SetAttributes(attribute.Int64("api_key.id", id))
+
+	var apiKey models.ApiKey
+	errRevoked := errors.New("API key was already revoked")
+	err = pgx.BeginFunc(spanCtx, h.db, func(tx pgx.Tx) error {
+		qtx := h.queries.WithTx(tx)
+		dbStart := time.Now()
+		var err error
+		apiKey, err = qtx.RevokeAPIKey(spanCtx, id)
+		h.recordDBOperation(spanCtx, "update", "api_key", dbStart, err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Either missing or revoked already
+			dbStart = time.Now()
+			_, err = qtx.GetAPIKey(spanCtx, id)
+			h.recordDBOperation(spanCtx, "get", "api_key", dbStart, err)
+			if err == nil {
+				return errRevoked
+			}
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		return h.recordAudit(spanCtx, qtx, auditEntry{
+			OrgID:      apiKey.OrgID,
+			EntityType: auditEntityAPIKey,
+			EntityID:   apiKey.ID,
+			Action:     "revoke",
+			Actor:      actor,
+		})
+	})
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "API key not found"),
+		})
+		return
+	case errors.Is(err, errRevoked):
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+		return
+	case err != nil:
+		slog.Error("Got an error while revoking API key: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to revoke API key"),
+		})
+		return
+	}
+
+	slog.Info("Revoked API key",
+		slog.Int64("id", apiKey.ID),
+		slog.String("tenant_id", apiKey.OrgID),
+		slog.String("prefix", apiKey.Prefix),
+		slog.String("actor", actor),
+	)
+	span.SetAttributes(
+		attribute.String("tenant.id", apiKey.OrgID),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Revoke API Key Successfully"),
+		"data":    newAPIKeyResponse(apiKey),
+	})
+}
+
+// GetOutboxStatus reports the outbox backlog and the undelivered messages
+// with their attempts and last error, in the order the relay retries them.
+// ?org_id= limits the messages to one organization.
+func (h *Handlers) GetOutboxStatus(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "GetOutboxStatus")
+	defer span.End()
+
+	limit, offset, err := pageParams(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	params := models.ListPendingOutboxMessagesParams{
+		Limit:  limit,
+		Offset: offset,
+	}
+	if v := ctx.Query("org_id"); v != "" {
+		params.OrgID = pgtype.Text{String: v, Valid: true}
+		span.SetAttributes(attribute.String("tenant.id", v))
+	}
+
+	dbStart := time.Now()
+	backlog, err := h.queries.GetOutboxBacklog(spanCtx)
+	h.recordDBOperation(spanCtx, "get", "outbox", dbStart, err)
+	var messages []models.Outbox
+	if err == nil {
+		dbStart = time.Now()
+		messages, err = h.queries.ListPendingOutboxMessages(spanCtx, params)
+		h.recordDBOperation(spanCtx, "list", "outbox", dbStart, err)
+	}
+	if err != nil {
+		slog.Error("Got an error while reading the outbox: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to read the outbox"),
+		})
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int64("outbox.pending", backlog.Pending),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Get Outbox Status Successfully"),
+		"data": OutboxStatusResponse{
+			Pending:    backlog.Pending,
+			LagSeconds: backlog.LagSeconds,
+			Messages:   mapSlice(messages, newOutboxMessageResponse),
+		},
+	})
+}
+
+// GetServiceStatus checks the health of the sibling services the
+// warehouse calls
+func (h *Handlers) GetServiceStatus(ctx *gin.Context) {
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "GetServiceStatus")
+	defer span.End()
+
+	var inventory, orders func(context.Context) error
+	if h.services.Inventory != nil {
+		inventory = h.services.Inventory.Check
+	}
+	if h.services.Orders != nil {
+		orders = h.services.Orders.Check
+	}
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Get Service Status Successfully"),
+		"data": ServiceStatusesResponse{
+			Inventory: serviceStatus(spanCtx, inventory),
+			Orders:    serviceStatus(spanCtx, orders),
+		},
+	})
+}
+
+// GetLogLevel returns the level the service logs at
+func (h *Handlers) GetLogLevel(ctx *gin.Context) {
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Get Log Level Successfully"),
+		"data":    LogLevelResponse{Level: strings.ToLower(observability.LogLevel.Level().String())},
+	})
+}
+
+// SetLogLevel changes the log level of the instance serving the request
+// until the next config reload, which applies LOG_LEVEL again
+func (h *Handlers) SetLogLevel(ctx *gin.Context) {
+	var req logLevelRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(req.Level)); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "level must be debug, info, warn or error"),
+		})
+		return
+	}
+
+	previous := observability.LogLevel.Level()
+	observability.SetLogLevel(level)
+	slog.Warn("Log level changed",
+		slog.String("from", previous.String()),
+		slog.String("to", level.String()),
+		slog.String("actor", actorID(ctx)),
+	)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Set Log Level Successfully"),
+		"data":    LogLevelResponse{Level: strings.ToLower(level.String())},
+	})
+}
+
+// FlushCaches drops the prepared statements and statement descriptions
+// pgx caches per connection by resetting the database pools of the
+// instance serving the request, e.g. after a migration changed a table
+// under a running service. It holds no other caches.
+func (h *Handlers) FlushCaches(ctx *gin.Context) {
+	_, span := h.tracer.Start(ctx.Request.Context(), "FlushCaches")
+	defer span.End()
+
+	h.router.Reset()
+	slog.Warn("Flushed database connection caches", slog.String("actor", actorID(ctx)))
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": tr(ctx, "Flush Caches Successfully"),
+	})
+}
+
+// GetJobStatus reports the scheduled tasks of the instance serving the
+// request and the background jobs of every tenant by kind and status
+func (h *Handlers) GetJobStatus(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "GetJobStatus")
+	defer span.End()
+
+	counts, err := h.countJobs(spanCtx)
+	if err != nil {
+		slog.Error("Got an error while counting jobs: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to count jobs"),
+		})
+		return
+	}
+	data := JobStatusResponse{Jobs: counts}
+	if h.scheduler != nil {
+		data.Scheduler = h.scheduler.Status()
+	}
+
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Get Job Status Successfully"),
+		"data":    data,
+	})
+}
+
+// countJobs returns job counts keyed by kind, then status
+func (h *Handlers) countJobs(ctx context.Context) (map[string]map[string]int64, error) {
+	dbStart := time.Now()
+	rows, err := h.queries.CountJobsByStatus(ctx)
+	h.recordDBOperation(ctx, "count", "job", dbStart, err)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]map[string]int64)
+	for _, row := range rows {
+		if counts[row.Kind] == nil {
+			counts[row.Kind] = make(map[string]int64)
+		}
+		counts[row.Kind][row.Status] = row.Jobs
+	}
+	return counts, nil
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"context"
+	"time"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/outbox"
+)
+
+// warehouseDeleted is the payload of a warehouse.deleted event
+type warehouseDeleted struct {
+	ID int64 `json:"id"`
+}
+
+// enqueueWarehouseEvent stores a warehouse event in the outbox. q must
+// belong to the transaction that changed the warehouse.
+func (h *Handlers) enqueueWarehouseEvent(ctx context.Context, q *models.Queries, topic string, warehouse models.Warehouse) error {
+	dbStart := time.Now()
+	err := outbox.Enqueue(ctx, q, warehouse.OrgID, topic, warehouse.ID, newWarehouseV2(warehouse))
+	h.recordDBOperation(ctx, "create", "outbox", dbStart, err)
+	return err
+}
+
+// transferEvent is the payload of the transfer events
+type transferEvent struct {
+	Transfer TransferOrderResponse       `json:"transfer"`
+	Lines    []TransferOrderLineResponse `json:"lines"`
+}
+
+// MarshalJSON keeps the v1 names of the transfer order, which the
+// transfer.v1 schema was published with
+func (e transferEvent) MarshalJSON() ([]byte, error) {
+	type plain transferEvent
+	return v1Names{value: plain(e)}.MarshalJSON()
+}
+
+// enqueueTransferEvent stores a transfer order event in the outbox. q must
+// belong to the transaction that changed the transfer order.
+func (h *Handlers) enqueueTransferEvent(ctx context.Context, q *models.Queries, topic string, order models.TransferOrder, lines []models.TransferOrderLine) error {
+	dbStart := time.Now()
+	err := outbox.Enqueue(ctx, q, order.OrgID, topic, order.ID, transferEvent{
+		Transfer: newTransferOrderResponse(order),
+		Lines:    mapSlice(lines, newTransferOrderLineResponse),
+	})
+	h.recordDBOperation(ctx, "create", "outbox", dbStart, err)
+	return err
+}
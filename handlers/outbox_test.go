@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/outbox"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// eventContracts holds payloads of every published topic as the handlers
+// build them, the sparse and the full shape where they differ
+func eventContracts() map[string][]any {
+	bare := models.Warehouse{ID: 1, Name: "Main", Status: warehouseStatusActive}
+	full := models.Warehouse{
+		ID:             2,
+		Name:           "North",
+		Address:        "1 Dock Rd",
+		City:           "Oslo",
+		Country:        "NO",
+		Latitude:       pgtype.Float8{Float64: 59.91, Valid: true},
+		Longitude:      pgtype.Float8{Float64: 10.75, Valid: true},
+		TimeZone:       "Europe/Oslo",
+		OperatingHours: []byte(`{"monday": {"open": "08:00", "close": "16:00"}}`),
+		ContactEmail:   pgtype.Text{String: "north@example.com", Valid: true},
+		Tags:           []string{"cold"},
+		Attributes:     []byte(`{"dock_count": 4}`),
+		Status:         warehouseStatusActive,
+	}
+	warehouses := []any{newWarehouseV2(bare), newWarehouseV2(full)}
+
+	now := pgtype.Timestamptz{Time: time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC), Valid: true}
+	order := models.TransferOrder{ID: 3, SourceWarehouseID: 1, DestinationWarehouseID: 2, Reference: "TO-1", Status: transferStatusOpen, CreatedAt: now, UpdatedAt: now}
+	shipped := order
+	shipped.Status, shipped.ShippedAt = transferStatusInTransit, now
+	lines := []models.TransferOrderLine{
+		{ID: 4, TransferOrderID: 3, Sku: "SKU-1", Quantity: 5},
+		{ID: 5, TransferOrderID: 3, Sku: "SKU-2", Quantity: 2, SourceStorageRoomID: pgtype.Int4{Int32: 7, Valid: true}, ShippedQuantity: 2},
+	}
+	transfers := []any{
+		transferEvent{Transfer: newTransferOrderResponse(order)},
+		transferEvent{Transfer: newTransferOrderResponse(shipped), Lines: mapSlice(lines, newTransferOrderLineResponse)},
+	}
+
+	return map[string][]any{
+		outbox.TopicWarehouseCreated:       warehouses,
+		outbox.TopicWarehouseUpdated:       warehouses,
+		outbox.TopicWarehouseDeleted:       {warehouseDeleted{ID: 1}},
+		outbox.TopicWarehouseStatusChanged: warehouses,
+		outbox.TopicTransferCreated:        transfers,
+		outbox.TopicTransferShipped:        transfers,
+		outbox.TopicTransferReceived:       transfers,
+		outbox.TopicTransferCancelled:      transfers,
+	}
+}
+
+// TestEventContracts checks the published payloads against the schemas
+// consumers rely on. A failure means the payload changed: update the
+// schema following the evolution rules of docs/events.md.
+func TestEventContracts(t *testing.T) {
+	schemas, err := outbox.Schemas()
+	if err != nil {
+		t.Fatal(err)
+	}
+	contracts := eventContracts()
+	for _, schema := range schemas {
+		payloads, ok := contracts[schema.Topic]
+		if !ok {
+			t.Errorf("no contract payloads for %s", schema.Topic)
+			continue
+		}
+		for _, payload := range payloads {
+			data, err := json.Marshal(payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := schema.Validate(data); err != nil {
+				t.Errorf("%s does not match %s: %v\n%s", schema.Topic, schema.File, err, data)
+			}
+		}
+	}
+	for topic := range contracts {
+		if _, err := outbox.SchemaOf(topic); err != nil {
+			t.Error(err)
+		}
+	}
+
+	// The schemas do constrain the payloads
+	schema, err := outbox.SchemaOf(outbox.TopicWarehouseUpdated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := schema.Validate([]byte(`{"id": "1", "name": "Main"}`)); err == nil {
+		t.Error("a warehouse without most fields and a string id passed")
+	}
+}
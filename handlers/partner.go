@@ -0,0 +1,410 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+	"warehouse-service/sftp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// partnerKind is a kind of partner of the directory: suppliers deliver
+// receipts, carriers take pick lists away
+type partnerKind struct {
+	// Value of partner.kind and the entity of metrics and spans
+	name  string
+	title string
+}
+
+var (
+	supplierKind = partnerKind{name: observability.EntitySupplier, title: "Supplier"}
+	carrierKind  = partnerKind{name: observability.EntityCarrier, title: "Carrier"}
+)
+
+type partnerRequest struct {
+	Name        string `json:"name" binding:"required"`
+	ContactName string `json:"contact_name"`
+	Email       string `json:"email" binding:"omitempty,email"`
+	Phone       string `json:"phone"`
+	// Days from order to delivery, for reorder planning
+	LeadTimeDays int32 `json:"lead_time_days" binding:"gte=0"`
+	// ISA ID of the partner's EDI documents, qualifier ZZ when left out
+	EdiQualifier string `json:"edi_qualifier" binding:"omitempty,len=2,alphanum"`
+	EdiID        string `json:"edi_id" binding:"omitempty,max=15"`
+	// sftp://user@host/directory drop that inventory advice is uploaded
+	// to, with the host key its server presents
+	SftpURL     string `json:"sftp_url"`
+	SftpHostKey string `json:"sftp_host_key"`
+}
+
+func parsePartnerID(ctx *gin.Context, kind partnerKind) (int64, bool) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "Invalid "+kind.name+" ID format"),
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+func bindPartnerRequest(ctx *gin.Context, kind partnerKind) (partnerRequest, bool) {
+	var req partnerRequest
+	err := ctx.ShouldBindJSON(&req)
+	if err == nil && strings.TrimSpace(req.Name) == "" {
+		err = errors.New("name is required")
+	}
+	if err == nil {
+		err = req.validateEdi()
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   tr(ctx, "Invalid "+kind.name+" payload"),
+			"details": err.Error(),
+		})
+		return req, false
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	return req, true
+}
+
+// validateEdi normalizes the EDI settings of a partner. A drop needs the
+// EDI ID documents are addressed to and the host key of its server.
+func (req *partnerRequest) validateEdi() error {
+	req.EdiQualifier = strings.ToUpper(strings.TrimSpace(req.EdiQualifier))
+	if req.EdiQualifier == "" {
+		req.EdiQualifier = ediQualifierMutual
+	}
+	req.EdiID = strings.TrimSpace(req.EdiID)
+	req.SftpURL = strings.TrimSpace(req.SftpURL)
+	req.SftpHostKey = strings.TrimSpace(req.SftpHostKey)
+	if req.SftpURL == "" {
+		if req.SftpHostKey != "" {
+			return errors.New("sftp_host_key is only used with sftp_url")
+		}
+		return nil
+	}
+	if req.EdiID == "" {
+		return errors.New("edi_id is required with sftp_url")
+	}
+	if _, err := sftp.ParseURL(req.SftpURL); err != nil {
+		return fmt.Errorf("sftp_url: %w", err)
+	}
+	if _, err := sftp.ParseHostKey(req.SftpHostKey); err != nil {
+		return errors.New("sftp_host_key must be the server's host key in authorized_keys format")
+	}
+	return nil
+}
+
+// partnerParam checks an optional partner reference of a request, e.g. the
+// supplier of a receipt. A partner of another kind or organization is
+// pgx.ErrNoRows.
+func (h *Handlers) partnerParam(ctx context.Context, qtx *models.Queries, orgID string, kind partnerKind, id *int64) (pgtype.Int8, error) {
+	if id == nil {
+		return pgtype.Int8{}, nil
+	}
+	dbStart := time.Now()
+	_, err := qtx.GetPartner(ctx, models.GetPartnerParams{
+		ID:    *id,
+		OrgID: orgID,
+		Kind:  kind.name,
+	})
+	h.recordDBOperation(ctx, "get", "partner", dbStart, err)
+	if err != nil {
+		return pgtype.Int8{}, err
+	}
+	return pgtype.Int8{Int64: *id, Valid: true}, nil
+}
+
+func (h *Handlers) CreateSupplier(ctx *gin.Context) { h.createPartner(ctx, supplierKind) }
+func (h *Handlers) GetSupplier(ctx *gin.Context)    { h.getPartner(ctx, supplierKind) }
+func (h *Handlers) ListSuppliers(ctx *gin.Context)  { h.listPartners(ctx, supplierKind) }
+func (h *Handlers) UpdateSupplier(ctx *gin.Context) { h.updatePartner(ctx, supplierKind) }
+func (h *Handlers) DeleteSupplier(ctx *gin.Context) { h.deletePartner(ctx, supplierKind) }
+
+func (h *Handlers) CreateCarrier(ctx *gin.Context) { h.createPartner(ctx, carrierKind) }
+func (h *Handlers) GetCarrier(ctx *gin.Context)    { h.getPartner(ctx, carrierKind) }
+func (h *Handlers) ListCarriers(ctx *gin.Context)  { h.listPartners(ctx, carrierKind) }
+func (h *Handlers) UpdateCarrier(ctx *gin.Context) { h.updatePartner(ctx, carrierKind) }
+func (h *Handlers) DeleteCarrier(ctx *gin.Context) { h.deletePartner(ctx, carrierKind) }
+
+func (h *Handlers) createPartner(ctx *gin.Context, kind partnerKind) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "Create"+kind.title)
+	defer span.End()
+
+	req, ok := bindPartnerRequest(ctx, kind)
+	if !ok {
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.String(kind.name+".name", req.Name),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	partner, err := h.queries.CreatePartner(spanCtx, models.CreatePartnerParams{
+		OrgID:        orgID,
+		Kind:         kind.name,
+		Name:         req.Name,
+		ContactName:  req.ContactName,
+		Email:        req.Email,
+		Phone:        req.Phone,
+		LeadTimeDays: req.LeadTimeDays,
+		EdiQualifier: req.EdiQualifier,
+		EdiID:        req.EdiID,
+		SftpUrl:      req.SftpURL,
+		SftpHostKey:  req.SftpHostKey,
+	})
+	h.recordDBOperation(spanCtx, "create", "partner", dbStart, err)
+	if conflict, ok := conflictFor(err); ok {
+		h.recordOperation(orgID, kind.name, "create", opStart, err)
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": tr(ctx, conflict.message),
+			"field": conflict.field,
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Could not create "+kind.name+": ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		h.recordOperation(orgID, kind.name, "create", opStart, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to create "+kind.name),
+		})
+		return
+	}
+
+	h.recordOperation(orgID, kind.name, "create", opStart, nil)
+
+	span.SetAttributes(
+		attribute.Int64(kind.name+".id", partner.ID),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusCreated, gin.H{
+		"message": tr(ctx, "Create "+kind.title+" Successfully"),
+		"data":    newPartnerResponse(partner),
+	})
+}
+
+func (h *Handlers) getPartner(ctx *gin.Context, kind partnerKind) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "Get"+kind.title)
+	defer span.End()
+
+	id, ok := parsePartnerID(ctx, kind)
+	if !ok {
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.Int64(kind.name+".id", id),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	partner, err := h.queries.GetPartner(spanCtx, models.GetPartnerParams{
+		ID:    id,
+		OrgID: orgID,
+		Kind:  kind.name,
+	})
+	h.recordDBOperation(spanCtx, "get", "partner", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, kind.title+" not found"),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Got an error while getting "+kind.name+": ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to get "+kind.name),
+		})
+		return
+	}
+
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Get "+kind.title+" Successfully"),
+		"data":    newPartnerResponse(partner),
+	})
+}
+
+func (h *Handlers) listPartners(ctx *gin.Context, kind partnerKind) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "List"+kind.title+"s")
+	defer span.End()
+
+	limit, offset, err := pageParams(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.Int(kind.name+".limit", int(limit)),
+		attribute.Int(kind.name+".offset", int(offset)),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	partners, err := h.queries.ListPartners(spanCtx, models.ListPartnersParams{
+		OrgID:  orgID,
+		Kind:   kind.name,
+		Limit:  limit,
+		Offset: offset,
+	})
+	h.recordDBOperation(spanCtx, "list", "partner", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while listing "+kind.name+"s: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to list "+kind.name+"s"),
+		})
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int(kind.name+".count", len(partners)),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "List "+kind.title+"s Successfully"),
+		"data":    mapSlice(partners, newPartnerResponse),
+	})
+}
+
+// updatePartner replaces the details of a supplier or carrier
+func (h *Handlers) updatePartner(ctx *gin.Context, kind partnerKind) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "Update"+kind.title)
+	defer span.End()
+
+	id, ok := parsePartnerID(ctx, kind)
+	if !ok {
+		return
+	}
+	req, ok := bindPartnerRequest(ctx, kind)
+	if !ok {
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.Int64(kind.name+".id", id),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	partner, err := h.queries.UpdatePartner(spanCtx, models.UpdatePartnerParams{
+		ID:           id,
+		OrgID:        orgID,
+		Kind:         kind.name,
+		Name:         req.Name,
+		ContactName:  req.ContactName,
+		Email:        req.Email,
+		Phone:        req.Phone,
+		LeadTimeDays: req.LeadTimeDays,
+		EdiQualifier: req.EdiQualifier,
+		EdiID:        req.EdiID,
+		SftpUrl:      req.SftpURL,
+		SftpHostKey:  req.SftpHostKey,
+	})
+	h.recordDBOperation(spanCtx, "update", "partner", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, kind.title+" not found"),
+		})
+		return
+	}
+	if conflict, ok := conflictFor(err); ok {
+		h.recordOperation(orgID, kind.name, "update", opStart, err)
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": tr(ctx, conflict.message),
+			"field": conflict.field,
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Could not update "+kind.name+": ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		h.recordOperation(orgID, kind.name, "update", opStart, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to update "+kind.name),
+		})
+		return
+	}
+
+	h.recordOperation(orgID, kind.name, "update", opStart, nil)
+
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Update "+kind.title+" Successfully"),
+		"data":    newPartnerResponse(partner),
+	})
+}
+
+// deletePartner removes a supplier or carrier from the directory. Receipts
+// and pick lists that referenced it keep their other details.
+func (h *Handlers) deletePartner(ctx *gin.Context, kind partnerKind) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "Delete"+kind.title)
+	defer span.End()
+
+	id, ok := parsePartnerID(ctx, kind)
+	if !ok {
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.Int64(kind.name+".id", id),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	rows, err := h.queries.DeletePartner(spanCtx, models.DeletePartnerParams{
+		ID:    id,
+		OrgID: orgID,
+		Kind:  kind.name,
+	})
+	h.recordDBOperation(spanCtx, "delete", "partner", dbStart, err)
+	if err != nil {
+		slog.Error("Could not delete "+kind.name+": ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		h.recordOperation(orgID, kind.name, "delete", opStart, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to delete "+kind.name),
+		})
+		return
+	}
+	if rows == 0 {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, kind.title+" not found"),
+		})
+		return
+	}
+
+	h.recordOperation(orgID, kind.name, "delete", opStart, nil)
+
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": tr(ctx, "Delete "+kind.title+" Successfully"),
+	})
+}
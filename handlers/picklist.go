@@ -0,0 +1,718 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Allocation strategies for pick lists
+const (
+	pickStrategyFIFO = "fifo" // oldest received stock first
+	pickStrategyFEFO = "fefo" // earliest expiring stock first
+)
+
+// Pick list lifecycle states
+const (
+	pickListStatusAllocated = "allocated"
+	pickListStatusPicked    = "picked"
+	pickListStatusShipped   = "shipped"
+	pickListStatusCancelled = "cancelled"
+)
+
+const auditEntityPickList = "pick_list"
+
+type pickItemRequest struct {
+	Sku      string `json:"sku" binding:"required"`
+	Quantity int32  `json:"quantity" binding:"gt=0"`
+	// Unit of the quantity, a registered unit of the item; base units when empty
+	Unit string `json:"unit"`
+}
+
+type createPickListRequest struct {
+	WarehouseID WarehouseRef `json:"warehouse_id" binding:"required"`
+	// Carrier collecting the shipment, a carrier of the directory
+	CarrierID *int64            `json:"carrier_id"`
+	Reference string            `json:"reference"`
+	Strategy  string            `json:"strategy" binding:"omitempty,oneof=fifo fefo"`
+	Items     []pickItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+type confirmPickLineRequest struct {
+	LineID         int64 `json:"line_id" binding:"required"`
+	PickedQuantity int32 `json:"picked_quantity" binding:"gte=0"`
+}
+
+type confirmPickRequest struct {
+	Lines []confirmPickLineRequest `json:"lines" binding:"required,min=1,dive"`
+	// Complete finishes picking even if some lines were short picked
+	Complete bool `json:"complete"`
+}
+
+// pickShortage reports a requested item that could not be fully allocated
+type pickShortage struct {
+	Sku       string `json:"sku"`
+	Requested int32  `json:"requested"`
+	Allocated int32  `json:"allocated"`
+}
+
+func parsePickListID(ctx *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "Invalid pick list ID format"),
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// allocatableStock returns the stock rows holding sku in the warehouse in the
+// order the strategy consumes them. Rows are locked until the transaction ends.
+func (h *Handlers) allocatableStock(ctx context.Context, qtx *models.Queries, orgID string, warehouseID int64, sku, strategy string) ([]models.StockLevel, error) {
+	var stock []models.StockLevel
+	var err error
+	dbStart := time.Now()
+	if strategy == pickStrategyFEFO {
+		stock, err = qtx.ListStockForAllocationFEFO(ctx, models.ListStockForAllocationFEFOParams{
+			OrgID:       orgID,
+			WarehouseID: int32(warehouseID),
+			Sku:         sku,
+		})
+	} else {
+		stock, err = qtx.ListStockForAllocationFIFO(ctx, models.ListStockForAllocationFIFOParams{
+			OrgID:       orgID,
+			WarehouseID: int32(warehouseID),
+			Sku:         sku,
+		})
+	}
+	h.recordDBOperation(ctx, "list", "stock_level", dbStart, err)
+	return stock, err
+}
+
+// releasePickListAllocations returns the stock reserved by every line of the pick list
+func (h *Handlers) releasePickListAllocations(ctx context.Context, qtx *models.Queries, orgID string, lines []models.PickListLine) error {
+	for _, line := range lines {
+		dbStart := time.Now()
+		_, err := qtx.ReleaseStockAllocation(ctx, models.ReleaseStockAllocationParams{
+			OrgID:             orgID,
+			StorageRoomID:     line.StorageRoomID,
+			Sku:               line.Sku,
+			AllocatedQuantity: line.Quantity,
+		})
+		h.recordDBOperation(ctx, "update", "stock_level", dbStart, err)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// allocatePickList creates a pick list of the warehouse and allocates the
+// items from its storage rooms with the strategy, reserving the stock.
+// Items that can't be allocated in full are returned as shortages, the
+// caller rolls the transaction back then.
+func (h *Handlers) allocatePickList(ctx context.Context, qtx *models.Queries, orgID string, warehouseID int64, reference, strategy string, carrierID pgtype.Int8, items []pickItemRequest) (models.PickList, []models.PickListLine, []pickShortage, error) {
+	dbStart := time.Now()
+	pickList, err := qtx.CreatePickList(ctx, models.CreatePickListParams{
+		OrgID:       orgID,
+		WarehouseID: warehouseID,
+		Reference:   reference,
+		Strategy:    strategy,
+		CarrierID:   carrierID,
+	})
+	h.recordDBOperation(ctx, "create", "pick_list", dbStart, err)
+	if err != nil {
+		return pickList, nil, nil, err
+	}
+
+	lines := []models.PickListLine{}
+	shortages := []pickShortage{}
+	units := h.unitConverter(qtx, orgID)
+	for _, item := range items {
+		quantity, err := units.toBase(ctx, item.Sku, item.Unit, item.Quantity)
+		if err != nil {
+			return pickList, nil, nil, err
+		}
+
+		stock, err := h.allocatableStock(ctx, qtx, orgID, warehouseID, item.Sku, strategy)
+		if err != nil {
+			return pickList, nil, nil, err
+		}
+
+		remaining := quantity
+		for _, level := range stock {
+			if remaining == 0 {
+				break
+			}
+			take := min(remaining, level.Quantity-level.AllocatedQuantity)
+
+			dbStart = time.Now()
+			_, err := qtx.AllocateStockLevel(ctx, models.AllocateStockLevelParams{
+				ID:                level.ID,
+				AllocatedQuantity: take,
+			})
+			h.recordDBOperation(ctx, "update", "stock_level", dbStart, err)
+			if err != nil {
+				return pickList, nil, nil, err
+			}
+			dbStart = time.Now()
+			line, err := qtx.CreatePickListLine(ctx, models.CreatePickListLineParams{
+				PickListID:    pickList.ID,
+				Sku:           item.Sku,
+				StorageRoomID: level.StorageRoomID,
+				Quantity:      take,
+			})
+			h.recordDBOperation(ctx, "create", "pick_list_line", dbStart, err)
+			if err != nil {
+				return pickList, nil, nil, err
+			}
+			lines = append(lines, line)
+			remaining -= take
+		}
+
+		if remaining > 0 {
+			shortages = append(shortages, pickShortage{
+				Sku:       item.Sku,
+				Requested: quantity,
+				Allocated: quantity - remaining,
+			})
+		}
+	}
+	if err := h.startFulfillmentSaga(ctx, qtx, pickList); err != nil {
+		return pickList, nil, nil, err
+	}
+	return pickList, lines, shortages, nil
+}
+
+// CreatePickList allocates the requested items from storage rooms of the
+// warehouse using the FIFO or FEFO strategy and reserves the allocated stock.
+func (h *Handlers) CreatePickList(ctx *gin.Context) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "CreatePickList")
+	defer span.End()
+
+	var req createPickListRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   tr(ctx, "Invalid pick list payload"),
+			"details": err.Error(),
+		})
+		return
+	}
+	if req.Strategy == "" {
+		req.Strategy = pickStrategyFIFO
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.String("warehouse.ref", string(req.WarehouseID)),
+		attribute.String("pick_list.strategy", req.Strategy),
+		attribute.Int("pick_list.items", len(req.Items)),
+		attribute.String("tenant.id", orgID),
+	)
+
+	tx, err := h.db.Begin(spanCtx)
+	if err != nil {
+		slog.Error("Failed to start transaction", slog.Any("err", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": tr(ctx, "Failed to start transaction"),
+		})
+		return
+	}
+	defer tx.Rollback(spanCtx) // This will be ignored if tx.Commit() succeeds
+
+	qtx := h.queries.WithTx(tx)
+
+	warehouseID, err := h.resolveWarehouse(spanCtx, qtx, orgID, req.WarehouseID)
+	if err == nil {
+		dbStart := time.Now()
+		_, err = qtx.GetWarehouse(spanCtx, models.GetWarehouseParams{
+			ID:    warehouseID,
+			OrgID: orgID,
+		})
+		h.recordDBOperation(spanCtx, "get", "warehouse", dbStart, err)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Warehouse not found"),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Got an error while getting warehouse: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to create pick list"),
+		})
+		return
+	}
+	span.SetAttributes(attribute.Int64("warehouse.id", warehouseID))
+
+	carrierID, err := h.partnerParam(spanCtx, qtx, orgID, carrierKind, req.CarrierID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Carrier not found"),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Got an error while getting carrier: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to create pick list"),
+		})
+		return
+	}
+
+	pickList, lines, shortages, err := h.allocatePickList(spanCtx, qtx, orgID, warehouseID, req.Reference, req.Strategy, carrierID, req.Items)
+	if rejected, ok := unitRejected(err); ok {
+		respondUnitError(ctx, rejected)
+		return
+	}
+	if err != nil {
+		slog.Error("Could not allocate pick list: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to create pick list"),
+		})
+		return
+	}
+
+	if len(shortages) > 0 {
+		span.SetAttributes(
+			attribute.Int("pick_list.shortages", len(shortages)),
+			attribute.String("operation.status", "insufficient_stock"),
+		)
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error":     tr(ctx, "Insufficient stock to allocate pick list"),
+			"shortages": shortages,
+		})
+		return
+	}
+
+	if err := h.recordAudit(spanCtx, qtx, auditEntry{
+		OrgID:      orgID,
+		EntityType: auditEntityPickList,
+		EntityID:   pickList.ID,
+		Action:     "create",
+		ToStatus:   pickList.Status,
+		Actor:      actorID(ctx),
+	}); err != nil {
+		slog.Error("Could not record audit log: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to create pick list"),
+		})
+		return
+	}
+
+	if err := tx.Commit(spanCtx); err != nil {
+		slog.Error("Failed to commit transaction", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to commit transaction"),
+		})
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntityPickList, "create", opStart, nil)
+
+	span.SetAttributes(
+		attribute.Int64("pick_list.id", pickList.ID),
+		attribute.Int("pick_list.lines", len(lines)),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusCreated, gin.H{
+		"message": tr(ctx, "Create Pick List Successfully"),
+		"data": gin.H{
+			"pick_list": newPickListResponse(pickList),
+			"lines":     mapSlice(lines, newPickListLineResponse),
+		},
+	})
+}
+
+func (h *Handlers) GetPickList(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "GetPickList")
+	defer span.End()
+
+	id, ok := parsePickListID(ctx)
+	if !ok {
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.Int64("pick_list.id", id),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	pickList, err := h.queries.GetPickList(spanCtx, models.GetPickListParams{
+		ID:    id,
+		OrgID: orgID,
+	})
+	h.recordDBOperation(spanCtx, "get", "pick_list", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Pick list not found"),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Got an error while getting pick list: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to get pick list"),
+		})
+		return
+	}
+
+	dbStart = time.Now()
+	lines, err := h.queries.ListPickListLines(spanCtx, pickList.ID)
+	h.recordDBOperation(spanCtx, "list", "pick_list_line", dbStart, err)
+	if err == nil {
+		var history []models.AuditLog
+		dbStart = time.Now()
+		history, err = h.queries.ListAuditLogsForEntity(spanCtx, models.ListAuditLogsForEntityParams{
+			OrgID:      orgID,
+			EntityType: auditEntityPickList,
+			EntityID:   pickList.ID,
+		})
+		h.recordDBOperation(spanCtx, "list", "audit_log", dbStart, err)
+		if err == nil {
+			span.SetAttributes(attribute.String("operation.status", "success"))
+			respondV1(ctx, http.StatusOK, gin.H{
+				"message": tr(ctx, "Get Pick List Successfully"),
+				"data": gin.H{
+					"pick_list": newPickListResponse(pickList),
+					"lines":     mapSlice(lines, newPickListLineResponse),
+					"history":   mapSlice(history, newAuditLogResponse),
+				},
+			})
+			return
+		}
+	}
+
+	slog.Error("Got an error while getting pick list details: ", slog.Any("err", err.Error()))
+	span.RecordError(err)
+	ctx.JSON(dbErrorStatus(err), gin.H{
+		"error": tr(ctx, "Failed to get pick list"),
+	})
+}
+
+func (h *Handlers) ListPickLists(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "ListPickLists")
+	defer span.End()
+
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.Int("pick_list.limit", 10),
+		attribute.Int("pick_list.offset", 0),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	pickLists, err := h.queries.ListPickLists(spanCtx, models.ListPickListsParams{
+		OrgID:  orgID,
+		Limit:  10,
+		Offset: 0,
+	})
+	h.recordDBOperation(spanCtx, "list", "pick_list", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while listing pick lists: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to list pick lists"),
+		})
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int("pick_list.count", len(pickLists)),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "List Pick Lists Successfully"),
+		"data":    mapSlice(pickLists, newPickListResponse),
+	})
+}
+
+// pickListTransition runs apply inside a transaction holding a lock on the
+// pick list, then moves the pick list to the returned status and audits the
+// transition. apply returns an HTTP status and message to reject the request.
+func (h *Handlers) pickListTransition(
+	ctx *gin.Context,
+	operation string,
+	allowed []string,
+	apply func(spanCtx context.Context, qtx *models.Queries, pickList models.PickList, lines []models.PickListLine) (string, int, error),
+) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), operation)
+	defer span.End()
+
+	id, ok := parsePickListID(ctx)
+	if !ok {
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.Int64("pick_list.id", id),
+		attribute.String("tenant.id", orgID),
+	)
+
+	tx, err := h.db.Begin(spanCtx)
+	if err != nil {
+		slog.Error("Failed to start transaction", slog.Any("err", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": tr(ctx, "Failed to start transaction"),
+		})
+		return
+	}
+	defer tx.Rollback(spanCtx) // This will be ignored if tx.Commit() succeeds
+
+	qtx := h.queries.WithTx(tx)
+
+	dbStart := time.Now()
+	pickList, err := qtx.GetPickListForUpdate(spanCtx, models.GetPickListForUpdateParams{
+		ID:    id,
+		OrgID: orgID,
+	})
+	h.recordDBOperation(spanCtx, "get", "pick_list", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Pick list not found"),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Got an error while getting pick list: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to update pick list"),
+		})
+		return
+	}
+
+	permitted := false
+	for _, status := range allowed {
+		if pickList.Status == status {
+			permitted = true
+		}
+	}
+	if !permitted {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": tr(ctx, "Pick list is %s", pickList.Status),
+		})
+		return
+	}
+
+	dbStart = time.Now()
+	lines, err := qtx.ListPickListLines(spanCtx, pickList.ID)
+	h.recordDBOperation(spanCtx, "list", "pick_list_line", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while listing pick list lines: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to update pick list"),
+		})
+		return
+	}
+
+	status, code, err := apply(spanCtx, qtx, pickList, lines)
+	if code != 0 {
+		ctx.JSON(code, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Could not update pick list: ", slog.String("operation", operation), slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to update pick list"),
+		})
+		return
+	}
+
+	fromStatus := pickList.Status
+	if status != fromStatus {
+		dbStart = time.Now()
+		pickList, err = qtx.UpdatePickListStatus(spanCtx, models.UpdatePickListStatusParams{
+			ID:     pickList.ID,
+			OrgID:  orgID,
+			Status: status,
+		})
+		h.recordDBOperation(spanCtx, "update", "pick_list", dbStart, err)
+		if err == nil {
+			err = h.recordAudit(spanCtx, qtx, auditEntry{
+				OrgID:      orgID,
+				EntityType: auditEntityPickList,
+				EntityID:   pickList.ID,
+				Action:     operation,
+				FromStatus: fromStatus,
+				ToStatus:   status,
+				Actor:      actorID(ctx),
+			})
+		}
+		if err != nil {
+			slog.Error("Could not update pick list status: ", slog.Any("err", err.Error()))
+			span.RecordError(err)
+			ctx.JSON(dbErrorStatus(err), gin.H{
+				"error": tr(ctx, "Failed to update pick list"),
+			})
+			return
+		}
+	}
+
+	dbStart = time.Now()
+	lines, err = qtx.ListPickListLines(spanCtx, pickList.ID)
+	h.recordDBOperation(spanCtx, "list", "pick_list_line", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while listing pick list lines: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to update pick list"),
+		})
+		return
+	}
+
+	if err := tx.Commit(spanCtx); err != nil {
+		slog.Error("Failed to commit transaction", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to commit transaction"),
+		})
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntityPickList, operation, opStart, nil)
+
+	span.SetAttributes(
+		attribute.String("pick_list.from_status", fromStatus),
+		attribute.String("pick_list.status", pickList.Status),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Update Pick List Successfully"),
+		"data": gin.H{
+			"pick_list": newPickListResponse(pickList),
+			"lines":     mapSlice(lines, newPickListLineResponse),
+		},
+	})
+}
+
+// ConfirmPick records picked quantities. The pick list moves to picked once
+// every line is picked in full, or when the request marks picking complete.
+func (h *Handlers) ConfirmPick(ctx *gin.Context) {
+	var req confirmPickRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   tr(ctx, "Invalid pick confirmation payload"),
+			"details": err.Error(),
+		})
+		return
+	}
+
+	h.pickListTransition(ctx, "pick", []string{pickListStatusAllocated},
+		func(spanCtx context.Context, qtx *models.Queries, pickList models.PickList, lines []models.PickListLine) (string, int, error) {
+			byID := make(map[int64]models.PickListLine, len(lines))
+			for _, line := range lines {
+				byID[line.ID] = line
+			}
+
+			for _, l := range req.Lines {
+				line, ok := byID[l.LineID]
+				if !ok {
+					return "", http.StatusBadRequest, fmt.Errorf("line %d does not belong to this pick list", l.LineID)
+				}
+				if l.PickedQuantity > line.Quantity {
+					return "", http.StatusBadRequest, fmt.Errorf("line %d cannot pick more than the allocated %d", l.LineID, line.Quantity)
+				}
+
+				dbStart := time.Now()
+				line, err := qtx.ConfirmPickListLine(spanCtx, models.ConfirmPickListLineParams{
+					ID:             line.ID,
+					PickListID:     pickList.ID,
+					PickedQuantity: l.PickedQuantity,
+				})
+				h.recordDBOperation(spanCtx, "update", "pick_list_line", dbStart, err)
+				if err != nil {
+					return "", 0, err
+				}
+				byID[line.ID] = line
+			}
+
+			complete := true
+			for _, line := range byID {
+				if line.PickedQuantity < line.Quantity {
+					complete = false
+				}
+			}
+			if complete || req.Complete {
+				return pickListStatusPicked, 0, nil
+			}
+			return pickList.Status, 0, nil
+		})
+}
+
+// ShipPickList confirms the shipment: picked quantities leave stock and any
+// allocation left over from short picks is released.
+func (h *Handlers) ShipPickList(ctx *gin.Context) {
+	orgID := tenantID(ctx)
+	h.pickListTransition(ctx, "ship", []string{pickListStatusPicked},
+		func(spanCtx context.Context, qtx *models.Queries, pickList models.PickList, lines []models.PickListLine) (string, int, error) {
+			if err := h.releasePickListAllocations(spanCtx, qtx, orgID, lines); err != nil {
+				return "", 0, err
+			}
+
+			reference := fmt.Sprintf("pick_list:%d", pickList.ID)
+			for _, line := range lines {
+				if line.PickedQuantity == 0 {
+					continue
+				}
+				if _, err := h.adjustStock(spanCtx, qtx, stockAdjustment{
+					OrgID:         orgID,
+					StorageRoomID: line.StorageRoomID,
+					Sku:           line.Sku,
+					Delta:         -line.PickedQuantity,
+					Reason:        adjustmentReasonShipment,
+					Reference:     reference,
+				}); err != nil {
+					return "", 0, err
+				}
+			}
+			if err := h.advancePickListSaga(spanCtx, qtx, pickList); err != nil {
+				return "", 0, err
+			}
+			return pickListStatusShipped, 0, nil
+		})
+}
+
+// CancelPickList releases all stock reserved by a pick list that has not shipped
+func (h *Handlers) CancelPickList(ctx *gin.Context) {
+	orgID := tenantID(ctx)
+	h.pickListTransition(ctx, "cancel", []string{pickListStatusAllocated, pickListStatusPicked},
+		func(spanCtx context.Context, qtx *models.Queries, pickList models.PickList, lines []models.PickListLine) (string, int, error) {
+			if err := h.releasePickListAllocations(spanCtx, qtx, orgID, lines); err != nil {
+				return "", 0, err
+			}
+			if err := h.advancePickListSaga(spanCtx, qtx, pickList); err != nil {
+				return "", 0, err
+			}
+			return pickListStatusCancelled, 0, nil
+		})
+}
@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+	"warehouse-service/geocode"
+	"warehouse-service/jobs"
+	"warehouse-service/labels"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+	"warehouse-service/printing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// JobKindPrintLabel is the background job sending a label to a printer
+const JobKindPrintLabel = "print_label"
+
+// Labels that can be printed, by what they identify
+const (
+	printLabelItem     = "item"
+	printLabelLocation = "location"
+	printLabelShipment = "shipment"
+)
+
+type printRequest struct {
+	PrinterID int64  `json:"printer_id" binding:"required"`
+	Label     string `json:"label" binding:"required,oneof=item location shipment"`
+	// ID of the item, storage room or pick list
+	ID        int64  `json:"id" binding:"required"`
+	Symbology string `json:"symbology" binding:"omitempty,oneof=qr code128"`
+	Copies    int    `json:"copies" binding:"omitempty,min=1,max=100"`
+}
+
+// printJob is the payload of a print_label job. The label is rendered when
+// the job is queued, so it shows the entity as it was then.
+type printJob struct {
+	PrinterID int64  `json:"printer_id"`
+	Label     string `json:"label"`
+	ID        int64  `json:"id"`
+	ZPL       string `json:"zpl"`
+}
+
+// printLabelContent looks up what a label shows and the symbology it is
+// printed with by default: QR codes on locations like bin labels, Code 128
+// on items and shipments, which handhelds and carriers scan as 1D codes
+func (h *Handlers) printLabelContent(ctx context.Context, orgID, label string, id int64) (labels.Label, labels.Symbology, error) {
+	dbStart := time.Now()
+	switch label {
+	case printLabelItem:
+		item, err := h.queries.GetItem(ctx, models.GetItemParams{ID: id, OrgID: orgID})
+		h.recordDBOperation(ctx, "get", "item", dbStart, err)
+		return labels.Label{
+			Code:  item.Sku,
+			Lines: []string{item.Sku, item.Description},
+		}, labels.SymbologyCode128, err
+	case printLabelLocation:
+		if id > math.MaxInt32 {
+			return labels.Label{}, "", pgx.ErrNoRows
+		}
+		room, err := h.queries.GetStorageRoomLabel(ctx, models.GetStorageRoomLabelParams{ID: int32(id), OrgID: orgID})
+		h.recordDBOperation(ctx, "get", "storage_room", dbStart, err)
+		code := locationCode(room.WarehouseID, room.Number)
+		return labels.Label{
+			Code:  code,
+			Lines: []string{code, room.WarehouseName + " / " + room.Name},
+		}, labels.SymbologyQR, err
+	case printLabelShipment:
+		shipment, err := h.queries.GetShipmentLabel(ctx, models.GetShipmentLabelParams{ID: id, OrgID: orgID})
+		h.recordDBOperation(ctx, "get", "pick_list", dbStart, err)
+		code := shipment.Reference
+		if code == "" {
+			code = strconv.FormatInt(shipment.ID, 10)
+		}
+		lines := []string{code, "From: " + shipment.WarehouseName}
+		if from := geocode.Address(shipment.Address, shipment.City, shipment.Country); from != "" {
+			lines = append(lines, from)
+		}
+		if shipment.CarrierName != "" {
+			lines = append(lines, "Carrier: "+shipment.CarrierName)
+		}
+		return labels.Label{Code: code, Lines: lines}, labels.SymbologyCode128, err
+	}
+	return labels.Label{}, "", fmt.Errorf("unknown label %q", label)
+}
+
+// PrintLabel queues a label for a printer of the registry. The label is
+// rendered as ZPL for the printer's resolution right away and sent by a
+// print_label job, which is retried with backoff while the printer can't be
+// reached. The job answered with 202 tracks the status at /v1/jobs/:id.
+func (h *Handlers) PrintLabel(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "PrintLabel")
+	defer span.End()
+
+	var req printRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   tr(ctx, "Invalid print payload"),
+			"details": err.Error(),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.Int64("printer.id", req.PrinterID),
+		attribute.String("label.kind", req.Label),
+		attribute.Int64("label.id", req.ID),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	printer, err := h.queries.GetPrinter(spanCtx, models.GetPrinterParams{
+		ID:    req.PrinterID,
+		OrgID: orgID,
+	})
+	h.recordDBOperation(spanCtx, "get", "printer", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Printer not found"),
+		})
+		return
+	}
+	var label labels.Label
+	var symbology labels.Symbology
+	if err == nil {
+		label, symbology, err = h.printLabelContent(spanCtx, orgID, req.Label, req.ID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error": tr(ctx, "No %s with ID %d", req.Label, req.ID),
+			})
+			return
+		}
+	}
+	if err != nil {
+		slog.Error("Got an error while preparing label: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to print label"),
+		})
+		return
+	}
+
+	if req.Symbology != "" {
+		symbology = labels.Symbology(req.Symbology)
+	}
+	zpl, err := labels.RenderZPL(label, symbology, labels.ZPLOptions{
+		DPI:    int(printer.Dpi),
+		Copies: req.Copies,
+	})
+	if err != nil {
+		// Code 128 can't encode every character a SKU or reference may hold
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	dbStart = time.Now()
+	job, err := jobs.Enqueue(spanCtx, h.queries, orgID, JobKindPrintLabel, printJob{
+		PrinterID: printer.ID,
+		Label:     req.Label,
+		ID:        req.ID,
+		ZPL:       string(zpl),
+	}, jobs.EnqueueOptions{})
+	h.recordDBOperation(spanCtx, "create", "job", dbStart, err)
+	if err != nil {
+		slog.Error("Could not queue print job: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to print label"),
+		})
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int64("job.id", job.ID),
+		attribute.String("operation.status", "success"),
+	)
+	ctx.Header("Location", fmt.Sprintf("/v1/jobs/%d", job.ID))
+	respondV1(ctx, http.StatusAccepted, gin.H{
+		"message": tr(ctx, "Print Label Successfully"),
+		"data":    newJobResponse(job),
+	})
+}
+
+// RunPrintJob sends the label of a print_label job to its printer
+func (h *Handlers) RunPrintJob(ctx context.Context, job models.Job) error {
+	opStart := time.Now()
+	spanCtx, span := h.tracer.Start(ctx, "RunPrintJob")
+	defer span.End()
+
+	var payload printJob
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return fmt.Errorf("decode print job: %w", err)
+	}
+	span.SetAttributes(
+		attribute.Int64("printer.id", payload.PrinterID),
+		attribute.String("tenant.id", job.OrgID),
+	)
+
+	dbStart := time.Now()
+	printer, err := h.queries.GetPrinter(spanCtx, models.GetPrinterParams{
+		ID:    payload.PrinterID,
+		OrgID: job.OrgID,
+	})
+	h.recordDBOperation(spanCtx, "get", "printer", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = fmt.Errorf("printer %d was deleted", payload.PrinterID)
+	}
+	if err == nil {
+		err = printing.Send(spanCtx, printer.Address, []byte(payload.ZPL))
+	}
+	h.recordOperation(job.OrgID, observability.EntityLabel, "print", opStart, err)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	slog.Info("Printed label",
+		slog.String("tenant_id", job.OrgID),
+		slog.String("printer", printer.Name),
+		slog.String("label", payload.Label),
+		slog.Int64("id", payload.ID),
+	)
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	return nil
+}
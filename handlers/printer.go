@@ -0,0 +1,297 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+	"warehouse-service/printing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type printerRequest struct {
+	Name string `json:"name" binding:"required"`
+	// host:port of the printer's raw socket, port 9100 when left out
+	Address string `json:"address" binding:"required"`
+	Dpi     int32  `json:"dpi" binding:"omitempty,oneof=203 300 600"`
+}
+
+func parsePrinterID(ctx *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "Invalid printer ID format"),
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+func bindPrinterRequest(ctx *gin.Context) (printerRequest, bool) {
+	var req printerRequest
+	err := ctx.ShouldBindJSON(&req)
+	if err == nil && strings.TrimSpace(req.Name) == "" {
+		err = errors.New("name is required")
+	}
+	if err == nil {
+		req.Address, err = printing.Address(strings.TrimSpace(req.Address))
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   tr(ctx, "Invalid printer payload"),
+			"details": err.Error(),
+		})
+		return req, false
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Dpi == 0 {
+		req.Dpi = 203
+	}
+	return req, true
+}
+
+func (h *Handlers) CreatePrinter(ctx *gin.Context) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "CreatePrinter")
+	defer span.End()
+
+	req, ok := bindPrinterRequest(ctx)
+	if !ok {
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.String("printer.name", req.Name),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	printer, err := h.queries.CreatePrinter(spanCtx, models.CreatePrinterParams{
+		OrgID:   orgID,
+		Name:    req.Name,
+		Address: req.Address,
+		Dpi:     req.Dpi,
+	})
+	h.recordDBOperation(spanCtx, "create", "printer", dbStart, err)
+	if conflict, ok := conflictFor(err); ok {
+		h.recordOperation(orgID, observability.EntityPrinter, "create", opStart, err)
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": tr(ctx, conflict.message),
+			"field": conflict.field,
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Could not create printer: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		h.recordOperation(orgID, observability.EntityPrinter, "create", opStart, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to create printer"),
+		})
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntityPrinter, "create", opStart, nil)
+
+	span.SetAttributes(
+		attribute.Int64("printer.id", printer.ID),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusCreated, gin.H{
+		"message": tr(ctx, "Create Printer Successfully"),
+		"data":    newPrinterResponse(printer),
+	})
+}
+
+func (h *Handlers) GetPrinter(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "GetPrinter")
+	defer span.End()
+
+	id, ok := parsePrinterID(ctx)
+	if !ok {
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.Int64("printer.id", id),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	printer, err := h.queries.GetPrinter(spanCtx, models.GetPrinterParams{
+		ID:    id,
+		OrgID: orgID,
+	})
+	h.recordDBOperation(spanCtx, "get", "printer", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Printer not found"),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Got an error while getting printer: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to get printer"),
+		})
+		return
+	}
+
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Get Printer Successfully"),
+		"data":    newPrinterResponse(printer),
+	})
+}
+
+func (h *Handlers) ListPrinters(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "ListPrinters")
+	defer span.End()
+
+	orgID := tenantID(ctx)
+	span.SetAttributes(attribute.String("tenant.id", orgID))
+
+	dbStart := time.Now()
+	printers, err := h.queries.ListPrinters(spanCtx, orgID)
+	h.recordDBOperation(spanCtx, "list", "printer", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while listing printers: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to list printers"),
+		})
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int("printer.count", len(printers)),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "List Printers Successfully"),
+		"data":    mapSlice(printers, newPrinterResponse),
+	})
+}
+
+// UpdatePrinter replaces the name, address and resolution of a printer.
+// Queued print jobs go to its new address.
+func (h *Handlers) UpdatePrinter(ctx *gin.Context) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "UpdatePrinter")
+	defer span.End()
+
+	id, ok := parsePrinterID(ctx)
+	if !ok {
+		return
+	}
+	req, ok := bindPrinterRequest(ctx)
+	if !ok {
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.Int64("printer.id", id),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	printer, err := h.queries.UpdatePrinter(spanCtx, models.UpdatePrinterParams{
+		ID:      id,
+		OrgID:   orgID,
+		Name:    req.Name,
+		Address: req.Address,
+		Dpi:     req.Dpi,
+	})
+	h.recordDBOperation(spanCtx, "update", "printer", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Printer not found"),
+		})
+		return
+	}
+	if conflict, ok := conflictFor(err); ok {
+		h.recordOperation(orgID, observability.EntityPrinter, "update", opStart, err)
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": tr(ctx, conflict.message),
+			"field": conflict.field,
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Could not update printer: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		h.recordOperation(orgID, observability.EntityPrinter, "update", opStart, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to update printer"),
+		})
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntityPrinter, "update", opStart, nil)
+
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Update Printer Successfully"),
+		"data":    newPrinterResponse(printer),
+	})
+}
+
+// DeletePrinter removes a printer from the registry. Print jobs still
+// queued for it fail.
+func (h *Handlers) DeletePrinter(ctx *gin.Context) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "DeletePrinter")
+	defer span.End()
+
+	id, ok := parsePrinterID(ctx)
+	if !ok {
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.Int64("printer.id", id),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	rows, err := h.queries.DeletePrinter(spanCtx, models.DeletePrinterParams{
+		ID:    id,
+		OrgID: orgID,
+	})
+	h.recordDBOperation(spanCtx, "delete", "printer", dbStart, err)
+	if err != nil {
+		slog.Error("Could not delete printer: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		h.recordOperation(orgID, observability.EntityPrinter, "delete", opStart, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to delete printer"),
+		})
+		return
+	}
+	if rows == 0 {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Printer not found"),
+		})
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntityPrinter, "delete", opStart, nil)
+
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": tr(ctx, "Delete Printer Successfully"),
+	})
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/quota"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const errCodeQuotaExceeded = "quota_exceeded"
+
+// QuotaUsage is the consumption of one quota. Limit is nil when unlimited.
+type QuotaUsage struct {
+	Used  int64  `json:"used" v1:"Used"`
+	Limit *int64 `json:"limit" v1:"Limit"`
+}
+
+// APICallUsage is the consumption of a daily API call quota
+type APICallUsage struct {
+	QuotaUsage
+	ResetsAt time.Time `json:"resets_at" v1:"ResetsAt"`
+}
+
+// StorageRoomUsage reports the warehouse with the most storage rooms, the
+// one closest to the per-warehouse quota
+type StorageRoomUsage struct {
+	QuotaUsage
+	WarehouseID *int32 `json:"warehouse_id" v1:"WarehouseID"`
+}
+
+type UsageResponse struct {
+	Warehouses               QuotaUsage       `json:"warehouses" v1:"Warehouses"`
+	StorageRoomsPerWarehouse StorageRoomUsage `json:"storage_rooms_per_warehouse" v1:"StorageRoomsPerWarehouse"`
+	APICalls                 APICallUsage     `json:"api_calls" v1:"APICalls"`
+	UserAPICalls             APICallUsage     `json:"user_api_calls" v1:"UserAPICalls"`
+}
+
+func newQuotaUsage(used, limit int64) QuotaUsage {
+	usage := QuotaUsage{Used: used}
+	if limit > 0 {
+		usage.Limit = &limit
+	}
+	return usage
+}
+
+// enforceWarehouseQuota fails with a *quota.ExceededError when the tenant
+// has more warehouses than its quota. Call it in the transaction that adds
+// a warehouse, after the insert; it holds the tenant's quota lock until the
+// transaction ends so concurrent creates cannot both pass.
+func (h *Handlers) enforceWarehouseQuota(ctx context.Context, qtx *models.Queries, orgID string) error {
+	if h.quotas.Warehouses == 0 {
+		return nil
+	}
+	if err := qtx.LockQuota(ctx, "warehouses:"+orgID); err != nil {
+		return err
+	}
+	dbStart := time.Now()
+	count, err := qtx.CountWarehousesForTenant(ctx, orgID)
+	h.recordDBOperation(ctx, "count", "warehouse", dbStart, err)
+	if err != nil {
+		return err
+	}
+	return h.checkQuota(quota.ResourceWarehouses, h.quotas.Warehouses, count)
+}
+
+// enforceStorageRoomQuota is enforceWarehouseQuota for the storage rooms of
+// one warehouse
+func (h *Handlers) enforceStorageRoomQuota(ctx context.Context, qtx *models.Queries, orgID string, warehouseID int32) error {
+	if h.quotas.StorageRoomsPerWarehouse == 0 {
+		return nil
+	}
+	if err := qtx.LockQuota(ctx, fmt.Sprintf("storage_rooms:%d", warehouseID)); err != nil {
+		return err
+	}
+	dbStart := time.Now()
+	count, err := qtx.CountStorageRoomsInWarehouse(ctx, models.CountStorageRoomsInWarehouseParams{
+		WarehouseID: warehouseID,
+		OrgID:       orgID,
+	})
+	h.recordDBOperation(ctx, "count", "storage_room", dbStart, err)
+	if err != nil {
+		return err
+	}
+	return h.checkQuota(quota.ResourceStorageRooms, h.quotas.StorageRoomsPerWarehouse, count)
+}
+
+func (h *Handlers) checkQuota(resource string, limit, used int64) error {
+	err := quota.Check(resource, limit, used)
+	if err != nil && h.prometheusMetrics != nil {
+		h.prometheusMetrics.RecordQuotaRejection(resource)
+	}
+	return err
+}
+
+// quotaExceeded reports whether err is a quota error and returns it
+func quotaExceeded(err error) (*quota.ExceededError, bool) {
+	var exceeded *quota.ExceededError
+	ok := errors.As(err, &exceeded)
+	return exceeded, ok
+}
+
+// respondQuotaExceeded answers a request that would go over a resource
+// quota. The quota belongs to the tenant's plan, so the status is 402.
+func respondQuotaExceeded(ctx *gin.Context, exceeded *quota.ExceededError) {
+	ctx.JSON(http.StatusPaymentRequired, gin.H{
+		"error": exceeded.Error(),
+		"quota": exceeded.Resource,
+		"limit": exceeded.Limit,
+	})
+}
+
+func respondQuotaExceededV2(ctx *gin.Context, exceeded *quota.ExceededError) {
+	respondV2Error(ctx, http.StatusPaymentRequired, errCodeQuotaExceeded, exceeded.Error())
+}
+
+// GetUsage reports the tenant's consumption of each quota, and the
+// caller's own API calls today
+func (h *Handlers) GetUsage(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "GetUsage")
+	defer span.End()
+
+	orgID := tenantID(ctx)
+	span.SetAttributes(attribute.String("tenant.id", orgID))
+	day, resetsAt := quota.Day(time.Now())
+
+	dbStart := time.Now()
+	warehouses, err := h.queries.CountWarehousesForTenant(spanCtx, orgID)
+	h.recordDBOperation(spanCtx, "count", "warehouse", dbStart, err)
+	var fullest models.GetFullestWarehouseRow
+	if err == nil {
+		dbStart = time.Now()
+		fullest, err = h.queries.GetFullestWarehouse(spanCtx, orgID)
+		h.recordDBOperation(spanCtx, "count", "storage_room", dbStart, err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = nil
+		}
+	}
+	var calls models.GetAPIUsageRow
+	if err == nil {
+		dbStart = time.Now()
+		calls, err = h.queries.GetAPIUsage(spanCtx, models.GetAPIUsageParams{
+			UserID: actorID(ctx),
+			OrgID:  orgID,
+			Day:    pgtype.Date{Time: day, Valid: true},
+		})
+		h.recordDBOperation(spanCtx, "get", "api_usage", dbStart, err)
+	}
+	if err != nil {
+		slog.Error("Got an error while reading usage: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to get usage"),
+		})
+		return
+	}
+
+	usage := UsageResponse{
+		Warehouses: newQuotaUsage(warehouses, h.quotas.Warehouses),
+		StorageRoomsPerWarehouse: StorageRoomUsage{
+			QuotaUsage: newQuotaUsage(fullest.StorageRooms, h.quotas.StorageRoomsPerWarehouse),
+		},
+		APICalls: APICallUsage{
+			QuotaUsage: newQuotaUsage(calls.TenantCalls, h.quotas.APICallsPerDay),
+			ResetsAt:   resetsAt,
+		},
+		UserAPICalls: APICallUsage{
+			QuotaUsage: newQuotaUsage(calls.UserCalls, h.quotas.APICallsPerUserPerDay),
+			ResetsAt:   resetsAt,
+		},
+	}
+	if fullest.StorageRooms > 0 {
+		usage.StorageRoomsPerWarehouse.WarehouseID = &fullest.WarehouseID
+	}
+
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Get Usage Successfully"),
+		"data":    usage,
+	})
+}
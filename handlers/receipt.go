@@ -0,0 +1,723 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Receipt lifecycle states
+const (
+	receiptStatusOpen                    = "open"
+	receiptStatusPartiallyReceived       = "partially_received"
+	receiptStatusReceived                = "received"
+	receiptStatusClosed                  = "closed"
+	receiptStatusClosedWithDiscrepancies = "closed_with_discrepancies"
+)
+
+type receiptLineRequest struct {
+	Sku              string `json:"sku" binding:"required"`
+	ExpectedQuantity int32  `json:"expected_quantity" binding:"gte=0"`
+	// Unit of the quantity, a registered unit of the item; base units when empty
+	Unit string `json:"unit"`
+	// Cost of one base unit in the smallest currency unit, for valuation
+	UnitCostCents *int64 `json:"unit_cost_cents" binding:"omitempty,gte=0"`
+}
+
+type createReceiptRequest struct {
+	WarehouseID WarehouseRef `json:"warehouse_id" binding:"required"`
+	// Supplier delivering the receipt, a supplier of the directory
+	SupplierID *int64               `json:"supplier_id"`
+	Reference  string               `json:"reference"`
+	Lines      []receiptLineRequest `json:"lines" binding:"required,min=1,dive"`
+}
+
+type receiveLineRequest struct {
+	LineID        int64  `json:"line_id" binding:"required"`
+	StorageRoomID int32  `json:"storage_room_id" binding:"required"`
+	Quantity      int32  `json:"quantity" binding:"gt=0"`
+	Unit          string `json:"unit"`
+	// Expiry date of the stock received, for FEFO pick lists
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type receiveReceiptRequest struct {
+	Lines []receiveLineRequest `json:"lines" binding:"required,min=1,dive"`
+	// Put the stock away even where it takes a room over its capacity
+	OverrideCapacity bool `json:"override_capacity"`
+}
+
+// receiptDiscrepancy reports a line whose received quantity differs from the expected one
+type receiptDiscrepancy struct {
+	LineID           int64  `json:"line_id"`
+	Sku              string `json:"sku"`
+	ExpectedQuantity int32  `json:"expected_quantity"`
+	ReceivedQuantity int32  `json:"received_quantity"`
+	Variance         int32  `json:"variance"`
+}
+
+func receiptDiscrepancies(lines []models.ReceiptLine) []receiptDiscrepancy {
+	discrepancies := []receiptDiscrepancy{}
+	for _, line := range lines {
+		if line.ReceivedQuantity == line.ExpectedQuantity {
+			continue
+		}
+		discrepancies = append(discrepancies, receiptDiscrepancy{
+			LineID:           line.ID,
+			Sku:              line.Sku,
+			ExpectedQuantity: line.ExpectedQuantity,
+			ReceivedQuantity: line.ReceivedQuantity,
+			Variance:         line.ReceivedQuantity - line.ExpectedQuantity,
+		})
+	}
+	return discrepancies
+}
+
+// receiptProgress derives the receiving status from the line quantities
+func receiptProgress(lines []models.ReceiptLine) string {
+	received := false
+	complete := true
+	for _, line := range lines {
+		if line.ReceivedQuantity > 0 {
+			received = true
+		}
+		if line.ReceivedQuantity < line.ExpectedQuantity {
+			complete = false
+		}
+	}
+	switch {
+	case complete:
+		return receiptStatusReceived
+	case received:
+		return receiptStatusPartiallyReceived
+	default:
+		return receiptStatusOpen
+	}
+}
+
+func receiptIsOpen(receipt models.Receipt) bool {
+	return receipt.Status != receiptStatusClosed && receipt.Status != receiptStatusClosedWithDiscrepancies
+}
+
+func parseReceiptID(ctx *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "Invalid receipt ID format"),
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// createReceiptLines adds the lines of a new receipt with their expected
+// quantities in base units. Fails with a unitError for a unit an item
+// doesn't have.
+func (h *Handlers) createReceiptLines(ctx context.Context, qtx *models.Queries, orgID string, receiptID int64, reqLines []receiptLineRequest) ([]models.ReceiptLine, error) {
+	units := h.unitConverter(qtx, orgID)
+	lines := make([]models.ReceiptLine, 0, len(reqLines))
+	for _, l := range reqLines {
+		expected, err := units.toBase(ctx, l.Sku, l.Unit, l.ExpectedQuantity)
+		if err != nil {
+			return nil, err
+		}
+
+		var unitCost pgtype.Int8
+		if l.UnitCostCents != nil {
+			unitCost = pgtype.Int8{Int64: *l.UnitCostCents, Valid: true}
+		}
+		dbStart := time.Now()
+		line, err := qtx.CreateReceiptLine(ctx, models.CreateReceiptLineParams{
+			ReceiptID:        receiptID,
+			Sku:              l.Sku,
+			ExpectedQuantity: expected,
+			UnitCostCents:    unitCost,
+		})
+		h.recordDBOperation(ctx, "create", "receipt_line", dbStart, err)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+func (h *Handlers) CreateReceipt(ctx *gin.Context) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "CreateReceipt")
+	defer span.End()
+
+	var req createReceiptRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   tr(ctx, "Invalid receipt payload"),
+			"details": err.Error(),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.String("warehouse.ref", string(req.WarehouseID)),
+		attribute.Int("receipt.lines", len(req.Lines)),
+		attribute.String("tenant.id", orgID),
+	)
+
+	tx, err := h.db.Begin(spanCtx)
+	if err != nil {
+		slog.Error("Failed to start transaction", slog.Any("err", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": tr(ctx, "Failed to start transaction"),
+		})
+		return
+	}
+	defer tx.Rollback(spanCtx) // This will be ignored if tx.Commit() succeeds
+
+	qtx := h.queries.WithTx(tx)
+
+	warehouseID, err := h.resolveWarehouse(spanCtx, qtx, orgID, req.WarehouseID)
+	if err == nil {
+		dbStart := time.Now()
+		_, err = qtx.GetWarehouse(spanCtx, models.GetWarehouseParams{
+			ID:    warehouseID,
+			OrgID: orgID,
+		})
+		h.recordDBOperation(spanCtx, "get", "warehouse", dbStart, err)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Warehouse not found"),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Got an error while getting warehouse: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to create receipt"),
+		})
+		return
+	}
+	span.SetAttributes(attribute.Int64("warehouse.id", warehouseID))
+
+	supplierID, err := h.partnerParam(spanCtx, qtx, orgID, supplierKind, req.SupplierID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Supplier not found"),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Got an error while getting supplier: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to create receipt"),
+		})
+		return
+	}
+
+	dbStart := time.Now()
+	receipt, err := qtx.CreateReceipt(spanCtx, models.CreateReceiptParams{
+		OrgID:       orgID,
+		WarehouseID: warehouseID,
+		Reference:   req.Reference,
+		SupplierID:  supplierID,
+	})
+	h.recordDBOperation(spanCtx, "create", "receipt", dbStart, err)
+	if err != nil {
+		slog.Error("Could not create receipt: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to create receipt"),
+		})
+		return
+	}
+
+	lines, err := h.createReceiptLines(spanCtx, qtx, orgID, receipt.ID, req.Lines)
+	if rejected, ok := unitRejected(err); ok {
+		respondUnitError(ctx, rejected)
+		return
+	}
+	if err != nil {
+		slog.Error("Could not create receipt line: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to create receipt"),
+		})
+		return
+	}
+
+	if err := tx.Commit(spanCtx); err != nil {
+		slog.Error("Failed to commit transaction", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to commit transaction"),
+		})
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntityReceipt, "create", opStart, nil)
+
+	span.SetAttributes(
+		attribute.Int64("receipt.id", receipt.ID),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusCreated, gin.H{
+		"message": tr(ctx, "Create Receipt Successfully"),
+		"data": gin.H{
+			"receipt": newReceiptResponse(receipt),
+			"lines":   mapSlice(lines, newReceiptLineResponse),
+		},
+	})
+}
+
+func (h *Handlers) GetReceipt(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "GetReceipt")
+	defer span.End()
+
+	id, ok := parseReceiptID(ctx)
+	if !ok {
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.Int64("receipt.id", id),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	receipt, err := h.queries.GetReceipt(spanCtx, models.GetReceiptParams{
+		ID:    id,
+		OrgID: orgID,
+	})
+	h.recordDBOperation(spanCtx, "get", "receipt", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Receipt not found"),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Got an error while getting receipt: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to get receipt"),
+		})
+		return
+	}
+
+	dbStart = time.Now()
+	lines, err := h.queries.ListReceiptLines(spanCtx, receipt.ID)
+	h.recordDBOperation(spanCtx, "list", "receipt_line", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while listing receipt lines: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to get receipt"),
+		})
+		return
+	}
+
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Get Receipt Successfully"),
+		"data": gin.H{
+			"receipt":       newReceiptResponse(receipt),
+			"lines":         mapSlice(lines, newReceiptLineResponse),
+			"discrepancies": receiptDiscrepancies(lines),
+		},
+	})
+}
+
+func (h *Handlers) ListReceipts(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "ListReceipts")
+	defer span.End()
+
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.Int("receipt.limit", 10),
+		attribute.Int("receipt.offset", 0),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	receipts, err := h.queries.ListReceipts(spanCtx, models.ListReceiptsParams{
+		OrgID:  orgID,
+		Limit:  10,
+		Offset: 0,
+	})
+	h.recordDBOperation(spanCtx, "list", "receipt", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while listing receipts: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to list receipts"),
+		})
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int("receipt.count", len(receipts)),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "List Receipts Successfully"),
+		"data":    mapSlice(receipts, newReceiptResponse),
+	})
+}
+
+// ReceiveReceipt confirms scanned quantities for receipt lines into storage
+// rooms. Stock levels, the adjustment ledger and the receipt status are
+// updated in a single transaction.
+func (h *Handlers) ReceiveReceipt(ctx *gin.Context) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "ReceiveReceipt")
+	defer span.End()
+
+	id, ok := parseReceiptID(ctx)
+	if !ok {
+		return
+	}
+	var req receiveReceiptRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   tr(ctx, "Invalid receive payload"),
+			"details": err.Error(),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.Int64("receipt.id", id),
+		attribute.Int("receipt.received_lines", len(req.Lines)),
+		attribute.String("tenant.id", orgID),
+	)
+
+	tx, err := h.db.Begin(spanCtx)
+	if err != nil {
+		slog.Error("Failed to start transaction", slog.Any("err", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": tr(ctx, "Failed to start transaction"),
+		})
+		return
+	}
+	defer tx.Rollback(spanCtx) // This will be ignored if tx.Commit() succeeds
+
+	qtx := h.queries.WithTx(tx)
+
+	// Lock the receipt so concurrent scans serialize on the status update
+	dbStart := time.Now()
+	receipt, err := qtx.GetReceiptForUpdate(spanCtx, models.GetReceiptForUpdateParams{
+		ID:    id,
+		OrgID: orgID,
+	})
+	h.recordDBOperation(spanCtx, "get", "receipt", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Receipt not found"),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Got an error while getting receipt: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to receive receipt"),
+		})
+		return
+	}
+	if !receiptIsOpen(receipt) {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": tr(ctx, "Receipt is already closed"),
+		})
+		return
+	}
+
+	// SKUs of the lines, to convert the scanned quantities to base units
+	dbStart = time.Now()
+	current, err := qtx.ListReceiptLines(spanCtx, receipt.ID)
+	h.recordDBOperation(spanCtx, "list", "receipt_line", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while listing receipt lines: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to receive receipt"),
+		})
+		return
+	}
+	skus := make(map[int64]string, len(current))
+	for _, line := range current {
+		skus[line.ID] = line.Sku
+	}
+
+	units := h.unitConverter(qtx, orgID)
+	reference := fmt.Sprintf("receipt:%d", receipt.ID)
+	for _, l := range req.Lines {
+		sku, ok := skus[l.LineID]
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": tr(ctx, "Line %d does not belong to this receipt", l.LineID),
+			})
+			return
+		}
+		quantity, err := units.toBase(spanCtx, sku, l.Unit, l.Quantity)
+		if rejected, ok := unitRejected(err); ok {
+			respondUnitError(ctx, rejected)
+			return
+		}
+		if err != nil {
+			slog.Error("Could not look up item: ", slog.Any("err", err.Error()))
+			span.RecordError(err)
+			ctx.JSON(dbErrorStatus(err), gin.H{
+				"error": tr(ctx, "Failed to receive receipt"),
+			})
+			return
+		}
+
+		dbStart = time.Now()
+		room, err := qtx.GetStorageRoom(spanCtx, models.GetStorageRoomParams{
+			ID:    l.StorageRoomID,
+			OrgID: orgID,
+		})
+		h.recordDBOperation(spanCtx, "get", "storage_room", dbStart, err)
+		if errors.Is(err, pgx.ErrNoRows) || (err == nil && int64(room.WarehouseID) != receipt.WarehouseID) {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": tr(ctx, "Storage room %d is not part of the receiving warehouse", l.StorageRoomID),
+			})
+			return
+		}
+		if err != nil {
+			slog.Error("Got an error while getting storage room: ", slog.Any("err", err.Error()))
+			span.RecordError(err)
+			ctx.JSON(dbErrorStatus(err), gin.H{
+				"error": tr(ctx, "Failed to receive receipt"),
+			})
+			return
+		}
+
+		var expiresAt pgtype.Timestamptz
+		if l.ExpiresAt != nil {
+			expiresAt = pgtype.Timestamptz{Time: *l.ExpiresAt, Valid: true}
+		}
+		dbStart = time.Now()
+		line, err := qtx.ReceiveReceiptLine(spanCtx, models.ReceiveReceiptLineParams{
+			ID:               l.LineID,
+			ReceiptID:        receipt.ID,
+			ReceivedQuantity: quantity,
+			ExpiresAt:        expiresAt,
+		})
+		h.recordDBOperation(spanCtx, "update", "receipt_line", dbStart, err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": tr(ctx, "Line %d does not belong to this receipt", l.LineID),
+			})
+			return
+		}
+		if err != nil {
+			slog.Error("Could not update receipt line: ", slog.Any("err", err.Error()))
+			span.RecordError(err)
+			ctx.JSON(dbErrorStatus(err), gin.H{
+				"error": tr(ctx, "Failed to receive receipt"),
+			})
+			return
+		}
+
+		if _, err := h.adjustStock(spanCtx, qtx, stockAdjustment{
+			OrgID:            orgID,
+			StorageRoomID:    room.ID,
+			Sku:              line.Sku,
+			Delta:            quantity,
+			Reason:           adjustmentReasonReceipt,
+			Reference:        reference,
+			OverrideCapacity: req.OverrideCapacity,
+			ExpiresAt:        expiresAt,
+		}); err != nil {
+			if exceeded, ok := capacityExceeded(err); ok {
+				respondCapacityExceeded(ctx, exceeded)
+				return
+			}
+			slog.Error("Could not adjust stock: ", slog.Any("err", err.Error()))
+			span.RecordError(err)
+			ctx.JSON(dbErrorStatus(err), gin.H{
+				"error": tr(ctx, "Failed to receive receipt"),
+			})
+			return
+		}
+	}
+
+	dbStart = time.Now()
+	lines, err := qtx.ListReceiptLines(spanCtx, receipt.ID)
+	h.recordDBOperation(spanCtx, "list", "receipt_line", dbStart, err)
+	if err == nil {
+		dbStart = time.Now()
+		receipt, err = qtx.UpdateReceiptStatus(spanCtx, models.UpdateReceiptStatusParams{
+			ID:     receipt.ID,
+			OrgID:  orgID,
+			Status: receiptProgress(lines),
+		})
+		h.recordDBOperation(spanCtx, "update", "receipt", dbStart, err)
+	}
+	if err != nil {
+		slog.Error("Could not update receipt status: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to receive receipt"),
+		})
+		return
+	}
+
+	if err := tx.Commit(spanCtx); err != nil {
+		slog.Error("Failed to commit transaction", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to commit transaction"),
+		})
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntityReceipt, "receive", opStart, nil)
+
+	span.SetAttributes(
+		attribute.String("receipt.status", receipt.Status),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Receive Receipt Successfully"),
+		"data": gin.H{
+			"receipt":       newReceiptResponse(receipt),
+			"lines":         mapSlice(lines, newReceiptLineResponse),
+			"discrepancies": receiptDiscrepancies(lines),
+		},
+	})
+}
+
+// CloseReceipt finalizes a receipt. Lines that were not received in full,
+// or were over-received, are reported as discrepancies.
+func (h *Handlers) CloseReceipt(ctx *gin.Context) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "CloseReceipt")
+	defer span.End()
+
+	id, ok := parseReceiptID(ctx)
+	if !ok {
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.Int64("receipt.id", id),
+		attribute.String("tenant.id", orgID),
+	)
+
+	tx, err := h.db.Begin(spanCtx)
+	if err != nil {
+		slog.Error("Failed to start transaction", slog.Any("err", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": tr(ctx, "Failed to start transaction"),
+		})
+		return
+	}
+	defer tx.Rollback(spanCtx) // This will be ignored if tx.Commit() succeeds
+
+	qtx := h.queries.WithTx(tx)
+
+	dbStart := time.Now()
+	receipt, err := qtx.GetReceiptForUpdate(spanCtx, models.GetReceiptForUpdateParams{
+		ID:    id,
+		OrgID: orgID,
+	})
+	h.recordDBOperation(spanCtx, "get", "receipt", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Receipt not found"),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Got an error while getting receipt: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to close receipt"),
+		})
+		return
+	}
+	if !receiptIsOpen(receipt) {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": tr(ctx, "Receipt is already closed"),
+		})
+		return
+	}
+
+	dbStart = time.Now()
+	lines, err := qtx.ListReceiptLines(spanCtx, receipt.ID)
+	h.recordDBOperation(spanCtx, "list", "receipt_line", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while listing receipt lines: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to close receipt"),
+		})
+		return
+	}
+
+	discrepancies := receiptDiscrepancies(lines)
+	status := receiptStatusClosed
+	if len(discrepancies) > 0 {
+		status = receiptStatusClosedWithDiscrepancies
+	}
+
+	dbStart = time.Now()
+	receipt, err = qtx.UpdateReceiptStatus(spanCtx, models.UpdateReceiptStatusParams{
+		ID:     receipt.ID,
+		OrgID:  orgID,
+		Status: status,
+	})
+	h.recordDBOperation(spanCtx, "update", "receipt", dbStart, err)
+	if err != nil {
+		slog.Error("Could not update receipt status: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to close receipt"),
+		})
+		return
+	}
+
+	if err := tx.Commit(spanCtx); err != nil {
+		slog.Error("Failed to commit transaction", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to commit transaction"),
+		})
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntityReceipt, "close", opStart, nil)
+
+	span.SetAttributes(
+		attribute.String("receipt.status", receipt.Status),
+		attribute.Int("receipt.discrepancies", len(discrepancies)),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Close Receipt Successfully"),
+		"data": gin.H{
+			"receipt":       newReceiptResponse(receipt),
+			"lines":         mapSlice(lines, newReceiptLineResponse),
+			"discrepancies": discrepancies,
+		},
+	})
+}
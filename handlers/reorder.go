@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+	models "warehouse-service/models/sqlc"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const skuConsumptionView = "sku_consumption"
+
+const (
+	// Days of shipments the sku_consumption view sums up
+	consumptionWindowDays = 90
+	// Lead time of a suggestion that names no supplier or lead time
+	defaultReorderLeadTimeDays = 7
+	// Consumption kept in stock on top of the lead time's
+	reorderSafetyDays = 7
+	// Consumption a reorder should cover once it arrives
+	reorderCoverDays = 30
+)
+
+// reorderSuggestion is when and how much of a SKU a warehouse should
+// reorder at its average daily consumption
+type reorderSuggestion struct {
+	DailyConsumption float64
+	ReorderPoint     int64
+	Quantity         int64
+	ReorderNow       bool
+}
+
+// suggestReorder works out a reorder for a warehouse that shipped shipped
+// units in the consumption window and has available units on hand or on
+// the way. The reorder point covers the lead time plus a safety stock; at or
+// below it the suggested quantity tops the stock up to cover another
+// reorderCoverDays. A SKU that was not shipped is never reordered.
+func suggestReorder(shipped, available int64, leadTimeDays int32) reorderSuggestion {
+	daily := float64(shipped) / consumptionWindowDays
+	s := reorderSuggestion{
+		DailyConsumption: daily,
+		ReorderPoint:     int64(math.Ceil(daily * float64(int64(leadTimeDays)+reorderSafetyDays))),
+	}
+	if daily == 0 || available > s.ReorderPoint {
+		return s
+	}
+	orderUpTo := s.ReorderPoint + int64(math.Ceil(daily*reorderCoverDays))
+	s.ReorderNow = true
+	s.Quantity = orderUpTo - available
+	return s
+}
+
+// ReorderSuggestionResponse is the suggested reorder of a SKU for one
+// warehouse, from consumption as of RefreshedAt and current stock
+type ReorderSuggestionResponse struct {
+	Sku                     string     `json:"sku" v1:"Sku"`
+	WarehouseID             int64      `json:"warehouse_id" v1:"WarehouseID"`
+	AverageDailyConsumption float64    `json:"average_daily_consumption" v1:"AverageDailyConsumption"`
+	LeadTimeDays            int32      `json:"lead_time_days" v1:"LeadTimeDays"`
+	OnHandQuantity          int64      `json:"on_hand_quantity" v1:"OnHandQuantity"`
+	AllocatedQuantity       int64      `json:"allocated_quantity" v1:"AllocatedQuantity"`
+	InTransitQuantity       int64      `json:"in_transit_quantity" v1:"InTransitQuantity"`
+	ReorderPoint            int64      `json:"reorder_point" v1:"ReorderPoint"`
+	SuggestedQuantity       int64      `json:"suggested_quantity" v1:"SuggestedQuantity"`
+	ReorderNow              bool       `json:"reorder_now" v1:"ReorderNow"`
+	RefreshedAt             *time.Time `json:"refreshed_at" v1:"RefreshedAt"`
+}
+
+func newReorderSuggestionResponse(sku string, p models.ListReorderPositionsRow, leadTimeDays int32, refresh models.MaterializedViewRefresh) ReorderSuggestionResponse {
+	available := p.OnHandQuantity - p.AllocatedQuantity + p.InTransitQuantity
+	s := suggestReorder(p.ShippedQuantity, available, leadTimeDays)
+	return ReorderSuggestionResponse{
+		Sku:                     sku,
+		WarehouseID:             p.WarehouseID,
+		AverageDailyConsumption: s.DailyConsumption,
+		LeadTimeDays:            leadTimeDays,
+		OnHandQuantity:          p.OnHandQuantity,
+		AllocatedQuantity:       p.AllocatedQuantity,
+		InTransitQuantity:       p.InTransitQuantity,
+		ReorderPoint:            s.ReorderPoint,
+		SuggestedQuantity:       s.Quantity,
+		ReorderNow:              s.ReorderNow,
+		RefreshedAt:             timePtr(refresh.RefreshedAt),
+	}
+}
+
+// RefreshSkuConsumption is the nightly refresh of the consumption view
+func (h *Handlers) RefreshSkuConsumption(ctx context.Context) error {
+	spanCtx, span := h.tracer.Start(ctx, "RefreshSkuConsumption")
+	defer span.End()
+
+	return h.scheduledRefresh(spanCtx, span, skuConsumptionView, (*models.Queries).RefreshSkuConsumption)
+}
+
+// reorderLeadTime is the lead time of a suggestion: that of the ?supplier_id=
+// from the directory, else ?lead_time_days=, else defaultReorderLeadTimeDays
+func (h *Handlers) reorderLeadTime(ctx *gin.Context, spanCtx context.Context, queries *models.Queries, orgID string) (int32, bool) {
+	if v := ctx.Query("supplier_id"); v != "" {
+		supplierID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": tr(ctx, "Invalid supplier ID format"),
+			})
+			return 0, false
+		}
+		dbStart := time.Now()
+		supplier, err := queries.GetPartner(spanCtx, models.GetPartnerParams{
+			ID:    supplierID,
+			OrgID: orgID,
+			Kind:  supplierKind.name,
+		})
+		h.recordDBOperation(spanCtx, "get", "partner", dbStart, err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error": tr(ctx, "Supplier not found"),
+			})
+			return 0, false
+		}
+		if err != nil {
+			slog.Error("Got an error while getting supplier: ", slog.Any("err", err.Error()))
+			ctx.JSON(dbErrorStatus(err), gin.H{
+				"error": tr(ctx, "Failed to get reorder suggestion"),
+			})
+			return 0, false
+		}
+		return supplier.LeadTimeDays, true
+	}
+	if v := ctx.Query("lead_time_days"); v != "" {
+		days, err := strconv.ParseInt(v, 10, 32)
+		if err != nil || days < 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": tr(ctx, "lead_time_days must be a whole number of days"),
+			})
+			return 0, false
+		}
+		return int32(days), true
+	}
+	return defaultReorderLeadTimeDays, true
+}
+
+// GetReorderSuggestion suggests a reorder point and quantity of a SKU for
+// every warehouse that shipped, holds or awaits it, or for one
+// ?warehouse_id=. Consumption is the average over the last 90 days as of the
+// nightly refresh, stock is current.
+func (h *Handlers) GetReorderSuggestion(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "GetReorderSuggestion")
+	defer span.End()
+
+	// The route shares its wildcard with /v1/items/:id, here it is the SKU
+	sku := ctx.Param("id")
+	orgID := tenantID(ctx)
+	params := models.ListReorderPositionsParams{
+		OrgID: orgID,
+		Sku:   sku,
+	}
+	if v := ctx.Query("warehouse_id"); v != "" {
+		warehouseID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": tr(ctx, "Invalid warehouse ID format"),
+			})
+			return
+		}
+		params.WarehouseID = pgtype.Int8{Int64: warehouseID, Valid: true}
+	}
+	span.SetAttributes(
+		attribute.String("tenant.id", orgID),
+		attribute.String("item.sku", sku),
+	)
+	queries := h.readQueries(spanCtx)
+
+	leadTimeDays, ok := h.reorderLeadTime(ctx, spanCtx, queries, orgID)
+	if !ok {
+		return
+	}
+
+	dbStart := time.Now()
+	positions, err := queries.ListReorderPositions(spanCtx, params)
+	h.recordDBOperation(spanCtx, "list", skuConsumptionView, dbStart, err)
+	var refresh models.MaterializedViewRefresh
+	if err == nil {
+		dbStart = time.Now()
+		refresh, err = queries.GetViewRefresh(spanCtx, skuConsumptionView)
+		h.recordDBOperation(spanCtx, "get", "materialized_view_refresh", dbStart, err)
+	}
+	if err != nil {
+		slog.Error("Got an error while getting reorder suggestion: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to get reorder suggestion"),
+		})
+		return
+	}
+
+	suggestions := make([]ReorderSuggestionResponse, 0, len(positions))
+	for _, p := range positions {
+		suggestions = append(suggestions, newReorderSuggestionResponse(sku, p, leadTimeDays, refresh))
+	}
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Get Reorder Suggestion Successfully"),
+		"data":    suggestions,
+	})
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import "testing"
+
+func TestSuggestReorder(t *testing.T) {
+	cases := []struct {
+		name           string
+		shipped        int64
+		available      int64
+		leadTimeDays   int32
+		wantPoint      int64
+		wantQuantity   int64
+		wantReorderNow bool
+	}{
+		// 10 a day: 14 days cover lead time and safety stock, 30 more once it arrives
+		{"below", 900, 40, 7, 140, 400, true},
+		{"at", 900, 140, 7, 140, 300, true},
+		{"above", 900, 141, 7, 140, 0, false},
+		{"fractional", 45, 0, 0, 4, 19, true},
+		{"not shipped", 0, 0, 7, 0, 0, false},
+	}
+	for _, c := range cases {
+		s := suggestReorder(c.shipped, c.available, c.leadTimeDays)
+		if s.ReorderPoint != c.wantPoint || s.Quantity != c.wantQuantity || s.ReorderNow != c.wantReorderNow {
+			t.Errorf("%s: %+v", c.name, s)
+		}
+	}
+}
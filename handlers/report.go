@@ -0,0 +1,401 @@
+package handlers
+
+import (
+	"cmp"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+	models "warehouse-service/models/sqlc"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// reportPeriods maps the ?group_by= of the movement history to the rough
+// length of one period, which bounds the range a request may cover
+var reportPeriods = map[string]time.Duration{
+	"day":   24 * time.Hour,
+	"week":  7 * 24 * time.Hour,
+	"month": 31 * 24 * time.Hour,
+}
+
+// maxReportPeriods caps the periods one movement history covers
+const maxReportPeriods = 366
+
+// defaultReportRange is the movement history range when ?from= is missing
+const defaultReportRange = 30 * 24 * time.Hour
+
+// WarehouseCount is the number of warehouses sharing a location or status
+type WarehouseCount struct {
+	Country    string `json:"country,omitempty" v1:"Country"`
+	City       string `json:"city,omitempty" v1:"City"`
+	Status     string `json:"status,omitempty" v1:"Status"`
+	Warehouses int64  `json:"warehouses" v1:"Warehouses"`
+}
+
+type WarehouseSummaryResponse struct {
+	Total     int64            `json:"total" v1:"Total"`
+	ByCountry []WarehouseCount `json:"by_country" v1:"ByCountry"`
+	ByCity    []WarehouseCount `json:"by_city" v1:"ByCity"`
+	ByStatus  []WarehouseCount `json:"by_status" v1:"ByStatus"`
+}
+
+type WarehouseStockResponse struct {
+	WarehouseID       int64  `json:"warehouse_id" v1:"WarehouseID"`
+	WarehouseName     string `json:"warehouse_name" v1:"WarehouseName"`
+	StorageRooms      int64  `json:"storage_rooms" v1:"StorageRooms"`
+	Skus              int64  `json:"skus" v1:"Skus"`
+	Quantity          int64  `json:"quantity" v1:"Quantity"`
+	AllocatedQuantity int64  `json:"allocated_quantity" v1:"AllocatedQuantity"`
+	AvailableQuantity int64  `json:"available_quantity" v1:"AvailableQuantity"`
+}
+
+type MovementPeriodResponse struct {
+	Period      time.Time `json:"period" v1:"Period"`
+	Reason      string    `json:"reason" v1:"Reason"`
+	Movements   int64     `json:"movements" v1:"Movements"`
+	QuantityIn  int64     `json:"quantity_in" v1:"QuantityIn"`
+	QuantityOut int64     `json:"quantity_out" v1:"QuantityOut"`
+	NetQuantity int64     `json:"net_quantity" v1:"NetQuantity"`
+}
+
+// MovementHistoryResponse is the movement history of [From, To) by period
+type MovementHistoryResponse struct {
+	From    time.Time                `json:"from" v1:"From"`
+	To      time.Time                `json:"to" v1:"To"`
+	GroupBy string                   `json:"group_by" v1:"GroupBy"`
+	Periods []MovementPeriodResponse `json:"periods" v1:"Periods"`
+}
+
+// summarizeWarehouses rolls the country, city and status groups of the
+// summary query up into each dimension, ordered by name
+func summarizeWarehouses(rows []models.WarehouseSummaryRow) WarehouseSummaryResponse {
+	type cityKey struct{ country, city string }
+	countries := map[string]int64{}
+	cities := map[cityKey]int64{}
+	statuses := map[string]int64{}
+	summary := WarehouseSummaryResponse{
+		ByCountry: []WarehouseCount{},
+		ByCity:    []WarehouseCount{},
+		ByStatus:  []WarehouseCount{},
+	}
+	for _, row := range rows {
+		summary.Total += row.Warehouses
+		countries[row.Country] += row.Warehouses
+		cities[cityKey{row.Country, row.City}] += row.Warehouses
+		statuses[row.Status] += row.Warehouses
+	}
+	for country, n := range countries {
+		summary.ByCountry = append(summary.ByCountry, WarehouseCount{Country: country, Warehouses: n})
+	}
+	for key, n := range cities {
+		summary.ByCity = append(summary.ByCity, WarehouseCount{Country: key.country, City: key.city, Warehouses: n})
+	}
+	for status, n := range statuses {
+		summary.ByStatus = append(summary.ByStatus, WarehouseCount{Status: status, Warehouses: n})
+	}
+	byName := func(a, b WarehouseCount) int {
+		return cmp.Or(cmp.Compare(a.Country, b.Country), cmp.Compare(a.City, b.City), cmp.Compare(a.Status, b.Status))
+	}
+	slices.SortFunc(summary.ByCountry, byName)
+	slices.SortFunc(summary.ByCity, byName)
+	slices.SortFunc(summary.ByStatus, byName)
+	return summary
+}
+
+// parseReportTime reads an RFC 3339 time or a date, which is midnight UTC
+func parseReportTime(value string) (t time.Time, dateOnly bool, err error) {
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, true, nil
+	}
+	t, err = time.Parse(time.RFC3339, value)
+	return t, false, err
+}
+
+// reportRange reads the [from, to) range of a movement history. A date in
+// ?to= includes that day, a missing ?to= is now and a missing ?from= goes
+// back 30 days from it.
+func reportRange(ctx *gin.Context, now time.Time, groupBy string) (from, to time.Time, err error) {
+	to = now
+	if v := ctx.Query("to"); v != "" {
+		var dateOnly bool
+		if to, dateOnly, err = parseReportTime(v); err != nil {
+			return from, to, fmt.Errorf("to must be a date or an RFC 3339 time, got %q", v)
+		}
+		if dateOnly {
+			to = to.AddDate(0, 0, 1)
+		}
+	}
+	from = to.Add(-defaultReportRange)
+	if v := ctx.Query("from"); v != "" {
+		if from, _, err = parseReportTime(v); err != nil {
+			return from, to, fmt.Errorf("from must be a date or an RFC 3339 time, got %q", v)
+		}
+	}
+	if !from.Before(to) {
+		return from, to, errors.New("from must be before to")
+	}
+	if to.Sub(from) > maxReportPeriods*reportPeriods[groupBy] {
+		return from, to, fmt.Errorf("the range must not span more than %d periods of a %s", maxReportPeriods, groupBy)
+	}
+	return from, to, nil
+}
+
+// reportFormat reads ?format=, json or csv
+func reportFormat(ctx *gin.Context) (csv bool, err error) {
+	switch format := ctx.DefaultQuery("format", "json"); format {
+	case "json":
+		return false, nil
+	case "csv":
+		return true, nil
+	default:
+		return false, fmt.Errorf("format must be json or csv, got %q", format)
+	}
+}
+
+// writeCSV sends a report as a CSV download
+func writeCSV(ctx *gin.Context, fileName string, header []string, records [][]string) {
+	ctx.Header("Content-Type", "text/csv")
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	ctx.Status(http.StatusOK)
+
+	w := csv.NewWriter(ctx.Writer)
+	w.Write(header)
+	w.WriteAll(records)
+	if err := w.Error(); err != nil {
+		slog.Error("Could not write report: ", slog.String("report", fileName), slog.Any("err", err.Error()))
+	}
+}
+
+// GetWarehouseSummary counts the tenant's warehouses by country, city and
+// status. The CSV has one row per country, city and status.
+func (h *Handlers) GetWarehouseSummary(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "GetWarehouseSummary")
+	defer span.End()
+
+	asCSV, err := reportFormat(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(attribute.String("tenant.id", orgID))
+
+	dbStart := time.Now()
+	rows, err := h.readQueries(spanCtx).WarehouseSummary(spanCtx, orgID)
+	h.recordDBOperation(spanCtx, "report", "warehouse", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while summarizing warehouses: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to get warehouse summary"),
+		})
+		return
+	}
+
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	if asCSV {
+		records := make([][]string, 0, len(rows))
+		for _, row := range rows {
+			records = append(records, []string{row.Country, row.City, row.Status, strconv.FormatInt(row.Warehouses, 10)})
+		}
+		writeCSV(ctx, "warehouse-summary.csv", []string{"country", "city", "status", "warehouses"}, records)
+		return
+	}
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Get Warehouse Summary Successfully"),
+		"data":    summarizeWarehouses(rows),
+	})
+}
+
+// GetStockByWarehouse totals the stock of every warehouse, optionally for
+// one ?sku= and warehouses in one ?status=
+func (h *Handlers) GetStockByWarehouse(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "GetStockByWarehouse")
+	defer span.End()
+
+	asCSV, err := reportFormat(ctx)
+	var status pgtype.Text
+	if err == nil {
+		status, err = statusFilter(ctx)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(attribute.String("tenant.id", orgID))
+
+	dbStart := time.Now()
+	rows, err := h.readQueries(spanCtx).StockByWarehouse(spanCtx, models.StockByWarehouseParams{
+		Sku:    queryText(ctx, "sku"),
+		OrgID:  orgID,
+		Status: status,
+	})
+	h.recordDBOperation(spanCtx, "report", "stock_level", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while totaling stock by warehouse: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to get stock by warehouse"),
+		})
+		return
+	}
+
+	stock := make([]WarehouseStockResponse, 0, len(rows))
+	for _, row := range rows {
+		stock = append(stock, WarehouseStockResponse{
+			WarehouseID:       row.WarehouseID,
+			WarehouseName:     row.WarehouseName,
+			StorageRooms:      row.StorageRooms,
+			Skus:              row.Skus,
+			Quantity:          row.Quantity,
+			AllocatedQuantity: row.AllocatedQuantity,
+			AvailableQuantity: row.Quantity - row.AllocatedQuantity,
+		})
+	}
+
+	span.SetAttributes(
+		attribute.Int("report.rows", len(stock)),
+		attribute.String("operation.status", "success"),
+	)
+	if asCSV {
+		records := make([][]string, 0, len(stock))
+		for _, s := range stock {
+			records = append(records, []string{
+				strconv.FormatInt(s.WarehouseID, 10),
+				s.WarehouseName,
+				strconv.FormatInt(s.StorageRooms, 10),
+				strconv.FormatInt(s.Skus, 10),
+				strconv.FormatInt(s.Quantity, 10),
+				strconv.FormatInt(s.AllocatedQuantity, 10),
+				strconv.FormatInt(s.AvailableQuantity, 10),
+			})
+		}
+		writeCSV(ctx, "stock-by-warehouse.csv",
+			[]string{"warehouse_id", "warehouse_name", "storage_rooms", "skus", "quantity", "allocated_quantity", "available_quantity"}, records)
+		return
+	}
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Get Stock By Warehouse Successfully"),
+		"data":    stock,
+	})
+}
+
+// GetMovementHistory sums stock movements per reason and ?group_by= day,
+// week or month between ?from= and ?to=, optionally for one ?warehouse_id=
+// and ?sku=
+func (h *Handlers) GetMovementHistory(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "GetMovementHistory")
+	defer span.End()
+
+	groupBy := ctx.DefaultQuery("group_by", "day")
+	if _, ok := reportPeriods[groupBy]; !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "group_by must be day, week or month, got %q", groupBy),
+		})
+		return
+	}
+	from, to, err := reportRange(ctx, time.Now().UTC(), groupBy)
+	var asCSV bool
+	if err == nil {
+		asCSV, err = reportFormat(ctx)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	params := models.MovementHistoryParams{
+		Granularity: groupBy,
+		OrgID:       orgID,
+		FromTime:    pgtype.Timestamptz{Time: from, Valid: true},
+		ToTime:      pgtype.Timestamptz{Time: to, Valid: true},
+		Sku:         queryText(ctx, "sku"),
+	}
+	if v := ctx.Query("warehouse_id"); v != "" {
+		warehouseID, err := strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": tr(ctx, "Invalid warehouse ID format"),
+			})
+			return
+		}
+		params.WarehouseID = pgtype.Int4{Int32: int32(warehouseID), Valid: true}
+	}
+	span.SetAttributes(
+		attribute.String("tenant.id", orgID),
+		attribute.String("report.group_by", groupBy),
+		attribute.String("report.from", from.Format(time.RFC3339)),
+		attribute.String("report.to", to.Format(time.RFC3339)),
+	)
+
+	dbStart := time.Now()
+	rows, err := h.readQueries(spanCtx).MovementHistory(spanCtx, params)
+	h.recordDBOperation(spanCtx, "report", "stock_adjustment", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while reading movement history: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to get movement history"),
+		})
+		return
+	}
+
+	periods := make([]MovementPeriodResponse, 0, len(rows))
+	for _, row := range rows {
+		periods = append(periods, MovementPeriodResponse{
+			Period:      row.Period.Time.UTC(),
+			Reason:      row.Reason,
+			Movements:   row.Movements,
+			QuantityIn:  row.QuantityIn,
+			QuantityOut: row.QuantityOut,
+			NetQuantity: row.NetQuantity,
+		})
+	}
+
+	span.SetAttributes(
+		attribute.Int("report.rows", len(periods)),
+		attribute.String("operation.status", "success"),
+	)
+	if asCSV {
+		records := make([][]string, 0, len(periods))
+		for _, p := range periods {
+			records = append(records, []string{
+				p.Period.Format(time.RFC3339),
+				p.Reason,
+				strconv.FormatInt(p.Movements, 10),
+				strconv.FormatInt(p.QuantityIn, 10),
+				strconv.FormatInt(p.QuantityOut, 10),
+				strconv.FormatInt(p.NetQuantity, 10),
+			})
+		}
+		writeCSV(ctx, "movement-history.csv",
+			[]string{"period", "reason", "movements", "quantity_in", "quantity_out", "net_quantity"}, records)
+		return
+	}
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Get Movement History Successfully"),
+		"data": MovementHistoryResponse{
+			From:    from,
+			To:      to,
+			GroupBy: groupBy,
+			Periods: periods,
+		},
+	})
+}
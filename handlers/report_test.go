@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+	models "warehouse-service/models/sqlc"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSummarizeWarehouses(t *testing.T) {
+	summary := summarizeWarehouses([]models.WarehouseSummaryRow{
+		{Country: "VN", City: "Hanoi", Status: "active", Warehouses: 2},
+		{Country: "VN", City: "Hanoi", Status: "archived", Warehouses: 1},
+		{Country: "DE", City: "Berlin", Status: "active", Warehouses: 3},
+		{Country: "VN", City: "Da Nang", Status: "maintenance", Warehouses: 1},
+	})
+	if summary.Total != 7 {
+		t.Errorf("total %d, want 7", summary.Total)
+	}
+	if got := fmt.Sprint(summary.ByCountry); got != "[{DE   3} {VN   4}]" {
+		t.Errorf("by country %s", got)
+	}
+	if got := fmt.Sprint(summary.ByCity); got != "[{DE Berlin  3} {VN Da Nang  1} {VN Hanoi  3}]" {
+		t.Errorf("by city %s", got)
+	}
+	if got := fmt.Sprint(summary.ByStatus); got != "[{  active 5} {  archived 1} {  maintenance 1}]" {
+		t.Errorf("by status %s", got)
+	}
+
+	empty := summarizeWarehouses(nil)
+	if empty.Total != 0 || empty.ByCountry == nil || empty.ByCity == nil || empty.ByStatus == nil {
+		t.Errorf("empty summary %+v, want empty lists", empty)
+	}
+}
+
+func TestReportRange(t *testing.T) {
+	now := time.Date(2024, 3, 10, 15, 0, 0, 0, time.UTC)
+	tests := []struct {
+		query    string
+		groupBy  string
+		from, to string
+		fails    bool
+	}{
+		{query: "", groupBy: "day", from: "2024-02-09T15:00:00Z", to: "2024-03-10T15:00:00Z"},
+		{query: "from=2024-03-01&to=2024-03-07", groupBy: "day", from: "2024-03-01T00:00:00Z", to: "2024-03-08T00:00:00Z"},
+		{query: "from=2024-03-01T06:00:00Z&to=2024-03-01T18:00:00Z", groupBy: "day", from: "2024-03-01T06:00:00Z", to: "2024-03-01T18:00:00Z"},
+		{query: "from=2020-01-01", groupBy: "month", from: "2020-01-01T00:00:00Z", to: "2024-03-10T15:00:00Z"},
+		{query: "from=2020-01-01", groupBy: "day", fails: true},
+		{query: "from=2024-03-08&to=2024-03-01", groupBy: "day", fails: true},
+		{query: "from=yesterday", groupBy: "day", fails: true},
+		{query: "to=03/01/2024", groupBy: "day", fails: true},
+	}
+	for _, tt := range tests {
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		ctx.Request = httptest.NewRequest("GET", "/v1/reports/movement-history?"+tt.query, nil)
+		from, to, err := reportRange(ctx, now, tt.groupBy)
+		if (err != nil) != tt.fails {
+			t.Errorf("%q by %s: err = %v", tt.query, tt.groupBy, err)
+			continue
+		}
+		if err == nil && (from.Format(time.RFC3339) != tt.from || to.Format(time.RFC3339) != tt.to) {
+			t.Errorf("%q by %s: range %s - %s, want %s - %s", tt.query, tt.groupBy, from.Format(time.RFC3339), to.Format(time.RFC3339), tt.from, tt.to)
+		}
+	}
+}
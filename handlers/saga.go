@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	"warehouse-service/listquery"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var sagaStatuses = []string{sagaStatusRunning, sagaStatusCompleted, sagaStatusCompensating, sagaStatusCompensated, sagaStatusFailed}
+
+func parseSagaID(ctx *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "Invalid saga ID format"),
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// getSaga looks up the saga of the :id parameter, writing the error
+// response when it can't
+func (h *Handlers) getSaga(ctx *gin.Context, orgID string) (models.Saga, bool) {
+	id, ok := parseSagaID(ctx)
+	if !ok {
+		return models.Saga{}, false
+	}
+	spanCtx := ctx.Request.Context()
+	dbStart := time.Now()
+	saga, err := h.queries.GetSaga(spanCtx, models.GetSagaParams{
+		ID:    id,
+		OrgID: orgID,
+	})
+	h.recordDBOperation(spanCtx, "get", "saga", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Saga not found"),
+		})
+		return saga, false
+	}
+	if err != nil {
+		slog.Error("Got an error while getting saga: ", slog.Any("err", err.Error()))
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to get saga"),
+		})
+		return saga, false
+	}
+	return saga, true
+}
+
+// sagaListSpec sorts and filters the sagas of ListSagas
+var sagaListSpec = &listquery.Spec{
+	Table: "saga",
+	Sort: map[string]string{
+		"id":         "id",
+		"kind":       "kind",
+		"status":     "status",
+		"created_at": "created_at",
+		"updated_at": "updated_at",
+	},
+	Filters: map[string]listquery.Filter{
+		"status": {Column: "status", Op: listquery.Eq, Parse: listquery.OneOf(sagaStatuses...)},
+		"kind":   {Column: "kind", Op: listquery.Eq, Parse: listquery.Text},
+	},
+	Default: []listquery.Order{{Field: "id", Desc: true}},
+	Key:     "id",
+}
+
+// ListSagas lists the sagas of the organization, newest first, optionally
+// of one status
+func (h *Handlers) ListSagas(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "ListSagas")
+	defer span.End()
+
+	q, err := listParams(ctx, sagaListSpec)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	q.Where("org_id", listquery.Eq, orgID)
+	span.SetAttributes(attribute.String("tenant.id", orgID))
+
+	dbStart := time.Now()
+	sagas, err := queryPage[models.Saga](ctx, spanCtx, h.db, q)
+	h.recordDBOperation(spanCtx, "list", "saga", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while listing sagas: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to list sagas"),
+		})
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int("saga.count", len(sagas)),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "List Sagas Successfully"),
+		"data":    mapSlice(sagas, newSagaResponse),
+	})
+}
+
+// GetSaga returns a saga with the status of its steps
+func (h *Handlers) GetSaga(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "GetSaga")
+	defer span.End()
+
+	orgID := tenantID(ctx)
+	span.SetAttributes(attribute.String("tenant.id", orgID))
+	saga, ok := h.getSaga(ctx, orgID)
+	if !ok {
+		return
+	}
+
+	dbStart := time.Now()
+	steps, err := h.queries.ListSagaSteps(spanCtx, saga.ID)
+	h.recordDBOperation(spanCtx, "list", "saga_step", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while listing saga steps: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to get saga"),
+		})
+		return
+	}
+
+	resp := newSagaResponse(saga)
+	resp.Steps = mapSlice(steps, newSagaStepResponse)
+	span.SetAttributes(
+		attribute.Int64("saga.id", saga.ID),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Get Saga Successfully"),
+		"data":    resp,
+	})
+}
+
+// RetrySaga advances a saga again, e.g. after its job ran out of attempts.
+// A failed saga resumes its compensation.
+func (h *Handlers) RetrySaga(ctx *gin.Context) {
+	h.sagaAction(ctx, "retry", func(saga models.Saga) (string, string, bool) {
+		switch saga.Status {
+		case sagaStatusRunning, sagaStatusCompensating:
+			return saga.Status, saga.Error, true
+		case sagaStatusFailed:
+			return sagaStatusCompensating, saga.Error, true
+		}
+		return "", "", false
+	})
+}
+
+// CompensateSaga gives up on a running saga, undoing the steps it did
+func (h *Handlers) CompensateSaga(ctx *gin.Context) {
+	h.sagaAction(ctx, "compensate", func(saga models.Saga) (string, string, bool) {
+		return sagaStatusCompensating, "compensated on request", saga.Status == sagaStatusRunning
+	})
+}
+
+// sagaAction moves a saga to the status and error next returns and has it
+// advance, holding the saga so that it doesn't advance meanwhile. next
+// tells whether the action applies to the saga's status.
+func (h *Handlers) sagaAction(ctx *gin.Context, op string, next func(models.Saga) (string, string, bool)) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "SagaAction")
+	defer span.End()
+
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.String("saga.action", op),
+		attribute.String("tenant.id", orgID),
+	)
+	saga, ok := h.getSaga(ctx, orgID)
+	if !ok {
+		return
+	}
+	span.SetAttributes(attribute.Int64("saga.id", saga.ID))
+
+	errNotApplicable := errors.New("not applicable")
+	err := h.withSagaLock(spanCtx, saga.ID, func(qtx *models.Queries) error {
+		// Read again now that the saga is held
+		current, err := qtx.GetSaga(spanCtx, models.GetSagaParams{
+			ID:    saga.ID,
+			OrgID: orgID,
+		})
+		if err != nil {
+			return err
+		}
+		status, message, ok := next(current)
+		if !ok {
+			saga = current
+			return errNotApplicable
+		}
+		saga, err = h.updateSagaStatus(spanCtx, qtx, current.ID, status, message)
+		if err != nil {
+			return err
+		}
+		return h.enqueueSagaAdvance(spanCtx, qtx, saga)
+	})
+	switch {
+	case errors.Is(err, errNotApplicable):
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": tr(ctx, "Saga is %s", saga.Status),
+		})
+		return
+	case errors.Is(err, errSagaAdvancing):
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": tr(ctx, "Saga is advancing, try again"),
+		})
+		return
+	case err != nil:
+		slog.Error("Could not update saga: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		h.recordOperation(orgID, observability.EntitySaga, op, opStart, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to update saga"),
+		})
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntitySaga, op, opStart, nil)
+
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	respondV1(ctx, http.StatusAccepted, gin.H{
+		"message": tr(ctx, "Update Saga Successfully"),
+		"data":    newSagaResponse(saga),
+	})
+}
@@ -0,0 +1,454 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"warehouse-service/jobs"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+	"warehouse-service/services"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// JobKindAdvanceSaga runs the steps of a saga that can run now, or
+// compensates the steps done of a saga that failed. It is enqueued in the
+// transaction of every change the saga waits on.
+const JobKindAdvanceSaga = "advance_saga"
+
+// A fulfillment saga follows a pick list from its allocation to the
+// shipment confirmed to order-service
+const sagaKindFulfillment = "fulfillment"
+
+// Statuses of a saga. A running saga runs its steps in order, a
+// compensating one undoes the steps done in reverse order. A saga whose
+// compensation failed for good is failed and left to people.
+const (
+	sagaStatusRunning      = "running"
+	sagaStatusCompleted    = "completed"
+	sagaStatusCompensating = "compensating"
+	sagaStatusCompensated  = "compensated"
+	sagaStatusFailed       = "failed"
+)
+
+// Statuses of a saga step
+const (
+	sagaStepPending   = "pending"
+	sagaStepSucceeded = "succeeded"
+	sagaStepFailed    = "failed"
+	// The step had nothing to do, e.g. its service is not configured
+	sagaStepSkipped     = "skipped"
+	sagaStepCompensated = "compensated"
+)
+
+// sagaActor is recorded in the audit log for changes made by compensations
+const sagaActor = "system:saga"
+
+type sagaJob struct {
+	SagaID int64 `json:"saga_id"`
+}
+
+var (
+	// errSagaAdvancing is returned while another runner holds the saga
+	errSagaAdvancing = errors.New("saga is advancing")
+	// errServiceDisabled fails a compensation that needs a sibling service
+	// configured no more
+	errServiceDisabled = errors.New("service is not configured")
+)
+
+// sagaState is what the steps of a fulfillment saga work on, read when the
+// saga advances
+type sagaState struct {
+	saga     models.Saga
+	pickList models.PickList
+	lines    []models.PickListLine
+}
+
+// sagaStep is a step of a saga. run returns the status the step reached:
+// pending while it waits on the pick list or failed temporarily, which the
+// job retries, failed with the error failing the saga. compensate undoes a
+// step that succeeded in the transaction of qtx, it is nil when there is
+// nothing to undo.
+type sagaStep struct {
+	name       string
+	run        func(ctx context.Context, s sagaState) (string, error)
+	compensate func(ctx context.Context, qtx *models.Queries, s sagaState) error
+}
+
+// sagaSteps returns the steps of a kind of saga in the order they run
+func (h *Handlers) sagaSteps(kind string) []sagaStep {
+	if kind != sagaKindFulfillment {
+		return nil
+	}
+	return []sagaStep{
+		{
+			name: "allocate",
+			// Done by the time the saga starts
+			run:        func(context.Context, sagaState) (string, error) { return sagaStepSucceeded, nil },
+			compensate: h.cancelSagaPickList,
+		},
+		{name: "reserve", run: h.reserveSagaStock, compensate: h.releaseSagaReservation},
+		{name: "ship", run: shipSagaPickList, compensate: h.reverseSagaShipment},
+		// The shipped stock is no longer the warehouse's to hold
+		{name: "release", run: h.releaseSagaStock},
+		{name: "confirm", run: h.confirmSagaShipment},
+	}
+}
+
+// sagaCallStatus is the status a step calling a sibling service reached
+func sagaCallStatus(err error) string {
+	switch {
+	case err == nil:
+		return sagaStepSucceeded
+	case services.Permanent(err):
+		return sagaStepFailed
+	}
+	return sagaStepPending
+}
+
+// sagaCompensationFailed tells whether a compensation failed for good
+func sagaCompensationFailed(err error) bool {
+	return services.Permanent(err) || errors.Is(err, errServiceDisabled)
+}
+
+func (h *Handlers) reserveSagaStock(ctx context.Context, s sagaState) (string, error) {
+	lines := serviceLines(s.lines, false)
+	if h.services.Inventory == nil || len(lines) == 0 {
+		return sagaStepSkipped, nil
+	}
+	_, err := h.services.Inventory.Reserve(ctx, services.Reservation{
+		OrgID:       s.pickList.OrgID,
+		Reference:   pickListServiceReference(s.pickList.ID),
+		WarehouseID: s.pickList.WarehouseID,
+		Lines:       lines,
+	})
+	return sagaCallStatus(err), err
+}
+
+func shipSagaPickList(_ context.Context, s sagaState) (string, error) {
+	switch s.pickList.Status {
+	case pickListStatusShipped:
+		return sagaStepSucceeded, nil
+	case pickListStatusCancelled:
+		return sagaStepFailed, errors.New("pick list was cancelled")
+	}
+	return sagaStepPending, nil
+}
+
+func (h *Handlers) releaseSagaStock(ctx context.Context, s sagaState) (string, error) {
+	if h.services.Inventory == nil {
+		return sagaStepSkipped, nil
+	}
+	err := h.services.Inventory.Release(ctx, s.pickList.OrgID, pickListServiceReference(s.pickList.ID))
+	return sagaCallStatus(err), err
+}
+
+func (h *Handlers) confirmSagaShipment(ctx context.Context, s sagaState) (string, error) {
+	if h.services.Orders == nil {
+		return sagaStepSkipped, nil
+	}
+	err := h.services.Orders.ConfirmShipment(ctx, services.Shipment{
+		OrgID:          s.pickList.OrgID,
+		OrderReference: s.pickList.Reference,
+		Reference:      pickListServiceReference(s.pickList.ID),
+		WarehouseID:    s.pickList.WarehouseID,
+		Lines:          serviceLines(s.lines, true),
+	})
+	return sagaCallStatus(err), err
+}
+
+// cancelSagaPickList cancels the pick list unless it shipped or was
+// cancelled already
+func (h *Handlers) cancelSagaPickList(ctx context.Context, qtx *models.Queries, s sagaState) error {
+	dbStart := time.Now()
+	pickList, err := qtx.GetPickListForUpdate(ctx, models.GetPickListForUpdateParams{
+		ID:    s.pickList.ID,
+		OrgID: s.pickList.OrgID,
+	})
+	h.recordDBOperation(ctx, "get", "pick_list", dbStart, err)
+	if err != nil {
+		return err
+	}
+	if pickList.Status != pickListStatusAllocated && pickList.Status != pickListStatusPicked {
+		return nil
+	}
+	return h.cancelPickList(ctx, qtx, pickList, "compensate", sagaActor)
+}
+
+func (h *Handlers) releaseSagaReservation(ctx context.Context, _ *models.Queries, s sagaState) error {
+	if h.services.Inventory == nil {
+		return fmt.Errorf("inventory %w", errServiceDisabled)
+	}
+	return h.services.Inventory.Release(ctx, s.pickList.OrgID, pickListServiceReference(s.pickList.ID))
+}
+
+// reverseSagaShipment puts the picked stock back into the storage rooms it
+// shipped from, whatever their capacity
+func (h *Handlers) reverseSagaShipment(ctx context.Context, qtx *models.Queries, s sagaState) error {
+	reference := fmt.Sprintf("saga:%d", s.saga.ID)
+	for _, line := range s.lines {
+		if line.PickedQuantity == 0 {
+			continue
+		}
+		if _, err := h.adjustStock(ctx, qtx, stockAdjustment{
+			OrgID:            s.saga.OrgID,
+			StorageRoomID:    line.StorageRoomID,
+			Sku:              line.Sku,
+			Delta:            line.PickedQuantity,
+			Reason:           adjustmentReasonShipmentReversal,
+			Reference:        reference,
+			OverrideCapacity: true,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// startFulfillmentSaga starts the fulfillment saga of a pick list allocated
+// with qtx when a sibling service is configured. It advances once the
+// allocation commits.
+func (h *Handlers) startFulfillmentSaga(ctx context.Context, qtx *models.Queries, pickList models.PickList) error {
+	if h.services.Inventory == nil && h.services.Orders == nil {
+		return nil
+	}
+	dbStart := time.Now()
+	saga, err := qtx.CreateSaga(ctx, models.CreateSagaParams{
+		OrgID:      pickList.OrgID,
+		Kind:       sagaKindFulfillment,
+		PickListID: pickList.ID,
+	})
+	h.recordDBOperation(ctx, "create", "saga", dbStart, err)
+	if err != nil {
+		return err
+	}
+	for i, step := range h.sagaSteps(saga.Kind) {
+		dbStart = time.Now()
+		err := qtx.CreateSagaStep(ctx, models.CreateSagaStepParams{
+			SagaID:   saga.ID,
+			Position: int32(i),
+			Name:     step.name,
+		})
+		h.recordDBOperation(ctx, "create", "saga_step", dbStart, err)
+		if err != nil {
+			return err
+		}
+	}
+	return h.enqueueSagaAdvance(ctx, qtx, saga)
+}
+
+// advancePickListSaga has the running saga of a pick list changed with qtx
+// advance once the change commits
+func (h *Handlers) advancePickListSaga(ctx context.Context, qtx *models.Queries, pickList models.PickList) error {
+	dbStart := time.Now()
+	saga, err := qtx.GetSagaForPickList(ctx, models.GetSagaForPickListParams{
+		PickListID: pickList.ID,
+		Kind:       sagaKindFulfillment,
+	})
+	h.recordDBOperation(ctx, "get", "saga", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if saga.Status != sagaStatusRunning {
+		return nil
+	}
+	return h.enqueueSagaAdvance(ctx, qtx, saga)
+}
+
+func (h *Handlers) enqueueSagaAdvance(ctx context.Context, qtx *models.Queries, saga models.Saga) error {
+	dbStart := time.Now()
+	_, err := jobs.Enqueue(ctx, qtx, saga.OrgID, JobKindAdvanceSaga, sagaJob{SagaID: saga.ID}, jobs.EnqueueOptions{})
+	h.recordDBOperation(ctx, "create", "job", dbStart, err)
+	return err
+}
+
+// withSagaLock runs fn holding the saga, or returns errSagaAdvancing. What
+// fn does with qtx commits when it returns nil.
+func (h *Handlers) withSagaLock(ctx context.Context, sagaID int64, fn func(qtx *models.Queries) error) error {
+	return pgx.BeginFunc(ctx, h.db, func(tx pgx.Tx) error {
+		qtx := h.queries.WithTx(tx)
+		locked, err := qtx.TryLockRefresh(ctx, fmt.Sprintf("saga:%d", sagaID))
+		if err != nil {
+			return err
+		}
+		if !locked {
+			return errSagaAdvancing
+		}
+		return fn(qtx)
+	})
+}
+
+// AdvanceSagaJob runs an advance_saga job. Steps failing temporarily are
+// retried as the job; a saga whose job runs out of attempts stays where it
+// was until it is retried.
+func (h *Handlers) AdvanceSagaJob(ctx context.Context, job models.Job) error {
+	opStart := time.Now()
+	spanCtx, span := h.tracer.Start(ctx, "AdvanceSagaJob")
+	defer span.End()
+
+	var payload sagaJob
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return fmt.Errorf("decode saga job: %w", err)
+	}
+	span.SetAttributes(
+		attribute.Int64("saga.id", payload.SagaID),
+		attribute.String("tenant.id", job.OrgID),
+	)
+
+	err := h.withSagaLock(spanCtx, payload.SagaID, func(*models.Queries) error {
+		return h.advanceSaga(spanCtx, job.OrgID, payload.SagaID)
+	})
+	h.recordOperation(job.OrgID, observability.EntitySaga, "advance", opStart, err)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	return nil
+}
+
+// advanceSaga runs the steps of a saga held by the caller. Each step is
+// recorded as it ends, so steps done stay done when a later one fails.
+func (h *Handlers) advanceSaga(ctx context.Context, orgID string, sagaID int64) error {
+	dbStart := time.Now()
+	saga, err := h.queries.GetSaga(ctx, models.GetSagaParams{
+		ID:    sagaID,
+		OrgID: orgID,
+	})
+	h.recordDBOperation(ctx, "get", "saga", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("saga %d was deleted", sagaID)
+	}
+	if err != nil {
+		return err
+	}
+	dbStart = time.Now()
+	steps, err := h.queries.ListSagaSteps(ctx, saga.ID)
+	h.recordDBOperation(ctx, "list", "saga_step", dbStart, err)
+	if err != nil {
+		return err
+	}
+	defs := h.sagaSteps(saga.Kind)
+	if len(defs) != len(steps) {
+		return fmt.Errorf("saga %d has %d steps, %s sagas have %d", saga.ID, len(steps), saga.Kind, len(defs))
+	}
+	state, err := h.sagaState(ctx, saga)
+	if err != nil {
+		return err
+	}
+
+	if saga.Status == sagaStatusRunning {
+		for i := range steps {
+			if steps[i].Status != sagaStepPending {
+				continue
+			}
+			status, runErr := defs[i].run(ctx, state)
+			if status == sagaStepPending && runErr == nil {
+				// Waiting on the pick list
+				return nil
+			}
+			if err := h.updateSagaStep(ctx, h.queries, steps[i], status, runErr); err != nil {
+				return err
+			}
+			if status == sagaStepPending {
+				return runErr
+			}
+			steps[i].Status = status
+			if status == sagaStepFailed {
+				saga, err = h.updateSagaStatus(ctx, h.queries, saga.ID, sagaStatusCompensating, fmt.Sprintf("%s: %v", steps[i].Name, runErr))
+				if err != nil {
+					return err
+				}
+				break
+			}
+		}
+		if saga.Status == sagaStatusRunning {
+			_, err := h.updateSagaStatus(ctx, h.queries, saga.ID, sagaStatusCompleted, "")
+			return err
+		}
+	}
+	if saga.Status != sagaStatusCompensating {
+		return nil
+	}
+
+	for i := len(steps) - 1; i >= 0; i-- {
+		if steps[i].Status != sagaStepSucceeded {
+			continue
+		}
+		err := pgx.BeginFunc(ctx, h.db, func(tx pgx.Tx) error {
+			qtx := h.queries.WithTx(tx)
+			if defs[i].compensate != nil {
+				if err := defs[i].compensate(ctx, qtx, state); err != nil {
+					return err
+				}
+			}
+			return h.updateSagaStep(ctx, qtx, steps[i], sagaStepCompensated, nil)
+		})
+		if err == nil {
+			continue
+		}
+		if err := h.updateSagaStep(ctx, h.queries, steps[i], sagaStepSucceeded, err); err != nil {
+			return err
+		}
+		if !sagaCompensationFailed(err) {
+			return err
+		}
+		_, err = h.updateSagaStatus(ctx, h.queries, saga.ID, sagaStatusFailed, fmt.Sprintf("compensate %s: %v", steps[i].Name, err))
+		return err
+	}
+	_, err = h.updateSagaStatus(ctx, h.queries, saga.ID, sagaStatusCompensated, saga.Error)
+	return err
+}
+
+// sagaState reads the pick list of a saga with its lines
+func (h *Handlers) sagaState(ctx context.Context, saga models.Saga) (sagaState, error) {
+	state := sagaState{saga: saga}
+	dbStart := time.Now()
+	pickList, err := h.queries.GetPickList(ctx, models.GetPickListParams{
+		ID:    saga.PickListID,
+		OrgID: saga.OrgID,
+	})
+	h.recordDBOperation(ctx, "get", "pick_list", dbStart, err)
+	if err != nil {
+		return state, err
+	}
+	state.pickList = pickList
+	dbStart = time.Now()
+	state.lines, err = h.queries.ListPickListLines(ctx, pickList.ID)
+	h.recordDBOperation(ctx, "list", "pick_list_line", dbStart, err)
+	return state, err
+}
+
+func (h *Handlers) updateSagaStep(ctx context.Context, q *models.Queries, step models.SagaStep, status string, stepErr error) error {
+	message := ""
+	if stepErr != nil {
+		message = stepErr.Error()
+	}
+	dbStart := time.Now()
+	err := q.UpdateSagaStep(ctx, models.UpdateSagaStepParams{
+		SagaID:   step.SagaID,
+		Position: step.Position,
+		Status:   status,
+		Error:    message,
+	})
+	h.recordDBOperation(ctx, "update", "saga_step", dbStart, err)
+	return err
+}
+
+func (h *Handlers) updateSagaStatus(ctx context.Context, q *models.Queries, id int64, status, message string) (models.Saga, error) {
+	dbStart := time.Now()
+	saga, err := q.UpdateSagaStatus(ctx, models.UpdateSagaStatusParams{
+		ID:     id,
+		Status: status,
+		Error:  message,
+	})
+	h.recordDBOperation(ctx, "update", "saga", dbStart, err)
+	return saga, err
+}
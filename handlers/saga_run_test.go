@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/services"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSagaCallStatus(t *testing.T) {
+	for _, c := range []struct {
+		err  error
+		want string
+	}{
+		{nil, sagaStepSucceeded},
+		{fmt.Errorf("order.v1.OrderService: %w", status.Error(codes.FailedPrecondition, "order is closed")), sagaStepFailed},
+		{services.ErrCircuitOpen, sagaStepPending},
+		{errors.New("connection reset"), sagaStepPending},
+	} {
+		if got := sagaCallStatus(c.err); got != c.want {
+			t.Errorf("sagaCallStatus(%v) = %s, want %s", c.err, got, c.want)
+		}
+	}
+	if !sagaCompensationFailed(fmt.Errorf("inventory %w", errServiceDisabled)) || sagaCompensationFailed(services.ErrCircuitOpen) {
+		t.Error("compensation failures misclassified")
+	}
+}
+
+func TestShipSagaPickList(t *testing.T) {
+	for pickListStatus, want := range map[string]string{
+		pickListStatusAllocated: sagaStepPending,
+		pickListStatusPicked:    sagaStepPending,
+		pickListStatusShipped:   sagaStepSucceeded,
+		pickListStatusCancelled: sagaStepFailed,
+	} {
+		got, err := shipSagaPickList(context.Background(), sagaState{pickList: models.PickList{Status: pickListStatus}})
+		if got != want || (err != nil) != (want == sagaStepFailed) {
+			t.Errorf("pick list %s: got %s, %v", pickListStatus, got, err)
+		}
+	}
+}
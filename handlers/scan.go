@@ -0,0 +1,482 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Kinds of entity a scan action answers with, as ResolveScan names them
+const (
+	scanKindSerial = "serial"
+	scanKindItem   = "item"
+)
+
+// Reference of stock movements made from a handheld
+const scanReference = "scan"
+
+// Payloads of the scan actions are short so scanners send them fast: codes
+// are what the device read, locations are "<warehouse>-<number>" codes and a
+// missing qty is one unit.
+type scanMoveRequest struct {
+	Code string `json:"code" binding:"required,max=128"`
+	From string `json:"from" binding:"max=64"`
+	To   string `json:"to" binding:"required,max=64"`
+	Qty  int32  `json:"qty" binding:"min=0"`
+}
+
+type scanCountRequest struct {
+	Location string `json:"location" binding:"required,max=64"`
+	Code     string `json:"code" binding:"required,max=128"`
+	Qty      *int32 `json:"qty" binding:"required,min=0"`
+}
+
+type scanPickRequest struct {
+	Code     string `json:"code" binding:"required,max=128"`
+	Location string `json:"location" binding:"max=64"`
+	Qty      int32  `json:"qty" binding:"min=0"`
+}
+
+// scanQuantity is the quantity of an action, one unit when it names none
+func scanQuantity(qty int32) int32 {
+	if qty == 0 {
+		return 1
+	}
+	return qty
+}
+
+// ScanResponse is the entity behind a scanned code. Fields that don't apply
+// to the kind are left out to keep the payload small.
+type ScanResponse struct {
+	Kind          string `json:"kind" v1:"Kind"`
+	ID            int64  `json:"id,omitempty" v1:"ID"`
+	WarehouseID   int64  `json:"warehouse_id,omitempty" v1:"WarehouseID"`
+	StorageRoomID int32  `json:"storage_room_id,omitempty" v1:"StorageRoomID"`
+	Sku           string `json:"sku,omitempty" v1:"Sku"`
+	Name          string `json:"name,omitempty" v1:"Name"`
+	Status        string `json:"status,omitempty" v1:"Status"`
+	OnHand        int64  `json:"on_hand" v1:"OnHand"`
+}
+
+func newScanResponse(r models.ResolveScanRow) ScanResponse {
+	return ScanResponse{
+		Kind:          r.Kind,
+		ID:            r.ID.Int64,
+		WarehouseID:   r.WarehouseID.Int64,
+		StorageRoomID: r.StorageRoomID.Int32,
+		Sku:           r.Sku,
+		Name:          r.Name,
+		Status:        r.Status,
+		OnHand:        r.OnHand,
+	}
+}
+
+// scanError rejects a scan action with a status other than a database
+// error's
+type scanError struct {
+	status  int
+	message string
+}
+
+func (e *scanError) Error() string {
+	return e.message
+}
+
+// scanLocation looks up the storage room of a location code
+func (h *Handlers) scanLocation(ctx context.Context, qtx *models.Queries, orgID, code string) (models.GetStorageRoomLabelByLocationRow, error) {
+	warehouseID, number, err := parseLocationCode(code)
+	if err != nil {
+		return models.GetStorageRoomLabelByLocationRow{}, &scanError{http.StatusBadRequest, err.Error()}
+	}
+	dbStart := time.Now()
+	room, err := qtx.GetStorageRoomLabelByLocation(ctx, models.GetStorageRoomLabelByLocationParams{
+		WarehouseID: warehouseID,
+		Number:      number,
+		OrgID:       orgID,
+	})
+	h.recordDBOperation(ctx, "get", "storage_room", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return room, &scanError{http.StatusNotFound, fmt.Sprintf("Location %s not found", code)}
+	}
+	return room, err
+}
+
+// scanPickLine finds the line of a pick list a scanned SKU is picked for:
+// the first one not picked in full, of the storage room when room is not
+// zero. It returns the line's picked quantity after qty more units.
+func scanPickLine(lines []models.PickListLine, sku string, room, qty int32) (models.PickListLine, int32, error) {
+	found := false
+	for _, line := range lines {
+		if line.Sku != sku || (room != 0 && line.StorageRoomID != room) {
+			continue
+		}
+		found = true
+		if line.PickedQuantity >= line.Quantity {
+			continue
+		}
+		if picked := line.PickedQuantity + qty; picked <= line.Quantity {
+			return line, picked, nil
+		}
+		return line, 0, fmt.Errorf("line %d has only %d units left to pick", line.ID, line.Quantity-line.PickedQuantity)
+	}
+	if found {
+		return models.PickListLine{}, 0, fmt.Errorf("%s is already picked in full", sku)
+	}
+	return models.PickListLine{}, 0, fmt.Errorf("%s is not on this pick list here", sku)
+}
+
+// respondScanError answers a failed scan action. Stock levels that would go
+// below zero are a conflict, a handheld shows the message to the operator.
+func (h *Handlers) respondScanError(ctx *gin.Context, orgID, operation string, opStart time.Time, err error) {
+	var scanErr *scanError
+	if errors.As(err, &scanErr) {
+		ctx.JSON(scanErr.status, gin.H{
+			"error": tr(ctx, scanErr.message),
+		})
+		return
+	}
+	var requestErr *serialRequestError
+	if errors.As(err, &requestErr) {
+		ctx.JSON(requestErr.status, gin.H{
+			"error": requestErr.message,
+		})
+		return
+	}
+	if exceeded, ok := capacityExceeded(err); ok {
+		respondCapacityExceeded(ctx, exceeded)
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) || isCheckViolation(err) {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": tr(ctx, "Not enough stock at the location"),
+		})
+		return
+	}
+	slog.Error("Could not "+operation+" scan: ", slog.Any("err", err.Error()))
+	h.recordOperation(orgID, observability.EntityScan, operation, opStart, err)
+	ctx.JSON(dbErrorStatus(err), gin.H{
+		"error": fmt.Sprintf("Failed to %s", operation),
+	})
+}
+
+// scanTransaction runs a scan action in a transaction and answers with what
+// it returns
+func (h *Handlers) scanTransaction(ctx *gin.Context, operation string, fn func(spanCtx context.Context, qtx *models.Queries, orgID string) (any, error)) {
+	opStart := time.Now()
+	name := "Scan " + strings.ToUpper(operation[:1]) + operation[1:]
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), strings.ReplaceAll(name, " ", ""))
+	defer span.End()
+
+	orgID := tenantID(ctx)
+	span.SetAttributes(attribute.String("tenant.id", orgID))
+
+	tx, err := h.db.Begin(spanCtx)
+	if err != nil {
+		slog.Error("Failed to start transaction", slog.Any("err", err.Error()))
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to start transaction"),
+		})
+		return
+	}
+	defer tx.Rollback(spanCtx) // This will be ignored if tx.Commit() succeeds
+
+	data, err := fn(spanCtx, h.queries.WithTx(tx), orgID)
+	if err != nil {
+		span.RecordError(err)
+		h.respondScanError(ctx, orgID, operation, opStart, err)
+		return
+	}
+
+	if err := tx.Commit(spanCtx); err != nil {
+		slog.Error("Failed to commit transaction", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to commit transaction"),
+		})
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntityScan, operation, opStart, nil)
+
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": name + " Successfully",
+		"data":    data,
+	})
+}
+
+// ResolveScan resolves a scanned code to the location, serial or item it
+// stands for in one round trip. Location codes take precedence over serial
+// numbers, serial numbers over SKUs.
+func (h *Handlers) ResolveScan(ctx *gin.Context) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "ResolveScan")
+	defer span.End()
+
+	code := ctx.Param("code")
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.String("scan.code", code),
+		attribute.String("tenant.id", orgID),
+	)
+
+	params := models.ResolveScanParams{
+		OrgID: orgID,
+		Code:  code,
+	}
+	// Codes that aren't location codes just skip the location lookup
+	if warehouseID, number, err := parseLocationCode(code); err == nil {
+		params.WarehouseID = pgtype.Int4{Int32: warehouseID, Valid: true}
+		params.Number = pgtype.Text{String: number, Valid: true}
+	}
+
+	dbStart := time.Now()
+	match, err := h.readQueries(spanCtx).ResolveScan(spanCtx, params)
+	h.recordDBOperation(spanCtx, "get", "scan", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		h.recordOperation(orgID, observability.EntityScan, "resolve", opStart, err)
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Nothing matches the scanned code"),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Got an error while resolving scan: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		h.recordOperation(orgID, observability.EntityScan, "resolve", opStart, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to resolve scan"),
+		})
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntityScan, "resolve", opStart, nil)
+	span.SetAttributes(
+		attribute.String("scan.kind", match.Kind),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Resolve Scan Successfully"),
+		"data":    newScanResponse(match),
+	})
+}
+
+// ScanMove moves what was scanned to the location to. A serial number moves
+// that unit, checked to be at from when it is given. Any other code is a
+// SKU, qty units of it move from from, which is then required.
+func (h *Handlers) ScanMove(ctx *gin.Context) {
+	var req scanMoveRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   tr(ctx, "Invalid scan move payload"),
+			"details": err.Error(),
+		})
+		return
+	}
+
+	actor := actorID(ctx)
+	h.scanTransaction(ctx, "move", func(spanCtx context.Context, qtx *models.Queries, orgID string) (any, error) {
+		to, err := h.scanLocation(spanCtx, qtx, orgID, req.To)
+		if err != nil {
+			return nil, err
+		}
+		var from models.GetStorageRoomLabelByLocationRow
+		if req.From != "" {
+			if from, err = h.scanLocation(spanCtx, qtx, orgID, req.From); err != nil {
+				return nil, err
+			}
+		}
+
+		dbStart := time.Now()
+		serial, err := qtx.GetSerialByNumber(spanCtx, models.GetSerialByNumberParams{
+			OrgID:        orgID,
+			SerialNumber: req.Code,
+		})
+		h.recordDBOperation(spanCtx, "get", "serial", dbStart, err)
+		if err == nil {
+			if req.From != "" && serial.StorageRoomID.Int32 != from.ID {
+				return nil, &scanError{http.StatusConflict, fmt.Sprintf("Serial %s is not at %s", req.Code, req.From)}
+			}
+			serials, err := h.moveSerials(spanCtx, qtx, orgID, moveSerialsRequest{
+				StorageRoomID: to.ID,
+				SerialNumbers: []string{req.Code},
+				Reference:     scanReference,
+			}, actor)
+			if err != nil {
+				return nil, err
+			}
+			// A serial already at the location is not moved
+			if len(serials) > 0 {
+				serial = serials[0]
+			}
+			return ScanResponse{
+				Kind:          scanKindSerial,
+				ID:            serial.ID,
+				WarehouseID:   int64(to.WarehouseID),
+				StorageRoomID: to.ID,
+				Sku:           serial.Sku,
+				Status:        serial.Status,
+				OnHand:        1,
+			}, nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+
+		if req.From == "" {
+			return nil, &scanError{http.StatusBadRequest, "from is required to move stock of a SKU"}
+		}
+		if from.ID == to.ID {
+			return nil, &scanError{http.StatusBadRequest, "from and to are the same location"}
+		}
+		qty := scanQuantity(req.Qty)
+		source, err := h.adjustStock(spanCtx, qtx, stockAdjustment{
+			OrgID:         orgID,
+			StorageRoomID: from.ID,
+			Sku:           req.Code,
+			Delta:         -qty,
+			Reason:        adjustmentReasonTransfer,
+			Reference:     scanReference,
+		})
+		if err != nil {
+			return nil, err
+		}
+		// The stock keeps its expiry in the room it moves to
+		level, err := h.adjustStock(spanCtx, qtx, stockAdjustment{
+			OrgID:         orgID,
+			StorageRoomID: to.ID,
+			Sku:           req.Code,
+			Delta:         qty,
+			Reason:        adjustmentReasonTransfer,
+			Reference:     scanReference,
+			ExpiresAt:     source.ExpiresAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return ScanResponse{
+			Kind:          scanKindItem,
+			WarehouseID:   int64(to.WarehouseID),
+			StorageRoomID: to.ID,
+			Sku:           level.Sku,
+			OnHand:        int64(level.Quantity),
+		}, nil
+	})
+}
+
+// ScanCount records the counted quantity of a SKU at a location on the open
+// count covering it, the warehouse's or the storage room's
+func (h *Handlers) ScanCount(ctx *gin.Context) {
+	var req scanCountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   tr(ctx, "Invalid scan count payload"),
+			"details": err.Error(),
+		})
+		return
+	}
+
+	h.scanTransaction(ctx, "count", func(spanCtx context.Context, qtx *models.Queries, orgID string) (any, error) {
+		room, err := h.scanLocation(spanCtx, qtx, orgID, req.Location)
+		if err != nil {
+			return nil, err
+		}
+
+		dbStart := time.Now()
+		session, err := qtx.GetOpenCountSessionForRoom(spanCtx, models.GetOpenCountSessionForRoomParams{
+			OrgID:         orgID,
+			WarehouseID:   int64(room.WarehouseID),
+			StorageRoomID: room.ID,
+		})
+		h.recordDBOperation(spanCtx, "get", "count_session", dbStart, err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, &scanError{http.StatusConflict, fmt.Sprintf("No open count covers %s", req.Location)}
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		dbStart = time.Now()
+		line, err := qtx.RecordCountLine(spanCtx, models.RecordCountLineParams{
+			CountSessionID:  session.ID,
+			StorageRoomID:   room.ID,
+			Sku:             req.Code,
+			CountedQuantity: pgtype.Int4{Int32: *req.Qty, Valid: true},
+		})
+		h.recordDBOperation(spanCtx, "upsert", "count_line", dbStart, err)
+		if err != nil {
+			return nil, err
+		}
+		return newCountLineResponse(line), nil
+	})
+}
+
+// ScanPick confirms qty units of a scanned SKU on a pick list, on its line
+// for the scanned location when one is given. The pick list moves to picked
+// once every line is picked in full.
+func (h *Handlers) ScanPick(ctx *gin.Context) {
+	var req scanPickRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   tr(ctx, "Invalid scan pick payload"),
+			"details": err.Error(),
+		})
+		return
+	}
+
+	orgID := tenantID(ctx)
+	h.pickListTransition(ctx, "pick", []string{pickListStatusAllocated},
+		func(spanCtx context.Context, qtx *models.Queries, pickList models.PickList, lines []models.PickListLine) (string, int, error) {
+			var room int32
+			if req.Location != "" {
+				location, err := h.scanLocation(spanCtx, qtx, orgID, req.Location)
+				var scanErr *scanError
+				if errors.As(err, &scanErr) {
+					return "", scanErr.status, err
+				}
+				if err != nil {
+					return "", 0, err
+				}
+				room = location.ID
+			}
+
+			line, picked, err := scanPickLine(lines, req.Code, room, scanQuantity(req.Qty))
+			if err != nil {
+				return "", http.StatusConflict, err
+			}
+
+			dbStart := time.Now()
+			_, err = qtx.ConfirmPickListLine(spanCtx, models.ConfirmPickListLineParams{
+				ID:             line.ID,
+				PickListID:     pickList.ID,
+				PickedQuantity: picked,
+			})
+			h.recordDBOperation(spanCtx, "update", "pick_list_line", dbStart, err)
+			if err != nil {
+				return "", 0, err
+			}
+
+			for _, l := range lines {
+				if l.ID != line.ID && l.PickedQuantity < l.Quantity {
+					return pickList.Status, 0, nil
+				}
+			}
+			if picked < line.Quantity {
+				return pickList.Status, 0, nil
+			}
+			return pickListStatusPicked, 0, nil
+		})
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"testing"
+	models "warehouse-service/models/sqlc"
+)
+
+func TestScanPickLine(t *testing.T) {
+	lines := []models.PickListLine{
+		{ID: 1, Sku: "A", StorageRoomID: 10, Quantity: 2, PickedQuantity: 2},
+		{ID: 2, Sku: "A", StorageRoomID: 11, Quantity: 3, PickedQuantity: 1},
+		{ID: 3, Sku: "B", StorageRoomID: 10, Quantity: 1},
+	}
+	cases := []struct {
+		name       string
+		sku        string
+		room       int32
+		qty        int32
+		wantLine   int64
+		wantPicked int32
+		wantErr    bool
+	}{
+		{"skips picked lines", "A", 0, 1, 2, 2, false},
+		{"up to the quantity", "A", 0, 2, 2, 3, false},
+		{"over the quantity", "A", 0, 3, 0, 0, true},
+		{"of the room", "A", 11, 1, 2, 2, false},
+		{"room picked in full", "A", 10, 1, 0, 0, true},
+		{"not on the list", "C", 0, 1, 0, 0, true},
+		{"not in the room", "B", 11, 1, 0, 0, true},
+	}
+	for _, c := range cases {
+		line, picked, err := scanPickLine(lines, c.sku, c.room, c.qty)
+		if (err != nil) != c.wantErr {
+			t.Errorf("%s: err %v", c.name, err)
+			continue
+		}
+		if err == nil && (line.ID != c.wantLine || picked != c.wantPicked) {
+			t.Errorf("%s: line %d picked %d, want %d and %d", c.name, line.ID, picked, c.wantLine, c.wantPicked)
+		}
+	}
+}
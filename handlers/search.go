@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+	models "warehouse-service/models/sqlc"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Result kinds returned by search, also accepted by the type filter
+const (
+	searchKindWarehouse   = "warehouse"
+	searchKindStorageRoom = "storage_room"
+)
+
+// Search finds warehouses and storage rooms of the tenant matching ?q= using
+// full-text and trigram similarity, best matches first. ?type= narrows the
+// results to a comma separated list of kinds.
+func (h *Handlers) Search(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "Search")
+	defer span.End()
+
+	query := strings.TrimSpace(ctx.Query("q"))
+	if query == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "Query parameter q is required"),
+		})
+		return
+	}
+	limit, offset, err := pageParams(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	includeWarehouses, includeStorageRooms := true, true
+	if types := ctx.Query("type"); types != "" {
+		includeWarehouses, includeStorageRooms = false, false
+		for _, kind := range strings.Split(types, ",") {
+			switch strings.TrimSpace(kind) {
+			case searchKindWarehouse:
+				includeWarehouses = true
+			case searchKindStorageRoom:
+				includeStorageRooms = true
+			default:
+				ctx.JSON(http.StatusBadRequest, gin.H{
+					"error": tr(ctx, "Unknown search type %s", kind),
+				})
+				return
+			}
+		}
+	}
+
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.String("search.query", query),
+		attribute.Bool("search.warehouses", includeWarehouses),
+		attribute.Bool("search.storage_rooms", includeStorageRooms),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	results, err := h.readQueries(spanCtx).SearchInventory(spanCtx, models.SearchInventoryParams{
+		Query:               query,
+		OrgID:               orgID,
+		IncludeWarehouses:   includeWarehouses,
+		IncludeStorageRooms: includeStorageRooms,
+		PageLimit:           limit,
+		PageOffset:          offset,
+	})
+	h.recordDBOperation(spanCtx, "search", "warehouse", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while searching: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to search"),
+		})
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int("search.count", len(results)),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Search Successfully"),
+		"data":    mapSlice(results, newSearchResultResponse),
+		"limit":   limit,
+		"offset":  offset,
+	})
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+	"warehouse-service/fixtures"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type seedRequest struct {
+	Set string `json:"set"`
+}
+
+// SeedFixtures loads an embedded fixture set into the caller's
+// organization. The route is only registered when SEED_ENDPOINT_ENABLED is
+// set, which config validation refuses in production. Seeded rows bypass
+// the audit log.
+func (h *Handlers) SeedFixtures(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "SeedFixtures")
+	defer span.End()
+
+	req := seedRequest{Set: "demo"}
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error":   tr(ctx, "Invalid request body"),
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.String("fixtures.set", req.Set),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	result, err := fixtures.Load(spanCtx, h.db, orgID, req.Set)
+	h.recordDBOperation(spanCtx, "seed", "warehouse", dbStart, err)
+	if errors.Is(err, fixtures.ErrUnknownSet) {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   tr(ctx, "Unknown fixture set"),
+			"details": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, fixtures.ErrIDTaken) {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error":   tr(ctx, "Fixture set is already loaded into another organization"),
+			"details": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to seed fixtures: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to seed fixtures"),
+		})
+		return
+	}
+
+	slog.Info("Seeded fixtures",
+		slog.String("set", result.Set),
+		slog.String("org_id", orgID),
+		slog.Int("warehouses", result.Warehouses),
+		slog.Int("storage_rooms", result.StorageRooms),
+		slog.Int("stock_levels", result.StockLevels),
+	)
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": tr(ctx, "Seed Fixtures Successfully"),
+		"data":    result,
+	})
+}
@@ -0,0 +1,525 @@
+package handlers
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Serial states
+const (
+	serialStatusInStock = "in_stock"
+	serialStatusShipped = "shipped"
+)
+
+// Serial movement actions
+const (
+	serialActionReceive = "receive"
+	serialActionMove    = "move"
+	serialActionShip    = "ship"
+)
+
+type receiveSerialsRequest struct {
+	StorageRoomID int32    `json:"storage_room_id" binding:"required"`
+	Sku           string   `json:"sku" binding:"required"`
+	SerialNumbers []string `json:"serial_numbers" binding:"required,min=1,max=1000,dive,required,max=128"`
+	Reference     string   `json:"reference"`
+	// Put the stock away even where it takes the room over its capacity
+	OverrideCapacity bool `json:"override_capacity"`
+}
+
+type moveSerialsRequest struct {
+	StorageRoomID    int32    `json:"storage_room_id" binding:"required"`
+	SerialNumbers    []string `json:"serial_numbers" binding:"required,min=1,max=1000,dive,required,max=128"`
+	Reference        string   `json:"reference"`
+	OverrideCapacity bool     `json:"override_capacity"`
+}
+
+type shipSerialsRequest struct {
+	SerialNumbers []string `json:"serial_numbers" binding:"required,min=1,max=1000,dive,required,max=128"`
+	Reference     string   `json:"reference"`
+}
+
+// duplicateSerial returns a serial number given more than once, empty when
+// they are all distinct
+func duplicateSerial(serialNumbers []string) string {
+	seen := make(map[string]bool, len(serialNumbers))
+	for _, sn := range serialNumbers {
+		if seen[sn] {
+			return sn
+		}
+		seen[sn] = true
+	}
+	return ""
+}
+
+// serialStock is the stock of a SKU in a storage room some serials move
+// into or out of
+type serialStock struct {
+	StorageRoomID int32
+	Sku           string
+}
+
+// serialStockDeltas counts the serials per storage room and SKU, sorted so
+// concurrent requests lock the stock levels in the same order
+func serialStockDeltas(serials []models.Serial) ([]serialStock, map[serialStock]int32) {
+	counts := map[serialStock]int32{}
+	var keys []serialStock
+	for _, s := range serials {
+		key := serialStock{StorageRoomID: s.StorageRoomID.Int32, Sku: s.Sku}
+		if counts[key] == 0 {
+			keys = append(keys, key)
+		}
+		counts[key]++
+	}
+	slices.SortFunc(keys, func(a, b serialStock) int {
+		return cmp.Or(cmp.Compare(a.StorageRoomID, b.StorageRoomID), strings.Compare(a.Sku, b.Sku))
+	})
+	return keys, counts
+}
+
+// serialRequestError is a request the serials can't satisfy, answered with
+// status and the serial numbers at fault
+type serialRequestError struct {
+	status        int
+	message       string
+	serialNumbers []string
+}
+
+func (e *serialRequestError) Error() string {
+	return e.message
+}
+
+// lockInStockSerials locks the serials of a move or shipment. All of them
+// must exist and be in stock.
+func (h *Handlers) lockInStockSerials(ctx context.Context, qtx *models.Queries, orgID string, serialNumbers []string) ([]models.Serial, error) {
+	dbStart := time.Now()
+	serials, err := qtx.LockSerials(ctx, models.LockSerialsParams{
+		OrgID:         orgID,
+		SerialNumbers: serialNumbers,
+	})
+	h.recordDBOperation(ctx, "get", "serial", dbStart, err)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[string]bool, len(serials))
+	var shipped []string
+	for _, s := range serials {
+		found[s.SerialNumber] = true
+		if s.Status != serialStatusInStock {
+			shipped = append(shipped, s.SerialNumber)
+		}
+	}
+	var missing []string
+	for _, sn := range serialNumbers {
+		if !found[sn] {
+			missing = append(missing, sn)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, &serialRequestError{http.StatusNotFound, "Serial numbers not found", missing}
+	}
+	if len(shipped) > 0 {
+		return nil, &serialRequestError{http.StatusConflict, "Serial numbers are not in stock", shipped}
+	}
+	return serials, nil
+}
+
+// relocateSerial moves a serial and records the movement in its history
+func (h *Handlers) relocateSerial(ctx context.Context, qtx *models.Queries, serial models.Serial, action string, to pgtype.Int4, status, reference, actor string) (models.Serial, error) {
+	from := serial.StorageRoomID
+	dbStart := time.Now()
+	serial, err := qtx.UpdateSerialLocation(ctx, models.UpdateSerialLocationParams{
+		ID:            serial.ID,
+		StorageRoomID: to,
+		Status:        status,
+	})
+	h.recordDBOperation(ctx, "update", "serial", dbStart, err)
+	if err != nil {
+		return models.Serial{}, err
+	}
+	return serial, h.recordSerialMovement(ctx, qtx, serial.ID, action, from, to, reference, actor)
+}
+
+func (h *Handlers) recordSerialMovement(ctx context.Context, qtx *models.Queries, serialID int64, action string, from, to pgtype.Int4, reference, actor string) error {
+	dbStart := time.Now()
+	err := qtx.CreateSerialMovement(ctx, models.CreateSerialMovementParams{
+		SerialID:          serialID,
+		Action:            action,
+		FromStorageRoomID: from,
+		ToStorageRoomID:   to,
+		Reference:         reference,
+		Actor:             actor,
+	})
+	h.recordDBOperation(ctx, "create", "serial_movement", dbStart, err)
+	return err
+}
+
+// respondSerialError answers a failed serial operation. Stock levels below
+// the serials, e.g. after stock of the SKU left without them, are a
+// conflict rather than a missing row.
+func (h *Handlers) respondSerialError(ctx *gin.Context, orgID, operation string, opStart time.Time, err error) {
+	var requestErr *serialRequestError
+	if errors.As(err, &requestErr) {
+		ctx.JSON(requestErr.status, gin.H{
+			"error":          requestErr.message,
+			"serial_numbers": requestErr.serialNumbers,
+		})
+		return
+	}
+	if exceeded, ok := capacityExceeded(err); ok {
+		respondCapacityExceeded(ctx, exceeded)
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) || isCheckViolation(err) {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": tr(ctx, "Stock levels hold fewer units than the serials"),
+		})
+		return
+	}
+	if conflict, ok := conflictFor(err); ok {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": tr(ctx, conflict.message),
+			"field": conflict.field,
+		})
+		return
+	}
+	slog.Error("Could not "+operation+" serials: ", slog.Any("err", err.Error()))
+	h.recordOperation(orgID, observability.EntitySerial, operation, opStart, err)
+	ctx.JSON(dbErrorStatus(err), gin.H{
+		"error": fmt.Sprintf("Failed to %s serials", operation),
+	})
+}
+
+// serialTransaction runs fn in a transaction and answers with the serials
+// it returns. action is the serial movement, e.g. receive.
+func (h *Handlers) serialTransaction(ctx *gin.Context, action string, status int, fn func(spanCtx context.Context, qtx *models.Queries, orgID string) ([]models.Serial, error)) {
+	opStart := time.Now()
+	name := strings.ToUpper(action[:1]) + action[1:] + " Serials"
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), strings.ReplaceAll(name, " ", ""))
+	defer span.End()
+
+	orgID := tenantID(ctx)
+	span.SetAttributes(attribute.String("tenant.id", orgID))
+
+	tx, err := h.db.Begin(spanCtx)
+	if err != nil {
+		slog.Error("Failed to start transaction", slog.Any("err", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": tr(ctx, "Failed to start transaction"),
+		})
+		return
+	}
+	defer tx.Rollback(spanCtx) // This will be ignored if tx.Commit() succeeds
+
+	serials, err := fn(spanCtx, h.queries.WithTx(tx), orgID)
+	if err != nil {
+		span.RecordError(err)
+		h.respondSerialError(ctx, orgID, action, opStart, err)
+		return
+	}
+
+	if err := tx.Commit(spanCtx); err != nil {
+		slog.Error("Failed to commit transaction", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to commit transaction"),
+		})
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntitySerial, action, opStart, nil)
+
+	span.SetAttributes(
+		attribute.Int("serial.count", len(serials)),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, status, gin.H{
+		"message": name + " Successfully",
+		"data":    mapSlice(serials, newSerialResponse),
+	})
+}
+
+// storageRoomOfOrg checks a storage room of the request exists
+func (h *Handlers) storageRoomOfOrg(ctx context.Context, qtx *models.Queries, orgID string, id int32) error {
+	dbStart := time.Now()
+	_, err := qtx.GetStorageRoom(ctx, models.GetStorageRoomParams{
+		ID:    id,
+		OrgID: orgID,
+	})
+	h.recordDBOperation(ctx, "get", "storage_room", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &serialRequestError{status: http.StatusNotFound, message: fmt.Sprintf("Storage room %d not found", id)}
+	}
+	return err
+}
+
+// ReceiveSerials puts serialized units of a SKU into a storage room and
+// adds them to its stock. Shipped serials can be received again, e.g. as
+// returns.
+func (h *Handlers) ReceiveSerials(ctx *gin.Context) {
+	var req receiveSerialsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   tr(ctx, "Invalid serials payload"),
+			"details": err.Error(),
+		})
+		return
+	}
+	if sn := duplicateSerial(req.SerialNumbers); sn != "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "Serial number %s is given more than once", sn),
+		})
+		return
+	}
+	actor := actorID(ctx)
+	h.serialTransaction(ctx, serialActionReceive, http.StatusCreated, func(spanCtx context.Context, qtx *models.Queries, orgID string) ([]models.Serial, error) {
+		if err := h.storageRoomOfOrg(spanCtx, qtx, orgID, req.StorageRoomID); err != nil {
+			return nil, err
+		}
+
+		dbStart := time.Now()
+		existing, err := qtx.LockSerials(spanCtx, models.LockSerialsParams{
+			OrgID:         orgID,
+			SerialNumbers: req.SerialNumbers,
+		})
+		h.recordDBOperation(spanCtx, "get", "serial", dbStart, err)
+		if err != nil {
+			return nil, err
+		}
+		returned := make(map[string]models.Serial, len(existing))
+		var taken []string
+		for _, s := range existing {
+			if s.Status != serialStatusShipped || s.Sku != req.Sku {
+				taken = append(taken, s.SerialNumber)
+			}
+			returned[s.SerialNumber] = s
+		}
+		if len(taken) > 0 {
+			return nil, &serialRequestError{http.StatusConflict, "Serial numbers are already in use", taken}
+		}
+
+		if _, err := h.adjustStock(spanCtx, qtx, stockAdjustment{
+			OrgID:            orgID,
+			StorageRoomID:    req.StorageRoomID,
+			Sku:              req.Sku,
+			Delta:            int32(len(req.SerialNumbers)),
+			Reason:           adjustmentReasonReceipt,
+			Reference:        req.Reference,
+			OverrideCapacity: req.OverrideCapacity,
+		}); err != nil {
+			return nil, err
+		}
+
+		room := pgtype.Int4{Int32: req.StorageRoomID, Valid: true}
+		serials := make([]models.Serial, 0, len(req.SerialNumbers))
+		for _, sn := range req.SerialNumbers {
+			if s, ok := returned[sn]; ok {
+				serial, err := h.relocateSerial(spanCtx, qtx, s, serialActionReceive, room, serialStatusInStock, req.Reference, actor)
+				if err != nil {
+					return nil, err
+				}
+				serials = append(serials, serial)
+				continue
+			}
+
+			dbStart = time.Now()
+			serial, err := qtx.CreateSerial(spanCtx, models.CreateSerialParams{
+				OrgID:         orgID,
+				SerialNumber:  sn,
+				Sku:           req.Sku,
+				StorageRoomID: room,
+			})
+			h.recordDBOperation(spanCtx, "create", "serial", dbStart, err)
+			if err == nil {
+				err = h.recordSerialMovement(spanCtx, qtx, serial.ID, serialActionReceive, pgtype.Int4{}, room, req.Reference, actor)
+			}
+			if err != nil {
+				return nil, err
+			}
+			serials = append(serials, serial)
+		}
+		return serials, nil
+	})
+}
+
+// MoveSerials moves serials to another storage room, the stock of their
+// SKUs moves along
+func (h *Handlers) MoveSerials(ctx *gin.Context) {
+	var req moveSerialsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   tr(ctx, "Invalid serials payload"),
+			"details": err.Error(),
+		})
+		return
+	}
+	if sn := duplicateSerial(req.SerialNumbers); sn != "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "Serial number %s is given more than once", sn),
+		})
+		return
+	}
+	actor := actorID(ctx)
+	h.serialTransaction(ctx, serialActionMove, http.StatusOK, func(spanCtx context.Context, qtx *models.Queries, orgID string) ([]models.Serial, error) {
+		return h.moveSerials(spanCtx, qtx, orgID, req, actor)
+	})
+}
+
+// moveSerials moves serials and the stock of their SKUs to another storage
+// room. Serials already in the room stay where they are.
+func (h *Handlers) moveSerials(ctx context.Context, qtx *models.Queries, orgID string, req moveSerialsRequest, actor string) ([]models.Serial, error) {
+	if err := h.storageRoomOfOrg(ctx, qtx, orgID, req.StorageRoomID); err != nil {
+		return nil, err
+	}
+	serials, err := h.lockInStockSerials(ctx, qtx, orgID, req.SerialNumbers)
+	if err != nil {
+		return nil, err
+	}
+	serials = slices.DeleteFunc(serials, func(s models.Serial) bool {
+		return s.StorageRoomID.Int32 == req.StorageRoomID
+	})
+
+	keys, counts := serialStockDeltas(serials)
+	for _, key := range keys {
+		if _, err := h.adjustStock(ctx, qtx, stockAdjustment{
+			OrgID:         orgID,
+			StorageRoomID: key.StorageRoomID,
+			Sku:           key.Sku,
+			Delta:         -counts[key],
+			Reason:        adjustmentReasonTransfer,
+			Reference:     req.Reference,
+		}); err != nil {
+			return nil, err
+		}
+		if _, err := h.adjustStock(ctx, qtx, stockAdjustment{
+			OrgID:            orgID,
+			StorageRoomID:    req.StorageRoomID,
+			Sku:              key.Sku,
+			Delta:            counts[key],
+			Reason:           adjustmentReasonTransfer,
+			Reference:        req.Reference,
+			OverrideCapacity: req.OverrideCapacity,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	room := pgtype.Int4{Int32: req.StorageRoomID, Valid: true}
+	for i, s := range serials {
+		if serials[i], err = h.relocateSerial(ctx, qtx, s, serialActionMove, room, serialStatusInStock, req.Reference, actor); err != nil {
+			return nil, err
+		}
+	}
+	return serials, nil
+}
+
+// ShipSerials ships serials, they leave the stock of their storage rooms
+func (h *Handlers) ShipSerials(ctx *gin.Context) {
+	var req shipSerialsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   tr(ctx, "Invalid serials payload"),
+			"details": err.Error(),
+		})
+		return
+	}
+	if sn := duplicateSerial(req.SerialNumbers); sn != "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "Serial number %s is given more than once", sn),
+		})
+		return
+	}
+	actor := actorID(ctx)
+	h.serialTransaction(ctx, serialActionShip, http.StatusOK, func(spanCtx context.Context, qtx *models.Queries, orgID string) ([]models.Serial, error) {
+		serials, err := h.lockInStockSerials(spanCtx, qtx, orgID, req.SerialNumbers)
+		if err != nil {
+			return nil, err
+		}
+
+		keys, counts := serialStockDeltas(serials)
+		for _, key := range keys {
+			if _, err := h.adjustStock(spanCtx, qtx, stockAdjustment{
+				OrgID:         orgID,
+				StorageRoomID: key.StorageRoomID,
+				Sku:           key.Sku,
+				Delta:         -counts[key],
+				Reason:        adjustmentReasonShipment,
+				Reference:     req.Reference,
+			}); err != nil {
+				return nil, err
+			}
+		}
+
+		for i, s := range serials {
+			if serials[i], err = h.relocateSerial(spanCtx, qtx, s, serialActionShip, pgtype.Int4{}, serialStatusShipped, req.Reference, actor); err != nil {
+				return nil, err
+			}
+		}
+		return serials, nil
+	})
+}
+
+// GetSerial returns a serial with its movements, oldest first
+func (h *Handlers) GetSerial(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "GetSerial")
+	defer span.End()
+
+	sn := ctx.Param("sn")
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.String("serial.number", sn),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	serial, err := h.queries.GetSerialByNumber(spanCtx, models.GetSerialByNumberParams{
+		OrgID:        orgID,
+		SerialNumber: sn,
+	})
+	h.recordDBOperation(spanCtx, "get", "serial", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Serial not found"),
+		})
+		return
+	}
+	var movements []models.SerialMovement
+	if err == nil {
+		dbStart = time.Now()
+		movements, err = h.queries.ListSerialMovements(spanCtx, serial.ID)
+		h.recordDBOperation(spanCtx, "list", "serial_movement", dbStart, err)
+	}
+	if err != nil {
+		slog.Error("Got an error while getting serial: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to get serial"),
+		})
+		return
+	}
+
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Get Serial Successfully"),
+		"data": gin.H{
+			"serial":    newSerialResponse(serial),
+			"movements": mapSlice(movements, newSerialMovementResponse),
+		},
+	})
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"testing"
+	models "warehouse-service/models/sqlc"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestDuplicateSerial(t *testing.T) {
+	if sn := duplicateSerial([]string{"A1", "A2", "A3"}); sn != "" {
+		t.Errorf("distinct serials reported %q", sn)
+	}
+	if sn := duplicateSerial([]string{"A1", "A2", "A1"}); sn != "A1" {
+		t.Errorf("duplicateSerial = %q, want A1", sn)
+	}
+}
+
+func TestSerialStockDeltas(t *testing.T) {
+	room := func(id int32) pgtype.Int4 { return pgtype.Int4{Int32: id, Valid: true} }
+	keys, counts := serialStockDeltas([]models.Serial{
+		{SerialNumber: "1", Sku: "TV", StorageRoomID: room(2)},
+		{SerialNumber: "2", Sku: "PHONE", StorageRoomID: room(2)},
+		{SerialNumber: "3", Sku: "TV", StorageRoomID: room(1)},
+		{SerialNumber: "4", Sku: "TV", StorageRoomID: room(2)},
+	})
+	want := []serialStock{{1, "TV"}, {2, "PHONE"}, {2, "TV"}}
+	if len(keys) != len(want) {
+		t.Fatalf("keys = %v, want %v", keys, want)
+	}
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Errorf("keys[%d] = %v, want %v", i, keys[i], want[i])
+		}
+	}
+	if counts[serialStock{2, "TV"}] != 2 || counts[serialStock{1, "TV"}] != 1 || counts[serialStock{2, "PHONE"}] != 1 {
+		t.Errorf("counts = %v", counts)
+	}
+}
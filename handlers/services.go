@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/services"
+)
+
+// Services are the clients of the sibling services. A nil client leaves
+// the saga steps calling it out.
+type Services struct {
+	Inventory services.Inventory
+	Orders    services.Orders
+}
+
+// Statuses of a sibling service on the operator status endpoint
+const (
+	serviceStatusServing  = "serving"
+	serviceStatusFailing  = "failing"
+	serviceStatusDisabled = "disabled"
+)
+
+// pickListServiceReference identifies a pick list to the sibling services,
+// the same reference its stock adjustments carry
+func pickListServiceReference(id int64) string {
+	return fmt.Sprintf("pick_list:%d", id)
+}
+
+// serviceLines sums the quantities of pick list lines by SKU, picked
+// quantities when picked is set, leaving out SKUs without any
+func serviceLines(lines []models.PickListLine, picked bool) []services.Line {
+	var out []services.Line
+	index := map[string]int{}
+	for _, line := range lines {
+		quantity := line.Quantity
+		if picked {
+			quantity = line.PickedQuantity
+		}
+		if quantity == 0 {
+			continue
+		}
+		i, ok := index[line.Sku]
+		if !ok {
+			i = len(out)
+			index[line.Sku] = i
+			out = append(out, services.Line{Sku: line.Sku})
+		}
+		out[i].Quantity += int64(quantity)
+	}
+	return out
+}
+
+// ServiceStatusResponse is the health of a sibling service, Error tells
+// why it is failing
+type ServiceStatusResponse struct {
+	Status string `json:"status" v1:"Status"`
+	Error  string `json:"error,omitempty" v1:"Error"`
+}
+
+// serviceStatus checks a sibling service, check is nil when it is not
+// configured
+func serviceStatus(ctx context.Context, check func(context.Context) error) ServiceStatusResponse {
+	if check == nil {
+		return ServiceStatusResponse{Status: serviceStatusDisabled}
+	}
+	if err := check(ctx); err != nil {
+		return ServiceStatusResponse{Status: serviceStatusFailing, Error: err.Error()}
+	}
+	return ServiceStatusResponse{Status: serviceStatusServing}
+}
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"testing"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/services"
+)
+
+func TestServiceLines(t *testing.T) {
+	lines := []models.PickListLine{
+		{Sku: "A", Quantity: 3, PickedQuantity: 3},
+		{Sku: "B", Quantity: 2, PickedQuantity: 0},
+		{Sku: "A", Quantity: 4, PickedQuantity: 1},
+	}
+	for _, c := range []struct {
+		picked bool
+		want   []services.Line
+	}{
+		{false, []services.Line{{Sku: "A", Quantity: 7}, {Sku: "B", Quantity: 2}}},
+		{true, []services.Line{{Sku: "A", Quantity: 4}}},
+	} {
+		got := serviceLines(lines, c.picked)
+		if len(got) != len(c.want) {
+			t.Fatalf("picked %v: got %+v", c.picked, got)
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Errorf("picked %v: line %d is %+v, want %+v", c.picked, i, got[i], c.want[i])
+			}
+		}
+	}
+}
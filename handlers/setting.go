@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+	models "warehouse-service/models/sqlc"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type tenantSettingRequest struct {
+	ValuationMethod string `json:"valuation_method" binding:"required,oneof=fifo weighted_average"`
+	// ISA ID the tenant sends EDI documents as, qualifier ZZ when left out
+	EdiQualifier string `json:"edi_qualifier" binding:"omitempty,len=2,alphanum"`
+	EdiID        string `json:"edi_id" binding:"omitempty,max=15"`
+}
+
+// TenantSettingResponse holds the settings of the tenant, UpdatedAt is nil
+// while it has the defaults
+type TenantSettingResponse struct {
+	ValuationMethod string     `json:"valuation_method" v1:"ValuationMethod"`
+	EdiQualifier    string     `json:"edi_qualifier" v1:"EdiQualifier"`
+	EdiID           string     `json:"edi_id" v1:"EdiID"`
+	UpdatedBy       string     `json:"updated_by" v1:"UpdatedBy"`
+	UpdatedAt       *time.Time `json:"updated_at" v1:"UpdatedAt"`
+}
+
+func newTenantSettingResponse(s models.TenantSetting) TenantSettingResponse {
+	return TenantSettingResponse{
+		ValuationMethod: s.ValuationMethod,
+		EdiQualifier:    s.EdiQualifier,
+		EdiID:           s.EdiID,
+		UpdatedBy:       s.UpdatedBy,
+		UpdatedAt:       timePtr(s.UpdatedAt),
+	}
+}
+
+// tenantSetting returns the settings of the tenant, the defaults when it
+// never changed them
+func (h *Handlers) tenantSetting(ctx context.Context, queries *models.Queries, orgID string) (models.TenantSetting, error) {
+	dbStart := time.Now()
+	setting, err := queries.GetTenantSetting(ctx, orgID)
+	h.recordDBOperation(ctx, "get", "tenant_setting", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.TenantSetting{OrgID: orgID, ValuationMethod: valuationFIFO, EdiQualifier: ediQualifierMutual}, nil
+	}
+	return setting, err
+}
+
+func (h *Handlers) GetTenantSettings(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "GetTenantSettings")
+	defer span.End()
+
+	orgID := tenantID(ctx)
+	span.SetAttributes(attribute.String("tenant.id", orgID))
+
+	setting, err := h.tenantSetting(spanCtx, h.queries, orgID)
+	if err != nil {
+		slog.Error("Got an error while getting tenant settings: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to get settings"),
+		})
+		return
+	}
+
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Get Settings Successfully"),
+		"data":    newTenantSettingResponse(setting),
+	})
+}
+
+// PutTenantSettings replaces the settings of the tenant
+func (h *Handlers) PutTenantSettings(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "PutTenantSettings")
+	defer span.End()
+
+	var req tenantSettingRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   tr(ctx, "Invalid settings payload"),
+			"details": err.Error(),
+		})
+		return
+	}
+	req.EdiQualifier = strings.ToUpper(req.EdiQualifier)
+	if req.EdiQualifier == "" {
+		req.EdiQualifier = ediQualifierMutual
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.String("tenant.id", orgID),
+		attribute.String("setting.valuation_method", req.ValuationMethod),
+	)
+
+	dbStart := time.Now()
+	setting, err := h.queries.UpsertTenantSetting(spanCtx, models.UpsertTenantSettingParams{
+		OrgID:           orgID,
+		ValuationMethod: req.ValuationMethod,
+		EdiQualifier:    req.EdiQualifier,
+		EdiID:           strings.TrimSpace(req.EdiID),
+		UpdatedBy:       actorID(ctx),
+	})
+	h.recordDBOperation(spanCtx, "upsert", "tenant_setting", dbStart, err)
+	if err != nil {
+		slog.Error("Could not store tenant settings: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to store settings"),
+		})
+		return
+	}
+
+	slog.Info("Tenant settings updated",
+		slog.String("tenant_id", orgID),
+		slog.String("valuation_method", setting.ValuationMethod),
+		slog.String("user_id", setting.UpdatedBy),
+	)
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Update Settings Successfully"),
+		"data":    newTenantSettingResponse(setting),
+	})
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+	"warehouse-service/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Reasons recorded on stock adjustments
+const (
+	adjustmentReasonReceipt  = "receipt"
+	adjustmentReasonShipment = "shipment"
+	// Posted variances from cycle counts
+	adjustmentReasonCycleCount = "cycle_count"
+	// Stock moved between storage rooms, e.g. with its serials
+	adjustmentReasonTransfer = "transfer"
+	// Shipped stock put back by a saga undoing a shipment
+	adjustmentReasonShipmentReversal = "shipment_reversal"
+)
+
+// stockAdjustment describes a change of on-hand quantity for a SKU in a storage room
+type stockAdjustment struct {
+	OrgID         string
+	StorageRoomID int32
+	Sku           string
+	Delta         int32
+	Reason        string
+	Reference     string
+	// Place the stock even when it takes the room over its capacity
+	OverrideCapacity bool
+	// Expiry of stock put in, for FEFO allocation; null when it doesn't
+	// expire or isn't known
+	ExpiresAt pgtype.Timestamptz
+}
+
+// capacityExceededError is returned when an adjustment would put more stock
+// in a storage room than its capacity
+type capacityExceededError struct {
+	StorageRoomID int32
+	Capacity      int32
+	Occupancy     int64
+}
+
+func (e *capacityExceededError) Error() string {
+	return fmt.Sprintf("storage room %d would hold %d units, over its capacity of %d", e.StorageRoomID, e.Occupancy, e.Capacity)
+}
+
+// capacityExceeded unwraps a capacityExceededError
+func capacityExceeded(err error) (*capacityExceededError, bool) {
+	var exceeded *capacityExceededError
+	ok := errors.As(err, &exceeded)
+	return exceeded, ok
+}
+
+// respondCapacityExceeded answers a placement that does not fit the storage
+// room. The client can retry with override_capacity.
+func respondCapacityExceeded(ctx *gin.Context, exceeded *capacityExceededError) {
+	ctx.JSON(http.StatusConflict, gin.H{
+		"error":           exceeded.Error(),
+		"storage_room_id": exceeded.StorageRoomID,
+		"capacity":        exceeded.Capacity,
+		"occupancy":       exceeded.Occupancy,
+	})
+}
+
+// adjustStock applies adj to the stock level and records it in the adjustment
+// ledger. qtx must be bound to the caller's transaction so both writes commit
+// or roll back together. Placing stock fails with a capacityExceededError
+// when the room would go over its capacity, unless adj overrides it.
+func (h *Handlers) adjustStock(ctx context.Context, qtx *models.Queries, adj stockAdjustment) (models.StockLevel, error) {
+	var level models.StockLevel
+	var err error
+	var capacity int32
+	if adj.Delta > 0 && !adj.OverrideCapacity {
+		// Locking the room serializes placements into it, so two of them
+		// can't both fit into the last free units
+		dbStart := time.Now()
+		capacity, err = qtx.LockStorageRoomCapacity(ctx, models.LockStorageRoomCapacityParams{
+			ID:    adj.StorageRoomID,
+			OrgID: adj.OrgID,
+		})
+		h.recordDBOperation(ctx, "get", "storage_room", dbStart, err)
+		if err != nil {
+			return models.StockLevel{}, err
+		}
+	}
+	dbStart := time.Now()
+	if adj.Delta >= 0 {
+		level, err = qtx.AdjustStockLevel(ctx, models.AdjustStockLevelParams{
+			OrgID:         adj.OrgID,
+			StorageRoomID: adj.StorageRoomID,
+			Sku:           adj.Sku,
+			Quantity:      adj.Delta,
+			ExpiresAt:     adj.ExpiresAt,
+		})
+		h.recordDBOperation(ctx, "upsert", "stock_level", dbStart, err)
+	} else {
+		// Decrements go through a plain UPDATE, the upsert would trip the
+		// non-negative CHECK on the proposed insert row
+		level, err = qtx.ApplyStockDelta(ctx, models.ApplyStockDeltaParams{
+			OrgID:         adj.OrgID,
+			StorageRoomID: adj.StorageRoomID,
+			Sku:           adj.Sku,
+			Quantity:      adj.Delta,
+		})
+		h.recordDBOperation(ctx, "update", "stock_level", dbStart, err)
+	}
+	if err != nil {
+		return models.StockLevel{}, err
+	}
+	if capacity > 0 {
+		dbStart = time.Now()
+		occupancy, err := qtx.GetStorageRoomOccupancy(ctx, models.GetStorageRoomOccupancyParams{
+			OrgID:         adj.OrgID,
+			StorageRoomID: adj.StorageRoomID,
+		})
+		h.recordDBOperation(ctx, "get", "stock_level", dbStart, err)
+		if err != nil {
+			return models.StockLevel{}, err
+		}
+		if occupancy > int64(capacity) {
+			return models.StockLevel{}, &capacityExceededError{
+				StorageRoomID: adj.StorageRoomID,
+				Capacity:      capacity,
+				Occupancy:     occupancy,
+			}
+		}
+	}
+
+	dbStart = time.Now()
+	_, err = qtx.CreateStockAdjustment(ctx, models.CreateStockAdjustmentParams{
+		OrgID:         adj.OrgID,
+		StorageRoomID: adj.StorageRoomID,
+		Sku:           adj.Sku,
+		QuantityDelta: adj.Delta,
+		Reason:        adj.Reason,
+		Reference:     adj.Reference,
+	})
+	h.recordDBOperation(ctx, "create", "stock_adjustment", dbStart, err)
+	if err != nil {
+		return models.StockLevel{}, err
+	}
+	tracing.StockAdjusted(trace.SpanFromContext(ctx), int64(adj.StorageRoomID), adj.Sku, int64(adj.Delta), int64(level.Quantity), adj.Reason)
+
+	return level, nil
+}
+
+// isCheckViolation reports whether err is a CHECK constraint violation, which
+// for stock levels means an adjustment would drive stock below zero.
+func isCheckViolation(err error) bool {
+	return observability.ClassifyDBError(err) == observability.DBErrCheckViolation
+}
@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"time"
+	"warehouse-service/changefeed"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	// Client messages a connection may send per second, with bursts of
+	// socketMessageBurst
+	socketMessageRate  = 5
+	socketMessageBurst = 10
+	socketMaxMessage   = 16 << 10
+	// Warehouses plus SKUs one connection may subscribe to
+	socketMaxSubscriptions = 500
+	socketWriteTimeout     = 10 * time.Second
+)
+
+// SocketRequest is a message from a /ws client. Action is "subscribe" or
+// "unsubscribe"; updates of a stock level are pushed when its warehouse or
+// its SKU is subscribed. Warehouses are named by ID or by slug.
+type SocketRequest struct {
+	Action       string         `json:"action"`
+	WarehouseIDs []WarehouseRef `json:"warehouse_ids"`
+	Skus         []string       `json:"skus"`
+}
+
+// SocketMessage is a message to a /ws client. Type is "subscriptions"
+// after every accepted request, "stock_level" for an update, "error" or
+// "heartbeat".
+type SocketMessage struct {
+	Type         string            `json:"type"`
+	WarehouseIDs []int32           `json:"warehouse_ids,omitempty"`
+	Skus         []string          `json:"skus,omitempty"`
+	StockLevel   *StockLevelUpdate `json:"stock_level,omitempty"`
+	Error        string            `json:"error,omitempty"`
+}
+
+// StockLevelUpdate is a changed stock level. Operation is insert, update
+// or delete; a deleted stock level has its last quantities.
+type StockLevelUpdate struct {
+	EventID           int64     `json:"event_id"`
+	Operation         string    `json:"operation"`
+	ID                int64     `json:"id"`
+	WarehouseID       int32     `json:"warehouse_id"`
+	StorageRoomID     int32     `json:"storage_room_id"`
+	Sku               string    `json:"sku"`
+	Quantity          int32     `json:"quantity"`
+	AllocatedQuantity int32     `json:"allocated_quantity"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+func stockLevelUpdate(e changefeed.Event) (StockLevelUpdate, error) {
+	update := StockLevelUpdate{
+		EventID:   e.ID,
+		Operation: e.Operation,
+		ID:        e.EntityID,
+		UpdatedAt: e.CreatedAt,
+	}
+	var data struct {
+		WarehouseID       *int32 `json:"warehouse_id"`
+		StorageRoomID     int32  `json:"storage_room_id"`
+		Sku               string `json:"sku"`
+		Quantity          int32  `json:"quantity"`
+		AllocatedQuantity int32  `json:"allocated_quantity"`
+	}
+	if err := json.Unmarshal(e.Data, &data); err != nil {
+		return update, err
+	}
+	if data.WarehouseID != nil {
+		update.WarehouseID = *data.WarehouseID
+	}
+	update.StorageRoomID = data.StorageRoomID
+	update.Sku = data.Sku
+	update.Quantity = data.Quantity
+	update.AllocatedQuantity = data.AllocatedQuantity
+	return update, nil
+}
+
+// socketSubscriptions are the warehouses and SKUs a connection follows
+type socketSubscriptions struct {
+	warehouses map[int32]bool
+	skus       map[string]bool
+}
+
+// apply subscribes to or unsubscribes from the warehouses and SKUs of req,
+// warehouses being the IDs of its WarehouseIDs
+func (s *socketSubscriptions) apply(req SocketRequest, warehouses []int32) error {
+	switch req.Action {
+	case "subscribe":
+		if len(s.warehouses)+len(s.skus)+len(warehouses)+len(req.Skus) > socketMaxSubscriptions {
+			return fmt.Errorf("at most %d warehouses and SKUs can be subscribed", socketMaxSubscriptions)
+		}
+		for _, id := range warehouses {
+			s.warehouses[id] = true
+		}
+		for _, sku := range req.Skus {
+			s.skus[sku] = true
+		}
+	case "unsubscribe":
+		for _, id := range warehouses {
+			delete(s.warehouses, id)
+		}
+		for _, sku := range req.Skus {
+			delete(s.skus, sku)
+		}
+	default:
+		return fmt.Errorf("unknown action %q, expected subscribe or unsubscribe", req.Action)
+	}
+	return nil
+}
+
+func (s *socketSubscriptions) match(update StockLevelUpdate) bool {
+	return s.warehouses[update.WarehouseID] || s.skus[update.Sku]
+}
+
+func (s *socketSubscriptions) message() SocketMessage {
+	msg := SocketMessage{Type: "subscriptions", WarehouseIDs: []int32{}, Skus: []string{}}
+	for id := range s.warehouses {
+		msg.WarehouseIDs = append(msg.WarehouseIDs, id)
+	}
+	for sku := range s.skus {
+		msg.Skus = append(msg.Skus, sku)
+	}
+	return msg
+}
+
+// socketWarehouses resolves the warehouses a socket request names among
+// those of orgID
+func (h *Handlers) socketWarehouses(ctx context.Context, orgID string, refs []WarehouseRef) ([]int32, error) {
+	if len(refs) > socketMaxSubscriptions {
+		return nil, fmt.Errorf("at most %d warehouses and SKUs can be subscribed", socketMaxSubscriptions)
+	}
+	ids := make([]int32, 0, len(refs))
+	for _, ref := range refs {
+		id, err := h.resolveWarehouse(ctx, h.queries, orgID, ref)
+		if errors.Is(err, pgx.ErrNoRows) || (err == nil && (id <= 0 || id > math.MaxInt32)) {
+			return nil, fmt.Errorf("warehouse %q not found", ref)
+		}
+		if err != nil {
+			slog.Error("Got an error while looking up a socket warehouse: ", slog.Any("err", err.Error()))
+			return nil, errors.New("failed to look up warehouses, request ignored")
+		}
+		ids = append(ids, int32(id))
+	}
+	return ids, nil
+}
+
+// tokenBucket allows rate events per second with bursts of burst
+type tokenBucket struct {
+	rate, burst, tokens float64
+	last                time.Time
+}
+
+func newTokenBucket(rate, burst float64) *tokenBucket {
+	return &tokenBucket{rate: rate, burst: burst, tokens: burst, last: time.Now()}
+}
+
+func (b *tokenBucket) allow(now time.Time) bool {
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// StockSocket upgrades to a WebSocket that pushes the tenant's stock level
+// changes for the warehouses and SKUs the client subscribes to with
+// SocketRequest messages. Messages beyond the per-connection rate limit are
+// answered with an error and ignored. Updates are live only, clients
+// reload the stock levels they show after connecting.
+func (h *Handlers) StockSocket(ctx *gin.Context) {
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "StockSocket")
+	defer span.End()
+	orgID := tenantID(ctx)
+	span.SetAttributes(attribute.String("tenant.id", orgID))
+
+	server := websocket.Server{
+		// Clients authenticate with a bearer token rather than cookies, so
+		// any origin may connect
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(conn *websocket.Conn) {
+			defer conn.Close()
+			conn.MaxPayloadBytes = socketMaxMessage
+			// Clear the deadline SERVER_READ_TIMEOUT left on the hijacked
+			// connection, clients may stay quiet between subscriptions
+			if err := conn.SetReadDeadline(time.Time{}); err != nil {
+				return
+			}
+			h.serveStockSocket(conn, orgID)
+		},
+	}
+	server.ServeHTTP(ctx.Writer, ctx.Request.WithContext(spanCtx))
+}
+
+func (h *Handlers) serveStockSocket(conn *websocket.Conn, orgID string) {
+	// Subscribe before reading requests so no update of an accepted
+	// subscription is missed
+	sub := h.changes.Subscribe(streamBuffer)
+	defer sub.Close()
+	if h.prometheusMetrics != nil {
+		defer h.prometheusMetrics.StreamClientConnected("websocket")()
+	}
+
+	// All writes happen on this goroutine, the reader hands requests over
+	requests := make(chan SocketRequest)
+	readErr := make(chan error, 1)
+	go func() {
+		for {
+			var req SocketRequest
+			if err := websocket.JSON.Receive(conn, &req); err != nil {
+				readErr <- err
+				return
+			}
+			select {
+			case requests <- req:
+			case <-conn.Request().Context().Done():
+				return
+			}
+		}
+	}()
+
+	send := func(msg SocketMessage) error {
+		if err := conn.SetWriteDeadline(time.Now().Add(socketWriteTimeout)); err != nil {
+			return err
+		}
+		return websocket.JSON.Send(conn, msg)
+	}
+
+	subs := &socketSubscriptions{warehouses: map[int32]bool{}, skus: map[string]bool{}}
+	limit := newTokenBucket(socketMessageRate, socketMessageBurst)
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		var err error
+		select {
+		case <-conn.Request().Context().Done():
+			return
+		case <-readErr:
+			// Closed by the client, or a message that is not a SocketRequest
+			return
+		case req := <-requests:
+			if !limit.allow(time.Now()) {
+				if h.prometheusMetrics != nil {
+					h.prometheusMetrics.RecordStreamRateLimited("websocket")
+				}
+				err = send(SocketMessage{Type: "error", Error: "rate limit exceeded, request ignored"})
+			} else if warehouses, refErr := h.socketWarehouses(conn.Request().Context(), orgID, req.WarehouseIDs); refErr != nil {
+				err = send(SocketMessage{Type: "error", Error: refErr.Error()})
+			} else if applyErr := subs.apply(req, warehouses); applyErr != nil {
+				err = send(SocketMessage{Type: "error", Error: applyErr.Error()})
+			} else {
+				err = send(subs.message())
+			}
+		case <-heartbeat.C:
+			err = send(SocketMessage{Type: "heartbeat"})
+		case event, ok := <-sub.C:
+			if !ok {
+				// Fell behind or shutting down, the client reconnects
+				return
+			}
+			if event.OrgID != orgID || event.Entity != changefeed.EntityStockLevel {
+				continue
+			}
+			update, decodeErr := stockLevelUpdate(event)
+			if decodeErr != nil {
+				slog.Error("Got an error while decoding a stock level change: ", slog.Any("err", decodeErr.Error()))
+				continue
+			}
+			if subs.match(update) {
+				err = send(SocketMessage{Type: "stock_level", StockLevel: &update})
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
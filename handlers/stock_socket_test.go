@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+	"warehouse-service/changefeed"
+)
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Now()
+	b := newTokenBucket(2, 3)
+	b.last = now
+	for i := range 3 {
+		if !b.allow(now) {
+			t.Fatalf("message %d of the burst rejected", i)
+		}
+	}
+	if b.allow(now) {
+		t.Fatal("message beyond the burst allowed")
+	}
+	if !b.allow(now.Add(500 * time.Millisecond)) {
+		t.Fatal("message after refill rejected")
+	}
+}
+
+func TestSocketSubscriptions(t *testing.T) {
+	subs := &socketSubscriptions{warehouses: map[int32]bool{}, skus: map[string]bool{}}
+	if err := subs.apply(SocketRequest{Action: "subscribe", WarehouseIDs: []WarehouseRef{"1"}, Skus: []string{"SKU-A"}}, []int32{1}); err != nil {
+		t.Fatal(err)
+	}
+	update, err := stockLevelUpdate(changefeed.Event{
+		Entity:    changefeed.EntityStockLevel,
+		Operation: "update",
+		Data:      []byte(`{"warehouse_id": 2, "storage_room_id": 7, "sku": "SKU-A", "quantity": 5, "allocated_quantity": 1}`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if update.WarehouseID != 2 || update.Quantity != 5 || !subs.match(update) {
+		t.Fatalf("update %+v should match the SKU subscription", update)
+	}
+	update.Sku = "SKU-B"
+	if subs.match(update) {
+		t.Fatal("update in another warehouse and SKU matched")
+	}
+	update.WarehouseID = 1
+	if !subs.match(update) {
+		t.Fatal("update in the subscribed warehouse did not match")
+	}
+
+	if err := subs.apply(SocketRequest{Action: "unsubscribe", WarehouseIDs: []WarehouseRef{"1"}}, []int32{1}); err != nil {
+		t.Fatal(err)
+	}
+	if subs.match(update) {
+		t.Fatal("update matched after unsubscribing")
+	}
+	if err := subs.apply(SocketRequest{Action: "watch"}, nil); err == nil {
+		t.Fatal("expected an error for an unknown action")
+	}
+	if err := subs.apply(SocketRequest{Action: "subscribe", Skus: make([]string, socketMaxSubscriptions+1)}, nil); err == nil {
+		t.Fatal("expected an error beyond the subscription limit")
+	}
+}
@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+	"warehouse-service/authz"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+	"warehouse-service/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// patchStorageRoomRequest holds the fields of a partial update. Omitted
+// fields are nil and keep their stored value.
+type patchStorageRoomRequest struct {
+	Name        *string       `json:"name" binding:"omitempty,min=1"`
+	Number      *string       `json:"number" binding:"omitempty,min=1"`
+	WarehouseID *WarehouseRef `json:"warehouse_id" binding:"omitempty,min=1"`
+	ZoneType    *string       `json:"zone_type" binding:"omitempty,oneof=ambient chilled frozen"`
+	// Tags replaces all tags when sent
+	Tags *[]string `json:"tags"`
+	// Attributes replaces the whole attributes object when sent
+	Attributes json.RawMessage `json:"attributes"`
+	// Units of stock the room holds, 0 for unlimited
+	Capacity *int32 `json:"capacity" binding:"omitempty,gte=0"`
+	// Location on the pick path, 0 for unknown
+	Aisle *int32 `json:"aisle" binding:"omitempty,gte=0"`
+	Bay   *int32 `json:"bay" binding:"omitempty,gte=0"`
+}
+
+// storageRoomResponses maps rooms to responses along with how much stock
+// each holds
+func (h *Handlers) storageRoomResponses(ctx context.Context, q *models.Queries, orgID string, rooms []models.StorageRoom) ([]StorageRoomResponse, error) {
+	ids := make([]int32, len(rooms))
+	for i, room := range rooms {
+		ids[i] = room.ID
+	}
+	dbStart := time.Now()
+	rows, err := q.ListStorageRoomOccupancy(ctx, models.ListStorageRoomOccupancyParams{
+		OrgID:         orgID,
+		StorageRoomID: ids,
+	})
+	h.recordDBOperation(ctx, "list", "stock_level", dbStart, err)
+	if err != nil {
+		return nil, err
+	}
+	occupancy := make(map[int32]int64, len(rows))
+	for _, row := range rows {
+		occupancy[row.StorageRoomID] = row.Occupancy
+	}
+	responses := make([]StorageRoomResponse, len(rooms))
+	for i, room := range rooms {
+		units := occupancy[room.ID]
+		responses[i] = newStorageRoomResponse(room)
+		responses[i].Occupancy = &units
+	}
+	return responses, nil
+}
+
+// PatchStorageRoom updates only the fields present in the JSON body. Moving
+// a room to another warehouse requires that warehouse to belong to the tenant
+// and to have room under the storage room quota.
+func (h *Handlers) PatchStorageRoom(ctx *gin.Context) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "PatchStorageRoom")
+	defer span.End()
+
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "Invalid storage room ID format"),
+		})
+		return
+	}
+	var req patchStorageRoomRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   tr(ctx, "Invalid storage room payload"),
+			"details": err.Error(),
+		})
+		return
+	}
+	if req.Name == nil && req.Number == nil && req.WarehouseID == nil && req.ZoneType == nil && req.Tags == nil && req.Attributes == nil && req.Capacity == nil && req.Aisle == nil && req.Bay == nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "No fields to update"),
+		})
+		return
+	}
+	var attrs []byte
+	if req.Attributes != nil {
+		if attrs, err = decodeAttributes(req.Attributes); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+	}
+	var tags []string
+	if req.Tags != nil {
+		if tags, err = normalizeTags(*req.Tags); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+	}
+	orgID := tenantID(ctx)
+	traceOperation(ctx, span, observability.EntityStorageRoom, id)
+	if attrs != nil {
+		if err := h.checkAttributes(spanCtx, orgID, observability.EntityStorageRoom, attrs); err != nil {
+			span.RecordError(err)
+			respondAttributesError(ctx, err)
+			return
+		}
+	}
+
+	warehouseID := pgtype.Int4{}
+	if req.WarehouseID != nil {
+		id, err := h.resolveWarehouse(spanCtx, h.queries, orgID, *req.WarehouseID)
+		if err == nil && id > math.MaxInt32 {
+			// storage_room.warehouse_id is an int, no room can be there
+			err = pgx.ErrNoRows
+		}
+		if err == nil {
+			dbStart := time.Now()
+			_, err = h.queries.GetWarehouse(spanCtx, models.GetWarehouseParams{
+				ID:    id,
+				OrgID: orgID,
+			})
+			h.recordDBOperation(spanCtx, "get", "warehouse", dbStart, err)
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": tr(ctx, "Warehouse not found"),
+			})
+			return
+		}
+		if err != nil {
+			slog.Error("Got an error while getting warehouse: ", slog.Any("err", err.Error()))
+			tracing.Failed(span, err)
+			h.recordOperation(orgID, observability.EntityStorageRoom, "patch", opStart, err)
+			ctx.JSON(dbErrorStatus(err), gin.H{
+				"error": tr(ctx, "Failed to update storage room"),
+			})
+			return
+		}
+		warehouseID = pgtype.Int4{Int32: int32(id), Valid: true}
+	}
+
+	var room models.StorageRoom
+	err = pgx.BeginFunc(spanCtx, h.db, func(tx pgx.Tx) error {
+		qtx := h.queries.WithTx(tx)
+		dbStart := time.Now()
+		var err error
+		room, err = qtx.PatchStorageRoom(spanCtx, models.PatchStorageRoomParams{
+			Name:        textParam(req.Name),
+			Number:      textParam(req.Number),
+			WarehouseID: warehouseID,
+			ZoneType:    textParam(req.ZoneType),
+			Attributes:  attrs,
+			Tags:        tags,
+			Capacity:    int4Param(req.Capacity),
+			Aisle:       int4Param(req.Aisle),
+			Bay:         int4Param(req.Bay),
+			ID:          int32(id),
+			OrgID:       orgID,
+		})
+		h.recordDBOperation(spanCtx, "update", "storage_room", dbStart, err)
+		if err == nil {
+			err = h.authorize(ctx, authz.Write, observability.EntityStorageRoom, room.OrgID)
+		}
+		if err != nil || !warehouseID.Valid {
+			return err
+		}
+		return h.enforceStorageRoomQuota(spanCtx, qtx, orgID, room.WarehouseID)
+	})
+	if exceeded, ok := quotaExceeded(err); ok {
+		h.recordOperation(orgID, observability.EntityStorageRoom, "patch", opStart, err)
+		respondQuotaExceeded(ctx, exceeded)
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		h.recordOperation(orgID, observability.EntityStorageRoom, "patch", opStart, pgx.ErrNoRows)
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Storage room not found"),
+		})
+		return
+	}
+	if conflict, ok := conflictFor(err); ok {
+		h.recordOperation(orgID, observability.EntityStorageRoom, "patch", opStart, err)
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": tr(ctx, conflict.message),
+			"field": conflict.field,
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Could not patch storage room: ", slog.Any("err", err.Error()))
+		tracing.Failed(span, err)
+		h.recordOperation(orgID, observability.EntityStorageRoom, "patch", opStart, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to update storage room"),
+		})
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntityStorageRoom, "patch", opStart, nil)
+
+	response := newStorageRoomResponse(room)
+	if responses, err := h.storageRoomResponses(spanCtx, h.queries, orgID, []models.StorageRoom{room}); err == nil {
+		response = responses[0]
+	} else {
+		// The update is committed, answer without the occupancy
+		slog.Error("Could not get storage room occupancy: ", slog.Any("err", err.Error()))
+	}
+	tracing.Result(span, observability.StatusSuccess)
+	h.setVersionTag(ctx, spanCtx, h.queries, observability.EntityStorageRoom, id, orgID)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Update Storage Room Successfully"),
+		"data":    response,
+	})
+}
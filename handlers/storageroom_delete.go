@@ -0,0 +1,376 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+	"warehouse-service/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxBlockingStock caps the stock levels listed in a 409 delete response
+const maxBlockingStock = 20
+
+// errRelocateTargetNotFound is returned when ?relocate_to= is not a storage
+// room of the tenant
+var errRelocateTargetNotFound = errors.New("Storage room to relocate to not found")
+
+// StorageRoomDeleteResponse is a deleted storage room. With ?relocate_to=
+// Relocated has the stock levels its stock moved into.
+type StorageRoomDeleteResponse struct {
+	ID          int32                `json:"id" v1:"ID"`
+	RelocatedTo *int32               `json:"relocated_to" v1:"RelocatedTo"`
+	Relocated   []StockLevelResponse `json:"relocated" v1:"Relocated"`
+	SerialCount int                  `json:"serial_count" v1:"SerialCount"`
+}
+
+// storageRoomDelete is a delete request. A zero RelocateTo keeps the stock
+// where it is and blocks the delete while there is any.
+type storageRoomDelete struct {
+	OrgID      string
+	ID         int32
+	RelocateTo int32
+	// Place the relocated stock even where it takes the target over its
+	// capacity
+	OverrideCapacity bool
+	Actor            string
+	// The versions the delete is conditional on, nil for any
+	IfMatch ifMatch
+}
+
+// storageRoomInUseError is returned when a storage room still holds stock
+// that the delete can't move: any stock without ?relocate_to=, allocated
+// stock with it
+type storageRoomInUseError struct {
+	id        int32
+	stock     []models.StockLevel
+	allocated bool
+	// The room was in the way of a cascaded warehouse delete
+	cascade bool
+}
+
+func (e *storageRoomInUseError) Error() string {
+	quantity, _ := e.quantity()
+	return fmt.Sprintf("storage room %d still holds %d units of %d SKUs", e.id, quantity, len(e.stock))
+}
+
+// quantity sums the units in the room and those allocated to pick lists
+func (e *storageRoomInUseError) quantity() (quantity, allocated int64) {
+	for _, level := range e.stock {
+		quantity += int64(level.Quantity)
+		allocated += int64(level.AllocatedQuantity)
+	}
+	return quantity, allocated
+}
+
+// relocateParams parses the optional ?relocate_to= and ?override_capacity=
+// of a delete request
+func relocateParams(ctx *gin.Context, id int32) (int32, bool, error) {
+	var relocateTo int32
+	if raw := ctx.Query("relocate_to"); raw != "" {
+		target, err := strconv.ParseInt(raw, 10, 32)
+		if err != nil || target <= 0 {
+			return 0, false, fmt.Errorf("Invalid relocate_to value %q", raw)
+		}
+		if int32(target) == id {
+			return 0, false, errors.New("relocate_to must be another storage room")
+		}
+		relocateTo = int32(target)
+	}
+	var override bool
+	if raw := ctx.Query("override_capacity"); raw != "" {
+		var err error
+		if override, err = strconv.ParseBool(raw); err != nil {
+			return 0, false, fmt.Errorf("Invalid override_capacity value %q", raw)
+		}
+	}
+	return relocateTo, override, nil
+}
+
+// deleteStorageRoom removes a tenant's storage room in one transaction. A
+// room holding stock is kept and a *storageRoomInUseError summarizes it,
+// unless req relocates the stock: its units and in-stock serials then move
+// to the target room first, as paired transfer adjustments. Allocated stock
+// can't move and still blocks the delete. A missing room is reported as
+// pgx.ErrNoRows, a room no longer at a version of req.IfMatch as
+// errVersionMismatch.
+func (h *Handlers) deleteStorageRoom(ctx context.Context, req storageRoomDelete) (StorageRoomDeleteResponse, error) {
+	result := StorageRoomDeleteResponse{ID: req.ID, Relocated: []StockLevelResponse{}}
+	tx, err := h.db.Begin(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback(ctx) // This will be ignored if tx.Commit() succeeds
+	qtx := h.queries.WithTx(tx)
+
+	stock, err := h.lockStorageRoomStock(ctx, qtx, req.OrgID, req.ID, req.IfMatch)
+	if err != nil {
+		return result, err
+	}
+
+	if len(stock) > 0 {
+		inUse := &storageRoomInUseError{id: req.ID, stock: stock}
+		if req.RelocateTo == 0 {
+			return result, inUse
+		}
+		if _, allocated := inUse.quantity(); allocated > 0 {
+			inUse.allocated = true
+			return result, inUse
+		}
+		if result.Relocated, result.SerialCount, err = h.relocateStorageRoomStock(ctx, qtx, req, stock); err != nil {
+			return result, err
+		}
+		result.RelocatedTo = &req.RelocateTo
+	}
+
+	deleted, err := h.removeStorageRoom(ctx, qtx, req.OrgID, req.ID)
+	if err != nil {
+		return result, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return result, err
+	}
+	tracing.RowsAffected(trace.SpanFromContext(ctx), deleted)
+	return result, nil
+}
+
+// lockStorageRoomStock locks a storage room about to be deleted, keeping
+// placements out of it until the transaction ends, and returns the stock
+// levels it still holds units in, locked as well. A missing room is
+// reported as pgx.ErrNoRows, a room no longer at a version of cond as
+// errVersionMismatch.
+func (h *Handlers) lockStorageRoomStock(ctx context.Context, qtx *models.Queries, orgID string, id int32, cond ifMatch) ([]models.StockLevel, error) {
+	dbStart := time.Now()
+	_, err := qtx.LockStorageRoomCapacity(ctx, models.LockStorageRoomCapacityParams{
+		ID:    id,
+		OrgID: orgID,
+	})
+	h.recordDBOperation(ctx, "get", "storage_room", dbStart, err)
+	if err == nil {
+		err = h.checkVersion(ctx, qtx, observability.EntityStorageRoom, int64(id), orgID, cond)
+	}
+	if err != nil {
+		return nil, err
+	}
+	dbStart = time.Now()
+	stock, err := qtx.LockStorageRoomStock(ctx, models.LockStorageRoomStockParams{
+		OrgID:         orgID,
+		StorageRoomID: id,
+	})
+	h.recordDBOperation(ctx, "list", "stock_level", dbStart, err)
+	return stock, err
+}
+
+// removeStorageRoom deletes a storage room locked by lockStorageRoomStock
+// and found empty, with the zero stock levels left behind by stock that
+// moved out of it
+func (h *Handlers) removeStorageRoom(ctx context.Context, qtx *models.Queries, orgID string, id int32) (int64, error) {
+	dbStart := time.Now()
+	_, err := qtx.DeleteEmptyStockLevels(ctx, models.DeleteEmptyStockLevelsParams{
+		OrgID:         orgID,
+		StorageRoomID: id,
+	})
+	h.recordDBOperation(ctx, "delete", "stock_level", dbStart, err)
+	if err != nil {
+		return 0, err
+	}
+	dbStart = time.Now()
+	deleted, err := qtx.DeleteStorageRoom(ctx, models.DeleteStorageRoomParams{
+		ID:    id,
+		OrgID: orgID,
+	})
+	h.recordDBOperation(ctx, "delete", "storage_room", dbStart, err)
+	if err != nil {
+		return 0, err
+	}
+	if deleted == 0 {
+		return 0, pgx.ErrNoRows
+	}
+	return deleted, nil
+}
+
+// relocateStorageRoomStock moves all of stock and the in-stock serials of
+// the room to req.RelocateTo, returning the target's stock levels and the
+// number of serials moved
+func (h *Handlers) relocateStorageRoomStock(ctx context.Context, qtx *models.Queries, req storageRoomDelete, stock []models.StockLevel) ([]StockLevelResponse, int, error) {
+	dbStart := time.Now()
+	_, err := qtx.GetStorageRoom(ctx, models.GetStorageRoomParams{
+		ID:    req.RelocateTo,
+		OrgID: req.OrgID,
+	})
+	h.recordDBOperation(ctx, "get", "storage_room", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, 0, errRelocateTargetNotFound
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	reference := fmt.Sprintf("storage_room:%d", req.ID)
+	relocated := make([]StockLevelResponse, 0, len(stock))
+	for _, level := range stock {
+		if _, err := h.adjustStock(ctx, qtx, stockAdjustment{
+			OrgID:         req.OrgID,
+			StorageRoomID: req.ID,
+			Sku:           level.Sku,
+			Delta:         -level.Quantity,
+			Reason:        adjustmentReasonTransfer,
+			Reference:     reference,
+		}); err != nil {
+			return nil, 0, err
+		}
+		target, err := h.adjustStock(ctx, qtx, stockAdjustment{
+			OrgID:            req.OrgID,
+			StorageRoomID:    req.RelocateTo,
+			Sku:              level.Sku,
+			Delta:            level.Quantity,
+			Reason:           adjustmentReasonTransfer,
+			Reference:        reference,
+			OverrideCapacity: req.OverrideCapacity,
+			ExpiresAt:        level.ExpiresAt,
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+		relocated = append(relocated, newStockLevelResponse(target))
+	}
+
+	dbStart = time.Now()
+	serials, err := qtx.LockSerialsInStorageRoom(ctx, models.LockSerialsInStorageRoomParams{
+		OrgID:         req.OrgID,
+		StorageRoomID: pgtype.Int4{Int32: req.ID, Valid: true},
+	})
+	h.recordDBOperation(ctx, "list", "serial", dbStart, err)
+	if err != nil {
+		return nil, 0, err
+	}
+	room := pgtype.Int4{Int32: req.RelocateTo, Valid: true}
+	for _, s := range serials {
+		if _, err := h.relocateSerial(ctx, qtx, s, serialActionMove, room, serialStatusInStock, reference, req.Actor); err != nil {
+			return nil, 0, err
+		}
+	}
+	return relocated, len(serials), nil
+}
+
+// respondStorageRoomInUse answers a blocked delete with the stock in the way
+func respondStorageRoomInUse(ctx *gin.Context, inUse *storageRoomInUseError) {
+	message := "Storage room still holds stock, move it first or retry with ?relocate_to=<storage room ID>"
+	if inUse.allocated {
+		message = "Storage room holds stock allocated to pick lists, pick or release it before relocating"
+	}
+	if inUse.cascade {
+		message = "Warehouse has a storage room still holding stock, move it or delete the room with ?relocate_to=<storage room ID> first"
+	}
+	quantity, allocated := inUse.quantity()
+	stock := inUse.stock
+	if len(stock) > maxBlockingStock {
+		stock = stock[:maxBlockingStock]
+	}
+	respondV1(ctx, http.StatusConflict, gin.H{
+		"error":              message,
+		"storage_room_id":    inUse.id,
+		"sku_count":          len(inUse.stock),
+		"quantity":           quantity,
+		"allocated_quantity": allocated,
+		"stock":              mapSlice(stock, newStockLevelResponse),
+	})
+}
+
+// DeleteStorageRoom deletes a storage room once it holds no stock. With
+// ?relocate_to= the stock and serials move to that room first, the move
+// taking ?override_capacity=true where the target is too small.
+func (h *Handlers) DeleteStorageRoom(ctx *gin.Context) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "DeleteStorageRoom")
+	defer span.End()
+
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "Invalid storage room ID format"),
+		})
+		return
+	}
+	relocateTo, override, err := relocateParams(ctx, int32(id))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	traceOperation(ctx, span, observability.EntityStorageRoom, id)
+	span.SetAttributes(attribute.Int64("storage_room.relocate_to", int64(relocateTo)))
+
+	result, err := h.deleteStorageRoom(spanCtx, storageRoomDelete{
+		OrgID:            orgID,
+		ID:               int32(id),
+		RelocateTo:       relocateTo,
+		OverrideCapacity: override,
+		Actor:            actorID(ctx),
+		IfMatch:          parseIfMatch(ctx),
+	})
+	var inUse *storageRoomInUseError
+	if errors.As(err, &inUse) {
+		tracing.Result(span, "in_use")
+		h.recordOperation(orgID, observability.EntityStorageRoom, "delete", opStart, err)
+		respondStorageRoomInUse(ctx, inUse)
+		return
+	}
+	if errors.Is(err, errVersionMismatch) {
+		tracing.Result(span, "version_mismatch")
+		h.recordOperation(orgID, observability.EntityStorageRoom, "delete", opStart, err)
+		ctx.JSON(http.StatusPreconditionFailed, gin.H{
+			"error": tr(ctx, "Storage room changed since the version in If-Match"),
+		})
+		return
+	}
+	if errors.Is(err, errRelocateTargetNotFound) {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if exceeded, ok := capacityExceeded(err); ok {
+		h.recordOperation(orgID, observability.EntityStorageRoom, "delete", opStart, err)
+		respondCapacityExceeded(ctx, exceeded)
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		tracing.Result(span, observability.StatusNotFound)
+		h.recordOperation(orgID, observability.EntityStorageRoom, "delete", opStart, pgx.ErrNoRows)
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Storage room not found"),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to delete storage room: ", slog.Any("err", err.Error()))
+		tracing.Failed(span, err)
+		h.recordOperation(orgID, observability.EntityStorageRoom, "delete", opStart, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to delete storage room"),
+		})
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntityStorageRoom, "delete", opStart, nil)
+	tracing.Result(span, observability.StatusSuccess)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Delete Storage Room Successfully"),
+		"data":    result,
+	})
+}
@@ -0,0 +1,354 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	"warehouse-service/authz"
+	"warehouse-service/listquery"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+	"warehouse-service/outbox"
+	"warehouse-service/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type tagsRequest struct {
+	Tags []string `json:"tags" binding:"required,min=1"`
+}
+
+// bindTags reads the normalized tags of an add request, writing the error
+// response itself when it returns false
+func bindTags(ctx *gin.Context) ([]string, bool) {
+	var req tagsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   tr(ctx, "Invalid tags payload"),
+			"details": err.Error(),
+		})
+		return nil, false
+	}
+	tags, err := normalizeTags(req.Tags)
+	if err == nil && len(tags) == 0 {
+		err = errors.New("tags must not be empty")
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return nil, false
+	}
+	return tags, true
+}
+
+// pathTag reads the tag removed by a DELETE .../tags/:tag request
+func pathTag(ctx *gin.Context) ([]string, bool) {
+	tags, err := normalizeTags([]string{ctx.Param("tag")})
+	if err == nil && len(tags) == 0 {
+		err = errors.New("tag must not be empty")
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return nil, false
+	}
+	return tags, true
+}
+
+// AddWarehouseTags adds tags to a warehouse, keeping the ones it has
+func (h *Handlers) AddWarehouseTags(ctx *gin.Context) {
+	tags, ok := bindTags(ctx)
+	if !ok {
+		return
+	}
+	h.changeWarehouseTags(ctx, "add_tags", func(spanCtx context.Context, qtx *models.Queries, id int64, orgID string) (models.Warehouse, error) {
+		warehouse, err := qtx.AddWarehouseTags(spanCtx, models.AddWarehouseTagsParams{
+			Tags:  tags,
+			ID:    id,
+			OrgID: orgID,
+		})
+		if err == nil && len(warehouse.Tags) > maxTags {
+			return warehouse, errTooManyTags
+		}
+		return warehouse, err
+	})
+}
+
+// RemoveWarehouseTag removes one tag from a warehouse. Removing a tag the
+// warehouse does not have succeeds.
+func (h *Handlers) RemoveWarehouseTag(ctx *gin.Context) {
+	tags, ok := pathTag(ctx)
+	if !ok {
+		return
+	}
+	h.changeWarehouseTags(ctx, "remove_tags", func(spanCtx context.Context, qtx *models.Queries, id int64, orgID string) (models.Warehouse, error) {
+		return qtx.RemoveWarehouseTags(spanCtx, models.RemoveWarehouseTagsParams{
+			Tags:  tags,
+			ID:    id,
+			OrgID: orgID,
+		})
+	})
+}
+
+// changeWarehouseTags runs apply in a transaction that also writes the
+// warehouse.updated event
+func (h *Handlers) changeWarehouseTags(ctx *gin.Context, operation string, apply func(spanCtx context.Context, qtx *models.Queries, id int64, orgID string) (models.Warehouse, error)) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "ChangeWarehouseTags")
+	defer span.End()
+
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "Invalid warehouse ID format"),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	traceOperation(ctx, span, observability.EntityWarehouse, id)
+	span.SetAttributes(attribute.String("operation", operation))
+
+	var warehouse models.Warehouse
+	err = pgx.BeginFunc(spanCtx, h.db, func(tx pgx.Tx) error {
+		qtx := h.queries.WithTx(tx)
+		dbStart := time.Now()
+		var err error
+		warehouse, err = apply(spanCtx, qtx, id, orgID)
+		h.recordDBOperation(spanCtx, "update", "warehouse", dbStart, err)
+		if err != nil {
+			return err
+		}
+		if err := h.authorize(ctx, authz.Write, observability.EntityWarehouse, warehouse.OrgID); err != nil {
+			return err
+		}
+		return h.enqueueWarehouseEvent(spanCtx, qtx, outbox.TopicWarehouseUpdated, warehouse)
+	})
+	if errors.Is(err, errTooManyTags) {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		h.recordOperation(orgID, observability.EntityWarehouse, operation, opStart, pgx.ErrNoRows)
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Warehouse not found"),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Could not change warehouse tags: ", slog.Any("err", err.Error()))
+		tracing.Failed(span, err)
+		h.recordOperation(orgID, observability.EntityWarehouse, operation, opStart, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to update warehouse tags"),
+		})
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntityWarehouse, operation, opStart, nil)
+
+	tracing.Result(span, observability.StatusSuccess)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Update Warehouse Tags Successfully"),
+		"data":    newWarehouseResponse(warehouse),
+	})
+}
+
+// AddStorageRoomTags adds tags to a storage room, keeping the ones it has
+func (h *Handlers) AddStorageRoomTags(ctx *gin.Context) {
+	tags, ok := bindTags(ctx)
+	if !ok {
+		return
+	}
+	h.changeStorageRoomTags(ctx, "add_tags", func(spanCtx context.Context, qtx *models.Queries, id int32, orgID string) (models.StorageRoom, error) {
+		room, err := qtx.AddStorageRoomTags(spanCtx, models.AddStorageRoomTagsParams{
+			Tags:  tags,
+			ID:    id,
+			OrgID: orgID,
+		})
+		if err == nil && len(room.Tags) > maxTags {
+			return room, errTooManyTags
+		}
+		return room, err
+	})
+}
+
+// RemoveStorageRoomTag removes one tag from a storage room
+func (h *Handlers) RemoveStorageRoomTag(ctx *gin.Context) {
+	tags, ok := pathTag(ctx)
+	if !ok {
+		return
+	}
+	h.changeStorageRoomTags(ctx, "remove_tags", func(spanCtx context.Context, qtx *models.Queries, id int32, orgID string) (models.StorageRoom, error) {
+		return qtx.RemoveStorageRoomTags(spanCtx, models.RemoveStorageRoomTagsParams{
+			Tags:  tags,
+			ID:    id,
+			OrgID: orgID,
+		})
+	})
+}
+
+func (h *Handlers) changeStorageRoomTags(ctx *gin.Context, operation string, apply func(spanCtx context.Context, qtx *models.Queries, id int32, orgID string) (models.StorageRoom, error)) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "ChangeStorageRoomTags")
+	defer span.End()
+
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "Invalid storage room ID format"),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	traceOperation(ctx, span, observability.EntityStorageRoom, id)
+	span.SetAttributes(attribute.String("operation", operation))
+
+	var room models.StorageRoom
+	err = pgx.BeginFunc(spanCtx, h.db, func(tx pgx.Tx) error {
+		dbStart := time.Now()
+		var err error
+		room, err = apply(spanCtx, h.queries.WithTx(tx), int32(id), orgID)
+		h.recordDBOperation(spanCtx, "update", "storage_room", dbStart, err)
+		if err != nil {
+			return err
+		}
+		return h.authorize(ctx, authz.Write, observability.EntityStorageRoom, room.OrgID)
+	})
+	if errors.Is(err, errTooManyTags) {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		h.recordOperation(orgID, observability.EntityStorageRoom, operation, opStart, pgx.ErrNoRows)
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Storage room not found"),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Could not change storage room tags: ", slog.Any("err", err.Error()))
+		tracing.Failed(span, err)
+		h.recordOperation(orgID, observability.EntityStorageRoom, operation, opStart, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to update storage room tags"),
+		})
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntityStorageRoom, operation, opStart, nil)
+
+	tracing.Result(span, observability.StatusSuccess)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Update Storage Room Tags Successfully"),
+		"data":    newStorageRoomResponse(room),
+	})
+}
+
+// storageRoomListSpec sorts and filters the rooms of ListStorageRooms
+var storageRoomListSpec = &listquery.Spec{
+	Table: "storage_room",
+	Sort: map[string]string{
+		"id":       "id",
+		"name":     "name",
+		"number":   "number",
+		"capacity": "capacity",
+		"aisle":    "aisle",
+		"bay":      "bay",
+	},
+	Filters: map[string]listquery.Filter{
+		"zone_type": {Column: "zone_type", Op: listquery.Eq, Parse: listquery.Text},
+	},
+	Default: []listquery.Order{{Field: "id"}},
+	Key:     "id",
+}
+
+// ListStorageRooms pages through the tenant's storage rooms, in id order
+// unless sorted by ?sort=, filtered by ?warehouse_id=, ?zone_type= and ?tag=
+// (repeatable, all must match)
+func (h *Handlers) ListStorageRooms(ctx *gin.Context) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "ListStorageRooms")
+	defer span.End()
+
+	q, err := listParams(ctx, storageRoomListSpec)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	fields, ok := bindFields[StorageRoomResponse](ctx)
+	if !ok {
+		return
+	}
+	orgID := tenantID(ctx)
+	q.Where("org_id", listquery.Eq, orgID)
+	if v := ctx.Query("warehouse_id"); v != "" {
+		warehouseID, err := strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": tr(ctx, "Invalid warehouse ID format"),
+			})
+			return
+		}
+		q.Where("warehouse_id", listquery.Eq, int32(warehouseID))
+	}
+	if values := ctx.QueryArray("tag"); len(values) > 0 {
+		tags, err := normalizeTags(values)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		q.Where("tags", listquery.Contains, tags)
+	}
+	tracing.Actor(span, orgID, actorID(ctx))
+
+	dbStart := time.Now()
+	rooms, err := queryPage[models.StorageRoom](ctx, spanCtx, h.router.Read(spanCtx), q)
+	h.recordDBOperation(spanCtx, "list", "storage_room", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while listing storage rooms: ", slog.Any("err", err.Error()))
+		tracing.Failed(span, err)
+		h.recordOperation(orgID, observability.EntityStorageRoom, "list", opStart, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to list storage rooms"),
+		})
+		return
+	}
+
+	rooms = authorizedRows(h, ctx, observability.EntityStorageRoom, rooms, storageRoomOrg)
+	responses, err := h.storageRoomResponses(spanCtx, h.readQueries(spanCtx), orgID, rooms)
+	if err != nil {
+		slog.Error("Got an error while getting storage room occupancy: ", slog.Any("err", err.Error()))
+		tracing.Failed(span, err)
+		h.recordOperation(orgID, observability.EntityStorageRoom, "list", opStart, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to list storage rooms"),
+		})
+		return
+	}
+	h.recordOperation(orgID, observability.EntityStorageRoom, "list", opStart, nil)
+
+	span.SetAttributes(attribute.Int("storage_room.count", len(rooms)))
+	tracing.Result(span, observability.StatusSuccess)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "List Storage Rooms Successfully"),
+		"data":    selectFields(fields, responses),
+	})
+}
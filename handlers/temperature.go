@@ -0,0 +1,498 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"warehouse-service/listquery"
+	models "warehouse-service/models/sqlc"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Zone types of a storage room
+const (
+	zoneAmbient = "ambient"
+	zoneChilled = "chilled"
+	zoneFrozen  = "frozen"
+)
+
+const (
+	maxTemperatureReadings = 500
+	// Readings outside this window around the server clock are rejected,
+	// they would land in the default partition
+	maxReadingAge  = 7 * 24 * time.Hour
+	maxReadingSkew = 5 * time.Minute
+	// temperaturePartitionPrefix names the monthly partitions of
+	// temperature_reading, followed by YYYY_MM
+	temperaturePartitionPrefix = "temperature_reading_"
+)
+
+// zoneThreshold is the allowed temperature range of a zone type in Celsius
+type zoneThreshold struct {
+	Min float64
+	Max float64
+}
+
+func (t zoneThreshold) contains(celsius float64) bool {
+	return celsius >= t.Min && celsius <= t.Max
+}
+
+var zoneThresholds = map[string]zoneThreshold{
+	zoneAmbient: {Min: 15, Max: 25},
+	zoneChilled: {Min: 2, Max: 8},
+	zoneFrozen:  {Min: -30, Max: -18},
+}
+
+type temperatureReading struct {
+	StorageRoomID int32     `json:"storage_room_id" binding:"required,gt=0"`
+	SensorID      string    `json:"sensor_id" binding:"required,max=100"`
+	Celsius       *float64  `json:"celsius" binding:"required"`
+	RecordedAt    time.Time `json:"recorded_at" binding:"required"`
+}
+
+type temperatureIngestRequest struct {
+	Readings []temperatureReading `json:"readings" binding:"required,min=1,max=500,dive"`
+}
+
+// validate rejects readings the sensors could not have taken, NaN values
+// and timestamps far from the server clock
+func (r temperatureIngestRequest) validate(now time.Time) error {
+	for i, reading := range r.Readings {
+		if math.IsNaN(*reading.Celsius) || math.IsInf(*reading.Celsius, 0) || *reading.Celsius < -100 || *reading.Celsius > 100 {
+			return fmt.Errorf("readings[%d].celsius must be between -100 and 100", i)
+		}
+		if reading.RecordedAt.After(now.Add(maxReadingSkew)) {
+			return fmt.Errorf("readings[%d].recorded_at is in the future", i)
+		}
+		if reading.RecordedAt.Before(now.Add(-maxReadingAge)) {
+			return fmt.Errorf("readings[%d].recorded_at is older than %s", i, maxReadingAge)
+		}
+	}
+	return nil
+}
+
+// latestReadings returns the most recent reading per storage room, they
+// decide the breach state of the room
+func latestReadings(readings []temperatureReading) map[int32]temperatureReading {
+	latest := make(map[int32]temperatureReading)
+	for _, reading := range readings {
+		if current, ok := latest[reading.StorageRoomID]; !ok || reading.RecordedAt.After(current.RecordedAt) {
+			latest[reading.StorageRoomID] = reading
+		}
+	}
+	return latest
+}
+
+// breachChange is a storage room entering or leaving its thresholds
+type breachChange struct {
+	breach   models.TemperatureBreach
+	resolved bool
+}
+
+// IngestTemperature stores a batch of sensor readings and opens or resolves
+// threshold breaches from the latest reading of each room
+func (h *Handlers) IngestTemperature(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "IngestTemperature")
+	defer span.End()
+
+	var req temperatureIngestRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   tr(ctx, "Invalid temperature payload"),
+			"details": err.Error(),
+		})
+		return
+	}
+	if err := req.validate(time.Now()); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	latest := latestReadings(req.Readings)
+	span.SetAttributes(
+		attribute.Int("temperature.readings", len(req.Readings)),
+		attribute.Int("temperature.rooms", len(latest)),
+		attribute.String("tenant.id", orgID),
+	)
+
+	roomIDs := make([]int32, 0, len(latest))
+	for id := range latest {
+		roomIDs = append(roomIDs, id)
+	}
+	sort.Slice(roomIDs, func(i, j int) bool { return roomIDs[i] < roomIDs[j] })
+
+	dbStart := time.Now()
+	rooms, err := h.queries.GetStorageRoomsByIDs(spanCtx, models.GetStorageRoomsByIDsParams{
+		OrgID: orgID,
+		ID:    roomIDs,
+	})
+	h.recordDBOperation(spanCtx, "get", "storage_room", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while getting storage rooms: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to store temperature readings"),
+		})
+		return
+	}
+	zones := make(map[int32]string, len(rooms))
+	for _, room := range rooms {
+		zones[room.ID] = room.ZoneType
+	}
+	var unknown []int32
+	for _, id := range roomIDs {
+		if _, ok := zones[id]; !ok {
+			unknown = append(unknown, id)
+		}
+	}
+	if len(unknown) > 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":            tr(ctx, "Unknown storage rooms"),
+			"storage_room_ids": unknown,
+		})
+		return
+	}
+
+	inserted, changes, err := h.storeTemperatureReadings(spanCtx, orgID, req.Readings, roomIDs, latest, zones)
+	if err != nil {
+		slog.Error("Could not store temperature readings: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to store temperature readings"),
+		})
+		return
+	}
+
+	breaches := []TemperatureBreachResponse{}
+	for _, change := range changes {
+		h.alertTemperatureBreach(orgID, change)
+		if !change.resolved {
+			breaches = append(breaches, newTemperatureBreachResponse(change.breach))
+		}
+	}
+	if h.prometheusMetrics != nil {
+		for id, reading := range latest {
+			h.prometheusMetrics.RecordTemperature(orgID, id, *reading.Celsius, !zoneThresholds[zones[id]].contains(*reading.Celsius))
+		}
+	}
+
+	span.SetAttributes(
+		attribute.Int64("temperature.inserted", inserted),
+		attribute.Int("temperature.breaches", len(breaches)),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Ingest Temperature Readings Successfully"),
+		"data": gin.H{
+			"accepted":   inserted,
+			"duplicates": int64(len(req.Readings)) - inserted,
+			"breaches":   breaches,
+		},
+	})
+}
+
+// storeTemperatureReadings inserts the readings, skipping ones already
+// stored, and updates the breach state of each room in one transaction
+func (h *Handlers) storeTemperatureReadings(ctx context.Context, orgID string, readings []temperatureReading, roomIDs []int32, latest map[int32]temperatureReading, zones map[int32]string) (int64, []breachChange, error) {
+	tx, err := h.db.Begin(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer tx.Rollback(ctx) // This will be ignored if tx.Commit() succeeds
+
+	qtx := h.queries.WithTx(tx)
+
+	params := models.InsertTemperatureReadingsParams{
+		OrgID:          orgID,
+		StorageRoomIds: make([]int32, 0, len(readings)),
+		SensorIds:      make([]string, 0, len(readings)),
+		Celsius:        make([]float64, 0, len(readings)),
+		RecordedAt:     make([]pgtype.Timestamptz, 0, len(readings)),
+	}
+	for _, reading := range readings {
+		params.StorageRoomIds = append(params.StorageRoomIds, reading.StorageRoomID)
+		params.SensorIds = append(params.SensorIds, reading.SensorID)
+		params.Celsius = append(params.Celsius, *reading.Celsius)
+		params.RecordedAt = append(params.RecordedAt, pgtype.Timestamptz{Time: reading.RecordedAt, Valid: true})
+	}
+	dbStart := time.Now()
+	inserted, err := qtx.InsertTemperatureReadings(ctx, params)
+	h.recordDBOperation(ctx, "create", "temperature_reading", dbStart, err)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var changes []breachChange
+	// Rooms are visited in ID order so concurrent batches lock breaches in
+	// the same order
+	for _, id := range roomIDs {
+		change, changed, err := h.updateBreach(ctx, qtx, orgID, id, zones[id], latest[id])
+		if err != nil {
+			return 0, nil, fmt.Errorf("update breach of storage room %d: %w", id, err)
+		}
+		if changed {
+			changes = append(changes, change)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, nil, err
+	}
+	return inserted, changes, nil
+}
+
+// updateBreach opens a breach when the reading leaves the zone thresholds,
+// tracks its peak while it lasts and resolves it once the room is back in
+// range. changed is true when a breach was opened or resolved.
+func (h *Handlers) updateBreach(ctx context.Context, qtx *models.Queries, orgID string, roomID int32, zone string, reading temperatureReading) (breachChange, bool, error) {
+	threshold := zoneThresholds[zone]
+	celsius := *reading.Celsius
+
+	dbStart := time.Now()
+	open, err := qtx.GetOpenTemperatureBreach(ctx, models.GetOpenTemperatureBreachParams{
+		StorageRoomID: roomID,
+		OrgID:         orgID,
+	})
+	h.recordDBOperation(ctx, "get", "temperature_breach", dbStart, err)
+	hasOpen := err == nil
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return breachChange{}, false, err
+	}
+
+	switch {
+	case threshold.contains(celsius) && hasOpen:
+		// A reading older than the breach does not end it
+		if reading.RecordedAt.Before(open.StartedAt.Time) {
+			return breachChange{}, false, nil
+		}
+		dbStart = time.Now()
+		err = qtx.ResolveTemperatureBreach(ctx, models.ResolveTemperatureBreachParams{
+			ID:         open.ID,
+			ResolvedAt: pgtype.Timestamptz{Time: reading.RecordedAt, Valid: true},
+		})
+		h.recordDBOperation(ctx, "update", "temperature_breach", dbStart, err)
+		if err != nil {
+			return breachChange{}, false, err
+		}
+		open.ResolvedAt = pgtype.Timestamptz{Time: reading.RecordedAt, Valid: true}
+		return breachChange{breach: open, resolved: true}, true, nil
+
+	case !threshold.contains(celsius) && hasOpen:
+		if math.Abs(celsius-midpoint(threshold)) <= math.Abs(open.PeakCelsius-midpoint(threshold)) {
+			return breachChange{}, false, nil
+		}
+		dbStart = time.Now()
+		err = qtx.UpdateTemperatureBreachPeak(ctx, models.UpdateTemperatureBreachPeakParams{
+			ID:          open.ID,
+			PeakCelsius: celsius,
+		})
+		h.recordDBOperation(ctx, "update", "temperature_breach", dbStart, err)
+		return breachChange{}, false, err
+
+	case !threshold.contains(celsius):
+		dbStart = time.Now()
+		breach, err := qtx.CreateTemperatureBreach(ctx, models.CreateTemperatureBreachParams{
+			OrgID:         orgID,
+			StorageRoomID: roomID,
+			ZoneType:      zone,
+			MinCelsius:    threshold.Min,
+			MaxCelsius:    threshold.Max,
+			PeakCelsius:   celsius,
+			StartedAt:     pgtype.Timestamptz{Time: reading.RecordedAt, Valid: true},
+		})
+		h.recordDBOperation(ctx, "create", "temperature_breach", dbStart, err)
+		// A concurrent batch opened the breach first
+		if errors.Is(err, pgx.ErrNoRows) {
+			return breachChange{}, false, nil
+		}
+		if err != nil {
+			return breachChange{}, false, err
+		}
+		return breachChange{breach: breach}, true, nil
+	}
+	return breachChange{}, false, nil
+}
+
+func midpoint(t zoneThreshold) float64 {
+	return (t.Min + t.Max) / 2
+}
+
+// alertTemperatureBreach logs a breach opening or resolving and counts new
+// breaches, log based alerting keys on the "Temperature breach" message
+func (h *Handlers) alertTemperatureBreach(orgID string, change breachChange) {
+	attrs := []any{
+		slog.String("tenant_id", orgID),
+		slog.Int64("breach_id", change.breach.ID),
+		slog.Int64("storage_room_id", int64(change.breach.StorageRoomID)),
+		slog.String("zone_type", change.breach.ZoneType),
+		slog.Float64("min_celsius", change.breach.MinCelsius),
+		slog.Float64("max_celsius", change.breach.MaxCelsius),
+		slog.Float64("peak_celsius", change.breach.PeakCelsius),
+	}
+	if change.resolved {
+		slog.Info("Temperature breach resolved", attrs...)
+		return
+	}
+	slog.Warn("Temperature breach", attrs...)
+	if h.prometheusMetrics != nil {
+		h.prometheusMetrics.RecordTemperatureBreach(orgID, change.breach.ZoneType)
+	}
+}
+
+// ListTemperatureBreaches returns breaches of the tenant, newest first.
+// ?open=true limits them to ongoing ones and ?storage_room_id= to one room.
+// temperatureBreachListSpec sorts and filters the breaches of
+// ListTemperatureBreaches
+var temperatureBreachListSpec = &listquery.Spec{
+	Table: "temperature_breach",
+	Sort: map[string]string{
+		"id":           "id",
+		"started_at":   "started_at",
+		"resolved_at":  "resolved_at",
+		"peak_celsius": "peak_celsius",
+	},
+	Filters: map[string]listquery.Filter{
+		"zone_type":      {Column: "zone_type", Op: listquery.Eq, Parse: listquery.Text},
+		"started_after":  {Column: "started_at", Op: listquery.Gte, Parse: listquery.Time},
+		"started_before": {Column: "started_at", Op: listquery.Lt, Parse: listquery.Time},
+	},
+	Default: []listquery.Order{{Field: "started_at", Desc: true}, {Field: "id", Desc: true}},
+	Key:     "id",
+}
+
+func (h *Handlers) ListTemperatureBreaches(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "ListTemperatureBreaches")
+	defer span.End()
+
+	q, err := listParams(ctx, temperatureBreachListSpec)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	q.Where("org_id", listquery.Eq, orgID)
+	var openOnly bool
+	if v := ctx.Query("open"); v != "" {
+		if openOnly, err = strconv.ParseBool(v); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": tr(ctx, "open must be true or false"),
+			})
+			return
+		}
+	}
+	if openOnly {
+		q.Where("resolved_at", listquery.IsNull, true)
+	}
+	if v := ctx.Query("storage_room_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 32)
+		if err != nil || id <= 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": tr(ctx, "Invalid storage room ID format"),
+			})
+			return
+		}
+		q.Where("storage_room_id", listquery.Eq, int32(id))
+	}
+	span.SetAttributes(
+		attribute.Bool("temperature_breach.open_only", openOnly),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	breaches, err := queryPage[models.TemperatureBreach](ctx, spanCtx, h.db, q)
+	h.recordDBOperation(spanCtx, "list", "temperature_breach", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while listing temperature breaches: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to list temperature breaches"),
+		})
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int("temperature_breach.count", len(breaches)),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "List Temperature Breaches Successfully"),
+		"data":    mapSlice(breaches, newTemperatureBreachResponse),
+	})
+}
+
+// MaintainTemperaturePartitions creates the monthly temperature_reading
+// partitions for this and next month and drops partitions that ended more
+// than retention ago
+func (h *Handlers) MaintainTemperaturePartitions(ctx context.Context, retention time.Duration) error {
+	spanCtx, span := h.tracer.Start(ctx, "MaintainTemperaturePartitions")
+	defer span.End()
+
+	now := time.Now().UTC()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 2; i++ {
+		from, to := month.AddDate(0, i, 0), month.AddDate(0, i+1, 0)
+		sql := fmt.Sprintf(
+			"CREATE TABLE IF NOT EXISTS %s PARTITION OF temperature_reading FOR VALUES FROM ('%s') TO ('%s')",
+			pgx.Identifier{temperaturePartitionPrefix + from.Format("2006_01")}.Sanitize(),
+			from.Format(time.RFC3339), to.Format(time.RFC3339),
+		)
+		dbStart := time.Now()
+		_, err := h.db.Exec(spanCtx, sql)
+		h.recordDBOperation(spanCtx, "create", "temperature_reading", dbStart, err)
+		if err != nil {
+			span.RecordError(err)
+			return fmt.Errorf("create partition for %s: %w", from.Format("2006-01"), err)
+		}
+	}
+
+	dbStart := time.Now()
+	partitions, err := h.queries.ListTemperaturePartitions(spanCtx)
+	h.recordDBOperation(spanCtx, "list", "temperature_reading", dbStart, err)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	cutoff := now.Add(-retention)
+	dropped := 0
+	for _, name := range partitions {
+		from, err := time.Parse("2006_01", strings.TrimPrefix(name, temperaturePartitionPrefix))
+		// The default partition and partitions not created here are kept
+		if err != nil || !strings.HasPrefix(name, temperaturePartitionPrefix) {
+			continue
+		}
+		if !from.AddDate(0, 1, 0).Before(cutoff) {
+			continue
+		}
+		dbStart = time.Now()
+		_, err = h.db.Exec(spanCtx, "DROP TABLE IF EXISTS "+pgx.Identifier{name}.Sanitize())
+		h.recordDBOperation(spanCtx, "delete", "temperature_reading", dbStart, err)
+		if err != nil {
+			span.RecordError(err)
+			return fmt.Errorf("drop partition %s: %w", name, err)
+		}
+		dropped++
+	}
+
+	span.SetAttributes(attribute.Int("temperature_reading.partitions_dropped", dropped))
+	if dropped > 0 {
+		slog.Info("Dropped temperature reading partitions", slog.Int("count", dropped))
+	}
+	return nil
+}
@@ -0,0 +1,425 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	"warehouse-service/jobs"
+	"warehouse-service/listquery"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// tenantDeletionGrace is how long a deletion waits before it runs, the
+// time an administrator has to cancel it
+const tenantDeletionGrace = 72 * time.Hour
+
+type tenantExportRequest struct {
+	Format string `json:"format" binding:"omitempty,oneof=json csv"`
+}
+
+type tenantDeletionRequest struct {
+	Mode string `json:"mode" binding:"required,oneof=purge anonymize"`
+	// The organization ID again, against deleting the wrong tenant
+	Confirm string `json:"confirm" binding:"required"`
+}
+
+// tenantParam checks the tenant of the path is the caller's organization,
+// writing the error response itself when it returns false. Another
+// organization is answered like one that does not exist.
+func tenantParam(ctx *gin.Context) (string, bool) {
+	orgID := tenantID(ctx)
+	if ctx.Param("id") != orgID {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Tenant not found"),
+		})
+		return "", false
+	}
+	return orgID, true
+}
+
+// ExportTenant queues an archive of all of the tenant's data, one file per
+// table in JSON lines or CSV. The archive is stored in the object store,
+// GetTenantExport returns its download URL once it completed.
+func (h *Handlers) ExportTenant(ctx *gin.Context) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "ExportTenant")
+	defer span.End()
+
+	orgID, ok := tenantParam(ctx)
+	if !ok {
+		return
+	}
+	// The body is optional, the format defaults to JSON
+	var req tenantExportRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   tr(ctx, "Invalid export payload"),
+			"details": err.Error(),
+		})
+		return
+	}
+	if req.Format == "" {
+		req.Format = exportFormatJSON
+	}
+	if h.attachments.Store == nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": tr(ctx, "Object storage is not configured"),
+		})
+		return
+	}
+	span.SetAttributes(
+		attribute.String("tenant.id", orgID),
+		attribute.String("export.format", req.Format),
+	)
+
+	var export models.TenantExport
+	err := pgx.BeginFunc(spanCtx, h.db, func(tx pgx.Tx) error {
+		qtx := h.queries.WithTx(tx)
+		dbStart := time.Now()
+		var err error
+		export, err = qtx.CreateTenantExport(spanCtx, models.CreateTenantExportParams{
+			OrgID:       orgID,
+			Format:      req.Format,
+			RequestedBy: actorID(ctx),
+		})
+		h.recordDBOperation(spanCtx, "create", "tenant_export", dbStart, err)
+		if err != nil {
+			return err
+		}
+		dbStart = time.Now()
+		_, err = jobs.Enqueue(spanCtx, qtx, orgID, JobKindExportTenant, tenantExportJob{
+			ExportID: export.ID,
+		}, jobs.EnqueueOptions{})
+		h.recordDBOperation(spanCtx, "create", "job", dbStart, err)
+		return err
+	})
+	if err != nil {
+		slog.Error("Could not queue tenant export: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		h.recordOperation(orgID, observability.EntityTenant, "export", opStart, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to export tenant"),
+		})
+		return
+	}
+
+	slog.Info("Tenant export requested",
+		slog.String("tenant_id", orgID),
+		slog.Int64("export_id", export.ID),
+		slog.String("user_id", actorID(ctx)),
+	)
+	span.SetAttributes(
+		attribute.Int64("export.id", export.ID),
+		attribute.String("operation.status", "success"),
+	)
+	ctx.Header("Location", fmt.Sprintf("/v1/admin/tenants/%s/exports/%d", orgID, export.ID))
+	respondV1(ctx, http.StatusAccepted, gin.H{
+		"message": tr(ctx, "Export Tenant Successfully"),
+		"data":    newTenantExportResponse(export),
+	})
+}
+
+// tenantExportListSpec sorts and filters the exports of ListTenantExports
+var tenantExportListSpec = &listquery.Spec{
+	Table: "tenant_export",
+	Sort: map[string]string{
+		"id":           "id",
+		"created_at":   "created_at",
+		"completed_at": "completed_at",
+		"size_bytes":   "size_bytes",
+	},
+	Filters: map[string]listquery.Filter{
+		"status": {Column: "status", Op: listquery.Eq, Parse: listquery.OneOf(exportStatusPending, exportStatusCompleted, exportStatusFailed)},
+		"format": {Column: "format", Op: listquery.Eq, Parse: listquery.OneOf(exportFormatJSON, exportFormatCSV)},
+	},
+	Default: []listquery.Order{{Field: "id", Desc: true}},
+	Key:     "id",
+}
+
+// ListTenantExports lists the exports of the tenant, newest first
+func (h *Handlers) ListTenantExports(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "ListTenantExports")
+	defer span.End()
+
+	orgID, ok := tenantParam(ctx)
+	if !ok {
+		return
+	}
+	q, err := listParams(ctx, tenantExportListSpec)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	q.Where("org_id", listquery.Eq, orgID)
+	span.SetAttributes(attribute.String("tenant.id", orgID))
+
+	dbStart := time.Now()
+	exports, err := queryPage[models.TenantExport](ctx, spanCtx, h.db, q)
+	h.recordDBOperation(spanCtx, "list", "tenant_export", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while listing tenant exports: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to list exports"),
+		})
+		return
+	}
+
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "List Exports Successfully"),
+		"data":    mapSlice(exports, newTenantExportResponse),
+	})
+}
+
+// GetTenantExport returns an export, with a presigned download URL of its
+// archive once it completed
+func (h *Handlers) GetTenantExport(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "GetTenantExport")
+	defer span.End()
+
+	orgID, ok := tenantParam(ctx)
+	if !ok {
+		return
+	}
+	exportID, err := strconv.ParseInt(ctx.Param("export_id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "Invalid export ID format"),
+		})
+		return
+	}
+	span.SetAttributes(
+		attribute.Int64("export.id", exportID),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	export, err := h.queries.GetTenantExport(spanCtx, models.GetTenantExportParams{
+		ID:    exportID,
+		OrgID: orgID,
+	})
+	h.recordDBOperation(spanCtx, "get", "tenant_export", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Export not found"),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Got an error while getting tenant export: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to get export"),
+		})
+		return
+	}
+
+	response := newTenantExportResponse(export)
+	if export.Status == exportStatusCompleted && h.attachments.Store != nil {
+		expiresAt := time.Now().Add(h.attachments.URLTTL).UTC()
+		fileName := fmt.Sprintf("%s-export-%d.zip", orgID, export.ID)
+		response.DownloadURL, err = h.attachments.Store.PresignGet(spanCtx, export.ObjectKey, fileName, h.attachments.URLTTL)
+		if err != nil {
+			slog.Error("Got an error while presigning export URL: ", slog.Any("err", err.Error()))
+			span.RecordError(err)
+			ctx.JSON(http.StatusBadGateway, gin.H{
+				"error": tr(ctx, "Failed to create download URL"),
+			})
+			return
+		}
+		response.ExpiresAt = &expiresAt
+	}
+
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Get Export Successfully"),
+		"data":    response,
+	})
+}
+
+// RequestTenantDeletion schedules the deletion of all of the tenant's data
+// after tenantDeletionGrace. A purge deletes every row and stored file; an
+// anonymization deletes the files, credentials and logs and keeps the
+// operational rows under a pseudonym with personal data blanked. The
+// deletion's report verifies nothing was left.
+func (h *Handlers) RequestTenantDeletion(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "RequestTenantDeletion")
+	defer span.End()
+
+	orgID, ok := tenantParam(ctx)
+	if !ok {
+		return
+	}
+	var req tenantDeletionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   tr(ctx, "Invalid deletion payload"),
+			"details": err.Error(),
+		})
+		return
+	}
+	if req.Confirm != orgID {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "confirm must be the organization ID"),
+			"field": "confirm",
+		})
+		return
+	}
+	span.SetAttributes(
+		attribute.String("tenant.id", orgID),
+		attribute.String("deletion.mode", req.Mode),
+	)
+
+	runAfter := time.Now().Add(tenantDeletionGrace)
+	var deletion models.TenantDeletion
+	err := pgx.BeginFunc(spanCtx, h.db, func(tx pgx.Tx) error {
+		qtx := h.queries.WithTx(tx)
+		dbStart := time.Now()
+		var err error
+		deletion, err = qtx.CreateTenantDeletion(spanCtx, models.CreateTenantDeletionParams{
+			OrgID:       orgID,
+			Mode:        req.Mode,
+			RunAfter:    pgtype.Timestamptz{Time: runAfter, Valid: true},
+			RequestedBy: actorID(ctx),
+		})
+		h.recordDBOperation(spanCtx, "create", "tenant_deletion", dbStart, err)
+		if err != nil {
+			return err
+		}
+		dbStart = time.Now()
+		_, err = jobs.Enqueue(spanCtx, qtx, orgID, JobKindDeleteTenant, tenantDeletionJob{
+			DeletionID: deletion.ID,
+		}, jobs.EnqueueOptions{RunAt: runAfter})
+		h.recordDBOperation(spanCtx, "create", "job", dbStart, err)
+		return err
+	})
+	if conflict, ok := conflictFor(err); ok {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": tr(ctx, conflict.message),
+			"field": conflict.field,
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Could not schedule tenant deletion: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to schedule deletion"),
+		})
+		return
+	}
+
+	slog.Warn("Tenant deletion scheduled",
+		slog.String("tenant_id", orgID),
+		slog.Int64("deletion_id", deletion.ID),
+		slog.String("mode", deletion.Mode),
+		slog.Time("run_after", runAfter),
+		slog.String("user_id", actorID(ctx)),
+	)
+	span.SetAttributes(
+		attribute.Int64("deletion.id", deletion.ID),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusAccepted, gin.H{
+		"message": tr(ctx, "Schedule Deletion Successfully"),
+		"data":    newTenantDeletionResponse(deletion),
+	})
+}
+
+// GetTenantDeletion returns the latest deletion of the tenant with its
+// verification report
+func (h *Handlers) GetTenantDeletion(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "GetTenantDeletion")
+	defer span.End()
+
+	orgID, ok := tenantParam(ctx)
+	if !ok {
+		return
+	}
+	span.SetAttributes(attribute.String("tenant.id", orgID))
+
+	dbStart := time.Now()
+	deletion, err := h.queries.GetLatestTenantDeletion(spanCtx, orgID)
+	h.recordDBOperation(spanCtx, "get", "tenant_deletion", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "No deletion was requested"),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Got an error while getting tenant deletion: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to get deletion"),
+		})
+		return
+	}
+
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Get Deletion Successfully"),
+		"data":    newTenantDeletionResponse(deletion),
+	})
+}
+
+// CancelTenantDeletion cancels the scheduled deletion of the tenant. One
+// that started running can't be cancelled.
+func (h *Handlers) CancelTenantDeletion(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "CancelTenantDeletion")
+	defer span.End()
+
+	orgID, ok := tenantParam(ctx)
+	if !ok {
+		return
+	}
+	span.SetAttributes(attribute.String("tenant.id", orgID))
+
+	dbStart := time.Now()
+	deletion, err := h.queries.CancelTenantDeletion(spanCtx, orgID)
+	h.recordDBOperation(spanCtx, "update", "tenant_deletion", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": tr(ctx, "No deletion is scheduled"),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Could not cancel tenant deletion: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to cancel deletion"),
+		})
+		return
+	}
+
+	slog.Info("Tenant deletion cancelled",
+		slog.String("tenant_id", orgID),
+		slog.Int64("deletion_id", deletion.ID),
+		slog.String("user_id", actorID(ctx)),
+	)
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Cancel Deletion Successfully"),
+		"data":    newTenantDeletionResponse(deletion),
+	})
+}
@@ -0,0 +1,632 @@
+package handlers
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"time"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// JobKindExportTenant writes the archive of a tenant export
+const JobKindExportTenant = "export_tenant"
+
+// JobKindDeleteTenant purges or anonymizes the data of a tenant, enqueued to
+// run once the grace period of the deletion passed
+const JobKindDeleteTenant = "delete_tenant"
+
+// Formats of the tables of an export archive
+const (
+	exportFormatJSON = "json"
+	exportFormatCSV  = "csv"
+)
+
+// Statuses of a tenant export, failed once its job ran out of attempts
+const (
+	exportStatusPending   = "pending"
+	exportStatusCompleted = "completed"
+	exportStatusFailed    = "failed"
+)
+
+// Modes and statuses of a tenant deletion
+const (
+	deletionModePurge     = "purge"
+	deletionModeAnonymize = "anonymize"
+
+	deletionStatusCompleted = "completed"
+	deletionStatusFailed    = "failed"
+)
+
+type tenantExportJob struct {
+	ExportID int64 `json:"export_id"`
+}
+
+type tenantDeletionJob struct {
+	DeletionID int64 `json:"deletion_id"`
+}
+
+// byOrg scopes a table holding org_id to the tenant, $1
+const byOrg = "org_id = $1"
+
+// tenantTable is a table holding tenant data. where scopes it to the tenant
+// in $1, byOrg when empty; child tables without org_id go through their
+// parent. scrub maps the columns holding personal data or credentials to the
+// SQL literal an anonymization blanks them with, drop deletes the rows an
+// anonymization would otherwise keep, and secret lists the columns left out
+// of exports.
+type tenantTable struct {
+	name   string
+	where  string
+	scrub  map[string]string
+	drop   bool
+	secret []string
+}
+
+func (t tenantTable) scope() string {
+	if t.where == "" {
+		return byOrg
+	}
+	return t.where
+}
+
+// childOf scopes a table through the parent its column references
+func childOf(parent, column string) string {
+	return pgx.Identifier{column}.Sanitize() + " IN (SELECT id FROM " + pgx.Identifier{parent}.Sanitize() + " WHERE org_id = $1)"
+}
+
+// tenantTables lists every table holding tenant data, children before the
+// tables they reference so that deleting in order never breaks a foreign
+// key. row_history comes after warehouse and storage_room, whose deletions
+// it records. A table added with tenant data belongs here, or deleting a
+// tenant leaves it behind.
+var tenantTables = []tenantTable{
+	{name: "saga_step", where: childOf("saga", "saga_id")},
+	{name: "saga"},
+	{name: "wave_pick_list", where: childOf("wave", "wave_id")},
+	{name: "wave"},
+	{name: "integration_order"},
+	{name: "integration_stock", where: childOf("integration", "integration_id")},
+	{name: "integration", scrub: map[string]string{"token": "''"}, secret: []string{"token"}},
+	{name: "pick_list_line", where: childOf("pick_list", "pick_list_id")},
+	{name: "pick_list"},
+	{name: "file_exchange_content", where: childOf("file_exchange_file", "file_id"), drop: true},
+	{name: "file_exchange_file"},
+	{name: "file_exchange_run"},
+	{name: "file_exchange", scrub: map[string]string{"inbound_url": "''", "outbound_url": "''"}},
+	{name: "edi_document", drop: true},
+	{name: "receipt_line", where: childOf("receipt", "receipt_id")},
+	{name: "receipt"},
+	{name: "count_line", where: childOf("count_session", "count_session_id")},
+	{name: "count_session"},
+	{name: "transfer_order_line", where: childOf("transfer_order", "transfer_order_id")},
+	{name: "transfer_order"},
+	{name: "serial_movement", where: childOf("serial", "serial_id"), scrub: map[string]string{"actor": "''"}},
+	{name: "serial"},
+	{name: "temperature_breach"},
+	{name: "temperature_reading"},
+	{name: "stock_adjustment"},
+	{name: "stock_level"},
+	{name: "attachment", drop: true},
+	{name: "storage_room"},
+	{name: "warehouse_address"},
+	{name: "warehouse", scrub: map[string]string{"contact_email": "NULL", "contact_phone": "NULL"}},
+	{name: "item_unit", where: childOf("item", "item_id")},
+	{name: "item"},
+	{name: "partner", scrub: map[string]string{"contact_name": "''", "email": "''", "phone": "''", "sftp_url": "''"}},
+	{name: "printer"},
+	{name: "attribute_schema", scrub: map[string]string{"updated_by": "''"}},
+	{name: "tenant_setting", scrub: map[string]string{"updated_by": "''"}},
+	{name: "tenant_export", drop: true},
+	{name: "api_key", drop: true, secret: []string{"secret_hash"}},
+	{name: "api_usage", drop: true},
+	{name: "tenant_metering"},
+	{name: "audit_log", scrub: map[string]string{"actor": "''"}},
+	{name: "change_event", drop: true},
+	{name: "row_history", drop: true},
+	{name: "outbox", drop: true},
+	{name: "dead_letter", drop: true},
+	{name: "processed_message", drop: true},
+	// The deletion's own job is left to the runner
+	{name: "job", where: byOrg + " AND kind <> '" + JobKindDeleteTenant + "'", drop: true},
+}
+
+// scrubbed returns the columns of scrub in order, for stable SQL
+func (t tenantTable) scrubbed() []string {
+	columns := make([]string, 0, len(t.scrub))
+	for column := range t.scrub {
+		columns = append(columns, column)
+	}
+	slices.Sort(columns)
+	return columns
+}
+
+// anonymizeSQL updates the tenant's rows of t to the pseudonym in $2 and
+// blanks its scrubbed columns. A child table only has its columns blanked,
+// its rows follow their parent. It is empty when there is nothing to do.
+func (t tenantTable) anonymizeSQL() string {
+	var set []string
+	if t.where == "" {
+		set = append(set, "org_id = $2")
+	}
+	for _, column := range t.scrubbed() {
+		set = append(set, pgx.Identifier{column}.Sanitize()+" = "+t.scrub[column])
+	}
+	if len(set) == 0 {
+		return ""
+	}
+	return "UPDATE " + pgx.Identifier{t.name}.Sanitize() + " SET " + strings.Join(set, ", ") + " WHERE " + t.scope()
+}
+
+// personalSQL counts the rows of the tenant in $1 still holding data the
+// anonymization blanks. It is empty for a table without scrubbed columns.
+func (t tenantTable) personalSQL() string {
+	if len(t.scrub) == 0 {
+		return ""
+	}
+	var held []string
+	for _, column := range t.scrubbed() {
+		held = append(held, pgx.Identifier{column}.Sanitize()+" IS DISTINCT FROM "+t.scrub[column])
+	}
+	return "SELECT count(*) FROM " + pgx.Identifier{t.name}.Sanitize() + " WHERE " + t.scope() + " AND (" + strings.Join(held, " OR ") + ")"
+}
+
+// exportValue formats a column value for a CSV cell: times in RFC 3339,
+// binary data in base64 and composite values as JSON
+func exportValue(v any) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano), nil
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v), nil
+	case bool, int16, int32, int64, float32, float64:
+		return fmt.Sprint(v), nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// exportManifest describes an export archive, written as manifest.json
+type exportManifest struct {
+	OrgID      string              `json:"org_id"`
+	Format     string              `json:"format"`
+	ExportedAt time.Time           `json:"exported_at"`
+	Tables     map[string]int64    `json:"tables"`
+	Omitted    map[string][]string `json:"omitted_columns"`
+}
+
+// writeExportTable writes the tenant's rows of t to w, one JSON object per
+// line or CSV with a header, and returns how many it wrote
+func writeExportTable(ctx context.Context, tx pgx.Tx, w io.Writer, t tenantTable, orgID, format string) (int64, error) {
+	rows, err := tx.Query(ctx, "SELECT * FROM "+pgx.Identifier{t.name}.Sanitize()+" WHERE "+t.scope(), orgID)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var columns []string
+	var keep []int
+	for i, field := range rows.FieldDescriptions() {
+		if slices.Contains(t.secret, field.Name) {
+			continue
+		}
+		columns = append(columns, field.Name)
+		keep = append(keep, i)
+	}
+
+	var cw *csv.Writer
+	if format == exportFormatCSV {
+		cw = csv.NewWriter(w)
+		if err := cw.Write(columns); err != nil {
+			return 0, err
+		}
+	}
+	encoder := json.NewEncoder(w)
+	var n int64
+	for rows.Next() {
+		values, err := rows.Values()
+		if err != nil {
+			return n, err
+		}
+		if cw != nil {
+			record := make([]string, len(keep))
+			for j, i := range keep {
+				if record[j], err = exportValue(values[i]); err != nil {
+					return n, fmt.Errorf("%s.%s: %w", t.name, columns[j], err)
+				}
+			}
+			if err := cw.Write(record); err != nil {
+				return n, err
+			}
+		} else {
+			record := make(map[string]any, len(keep))
+			for j, i := range keep {
+				record[columns[j]] = values[i]
+			}
+			if err := encoder.Encode(record); err != nil {
+				return n, fmt.Errorf("%s: %w", t.name, err)
+			}
+		}
+		n++
+	}
+	if err := rows.Err(); err != nil {
+		return n, err
+	}
+	if cw != nil {
+		cw.Flush()
+		return n, cw.Error()
+	}
+	return n, nil
+}
+
+// writeExport writes the archive of the tenant's data to file, from one
+// snapshot of the database, and returns the rows written per table
+func (h *Handlers) writeExport(ctx context.Context, file io.Writer, export models.TenantExport) (map[string]int64, error) {
+	archive := zip.NewWriter(file)
+	counts := map[string]int64{}
+	omitted := map[string][]string{}
+	ext := ".ndjson"
+	if export.Format == exportFormatCSV {
+		ext = ".csv"
+	}
+
+	err := pgx.BeginTxFunc(ctx, h.db, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}, func(tx pgx.Tx) error {
+		for _, t := range tenantTables {
+			w, err := archive.Create(t.name + ext)
+			if err != nil {
+				return err
+			}
+			dbStart := time.Now()
+			counts[t.name], err = writeExportTable(ctx, tx, w, t, export.OrgID, export.Format)
+			h.recordDBOperation(ctx, "export", t.name, dbStart, err)
+			if err != nil {
+				return fmt.Errorf("export %s: %w", t.name, err)
+			}
+			if len(t.secret) > 0 {
+				omitted[t.name] = t.secret
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := archive.Create("manifest.json")
+	if err != nil {
+		return nil, err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(exportManifest{
+		OrgID:      export.OrgID,
+		Format:     export.Format,
+		ExportedAt: time.Now().UTC(),
+		Tables:     counts,
+		Omitted:    omitted,
+	}); err != nil {
+		return nil, err
+	}
+	return counts, archive.Close()
+}
+
+// exportObjectKey is where the archive of an export is stored
+func exportObjectKey(export models.TenantExport) string {
+	return fmt.Sprintf("tenant-exports/%s/%d.zip", export.OrgID, export.ID)
+}
+
+// ExportTenantJob runs an export_tenant job: the archive is written to a
+// temporary file, uploaded to the object store and the export completed.
+// An export whose job runs out of attempts is failed.
+func (h *Handlers) ExportTenantJob(ctx context.Context, job models.Job) error {
+	opStart := time.Now()
+	spanCtx, span := h.tracer.Start(ctx, "ExportTenantJob")
+	defer span.End()
+
+	var payload tenantExportJob
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return fmt.Errorf("decode export job: %w", err)
+	}
+	span.SetAttributes(
+		attribute.Int64("export.id", payload.ExportID),
+		attribute.String("tenant.id", job.OrgID),
+	)
+
+	dbStart := time.Now()
+	export, err := h.queries.GetTenantExport(spanCtx, models.GetTenantExportParams{
+		ID:    payload.ExportID,
+		OrgID: job.OrgID,
+	})
+	h.recordDBOperation(spanCtx, "get", "tenant_export", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("export %d was deleted", payload.ExportID)
+	}
+	if err != nil {
+		return err
+	}
+	if export.Status != exportStatusPending {
+		return nil
+	}
+
+	err = h.exportTenant(spanCtx, export)
+	h.recordOperation(job.OrgID, observability.EntityTenant, "export", opStart, err)
+	if err != nil {
+		span.RecordError(err)
+		if job.Attempts >= job.MaxAttempts {
+			dbStart := time.Now()
+			failErr := h.queries.FailTenantExport(spanCtx, models.FailTenantExportParams{
+				ID:    export.ID,
+				Error: err.Error(),
+			})
+			h.recordDBOperation(spanCtx, "update", "tenant_export", dbStart, failErr)
+			if failErr != nil {
+				return errors.Join(err, failErr)
+			}
+		}
+		return err
+	}
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	return nil
+}
+
+func (h *Handlers) exportTenant(ctx context.Context, export models.TenantExport) error {
+	if h.attachments.Store == nil {
+		return errors.New("object storage is not configured")
+	}
+	file, err := os.CreateTemp("", "tenant-export-*.zip")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	hash := sha256.New()
+	counts, err := h.writeExport(ctx, io.MultiWriter(file, hash), export)
+	if err != nil {
+		return err
+	}
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	checksum := hex.EncodeToString(hash.Sum(nil))
+	key := exportObjectKey(export)
+	if err := h.attachments.Store.Put(ctx, key, file, size, "application/zip", checksum); err != nil {
+		return fmt.Errorf("upload export: %w", err)
+	}
+
+	tables, err := json.Marshal(counts)
+	if err != nil {
+		return err
+	}
+	dbStart := time.Now()
+	_, err = h.queries.CompleteTenantExport(ctx, models.CompleteTenantExportParams{
+		ID:        export.ID,
+		ObjectKey: key,
+		SizeBytes: size,
+		Checksum:  checksum,
+		Tables:    tables,
+	})
+	h.recordDBOperation(ctx, "update", "tenant_export", dbStart, err)
+	if err != nil {
+		return err
+	}
+	slog.Info("Tenant exported",
+		slog.String("tenant_id", export.OrgID),
+		slog.Int64("export_id", export.ID),
+		slog.Int64("size_bytes", size),
+	)
+	return nil
+}
+
+// tenantDeletionReport is the verification report of a deletion
+type tenantDeletionReport struct {
+	Mode           string              `json:"mode"`
+	ObjectsDeleted int                 `json:"objects_deleted"`
+	Tables         []tenantTableReport `json:"tables"`
+	// Rows left under the tenant, plus rows of the pseudonym still holding
+	// personal data; the deletion is verified when there are none
+	Remaining int64 `json:"remaining"`
+	Verified  bool  `json:"verified"`
+}
+
+type tenantTableReport struct {
+	Table string `json:"table"`
+	// deleted, anonymized or, for a child table, kept with its parent
+	Action string `json:"action"`
+	Rows   int64  `json:"rows"`
+	// Rows left under the tenant
+	Remaining int64 `json:"remaining"`
+	// Rows of the pseudonym still holding personal data
+	Personal int64 `json:"personal,omitempty"`
+}
+
+// deleteTenantData deletes the objects of the tenant, then purges or
+// anonymizes its rows in one transaction, and verifies nothing is left
+func (h *Handlers) deleteTenantData(ctx context.Context, deletion models.TenantDeletion) (tenantDeletionReport, error) {
+	report := tenantDeletionReport{Mode: deletion.Mode}
+
+	dbStart := time.Now()
+	keys, err := h.queries.ListTenantObjectKeys(ctx, deletion.OrgID)
+	h.recordDBOperation(ctx, "list", "attachment", dbStart, err)
+	if err != nil {
+		return report, err
+	}
+	if len(keys) > 0 && h.attachments.Store == nil {
+		return report, fmt.Errorf("object storage is not configured, %d objects can't be deleted", len(keys))
+	}
+	for _, key := range keys {
+		if err := h.attachments.Store.Delete(ctx, key); err != nil {
+			return report, fmt.Errorf("delete object %s: %w", key, err)
+		}
+		report.ObjectsDeleted++
+	}
+
+	err = pgx.BeginFunc(ctx, h.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SET LOCAL inventium.skip_change_feed = 'on'`); err != nil {
+			return err
+		}
+		report.Tables = report.Tables[:0]
+		for _, t := range tenantTables {
+			row := tenantTableReport{Table: t.name, Action: "kept"}
+			var sql, operation string
+			var args []any
+			switch {
+			case deletion.Mode == deletionModePurge || t.drop:
+				sql, args = "DELETE FROM "+pgx.Identifier{t.name}.Sanitize()+" WHERE "+t.scope(), []any{deletion.OrgID}
+				row.Action, operation = "deleted", "delete"
+			case t.anonymizeSQL() != "":
+				sql, args = t.anonymizeSQL(), []any{deletion.OrgID, deletion.Pseudonym}
+				row.Action, operation = "anonymized", "update"
+			}
+			if sql != "" {
+				dbStart := time.Now()
+				tag, err := tx.Exec(ctx, sql, args...)
+				h.recordDBOperation(ctx, operation, t.name, dbStart, err)
+				if err != nil {
+					return fmt.Errorf("%s: %w", t.name, err)
+				}
+				row.Rows = tag.RowsAffected()
+			}
+			report.Tables = append(report.Tables, row)
+		}
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+
+	// Verified after the commit, from what other sessions see
+	for i, t := range tenantTables {
+		if err := h.db.QueryRow(ctx, "SELECT count(*) FROM "+pgx.Identifier{t.name}.Sanitize()+" WHERE "+t.scope(), deletion.OrgID).
+			Scan(&report.Tables[i].Remaining); err != nil {
+			return report, fmt.Errorf("verify %s: %w", t.name, err)
+		}
+		report.Remaining += report.Tables[i].Remaining
+		if deletion.Mode != deletionModeAnonymize || t.drop || t.personalSQL() == "" {
+			continue
+		}
+		if err := h.db.QueryRow(ctx, t.personalSQL(), deletion.Pseudonym).Scan(&report.Tables[i].Personal); err != nil {
+			return report, fmt.Errorf("verify %s: %w", t.name, err)
+		}
+		report.Remaining += report.Tables[i].Personal
+	}
+	report.Verified = report.Remaining == 0
+	return report, nil
+}
+
+// DeleteTenantJob runs a delete_tenant job. A deletion cancelled before the
+// job ran is left alone. The deletion fails when the verification finds
+// rows left, or when its job runs out of attempts.
+func (h *Handlers) DeleteTenantJob(ctx context.Context, job models.Job) error {
+	opStart := time.Now()
+	spanCtx, span := h.tracer.Start(ctx, "DeleteTenantJob")
+	defer span.End()
+
+	var payload tenantDeletionJob
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return fmt.Errorf("decode deletion job: %w", err)
+	}
+	span.SetAttributes(
+		attribute.Int64("deletion.id", payload.DeletionID),
+		attribute.String("tenant.id", job.OrgID),
+	)
+
+	dbStart := time.Now()
+	deletion, err := h.queries.StartTenantDeletion(spanCtx, models.StartTenantDeletionParams{
+		ID:        payload.DeletionID,
+		Pseudonym: "anonymized:" + uuid.NewString(),
+	})
+	h.recordDBOperation(spanCtx, "update", "tenant_deletion", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		span.SetAttributes(attribute.Bool("deletion.cancelled", true))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if deletion.OrgID != job.OrgID {
+		return fmt.Errorf("deletion %d is not of organization %s", deletion.ID, job.OrgID)
+	}
+
+	report, runErr := h.deleteTenantData(spanCtx, deletion)
+	h.recordOperation(job.OrgID, observability.EntityTenant, deletion.Mode, opStart, runErr)
+	if runErr != nil {
+		span.RecordError(runErr)
+		if job.Attempts < job.MaxAttempts {
+			return runErr
+		}
+	}
+
+	status, reason := deletionStatusCompleted, ""
+	switch {
+	case runErr != nil:
+		status, reason = deletionStatusFailed, runErr.Error()
+	case !report.Verified:
+		status, reason = deletionStatusFailed, fmt.Sprintf("%d rows left after the deletion", report.Remaining)
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	dbStart = time.Now()
+	_, err = h.queries.FinishTenantDeletion(spanCtx, models.FinishTenantDeletionParams{
+		ID:     deletion.ID,
+		Status: status,
+		Report: data,
+		Error:  reason,
+	})
+	h.recordDBOperation(spanCtx, "update", "tenant_deletion", dbStart, err)
+	if err != nil {
+		return errors.Join(runErr, err)
+	}
+	if runErr != nil {
+		return runErr
+	}
+
+	// The views aggregate the tenant's rows until they refresh
+	if err := h.RefreshDashboardStats(spanCtx); err != nil {
+		slog.Error("Failed to refresh the dashboard view after a tenant deletion", slog.Any("error", err))
+	}
+	if err := h.RefreshSkuConsumption(spanCtx); err != nil {
+		slog.Error("Failed to refresh the consumption view after a tenant deletion", slog.Any("error", err))
+	}
+
+	slog.Info("Tenant data deleted",
+		slog.String("tenant_id", deletion.OrgID),
+		slog.Int64("deletion_id", deletion.ID),
+		slog.String("mode", deletion.Mode),
+		slog.String("status", status),
+		slog.Int64("remaining", report.Remaining),
+	)
+	span.SetAttributes(
+		attribute.String("deletion.status", status),
+		attribute.String("operation.status", "success"),
+	)
+	return nil
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Tables of the schema not holding tenant data
+var nonTenantTables = []string{"materialized_view_refresh", "tenant_deletion"}
+
+// TestTenantTablesCoverSchema fails for a table of the migrations missing
+// from tenantTables, which deleting a tenant would leave behind
+func TestTenantTablesCoverSchema(t *testing.T) {
+	files, err := filepath.Glob("../models/migration/*.up.sql")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no migrations: %v", err)
+	}
+	// Partitions are reached through their table
+	created := regexp.MustCompile(`(?m)^CREATE TABLE "?([a-z_]+)"?( PARTITION OF)?`)
+	dropped := regexp.MustCompile(`(?m)^DROP TABLE (?:IF EXISTS )?"?([a-z_]+)"?`)
+	tables := map[string]bool{}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range created.FindAllStringSubmatch(string(data), -1) {
+			if m[2] == "" {
+				tables[m[1]] = true
+			}
+		}
+		for _, m := range dropped.FindAllStringSubmatch(string(data), -1) {
+			delete(tables, m[1])
+		}
+	}
+	listed := map[string]bool{}
+	for _, table := range tenantTables {
+		if listed[table.name] {
+			t.Errorf("%s is listed twice", table.name)
+		}
+		listed[table.name] = true
+		if !tables[table.name] {
+			t.Errorf("%s is not a table of the migrations", table.name)
+		}
+	}
+	for table := range tables {
+		if !listed[table] && !slices.Contains(nonTenantTables, table) {
+			t.Errorf("%s is missing from tenantTables", table)
+		}
+	}
+}
+
+func TestTenantTablesOrder(t *testing.T) {
+	index := map[string]int{}
+	for i, table := range tenantTables {
+		index[table.name] = i
+	}
+	for i, table := range tenantTables {
+		for parent, j := range index {
+			if strings.Contains(table.where, `FROM "`+parent+`"`) && j < i {
+				t.Errorf("%s comes after its parent %s", table.name, parent)
+			}
+		}
+	}
+	for _, recorded := range []string{"warehouse", "storage_room"} {
+		if index["row_history"] < index[recorded] {
+			t.Errorf("row_history comes before %s, whose deletions it records", recorded)
+		}
+	}
+}
+
+func TestTenantTableSQL(t *testing.T) {
+	byName := func(name string) tenantTable {
+		i := slices.IndexFunc(tenantTables, func(table tenantTable) bool { return table.name == name })
+		return tenantTables[i]
+	}
+	for _, c := range []struct {
+		table         string
+		anonymize     string
+		personalCheck string
+	}{
+		{
+			table:         "warehouse",
+			anonymize:     `UPDATE "warehouse" SET org_id = $2, "contact_email" = NULL, "contact_phone" = NULL WHERE org_id = $1`,
+			personalCheck: `SELECT count(*) FROM "warehouse" WHERE org_id = $1 AND ("contact_email" IS DISTINCT FROM NULL OR "contact_phone" IS DISTINCT FROM NULL)`,
+		},
+		{
+			table:         "serial_movement",
+			anonymize:     `UPDATE "serial_movement" SET "actor" = '' WHERE "serial_id" IN (SELECT id FROM "serial" WHERE org_id = $1)`,
+			personalCheck: `SELECT count(*) FROM "serial_movement" WHERE "serial_id" IN (SELECT id FROM "serial" WHERE org_id = $1) AND ("actor" IS DISTINCT FROM '')`,
+		},
+		{table: "item_unit"},
+		{table: "stock_level", anonymize: `UPDATE "stock_level" SET org_id = $2 WHERE org_id = $1`},
+	} {
+		table := byName(c.table)
+		if got := table.anonymizeSQL(); got != c.anonymize {
+			t.Errorf("%s anonymizes with %s", c.table, got)
+		}
+		if got := table.personalSQL(); got != c.personalCheck {
+			t.Errorf("%s checks personal data with %s", c.table, got)
+		}
+	}
+}
+
+func TestExportValue(t *testing.T) {
+	var price pgtype.Numeric
+	if err := price.Scan("12.50"); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		value any
+		want  string
+	}{
+		{nil, ""},
+		{"Main", "Main"},
+		{int64(42), "42"},
+		{true, "true"},
+		{time.Date(2026, 10, 15, 11, 0, 0, 0, time.FixedZone("CEST", 2*3600)), "2026-10-15T09:00:00Z"},
+		{[]byte{0xde, 0xad}, "3q0="},
+		{[]any{"cold", "dry"}, `["cold","dry"]`},
+		{map[string]any{"dock_count": 4.0}, `{"dock_count":4}`},
+		{price, "12.50"},
+	} {
+		got, err := exportValue(c.value)
+		if err != nil || got != c.want {
+			t.Errorf("exportValue(%v) = %q, %v, want %q", c.value, got, err, c.want)
+		}
+	}
+}
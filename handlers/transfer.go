@@ -0,0 +1,700 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"warehouse-service/listquery"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+	"warehouse-service/outbox"
+	"warehouse-service/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Transfer order lifecycle states
+const (
+	transferStatusOpen      = "open"
+	transferStatusInTransit = "in_transit"
+	transferStatusReceived  = "received"
+	transferStatusCancelled = "cancelled"
+)
+
+const auditEntityTransfer = "transfer_order"
+
+type transferItemRequest struct {
+	Sku      string `json:"sku" binding:"required"`
+	Quantity int32  `json:"quantity" binding:"gt=0"`
+	// Unit of the quantity, a registered unit of the item; base units when empty
+	Unit string `json:"unit"`
+}
+
+type createTransferRequest struct {
+	SourceWarehouseID      WarehouseRef          `json:"source_warehouse_id" binding:"required"`
+	DestinationWarehouseID WarehouseRef          `json:"destination_warehouse_id" binding:"required"`
+	Reference              string                `json:"reference"`
+	Items                  []transferItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+type shipTransferLineRequest struct {
+	LineID        int64 `json:"line_id" binding:"required"`
+	StorageRoomID int32 `json:"storage_room_id" binding:"required"`
+}
+
+// shipTransferRequest names the storage room of the source each line ships from
+type shipTransferRequest struct {
+	Lines []shipTransferLineRequest `json:"lines" binding:"required,min=1,dive"`
+}
+
+type receiveTransferLineRequest struct {
+	LineID        int64  `json:"line_id" binding:"required"`
+	StorageRoomID int32  `json:"storage_room_id" binding:"required"`
+	Quantity      int32  `json:"quantity" binding:"gt=0"`
+	Unit          string `json:"unit"`
+}
+
+type receiveTransferRequest struct {
+	Lines []receiveTransferLineRequest `json:"lines" binding:"required,min=1,dive"`
+	// Complete closes the transfer even if some units did not arrive
+	Complete bool `json:"complete"`
+	// Put the stock away even where it takes a room over its capacity
+	OverrideCapacity bool `json:"override_capacity"`
+}
+
+// transferError is a request the transfer order can't satisfy, answered
+// with status
+type transferError struct {
+	status  int
+	message string
+}
+
+func (e *transferError) Error() string {
+	return e.message
+}
+
+// transferShipRooms maps every line of the transfer to the storage room the
+// request ships it from. Each line must be named exactly once.
+func transferShipRooms(lines []models.TransferOrderLine, req []shipTransferLineRequest) (map[int64]int32, error) {
+	ofTransfer := make(map[int64]bool, len(lines))
+	for _, line := range lines {
+		ofTransfer[line.ID] = true
+	}
+	rooms := make(map[int64]int32, len(req))
+	for _, l := range req {
+		if !ofTransfer[l.LineID] {
+			return nil, &transferError{http.StatusBadRequest, fmt.Sprintf("Line %d does not belong to this transfer", l.LineID)}
+		}
+		if _, ok := rooms[l.LineID]; ok {
+			return nil, &transferError{http.StatusBadRequest, fmt.Sprintf("Line %d is named twice", l.LineID)}
+		}
+		rooms[l.LineID] = l.StorageRoomID
+	}
+	for _, line := range lines {
+		if _, ok := rooms[line.ID]; !ok {
+			return nil, &transferError{http.StatusBadRequest, fmt.Sprintf("Line %d has no storage room to ship from", line.ID)}
+		}
+	}
+	return rooms, nil
+}
+
+// transferReceived reports whether every shipped unit of the transfer arrived
+func transferReceived(lines []models.TransferOrderLine) bool {
+	for _, line := range lines {
+		if line.ReceivedQuantity < line.ShippedQuantity {
+			return false
+		}
+	}
+	return true
+}
+
+func parseTransferID(ctx *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "Invalid transfer order ID format"),
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// roomOfWarehouse checks a storage room of the request is in the warehouse
+// the transfer ships from or receives into
+func (h *Handlers) roomOfWarehouse(ctx context.Context, qtx *models.Queries, orgID string, roomID int32, warehouseID int64) error {
+	dbStart := time.Now()
+	room, err := qtx.GetStorageRoom(ctx, models.GetStorageRoomParams{
+		ID:    roomID,
+		OrgID: orgID,
+	})
+	h.recordDBOperation(ctx, "get", "storage_room", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && int64(room.WarehouseID) != warehouseID) {
+		return &transferError{http.StatusBadRequest, fmt.Sprintf("Storage room %d is not part of warehouse %d", roomID, warehouseID)}
+	}
+	return err
+}
+
+// respondTransferError answers a failed transfer operation
+func (h *Handlers) respondTransferError(ctx *gin.Context, orgID, operation string, opStart time.Time, err error) {
+	h.recordOperation(orgID, observability.EntityTransfer, operation, opStart, err)
+	var requestErr *transferError
+	if errors.As(err, &requestErr) {
+		ctx.JSON(requestErr.status, gin.H{
+			"error": requestErr.message,
+		})
+		return
+	}
+	if rejected, ok := unitRejected(err); ok {
+		respondUnitError(ctx, rejected)
+		return
+	}
+	if exceeded, ok := capacityExceeded(err); ok {
+		respondCapacityExceeded(ctx, exceeded)
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Transfer order not found"),
+		})
+		return
+	}
+	slog.Error("Could not "+operation+" transfer order: ", slog.Any("err", err.Error()))
+	ctx.JSON(dbErrorStatus(err), gin.H{
+		"error": fmt.Sprintf("Failed to %s transfer order", operation),
+	})
+}
+
+// CreateTransferOrder opens a transfer of stock from one warehouse to
+// another. Nothing moves until the transfer ships.
+func (h *Handlers) CreateTransferOrder(ctx *gin.Context) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "CreateTransferOrder")
+	defer span.End()
+
+	var req createTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   tr(ctx, "Invalid transfer order payload"),
+			"details": err.Error(),
+		})
+		return
+	}
+	if req.SourceWarehouseID == req.DestinationWarehouseID {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "Source and destination warehouse must differ"),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	tracing.Actor(span, orgID, actorID(ctx))
+	span.SetAttributes(
+		attribute.String("transfer.source_warehouse_ref", string(req.SourceWarehouseID)),
+		attribute.String("transfer.destination_warehouse_ref", string(req.DestinationWarehouseID)),
+		attribute.Int("transfer.items", len(req.Items)),
+	)
+
+	var order models.TransferOrder
+	var lines []models.TransferOrderLine
+	err := pgx.BeginFunc(spanCtx, h.db, func(tx pgx.Tx) error {
+		qtx := h.queries.WithTx(tx)
+		var ids []int64
+		for _, ref := range []WarehouseRef{req.SourceWarehouseID, req.DestinationWarehouseID} {
+			id, err := h.resolveWarehouse(spanCtx, qtx, orgID, ref)
+			if err == nil {
+				dbStart := time.Now()
+				_, err = qtx.GetWarehouse(spanCtx, models.GetWarehouseParams{
+					ID:    id,
+					OrgID: orgID,
+				})
+				h.recordDBOperation(spanCtx, "get", "warehouse", dbStart, err)
+			}
+			if errors.Is(err, pgx.ErrNoRows) {
+				return &transferError{http.StatusNotFound, fmt.Sprintf("Warehouse %s not found", ref)}
+			}
+			if err != nil {
+				return err
+			}
+			ids = append(ids, id)
+		}
+		// An ID and a slug may name the same warehouse
+		if ids[0] == ids[1] {
+			return &transferError{http.StatusBadRequest, "Source and destination warehouse must differ"}
+		}
+		span.SetAttributes(
+			attribute.Int64("transfer.source_warehouse_id", ids[0]),
+			attribute.Int64("transfer.destination_warehouse_id", ids[1]),
+		)
+
+		dbStart := time.Now()
+		var err error
+		order, err = qtx.CreateTransferOrder(spanCtx, models.CreateTransferOrderParams{
+			OrgID:                  orgID,
+			SourceWarehouseID:      ids[0],
+			DestinationWarehouseID: ids[1],
+			Reference:              req.Reference,
+		})
+		h.recordDBOperation(spanCtx, "create", "transfer_order", dbStart, err)
+		if err != nil {
+			return err
+		}
+
+		units := h.unitConverter(qtx, orgID)
+		for _, item := range req.Items {
+			quantity, err := units.toBase(spanCtx, item.Sku, item.Unit, item.Quantity)
+			if err != nil {
+				return err
+			}
+			dbStart = time.Now()
+			line, err := qtx.CreateTransferOrderLine(spanCtx, models.CreateTransferOrderLineParams{
+				TransferOrderID: order.ID,
+				Sku:             item.Sku,
+				Quantity:        quantity,
+			})
+			h.recordDBOperation(spanCtx, "create", "transfer_order_line", dbStart, err)
+			if err != nil {
+				return err
+			}
+			lines = append(lines, line)
+		}
+
+		if err := h.recordAudit(spanCtx, qtx, auditEntry{
+			OrgID:      orgID,
+			EntityType: auditEntityTransfer,
+			EntityID:   order.ID,
+			Action:     "create",
+			ToStatus:   order.Status,
+			Actor:      actorID(ctx),
+		}); err != nil {
+			return err
+		}
+		return h.enqueueTransferEvent(spanCtx, qtx, outbox.TopicTransferCreated, order, lines)
+	})
+	if err != nil {
+		tracing.Failed(span, err)
+		h.respondTransferError(ctx, orgID, "create", opStart, err)
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntityTransfer, "create", opStart, nil)
+
+	tracing.Entity(span, observability.EntityTransfer, order.ID)
+	tracing.Transition(span, observability.EntityTransfer, order.ID, "", order.Status)
+	tracing.Result(span, observability.StatusSuccess)
+	respondV1(ctx, http.StatusCreated, gin.H{
+		"message": tr(ctx, "Create Transfer Order Successfully"),
+		"data": gin.H{
+			"transfer": newTransferOrderResponse(order),
+			"lines":    mapSlice(lines, newTransferOrderLineResponse),
+		},
+	})
+}
+
+// GetTransferOrder returns a transfer order with its lines and the history
+// of its status
+func (h *Handlers) GetTransferOrder(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "GetTransferOrder")
+	defer span.End()
+
+	id, ok := parseTransferID(ctx)
+	if !ok {
+		return
+	}
+	orgID := tenantID(ctx)
+	traceOperation(ctx, span, observability.EntityTransfer, id)
+
+	dbStart := time.Now()
+	order, err := h.queries.GetTransferOrder(spanCtx, models.GetTransferOrderParams{
+		ID:    id,
+		OrgID: orgID,
+	})
+	h.recordDBOperation(spanCtx, "get", "transfer_order", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Transfer order not found"),
+		})
+		return
+	}
+	if err == nil {
+		var lines []models.TransferOrderLine
+		dbStart = time.Now()
+		lines, err = h.queries.ListTransferOrderLines(spanCtx, order.ID)
+		h.recordDBOperation(spanCtx, "list", "transfer_order_line", dbStart, err)
+		if err == nil {
+			var history []models.AuditLog
+			dbStart = time.Now()
+			history, err = h.queries.ListAuditLogsForEntity(spanCtx, models.ListAuditLogsForEntityParams{
+				OrgID:      orgID,
+				EntityType: auditEntityTransfer,
+				EntityID:   order.ID,
+			})
+			h.recordDBOperation(spanCtx, "list", "audit_log", dbStart, err)
+			if err == nil {
+				tracing.Result(span, observability.StatusSuccess)
+				respondV1(ctx, http.StatusOK, gin.H{
+					"message": tr(ctx, "Get Transfer Order Successfully"),
+					"data": gin.H{
+						"transfer": newTransferOrderResponse(order),
+						"lines":    mapSlice(lines, newTransferOrderLineResponse),
+						"history":  mapSlice(history, newAuditLogResponse),
+					},
+				})
+				return
+			}
+		}
+	}
+
+	slog.Error("Got an error while getting transfer order: ", slog.Any("err", err.Error()))
+	tracing.Failed(span, err)
+	ctx.JSON(dbErrorStatus(err), gin.H{
+		"error": tr(ctx, "Failed to get transfer order"),
+	})
+}
+
+// transferOrderListSpec sorts and filters the orders of ListTransferOrders
+var transferOrderListSpec = &listquery.Spec{
+	Table: "transfer_order",
+	Sort: map[string]string{
+		"id":          "id",
+		"reference":   "reference",
+		"status":      "status",
+		"created_at":  "created_at",
+		"shipped_at":  "shipped_at",
+		"received_at": "received_at",
+	},
+	Filters: map[string]listquery.Filter{
+		"status": {Column: "status", Op: listquery.Eq, Parse: listquery.OneOf(
+			transferStatusOpen, transferStatusInTransit, transferStatusReceived, transferStatusCancelled)},
+		"source_warehouse_id":      {Column: "source_warehouse_id", Op: listquery.Eq, Parse: listquery.Int},
+		"destination_warehouse_id": {Column: "destination_warehouse_id", Op: listquery.Eq, Parse: listquery.Int},
+	},
+	Default: []listquery.Order{{Field: "id", Desc: true}},
+	Key:     "id",
+}
+
+func (h *Handlers) ListTransferOrders(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "ListTransferOrders")
+	defer span.End()
+
+	q, err := listParams(ctx, transferOrderListSpec)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	q.Where("org_id", listquery.Eq, orgID)
+	tracing.Actor(span, orgID, actorID(ctx))
+
+	dbStart := time.Now()
+	orders, err := queryPage[models.TransferOrder](ctx, spanCtx, h.db, q)
+	h.recordDBOperation(spanCtx, "list", "transfer_order", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while listing transfer orders: ", slog.Any("err", err.Error()))
+		tracing.Failed(span, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to list transfer orders"),
+		})
+		return
+	}
+
+	span.SetAttributes(attribute.Int("transfer.count", len(orders)))
+	tracing.Result(span, observability.StatusSuccess)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "List Transfer Orders Successfully"),
+		"data":    mapSlice(orders, newTransferOrderResponse),
+	})
+}
+
+// ListInTransitStock sums the units shipped by transfers and not yet
+// received, per destination warehouse and SKU
+func (h *Handlers) ListInTransitStock(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "ListInTransitStock")
+	defer span.End()
+
+	orgID := tenantID(ctx)
+	tracing.Actor(span, orgID, actorID(ctx))
+
+	dbStart := time.Now()
+	stock, err := h.queries.ListInTransitStock(spanCtx, orgID)
+	h.recordDBOperation(spanCtx, "list", "transfer_order_line", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while listing in-transit stock: ", slog.Any("err", err.Error()))
+		tracing.Failed(span, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to list in-transit stock"),
+		})
+		return
+	}
+
+	tracing.Result(span, observability.StatusSuccess)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "List In-Transit Stock Successfully"),
+		"data":    mapSlice(stock, newInTransitStockResponse),
+	})
+}
+
+// transferTransition runs apply in a transaction holding a lock on the
+// transfer order, then moves it to the returned status. The transition is
+// audited and published as a topic event in the same transaction.
+func (h *Handlers) transferTransition(
+	ctx *gin.Context,
+	operation, topic string,
+	allowed []string,
+	apply func(spanCtx context.Context, qtx *models.Queries, order models.TransferOrder, lines []models.TransferOrderLine) (string, error),
+) {
+	opStart := time.Now()
+	name := strings.ToUpper(operation[:1]) + operation[1:] + " Transfer Order"
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), strings.ReplaceAll(name, " ", ""))
+	defer span.End()
+
+	id, ok := parseTransferID(ctx)
+	if !ok {
+		return
+	}
+	orgID := tenantID(ctx)
+	traceOperation(ctx, span, observability.EntityTransfer, id)
+
+	var fromStatus string
+	var order models.TransferOrder
+	var lines []models.TransferOrderLine
+	err := pgx.BeginFunc(spanCtx, h.db, func(tx pgx.Tx) error {
+		qtx := h.queries.WithTx(tx)
+		dbStart := time.Now()
+		current, err := qtx.GetTransferOrderForUpdate(spanCtx, models.GetTransferOrderForUpdateParams{
+			ID:    id,
+			OrgID: orgID,
+		})
+		h.recordDBOperation(spanCtx, "get", "transfer_order", dbStart, err)
+		if err != nil {
+			return err
+		}
+		fromStatus = current.Status
+		permitted := false
+		for _, status := range allowed {
+			if fromStatus == status {
+				permitted = true
+			}
+		}
+		if !permitted {
+			return &transferError{http.StatusConflict, fmt.Sprintf("Transfer order is %s", fromStatus)}
+		}
+
+		dbStart = time.Now()
+		lines, err = qtx.ListTransferOrderLines(spanCtx, current.ID)
+		h.recordDBOperation(spanCtx, "list", "transfer_order_line", dbStart, err)
+		if err != nil {
+			return err
+		}
+
+		status, err := apply(spanCtx, qtx, current, lines)
+		if err != nil {
+			return err
+		}
+
+		dbStart = time.Now()
+		lines, err = qtx.ListTransferOrderLines(spanCtx, current.ID)
+		h.recordDBOperation(spanCtx, "list", "transfer_order_line", dbStart, err)
+		if err != nil {
+			return err
+		}
+
+		order = current
+		if status != fromStatus {
+			dbStart = time.Now()
+			order, err = qtx.UpdateTransferOrderStatus(spanCtx, models.UpdateTransferOrderStatusParams{
+				Status: status,
+				ID:     current.ID,
+				OrgID:  orgID,
+			})
+			h.recordDBOperation(spanCtx, "update", "transfer_order", dbStart, err)
+			if err != nil {
+				return err
+			}
+			if err := h.recordAudit(spanCtx, qtx, auditEntry{
+				OrgID:      orgID,
+				EntityType: auditEntityTransfer,
+				EntityID:   order.ID,
+				Action:     operation,
+				FromStatus: fromStatus,
+				ToStatus:   status,
+				Actor:      actorID(ctx),
+			}); err != nil {
+				return err
+			}
+		}
+		return h.enqueueTransferEvent(spanCtx, qtx, topic, order, lines)
+	})
+	if err != nil {
+		tracing.Failed(span, err)
+		h.respondTransferError(ctx, orgID, operation, opStart, err)
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntityTransfer, operation, opStart, nil)
+
+	if order.Status != fromStatus {
+		tracing.Transition(span, observability.EntityTransfer, order.ID, fromStatus, order.Status)
+	}
+	tracing.Result(span, observability.StatusSuccess)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": name + " Successfully",
+		"data": gin.H{
+			"transfer": newTransferOrderResponse(order),
+			"lines":    mapSlice(lines, newTransferOrderLineResponse),
+		},
+	})
+}
+
+// ShipTransferOrder takes the stock of every line out of the storage room of
+// the source warehouse it ships from. The stock is in transit until the
+// destination receives it.
+func (h *Handlers) ShipTransferOrder(ctx *gin.Context) {
+	var req shipTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   tr(ctx, "Invalid transfer shipment payload"),
+			"details": err.Error(),
+		})
+		return
+	}
+
+	orgID := tenantID(ctx)
+	h.transferTransition(ctx, "ship", outbox.TopicTransferShipped, []string{transferStatusOpen},
+		func(spanCtx context.Context, qtx *models.Queries, order models.TransferOrder, lines []models.TransferOrderLine) (string, error) {
+			rooms, err := transferShipRooms(lines, req.Lines)
+			if err != nil {
+				return "", err
+			}
+
+			reference := fmt.Sprintf("transfer:%d", order.ID)
+			for _, line := range lines {
+				room := rooms[line.ID]
+				if err := h.roomOfWarehouse(spanCtx, qtx, orgID, room, order.SourceWarehouseID); err != nil {
+					return "", err
+				}
+				_, err := h.adjustStock(spanCtx, qtx, stockAdjustment{
+					OrgID:         orgID,
+					StorageRoomID: room,
+					Sku:           line.Sku,
+					Delta:         -line.Quantity,
+					Reason:        adjustmentReasonTransfer,
+					Reference:     reference,
+				})
+				if errors.Is(err, pgx.ErrNoRows) || isCheckViolation(err) {
+					return "", &transferError{http.StatusConflict, fmt.Sprintf("Storage room %d does not hold %d unallocated units of %s", room, line.Quantity, line.Sku)}
+				}
+				if err != nil {
+					return "", err
+				}
+
+				dbStart := time.Now()
+				_, err = qtx.ShipTransferOrderLine(spanCtx, models.ShipTransferOrderLineParams{
+					ID:                  line.ID,
+					TransferOrderID:     order.ID,
+					SourceStorageRoomID: pgtype.Int4{Int32: room, Valid: true},
+				})
+				h.recordDBOperation(spanCtx, "update", "transfer_order_line", dbStart, err)
+				if err != nil {
+					return "", err
+				}
+			}
+			return transferStatusInTransit, nil
+		})
+}
+
+// ReceiveTransferOrder puts units in transit away in storage rooms of the
+// destination warehouse. The transfer is received once every shipped unit
+// arrived, or when the request marks it complete.
+func (h *Handlers) ReceiveTransferOrder(ctx *gin.Context) {
+	var req receiveTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   tr(ctx, "Invalid transfer receipt payload"),
+			"details": err.Error(),
+		})
+		return
+	}
+
+	orgID := tenantID(ctx)
+	h.transferTransition(ctx, "receive", outbox.TopicTransferReceived, []string{transferStatusInTransit},
+		func(spanCtx context.Context, qtx *models.Queries, order models.TransferOrder, lines []models.TransferOrderLine) (string, error) {
+			byID := make(map[int64]models.TransferOrderLine, len(lines))
+			for _, line := range lines {
+				byID[line.ID] = line
+			}
+
+			units := h.unitConverter(qtx, orgID)
+			reference := fmt.Sprintf("transfer:%d", order.ID)
+			for _, l := range req.Lines {
+				line, ok := byID[l.LineID]
+				if !ok {
+					return "", &transferError{http.StatusBadRequest, fmt.Sprintf("Line %d does not belong to this transfer", l.LineID)}
+				}
+				quantity, err := units.toBase(spanCtx, line.Sku, l.Unit, l.Quantity)
+				if err != nil {
+					return "", err
+				}
+				if inTransit := line.ShippedQuantity - line.ReceivedQuantity; quantity > inTransit {
+					return "", &transferError{http.StatusBadRequest, fmt.Sprintf("Line %d has only %d units in transit", l.LineID, inTransit)}
+				}
+				if err := h.roomOfWarehouse(spanCtx, qtx, orgID, l.StorageRoomID, order.DestinationWarehouseID); err != nil {
+					return "", err
+				}
+
+				if _, err := h.adjustStock(spanCtx, qtx, stockAdjustment{
+					OrgID:            orgID,
+					StorageRoomID:    l.StorageRoomID,
+					Sku:              line.Sku,
+					Delta:            quantity,
+					Reason:           adjustmentReasonTransfer,
+					Reference:        reference,
+					OverrideCapacity: req.OverrideCapacity,
+				}); err != nil {
+					return "", err
+				}
+
+				dbStart := time.Now()
+				line, err = qtx.ReceiveTransferOrderLine(spanCtx, models.ReceiveTransferOrderLineParams{
+					ID:               line.ID,
+					TransferOrderID:  order.ID,
+					ReceivedQuantity: quantity,
+				})
+				h.recordDBOperation(spanCtx, "update", "transfer_order_line", dbStart, err)
+				if err != nil {
+					return "", err
+				}
+				byID[line.ID] = line
+			}
+
+			received := make([]models.TransferOrderLine, 0, len(byID))
+			for _, line := range byID {
+				received = append(received, line)
+			}
+			if transferReceived(received) || req.Complete {
+				return transferStatusReceived, nil
+			}
+			return order.Status, nil
+		})
+}
+
+// CancelTransferOrder drops a transfer that has not shipped
+func (h *Handlers) CancelTransferOrder(ctx *gin.Context) {
+	h.transferTransition(ctx, "cancel", outbox.TopicTransferCancelled, []string{transferStatusOpen},
+		func(context.Context, *models.Queries, models.TransferOrder, []models.TransferOrderLine) (string, error) {
+			return transferStatusCancelled, nil
+		})
+}
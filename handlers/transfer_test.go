@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"testing"
+	models "warehouse-service/models/sqlc"
+)
+
+func TestTransferShipRooms(t *testing.T) {
+	lines := []models.TransferOrderLine{{ID: 1}, {ID: 2}}
+	rooms, err := transferShipRooms(lines, []shipTransferLineRequest{
+		{LineID: 2, StorageRoomID: 20},
+		{LineID: 1, StorageRoomID: 10},
+	})
+	if err != nil || rooms[1] != 10 || rooms[2] != 20 {
+		t.Fatalf("rooms = %v, %v", rooms, err)
+	}
+
+	for name, req := range map[string][]shipTransferLineRequest{
+		"missing": {{LineID: 1, StorageRoomID: 10}},
+		"twice":   {{LineID: 1, StorageRoomID: 10}, {LineID: 1, StorageRoomID: 11}, {LineID: 2, StorageRoomID: 20}},
+		"foreign": {{LineID: 1, StorageRoomID: 10}, {LineID: 2, StorageRoomID: 20}, {LineID: 3, StorageRoomID: 30}},
+	} {
+		if _, err := transferShipRooms(lines, req); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
+
+func TestTransferReceived(t *testing.T) {
+	lines := []models.TransferOrderLine{
+		{ID: 1, ShippedQuantity: 5, ReceivedQuantity: 5},
+		{ID: 2, ShippedQuantity: 3, ReceivedQuantity: 1},
+	}
+	if transferReceived(lines) {
+		t.Error("partly received transfer reported received")
+	}
+	lines[1].ReceivedQuantity = 3
+	if !transferReceived(lines) {
+		t.Error("fully received transfer not reported received")
+	}
+}
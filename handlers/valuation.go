@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+	models "warehouse-service/models/sqlc"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Inventory valuation methods, a tenant setting
+const (
+	valuationFIFO            = "fifo"
+	valuationWeightedAverage = "weighted_average"
+)
+
+// costLayer is a received quantity of a SKU at one unit cost
+type costLayer struct {
+	Quantity      int64
+	UnitCostCents int64
+}
+
+// valueStock values quantity units of a SKU in a warehouse from its cost
+// layers, oldest first. FIFO assumes the oldest units left first, so what is
+// on hand comes from the newest layers; weighted average values every unit
+// at the average cost of all layers. Units the layers don't account for,
+// e.g. stock that was transferred in or counted up, are uncosted.
+func valueStock(method string, quantity int64, layers []costLayer) (valueCents, uncosted int64) {
+	if method == valuationWeightedAverage {
+		var total, cost int64
+		for _, l := range layers {
+			total += l.Quantity
+			cost += l.Quantity * l.UnitCostCents
+		}
+		if total == 0 {
+			return 0, quantity
+		}
+		costed := min(quantity, total)
+		return int64(math.Round(float64(costed) * float64(cost) / float64(total))), quantity - costed
+	}
+
+	remaining := quantity
+	for i := len(layers) - 1; i >= 0 && remaining > 0; i-- {
+		take := min(remaining, layers[i].Quantity)
+		valueCents += take * layers[i].UnitCostCents
+		remaining -= take
+	}
+	return valueCents, remaining
+}
+
+type SkuValuationResponse struct {
+	Sku              string `json:"sku" v1:"Sku"`
+	Quantity         int64  `json:"quantity" v1:"Quantity"`
+	ValueCents       int64  `json:"value_cents" v1:"ValueCents"`
+	UncostedQuantity int64  `json:"uncosted_quantity" v1:"UncostedQuantity"`
+}
+
+type WarehouseValuationResponse struct {
+	WarehouseID      int64                  `json:"warehouse_id" v1:"WarehouseID"`
+	WarehouseName    string                 `json:"warehouse_name" v1:"WarehouseName"`
+	Quantity         int64                  `json:"quantity" v1:"Quantity"`
+	ValueCents       int64                  `json:"value_cents" v1:"ValueCents"`
+	UncostedQuantity int64                  `json:"uncosted_quantity" v1:"UncostedQuantity"`
+	Skus             []SkuValuationResponse `json:"skus" v1:"Skus"`
+}
+
+// ValuationResponse is the value of the stock on hand per warehouse and
+// SKU by Method, in the smallest unit of the tenant's currency
+type ValuationResponse struct {
+	Method           string                       `json:"method" v1:"Method"`
+	ValueCents       int64                        `json:"value_cents" v1:"ValueCents"`
+	UncostedQuantity int64                        `json:"uncosted_quantity" v1:"UncostedQuantity"`
+	Warehouses       []WarehouseValuationResponse `json:"warehouses" v1:"Warehouses"`
+}
+
+// buildValuation values the stock on hand, ordered by warehouse and SKU,
+// with the cost layers of each warehouse and SKU
+func buildValuation(method string, stock []models.ListStockOnHandRow, rows []models.ListCostLayersRow) ValuationResponse {
+	type layerKey struct {
+		warehouseID int64
+		sku         string
+	}
+	layers := make(map[layerKey][]costLayer)
+	for _, r := range rows {
+		key := layerKey{r.WarehouseID, r.Sku}
+		layers[key] = append(layers[key], costLayer{Quantity: r.Quantity, UnitCostCents: r.UnitCostCents})
+	}
+
+	valuation := ValuationResponse{Method: method, Warehouses: []WarehouseValuationResponse{}}
+	for _, s := range stock {
+		n := len(valuation.Warehouses)
+		if n == 0 || valuation.Warehouses[n-1].WarehouseID != s.WarehouseID {
+			valuation.Warehouses = append(valuation.Warehouses, WarehouseValuationResponse{
+				WarehouseID:   s.WarehouseID,
+				WarehouseName: s.WarehouseName,
+			})
+			n++
+		}
+		w := &valuation.Warehouses[n-1]
+		value, uncosted := valueStock(method, s.Quantity, layers[layerKey{s.WarehouseID, s.Sku}])
+		w.Skus = append(w.Skus, SkuValuationResponse{
+			Sku:              s.Sku,
+			Quantity:         s.Quantity,
+			ValueCents:       value,
+			UncostedQuantity: uncosted,
+		})
+		w.Quantity += s.Quantity
+		w.ValueCents += value
+		w.UncostedQuantity += uncosted
+		valuation.ValueCents += value
+		valuation.UncostedQuantity += uncosted
+	}
+	return valuation
+}
+
+// GetInventoryValuation values the stock on hand per warehouse, optionally
+// of one ?warehouse_id=, by the tenant's valuation method or ?method=. The
+// CSV has one row per warehouse and SKU.
+func (h *Handlers) GetInventoryValuation(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "GetInventoryValuation")
+	defer span.End()
+
+	asCSV, err := reportFormat(ctx)
+	method := ctx.Query("method")
+	if err == nil && method != "" && method != valuationFIFO && method != valuationWeightedAverage {
+		err = fmt.Errorf("method must be fifo or weighted_average, got %q", method)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	var warehouseID pgtype.Int8
+	if v := ctx.Query("warehouse_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": tr(ctx, "Invalid warehouse ID format"),
+			})
+			return
+		}
+		warehouseID = pgtype.Int8{Int64: id, Valid: true}
+	}
+	queries := h.readQueries(spanCtx)
+
+	if method == "" {
+		var setting models.TenantSetting
+		setting, err = h.tenantSetting(spanCtx, queries, orgID)
+		method = setting.ValuationMethod
+	}
+	var stock []models.ListStockOnHandRow
+	if err == nil {
+		dbStart := time.Now()
+		stock, err = queries.ListStockOnHand(spanCtx, models.ListStockOnHandParams{
+			OrgID:       orgID,
+			WarehouseID: warehouseID,
+		})
+		h.recordDBOperation(spanCtx, "report", "stock_level", dbStart, err)
+	}
+	var layers []models.ListCostLayersRow
+	if err == nil {
+		dbStart := time.Now()
+		layers, err = queries.ListCostLayers(spanCtx, models.ListCostLayersParams{
+			OrgID:       orgID,
+			WarehouseID: warehouseID,
+		})
+		h.recordDBOperation(spanCtx, "report", "receipt_line", dbStart, err)
+	}
+	if err != nil {
+		slog.Error("Got an error while valuing inventory: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to get inventory valuation"),
+		})
+		return
+	}
+
+	valuation := buildValuation(method, stock, layers)
+	span.SetAttributes(
+		attribute.String("tenant.id", orgID),
+		attribute.String("report.method", method),
+		attribute.Int("report.rows", len(stock)),
+		attribute.String("operation.status", "success"),
+	)
+	if asCSV {
+		records := make([][]string, 0, len(stock))
+		for _, w := range valuation.Warehouses {
+			for _, s := range w.Skus {
+				records = append(records, []string{
+					strconv.FormatInt(w.WarehouseID, 10),
+					w.WarehouseName,
+					s.Sku,
+					method,
+					strconv.FormatInt(s.Quantity, 10),
+					strconv.FormatInt(s.ValueCents, 10),
+					strconv.FormatInt(s.UncostedQuantity, 10),
+				})
+			}
+		}
+		writeCSV(ctx, "valuation.csv",
+			[]string{"warehouse_id", "warehouse_name", "sku", "method", "quantity", "value_cents", "uncosted_quantity"}, records)
+		return
+	}
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Get Inventory Valuation Successfully"),
+		"data":    valuation,
+	})
+}
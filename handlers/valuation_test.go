@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"testing"
+	models "warehouse-service/models/sqlc"
+)
+
+func TestValueStock(t *testing.T) {
+	layers := []costLayer{
+		{Quantity: 10, UnitCostCents: 100},
+		{Quantity: 10, UnitCostCents: 130},
+	}
+	cases := []struct {
+		name         string
+		method       string
+		quantity     int64
+		layers       []costLayer
+		wantValue    int64
+		wantUncosted int64
+	}{
+		// What is left of FIFO stock is from the newest receipt
+		{"fifo newest", valuationFIFO, 5, layers, 650, 0},
+		{"fifo across layers", valuationFIFO, 15, layers, 1800, 0},
+		{"fifo beyond layers", valuationFIFO, 25, layers, 2300, 5},
+		{"average", valuationWeightedAverage, 5, layers, 575, 0},
+		{"average beyond layers", valuationWeightedAverage, 25, layers, 2300, 5},
+		{"no layers", valuationWeightedAverage, 3, nil, 0, 3},
+	}
+	for _, c := range cases {
+		value, uncosted := valueStock(c.method, c.quantity, c.layers)
+		if value != c.wantValue || uncosted != c.wantUncosted {
+			t.Errorf("%s: value %d, uncosted %d", c.name, value, uncosted)
+		}
+	}
+}
+
+func TestBuildValuation(t *testing.T) {
+	stock := []models.ListStockOnHandRow{
+		{WarehouseID: 1, WarehouseName: "A", Sku: "SKU-1", Quantity: 4},
+		{WarehouseID: 1, WarehouseName: "A", Sku: "SKU-2", Quantity: 2},
+		{WarehouseID: 2, WarehouseName: "B", Sku: "SKU-1", Quantity: 3},
+	}
+	layers := []models.ListCostLayersRow{
+		{WarehouseID: 1, Sku: "SKU-1", Quantity: 10, UnitCostCents: 50},
+		{WarehouseID: 2, Sku: "SKU-1", Quantity: 1, UnitCostCents: 70},
+	}
+	v := buildValuation(valuationFIFO, stock, layers)
+	if v.ValueCents != 270 || v.UncostedQuantity != 4 || len(v.Warehouses) != 2 {
+		t.Fatalf("valuation %+v", v)
+	}
+	if a := v.Warehouses[0]; a.Quantity != 6 || a.ValueCents != 200 || a.UncostedQuantity != 2 || len(a.Skus) != 2 {
+		t.Errorf("warehouse A %+v", a)
+	}
+	if b := v.Warehouses[1]; b.ValueCents != 70 || b.UncostedQuantity != 2 {
+		t.Errorf("warehouse B %+v", b)
+	}
+}
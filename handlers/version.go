@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+	models "warehouse-service/models/sqlc"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errVersionMismatch is returned by a conditional write when the row is no
+// longer at a version its If-Match names
+var errVersionMismatch = errors.New("row changed since the version in If-Match")
+
+// versionTag is the ETag of a version of a warehouse or storage room: its
+// row_history ID, the version history lists. The tag is strong so that
+// If-Match can compare it.
+func versionTag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// ifMatch is the If-Match of a request, nil without one
+type ifMatch []string
+
+func parseIfMatch(ctx *gin.Context) ifMatch {
+	var tags ifMatch
+	for _, header := range ctx.Request.Header.Values("If-Match") {
+		for _, tag := range strings.Split(header, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
+
+// holds tells whether the current version satisfies m. If-Match compares
+// strongly, so a weak tag never holds; * holds for any version.
+func (m ifMatch) holds(version int64) bool {
+	if m == nil {
+		return true
+	}
+	current := versionTag(version)
+	for _, tag := range m {
+		if tag == "*" || tag == current {
+			return true
+		}
+	}
+	return false
+}
+
+// checkVersion returns errVersionMismatch unless the current version of
+// the row satisfies m. Run in the transaction of the write, after the row
+// is locked, no other write slips in between.
+func (h *Handlers) checkVersion(ctx context.Context, q *models.Queries, entity string, id int64, orgID string, m ifMatch) error {
+	if m == nil {
+		return nil
+	}
+	dbStart := time.Now()
+	version, err := q.GetCurrentVersion(ctx, models.GetCurrentVersionParams{
+		Entity:   entity,
+		EntityID: id,
+		OrgID:    orgID,
+	})
+	h.recordDBOperation(ctx, "get", "row_history", dbStart, err)
+	if err != nil {
+		return err
+	}
+	if !m.holds(version) {
+		return errVersionMismatch
+	}
+	return nil
+}
+
+// setVersionTag sets the ETag of a response to the current version of the
+// row. A read isn't failed for it, without a version the response keeps
+// the ETag of its body.
+func (h *Handlers) setVersionTag(ctx *gin.Context, spanCtx context.Context, q *models.Queries, entity string, id int64, orgID string) {
+	dbStart := time.Now()
+	version, err := q.GetCurrentVersion(spanCtx, models.GetCurrentVersionParams{
+		Entity:   entity,
+		EntityID: id,
+		OrgID:    orgID,
+	})
+	h.recordDBOperation(spanCtx, "get", "row_history", dbStart, err)
+	if err != nil {
+		slog.Warn("Could not get the current version", slog.String("entity", entity), slog.Int64("id", id), slog.Any("err", err.Error()))
+		return
+	}
+	ctx.Header("ETag", versionTag(version))
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestIfMatch(t *testing.T) {
+	tests := []struct {
+		header string
+		holds  bool
+	}{
+		{header: "", holds: true},
+		{header: `"42"`, holds: true},
+		{header: `"41", "42"`, holds: true},
+		{header: "*", holds: true},
+		{header: `"41"`, holds: false},
+		{header: `W/"42"`, holds: false},
+		{header: "42", holds: false},
+	}
+	for _, tt := range tests {
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		ctx.Request = httptest.NewRequest(http.MethodDelete, "/", nil)
+		if tt.header != "" {
+			ctx.Request.Header.Set("If-Match", tt.header)
+		}
+		if got := parseIfMatch(ctx).holds(42); got != tt.holds {
+			t.Errorf("If-Match %q holds for version 42 = %v, want %v", tt.header, got, tt.holds)
+		}
+	}
+}
@@ -1,135 +1,251 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
 	"time"
+	"warehouse-service/address"
+	"warehouse-service/authz"
+	"warehouse-service/changefeed"
+	"warehouse-service/dbroute"
+	"warehouse-service/geocode"
+	"warehouse-service/inbox"
+	"warehouse-service/listquery"
 	models "warehouse-service/models/sqlc"
 	"warehouse-service/observability"
+	"warehouse-service/outbox"
+	"warehouse-service/quota"
+	"warehouse-service/scheduler"
+	"warehouse-service/tracing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 )
 
 type Handlers struct {
-	db                *pgx.Conn
+	db                *pgxpool.Pool
 	queries           *models.Queries
+	router            *dbroute.Router
 	tracer            trace.Tracer
 	prometheusMetrics *observability.PrometheusMetrics
+	scheduler         *scheduler.Scheduler
+	geocoder          geocode.Geocoder
+	addressValidator  address.Validator
+	changes           *changefeed.Feed
+	quotas            quota.Limits
+	attachments       Attachments
+	services          Services
+	// Consumes the broker's messages with the handlers of inbox.go
+	inbox *inbox.Consumer
 }
 
-func NewHandlers(db *pgx.Conn, prometheusMetrics *observability.PrometheusMetrics) *Handlers {
-	return &Handlers{
-		db:                db,
-		queries:           models.New(db),
+// NewHandlers builds the HTTP handlers. geocoder may be nil to disable
+// address lookups, addressValidator nil to only normalize addresses,
+// attachments.Store nil to disable attachments and the
+// clients of services nil to leave the sibling services alone.
+func NewHandlers(db *dbroute.Router, prometheusMetrics *observability.PrometheusMetrics, scheduler *scheduler.Scheduler, geocoder geocode.Geocoder, addressValidator address.Validator, changes *changefeed.Feed, quotas quota.Limits, attachments Attachments, services Services) *Handlers {
+	h := &Handlers{
+		db:                db.Primary(),
+		queries:           models.New(db.Primary()),
+		router:            db,
 		tracer:            otel.Tracer("warehouse-service/handlers"),
 		prometheusMetrics: prometheusMetrics,
+		scheduler:         scheduler,
+		geocoder:          geocoder,
+		addressValidator:  addressValidator,
+		changes:           changes,
+		quotas:            quotas,
+		attachments:       attachments,
+		services:          services,
+		inbox:             inbox.NewConsumer(db.Primary(), prometheusMetrics),
 	}
+	h.inbox.Handle(inbox.TopicOrderCreated, h.consumeOrderCreated)
+	return h
+}
+
+// readQueries returns queries for a read-only request, on a read replica
+// when the route tolerates stale data, see dbroute.WithStaleness
+func (h *Handlers) readQueries(ctx context.Context) *models.Queries {
+	return models.New(h.router.Read(ctx))
+}
+
+// recordDBOperation records the duration of a database call started at start
+func (h *Handlers) recordDBOperation(ctx context.Context, operation, table string, start time.Time, err error) {
+	if h.prometheusMetrics != nil {
+		h.prometheusMetrics.RecordDBOperation(ctx, operation, table, time.Since(start), err)
+	}
+}
+
+// traceOperation records the tenant, the acting user or API key and the
+// entity an operation works on
+func traceOperation(ctx *gin.Context, span trace.Span, entityType string, id int64) {
+	tracing.Actor(span, tenantID(ctx), actorID(ctx))
+	tracing.Entity(span, entityType, id)
+}
+
+// recordOperation counts a business operation started at start and records
+// its duration. err picks the status label: nil is a success, pgx.ErrNoRows
+// not_found and anything else an error.
+func (h *Handlers) recordOperation(tenant, entityType, operation string, start time.Time, err error) {
+	if h.prometheusMetrics == nil {
+		return
+	}
+	status := observability.StatusSuccess
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		status = observability.StatusNotFound
+	case err != nil:
+		status = observability.StatusError
+	}
+	h.prometheusMetrics.RecordOperation(tenant, entityType, operation, status, time.Since(start))
 }
 
 func (h *Handlers) GetWarehouse(ctx *gin.Context) {
+	opStart := time.Now()
 	// Start a new span for this operation
-	_, span := h.tracer.Start(ctx.Request.Context(), "GetWarehouse")
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "GetWarehouse")
 	defer span.End()
 
 	idStr := ctx.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 32)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid warehouse ID format",
+			"error": tr(ctx, "Invalid warehouse ID format"),
 		})
 		return
 	}
-	span.SetAttributes(attribute.Int64("warehouse.id", id))
+	fields, ok := bindFields[WarehouseResponse](ctx)
+	if !ok {
+		return
+	}
+	orgID := tenantID(ctx)
+	traceOperation(ctx, span, observability.EntityWarehouse, id)
+	if ctx.Query("as_of") != "" {
+		h.getWarehouseAsOf(ctx, spanCtx, id, fields)
+		return
+	}
 
 	dbStart := time.Now()
-	warehouse, err := h.queries.GetWarehouse(ctx, id)
+	warehouse, err := h.readQueries(spanCtx).GetWarehouse(spanCtx, models.GetWarehouseParams{
+		ID:    id,
+		OrgID: orgID,
+	})
 	dbDuration := time.Since(dbStart)
 
 	// Record database operation duration (Prometheus)
 	if h.prometheusMetrics != nil {
-		h.prometheusMetrics.RecordDBOperation("get", "warehouse", dbDuration, err)
+		h.prometheusMetrics.RecordDBOperation(spanCtx, "get", "warehouse", dbDuration, err)
+	}
+	if err == nil {
+		err = h.authorize(ctx, authz.Read, observability.EntityWarehouse, warehouse.OrgID)
 	}
 
+	// Warehouses owned by another tenant are reported as missing
+	if errors.Is(err, pgx.ErrNoRows) {
+		tracing.Result(span, observability.StatusNotFound)
+		h.recordOperation(orgID, observability.EntityWarehouse, "get", opStart, pgx.ErrNoRows)
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Warehouse not found"),
+		})
+		return
+	}
 	if err != nil {
 		slog.Error("Got an error while getting warehouse: ", slog.Any("err", err.Error()))
-		span.RecordError(err)
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to get warehouse",
+		tracing.Failed(span, err)
+		h.recordOperation(orgID, observability.EntityWarehouse, "get", opStart, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to get warehouse"),
 		})
 		return
 	}
 
 	// Record successful retrieval (Prometheus)
-	if h.prometheusMetrics != nil {
-		h.prometheusMetrics.RecordInventoryOperation("get", warehouse.Name, warehouse.Address)
-	}
+	h.recordOperation(orgID, observability.EntityWarehouse, "get", opStart, nil)
 
 	// Record successful operation
-	span.SetAttributes(
-		attribute.String("warehouse.name", warehouse.Name),
-		attribute.String("operation.status", "success"),
-	)
-	ctx.JSON(200, gin.H{
-		"message": "Get Warehouse Successfully",
-		"data":    warehouse,
+	span.SetAttributes(attribute.String("warehouse.name", warehouse.Name))
+	tracing.Result(span, observability.StatusSuccess)
+	h.setVersionTag(ctx, spanCtx, h.readQueries(spanCtx), observability.EntityWarehouse, id, orgID)
+	respondV1(ctx, 200, gin.H{
+		"message": tr(ctx, "Get Warehouse Successfully"),
+		"data":    fields.one(newWarehouseResponse(warehouse)),
 	})
 }
 
 func (h *Handlers) ListWarehouse(ctx *gin.Context) {
+	opStart := time.Now()
 	// Start a new span for this operation
 	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "ListWarehouse")
 	defer span.End()
 
+	fields, ok := bindFields[WarehouseResponse](ctx)
+	if !ok {
+		return
+	}
+	orgID := tenantID(ctx)
+	q, err := warehouseListSpec.Parse(ctx.Request.URL.Query())
+	if err == nil {
+		q.Where("org_id", listquery.Eq, orgID).Page(10, 0)
+		err = listFilters(ctx, q)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
 	// Add attributes to the span
+	tracing.Actor(span, orgID, actorID(ctx))
 	span.SetAttributes(
 		attribute.Int("warehouse.limit", 10),
 		attribute.Int("warehouse.offset", 0),
 	)
 
 	dbStart := time.Now()
-	warehouses, err := h.queries.ListWarehouse(spanCtx, models.ListWarehouseParams{
-		Limit:  10,
-		Offset: 0,
-	})
+	warehouses, err := queryPage[models.Warehouse](ctx, spanCtx, h.router.Read(spanCtx), q)
 	dbDuration := time.Since(dbStart)
 	// Record database operation duration (Prometheus)
 	if h.prometheusMetrics != nil {
-		h.prometheusMetrics.RecordDBOperation("list", "inventory", dbDuration, err)
+		h.prometheusMetrics.RecordDBOperation(spanCtx, "list", "inventory", dbDuration, err)
 	}
 
 	if err != nil {
-		span.RecordError(err)
-		span.SetAttributes(attribute.String("error", "database_query_failed"))
+		tracing.Failed(span, err)
 		slog.Error("Got an error while listing warehouses: ", slog.Any("err", err.Error()))
+		h.recordOperation(orgID, observability.EntityWarehouse, "list", opStart, err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to list warehouses",
+			"error": tr(ctx, "Failed to list warehouses"),
 		})
 		return
 	}
 
+	warehouses = authorizedRows(h, ctx, observability.EntityWarehouse, warehouses, warehouseOrg)
+
 	// Record successful list operation (Prometheus)
-	if h.prometheusMetrics != nil {
-		h.prometheusMetrics.RecordInventoryOperation("list", "all", "all")
-	}
+	h.recordOperation(orgID, observability.EntityWarehouse, "list", opStart, nil)
 
 	// Record successful operation
-	span.SetAttributes(
-		attribute.Int("warehouse.count", len(warehouses)),
-		attribute.String("operation.status", "success"),
-	)
+	span.SetAttributes(attribute.Int("warehouse.count", len(warehouses)))
+	tracing.Result(span, observability.StatusSuccess)
 
-	ctx.JSON(200, gin.H{
-		"message": "List Warehouse Successfully",
-		"data":    warehouses,
+	respondV1(ctx, 200, gin.H{
+		"message": tr(ctx, "List Warehouse Successfully"),
+		"data":    selectFields(fields, mapSlice(warehouses, newWarehouseResponse)),
 	})
 }
 
 func (h *Handlers) UpdateWarehouse(ctx *gin.Context) {
+	opStart := time.Now()
 	// Start a new span for this operation
 	_, span := h.tracer.Start(ctx.Request.Context(), "UpdateWarehouse")
 	defer span.End()
@@ -139,18 +255,54 @@ func (h *Handlers) UpdateWarehouse(ctx *gin.Context) {
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid warehouse ID",
+			"error": tr(ctx, "Invalid warehouse ID"),
+		})
+		return
+	}
+	lat, lng, err := formCoordinates(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	metadata, err := formMetadata(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	attrs, attrsSent, err := formAttributes(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
 		})
 		return
 	}
-	span.SetAttributes(attribute.Int64("warehouse.id", id))
+	raw := formAddress(ctx)
+	addr, validated, err := h.normalizeAddress(ctx, raw)
+	if err != nil {
+		respondAddressError(ctx, err)
+		return
+	}
+	orgID := tenantID(ctx)
+	traceOperation(ctx, span, observability.EntityWarehouse, id)
+	if attrsSent {
+		if err := h.checkAttributes(ctx, orgID, observability.EntityWarehouse, attrs); err != nil {
+			span.RecordError(err)
+			respondAttributesError(ctx, err)
+			return
+		}
+	}
 
 	// Start database transaction
 	tx, err := h.db.Begin(ctx)
 	if err != nil {
 		slog.Error("Failed to start transaction", slog.Any("err", err.Error()))
+		h.recordOperation(orgID, observability.EntityWarehouse, "update", opStart, err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to start transaction",
+			"error": tr(ctx, "Failed to start transaction"),
 		})
 		return
 	}
@@ -161,31 +313,48 @@ func (h *Handlers) UpdateWarehouse(ctx *gin.Context) {
 
 	// Check if warehouse exists before updating
 	dbStart := time.Now()
-	_, err = qtx.GetWarehouse(ctx, id)
+	existing, err := qtx.GetWarehouse(ctx, models.GetWarehouseParams{
+		ID:    id,
+		OrgID: orgID,
+	})
 	dbDuration := time.Since(dbStart)
 
 	// Record database operation duration (Prometheus)
 	if h.prometheusMetrics != nil {
-		h.prometheusMetrics.RecordDBOperation("get", "warehouse", dbDuration, err)
+		h.prometheusMetrics.RecordDBOperation(ctx, "get", "warehouse", dbDuration, err)
+	}
+	if err == nil {
+		err = h.authorize(ctx, authz.Write, observability.EntityWarehouse, existing.OrgID)
 	}
 
 	if err != nil {
 		slog.Error("Warehouse not found", slog.Any("err", err.Error()))
-		span.RecordError(err)
+		tracing.Failed(span, err)
+		h.recordOperation(orgID, observability.EntityWarehouse, "update", opStart, pgx.ErrNoRows)
 		ctx.JSON(http.StatusNotFound, gin.H{
-			"error": "Warehouse not found",
+			"error": tr(ctx, "Warehouse not found"),
 		})
 		return
 	}
 
 	// Update warehouse within transaction
 	param := models.UpdateWarehouseParams{
-		ID:      id,
-		Name:    ctx.PostForm("Name"),
-		Address: ctx.PostForm("Address"),
-		Ward:    ctx.PostForm("Ward"),
-		City:    ctx.PostForm("City"),
-		Country: ctx.PostForm("Country"),
+		ID:       id,
+		Name:     ctx.PostForm("Name"),
+		Address:  addr.Street,
+		Ward:     addr.Ward,
+		District: addr.District,
+		City:     addr.City,
+		Country:  addr.Country,
+		OrgID:    orgID,
+	}
+	param.Latitude, param.Longitude = h.coordinates(ctx, lat, lng, param.Address, param.Ward, param.City, param.Country)
+	// Metadata fields missing from the form keep their stored value
+	md := metadata.full(metadataOf(existing))
+	param.TimeZone, param.OperatingHours, param.ContactEmail, param.ContactPhone, param.Tags = md.TimeZone, md.OperatingHours, md.ContactEmail, md.ContactPhone, md.Tags
+	param.Attributes = existing.Attributes
+	if attrsSent {
+		param.Attributes = attrs
 	}
 
 	dbStart = time.Now()
@@ -194,14 +363,43 @@ func (h *Handlers) UpdateWarehouse(ctx *gin.Context) {
 
 	// Record database operation duration (Prometheus)
 	if h.prometheusMetrics != nil {
-		h.prometheusMetrics.RecordDBOperation("update", "warehouse", dbDuration, err)
+		h.prometheusMetrics.RecordDBOperation(ctx, "update", "warehouse", dbDuration, err)
 	}
 
+	if conflict, ok := conflictFor(err); ok {
+		h.recordOperation(orgID, observability.EntityWarehouse, "update", opStart, err)
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": tr(ctx, conflict.message),
+			"field": conflict.field,
+		})
+		return
+	}
 	if err != nil {
 		slog.Error("Could not update warehouse", slog.Any("err", err.Error()))
-		span.RecordError(err)
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to update warehouse",
+		tracing.Failed(span, err)
+		h.recordOperation(orgID, observability.EntityWarehouse, "update", opStart, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to update warehouse"),
+		})
+		return
+	}
+
+	if err := h.saveRawAddress(ctx, qtx, rawAddressParams(id, orgID, raw, validated)); err != nil {
+		slog.Error("Could not record warehouse address", slog.Any("err", err.Error()))
+		tracing.Failed(span, err)
+		h.recordOperation(orgID, observability.EntityWarehouse, "update", opStart, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to update warehouse"),
+		})
+		return
+	}
+
+	if err := h.enqueueWarehouseEvent(ctx, qtx, outbox.TopicWarehouseUpdated, warehouse); err != nil {
+		slog.Error("Could not record warehouse update event", slog.Any("err", err.Error()))
+		tracing.Failed(span, err)
+		h.recordOperation(orgID, observability.EntityWarehouse, "update", opStart, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to update warehouse"),
 		})
 		return
 	}
@@ -209,127 +407,458 @@ func (h *Handlers) UpdateWarehouse(ctx *gin.Context) {
 	// Commit transaction
 	if err := tx.Commit(ctx); err != nil {
 		slog.Error("Failed to commit transaction", slog.Any("err", err.Error()))
-		span.RecordError(err)
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to commit transaction",
+		tracing.Failed(span, err)
+		h.recordOperation(orgID, observability.EntityWarehouse, "update", opStart, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to commit transaction"),
 		})
 		return
 	}
 
 	// Record successful update (Prometheus)
-	if h.prometheusMetrics != nil {
-		h.prometheusMetrics.RecordInventoryOperation("update", warehouse.Name, warehouse.Address)
-	}
+	h.recordOperation(orgID, observability.EntityWarehouse, "update", opStart, nil)
 
 	// Record successful operation
-	span.SetAttributes(
-		attribute.String("warehouse.name", warehouse.Name),
-		attribute.String("operation.status", "success"),
-	)
+	span.SetAttributes(attribute.String("warehouse.name", warehouse.Name))
+	tracing.Result(span, observability.StatusSuccess)
 
-	ctx.JSON(200, gin.H{
-		"message": "Update Warehouse Successfully",
-		"data":    warehouse,
+	respondV1(ctx, 200, gin.H{
+		"message": tr(ctx, "Update Warehouse Successfully"),
+		"data":    newWarehouseResponse(warehouse),
 	})
 }
 
 func (h *Handlers) CreateWarehouse(ctx *gin.Context) {
+	opStart := time.Now()
 	// Start a new span for this operation
 	_, span := h.tracer.Start(ctx.Request.Context(), "CreateWarehouse")
 	defer span.End()
 
+	lat, lng, err := formCoordinates(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	metadata, err := formMetadata(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	attrs, attrsSent, err := formAttributes(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if !attrsSent {
+		attrs = []byte("{}")
+	}
+	status, err := initialWarehouseStatus(ctx.PostForm("Status"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	force, err := forceParam(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	raw := formAddress(ctx)
+	addr, validated, err := h.normalizeAddress(ctx, raw)
+	if err != nil {
+		respondAddressError(ctx, err)
+		return
+	}
 	param := models.CreateWarehouseParams{
-		Name:    ctx.PostForm("Name"),
-		Address: ctx.PostForm("Address"),
-		Ward:    ctx.PostForm("Ward"),
-		City:    ctx.PostForm("City"),
-		Country: ctx.PostForm("Country"),
+		Name:     ctx.PostForm("Name"),
+		Address:  addr.Street,
+		Ward:     addr.Ward,
+		District: addr.District,
+		City:     addr.City,
+		Country:  addr.Country,
+		OrgID:    tenantID(ctx),
 	}
+	param.Latitude, param.Longitude = h.coordinates(ctx, lat, lng, param.Address, param.Ward, param.City, param.Country)
+	md := metadata.full(defaultMetadata())
+	param.TimeZone, param.OperatingHours, param.ContactEmail, param.ContactPhone, param.Tags = md.TimeZone, md.OperatingHours, md.ContactEmail, md.ContactPhone, md.Tags
+	param.Attributes = attrs
+	param.Status = status
 
+	tracing.Actor(span, param.OrgID, actorID(ctx))
 	span.SetAttributes(
 		attribute.String("warehouse.name", param.Name),
 		attribute.String("warehouse.address", param.Address),
 	)
 
-	dbStart := time.Now()
-	warehouse, err := h.queries.CreateWarehouse(ctx, param)
-	dbDuration := time.Since(dbStart)
-
-	// Record database operation duration (Prometheus)
-	if h.prometheusMetrics != nil {
-		h.prometheusMetrics.RecordDBOperation("create", "warehouse", dbDuration, err)
+	// A new warehouse must satisfy the schema even without attributes, it
+	// may require some
+	if err := h.checkAttributes(ctx, param.OrgID, observability.EntityWarehouse, param.Attributes); err != nil {
+		span.RecordError(err)
+		respondAttributesError(ctx, err)
+		return
 	}
 
+	var warehouse models.Warehouse
+	err = pgx.BeginFunc(ctx, h.db, func(tx pgx.Tx) error {
+		qtx := h.queries.WithTx(tx)
+		if !force {
+			if err := h.checkDuplicateWarehouse(ctx, qtx, param.OrgID, param.Name, param.Address, param.Ward, param.City, param.Country); err != nil {
+				return err
+			}
+		}
+		if param.Slug, err = h.warehouseSlug(ctx, qtx, param.OrgID, param.Name); err != nil {
+			return err
+		}
+		dbStart := time.Now()
+		warehouse, err = qtx.CreateWarehouse(ctx, param)
+		h.recordDBOperation(ctx, "create", "warehouse", dbStart, err)
+		if err != nil {
+			return err
+		}
+		if err := h.enforceWarehouseQuota(ctx, qtx, param.OrgID); err != nil {
+			return err
+		}
+		if err := h.saveRawAddress(ctx, qtx, rawAddressParams(warehouse.ID, param.OrgID, raw, validated)); err != nil {
+			return err
+		}
+		return h.enqueueWarehouseEvent(ctx, qtx, outbox.TopicWarehouseCreated, warehouse)
+	})
+
+	if exceeded, ok := quotaExceeded(err); ok {
+		h.recordOperation(param.OrgID, observability.EntityWarehouse, "create", opStart, err)
+		respondQuotaExceeded(ctx, exceeded)
+		return
+	}
+	var duplicate *duplicateWarehouseError
+	if errors.As(err, &duplicate) {
+		tracing.Result(span, "duplicate")
+		h.recordOperation(param.OrgID, observability.EntityWarehouse, "create", opStart, err)
+		respondDuplicateWarehouse(ctx, duplicate)
+		return
+	}
+	if conflict, ok := conflictFor(err); ok {
+		h.recordOperation(param.OrgID, observability.EntityWarehouse, "create", opStart, err)
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": tr(ctx, conflict.message),
+			"field": conflict.field,
+		})
+		return
+	}
 	if err != nil {
 		slog.Error("Could not create warehouse: ", slog.Any("err", err.Error()))
-		span.RecordError(err)
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to create warehouse",
+		tracing.Failed(span, err)
+		h.recordOperation(param.OrgID, observability.EntityWarehouse, "create", opStart, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to create warehouse"),
 		})
 		return
 	}
 
 	// Record successful creation (Prometheus)
-	if h.prometheusMetrics != nil {
-		h.prometheusMetrics.RecordInventoryOperation("create", warehouse.Name, warehouse.Address)
-		// Update active warehouse count (increment by 1)
-		h.prometheusMetrics.UpdateInventoryCount(1) // This should be the actual total count in production
-	}
+	h.recordOperation(param.OrgID, observability.EntityWarehouse, "create", opStart, nil)
+	h.refreshWarehouseGauge(ctx, param.OrgID)
 
 	// Record successful operation
-	span.SetAttributes(
-		attribute.Int64("warehouse.id", warehouse.ID),
-		attribute.String("operation.status", "success"),
-	)
+	tracing.Entity(span, observability.EntityWarehouse, warehouse.ID)
+	tracing.Transition(span, observability.EntityWarehouse, warehouse.ID, "", warehouse.Status)
+	tracing.Result(span, observability.StatusSuccess)
 
-	ctx.JSON(200, gin.H{
-		"message": "Create Warehouse Successfully",
-		"data":    warehouse,
+	respondV1(ctx, 200, gin.H{
+		"message": tr(ctx, "Create Warehouse Successfully"),
+		"data":    newWarehouseResponse(warehouse),
 	})
 }
 
 func (h *Handlers) DeleteWarehouse(ctx *gin.Context) {
+	opStart := time.Now()
 	// Start a new span for this operation
-	_, span := h.tracer.Start(ctx.Request.Context(), "DeleteWarehouse")
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "DeleteWarehouse")
 	defer span.End()
 
 	idStr := ctx.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 32)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid warehouse ID format",
+			"error": tr(ctx, "Invalid warehouse ID format"),
 		})
 		return
 	}
-	span.SetAttributes(attribute.Int64("warehouse.id", id))
-
-	dbStart := time.Now()
-	err = h.queries.DeleteWarehouse(ctx, id)
-	dbDuration := time.Since(dbStart)
+	orgID := tenantID(ctx)
+	traceOperation(ctx, span, observability.EntityWarehouse, id)
 
-	// Record database operation duration (Prometheus)
-	if h.prometheusMetrics != nil {
-		h.prometheusMetrics.RecordDBOperation("delete", "warehouse", dbDuration, err)
+	cascade, err := cascadeParam(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	span.SetAttributes(attribute.Bool("warehouse.cascade", cascade))
+
+	err = h.deleteWarehouse(spanCtx, orgID, id, cascade, parseIfMatch(ctx))
+	var inUse *warehouseInUseError
+	if errors.As(err, &inUse) {
+		tracing.Result(span, "in_use")
+		h.recordOperation(orgID, observability.EntityWarehouse, "delete", opStart, err)
+		respondWarehouseInUse(ctx, inUse)
+		return
+	}
+	var roomInUse *storageRoomInUseError
+	if errors.As(err, &roomInUse) {
+		tracing.Result(span, "in_use")
+		h.recordOperation(orgID, observability.EntityWarehouse, "delete", opStart, err)
+		respondStorageRoomInUse(ctx, roomInUse)
+		return
+	}
+	if errors.Is(err, errVersionMismatch) {
+		tracing.Result(span, "version_mismatch")
+		h.recordOperation(orgID, observability.EntityWarehouse, "delete", opStart, err)
+		ctx.JSON(http.StatusPreconditionFailed, gin.H{
+			"error": tr(ctx, "Warehouse changed since the version in If-Match"),
+		})
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		tracing.Result(span, observability.StatusNotFound)
+		h.recordOperation(orgID, observability.EntityWarehouse, "delete", opStart, pgx.ErrNoRows)
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Warehouse not found"),
+		})
+		return
 	}
-
 	if err != nil {
 		slog.Error("Failed to delete warehouse: ", slog.Any("err", err.Error()))
-		span.RecordError(err)
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to delete warehouse",
+		tracing.Failed(span, err)
+		h.recordOperation(orgID, observability.EntityWarehouse, "delete", opStart, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to delete warehouse"),
 		})
 		return
 	}
 
 	// Record successful deletion (Prometheus)
-	if h.prometheusMetrics != nil {
-		h.prometheusMetrics.RecordInventoryOperation("delete", "warehouse", "unknown")
-	}
+	h.recordOperation(orgID, observability.EntityWarehouse, "delete", opStart, nil)
+	h.refreshWarehouseGauge(ctx, orgID)
 
 	// Record successful operation
-	span.SetAttributes(
-		attribute.String("operation.status", "success"),
-	)
+	tracing.Result(span, observability.StatusSuccess)
+
+	ctx.JSON(200, gin.H{"message": tr(ctx, "Delete Warehouse Successfully")})
+}
+
+// patchWarehouseRequest holds the fields of a partial update. Omitted fields
+// are nil and keep their stored value.
+type patchWarehouseRequest struct {
+	Name      *string  `json:"name" binding:"omitempty,min=1"`
+	Address   *string  `json:"address"`
+	Ward      *string  `json:"ward"`
+	District  *string  `json:"district"`
+	City      *string  `json:"city"`
+	Country   *string  `json:"country"`
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+	warehouseMetadata
+	// Attributes replaces the whole attributes object when sent
+	Attributes json.RawMessage `json:"attributes"`
+}
+
+func (r patchWarehouseRequest) empty() bool {
+	return !r.addressChanged() && r.Name == nil && r.Latitude == nil && r.Longitude == nil && r.warehouseMetadata.empty() && r.Attributes == nil
+}
+
+func (r patchWarehouseRequest) addressChanged() bool {
+	return r.Address != nil || r.Ward != nil || r.District != nil || r.City != nil || r.Country != nil
+}
+
+// textParam maps an optional request field to a nullable query parameter
+func textParam(s *string) pgtype.Text {
+	if s == nil {
+		return pgtype.Text{}
+	}
+	return pgtype.Text{String: *s, Valid: true}
+}
+
+// int4Param maps an optional request field to a nullable query parameter
+func int4Param(n *int32) pgtype.Int4 {
+	if n == nil {
+		return pgtype.Int4{}
+	}
+	return pgtype.Int4{Int32: *n, Valid: true}
+}
+
+// PatchWarehouse updates only the fields present in the JSON body
+func (h *Handlers) PatchWarehouse(ctx *gin.Context) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "PatchWarehouse")
+	defer span.End()
+
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "Invalid warehouse ID format"),
+		})
+		return
+	}
+	var req patchWarehouseRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   tr(ctx, "Invalid warehouse payload"),
+			"details": err.Error(),
+		})
+		return
+	}
+	if req.empty() {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "No fields to update"),
+		})
+		return
+	}
+	if err := validCoordinates(req.Latitude, req.Longitude); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err := req.warehouseMetadata.validate(); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	var attrs []byte
+	if req.Attributes != nil {
+		if attrs, err = decodeAttributes(req.Attributes); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+	}
+	orgID := tenantID(ctx)
+	traceOperation(ctx, span, observability.EntityWarehouse, id)
+	if attrs != nil {
+		if err := h.checkAttributes(spanCtx, orgID, observability.EntityWarehouse, attrs); err != nil {
+			span.RecordError(err)
+			respondAttributesError(ctx, err)
+			return
+		}
+	}
+
+	params := models.PatchWarehouseParams{
+		Name:       textParam(req.Name),
+		Address:    textParam(req.Address),
+		Ward:       textParam(req.Ward),
+		District:   textParam(req.District),
+		City:       textParam(req.City),
+		Country:    textParam(req.Country),
+		Latitude:   floatParam(req.Latitude),
+		Longitude:  floatParam(req.Longitude),
+		ID:         id,
+		OrgID:      orgID,
+		Attributes: attrs,
+	}
+	req.warehouseMetadata.patchParams(&params)
+
+	var warehouse models.Warehouse
+	err = pgx.BeginFunc(spanCtx, h.db, func(tx pgx.Tx) error {
+		qtx := h.queries.WithTx(tx)
+		dbStart := time.Now()
+		warehouse, err = qtx.PatchWarehouse(spanCtx, params)
+		h.recordDBOperation(spanCtx, "update", "warehouse", dbStart, err)
+		if err != nil {
+			return err
+		}
+		if err := h.authorize(ctx, authz.Write, observability.EntityWarehouse, warehouse.OrgID); err != nil {
+			return err
+		}
+		if req.addressChanged() {
+			// The sent parts are merged with the stored ones first, the
+			// address is normalized and validated as a whole
+			stored := warehouseAddress(warehouse)
+			addr, validated, err := h.normalizeAddress(spanCtx, stored)
+			if err != nil {
+				return err
+			}
+			if err := h.saveRawAddress(spanCtx, qtx, models.UpsertWarehouseAddressParams{
+				Address:     textParam(req.Address),
+				Ward:        textParam(req.Ward),
+				District:    textParam(req.District),
+				City:        textParam(req.City),
+				Country:     textParam(req.Country),
+				Validated:   validated,
+				WarehouseID: id,
+				OrgID:       orgID,
+			}); err != nil {
+				return err
+			}
+			fix := models.PatchWarehouseParams{ID: id, OrgID: orgID}
+			refix := addr != stored
+			if refix {
+				fix.Address, fix.Ward, fix.District = textParam(&addr.Street), textParam(&addr.Ward), textParam(&addr.District)
+				fix.City, fix.Country = textParam(&addr.City), textParam(&addr.Country)
+			}
+			// A moved address without explicit coordinates is geocoded again
+			if req.Latitude == nil && h.geocoder != nil {
+				fix.Latitude, fix.Longitude = h.coordinates(spanCtx, nil, nil, addr.Street, addr.Ward, addr.District, addr.City, addr.Country)
+				refix = true
+			}
+			if refix {
+				dbStart = time.Now()
+				warehouse, err = qtx.PatchWarehouse(spanCtx, fix)
+				h.recordDBOperation(spanCtx, "update", "warehouse", dbStart, err)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return h.enqueueWarehouseEvent(spanCtx, qtx, outbox.TopicWarehouseUpdated, warehouse)
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		h.recordOperation(orgID, observability.EntityWarehouse, "patch", opStart, pgx.ErrNoRows)
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Warehouse not found"),
+		})
+		return
+	}
+	if isAddressError(err) {
+		h.recordOperation(orgID, observability.EntityWarehouse, "patch", opStart, err)
+		respondAddressError(ctx, err)
+		return
+	}
+	if conflict, ok := conflictFor(err); ok {
+		h.recordOperation(orgID, observability.EntityWarehouse, "patch", opStart, err)
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": tr(ctx, conflict.message),
+			"field": conflict.field,
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Could not patch warehouse: ", slog.Any("err", err.Error()))
+		tracing.Failed(span, err)
+		h.recordOperation(orgID, observability.EntityWarehouse, "patch", opStart, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to update warehouse"),
+		})
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntityWarehouse, "patch", opStart, nil)
 
-	ctx.JSON(200, gin.H{"message": "Delete Warehouse Successfully"})
+	span.SetAttributes(attribute.String("warehouse.name", warehouse.Name))
+	tracing.Result(span, observability.StatusSuccess)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Update Warehouse Successfully"),
+		"data":    newWarehouseResponse(warehouse),
+	})
 }
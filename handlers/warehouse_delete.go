@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+	"warehouse-service/outbox"
+	"warehouse-service/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxBlockingRooms caps the storage rooms listed in a 409 delete response
+const maxBlockingRooms = 20
+
+// warehouseInUseError is returned when a warehouse still has storage rooms
+// and the delete was not cascaded
+type warehouseInUseError struct {
+	total int64
+	rooms []models.StorageRoom
+}
+
+func (e *warehouseInUseError) Error() string {
+	return fmt.Sprintf("warehouse still has %d storage rooms", e.total)
+}
+
+// cascadeParam parses the optional ?cascade= flag of a delete request
+func cascadeParam(ctx *gin.Context) (bool, error) {
+	raw := ctx.Query("cascade")
+	if raw == "" {
+		return false, nil
+	}
+	cascade, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("Invalid cascade value %q", raw)
+	}
+	return cascade, nil
+}
+
+// deleteWarehouse removes a tenant's warehouse in one transaction. Without
+// cascade a warehouse that still has storage rooms is kept and a
+// *warehouseInUseError lists the rooms in the way, with cascade the rooms
+// are deleted first, each as deleteStorageRoom would: a room still holding
+// stock keeps the warehouse and a *storageRoomInUseError summarizes it.
+// Its attachments are always deleted, their objects after the commit. A
+// missing warehouse is reported as pgx.ErrNoRows, a warehouse no longer at
+// a version cond names as errVersionMismatch.
+func (h *Handlers) deleteWarehouse(ctx context.Context, orgID string, id int64, cascade bool, cond ifMatch) error {
+	tx, err := h.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) // This will be ignored if tx.Commit() succeeds
+	qtx := h.queries.WithTx(tx)
+	var roomsDeleted int64
+
+	if cond != nil {
+		// Locking the warehouse keeps writes out until the delete commits
+		dbStart := time.Now()
+		_, err = qtx.GetWarehouseForUpdate(ctx, models.GetWarehouseForUpdateParams{
+			ID:    id,
+			OrgID: orgID,
+		})
+		h.recordDBOperation(ctx, "get", "warehouse", dbStart, err)
+		if err == nil {
+			err = h.checkVersion(ctx, qtx, observability.EntityWarehouse, id, orgID, cond)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// storage_room.warehouse_id is an int, larger IDs can't have rooms
+	if id <= math.MaxInt32 {
+		if cascade {
+			dbStart := time.Now()
+			roomIDs, err := qtx.ListStorageRoomIDsInWarehouse(ctx, models.ListStorageRoomIDsInWarehouseParams{
+				WarehouseID: int32(id),
+				OrgID:       orgID,
+			})
+			h.recordDBOperation(ctx, "list", "storage_room", dbStart, err)
+			if err != nil {
+				return err
+			}
+			// Each room goes as DELETE /storageroom/:id would take it
+			for _, roomID := range roomIDs {
+				stock, err := h.lockStorageRoomStock(ctx, qtx, orgID, roomID, nil)
+				if err != nil {
+					return err
+				}
+				if len(stock) > 0 {
+					return &storageRoomInUseError{id: roomID, stock: stock, cascade: true}
+				}
+				deleted, err := h.removeStorageRoom(ctx, qtx, orgID, roomID)
+				if err != nil {
+					return err
+				}
+				roomsDeleted += deleted
+			}
+		} else {
+			dbStart := time.Now()
+			total, err := qtx.CountStorageRoomsInWarehouse(ctx, models.CountStorageRoomsInWarehouseParams{
+				WarehouseID: int32(id),
+				OrgID:       orgID,
+			})
+			h.recordDBOperation(ctx, "count", "storage_room", dbStart, err)
+			if err != nil {
+				return err
+			}
+			if total > 0 {
+				dbStart = time.Now()
+				rooms, err := qtx.ListStorageRoomsInWarehouse(ctx, models.ListStorageRoomsInWarehouseParams{
+					WarehouseID: int32(id),
+					OrgID:       orgID,
+					Limit:       maxBlockingRooms,
+				})
+				h.recordDBOperation(ctx, "list", "storage_room", dbStart, err)
+				if err != nil {
+					return err
+				}
+				return &warehouseInUseError{total: total, rooms: rooms}
+			}
+		}
+	}
+
+	dbStart := time.Now()
+	deleted, err := qtx.DeleteWarehouse(ctx, models.DeleteWarehouseParams{
+		ID:    id,
+		OrgID: orgID,
+	})
+	h.recordDBOperation(ctx, "delete", "warehouse", dbStart, err)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return pgx.ErrNoRows
+	}
+	dbStart = time.Now()
+	objectKeys, err := qtx.DeleteAttachmentsInWarehouse(ctx, models.DeleteAttachmentsInWarehouseParams{
+		WarehouseID: id,
+		OrgID:       orgID,
+	})
+	h.recordDBOperation(ctx, "delete", "attachment", dbStart, err)
+	if err != nil {
+		return err
+	}
+	dbStart = time.Now()
+	err = outbox.Enqueue(ctx, qtx, orgID, outbox.TopicWarehouseDeleted, id, warehouseDeleted{ID: id})
+	h.recordDBOperation(ctx, "create", "outbox", dbStart, err)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	tracing.RowsAffected(trace.SpanFromContext(ctx), deleted+roomsDeleted)
+	// Objects go once their rows are gone for good
+	if h.attachments.Store != nil {
+		h.deleteObjects(ctx, objectKeys...)
+	}
+	return nil
+}
+
+// respondWarehouseInUse answers a blocked v1 delete with the rooms in the way
+func respondWarehouseInUse(ctx *gin.Context, inUse *warehouseInUseError) {
+	respondV1(ctx, http.StatusConflict, gin.H{
+		"error":              tr(ctx, "Warehouse still has storage rooms, delete them first or retry with ?cascade=true"),
+		"storage_room_count": inUse.total,
+		"storage_rooms":      mapSlice(inUse.rooms, newStorageRoomResponse),
+	})
+}
+
+// respondWarehouseInUseV2 answers a blocked v2 delete with the rooms in the way
+func respondWarehouseInUseV2(ctx *gin.Context, inUse *warehouseInUseError) {
+	numbers := make([]string, 0, len(inUse.rooms))
+	for _, room := range inUse.rooms {
+		numbers = append(numbers, room.Number)
+	}
+	message := fmt.Sprintf("Warehouse still has %d storage rooms (%s), delete them first or retry with ?cascade=true",
+		inUse.total, strings.Join(numbers, ", "))
+	respondV2Error(ctx, http.StatusConflict, errCodeConflict, message)
+}
+
+// respondStorageRoomInUseV2 answers a cascaded v2 delete blocked by a room
+// still holding stock
+func respondStorageRoomInUseV2(ctx *gin.Context, inUse *storageRoomInUseError) {
+	quantity, _ := inUse.quantity()
+	message := fmt.Sprintf("Storage room %d still holds %d units of %d SKUs, move them or delete the room with ?relocate_to=<storage room ID> first",
+		inUse.id, quantity, len(inUse.stock))
+	respondV2Error(ctx, http.StatusConflict, errCodeConflict, message)
+}
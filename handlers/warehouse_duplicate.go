@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	models "warehouse-service/models/sqlc"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Trigram similarities from which an existing warehouse is taken for the
+// one being created. Names differing in case, spacing or punctuation only
+// score 1.
+const (
+	duplicateNameSimilarity    = 0.8
+	duplicateAddressSimilarity = 0.6
+)
+
+// maxDuplicateCandidates caps the warehouses listed in a 409 create response
+const maxDuplicateCandidates = 5
+
+// duplicateWarehouseError is returned when a new warehouse looks like
+// existing ones and the create was not forced
+type duplicateWarehouseError struct {
+	candidates []models.FindDuplicateWarehousesRow
+}
+
+func (e *duplicateWarehouseError) Error() string {
+	return fmt.Sprintf("warehouse looks like %d existing warehouses", len(e.candidates))
+}
+
+// forceParam parses the optional ?force= flag of a create request
+func forceParam(ctx *gin.Context) (bool, error) {
+	raw := ctx.Query("force")
+	if raw == "" {
+		return false, nil
+	}
+	force, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("Invalid force value %q", raw)
+	}
+	return force, nil
+}
+
+// fullAddress joins the non-empty parts of an address, street first, as the
+// duplicate check compares them
+func fullAddress(parts ...string) string {
+	var kept []string
+	for _, part := range parts {
+		if part = strings.Join(strings.Fields(part), " "); part != "" {
+			kept = append(kept, part)
+		}
+	}
+	return strings.Join(kept, " ")
+}
+
+// checkDuplicateWarehouse fails with a *duplicateWarehouseError when the
+// tenant has warehouses with a similar name and a similar full address. It
+// runs in the create's transaction under the tenant's warehouse lock, so
+// two concurrent imports of the same row can't both pass. A warehouse
+// without an address is never taken for a duplicate.
+func (h *Handlers) checkDuplicateWarehouse(ctx context.Context, qtx *models.Queries, orgID, name string, address ...string) error {
+	full := fullAddress(address...)
+	if full == "" {
+		return nil
+	}
+	if err := qtx.LockQuota(ctx, "warehouses:"+orgID); err != nil {
+		return err
+	}
+	dbStart := time.Now()
+	candidates, err := qtx.FindDuplicateWarehouses(ctx, models.FindDuplicateWarehousesParams{
+		Name:             name,
+		Address:          full,
+		OrgID:            orgID,
+		NameThreshold:    duplicateNameSimilarity,
+		AddressThreshold: duplicateAddressSimilarity,
+		PageLimit:        maxDuplicateCandidates,
+	})
+	h.recordDBOperation(ctx, "list", "warehouse", dbStart, err)
+	if err != nil {
+		return err
+	}
+	if len(candidates) > 0 {
+		return &duplicateWarehouseError{candidates: candidates}
+	}
+	return nil
+}
+
+// respondDuplicateWarehouse answers a blocked v1 create with the warehouses
+// it looks like
+func respondDuplicateWarehouse(ctx *gin.Context, duplicate *duplicateWarehouseError) {
+	respondV1(ctx, http.StatusConflict, gin.H{
+		"error":      tr(ctx, "Warehouse looks like an existing one, retry with ?force=true to create it anyway"),
+		"candidates": mapSlice(duplicate.candidates, newDuplicateWarehouseResponse),
+	})
+}
+
+// respondDuplicateWarehouseV2 answers a blocked v2 create with one error per
+// warehouse it looks like
+func respondDuplicateWarehouseV2(ctx *gin.Context, duplicate *duplicateWarehouseError) {
+	apiErrors := make([]apiError, 0, len(duplicate.candidates))
+	for _, c := range duplicate.candidates {
+		apiErrors = append(apiErrors, apiError{
+			Code: errCodeDuplicate,
+			Message: tr(ctx, "Warehouse looks like warehouse %d %q at %q, retry with ?force=true to create it anyway",
+				c.ID, c.Name, fullAddress(c.Address, c.Ward, c.District, c.City, c.Country)),
+			Field: "name",
+		})
+	}
+	ctx.JSON(http.StatusConflict, envelope{Errors: apiErrors})
+}
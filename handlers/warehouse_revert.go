@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	"warehouse-service/authz"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+	"warehouse-service/outbox"
+	"warehouse-service/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// errVersionNotFound is returned when ?to_version= is not a version of the
+// warehouse
+var errVersionNotFound = errors.New("version not found")
+
+// RevertWarehouse restores the fields of the warehouse from a version of its
+// history given by ?to_version=. The lifecycle state is kept, it only
+// changes through its transitions. The revert is a new version itself, so it
+// can be reverted in turn.
+func (h *Handlers) RevertWarehouse(ctx *gin.Context) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "RevertWarehouse")
+	defer span.End()
+
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "Invalid warehouse ID format"),
+		})
+		return
+	}
+	toVersion, err := strconv.ParseInt(ctx.Query("to_version"), 10, 64)
+	if err != nil || toVersion <= 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "to_version must be a positive integer"),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	traceOperation(ctx, span, observability.EntityWarehouse, id)
+	span.SetAttributes(attribute.Int64("warehouse.to_version", toVersion))
+
+	var warehouse models.Warehouse
+	err = pgx.BeginFunc(spanCtx, h.db, func(tx pgx.Tx) error {
+		qtx := h.queries.WithTx(tx)
+		dbStart := time.Now()
+		current, err := qtx.GetWarehouseForUpdate(spanCtx, models.GetWarehouseForUpdateParams{
+			ID:    id,
+			OrgID: orgID,
+		})
+		h.recordDBOperation(spanCtx, "get", "warehouse", dbStart, err)
+		if err != nil {
+			return err
+		}
+		if err := h.authorize(ctx, authz.Write, observability.EntityWarehouse, current.OrgID); err != nil {
+			return err
+		}
+
+		dbStart = time.Now()
+		version, err := qtx.GetWarehouseVersion(spanCtx, models.GetWarehouseVersionParams{
+			Version: toVersion,
+			ID:      id,
+			OrgID:   orgID,
+		})
+		h.recordDBOperation(spanCtx, "get", "row_history", dbStart, err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errVersionNotFound
+		}
+		if err != nil {
+			return err
+		}
+		// The attribute schema may have changed since
+		if err := h.checkAttributes(spanCtx, orgID, observability.EntityWarehouse, version.Attributes); err != nil {
+			return err
+		}
+
+		dbStart = time.Now()
+		warehouse, err = qtx.UpdateWarehouse(spanCtx, models.UpdateWarehouseParams{
+			ID:             id,
+			Name:           version.Name,
+			Address:        version.Address,
+			Ward:           version.Ward,
+			District:       version.District,
+			City:           version.City,
+			Country:        version.Country,
+			OrgID:          orgID,
+			Latitude:       version.Latitude,
+			Longitude:      version.Longitude,
+			TimeZone:       version.TimeZone,
+			OperatingHours: version.OperatingHours,
+			ContactEmail:   version.ContactEmail,
+			ContactPhone:   version.ContactPhone,
+			Tags:           version.Tags,
+			Attributes:     version.Attributes,
+		})
+		h.recordDBOperation(spanCtx, "update", "warehouse", dbStart, err)
+		if err != nil {
+			return err
+		}
+		if err := h.recordAudit(spanCtx, qtx, auditEntry{
+			OrgID:      orgID,
+			EntityType: auditEntityWarehouse,
+			EntityID:   id,
+			Action:     "revert",
+			FromStatus: current.Status,
+			ToStatus:   warehouse.Status,
+			Actor:      actorID(ctx),
+		}); err != nil {
+			return err
+		}
+		return h.enqueueWarehouseEvent(spanCtx, qtx, outbox.TopicWarehouseUpdated, warehouse)
+	})
+
+	var invalid *attributesInvalidError
+	if errors.As(err, &invalid) {
+		h.recordOperation(orgID, observability.EntityWarehouse, "revert", opStart, err)
+		respondAttributesInvalid(ctx, invalid)
+		return
+	}
+	if conflict, ok := conflictFor(err); ok {
+		h.recordOperation(orgID, observability.EntityWarehouse, "revert", opStart, err)
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": tr(ctx, conflict.message),
+			"field": conflict.field,
+		})
+		return
+	}
+	if errors.Is(err, errVersionNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Version not found"),
+		})
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		h.recordOperation(orgID, observability.EntityWarehouse, "revert", opStart, pgx.ErrNoRows)
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Warehouse not found"),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Could not revert warehouse: ", slog.Any("err", err.Error()))
+		tracing.Failed(span, err)
+		h.recordOperation(orgID, observability.EntityWarehouse, "revert", opStart, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to revert warehouse"),
+		})
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntityWarehouse, "revert", opStart, nil)
+
+	span.SetAttributes(attribute.String("warehouse.name", warehouse.Name))
+	tracing.Result(span, observability.StatusSuccess)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Revert Warehouse Successfully"),
+		"data":    newWarehouseResponse(warehouse),
+	})
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+	"warehouse-service/slug"
+	"warehouse-service/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// WarehouseRef names a warehouse in a request body. A JSON number is its
+// ID, a JSON string its slug or its ID in digits; zero and null name none,
+// so binding:"required" still applies.
+type WarehouseRef string
+
+func (r *WarehouseRef) UnmarshalJSON(data []byte) error {
+	var id int64
+	if err := json.Unmarshal(data, &id); err == nil {
+		*r = ""
+		if id != 0 {
+			*r = WarehouseRef(strconv.FormatInt(id, 10))
+		}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("a warehouse is named by its ID or its slug, not %s", data)
+	}
+	*r = WarehouseRef(s)
+	return nil
+}
+
+// resolveWarehouse returns the ID of the warehouse ref names, looking a
+// slug up among the warehouses of orgID as the WarehouseSlugs middleware
+// does for paths. A slug no warehouse of the tenant has is reported as
+// pgx.ErrNoRows. An ID is returned as is, without checking it exists.
+func (h *Handlers) resolveWarehouse(ctx context.Context, q *models.Queries, orgID string, ref WarehouseRef) (int64, error) {
+	if slug.IsID(string(ref)) {
+		id, err := strconv.ParseInt(string(ref), 10, 64)
+		if err != nil {
+			// Too large for an ID, so no warehouse has it
+			return 0, pgx.ErrNoRows
+		}
+		return id, nil
+	}
+	dbStart := time.Now()
+	id, err := q.GetWarehouseIDBySlug(ctx, models.GetWarehouseIDBySlugParams{
+		OrgID: orgID,
+		Slug:  string(ref),
+	})
+	h.recordDBOperation(ctx, "get", "warehouse", dbStart, err)
+	return id, err
+}
+
+// warehouseSlug picks the slug of a new warehouse of orgID called name. It
+// takes the tenant's warehouse quota lock until the transaction ends, so
+// two warehouses created at once don't pick the same suffix.
+func (h *Handlers) warehouseSlug(ctx context.Context, qtx *models.Queries, orgID, name string) (string, error) {
+	if err := qtx.LockQuota(ctx, "warehouses:"+orgID); err != nil {
+		return "", err
+	}
+	base := slug.Make(name)
+	dbStart := time.Now()
+	taken, err := qtx.ListWarehouseSlugs(ctx, models.ListWarehouseSlugsParams{
+		OrgID: orgID,
+		Base:  base,
+	})
+	h.recordDBOperation(ctx, "list", "warehouse", dbStart, err)
+	if err != nil {
+		return "", err
+	}
+	return slug.Next(base, taken), nil
+}
+
+// GetWarehouseBySlug answers GET /v1/warehouse/by-slug/:slug as GetWarehouse
+// answers for the ID of the warehouse. Unlike :id elsewhere the parameter
+// is always a slug.
+func (h *Handlers) GetWarehouseBySlug(ctx *gin.Context) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "GetWarehouseBySlug")
+	defer span.End()
+
+	orgID := tenantID(ctx)
+	s := ctx.Param("slug")
+	span.SetAttributes(
+		attribute.String("tenant.id", orgID),
+		attribute.String("warehouse.slug", s),
+	)
+
+	dbStart := time.Now()
+	id, err := h.readQueries(spanCtx).GetWarehouseIDBySlug(spanCtx, models.GetWarehouseIDBySlugParams{
+		OrgID: orgID,
+		Slug:  s,
+	})
+	h.recordDBOperation(spanCtx, "get", "warehouse", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		tracing.Result(span, observability.StatusNotFound)
+		h.recordOperation(orgID, observability.EntityWarehouse, "get", opStart, err)
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Warehouse not found"),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Got an error while looking up warehouse slug: ", slog.Any("err", err.Error()))
+		tracing.Failed(span, err)
+		h.recordOperation(orgID, observability.EntityWarehouse, "get", opStart, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to get warehouse"),
+		})
+		return
+	}
+	tracing.Result(span, observability.StatusSuccess)
+
+	ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: strconv.FormatInt(id, 10)})
+	h.GetWarehouse(ctx)
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestWarehouseRefUnmarshal(t *testing.T) {
+	tests := []struct {
+		body string
+		want WarehouseRef
+	}{
+		{body: `{"warehouse_id": 17}`, want: "17"},
+		{body: `{"warehouse_id": "17"}`, want: "17"},
+		{body: `{"warehouse_id": "kho-ha-noi"}`, want: "kho-ha-noi"},
+		{body: `{"warehouse_id": 0}`, want: ""},
+		{body: `{"warehouse_id": null}`, want: ""},
+		{body: `{}`, want: ""},
+	}
+	for _, tt := range tests {
+		var req createReceiptRequest
+		if err := json.Unmarshal([]byte(tt.body), &req); err != nil {
+			t.Errorf("decode %s: %v", tt.body, err)
+			continue
+		}
+		if req.WarehouseID != tt.want {
+			t.Errorf("decode %s = %q, want %q", tt.body, req.WarehouseID, tt.want)
+		}
+	}
+
+	for _, body := range []string{`{"warehouse_id": 1.5}`, `{"warehouse_id": true}`, `{"warehouse_id": ["main"]}`} {
+		var req createReceiptRequest
+		if err := json.Unmarshal([]byte(body), &req); err == nil {
+			t.Errorf("decode %s = %q, want an error", body, req.WarehouseID)
+		}
+	}
+}
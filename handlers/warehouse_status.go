@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	"warehouse-service/authz"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+	"warehouse-service/outbox"
+	"warehouse-service/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Lifecycle states of a warehouse
+const (
+	warehouseStatusDraft       = "draft"
+	warehouseStatusActive      = "active"
+	warehouseStatusMaintenance = "maintenance"
+	warehouseStatusArchived    = "archived"
+)
+
+const auditEntityWarehouse = "warehouse"
+
+// warehouseTransitions lists the states each state may move to. A draft
+// that is never used can be archived directly, an archived warehouse comes
+// back through activate.
+var warehouseTransitions = map[string][]string{
+	warehouseStatusDraft:       {warehouseStatusActive, warehouseStatusArchived},
+	warehouseStatusActive:      {warehouseStatusMaintenance, warehouseStatusArchived},
+	warehouseStatusMaintenance: {warehouseStatusActive, warehouseStatusArchived},
+	warehouseStatusArchived:    {warehouseStatusActive},
+}
+
+// warehouseTransitionAllowed reports whether a warehouse may move from one
+// state to another
+func warehouseTransitionAllowed(from, to string) bool {
+	for _, status := range warehouseTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+func validWarehouseStatus(status string) bool {
+	_, ok := warehouseTransitions[status]
+	return ok
+}
+
+// initialWarehouseStatus parses the status of a new warehouse, which starts
+// as a draft or active. Empty keeps the column default, active.
+func initialWarehouseStatus(status string) (pgtype.Text, error) {
+	switch status {
+	case "":
+		return pgtype.Text{}, nil
+	case warehouseStatusDraft, warehouseStatusActive:
+		return pgtype.Text{String: status, Valid: true}, nil
+	}
+	return pgtype.Text{}, fmt.Errorf("status must be %s or %s", warehouseStatusDraft, warehouseStatusActive)
+}
+
+// statusFilter reads the status a warehouse list is filtered by
+func statusFilter(ctx *gin.Context) (pgtype.Text, error) {
+	status := ctx.Query("status")
+	if status == "" {
+		return pgtype.Text{}, nil
+	}
+	if !validWarehouseStatus(status) {
+		return pgtype.Text{}, fmt.Errorf("invalid status %q", status)
+	}
+	return pgtype.Text{String: status, Valid: true}, nil
+}
+
+// warehouseTransitionError rejects a state change the current state does
+// not allow
+type warehouseTransitionError struct {
+	From string
+	To   string
+}
+
+func (e *warehouseTransitionError) Error() string {
+	return fmt.Sprintf("Warehouse is %s and cannot become %s", e.From, e.To)
+}
+
+// ActivateWarehouse puts a draft, maintenance or archived warehouse in use
+func (h *Handlers) ActivateWarehouse(ctx *gin.Context) {
+	h.warehouseTransition(ctx, "activate", warehouseStatusActive)
+}
+
+// StartWarehouseMaintenance takes an active warehouse out of use for a while
+func (h *Handlers) StartWarehouseMaintenance(ctx *gin.Context) {
+	h.warehouseTransition(ctx, "maintenance", warehouseStatusMaintenance)
+}
+
+// ArchiveWarehouse retires a warehouse. It keeps its storage rooms, stock
+// and history and can be activated again.
+func (h *Handlers) ArchiveWarehouse(ctx *gin.Context) {
+	h.warehouseTransition(ctx, "archive", warehouseStatusArchived)
+}
+
+// warehouseTransition moves the warehouse to status under a row lock, and
+// records the change in the audit log and the outbox in the same
+// transaction
+func (h *Handlers) warehouseTransition(ctx *gin.Context, operation, status string) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), operation+"Warehouse")
+	defer span.End()
+
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "Invalid warehouse ID format"),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	traceOperation(ctx, span, observability.EntityWarehouse, id)
+
+	var fromStatus string
+	var warehouse models.Warehouse
+	err = pgx.BeginFunc(spanCtx, h.db, func(tx pgx.Tx) error {
+		qtx := h.queries.WithTx(tx)
+		dbStart := time.Now()
+		current, err := qtx.GetWarehouseForUpdate(spanCtx, models.GetWarehouseForUpdateParams{
+			ID:    id,
+			OrgID: orgID,
+		})
+		h.recordDBOperation(spanCtx, "get", "warehouse", dbStart, err)
+		if err != nil {
+			return err
+		}
+		if err := h.authorize(ctx, authz.Write, observability.EntityWarehouse, current.OrgID); err != nil {
+			return err
+		}
+		fromStatus = current.Status
+		if !warehouseTransitionAllowed(fromStatus, status) {
+			return &warehouseTransitionError{From: fromStatus, To: status}
+		}
+
+		dbStart = time.Now()
+		warehouse, err = qtx.UpdateWarehouseStatus(spanCtx, models.UpdateWarehouseStatusParams{
+			ID:     id,
+			OrgID:  orgID,
+			Status: status,
+		})
+		h.recordDBOperation(spanCtx, "update", "warehouse", dbStart, err)
+		if err != nil {
+			return err
+		}
+		if err := h.recordAudit(spanCtx, qtx, auditEntry{
+			OrgID:      orgID,
+			EntityType: auditEntityWarehouse,
+			EntityID:   id,
+			Action:     operation,
+			FromStatus: fromStatus,
+			ToStatus:   status,
+			Actor:      actorID(ctx),
+		}); err != nil {
+			return err
+		}
+		return h.enqueueWarehouseEvent(spanCtx, qtx, outbox.TopicWarehouseStatusChanged, warehouse)
+	})
+
+	var transition *warehouseTransitionError
+	if errors.As(err, &transition) {
+		h.recordOperation(orgID, observability.EntityWarehouse, operation, opStart, err)
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": transition.Error(),
+		})
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		h.recordOperation(orgID, observability.EntityWarehouse, operation, opStart, pgx.ErrNoRows)
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Warehouse not found"),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Could not change warehouse status: ", slog.String("operation", operation), slog.Any("err", err.Error()))
+		tracing.Failed(span, err)
+		h.recordOperation(orgID, observability.EntityWarehouse, operation, opStart, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to change warehouse status"),
+		})
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntityWarehouse, operation, opStart, nil)
+
+	tracing.Transition(span, observability.EntityWarehouse, id, fromStatus, warehouse.Status)
+	tracing.Result(span, observability.StatusSuccess)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Update Warehouse Status Successfully"),
+		"data":    newWarehouseResponse(warehouse),
+	})
+}
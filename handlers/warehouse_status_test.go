@@ -0,0 +1,43 @@
+package handlers
+
+import "testing"
+
+func TestWarehouseTransitionAllowed(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{warehouseStatusDraft, warehouseStatusActive, true},
+		{warehouseStatusDraft, warehouseStatusArchived, true},
+		{warehouseStatusDraft, warehouseStatusMaintenance, false},
+		{warehouseStatusActive, warehouseStatusMaintenance, true},
+		{warehouseStatusActive, warehouseStatusArchived, true},
+		{warehouseStatusActive, warehouseStatusActive, false},
+		{warehouseStatusActive, warehouseStatusDraft, false},
+		{warehouseStatusMaintenance, warehouseStatusActive, true},
+		{warehouseStatusMaintenance, warehouseStatusArchived, true},
+		{warehouseStatusArchived, warehouseStatusActive, true},
+		{warehouseStatusArchived, warehouseStatusMaintenance, false},
+		{warehouseStatusArchived, warehouseStatusArchived, false},
+		{"closed", warehouseStatusActive, false},
+	}
+	for _, tt := range tests {
+		if got := warehouseTransitionAllowed(tt.from, tt.to); got != tt.want {
+			t.Errorf("warehouseTransitionAllowed(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestInitialWarehouseStatus(t *testing.T) {
+	if status, err := initialWarehouseStatus(""); err != nil || status.Valid {
+		t.Errorf("empty status = %+v, %v, want NULL", status, err)
+	}
+	if status, err := initialWarehouseStatus(warehouseStatusDraft); err != nil || status.String != warehouseStatusDraft {
+		t.Errorf("draft status = %+v, %v", status, err)
+	}
+	for _, status := range []string{warehouseStatusMaintenance, warehouseStatusArchived, "closed"} {
+		if _, err := initialWarehouseStatus(status); err == nil {
+			t.Errorf("initialWarehouseStatus(%q) succeeded", status)
+		}
+	}
+}
@@ -0,0 +1,458 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	"warehouse-service/address"
+	"warehouse-service/authz"
+	"warehouse-service/listquery"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+	"warehouse-service/outbox"
+	"warehouse-service/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type warehouseRequest struct {
+	Name      string   `json:"name" binding:"required"`
+	Address   string   `json:"address" binding:"required"`
+	Ward      string   `json:"ward"`
+	District  string   `json:"district"`
+	City      string   `json:"city"`
+	Country   string   `json:"country"`
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+	warehouseMetadata
+	Attributes json.RawMessage `json:"attributes"`
+	// Status is the initial state on create, PUT keeps the current state
+	Status string `json:"status"`
+}
+
+// rawAddress is the address as the request sent it
+func (r warehouseRequest) rawAddress() address.Address {
+	return address.Address{
+		Street:   r.Address,
+		Ward:     r.Ward,
+		District: r.District,
+		City:     r.City,
+		Country:  r.Country,
+	}
+}
+
+// attributes returns the attributes a PUT stores, omitted attributes are
+// reset to an empty object
+func (r warehouseRequest) attributes() ([]byte, error) {
+	if r.Attributes == nil {
+		return []byte("{}"), nil
+	}
+	return decodeAttributes(r.Attributes)
+}
+
+func parseWarehouseIDV2(ctx *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		respondV2Error(ctx, http.StatusBadRequest, errCodeInvalidRequest, "Invalid warehouse ID format")
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *Handlers) GetWarehouseV2(ctx *gin.Context) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "GetWarehouseV2")
+	defer span.End()
+
+	id, ok := parseWarehouseIDV2(ctx)
+	if !ok {
+		return
+	}
+	fields, ok := bindFieldsV2[WarehouseV2](ctx)
+	if !ok {
+		return
+	}
+	orgID := tenantID(ctx)
+	traceOperation(ctx, span, observability.EntityWarehouse, id)
+
+	dbStart := time.Now()
+	warehouse, err := h.readQueries(spanCtx).GetWarehouse(spanCtx, models.GetWarehouseParams{
+		ID:    id,
+		OrgID: orgID,
+	})
+	h.recordDBOperation(spanCtx, "get", "warehouse", dbStart, err)
+	if err == nil {
+		err = h.authorize(ctx, authz.Read, observability.EntityWarehouse, warehouse.OrgID)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		h.recordOperation(orgID, observability.EntityWarehouse, "get", opStart, pgx.ErrNoRows)
+		respondV2Error(ctx, http.StatusNotFound, errCodeNotFound, "Warehouse not found")
+		return
+	}
+	if err != nil {
+		slog.Error("Got an error while getting warehouse: ", slog.Any("err", err.Error()))
+		tracing.Failed(span, err)
+		h.recordOperation(orgID, observability.EntityWarehouse, "get", opStart, err)
+		respondV2Error(ctx, dbErrorStatus(err), dbErrorCode(err), "Failed to get warehouse")
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntityWarehouse, "get", opStart, nil)
+
+	tracing.Result(span, observability.StatusSuccess)
+	h.setVersionTag(ctx, spanCtx, h.readQueries(spanCtx), observability.EntityWarehouse, id, orgID)
+	respondV2(ctx, http.StatusOK, fields.one(newWarehouseV2(warehouse)), nil)
+}
+
+func (h *Handlers) ListWarehousesV2(ctx *gin.Context) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "ListWarehousesV2")
+	defer span.End()
+
+	limit, offset, ok := parsePage(ctx)
+	if !ok {
+		return
+	}
+	fields, ok := bindFieldsV2[WarehouseV2](ctx)
+	if !ok {
+		return
+	}
+	orgID := tenantID(ctx)
+	tracing.Actor(span, orgID, actorID(ctx))
+	span.SetAttributes(
+		attribute.Int("warehouse.limit", int(limit)),
+		attribute.Int("warehouse.offset", int(offset)),
+	)
+
+	q, err := warehouseListSpec.Parse(ctx.Request.URL.Query())
+	if err == nil {
+		q.Where("org_id", listquery.Eq, orgID).Page(limit, offset)
+		err = listFilters(ctx, q)
+	}
+	if err != nil {
+		respondV2Error(ctx, http.StatusBadRequest, errCodeInvalidRequest, err.Error())
+		return
+	}
+
+	dbStart := time.Now()
+	warehouses, err := queryPage[models.Warehouse](ctx, spanCtx, h.router.Read(spanCtx), q)
+	h.recordDBOperation(spanCtx, "list", "warehouse", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while listing warehouses: ", slog.Any("err", err.Error()))
+		tracing.Failed(span, err)
+		h.recordOperation(orgID, observability.EntityWarehouse, "list", opStart, err)
+		respondV2Error(ctx, dbErrorStatus(err), dbErrorCode(err), "Failed to list warehouses")
+		return
+	}
+
+	warehouses = authorizedRows(h, ctx, observability.EntityWarehouse, warehouses, warehouseOrg)
+	h.recordOperation(orgID, observability.EntityWarehouse, "list", opStart, nil)
+
+	span.SetAttributes(attribute.Int("warehouse.count", len(warehouses)))
+	tracing.Result(span, observability.StatusSuccess)
+	respondV2(ctx, http.StatusOK, selectFields(fields, newWarehousesV2(warehouses)), &pageMeta{
+		Limit:  limit,
+		Offset: offset,
+		Count:  len(warehouses),
+	})
+}
+
+func (h *Handlers) CreateWarehouseV2(ctx *gin.Context) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "CreateWarehouseV2")
+	defer span.End()
+
+	var req warehouseRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondV2BindError(ctx, err)
+		return
+	}
+	if err := validCoordinates(req.Latitude, req.Longitude); err != nil {
+		respondV2Error(ctx, http.StatusBadRequest, errCodeInvalidRequest, err.Error())
+		return
+	}
+	if err := req.warehouseMetadata.validate(); err != nil {
+		respondV2Error(ctx, http.StatusBadRequest, errCodeInvalidRequest, err.Error())
+		return
+	}
+	attrs, err := req.attributes()
+	if err != nil {
+		respondV2Error(ctx, http.StatusBadRequest, errCodeInvalidRequest, err.Error())
+		return
+	}
+	status, err := initialWarehouseStatus(req.Status)
+	if err != nil {
+		respondV2Error(ctx, http.StatusBadRequest, errCodeInvalidRequest, err.Error())
+		return
+	}
+	force, err := forceParam(ctx)
+	if err != nil {
+		respondV2Error(ctx, http.StatusBadRequest, errCodeInvalidRequest, err.Error())
+		return
+	}
+	// PUT replaces the warehouse, omitted metadata is reset to its default
+	md := req.full(defaultMetadata())
+	raw := req.rawAddress()
+	addr, validated, err := h.normalizeAddress(spanCtx, raw)
+	if err != nil {
+		respondAddressErrorV2(ctx, err)
+		return
+	}
+	lat, lng := h.coordinates(spanCtx, req.Latitude, req.Longitude, addr.Street, addr.Ward, addr.District, addr.City, addr.Country)
+	orgID := tenantID(ctx)
+	tracing.Actor(span, orgID, actorID(ctx))
+	span.SetAttributes(attribute.String("warehouse.name", req.Name))
+	if err := h.checkAttributes(spanCtx, orgID, observability.EntityWarehouse, attrs); err != nil {
+		span.RecordError(err)
+		respondAttributesErrorV2(ctx, err)
+		return
+	}
+
+	var warehouse models.Warehouse
+	err = pgx.BeginFunc(spanCtx, h.db, func(tx pgx.Tx) error {
+		qtx := h.queries.WithTx(tx)
+		if !force {
+			if err := h.checkDuplicateWarehouse(spanCtx, qtx, orgID, req.Name, addr.Street, addr.Ward, addr.District, addr.City, addr.Country); err != nil {
+				return err
+			}
+		}
+		slug, err := h.warehouseSlug(spanCtx, qtx, orgID, req.Name)
+		if err != nil {
+			return err
+		}
+		dbStart := time.Now()
+		warehouse, err = qtx.CreateWarehouse(spanCtx, models.CreateWarehouseParams{
+			Name:           req.Name,
+			Address:        addr.Street,
+			Ward:           addr.Ward,
+			District:       addr.District,
+			City:           addr.City,
+			Country:        addr.Country,
+			OrgID:          orgID,
+			Latitude:       lat,
+			Longitude:      lng,
+			TimeZone:       md.TimeZone,
+			OperatingHours: md.OperatingHours,
+			ContactEmail:   md.ContactEmail,
+			ContactPhone:   md.ContactPhone,
+			Tags:           md.Tags,
+			Attributes:     attrs,
+			Status:         status,
+			Slug:           slug,
+		})
+		h.recordDBOperation(spanCtx, "create", "warehouse", dbStart, err)
+		if err != nil {
+			return err
+		}
+		if err := h.enforceWarehouseQuota(spanCtx, qtx, orgID); err != nil {
+			return err
+		}
+		if err := h.saveRawAddress(spanCtx, qtx, rawAddressParams(warehouse.ID, orgID, raw, validated)); err != nil {
+			return err
+		}
+		return h.enqueueWarehouseEvent(spanCtx, qtx, outbox.TopicWarehouseCreated, warehouse)
+	})
+	if exceeded, ok := quotaExceeded(err); ok {
+		h.recordOperation(orgID, observability.EntityWarehouse, "create", opStart, err)
+		respondQuotaExceededV2(ctx, exceeded)
+		return
+	}
+	var duplicate *duplicateWarehouseError
+	if errors.As(err, &duplicate) {
+		tracing.Result(span, "duplicate")
+		h.recordOperation(orgID, observability.EntityWarehouse, "create", opStart, err)
+		respondDuplicateWarehouseV2(ctx, duplicate)
+		return
+	}
+	if conflict, ok := conflictFor(err); ok {
+		h.recordOperation(orgID, observability.EntityWarehouse, "create", opStart, err)
+		ctx.JSON(http.StatusConflict, envelope{Errors: []apiError{{Code: errCodeConflict, Message: tr(ctx, conflict.message), Field: conflict.field}}})
+		return
+	}
+	if err != nil {
+		slog.Error("Could not create warehouse: ", slog.Any("err", err.Error()))
+		tracing.Failed(span, err)
+		h.recordOperation(orgID, observability.EntityWarehouse, "create", opStart, err)
+		respondV2Error(ctx, dbErrorStatus(err), dbErrorCode(err), "Failed to create warehouse")
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntityWarehouse, "create", opStart, nil)
+	h.refreshWarehouseGauge(spanCtx, orgID)
+
+	tracing.Entity(span, observability.EntityWarehouse, warehouse.ID)
+	tracing.Transition(span, observability.EntityWarehouse, warehouse.ID, "", warehouse.Status)
+	tracing.Result(span, observability.StatusSuccess)
+	respondV2(ctx, http.StatusCreated, newWarehouseV2(warehouse), nil)
+}
+
+func (h *Handlers) UpdateWarehouseV2(ctx *gin.Context) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "UpdateWarehouseV2")
+	defer span.End()
+
+	id, ok := parseWarehouseIDV2(ctx)
+	if !ok {
+		return
+	}
+	var req warehouseRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondV2BindError(ctx, err)
+		return
+	}
+	if err := validCoordinates(req.Latitude, req.Longitude); err != nil {
+		respondV2Error(ctx, http.StatusBadRequest, errCodeInvalidRequest, err.Error())
+		return
+	}
+	if err := req.warehouseMetadata.validate(); err != nil {
+		respondV2Error(ctx, http.StatusBadRequest, errCodeInvalidRequest, err.Error())
+		return
+	}
+	attrs, err := req.attributes()
+	if err != nil {
+		respondV2Error(ctx, http.StatusBadRequest, errCodeInvalidRequest, err.Error())
+		return
+	}
+	// PUT replaces the warehouse, omitted metadata is reset to its default
+	md := req.full(defaultMetadata())
+	raw := req.rawAddress()
+	addr, validated, err := h.normalizeAddress(spanCtx, raw)
+	if err != nil {
+		respondAddressErrorV2(ctx, err)
+		return
+	}
+	lat, lng := h.coordinates(spanCtx, req.Latitude, req.Longitude, addr.Street, addr.Ward, addr.District, addr.City, addr.Country)
+	orgID := tenantID(ctx)
+	traceOperation(ctx, span, observability.EntityWarehouse, id)
+	if err := h.checkAttributes(spanCtx, orgID, observability.EntityWarehouse, attrs); err != nil {
+		span.RecordError(err)
+		respondAttributesErrorV2(ctx, err)
+		return
+	}
+
+	var warehouse models.Warehouse
+	err = pgx.BeginFunc(spanCtx, h.db, func(tx pgx.Tx) error {
+		qtx := h.queries.WithTx(tx)
+		dbStart := time.Now()
+		warehouse, err = qtx.UpdateWarehouse(spanCtx, models.UpdateWarehouseParams{
+			ID:             id,
+			Name:           req.Name,
+			Address:        addr.Street,
+			Ward:           addr.Ward,
+			District:       addr.District,
+			City:           addr.City,
+			Country:        addr.Country,
+			OrgID:          orgID,
+			Latitude:       lat,
+			Longitude:      lng,
+			TimeZone:       md.TimeZone,
+			OperatingHours: md.OperatingHours,
+			ContactEmail:   md.ContactEmail,
+			ContactPhone:   md.ContactPhone,
+			Tags:           md.Tags,
+			Attributes:     attrs,
+		})
+		h.recordDBOperation(spanCtx, "update", "warehouse", dbStart, err)
+		if err != nil {
+			return err
+		}
+		if err := h.authorize(ctx, authz.Write, observability.EntityWarehouse, warehouse.OrgID); err != nil {
+			return err
+		}
+		if err := h.saveRawAddress(spanCtx, qtx, rawAddressParams(id, orgID, raw, validated)); err != nil {
+			return err
+		}
+		return h.enqueueWarehouseEvent(spanCtx, qtx, outbox.TopicWarehouseUpdated, warehouse)
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		h.recordOperation(orgID, observability.EntityWarehouse, "update", opStart, pgx.ErrNoRows)
+		respondV2Error(ctx, http.StatusNotFound, errCodeNotFound, "Warehouse not found")
+		return
+	}
+	if conflict, ok := conflictFor(err); ok {
+		h.recordOperation(orgID, observability.EntityWarehouse, "update", opStart, err)
+		ctx.JSON(http.StatusConflict, envelope{Errors: []apiError{{Code: errCodeConflict, Message: tr(ctx, conflict.message), Field: conflict.field}}})
+		return
+	}
+	if err != nil {
+		slog.Error("Could not update warehouse: ", slog.Any("err", err.Error()))
+		tracing.Failed(span, err)
+		h.recordOperation(orgID, observability.EntityWarehouse, "update", opStart, err)
+		respondV2Error(ctx, dbErrorStatus(err), dbErrorCode(err), "Failed to update warehouse")
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntityWarehouse, "update", opStart, nil)
+
+	tracing.Result(span, observability.StatusSuccess)
+	respondV2(ctx, http.StatusOK, newWarehouseV2(warehouse), nil)
+}
+
+func (h *Handlers) DeleteWarehouseV2(ctx *gin.Context) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "DeleteWarehouseV2")
+	defer span.End()
+
+	id, ok := parseWarehouseIDV2(ctx)
+	if !ok {
+		return
+	}
+	orgID := tenantID(ctx)
+	traceOperation(ctx, span, observability.EntityWarehouse, id)
+
+	cascade, err := cascadeParam(ctx)
+	if err != nil {
+		respondV2Error(ctx, http.StatusBadRequest, errCodeInvalidRequest, err.Error())
+		return
+	}
+	span.SetAttributes(attribute.Bool("warehouse.cascade", cascade))
+
+	err = h.deleteWarehouse(spanCtx, orgID, id, cascade, parseIfMatch(ctx))
+	var inUse *warehouseInUseError
+	if errors.As(err, &inUse) {
+		h.recordOperation(orgID, observability.EntityWarehouse, "delete", opStart, err)
+		respondWarehouseInUseV2(ctx, inUse)
+		return
+	}
+	var roomInUse *storageRoomInUseError
+	if errors.As(err, &roomInUse) {
+		tracing.Result(span, "in_use")
+		h.recordOperation(orgID, observability.EntityWarehouse, "delete", opStart, err)
+		respondStorageRoomInUseV2(ctx, roomInUse)
+		return
+	}
+	if errors.Is(err, errVersionMismatch) {
+		tracing.Result(span, "version_mismatch")
+		h.recordOperation(orgID, observability.EntityWarehouse, "delete", opStart, err)
+		respondV2Error(ctx, http.StatusPreconditionFailed, errCodePrecondition, "Warehouse changed since the version in If-Match")
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		h.recordOperation(orgID, observability.EntityWarehouse, "delete", opStart, pgx.ErrNoRows)
+		respondV2Error(ctx, http.StatusNotFound, errCodeNotFound, "Warehouse not found")
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to delete warehouse: ", slog.Any("err", err.Error()))
+		tracing.Failed(span, err)
+		h.recordOperation(orgID, observability.EntityWarehouse, "delete", opStart, err)
+		respondV2Error(ctx, dbErrorStatus(err), dbErrorCode(err), "Failed to delete warehouse")
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntityWarehouse, "delete", opStart, nil)
+	h.refreshWarehouseGauge(spanCtx, orgID)
+
+	tracing.Result(span, observability.StatusSuccess)
+	ctx.Status(http.StatusNoContent)
+}
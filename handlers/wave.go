@@ -0,0 +1,433 @@
+package handlers
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+	"warehouse-service/listquery"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// aisleSpacing is the walk from one aisle to the next along the front cross
+// aisle, in bays
+const aisleSpacing = 2
+
+// zoneOrder is the order zones are picked in. Colder goods are picked last
+// so they spend the least time out of their zone.
+var zoneOrder = []string{zoneAmbient, zoneChilled, zoneFrozen}
+
+type createWaveRequest struct {
+	WarehouseID WarehouseRef `json:"warehouse_id" binding:"required"`
+	Reference   string       `json:"reference"`
+	PickListIDs []int64      `json:"pick_list_ids" binding:"required,min=1,max=100,dive,gt=0"`
+}
+
+// pickLocation is a storage room on the pick path
+type pickLocation struct {
+	StorageRoomID int32
+	Number        string
+	ZoneType      string
+	Aisle         int32
+	Bay           int32
+}
+
+// walkDistance is how far a picker walks from a to b in the same zone.
+// Aisles are entered from the front cross aisle, so changing aisles means
+// walking back to the front first.
+func walkDistance(a, b pickLocation) int64 {
+	if a.Aisle == b.Aisle {
+		return abs64(int64(a.Bay) - int64(b.Bay))
+	}
+	return int64(a.Bay) + abs64(int64(a.Aisle)-int64(b.Aisle))*aisleSpacing + int64(b.Bay)
+}
+
+func abs64(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func zoneRank(zone string) int {
+	if i := slices.Index(zoneOrder, zone); i >= 0 {
+		return i
+	}
+	return len(zoneOrder)
+}
+
+// pickPath orders the locations of a wave into a walk: zone by zone in
+// zoneOrder, and within a zone always to the nearest location not yet
+// visited, starting at the zone entrance in front of the first aisle.
+// Locations with an unknown aisle or bay count as at the entrance.
+func pickPath(locations []pickLocation) []pickLocation {
+	remaining := slices.Clone(locations)
+	slices.SortFunc(remaining, func(a, b pickLocation) int {
+		return cmp.Or(
+			cmp.Compare(zoneRank(a.ZoneType), zoneRank(b.ZoneType)),
+			cmp.Compare(a.ZoneType, b.ZoneType),
+			cmp.Compare(a.Aisle, b.Aisle),
+			cmp.Compare(a.Bay, b.Bay),
+			cmp.Compare(a.StorageRoomID, b.StorageRoomID),
+		)
+	})
+
+	path := make([]pickLocation, 0, len(remaining))
+	for len(remaining) > 0 {
+		zone := remaining[0].ZoneType
+		current := pickLocation{ZoneType: zone}
+		for len(remaining) > 0 && remaining[0].ZoneType == zone {
+			// remaining is sorted, so the first of equally near locations wins
+			next := 0
+			for i := 1; i < len(remaining) && remaining[i].ZoneType == zone; i++ {
+				if walkDistance(current, remaining[i]) < walkDistance(current, remaining[next]) {
+					next = i
+				}
+			}
+			current = remaining[next]
+			path = append(path, current)
+			remaining = slices.Delete(remaining, next, next+1)
+		}
+	}
+	return path
+}
+
+// wavePath groups the open picks of a wave by storage room, in the order
+// of the pick path
+func wavePath(picks []models.ListWavePicksRow) []WaveStopResponse {
+	byRoom := map[int32][]WavePickResponse{}
+	var locations []pickLocation
+	for _, p := range picks {
+		if _, ok := byRoom[p.StorageRoomID]; !ok {
+			locations = append(locations, pickLocation{
+				StorageRoomID: p.StorageRoomID,
+				Number:        p.Number,
+				ZoneType:      p.ZoneType,
+				Aisle:         p.Aisle,
+				Bay:           p.Bay,
+			})
+		}
+		byRoom[p.StorageRoomID] = append(byRoom[p.StorageRoomID], WavePickResponse{
+			PickListID: p.PickListID,
+			LineID:     p.ID,
+			Sku:        p.Sku,
+			Quantity:   p.Quantity - p.PickedQuantity,
+		})
+	}
+
+	stops := make([]WaveStopResponse, 0, len(locations))
+	for i, l := range pickPath(locations) {
+		stops = append(stops, WaveStopResponse{
+			Sequence:      i + 1,
+			StorageRoomID: l.StorageRoomID,
+			Number:        l.Number,
+			ZoneType:      l.ZoneType,
+			Aisle:         l.Aisle,
+			Bay:           l.Bay,
+			Picks:         byRoom[l.StorageRoomID],
+		})
+	}
+	return stops
+}
+
+func parseWaveID(ctx *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(ctx, "Invalid wave ID format"),
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// waveDetail loads the pick lists and the pick path of a wave
+func (h *Handlers) waveDetail(ctx context.Context, q *models.Queries, wave models.Wave) (gin.H, error) {
+	dbStart := time.Now()
+	pickLists, err := q.ListWavePickLists(ctx, wave.ID)
+	h.recordDBOperation(ctx, "list", "pick_list", dbStart, err)
+	if err != nil {
+		return nil, err
+	}
+	dbStart = time.Now()
+	picks, err := q.ListWavePicks(ctx, wave.ID)
+	h.recordDBOperation(ctx, "list", "pick_list_line", dbStart, err)
+	if err != nil {
+		return nil, err
+	}
+	return gin.H{
+		"wave":       newWaveResponse(wave),
+		"pick_lists": mapSlice(pickLists, newPickListResponse),
+		"path":       wavePath(picks),
+	}, nil
+}
+
+// CreateWave groups allocated pick lists of a warehouse into a wave and
+// returns the order to walk their picks in
+func (h *Handlers) CreateWave(ctx *gin.Context) {
+	opStart := time.Now()
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "CreateWave")
+	defer span.End()
+
+	var req createWaveRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   tr(ctx, "Invalid wave payload"),
+			"details": err.Error(),
+		})
+		return
+	}
+	slices.Sort(req.PickListIDs)
+	req.PickListIDs = slices.Compact(req.PickListIDs)
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.String("warehouse.ref", string(req.WarehouseID)),
+		attribute.Int("wave.pick_lists", len(req.PickListIDs)),
+		attribute.String("tenant.id", orgID),
+	)
+
+	tx, err := h.db.Begin(spanCtx)
+	if err != nil {
+		slog.Error("Failed to start transaction", slog.Any("err", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": tr(ctx, "Failed to start transaction"),
+		})
+		return
+	}
+	defer tx.Rollback(spanCtx) // This will be ignored if tx.Commit() succeeds
+
+	qtx := h.queries.WithTx(tx)
+
+	warehouseID, err := h.resolveWarehouse(spanCtx, qtx, orgID, req.WarehouseID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Warehouse not found"),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Got an error while getting warehouse: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to create wave"),
+		})
+		return
+	}
+	span.SetAttributes(attribute.Int64("warehouse.id", warehouseID))
+
+	// Lock the pick lists so they can't ship or be cancelled while joining
+	dbStart := time.Now()
+	pickLists, err := qtx.LockPickLists(spanCtx, models.LockPickListsParams{
+		OrgID: orgID,
+		Ids:   req.PickListIDs,
+	})
+	h.recordDBOperation(spanCtx, "get", "pick_list", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while getting pick lists: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to create wave"),
+		})
+		return
+	}
+	if len(pickLists) != len(req.PickListIDs) {
+		var missing []int64
+		for _, id := range req.PickListIDs {
+			if !slices.ContainsFunc(pickLists, func(p models.PickList) bool { return p.ID == id }) {
+				missing = append(missing, id)
+			}
+		}
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error":         tr(ctx, "Pick lists not found"),
+			"pick_list_ids": missing,
+		})
+		return
+	}
+	for _, p := range pickLists {
+		if p.WarehouseID != warehouseID {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": tr(ctx, "Pick list %d is for another warehouse", p.ID),
+			})
+			return
+		}
+		if p.Status != pickListStatusAllocated {
+			ctx.JSON(http.StatusConflict, gin.H{
+				"error": tr(ctx, "Pick list %d is %s", p.ID, p.Status),
+			})
+			return
+		}
+	}
+
+	dbStart = time.Now()
+	wave, err := qtx.CreateWave(spanCtx, models.CreateWaveParams{
+		OrgID:       orgID,
+		WarehouseID: warehouseID,
+		Reference:   req.Reference,
+	})
+	h.recordDBOperation(spanCtx, "create", "wave", dbStart, err)
+	if err == nil {
+		dbStart = time.Now()
+		err = qtx.AddPickListsToWave(spanCtx, models.AddPickListsToWaveParams{
+			PickListIds: req.PickListIDs,
+			WaveID:      wave.ID,
+		})
+		h.recordDBOperation(spanCtx, "create", "wave_pick_list", dbStart, err)
+	}
+	if conflict, ok := conflictFor(err); ok {
+		h.recordOperation(orgID, observability.EntityWave, "create", opStart, err)
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": tr(ctx, conflict.message),
+			"field": conflict.field,
+		})
+		return
+	}
+	var detail gin.H
+	if err == nil {
+		detail, err = h.waveDetail(spanCtx, qtx, wave)
+	}
+	if err != nil {
+		slog.Error("Could not create wave: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		h.recordOperation(orgID, observability.EntityWave, "create", opStart, err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to create wave"),
+		})
+		return
+	}
+
+	if err := tx.Commit(spanCtx); err != nil {
+		slog.Error("Failed to commit transaction", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to commit transaction"),
+		})
+		return
+	}
+
+	h.recordOperation(orgID, observability.EntityWave, "create", opStart, nil)
+
+	span.SetAttributes(
+		attribute.Int64("wave.id", wave.ID),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusCreated, gin.H{
+		"message": tr(ctx, "Create Wave Successfully"),
+		"data":    detail,
+	})
+}
+
+// GetWave returns a wave with the picks still open, in pick path order
+func (h *Handlers) GetWave(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "GetWave")
+	defer span.End()
+
+	id, ok := parseWaveID(ctx)
+	if !ok {
+		return
+	}
+	orgID := tenantID(ctx)
+	span.SetAttributes(
+		attribute.Int64("wave.id", id),
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	wave, err := h.queries.GetWave(spanCtx, models.GetWaveParams{
+		ID:    id,
+		OrgID: orgID,
+	})
+	h.recordDBOperation(spanCtx, "get", "wave", dbStart, err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": tr(ctx, "Wave not found"),
+		})
+		return
+	}
+	var detail gin.H
+	if err == nil {
+		detail, err = h.waveDetail(spanCtx, h.queries, wave)
+	}
+	if err != nil {
+		slog.Error("Got an error while getting wave: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to get wave"),
+		})
+		return
+	}
+
+	span.SetAttributes(attribute.String("operation.status", "success"))
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "Get Wave Successfully"),
+		"data":    detail,
+	})
+}
+
+// waveListSpec sorts and filters the waves of ListWaves
+var waveListSpec = &listquery.Spec{
+	Table: "wave",
+	Sort: map[string]string{
+		"id":           "id",
+		"reference":    "reference",
+		"warehouse_id": "warehouse_id",
+		"created_at":   "created_at",
+	},
+	Filters: map[string]listquery.Filter{
+		"warehouse_id":   {Column: "warehouse_id", Op: listquery.Eq, Parse: listquery.Int},
+		"created_after":  {Column: "created_at", Op: listquery.Gte, Parse: listquery.Time},
+		"created_before": {Column: "created_at", Op: listquery.Lt, Parse: listquery.Time},
+	},
+	Default: []listquery.Order{{Field: "id", Desc: true}},
+	Key:     "id",
+}
+
+func (h *Handlers) ListWaves(ctx *gin.Context) {
+	// Start a new span for this operation
+	spanCtx, span := h.tracer.Start(ctx.Request.Context(), "ListWaves")
+	defer span.End()
+
+	q, err := listParams(ctx, waveListSpec)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	orgID := tenantID(ctx)
+	q.Where("org_id", listquery.Eq, orgID)
+	span.SetAttributes(
+		attribute.String("tenant.id", orgID),
+	)
+
+	dbStart := time.Now()
+	waves, err := queryPage[models.Wave](ctx, spanCtx, h.db, q)
+	h.recordDBOperation(spanCtx, "list", "wave", dbStart, err)
+	if err != nil {
+		slog.Error("Got an error while listing waves: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+		ctx.JSON(dbErrorStatus(err), gin.H{
+			"error": tr(ctx, "Failed to list waves"),
+		})
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int("wave.count", len(waves)),
+		attribute.String("operation.status", "success"),
+	)
+	respondV1(ctx, http.StatusOK, gin.H{
+		"message": tr(ctx, "List Waves Successfully"),
+		"data":    mapSlice(waves, newWaveResponse),
+	})
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"testing"
+	models "warehouse-service/models/sqlc"
+)
+
+func TestWalkDistance(t *testing.T) {
+	tests := []struct {
+		a, b pickLocation
+		want int64
+	}{
+		{pickLocation{Aisle: 1, Bay: 2}, pickLocation{Aisle: 1, Bay: 9}, 7},
+		{pickLocation{Aisle: 1, Bay: 9}, pickLocation{Aisle: 1, Bay: 2}, 7},
+		{pickLocation{Aisle: 1, Bay: 3}, pickLocation{Aisle: 3, Bay: 4}, 3 + 2*aisleSpacing + 4},
+		{pickLocation{}, pickLocation{Aisle: 2, Bay: 1}, 2*aisleSpacing + 1},
+	}
+	for _, tt := range tests {
+		if got := walkDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("walkDistance(%+v, %+v) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestPickPath(t *testing.T) {
+	path := pickPath([]pickLocation{
+		{StorageRoomID: 1, ZoneType: zoneFrozen, Aisle: 1, Bay: 1},
+		{StorageRoomID: 2, ZoneType: zoneAmbient, Aisle: 3, Bay: 1},
+		{StorageRoomID: 3, ZoneType: zoneAmbient, Aisle: 1, Bay: 8},
+		{StorageRoomID: 4, ZoneType: zoneAmbient, Aisle: 1, Bay: 2},
+		{StorageRoomID: 5, ZoneType: zoneChilled, Aisle: 2, Bay: 5},
+		{StorageRoomID: 6, ZoneType: zoneAmbient, Aisle: 2, Bay: 1},
+	})
+	// Ambient from the entrance: aisle 1 bay 2, then aisle 2 bay 1 (2+2+1)
+	// is nearer than aisle 1 bay 8 (6), then aisle 3 bay 1 (1+2+1) before
+	// going back to aisle 1 bay 8
+	want := []int32{4, 6, 2, 3, 5, 1}
+	if len(path) != len(want) {
+		t.Fatalf("path = %+v", path)
+	}
+	for i, id := range want {
+		if path[i].StorageRoomID != id {
+			t.Errorf("path[%d] = room %d, want %d", i, path[i].StorageRoomID, id)
+		}
+	}
+}
+
+func TestWavePath(t *testing.T) {
+	stops := wavePath([]models.ListWavePicksRow{
+		{ID: 10, PickListID: 1, Sku: "A", StorageRoomID: 7, Quantity: 5, PickedQuantity: 2, ZoneType: zoneAmbient, Aisle: 2, Bay: 1},
+		{ID: 11, PickListID: 1, Sku: "B", StorageRoomID: 8, Quantity: 1, ZoneType: zoneAmbient, Aisle: 1, Bay: 1},
+		{ID: 20, PickListID: 2, Sku: "A", StorageRoomID: 7, Quantity: 4, ZoneType: zoneAmbient, Aisle: 2, Bay: 1},
+	})
+	if len(stops) != 2 || stops[0].StorageRoomID != 8 || stops[1].Sequence != 2 {
+		t.Fatalf("stops = %+v", stops)
+	}
+	if picks := stops[1].Picks; len(picks) != 2 || picks[0].Quantity != 3 || picks[1].PickListID != 2 {
+		t.Errorf("picks at room 7 = %+v", picks)
+	}
+	if stops := wavePath(nil); stops == nil || len(stops) != 0 {
+		t.Errorf("empty wave path = %#v, want an empty slice", stops)
+	}
+}
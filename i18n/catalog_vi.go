@@ -0,0 +1,459 @@
+package i18n
+
+// vi is the Vietnamese catalog
+var vi = map[string]string{
+	"A client certificate is required":                                "Cần có chứng chỉ máy khách",
+	"A deletion of the tenant is already pending":                     "Đã có một yêu cầu xóa tổ chức đang chờ xử lý",
+	"A file exchange with this name already exists":                   "Đã có kênh trao đổi tệp với tên này",
+	"A partner with this EDI ID already exists":                       "Đã có đối tác với mã EDI này",
+	"A partner with this name already exists":                         "Đã có đối tác với tên này",
+	"A pick list is already in a wave":                                "Phiếu lấy hàng đã thuộc một đợt lấy hàng",
+	"A printer with this name already exists":                         "Đã có máy in với tên này",
+	"A resource with the same unique values already exists":           "Đã có tài nguyên với cùng các giá trị duy nhất",
+	"A serial with this number already exists":                        "Đã có số sê-ri này",
+	"A storage room with this number already exists in the warehouse": "Kho đã có phòng kho với số này",
+	"A warehouse with this name already exists":                       "Đã có kho với tên này",
+	"A warehouse with this slug already exists":                       "Đã có kho với slug này",
+	"API key not found":                                               "Không tìm thấy khóa API",
+	"Aggregate Metering Successfully":                                 "Đã tổng hợp số liệu sử dụng thành công",
+	"An active organization is required":                              "Cần chọn một tổ chức đang hoạt động",
+	"An integration with this name already exists":                    "Đã có tích hợp với tên này",
+	"An item with this SKU already exists":                            "Đã có mặt hàng với mã SKU này",
+	"Attachment not found":                                            "Không tìm thấy tệp đính kèm",
+	"Attribute schema must be at most %d bytes":                       "Lược đồ thuộc tính tối đa %d byte",
+	"Attributes do not match the attribute schema":                    "Thuộc tính không khớp với lược đồ thuộc tính",
+	"Authorization header required":                                   "Cần có header Authorization",
+	"Batch Get Storage Rooms Successfully":                            "Đã lấy hàng loạt phòng kho thành công",
+	"Batch Get Warehouses Successfully":                               "Đã lấy hàng loạt kho thành công",
+	"Bearer token required":                                           "Cần có bearer token",
+	"Cancel Deletion Successfully":                                    "Đã hủy yêu cầu xóa thành công",
+	"Carrier not found":                                               "Không tìm thấy đơn vị vận chuyển",
+	"Close Receipt Successfully":                                      "Đã đóng phiếu nhập kho thành công",
+	"Consume Message Successfully":                                    "Đã xử lý thông điệp thành công",
+	"Could not read the request body":                                 "Không thể đọc nội dung yêu cầu",
+	"Count session is %s":                                             "Phiên kiểm kê đang ở trạng thái %s",
+	"Count session not found":                                         "Không tìm thấy phiên kiểm kê",
+	"Create API Key Successfully":                                     "Đã tạo khóa API thành công",
+	"Create Carrier Successfully":                                     "Đã tạo đơn vị vận chuyển thành công",
+	"Create File Exchange Successfully":                               "Đã tạo kênh trao đổi tệp thành công",
+	"Create Integration Successfully":                                 "Đã tạo tích hợp thành công",
+	"Create Item Successfully":                                        "Đã tạo mặt hàng thành công",
+	"Create Pick List Successfully":                                   "Đã tạo phiếu lấy hàng thành công",
+	"Create Printer Successfully":                                     "Đã tạo máy in thành công",
+	"Create Receipt Successfully":                                     "Đã tạo phiếu nhập kho thành công",
+	"Create Supplier Successfully":                                    "Đã tạo nhà cung cấp thành công",
+	"Create Transfer Order Successfully":                              "Đã tạo lệnh chuyển kho thành công",
+	"Create Warehouse Successfully":                                   "Đã tạo kho thành công",
+	"Create Wave Successfully":                                        "Đã tạo đợt lấy hàng thành công",
+	"Daily quota of %d API calls exceeded":                            "Đã vượt hạn mức %d lượt gọi API mỗi ngày",
+	"Dashboard stats are being refreshed already":                     "Số liệu bảng điều khiển đang được làm mới",
+	"Dead letter not found":                                           "Không tìm thấy thư lỗi",
+	"Dead letter was already replayed":                                "Thư lỗi đã được gửi lại",
+	"Delete Attachment Successfully":                                  "Đã xóa tệp đính kèm thành công",
+	"Delete Attribute Schema Successfully":                            "Đã xóa lược đồ thuộc tính thành công",
+	"Delete Carrier Successfully":                                     "Đã xóa đơn vị vận chuyển thành công",
+	"Delete File Exchange Successfully":                               "Đã xóa kênh trao đổi tệp thành công",
+	"Delete Integration Successfully":                                 "Đã xóa tích hợp thành công",
+	"Delete Item Successfully":                                        "Đã xóa mặt hàng thành công",
+	"Delete Printer Successfully":                                     "Đã xóa máy in thành công",
+	"Delete Storage Room Successfully":                                "Đã xóa phòng kho thành công",
+	"Delete Supplier Successfully":                                    "Đã xóa nhà cung cấp thành công",
+	"Delete Warehouse Successfully":                                   "Đã xóa kho thành công",
+	"Export Tenant Successfully":                                      "Đã xuất dữ liệu tổ chức thành công",
+	"Export not found":                                                "Không tìm thấy bản xuất",
+	"Failed to aggregate metering":                                    "Không thể tổng hợp số liệu sử dụng",
+	"Failed to cancel deletion":                                       "Không thể hủy yêu cầu xóa",
+	"Failed to change warehouse status":                               "Không thể thay đổi trạng thái kho",
+	"Failed to close receipt":                                         "Không thể đóng phiếu nhập kho",
+	"Failed to commit transaction":                                    "Không thể xác nhận giao dịch",
+	"Failed to consume message":                                       "Không thể xử lý thông điệp",
+	"Failed to count jobs":                                            "Không thể đếm các tác vụ",
+	"Failed to create API key":                                        "Không thể tạo khóa API",
+	"Failed to create carrier":                                        "Không thể tạo đơn vị vận chuyển",
+	"Failed to create download URL":                                   "Không thể tạo URL tải xuống",
+	"Failed to create file exchange":                                  "Không thể tạo kênh trao đổi tệp",
+	"Failed to create integration":                                    "Không thể tạo tích hợp",
+	"Failed to create item":                                           "Không thể tạo mặt hàng",
+	"Failed to create pick list":                                      "Không thể tạo phiếu lấy hàng",
+	"Failed to create printer":                                        "Không thể tạo máy in",
+	"Failed to create receipt":                                        "Không thể tạo phiếu nhập kho",
+	"Failed to create supplier":                                       "Không thể tạo nhà cung cấp",
+	"Failed to create warehouse":                                      "Không thể tạo kho",
+	"Failed to create wave":                                           "Không thể tạo đợt lấy hàng",
+	"Failed to delete attachment":                                     "Không thể xóa tệp đính kèm",
+	"Failed to delete attribute schema":                               "Không thể xóa lược đồ thuộc tính",
+	"Failed to delete carrier":                                        "Không thể xóa đơn vị vận chuyển",
+	"Failed to delete file exchange":                                  "Không thể xóa kênh trao đổi tệp",
+	"Failed to delete integration":                                    "Không thể xóa tích hợp",
+	"Failed to delete item":                                           "Không thể xóa mặt hàng",
+	"Failed to delete printer":                                        "Không thể xóa máy in",
+	"Failed to delete storage room":                                   "Không thể xóa phòng kho",
+	"Failed to delete supplier":                                       "Không thể xóa nhà cung cấp",
+	"Failed to delete warehouse":                                      "Không thể xóa kho",
+	"Failed to export inventory advice":                               "Không thể xuất báo cáo tồn kho",
+	"Failed to export tenant":                                         "Không thể xuất dữ liệu tổ chức",
+	"Failed to get attachment":                                        "Không thể lấy tệp đính kèm",
+	"Failed to get attribute schema":                                  "Không thể lấy lược đồ thuộc tính",
+	"Failed to get carrier":                                           "Không thể lấy đơn vị vận chuyển",
+	"Failed to get count session":                                     "Không thể lấy phiên kiểm kê",
+	"Failed to get dashboard stats":                                   "Không thể lấy số liệu bảng điều khiển",
+	"Failed to get dead letter":                                       "Không thể lấy thư lỗi",
+	"Failed to get deletion":                                          "Không thể lấy yêu cầu xóa",
+	"Failed to get export":                                            "Không thể lấy bản xuất",
+	"Failed to get file exchange":                                     "Không thể lấy kênh trao đổi tệp",
+	"Failed to get integration":                                       "Không thể lấy tích hợp",
+	"Failed to get inventory valuation":                               "Không thể lấy giá trị tồn kho",
+	"Failed to get item":                                              "Không thể lấy mặt hàng",
+	"Failed to get job":                                               "Không thể lấy tác vụ",
+	"Failed to get location":                                          "Không thể lấy vị trí",
+	"Failed to get metering":                                          "Không thể lấy số liệu sử dụng",
+	"Failed to get movement history":                                  "Không thể lấy lịch sử biến động",
+	"Failed to get pick list":                                         "Không thể lấy phiếu lấy hàng",
+	"Failed to get printer":                                           "Không thể lấy máy in",
+	"Failed to get receipt":                                           "Không thể lấy phiếu nhập kho",
+	"Failed to get reorder suggestion":                                "Không thể lấy đề xuất đặt hàng lại",
+	"Failed to get saga":                                              "Không thể lấy saga",
+	"Failed to get serial":                                            "Không thể lấy số sê-ri",
+	"Failed to get settings":                                          "Không thể lấy cài đặt",
+	"Failed to get stock by warehouse":                                "Không thể lấy tồn kho theo kho",
+	"Failed to get storage room":                                      "Không thể lấy phòng kho",
+	"Failed to get storage room history":                              "Không thể lấy lịch sử phòng kho",
+	"Failed to get storage rooms":                                     "Không thể lấy các phòng kho",
+	"Failed to get supplier":                                          "Không thể lấy nhà cung cấp",
+	"Failed to get transfer order":                                    "Không thể lấy lệnh chuyển kho",
+	"Failed to get usage":                                             "Không thể lấy mức sử dụng",
+	"Failed to get warehouse":                                         "Không thể lấy kho",
+	"Failed to get warehouse address":                                 "Không thể lấy địa chỉ kho",
+	"Failed to get warehouse history":                                 "Không thể lấy lịch sử kho",
+	"Failed to get warehouse summary":                                 "Không thể lấy tổng quan kho",
+	"Failed to get warehouses":                                        "Không thể lấy các kho",
+	"Failed to get wave":                                              "Không thể lấy đợt lấy hàng",
+	"Failed to import receipts":                                       "Không thể nhập các phiếu nhập kho",
+	"Failed to list API keys":                                         "Không thể lấy danh sách khóa API",
+	"Failed to list EDI documents":                                    "Không thể lấy danh sách chứng từ EDI",
+	"Failed to list attachments":                                      "Không thể lấy danh sách tệp đính kèm",
+	"Failed to list attribute schemas":                                "Không thể lấy danh sách lược đồ thuộc tính",
+	"Failed to list audit logs":                                       "Không thể lấy danh sách nhật ký kiểm tra",
+	"Failed to list carriers":                                         "Không thể lấy danh sách đơn vị vận chuyển",
+	"Failed to list count sessions":                                   "Không thể lấy danh sách phiên kiểm kê",
+	"Failed to list dead letters":                                     "Không thể lấy danh sách thư lỗi",
+	"Failed to list exports":                                          "Không thể lấy danh sách bản xuất",
+	"Failed to list file exchange files":                              "Không thể lấy danh sách tệp của kênh trao đổi",
+	"Failed to list file exchange runs":                               "Không thể lấy danh sách lần chạy của kênh trao đổi",
+	"Failed to list file exchanges":                                   "Không thể lấy danh sách kênh trao đổi tệp",
+	"Failed to list in-transit stock":                                 "Không thể lấy danh sách hàng đang vận chuyển",
+	"Failed to list integration orders":                               "Không thể lấy danh sách đơn hàng tích hợp",
+	"Failed to list integrations":                                     "Không thể lấy danh sách tích hợp",
+	"Failed to list items":                                            "Không thể lấy danh sách mặt hàng",
+	"Failed to list jobs":                                             "Không thể lấy danh sách tác vụ",
+	"Failed to list nearby warehouses":                                "Không thể lấy danh sách kho lân cận",
+	"Failed to list pick lists":                                       "Không thể lấy danh sách phiếu lấy hàng",
+	"Failed to list printers":                                         "Không thể lấy danh sách máy in",
+	"Failed to list receipts":                                         "Không thể lấy danh sách phiếu nhập kho",
+	"Failed to list sagas":                                            "Không thể lấy danh sách saga",
+	"Failed to list stock levels":                                     "Không thể lấy danh sách mức tồn kho",
+	"Failed to list stock movements":                                  "Không thể lấy danh sách biến động tồn kho",
+	"Failed to list storage rooms":                                    "Không thể lấy danh sách phòng kho",
+	"Failed to list suppliers":                                        "Không thể lấy danh sách nhà cung cấp",
+	"Failed to list temperature breaches":                             "Không thể lấy danh sách lần vượt ngưỡng nhiệt độ",
+	"Failed to list tenants":                                          "Không thể lấy danh sách tổ chức",
+	"Failed to list transfer orders":                                  "Không thể lấy danh sách lệnh chuyển kho",
+	"Failed to list warehouses":                                       "Không thể lấy danh sách kho",
+	"Failed to list waves":                                            "Không thể lấy danh sách đợt lấy hàng",
+	"Failed to open count session":                                    "Không thể mở phiên kiểm kê",
+	"Failed to post count session":                                    "Không thể ghi sổ phiên kiểm kê",
+	"Failed to print label":                                           "Không thể in nhãn",
+	"Failed to read the outbox":                                       "Không thể đọc hàng đợi gửi",
+	"Failed to receive receipt":                                       "Không thể nhận hàng cho phiếu nhập kho",
+	"Failed to record counts":                                         "Không thể ghi nhận số lượng kiểm kê",
+	"Failed to refresh dashboard stats":                               "Không thể làm mới số liệu bảng điều khiển",
+	"Failed to render label":                                          "Không thể tạo nhãn",
+	"Failed to replay dead letter":                                    "Không thể gửi lại thư lỗi",
+	"Failed to reprocess file":                                        "Không thể xử lý lại tệp",
+	"Failed to resolve scan":                                          "Không thể xác định mã quét",
+	"Failed to revert warehouse":                                      "Không thể khôi phục kho",
+	"Failed to revoke API key":                                        "Không thể thu hồi khóa API",
+	"Failed to run file exchange":                                     "Không thể chạy kênh trao đổi tệp",
+	"Failed to save file exchange":                                    "Không thể lưu kênh trao đổi tệp",
+	"Failed to save integration":                                      "Không thể lưu tích hợp",
+	"Failed to schedule deletion":                                     "Không thể lên lịch xóa",
+	"Failed to search":                                                "Không thể tìm kiếm",
+	"Failed to seed fixtures":                                         "Không thể nạp dữ liệu mẫu",
+	"Failed to start transaction":                                     "Không thể bắt đầu giao dịch",
+	"Failed to store attribute schema":                                "Không thể lưu lược đồ thuộc tính",
+	"Failed to store settings":                                        "Không thể lưu cài đặt",
+	"Failed to store temperature readings":                            "Không thể lưu các số đo nhiệt độ",
+	"Failed to sync integration":                                      "Không thể đồng bộ tích hợp",
+	"Failed to update carrier":                                        "Không thể cập nhật đơn vị vận chuyển",
+	"Failed to update file exchange":                                  "Không thể cập nhật kênh trao đổi tệp",
+	"Failed to update integration":                                    "Không thể cập nhật tích hợp",
+	"Failed to update item":                                           "Không thể cập nhật mặt hàng",
+	"Failed to update pick list":                                      "Không thể cập nhật phiếu lấy hàng",
+	"Failed to update printer":                                        "Không thể cập nhật máy in",
+	"Failed to update saga":                                           "Không thể cập nhật saga",
+	"Failed to update storage room":                                   "Không thể cập nhật phòng kho",
+	"Failed to update storage room tags":                              "Không thể cập nhật thẻ phòng kho",
+	"Failed to update supplier":                                       "Không thể cập nhật nhà cung cấp",
+	"Failed to update warehouse":                                      "Không thể cập nhật kho",
+	"Failed to update warehouse tags":                                 "Không thể cập nhật thẻ kho",
+	"Failed to upload attachment":                                     "Không thể tải lên tệp đính kèm",
+	"Failed to validate attributes":                                   "Không thể kiểm tra thuộc tính",
+	"File exchange not found":                                         "Không tìm thấy kênh trao đổi tệp",
+	"File must be at most %d bytes":                                   "Tệp tối đa %d byte",
+	"File not found":                                                  "Không tìm thấy tệp",
+	"Fixture set is already loaded into another organization":         "Bộ dữ liệu mẫu đã được nạp vào một tổ chức khác",
+	"Flush Caches Successfully":                                       "Đã xóa bộ nhớ đệm thành công",
+	"Forbidden":                                                       "Không có quyền truy cập",
+	"Get Attachment Successfully":                                     "Đã lấy tệp đính kèm thành công",
+	"Get Attribute Schema Successfully":                               "Đã lấy lược đồ thuộc tính thành công",
+	"Get Carrier Successfully":                                        "Đã lấy đơn vị vận chuyển thành công",
+	"Get Count Session Successfully":                                  "Đã lấy phiên kiểm kê thành công",
+	"Get Count Variance Successfully":                                 "Đã lấy chênh lệch kiểm kê thành công",
+	"Get Dashboard Stats Successfully":                                "Đã lấy số liệu bảng điều khiển thành công",
+	"Get Dead Letter Successfully":                                    "Đã lấy thư lỗi thành công",
+	"Get Deletion Successfully":                                       "Đã lấy yêu cầu xóa thành công",
+	"Get Export Successfully":                                         "Đã lấy bản xuất thành công",
+	"Get File Exchange Successfully":                                  "Đã lấy kênh trao đổi tệp thành công",
+	"Get Integration Successfully":                                    "Đã lấy tích hợp thành công",
+	"Get Inventory Valuation Successfully":                            "Đã lấy giá trị tồn kho thành công",
+	"Get Item Successfully":                                           "Đã lấy mặt hàng thành công",
+	"Get Job Status Successfully":                                     "Đã lấy trạng thái tác vụ thành công",
+	"Get Job Successfully":                                            "Đã lấy tác vụ thành công",
+	"Get Log Level Successfully":                                      "Đã lấy mức ghi log thành công",
+	"Get Metering Successfully":                                       "Đã lấy số liệu sử dụng thành công",
+	"Get Movement History Successfully":                               "Đã lấy lịch sử biến động thành công",
+	"Get Outbox Status Successfully":                                  "Đã lấy trạng thái hàng đợi gửi thành công",
+	"Get Pick List Successfully":                                      "Đã lấy phiếu lấy hàng thành công",
+	"Get Printer Successfully":                                        "Đã lấy máy in thành công",
+	"Get Receipt Successfully":                                        "Đã lấy phiếu nhập kho thành công",
+	"Get Reorder Suggestion Successfully":                             "Đã lấy đề xuất đặt hàng lại thành công",
+	"Get Runtime Stats Successfully":                                  "Đã lấy số liệu thời gian chạy thành công",
+	"Get Saga Successfully":                                           "Đã lấy saga thành công",
+	"Get Scheduler Status Successfully":                               "Đã lấy trạng thái bộ lập lịch thành công",
+	"Get Serial Successfully":                                         "Đã lấy số sê-ri thành công",
+	"Get Service Status Successfully":                                 "Đã lấy trạng thái dịch vụ thành công",
+	"Get Settings Successfully":                                       "Đã lấy cài đặt thành công",
+	"Get Stock By Warehouse Successfully":                             "Đã lấy tồn kho theo kho thành công",
+	"Get Storage Room History Successfully":                           "Đã lấy lịch sử phòng kho thành công",
+	"Get Supplier Successfully":                                       "Đã lấy nhà cung cấp thành công",
+	"Get Transfer Order Successfully":                                 "Đã lấy lệnh chuyển kho thành công",
+	"Get Usage Successfully":                                          "Đã lấy mức sử dụng thành công",
+	"Get Warehouse Address Successfully":                              "Đã lấy địa chỉ kho thành công",
+	"Get Warehouse History Successfully":                              "Đã lấy lịch sử kho thành công",
+	"Get Warehouse Successfully":                                      "Đã lấy kho thành công",
+	"Get Warehouse Summary Successfully":                              "Đã lấy tổng quan kho thành công",
+	"Get Wave Successfully":                                           "Đã lấy đợt lấy hàng thành công",
+	"Import ASN Successfully":                                         "Đã nhập ASN thành công",
+	"Ingest Temperature Readings Successfully":                        "Đã ghi nhận các số đo nhiệt độ thành công",
+	"Insufficient stock to allocate pick list":                        "Không đủ tồn kho để phân bổ phiếu lấy hàng",
+	"Integration not found":                                           "Không tìm thấy tích hợp",
+	"Internal server error":                                           "Lỗi máy chủ nội bộ",
+	"Invalid 856 interchange":                                         "Tệp trao đổi 856 không hợp lệ",
+	"Invalid API key ID format":                                       "Định dạng ID khóa API không hợp lệ",
+	"Invalid attachment ID format":                                    "Định dạng ID tệp đính kèm không hợp lệ",
+	"Invalid attachment payload":                                      "Dữ liệu tệp đính kèm không hợp lệ",
+	"Invalid attribute schema":                                        "Lược đồ thuộc tính không hợp lệ",
+	"Invalid attribute schema payload":                                "Dữ liệu lược đồ thuộc tính không hợp lệ",
+	"Invalid batch get payload":                                       "Dữ liệu lấy hàng loạt không hợp lệ",
+	"Invalid carrier ID format":                                       "Định dạng ID đơn vị vận chuyển không hợp lệ",
+	"Invalid carrier payload":                                         "Dữ liệu đơn vị vận chuyển không hợp lệ",
+	"Invalid count payload":                                           "Dữ liệu kiểm kê không hợp lệ",
+	"Invalid count session ID format":                                 "Định dạng ID phiên kiểm kê không hợp lệ",
+	"Invalid count session payload":                                   "Dữ liệu phiên kiểm kê không hợp lệ",
+	"Invalid dead letter ID format":                                   "Định dạng ID thư lỗi không hợp lệ",
+	"Invalid deletion payload":                                        "Dữ liệu yêu cầu xóa không hợp lệ",
+	"Invalid export ID format":                                        "Định dạng ID bản xuất không hợp lệ",
+	"Invalid export payload":                                          "Dữ liệu bản xuất không hợp lệ",
+	"Invalid file ID format":                                          "Định dạng ID tệp không hợp lệ",
+	"Invalid file exchange ID format":                                 "Định dạng ID kênh trao đổi tệp không hợp lệ",
+	"Invalid file exchange payload":                                   "Dữ liệu kênh trao đổi tệp không hợp lệ",
+	"Invalid integration ID format":                                   "Định dạng ID tích hợp không hợp lệ",
+	"Invalid integration payload":                                     "Dữ liệu tích hợp không hợp lệ",
+	"Invalid item ID format":                                          "Định dạng ID mặt hàng không hợp lệ",
+	"Invalid item payload":                                            "Dữ liệu mặt hàng không hợp lệ",
+	"Invalid job ID format":                                           "Định dạng ID tác vụ không hợp lệ",
+	"Invalid location code format":                                    "Định dạng mã vị trí không hợp lệ",
+	"Invalid message":                                                 "Thông điệp không hợp lệ",
+	"Invalid or expired API key":                                      "Khóa API không hợp lệ hoặc đã hết hạn",
+	"Invalid or expired token":                                        "Token không hợp lệ hoặc đã hết hạn",
+	"Invalid pick confirmation payload":                               "Dữ liệu xác nhận lấy hàng không hợp lệ",
+	"Invalid pick list ID format":                                     "Định dạng ID phiếu lấy hàng không hợp lệ",
+	"Invalid pick list payload":                                       "Dữ liệu phiếu lấy hàng không hợp lệ",
+	"Invalid post payload":                                            "Dữ liệu ghi sổ không hợp lệ",
+	"Invalid print payload":                                           "Dữ liệu in không hợp lệ",
+	"Invalid printer ID format":                                       "Định dạng ID máy in không hợp lệ",
+	"Invalid printer payload":                                         "Dữ liệu máy in không hợp lệ",
+	"Invalid receipt ID format":                                       "Định dạng ID phiếu nhập kho không hợp lệ",
+	"Invalid receipt payload":                                         "Dữ liệu phiếu nhập kho không hợp lệ",
+	"Invalid receive payload":                                         "Dữ liệu nhận hàng không hợp lệ",
+	"Invalid request body":                                            "Nội dung yêu cầu không hợp lệ",
+	"Invalid saga ID format":                                          "Định dạng ID saga không hợp lệ",
+	"Invalid scan count payload":                                      "Dữ liệu quét kiểm kê không hợp lệ",
+	"Invalid scan move payload":                                       "Dữ liệu quét chuyển hàng không hợp lệ",
+	"Invalid scan pick payload":                                       "Dữ liệu quét lấy hàng không hợp lệ",
+	"Invalid serials payload":                                         "Dữ liệu số sê-ri không hợp lệ",
+	"Invalid settings payload":                                        "Dữ liệu cài đặt không hợp lệ",
+	"Invalid storage room ID %d":                                      "ID phòng kho %d không hợp lệ",
+	"Invalid storage room ID format":                                  "Định dạng ID phòng kho không hợp lệ",
+	"Invalid storage room payload":                                    "Dữ liệu phòng kho không hợp lệ",
+	"Invalid supplier ID format":                                      "Định dạng ID nhà cung cấp không hợp lệ",
+	"Invalid supplier payload":                                        "Dữ liệu nhà cung cấp không hợp lệ",
+	"Invalid tags payload":                                            "Dữ liệu thẻ không hợp lệ",
+	"Invalid temperature payload":                                     "Dữ liệu nhiệt độ không hợp lệ",
+	"Invalid transfer order ID format":                                "Định dạng ID lệnh chuyển kho không hợp lệ",
+	"Invalid transfer order payload":                                  "Dữ liệu lệnh chuyển kho không hợp lệ",
+	"Invalid transfer receipt payload":                                "Dữ liệu nhận hàng chuyển kho không hợp lệ",
+	"Invalid transfer shipment payload":                               "Dữ liệu xuất hàng chuyển kho không hợp lệ",
+	"Invalid warehouse ID":                                            "ID kho không hợp lệ",
+	"Invalid warehouse ID format":                                     "Định dạng ID kho không hợp lệ",
+	"Invalid warehouse payload":                                       "Dữ liệu kho không hợp lệ",
+	"Invalid wave ID format":                                          "Định dạng ID đợt lấy hàng không hợp lệ",
+	"Invalid wave payload":                                            "Dữ liệu đợt lấy hàng không hợp lệ",
+	"Item not found":                                                  "Không tìm thấy mặt hàng",
+	"Job not found":                                                   "Không tìm thấy tác vụ",
+	"Last-Event-ID must be a positive integer":                        "Last-Event-ID phải là số nguyên dương",
+	"Line %d does not belong to this receipt":                         "Dòng %d không thuộc phiếu nhập kho này",
+	"Line %d has no counted variance in this session":                 "Dòng %d không có chênh lệch kiểm kê trong phiên này",
+	"Line %d would drive stock below zero, recount it":                "Dòng %d sẽ làm tồn kho âm, hãy kiểm kê lại",
+	"List API Keys Successfully":                                      "Đã lấy danh sách khóa API thành công",
+	"List Attachments Successfully":                                   "Đã lấy danh sách tệp đính kèm thành công",
+	"List Attribute Schemas Successfully":                             "Đã lấy danh sách lược đồ thuộc tính thành công",
+	"List Audit Logs Successfully":                                    "Đã lấy danh sách nhật ký kiểm tra thành công",
+	"List Carriers Successfully":                                      "Đã lấy danh sách đơn vị vận chuyển thành công",
+	"List Count Sessions Successfully":                                "Đã lấy danh sách phiên kiểm kê thành công",
+	"List Dead Letters Successfully":                                  "Đã lấy danh sách thư lỗi thành công",
+	"List EDI Documents Successfully":                                 "Đã lấy danh sách chứng từ EDI thành công",
+	"List Exports Successfully":                                       "Đã lấy danh sách bản xuất thành công",
+	"List File Exchange Files Successfully":                           "Đã lấy danh sách tệp của kênh trao đổi thành công",
+	"List File Exchange Runs Successfully":                            "Đã lấy danh sách lần chạy của kênh trao đổi thành công",
+	"List File Exchanges Successfully":                                "Đã lấy danh sách kênh trao đổi tệp thành công",
+	"List In-Transit Stock Successfully":                              "Đã lấy danh sách hàng đang vận chuyển thành công",
+	"List Integration Orders Successfully":                            "Đã lấy danh sách đơn hàng tích hợp thành công",
+	"List Integrations Successfully":                                  "Đã lấy danh sách tích hợp thành công",
+	"List Items Successfully":                                         "Đã lấy danh sách mặt hàng thành công",
+	"List Jobs Successfully":                                          "Đã lấy danh sách tác vụ thành công",
+	"List Nearby Warehouses Successfully":                             "Đã lấy danh sách kho lân cận thành công",
+	"List Pick Lists Successfully":                                    "Đã lấy danh sách phiếu lấy hàng thành công",
+	"List Printers Successfully":                                      "Đã lấy danh sách máy in thành công",
+	"List Receipts Successfully":                                      "Đã lấy danh sách phiếu nhập kho thành công",
+	"List Sagas Successfully":                                         "Đã lấy danh sách saga thành công",
+	"List Stock Levels Successfully":                                  "Đã lấy danh sách mức tồn kho thành công",
+	"List Stock Movements Successfully":                               "Đã lấy danh sách biến động tồn kho thành công",
+	"List Storage Rooms Successfully":                                 "Đã lấy danh sách phòng kho thành công",
+	"List Suppliers Successfully":                                     "Đã lấy danh sách nhà cung cấp thành công",
+	"List Temperature Breaches Successfully":                          "Đã lấy danh sách lần vượt ngưỡng nhiệt độ thành công",
+	"List Tenants Successfully":                                       "Đã lấy danh sách tổ chức thành công",
+	"List Transfer Orders Successfully":                               "Đã lấy danh sách lệnh chuyển kho thành công",
+	"List Warehouse Successfully":                                     "Đã lấy danh sách kho thành công",
+	"List Waves Successfully":                                         "Đã lấy danh sách đợt lấy hàng thành công",
+	"Location not found":                                              "Không tìm thấy vị trí",
+	"No %s with ID %d":                                                "Không có %s với ID %d",
+	"No attribute schema for %s":                                      "Không có lược đồ thuộc tính cho %s",
+	"No deletion is scheduled":                                        "Không có yêu cầu xóa nào được lên lịch",
+	"No deletion was requested":                                       "Không có yêu cầu xóa nào",
+	"No fields to update":                                             "Không có trường nào để cập nhật",
+	"Not enough stock at the location":                                "Không đủ tồn kho tại vị trí",
+	"Nothing matches the scanned code":                                "Không có gì khớp với mã đã quét",
+	"Object storage is not configured":                                "Chưa cấu hình lưu trữ đối tượng",
+	"Only backend services deliver messages":                          "Chỉ các dịch vụ backend mới được gửi thông điệp",
+	"Only failed inbound files can be reprocessed":                    "Chỉ có thể xử lý lại các tệp nhận vào bị lỗi",
+	"Open Count Session Successfully":                                 "Đã mở phiên kiểm kê thành công",
+	"Operator access is required":                                     "Cần quyền vận hành",
+	"Pick list %d is %s":                                              "Phiếu lấy hàng %d đang ở trạng thái %s",
+	"Pick list %d is for another warehouse":                           "Phiếu lấy hàng %d thuộc kho khác",
+	"Pick list is %s":                                                 "Phiếu lấy hàng đang ở trạng thái %s",
+	"Pick list not found":                                             "Không tìm thấy phiếu lấy hàng",
+	"Pick lists not found":                                            "Không tìm thấy các phiếu lấy hàng",
+	"Post Count Session Successfully":                                 "Đã ghi sổ phiên kiểm kê thành công",
+	"Print Label Successfully":                                        "Đã in nhãn thành công",
+	"Printer not found":                                               "Không tìm thấy máy in",
+	"Query parameter q is required":                                   "Cần có tham số truy vấn q",
+	"Query parameters lat and lng are required numbers":               "Tham số truy vấn lat và lng là số bắt buộc",
+	"Receipt is already closed":                                       "Phiếu nhập kho đã đóng",
+	"Receipt not found":                                               "Không tìm thấy phiếu nhập kho",
+	"Receive Receipt Successfully":                                    "Đã nhận hàng cho phiếu nhập kho thành công",
+	"Record Counts Successfully":                                      "Đã ghi nhận số lượng kiểm kê thành công",
+	"Refresh Dashboard Stats Successfully":                            "Đã làm mới số liệu bảng điều khiển thành công",
+	"Replay Dead Letter Successfully":                                 "Đã gửi lại thư lỗi thành công",
+	"Reprocess File Successfully":                                     "Đã xử lý lại tệp thành công",
+	"Request body must be at most %d bytes":                           "Nội dung yêu cầu tối đa %d byte",
+	"Resolve Scan Successfully":                                       "Đã xác định mã quét thành công",
+	"Revert Warehouse Successfully":                                   "Đã khôi phục kho thành công",
+	"Revoke API Key Successfully":                                     "Đã thu hồi khóa API thành công",
+	"Run File Exchange Successfully":                                  "Đã chạy kênh trao đổi tệp thành công",
+	"Saga is %s":                                                      "Saga đang ở trạng thái %s",
+	"Saga is advancing, try again":                                    "Saga đang được xử lý, hãy thử lại",
+	"Saga not found":                                                  "Không tìm thấy saga",
+	"Schedule Deletion Successfully":                                  "Đã lên lịch xóa thành công",
+	"Scheduler is not running":                                        "Bộ lập lịch không chạy",
+	"Search Successfully":                                             "Đã tìm kiếm thành công",
+	"Seed Fixtures Successfully":                                      "Đã nạp dữ liệu mẫu thành công",
+	"Serial not found":                                                "Không tìm thấy số sê-ri",
+	"Serial number %s is given more than once":                        "Số sê-ri %s được nhập nhiều lần",
+	"Service Unavailable":                                             "Dịch vụ không khả dụng",
+	"Set Log Level Successfully":                                      "Đã đặt mức ghi log thành công",
+	"Source and destination warehouse must differ":                    "Kho nguồn và kho đích phải khác nhau",
+	"Stock levels hold fewer units than the serials":                  "Mức tồn kho ít hơn số lượng số sê-ri",
+	"Storage room %d is not covered by this count":                    "Phòng kho %d không thuộc đợt kiểm kê này",
+	"Storage room %d is not part of the receiving warehouse":          "Phòng kho %d không thuộc kho nhận hàng",
+	"Storage room changed since the version in If-Match":              "Phòng kho đã thay đổi so với phiên bản trong If-Match",
+	"Storage room not found":                                          "Không tìm thấy phòng kho",
+	"Storage room not found in warehouse":                             "Không tìm thấy phòng kho trong kho",
+	"Supplier not found":                                              "Không tìm thấy nhà cung cấp",
+	"Sync Integration Successfully":                                   "Đã đồng bộ tích hợp thành công",
+	"Tenant not found":                                                "Không tìm thấy tổ chức",
+	"The %s role is required":                                         "Cần có vai trò %s",
+	"The client certificate is not allowed":                           "Chứng chỉ máy khách không được phép",
+	"The file exchange is running, try again when the run is over":    "Kênh trao đổi tệp đang chạy, hãy thử lại khi lần chạy kết thúc",
+	"The request is not allowed by policy":                            "Yêu cầu không được chính sách cho phép",
+	"The supplier has no EDI ID":                                      "Nhà cung cấp chưa có mã EDI",
+	"This document was already imported":                              "Chứng từ này đã được nhập",
+	"Too Many Requests":                                               "Quá nhiều yêu cầu",
+	"Transfer order not found":                                        "Không tìm thấy lệnh chuyển kho",
+	"Unable to authorize the request, try again later":                "Không thể phân quyền cho yêu cầu, hãy thử lại sau",
+	"Unable to verify token, try again later":                         "Không thể xác minh token, hãy thử lại sau",
+	"Unauthorized":                                                    "Chưa xác thực",
+	"Unknown entity type, expected one of %s":                         "Loại thực thể không xác định, cần là một trong %s",
+	"Unknown fields %s, expected some of %s":                          "Trường %s không xác định, cần là một số trong %s",
+	"Unknown fixture set":                                             "Bộ dữ liệu mẫu không xác định",
+	"Unknown search type %s":                                          "Loại tìm kiếm %s không xác định",
+	"Unknown storage rooms":                                           "Các phòng kho không xác định",
+	"Update Attribute Schema Successfully":                            "Đã cập nhật lược đồ thuộc tính thành công",
+	"Update Carrier Successfully":                                     "Đã cập nhật đơn vị vận chuyển thành công",
+	"Update File Exchange Successfully":                               "Đã cập nhật kênh trao đổi tệp thành công",
+	"Update Integration Successfully":                                 "Đã cập nhật tích hợp thành công",
+	"Update Item Successfully":                                        "Đã cập nhật mặt hàng thành công",
+	"Update Pick List Successfully":                                   "Đã cập nhật phiếu lấy hàng thành công",
+	"Update Printer Successfully":                                     "Đã cập nhật máy in thành công",
+	"Update Saga Successfully":                                        "Đã cập nhật saga thành công",
+	"Update Settings Successfully":                                    "Đã cập nhật cài đặt thành công",
+	"Update Storage Room Successfully":                                "Đã cập nhật phòng kho thành công",
+	"Update Storage Room Tags Successfully":                           "Đã cập nhật thẻ phòng kho thành công",
+	"Update Supplier Successfully":                                    "Đã cập nhật nhà cung cấp thành công",
+	"Update Warehouse Status Successfully":                            "Đã cập nhật trạng thái kho thành công",
+	"Update Warehouse Successfully":                                   "Đã cập nhật kho thành công",
+	"Update Warehouse Tags Successfully":                              "Đã cập nhật thẻ kho thành công",
+	"Upload Attachment Successfully":                                  "Đã tải lên tệp đính kèm thành công",
+	"Version not found":                                               "Không tìm thấy phiên bản",
+	"Warehouse changed since the version in If-Match":                 "Kho đã thay đổi so với phiên bản trong If-Match",
+	"Warehouse looks like an existing one, retry with ?force=true to create it anyway":       "Kho có vẻ trùng với một kho đã có, hãy thử lại với ?force=true để vẫn tạo kho",
+	"Warehouse looks like warehouse %d %q at %q, retry with ?force=true to create it anyway": "Kho có vẻ trùng với kho %d %q tại %q, hãy thử lại với ?force=true để vẫn tạo kho",
+	"Warehouse not found": "Không tìm thấy kho",
+	"Warehouse still has storage rooms, delete them first or retry with ?cascade=true": "Kho vẫn còn phòng kho, hãy xóa chúng trước hoặc thử lại với ?cascade=true",
+	"Wave not found":                                      "Không tìm thấy đợt lấy hàng",
+	"confirm must be the organization ID":                 "Confirm phải là ID của tổ chức",
+	"database connection failed":                          "Kết nối cơ sở dữ liệu thất bại",
+	"expires_in must be a positive duration such as 720h": "Expires_in phải là một khoảng thời gian dương, ví dụ 720h",
+	"from and to are the same location":                   "From và to là cùng một vị trí",
+	"from is required to move stock of a SKU":             "Cần có from để chuyển tồn kho của một SKU",
+	"group_by must be day, week or month, got %q":         "Group_by phải là day, week hoặc month, nhận được %q",
+	"kind must be one of %s":                              "Kind phải là một trong %s",
+	"lead_time_days must be a whole number of days":       "Lead_time_days phải là số ngày nguyên",
+	"level must be debug, info, warn or error":            "Level phải là debug, info, warn hoặc error",
+	"open must be true or false":                          "Open phải là true hoặc false",
+	"pending must be true or false":                       "Pending phải là true hoặc false",
+	"radius must be between 0 and %d meters":              "Radius phải nằm trong khoảng 0 đến %d mét",
+	"supplier_id is required":                             "Cần có supplier_id",
+	"to_version must be a positive integer":               "To_version phải là số nguyên dương",
+	"warehouse_id is required":                            "Cần có warehouse_id",
+}
@@ -0,0 +1,58 @@
+// Package i18n translates the human-readable messages of responses. A
+// message is looked up by its English text in the catalog of the locale
+// negotiated from the request's Accept-Language header; messages missing
+// from a catalog are answered in English.
+package i18n
+
+import (
+	"fmt"
+
+	"golang.org/x/text/language"
+)
+
+// Default is the locale of requests accepting none of the supported ones
+var Default = language.English
+
+// supported are the locales with a catalog, Default first
+var supported = []language.Tag{language.English, language.Vietnamese}
+
+var matcher = language.NewMatcher(supported)
+
+// catalogs maps a locale to its translations, keyed by the English message.
+// English needs none.
+var catalogs = map[language.Tag]map[string]string{
+	language.Vietnamese: vi,
+}
+
+// Supported returns the locales messages are translated to
+func Supported() []language.Tag {
+	return append([]language.Tag(nil), supported...)
+}
+
+// Negotiate returns the supported locale best matching an Accept-Language
+// header, Default when the header is empty, malformed or matches none
+func Negotiate(acceptLanguage string) language.Tag {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return Default
+	}
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return Default
+	}
+	return supported[index]
+}
+
+// Translate returns message in locale, formatted with args as fmt.Sprintf
+// does. message is the English text and the key of the catalogs; a message
+// without args is returned untouched when it has no translation, so text
+// that isn't a format, such as an error's, may go through too.
+func Translate(locale language.Tag, message string, args ...any) string {
+	if translated, ok := catalogs[locale][message]; ok {
+		message = translated
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
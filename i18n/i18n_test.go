@@ -0,0 +1,64 @@
+package i18n
+
+import (
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header string
+		want   language.Tag
+	}{
+		{"", language.English},
+		{"vi", language.Vietnamese},
+		{"vi-VN,vi;q=0.9,en;q=0.8", language.Vietnamese},
+		{"fr-FR, vi;q=0.5", language.Vietnamese},
+		{"en-GB,vi;q=0.5", language.English},
+		{"fr", language.English},
+		{"*", language.English},
+		{";;q=x", language.English},
+	}
+	for _, tt := range tests {
+		if got := Negotiate(tt.header); got != tt.want {
+			t.Errorf("Negotiate(%q) = %s, want %s", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	if got := Translate(language.Vietnamese, "Warehouse not found"); got != "Không tìm thấy kho" {
+		t.Errorf("translated %q", got)
+	}
+	if got := Translate(language.English, "Warehouse not found"); got != "Warehouse not found" {
+		t.Errorf("English %q", got)
+	}
+	if got := Translate(language.Vietnamese, "Invalid storage room ID %d", 7); got != "ID phòng kho 7 không hợp lệ" {
+		t.Errorf("formatted %q", got)
+	}
+	// Untranslated text is kept as is, even when it looks like a format
+	if got := Translate(language.Vietnamese, "100% off"); got != "100% off" {
+		t.Errorf("untranslated %q", got)
+	}
+}
+
+func TestCatalogFormats(t *testing.T) {
+	verbs := func(s string) string {
+		var v []byte
+		for i := 0; i < len(s)-1; i++ {
+			if s[i] == '%' {
+				v = append(v, s[i+1])
+				i++
+			}
+		}
+		return string(v)
+	}
+	for tag, catalog := range catalogs {
+		for message, translated := range catalog {
+			if verbs(message) != verbs(translated) {
+				t.Errorf("%s: %q translates %q with other verbs", tag, message, translated)
+			}
+		}
+	}
+}
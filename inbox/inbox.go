@@ -0,0 +1,187 @@
+// Package inbox consumes the messages other services publish to the broker.
+// Delivery is at least once: the broker bridge pushes each message until it
+// is acknowledged, so a message may arrive again, also after newer ones.
+// The Consumer records every message it handles in the processed_message
+// table in the transaction of the handler's changes, which makes a message
+// take effect once however often it is delivered.
+package inbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Topics consumed
+const (
+	TopicOrderCreated = "order.created"
+)
+
+// Outcomes of consuming a message
+const (
+	// The handler's changes committed
+	OutcomeProcessed = "processed"
+	// The message was processed or rejected before
+	OutcomeDuplicate = "duplicate"
+	// The message can never be handled, it is recorded and dropped
+	OutcomeRejected = "rejected"
+	// No handler consumes the topic
+	OutcomeIgnored = "ignored"
+	// Handling failed for now, the message is to be delivered again
+	OutcomeError = "error"
+)
+
+// Message is a message as the broker bridge delivers it, in the shape the
+// outbox publishes. ID is unique per topic, its producer sets it.
+type Message struct {
+	ID        string          `json:"id" binding:"required"`
+	OrgID     string          `json:"org_id" binding:"required"`
+	Topic     string          `json:"topic" binding:"required"`
+	Key       string          `json:"key"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// Handler handles the messages of a topic with q, which belongs to the
+// transaction recording the message. An error rolls the changes back and
+// has the message delivered again, unless it is a Reject.
+type Handler func(ctx context.Context, q *models.Queries, msg Message) error
+
+type rejection struct {
+	err error
+}
+
+func (r rejection) Error() string { return r.err.Error() }
+func (r rejection) Unwrap() error { return r.err }
+
+// Reject tells the consumer a message can never be handled, e.g. it is
+// malformed. Its changes are rolled back and the message is recorded as
+// rejected, it is not delivered again.
+func Reject(err error) error {
+	return rejection{err}
+}
+
+// Consumer hands messages to the handler of their topic. Several instances
+// may consume at once, a message delivered to two of them is handled by
+// one.
+type Consumer struct {
+	db                *pgxpool.Pool
+	queries           *models.Queries
+	handlers          map[string]Handler
+	tracer            trace.Tracer
+	prometheusMetrics *observability.PrometheusMetrics
+}
+
+func NewConsumer(db *pgxpool.Pool, prometheusMetrics *observability.PrometheusMetrics) *Consumer {
+	return &Consumer{
+		db:                db,
+		queries:           models.New(db),
+		handlers:          map[string]Handler{},
+		tracer:            otel.Tracer("warehouse-service/inbox"),
+		prometheusMetrics: prometheusMetrics,
+	}
+}
+
+// Handle registers the handler of topic, replacing any before
+func (c *Consumer) Handle(topic string, handler Handler) {
+	c.handlers[topic] = handler
+}
+
+// Consume handles a message and returns the outcome. The message is
+// acknowledged when the error is nil; otherwise the outcome is
+// OutcomeError and the message is to be delivered again.
+func (c *Consumer) Consume(ctx context.Context, msg Message) (string, error) {
+	spanCtx, span := c.tracer.Start(ctx, "inbox consume", trace.WithSpanKind(trace.SpanKindConsumer))
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("messaging.destination.name", msg.Topic),
+		attribute.String("messaging.message.id", msg.ID),
+		attribute.String("tenant.id", msg.OrgID),
+	)
+
+	start := time.Now()
+	outcome, err := c.consume(spanCtx, msg)
+	if err != nil {
+		outcome = OutcomeError
+		span.RecordError(err)
+	}
+	span.SetAttributes(attribute.String("inbox.outcome", outcome))
+	if c.prometheusMetrics != nil {
+		topic := msg.Topic
+		if _, ok := c.handlers[topic]; !ok {
+			// Topics nobody consumes don't get a series each
+			topic = "other"
+		}
+		var lag time.Duration
+		if !msg.CreatedAt.IsZero() {
+			lag = max(start.Sub(msg.CreatedAt), 0)
+		}
+		c.prometheusMetrics.RecordInboxMessage(topic, outcome, time.Since(start), lag)
+	}
+	return outcome, err
+}
+
+func (c *Consumer) consume(ctx context.Context, msg Message) (string, error) {
+	handler, ok := c.handlers[msg.Topic]
+	if !ok {
+		slog.Debug("Ignored a message of a topic without handler",
+			slog.String("topic", msg.Topic),
+			slog.String("id", msg.ID),
+		)
+		return OutcomeIgnored, nil
+	}
+
+	outcome := OutcomeProcessed
+	err := pgx.BeginFunc(ctx, c.db, func(tx pgx.Tx) error {
+		qtx := c.queries.WithTx(tx)
+		claimed, err := qtx.ClaimProcessedMessage(ctx, models.ClaimProcessedMessageParams{
+			Topic:     msg.Topic,
+			MessageID: msg.ID,
+			OrgID:     msg.OrgID,
+		})
+		if err != nil {
+			return err
+		}
+		if claimed == 0 {
+			outcome = OutcomeDuplicate
+			return nil
+		}
+
+		// A savepoint, so that a rejected message's changes roll back while
+		// it is still recorded
+		err = pgx.BeginFunc(ctx, tx, func(sp pgx.Tx) error {
+			return handler(ctx, c.queries.WithTx(sp), msg)
+		})
+		var rejected rejection
+		if !errors.As(err, &rejected) {
+			return err
+		}
+		outcome = OutcomeRejected
+		slog.Warn("Rejected a message that can't be handled",
+			slog.String("topic", msg.Topic),
+			slog.String("id", msg.ID),
+			slog.String("tenant_id", msg.OrgID),
+			slog.Any("err", rejected.Error()),
+		)
+		return qtx.RejectProcessedMessage(ctx, models.RejectProcessedMessageParams{
+			Topic:     msg.Topic,
+			MessageID: msg.ID,
+			Error:     rejected.Error(),
+		})
+	})
+	if err != nil {
+		return OutcomeError, fmt.Errorf("%s message %s: %w", msg.Topic, msg.ID, err)
+	}
+	return outcome, nil
+}
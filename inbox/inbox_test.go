@@ -0,0 +1,27 @@
+package inbox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestReject(t *testing.T) {
+	err := Reject(io.ErrUnexpectedEOF)
+	var rejected rejection
+	if !errors.As(err, &rejected) || !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("Reject(%v) = %#v", io.ErrUnexpectedEOF, err)
+	}
+	if err.Error() != io.ErrUnexpectedEOF.Error() {
+		t.Errorf("message %q", err.Error())
+	}
+}
+
+func TestConsumeIgnoresUnknownTopics(t *testing.T) {
+	c := NewConsumer(nil, nil)
+	outcome, err := c.Consume(context.Background(), Message{ID: "1", OrgID: "org_1", Topic: "order.cancelled"})
+	if err != nil || outcome != OutcomeIgnored {
+		t.Fatalf("outcome %s, %v", outcome, err)
+	}
+}
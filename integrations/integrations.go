@@ -0,0 +1,194 @@
+// Package integrations connects the stock of a warehouse to the sales
+// channels and ERPs an organization sells through. A Connector pushes the
+// quantity of each SKU available to promise and pulls the orders placed
+// in the channel, which the service reserves stock for.
+package integrations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Kinds of connectors
+const (
+	KindShopify = "shopify"
+	KindWebhook = "webhook"
+)
+
+// requestTimeout bounds each request to a channel
+const requestTimeout = 15 * time.Second
+
+// Level is the quantity of a SKU available to promise
+type Level struct {
+	Sku       string `json:"sku"`
+	Available int64  `json:"available"`
+}
+
+// Order is an order of a channel. An order is pulled again whenever it
+// changes in the channel, with a later UpdatedAt.
+type Order struct {
+	// ID identifies the order in the channel
+	ID string
+	// Reference is the order number people know the order by
+	Reference string
+	Lines     []OrderLine
+	Cancelled bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// OrderLine is a quantity of a SKU in base units
+type OrderLine struct {
+	Sku      string `json:"sku"`
+	Quantity int32  `json:"quantity"`
+}
+
+// Connector talks to one channel
+type Connector interface {
+	// PushAvailability sets the quantity available of each SKU of levels
+	// in the channel. SKUs the channel doesn't sell are skipped.
+	PushAvailability(ctx context.Context, levels []Level) error
+	// PullOrders returns the orders updated at or after since, oldest
+	// first
+	PullOrders(ctx context.Context, since time.Time) ([]Order, error)
+}
+
+// Settings configure a connector
+type Settings struct {
+	Kind string
+	// URL is the shop of Shopify, e.g. https://acme.myshopify.com, or the
+	// endpoint levels are posted to
+	URL string
+	// OrdersURL is the endpoint orders are pulled from for a webhook. A
+	// webhook without one only pushes levels.
+	OrdersURL string
+	// Token authenticates requests, an Admin API access token for Shopify
+	// and a bearer token for a webhook
+	Token string
+	// LocationID is the Shopify location levels are set at
+	LocationID string
+}
+
+// New returns the connector of settings, or an error saying what's wrong
+// with them
+func New(s Settings) (Connector, error) {
+	if err := checkURL("url", s.URL); err != nil {
+		return nil, err
+	}
+	switch s.Kind {
+	case KindShopify:
+		if s.OrdersURL != "" {
+			return nil, errors.New("orders_url is only for webhooks, Shopify orders are pulled from the shop")
+		}
+		if s.Token == "" {
+			return nil, errors.New("a Shopify integration needs the token of its app")
+		}
+		location, err := strconv.ParseInt(s.LocationID, 10, 64)
+		if err != nil || location <= 0 {
+			return nil, errors.New("a Shopify integration needs the numeric ID of its location")
+		}
+		return NewShopify(s.URL, s.Token, location), nil
+	case KindWebhook:
+		if s.OrdersURL != "" {
+			if err := checkURL("orders_url", s.OrdersURL); err != nil {
+				return nil, err
+			}
+		}
+		if s.LocationID != "" {
+			return nil, errors.New("location_id is only for Shopify")
+		}
+		return NewWebhook(s.URL, s.OrdersURL, s.Token), nil
+	}
+	return nil, fmt.Errorf("unknown integration kind %q", s.Kind)
+}
+
+func checkURL(field, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("%s must be an absolute http or https URL", field)
+	}
+	if u.User != nil {
+		return fmt.Errorf("%s must not hold credentials, send them as the token", field)
+	}
+	return nil
+}
+
+// RateLimitError is returned when the channel asks to slow down
+type RateLimitError struct {
+	// RetryAfter is how long the channel asked to wait, zero when it
+	// didn't say
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("rate limited, retry after %s", e.RetryAfter)
+	}
+	return "rate limited"
+}
+
+// StatusError is returned for a response other than 2xx
+type StatusError struct {
+	Request    string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s: unexpected status %d", e.Request, e.StatusCode)
+}
+
+// maxResponseSize bounds the responses decoded
+const maxResponseSize = 16 << 20
+
+// send sends req, described as what in errors, and decodes the JSON
+// response into v unless v is nil
+func send(client *http.Client, req *http.Request, what string, v any) (http.Header, error) {
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", what, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusTooManyRequests {
+		seconds, _ := strconv.ParseFloat(resp.Header.Get("Retry-After"), 64)
+		return nil, &RateLimitError{RetryAfter: time.Duration(seconds * float64(time.Second))}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &StatusError{Request: what, StatusCode: resp.StatusCode}
+	}
+	if v != nil {
+		if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(v); err != nil {
+			return nil, fmt.Errorf("%s: decode response: %w", what, err)
+		}
+	}
+	return resp.Header, nil
+}
+
+// newJSONRequest builds a request with body encoded as JSON, or none when
+// body is nil
+func newJSONRequest(ctx context.Context, method, rawURL string, body any) (*http.Request, error) {
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		r = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, r)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, nil
+}
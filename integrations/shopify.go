@@ -0,0 +1,199 @@
+package integrations
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// shopifyAPIVersion is the version of the Admin REST API spoken
+const shopifyAPIVersion = "2024-10"
+
+// shopifyPageSize is the most records Shopify returns per page
+const shopifyPageSize = "250"
+
+// Shopify sets inventory levels at a location of a shop and pulls the
+// shop's orders through the Admin REST API
+type Shopify struct {
+	baseURL    string
+	token      string
+	locationID int64
+	client     *http.Client
+}
+
+// NewShopify returns a connector for the shop at shopURL authenticating
+// with the Admin API access token of a custom app, which needs the
+// read_products, read_orders and write_inventory scopes
+func NewShopify(shopURL, token string, locationID int64) *Shopify {
+	return &Shopify{
+		baseURL:    strings.TrimRight(shopURL, "/") + "/admin/api/" + shopifyAPIVersion,
+		token:      token,
+		locationID: locationID,
+		client:     &http.Client{Timeout: requestTimeout},
+	}
+}
+
+func (s *Shopify) send(ctx context.Context, method, rawURL string, body, v any) (http.Header, error) {
+	req, err := newJSONRequest(ctx, method, rawURL, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Shopify-Access-Token", s.token)
+	path := strings.TrimPrefix(req.URL.Path, "/admin/api/"+shopifyAPIVersion)
+	return send(s.client, req, "shopify "+method+" "+path, v)
+}
+
+// nextPage returns the URL of the next page of a Link header, empty on the
+// last page
+func nextPage(header http.Header) string {
+	for _, link := range strings.Split(header.Get("Link"), ",") {
+		target, params, ok := strings.Cut(strings.TrimSpace(link), ";")
+		if ok && strings.Contains(params, `rel="next"`) {
+			return strings.Trim(strings.TrimSpace(target), "<>")
+		}
+	}
+	return ""
+}
+
+type shopifyProducts struct {
+	Products []struct {
+		Variants []struct {
+			Sku             string `json:"sku"`
+			InventoryItemID int64  `json:"inventory_item_id"`
+		} `json:"variants"`
+	} `json:"products"`
+}
+
+// inventoryItems maps the SKU of each variant of the shop to its inventory
+// item
+func (s *Shopify) inventoryItems(ctx context.Context) (map[string]int64, error) {
+	items := map[string]int64{}
+	next := s.baseURL + "/products.json?fields=variants&limit=" + shopifyPageSize
+	for next != "" {
+		var page shopifyProducts
+		header, err := s.send(ctx, http.MethodGet, next, nil, &page)
+		if err != nil {
+			return nil, err
+		}
+		for _, product := range page.Products {
+			for _, variant := range product.Variants {
+				if variant.Sku != "" {
+					items[variant.Sku] = variant.InventoryItemID
+				}
+			}
+		}
+		next = nextPage(header)
+	}
+	return items, nil
+}
+
+// PushAvailability sets the available quantity of the variants with the
+// SKUs of levels at the location. A variant whose inventory isn't tracked
+// or stocked at the location, which Shopify rejects, is skipped like a SKU
+// the shop doesn't sell.
+func (s *Shopify) PushAvailability(ctx context.Context, levels []Level) error {
+	if len(levels) == 0 {
+		return nil
+	}
+	items, err := s.inventoryItems(ctx)
+	if err != nil {
+		return err
+	}
+	for _, level := range levels {
+		item, ok := items[level.Sku]
+		if !ok {
+			continue
+		}
+		_, err := s.send(ctx, http.MethodPost, s.baseURL+"/inventory_levels/set.json", map[string]any{
+			"location_id":       s.locationID,
+			"inventory_item_id": item,
+			"available":         level.Available,
+		}, nil)
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusUnprocessableEntity {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type shopifyOrders struct {
+	Orders []struct {
+		ID          int64      `json:"id"`
+		Name        string     `json:"name"`
+		CancelledAt *time.Time `json:"cancelled_at"`
+		CreatedAt   time.Time  `json:"created_at"`
+		UpdatedAt   time.Time  `json:"updated_at"`
+		LineItems   []struct {
+			Sku      string `json:"sku"`
+			Quantity int32  `json:"quantity"`
+			// The quantity left after edits removed some, missing from
+			// older orders
+			CurrentQuantity *int32 `json:"current_quantity"`
+		} `json:"line_items"`
+	} `json:"orders"`
+}
+
+// PullOrders returns the orders of the shop updated at or after since,
+// cancelled or not. Line items without a SKU, such as gift cards, are left
+// out.
+func (s *Shopify) PullOrders(ctx context.Context, since time.Time) ([]Order, error) {
+	query := url.Values{}
+	query.Set("status", "any")
+	query.Set("updated_at_min", since.UTC().Format(time.RFC3339))
+	query.Set("fields", "id,name,cancelled_at,created_at,updated_at,line_items")
+	query.Set("limit", shopifyPageSize)
+
+	var orders []Order
+	next := s.baseURL + "/orders.json?" + query.Encode()
+	for next != "" {
+		var page shopifyOrders
+		header, err := s.send(ctx, http.MethodGet, next, nil, &page)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range page.Orders {
+			order := Order{
+				ID:        strconv.FormatInt(o.ID, 10),
+				Reference: o.Name,
+				Cancelled: o.CancelledAt != nil,
+				CreatedAt: o.CreatedAt,
+				UpdatedAt: o.UpdatedAt,
+			}
+			for _, item := range o.LineItems {
+				quantity := item.Quantity
+				if item.CurrentQuantity != nil {
+					quantity = *item.CurrentQuantity
+				}
+				order.Lines = addLine(order.Lines, item.Sku, quantity)
+			}
+			orders = append(orders, order)
+		}
+		next = nextPage(header)
+	}
+	sort.SliceStable(orders, func(i, j int) bool { return orders[i].UpdatedAt.Before(orders[j].UpdatedAt) })
+	return orders, nil
+}
+
+// addLine adds a quantity of sku to lines, to the line of the SKU if
+// there is one already. Lines without a SKU or quantity are left out.
+func addLine(lines []OrderLine, sku string, quantity int32) []OrderLine {
+	if sku == "" || quantity <= 0 {
+		return lines
+	}
+	for i := range lines {
+		if lines[i].Sku == sku {
+			lines[i].Quantity += quantity
+			return lines
+		}
+	}
+	return append(lines, OrderLine{Sku: sku, Quantity: quantity})
+}
@@ -0,0 +1,113 @@
+package integrations
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestShopifyPushAvailability(t *testing.T) {
+	set := map[int64]int64{}
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Shopify-Access-Token") != "shpat_test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/admin/api/" + shopifyAPIVersion + "/products.json":
+			if r.URL.Query().Get("page_info") == "" {
+				w.Header().Set("Link", `<`+server.URL+`/admin/api/`+shopifyAPIVersion+`/products.json?page_info=2&limit=250>; rel="next"`)
+				w.Write([]byte(`{"products": [{"variants": [{"sku": "A", "inventory_item_id": 1}, {"sku": "", "inventory_item_id": 2}]}]}`))
+				return
+			}
+			w.Write([]byte(`{"products": [{"variants": [{"sku": "B", "inventory_item_id": 3}, {"sku": "C", "inventory_item_id": 4}]}]}`))
+		case "/admin/api/" + shopifyAPIVersion + "/inventory_levels/set.json":
+			var body struct {
+				LocationID      int64 `json:"location_id"`
+				InventoryItemID int64 `json:"inventory_item_id"`
+				Available       int64 `json:"available"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.LocationID != 42 {
+				t.Errorf("set %+v, %v", body, err)
+			}
+			if body.InventoryItemID == 4 {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				return
+			}
+			set[body.InventoryItemID] = body.Available
+			w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	s := NewShopify(server.URL+"/", "shpat_test", 42)
+	err := s.PushAvailability(context.Background(), []Level{
+		{Sku: "A", Available: 5}, {Sku: "B", Available: 0}, {Sku: "C", Available: 1}, {Sku: "D", Available: 9},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(set) != 2 || set[1] != 5 || set[3] != 0 {
+		t.Errorf("set %v", set)
+	}
+
+	s = NewShopify(server.URL, "wrong", 42)
+	var statusErr *StatusError
+	if err := s.PushAvailability(context.Background(), []Level{{Sku: "A"}}); !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("got %v with a wrong token", err)
+	}
+}
+
+func TestShopifyPullOrders(t *testing.T) {
+	since := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("updated_at_min") != "2026-03-01T12:00:00Z" || query.Get("status") != "any" {
+			t.Errorf("query %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"orders": [
+			{"id": 2, "name": "#1002", "cancelled_at": "2026-03-01T13:00:00Z", "updated_at": "2026-03-01T13:00:00Z",
+			 "line_items": [{"sku": "A", "quantity": 1}]},
+			{"id": 1, "name": "#1001", "cancelled_at": null, "updated_at": "2026-03-01T12:30:00Z",
+			 "line_items": [{"sku": "A", "quantity": 2}, {"sku": "", "quantity": 1}, {"sku": "A", "quantity": 3, "current_quantity": 1},
+			  {"sku": "B", "quantity": 1, "current_quantity": 0}]}
+		]}`))
+	}))
+	defer server.Close()
+
+	orders, err := NewShopify(server.URL, "shpat_test", 42).PullOrders(context.Background(), since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orders) != 2 || orders[0].ID != "1" || orders[1].ID != "2" {
+		t.Fatalf("orders %+v", orders)
+	}
+	first := orders[0]
+	if first.Reference != "#1001" || first.Cancelled || len(first.Lines) != 1 || first.Lines[0] != (OrderLine{Sku: "A", Quantity: 3}) {
+		t.Errorf("first order %+v", first)
+	}
+	if !orders[1].Cancelled {
+		t.Errorf("second order %+v", orders[1])
+	}
+}
+
+func TestShopifyRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "2.0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	_, err := NewShopify(server.URL, "shpat_test", 42).PullOrders(context.Background(), time.Now())
+	var limited *RateLimitError
+	if !errors.As(err, &limited) || limited.RetryAfter != 2*time.Second {
+		t.Errorf("got %v", err)
+	}
+}
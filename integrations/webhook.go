@@ -0,0 +1,101 @@
+package integrations
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+)
+
+// Webhook posts levels to an endpoint of an ERP or middleware and pulls
+// orders from another
+//
+// Levels are posted as {"levels": [{"sku": "A-1", "available": 12}]}.
+// Orders are read from a GET of the orders URL with the updated_since
+// query parameter, an RFC 3339 time, answered with
+// {"orders": [{"id", "reference", "cancelled", "created_at", "updated_at", "lines": [{"sku", "quantity"}]}]},
+// created_at defaulting to updated_at.
+type Webhook struct {
+	url       string
+	ordersURL string
+	token     string
+	client    *http.Client
+}
+
+// NewWebhook returns a connector posting levels to levelsURL and pulling
+// orders from ordersURL, none when it is empty. Requests carry token as a
+// bearer token unless it is empty.
+func NewWebhook(levelsURL, ordersURL, token string) *Webhook {
+	return &Webhook{
+		url:       levelsURL,
+		ordersURL: ordersURL,
+		token:     token,
+		client:    &http.Client{Timeout: requestTimeout},
+	}
+}
+
+func (w *Webhook) send(ctx context.Context, method, rawURL string, body, v any) error {
+	req, err := newJSONRequest(ctx, method, rawURL, body)
+	if err != nil {
+		return err
+	}
+	if w.token != "" {
+		req.Header.Set("Authorization", "Bearer "+w.token)
+	}
+	_, err = send(w.client, req, "webhook "+method, v)
+	return err
+}
+
+func (w *Webhook) PushAvailability(ctx context.Context, levels []Level) error {
+	if len(levels) == 0 {
+		return nil
+	}
+	return w.send(ctx, http.MethodPost, w.url, map[string]any{"levels": levels}, nil)
+}
+
+type webhookOrders struct {
+	Orders []struct {
+		ID        string      `json:"id"`
+		Reference string      `json:"reference"`
+		Cancelled bool        `json:"cancelled"`
+		CreatedAt time.Time   `json:"created_at"`
+		UpdatedAt time.Time   `json:"updated_at"`
+		Lines     []OrderLine `json:"lines"`
+	} `json:"orders"`
+}
+
+func (w *Webhook) PullOrders(ctx context.Context, since time.Time) ([]Order, error) {
+	if w.ordersURL == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(w.ordersURL)
+	if err != nil {
+		return nil, err
+	}
+	query := u.Query()
+	query.Set("updated_since", since.UTC().Format(time.RFC3339Nano))
+	u.RawQuery = query.Encode()
+
+	var page webhookOrders
+	if err := w.send(ctx, http.MethodGet, u.String(), nil, &page); err != nil {
+		return nil, err
+	}
+	orders := make([]Order, 0, len(page.Orders))
+	for _, o := range page.Orders {
+		if o.ID == "" || o.UpdatedAt.IsZero() {
+			return nil, fmt.Errorf("webhook GET: order %q lacks its id or updated_at", o.ID)
+		}
+		order := Order{ID: o.ID, Reference: o.Reference, Cancelled: o.Cancelled, CreatedAt: o.CreatedAt, UpdatedAt: o.UpdatedAt}
+		if order.CreatedAt.IsZero() {
+			order.CreatedAt = order.UpdatedAt
+		}
+		for _, line := range o.Lines {
+			order.Lines = addLine(order.Lines, line.Sku, line.Quantity)
+		}
+		orders = append(orders, order)
+	}
+	sort.SliceStable(orders, func(i, j int) bool { return orders[i].UpdatedAt.Before(orders[j].UpdatedAt) })
+	return orders, nil
+}
@@ -0,0 +1,79 @@
+//go:build integration
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"warehouse-service/fixtures"
+	"warehouse-service/handlers"
+)
+
+func TestAttributeSchemas(t *testing.T) {
+	e := requireEnv(t)
+	admin := e.Member(t, "org:admin")
+	member := e.WithToken(e.Token(t, "user_member", admin.OrgID, "org:member"), admin.OrgID)
+
+	schema := map[string]any{
+		"type":     "object",
+		"required": []string{"dock_count"},
+		"properties": map[string]any{
+			"dock_count": map[string]any{"type": "integer", "minimum": 1},
+		},
+	}
+	admin.Do(t, http.MethodPut, "/v1/admin/attribute-schemas/warehouse", schema).Expect(t, http.StatusOK)
+	admin.Do(t, http.MethodPut, "/v1/admin/attribute-schemas/pallet", schema).Expect(t, http.StatusNotFound)
+
+	var got handlers.AttributeSchemaResponse
+	admin.Do(t, http.MethodGet, "/v1/admin/attribute-schemas/warehouse", nil).Expect(t, http.StatusOK).Data(t, &got)
+	if got.EntityType != "warehouse" {
+		t.Fatalf("schema %+v", got)
+	}
+	var list []handlers.AttributeSchemaResponse
+	admin.Do(t, http.MethodGet, "/v1/admin/attribute-schemas", nil).Expect(t, http.StatusOK).Data(t, &list)
+	if len(list) != 1 {
+		t.Fatalf("schemas %+v", list)
+	}
+
+	member.Form(t, http.MethodPost, "/v1/warehouse/create", url.Values{
+		"Name": {"No docks"}, "Address": {"1 Test Rd"},
+	}).Expect(t, http.StatusBadRequest)
+	member.Form(t, http.MethodPost, "/v1/warehouse/create", url.Values{
+		"Name": {"Docks"}, "Address": {"1 Test Rd"}, "Attributes": {`{"dock_count": 4}`},
+	}).Expect(t, http.StatusOK)
+
+	var filtered []handlers.WarehouseResponse
+	member.Do(t, http.MethodGet, "/v1/warehouse/list?attr.dock_count=4", nil).Expect(t, http.StatusOK).Data(t, &filtered)
+	if len(filtered) != 1 {
+		t.Fatalf("attribute filter returned %+v", filtered)
+	}
+
+	admin.Do(t, http.MethodDelete, "/v1/admin/attribute-schemas/warehouse", nil).Expect(t, http.StatusOK)
+	admin.Do(t, http.MethodDelete, "/v1/admin/attribute-schemas/warehouse", nil).Expect(t, http.StatusNotFound)
+}
+
+func TestSeedEndpoint(t *testing.T) {
+	e := requireEnv(t)
+	admin := e.Member(t, "org:admin")
+
+	var result fixtures.Result
+	admin.Do(t, http.MethodPost, "/v1/admin/seed", map[string]any{"set": "minimal"}).
+		Expect(t, http.StatusOK).Data(t, &result)
+	if result.Warehouses != 1 || result.StorageRooms != 2 || result.StockLevels != 2 {
+		t.Fatalf("seed result %+v", result)
+	}
+	// Seeding is idempotent for the same organization
+	admin.Do(t, http.MethodPost, "/v1/admin/seed", map[string]any{"set": "minimal"}).Expect(t, http.StatusOK)
+
+	set, err := fixtures.Get("minimal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin.Do(t, http.MethodGet, fmt.Sprintf("/v1/warehouse/%d", set.Warehouses[0].ID), nil).Expect(t, http.StatusOK)
+
+	e.Member(t, "org:admin").Do(t, http.MethodPost, "/v1/admin/seed", map[string]any{"set": "minimal"}).
+		Expect(t, http.StatusConflict)
+	admin.Do(t, http.MethodPost, "/v1/admin/seed", map[string]any{"set": "nope"}).Expect(t, http.StatusBadRequest)
+}
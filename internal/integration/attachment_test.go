@@ -0,0 +1,178 @@
+//go:build integration
+
+package integration
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+	"warehouse-service/dbroute"
+	"warehouse-service/handlers"
+	"warehouse-service/objectstore"
+	"warehouse-service/quota"
+	"warehouse-service/routes"
+
+	"github.com/gin-gonic/gin"
+)
+
+// memStore is an in-memory objectstore.Store
+type memStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (s *memStore) Put(_ context.Context, key string, body io.Reader, _ int64, _, _ string) error {
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = b
+	return nil
+}
+
+func (s *memStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+	return nil
+}
+
+func (s *memStore) PresignGet(_ context.Context, key, _ string, ttl time.Duration) (string, error) {
+	return fmt.Sprintf("https://store.test/%s?expires=%d", key, int(ttl.Seconds())), nil
+}
+
+func (s *memStore) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.objects)
+}
+
+// rejectScanner rejects files named virus.pdf
+type rejectScanner struct{}
+
+func (rejectScanner) Scan(_ context.Context, fileName string, _ io.Reader) error {
+	if fileName == "virus.pdf" {
+		return objectstore.ErrRejected
+	}
+	return nil
+}
+
+// withAttachments returns an environment whose warehouse and attachment
+// routes keep files in store
+func (e *Env) withAttachments(store objectstore.Store) *Env {
+	router := gin.New()
+	r := routes.NewRoute(dbroute.New(e.DB, nil, time.Second), nil, nil, nil, nil, nil, quota.Limits{}, handlers.Attachments{
+		Store:        store,
+		Scanner:      rejectScanner{},
+		MaxSize:      1 << 10,
+		ContentTypes: []string{"application/pdf"},
+		URLTTL:       5 * time.Minute,
+	}, handlers.Services{}, nil, nil)
+	r.AddWarehouseRoutes(router)
+	r.AddV2Routes(router)
+	r.AddAttachmentRoutes(router)
+	attached := *e
+	attached.handler = router
+	return &attached
+}
+
+// upload posts content as the file of a multipart attachment upload
+func (c *Client) upload(t testing.TB, path, kind, fileName string, content []byte) Response {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	if err := form.WriteField("kind", kind); err != nil {
+		t.Fatal(err)
+	}
+	part, err := form.CreateFormFile("file", fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(content)
+	form.Close()
+
+	req := httptest.NewRequest(http.MethodPost, path, &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	rec := httptest.NewRecorder()
+	c.env.handler.ServeHTTP(rec, req)
+	return Response{rec}
+}
+
+func TestAttachments(t *testing.T) {
+	e := requireEnv(t)
+	store := &memStore{objects: map[string][]byte{}}
+	c := e.withAttachments(store).Member(t, "org:member")
+	warehouse := createWarehouse(t, c, "Documented")
+	path := fmt.Sprintf("/v1/warehouse/%d/attachments", warehouse.ID)
+	pdf := []byte("%PDF-1.7\nfloor plan")
+
+	var created handlers.AttachmentResponse
+	c.upload(t, path, "floor_plan", `C:\plans\ground floor.pdf`, pdf).Expect(t, http.StatusCreated).Data(t, &created)
+	if created.FileName != "ground floor.pdf" || created.ContentType != "application/pdf" || created.SizeBytes != int64(len(pdf)) {
+		t.Fatalf("created %+v", created)
+	}
+	if store.len() != 1 {
+		t.Fatalf("%d objects stored, want 1", store.len())
+	}
+
+	t.Run("validation", func(t *testing.T) {
+		c.upload(t, path, "photo", "plan.pdf", pdf).Expect(t, http.StatusBadRequest)
+		c.upload(t, path, "other", "notes.txt", []byte("plain text")).Expect(t, http.StatusUnsupportedMediaType)
+		c.upload(t, path, "other", "big.pdf", append([]byte("%PDF-"), make([]byte, 2<<10)...)).Expect(t, http.StatusRequestEntityTooLarge)
+		c.upload(t, path, "other", "virus.pdf", pdf).Expect(t, http.StatusUnprocessableEntity)
+		c.upload(t, "/v1/warehouse/999999999/attachments", "other", "plan.pdf", pdf).Expect(t, http.StatusNotFound)
+		if store.len() != 1 {
+			t.Fatalf("%d objects stored after rejected uploads, want 1", store.len())
+		}
+	})
+
+	t.Run("list and download", func(t *testing.T) {
+		var list []handlers.AttachmentResponse
+		c.Do(t, http.MethodGet, path+"?kind=floor_plan", nil).Expect(t, http.StatusOK).Data(t, &list)
+		if len(list) != 1 || list[0].ID != created.ID {
+			t.Fatalf("list %+v", list)
+		}
+		c.Do(t, http.MethodGet, path+"?kind=lease", nil).Expect(t, http.StatusOK).Data(t, &list)
+		if len(list) != 0 {
+			t.Fatalf("lease list %+v", list)
+		}
+
+		var got handlers.AttachmentDownloadResponse
+		c.Do(t, http.MethodGet, fmt.Sprintf("%s/%d", path, created.ID), nil).Expect(t, http.StatusOK).Data(t, &got)
+		if got.DownloadURL == "" || got.ExpiresAt.Before(time.Now()) {
+			t.Fatalf("download %+v", got)
+		}
+	})
+
+	t.Run("tenant isolation", func(t *testing.T) {
+		other := e.withAttachments(store).Member(t, "org:member")
+		other.Do(t, http.MethodGet, fmt.Sprintf("%s/%d", path, created.ID), nil).Expect(t, http.StatusNotFound)
+		other.Do(t, http.MethodDelete, fmt.Sprintf("%s/%d", path, created.ID), nil).Expect(t, http.StatusNotFound)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		c.Do(t, http.MethodDelete, fmt.Sprintf("%s/%d", path, created.ID), nil).Expect(t, http.StatusOK)
+		c.Do(t, http.MethodGet, fmt.Sprintf("%s/%d", path, created.ID), nil).Expect(t, http.StatusNotFound)
+		if store.len() != 0 {
+			t.Fatalf("%d objects left, want 0", store.len())
+		}
+	})
+
+	t.Run("warehouse delete removes attachments", func(t *testing.T) {
+		c.upload(t, path, "lease", "lease.pdf", pdf).Expect(t, http.StatusCreated)
+		c.Do(t, http.MethodDelete, fmt.Sprintf("/v1/warehouse/%d", warehouse.ID), nil).Expect(t, http.StatusOK)
+		if store.len() != 0 {
+			t.Fatalf("%d objects left after warehouse delete, want 0", store.len())
+		}
+	})
+}
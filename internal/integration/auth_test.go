@@ -0,0 +1,128 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"warehouse-service/handlers"
+	"warehouse-service/middlewares"
+	models "warehouse-service/models/sqlc"
+)
+
+func TestAuthentication(t *testing.T) {
+	e := requireEnv(t)
+
+	t.Run("missing header", func(t *testing.T) {
+		e.Anonymous().Do(t, http.MethodGet, "/v1/warehouse/list", nil).Expect(t, http.StatusUnauthorized)
+	})
+	t.Run("invalid token", func(t *testing.T) {
+		e.WithToken("not-a-jwt", "").Do(t, http.MethodGet, "/v1/warehouse/list", nil).Expect(t, http.StatusUnauthorized)
+	})
+	t.Run("no active organization", func(t *testing.T) {
+		token := e.Token(t, "user_without_org", "", "")
+		e.WithToken(token, "").Do(t, http.MethodGet, "/v1/warehouse/list", nil).Expect(t, http.StatusForbidden)
+	})
+	t.Run("admin routes need the admin role", func(t *testing.T) {
+		e.Member(t, "org:member").Do(t, http.MethodGet, "/v1/admin/scheduler", nil).Expect(t, http.StatusForbidden)
+		e.Member(t, "org:admin").Do(t, http.MethodGet, "/v1/admin/scheduler", nil).Expect(t, http.StatusOK)
+	})
+}
+
+func TestServiceAccounts(t *testing.T) {
+	e := requireEnv(t)
+	owner := e.Member(t, "org:member")
+	theirs := createWarehouse(t, owner, "Someone Else's")
+	path := fmt.Sprintf("/v1/warehouse/%d", theirs.ID)
+
+	// Each tenant's admins grant organization roles, so none makes a
+	// service account
+	member := e.Member(t, "org:service_account")
+	member.Do(t, http.MethodGet, path, nil).Expect(t, http.StatusNotFound)
+	member.Header = http.Header{middlewares.TenantHeader: {owner.OrgID}}
+	member.Do(t, http.MethodGet, path, nil).Expect(t, http.StatusNotFound)
+	member.Do(t, http.MethodDelete, path, nil).Expect(t, http.StatusNotFound)
+	var listed []handlers.WarehouseResponse
+	member.Do(t, http.MethodGet, "/v1/warehouse/list", nil).Expect(t, http.StatusOK).Data(t, &listed)
+	if len(listed) != 0 {
+		t.Fatalf("listed %+v of another tenant", listed)
+	}
+
+	// A configured service account acts for the tenant in the header
+	orgID := NewOrg()
+	service := e.WithToken(e.Token(t, serviceAccountUserID, orgID, "org:member"), orgID)
+	service.Header = http.Header{middlewares.TenantHeader: {owner.OrgID}}
+	service.Do(t, http.MethodGet, path, nil).Expect(t, http.StatusOK)
+}
+
+func TestAPIKeyAuthentication(t *testing.T) {
+	e := requireEnv(t)
+	orgID := NewOrg()
+
+	key, prefix, secretHash, err := middlewares.GenerateAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = models.New(e.DB).CreateAPIKey(context.Background(), models.CreateAPIKeyParams{
+		OrgID:      orgID,
+		Name:       "integration",
+		Prefix:     prefix,
+		SecretHash: secretHash,
+		CreatedBy:  "test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := e.WithToken(key, orgID)
+	c.Do(t, http.MethodGet, "/v1/warehouse/list", nil).Expect(t, http.StatusOK)
+	// API keys carry no organization role
+	c.Do(t, http.MethodGet, "/v1/admin/scheduler", nil).Expect(t, http.StatusForbidden)
+
+	e.WithToken(key+"x", orgID).Do(t, http.MethodGet, "/v1/warehouse/list", nil).Expect(t, http.StatusUnauthorized)
+	e.WithToken(middlewares.APIKeyPrefix+"000000000000_secret", orgID).Do(t, http.MethodGet, "/v1/warehouse/list", nil).Expect(t, http.StatusUnauthorized)
+}
+
+func TestAssignUnownedRows(t *testing.T) {
+	e := requireEnv(t)
+	ctx := context.Background()
+	var warehouseID int64
+	if err := e.DB.QueryRow(ctx,
+		`INSERT INTO warehouse (name, address, ward, district, city, country, org_id, slug)
+		VALUES ('Before Tenancy', 'Test Rd', '4', '2', 'Test', 'test', '', 'before-tenancy') RETURNING id`).
+		Scan(&warehouseID); err != nil {
+		t.Fatalf("insert warehouse: %v", err)
+	}
+	roomID := e.StorageRoom(t, "", warehouseID, "1", "ambient")
+
+	// Nobody sees rows without an organization until they are assigned
+	c := e.Member(t, "org:member")
+	path := fmt.Sprintf("/v1/warehouse/%d", warehouseID)
+	c.Do(t, http.MethodGet, path, nil).Expect(t, http.StatusNotFound)
+
+	// Other tests may leave rows without an organization, so only the ones
+	// inserted here are checked
+	q := models.New(e.DB)
+	if _, err := q.AssignUnownedWarehouses(ctx, c.OrgID); err != nil {
+		t.Fatalf("assign warehouses: %v", err)
+	}
+	if _, err := q.AssignUnownedStorageRooms(ctx); err != nil {
+		t.Fatalf("assign storage rooms: %v", err)
+	}
+	var roomOrg string
+	if err := e.DB.QueryRow(ctx, `SELECT org_id FROM storage_room WHERE id = $1`, roomID).Scan(&roomOrg); err != nil {
+		t.Fatalf("read storage room: %v", err)
+	}
+	if roomOrg != c.OrgID {
+		t.Fatalf("storage room org_id %q, want %q", roomOrg, c.OrgID)
+	}
+	c.Do(t, http.MethodGet, path, nil).Expect(t, http.StatusOK)
+	var rooms []handlers.StorageRoomResponse
+	c.Do(t, http.MethodGet, fmt.Sprintf("/v1/storageroom/list?warehouse_id=%d", warehouseID), nil).
+		Expect(t, http.StatusOK).Data(t, &rooms)
+	if len(rooms) != 1 || rooms[0].ID != roomID {
+		t.Fatalf("rooms %+v, want room %d", rooms, roomID)
+	}
+}
@@ -0,0 +1,146 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"warehouse-service/changefeed"
+	"warehouse-service/handlers"
+
+	"golang.org/x/net/websocket"
+)
+
+func TestChangeFeed(t *testing.T) {
+	e := requireEnv(t)
+	feed := changefeed.New(e.DB, nil)
+	feed.Start(context.Background())
+	defer feed.Stop()
+	sub := feed.Subscribe(100)
+	defer sub.Close()
+
+	// The feed starts following asynchronously, keep writing until it sees
+	// one of the warehouses
+	c := e.Member(t, "org:member")
+	deadline := time.After(30 * time.Second)
+	for {
+		warehouse := createWarehouse(t, c, NewOrg())
+		select {
+		case event, ok := <-sub.C:
+			if !ok {
+				t.Fatal("subscription closed")
+			}
+			if event.OrgID != c.OrgID || event.Entity != changefeed.EntityWarehouse || event.Operation != "insert" {
+				t.Fatalf("event %+v", event)
+			}
+			if event.EntityID > warehouse.ID {
+				t.Fatalf("event for warehouse %d, last created %d", event.EntityID, warehouse.ID)
+			}
+			return
+		case <-time.After(time.Second):
+		case <-deadline:
+			t.Fatal("no change event within 30s")
+		}
+	}
+}
+
+// stream reads /v1/events/stream for a second, long enough for the missed
+// events; the harness does not start the feed so nothing live arrives
+func stream(t *testing.T, c *Client, query, lastEventID string) Response {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	req := httptest.NewRequest(http.MethodGet, "/v1/events/stream"+query, nil).WithContext(ctx)
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	rec := httptest.NewRecorder()
+	c.env.handler.ServeHTTP(rec, req)
+	return Response{rec}
+}
+
+func TestStreamEvents(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:member")
+	first := createWarehouse(t, c, "Stream 1")
+	second := createWarehouse(t, c, "Stream 2")
+	// Another tenant's change must not be streamed
+	createWarehouse(t, e.Member(t, "org:member"), "Stream other")
+
+	var firstEvent int64
+	if err := e.DB.QueryRow(context.Background(),
+		`SELECT id FROM change_event WHERE org_id = $1 AND entity = 'warehouse' AND entity_id = $2`,
+		c.OrgID, first.ID).Scan(&firstEvent); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("resume", func(t *testing.T) {
+		body := stream(t, c, "?types=warehouse.insert", strconv.FormatInt(firstEvent, 10)).Expect(t, http.StatusOK).Body.String()
+		if strings.Count(body, "event: warehouse.insert") != 1 {
+			t.Fatalf("want exactly the second warehouse, got %q", body)
+		}
+		if !strings.Contains(body, fmt.Sprintf(`"entity_id":%d`, second.ID)) {
+			t.Fatalf("second warehouse missing: %q", body)
+		}
+	})
+	t.Run("filtered out", func(t *testing.T) {
+		body := stream(t, c, "?types=stock_level", strconv.FormatInt(firstEvent, 10)).Expect(t, http.StatusOK).Body.String()
+		if strings.Contains(body, "event: warehouse") {
+			t.Fatalf("unexpected warehouse event: %q", body)
+		}
+	})
+	t.Run("pruned", func(t *testing.T) {
+		body := stream(t, c, "", "9223372036854775807").Expect(t, http.StatusOK).Body.String()
+		if !strings.Contains(body, "event: reset") {
+			t.Fatalf("want a reset event, got %q", body)
+		}
+	})
+	t.Run("unknown type", func(t *testing.T) {
+		stream(t, c, "?types=pallet", "").Expect(t, http.StatusBadRequest)
+	})
+}
+
+func TestStockSocket(t *testing.T) {
+	e := requireEnv(t)
+	srv := httptest.NewServer(e.handler)
+	defer srv.Close()
+	c := e.Member(t, "org:member")
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws"
+
+	if _, err := websocket.Dial(wsURL, "", srv.URL); err == nil {
+		t.Fatal("connected without a token")
+	}
+
+	conn, err := websocket.Dial(wsURL+"?access_token="+c.token, "", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := websocket.JSON.Send(conn, handlers.SocketRequest{Action: "subscribe", WarehouseIDs: []handlers.WarehouseRef{"1"}, Skus: []string{"SKU-WS"}}); err != nil {
+		t.Fatal(err)
+	}
+	var msg handlers.SocketMessage
+	if err := websocket.JSON.Receive(conn, &msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Type != "subscriptions" || len(msg.WarehouseIDs) != 1 || len(msg.Skus) != 1 {
+		t.Fatalf("message %+v", msg)
+	}
+
+	if err := websocket.JSON.Send(conn, handlers.SocketRequest{Action: "watch"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := websocket.JSON.Receive(conn, &msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Type != "error" {
+		t.Fatalf("message %+v, want an error", msg)
+	}
+}
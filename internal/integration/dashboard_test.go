@@ -0,0 +1,47 @@
+//go:build integration
+
+package integration
+
+import (
+	"net/http"
+	"testing"
+	"warehouse-service/handlers"
+)
+
+func TestDashboardStats(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:member")
+	w := createWarehouse(t, c, "Dashboard")
+	room := e.StorageRoom(t, c.OrgID, w.ID, "D1", "ambient")
+	receiveStock(t, c, w.ID, room, "SKU-D1", 7)
+
+	var before handlers.DashboardStatsResponse
+	c.Do(t, http.MethodGet, "/v1/reports/dashboard", nil).Expect(t, http.StatusOK).Data(t, &before)
+	if before.RefreshedAt == nil {
+		t.Fatal("missing refreshed at")
+	}
+
+	var refresh handlers.ViewRefreshResponse
+	e.Operator(t).Do(t, http.MethodPost, "/admin/stats/refresh", nil).Expect(t, http.StatusOK).Data(t, &refresh)
+	if refresh.Name != "dashboard_stats" || refresh.RefreshedAt == nil || !refresh.RefreshedAt.After(*before.RefreshedAt) {
+		t.Fatalf("refresh %+v, before %v", refresh, before.RefreshedAt)
+	}
+
+	var stats handlers.DashboardStatsResponse
+	c.Do(t, http.MethodGet, "/v1/reports/dashboard", nil).Expect(t, http.StatusOK).Data(t, &stats)
+	if stats.Warehouses != 1 || stats.ActiveWarehouses != 1 || stats.StorageRooms != 1 || stats.Skus != 1 ||
+		stats.OnHandQuantity != 7 || stats.MovementsLast30Days != 1 {
+		t.Fatalf("stats %+v", stats)
+	}
+	if !stats.RefreshedAt.Equal(*refresh.RefreshedAt) {
+		t.Fatalf("refreshed at %v, want %v", stats.RefreshedAt, refresh.RefreshedAt)
+	}
+
+	var other handlers.DashboardStatsResponse
+	e.Member(t, "org:member").Do(t, http.MethodGet, "/v1/reports/dashboard", nil).Expect(t, http.StatusOK).Data(t, &other)
+	if other.Warehouses != 0 || other.RefreshedAt == nil {
+		t.Fatalf("other tenant sees %+v", other)
+	}
+
+	c.Do(t, http.MethodPost, "/admin/stats/refresh", nil).Expect(t, http.StatusForbidden)
+}
@@ -0,0 +1,100 @@
+//go:build integration
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"warehouse-service/handlers"
+)
+
+const ediASN = `ISA*00*          *00*          *ZZ*ACMESUPPLY     *ZZ*WAREHOUSE      *261015*0930*U*00401*000000017*0*P*>~
+GS*SH*ACMESUPPLY*WAREHOUSE*20261015*0930*17*X*004010~
+ST*856*0001~
+BSN*00*SHIP-9001*20261015*0930~
+HL*1**S~
+LIN**SK*SKU-EDI-A~
+SN1**12*EA~
+LIN**VP*SKU-EDI-B~
+SN1**3~
+SE*8*0001~
+GE*1*17~
+IEA*1*000000017~
+`
+
+func TestEDI(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:member")
+	admin := e.WithToken(e.Token(t, "user_admin_"+c.OrgID, c.OrgID, "org:admin"), c.OrgID)
+	warehouse := createWarehouse(t, c, "EDI")
+	room := e.StorageRoom(t, c.OrgID, warehouse.ID, "E1", "ambient")
+
+	var supplier handlers.PartnerResponse
+	c.Do(t, http.MethodPost, "/v1/suppliers", map[string]any{
+		"name": "Acme Supply", "edi_id": "ACMESUPPLY",
+	}).Expect(t, http.StatusCreated).Data(t, &supplier)
+	if supplier.EdiQualifier != "ZZ" || supplier.EdiID != "ACMESUPPLY" {
+		t.Fatalf("supplier %+v", supplier)
+	}
+	c.Do(t, http.MethodPost, "/v1/suppliers", map[string]any{
+		"name": "Acme Twin", "edi_id": "ACMESUPPLY",
+	}).Expect(t, http.StatusConflict)
+	c.Do(t, http.MethodPost, "/v1/suppliers", map[string]any{
+		"name": "No Host Key", "edi_id": "NOKEY", "sftp_url": "sftp://edi@partner.test/in",
+	}).Expect(t, http.StatusBadRequest)
+
+	t.Run("import ASN", func(t *testing.T) {
+		path := fmt.Sprintf("/v1/edi/asn?warehouse_id=%d", warehouse.ID)
+		var imported struct {
+			Documents []handlers.EdiDocumentResponse `json:"documents"`
+			Receipts  []handlers.ReceiptResponse     `json:"receipts"`
+		}
+		c.Do(t, http.MethodPost, path, strings.NewReader(ediASN)).Expect(t, http.StatusCreated).Data(t, &imported)
+		if len(imported.Receipts) != 1 || imported.Receipts[0].Reference != "SHIP-9001" {
+			t.Fatalf("receipts %+v", imported.Receipts)
+		}
+		if doc := imported.Documents[0]; doc.DocumentType != "856" || doc.PartnerID == nil || *doc.PartnerID != supplier.ID {
+			t.Fatalf("document %+v", doc)
+		}
+
+		var receipt struct {
+			Receipt handlers.ReceiptResponse       `json:"receipt"`
+			Lines   []handlers.ReceiptLineResponse `json:"lines"`
+		}
+		c.Do(t, http.MethodGet, fmt.Sprintf("/v1/receipts/%d", imported.Receipts[0].ID), nil).Expect(t, http.StatusOK).Data(t, &receipt)
+		if len(receipt.Lines) != 2 || receipt.Lines[0].ExpectedQuantity+receipt.Lines[1].ExpectedQuantity != 15 {
+			t.Fatalf("receipt lines %+v", receipt.Lines)
+		}
+
+		// A resent interchange is not imported twice
+		c.Do(t, http.MethodPost, path, strings.NewReader(ediASN)).Expect(t, http.StatusConflict)
+		c.Do(t, http.MethodPost, path, strings.NewReader("ST*856*0001~")).Expect(t, http.StatusBadRequest)
+		unknown := strings.ReplaceAll(ediASN, "ACMESUPPLY     ", "SOMEONEELSE    ")
+		c.Do(t, http.MethodPost, path, strings.NewReader(unknown)).Expect(t, http.StatusNotFound)
+	})
+
+	t.Run("export inventory advice", func(t *testing.T) {
+		receiveStock(t, c, warehouse.ID, room, "SKU-EDI-A", 5)
+		path := fmt.Sprintf("/v1/edi/inventory-advice?supplier_id=%d", supplier.ID)
+		c.Do(t, http.MethodGet, path, nil).Expect(t, http.StatusConflict)
+
+		admin.Do(t, http.MethodPut, "/v1/admin/settings", map[string]any{
+			"valuation_method": "fifo", "edi_id": "WAREHOUSE",
+		}).Expect(t, http.StatusOK)
+		res := c.Do(t, http.MethodGet, path, nil).Expect(t, http.StatusOK)
+		body := res.Body.String()
+		for _, want := range []string{"*ZZ*WAREHOUSE      *ZZ*ACMESUPPLY     *", "ST*846*0001~", "LIN*1*SK*SKU-EDI-A~", "QTY*17*5*EA~", "CTT*1~"} {
+			if !strings.Contains(body, want) {
+				t.Errorf("846 lacks %q:\n%s", want, body)
+			}
+		}
+
+		var documents []handlers.EdiDocumentResponse
+		c.Do(t, http.MethodGet, "/v1/edi/documents", nil).Expect(t, http.StatusOK).Data(t, &documents)
+		if len(documents) != 2 || documents[0].DocumentType != "846" || documents[0].Status != "sent" {
+			t.Fatalf("documents %+v", documents)
+		}
+	})
+}
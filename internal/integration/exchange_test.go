@@ -0,0 +1,89 @@
+//go:build integration
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"warehouse-service/handlers"
+)
+
+func TestFileExchange(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:member")
+	admin := e.WithToken(e.Token(t, "user_admin_"+c.OrgID, c.OrgID, "org:admin"), c.OrgID)
+	warehouse := createWarehouse(t, c, "Exchange")
+
+	c.Do(t, http.MethodGet, "/v1/admin/exchanges", nil).Expect(t, http.StatusForbidden)
+
+	var ex handlers.FileExchangeResponse
+	admin.Do(t, http.MethodPost, "/v1/admin/exchanges", map[string]any{
+		"name":           "Acme",
+		"inbound_url":    "s3://acme-exchange/outbox",
+		"outbound_url":   "s3://acme-exchange/inbox",
+		"warehouse_id":   warehouse.ID,
+		"extract_format": "csv",
+	}).Expect(t, http.StatusCreated).Data(t, &ex)
+	if !ex.Enabled || ex.ExtractFormat != "csv" || ex.SupplierID != nil {
+		t.Fatalf("exchange %+v", ex)
+	}
+	admin.Do(t, http.MethodPost, "/v1/admin/exchanges", map[string]any{
+		"name": "Acme", "inbound_url": "s3://acme-exchange/outbox", "warehouse_id": warehouse.ID,
+	}).Expect(t, http.StatusConflict)
+
+	for _, body := range []map[string]any{
+		{"name": "No folder", "warehouse_id": warehouse.ID},
+		{"name": "No format", "outbound_url": "s3://acme-exchange/inbox", "warehouse_id": warehouse.ID},
+		{"name": "No supplier", "outbound_url": "s3://acme-exchange/inbox", "extract_format": "846", "warehouse_id": warehouse.ID},
+		{"name": "FTP", "inbound_url": "ftp://acme.test/outbox", "warehouse_id": warehouse.ID},
+		{"name": "No host key", "inbound_url": "sftp://edi@acme.test/outbox", "warehouse_id": warehouse.ID},
+		{"name": "Two servers", "inbound_url": "sftp://edi@a.acme.test/outbox", "outbound_url": "sftp://edi@b.acme.test/inbox",
+			"extract_format": "csv", "host_key": "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl",
+			"warehouse_id": warehouse.ID},
+	} {
+		admin.Do(t, http.MethodPost, "/v1/admin/exchanges", body).Expect(t, http.StatusBadRequest)
+	}
+	admin.Do(t, http.MethodPost, "/v1/admin/exchanges", map[string]any{
+		"name": "Elsewhere", "inbound_url": "s3://acme-exchange/outbox", "warehouse_id": 999999,
+	}).Expect(t, http.StatusNotFound)
+
+	path := fmt.Sprintf("/v1/admin/exchanges/%d", ex.ID)
+	admin.Do(t, http.MethodPut, path, map[string]any{
+		"name":         "Acme",
+		"inbound_url":  "sftp://edi@sftp.acme.test/outbox",
+		"host_key":     "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl",
+		"warehouse_id": warehouse.ID,
+		"enabled":      false,
+	}).Expect(t, http.StatusOK).Data(t, &ex)
+	if ex.Enabled || ex.OutboundURL != "" || ex.ExtractFormat != "" {
+		t.Fatalf("updated exchange %+v", ex)
+	}
+
+	var job handlers.JobResponse
+	res := admin.Do(t, http.MethodPost, path+"/run", nil).Expect(t, http.StatusAccepted)
+	res.Data(t, &job)
+	if job.Kind != handlers.JobKindRunFileExchange || job.Status != "queued" {
+		t.Fatalf("run job %+v", job)
+	}
+	if got := res.Header().Get("Location"); got != fmt.Sprintf("/v1/jobs/%d", job.ID) {
+		t.Fatalf("location %q", got)
+	}
+
+	var runs []handlers.FileExchangeRunResponse
+	admin.Do(t, http.MethodGet, path+"/runs", nil).Expect(t, http.StatusOK).Data(t, &runs)
+	var files []handlers.FileExchangeFileResponse
+	admin.Do(t, http.MethodGet, path+"/files", nil).Expect(t, http.StatusOK).Data(t, &files)
+	if len(files) != 0 {
+		t.Fatalf("files %+v", files)
+	}
+	admin.Do(t, http.MethodPost, path+"/files/999999/reprocess", nil).Expect(t, http.StatusNotFound)
+
+	// Other organizations don't see the exchange
+	other := e.Member(t, "org:admin")
+	other.Do(t, http.MethodGet, path, nil).Expect(t, http.StatusNotFound)
+	other.Do(t, http.MethodPost, path+"/run", nil).Expect(t, http.StatusNotFound)
+
+	admin.Do(t, http.MethodDelete, path, nil).Expect(t, http.StatusOK)
+	admin.Do(t, http.MethodDelete, path, nil).Expect(t, http.StatusNotFound)
+}
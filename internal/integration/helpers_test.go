@@ -0,0 +1,95 @@
+//go:build integration
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+	"warehouse-service/handlers"
+)
+
+// createWarehouse creates a warehouse through the v2 API
+func createWarehouse(t *testing.T, c *Client, name string) handlers.WarehouseV2 {
+	t.Helper()
+	var warehouse handlers.WarehouseV2
+	c.Do(t, http.MethodPost, "/v2/warehouses", map[string]any{
+		"name":      name,
+		"address":   "1 Test Rd",
+		"city":      "Test",
+		"country":   "VN",
+		"latitude":  10.7769,
+		"longitude": 106.7009,
+	}).Expect(t, http.StatusCreated).Data(t, &warehouse)
+	return warehouse
+}
+
+// receiveStock puts quantity of sku into a room through a receipt
+func receiveStock(t *testing.T, c *Client, warehouseID int64, roomID int32, sku string, quantity int32) {
+	t.Helper()
+	receiveExpiringStock(t, c, warehouseID, roomID, sku, quantity, time.Time{})
+}
+
+// receiveExpiringStock puts quantity of sku expiring at expiresAt into a
+// room through a receipt, stock that doesn't expire for a zero expiresAt
+func receiveExpiringStock(t *testing.T, c *Client, warehouseID int64, roomID int32, sku string, quantity int32, expiresAt time.Time) {
+	t.Helper()
+	var created struct {
+		Receipt handlers.ReceiptResponse       `json:"receipt"`
+		Lines   []handlers.ReceiptLineResponse `json:"lines"`
+	}
+	c.Do(t, http.MethodPost, "/v1/receipts", map[string]any{
+		"warehouse_id": warehouseID,
+		"lines":        []map[string]any{{"sku": sku, "expected_quantity": quantity}},
+	}).Expect(t, http.StatusCreated).Data(t, &created)
+
+	line := map[string]any{
+		"line_id":         created.Lines[0].ID,
+		"storage_room_id": roomID,
+		"quantity":        quantity,
+	}
+	if !expiresAt.IsZero() {
+		line["expires_at"] = expiresAt
+	}
+	var received struct {
+		Lines []handlers.ReceiptLineResponse `json:"lines"`
+	}
+	c.Do(t, http.MethodPost, fmt.Sprintf("/v1/receipts/%d/receive", created.Receipt.ID), map[string]any{
+		"lines": []map[string]any{line},
+	}).Expect(t, http.StatusOK).Data(t, &received)
+	if got := received.Lines[0].ExpiresAt; !expiresAt.IsZero() && (got == nil || !got.Equal(expiresAt)) {
+		t.Fatalf("receipt line expires at %v, want %v", got, expiresAt)
+	}
+}
+
+// stockLevelOf returns the stock level of sku in a room, failing the test
+// when the room never held it
+func stockLevelOf(t *testing.T, c *Client, roomID int32, sku string) handlers.StockLevelResponse {
+	t.Helper()
+	var levels []handlers.StockLevelResponse
+	c.Do(t, http.MethodGet, fmt.Sprintf("/v1/stock?sku=%s", sku), nil).
+		Expect(t, http.StatusOK).Data(t, &levels)
+	for _, level := range levels {
+		if level.StorageRoomID == roomID && level.Sku == sku {
+			return level
+		}
+	}
+	t.Fatalf("no stock level of %s in storage room %d", sku, roomID)
+	return handlers.StockLevelResponse{}
+}
+
+// stockOf returns the on hand quantity of sku in a room, zero when the
+// room never held it
+func stockOf(t *testing.T, c *Client, roomID int32, sku string) int32 {
+	t.Helper()
+	var levels []handlers.StockLevelResponse
+	c.Do(t, http.MethodGet, fmt.Sprintf("/v1/stock?sku=%s", sku), nil).
+		Expect(t, http.StatusOK).Data(t, &levels)
+	for _, level := range levels {
+		if level.StorageRoomID == roomID && level.Sku == sku {
+			return level.Quantity
+		}
+	}
+	return 0
+}
@@ -0,0 +1,85 @@
+//go:build integration
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+	"warehouse-service/handlers"
+)
+
+func TestHistory(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:member")
+	w := createWarehouse(t, c, "Before")
+	room := e.StorageRoom(t, c.OrgID, w.ID, "H1", "ambient")
+	c.Do(t, http.MethodPatch, fmt.Sprintf("/v1/warehouse/%d", w.ID), map[string]any{"name": "After"}).Expect(t, http.StatusOK)
+	c.Do(t, http.MethodPatch, fmt.Sprintf("/v1/storageroom/%d", room), map[string]any{"zone_type": "chilled"}).Expect(t, http.StatusOK)
+
+	var versions []handlers.WarehouseVersionResponse
+	c.Do(t, http.MethodGet, fmt.Sprintf("/v1/warehouse/%d/history", w.ID), nil).Expect(t, http.StatusOK).Data(t, &versions)
+	if len(versions) != 2 || versions[0].Operation != "update" || versions[0].Warehouse.Name != "After" ||
+		versions[1].Operation != "insert" || versions[1].Warehouse.Name != "Before" {
+		t.Fatalf("versions %+v", versions)
+	}
+	if versions[0].ValidTo != nil || versions[1].ValidTo == nil || !versions[1].ValidTo.Equal(*versions[0].ValidFrom) {
+		t.Fatalf("ranges %v - %v, %v - %v", versions[1].ValidFrom, versions[1].ValidTo, versions[0].ValidFrom, versions[0].ValidTo)
+	}
+
+	asOf := func(at time.Time) string {
+		return url.QueryEscape(at.Format(time.RFC3339Nano))
+	}
+	var warehouse handlers.WarehouseResponse
+	c.Do(t, http.MethodGet, fmt.Sprintf("/v1/warehouse/%d?as_of=%s", w.ID, asOf(*versions[1].ValidFrom)), nil).Expect(t, http.StatusOK).Data(t, &warehouse)
+	if warehouse.Name != "Before" {
+		t.Fatalf("as of creation %+v", warehouse)
+	}
+	c.Do(t, http.MethodGet, fmt.Sprintf("/v1/warehouse/%d?as_of=2000-01-01", w.ID), nil).Expect(t, http.StatusNotFound)
+	c.Do(t, http.MethodGet, fmt.Sprintf("/v1/warehouse/%d?as_of=yesterday", w.ID), nil).Expect(t, http.StatusBadRequest)
+
+	var rooms []handlers.StorageRoomVersionResponse
+	c.Do(t, http.MethodGet, fmt.Sprintf("/v1/storageroom/%d/history?as_of=%s", room, asOf(*versions[1].ValidTo)), nil).Expect(t, http.StatusOK).Data(t, &rooms)
+	if len(rooms) != 1 || rooms[0].StorageRoom.ZoneType != "ambient" {
+		t.Fatalf("room as of warehouse update %+v", rooms)
+	}
+
+	t.Run("revert", func(t *testing.T) {
+		var reverted handlers.WarehouseResponse
+		c.Do(t, http.MethodPost, fmt.Sprintf("/v1/warehouse/%d/revert?to_version=%d", w.ID, versions[1].Version), nil).Expect(t, http.StatusOK).Data(t, &reverted)
+		if reverted.Name != "Before" || reverted.Status != "active" {
+			t.Fatalf("reverted %+v", reverted)
+		}
+		c.Do(t, http.MethodGet, fmt.Sprintf("/v1/warehouse/%d/history?limit=1", w.ID), nil).Expect(t, http.StatusOK).Data(t, &versions)
+		if versions[0].Operation != "update" || versions[0].Warehouse.Name != "Before" {
+			t.Fatalf("latest version %+v", versions[0])
+		}
+
+		var audit []handlers.AuditLogResponse
+		c.Do(t, http.MethodGet, "/v1/audit?entity_type=warehouse", nil).Expect(t, http.StatusOK).Data(t, &audit)
+		if len(audit) == 0 || audit[0].Action != "revert" || audit[0].EntityID != w.ID {
+			t.Fatalf("audit %+v", audit)
+		}
+
+		c.Do(t, http.MethodPost, fmt.Sprintf("/v1/warehouse/%d/revert?to_version=%d", w.ID, rooms[0].Version), nil).Expect(t, http.StatusNotFound)
+		c.Do(t, http.MethodPost, fmt.Sprintf("/v1/warehouse/%d/revert", w.ID), nil).Expect(t, http.StatusBadRequest)
+		e.Member(t, "org:member").Do(t, http.MethodPost, fmt.Sprintf("/v1/warehouse/%d/revert?to_version=%d", w.ID, versions[0].Version), nil).Expect(t, http.StatusNotFound)
+	})
+
+	t.Run("deleted", func(t *testing.T) {
+		c.Do(t, http.MethodDelete, fmt.Sprintf("/v1/warehouse/%d?cascade=true", w.ID), nil).Expect(t, http.StatusOK)
+		c.Do(t, http.MethodGet, fmt.Sprintf("/v1/warehouse/%d/history", w.ID), nil).Expect(t, http.StatusOK).Data(t, &versions)
+		if len(versions) != 4 || versions[0].Operation != "delete" || versions[0].Warehouse.Name != "Before" {
+			t.Fatalf("versions %+v", versions)
+		}
+		c.Do(t, http.MethodGet, fmt.Sprintf("/v1/warehouse/%d?as_of=%s", w.ID, asOf(time.Now())), nil).Expect(t, http.StatusNotFound)
+		c.Do(t, http.MethodGet, fmt.Sprintf("/v1/warehouse/%d?as_of=%s", w.ID, asOf(*versions[1].ValidFrom)), nil).Expect(t, http.StatusOK)
+		c.Do(t, http.MethodPost, fmt.Sprintf("/v1/warehouse/%d/revert?to_version=%d", w.ID, versions[1].Version), nil).Expect(t, http.StatusNotFound)
+	})
+
+	t.Run("tenant isolation", func(t *testing.T) {
+		e.Member(t, "org:member").Do(t, http.MethodGet, fmt.Sprintf("/v1/warehouse/%d/history", w.ID), nil).Expect(t, http.StatusNotFound)
+	})
+}
@@ -0,0 +1,89 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+	"warehouse-service/dbroute"
+	"warehouse-service/handlers"
+	"warehouse-service/inbox"
+	"warehouse-service/quota"
+)
+
+func TestInbox(t *testing.T) {
+	e := requireEnv(t)
+	ctx := context.Background()
+	c := e.Member(t, "org:member")
+	warehouse := createWarehouse(t, c, "Inbox")
+	roomID := e.StorageRoom(t, c.OrgID, warehouse.ID, "IN-01", "ambient")
+	receiveStock(t, c, warehouse.ID, roomID, "SKU-IN", 5)
+
+	h := handlers.NewHandlers(dbroute.New(e.DB, nil, time.Second), nil, nil, nil, nil, nil, quota.Limits{}, handlers.Attachments{}, handlers.Services{})
+	orderCreated := func(id, reference string, quantity int) inbox.Message {
+		payload, _ := json.Marshal(map[string]any{
+			"order_id":     reference,
+			"warehouse_id": warehouse.ID,
+			"lines":        []map[string]any{{"sku": "SKU-IN", "quantity": quantity}},
+		})
+		return inbox.Message{ID: id, OrgID: c.OrgID, Topic: inbox.TopicOrderCreated, Payload: payload, CreatedAt: time.Now()}
+	}
+	consume := func(t *testing.T, msg inbox.Message, want string) {
+		t.Helper()
+		outcome, err := h.ConsumeMessage(ctx, msg)
+		if err != nil || outcome != want {
+			t.Fatalf("consumed %s as %s, %v, want %s", msg.ID, outcome, err, want)
+		}
+	}
+	pickLists := func(t *testing.T, reference string) int {
+		t.Helper()
+		var count int
+		if err := e.DB.QueryRow(ctx, `SELECT count(*) FROM pick_list WHERE org_id = $1 AND reference = $2`, c.OrgID, reference).
+			Scan(&count); err != nil {
+			t.Fatal(err)
+		}
+		return count
+	}
+
+	// A message delivered twice reserves once
+	consume(t, orderCreated("msg-1", "SO-IN-1", 3), inbox.OutcomeProcessed)
+	consume(t, orderCreated("msg-1", "SO-IN-1", 3), inbox.OutcomeDuplicate)
+	if n := pickLists(t, "SO-IN-1"); n != 1 {
+		t.Fatalf("%d pick lists for SO-IN-1, want 1", n)
+	}
+
+	// An order the stock left can't fill is rejected and reserves nothing
+	consume(t, orderCreated("msg-2", "SO-IN-2", 3), inbox.OutcomeRejected)
+	consume(t, orderCreated("msg-2", "SO-IN-2", 3), inbox.OutcomeDuplicate)
+	if n := pickLists(t, "SO-IN-2"); n != 0 {
+		t.Fatalf("%d pick lists for SO-IN-2, want none", n)
+	}
+	var status, reason string
+	if err := e.DB.QueryRow(ctx, `SELECT status, error FROM processed_message WHERE topic = $1 AND message_id = 'msg-2'`, inbox.TopicOrderCreated).
+		Scan(&status, &reason); err != nil {
+		t.Fatal(err)
+	}
+	if status != "rejected" || reason == "" {
+		t.Fatalf("msg-2 is %s with %q", status, reason)
+	}
+
+	malformed := orderCreated("msg-3", "SO-IN-3", 1)
+	malformed.Payload = json.RawMessage(`{"order_id": 7}`)
+	consume(t, malformed, inbox.OutcomeRejected)
+
+	consume(t, inbox.Message{ID: "msg-4", OrgID: c.OrgID, Topic: "order.cancelled"}, inbox.OutcomeIgnored)
+
+	// Pruned messages are forgotten
+	if err := h.PruneProcessedMessages(ctx, -time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	var left int
+	if err := e.DB.QueryRow(ctx, `SELECT count(*) FROM processed_message WHERE org_id = $1`, c.OrgID).Scan(&left); err != nil {
+		t.Fatal(err)
+	}
+	if left != 0 {
+		t.Fatalf("%d processed messages left after pruning", left)
+	}
+}
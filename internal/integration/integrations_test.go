@@ -0,0 +1,231 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+	"warehouse-service/dbroute"
+	"warehouse-service/handlers"
+	"warehouse-service/integrations"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/quota"
+)
+
+// fakeChannel serves the orders of a webhook integration and records the
+// levels pushed to it
+type fakeChannel struct {
+	mu     sync.Mutex
+	orders map[string]map[string]any
+	levels map[string]int64
+}
+
+func (f *fakeChannel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.Header.Get("Authorization") != "Bearer erp-token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if r.Method == http.MethodPost {
+		var body struct{ Levels []integrations.Level }
+		json.NewDecoder(r.Body).Decode(&body)
+		for _, level := range body.Levels {
+			f.levels[level.Sku] = level.Available
+		}
+		return
+	}
+	orders := []map[string]any{}
+	for _, order := range f.orders {
+		orders = append(orders, order)
+	}
+	json.NewEncoder(w).Encode(map[string]any{"orders": orders})
+}
+
+func (f *fakeChannel) place(id string, at time.Time, lines map[string]int, cancelled bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	orderLines := []map[string]any{}
+	for sku, quantity := range lines {
+		orderLines = append(orderLines, map[string]any{"sku": sku, "quantity": quantity})
+	}
+	created := any(at)
+	if previous, ok := f.orders[id]; ok {
+		created = previous["created_at"]
+	}
+	f.orders[id] = map[string]any{
+		"id": id, "reference": id, "cancelled": cancelled, "created_at": created, "updated_at": at, "lines": orderLines,
+	}
+}
+
+func (f *fakeChannel) level(sku string) (int64, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	available, ok := f.levels[sku]
+	return available, ok
+}
+
+func TestIntegrations(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:member")
+	admin := e.WithToken(e.Token(t, "user_admin_"+c.OrgID, c.OrgID, "org:admin"), c.OrgID)
+	warehouse := createWarehouse(t, c, "Channel")
+	roomID := e.StorageRoom(t, c.OrgID, warehouse.ID, "CH-01", "ambient")
+	receiveStock(t, c, warehouse.ID, roomID, "SKU-CH", 10)
+	receiveStock(t, c, warehouse.ID, roomID, "SKU-CH-2", 4)
+
+	channel := &fakeChannel{orders: map[string]map[string]any{}, levels: map[string]int64{}}
+	server := httptest.NewServer(channel)
+	defer server.Close()
+
+	c.Do(t, http.MethodGet, "/v1/admin/integrations", nil).Expect(t, http.StatusForbidden)
+	for _, body := range []map[string]any{
+		{"name": "Shop", "kind": "shopify", "url": "https://acme.myshopify.com", "token": "shpat_x", "warehouse_id": warehouse.ID},
+		{"name": "Shop", "kind": "shopify", "url": "https://acme.myshopify.com", "location_id": "1", "warehouse_id": warehouse.ID},
+		{"name": "ERP", "kind": "webhook", "url": "erp.example.com/levels", "warehouse_id": warehouse.ID},
+		{"name": "ERP", "kind": "magento", "url": "https://erp.example.com", "warehouse_id": warehouse.ID},
+	} {
+		admin.Do(t, http.MethodPost, "/v1/admin/integrations", body).Expect(t, http.StatusBadRequest)
+	}
+	admin.Do(t, http.MethodPost, "/v1/admin/integrations", map[string]any{
+		"name": "ERP", "kind": "webhook", "url": server.URL + "/levels", "warehouse_id": 999999,
+	}).Expect(t, http.StatusNotFound)
+
+	var in handlers.IntegrationResponse
+	admin.Do(t, http.MethodPost, "/v1/admin/integrations", map[string]any{
+		"name":         "ERP",
+		"kind":         "webhook",
+		"url":          server.URL + "/levels",
+		"orders_url":   server.URL + "/orders",
+		"token":        "erp-token",
+		"warehouse_id": warehouse.ID,
+	}).Expect(t, http.StatusCreated).Data(t, &in)
+	if !in.HasToken || !in.Enabled || in.Failures != 0 {
+		t.Fatalf("integration %+v", in)
+	}
+	admin.Do(t, http.MethodPost, "/v1/admin/integrations", map[string]any{
+		"name": "ERP", "kind": "webhook", "url": server.URL + "/levels", "warehouse_id": warehouse.ID,
+	}).Expect(t, http.StatusConflict)
+
+	h := handlers.NewHandlers(dbroute.New(e.DB, nil, time.Second), nil, nil, nil, nil, nil, quota.Limits{}, handlers.Attachments{}, handlers.Services{})
+	payload, _ := json.Marshal(map[string]any{"integration_id": in.ID})
+	syncNow := func(t *testing.T) error {
+		t.Helper()
+		return h.SyncIntegrationJob(context.Background(), models.Job{OrgID: c.OrgID, Payload: payload})
+	}
+	path := fmt.Sprintf("/v1/admin/integrations/%d", in.ID)
+	orders := func(t *testing.T) map[string]handlers.IntegrationOrderResponse {
+		t.Helper()
+		var list []handlers.IntegrationOrderResponse
+		admin.Do(t, http.MethodGet, path+"/orders", nil).Expect(t, http.StatusOK).Data(t, &list)
+		byID := map[string]handlers.IntegrationOrderResponse{}
+		for _, order := range list {
+			byID[order.ExternalID] = order
+		}
+		return byID
+	}
+	pickList := func(t *testing.T, id *int64) pickListBody {
+		t.Helper()
+		if id == nil {
+			t.Fatal("no pick list")
+		}
+		var body pickListBody
+		c.Do(t, http.MethodGet, fmt.Sprintf("/v1/picklists/%d", *id), nil).Expect(t, http.StatusOK).Data(t, &body)
+		return body
+	}
+
+	placed := in.CreatedAt.Add(time.Second)
+	channel.place("SO-OLD", in.CreatedAt.Add(-time.Hour), map[string]int{"SKU-CH": 1}, false)
+	channel.place("SO-1", placed, map[string]int{"SKU-CH": 3}, false)
+	channel.place("SO-2", placed, map[string]int{"SKU-CH": 20}, false)
+	if err := syncNow(t); err != nil {
+		t.Fatal(err)
+	}
+
+	got := orders(t)
+	if _, ok := got["SO-OLD"]; ok || len(got) != 2 {
+		t.Fatalf("orders %+v", got)
+	}
+	if got["SO-1"].Status != "reserved" || got["SO-2"].Status != "short" || got["SO-2"].Error == "" {
+		t.Fatalf("orders %+v", got)
+	}
+	first := pickList(t, got["SO-1"].PickListID)
+	if first.PickList.Status != "allocated" || first.Lines[0].Quantity != 3 {
+		t.Fatalf("pick list %+v", first)
+	}
+	if available, _ := channel.level("SKU-CH"); available != 7 {
+		t.Errorf("pushed %d of SKU-CH, want 7", available)
+	}
+	if available, _ := channel.level("SKU-CH-2"); available != 4 {
+		t.Errorf("pushed %d of SKU-CH-2, want 4", available)
+	}
+
+	// A changed order is reserved again, a cancelled one releases its stock
+	channel.place("SO-1", placed.Add(time.Minute), map[string]int{"SKU-CH": 2}, false)
+	channel.place("SO-2", placed.Add(time.Minute), map[string]int{"SKU-CH": 20}, true)
+	if err := syncNow(t); err != nil {
+		t.Fatal(err)
+	}
+	got = orders(t)
+	if got["SO-1"].Status != "reserved" || got["SO-2"].Status != "cancelled" {
+		t.Fatalf("orders %+v", got)
+	}
+	if pickList(t, &first.PickList.ID).PickList.Status != "cancelled" {
+		t.Error("previous pick list not cancelled")
+	}
+	second := pickList(t, got["SO-1"].PickListID)
+	if second.Lines[0].Quantity != 2 {
+		t.Fatalf("pick list %+v", second)
+	}
+	if available, _ := channel.level("SKU-CH"); available != 8 {
+		t.Errorf("pushed %d of SKU-CH, want 8", available)
+	}
+
+	// Once picked, a change is a conflict and the stock stays with the pick list
+	c.Do(t, http.MethodPost, fmt.Sprintf("/v1/picklists/%d/pick", second.PickList.ID), map[string]any{
+		"lines": []map[string]any{{"line_id": second.Lines[0].ID, "picked_quantity": 2}},
+	}).Expect(t, http.StatusOK)
+	channel.place("SO-1", placed.Add(2*time.Minute), map[string]int{"SKU-CH": 5}, false)
+	if err := syncNow(t); err != nil {
+		t.Fatal(err)
+	}
+	got = orders(t)
+	if got["SO-1"].Status != "conflict" || *got["SO-1"].PickListID != second.PickList.ID {
+		t.Fatalf("orders %+v", got)
+	}
+
+	// Failures are recorded on the integration
+	admin.Do(t, http.MethodPut, path, map[string]any{
+		"name": "ERP", "kind": "webhook", "url": server.URL + "/levels", "orders_url": server.URL + "/orders",
+		"token": "wrong", "warehouse_id": warehouse.ID, "enabled": false,
+	}).Expect(t, http.StatusOK).Data(t, &in)
+	if in.Enabled || !in.HasToken {
+		t.Fatalf("updated integration %+v", in)
+	}
+	if err := syncNow(t); err == nil {
+		t.Fatal("synced with a wrong token")
+	}
+	admin.Do(t, http.MethodGet, path, nil).Expect(t, http.StatusOK).Data(t, &in)
+	if in.Failures != 1 || in.LastError == "" || !in.NextSyncAt.After(time.Now()) {
+		t.Fatalf("integration after a failed sync %+v", in)
+	}
+
+	var job handlers.JobResponse
+	admin.Do(t, http.MethodPost, path+"/sync", nil).Expect(t, http.StatusAccepted).Data(t, &job)
+	if job.Kind != handlers.JobKindSyncIntegration {
+		t.Fatalf("sync job %+v", job)
+	}
+
+	other := e.Member(t, "org:admin")
+	other.Do(t, http.MethodGet, path, nil).Expect(t, http.StatusNotFound)
+	other.Do(t, http.MethodGet, path+"/orders", nil).Expect(t, http.StatusOK)
+
+	admin.Do(t, http.MethodDelete, path, nil).Expect(t, http.StatusOK)
+	admin.Do(t, http.MethodDelete, path, nil).Expect(t, http.StatusNotFound)
+}
@@ -0,0 +1,249 @@
+//go:build integration
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+	"warehouse-service/handlers"
+)
+
+type pickListBody struct {
+	PickList handlers.PickListResponse       `json:"pick_list"`
+	Lines    []handlers.PickListLineResponse `json:"lines"`
+}
+
+func TestReceiving(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:member")
+	warehouse := createWarehouse(t, c, "Receiving")
+	roomID := e.StorageRoom(t, c.OrgID, warehouse.ID, "R-01", "ambient")
+
+	var created struct {
+		Receipt handlers.ReceiptResponse       `json:"receipt"`
+		Lines   []handlers.ReceiptLineResponse `json:"lines"`
+	}
+	c.Do(t, http.MethodPost, "/v1/receipts", map[string]any{
+		"warehouse_id": warehouse.ID,
+		"reference":    "PO-1",
+		"lines": []map[string]any{
+			{"sku": "SKU-A", "expected_quantity": 10},
+			{"sku": "SKU-B", "expected_quantity": 5},
+		},
+	}).Expect(t, http.StatusCreated).Data(t, &created)
+	path := fmt.Sprintf("/v1/receipts/%d", created.Receipt.ID)
+
+	var received struct {
+		Receipt       handlers.ReceiptResponse `json:"receipt"`
+		Discrepancies []map[string]any         `json:"discrepancies"`
+	}
+	c.Do(t, http.MethodPost, path+"/receive", map[string]any{
+		"lines": []map[string]any{
+			{"line_id": created.Lines[0].ID, "storage_room_id": roomID, "quantity": 10},
+			{"line_id": created.Lines[1].ID, "storage_room_id": roomID, "quantity": 3},
+		},
+	}).Expect(t, http.StatusOK).Data(t, &received)
+	if received.Receipt.Status != "partially_received" {
+		t.Fatalf("receipt status %q", received.Receipt.Status)
+	}
+
+	c.Do(t, http.MethodGet, path, nil).Expect(t, http.StatusOK)
+	var receipts []handlers.ReceiptResponse
+	c.Do(t, http.MethodGet, "/v1/receipts", nil).Expect(t, http.StatusOK).Data(t, &receipts)
+	if len(receipts) != 1 {
+		t.Fatalf("receipts %+v", receipts)
+	}
+
+	var closed struct {
+		Receipt handlers.ReceiptResponse `json:"receipt"`
+	}
+	c.Do(t, http.MethodPost, path+"/close", nil).Expect(t, http.StatusOK).Data(t, &closed)
+	if closed.Receipt.Status != "closed_with_discrepancies" {
+		t.Fatalf("closed status %q", closed.Receipt.Status)
+	}
+	c.Do(t, http.MethodPost, path+"/close", nil).Expect(t, http.StatusConflict)
+
+	if got := stockOf(t, c, roomID, "SKU-A"); got != 10 {
+		t.Fatalf("SKU-A stock %d, want 10", got)
+	}
+	var movements []handlers.StockAdjustmentResponse
+	c.Do(t, http.MethodGet, "/v1/stock/movements", nil).Expect(t, http.StatusOK).Data(t, &movements)
+	if len(movements) != 2 {
+		t.Fatalf("movements %+v", movements)
+	}
+}
+
+func TestPickListStrategies(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:member")
+	warehouse := createWarehouse(t, c, "Strategies")
+	older := e.StorageRoom(t, c.OrgID, warehouse.ID, "ST-01", "chilled")
+	newer := e.StorageRoom(t, c.OrgID, warehouse.ID, "ST-02", "chilled")
+	// Received first but expiring last, so FIFO and FEFO disagree
+	late := time.Date(2031, 6, 1, 0, 0, 0, 0, time.UTC)
+	early := time.Date(2030, 6, 1, 0, 0, 0, 0, time.UTC)
+	receiveExpiringStock(t, c, warehouse.ID, older, "SKU-MILK", 5, late)
+	receiveExpiringStock(t, c, warehouse.ID, newer, "SKU-MILK", 5, early)
+
+	if level := stockLevelOf(t, c, newer, "SKU-MILK"); level.ExpiresAt == nil || !level.ExpiresAt.Equal(early) {
+		t.Fatalf("stock level expires at %v, want %v", level.ExpiresAt, early)
+	}
+
+	allocate := func(strategy string) pickListBody {
+		var body pickListBody
+		c.Do(t, http.MethodPost, "/v1/picklists", map[string]any{
+			"warehouse_id": warehouse.ID,
+			"strategy":     strategy,
+			"items":        []map[string]any{{"sku": "SKU-MILK", "quantity": 3}},
+		}).Expect(t, http.StatusCreated).Data(t, &body)
+		return body
+	}
+	fifo := allocate("fifo")
+	if len(fifo.Lines) != 1 || fifo.Lines[0].StorageRoomID != older {
+		t.Fatalf("fifo lines %+v, want all from the room received first", fifo.Lines)
+	}
+	fefo := allocate("fefo")
+	if len(fefo.Lines) != 1 || fefo.Lines[0].StorageRoomID != newer {
+		t.Fatalf("fefo lines %+v, want all from the room expiring first", fefo.Lines)
+	}
+
+	t.Run("short allocation rolls back", func(t *testing.T) {
+		var pickLists []handlers.PickListResponse
+		c.Do(t, http.MethodGet, "/v1/picklists", nil).Expect(t, http.StatusOK).Data(t, &pickLists)
+		before := len(pickLists)
+
+		// 4 units are free, 2 in each room: the first item is allocated in
+		// full, the second only in part
+		c.Do(t, http.MethodPost, "/v1/picklists", map[string]any{
+			"warehouse_id": warehouse.ID,
+			"strategy":     "fefo",
+			"items": []map[string]any{
+				{"sku": "SKU-MILK", "quantity": 3},
+				{"sku": "SKU-MILK", "quantity": 2},
+			},
+		}).Expect(t, http.StatusConflict)
+
+		for room, want := range map[int32]int32{older: 3, newer: 3} {
+			if got := stockLevelOf(t, c, room, "SKU-MILK").AllocatedQuantity; got != want {
+				t.Fatalf("storage room %d has %d allocated after a rolled back pick list, want %d", room, got, want)
+			}
+		}
+		c.Do(t, http.MethodGet, "/v1/picklists", nil).Expect(t, http.StatusOK).Data(t, &pickLists)
+		if len(pickLists) != before {
+			t.Fatalf("%d pick lists after a rolled back one, want %d", len(pickLists), before)
+		}
+	})
+}
+
+func TestPickLists(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:member")
+	warehouse := createWarehouse(t, c, "Picking")
+	roomID := e.StorageRoom(t, c.OrgID, warehouse.ID, "P-01", "ambient")
+	receiveStock(t, c, warehouse.ID, roomID, "SKU-P", 10)
+
+	create := func(quantity int32) pickListBody {
+		var body pickListBody
+		c.Do(t, http.MethodPost, "/v1/picklists", map[string]any{
+			"warehouse_id": warehouse.ID,
+			"items":        []map[string]any{{"sku": "SKU-P", "quantity": quantity}},
+		}).Expect(t, http.StatusCreated).Data(t, &body)
+		return body
+	}
+
+	t.Run("insufficient stock", func(t *testing.T) {
+		c.Do(t, http.MethodPost, "/v1/picklists", map[string]any{
+			"warehouse_id": warehouse.ID,
+			"items":        []map[string]any{{"sku": "SKU-P", "quantity": 11}},
+		}).Expect(t, http.StatusConflict)
+	})
+
+	t.Run("pick and ship", func(t *testing.T) {
+		pickList := create(4)
+		path := fmt.Sprintf("/v1/picklists/%d", pickList.PickList.ID)
+
+		var picked pickListBody
+		c.Do(t, http.MethodPost, path+"/pick", map[string]any{
+			"lines": []map[string]any{{"line_id": pickList.Lines[0].ID, "picked_quantity": 4}},
+		}).Expect(t, http.StatusOK).Data(t, &picked)
+		if picked.PickList.Status != "picked" {
+			t.Fatalf("status %q after pick", picked.PickList.Status)
+		}
+
+		var shipped pickListBody
+		c.Do(t, http.MethodPost, path+"/ship", nil).Expect(t, http.StatusOK).Data(t, &shipped)
+		if shipped.PickList.Status != "shipped" {
+			t.Fatalf("status %q after ship", shipped.PickList.Status)
+		}
+		c.Do(t, http.MethodPost, path+"/cancel", nil).Expect(t, http.StatusConflict)
+		c.Do(t, http.MethodGet, path, nil).Expect(t, http.StatusOK)
+
+		if got := stockOf(t, c, roomID, "SKU-P"); got != 6 {
+			t.Fatalf("stock %d after shipping, want 6", got)
+		}
+	})
+
+	t.Run("cancel releases allocation", func(t *testing.T) {
+		pickList := create(6)
+		c.Do(t, http.MethodPost, fmt.Sprintf("/v1/picklists/%d/cancel", pickList.PickList.ID), nil).Expect(t, http.StatusOK)
+		// The whole quantity can be allocated again
+		create(6)
+	})
+
+	var pickLists []handlers.PickListResponse
+	c.Do(t, http.MethodGet, "/v1/picklists", nil).Expect(t, http.StatusOK).Data(t, &pickLists)
+	if len(pickLists) != 3 {
+		t.Fatalf("pick lists %+v", pickLists)
+	}
+
+	var audit []handlers.AuditLogResponse
+	c.Do(t, http.MethodGet, "/v1/audit?entity_type=pick_list", nil).Expect(t, http.StatusOK).Data(t, &audit)
+	if len(audit) == 0 {
+		t.Fatal("no pick list audit entries")
+	}
+}
+
+func TestCycleCounts(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:member")
+	warehouse := createWarehouse(t, c, "Counting")
+	roomID := e.StorageRoom(t, c.OrgID, warehouse.ID, "C-01", "ambient")
+	receiveStock(t, c, warehouse.ID, roomID, "SKU-C", 10)
+
+	var session handlers.CountSessionResponse
+	c.Do(t, http.MethodPost, "/v1/counts", map[string]any{
+		"warehouse_id":    warehouse.ID,
+		"storage_room_id": roomID,
+	}).Expect(t, http.StatusCreated).Data(t, &session)
+	path := fmt.Sprintf("/v1/counts/%d", session.ID)
+
+	c.Do(t, http.MethodPost, path+"/lines", map[string]any{
+		"lines": []map[string]any{{"storage_room_id": roomID, "sku": "SKU-C", "counted_quantity": 8}},
+	}).Expect(t, http.StatusOK)
+
+	var variance struct {
+		Variances []struct {
+			Variance int32 `json:"variance"`
+		} `json:"variances"`
+	}
+	c.Do(t, http.MethodGet, path+"/variance", nil).Expect(t, http.StatusOK).Data(t, &variance)
+	if len(variance.Variances) != 1 || variance.Variances[0].Variance != -2 {
+		t.Fatalf("variance %+v", variance)
+	}
+	c.Do(t, http.MethodGet, path+"/variance?format=csv", nil).Expect(t, http.StatusOK)
+
+	c.Do(t, http.MethodPost, path+"/post", map[string]any{}).Expect(t, http.StatusOK)
+	c.Do(t, http.MethodPost, path+"/post", map[string]any{}).Expect(t, http.StatusConflict)
+	c.Do(t, http.MethodGet, path, nil).Expect(t, http.StatusOK)
+
+	var sessions []handlers.CountSessionResponse
+	c.Do(t, http.MethodGet, "/v1/counts", nil).Expect(t, http.StatusOK).Data(t, &sessions)
+	if len(sessions) != 1 || sessions[0].Status != "posted" {
+		t.Fatalf("sessions %+v", sessions)
+	}
+	if got := stockOf(t, c, roomID, "SKU-C"); got != 8 {
+		t.Fatalf("stock %d after posting, want 8", got)
+	}
+}
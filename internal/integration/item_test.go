@@ -0,0 +1,86 @@
+//go:build integration
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"warehouse-service/handlers"
+)
+
+func TestItems(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:member")
+	warehouse := createWarehouse(t, c, "Items")
+	roomID := e.StorageRoom(t, c.OrgID, warehouse.ID, "I-01", "ambient")
+
+	var item handlers.ItemResponse
+	c.Do(t, http.MethodPost, "/v1/items", map[string]any{
+		"sku":         "SKU-UOM",
+		"description": "Bottled water",
+		"weight_kg":   0.5,
+		"units":       []map[string]any{{"unit": "Case", "factor": 12}},
+	}).Expect(t, http.StatusCreated).Data(t, &item)
+	if item.BaseUnit != "each" || len(item.Units) != 1 || item.Units[0].Unit != "case" {
+		t.Fatalf("created %+v", item)
+	}
+	c.Do(t, http.MethodPost, "/v1/items", map[string]any{"sku": "SKU-UOM"}).
+		Expect(t, http.StatusConflict)
+
+	t.Run("convert", func(t *testing.T) {
+		var created struct {
+			Receipt handlers.ReceiptResponse       `json:"receipt"`
+			Lines   []handlers.ReceiptLineResponse `json:"lines"`
+		}
+		c.Do(t, http.MethodPost, "/v1/receipts", map[string]any{
+			"warehouse_id": warehouse.ID,
+			"lines":        []map[string]any{{"sku": "SKU-UOM", "expected_quantity": 2, "unit": "case"}},
+		}).Expect(t, http.StatusCreated).Data(t, &created)
+		if created.Lines[0].ExpectedQuantity != 24 {
+			t.Fatalf("expected quantity %d, want 24", created.Lines[0].ExpectedQuantity)
+		}
+		c.Do(t, http.MethodPost, fmt.Sprintf("/v1/receipts/%d/receive", created.Receipt.ID), map[string]any{
+			"lines": []map[string]any{{
+				"line_id":         created.Lines[0].ID,
+				"storage_room_id": roomID,
+				"quantity":        1,
+				"unit":            "pallet",
+			}},
+		}).Expect(t, http.StatusBadRequest)
+		c.Do(t, http.MethodPost, fmt.Sprintf("/v1/receipts/%d/receive", created.Receipt.ID), map[string]any{
+			"lines": []map[string]any{{
+				"line_id":         created.Lines[0].ID,
+				"storage_room_id": roomID,
+				"quantity":        2,
+				"unit":            "case",
+			}},
+		}).Expect(t, http.StatusOK)
+		if got := stockOf(t, c, roomID, "SKU-UOM"); got != 24 {
+			t.Fatalf("stock %d, want 24", got)
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		var updated handlers.ItemResponse
+		c.Do(t, http.MethodPut, fmt.Sprintf("/v1/items/%d", item.ID), map[string]any{
+			"description": "Sparkling water",
+			"units":       []map[string]any{{"unit": "case", "factor": 6}, {"unit": "pallet", "factor": 240}},
+		}).Expect(t, http.StatusOK).Data(t, &updated)
+		if updated.Description != "Sparkling water" || len(updated.Units) != 2 || updated.WeightKg != nil {
+			t.Fatalf("updated %+v", updated)
+		}
+		c.Do(t, http.MethodPut, fmt.Sprintf("/v1/items/%d", item.ID), map[string]any{
+			"sku": "SKU-OTHER",
+		}).Expect(t, http.StatusBadRequest)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		c.Do(t, http.MethodDelete, fmt.Sprintf("/v1/items/%d", item.ID), nil).Expect(t, http.StatusOK)
+		c.Do(t, http.MethodGet, fmt.Sprintf("/v1/items/%d", item.ID), nil).Expect(t, http.StatusNotFound)
+		c.Do(t, http.MethodPost, "/v1/receipts", map[string]any{
+			"warehouse_id": warehouse.ID,
+			"lines":        []map[string]any{{"sku": "SKU-UOM", "expected_quantity": 1, "unit": "case"}},
+		}).Expect(t, http.StatusBadRequest)
+	})
+}
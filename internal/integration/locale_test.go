@@ -0,0 +1,61 @@
+//go:build integration
+
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestLocalizedMessages(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:member")
+
+	message := func(t *testing.T, res Response, key string) string {
+		t.Helper()
+		var body map[string]any
+		if err := json.Unmarshal(res.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode response: %v: %s", err, res.Body.String())
+		}
+		s, _ := body[key].(string)
+		return s
+	}
+
+	res := c.Do(t, http.MethodGet, "/v1/warehouse/99999999", nil).Expect(t, http.StatusNotFound)
+	if got := message(t, res, "error"); got != "Warehouse not found" || res.Header().Get("Content-Language") != "en" {
+		t.Fatalf("default locale %q, Content-Language %q", got, res.Header().Get("Content-Language"))
+	}
+
+	c.AcceptLanguage = "fr-FR, vi;q=0.8, en;q=0.5"
+	res = c.Do(t, http.MethodGet, "/v1/warehouse/99999999", nil).Expect(t, http.StatusNotFound)
+	if got := message(t, res, "error"); got != "Không tìm thấy kho" || res.Header().Get("Content-Language") != "vi" {
+		t.Fatalf("vi %q, Content-Language %q", got, res.Header().Get("Content-Language"))
+	}
+	res = c.Form(t, http.MethodPost, "/v1/warehouse/create", url.Values{
+		"Name":    {"Localized"},
+		"Address": {"1 Test Rd"},
+	}).Expect(t, http.StatusOK)
+	if got := message(t, res, "message"); got != "Đã tạo kho thành công" {
+		t.Fatalf("vi create %q", got)
+	}
+	res = c.Do(t, http.MethodGet, "/v2/warehouses/99999999", nil).Expect(t, http.StatusNotFound)
+	var v2 struct {
+		Errors []struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(res.Body.Bytes(), &v2); err != nil || len(v2.Errors) != 1 || v2.Errors[0].Message != "Không tìm thấy kho" || v2.Errors[0].Code != "not_found" {
+		t.Fatalf("vi v2 %s", res.Body.String())
+	}
+
+	// Middlewares answer in the negotiated locale too
+	anonymous := e.Anonymous()
+	anonymous.AcceptLanguage = "vi"
+	res = anonymous.Do(t, http.MethodGet, "/v1/warehouse/list", nil).Expect(t, http.StatusUnauthorized)
+	if got := message(t, res, "message"); got != "Cần có header Authorization" {
+		t.Fatalf("vi unauthorized %q", got)
+	}
+}
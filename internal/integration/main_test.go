@@ -0,0 +1,63 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+var (
+	envOnce  sync.Once
+	env      *Env
+	startErr error
+)
+
+// TestMain shares one database across the package. It is started by the
+// first test that needs it, so the package skips cleanly without Docker.
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if env != nil {
+		env.Close()
+	}
+	os.Exit(code)
+}
+
+// requireEnv returns the shared environment, skipping the test or
+// benchmark when no container runtime is available
+func requireEnv(t testing.TB) *Env {
+	t.Helper()
+	skipIfNoDocker(t)
+	envOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+		defer cancel()
+		env, startErr = Start(ctx)
+	})
+	if startErr != nil {
+		t.Fatalf("start integration environment: %v", startErr)
+	}
+	return env
+}
+
+// skipIfNoDocker is testcontainers.SkipIfProviderIsNotHealthy for
+// benchmarks too
+func skipIfNoDocker(t testing.TB) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r != nil {
+			t.Skipf("Docker is not available: %v", r)
+		}
+	}()
+	provider, err := testcontainers.ProviderDocker.GetProvider()
+	if err == nil {
+		err = provider.Health(context.Background())
+	}
+	if err != nil {
+		t.Skipf("Docker is not available: %v", err)
+	}
+}
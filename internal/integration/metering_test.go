@@ -0,0 +1,75 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"encoding/csv"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+	"warehouse-service/handlers"
+)
+
+func TestMetering(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:admin")
+	w := createWarehouse(t, c, "Metered")
+	e.StorageRoom(t, c.OrgID, w.ID, "M1", "ambient")
+	if _, err := e.DB.Exec(context.Background(),
+		`INSERT INTO outbox (org_id, topic, key, payload, delivered_at) VALUES ($1, 'warehouse.created', 'm', '{}', now())`,
+		c.OrgID); err != nil {
+		t.Fatal(err)
+	}
+
+	var aggregated handlers.MeteringAggregateResponse
+	e.Operator(t).Do(t, http.MethodPost, "/admin/metering/aggregate", nil).Expect(t, http.StatusOK).Data(t, &aggregated)
+	today := time.Now().UTC().Format(time.DateOnly)
+	if len(aggregated.Days) != 2 || aggregated.Days[1] != today || aggregated.Rows == 0 {
+		t.Fatalf("aggregated %+v", aggregated)
+	}
+
+	var metering []handlers.MeteringResponse
+	c.Do(t, http.MethodGet, "/v1/admin/metering", nil).Expect(t, http.StatusOK).Data(t, &metering)
+	if len(metering) != 1 {
+		t.Fatalf("metering %+v", metering)
+	}
+	m := metering[0]
+	if m.OrgID != c.OrgID || m.Day != today || m.APICalls == 0 || m.Warehouses != 1 || m.StorageRooms != 1 ||
+		m.EventsDelivered == 0 || m.AggregatedAt == nil {
+		t.Fatalf("metering %+v", m)
+	}
+
+	// Aggregating again replaces the day's row
+	e.Operator(t).Do(t, http.MethodPost, "/admin/metering/aggregate", nil).Expect(t, http.StatusOK)
+	c.Do(t, http.MethodGet, "/v1/admin/metering", nil).Expect(t, http.StatusOK).Data(t, &metering)
+	if len(metering) != 1 || metering[0].APICalls <= m.APICalls {
+		t.Fatalf("after second aggregation %+v, before %+v", metering, m)
+	}
+
+	resp := e.Operator(t).Do(t, http.MethodGet, "/admin/metering?format=csv&from="+today, nil).Expect(t, http.StatusOK)
+	records, err := csv.NewReader(strings.NewReader(resp.Body.String())).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if records[0][0] != "org_id" || records[0][6] != "events_delivered" {
+		t.Fatalf("header %v", records[0])
+	}
+	found := false
+	for _, record := range records[1:] {
+		found = found || (record[0] == c.OrgID && record[1] == today && record[3] == "1")
+	}
+	if !found {
+		t.Fatalf("tenant missing from %v", records)
+	}
+
+	var other []handlers.MeteringResponse
+	e.Member(t, "org:admin").Do(t, http.MethodGet, "/v1/admin/metering", nil).Expect(t, http.StatusOK).Data(t, &other)
+	if len(other) != 0 {
+		t.Fatalf("other tenant sees %+v", other)
+	}
+	e.Member(t, "org:member").Do(t, http.MethodGet, "/v1/admin/metering", nil).Expect(t, http.StatusForbidden)
+	c.Do(t, http.MethodGet, "/v1/admin/metering?from=2020-01-01", nil).Expect(t, http.StatusBadRequest)
+	c.Do(t, http.MethodGet, "/admin/metering", nil).Expect(t, http.StatusForbidden)
+}
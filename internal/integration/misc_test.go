@@ -0,0 +1,57 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"warehouse-service/handlers"
+	"warehouse-service/jobs"
+	models "warehouse-service/models/sqlc"
+)
+
+func TestHealthAndMetrics(t *testing.T) {
+	e := requireEnv(t)
+	anon := e.Anonymous()
+	anon.Do(t, http.MethodGet, "/healthz", nil).Expect(t, http.StatusOK)
+	anon.Do(t, http.MethodGet, "/readyz", nil).Expect(t, http.StatusOK)
+	anon.Do(t, http.MethodGet, "/metrics", nil).Expect(t, http.StatusOK)
+}
+
+func TestSearch(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:member")
+	warehouse := createWarehouse(t, c, "Riverside Distribution")
+	e.StorageRoom(t, c.OrgID, warehouse.ID, "RIV-01", "ambient")
+
+	var results []handlers.SearchResultResponse
+	c.Do(t, http.MethodGet, "/v1/search?q=riverside", nil).Expect(t, http.StatusOK).Data(t, &results)
+	if len(results) == 0 || results[0].ID != warehouse.ID {
+		t.Fatalf("search results %+v", results)
+	}
+	c.Do(t, http.MethodGet, "/v1/search", nil).Expect(t, http.StatusBadRequest)
+	c.Do(t, http.MethodGet, "/v1/search?q=x&type=pallet", nil).Expect(t, http.StatusBadRequest)
+}
+
+func TestJobs(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:member")
+	job, err := jobs.Enqueue(context.Background(), models.New(e.DB), c.OrgID, "integration_test", map[string]string{"hello": "world"}, jobs.EnqueueOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got handlers.JobResponse
+	c.Do(t, http.MethodGet, fmt.Sprintf("/v1/jobs/%d", job.ID), nil).Expect(t, http.StatusOK).Data(t, &got)
+	if got.Kind != "integration_test" {
+		t.Fatalf("job %+v", got)
+	}
+	var list []handlers.JobResponse
+	c.Do(t, http.MethodGet, "/v1/jobs", nil).Expect(t, http.StatusOK).Data(t, &list)
+	if len(list) != 1 {
+		t.Fatalf("jobs %+v", list)
+	}
+	e.Member(t, "org:member").Do(t, http.MethodGet, fmt.Sprintf("/v1/jobs/%d", job.ID), nil).Expect(t, http.StatusNotFound)
+}
@@ -0,0 +1,113 @@
+//go:build integration
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"warehouse-service/handlers"
+)
+
+func TestOperatorAccess(t *testing.T) {
+	e := requireEnv(t)
+	admin := e.Member(t, "org:admin")
+
+	admin.Do(t, http.MethodGet, "/admin/tenants", nil).Expect(t, http.StatusForbidden)
+	e.Anonymous().Do(t, http.MethodGet, "/admin/tenants", nil).Expect(t, http.StatusUnauthorized)
+	// An operator session without an organization still reaches /admin
+	e.Operator(t).Do(t, http.MethodGet, "/admin/tenants", nil).Expect(t, http.StatusOK)
+	// but is not a tenant member
+	e.Operator(t).Do(t, http.MethodGet, "/v1/warehouse/list", nil).Expect(t, http.StatusForbidden)
+}
+
+func TestOperatorAPIKeys(t *testing.T) {
+	e := requireEnv(t)
+	operator := e.Operator(t)
+	member := e.Member(t, "org:member")
+	member.Form(t, http.MethodPost, "/v1/warehouse/create", url.Values{
+		"Name": {"Tenant"}, "Address": {"1 Test Rd"},
+	}).Expect(t, http.StatusOK)
+
+	var created struct {
+		Key    string
+		APIKey handlers.APIKeyResponse
+	}
+	operator.Do(t, http.MethodPost, "/admin/tenants/"+member.OrgID+"/api-keys", map[string]any{
+		"name": "gateway", "expires_in": "24h",
+	}).Expect(t, http.StatusCreated).Data(t, &created)
+	if created.Key == "" || created.APIKey.OrgID != member.OrgID || created.APIKey.ExpiresAt == nil {
+		t.Fatalf("created %+v", created)
+	}
+	operator.Do(t, http.MethodPost, "/admin/tenants/"+member.OrgID+"/api-keys", map[string]any{
+		"name": "gateway", "expires_in": "soon",
+	}).Expect(t, http.StatusBadRequest)
+
+	withKey := e.WithToken(created.Key, member.OrgID)
+	withKey.Do(t, http.MethodGet, "/v1/warehouse/list", nil).Expect(t, http.StatusOK)
+	withKey.Do(t, http.MethodGet, "/admin/tenants", nil).Expect(t, http.StatusForbidden)
+
+	var keys []handlers.APIKeyResponse
+	operator.Do(t, http.MethodGet, "/admin/tenants/"+member.OrgID+"/api-keys", nil).Expect(t, http.StatusOK).Data(t, &keys)
+	if len(keys) != 1 || keys[0].ID != created.APIKey.ID {
+		t.Fatalf("keys %+v", keys)
+	}
+
+	var tenants []handlers.TenantResponse
+	operator.Do(t, http.MethodGet, "/admin/tenants?limit=100", nil).Expect(t, http.StatusOK).Data(t, &tenants)
+	found := false
+	for _, tenant := range tenants {
+		if tenant.OrgID == member.OrgID {
+			found = tenant.Warehouses == 1 && tenant.ActiveAPIKeys == 1
+		}
+	}
+	if !found {
+		t.Fatalf("tenant %s missing or miscounted in %+v", member.OrgID, tenants)
+	}
+
+	path := fmt.Sprintf("/admin/api-keys/%d", created.APIKey.ID)
+	var revoked handlers.APIKeyResponse
+	operator.Do(t, http.MethodDelete, path, nil).Expect(t, http.StatusOK).Data(t, &revoked)
+	if revoked.RevokedAt == nil {
+		t.Fatalf("revoked %+v", revoked)
+	}
+	operator.Do(t, http.MethodDelete, path, nil).Expect(t, http.StatusConflict)
+	operator.Do(t, http.MethodDelete, "/admin/api-keys/0", nil).Expect(t, http.StatusNotFound)
+	withKey.Do(t, http.MethodGet, "/v1/warehouse/list", nil).Expect(t, http.StatusUnauthorized)
+}
+
+func TestOperatorRuntime(t *testing.T) {
+	e := requireEnv(t)
+	operator := e.Operator(t)
+
+	var level struct{ Level string }
+	operator.Do(t, http.MethodGet, "/admin/log-level", nil).Expect(t, http.StatusOK).Data(t, &level)
+	previous := level.Level
+	t.Cleanup(func() {
+		operator.Do(t, http.MethodPut, "/admin/log-level", map[string]any{"level": previous}).Expect(t, http.StatusOK)
+	})
+	operator.Do(t, http.MethodPut, "/admin/log-level", map[string]any{"level": "debug"}).Expect(t, http.StatusOK)
+	operator.Do(t, http.MethodGet, "/admin/log-level", nil).Expect(t, http.StatusOK).Data(t, &level)
+	if level.Level != "debug" {
+		t.Fatalf("level %q", level.Level)
+	}
+	operator.Do(t, http.MethodPut, "/admin/log-level", map[string]any{"level": "loud"}).Expect(t, http.StatusBadRequest)
+
+	operator.Do(t, http.MethodPost, "/admin/cache/flush", nil).Expect(t, http.StatusOK)
+	// Queries run on fresh connections afterwards
+	e.Member(t, "").Do(t, http.MethodGet, "/v1/warehouse/list", nil).Expect(t, http.StatusOK)
+
+	var jobs struct {
+		Jobs map[string]map[string]int64
+	}
+	operator.Do(t, http.MethodGet, "/admin/jobs", nil).Expect(t, http.StatusOK).Data(t, &jobs)
+	var outbox struct {
+		Pending  int64
+		Messages []handlers.OutboxMessageResponse
+	}
+	operator.Do(t, http.MethodGet, "/admin/outbox?org_id=org_none", nil).Expect(t, http.StatusOK).Data(t, &outbox)
+	if len(outbox.Messages) != 0 {
+		t.Fatalf("outbox %+v", outbox)
+	}
+}
@@ -0,0 +1,137 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+	"warehouse-service/handlers"
+	"warehouse-service/outbox"
+)
+
+// recordingPublisher keeps the messages of one tenant and fails while
+// failing is set
+type recordingPublisher struct {
+	orgID string
+
+	mu       sync.Mutex
+	failing  bool
+	messages []outbox.Message
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, msg outbox.Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.failing {
+		return errors.New("broker unavailable")
+	}
+	if msg.OrgID == p.orgID {
+		p.messages = append(p.messages, msg)
+	}
+	return nil
+}
+
+func TestOutbox(t *testing.T) {
+	e := requireEnv(t)
+	ctx := context.Background()
+	c := e.Member(t, "org:member")
+
+	warehouse := createWarehouse(t, c, NewOrg())
+	c.Do(t, http.MethodDelete, fmt.Sprintf("/v2/warehouses/%d", warehouse.ID), nil).Expect(t, http.StatusNoContent)
+
+	publisher := &recordingPublisher{orgID: c.OrgID, failing: true}
+	relay := outbox.NewRelay(e.DB, publisher, nil, outbox.Config{BatchSize: 1000, BaseBackoff: time.Millisecond})
+
+	// A failed publish is kept and retried
+	if _, err := relay.RelayBatch(ctx); err != nil {
+		t.Fatal(err)
+	}
+	var attempts int32
+	var lastError string
+	if err := e.DB.QueryRow(ctx, `SELECT attempts, last_error FROM outbox WHERE org_id = $1 ORDER BY id LIMIT 1`, c.OrgID).
+		Scan(&attempts, &lastError); err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 1 || lastError != "broker unavailable" {
+		t.Fatalf("attempts %d, last error %q", attempts, lastError)
+	}
+
+	publisher.failing = false
+	time.Sleep(10 * time.Millisecond)
+	for {
+		claimed, err := relay.RelayBatch(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if claimed == 0 {
+			break
+		}
+	}
+	if len(publisher.messages) != 2 {
+		t.Fatalf("published %+v, want the create and delete events", publisher.messages)
+	}
+	for i, topic := range []string{outbox.TopicWarehouseCreated, outbox.TopicWarehouseDeleted} {
+		if msg := publisher.messages[i]; msg.Topic != topic || msg.Key != fmt.Sprint(warehouse.ID) {
+			t.Fatalf("message %d is %+v, want %s for warehouse %d", i, msg, topic, warehouse.ID)
+		}
+	}
+
+	var pending int
+	if err := e.DB.QueryRow(ctx, `SELECT count(*) FROM outbox WHERE org_id = $1 AND delivered_at IS NULL`, c.OrgID).
+		Scan(&pending); err != nil {
+		t.Fatal(err)
+	}
+	if pending != 0 {
+		t.Fatalf("%d messages still pending", pending)
+	}
+}
+
+func TestDeadLetters(t *testing.T) {
+	e := requireEnv(t)
+	ctx := context.Background()
+	admin := e.Member(t, "org:admin")
+	warehouse := createWarehouse(t, admin, NewOrg())
+
+	publisher := &recordingPublisher{orgID: admin.OrgID, failing: true}
+	relay := outbox.NewRelay(e.DB, publisher, nil, outbox.Config{BatchSize: 1000, MaxAttempts: 1})
+	if _, err := relay.RelayBatch(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	var deadLetters []handlers.DeadLetterResponse
+	admin.Do(t, http.MethodGet, "/v1/admin/deadletters?pending=true", nil).Expect(t, http.StatusOK).Data(t, &deadLetters)
+	if len(deadLetters) != 1 {
+		t.Fatalf("dead letters %+v, want the create event", deadLetters)
+	}
+	deadLetter := deadLetters[0]
+	if deadLetter.Topic != outbox.TopicWarehouseCreated || deadLetter.Key != fmt.Sprint(warehouse.ID) ||
+		deadLetter.LastError != "broker unavailable" || len(deadLetter.Payload) == 0 {
+		t.Fatalf("dead letter %+v", deadLetter)
+	}
+	path := fmt.Sprintf("/v1/admin/deadletters/%d", deadLetter.ID)
+	admin.Do(t, http.MethodGet, path, nil).Expect(t, http.StatusOK)
+	e.Member(t, "org:admin").Do(t, http.MethodGet, path, nil).Expect(t, http.StatusNotFound)
+	e.Member(t, "org:member").Do(t, http.MethodGet, "/v1/admin/deadletters", nil).Expect(t, http.StatusForbidden)
+
+	admin.Do(t, http.MethodPost, path+"/replay", nil).Expect(t, http.StatusAccepted)
+	admin.Do(t, http.MethodPost, path+"/replay", nil).Expect(t, http.StatusConflict)
+
+	publisher.failing = false
+	for {
+		claimed, err := relay.RelayBatch(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if claimed == 0 {
+			break
+		}
+	}
+	if len(publisher.messages) != 1 || publisher.messages[0].ID != deadLetter.SourceID {
+		t.Fatalf("published %+v, want message %d again", publisher.messages, deadLetter.SourceID)
+	}
+}
@@ -0,0 +1,76 @@
+//go:build integration
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"warehouse-service/handlers"
+)
+
+func TestPartnerDirectory(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:member")
+	warehouse := createWarehouse(t, c, "Partners")
+
+	var supplier handlers.PartnerResponse
+	c.Do(t, http.MethodPost, "/v1/suppliers", map[string]any{
+		"name":           "Acme Beverages",
+		"contact_name":   "Lan",
+		"email":          "orders@acme.test",
+		"lead_time_days": 5,
+	}).Expect(t, http.StatusCreated).Data(t, &supplier)
+	if supplier.LeadTimeDays != 5 || supplier.Email != "orders@acme.test" {
+		t.Fatalf("supplier %+v", supplier)
+	}
+	c.Do(t, http.MethodPost, "/v1/suppliers", map[string]any{"name": "Acme Beverages"}).
+		Expect(t, http.StatusConflict)
+	c.Do(t, http.MethodPost, "/v1/suppliers", map[string]any{"name": "Bad", "email": "not-an-email"}).
+		Expect(t, http.StatusBadRequest)
+
+	// A carrier may share a supplier's name, and is not a supplier
+	var carrier handlers.PartnerResponse
+	c.Do(t, http.MethodPost, "/v1/carriers", map[string]any{"name": "Acme Beverages", "lead_time_days": 2}).
+		Expect(t, http.StatusCreated).Data(t, &carrier)
+	c.Do(t, http.MethodGet, fmt.Sprintf("/v1/suppliers/%d", carrier.ID), nil).Expect(t, http.StatusNotFound)
+
+	var receipt struct {
+		Receipt handlers.ReceiptResponse `json:"receipt"`
+	}
+	c.Do(t, http.MethodPost, "/v1/receipts", map[string]any{
+		"warehouse_id": warehouse.ID,
+		"supplier_id":  supplier.ID,
+		"lines":        []map[string]any{{"sku": "SKU-P", "expected_quantity": 3}},
+	}).Expect(t, http.StatusCreated).Data(t, &receipt)
+	if receipt.Receipt.SupplierID == nil || *receipt.Receipt.SupplierID != supplier.ID {
+		t.Fatalf("receipt supplier %v", receipt.Receipt.SupplierID)
+	}
+	c.Do(t, http.MethodPost, "/v1/receipts", map[string]any{
+		"warehouse_id": warehouse.ID,
+		"supplier_id":  carrier.ID,
+		"lines":        []map[string]any{{"sku": "SKU-P", "expected_quantity": 3}},
+	}).Expect(t, http.StatusNotFound)
+
+	c.Do(t, http.MethodPut, fmt.Sprintf("/v1/suppliers/%d", supplier.ID), map[string]any{
+		"name":           "Acme Drinks",
+		"lead_time_days": 7,
+	}).Expect(t, http.StatusOK).Data(t, &supplier)
+	if supplier.Name != "Acme Drinks" || supplier.LeadTimeDays != 7 || supplier.ContactName != "" {
+		t.Fatalf("updated supplier %+v", supplier)
+	}
+
+	var suppliers []handlers.PartnerResponse
+	c.Do(t, http.MethodGet, "/v1/suppliers", nil).Expect(t, http.StatusOK).Data(t, &suppliers)
+	if len(suppliers) != 1 || suppliers[0].ID != supplier.ID {
+		t.Fatalf("suppliers %+v", suppliers)
+	}
+
+	// Deleting the supplier keeps the receipt
+	c.Do(t, http.MethodDelete, fmt.Sprintf("/v1/suppliers/%d", supplier.ID), nil).Expect(t, http.StatusOK)
+	c.Do(t, http.MethodDelete, fmt.Sprintf("/v1/suppliers/%d", supplier.ID), nil).Expect(t, http.StatusNotFound)
+	c.Do(t, http.MethodGet, fmt.Sprintf("/v1/receipts/%d", receipt.Receipt.ID), nil).Expect(t, http.StatusOK).Data(t, &receipt)
+	if receipt.Receipt.SupplierID != nil {
+		t.Errorf("receipt still references deleted supplier %d", *receipt.Receipt.SupplierID)
+	}
+}
@@ -0,0 +1,73 @@
+//go:build integration
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"warehouse-service/handlers"
+)
+
+func TestPrinting(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:member")
+	warehouse := createWarehouse(t, c, "Printing")
+	roomID := e.StorageRoom(t, c.OrgID, warehouse.ID, "PR-01", "ambient")
+
+	var printer handlers.PrinterResponse
+	c.Do(t, http.MethodPost, "/v1/printers", map[string]any{
+		"name":    "Dock 1",
+		"address": "printer.local",
+	}).Expect(t, http.StatusCreated).Data(t, &printer)
+	if printer.Address != "printer.local:9100" || printer.Dpi != 203 {
+		t.Fatalf("printer %+v", printer)
+	}
+	c.Do(t, http.MethodPost, "/v1/printers", map[string]any{
+		"name": "Dock 1", "address": "10.0.0.2",
+	}).Expect(t, http.StatusConflict)
+	c.Do(t, http.MethodPost, "/v1/printers", map[string]any{
+		"name": "Dock 2", "address": "10.0.0.2:http",
+	}).Expect(t, http.StatusBadRequest)
+	c.Do(t, http.MethodPost, "/v1/printers", map[string]any{
+		"name": "Dock 2", "address": "10.0.0.2", "dpi": 150,
+	}).Expect(t, http.StatusBadRequest)
+
+	path := fmt.Sprintf("/v1/printers/%d", printer.ID)
+	c.Do(t, http.MethodPut, path, map[string]any{
+		"name": "Dock 1", "address": "10.0.0.3:6101", "dpi": 300,
+	}).Expect(t, http.StatusOK).Data(t, &printer)
+	if printer.Address != "10.0.0.3:6101" || printer.Dpi != 300 {
+		t.Fatalf("updated printer %+v", printer)
+	}
+
+	var job handlers.JobResponse
+	res := c.Do(t, http.MethodPost, "/v1/print", map[string]any{
+		"printer_id": printer.ID, "label": "location", "id": roomID, "copies": 2,
+	}).Expect(t, http.StatusAccepted)
+	res.Data(t, &job)
+	if job.Kind != handlers.JobKindPrintLabel || job.Status != "queued" {
+		t.Fatalf("print job %+v", job)
+	}
+	if got := res.Header().Get("Location"); got != fmt.Sprintf("/v1/jobs/%d", job.ID) {
+		t.Fatalf("location %q", got)
+	}
+	c.Do(t, http.MethodGet, fmt.Sprintf("/v1/jobs/%d", job.ID), nil).Expect(t, http.StatusOK)
+
+	c.Do(t, http.MethodPost, "/v1/print", map[string]any{
+		"printer_id": printer.ID, "label": "item", "id": 999999,
+	}).Expect(t, http.StatusNotFound)
+	c.Do(t, http.MethodPost, "/v1/print", map[string]any{
+		"printer_id": printer.ID, "label": "pallet", "id": roomID,
+	}).Expect(t, http.StatusBadRequest)
+
+	// Other organizations neither see the printer nor print on it
+	other := e.Member(t, "org:member")
+	other.Do(t, http.MethodGet, path, nil).Expect(t, http.StatusNotFound)
+	other.Do(t, http.MethodPost, "/v1/print", map[string]any{
+		"printer_id": printer.ID, "label": "location", "id": roomID,
+	}).Expect(t, http.StatusNotFound)
+
+	c.Do(t, http.MethodDelete, path, nil).Expect(t, http.StatusOK)
+	c.Do(t, http.MethodDelete, path, nil).Expect(t, http.StatusNotFound)
+}
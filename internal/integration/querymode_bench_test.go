@@ -0,0 +1,88 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"warehouse-service/dbroute"
+	models "warehouse-service/models/sqlc"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// BenchmarkHotQueries compares the throughput of GetWarehouse and
+// ListWarehouse under each pgx query exec mode, see docs/database.md:
+//
+//	go test -tags integration -run '^$' -bench HotQueries ./internal/integration/...
+func BenchmarkHotQueries(b *testing.B) {
+	e := requireEnv(b)
+	ctx := context.Background()
+
+	orgID := NewOrg()
+	rows := make([][]any, 200)
+	for i := range rows {
+		rows[i] = []any{fmt.Sprintf("Bench %d", i), "1 Bench Rd", "1", "1", "Bench", "Bench", orgID}
+	}
+	if _, err := e.DB.CopyFrom(ctx, pgx.Identifier{"warehouse"},
+		[]string{"name", "address", "ward", "district", "city", "country", "org_id"},
+		pgx.CopyFromRows(rows)); err != nil {
+		b.Fatal(err)
+	}
+	var warehouseID int64
+	if err := e.DB.QueryRow(ctx, `SELECT min(id) FROM warehouse WHERE org_id = $1`, orgID).Scan(&warehouseID); err != nil {
+		b.Fatal(err)
+	}
+
+	modes := []struct {
+		name    string
+		mode    pgx.QueryExecMode
+		prepare bool
+	}{
+		{"cache_statement", pgx.QueryExecModeCacheStatement, false},
+		{"cache_describe", pgx.QueryExecModeCacheDescribe, false},
+		{"describe_exec", pgx.QueryExecModeDescribeExec, false},
+		{"exec", pgx.QueryExecModeExec, false},
+		{"simple_protocol", pgx.QueryExecModeSimpleProtocol, false},
+		{"exec+prepared", pgx.QueryExecModeExec, true},
+	}
+	for _, m := range modes {
+		poolConfig, err := pgxpool.ParseConfig(e.DSN)
+		if err != nil {
+			b.Fatal(err)
+		}
+		poolConfig.ConnConfig.DefaultQueryExecMode = m.mode
+		if m.prepare {
+			poolConfig.AfterConnect = dbroute.PrepareHotQueries
+		}
+		pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+		if err != nil {
+			b.Fatal(err)
+		}
+		queries := models.New(pool)
+
+		b.Run(m.name+"/GetWarehouse", func(b *testing.B) {
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if _, err := queries.GetWarehouse(ctx, models.GetWarehouseParams{ID: warehouseID, OrgID: orgID}); err != nil {
+						b.Error(err)
+						return
+					}
+				}
+			})
+		})
+		b.Run(m.name+"/ListWarehouse", func(b *testing.B) {
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if _, err := queries.ListWarehouse(ctx, models.ListWarehouseParams{OrgID: orgID, Limit: 20}); err != nil {
+						b.Error(err)
+						return
+					}
+				}
+			})
+		})
+		pool.Close()
+	}
+}
@@ -0,0 +1,73 @@
+//go:build integration
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+	"warehouse-service/dbroute"
+	"warehouse-service/handlers"
+	"warehouse-service/quota"
+	"warehouse-service/routes"
+
+	"github.com/gin-gonic/gin"
+)
+
+// withQuotas returns an environment whose warehouse, storage room and
+// usage routes enforce limits. The routes are built without metrics, which
+// a second api.Server would register twice.
+func (e *Env) withQuotas(limits quota.Limits) *Env {
+	router := gin.New()
+	r := routes.NewRoute(dbroute.New(e.DB, nil, time.Second), nil, nil, nil, nil, nil, limits, handlers.Attachments{}, handlers.Services{}, nil, nil)
+	r.AddWarehouseRoutes(router)
+	r.AddV2Routes(router)
+	r.AddStorageRoomRoutes(router)
+	r.AddUsageRoutes(router)
+	limited := *e
+	limited.handler = router
+	return &limited
+}
+
+func TestQuotas(t *testing.T) {
+	e := requireEnv(t)
+	c := e.withQuotas(quota.Limits{Warehouses: 2, StorageRoomsPerWarehouse: 1, APICallsPerDay: 6}).Member(t, "org:member")
+
+	first := createWarehouse(t, c, "First")
+	second := createWarehouse(t, c, "Second")
+	c.Do(t, http.MethodPost, "/v2/warehouses", map[string]any{
+		"name": "Third", "address": "1 Test Rd", "latitude": 10.7769, "longitude": 106.7009,
+	}).Expect(t, http.StatusPaymentRequired)
+
+	room := e.StorageRoom(t, c.OrgID, first.ID, "Q1", "ambient")
+	e.StorageRoom(t, c.OrgID, second.ID, "Q2", "ambient")
+	// second is full
+	c.Do(t, http.MethodPatch, fmt.Sprintf("/v1/storageroom/%d", room), map[string]any{
+		"warehouse_id": second.ID,
+	}).Expect(t, http.StatusPaymentRequired)
+	c.Do(t, http.MethodPatch, fmt.Sprintf("/v1/storageroom/%d", room), map[string]any{
+		"name": "Renamed",
+	}).Expect(t, http.StatusOK)
+
+	var usage handlers.UsageResponse
+	c.Do(t, http.MethodGet, "/v1/usage", nil).Expect(t, http.StatusOK).Data(t, &usage)
+	if usage.Warehouses.Used != 2 || usage.Warehouses.Limit == nil || *usage.Warehouses.Limit != 2 {
+		t.Fatalf("warehouse usage %+v", usage.Warehouses)
+	}
+	if usage.StorageRoomsPerWarehouse.Used != 1 || usage.StorageRoomsPerWarehouse.WarehouseID == nil {
+		t.Fatalf("storage room usage %+v", usage.StorageRoomsPerWarehouse)
+	}
+	// Every call so far, the usage request included
+	if usage.APICalls.Used != 6 || usage.UserAPICalls.Used != 6 || usage.UserAPICalls.Limit != nil {
+		t.Fatalf("API call usage %+v %+v", usage.APICalls, usage.UserAPICalls)
+	}
+
+	resp := c.Do(t, http.MethodGet, "/v1/usage", nil).Expect(t, http.StatusTooManyRequests)
+	if resp.Header().Get("Retry-After") == "" {
+		t.Fatal("429 without Retry-After")
+	}
+	// Other tenants have their own count
+	e.withQuotas(quota.Limits{APICallsPerDay: 6}).Member(t, "org:member").
+		Do(t, http.MethodGet, "/v1/usage", nil).Expect(t, http.StatusOK)
+}
@@ -0,0 +1,64 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"warehouse-service/handlers"
+	models "warehouse-service/models/sqlc"
+)
+
+func TestReorderSuggestion(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:member")
+	w := createWarehouse(t, c, "Reorder")
+	createWarehouse(t, c, "Reorder elsewhere")
+	room := e.StorageRoom(t, c.OrgID, w.ID, "R1", "ambient")
+	receiveStock(t, c, w.ID, room, "SKU-R", 100)
+
+	// 900 shipped in the window is 10 a day, older shipments don't count
+	for _, daysAgo := range []int{10, 40, 120} {
+		if _, err := e.DB.Exec(context.Background(),
+			`INSERT INTO stock_adjustment (org_id, storage_room_id, sku, quantity_delta, reason, reference, created_at)
+			 VALUES ($1, $2, 'SKU-R', -450, 'shipment', 'test', now() - make_interval(days => $3))`,
+			c.OrgID, room, daysAgo); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := models.New(e.DB).RefreshSkuConsumption(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var suggestions []handlers.ReorderSuggestionResponse
+	c.Do(t, http.MethodGet, "/v1/items/SKU-R/reorder-suggestion", nil).Expect(t, http.StatusOK).Data(t, &suggestions)
+	if len(suggestions) != 1 {
+		t.Fatalf("suggestions %+v", suggestions)
+	}
+	s := suggestions[0]
+	if s.WarehouseID != w.ID || s.AverageDailyConsumption != 10 || s.LeadTimeDays != 7 || s.OnHandQuantity != 100 ||
+		s.ReorderPoint != 140 || s.SuggestedQuantity != 340 || !s.ReorderNow {
+		t.Fatalf("suggestion %+v", s)
+	}
+
+	var supplier handlers.PartnerResponse
+	c.Do(t, http.MethodPost, "/v1/suppliers", map[string]any{"name": "Slow Supplier", "lead_time_days": 14}).
+		Expect(t, http.StatusCreated).Data(t, &supplier)
+	c.Do(t, http.MethodGet, fmt.Sprintf("/v1/items/SKU-R/reorder-suggestion?supplier_id=%d&warehouse_id=%d", supplier.ID, w.ID), nil).
+		Expect(t, http.StatusOK).Data(t, &suggestions)
+	if len(suggestions) != 1 || suggestions[0].ReorderPoint != 210 || suggestions[0].SuggestedQuantity != 410 {
+		t.Fatalf("suggestions with supplier lead time %+v", suggestions)
+	}
+
+	c.Do(t, http.MethodGet, "/v1/items/SKU-R/reorder-suggestion?supplier_id=999999", nil).Expect(t, http.StatusNotFound)
+	c.Do(t, http.MethodGet, "/v1/items/SKU-R/reorder-suggestion?lead_time_days=-1", nil).Expect(t, http.StatusBadRequest)
+
+	var other []handlers.ReorderSuggestionResponse
+	e.Member(t, "org:member").Do(t, http.MethodGet, "/v1/items/SKU-R/reorder-suggestion", nil).
+		Expect(t, http.StatusOK).Data(t, &other)
+	if len(other) != 0 {
+		t.Fatalf("other tenant sees %+v", other)
+	}
+}
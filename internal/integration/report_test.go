@@ -0,0 +1,95 @@
+//go:build integration
+
+package integration
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+	"warehouse-service/handlers"
+)
+
+func TestReports(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:member")
+	stocked := createWarehouse(t, c, "Stocked")
+	empty := createWarehouse(t, c, "Empty")
+	c.Do(t, http.MethodPost, fmt.Sprintf("/v1/warehouse/%d/archive", empty.ID), nil).Expect(t, http.StatusOK)
+	room := e.StorageRoom(t, c.OrgID, stocked.ID, "R1", "ambient")
+	receiveStock(t, c, stocked.ID, room, "SKU-R1", 10)
+	receiveStock(t, c, stocked.ID, room, "SKU-R2", 5)
+
+	t.Run("warehouse summary", func(t *testing.T) {
+		var summary handlers.WarehouseSummaryResponse
+		c.Do(t, http.MethodGet, "/v1/reports/warehouse-summary", nil).Expect(t, http.StatusOK).Data(t, &summary)
+		if summary.Total != 2 || len(summary.ByCountry) != 1 || summary.ByCountry[0].Warehouses != 2 {
+			t.Fatalf("summary %+v", summary)
+		}
+		if fmt.Sprint(summary.ByStatus) != "[{  active 1} {  archived 1}]" {
+			t.Fatalf("by status %v", summary.ByStatus)
+		}
+	})
+
+	t.Run("stock by warehouse", func(t *testing.T) {
+		var stock []handlers.WarehouseStockResponse
+		c.Do(t, http.MethodGet, "/v1/reports/stock-by-warehouse", nil).Expect(t, http.StatusOK).Data(t, &stock)
+		if len(stock) != 2 || stock[0].WarehouseID != stocked.ID || stock[0].Quantity != 15 || stock[0].Skus != 2 || stock[0].StorageRooms != 1 {
+			t.Fatalf("stock %+v", stock)
+		}
+		if stock[1].WarehouseID != empty.ID || stock[1].Quantity != 0 {
+			t.Fatalf("empty warehouse %+v", stock[1])
+		}
+
+		c.Do(t, http.MethodGet, "/v1/reports/stock-by-warehouse?sku=SKU-R2&status=active", nil).Expect(t, http.StatusOK).Data(t, &stock)
+		if len(stock) != 1 || stock[0].Quantity != 5 || stock[0].Skus != 1 {
+			t.Fatalf("filtered stock %+v", stock)
+		}
+	})
+
+	t.Run("movement history", func(t *testing.T) {
+		var history handlers.MovementHistoryResponse
+		c.Do(t, http.MethodGet, "/v1/reports/movement-history?group_by=month", nil).Expect(t, http.StatusOK).Data(t, &history)
+		if len(history.Periods) != 1 {
+			t.Fatalf("periods %+v", history.Periods)
+		}
+		period := history.Periods[0]
+		month := time.Now().UTC()
+		if !period.Period.Equal(time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)) ||
+			period.Movements != 2 || period.QuantityIn != 15 || period.NetQuantity != 15 {
+			t.Fatalf("period %+v", period)
+		}
+
+		c.Do(t, http.MethodGet, fmt.Sprintf("/v1/reports/movement-history?warehouse_id=%d", empty.ID), nil).Expect(t, http.StatusOK).Data(t, &history)
+		if len(history.Periods) != 0 {
+			t.Fatalf("empty warehouse periods %+v", history.Periods)
+		}
+		c.Do(t, http.MethodGet, "/v1/reports/movement-history?group_by=year", nil).Expect(t, http.StatusBadRequest)
+		c.Do(t, http.MethodGet, "/v1/reports/movement-history?from=2000-01-01", nil).Expect(t, http.StatusBadRequest)
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		resp := c.Do(t, http.MethodGet, "/v1/reports/stock-by-warehouse?format=csv", nil).Expect(t, http.StatusOK)
+		if resp.Header().Get("Content-Type") != "text/csv" {
+			t.Fatalf("content type %q", resp.Header().Get("Content-Type"))
+		}
+		records, err := csv.NewReader(strings.NewReader(resp.Body.String())).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 3 || records[0][0] != "warehouse_id" || records[1][1] != "Stocked" {
+			t.Fatalf("records %v", records)
+		}
+		c.Do(t, http.MethodGet, "/v1/reports/warehouse-summary?format=xml", nil).Expect(t, http.StatusBadRequest)
+	})
+
+	t.Run("tenant isolation", func(t *testing.T) {
+		var summary handlers.WarehouseSummaryResponse
+		e.Member(t, "org:member").Do(t, http.MethodGet, "/v1/reports/warehouse-summary", nil).Expect(t, http.StatusOK).Data(t, &summary)
+		if summary.Total != 0 {
+			t.Fatalf("other tenant sees %+v", summary)
+		}
+	})
+}
@@ -0,0 +1,112 @@
+//go:build integration
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"warehouse-service/handlers"
+)
+
+func TestScan(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:member")
+	warehouse := createWarehouse(t, c, "Scanning")
+	roomA := e.StorageRoom(t, c.OrgID, warehouse.ID, "SC-01", "ambient")
+	roomB := e.StorageRoom(t, c.OrgID, warehouse.ID, "SC-02", "ambient")
+	locationA := fmt.Sprintf("%d-SC-01", warehouse.ID)
+	locationB := fmt.Sprintf("%d-SC-02", warehouse.ID)
+	receiveStock(t, c, warehouse.ID, roomA, "SKU-SCAN", 5)
+	c.Do(t, http.MethodPost, "/v1/serials/receive", map[string]any{
+		"storage_room_id": roomA,
+		"sku":             "SKU-SCANSN",
+		"serial_numbers":  []string{"SCAN-SN-1"},
+	}).Expect(t, http.StatusCreated)
+
+	t.Run("resolve", func(t *testing.T) {
+		var location handlers.ScanResponse
+		c.Do(t, http.MethodGet, "/v1/scan/"+locationA, nil).Expect(t, http.StatusOK).Data(t, &location)
+		if location.Kind != "location" || location.StorageRoomID != roomA || location.OnHand != 6 {
+			t.Fatalf("location %+v", location)
+		}
+		var serial handlers.ScanResponse
+		c.Do(t, http.MethodGet, "/v1/scan/SCAN-SN-1", nil).Expect(t, http.StatusOK).Data(t, &serial)
+		if serial.Kind != "serial" || serial.Sku != "SKU-SCANSN" || serial.StorageRoomID != roomA {
+			t.Fatalf("serial %+v", serial)
+		}
+		var item handlers.ScanResponse
+		c.Do(t, http.MethodGet, "/v1/scan/SKU-SCAN", nil).Expect(t, http.StatusOK).Data(t, &item)
+		if item.Kind != "item" || item.OnHand != 5 {
+			t.Fatalf("item %+v", item)
+		}
+		c.Do(t, http.MethodGet, "/v1/scan/NOTHING-HERE", nil).Expect(t, http.StatusNotFound)
+	})
+
+	t.Run("move", func(t *testing.T) {
+		c.Do(t, http.MethodPost, "/v1/scan/move", map[string]any{
+			"code": "SKU-SCAN", "from": locationA, "to": locationB, "qty": 2,
+		}).Expect(t, http.StatusOK)
+		if a, b := stockOf(t, c, roomA, "SKU-SCAN"), stockOf(t, c, roomB, "SKU-SCAN"); a != 3 || b != 2 {
+			t.Fatalf("stock %d and %d, want 3 and 2", a, b)
+		}
+		c.Do(t, http.MethodPost, "/v1/scan/move", map[string]any{
+			"code": "SKU-SCAN", "from": locationA, "to": locationB, "qty": 9,
+		}).Expect(t, http.StatusConflict)
+		c.Do(t, http.MethodPost, "/v1/scan/move", map[string]any{
+			"code": "SKU-SCAN", "to": locationB,
+		}).Expect(t, http.StatusBadRequest)
+
+		c.Do(t, http.MethodPost, "/v1/scan/move", map[string]any{
+			"code": "SCAN-SN-1", "from": locationB, "to": locationB,
+		}).Expect(t, http.StatusConflict)
+		var moved handlers.ScanResponse
+		c.Do(t, http.MethodPost, "/v1/scan/move", map[string]any{
+			"code": "SCAN-SN-1", "to": locationB,
+		}).Expect(t, http.StatusOK).Data(t, &moved)
+		if moved.Kind != "serial" || moved.StorageRoomID != roomB {
+			t.Fatalf("moved %+v", moved)
+		}
+		if got := stockOf(t, c, roomB, "SKU-SCANSN"); got != 1 {
+			t.Fatalf("serialized stock %d, want 1", got)
+		}
+	})
+
+	t.Run("count", func(t *testing.T) {
+		count := map[string]any{"location": locationA, "code": "SKU-SCAN", "qty": 2}
+		c.Do(t, http.MethodPost, "/v1/scan/count", count).Expect(t, http.StatusConflict)
+
+		c.Do(t, http.MethodPost, "/v1/counts", map[string]any{
+			"warehouse_id": warehouse.ID,
+		}).Expect(t, http.StatusCreated)
+		var line handlers.CountLineResponse
+		c.Do(t, http.MethodPost, "/v1/scan/count", count).Expect(t, http.StatusOK).Data(t, &line)
+		if line.StorageRoomID != roomA || line.CountedQuantity == nil || *line.CountedQuantity != 2 {
+			t.Fatalf("count line %+v", line)
+		}
+		c.Do(t, http.MethodPost, "/v1/scan/count", map[string]any{
+			"location": locationA, "code": "SKU-SCAN",
+		}).Expect(t, http.StatusBadRequest)
+	})
+
+	t.Run("pick", func(t *testing.T) {
+		var pickList pickListBody
+		c.Do(t, http.MethodPost, "/v1/picklists", map[string]any{
+			"warehouse_id": warehouse.ID,
+			"items":        []map[string]any{{"sku": "SKU-SCAN", "quantity": 2}},
+		}).Expect(t, http.StatusCreated).Data(t, &pickList)
+		path := fmt.Sprintf("/v1/scan/pick/%d", pickList.PickList.ID)
+
+		var picked pickListBody
+		c.Do(t, http.MethodPost, path, map[string]any{"code": "SKU-SCAN"}).Expect(t, http.StatusOK).Data(t, &picked)
+		if picked.PickList.Status != "allocated" || picked.Lines[0].PickedQuantity != 1 {
+			t.Fatalf("after one scan %+v", picked)
+		}
+		c.Do(t, http.MethodPost, path, map[string]any{"code": "SKU-SCAN", "qty": 2}).Expect(t, http.StatusConflict)
+		c.Do(t, http.MethodPost, path, map[string]any{"code": "SKU-OTHER"}).Expect(t, http.StatusConflict)
+		c.Do(t, http.MethodPost, path, map[string]any{"code": "SKU-SCAN"}).Expect(t, http.StatusOK).Data(t, &picked)
+		if picked.PickList.Status != "picked" {
+			t.Fatalf("status %q after picking in full", picked.PickList.Status)
+		}
+	})
+}
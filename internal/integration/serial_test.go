@@ -0,0 +1,76 @@
+//go:build integration
+
+package integration
+
+import (
+	"net/http"
+	"testing"
+	"warehouse-service/handlers"
+)
+
+func TestSerials(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:member")
+	warehouse := createWarehouse(t, c, "Serials")
+	roomA := e.StorageRoom(t, c.OrgID, warehouse.ID, "S-01", "ambient")
+	roomB := e.StorageRoom(t, c.OrgID, warehouse.ID, "S-02", "ambient")
+
+	var received []handlers.SerialResponse
+	c.Do(t, http.MethodPost, "/v1/serials/receive", map[string]any{
+		"storage_room_id": roomA,
+		"sku":             "SKU-SER",
+		"serial_numbers":  []string{"SN-1", "SN-2"},
+		"reference":       "po-1",
+	}).Expect(t, http.StatusCreated).Data(t, &received)
+	if len(received) != 2 || received[0].Status != "in_stock" {
+		t.Fatalf("received %+v", received)
+	}
+	if got := stockOf(t, c, roomA, "SKU-SER"); got != 2 {
+		t.Fatalf("stock %d, want 2", got)
+	}
+	c.Do(t, http.MethodPost, "/v1/serials/receive", map[string]any{
+		"storage_room_id": roomA,
+		"sku":             "SKU-SER",
+		"serial_numbers":  []string{"SN-1"},
+	}).Expect(t, http.StatusConflict)
+
+	c.Do(t, http.MethodPost, "/v1/serials/move", map[string]any{
+		"storage_room_id": roomB,
+		"serial_numbers":  []string{"SN-1"},
+	}).Expect(t, http.StatusOK)
+	if a, b := stockOf(t, c, roomA, "SKU-SER"), stockOf(t, c, roomB, "SKU-SER"); a != 1 || b != 1 {
+		t.Fatalf("stock %d and %d, want 1 and 1", a, b)
+	}
+
+	c.Do(t, http.MethodPost, "/v1/serials/ship", map[string]any{
+		"serial_numbers": []string{"SN-1", "SN-404"},
+	}).Expect(t, http.StatusNotFound)
+	c.Do(t, http.MethodPost, "/v1/serials/ship", map[string]any{
+		"serial_numbers": []string{"SN-1"},
+		"reference":      "so-1",
+	}).Expect(t, http.StatusOK)
+	c.Do(t, http.MethodPost, "/v1/serials/move", map[string]any{
+		"storage_room_id": roomA,
+		"serial_numbers":  []string{"SN-1"},
+	}).Expect(t, http.StatusConflict)
+
+	var lookup struct {
+		Serial    handlers.SerialResponse           `json:"serial"`
+		Movements []handlers.SerialMovementResponse `json:"movements"`
+	}
+	c.Do(t, http.MethodGet, "/v1/serials/SN-1", nil).Expect(t, http.StatusOK).Data(t, &lookup)
+	if lookup.Serial.Status != "shipped" || lookup.Serial.StorageRoomID != nil || len(lookup.Movements) != 3 {
+		t.Fatalf("serial %+v", lookup)
+	}
+	if m := lookup.Movements; m[0].Action != "receive" || m[1].Action != "move" || m[2].Action != "ship" || *m[2].FromStorageRoomID != roomB {
+		t.Fatalf("movements %+v", m)
+	}
+
+	// A shipped serial can be received again as a return
+	c.Do(t, http.MethodPost, "/v1/serials/receive", map[string]any{
+		"storage_room_id": roomA,
+		"sku":             "SKU-SER",
+		"serial_numbers":  []string{"SN-1"},
+	}).Expect(t, http.StatusCreated)
+	c.Do(t, http.MethodGet, "/v1/serials/SN-404", nil).Expect(t, http.StatusNotFound)
+}
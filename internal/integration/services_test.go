@@ -0,0 +1,218 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+	"warehouse-service/dbroute"
+	"warehouse-service/handlers"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/quota"
+	"warehouse-service/routes"
+	"warehouse-service/services"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// withServices returns an environment whose pick lists run sagas calling
+// the sibling services through siblings, and the handlers running their
+// jobs
+func (e *Env) withServices(siblings handlers.Services) (*Env, *handlers.Handlers) {
+	router := gin.New()
+	r := routes.NewRoute(dbroute.New(e.DB, nil, time.Second), nil, nil, nil, nil, nil, quota.Limits{}, handlers.Attachments{}, siblings, nil, nil)
+	r.AddPickListRoutes(router)
+	r.AddAdminRoutes(router)
+	linked := *e
+	linked.handler = router
+	return &linked, r.Handlers()
+}
+
+// runJobs runs the queued jobs of kind for orgID with run, oldest first
+func (e *Env) runJobs(t *testing.T, orgID, kind string, run func(context.Context, models.Job) error) int {
+	t.Helper()
+	ctx := context.Background()
+	rows, err := e.DB.Query(ctx, `DELETE FROM job WHERE org_id = $1 AND kind = $2 RETURNING id, payload`, orgID, kind)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var queued []models.Job
+	for rows.Next() {
+		job := models.Job{OrgID: orgID, Kind: kind}
+		if err := rows.Scan(&job.ID, &job.Payload); err != nil {
+			t.Fatal(err)
+		}
+		queued = append(queued, job)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	for _, job := range queued {
+		if err := run(ctx, job); err != nil {
+			t.Fatalf("job %d: %v", job.ID, err)
+		}
+	}
+	return len(queued)
+}
+
+// sagaOf returns the saga of a pick list with its steps
+func sagaOf(t *testing.T, c *Client, pickListID int64) handlers.SagaResponse {
+	t.Helper()
+	var sagas []handlers.SagaResponse
+	c.Do(t, http.MethodGet, "/v1/sagas?limit=100", nil).Expect(t, http.StatusOK).Data(t, &sagas)
+	for _, saga := range sagas {
+		if saga.PickListID == pickListID {
+			c.Do(t, http.MethodGet, fmt.Sprintf("/v1/sagas/%d", saga.ID), nil).Expect(t, http.StatusOK).Data(t, &saga)
+			return saga
+		}
+	}
+	t.Fatalf("no saga of pick list %d", pickListID)
+	return handlers.SagaResponse{}
+}
+
+func stepStatuses(saga handlers.SagaResponse) []string {
+	var out []string
+	for _, step := range saga.Steps {
+		out = append(out, step.Name+"="+step.Status)
+	}
+	return out
+}
+
+func TestFulfillmentSaga(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:member")
+	warehouse := createWarehouse(t, c, "Linked")
+	roomID := e.StorageRoom(t, c.OrgID, warehouse.ID, "L-01", "ambient")
+	receiveStock(t, c, warehouse.ID, roomID, "SKU-L", 10)
+
+	inventory := &services.StubInventory{}
+	orders := &services.StubOrders{}
+	linked, h := e.withServices(handlers.Services{Inventory: inventory, Orders: orders})
+	lc := linked.WithToken(e.Token(t, "user_linked_"+c.OrgID, c.OrgID, "org:member"), c.OrgID)
+	admin := linked.WithToken(e.Token(t, "user_linked_admin_"+c.OrgID, c.OrgID, "org:admin"), c.OrgID)
+
+	create := func(reference string) pickListBody {
+		var body pickListBody
+		lc.Do(t, http.MethodPost, "/v1/picklists", map[string]any{
+			"warehouse_id": warehouse.ID,
+			"reference":    reference,
+			"items":        []map[string]any{{"sku": "SKU-L", "quantity": 3}},
+		}).Expect(t, http.StatusCreated).Data(t, &body)
+		return body
+	}
+	ship := func(body pickListBody, picked int) {
+		lc.Do(t, http.MethodPost, fmt.Sprintf("/v1/picklists/%d/pick", body.PickList.ID), map[string]any{
+			"lines":    []map[string]any{{"line_id": body.Lines[0].ID, "picked_quantity": picked}},
+			"complete": true,
+		}).Expect(t, http.StatusOK)
+		lc.Do(t, http.MethodPost, fmt.Sprintf("/v1/picklists/%d/ship", body.PickList.ID), nil).Expect(t, http.StatusOK)
+	}
+	advance := func(want int) {
+		t.Helper()
+		if n := e.runJobs(t, c.OrgID, handlers.JobKindAdvanceSaga, h.AdvanceSagaJob); n != want {
+			t.Fatalf("ran %d advance_saga jobs, want %d", n, want)
+		}
+	}
+
+	shipped := create("SO-77")
+	cancelled := create("SO-78")
+	advance(2)
+	reservations := inventory.Reservations()
+	if len(reservations) != 2 || reservations[0].WarehouseID != warehouse.ID ||
+		len(reservations[0].Lines) != 1 || reservations[0].Lines[0] != (services.Line{Sku: "SKU-L", Quantity: 3}) {
+		t.Fatalf("reservations %+v", reservations)
+	}
+	saga := sagaOf(t, lc, shipped.PickList.ID)
+	if saga.Status != "running" || fmt.Sprint(stepStatuses(saga)) != "[allocate=succeeded reserve=succeeded ship=pending release=pending confirm=pending]" {
+		t.Fatalf("saga %+v", saga)
+	}
+
+	// A service failing for now leaves the step to be retried
+	ship(shipped, 2)
+	inventory.Fail(services.ErrCircuitOpen)
+	ctx := context.Background()
+	if err := h.AdvanceSagaJob(ctx, models.Job{OrgID: c.OrgID, Payload: fmt.Appendf(nil, `{"saga_id":%d}`, saga.ID)}); err == nil {
+		t.Fatal("saga advanced with inventory-service failing")
+	}
+	saga = sagaOf(t, lc, shipped.PickList.ID)
+	if release := saga.Steps[3]; saga.Status != "running" || release.Status != "pending" || release.Attempts != 1 || release.Error == "" {
+		t.Fatalf("saga %+v", saga)
+	}
+	inventory.Fail(nil)
+
+	// order-service refusing the shipment for good puts the stock back and
+	// releases the reservation
+	orders.Fail(status.Error(codes.FailedPrecondition, "order is closed"))
+	lc.Do(t, http.MethodPost, fmt.Sprintf("/v1/picklists/%d/cancel", cancelled.PickList.ID), nil).Expect(t, http.StatusOK)
+	advance(2)
+	saga = sagaOf(t, lc, shipped.PickList.ID)
+	if saga.Status != "compensated" || !strings.Contains(saga.Error, "order is closed") ||
+		fmt.Sprint(stepStatuses(saga)) != "[allocate=compensated reserve=compensated ship=compensated release=compensated confirm=failed]" {
+		t.Fatalf("saga %+v", saga)
+	}
+	if got := stockOf(t, c, roomID, "SKU-L"); got != 10 {
+		t.Errorf("stock %d after the shipment was reversed", got)
+	}
+	saga = sagaOf(t, lc, cancelled.PickList.ID)
+	if saga.Status != "compensated" || fmt.Sprint(stepStatuses(saga)) != "[allocate=compensated reserve=compensated ship=failed release=pending confirm=pending]" {
+		t.Fatalf("saga %+v", saga)
+	}
+	if reservations := inventory.Reservations(); len(reservations) != 0 {
+		t.Fatalf("reservations left %+v", reservations)
+	}
+	if shipments := orders.Shipments(); len(shipments) != 0 {
+		t.Fatalf("shipments %+v", shipments)
+	}
+	orders.Fail(nil)
+
+	// A saga that ran through confirms the shipment
+	completed := create("SO-79")
+	advance(1)
+	ship(completed, 3)
+	advance(1)
+	saga = sagaOf(t, lc, completed.PickList.ID)
+	if saga.Status != "completed" {
+		t.Fatalf("saga %+v", saga)
+	}
+	shipments := orders.Shipments()
+	if len(shipments) != 1 || shipments[0].OrderReference != "SO-79" || shipments[0].Reference != fmt.Sprintf("pick_list:%d", completed.PickList.ID) ||
+		shipments[0].WarehouseID != warehouse.ID || len(shipments[0].Lines) != 1 || shipments[0].Lines[0].Quantity != 3 {
+		t.Fatalf("shipments %+v", shipments)
+	}
+	if reservations := inventory.Reservations(); len(reservations) != 0 {
+		t.Fatalf("reservations left %+v", reservations)
+	}
+	admin.Do(t, http.MethodPost, fmt.Sprintf("/v1/admin/sagas/%d/compensate", saga.ID), nil).Expect(t, http.StatusConflict)
+
+	// An operator gives up on a running saga
+	given := create("SO-80")
+	advance(1)
+	saga = sagaOf(t, lc, given.PickList.ID)
+	lc.Do(t, http.MethodPost, fmt.Sprintf("/v1/admin/sagas/%d/compensate", saga.ID), nil).Expect(t, http.StatusForbidden)
+	admin.Do(t, http.MethodPost, fmt.Sprintf("/v1/admin/sagas/%d/compensate", saga.ID), nil).Expect(t, http.StatusAccepted)
+	advance(1)
+	var pickList pickListBody
+	lc.Do(t, http.MethodGet, fmt.Sprintf("/v1/picklists/%d", given.PickList.ID), nil).Expect(t, http.StatusOK).Data(t, &pickList)
+	if saga = sagaOf(t, lc, given.PickList.ID); saga.Status != "compensated" || pickList.PickList.Status != "cancelled" {
+		t.Fatalf("saga %+v, pick list %s", saga, pickList.PickList.Status)
+	}
+	var list []handlers.SagaResponse
+	lc.Do(t, http.MethodGet, "/v1/sagas?status=completed", nil).Expect(t, http.StatusOK).Data(t, &list)
+	if len(list) != 1 || list[0].PickListID != completed.PickList.ID || list[0].Steps != nil {
+		t.Fatalf("completed sagas %+v", list)
+	}
+	lc.Do(t, http.MethodGet, "/v1/sagas?status=stuck", nil).Expect(t, http.StatusBadRequest)
+
+	// Without the services no saga starts
+	c.Do(t, http.MethodPost, "/v1/picklists", map[string]any{
+		"warehouse_id": warehouse.ID,
+		"items":        []map[string]any{{"sku": "SKU-L", "quantity": 1}},
+	}).Expect(t, http.StatusCreated)
+	advance(0)
+}
@@ -0,0 +1,161 @@
+//go:build integration
+
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"warehouse-service/handlers"
+)
+
+func TestStorageRooms(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:member")
+	warehouse := createWarehouse(t, c, "Rooms")
+	roomID := e.StorageRoom(t, c.OrgID, warehouse.ID, "A-01", "ambient")
+
+	t.Run("patch", func(t *testing.T) {
+		var room handlers.StorageRoomResponse
+		c.Do(t, http.MethodPatch, fmt.Sprintf("/v1/storageroom/%d", roomID), map[string]any{
+			"name":      "Chiller",
+			"zone_type": "chilled",
+		}).Expect(t, http.StatusOK).Data(t, &room)
+		if room.Name != "Chiller" || room.ZoneType != "chilled" || room.Number != "A-01" {
+			t.Fatalf("patched %+v", room)
+		}
+		c.Do(t, http.MethodPatch, fmt.Sprintf("/v1/storageroom/%d", roomID), map[string]any{
+			"zone_type": "tropical",
+		}).Expect(t, http.StatusBadRequest)
+	})
+
+	t.Run("batch get", func(t *testing.T) {
+		var batch struct {
+			Found   map[string]handlers.StorageRoomResponse `json:"found"`
+			Missing []int64                                 `json:"missing"`
+		}
+		c.Do(t, http.MethodPost, "/v1/storageroom/batch-get", map[string]any{
+			"ids": []int64{int64(roomID), 1},
+		}).Expect(t, http.StatusOK).Data(t, &batch)
+		if len(batch.Found) != 1 || len(batch.Missing) != 1 {
+			t.Fatalf("batch %+v", batch)
+		}
+	})
+
+	t.Run("capacity", func(t *testing.T) {
+		var room handlers.StorageRoomResponse
+		c.Do(t, http.MethodPatch, fmt.Sprintf("/v1/storageroom/%d", roomID), map[string]any{
+			"capacity": 10,
+		}).Expect(t, http.StatusOK).Data(t, &room)
+		if room.Capacity != 10 || room.Occupancy == nil || *room.Occupancy != 0 {
+			t.Fatalf("patched %+v", room)
+		}
+		receiveStock(t, c, warehouse.ID, roomID, "SKU-CAP", 8)
+
+		var created struct {
+			Receipt handlers.ReceiptResponse       `json:"receipt"`
+			Lines   []handlers.ReceiptLineResponse `json:"lines"`
+		}
+		c.Do(t, http.MethodPost, "/v1/receipts", map[string]any{
+			"warehouse_id": warehouse.ID,
+			"lines":        []map[string]any{{"sku": "SKU-CAP", "expected_quantity": 5}},
+		}).Expect(t, http.StatusCreated).Data(t, &created)
+		receive := map[string]any{
+			"lines": []map[string]any{{
+				"line_id":         created.Lines[0].ID,
+				"storage_room_id": roomID,
+				"quantity":        5,
+			}},
+		}
+		path := fmt.Sprintf("/v1/receipts/%d/receive", created.Receipt.ID)
+		c.Do(t, http.MethodPost, path, receive).Expect(t, http.StatusConflict)
+		if got := stockOf(t, c, roomID, "SKU-CAP"); got != 8 {
+			t.Fatalf("stock %d after rejected placement, want 8", got)
+		}
+		receive["override_capacity"] = true
+		c.Do(t, http.MethodPost, path, receive).Expect(t, http.StatusOK)
+
+		var rooms []handlers.StorageRoomResponse
+		c.Do(t, http.MethodGet, fmt.Sprintf("/v1/storageroom/list?warehouse_id=%d", warehouse.ID), nil).
+			Expect(t, http.StatusOK).Data(t, &rooms)
+		if len(rooms) != 1 || rooms[0].Occupancy == nil || *rooms[0].Occupancy != 13 {
+			t.Fatalf("rooms %+v", rooms)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		full := e.StorageRoom(t, c.OrgID, warehouse.ID, "D-01", "ambient")
+		target := e.StorageRoom(t, c.OrgID, warehouse.ID, "D-02", "ambient")
+		receiveStock(t, c, warehouse.ID, full, "SKU-DEL", 6)
+		c.Do(t, http.MethodPost, "/v1/serials/receive", map[string]any{
+			"storage_room_id": full,
+			"sku":             "SKU-DEL-SER",
+			"serial_numbers":  []string{"SN-DEL-1"},
+		}).Expect(t, http.StatusCreated)
+
+		path := fmt.Sprintf("/v1/storageroom/%d", full)
+		rec := c.Do(t, http.MethodDelete, path, nil).Expect(t, http.StatusConflict)
+		var blocked struct {
+			SkuCount int                           `json:"sku_count"`
+			Quantity int64                         `json:"quantity"`
+			Stock    []handlers.StockLevelResponse `json:"stock"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &blocked); err != nil {
+			t.Fatalf("decode conflict: %v", err)
+		}
+		if blocked.SkuCount != 2 || blocked.Quantity != 7 || len(blocked.Stock) != 2 {
+			t.Fatalf("blocked %+v", blocked)
+		}
+		c.Do(t, http.MethodDelete, path+"?relocate_to=2147400000", nil).Expect(t, http.StatusBadRequest)
+		c.Do(t, http.MethodDelete, fmt.Sprintf("%s?relocate_to=%d", path, full), nil).Expect(t, http.StatusBadRequest)
+
+		var deleted handlers.StorageRoomDeleteResponse
+		c.Do(t, http.MethodDelete, fmt.Sprintf("%s?relocate_to=%d", path, target), nil).
+			Expect(t, http.StatusOK).Data(t, &deleted)
+		if deleted.RelocatedTo == nil || *deleted.RelocatedTo != target || len(deleted.Relocated) != 2 || deleted.SerialCount != 1 {
+			t.Fatalf("deleted %+v", deleted)
+		}
+		if got := stockOf(t, c, target, "SKU-DEL"); got != 6 {
+			t.Fatalf("relocated stock %d, want 6", got)
+		}
+		var lookup struct {
+			Serial handlers.SerialResponse `json:"serial"`
+		}
+		c.Do(t, http.MethodGet, "/v1/serials/SN-DEL-1", nil).Expect(t, http.StatusOK).Data(t, &lookup)
+		if lookup.Serial.StorageRoomID == nil || *lookup.Serial.StorageRoomID != target {
+			t.Fatalf("serial %+v", lookup.Serial)
+		}
+		c.Do(t, http.MethodDelete, path, nil).Expect(t, http.StatusNotFound)
+
+		empty := e.StorageRoom(t, c.OrgID, warehouse.ID, "D-03", "ambient")
+		c.Do(t, http.MethodDelete, fmt.Sprintf("/v1/storageroom/%d", empty), nil).Expect(t, http.StatusOK)
+	})
+
+	t.Run("conditional delete", func(t *testing.T) {
+		path := fmt.Sprintf("/v1/storageroom/%d", e.StorageRoom(t, c.OrgID, warehouse.ID, "D-04", "ambient"))
+		seen := c.Do(t, http.MethodPatch, path, map[string]any{"name": "Seen"}).Expect(t, http.StatusOK).Header().Get("ETag")
+		changed := c.Do(t, http.MethodPatch, path, map[string]any{"name": "Changed"}).Expect(t, http.StatusOK).Header().Get("ETag")
+		if seen == "" || changed == seen {
+			t.Fatalf("ETags %q and %q", seen, changed)
+		}
+
+		c.Header = http.Header{"If-Match": {seen}}
+		c.Do(t, http.MethodDelete, path, nil).Expect(t, http.StatusPreconditionFailed)
+		c.Header = http.Header{"If-Match": {changed}}
+		c.Do(t, http.MethodDelete, path, nil).Expect(t, http.StatusOK)
+		c.Header = nil
+	})
+
+	t.Run("labels", func(t *testing.T) {
+		rec := c.Do(t, http.MethodGet, fmt.Sprintf("/v1/storageroom/%d/label", roomID), nil).Expect(t, http.StatusOK)
+		if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "image/png") {
+			t.Fatalf("label content type %q", ct)
+		}
+		c.Do(t, http.MethodGet, fmt.Sprintf("/v1/location/%d-A-01/label", warehouse.ID), nil).Expect(t, http.StatusOK)
+		c.Do(t, http.MethodGet, "/v1/location/bogus/label", nil).Expect(t, http.StatusBadRequest)
+		e.Member(t, "org:member").Do(t, http.MethodGet, fmt.Sprintf("/v1/storageroom/%d/label", roomID), nil).
+			Expect(t, http.StatusNotFound)
+	})
+}
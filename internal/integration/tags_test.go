@@ -0,0 +1,108 @@
+//go:build integration
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"warehouse-service/handlers"
+)
+
+func TestWarehouseTags(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:member")
+	warehouse := createWarehouse(t, c, "Tagged")
+	other := createWarehouse(t, c, "Untagged")
+	path := fmt.Sprintf("/v1/warehouse/%d/tags", warehouse.ID)
+
+	var got handlers.WarehouseResponse
+	c.Do(t, http.MethodPost, path, map[string]any{"tags": []string{"Cold-Chain", "eu", "eu"}}).
+		Expect(t, http.StatusOK).Data(t, &got)
+	c.Do(t, http.MethodPost, path, map[string]any{"tags": []string{"eu", "hub"}}).
+		Expect(t, http.StatusOK).Data(t, &got)
+	if fmt.Sprint(got.Tags) != "[cold-chain eu hub]" {
+		t.Fatalf("tags %v", got.Tags)
+	}
+
+	t.Run("filter", func(t *testing.T) {
+		var list []handlers.WarehouseResponse
+		c.Do(t, http.MethodGet, "/v1/warehouse/list?tag=cold-chain&tag=eu", nil).Expect(t, http.StatusOK).Data(t, &list)
+		if len(list) != 1 || list[0].ID != warehouse.ID {
+			t.Fatalf("list %+v, want only %d and not %d", list, warehouse.ID, other.ID)
+		}
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		var removed handlers.WarehouseResponse
+		c.Do(t, http.MethodDelete, path+"/EU", nil).Expect(t, http.StatusOK).Data(t, &removed)
+		if fmt.Sprint(removed.Tags) != "[cold-chain hub]" {
+			t.Fatalf("tags %v", removed.Tags)
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		c.Do(t, http.MethodPost, path, map[string]any{"tags": []string{" "}}).Expect(t, http.StatusBadRequest)
+	})
+
+	t.Run("too many", func(t *testing.T) {
+		tags := make([]string, 19)
+		for i := range tags {
+			tags[i] = fmt.Sprintf("t%d", i)
+		}
+		c.Do(t, http.MethodPost, path, map[string]any{"tags": tags}).Expect(t, http.StatusBadRequest)
+	})
+
+	t.Run("other tenants get 404", func(t *testing.T) {
+		e.Member(t, "org:member").Do(t, http.MethodPost, path, map[string]any{"tags": []string{"eu"}}).
+			Expect(t, http.StatusNotFound)
+	})
+}
+
+func TestStorageRoomTags(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:member")
+	warehouse := createWarehouse(t, c, "Rooms")
+	tagged := e.StorageRoom(t, c.OrgID, warehouse.ID, "A-01", "chilled")
+	e.StorageRoom(t, c.OrgID, warehouse.ID, "A-02", "ambient")
+	path := fmt.Sprintf("/v1/storageroom/%d/tags", tagged)
+
+	var got handlers.StorageRoomResponse
+	c.Do(t, http.MethodPost, path, map[string]any{"tags": []string{"cold-chain", "eu"}}).
+		Expect(t, http.StatusOK).Data(t, &got)
+	if fmt.Sprint(got.Tags) != "[cold-chain eu]" {
+		t.Fatalf("tags %v", got.Tags)
+	}
+
+	t.Run("list", func(t *testing.T) {
+		var all []handlers.StorageRoomResponse
+		c.Do(t, http.MethodGet, fmt.Sprintf("/v1/storageroom/list?warehouse_id=%d", warehouse.ID), nil).
+			Expect(t, http.StatusOK).Data(t, &all)
+		if len(all) != 2 {
+			t.Fatalf("rooms %+v", all)
+		}
+		var filtered []handlers.StorageRoomResponse
+		c.Do(t, http.MethodGet, "/v1/storageroom/list?tag=eu&tag=cold-chain", nil).
+			Expect(t, http.StatusOK).Data(t, &filtered)
+		if len(filtered) != 1 || filtered[0].ID != tagged {
+			t.Fatalf("rooms %+v", filtered)
+		}
+	})
+
+	t.Run("patch replaces", func(t *testing.T) {
+		var patched handlers.StorageRoomResponse
+		c.Do(t, http.MethodPatch, fmt.Sprintf("/v1/storageroom/%d", tagged), map[string]any{"tags": []string{"hub"}}).
+			Expect(t, http.StatusOK).Data(t, &patched)
+		if fmt.Sprint(patched.Tags) != "[hub]" {
+			t.Fatalf("tags %v", patched.Tags)
+		}
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		var removed handlers.StorageRoomResponse
+		c.Do(t, http.MethodDelete, path+"/hub", nil).Expect(t, http.StatusOK).Data(t, &removed)
+		if len(removed.Tags) != 0 {
+			t.Fatalf("tags %v", removed.Tags)
+		}
+	})
+}
@@ -0,0 +1,70 @@
+//go:build integration
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+	"warehouse-service/handlers"
+)
+
+func TestTemperatureTelemetry(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:member")
+	warehouse := createWarehouse(t, c, "Cold chain")
+	roomID := e.StorageRoom(t, c.OrgID, warehouse.ID, "F-01", "frozen")
+
+	ingest := func(celsius float64, at time.Time) {
+		t.Helper()
+		c.Do(t, http.MethodPost, "/v1/telemetry/temperature", map[string]any{
+			"readings": []map[string]any{{
+				"storage_room_id": roomID,
+				"sensor_id":       "probe-1",
+				"celsius":         celsius,
+				"recorded_at":     at,
+			}},
+		}).Expect(t, http.StatusOK)
+	}
+	breaches := func(query string) []handlers.TemperatureBreachResponse {
+		t.Helper()
+		var list []handlers.TemperatureBreachResponse
+		c.Do(t, http.MethodGet, "/v1/telemetry/breaches"+query, nil).Expect(t, http.StatusOK).Data(t, &list)
+		return list
+	}
+
+	now := time.Now().UTC()
+	ingest(-20, now.Add(-3*time.Minute))
+	if open := breaches("?open=true"); len(open) != 0 {
+		t.Fatalf("breach opened within thresholds: %+v", open)
+	}
+
+	ingest(-10, now.Add(-2*time.Minute))
+	ingest(-5, now.Add(-time.Minute))
+	open := breaches(fmt.Sprintf("?open=true&storage_room_id=%d", roomID))
+	if len(open) != 1 || open[0].PeakCelsius != -5 || open[0].ResolvedAt != nil {
+		t.Fatalf("open breaches %+v", open)
+	}
+
+	ingest(-22, now)
+	if open := breaches("?open=true"); len(open) != 0 {
+		t.Fatalf("breach not resolved: %+v", open)
+	}
+	if all := breaches(""); len(all) != 1 {
+		t.Fatalf("breaches %+v", all)
+	}
+
+	t.Run("rejects stale and unknown rooms", func(t *testing.T) {
+		c.Do(t, http.MethodPost, "/v1/telemetry/temperature", map[string]any{
+			"readings": []map[string]any{{
+				"storage_room_id": roomID, "sensor_id": "probe-1", "celsius": -20, "recorded_at": now.Add(-30 * 24 * time.Hour),
+			}},
+		}).Expect(t, http.StatusBadRequest)
+		e.Member(t, "org:member").Do(t, http.MethodPost, "/v1/telemetry/temperature", map[string]any{
+			"readings": []map[string]any{{
+				"storage_room_id": roomID, "sensor_id": "probe-1", "celsius": -20, "recorded_at": now,
+			}},
+		}).Expect(t, http.StatusBadRequest)
+	})
+}
@@ -0,0 +1,237 @@
+//go:build integration
+
+package integration
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+	"warehouse-service/dbroute"
+	"warehouse-service/handlers"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/quota"
+	"warehouse-service/routes"
+
+	"github.com/gin-gonic/gin"
+)
+
+// withTenantData returns an environment whose admin routes keep exports in
+// store, and the handlers running their jobs
+func (e *Env) withTenantData(store *memStore) (*Env, *handlers.Handlers) {
+	router := gin.New()
+	r := routes.NewRoute(dbroute.New(e.DB, nil, time.Second), nil, nil, nil, nil, nil, quota.Limits{}, handlers.Attachments{
+		Store:  store,
+		URLTTL: 5 * time.Minute,
+	}, handlers.Services{}, nil, nil)
+	r.AddAdminRoutes(router)
+	linked := *e
+	linked.handler = router
+	return &linked, r.Handlers()
+}
+
+// tenantRows counts the rows of table owned by orgID
+func tenantRows(t *testing.T, e *Env, table, orgID string) int {
+	t.Helper()
+	var n int
+	if err := e.DB.QueryRow(context.Background(), `SELECT count(*) FROM `+table+` WHERE org_id = $1`, orgID).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestTenantExport(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:admin")
+	warehouse := createWarehouse(t, c, "Exported")
+	roomID := e.StorageRoom(t, c.OrgID, warehouse.ID, "EX-01", "ambient")
+	receiveStock(t, c, warehouse.ID, roomID, "SKU-EX", 4)
+
+	store := &memStore{objects: map[string][]byte{}}
+	env, h := e.withTenantData(store)
+	admin := env.WithToken(c.token, c.OrgID)
+	path := "/v1/admin/tenants/" + c.OrgID
+
+	// Only the caller's own organization
+	other := env.WithToken(e.Member(t, "org:admin").token, "")
+	other.Do(t, http.MethodPost, path+"/export", nil).Expect(t, http.StatusNotFound)
+
+	var export handlers.TenantExportResponse
+	admin.Do(t, http.MethodPost, path+"/export", map[string]any{"format": "csv"}).Expect(t, http.StatusAccepted).Data(t, &export)
+	if export.Status != "pending" || export.Format != "csv" {
+		t.Fatalf("export is %s in %s", export.Status, export.Format)
+	}
+	if n := env.runJobs(t, c.OrgID, handlers.JobKindExportTenant, h.ExportTenantJob); n != 1 {
+		t.Fatalf("ran %d export jobs", n)
+	}
+
+	admin.Do(t, http.MethodGet, fmt.Sprintf("%s/exports/%d", path, export.ID), nil).Expect(t, http.StatusOK).Data(t, &export)
+	if export.Status != "completed" || export.DownloadURL == "" || export.Checksum == "" {
+		t.Fatalf("export is %s with URL %q", export.Status, export.DownloadURL)
+	}
+	var tables map[string]int64
+	if err := json.Unmarshal(export.Tables, &tables); err != nil {
+		t.Fatal(err)
+	}
+	if tables["warehouse"] != 1 || tables["stock_level"] != 1 {
+		t.Fatalf("exported %v", tables)
+	}
+
+	archive := store.objects[fmt.Sprintf("tenant-exports/%s/%d.zip", c.OrgID, export.ID)]
+	if int64(len(archive)) != export.SizeBytes {
+		t.Fatalf("archive of %d bytes, export says %d", len(archive), export.SizeBytes)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(r)
+		r.Close()
+		files[f.Name] = string(data)
+	}
+	if !strings.Contains(files["warehouse.csv"], "Exported") || !strings.Contains(files["manifest.json"], c.OrgID) {
+		t.Fatalf("archive holds %d files, warehouse.csv:\n%s", len(files), files["warehouse.csv"])
+	}
+	if strings.Contains(files["integration.csv"], "token") || strings.Contains(files["api_key.csv"], "secret_hash") {
+		t.Fatal("credentials were exported")
+	}
+
+	var exports []handlers.TenantExportResponse
+	admin.Do(t, http.MethodGet, path+"/exports", nil).Expect(t, http.StatusOK).Data(t, &exports)
+	if len(exports) != 1 || exports[0].DownloadURL != "" {
+		t.Fatalf("listed %+v", exports)
+	}
+}
+
+func TestTenantDeletion(t *testing.T) {
+	e := requireEnv(t)
+	ctx := context.Background()
+	store := &memStore{objects: map[string][]byte{}}
+	env, h := e.withTenantData(store)
+
+	seed := func(t *testing.T, name string) *Client {
+		c := e.Member(t, "org:admin")
+		warehouse := createWarehouse(t, c, name)
+		roomID := e.StorageRoom(t, c.OrgID, warehouse.ID, "DEL-01", "ambient")
+		receiveStock(t, c, warehouse.ID, roomID, "SKU-DEL", 3)
+		if _, err := e.DB.Exec(ctx, `UPDATE warehouse SET contact_email = 'ops@example.com' WHERE id = $1`, warehouse.ID); err != nil {
+			t.Fatal(err)
+		}
+		store.objects["attachments/"+c.OrgID+"/lease.pdf"] = []byte("%PDF")
+		if _, err := e.DB.Exec(ctx, `INSERT INTO attachment (org_id, warehouse_id, kind, file_name, content_type, size_bytes, checksum, object_key, uploaded_by)
+			VALUES ($1, $2, 'lease', 'lease.pdf', 'application/pdf', 4, '', $3, 'user')`, c.OrgID, warehouse.ID, "attachments/"+c.OrgID+"/lease.pdf"); err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	deletion := func(t *testing.T, c *Client, mode string) handlers.TenantDeletionResponse {
+		t.Helper()
+		admin := env.WithToken(c.token, c.OrgID)
+		path := "/v1/admin/tenants/" + c.OrgID + "/deletion"
+		admin.Do(t, http.MethodPost, path, map[string]any{"mode": mode, "confirm": "someone-else"}).Expect(t, http.StatusBadRequest)
+		var scheduled handlers.TenantDeletionResponse
+		admin.Do(t, http.MethodPost, path, map[string]any{"mode": mode, "confirm": c.OrgID}).Expect(t, http.StatusAccepted).Data(t, &scheduled)
+		if scheduled.Status != "scheduled" || scheduled.RunAfter == nil || scheduled.RunAfter.Before(time.Now().Add(time.Hour)) {
+			t.Fatalf("deletion is %s to run after %v", scheduled.Status, scheduled.RunAfter)
+		}
+		admin.Do(t, http.MethodPost, path, map[string]any{"mode": mode, "confirm": c.OrgID}).Expect(t, http.StatusConflict)
+
+		if n := env.runJobs(t, c.OrgID, handlers.JobKindDeleteTenant, h.DeleteTenantJob); n != 1 {
+			t.Fatalf("ran %d deletion jobs", n)
+		}
+		var done handlers.TenantDeletionResponse
+		admin.Do(t, http.MethodGet, path, nil).Expect(t, http.StatusOK).Data(t, &done)
+		return done
+	}
+
+	bystander := seed(t, "Bystander")
+
+	t.Run("cancel", func(t *testing.T) {
+		c := e.Member(t, "org:admin")
+		admin := env.WithToken(c.token, c.OrgID)
+		path := "/v1/admin/tenants/" + c.OrgID + "/deletion"
+		admin.Do(t, http.MethodDelete, path, nil).Expect(t, http.StatusConflict)
+		admin.Do(t, http.MethodPost, path, map[string]any{"mode": "purge", "confirm": c.OrgID}).Expect(t, http.StatusAccepted)
+		admin.Do(t, http.MethodDelete, path, nil).Expect(t, http.StatusOK)
+		// The job finds the deletion cancelled and does nothing
+		env.runJobs(t, c.OrgID, handlers.JobKindDeleteTenant, h.DeleteTenantJob)
+		var cancelled handlers.TenantDeletionResponse
+		admin.Do(t, http.MethodGet, path, nil).Expect(t, http.StatusOK).Data(t, &cancelled)
+		if cancelled.Status != "cancelled" {
+			t.Fatalf("deletion is %s", cancelled.Status)
+		}
+	})
+
+	t.Run("purge", func(t *testing.T) {
+		c := seed(t, "Purged")
+		done := deletion(t, c, "purge")
+		if done.Status != "completed" {
+			t.Fatalf("deletion is %s: %s\n%s", done.Status, done.Error, done.Report)
+		}
+		var report struct {
+			ObjectsDeleted int  `json:"objects_deleted"`
+			Verified       bool `json:"verified"`
+		}
+		if err := json.Unmarshal(done.Report, &report); err != nil {
+			t.Fatal(err)
+		}
+		if !report.Verified || report.ObjectsDeleted != 1 {
+			t.Fatalf("report %s", done.Report)
+		}
+		for _, table := range []string{"warehouse", "storage_room", "stock_level", "receipt", "audit_log", "row_history", "attachment"} {
+			if n := tenantRows(t, e, table, c.OrgID); n != 0 {
+				t.Errorf("%d %s rows left", n, table)
+			}
+		}
+		if _, ok := store.objects["attachments/"+c.OrgID+"/lease.pdf"]; ok {
+			t.Error("attachment object left")
+		}
+	})
+
+	t.Run("anonymize", func(t *testing.T) {
+		c := seed(t, "Anonymized")
+		done := deletion(t, c, "anonymize")
+		if done.Status != "completed" || !strings.HasPrefix(done.Pseudonym, "anonymized:") {
+			t.Fatalf("deletion is %s as %q: %s\n%s", done.Status, done.Pseudonym, done.Error, done.Report)
+		}
+		if n := tenantRows(t, e, "warehouse", c.OrgID); n != 0 {
+			t.Fatalf("%d warehouses left under the organization", n)
+		}
+		var warehouse models.Warehouse
+		if err := e.DB.QueryRow(ctx, `SELECT name, contact_email FROM warehouse WHERE org_id = $1`, done.Pseudonym).
+			Scan(&warehouse.Name, &warehouse.ContactEmail); err != nil {
+			t.Fatal(err)
+		}
+		if warehouse.Name != "Anonymized" || warehouse.ContactEmail.Valid {
+			t.Fatalf("kept %+v", warehouse)
+		}
+		if n := tenantRows(t, e, "stock_level", done.Pseudonym); n != 1 {
+			t.Fatalf("%d stock levels kept", n)
+		}
+		if n := tenantRows(t, e, "attachment", done.Pseudonym); n != 0 {
+			t.Fatalf("%d attachments kept", n)
+		}
+	})
+
+	// Other organizations keep everything
+	for _, table := range []string{"warehouse", "stock_level", "attachment"} {
+		if n := tenantRows(t, e, table, bystander.OrgID); n != 1 {
+			t.Errorf("bystander has %d %s rows", n, table)
+		}
+	}
+	if _, ok := store.objects["attachments/"+bystander.OrgID+"/lease.pdf"]; !ok {
+		t.Error("bystander's attachment object was deleted")
+	}
+}
@@ -0,0 +1,144 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"warehouse-service/handlers"
+	"warehouse-service/outbox"
+)
+
+type transferBody struct {
+	Transfer handlers.TransferOrderResponse       `json:"transfer"`
+	Lines    []handlers.TransferOrderLineResponse `json:"lines"`
+}
+
+func TestTransferOrders(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:member")
+	source := createWarehouse(t, c, "Transfer Source")
+	destination := createWarehouse(t, c, "Transfer Destination")
+	from := e.StorageRoom(t, c.OrgID, source.ID, "TS-01", "ambient")
+	to := e.StorageRoom(t, c.OrgID, destination.ID, "TD-01", "ambient")
+	receiveStock(t, c, source.ID, from, "SKU-XFER", 10)
+
+	c.Do(t, http.MethodPost, "/v1/transfers", map[string]any{
+		"source_warehouse_id":      source.ID,
+		"destination_warehouse_id": source.ID,
+		"items":                    []map[string]any{{"sku": "SKU-XFER", "quantity": 4}},
+	}).Expect(t, http.StatusBadRequest)
+
+	var transfer transferBody
+	c.Do(t, http.MethodPost, "/v1/transfers", map[string]any{
+		"source_warehouse_id":      source.ID,
+		"destination_warehouse_id": destination.ID,
+		"reference":                "restock",
+		"items":                    []map[string]any{{"sku": "SKU-XFER", "quantity": 4}},
+	}).Expect(t, http.StatusCreated).Data(t, &transfer)
+	if transfer.Transfer.Status != "open" || len(transfer.Lines) != 1 {
+		t.Fatalf("created %+v", transfer)
+	}
+	id, line := transfer.Transfer.ID, transfer.Lines[0].ID
+
+	// Receiving before shipping is a conflict, shipping from the wrong
+	// warehouse a bad request
+	c.Do(t, http.MethodPost, fmt.Sprintf("/v1/transfers/%d/receive", id), map[string]any{
+		"lines": []map[string]any{{"line_id": line, "storage_room_id": to, "quantity": 4}},
+	}).Expect(t, http.StatusConflict)
+	c.Do(t, http.MethodPost, fmt.Sprintf("/v1/transfers/%d/ship", id), map[string]any{
+		"lines": []map[string]any{{"line_id": line, "storage_room_id": to}},
+	}).Expect(t, http.StatusBadRequest)
+
+	c.Do(t, http.MethodPost, fmt.Sprintf("/v1/transfers/%d/ship", id), map[string]any{
+		"lines": []map[string]any{{"line_id": line, "storage_room_id": from}},
+	}).Expect(t, http.StatusOK).Data(t, &transfer)
+	if transfer.Transfer.Status != "in_transit" || transfer.Transfer.ShippedAt == nil || transfer.Lines[0].InTransit != 4 {
+		t.Fatalf("shipped %+v", transfer)
+	}
+	if got := stockOf(t, c, from, "SKU-XFER"); got != 6 {
+		t.Errorf("source stock %d, want 6", got)
+	}
+
+	var inTransit []handlers.InTransitStockResponse
+	c.Do(t, http.MethodGet, "/v1/transfers/in-transit", nil).Expect(t, http.StatusOK).Data(t, &inTransit)
+	if len(inTransit) != 1 || inTransit[0].DestinationWarehouseID != destination.ID || inTransit[0].Quantity != 4 {
+		t.Fatalf("in transit %+v", inTransit)
+	}
+
+	c.Do(t, http.MethodPost, fmt.Sprintf("/v1/transfers/%d/receive", id), map[string]any{
+		"lines": []map[string]any{{"line_id": line, "storage_room_id": to, "quantity": 5}},
+	}).Expect(t, http.StatusBadRequest)
+	c.Do(t, http.MethodPost, fmt.Sprintf("/v1/transfers/%d/receive", id), map[string]any{
+		"lines": []map[string]any{{"line_id": line, "storage_room_id": to, "quantity": 3}},
+	}).Expect(t, http.StatusOK).Data(t, &transfer)
+	if transfer.Transfer.Status != "in_transit" || transfer.Lines[0].InTransit != 1 {
+		t.Fatalf("partly received %+v", transfer)
+	}
+	c.Do(t, http.MethodPost, fmt.Sprintf("/v1/transfers/%d/receive", id), map[string]any{
+		"lines": []map[string]any{{"line_id": line, "storage_room_id": to, "quantity": 1}},
+	}).Expect(t, http.StatusOK).Data(t, &transfer)
+	if transfer.Transfer.Status != "received" || transfer.Transfer.ReceivedAt == nil {
+		t.Fatalf("received %+v", transfer)
+	}
+	if got := stockOf(t, c, to, "SKU-XFER"); got != 4 {
+		t.Errorf("destination stock %d, want 4", got)
+	}
+
+	var detail struct {
+		History []handlers.AuditLogResponse `json:"history"`
+	}
+	c.Do(t, http.MethodGet, fmt.Sprintf("/v1/transfers/%d", id), nil).Expect(t, http.StatusOK).Data(t, &detail)
+	if len(detail.History) != 3 {
+		t.Errorf("history %+v", detail.History)
+	}
+
+	var topics []string
+	rows, err := e.DB.Query(context.Background(), `SELECT topic FROM outbox WHERE org_id = $1 AND key = $2 AND topic LIKE 'transfer.%' ORDER BY id`, c.OrgID, fmt.Sprint(id))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rows.Next() {
+		var topic string
+		if err := rows.Scan(&topic); err != nil {
+			t.Fatal(err)
+		}
+		topics = append(topics, topic)
+	}
+	rows.Close()
+	want := []string{outbox.TopicTransferCreated, outbox.TopicTransferShipped, outbox.TopicTransferReceived, outbox.TopicTransferReceived}
+	if fmt.Sprint(topics) != fmt.Sprint(want) {
+		t.Errorf("topics %v, want %v", topics, want)
+	}
+}
+
+func TestTransferOrderShortStock(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:member")
+	source := createWarehouse(t, c, "Short Source")
+	destination := createWarehouse(t, c, "Short Destination")
+	from := e.StorageRoom(t, c.OrgID, source.ID, "SS-01", "ambient")
+	receiveStock(t, c, source.ID, from, "SKU-SHORT", 2)
+
+	var transfer transferBody
+	c.Do(t, http.MethodPost, "/v1/transfers", map[string]any{
+		"source_warehouse_id":      source.ID,
+		"destination_warehouse_id": destination.ID,
+		"items":                    []map[string]any{{"sku": "SKU-SHORT", "quantity": 3}},
+	}).Expect(t, http.StatusCreated).Data(t, &transfer)
+
+	c.Do(t, http.MethodPost, fmt.Sprintf("/v1/transfers/%d/ship", transfer.Transfer.ID), map[string]any{
+		"lines": []map[string]any{{"line_id": transfer.Lines[0].ID, "storage_room_id": from}},
+	}).Expect(t, http.StatusConflict)
+	if got := stockOf(t, c, from, "SKU-SHORT"); got != 2 {
+		t.Errorf("stock %d after a failed shipment, want 2", got)
+	}
+
+	c.Do(t, http.MethodPost, fmt.Sprintf("/v1/transfers/%d/cancel", transfer.Transfer.ID), nil).
+		Expect(t, http.StatusOK).Data(t, &transfer)
+	if transfer.Transfer.Status != "cancelled" {
+		t.Errorf("status %s, want cancelled", transfer.Transfer.Status)
+	}
+}
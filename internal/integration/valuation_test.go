@@ -0,0 +1,73 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"warehouse-service/handlers"
+)
+
+// receiveCosted receives quantity of sku into a room at a unit cost
+func receiveCosted(t *testing.T, c *Client, warehouseID int64, roomID int32, sku string, quantity int32, unitCostCents int64) {
+	t.Helper()
+	var created struct {
+		Receipt handlers.ReceiptResponse       `json:"receipt"`
+		Lines   []handlers.ReceiptLineResponse `json:"lines"`
+	}
+	c.Do(t, http.MethodPost, "/v1/receipts", map[string]any{
+		"warehouse_id": warehouseID,
+		"lines":        []map[string]any{{"sku": sku, "expected_quantity": quantity, "unit_cost_cents": unitCostCents}},
+	}).Expect(t, http.StatusCreated).Data(t, &created)
+	if created.Lines[0].UnitCostCents == nil || *created.Lines[0].UnitCostCents != unitCostCents {
+		t.Fatalf("line cost %v", created.Lines[0].UnitCostCents)
+	}
+	c.Do(t, http.MethodPost, fmt.Sprintf("/v1/receipts/%d/receive", created.Receipt.ID), map[string]any{
+		"lines": []map[string]any{{"line_id": created.Lines[0].ID, "storage_room_id": roomID, "quantity": quantity}},
+	}).Expect(t, http.StatusOK)
+}
+
+func TestInventoryValuation(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:member")
+	admin := e.WithToken(e.Token(t, "user_admin_"+c.OrgID, c.OrgID, "org:admin"), c.OrgID)
+	w := createWarehouse(t, c, "Valuation")
+	room := e.StorageRoom(t, c.OrgID, w.ID, "V1", "ambient")
+	receiveCosted(t, c, w.ID, room, "SKU-V", 10, 100)
+	receiveCosted(t, c, w.ID, room, "SKU-V", 10, 130)
+	receiveStock(t, c, w.ID, room, "SKU-U", 4)
+	// As if 15 of SKU-V had been shipped
+	if _, err := e.DB.Exec(context.Background(),
+		`UPDATE stock_level SET quantity = 5 WHERE org_id = $1 AND sku = 'SKU-V'`, c.OrgID); err != nil {
+		t.Fatal(err)
+	}
+
+	var valuation handlers.ValuationResponse
+	c.Do(t, http.MethodGet, "/v1/reports/valuation", nil).Expect(t, http.StatusOK).Data(t, &valuation)
+	if valuation.Method != "fifo" || valuation.ValueCents != 650 || valuation.UncostedQuantity != 4 ||
+		len(valuation.Warehouses) != 1 || len(valuation.Warehouses[0].Skus) != 2 {
+		t.Fatalf("fifo valuation %+v", valuation)
+	}
+
+	c.Do(t, http.MethodPut, "/v1/admin/settings", map[string]any{"valuation_method": "weighted_average"}).
+		Expect(t, http.StatusForbidden)
+	var setting handlers.TenantSettingResponse
+	admin.Do(t, http.MethodPut, "/v1/admin/settings", map[string]any{"valuation_method": "weighted_average"}).
+		Expect(t, http.StatusOK).Data(t, &setting)
+	if setting.ValuationMethod != "weighted_average" || setting.UpdatedAt == nil {
+		t.Fatalf("setting %+v", setting)
+	}
+	c.Do(t, http.MethodGet, "/v1/reports/valuation", nil).Expect(t, http.StatusOK).Data(t, &valuation)
+	if valuation.Method != "weighted_average" || valuation.ValueCents != 575 {
+		t.Fatalf("weighted average valuation %+v", valuation)
+	}
+	c.Do(t, http.MethodGet, "/v1/reports/valuation?method=lifo", nil).Expect(t, http.StatusBadRequest)
+
+	res := c.Do(t, http.MethodGet, fmt.Sprintf("/v1/reports/valuation?format=csv&method=fifo&warehouse_id=%d", w.ID), nil).Expect(t, http.StatusOK)
+	if !strings.Contains(res.Body.String(), fmt.Sprintf("%d,Valuation,SKU-V,fifo,5,650,0", w.ID)) {
+		t.Fatalf("csv %s", res.Body)
+	}
+}
@@ -0,0 +1,105 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"warehouse-service/handlers"
+	"warehouse-service/outbox"
+)
+
+func TestWarehouseLifecycle(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:member")
+
+	var draft handlers.WarehouseResponse
+	c.Form(t, http.MethodPost, "/v1/warehouse/create", url.Values{
+		"Name":    {"Planned"},
+		"Address": {"1 Test Rd"},
+		"Status":  {"draft"},
+	}).Expect(t, http.StatusOK).Data(t, &draft)
+	if draft.Status != "draft" {
+		t.Fatalf("created with status %q, want draft", draft.Status)
+	}
+	active := createWarehouse(t, c, "Running")
+	if active.Status != "active" {
+		t.Fatalf("created with status %q, want active", active.Status)
+	}
+
+	t.Run("invalid initial status", func(t *testing.T) {
+		c.Form(t, http.MethodPost, "/v1/warehouse/create", url.Values{
+			"Name":    {"Retired"},
+			"Address": {"1 Test Rd"},
+			"Status":  {"archived"},
+		}).Expect(t, http.StatusBadRequest)
+	})
+
+	path := fmt.Sprintf("/v1/warehouse/%d", draft.ID)
+	t.Run("draft cannot go into maintenance", func(t *testing.T) {
+		c.Do(t, http.MethodPost, path+"/maintenance", nil).Expect(t, http.StatusConflict)
+	})
+
+	for _, step := range []struct{ action, status string }{
+		{"activate", "active"},
+		{"maintenance", "maintenance"},
+		{"archive", "archived"},
+	} {
+		var got handlers.WarehouseResponse
+		c.Do(t, http.MethodPost, path+"/"+step.action, nil).Expect(t, http.StatusOK).Data(t, &got)
+		if got.Status != step.status {
+			t.Fatalf("%s: status %q, want %q", step.action, got.Status, step.status)
+		}
+	}
+
+	t.Run("archived twice", func(t *testing.T) {
+		c.Do(t, http.MethodPost, path+"/archive", nil).Expect(t, http.StatusConflict)
+	})
+
+	t.Run("other tenants get 404", func(t *testing.T) {
+		e.Member(t, "org:member").Do(t, http.MethodPost, path+"/activate", nil).Expect(t, http.StatusNotFound)
+	})
+
+	t.Run("list by status", func(t *testing.T) {
+		var archived []handlers.WarehouseResponse
+		c.Do(t, http.MethodGet, "/v1/warehouse/list?status=archived", nil).Expect(t, http.StatusOK).Data(t, &archived)
+		if len(archived) != 1 || archived[0].ID != draft.ID {
+			t.Fatalf("archived %+v", archived)
+		}
+		var running []handlers.WarehouseV2
+		c.Do(t, http.MethodGet, "/v2/warehouses?status=active", nil).Expect(t, http.StatusOK).Data(t, &running)
+		if len(running) != 1 || running[0].ID != active.ID {
+			t.Fatalf("active %+v", running)
+		}
+		c.Do(t, http.MethodGet, "/v1/warehouse/list?status=closed", nil).Expect(t, http.StatusBadRequest)
+	})
+
+	t.Run("audit", func(t *testing.T) {
+		var audit []handlers.AuditLogResponse
+		c.Do(t, http.MethodGet, "/v1/audit?entity_type=warehouse", nil).Expect(t, http.StatusOK).Data(t, &audit)
+		transitions := 0
+		for _, entry := range audit {
+			if entry.EntityID == draft.ID {
+				transitions++
+			}
+		}
+		if transitions != 3 {
+			t.Fatalf("audit %+v", audit)
+		}
+	})
+
+	t.Run("events", func(t *testing.T) {
+		var events int
+		if err := e.DB.QueryRow(context.Background(),
+			`SELECT count(*) FROM outbox WHERE org_id = $1 AND topic = $2 AND key = $3`,
+			c.OrgID, outbox.TopicWarehouseStatusChanged, fmt.Sprint(draft.ID)).Scan(&events); err != nil {
+			t.Fatal(err)
+		}
+		if events != 3 {
+			t.Fatalf("%d status events, want 3", events)
+		}
+	})
+}
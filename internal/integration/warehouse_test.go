@@ -0,0 +1,477 @@
+//go:build integration
+
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"warehouse-service/handlers"
+)
+
+func TestWarehouseV1(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:member")
+
+	var created handlers.WarehouseResponse
+	c.Form(t, http.MethodPost, "/v1/warehouse/create", url.Values{
+		"Name":      {"Main"},
+		"Address":   {"1 Test Rd"},
+		"Ward":      {"1"},
+		"City":      {"Test"},
+		"Country":   {"VN"},
+		"Latitude":  {"10.7769"},
+		"Longitude": {"106.7009"},
+		"Tags":      {"cold-chain"},
+	}).Expect(t, http.StatusOK).Data(t, &created)
+	if created.Name != "Main" || created.TimeZone != "UTC" {
+		t.Fatalf("created %+v", created)
+	}
+	path := fmt.Sprintf("/v1/warehouse/%d", created.ID)
+
+	t.Run("duplicate name", func(t *testing.T) {
+		c.Form(t, http.MethodPost, "/v1/warehouse/create", url.Values{
+			"Name":    {"main"},
+			"Address": {"2 Test Rd"},
+		}).Expect(t, http.StatusConflict)
+	})
+
+	t.Run("get", func(t *testing.T) {
+		var got handlers.WarehouseResponse
+		c.Do(t, http.MethodGet, path, nil).Expect(t, http.StatusOK).Data(t, &got)
+		if got.ID != created.ID {
+			t.Fatalf("got warehouse %d, want %d", got.ID, created.ID)
+		}
+	})
+
+	t.Run("other tenants get 404", func(t *testing.T) {
+		e.Member(t, "org:member").Do(t, http.MethodGet, path, nil).Expect(t, http.StatusNotFound)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		var list []handlers.WarehouseResponse
+		c.Do(t, http.MethodGet, "/v1/warehouse/list?tag=cold-chain", nil).Expect(t, http.StatusOK).Data(t, &list)
+		if len(list) != 1 || list[0].ID != created.ID {
+			t.Fatalf("list %+v", list)
+		}
+	})
+
+	t.Run("nearby", func(t *testing.T) {
+		var nearby []handlers.NearbyWarehouseResponse
+		c.Do(t, http.MethodGet, "/v1/warehouse/nearby?lat=10.78&lng=106.70&radius=5000", nil).
+			Expect(t, http.StatusOK).Data(t, &nearby)
+		if len(nearby) != 1 {
+			t.Fatalf("nearby %+v", nearby)
+		}
+	})
+
+	t.Run("batch get", func(t *testing.T) {
+		var batch struct {
+			Found   map[string]handlers.WarehouseResponse `json:"found"`
+			Missing []int64                               `json:"missing"`
+		}
+		c.Do(t, http.MethodPost, "/v1/warehouse/batch-get", map[string]any{
+			"ids": []int64{created.ID, 1 << 40},
+		}).Expect(t, http.StatusOK).Data(t, &batch)
+		if len(batch.Found) != 1 || len(batch.Missing) != 1 {
+			t.Fatalf("batch %+v", batch)
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		var updated handlers.WarehouseResponse
+		c.Form(t, http.MethodPut, path, url.Values{
+			"Name":    {"Main renamed"},
+			"Address": {"1 Test Rd"},
+			"City":    {"Test"},
+			"Country": {"VN"},
+		}).Expect(t, http.StatusOK).Data(t, &updated)
+		if updated.Name != "Main renamed" || len(updated.Tags) != 1 {
+			t.Fatalf("updated %+v", updated)
+		}
+	})
+
+	t.Run("patch", func(t *testing.T) {
+		var patched handlers.WarehouseResponse
+		c.Do(t, http.MethodPatch, path, map[string]any{"city": "Patched"}).
+			Expect(t, http.StatusOK).Data(t, &patched)
+		if patched.City != "Patched" || patched.Name != "Main renamed" {
+			t.Fatalf("patched %+v", patched)
+		}
+	})
+
+	t.Run("delete with rooms", func(t *testing.T) {
+		e.StorageRoom(t, c.OrgID, created.ID, "R-1", "ambient")
+		c.Do(t, http.MethodDelete, path, nil).Expect(t, http.StatusConflict)
+		c.Do(t, http.MethodDelete, path+"?cascade=true", nil).Expect(t, http.StatusOK)
+		c.Do(t, http.MethodGet, path, nil).Expect(t, http.StatusNotFound)
+	})
+}
+
+func TestWarehouseV2(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:member")
+
+	created := createWarehouse(t, c, "V2")
+	path := fmt.Sprintf("/v2/warehouses/%d", created.ID)
+
+	var got handlers.WarehouseV2
+	c.Do(t, http.MethodGet, path, nil).Expect(t, http.StatusOK).Data(t, &got)
+	if got.Name != "V2" || string(got.Attributes) != "{}" {
+		t.Fatalf("got %+v", got)
+	}
+
+	var list []handlers.WarehouseV2
+	c.Do(t, http.MethodGet, "/v2/warehouses?limit=10", nil).Expect(t, http.StatusOK).Data(t, &list)
+	if len(list) != 1 {
+		t.Fatalf("list %+v", list)
+	}
+
+	var updated handlers.WarehouseV2
+	c.Do(t, http.MethodPut, path, map[string]any{
+		"name":    "V2 renamed",
+		"address": "2 Test Rd",
+	}).Expect(t, http.StatusOK).Data(t, &updated)
+	if updated.Name != "V2 renamed" || updated.Latitude != nil {
+		t.Fatalf("updated %+v", updated)
+	}
+
+	c.Do(t, http.MethodPost, "/v2/warehouses", map[string]any{"name": "missing address"}).
+		Expect(t, http.StatusBadRequest)
+	c.Do(t, http.MethodDelete, path, nil).Expect(t, http.StatusNoContent)
+	c.Do(t, http.MethodGet, path, nil).Expect(t, http.StatusNotFound)
+}
+
+func TestWarehouseDuplicates(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:member")
+	original := createWarehouse(t, c, "Riverside Distribution")
+
+	// Same name but for punctuation and spacing, at the same address
+	rec := c.Do(t, http.MethodPost, "/v2/warehouses", map[string]any{
+		"name":    "Riverside  Distribution.",
+		"address": "1 Test Rd",
+		"city":    "Test",
+		"country": "VN",
+	}).Expect(t, http.StatusConflict)
+	if !strings.Contains(rec.Body.String(), `"code":"duplicate"`) || !strings.Contains(rec.Body.String(), fmt.Sprint(original.ID)) {
+		t.Fatalf("conflict %s", rec.Body.String())
+	}
+
+	rec = c.Form(t, http.MethodPost, "/v1/warehouse/create", url.Values{
+		"Name":    {"riverside distribution!"},
+		"Address": {"1 Test Rd"},
+		"City":    {"Test"},
+		"Country": {"VN"},
+	}).Expect(t, http.StatusConflict)
+	var blocked struct {
+		Candidates []handlers.DuplicateWarehouseResponse `json:"candidates"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &blocked); err != nil {
+		t.Fatalf("decode conflict: %v", err)
+	}
+	if len(blocked.Candidates) != 1 || blocked.Candidates[0].ID != original.ID || blocked.Candidates[0].NameSimilarity != 1 {
+		t.Fatalf("candidates %+v", blocked.Candidates)
+	}
+
+	// Another address is another warehouse, and force skips the check
+	c.Do(t, http.MethodPost, "/v2/warehouses", map[string]any{
+		"name":    "Riverside Distribution 2",
+		"address": "99 Harbour Avenue",
+		"city":    "Elsewhere",
+	}).Expect(t, http.StatusCreated)
+	c.Do(t, http.MethodPost, "/v2/warehouses?force=true", map[string]any{
+		"name":    "Riverside  Distribution.",
+		"address": "1 Test Rd",
+		"city":    "Test",
+		"country": "VN",
+	}).Expect(t, http.StatusCreated)
+	c.Do(t, http.MethodPost, "/v2/warehouses?force=maybe", map[string]any{
+		"name":    "Riverside",
+		"address": "1 Test Rd",
+	}).Expect(t, http.StatusBadRequest)
+}
+
+func TestWarehouseAddress(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:member")
+
+	var created handlers.WarehouseV2
+	c.Do(t, http.MethodPost, "/v2/warehouses", map[string]any{
+		"name":    "Saigon",
+		"address": " 12  Tran Hung Dao ",
+		"city":    "HO CHI MINH city",
+		"country": "Viet Nam",
+	}).Expect(t, http.StatusCreated).Data(t, &created)
+	if created.Address != "12 Tran Hung Dao" || created.City != "Ho Chi Minh City" || created.Country != "VN" {
+		t.Fatalf("created %+v", created)
+	}
+	path := fmt.Sprintf("/v1/warehouse/%d", created.ID)
+
+	var got handlers.WarehouseAddressResponse
+	c.Do(t, http.MethodGet, path+"/address", nil).Expect(t, http.StatusOK).Data(t, &got)
+	if got.Normalized.City != "Ho Chi Minh City" || got.Raw == nil || got.Raw.City != "HO CHI MINH city" || got.Raw.Country != "Viet Nam" || got.ValidatedAt != nil {
+		t.Fatalf("address %+v raw %+v", got, got.Raw)
+	}
+
+	// A patch merges the sent parts into the raw address too
+	var patched handlers.WarehouseResponse
+	c.Do(t, http.MethodPatch, path, map[string]any{"country": "vnm"}).
+		Expect(t, http.StatusOK).Data(t, &patched)
+	if patched.Country != "VN" {
+		t.Fatalf("patched %+v", patched)
+	}
+	c.Do(t, http.MethodGet, path+"/address", nil).Expect(t, http.StatusOK).Data(t, &got)
+	if got.Raw == nil || got.Raw.Country != "vnm" || got.Raw.City != "HO CHI MINH city" {
+		t.Fatalf("raw after patch %+v", got.Raw)
+	}
+
+	rec := c.Do(t, http.MethodPatch, path, map[string]any{"country": "Atlantis"}).Expect(t, http.StatusBadRequest)
+	if !strings.Contains(rec.Body.String(), `"field":"country"`) {
+		t.Fatalf("unknown country %s", rec.Body.String())
+	}
+	c.Form(t, http.MethodPost, "/v1/warehouse/create", url.Values{
+		"Name":    {"Nowhere"},
+		"Address": {"1 Test Rd"},
+		"Country": {"XX"},
+	}).Expect(t, http.StatusBadRequest)
+	c.Do(t, http.MethodPost, "/v2/warehouses", map[string]any{
+		"name":    "Nowhere",
+		"address": "1 Test Rd",
+		"country": "Test",
+	}).Expect(t, http.StatusBadRequest)
+}
+
+func TestWarehouseFields(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:member")
+
+	created := createWarehouse(t, c, "Sparse")
+
+	var got map[string]any
+	c.Do(t, http.MethodGet, fmt.Sprintf("/v1/warehouse/%d?fields=name,city", created.ID), nil).
+		Expect(t, http.StatusOK).Data(t, &got)
+	if len(got) != 2 || got["name"] != "Sparse" || got["city"] != "Test" {
+		t.Fatalf("v1 fields %+v", got)
+	}
+	var legacy map[string]any
+	c.V1Names = true
+	c.Do(t, http.MethodGet, fmt.Sprintf("/v1/warehouse/%d?fields=Name,City", created.ID), nil).
+		Expect(t, http.StatusOK).Data(t, &legacy)
+	if len(legacy) != 2 || legacy["Name"] != "Sparse" || legacy["City"] != "Test" {
+		t.Fatalf("v1 names %+v", legacy)
+	}
+	c.V1Names = false
+
+	var list []map[string]any
+	c.Do(t, http.MethodGet, "/v2/warehouses?fields=id,country", nil).
+		Expect(t, http.StatusOK).Data(t, &list)
+	if len(list) != 1 || len(list[0]) != 2 || list[0]["country"] != "VN" {
+		t.Fatalf("v2 fields %+v", list)
+	}
+
+	rec := c.Do(t, http.MethodGet, "/v1/warehouse/list?fields=name,bogus", nil).Expect(t, http.StatusBadRequest)
+	if !strings.Contains(rec.Body.String(), `"field":"fields"`) {
+		t.Fatalf("unknown field %s", rec.Body.String())
+	}
+	c.Do(t, http.MethodGet, fmt.Sprintf("/v2/warehouses/%d?fields=bogus", created.ID), nil).
+		Expect(t, http.StatusBadRequest)
+}
+
+func TestWarehouseProbes(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:member")
+
+	created := createWarehouse(t, c, "Probed")
+	createWarehouse(t, c, "Probed Too")
+	path := fmt.Sprintf("/v1/warehouse/%d", created.ID)
+
+	got := c.Do(t, http.MethodGet, path, nil).Expect(t, http.StatusOK)
+	head := c.Do(t, http.MethodHead, path, nil).Expect(t, http.StatusOK)
+	etag := got.Header().Get("ETag")
+	if etag == "" || head.Header().Get("ETag") != etag {
+		t.Fatalf("ETag %q, HEAD %q", etag, head.Header().Get("ETag"))
+	}
+	if head.Body.Len() != 0 || head.Header().Get("Content-Length") != fmt.Sprint(got.Body.Len()) {
+		t.Fatalf("HEAD answered %d bytes with Content-Length %s, GET %d", head.Body.Len(), head.Header().Get("Content-Length"), got.Body.Len())
+	}
+
+	c.Header = http.Header{"If-None-Match": {etag}}
+	c.Do(t, http.MethodGet, path, nil).Expect(t, http.StatusNotModified)
+	c.Header = nil
+
+	list := c.Do(t, http.MethodHead, "/v1/warehouse/list", nil).Expect(t, http.StatusOK)
+	if total := list.Header().Get(handlers.TotalCountHeader); total != "2" {
+		t.Fatalf("X-Total-Count %q", total)
+	}
+
+	options := e.Anonymous().Do(t, http.MethodOptions, path, nil).Expect(t, http.StatusNoContent)
+	if allow := options.Header().Get("Allow"); allow != "DELETE, GET, HEAD, OPTIONS, PATCH, PUT" {
+		t.Fatalf("Allow %q", allow)
+	}
+	c.Do(t, http.MethodPost, path, nil).Expect(t, http.StatusMethodNotAllowed)
+}
+
+func TestWarehouseConditionalDelete(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:member")
+
+	created := createWarehouse(t, c, "Contested")
+	path := fmt.Sprintf("/v1/warehouse/%d", created.ID)
+	seen := c.Do(t, http.MethodGet, path, nil).Expect(t, http.StatusOK).Header().Get("ETag")
+	if strings.HasPrefix(seen, "W/") || seen == "" {
+		t.Fatalf("ETag %q, want the strong version tag", seen)
+	}
+	c.Do(t, http.MethodPatch, path, map[string]any{"name": "Contested Again"}).Expect(t, http.StatusOK)
+
+	c.Header = http.Header{"If-Match": {seen}}
+	c.Do(t, http.MethodDelete, path, nil).Expect(t, http.StatusPreconditionFailed)
+	c.Do(t, http.MethodDelete, fmt.Sprintf("/v2/warehouses/%d", created.ID), nil).Expect(t, http.StatusPreconditionFailed)
+	c.Header = nil
+
+	current := c.Do(t, http.MethodGet, fmt.Sprintf("/v2/warehouses/%d", created.ID), nil).Expect(t, http.StatusOK).Header().Get("ETag")
+	if current == seen {
+		t.Fatalf("ETag %q didn't change with the update", current)
+	}
+	c.Header = http.Header{"If-Match": {current}}
+	c.Do(t, http.MethodDelete, fmt.Sprintf("/v2/warehouses/%d", created.ID), nil).Expect(t, http.StatusNoContent)
+	c.Header = nil
+}
+
+func TestWarehouseSlugs(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:member")
+
+	first := createWarehouse(t, c, "Kho Hà Nội")
+	if first.Slug != "kho-ha-noi" {
+		t.Fatalf("slug %q", first.Slug)
+	}
+	// Another name with the same slug gets a suffix
+	var second handlers.WarehouseResponse
+	c.Form(t, http.MethodPost, "/v1/warehouse/create?force=true", url.Values{
+		"Name":    {"Kho Ha Noi!"},
+		"Address": {"1 Test Rd"},
+		"City":    {"Test"},
+		"Country": {"VN"},
+	}).Expect(t, http.StatusOK).Data(t, &second)
+	if second.Slug != "kho-ha-noi-2" {
+		t.Fatalf("slug %q", second.Slug)
+	}
+
+	// A rename keeps the slug
+	c.Do(t, http.MethodPatch, fmt.Sprintf("/v1/warehouse/%d", first.ID), map[string]any{"name": "Kho Hà Nội Cũ"}).Expect(t, http.StatusOK)
+	var found handlers.WarehouseResponse
+	c.Do(t, http.MethodGet, "/v1/warehouse/by-slug/kho-ha-noi", nil).Expect(t, http.StatusOK).Data(t, &found)
+	if found.ID != first.ID || found.Slug != "kho-ha-noi" {
+		t.Fatalf("found %+v", found)
+	}
+	c.Do(t, http.MethodGet, fmt.Sprintf("/v1/warehouse/by-slug/%d", first.ID), nil).Expect(t, http.StatusNotFound)
+
+	// The slug stands in for the ID in paths and query parameters
+	var v2 handlers.WarehouseV2
+	c.Do(t, http.MethodGet, "/v2/warehouses/kho-ha-noi-2", nil).Expect(t, http.StatusOK).Data(t, &v2)
+	if v2.ID != second.ID {
+		t.Fatalf("v2 %+v", v2)
+	}
+	c.Do(t, http.MethodGet, "/v1/warehouse/kho-ha-noi-2/history", nil).Expect(t, http.StatusOK)
+	e.StorageRoom(t, c.OrgID, second.ID, "S-1", "ambient")
+	var rooms []handlers.StorageRoomResponse
+	c.Do(t, http.MethodGet, "/v1/storageroom/list?warehouse_id=kho-ha-noi-2", nil).Expect(t, http.StatusOK).Data(t, &rooms)
+	if len(rooms) != 1 || int64(rooms[0].WarehouseID) != second.ID {
+		t.Fatalf("rooms %+v", rooms)
+	}
+	c.Do(t, http.MethodGet, "/v1/storageroom/list?warehouse_id=nowhere", nil).Expect(t, http.StatusNotFound)
+
+	// Request bodies take the slug as a string where they take the ID
+	depot := createWarehouse(t, c, "Depot Nord")
+	var receipt struct {
+		Receipt handlers.ReceiptResponse `json:"receipt"`
+	}
+	c.Do(t, http.MethodPost, "/v1/receipts", map[string]any{
+		"warehouse_id": "depot-nord",
+		"lines":        []map[string]any{{"sku": "SKU-SLUG", "expected_quantity": 3}},
+	}).Expect(t, http.StatusCreated).Data(t, &receipt)
+	if receipt.Receipt.WarehouseID != depot.ID {
+		t.Fatalf("receipt %+v", receipt.Receipt)
+	}
+	c.Do(t, http.MethodPost, "/v1/receipts", map[string]any{
+		"warehouse_id": "nowhere",
+		"lines":        []map[string]any{{"sku": "SKU-SLUG", "expected_quantity": 3}},
+	}).Expect(t, http.StatusNotFound)
+	var transfer transferBody
+	c.Do(t, http.MethodPost, "/v1/transfers", map[string]any{
+		"source_warehouse_id":      "kho-ha-noi",
+		"destination_warehouse_id": "depot-nord",
+		"items":                    []map[string]any{{"sku": "SKU-SLUG", "quantity": 1}},
+	}).Expect(t, http.StatusCreated).Data(t, &transfer)
+	if transfer.Transfer.SourceWarehouseID != first.ID || transfer.Transfer.DestinationWarehouseID != depot.ID {
+		t.Fatalf("transfer %+v", transfer.Transfer)
+	}
+	// An ID and a slug of the same warehouse are one warehouse
+	c.Do(t, http.MethodPost, "/v1/transfers", map[string]any{
+		"source_warehouse_id":      first.ID,
+		"destination_warehouse_id": "kho-ha-noi",
+		"items":                    []map[string]any{{"sku": "SKU-SLUG", "quantity": 1}},
+	}).Expect(t, http.StatusBadRequest)
+
+	// Slugs are unique within a tenant only, and resolve within it
+	other := e.Member(t, "org:member")
+	if theirs := createWarehouse(t, other, "Kho Hà Nội"); theirs.Slug != "kho-ha-noi" {
+		t.Fatalf("slug %q in another tenant", theirs.Slug)
+	}
+	other.Do(t, http.MethodGet, "/v1/warehouse/by-slug/kho-ha-noi-2", nil).Expect(t, http.StatusNotFound)
+	other.Do(t, http.MethodDelete, "/v1/warehouse/kho-ha-noi-2", nil).Expect(t, http.StatusNotFound)
+	other.Do(t, http.MethodPost, "/v1/receipts", map[string]any{
+		"warehouse_id": "depot-nord",
+		"lines":        []map[string]any{{"sku": "SKU-SLUG", "expected_quantity": 3}},
+	}).Expect(t, http.StatusNotFound)
+	c.Do(t, http.MethodDelete, "/v2/warehouses/kho-ha-noi-2?cascade=true", nil).Expect(t, http.StatusNoContent)
+}
+
+func TestWarehouseCascadeDelete(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:member")
+	warehouse := createWarehouse(t, c, "Cascade")
+	overflow := createWarehouse(t, c, "Overflow")
+	room := e.StorageRoom(t, c.OrgID, warehouse.ID, "CD-01", "ambient")
+	e.StorageRoom(t, c.OrgID, warehouse.ID, "CD-02", "ambient")
+	target := e.StorageRoom(t, c.OrgID, overflow.ID, "OF-01", "ambient")
+	receiveStock(t, c, warehouse.ID, room, "SKU-CASCADE", 4)
+	path := fmt.Sprintf("/v1/warehouse/%d?cascade=true", warehouse.ID)
+
+	rec := c.Do(t, http.MethodDelete, path, nil).Expect(t, http.StatusConflict)
+	var blocked struct {
+		StorageRoomID int32 `json:"storage_room_id"`
+		SkuCount      int   `json:"sku_count"`
+		Quantity      int64 `json:"quantity"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &blocked); err != nil {
+		t.Fatalf("decode conflict: %v", err)
+	}
+	if blocked.StorageRoomID != room || blocked.SkuCount != 1 || blocked.Quantity != 4 {
+		t.Fatalf("blocked %+v", blocked)
+	}
+	c.Do(t, http.MethodDelete, fmt.Sprintf("/v2/warehouses/%d?cascade=true", warehouse.ID), nil).
+		Expect(t, http.StatusConflict)
+
+	// Moving the stock out leaves a zero stock level behind in the room
+	c.Do(t, http.MethodPost, "/v1/scan/move", map[string]any{
+		"code": "SKU-CASCADE",
+		"from": fmt.Sprintf("%d-CD-01", warehouse.ID),
+		"to":   fmt.Sprintf("%d-OF-01", overflow.ID),
+		"qty":  4,
+	}).Expect(t, http.StatusOK)
+	if got := stockOf(t, c, target, "SKU-CASCADE"); got != 4 {
+		t.Fatalf("moved stock %d, want 4", got)
+	}
+
+	c.Do(t, http.MethodDelete, path, nil).Expect(t, http.StatusOK)
+	c.Do(t, http.MethodGet, fmt.Sprintf("/v1/warehouse/%d", warehouse.ID), nil).Expect(t, http.StatusNotFound)
+	c.Do(t, http.MethodDelete, fmt.Sprintf("/v1/storageroom/%d", room), nil).Expect(t, http.StatusNotFound)
+	if got := stockOf(t, c, target, "SKU-CASCADE"); got != 4 {
+		t.Fatalf("stock left in the overflow %d, want 4", got)
+	}
+}
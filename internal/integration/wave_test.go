@@ -0,0 +1,66 @@
+//go:build integration
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"warehouse-service/handlers"
+)
+
+func TestWaves(t *testing.T) {
+	e := requireEnv(t)
+	c := e.Member(t, "org:member")
+	warehouse := createWarehouse(t, c, "Waves")
+	far := e.StorageRoom(t, c.OrgID, warehouse.ID, "W-03-09", "ambient")
+	near := e.StorageRoom(t, c.OrgID, warehouse.ID, "W-01-02", "ambient")
+	cold := e.StorageRoom(t, c.OrgID, warehouse.ID, "W-C-01", "frozen")
+	for room, location := range map[int32][2]int{far: {3, 9}, near: {1, 2}, cold: {1, 1}} {
+		c.Do(t, http.MethodPatch, fmt.Sprintf("/v1/storageroom/%d", room), map[string]any{
+			"aisle": location[0],
+			"bay":   location[1],
+		}).Expect(t, http.StatusOK)
+	}
+	receiveStock(t, c, warehouse.ID, far, "SKU-FAR", 5)
+	receiveStock(t, c, warehouse.ID, near, "SKU-NEAR", 5)
+	receiveStock(t, c, warehouse.ID, cold, "SKU-ICE", 5)
+
+	pickList := func(sku string) int64 {
+		var body pickListBody
+		c.Do(t, http.MethodPost, "/v1/picklists", map[string]any{
+			"warehouse_id": warehouse.ID,
+			"items":        []map[string]any{{"sku": sku, "quantity": 2}},
+		}).Expect(t, http.StatusCreated).Data(t, &body)
+		return body.PickList.ID
+	}
+	first, second, third := pickList("SKU-ICE"), pickList("SKU-FAR"), pickList("SKU-NEAR")
+
+	var wave struct {
+		Wave handlers.WaveResponse       `json:"wave"`
+		Path []handlers.WaveStopResponse `json:"path"`
+	}
+	c.Do(t, http.MethodPost, "/v1/waves", map[string]any{
+		"warehouse_id":  warehouse.ID,
+		"pick_list_ids": []int64{first, second, third},
+	}).Expect(t, http.StatusCreated).Data(t, &wave)
+	if len(wave.Path) != 3 || wave.Path[0].StorageRoomID != near || wave.Path[1].StorageRoomID != far || wave.Path[2].StorageRoomID != cold {
+		t.Fatalf("path %+v", wave.Path)
+	}
+
+	c.Do(t, http.MethodPost, "/v1/waves", map[string]any{
+		"warehouse_id":  warehouse.ID,
+		"pick_list_ids": []int64{first},
+	}).Expect(t, http.StatusConflict)
+	c.Do(t, http.MethodPost, "/v1/waves", map[string]any{
+		"warehouse_id":  warehouse.ID,
+		"pick_list_ids": []int64{1 << 40},
+	}).Expect(t, http.StatusNotFound)
+
+	// Cancelled pick lists leave the path
+	c.Do(t, http.MethodPost, fmt.Sprintf("/v1/picklists/%d/cancel", second), nil).Expect(t, http.StatusOK)
+	c.Do(t, http.MethodGet, fmt.Sprintf("/v1/waves/%d", wave.Wave.ID), nil).Expect(t, http.StatusOK).Data(t, &wave)
+	if len(wave.Path) != 2 || wave.Path[1].Sequence != 2 || wave.Path[1].StorageRoomID != cold {
+		t.Fatalf("path after cancel %+v", wave.Path)
+	}
+}
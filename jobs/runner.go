@@ -0,0 +1,316 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Job statuses stored in the job table
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+const defaultMaxAttempts = 5
+
+// Handler processes a single job. Returning an error schedules a retry until
+// the job runs out of attempts.
+type Handler func(ctx context.Context, job models.Job) error
+
+// Config controls the worker pool
+type Config struct {
+	Workers      int
+	PollInterval time.Duration
+	// Jobs locked longer than this are assumed to belong to a dead worker and
+	// are put back on the queue
+	StaleAfter  time.Duration
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+}
+
+func (c Config) withDefaults() Config {
+	if c.Workers <= 0 {
+		c.Workers = 4
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = time.Second
+	}
+	if c.StaleAfter <= 0 {
+		c.StaleAfter = 15 * time.Minute
+	}
+	if c.BaseBackoff <= 0 {
+		c.BaseBackoff = 5 * time.Second
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = time.Hour
+	}
+	return c
+}
+
+// Runner polls the job table and dispatches queued jobs to registered handlers
+type Runner struct {
+	queries           *models.Queries
+	tracer            trace.Tracer
+	prometheusMetrics *observability.PrometheusMetrics
+	config            Config
+
+	mu       sync.RWMutex
+	handlers map[string]Handler
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewRunner(db *pgxpool.Pool, prometheusMetrics *observability.PrometheusMetrics, config Config) *Runner {
+	return &Runner{
+		queries:           models.New(db),
+		tracer:            otel.Tracer("warehouse-service/jobs"),
+		prometheusMetrics: prometheusMetrics,
+		config:            config.withDefaults(),
+		handlers:          make(map[string]Handler),
+	}
+}
+
+// Register sets the handler for a job kind. It must be called before Start.
+func (r *Runner) Register(kind string, handler Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers[kind] = handler
+}
+
+// EnqueueOptions tweaks a single enqueued job
+type EnqueueOptions struct {
+	RunAt       time.Time
+	MaxAttempts int32
+}
+
+// Enqueue stores a job for orgID. The payload is marshalled to JSON.
+func (r *Runner) Enqueue(ctx context.Context, orgID, kind string, payload any, opts EnqueueOptions) (models.Job, error) {
+	return Enqueue(ctx, r.queries, orgID, kind, payload, opts)
+}
+
+// Enqueue stores a job using q, so callers can enqueue inside their own
+// transaction and have the job only become visible if it commits.
+func Enqueue(ctx context.Context, q *models.Queries, orgID, kind string, payload any, opts EnqueueOptions) (models.Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return models.Job{}, fmt.Errorf("marshal %s payload: %w", kind, err)
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = defaultMaxAttempts
+	}
+	if opts.RunAt.IsZero() {
+		opts.RunAt = time.Now()
+	}
+	return q.EnqueueJob(ctx, models.EnqueueJobParams{
+		OrgID:       orgID,
+		Kind:        kind,
+		Payload:     data,
+		MaxAttempts: opts.MaxAttempts,
+		RunAt:       pgtype.Timestamptz{Time: opts.RunAt, Valid: true},
+	})
+}
+
+// Start launches the worker pool. Workers stop when ctx is cancelled or
+// Stop is called.
+func (r *Runner) Start(ctx context.Context) {
+	ctx, r.cancel = context.WithCancel(ctx)
+
+	slog.Info("Starting job runner",
+		slog.Int("workers", r.config.Workers),
+		slog.Duration("poll_interval", r.config.PollInterval),
+	)
+
+	r.wg.Add(1)
+	go r.requeueStale(ctx)
+
+	for i := 0; i < r.config.Workers; i++ {
+		r.wg.Add(1)
+		go r.work(ctx)
+	}
+}
+
+// Stop cancels the workers and waits for in-flight jobs to return
+func (r *Runner) Stop() {
+	if r.cancel == nil {
+		return
+	}
+	r.cancel()
+	r.wg.Wait()
+	slog.Info("Job runner stopped")
+}
+
+func (r *Runner) work(ctx context.Context) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Drain the queue before going back to sleep
+		for ctx.Err() == nil && r.runNext(ctx) {
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runNext claims and runs one job. It reports whether a job was claimed.
+func (r *Runner) runNext(ctx context.Context) bool {
+	job, err := r.queries.ClaimJob(ctx)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false
+	}
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Error("Got an error while claiming job: ", slog.Any("err", err.Error()))
+		}
+		return false
+	}
+
+	r.run(ctx, job)
+	return true
+}
+
+func (r *Runner) run(ctx context.Context, job models.Job) {
+	spanCtx, span := r.tracer.Start(ctx, "job "+job.Kind, trace.WithSpanKind(trace.SpanKindConsumer))
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("job.id", job.ID),
+		attribute.String("job.kind", job.Kind),
+		attribute.Int("job.attempt", int(job.Attempts)),
+		attribute.Int("job.max_attempts", int(job.MaxAttempts)),
+		attribute.String("tenant.id", job.OrgID),
+	)
+
+	start := time.Now()
+	err := r.dispatch(spanCtx, job)
+	status := StatusSucceeded
+
+	// Bookkeeping uses a fresh context so a shutdown mid-job still records
+	// the outcome instead of leaving the row locked until it goes stale
+	bgCtx, cancel := context.WithTimeout(context.WithoutCancel(spanCtx), 5*time.Second)
+	defer cancel()
+
+	switch {
+	case err == nil:
+		err = r.queries.CompleteJob(bgCtx, job.ID)
+	case job.Attempts >= job.MaxAttempts:
+		status = StatusFailed
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		slog.Error("Job failed permanently",
+			slog.Int64("job_id", job.ID),
+			slog.String("kind", job.Kind),
+			slog.Any("err", err.Error()),
+		)
+		err = r.queries.FailJob(bgCtx, models.FailJobParams{
+			ID:        job.ID,
+			LastError: err.Error(),
+		})
+	default:
+		status = "retried"
+		delay := r.backoff(job.Attempts)
+		span.RecordError(err)
+		span.SetAttributes(attribute.String("job.retry_in", delay.String()))
+		slog.Warn("Job failed, retrying",
+			slog.Int64("job_id", job.ID),
+			slog.String("kind", job.Kind),
+			slog.Duration("backoff", delay),
+			slog.Any("err", err.Error()),
+		)
+		err = r.queries.RetryJob(bgCtx, models.RetryJobParams{
+			ID:        job.ID,
+			LastError: err.Error(),
+			RunAt:     pgtype.Timestamptz{Time: time.Now().Add(delay), Valid: true},
+		})
+	}
+	if err != nil {
+		slog.Error("Got an error while updating job status: ", slog.Any("err", err.Error()))
+		span.RecordError(err)
+	}
+
+	span.SetAttributes(attribute.String("job.status", status))
+	if r.prometheusMetrics != nil {
+		r.prometheusMetrics.RecordJob(job.Kind, status, time.Since(start))
+	}
+}
+
+// dispatch calls the registered handler, turning panics into errors so one
+// bad job cannot take down a worker
+func (r *Runner) dispatch(ctx context.Context, job models.Job) (err error) {
+	r.mu.RLock()
+	handler, ok := r.handlers[job.Kind]
+	r.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("no handler registered for job kind %q", job.Kind)
+	}
+
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("job panicked: %v", rec)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// backoff returns the exponential delay before the next attempt, with up to
+// 20% jitter so failed jobs don't retry in lockstep
+func (r *Runner) backoff(attempt int32) time.Duration {
+	delay := r.config.BaseBackoff
+	for i := int32(1); i < attempt && delay < r.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	delay = min(delay, r.config.MaxBackoff)
+	return delay + time.Duration(rand.Int64N(int64(delay)/5+1))
+}
+
+func (r *Runner) requeueStale(ctx context.Context) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.config.StaleAfter / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		cutoff := time.Now().Add(-r.config.StaleAfter)
+		count, err := r.queries.RequeueStaleJobs(ctx, pgtype.Timestamptz{Time: cutoff, Valid: true})
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Error("Got an error while requeueing stale jobs: ", slog.Any("err", err.Error()))
+			}
+			continue
+		}
+		if count > 0 {
+			slog.Warn("Requeued stale jobs", slog.Int64("count", count))
+		}
+	}
+}
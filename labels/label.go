@@ -0,0 +1,265 @@
+package labels
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+	"sync"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/qr"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Symbology selects the barcode encoding printed on a label
+type Symbology string
+
+// Format selects the output document type of a label
+type Format string
+
+const (
+	SymbologyQR      Symbology = "qr"
+	SymbologyCode128 Symbology = "code128"
+
+	FormatPNG Format = "png"
+	FormatPDF Format = "pdf"
+	FormatZPL Format = "zpl"
+)
+
+// ErrUnsupported is returned for unknown symbologies or formats
+var ErrUnsupported = errors.New("unsupported label option")
+
+// Label is the content printed on a bin label. Code is encoded in the
+// barcode, Lines are printed underneath as human-readable text.
+type Label struct {
+	Code  string
+	Lines []string
+}
+
+// Render encodes the label and returns the document bytes with its content type
+func Render(label Label, symbology Symbology, format Format) ([]byte, string, error) {
+	code, err := encode(label.Code, symbology)
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch format {
+	case FormatPNG:
+		data, err := renderPNG(code, label)
+		return data, "image/png", err
+	case FormatPDF:
+		data, err := renderPDF(code, label)
+		return data, "application/pdf", err
+	case FormatZPL:
+		data, err := renderZPL(code, label, ZPLOptions{})
+		return data, "application/zpl", err
+	default:
+		return nil, "", fmt.Errorf("%w: format %q", ErrUnsupported, format)
+	}
+}
+
+func encode(content string, symbology Symbology) (barcode.Barcode, error) {
+	switch symbology {
+	case SymbologyQR:
+		return qr.Encode(content, qr.M, qr.Auto)
+	case SymbologyCode128:
+		return code128.Encode(content)
+	default:
+		return nil, fmt.Errorf("%w: symbology %q", ErrUnsupported, symbology)
+	}
+}
+
+const (
+	pngMargin     = 16
+	pngLineHeight = 16
+	pngFontSize   = 12
+	// Smallest module size that still scans reliably on a 203dpi printer
+	pngModule = 4
+)
+
+// pngFace is Go Regular, which has the Latin, Greek and Cyrillic letters
+// the bitmap fonts of x/image lack
+var pngFace = sync.OnceValues(func() (font.Face, error) {
+	f, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		return nil, err
+	}
+	return opentype.NewFace(f, &opentype.FaceOptions{Size: pngFontSize, DPI: 72, Hinting: font.HintingFull})
+})
+
+// renderPNG draws the barcode scaled to whole modules with the text lines below it
+func renderPNG(code barcode.Barcode, label Label) ([]byte, error) {
+	face, err := pngFace()
+	if err != nil {
+		return nil, fmt.Errorf("load label font: %w", err)
+	}
+	lines := make([]string, len(label.Lines))
+	for i, line := range label.Lines {
+		lines[i] = fold(line, func(r rune) bool {
+			_, ok := face.GlyphAdvance(r)
+			return ok
+		})
+	}
+	drawer := &font.Drawer{Src: image.Black, Face: face}
+
+	bounds := code.Bounds()
+	codeWidth := bounds.Dx() * pngModule
+	codeHeight := bounds.Dy() * pngModule
+	if bounds.Dy() == 1 {
+		// Linear barcodes are one module high, give them a usable height
+		codeHeight = 80
+	}
+
+	width := codeWidth + 2*pngMargin
+	for _, line := range lines {
+		if w := drawer.MeasureString(line).Ceil() + 2*pngMargin; w > width {
+			width = w
+		}
+	}
+	height := codeHeight + 2*pngMargin + len(lines)*pngLineHeight
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	offsetX := (width - codeWidth) / 2
+	for x := 0; x < codeWidth; x++ {
+		for y := 0; y < codeHeight; y++ {
+			srcY := y / pngModule
+			if bounds.Dy() == 1 {
+				srcY = 0
+			}
+			if isDark(code.At(bounds.Min.X+x/pngModule, bounds.Min.Y+srcY)) {
+				img.Set(offsetX+x, pngMargin+y, color.Black)
+			}
+		}
+	}
+
+	drawer.Dst = img
+	for i, line := range lines {
+		lineWidth := drawer.MeasureString(line).Ceil()
+		drawer.Dot = fixed.P((width-lineWidth)/2, pngMargin+codeHeight+(i+1)*pngLineHeight)
+		drawer.DrawString(line)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// PDF labels are 4x2 inch pages, the common size for bin label stock
+const (
+	pdfPageWidth  = 288.0
+	pdfPageHeight = 144.0
+	pdfMargin     = 12.0
+	pdfFontSize   = 9.0
+)
+
+// renderPDF writes a single page PDF with the barcode drawn as vector
+// rectangles, which keeps the document small and crisp at any print size.
+func renderPDF(code barcode.Barcode, label Label) ([]byte, error) {
+	bounds := code.Bounds()
+	textHeight := float64(len(label.Lines)) * (pdfFontSize + 2)
+	boxWidth := pdfPageWidth - 2*pdfMargin
+	boxHeight := pdfPageHeight - 2*pdfMargin - textHeight - 4
+
+	moduleW := boxWidth / float64(bounds.Dx())
+	moduleH := boxHeight / float64(bounds.Dy())
+	if bounds.Dy() > 1 {
+		// 2D symbols need square modules
+		moduleW = min(moduleW, moduleH)
+		moduleH = moduleW
+	}
+	codeWidth := moduleW * float64(bounds.Dx())
+	codeHeight := moduleH * float64(bounds.Dy())
+	originX := (pdfPageWidth - codeWidth) / 2
+	originY := pdfPageHeight - pdfMargin - codeHeight
+
+	var content bytes.Buffer
+	content.WriteString("0 g\n")
+	for x := 0; x < bounds.Dx(); x++ {
+		for y := 0; y < bounds.Dy(); y++ {
+			if !isDark(code.At(bounds.Min.X+x, bounds.Min.Y+y)) {
+				continue
+			}
+			// PDF coordinates grow upwards, barcode rows grow downwards
+			fmt.Fprintf(&content, "%.2f %.2f %.2f %.2f re\n",
+				originX+float64(x)*moduleW,
+				originY+codeHeight-float64(y+1)*moduleH,
+				moduleW, moduleH)
+		}
+	}
+	content.WriteString("f\n")
+
+	for i, line := range label.Lines {
+		text := winAnsi(line)
+		// Helvetica averages roughly half an em per glyph, one byte each
+		lineWidth := float64(len(text)) * pdfFontSize * 0.5
+		fmt.Fprintf(&content, "BT /F1 %.1f Tf %.2f %.2f Td (%s) Tj ET\n",
+			pdfFontSize,
+			(pdfPageWidth-lineWidth)/2,
+			originY-4-float64(i+1)*(pdfFontSize+2),
+			escapePDFString(text))
+	}
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 4 0 R >> >> /Contents 5 0 R >>",
+			pdfPageWidth, pdfPageHeight),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return buf.Bytes(), nil
+}
+
+func isDark(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	return r+g+b < 3*0x8000
+}
+
+// winAnsi encodes s for the standard PDF fonts, one byte per glyph. They
+// only have the Windows-1252 characters, fold drops the accents they lack.
+func winAnsi(s string) string {
+	folded := fold(s, func(r rune) bool {
+		_, ok := charmap.Windows1252.EncodeRune(r)
+		return ok
+	})
+	encoded := make([]byte, 0, len(folded))
+	for _, r := range folded {
+		b, _ := charmap.Windows1252.EncodeRune(r)
+		encoded = append(encoded, b)
+	}
+	return string(encoded)
+}
+
+// escapePDFString escapes characters with special meaning in PDF literal strings
+func escapePDFString(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`).Replace(s)
+}
@@ -0,0 +1,82 @@
+package labels
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/font"
+)
+
+func TestRender(t *testing.T) {
+	label := Label{Code: "WH1-R12", Lines: []string{"Kho Hà Nội", "Room 12"}}
+	for _, symbology := range []Symbology{SymbologyQR, SymbologyCode128} {
+		for format, contentType := range map[Format]string{
+			FormatPNG: "image/png",
+			FormatPDF: "application/pdf",
+			FormatZPL: "application/zpl",
+		} {
+			data, gotType, err := Render(label, symbology, format)
+			if err != nil {
+				t.Fatalf("Render(%s, %s): %v", symbology, format, err)
+			}
+			if gotType != contentType || len(data) == 0 {
+				t.Errorf("Render(%s, %s) = %d bytes of %s, want %s", symbology, format, len(data), gotType, contentType)
+			}
+		}
+	}
+
+	if _, _, err := Render(label, "ean13", FormatPNG); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("unknown symbology: err = %v, want ErrUnsupported", err)
+	}
+	if _, _, err := Render(label, SymbologyQR, "svg"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("unknown format: err = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestRenderPNGFitsText(t *testing.T) {
+	// Wider than the barcode, in letters several bytes long in UTF-8
+	line := strings.Repeat("Ωж", 30)
+	data, _, err := Render(Label{Code: "1", Lines: []string{line}}, SymbologyCode128, FormatPNG)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	face, err := pngFace()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := font.MeasureString(face, line).Ceil() + 2*pngMargin
+	if got := img.Bounds().Dx(); got != want {
+		t.Errorf("width = %d, want %d to fit the text", got, want)
+	}
+}
+
+func TestRenderPDFText(t *testing.T) {
+	data, _, err := Render(Label{Code: "1", Lines: []string{"Crème (1)", "Kho Hà Nội"}}, SymbologyQR, FormatPDF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"/Encoding /WinAnsiEncoding", "(Cr\xe8me \\(1\\))", "(Kho H\xe0 Noi)"} {
+		if !bytes.Contains(data, []byte(want)) {
+			t.Errorf("PDF has no %q", want)
+		}
+	}
+}
+
+func TestRenderZPL(t *testing.T) {
+	data, err := RenderZPL(Label{Code: "A^B", Lines: []string{"Kho Hà Nội_1"}}, SymbologyCode128, ZPLOptions{Copies: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"^CI28", "^FDA_5EB^FS", "^FDKho Hà Nội_5F1^FS", "^PQ2"} {
+		if !bytes.Contains(data, []byte(want)) {
+			t.Errorf("ZPL has no %q:\n%s", want, data)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package labels
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// fold returns s with every rune the font cannot show replaced: accented
+// letters lose their accents, "Kho Hà Nội" is "Kho Ha Noi" to a font
+// without Vietnamese, and what is left without a glyph becomes '?'.
+func fold(s string, has func(rune) bool) string {
+	var b strings.Builder
+	for _, r := range norm.NFC.String(s) {
+		if has(r) {
+			b.WriteRune(r)
+			continue
+		}
+		if unicode.Is(unicode.Mn, r) {
+			continue // an accent NFC found no letter to join
+		}
+		switch r {
+		case 'đ':
+			r = 'd'
+		case 'Đ':
+			r = 'D'
+		}
+		for _, base := range norm.NFD.String(string(r)) {
+			switch {
+			case unicode.Is(unicode.Mn, base):
+				// The accent is dropped
+			case has(base):
+				b.WriteRune(base)
+			default:
+				b.WriteByte('?')
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,45 @@
+package labels
+
+import (
+	"testing"
+	"unicode"
+)
+
+func TestFold(t *testing.T) {
+	ascii := func(r rune) bool { return r <= unicode.MaxASCII }
+	latin1 := func(r rune) bool { return r <= unicode.MaxLatin1 }
+	tests := []struct {
+		in   string
+		has  func(rune) bool
+		want string
+	}{
+		{in: "Room 12", has: ascii, want: "Room 12"},
+		{in: "Kho Hà Nội", has: ascii, want: "Kho Ha Noi"},
+		{in: "Đà Nẵng", has: ascii, want: "Da Nang"},
+		{in: "Crème Brûlée", has: latin1, want: "Crème Brûlée"},
+		{in: "Kho Hà Nội", has: latin1, want: "Kho Hà Noi"},
+		// Decomposed accents are joined to their letter first
+		{in: "Cre\u0300me", has: latin1, want: "Crème"},
+		{in: "倉庫 A", has: ascii, want: "?? A"},
+	}
+	for _, tt := range tests {
+		if got := fold(tt.in, tt.has); got != tt.want {
+			t.Errorf("fold(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWinAnsi(t *testing.T) {
+	tests := map[string]string{
+		"SKU-1 (x2)": "SKU-1 (x2)",
+		"Crème":      "Cr\xe8me",
+		"5 €":        "5 \x80",
+		"Kho Hà Nội": "Kho H\xe0 Noi",
+		"Склад":      "?????",
+	}
+	for in, want := range tests {
+		if got := winAnsi(in); got != want {
+			t.Errorf("winAnsi(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package labels
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/boombuler/barcode"
+)
+
+// ZPL labels are laid out for 4x2 inch stock like PDF labels
+const (
+	zplWidthInches  = 4
+	zplHeightInches = 2
+	// Resolution the dot sizes below are given for, 8 dots per mm
+	zplBaseDPI = 203
+	zplMargin  = 24
+	zplFont    = 28
+	// Linear barcodes are one module high, give them a usable height
+	zplLinearHeight = 100
+)
+
+// ZPLOptions sets up a ZPL label for the printer it is sent to
+type ZPLOptions struct {
+	// Print resolution of the printer, 203 dpi when zero
+	DPI int
+	// Copies printed of the label, one when zero
+	Copies int
+}
+
+// RenderZPL encodes the label as a ZPL II document for Zebra printers. The
+// printer draws the barcode itself, the symbology only picks its command.
+func RenderZPL(label Label, symbology Symbology, opts ZPLOptions) ([]byte, error) {
+	code, err := encode(label.Code, symbology)
+	if err != nil {
+		return nil, err
+	}
+	return renderZPL(code, label, opts)
+}
+
+func renderZPL(code barcode.Barcode, label Label, opts ZPLOptions) ([]byte, error) {
+	if opts.DPI <= 0 {
+		opts.DPI = zplBaseDPI
+	}
+	if opts.Copies <= 0 {
+		opts.Copies = 1
+	}
+	dots := func(n int) int {
+		return max(1, n*opts.DPI/zplBaseDPI)
+	}
+
+	var buf bytes.Buffer
+	// UTF-8 field data, label size in dots
+	fmt.Fprintf(&buf, "^XA\n^CI28\n^PW%d\n^LL%d\n", zplWidthInches*opts.DPI, zplHeightInches*opts.DPI)
+
+	codeHeight := dots(zplLinearHeight)
+	fmt.Fprintf(&buf, "^FO%d,%d", dots(zplMargin), dots(zplMargin))
+	switch code.Metadata().CodeKind {
+	case barcode.TypeQR:
+		// Magnification is the module size in dots, at most 10
+		magnification := min(10, dots(4))
+		codeHeight = code.Bounds().Dy() * magnification
+		fmt.Fprintf(&buf, "^BQN,2,%d^FH_^FDMA,%s^FS\n", magnification, escapeZPL(label.Code))
+	default:
+		fmt.Fprintf(&buf, "^BY%d^BCN,%d,N,N,N^FH_^FD%s^FS\n", dots(2), codeHeight, escapeZPL(label.Code))
+	}
+
+	y := dots(zplMargin) + codeHeight + dots(zplMargin)/2
+	for _, line := range label.Lines {
+		fmt.Fprintf(&buf, "^FO%d,%d^A0N,%d,%d^FH_^FD%s^FS\n", dots(zplMargin), y, dots(zplFont), dots(zplFont), escapeZPL(line))
+		y += dots(zplFont) + dots(zplFont)/4
+	}
+
+	fmt.Fprintf(&buf, "^PQ%d\n^XZ\n", opts.Copies)
+	return buf.Bytes(), nil
+}
+
+// escapeZPL hex-escapes the characters that would start a ZPL command in
+// field data, to be used with ^FH_
+func escapeZPL(s string) string {
+	return strings.NewReplacer("_", "_5F", "^", "_5E", "~", "_7E").Replace(s)
+}
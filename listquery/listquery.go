@@ -0,0 +1,266 @@
+// Package listquery builds the SQL of list endpoints from their sort and
+// filter parameters. Only the fields and filters a Spec whitelists are
+// accepted: column names come from the Spec, never from the request, and
+// every value is passed as a query argument.
+package listquery
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// SortParam is the query parameter listing the sort fields, such as
+// sort=name,-created_at where a leading - sorts descending
+const SortParam = "sort"
+
+// Op compares a column with the value of a filter
+type Op string
+
+const (
+	Eq  Op = "="
+	Gte Op = ">="
+	Lt  Op = "<"
+	// Contains matches array and JSON columns holding every element of the
+	// value
+	Contains Op = "@>"
+	// IsNull takes a bool, true for rows whose column is NULL and false for
+	// the others
+	IsNull Op = "IS NULL"
+)
+
+// Order sorts by one field of a Spec
+type Order struct {
+	Field string
+	Desc  bool
+}
+
+// Filter is a query parameter restricting the rows of a list to those whose
+// column compares with its parsed value
+type Filter struct {
+	Column string
+	Op     Op
+	Parse  func(string) (any, error)
+}
+
+// Spec is what a list endpoint accepts: the fields it sorts by, mapped to
+// their column, and its filters by query parameter
+type Spec struct {
+	Table   string
+	Sort    map[string]string
+	Filters map[string]Filter
+	// Default is the order of requests without a sort parameter
+	Default []Order
+	// Key is a unique column appended to every order, so that pages do not
+	// repeat or skip rows sorted equal
+	Key string
+}
+
+// Fields returns the sort fields of s in alphabetical order
+func (s *Spec) Fields() []string {
+	fields := make([]string, 0, len(s.Sort))
+	for field := range s.Sort {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// Query is a list query being built from a Spec
+type Query struct {
+	spec   *Spec
+	where  []string
+	args   []any
+	order  []Order
+	limit  int32
+	offset int32
+}
+
+// New starts a query of s in its default order
+func (s *Spec) New() *Query {
+	return &Query{spec: s, order: s.Default}
+}
+
+// Parse starts a query of s from the sort and filter parameters of values.
+// Unknown sort fields and invalid filter values are errors, other
+// parameters are left to the caller.
+func (s *Spec) Parse(values url.Values) (*Query, error) {
+	q := s.New()
+	if raw, ok := values[SortParam]; ok {
+		if len(raw) > 1 {
+			return nil, fmt.Errorf("%s can only be given once", SortParam)
+		}
+		order, err := s.parseSort(raw[0])
+		if err != nil {
+			return nil, err
+		}
+		q.order = order
+	}
+	params := make([]string, 0, len(s.Filters))
+	for param := range s.Filters {
+		params = append(params, param)
+	}
+	sort.Strings(params)
+	for _, param := range params {
+		raw, ok := values[param]
+		if !ok || raw[0] == "" {
+			continue
+		}
+		if len(raw) > 1 {
+			return nil, fmt.Errorf("%s can only be given once", param)
+		}
+		filter := s.Filters[param]
+		value, err := filter.Parse(raw[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", param, err)
+		}
+		q.Where(filter.Column, filter.Op, value)
+	}
+	return q, nil
+}
+
+func (s *Spec) parseSort(raw string) ([]Order, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, fmt.Errorf("%s must list at least one field", SortParam)
+	}
+	var order []Order
+	seen := map[string]bool{}
+	for _, field := range strings.Split(raw, ",") {
+		field = strings.TrimSpace(field)
+		name, desc := strings.CutPrefix(field, "-")
+		if _, ok := s.Sort[name]; !ok {
+			return nil, fmt.Errorf("unknown %s field %q, sort by %s", SortParam, field, strings.Join(s.Fields(), ", "))
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%s field %q is given twice", SortParam, name)
+		}
+		seen[name] = true
+		order = append(order, Order{Field: name, Desc: desc})
+	}
+	return order, nil
+}
+
+// Where restricts the query to rows whose column compares with value by
+// op. The column is quoted, value passed as an argument.
+func (q *Query) Where(column string, op Op, value any) *Query {
+	col := pgx.Identifier{column}.Sanitize()
+	if op == IsNull {
+		if null, _ := value.(bool); null {
+			q.where = append(q.where, col+" IS NULL")
+		} else {
+			q.where = append(q.where, col+" IS NOT NULL")
+		}
+		return q
+	}
+	q.args = append(q.args, value)
+	q.where = append(q.where, fmt.Sprintf("%s %s $%d", col, op, len(q.args)))
+	return q
+}
+
+// Page limits the query to limit rows after skipping offset
+func (q *Query) Page(limit, offset int32) *Query {
+	q.limit, q.offset = limit, offset
+	return q
+}
+
+// SQL returns the statement selecting every column of the rows of the
+// query, and its arguments
+func (q *Query) SQL() (string, []any) {
+	var b strings.Builder
+	b.WriteString("SELECT * FROM ")
+	b.WriteString(pgx.Identifier{q.spec.Table}.Sanitize())
+	if len(q.where) > 0 {
+		b.WriteString(" WHERE ")
+		b.WriteString(strings.Join(q.where, " AND "))
+	}
+	var order []string
+	keyed := false
+	for _, o := range q.order {
+		column := q.spec.Sort[o.Field]
+		keyed = keyed || column == q.spec.Key
+		term := pgx.Identifier{column}.Sanitize()
+		if o.Desc {
+			term += " DESC"
+		}
+		order = append(order, term)
+	}
+	if q.spec.Key != "" && !keyed {
+		order = append(order, pgx.Identifier{q.spec.Key}.Sanitize())
+	}
+	if len(order) > 0 {
+		b.WriteString(" ORDER BY ")
+		b.WriteString(strings.Join(order, ", "))
+	}
+	args := slices.Clone(q.args)
+	if q.limit > 0 {
+		args = append(args, q.limit)
+		fmt.Fprintf(&b, " LIMIT $%d", len(args))
+	}
+	if q.offset > 0 {
+		args = append(args, q.offset)
+		fmt.Fprintf(&b, " OFFSET $%d", len(args))
+	}
+	return b.String(), args
+}
+
+// CountSQL returns the statement counting the rows of the query across
+// every page, and its arguments
+func (q *Query) CountSQL() (string, []any) {
+	var b strings.Builder
+	b.WriteString("SELECT count(*) FROM ")
+	b.WriteString(pgx.Identifier{q.spec.Table}.Sanitize())
+	if len(q.where) > 0 {
+		b.WriteString(" WHERE ")
+		b.WriteString(strings.Join(q.where, " AND "))
+	}
+	return b.String(), slices.Clone(q.args)
+}
+
+// Text takes the parameter as it is
+func Text(raw string) (any, error) {
+	return raw, nil
+}
+
+// OneOf accepts one of values
+func OneOf(values ...string) func(string) (any, error) {
+	return func(raw string) (any, error) {
+		if !slices.Contains(values, raw) {
+			return nil, fmt.Errorf("%q is not one of %s", raw, strings.Join(values, ", "))
+		}
+		return raw, nil
+	}
+}
+
+// Int takes a 64-bit integer
+func Int(raw string) (any, error) {
+	n, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return nil, errors.New("must be an integer")
+	}
+	return n, nil
+}
+
+// Bool takes true or false, as strconv.ParseBool reads them
+func Bool(raw string) (any, error) {
+	b, err := strconv.ParseBool(raw)
+	if err != nil {
+		return nil, errors.New("must be true or false")
+	}
+	return b, nil
+}
+
+// Time takes an RFC 3339 time
+func Time(raw string) (any, error) {
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, errors.New("must be an RFC 3339 time")
+	}
+	return t, nil
+}
@@ -0,0 +1,186 @@
+package listquery
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testSpec = &Spec{
+	Table: "warehouse",
+	Sort: map[string]string{
+		"id":      "id",
+		"name":    "name",
+		"created": "created_at",
+	},
+	Filters: map[string]Filter{
+		"status":        {Column: "status", Op: Eq, Parse: OneOf("active", "closed")},
+		"city":          {Column: "city", Op: Eq, Parse: Text},
+		"created_after": {Column: "created_at", Op: Gte, Parse: Time},
+	},
+	Default: []Order{{Field: "id", Desc: true}},
+	Key:     "id",
+}
+
+func TestSQL(t *testing.T) {
+	for _, c := range []struct {
+		query string
+		sql   string
+		args  []any
+	}{
+		{
+			query: "",
+			sql:   `SELECT * FROM "warehouse" WHERE "org_id" = $1 ORDER BY "id" DESC LIMIT $2`,
+			args:  []any{"org_1", int32(20)},
+		},
+		{
+			query: "sort=name,-created",
+			sql:   `SELECT * FROM "warehouse" WHERE "org_id" = $1 ORDER BY "name", "created_at" DESC, "id" LIMIT $2`,
+			args:  []any{"org_1", int32(20)},
+		},
+		{
+			query: "sort=-id,name&status=active&city=Hanoi&limit=5",
+			sql:   `SELECT * FROM "warehouse" WHERE "city" = $1 AND "status" = $2 AND "org_id" = $3 ORDER BY "id" DESC, "name" LIMIT $4`,
+			args:  []any{"Hanoi", "active", "org_1", int32(20)},
+		},
+		{
+			query: "created_after=2026-10-15T09:00:00Z&status=",
+			sql:   `SELECT * FROM "warehouse" WHERE "created_at" >= $1 AND "org_id" = $2 ORDER BY "id" DESC LIMIT $3`,
+			args:  []any{time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC), "org_1", int32(20)},
+		},
+	} {
+		values, err := url.ParseQuery(c.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		q, err := testSpec.Parse(values)
+		if err != nil {
+			t.Fatalf("%s: %v", c.query, err)
+		}
+		sql, args := q.Where("org_id", Eq, "org_1").Page(20, 0).SQL()
+		if sql != c.sql {
+			t.Errorf("%s: got %s, want %s", c.query, sql, c.sql)
+		}
+		if !reflect.DeepEqual(args, c.args) {
+			t.Errorf("%s: got args %v, want %v", c.query, args, c.args)
+		}
+	}
+}
+
+func TestWhereAndPage(t *testing.T) {
+	q := testSpec.New()
+	q.Where("org_id", Eq, "org_1").
+		Where("tags", Contains, []string{"cold"}).
+		Where("closed_at", IsNull, true).
+		Where("deleted_at", IsNull, false).
+		Page(10, 30)
+	sql, args := q.SQL()
+	want := `SELECT * FROM "warehouse" WHERE "org_id" = $1 AND "tags" @> $2 AND "closed_at" IS NULL AND "deleted_at" IS NOT NULL ORDER BY "id" DESC LIMIT $3 OFFSET $4`
+	if sql != want {
+		t.Errorf("got %s, want %s", sql, want)
+	}
+	if !reflect.DeepEqual(args, []any{"org_1", []string{"cold"}, int32(10), int32(30)}) {
+		t.Errorf("args %v", args)
+	}
+	// SQL does not change the query
+	if again, _ := q.SQL(); again != sql {
+		t.Errorf("second SQL %s", again)
+	}
+
+	count, args := q.CountSQL()
+	want = `SELECT count(*) FROM "warehouse" WHERE "org_id" = $1 AND "tags" @> $2 AND "closed_at" IS NULL AND "deleted_at" IS NOT NULL`
+	if count != want {
+		t.Errorf("got %s, want %s", count, want)
+	}
+	if !reflect.DeepEqual(args, []any{"org_1", []string{"cold"}}) {
+		t.Errorf("count args %v", args)
+	}
+}
+
+func TestSortInjection(t *testing.T) {
+	for _, sort := range []string{
+		"name;DROP TABLE warehouse",
+		"name; DROP TABLE warehouse--",
+		"name DESC",
+		"name,(SELECT 1)",
+		`"name"`,
+		"created_at",
+		"Name",
+		"1",
+		"-",
+		"--name",
+		"name,",
+		",",
+		"name,name",
+		"name,-name",
+		"id/**/DESC",
+		"name\x00",
+		"",
+	} {
+		values := url.Values{SortParam: {sort}}
+		if q, err := testSpec.Parse(values); err == nil {
+			sql, _ := q.SQL()
+			t.Errorf("sort=%q accepted as %s", sort, sql)
+		}
+	}
+	if _, err := testSpec.Parse(url.Values{SortParam: {"name", "id"}}); err == nil {
+		t.Error("sort given twice was accepted")
+	}
+}
+
+func TestFilterInjection(t *testing.T) {
+	attack := "Hanoi' OR '1'='1"
+	q, err := testSpec.Parse(url.Values{"city": {attack}, "org_id": {"org_2"}, "1=1;--": {"x"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sql, args := q.SQL()
+	if strings.Contains(sql, "Hanoi") || strings.Contains(sql, "org_2") || strings.Contains(sql, "1=1") {
+		t.Errorf("request text in %s", sql)
+	}
+	if len(args) != 1 || args[0] != attack {
+		t.Errorf("args %v", args)
+	}
+
+	for _, values := range []url.Values{
+		{"status": {"active' --"}},
+		{"status": {"active", "closed"}},
+		{"created_after": {"now()"}},
+		{"created_after": {"2026-10-15'; DROP TABLE warehouse; --"}},
+	} {
+		if _, err := testSpec.Parse(values); err == nil {
+			t.Errorf("%v accepted", values)
+		}
+	}
+}
+
+func TestIdentifiersQuoted(t *testing.T) {
+	// Columns of a spec are quoted, whatever they hold
+	spec := &Spec{
+		Table:   `odd"table`,
+		Sort:    map[string]string{"x": `x"; DROP TABLE warehouse; --`},
+		Default: []Order{{Field: "x"}},
+	}
+	sql, _ := spec.New().Where(`y" = '' OR "1`, Eq, 1).SQL()
+	want := `SELECT * FROM "odd""table" WHERE "y"" = '' OR ""1" = $1 ORDER BY "x""; DROP TABLE warehouse; --"`
+	if sql != want {
+		t.Errorf("got %s, want %s", sql, want)
+	}
+}
+
+func TestParsers(t *testing.T) {
+	if v, err := Int("42"); err != nil || v != int64(42) {
+		t.Errorf("Int(42) = %v, %v", v, err)
+	}
+	if _, err := Int("42 OR 1=1"); err == nil {
+		t.Error("Int accepted 42 OR 1=1")
+	}
+	if v, err := Bool("true"); err != nil || v != true {
+		t.Errorf("Bool(true) = %v, %v", v, err)
+	}
+	if _, err := OneOf("a", "b")("c"); err == nil || !strings.Contains(err.Error(), "a, b") {
+		t.Errorf("OneOf error %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package middlewares
+
+import (
+	"log/slog"
+	"math/rand/v2"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader carries the request ID in both directions
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID reuses the caller's X-Request-ID or generates one, stores it as
+// "request_id" in the context and echoes it on the response
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" || len(id) > 128 {
+			id = uuid.NewString()
+		}
+		c.Set("request_id", id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// AccessLogSampling thins out the access log of successful requests.
+// Client and server errors and requests taking at least SlowThreshold are
+// always logged, 0 logs none as slow.
+type AccessLogSampling struct {
+	// Fraction of the other requests logged, from 0 to 1
+	Ratio         float64
+	SlowThreshold time.Duration
+}
+
+// sampled reports whether a request is left to sampling, rather than always
+// logged
+func (s AccessLogSampling) sampled(status int, latency time.Duration) bool {
+	return status < 400 && (s.SlowThreshold <= 0 || latency < s.SlowThreshold)
+}
+
+// keep draws whether a sampled request is logged
+func (s AccessLogSampling) keep() bool {
+	switch {
+	case s.Ratio >= 1:
+		return true
+	case s.Ratio <= 0:
+		return false
+	}
+	return rand.Float64() < s.Ratio
+}
+
+// accessLogSampling starts out logging every request
+var accessLogSampling atomic.Pointer[AccessLogSampling]
+
+// SetAccessLogSampling changes the sampling of the access log, for the
+// requests finishing from now on
+func SetAccessLogSampling(s AccessLogSampling) {
+	accessLogSampling.Store(&s)
+}
+
+// AccessLog replaces gin's text logger with one structured slog entry per
+// request. Server errors log at error level and client errors at warn.
+// Successful requests are sampled, see SetAccessLogSampling.
+func AccessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		latency := time.Since(start)
+		sampling := AccessLogSampling{Ratio: 1}
+		if s := accessLogSampling.Load(); s != nil {
+			sampling = *s
+		}
+		sampled := sampling.sampled(status, latency)
+		if sampled && !sampling.keep() {
+			return
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("route", route),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Duration("latency", latency),
+			slog.Int("bytes", c.Writer.Size()),
+			slog.String("client_ip", c.ClientIP()),
+			slog.String("request_id", c.GetString("request_id")),
+		}
+		if userID := c.GetString("user_id"); userID != "" {
+			attrs = append(attrs, slog.String("user_id", userID))
+		}
+		if orgID := c.GetString("org_id"); orgID != "" {
+			attrs = append(attrs, slog.String("tenant_id", orgID))
+		}
+		if sc := trace.SpanContextFromContext(c.Request.Context()); sc.HasTraceID() {
+			attrs = append(attrs, slog.String("trace_id", sc.TraceID().String()))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
+		}
+		// Lets log queries scale sampled counts back up
+		if sampled && sampling.Ratio < 1 {
+			attrs = append(attrs, slog.Float64("sample_ratio", sampling.Ratio))
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+		slog.LogAttrs(c.Request.Context(), level, "HTTP request", attrs...)
+	}
+}
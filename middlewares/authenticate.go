@@ -1,44 +1,63 @@
 package middlewares
 
 import (
-  "github.com/clerk/clerk-sdk-go/v2/jwt"
+  "errors"
   "log/slog"
   "net/http"
   "strings"
+  "time"
 
   "github.com/gin-gonic/gin"
-  "github.com/jackc/pgx/v5"
+  "github.com/jackc/pgx/v5/pgxpool"
 )
 
-func ClerkAuth(db *pgx.Conn) gin.HandlerFunc {
+func ClerkAuth(db *pgxpool.Pool) gin.HandlerFunc {
   return func(c *gin.Context) {
+    if id := c.GetString(serviceIdentityKey); id != "" {
+      authenticateService(c, id)
+      return
+    }
     authHeader := c.GetHeader("Authorization")
     if authHeader == "" {
       c.JSON(http.StatusUnauthorized, gin.H{
-        "error":   "Unauthorized",
-        "message": "Authorization header required",
+        "error":   Translate(c, "Unauthorized"),
+        "message": Translate(c, "Authorization header required"),
       })
       slog.Error("Unable to get authorization header")
       c.Abort()
       return
     }
-    sessionToken := strings.TrimPrefix(authHeader, "Bearer")
+    sessionToken := strings.TrimPrefix(authHeader, "Bearer ")
     if sessionToken == authHeader || sessionToken == "" {
       c.JSON(http.StatusUnauthorized, gin.H{
-        "error":   "Unauthorized",
-        "message": "Bearer token required",
+        "error":   Translate(c, "Unauthorized"),
+        "message": Translate(c, "Bearer token required"),
       })
       slog.Error("Unable to get authorization header")
       c.Abort()
       return
     }
-    claims, err := jwt.Verify(c.Request.Context(), &jwt.VerifyParams{
-      Token: sessionToken,
-    })
+    if strings.HasPrefix(sessionToken, APIKeyPrefix) {
+      authenticateAPIKey(c, db, sessionToken)
+      return
+    }
+    start := time.Now()
+    claims, err := verifySessionToken(c.Request.Context(), sessionToken)
+    clerkKeys.observeVerification(c.Request.Context(), start, err)
+    if errors.Is(err, errKeysUnavailable) {
+      // Clerk is down and the key isn't cached, the token may be valid
+      c.JSON(http.StatusServiceUnavailable, gin.H{
+        "error":   Translate(c, "Service Unavailable"),
+        "message": Translate(c, "Unable to verify token, try again later"),
+      })
+      slog.Error("Clerk signing keys unavailable: ", slog.Any("ERROR", err.Error()))
+      c.Abort()
+      return
+    }
     if err != nil {
       c.JSON(http.StatusUnauthorized, gin.H{
-        "error":   "Unauthorized",
-        "message": "Invalid or expired token",
+        "error":   Translate(c, "Unauthorized"),
+        "message": Translate(c, "Invalid or expired token"),
         "detail":  err.Error(),
       })
       slog.Error("User token is invalid: ", slog.Any("ERROR", err.Error()))
@@ -47,6 +66,7 @@ func ClerkAuth(db *pgx.Conn) gin.HandlerFunc {
     }
     c.Set("claims", claims)
     c.Set("user_id", claims.Subject)
+    c.Set("org_id", claims.ActiveOrganizationID)
     c.Next()
   }
 }
@@ -0,0 +1,54 @@
+package middlewares
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BodyLimit answers requests with a body over max bytes with 413 before
+// they reach the handler. Routes in exempt, such as attachment uploads,
+// enforce their own limit. A max of zero disables the limit.
+func BodyLimit(max int64, exempt ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body := c.Request.Body
+		if max <= 0 || body == nil || body == http.NoBody || slices.Contains(exempt, c.FullPath()) {
+			c.Next()
+			return
+		}
+		if c.Request.ContentLength > max {
+			bodyTooLarge(c, max)
+			return
+		}
+		if c.Request.ContentLength < 0 {
+			// Without a Content-Length the body is read up front, so a
+			// handler never sees a truncated payload it would answer with 400
+			buf, err := io.ReadAll(io.LimitReader(body, max+1))
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+					"error": Translate(c, "Could not read the request body"),
+				})
+				slog.Warn("Could not read request body", slog.Any("error", err))
+				return
+			}
+			if int64(len(buf)) > max {
+				bodyTooLarge(c, max)
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(buf))
+		}
+		c.Next()
+	}
+}
+
+func bodyTooLarge(c *gin.Context, max int64) {
+	// The rest of the body is not read, don't reuse the connection
+	c.Header("Connection", "close")
+	c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+		"error": Translate(c, "Request body must be at most %d bytes", max),
+	})
+}
@@ -0,0 +1,14 @@
+package middlewares
+
+import (
+	"github.com/clerk/clerk-sdk-go/v2"
+)
+
+// SetClerkKey installs the Clerk secret key. The backend is swapped under
+// the SDK's lock so a rotated key can be applied while requests are served.
+func SetClerkKey(key string) {
+	clerk.SetKey(key)
+	clerk.SetBackend(clerk.NewBackend(&clerk.BackendConfig{
+		Key: clerk.String(key),
+	}))
+}
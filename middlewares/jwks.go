@@ -0,0 +1,263 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/clerk/clerk-sdk-go/v2"
+	"github.com/clerk/clerk-sdk-go/v2/jwks"
+	"github.com/clerk/clerk-sdk-go/v2/jwt"
+
+	"warehouse-service/observability"
+)
+
+// JWKSConfig controls how the Clerk signing keys are kept. Zero values
+// fall back to the defaults.
+type JWKSConfig struct {
+	RefreshInterval time.Duration
+	// Consecutive failed fetches that open the circuit, Clerk is not asked
+	// again until Cooldown has passed and the cached keys keep serving
+	FailureThreshold int
+	Cooldown         time.Duration
+	// Accepted difference between our clock and Clerk's on exp and nbf
+	ClockSkew time.Duration
+	// PEM public key from the Clerk dashboard. When set every token is
+	// verified with it and the JWKS is never fetched.
+	StaticKey string
+}
+
+func (c JWKSConfig) withDefaults() JWKSConfig {
+	if c.RefreshInterval <= 0 {
+		c.RefreshInterval = time.Hour
+	}
+	if c.FailureThreshold <= 0 {
+		c.FailureThreshold = 3
+	}
+	if c.Cooldown <= 0 {
+		c.Cooldown = 30 * time.Second
+	}
+	if c.ClockSkew < 0 {
+		c.ClockSkew = 0
+	}
+	return c
+}
+
+const (
+	// jwksFetchTimeout bounds one fetch, it runs detached from the request
+	// so a client hanging up does not count as a Clerk failure
+	jwksFetchTimeout = 5 * time.Second
+	// minKeyLookupInterval limits the fetches for unknown key IDs, which
+	// anyone can put in a token
+	minKeyLookupInterval = 10 * time.Second
+)
+
+// errKeysUnavailable means there is no key to check the token with because
+// Clerk could not be reached, the token itself may be fine
+var errKeysUnavailable = errors.New("clerk signing keys unavailable")
+
+// jwksCache holds the Clerk signing keys by key ID. Fetches are serialized
+// by fetchMu, which also guards the circuit breaker state.
+type jwksCache struct {
+	mu      sync.RWMutex
+	keys    map[string]*clerk.JSONWebKey
+	static  *clerk.JSONWebKey
+	config  JWKSConfig
+	metrics *observability.PrometheusMetrics
+
+	fetchMu     sync.Mutex
+	lastAttempt time.Time
+	failures    int
+	openUntil   time.Time
+}
+
+// clerkKeys is shared by every ClerkAuth middleware. Without
+// ConfigureClerkJWKS it fetches on demand with the defaults.
+var clerkKeys = &jwksCache{config: JWKSConfig{}.withDefaults()}
+
+// ConfigureClerkJWKS applies c and reports verification metrics to m
+func ConfigureClerkJWKS(c JWKSConfig, m *observability.PrometheusMetrics) error {
+	var static *clerk.JSONWebKey
+	if c.StaticKey != "" {
+		var err error
+		if static, err = clerk.JSONWebKeyFromPEM(c.StaticKey); err != nil {
+			return fmt.Errorf("CLERK_JWT_KEY: %w", err)
+		}
+	}
+	clerkKeys.mu.Lock()
+	defer clerkKeys.mu.Unlock()
+	clerkKeys.config = c.withDefaults()
+	clerkKeys.static = static
+	clerkKeys.metrics = m
+	return nil
+}
+
+// StartJWKSRefresh fetches the keys and refreshes them in the background
+// until ctx is done, so requests find them cached
+func StartJWKSRefresh(ctx context.Context) {
+	clerkKeys.mu.RLock()
+	static, interval := clerkKeys.static, clerkKeys.config.RefreshInterval
+	clerkKeys.mu.RUnlock()
+	if static != nil {
+		slog.Info("Verifying Clerk session tokens with CLERK_JWT_KEY")
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := clerkKeys.refresh(ctx, false); err != nil {
+				slog.Warn("Could not refresh Clerk JWKS, keeping cached keys", slog.Any("error", err))
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// verifySessionToken checks a Clerk session token against the cached keys
+func verifySessionToken(ctx context.Context, token string) (*clerk.SessionClaims, error) {
+	unverified, err := jwt.Decode(ctx, &jwt.DecodeParams{Token: token})
+	if err != nil {
+		return nil, err
+	}
+	key, err := clerkKeys.key(ctx, unverified.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	clerkKeys.mu.RLock()
+	leeway := clerkKeys.config.ClockSkew
+	clerkKeys.mu.RUnlock()
+	return jwt.Verify(ctx, &jwt.VerifyParams{
+		Token:  token,
+		JWK:    key,
+		Leeway: leeway,
+	})
+}
+
+// key returns the key kid names. An unknown kid is looked up at Clerk, at
+// most once per minKeyLookupInterval, to pick up rotated keys early.
+func (c *jwksCache) key(ctx context.Context, kid string) (*clerk.JSONWebKey, error) {
+	if kid == "" {
+		if static := c.staticKey(); static != nil {
+			return static, nil
+		}
+		return nil, errors.New("missing jwt kid header claim")
+	}
+	if key, ok := c.lookup(kid); ok {
+		return key, nil
+	}
+	if err := c.refresh(ctx, true); err != nil {
+		return nil, fmt.Errorf("%w: %w", errKeysUnavailable, err)
+	}
+	if key, ok := c.lookup(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+func (c *jwksCache) staticKey() *clerk.JSONWebKey {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.static
+}
+
+func (c *jwksCache) lookup(kid string) (*clerk.JSONWebKey, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.static != nil {
+		return c.static, true
+	}
+	key, ok := c.keys[kid]
+	return key, ok
+}
+
+// refresh fetches the JWKS unless the circuit is open. A failed fetch
+// leaves the cached keys in place. onDemand fetches are rate limited.
+func (c *jwksCache) refresh(ctx context.Context, onDemand bool) error {
+	c.fetchMu.Lock()
+	defer c.fetchMu.Unlock()
+	c.mu.RLock()
+	config, metrics := c.config, c.metrics
+	c.mu.RUnlock()
+
+	now := time.Now()
+	if now.Before(c.openUntil) {
+		recordJWKSRefresh(metrics, "skipped")
+		return fmt.Errorf("circuit open until %s", c.openUntil.Format(time.RFC3339))
+	}
+	if onDemand && now.Sub(c.lastAttempt) < minKeyLookupInterval {
+		// Fetched moments ago, possibly by a request waiting on fetchMu
+		// for the same key
+		return nil
+	}
+	c.lastAttempt = now
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), jwksFetchTimeout)
+	defer cancel()
+	set, err := (&jwks.Client{Backend: clerk.GetBackend()}).Get(ctx, &jwks.GetParams{})
+	if err != nil {
+		c.failures++
+		recordJWKSRefresh(metrics, "error")
+		if c.failures >= config.FailureThreshold {
+			c.openUntil = now.Add(config.Cooldown)
+			slog.Warn("Clerk JWKS unreachable, pausing fetches",
+				slog.Int("failures", c.failures),
+				slog.Duration("cooldown", config.Cooldown))
+			if metrics != nil {
+				metrics.SetJWKSCircuitOpen(true)
+			}
+		}
+		return err
+	}
+
+	keys := make(map[string]*clerk.JSONWebKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k != nil && k.KeyID != "" {
+			keys[k.KeyID] = k
+		}
+	}
+	c.mu.Lock()
+	c.keys = keys
+	c.mu.Unlock()
+	c.failures = 0
+	c.openUntil = time.Time{}
+	recordJWKSRefresh(metrics, "success")
+	if metrics != nil {
+		metrics.SetJWKSCircuitOpen(false)
+		metrics.SetJWKSKeys(len(keys))
+	}
+	return nil
+}
+
+func recordJWKSRefresh(m *observability.PrometheusMetrics, status string) {
+	if m != nil {
+		m.RecordJWKSRefresh(status)
+	}
+}
+
+// observeVerification records the outcome and latency of a session token
+// check
+func (c *jwksCache) observeVerification(ctx context.Context, start time.Time, err error) {
+	c.mu.RLock()
+	m := c.metrics
+	c.mu.RUnlock()
+	if m == nil {
+		return
+	}
+	status := "success"
+	switch {
+	case errors.Is(err, errKeysUnavailable):
+		status = "unavailable"
+	case err != nil:
+		status = "failure"
+	}
+	m.RecordAuthAttempt(status, "jwt")
+	m.RecordAuthVerification(ctx, "jwt", status, time.Since(start))
+}
@@ -0,0 +1,38 @@
+package middlewares
+
+import (
+	"warehouse-service/i18n"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+)
+
+// localeKey holds the negotiated locale on the gin context
+const localeKey = "locale"
+
+// Localize negotiates the locale of the response messages from the
+// Accept-Language header and announces it with Content-Language. Responses
+// vary with Accept-Language for caches.
+func Localize() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale := i18n.Negotiate(c.GetHeader("Accept-Language"))
+		c.Set(localeKey, locale)
+		c.Header("Content-Language", locale.String())
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		c.Next()
+	}
+}
+
+// Locale returns the locale Localize negotiated, i18n.Default when it did
+// not run
+func Locale(c *gin.Context) language.Tag {
+	if locale, ok := c.Value(localeKey).(language.Tag); ok {
+		return locale
+	}
+	return i18n.Default
+}
+
+// Translate returns message in the request's locale, see i18n.Translate
+func Translate(c *gin.Context, message string, args ...any) string {
+	return i18n.Translate(Locale(c), message, args...)
+}
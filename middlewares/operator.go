@@ -0,0 +1,31 @@
+package middlewares
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireOperator rejects requests not made by one of userIDs with a Clerk
+// session. It must run after Identify. Organization roles and API keys
+// grant nothing here, operators act across every tenant.
+func RequireOperator(userIDs []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rc := GetRequestContext(c)
+		if rc.IsAPIKey() || !slices.Contains(userIDs, rc.UserID) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   Translate(c, "Forbidden"),
+				"message": Translate(c, "Operator access is required"),
+			})
+			slog.Warn("Request denied operator access",
+				slog.String("user_id", c.GetString("user_id")),
+				slog.String("path", c.FullPath()),
+			)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,27 @@
+package middlewares
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Options answers OPTIONS requests with 204 and the methods the path
+// accepts in Allow. It is the NoMethod handler of a router that handles
+// 405s: gin has matched the path against the other methods and set Allow
+// already. Other methods keep their 405, CORS preflights are answered by
+// the CORS middleware before.
+func Options() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodOptions {
+			return
+		}
+		allowed := strings.Split(c.Writer.Header().Get("Allow"), ", ")
+		allowed = append(allowed, http.MethodOptions)
+		slices.Sort(allowed)
+		c.Header("Allow", strings.Join(slices.Compact(allowed), ", "))
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
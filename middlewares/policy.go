@@ -0,0 +1,109 @@
+package middlewares
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+	"warehouse-service/observability"
+	"warehouse-service/policy"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Which policy decisions are logged, as in POLICY_DECISION_LOG
+const (
+	DecisionLogNone = "none"
+	DecisionLogDeny = "deny"
+	DecisionLogAll  = "all"
+)
+
+// Authorize asks engine whether the request may reach its handler. It must
+// run after Identify. A denied request is answered with 403 and one the
+// engine could not decide on with 503, the policy fails closed.
+func Authorize(engine policy.Engine, decisionLog string, prometheusMetrics *observability.PrometheusMetrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rc := GetRequestContext(c)
+		route := c.FullPath()
+		input := policy.Input{
+			Method:     c.Request.Method,
+			Route:      route,
+			Path:       c.Request.URL.Path,
+			Resource:   policy.ResourceOf(route),
+			ResourceID: c.Param("id"),
+			Subject: policy.Subject{
+				UserID:         rc.UserID,
+				OrgID:          rc.OrgID,
+				Role:           rc.OrgRole,
+				Permissions:    rc.Permissions,
+				APIKey:         rc.IsAPIKey(),
+				ServiceAccount: rc.ServiceAccount,
+			},
+		}
+
+		start := time.Now()
+		decision, err := engine.Evaluate(c.Request.Context(), input)
+		outcome := "allow"
+		switch {
+		case err != nil:
+			outcome = "error"
+		case !decision.Allow:
+			outcome = "deny"
+		}
+		if prometheusMetrics != nil {
+			prometheusMetrics.RecordPolicyDecision(engine.Name(), outcome, time.Since(start))
+		}
+		if decisionLog == DecisionLogAll || (decisionLog == DecisionLogDeny && outcome != "allow") {
+			logDecision(c, engine.Name(), input, decision, outcome, err)
+		}
+
+		switch outcome {
+		case "error":
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error":   Translate(c, "Service Unavailable"),
+				"message": Translate(c, "Unable to authorize the request, try again later"),
+			})
+			c.Abort()
+		case "deny":
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   Translate(c, "Forbidden"),
+				"message": Translate(c, "The request is not allowed by policy"),
+			})
+			c.Abort()
+		default:
+			c.Next()
+		}
+	}
+}
+
+// logDecision writes the audit record of a policy decision. The entries
+// share the "Policy decision" message so they can be routed apart from the
+// access log.
+func logDecision(c *gin.Context, engine string, input policy.Input, decision policy.Decision, outcome string, err error) {
+	attrs := []slog.Attr{
+		slog.String("engine", engine),
+		slog.String("decision", outcome),
+		slog.String("reason", decision.Reason),
+		slog.String("method", input.Method),
+		slog.String("route", input.Route),
+		slog.String("resource", input.Resource),
+		slog.String("resource_id", input.ResourceID),
+		slog.String("user_id", input.Subject.UserID),
+		slog.String("tenant_id", input.Subject.OrgID),
+		slog.String("role", input.Subject.Role),
+		slog.Bool("api_key", input.Subject.APIKey),
+		slog.Bool("service_account", input.Subject.ServiceAccount),
+		slog.String("request_id", c.GetString("request_id")),
+	}
+	if sc := trace.SpanContextFromContext(c.Request.Context()); sc.HasTraceID() {
+		attrs = append(attrs, slog.String("trace_id", sc.TraceID().String()))
+	}
+	level := slog.LevelInfo
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+		level = slog.LevelError
+	} else if outcome == "deny" {
+		level = slog.LevelWarn
+	}
+	slog.LogAttrs(c.Request.Context(), level, "Policy decision", attrs...)
+}
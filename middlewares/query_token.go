@@ -0,0 +1,16 @@
+package middlewares
+
+import "github.com/gin-gonic/gin"
+
+// BearerFromQuery uses the query parameter param as the bearer token when
+// the request has no Authorization header. Browsers cannot set headers on
+// a WebSocket handshake. Only the path is logged, so the token stays out
+// of the access log.
+func BearerFromQuery(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token := c.Query(param); token != "" && c.GetHeader("Authorization") == "" {
+			c.Request.Header.Set("Authorization", "Bearer "+token)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,61 @@
+package middlewares
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+	"warehouse-service/quota"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// MeterAPICalls counts every request towards the daily API calls of the
+// tenant and of the caller, and rejects it with 429 once either is over
+// its quota. It must run after RequireTenant. When the count cannot be
+// stored the request goes through uncounted.
+func MeterAPICalls(db *pgxpool.Pool, limits quota.Limits, prometheusMetrics *observability.PrometheusMetrics) gin.HandlerFunc {
+	queries := models.New(db)
+	return func(c *gin.Context) {
+		day, resetsAt := quota.Day(time.Now())
+		counts, err := queries.RecordAPICall(c.Request.Context(), models.RecordAPICallParams{
+			OrgID:  c.GetString("org_id"),
+			Day:    pgtype.Date{Time: day, Valid: true},
+			UserID: c.GetString("user_id"),
+		})
+		if err != nil {
+			slog.Error("Failed to count API call", slog.Any("error", err))
+			c.Next()
+			return
+		}
+		for _, count := range counts {
+			resource, limit := quota.ResourceAPICalls, limits.APICallsPerDay
+			if count.UserID != "" {
+				resource, limit = quota.ResourceAPICallsPerUser, limits.APICallsPerUserPerDay
+			}
+			if err := quota.Check(resource, limit, count.Calls); err != nil {
+				if prometheusMetrics != nil {
+					prometheusMetrics.RecordQuotaRejection(resource)
+				}
+				c.Header("Retry-After", strconv.Itoa(int(time.Until(resetsAt).Seconds())+1))
+				c.JSON(http.StatusTooManyRequests, gin.H{
+					"error":   Translate(c, "Too Many Requests"),
+					"message": Translate(c, "Daily quota of %d API calls exceeded", limit),
+					"quota":   resource,
+				})
+				slog.Warn("API call quota exceeded",
+					slog.String("org_id", c.GetString("org_id")),
+					slog.String("user_id", c.GetString("user_id")),
+					slog.String("quota", resource),
+				)
+				c.Abort()
+				return
+			}
+		}
+		c.Next()
+	}
+}
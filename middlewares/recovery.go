@@ -0,0 +1,74 @@
+package middlewares
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"warehouse-service/observability"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Recovery replaces gin's default recovery middleware. It logs the panic and
+// stack trace through slog, records the panic as an exception event on the
+// active span, counts it in Prometheus and answers with a sanitized 500 body.
+func Recovery(prometheusMetrics *observability.PrometheusMetrics) gin.HandlerFunc {
+	tracer := otel.Tracer("warehouse-service/middlewares")
+
+	return func(c *gin.Context) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+
+			stack := debug.Stack()
+			route := c.FullPath()
+			if route == "" {
+				route = "unknown"
+			}
+
+			slog.Error("Recovered from panic",
+				slog.Any("panic", rec),
+				slog.String("method", c.Request.Method),
+				slog.String("path", c.Request.URL.Path),
+				slog.String("route", route),
+				slog.String("stack", string(stack)),
+			)
+
+			// Handler spans are usually ended by the time we get here, so fall
+			// back to a dedicated span when there is nothing recording.
+			span := trace.SpanFromContext(c.Request.Context())
+			if !span.IsRecording() {
+				_, span = tracer.Start(c.Request.Context(), "PanicRecovery")
+				defer span.End()
+			}
+			span.RecordError(fmt.Errorf("panic: %v", rec),
+				trace.WithStackTrace(true),
+				trace.WithAttributes(
+					attribute.String("http.method", c.Request.Method),
+					attribute.String("http.route", route),
+				),
+			)
+			span.SetStatus(codes.Error, "panic recovered")
+
+			if prometheusMetrics != nil {
+				prometheusMetrics.RecordPanic(c.Request.Method, route)
+			}
+
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error": Translate(c, "Internal server error"),
+			})
+		}()
+		c.Next()
+	}
+}
@@ -0,0 +1,109 @@
+package middlewares
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Representation holds the body of GET and HEAD responses back to send it
+// with its ETag and Content-Length. HEAD answers with the headers GET
+// would, without the body, and a request whose If-None-Match holds the
+// ETag gets 304. Handlers that know the version of what they return set
+// the ETag themselves, others get a weak one hashed from the body.
+func Representation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		w := &representationWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		// A panicking handler leaves the body to the recovery middleware
+		defer func() { c.Writer = w.ResponseWriter }()
+		c.Next()
+		w.send(c.Request)
+	}
+}
+
+// representationWriter buffers the body until the handlers are done
+type representationWriter struct {
+	gin.ResponseWriter
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func (w *representationWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.body.Write(b)
+}
+
+func (w *representationWriter) WriteString(s string) (int, error) {
+	w.wroteHeader = true
+	return w.body.WriteString(s)
+}
+
+func (w *representationWriter) WriteHeaderNow() {
+	w.wroteHeader = true
+}
+
+func (w *representationWriter) Written() bool {
+	return w.wroteHeader
+}
+
+func (w *representationWriter) Size() int {
+	if !w.wroteHeader {
+		return -1
+	}
+	return w.body.Len()
+}
+
+// Flush is a no-op, the body is sent whole once its ETag is known
+func (w *representationWriter) Flush() {}
+
+// Unwrap lets http.ResponseController reach the connection
+func (w *representationWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *representationWriter) send(r *http.Request) {
+	status := w.Status()
+	h := w.Header()
+	if status == http.StatusOK {
+		if h.Get("ETag") == "" {
+			sum := sha256.Sum256(w.body.Bytes())
+			h.Set("ETag", fmt.Sprintf(`W/"%x"`, sum[:16]))
+		}
+		if etagMatches(r.Header.Get("If-None-Match"), h.Get("ETag")) {
+			h.Del("Content-Type")
+			h.Del("Content-Length")
+			w.ResponseWriter.WriteHeader(http.StatusNotModified)
+			w.ResponseWriter.WriteHeaderNow()
+			return
+		}
+	}
+	if status != http.StatusNoContent && status != http.StatusNotModified && h.Get("Content-Length") == "" {
+		h.Set("Content-Length", strconv.Itoa(w.body.Len()))
+	}
+	if r.Method == http.MethodHead || w.body.Len() == 0 {
+		w.ResponseWriter.WriteHeaderNow()
+		return
+	}
+	w.ResponseWriter.Write(w.body.Bytes())
+}
+
+// etagMatches tells whether the If-None-Match header list holds etag,
+// compared weakly as RFC 9110 asks of If-None-Match
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
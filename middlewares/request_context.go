@@ -0,0 +1,193 @@
+package middlewares
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/clerk/clerk-sdk-go/v2"
+	"github.com/clerk/clerk-sdk-go/v2/user"
+	"github.com/gin-gonic/gin"
+)
+
+// requestContextKey holds the RequestContext on the gin context
+const requestContextKey = "request_context"
+
+// TenantHeader names the organization an internal service account acts
+// for. It is ignored for everyone else.
+const TenantHeader = "X-Tenant-ID"
+
+// RequestContext is who a request acts as and for which tenant. Identify
+// sets it after ClerkAuth, read it with GetRequestContext.
+type RequestContext struct {
+	// Clerk user ID, or apikey: and the key name
+	UserID string
+	OrgID  string
+	// Active organization of a Clerk session, empty for API keys
+	OrgSlug     string
+	OrgRole     string
+	Permissions []string
+	// Set for requests made with an API key
+	APIKeyID int64
+	// Internal service accounts, backend services verified by ServiceAuth
+	// and the Clerk users configured as such, may act for any
+	// organization, OrgID is then taken from TenantHeader
+	ServiceAccount bool
+	// Profile of a Clerk user, empty for API keys or when Clerk could not
+	// be reached
+	Email string
+	Name  string
+}
+
+// IsAPIKey reports whether the request was made with an API key
+func (r RequestContext) IsAPIKey() bool {
+	return r.APIKeyID != 0
+}
+
+// HasRole reports whether the user holds role, e.g. org:admin, in the
+// active organization
+func (r RequestContext) HasRole(role string) bool {
+	return role != "" && r.OrgRole == role
+}
+
+// HasPermission reports whether the user holds permission in the active
+// organization
+func (r RequestContext) HasPermission(permission string) bool {
+	return slices.Contains(r.Permissions, permission)
+}
+
+// GetRequestContext returns the RequestContext set by Identify, the zero
+// value when it did not run
+func GetRequestContext(c *gin.Context) RequestContext {
+	rc, _ := c.Value(requestContextKey).(RequestContext)
+	return rc
+}
+
+// Identify builds the RequestContext from what ClerkAuth verified: the
+// session claims, the API key or the backend service. The profile of a
+// Clerk user is looked up in profiles; a failed lookup leaves it empty
+// rather than failing the request. Clerk users are service accounts when
+// listed in serviceAccounts; the organization role is no proof of it, as
+// each tenant's admins grant roles.
+func Identify(profiles *ProfileCache, serviceAccounts []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rc := RequestContext{
+			UserID: c.GetString("user_id"),
+			OrgID:  c.GetString("org_id"),
+		}
+		if id, ok := c.Value("api_key_id").(int64); ok {
+			rc.APIKeyID = id
+		}
+		// Backend services authenticated by ServiceAuth
+		rc.ServiceAccount = c.GetBool("service_account")
+		if claims, ok := c.Value("claims").(*clerk.SessionClaims); ok {
+			rc.OrgSlug = claims.ActiveOrganizationSlug
+			rc.OrgRole = claims.ActiveOrganizationRole
+			rc.Permissions = claims.ActiveOrganizationPermissions
+			rc.ServiceAccount = slices.Contains(serviceAccounts, rc.UserID)
+			profile := profiles.Get(c.Request.Context(), claims.Subject)
+			rc.Email, rc.Name = profile.Email, profile.Name
+		}
+		if tenant := c.GetHeader(TenantHeader); rc.ServiceAccount && tenant != "" {
+			rc.OrgID = tenant
+			c.Set("org_id", tenant)
+		}
+		c.Set(requestContextKey, rc)
+		c.Next()
+	}
+}
+
+// Profile is what requests need to know of a Clerk user
+type Profile struct {
+	Email string
+	Name  string
+}
+
+const (
+	profileTTL = 5 * time.Minute
+	// A failed lookup is retried after a minute rather than on every
+	// request of the user
+	profileErrorTTL   = time.Minute
+	profileTimeout    = 2 * time.Second
+	maxCachedProfiles = 10000
+)
+
+type cachedProfile struct {
+	profile Profile
+	expires time.Time
+}
+
+// ProfileCache keeps Clerk user profiles for a few minutes, so only the
+// first request of a user in a while calls Clerk
+type ProfileCache struct {
+	mu       sync.Mutex
+	profiles map[string]cachedProfile
+}
+
+// NewProfileCache returns an empty cache
+func NewProfileCache() *ProfileCache {
+	return &ProfileCache{
+		profiles: make(map[string]cachedProfile),
+	}
+}
+
+// Get returns the profile of userID, fetching it from Clerk when it is not
+// cached. A failed fetch keeps serving an expired profile.
+func (p *ProfileCache) Get(ctx context.Context, userID string) Profile {
+	now := time.Now()
+	p.mu.Lock()
+	cached, ok := p.profiles[userID]
+	p.mu.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.profile
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, profileTimeout)
+	defer cancel()
+	u, err := user.Get(ctx, userID)
+	entry := cachedProfile{expires: now.Add(profileTTL)}
+	if err != nil {
+		slog.Warn("Could not look up Clerk user profile", slog.String("user_id", userID), slog.Any("error", err))
+		entry = cachedProfile{profile: cached.profile, expires: now.Add(profileErrorTTL)}
+	} else {
+		entry.profile = profileOf(u)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.profiles) >= maxCachedProfiles {
+		for id, c := range p.profiles {
+			if now.After(c.expires) {
+				delete(p.profiles, id)
+			}
+		}
+		if len(p.profiles) >= maxCachedProfiles {
+			clear(p.profiles)
+		}
+	}
+	p.profiles[userID] = entry
+	return entry.profile
+}
+
+func profileOf(u *clerk.User) Profile {
+	var profile Profile
+	var names []string
+	for _, name := range []*string{u.FirstName, u.LastName} {
+		if name != nil && *name != "" {
+			names = append(names, *name)
+		}
+	}
+	profile.Name = strings.Join(names, " ")
+	for _, email := range u.EmailAddresses {
+		if email == nil {
+			continue
+		}
+		if profile.Email == "" || (u.PrimaryEmailAddressID != nil && email.ID == *u.PrimaryEmailAddressID) {
+			profile.Email = email.EmailAddress
+		}
+	}
+	return profile
+}
@@ -0,0 +1,65 @@
+package middlewares
+
+import (
+	"crypto/x509"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// serviceIdentityKey holds the identity ServiceAuth verified
+const serviceIdentityKey = "service_identity"
+
+// serviceActorPrefix marks the user_id of requests made by a backend
+// service, like apiKeyActorPrefix for API keys
+const serviceActorPrefix = "service:"
+
+// ServiceAuth authenticates backend services on the internal listener by
+// the client certificate the TLS handshake verified. The identity is the
+// SPIFFE ID of the certificate, or its common name without one, and must
+// be in allowed unless allowed is empty. ClerkAuth then accepts the request
+// without a token, the service acts for the organization in TenantHeader.
+func ServiceAuth(allowed []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tls := c.Request.TLS
+		if tls == nil || len(tls.VerifiedChains) == 0 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error":   Translate(c, "Unauthorized"),
+				"message": Translate(c, "A client certificate is required"),
+			})
+			slog.Error("Internal request without a verified client certificate")
+			return
+		}
+		id := serviceIdentity(tls.VerifiedChains[0][0])
+		if id == "" || (len(allowed) > 0 && !slices.Contains(allowed, id)) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error":   Translate(c, "Forbidden"),
+				"message": Translate(c, "The client certificate is not allowed"),
+			})
+			slog.Warn("Internal request from an unknown service", slog.String("identity", id))
+			return
+		}
+		c.Set(serviceIdentityKey, id)
+		c.Next()
+	}
+}
+
+func serviceIdentity(cert *x509.Certificate) string {
+	for _, uri := range cert.URIs {
+		if uri.Scheme == "spiffe" {
+			return uri.String()
+		}
+	}
+	return cert.Subject.CommonName
+}
+
+// authenticateService accepts a request ServiceAuth verified. Services are
+// internal service accounts, Identify takes their organization from
+// TenantHeader.
+func authenticateService(c *gin.Context, id string) {
+	c.Set("user_id", serviceActorPrefix+id)
+	c.Set("service_account", true)
+	c.Next()
+}
@@ -0,0 +1,17 @@
+package middlewares
+
+import (
+	"time"
+	"warehouse-service/dbroute"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AllowStaleReads lets the handler's read-only queries run on a read
+// replica that is at most maxAge behind the primary
+func AllowStaleReads(maxAge time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(dbroute.WithStaleness(c.Request.Context(), maxAge))
+		c.Next()
+	}
+}
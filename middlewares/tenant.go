@@ -0,0 +1,46 @@
+package middlewares
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireTenant rejects requests whose Clerk session has no active
+// organization. It must run after ClerkAuth, which sets "org_id".
+func RequireTenant() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("org_id") == "" {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   Translate(c, "Forbidden"),
+				"message": Translate(c, "An active organization is required"),
+			})
+			slog.Error("Request has no active organization", slog.String("user_id", c.GetString("user_id")))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireOrgRole rejects requests whose Clerk session does not hold role in
+// the active organization. It must run after Identify. Requests made with
+// an API key have no session and are always rejected.
+func RequireOrgRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !GetRequestContext(c).HasRole(role) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   Translate(c, "Forbidden"),
+				"message": Translate(c, "The %s role is required", role),
+			})
+			slog.Error("Request is missing organization role",
+				slog.String("user_id", c.GetString("user_id")),
+				slog.String("role", role),
+			)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
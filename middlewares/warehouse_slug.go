@@ -0,0 +1,84 @@
+package middlewares
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/slug"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// warehouseIDQueryParams are the query parameters naming a warehouse by ID
+var warehouseIDQueryParams = []string{"warehouse_id", "source_warehouse_id", "destination_warehouse_id"}
+
+// WarehouseSlugs lets requests name a warehouse by slug wherever they name
+// it by ID: in the path parameters params and in the warehouse ID query
+// parameters. A value that isn't a number is looked up among the slugs of
+// the tenant and replaced with the ID, so handlers only ever see IDs; an
+// unknown slug is answered with 404. It must run after RequireTenant and
+// before anything reads the query.
+func WarehouseSlugs(db *pgxpool.Pool, params ...string) gin.HandlerFunc {
+	queries := models.New(db)
+	return func(c *gin.Context) {
+		resolve := func(value string) (string, bool) {
+			if value == "" || slug.IsID(value) {
+				return value, true
+			}
+			id, err := queries.GetWarehouseIDBySlug(c.Request.Context(), models.GetWarehouseIDBySlugParams{
+				OrgID: c.GetString("org_id"),
+				Slug:  value,
+			})
+			if errors.Is(err, pgx.ErrNoRows) {
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": Translate(c, "Warehouse not found"),
+				})
+				c.Abort()
+				return "", false
+			}
+			if err != nil {
+				slog.Error("Failed to look up warehouse slug", slog.String("slug", value), slog.Any("error", err))
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": Translate(c, "Failed to get warehouse"),
+				})
+				c.Abort()
+				return "", false
+			}
+			return strconv.FormatInt(id, 10), true
+		}
+
+		for i := range c.Params {
+			if !slices.Contains(params, c.Params[i].Key) {
+				continue
+			}
+			id, ok := resolve(c.Params[i].Value)
+			if !ok {
+				return
+			}
+			c.Params[i].Value = id
+		}
+
+		query := c.Request.URL.Query()
+		changed := false
+		for _, key := range warehouseIDQueryParams {
+			value := query.Get(key)
+			id, ok := resolve(value)
+			if !ok {
+				return
+			}
+			if id != value {
+				query.Set(key, id)
+				changed = true
+			}
+		}
+		if changed {
+			c.Request.URL.RawQuery = query.Encode()
+		}
+		c.Next()
+	}
+}
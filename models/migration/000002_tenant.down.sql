@@ -0,0 +1,2 @@
+ALTER TABLE "storage_room" DROP COLUMN IF EXISTS "org_id";
+ALTER TABLE "warehouse" DROP COLUMN IF EXISTS "org_id";
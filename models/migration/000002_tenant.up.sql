@@ -0,0 +1,5 @@
+ALTER TABLE "warehouse" ADD COLUMN "org_id" varchar NOT NULL DEFAULT '';
+ALTER TABLE "storage_room" ADD COLUMN "org_id" varchar NOT NULL DEFAULT '';
+
+CREATE INDEX ON "warehouse" ("org_id");
+CREATE INDEX ON "storage_room" ("org_id");
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS receipt_line;
+DROP TABLE IF EXISTS receipt;
+DROP TABLE IF EXISTS stock_adjustment;
+DROP TABLE IF EXISTS stock_level;
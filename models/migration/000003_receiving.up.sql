@@ -0,0 +1,47 @@
+CREATE TABLE "stock_level" (
+  "id" bigserial PRIMARY KEY,
+  "org_id" varchar NOT NULL,
+  "storage_room_id" int NOT NULL,
+  "sku" varchar NOT NULL,
+  "quantity" int NOT NULL DEFAULT 0 CHECK ("quantity" >= 0),
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  UNIQUE ("org_id", "storage_room_id", "sku")
+);
+
+CREATE TABLE "stock_adjustment" (
+  "id" bigserial PRIMARY KEY,
+  "org_id" varchar NOT NULL,
+  "storage_room_id" int NOT NULL,
+  "sku" varchar NOT NULL,
+  "quantity_delta" int NOT NULL,
+  "reason" varchar NOT NULL,
+  "reference" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "receipt" (
+  "id" bigserial PRIMARY KEY,
+  "org_id" varchar NOT NULL,
+  "warehouse_id" bigint NOT NULL,
+  "reference" varchar NOT NULL DEFAULT '',
+  "status" varchar NOT NULL DEFAULT 'open',
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "receipt_line" (
+  "id" bigserial PRIMARY KEY,
+  "receipt_id" bigint NOT NULL,
+  "sku" varchar NOT NULL,
+  "expected_quantity" int NOT NULL CHECK ("expected_quantity" >= 0),
+  "received_quantity" int NOT NULL DEFAULT 0
+);
+
+ALTER TABLE "stock_level" ADD FOREIGN KEY ("storage_room_id") REFERENCES "storage_room" ("id");
+ALTER TABLE "stock_adjustment" ADD FOREIGN KEY ("storage_room_id") REFERENCES "storage_room" ("id");
+ALTER TABLE "receipt" ADD FOREIGN KEY ("warehouse_id") REFERENCES "warehouse" ("id");
+ALTER TABLE "receipt_line" ADD FOREIGN KEY ("receipt_id") REFERENCES "receipt" ("id") ON DELETE CASCADE;
+
+CREATE INDEX ON "stock_adjustment" ("org_id", "storage_room_id", "sku");
+CREATE INDEX ON "receipt" ("org_id");
+CREATE INDEX ON "receipt_line" ("receipt_id");
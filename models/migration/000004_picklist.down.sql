@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS audit_log;
+DROP TABLE IF EXISTS pick_list_line;
+DROP TABLE IF EXISTS pick_list;
+ALTER TABLE "receipt_line" DROP COLUMN IF EXISTS "expires_at";
+ALTER TABLE "stock_level" DROP CONSTRAINT IF EXISTS "stock_level_allocation_check";
+ALTER TABLE "stock_level" DROP COLUMN IF EXISTS "expires_at";
+ALTER TABLE "stock_level" DROP COLUMN IF EXISTS "received_at";
+ALTER TABLE "stock_level" DROP COLUMN IF EXISTS "allocated_quantity";
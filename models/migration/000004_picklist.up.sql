@@ -0,0 +1,48 @@
+ALTER TABLE "stock_level" ADD COLUMN "allocated_quantity" int NOT NULL DEFAULT 0;
+ALTER TABLE "stock_level" ADD COLUMN "received_at" timestamptz NOT NULL DEFAULT (now());
+ALTER TABLE "stock_level" ADD COLUMN "expires_at" timestamptz;
+-- Expiry date of the stock a receipt line put away, the earliest when it
+-- was received more than once
+ALTER TABLE "receipt_line" ADD COLUMN "expires_at" timestamptz;
+ALTER TABLE "stock_level" ADD CONSTRAINT "stock_level_allocation_check"
+  CHECK ("allocated_quantity" >= 0 AND "allocated_quantity" <= "quantity");
+
+CREATE TABLE "pick_list" (
+  "id" bigserial PRIMARY KEY,
+  "org_id" varchar NOT NULL,
+  "warehouse_id" bigint NOT NULL,
+  "reference" varchar NOT NULL DEFAULT '',
+  "strategy" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'allocated',
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "pick_list_line" (
+  "id" bigserial PRIMARY KEY,
+  "pick_list_id" bigint NOT NULL,
+  "sku" varchar NOT NULL,
+  "storage_room_id" int NOT NULL,
+  "quantity" int NOT NULL CHECK ("quantity" > 0),
+  "picked_quantity" int NOT NULL DEFAULT 0
+);
+
+CREATE TABLE "audit_log" (
+  "id" bigserial PRIMARY KEY,
+  "org_id" varchar NOT NULL,
+  "entity_type" varchar NOT NULL,
+  "entity_id" bigint NOT NULL,
+  "action" varchar NOT NULL,
+  "from_status" varchar NOT NULL DEFAULT '',
+  "to_status" varchar NOT NULL DEFAULT '',
+  "actor" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "pick_list" ADD FOREIGN KEY ("warehouse_id") REFERENCES "warehouse" ("id");
+ALTER TABLE "pick_list_line" ADD FOREIGN KEY ("pick_list_id") REFERENCES "pick_list" ("id") ON DELETE CASCADE;
+ALTER TABLE "pick_list_line" ADD FOREIGN KEY ("storage_room_id") REFERENCES "storage_room" ("id");
+
+CREATE INDEX ON "pick_list" ("org_id");
+CREATE INDEX ON "pick_list_line" ("pick_list_id");
+CREATE INDEX ON "audit_log" ("org_id", "entity_type", "entity_id");
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS count_line;
+DROP TABLE IF EXISTS count_session;
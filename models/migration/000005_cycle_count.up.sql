@@ -0,0 +1,27 @@
+CREATE TABLE "count_session" (
+  "id" bigserial PRIMARY KEY,
+  "org_id" varchar NOT NULL,
+  "warehouse_id" bigint NOT NULL,
+  "storage_room_id" int,
+  "status" varchar NOT NULL DEFAULT 'open',
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "count_line" (
+  "id" bigserial PRIMARY KEY,
+  "count_session_id" bigint NOT NULL,
+  "storage_room_id" int NOT NULL,
+  "sku" varchar NOT NULL,
+  "book_quantity" int NOT NULL,
+  "counted_quantity" int,
+  "approved" boolean NOT NULL DEFAULT false,
+  UNIQUE ("count_session_id", "storage_room_id", "sku")
+);
+
+ALTER TABLE "count_session" ADD FOREIGN KEY ("warehouse_id") REFERENCES "warehouse" ("id");
+ALTER TABLE "count_session" ADD FOREIGN KEY ("storage_room_id") REFERENCES "storage_room" ("id");
+ALTER TABLE "count_line" ADD FOREIGN KEY ("count_session_id") REFERENCES "count_session" ("id") ON DELETE CASCADE;
+ALTER TABLE "count_line" ADD FOREIGN KEY ("storage_room_id") REFERENCES "storage_room" ("id");
+
+CREATE INDEX ON "count_session" ("org_id");
@@ -0,0 +1,17 @@
+CREATE TABLE "job" (
+  "id" bigserial PRIMARY KEY,
+  "org_id" varchar NOT NULL DEFAULT '',
+  "kind" varchar NOT NULL,
+  "payload" jsonb NOT NULL DEFAULT '{}',
+  "status" varchar NOT NULL DEFAULT 'queued',
+  "attempts" int NOT NULL DEFAULT 0,
+  "max_attempts" int NOT NULL DEFAULT 5,
+  "last_error" varchar NOT NULL DEFAULT '',
+  "run_at" timestamptz NOT NULL DEFAULT (now()),
+  "locked_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "job" ("status", "run_at");
+CREATE INDEX ON "job" ("org_id");
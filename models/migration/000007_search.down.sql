@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS storage_room_number_trgm_idx;
+DROP INDEX IF EXISTS storage_room_name_trgm_idx;
+DROP INDEX IF EXISTS storage_room_search_idx;
+DROP INDEX IF EXISTS warehouse_address_trgm_idx;
+DROP INDEX IF EXISTS warehouse_name_trgm_idx;
+DROP INDEX IF EXISTS warehouse_search_idx;
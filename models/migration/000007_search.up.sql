@@ -0,0 +1,13 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX warehouse_search_idx ON "warehouse" USING gin (
+  to_tsvector('simple', name || ' ' || address || ' ' || ward || ' ' || district || ' ' || city || ' ' || country)
+);
+CREATE INDEX warehouse_name_trgm_idx ON "warehouse" USING gin (name gin_trgm_ops);
+CREATE INDEX warehouse_address_trgm_idx ON "warehouse" USING gin (address gin_trgm_ops);
+
+CREATE INDEX storage_room_search_idx ON "storage_room" USING gin (
+  to_tsvector('simple', name || ' ' || number)
+);
+CREATE INDEX storage_room_name_trgm_idx ON "storage_room" USING gin (name gin_trgm_ops);
+CREATE INDEX storage_room_number_trgm_idx ON "storage_room" USING gin (number gin_trgm_ops);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS warehouse_location_idx;
+ALTER TABLE "warehouse" DROP CONSTRAINT IF EXISTS "warehouse_coordinates_check";
+ALTER TABLE "warehouse" DROP COLUMN IF EXISTS "longitude";
+ALTER TABLE "warehouse" DROP COLUMN IF EXISTS "latitude";
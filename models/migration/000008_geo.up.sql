@@ -0,0 +1,13 @@
+CREATE EXTENSION IF NOT EXISTS cube;
+CREATE EXTENSION IF NOT EXISTS earthdistance;
+
+ALTER TABLE "warehouse" ADD COLUMN "latitude" double precision;
+ALTER TABLE "warehouse" ADD COLUMN "longitude" double precision;
+ALTER TABLE "warehouse" ADD CONSTRAINT "warehouse_coordinates_check"
+  CHECK (
+    ("latitude" IS NULL AND "longitude" IS NULL)
+    OR ("latitude" BETWEEN -90 AND 90 AND "longitude" BETWEEN -180 AND 180)
+  );
+
+CREATE INDEX warehouse_location_idx ON "warehouse" USING gist (ll_to_earth(latitude, longitude))
+  WHERE latitude IS NOT NULL;
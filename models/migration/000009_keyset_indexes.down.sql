@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS audit_log_keyset_idx;
+DROP INDEX IF EXISTS stock_adjustment_keyset_idx;
@@ -0,0 +1,2 @@
+CREATE INDEX stock_adjustment_keyset_idx ON "stock_adjustment" ("org_id", "created_at" DESC, "id" DESC);
+CREATE INDEX audit_log_keyset_idx ON "audit_log" ("org_id", "created_at" DESC, "id" DESC);
@@ -0,0 +1,2 @@
+ALTER TABLE "storage_room" DROP CONSTRAINT IF EXISTS storage_room_warehouse_number_key;
+DROP INDEX IF EXISTS warehouse_org_name_key;
@@ -0,0 +1,2 @@
+CREATE UNIQUE INDEX warehouse_org_name_key ON "warehouse" ("org_id", lower("name"));
+ALTER TABLE "storage_room" ADD CONSTRAINT storage_room_warehouse_number_key UNIQUE ("warehouse_id", "number");
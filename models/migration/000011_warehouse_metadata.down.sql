@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS warehouse_tags_idx;
+
+ALTER TABLE "warehouse" DROP COLUMN IF EXISTS "tags";
+ALTER TABLE "warehouse" DROP COLUMN IF EXISTS "contact_phone";
+ALTER TABLE "warehouse" DROP COLUMN IF EXISTS "contact_email";
+ALTER TABLE "warehouse" DROP COLUMN IF EXISTS "operating_hours";
+ALTER TABLE "warehouse" DROP COLUMN IF EXISTS "time_zone";
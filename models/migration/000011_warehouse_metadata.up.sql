@@ -0,0 +1,7 @@
+ALTER TABLE "warehouse" ADD COLUMN "time_zone" varchar NOT NULL DEFAULT 'UTC';
+ALTER TABLE "warehouse" ADD COLUMN "operating_hours" jsonb NOT NULL DEFAULT '{}';
+ALTER TABLE "warehouse" ADD COLUMN "contact_email" varchar;
+ALTER TABLE "warehouse" ADD COLUMN "contact_phone" varchar;
+ALTER TABLE "warehouse" ADD COLUMN "tags" text[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX warehouse_tags_idx ON "warehouse" USING gin ("tags");
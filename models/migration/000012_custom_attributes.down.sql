@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "attribute_schema";
+
+DROP INDEX IF EXISTS storage_room_attributes_idx;
+DROP INDEX IF EXISTS warehouse_attributes_idx;
+
+ALTER TABLE "storage_room" DROP COLUMN IF EXISTS "attributes";
+ALTER TABLE "warehouse" DROP COLUMN IF EXISTS "attributes";
@@ -0,0 +1,15 @@
+ALTER TABLE "warehouse" ADD COLUMN "attributes" jsonb NOT NULL DEFAULT '{}';
+ALTER TABLE "storage_room" ADD COLUMN "attributes" jsonb NOT NULL DEFAULT '{}';
+
+CREATE INDEX warehouse_attributes_idx ON "warehouse" USING gin ("attributes" jsonb_path_ops);
+CREATE INDEX storage_room_attributes_idx ON "storage_room" USING gin ("attributes" jsonb_path_ops);
+
+CREATE TABLE "attribute_schema" (
+  "org_id" varchar NOT NULL,
+  "entity_type" varchar NOT NULL,
+  "schema" jsonb NOT NULL,
+  "updated_by" varchar NOT NULL DEFAULT '',
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("org_id", "entity_type"),
+  CONSTRAINT attribute_schema_entity_type_check CHECK ("entity_type" IN ('warehouse', 'storage_room'))
+);
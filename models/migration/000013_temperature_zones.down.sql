@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "temperature_breach";
+DROP TABLE IF EXISTS "temperature_reading";
+
+ALTER TABLE "storage_room" DROP CONSTRAINT IF EXISTS storage_room_zone_type_check;
+ALTER TABLE "storage_room" DROP COLUMN IF EXISTS "zone_type";
@@ -0,0 +1,51 @@
+ALTER TABLE "storage_room" ADD COLUMN "zone_type" varchar NOT NULL DEFAULT 'ambient';
+ALTER TABLE "storage_room" ADD CONSTRAINT storage_room_zone_type_check CHECK ("zone_type" IN ('ambient', 'chilled', 'frozen'));
+
+CREATE TABLE "temperature_reading" (
+  "org_id" varchar NOT NULL,
+  "storage_room_id" int NOT NULL,
+  "sensor_id" varchar NOT NULL,
+  "celsius" float8 NOT NULL,
+  "recorded_at" timestamptz NOT NULL,
+  "received_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("storage_room_id", "sensor_id", "recorded_at")
+) PARTITION BY RANGE ("recorded_at");
+
+CREATE INDEX ON "temperature_reading" ("org_id", "storage_room_id", "recorded_at");
+
+-- Monthly partitions are created ahead of time by the
+-- temperature_partitions task, the default partition only catches readings
+-- outside of them
+CREATE TABLE "temperature_reading_default" PARTITION OF "temperature_reading" DEFAULT;
+
+DO $$
+DECLARE
+  -- Bounds are UTC month starts, like the ones the task creates
+  month timestamp := date_trunc('month', now() AT TIME ZONE 'UTC');
+BEGIN
+  FOR i IN 0..1 LOOP
+    EXECUTE format(
+      'CREATE TABLE IF NOT EXISTS %I PARTITION OF temperature_reading FOR VALUES FROM (%L) TO (%L)',
+      'temperature_reading_' || to_char(month, 'YYYY_MM'),
+      month AT TIME ZONE 'UTC', (month + interval '1 month') AT TIME ZONE 'UTC'
+    );
+    month := month + interval '1 month';
+  END LOOP;
+END $$;
+
+CREATE TABLE "temperature_breach" (
+  "id" bigserial PRIMARY KEY,
+  "org_id" varchar NOT NULL,
+  "storage_room_id" int NOT NULL REFERENCES "storage_room" ("id") ON DELETE CASCADE,
+  "zone_type" varchar NOT NULL,
+  "min_celsius" float8 NOT NULL,
+  "max_celsius" float8 NOT NULL,
+  "peak_celsius" float8 NOT NULL,
+  "started_at" timestamptz NOT NULL,
+  "resolved_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+-- A room has at most one open breach
+CREATE UNIQUE INDEX temperature_breach_open_key ON "temperature_breach" ("storage_room_id") WHERE "resolved_at" IS NULL;
+CREATE INDEX ON "temperature_breach" ("org_id", "started_at");
@@ -0,0 +1,14 @@
+CREATE TABLE "api_key" (
+  "id" bigserial PRIMARY KEY,
+  "org_id" varchar NOT NULL,
+  "name" varchar NOT NULL,
+  "prefix" varchar NOT NULL UNIQUE,
+  "secret_hash" bytea NOT NULL,
+  "created_by" varchar NOT NULL DEFAULT '',
+  "expires_at" timestamptz,
+  "revoked_at" timestamptz,
+  "last_used_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "api_key" ("org_id");
@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS stock_level_change_event ON "stock_level";
+DROP TRIGGER IF EXISTS storage_room_change_event ON "storage_room";
+DROP TRIGGER IF EXISTS warehouse_change_event ON "warehouse";
+DROP FUNCTION IF EXISTS record_change_event();
+DROP TABLE IF EXISTS "change_event";
@@ -0,0 +1,63 @@
+CREATE TABLE "change_event" (
+  "id" bigserial PRIMARY KEY,
+  "org_id" varchar NOT NULL,
+  "entity" varchar NOT NULL,
+  "entity_id" bigint NOT NULL,
+  "operation" varchar NOT NULL,
+  "data" jsonb NOT NULL DEFAULT '{}',
+  -- Readers follow (txid, id) up to the oldest running transaction, IDs
+  -- alone can commit out of order
+  "txid" bigint NOT NULL DEFAULT (pg_current_xact_id()::text::bigint),
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "change_event" ("txid", "id");
+CREATE INDEX ON "change_event" ("created_at");
+
+-- Records a change_event row per changed warehouse, storage room or stock
+-- level and wakes the listeners. The notification has no payload so
+-- Postgres folds all of a transaction's notifications into one; listeners
+-- read the new rows after the last event they saw. Bulk loads can skip the
+-- feed with SET LOCAL inventium.skip_change_feed = 'on'.
+CREATE FUNCTION record_change_event() RETURNS trigger AS $$
+DECLARE
+  rec record;
+  payload jsonb;
+BEGIN
+  IF current_setting('inventium.skip_change_feed', true) = 'on' THEN
+    RETURN NULL;
+  END IF;
+  IF TG_OP = 'DELETE' THEN
+    rec := OLD;
+  ELSE
+    rec := NEW;
+  END IF;
+
+  -- Each statement is planned on first execution, so fields only exist in
+  -- the branch of their table
+  IF TG_TABLE_NAME = 'warehouse' THEN
+    payload := jsonb_build_object('name', rec.name);
+  ELSIF TG_TABLE_NAME = 'storage_room' THEN
+    payload := jsonb_build_object('warehouse_id', rec.warehouse_id, 'number', rec.number);
+  ELSE
+    payload := jsonb_build_object(
+      'storage_room_id', rec.storage_room_id,
+      'sku', rec.sku,
+      'quantity', rec.quantity,
+      'allocated_quantity', rec.allocated_quantity
+    );
+  END IF;
+
+  INSERT INTO change_event (org_id, entity, entity_id, operation, data)
+  VALUES (rec.org_id, TG_TABLE_NAME, rec.id, lower(TG_OP), payload);
+  PERFORM pg_notify('change_event', '');
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER warehouse_change_event AFTER INSERT OR UPDATE OR DELETE ON "warehouse"
+  FOR EACH ROW EXECUTE FUNCTION record_change_event();
+CREATE TRIGGER storage_room_change_event AFTER INSERT OR UPDATE OR DELETE ON "storage_room"
+  FOR EACH ROW EXECUTE FUNCTION record_change_event();
+CREATE TRIGGER stock_level_change_event AFTER INSERT OR UPDATE OR DELETE ON "stock_level"
+  FOR EACH ROW EXECUTE FUNCTION record_change_event();
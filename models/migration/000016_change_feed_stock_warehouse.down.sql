@@ -0,0 +1,35 @@
+CREATE OR REPLACE FUNCTION record_change_event() RETURNS trigger AS $$
+DECLARE
+  rec record;
+  payload jsonb;
+BEGIN
+  IF current_setting('inventium.skip_change_feed', true) = 'on' THEN
+    RETURN NULL;
+  END IF;
+  IF TG_OP = 'DELETE' THEN
+    rec := OLD;
+  ELSE
+    rec := NEW;
+  END IF;
+
+  -- Each statement is planned on first execution, so fields only exist in
+  -- the branch of their table
+  IF TG_TABLE_NAME = 'warehouse' THEN
+    payload := jsonb_build_object('name', rec.name);
+  ELSIF TG_TABLE_NAME = 'storage_room' THEN
+    payload := jsonb_build_object('warehouse_id', rec.warehouse_id, 'number', rec.number);
+  ELSE
+    payload := jsonb_build_object(
+      'storage_room_id', rec.storage_room_id,
+      'sku', rec.sku,
+      'quantity', rec.quantity,
+      'allocated_quantity', rec.allocated_quantity
+    );
+  END IF;
+
+  INSERT INTO change_event (org_id, entity, entity_id, operation, data)
+  VALUES (rec.org_id, TG_TABLE_NAME, rec.id, lower(TG_OP), payload);
+  PERFORM pg_notify('change_event', '');
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,17 @@
+-- Events for the message broker, written in the transaction of the change
+-- they describe and published by the outbox relay
+CREATE TABLE "outbox" (
+  "id" bigserial PRIMARY KEY,
+  "org_id" varchar NOT NULL,
+  "topic" varchar NOT NULL,
+  "key" varchar NOT NULL,
+  "payload" jsonb NOT NULL,
+  "attempts" int NOT NULL DEFAULT 0,
+  "last_error" varchar NOT NULL DEFAULT '',
+  "next_attempt_at" timestamptz NOT NULL DEFAULT (now()),
+  "delivered_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "outbox" ("next_attempt_at", "id") WHERE "delivered_at" IS NULL;
+CREATE INDEX ON "outbox" ("delivered_at") WHERE "delivered_at" IS NOT NULL;
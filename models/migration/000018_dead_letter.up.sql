@@ -0,0 +1,20 @@
+-- Deliveries that ran out of attempts, kept with their payload until an
+-- admin replays them. source names the delivery pipeline, source_id the
+-- message there.
+CREATE TABLE "dead_letter" (
+  "id" bigserial PRIMARY KEY,
+  "org_id" varchar NOT NULL,
+  "source" varchar NOT NULL,
+  "source_id" bigint NOT NULL,
+  "topic" varchar NOT NULL,
+  "key" varchar NOT NULL,
+  "payload" jsonb NOT NULL,
+  "attempts" int NOT NULL,
+  "last_error" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL,
+  "failed_at" timestamptz NOT NULL DEFAULT (now()),
+  "replayed_at" timestamptz,
+  "replayed_by" varchar NOT NULL DEFAULT ''
+);
+
+CREATE INDEX ON "dead_letter" ("org_id", "id");
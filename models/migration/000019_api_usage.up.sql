@@ -0,0 +1,11 @@
+-- API calls per tenant and UTC day. The row with an empty user_id counts
+-- every call of the tenant, the others the calls of one user or API key.
+CREATE TABLE "api_usage" (
+  "org_id" varchar NOT NULL,
+  "day" date NOT NULL,
+  "user_id" varchar NOT NULL,
+  "calls" bigint NOT NULL DEFAULT 0,
+  PRIMARY KEY ("org_id", "day", "user_id")
+);
+
+CREATE INDEX ON "api_usage" ("day");
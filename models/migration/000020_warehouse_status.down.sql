@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS warehouse_org_id_status_idx;
+
+ALTER TABLE "warehouse" DROP CONSTRAINT IF EXISTS "warehouse_status_check";
+ALTER TABLE "warehouse" DROP COLUMN IF EXISTS "status";
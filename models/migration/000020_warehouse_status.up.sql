@@ -0,0 +1,6 @@
+-- Lifecycle of a warehouse, transitions are checked by the service
+ALTER TABLE "warehouse" ADD COLUMN "status" varchar NOT NULL DEFAULT 'active';
+ALTER TABLE "warehouse" ADD CONSTRAINT "warehouse_status_check"
+  CHECK ("status" IN ('draft', 'active', 'maintenance', 'archived'));
+
+CREATE INDEX warehouse_org_id_status_idx ON "warehouse" ("org_id", "status");
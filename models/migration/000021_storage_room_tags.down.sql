@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS storage_room_tags_idx;
+
+ALTER TABLE "storage_room" DROP COLUMN IF EXISTS "tags";
@@ -0,0 +1,3 @@
+ALTER TABLE "storage_room" ADD COLUMN "tags" text[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX storage_room_tags_idx ON "storage_room" USING gin ("tags");
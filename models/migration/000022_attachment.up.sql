@@ -0,0 +1,18 @@
+-- Documents of a warehouse. The file is kept in the object store under
+-- object_key, the row holds what the API lists.
+CREATE TABLE "attachment" (
+  "id" bigserial PRIMARY KEY,
+  "org_id" varchar NOT NULL,
+  "warehouse_id" bigint NOT NULL,
+  "kind" varchar NOT NULL,
+  "file_name" varchar NOT NULL,
+  "content_type" varchar NOT NULL,
+  "size_bytes" bigint NOT NULL,
+  "checksum" varchar NOT NULL,
+  "object_key" varchar NOT NULL UNIQUE,
+  "uploaded_by" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT attachment_kind_check CHECK ("kind" IN ('floor_plan', 'certificate', 'lease', 'other'))
+);
+
+CREATE INDEX ON "attachment" ("org_id", "warehouse_id", "id");
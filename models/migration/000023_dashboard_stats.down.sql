@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "materialized_view_refresh";
+DROP MATERIALIZED VIEW IF EXISTS "dashboard_stats";
@@ -0,0 +1,67 @@
+-- Headline numbers of each tenant for the dashboard. The service refreshes
+-- the view on a schedule instead of aggregating on every request; a
+-- migration changing a column it reads has to drop and recreate it.
+CREATE MATERIALIZED VIEW "dashboard_stats" AS
+WITH warehouses AS (
+  SELECT org_id,
+         count(*) AS warehouses,
+         count(*) FILTER (WHERE status = 'active') AS active_warehouses
+  FROM warehouse
+  GROUP BY org_id
+), rooms AS (
+  SELECT org_id, count(*) AS storage_rooms
+  FROM storage_room
+  GROUP BY org_id
+), stock AS (
+  SELECT org_id,
+         count(DISTINCT sku) AS skus,
+         sum(quantity) AS on_hand_quantity,
+         sum(allocated_quantity) AS allocated_quantity
+  FROM stock_level
+  WHERE quantity > 0
+  GROUP BY org_id
+), receipts AS (
+  SELECT org_id, count(*) AS open_receipts
+  FROM receipt
+  WHERE status IN ('open', 'partially_received')
+  GROUP BY org_id
+), pick_lists AS (
+  SELECT org_id, count(*) AS open_pick_lists
+  FROM pick_list
+  WHERE status IN ('allocated', 'picked')
+  GROUP BY org_id
+), movements AS (
+  SELECT org_id, count(*) AS movements_last_30_days
+  FROM stock_adjustment
+  WHERE created_at >= now() - interval '30 days'
+  GROUP BY org_id
+)
+SELECT warehouses.org_id,
+       warehouses.warehouses::bigint,
+       warehouses.active_warehouses::bigint,
+       COALESCE(rooms.storage_rooms, 0)::bigint AS storage_rooms,
+       COALESCE(stock.skus, 0)::bigint AS skus,
+       COALESCE(stock.on_hand_quantity, 0)::bigint AS on_hand_quantity,
+       COALESCE(stock.allocated_quantity, 0)::bigint AS allocated_quantity,
+       COALESCE(receipts.open_receipts, 0)::bigint AS open_receipts,
+       COALESCE(pick_lists.open_pick_lists, 0)::bigint AS open_pick_lists,
+       COALESCE(movements.movements_last_30_days, 0)::bigint AS movements_last_30_days
+FROM warehouses
+LEFT JOIN rooms USING (org_id)
+LEFT JOIN stock USING (org_id)
+LEFT JOIN receipts USING (org_id)
+LEFT JOIN pick_lists USING (org_id)
+LEFT JOIN movements USING (org_id);
+
+-- REFRESH ... CONCURRENTLY needs a unique index
+CREATE UNIQUE INDEX dashboard_stats_org_id_idx ON "dashboard_stats" ("org_id");
+
+-- When each materialized view was last refreshed, the age reported with
+-- its data
+CREATE TABLE "materialized_view_refresh" (
+  "name" varchar PRIMARY KEY,
+  "refreshed_at" timestamptz NOT NULL,
+  "duration_ms" bigint NOT NULL DEFAULT 0
+);
+
+INSERT INTO "materialized_view_refresh" ("name", "refreshed_at") VALUES ('dashboard_stats', now());
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS storage_room_row_history ON "storage_room";
+DROP TRIGGER IF EXISTS warehouse_row_history ON "warehouse";
+DROP FUNCTION IF EXISTS record_row_history();
+DROP TABLE IF EXISTS "row_history";
@@ -0,0 +1,47 @@
+-- Versions of warehouses and storage rooms. A version holds the row as it
+-- was from valid_from until valid_to, the current one has no valid_to. A
+-- delete closes it and adds a version with an empty range, so the deleted
+-- row stays in the history but no point in time finds it.
+CREATE TABLE "row_history" (
+  "id" bigserial PRIMARY KEY,
+  "org_id" varchar NOT NULL,
+  "entity" varchar NOT NULL,
+  "entity_id" bigint NOT NULL,
+  "operation" varchar NOT NULL,
+  "data" jsonb NOT NULL,
+  "valid_from" timestamptz NOT NULL,
+  "valid_to" timestamptz
+);
+
+CREATE INDEX ON "row_history" ("entity", "entity_id", "valid_from");
+
+-- Versions are stamped with now(), the start of the transaction, so all
+-- but the last version a transaction writes for a row are empty
+CREATE FUNCTION record_row_history() RETURNS trigger AS $$
+BEGIN
+  IF TG_OP <> 'INSERT' THEN
+    UPDATE row_history SET valid_to = now()
+    WHERE entity = TG_TABLE_NAME AND entity_id = OLD.id AND valid_to IS NULL;
+  END IF;
+
+  IF TG_OP = 'DELETE' THEN
+    INSERT INTO row_history (org_id, entity, entity_id, operation, data, valid_from, valid_to)
+    VALUES (OLD.org_id, TG_TABLE_NAME, OLD.id, 'delete', to_jsonb(OLD), now(), now());
+  ELSE
+    INSERT INTO row_history (org_id, entity, entity_id, operation, data, valid_from)
+    VALUES (NEW.org_id, TG_TABLE_NAME, NEW.id, lower(TG_OP), to_jsonb(NEW), now());
+  END IF;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER warehouse_row_history AFTER INSERT OR UPDATE OR DELETE ON "warehouse"
+  FOR EACH ROW EXECUTE FUNCTION record_row_history();
+CREATE TRIGGER storage_room_row_history AFTER INSERT OR UPDATE OR DELETE ON "storage_room"
+  FOR EACH ROW EXECUTE FUNCTION record_row_history();
+
+-- History starts now, existing rows get a snapshot version
+INSERT INTO row_history (org_id, entity, entity_id, operation, data, valid_from)
+SELECT org_id, 'warehouse', id, 'snapshot', to_jsonb(w), now() FROM warehouse w;
+INSERT INTO row_history (org_id, entity, entity_id, operation, data, valid_from)
+SELECT org_id, 'storage_room', id, 'snapshot', to_jsonb(r), now() FROM storage_room r;
@@ -0,0 +1,5 @@
+UPDATE row_history SET data = data - 'capacity'
+WHERE entity = 'storage_room';
+
+ALTER TABLE "storage_room" DROP CONSTRAINT IF EXISTS "storage_room_capacity_check";
+ALTER TABLE "storage_room" DROP COLUMN IF EXISTS "capacity";
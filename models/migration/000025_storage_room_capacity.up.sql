@@ -0,0 +1,10 @@
+-- Units of stock a storage room holds, zero is unlimited. Occupancy is the
+-- sum of the room's stock levels.
+ALTER TABLE "storage_room" ADD COLUMN "capacity" integer NOT NULL DEFAULT 0;
+ALTER TABLE "storage_room" ADD CONSTRAINT "storage_room_capacity_check"
+  CHECK ("capacity" >= 0);
+
+-- Earlier versions had no capacity, give them the default so they still
+-- populate a storage_room record
+UPDATE row_history SET data = data || '{"capacity": 0}'
+WHERE entity = 'storage_room' AND NOT data ? 'capacity';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "item_unit";
+DROP TABLE IF EXISTS "item";
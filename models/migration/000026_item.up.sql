@@ -0,0 +1,25 @@
+-- Item master data. Stock is kept in the item's base unit, item_unit holds
+-- the other units quantities may be given in and how many base units one
+-- of them is.
+CREATE TABLE "item" (
+  "id" bigserial PRIMARY KEY,
+  "org_id" varchar NOT NULL,
+  "sku" varchar NOT NULL,
+  "description" varchar NOT NULL DEFAULT '',
+  "base_unit" varchar NOT NULL DEFAULT 'each',
+  "length_cm" double precision,
+  "width_cm" double precision,
+  "height_cm" double precision,
+  "weight_kg" double precision,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT item_org_sku_key UNIQUE ("org_id", "sku")
+);
+
+CREATE TABLE "item_unit" (
+  "item_id" bigint NOT NULL REFERENCES "item" ("id") ON DELETE CASCADE,
+  "unit" varchar NOT NULL,
+  "factor" integer NOT NULL,
+  PRIMARY KEY ("item_id", "unit"),
+  CONSTRAINT item_unit_factor_check CHECK ("factor" > 1)
+);
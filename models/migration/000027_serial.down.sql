@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "serial_movement";
+DROP TABLE IF EXISTS "serial";
@@ -0,0 +1,28 @@
+-- Serialized inventory. A serial is one unit of a SKU, tracked from the
+-- storage room it is in until it ships; the stock levels still hold the
+-- quantities.
+CREATE TABLE "serial" (
+  "id" bigserial PRIMARY KEY,
+  "org_id" varchar NOT NULL,
+  "serial_number" varchar NOT NULL,
+  "sku" varchar NOT NULL,
+  "storage_room_id" int,
+  "status" varchar NOT NULL DEFAULT 'in_stock',
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT serial_org_number_key UNIQUE ("org_id", "serial_number"),
+  CONSTRAINT serial_status_check CHECK ("status" IN ('in_stock', 'shipped'))
+);
+
+CREATE TABLE "serial_movement" (
+  "id" bigserial PRIMARY KEY,
+  "serial_id" bigint NOT NULL REFERENCES "serial" ("id") ON DELETE CASCADE,
+  "action" varchar NOT NULL,
+  "from_storage_room_id" int,
+  "to_storage_room_id" int,
+  "reference" varchar NOT NULL DEFAULT '',
+  "actor" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "serial_movement" ("serial_id", "id");
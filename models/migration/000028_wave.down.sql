@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS "wave_pick_list";
+DROP TABLE IF EXISTS "wave";
+
+UPDATE row_history SET data = data - 'aisle' - 'bay'
+WHERE entity = 'storage_room';
+
+ALTER TABLE "storage_room" DROP CONSTRAINT IF EXISTS "storage_room_location_check";
+ALTER TABLE "storage_room" DROP COLUMN IF EXISTS "bay";
+ALTER TABLE "storage_room" DROP COLUMN IF EXISTS "aisle";
@@ -0,0 +1,26 @@
+-- Location of a storage room on the pick path: the aisle it is in and how
+-- far down the aisle, counted from the front cross aisle. Zero is unknown.
+ALTER TABLE "storage_room" ADD COLUMN "aisle" integer NOT NULL DEFAULT 0;
+ALTER TABLE "storage_room" ADD COLUMN "bay" integer NOT NULL DEFAULT 0;
+ALTER TABLE "storage_room" ADD CONSTRAINT "storage_room_location_check"
+  CHECK ("aisle" >= 0 AND "bay" >= 0);
+
+UPDATE row_history SET data = data || '{"aisle": 0, "bay": 0}'
+WHERE entity = 'storage_room' AND NOT data ? 'aisle';
+
+-- A wave groups pick lists of a warehouse that are picked in one walk
+CREATE TABLE "wave" (
+  "id" bigserial PRIMARY KEY,
+  "org_id" varchar NOT NULL,
+  "warehouse_id" bigint NOT NULL,
+  "reference" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+-- A pick list is in at most one wave
+CREATE TABLE "wave_pick_list" (
+  "pick_list_id" bigint PRIMARY KEY REFERENCES "pick_list" ("id") ON DELETE CASCADE,
+  "wave_id" bigint NOT NULL REFERENCES "wave" ("id") ON DELETE CASCADE
+);
+
+CREATE INDEX ON "wave_pick_list" ("wave_id");
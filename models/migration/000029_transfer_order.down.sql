@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "transfer_order_line";
+DROP TABLE IF EXISTS "transfer_order";
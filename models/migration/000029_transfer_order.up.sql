@@ -0,0 +1,35 @@
+-- A transfer order moves stock from one warehouse of the organization to
+-- another. Shipping takes the stock out of the source, it is in transit
+-- until the destination receives it.
+CREATE TABLE "transfer_order" (
+  "id" bigserial PRIMARY KEY,
+  "org_id" varchar NOT NULL,
+  "source_warehouse_id" bigint NOT NULL REFERENCES "warehouse" ("id"),
+  "destination_warehouse_id" bigint NOT NULL REFERENCES "warehouse" ("id"),
+  "reference" varchar NOT NULL DEFAULT '',
+  "status" varchar NOT NULL DEFAULT 'open',
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  "shipped_at" timestamptz,
+  "received_at" timestamptz,
+  CONSTRAINT "transfer_order_status_check"
+    CHECK ("status" IN ('open', 'in_transit', 'received', 'cancelled')),
+  CONSTRAINT "transfer_order_warehouse_check"
+    CHECK ("source_warehouse_id" <> "destination_warehouse_id")
+);
+
+-- Shipped minus received units of a line are in transit
+CREATE TABLE "transfer_order_line" (
+  "id" bigserial PRIMARY KEY,
+  "transfer_order_id" bigint NOT NULL REFERENCES "transfer_order" ("id") ON DELETE CASCADE,
+  "sku" varchar NOT NULL,
+  "quantity" int NOT NULL CHECK ("quantity" > 0),
+  "source_storage_room_id" int REFERENCES "storage_room" ("id"),
+  "shipped_quantity" int NOT NULL DEFAULT 0,
+  "received_quantity" int NOT NULL DEFAULT 0,
+  CONSTRAINT "transfer_order_line_received_check"
+    CHECK ("received_quantity" >= 0 AND "received_quantity" <= "shipped_quantity")
+);
+
+CREATE INDEX ON "transfer_order" ("org_id");
+CREATE INDEX ON "transfer_order_line" ("transfer_order_id");
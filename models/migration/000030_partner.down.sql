@@ -0,0 +1,3 @@
+ALTER TABLE "pick_list" DROP COLUMN IF EXISTS "carrier_id";
+ALTER TABLE "receipt" DROP COLUMN IF EXISTS "supplier_id";
+DROP TABLE IF EXISTS "partner";
@@ -0,0 +1,20 @@
+-- Suppliers and carriers an organization works with. Lead time is how many
+-- days a supplier takes to deliver an order, or a carrier a shipment.
+CREATE TABLE "partner" (
+  "id" bigserial PRIMARY KEY,
+  "org_id" varchar NOT NULL,
+  "kind" varchar NOT NULL,
+  "name" varchar NOT NULL,
+  "contact_name" varchar NOT NULL DEFAULT '',
+  "email" varchar NOT NULL DEFAULT '',
+  "phone" varchar NOT NULL DEFAULT '',
+  "lead_time_days" integer NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT partner_org_kind_name_key UNIQUE ("org_id", "kind", "name"),
+  CONSTRAINT partner_kind_check CHECK ("kind" IN ('supplier', 'carrier')),
+  CONSTRAINT partner_lead_time_check CHECK ("lead_time_days" >= 0)
+);
+
+ALTER TABLE "receipt" ADD COLUMN "supplier_id" bigint REFERENCES "partner" ("id") ON DELETE SET NULL;
+ALTER TABLE "pick_list" ADD COLUMN "carrier_id" bigint REFERENCES "partner" ("id") ON DELETE SET NULL;
@@ -0,0 +1,2 @@
+DELETE FROM "materialized_view_refresh" WHERE "name" = 'sku_consumption';
+DROP MATERIALIZED VIEW IF EXISTS "sku_consumption";
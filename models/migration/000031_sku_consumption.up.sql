@@ -0,0 +1,20 @@
+-- Quantity of each SKU shipped from each warehouse over the last 90 days,
+-- the consumption reorder suggestions are based on. Only shipments count:
+-- transfers move stock between warehouses without consuming it. The
+-- service refreshes the view nightly.
+CREATE MATERIALIZED VIEW "sku_consumption" AS
+SELECT stock_adjustment.org_id,
+       storage_room.warehouse_id::bigint AS warehouse_id,
+       stock_adjustment.sku,
+       (-sum(stock_adjustment.quantity_delta))::bigint AS shipped_quantity
+FROM stock_adjustment
+JOIN storage_room ON storage_room.id = stock_adjustment.storage_room_id
+WHERE stock_adjustment.reason = 'shipment'
+  AND stock_adjustment.quantity_delta < 0
+  AND stock_adjustment.created_at >= now() - interval '90 days'
+GROUP BY stock_adjustment.org_id, storage_room.warehouse_id, stock_adjustment.sku;
+
+-- REFRESH ... CONCURRENTLY needs a unique index
+CREATE UNIQUE INDEX sku_consumption_org_id_sku_warehouse_id_idx ON "sku_consumption" ("org_id", "sku", "warehouse_id");
+
+INSERT INTO "materialized_view_refresh" ("name", "refreshed_at") VALUES ('sku_consumption', now());
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "tenant_setting";
+ALTER TABLE "receipt_line" DROP COLUMN IF EXISTS "unit_cost_cents";
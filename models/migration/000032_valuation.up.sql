@@ -0,0 +1,13 @@
+-- Cost of one base unit of a receipt line in the smallest unit of the
+-- tenant's currency, NULL when unknown. Received quantities of costed lines
+-- are the cost layers of the inventory valuation.
+ALTER TABLE "receipt_line" ADD COLUMN "unit_cost_cents" bigint CHECK ("unit_cost_cents" >= 0);
+
+-- Settings of a tenant; a tenant without a row has the defaults
+CREATE TABLE "tenant_setting" (
+  "org_id" varchar PRIMARY KEY,
+  "valuation_method" varchar NOT NULL DEFAULT 'fifo',
+  "updated_by" varchar NOT NULL DEFAULT '',
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT tenant_setting_valuation_method_check CHECK ("valuation_method" IN ('fifo', 'weighted_average'))
+);
@@ -0,0 +1,14 @@
+-- Network label printers of an organization. Address is host:port of the
+-- printer's raw socket, usually port 9100; dpi is its print resolution,
+-- which ZPL labels are laid out for.
+CREATE TABLE "printer" (
+  "id" bigserial PRIMARY KEY,
+  "org_id" varchar NOT NULL,
+  "name" varchar NOT NULL,
+  "address" varchar NOT NULL,
+  "dpi" integer NOT NULL DEFAULT 203,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT printer_org_name_key UNIQUE ("org_id", "name"),
+  CONSTRAINT printer_dpi_check CHECK ("dpi" IN (203, 300, 600))
+);
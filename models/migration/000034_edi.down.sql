@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS "edi_document";
+ALTER TABLE "tenant_setting" DROP COLUMN IF EXISTS "edi_id", DROP COLUMN IF EXISTS "edi_qualifier";
+DROP INDEX IF EXISTS "partner_org_edi_key";
+ALTER TABLE "partner"
+  DROP COLUMN IF EXISTS "sftp_host_key",
+  DROP COLUMN IF EXISTS "sftp_url",
+  DROP COLUMN IF EXISTS "edi_id",
+  DROP COLUMN IF EXISTS "edi_qualifier";
@@ -0,0 +1,43 @@
+-- EDI identities and SFTP drops of partners. edi_id with its qualifier is
+-- the partner's ISA sender/receiver ID; sftp_url is an
+-- sftp://user@host/directory drop that inventory advice is uploaded to,
+-- sftp_host_key the host key its server must present.
+ALTER TABLE "partner"
+  ADD COLUMN "edi_qualifier" varchar NOT NULL DEFAULT 'ZZ',
+  ADD COLUMN "edi_id" varchar NOT NULL DEFAULT '',
+  ADD COLUMN "sftp_url" varchar NOT NULL DEFAULT '',
+  ADD COLUMN "sftp_host_key" varchar NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX "partner_org_edi_key" ON "partner" ("org_id", "edi_qualifier", "edi_id")
+  WHERE "edi_id" <> '';
+
+-- The tenant's own ISA ID, the sender of the documents it exports
+ALTER TABLE "tenant_setting"
+  ADD COLUMN "edi_qualifier" varchar NOT NULL DEFAULT 'ZZ',
+  ADD COLUMN "edi_id" varchar NOT NULL DEFAULT '';
+
+-- EDI documents imported and exported. Each transaction set of an
+-- imported interchange is one row, identified by the sender and control
+-- numbers so a resent interchange isn't imported twice. The ID of an
+-- exported document is its interchange control number.
+CREATE TABLE "edi_document" (
+  "id" bigserial PRIMARY KEY,
+  "org_id" varchar NOT NULL,
+  "partner_id" bigint REFERENCES "partner" ("id") ON DELETE SET NULL,
+  "direction" varchar NOT NULL,
+  "document_type" varchar NOT NULL,
+  "sender_id" varchar NOT NULL DEFAULT '',
+  "control_number" varchar NOT NULL DEFAULT '',
+  "transaction_control" varchar NOT NULL DEFAULT '',
+  "receipt_id" bigint REFERENCES "receipt" ("id") ON DELETE SET NULL,
+  "status" varchar NOT NULL,
+  "error" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT edi_document_direction_check CHECK ("direction" IN ('inbound', 'outbound')),
+  CONSTRAINT edi_document_status_check CHECK ("status" IN ('imported', 'pending', 'sent', 'failed'))
+);
+
+CREATE UNIQUE INDEX "edi_document_inbound_key" ON "edi_document" ("org_id", "sender_id", "control_number", "transaction_control")
+  WHERE "direction" = 'inbound';
+CREATE INDEX ON "edi_document" ("org_id", "id");
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS "file_exchange_content";
+DROP TABLE IF EXISTS "file_exchange_file";
+DROP TABLE IF EXISTS "file_exchange_run";
+DROP TABLE IF EXISTS "file_exchange";
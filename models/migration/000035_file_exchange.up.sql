@@ -0,0 +1,71 @@
+-- Folders files are exchanged with a partner through. Files dropped in
+-- inbound_url are imported as receipts of warehouse_id from supplier_id;
+-- extract_format is the extract written to outbound_url, a CSV of the
+-- stock or an 846 for the supplier. host_key is the key the SFTP server of
+-- the folders must present.
+CREATE TABLE "file_exchange" (
+  "id" bigserial PRIMARY KEY,
+  "org_id" varchar NOT NULL,
+  "name" varchar NOT NULL,
+  "inbound_url" varchar NOT NULL DEFAULT '',
+  "outbound_url" varchar NOT NULL DEFAULT '',
+  "host_key" varchar NOT NULL DEFAULT '',
+  "warehouse_id" bigint NOT NULL REFERENCES "warehouse" ("id"),
+  "supplier_id" bigint REFERENCES "partner" ("id") ON DELETE SET NULL,
+  "extract_format" varchar NOT NULL DEFAULT '',
+  "enabled" boolean NOT NULL DEFAULT true,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT file_exchange_org_name_key UNIQUE ("org_id", "name"),
+  CONSTRAINT file_exchange_extract_format_check CHECK ("extract_format" IN ('', 'csv', '846'))
+);
+
+-- Each time the folders of an exchange were worked on
+CREATE TABLE "file_exchange_run" (
+  "id" bigserial PRIMARY KEY,
+  "org_id" varchar NOT NULL,
+  "exchange_id" bigint NOT NULL REFERENCES "file_exchange" ("id") ON DELETE CASCADE,
+  "trigger" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'running',
+  "files_processed" integer NOT NULL DEFAULT 0,
+  "files_failed" integer NOT NULL DEFAULT 0,
+  "error" varchar NOT NULL DEFAULT '',
+  "started_at" timestamptz NOT NULL DEFAULT (now()),
+  "finished_at" timestamptz,
+  CONSTRAINT file_exchange_run_trigger_check CHECK ("trigger" IN ('schedule', 'manual')),
+  CONSTRAINT file_exchange_run_status_check CHECK ("status" IN ('running', 'succeeded', 'failed'))
+);
+
+CREATE INDEX ON "file_exchange_run" ("exchange_id", "id");
+
+-- Files picked up or written by a run. An inbound file is identified by
+-- the SHA-256 of its content so a file dropped twice isn't imported twice.
+CREATE TABLE "file_exchange_file" (
+  "id" bigserial PRIMARY KEY,
+  "org_id" varchar NOT NULL,
+  "exchange_id" bigint NOT NULL REFERENCES "file_exchange" ("id") ON DELETE CASCADE,
+  "run_id" bigint NOT NULL REFERENCES "file_exchange_run" ("id") ON DELETE CASCADE,
+  "direction" varchar NOT NULL,
+  "name" varchar NOT NULL,
+  "format" varchar NOT NULL,
+  "sha256" varchar NOT NULL,
+  "size" integer NOT NULL,
+  "status" varchar NOT NULL,
+  "error" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT file_exchange_file_direction_check CHECK ("direction" IN ('inbound', 'outbound')),
+  CONSTRAINT file_exchange_file_format_check CHECK ("format" IN ('csv', 'edi')),
+  CONSTRAINT file_exchange_file_status_check CHECK ("status" IN ('received', 'processed', 'failed', 'sent'))
+);
+
+CREATE UNIQUE INDEX "file_exchange_file_inbound_key" ON "file_exchange_file" ("exchange_id", "sha256")
+  WHERE "direction" = 'inbound';
+CREATE INDEX ON "file_exchange_file" ("exchange_id", "id");
+
+-- Content of inbound files, kept to reprocess a file after it was removed
+-- from the folder
+CREATE TABLE "file_exchange_content" (
+  "file_id" bigint PRIMARY KEY REFERENCES "file_exchange_file" ("id") ON DELETE CASCADE,
+  "content" bytea NOT NULL
+);
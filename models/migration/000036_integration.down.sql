@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "integration_order";
+DROP TABLE IF EXISTS "integration_stock";
+DROP TABLE IF EXISTS "integration";
@@ -0,0 +1,57 @@
+-- Connections to a sales channel or ERP. The available to promise stock
+-- of warehouse_id is pushed to it and its orders are reserved there. url
+-- is the shop of a Shopify integration, the endpoint levels are posted to
+-- for a webhook; token authenticates with it and is never returned.
+CREATE TABLE "integration" (
+  "id" bigserial PRIMARY KEY,
+  "org_id" varchar NOT NULL,
+  "name" varchar NOT NULL,
+  "kind" varchar NOT NULL,
+  "url" varchar NOT NULL,
+  "orders_url" varchar NOT NULL DEFAULT '',
+  "token" varchar NOT NULL DEFAULT '',
+  "location_id" varchar NOT NULL DEFAULT '',
+  "warehouse_id" bigint NOT NULL REFERENCES "warehouse" ("id"),
+  "enabled" boolean NOT NULL DEFAULT true,
+  -- Orders updated up to this time were pulled
+  "orders_synced_at" timestamptz NOT NULL DEFAULT (now()),
+  "last_synced_at" timestamptz,
+  "failures" integer NOT NULL DEFAULT 0,
+  "last_error" varchar NOT NULL DEFAULT '',
+  -- The scheduler leaves the integration alone until then after failures
+  "next_sync_at" timestamptz NOT NULL DEFAULT (now()),
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT integration_org_name_key UNIQUE ("org_id", "name"),
+  CONSTRAINT integration_kind_check CHECK ("kind" IN ('shopify', 'webhook'))
+);
+
+-- The quantity last pushed for each SKU, so a sync only pushes changes
+CREATE TABLE "integration_stock" (
+  "integration_id" bigint NOT NULL REFERENCES "integration" ("id") ON DELETE CASCADE,
+  "sku" varchar NOT NULL,
+  "available" bigint NOT NULL,
+  "pushed_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("integration_id", "sku")
+);
+
+-- Orders pulled from an integration and the pick list reserving their
+-- stock. lines is the order as last pulled, [{"sku", "quantity"}].
+CREATE TABLE "integration_order" (
+  "id" bigserial PRIMARY KEY,
+  "org_id" varchar NOT NULL,
+  "integration_id" bigint NOT NULL REFERENCES "integration" ("id") ON DELETE CASCADE,
+  "external_id" varchar NOT NULL,
+  "reference" varchar NOT NULL DEFAULT '',
+  "lines" jsonb NOT NULL,
+  "status" varchar NOT NULL,
+  "pick_list_id" bigint REFERENCES "pick_list" ("id") ON DELETE SET NULL,
+  "error" varchar NOT NULL DEFAULT '',
+  "external_updated_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT integration_order_external_key UNIQUE ("integration_id", "external_id"),
+  CONSTRAINT integration_order_status_check CHECK ("status" IN ('reserved', 'short', 'cancelled', 'conflict'))
+);
+
+CREATE INDEX ON "integration_order" ("integration_id", "status");
@@ -0,0 +1,33 @@
+-- Sagas coordinate a flow spanning the warehouse and the sibling services.
+-- A fulfillment saga follows a pick list from allocation to the shipment
+-- confirmed to order-service. When a step fails for good, the steps done
+-- are compensated in reverse order.
+CREATE TABLE "saga" (
+  "id" bigserial PRIMARY KEY,
+  "org_id" varchar NOT NULL,
+  "kind" varchar NOT NULL,
+  "pick_list_id" bigint NOT NULL REFERENCES "pick_list" ("id") ON DELETE CASCADE,
+  "status" varchar NOT NULL DEFAULT 'running',
+  "error" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT saga_pick_list_key UNIQUE ("pick_list_id", "kind"),
+  CONSTRAINT saga_kind_check CHECK ("kind" IN ('fulfillment')),
+  CONSTRAINT saga_status_check CHECK ("status" IN ('running', 'completed', 'compensating', 'compensated', 'failed'))
+);
+
+CREATE INDEX ON "saga" ("org_id", "status");
+
+-- Steps of a saga in the order they run. error is why the step, or its
+-- compensation, last failed.
+CREATE TABLE "saga_step" (
+  "saga_id" bigint NOT NULL REFERENCES "saga" ("id") ON DELETE CASCADE,
+  "position" integer NOT NULL,
+  "name" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "attempts" integer NOT NULL DEFAULT 0,
+  "error" varchar NOT NULL DEFAULT '',
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("saga_id", "position"),
+  CONSTRAINT saga_step_status_check CHECK ("status" IN ('pending', 'succeeded', 'failed', 'skipped', 'compensated'))
+);
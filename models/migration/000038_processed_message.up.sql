@@ -0,0 +1,16 @@
+-- Messages consumed from the broker, keyed by topic and the producer's
+-- message ID. A message is recorded in the transaction handling it, so one
+-- delivered again is acknowledged without being handled twice. Rejected
+-- messages could never be handled, error says why.
+CREATE TABLE "processed_message" (
+  "topic" varchar NOT NULL,
+  "message_id" varchar NOT NULL,
+  "org_id" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'processed',
+  "error" varchar NOT NULL DEFAULT '',
+  "processed_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("topic", "message_id"),
+  CONSTRAINT processed_message_status_check CHECK ("status" IN ('processed', 'rejected'))
+);
+
+CREATE INDEX ON "processed_message" ("processed_at");
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "tenant_deletion";
+DROP TABLE IF EXISTS "tenant_export";
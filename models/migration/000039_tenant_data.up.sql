@@ -0,0 +1,44 @@
+-- Archives of all of a tenant's data, written to object storage as a zip
+-- of one file per table. tables holds the rows exported per table.
+CREATE TABLE "tenant_export" (
+  "id" bigserial PRIMARY KEY,
+  "org_id" varchar NOT NULL,
+  "format" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "object_key" varchar NOT NULL DEFAULT '',
+  "size_bytes" bigint NOT NULL DEFAULT 0,
+  "checksum" varchar NOT NULL DEFAULT '',
+  "tables" jsonb NOT NULL DEFAULT '{}',
+  "error" varchar NOT NULL DEFAULT '',
+  "requested_by" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "completed_at" timestamptz,
+  CONSTRAINT tenant_export_format_check CHECK ("format" IN ('json', 'csv')),
+  CONSTRAINT tenant_export_status_check CHECK ("status" IN ('pending', 'completed', 'failed'))
+);
+
+CREATE INDEX ON "tenant_export" ("org_id", "id");
+
+-- Requests to delete all of a tenant's data, run once run_after passed
+-- unless cancelled. A purge deletes the rows, an anonymization moves what
+-- is kept to the pseudonym as org_id and blanks personal data. report is
+-- the verification of what is left. These rows outlive the tenant's data,
+-- as the record of its deletion.
+CREATE TABLE "tenant_deletion" (
+  "id" bigserial PRIMARY KEY,
+  "org_id" varchar NOT NULL,
+  "mode" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'scheduled',
+  "run_after" timestamptz NOT NULL,
+  "pseudonym" varchar NOT NULL DEFAULT '',
+  "report" jsonb NOT NULL DEFAULT '{}',
+  "error" varchar NOT NULL DEFAULT '',
+  "requested_by" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "completed_at" timestamptz,
+  CONSTRAINT tenant_deletion_mode_check CHECK ("mode" IN ('purge', 'anonymize')),
+  CONSTRAINT tenant_deletion_status_check CHECK ("status" IN ('scheduled', 'running', 'completed', 'failed', 'cancelled'))
+);
+
+-- One pending deletion per tenant
+CREATE UNIQUE INDEX tenant_deletion_pending_key ON "tenant_deletion" ("org_id") WHERE "status" IN ('scheduled', 'running');
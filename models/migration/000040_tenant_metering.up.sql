@@ -0,0 +1,16 @@
+-- Usage of each tenant per UTC day for billing: the API calls and delivered
+-- events of the day and the entities stored when the day was aggregated.
+-- Aggregation rewrites the rows of the current and previous day only.
+CREATE TABLE "tenant_metering" (
+  "org_id" varchar NOT NULL,
+  "day" date NOT NULL,
+  "api_calls" bigint NOT NULL DEFAULT 0,
+  "warehouses" bigint NOT NULL DEFAULT 0,
+  "storage_rooms" bigint NOT NULL DEFAULT 0,
+  "items" bigint NOT NULL DEFAULT 0,
+  "events_delivered" bigint NOT NULL DEFAULT 0,
+  "aggregated_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("org_id", "day")
+);
+
+CREATE INDEX ON "tenant_metering" ("day");
@@ -0,0 +1,6 @@
+-- Rows of deleted rooms may remain, the keys only hold for new rows
+ALTER TABLE "stock_adjustment" ADD CONSTRAINT "stock_adjustment_storage_room_id_fkey" FOREIGN KEY ("storage_room_id") REFERENCES "storage_room" ("id") NOT VALID;
+ALTER TABLE "pick_list_line" ADD CONSTRAINT "pick_list_line_storage_room_id_fkey" FOREIGN KEY ("storage_room_id") REFERENCES "storage_room" ("id") NOT VALID;
+ALTER TABLE "count_session" ADD CONSTRAINT "count_session_storage_room_id_fkey" FOREIGN KEY ("storage_room_id") REFERENCES "storage_room" ("id") NOT VALID;
+ALTER TABLE "count_line" ADD CONSTRAINT "count_line_storage_room_id_fkey" FOREIGN KEY ("storage_room_id") REFERENCES "storage_room" ("id") NOT VALID;
+ALTER TABLE "transfer_order_line" ADD CONSTRAINT "transfer_order_line_source_storage_room_id_fkey" FOREIGN KEY ("source_storage_room_id") REFERENCES "storage_room" ("id") NOT VALID;
@@ -0,0 +1,10 @@
+-- Storage rooms can be deleted once they hold no stock. The adjustment
+-- ledger, pick lists, counts and transfer lines are history and keep the ID
+-- of a deleted room, as serial movements do, so their foreign keys go.
+-- stock_level keeps its key: a room is only deleted after its empty stock
+-- levels.
+ALTER TABLE "stock_adjustment" DROP CONSTRAINT IF EXISTS "stock_adjustment_storage_room_id_fkey";
+ALTER TABLE "pick_list_line" DROP CONSTRAINT IF EXISTS "pick_list_line_storage_room_id_fkey";
+ALTER TABLE "count_session" DROP CONSTRAINT IF EXISTS "count_session_storage_room_id_fkey";
+ALTER TABLE "count_line" DROP CONSTRAINT IF EXISTS "count_line_storage_room_id_fkey";
+ALTER TABLE "transfer_order_line" DROP CONSTRAINT IF EXISTS "transfer_order_line_source_storage_room_id_fkey";
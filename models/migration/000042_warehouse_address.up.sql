@@ -0,0 +1,18 @@
+-- Warehouse addresses as they were sent. The warehouse holds the normalized
+-- address: trimmed, the city in title case and the country as its ISO
+-- 3166-1 alpha-2 code, corrected by the validation provider when one is
+-- configured. validated_at is when the provider last accepted it.
+-- Warehouses last written before normalization have no row.
+CREATE TABLE "warehouse_address" (
+  "warehouse_id" bigint PRIMARY KEY REFERENCES "warehouse" ("id") ON DELETE CASCADE,
+  "org_id" varchar NOT NULL,
+  "address" varchar NOT NULL DEFAULT '',
+  "ward" varchar NOT NULL DEFAULT '',
+  "district" varchar NOT NULL DEFAULT '',
+  "city" varchar NOT NULL DEFAULT '',
+  "country" varchar NOT NULL DEFAULT '',
+  "validated_at" timestamptz,
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "warehouse_address" ("org_id");
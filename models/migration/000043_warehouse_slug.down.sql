@@ -0,0 +1,5 @@
+UPDATE row_history SET data = data - 'slug'
+WHERE entity = 'warehouse';
+
+DROP INDEX IF EXISTS warehouse_org_slug_key;
+ALTER TABLE "warehouse" DROP COLUMN IF EXISTS "slug";
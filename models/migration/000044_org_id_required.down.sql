@@ -0,0 +1,2 @@
+ALTER TABLE "storage_room" ALTER COLUMN "org_id" SET DEFAULT '';
+ALTER TABLE "warehouse" ALTER COLUMN "org_id" SET DEFAULT '';
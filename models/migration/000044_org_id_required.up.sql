@@ -0,0 +1,4 @@
+-- Rows from before tenancy keep an empty org_id until assign-tenant gives
+-- them an organization, new rows must name theirs
+ALTER TABLE "warehouse" ALTER COLUMN "org_id" DROP DEFAULT;
+ALTER TABLE "storage_room" ALTER COLUMN "org_id" DROP DEFAULT;
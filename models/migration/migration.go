@@ -0,0 +1,39 @@
+// Package migration embeds the golang-migrate SQL files so the service
+// binary can apply them without the source tree
+package migration
+
+import (
+	"embed"
+	"fmt"
+
+	"github.com/golang-migrate/migrate/v4"
+	pgxmigrate "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+//go:embed *.sql
+var FS embed.FS
+
+// New returns a migrator applying the embedded migrations to the database
+// at dsn. Closing it closes its database connection.
+func New(dsn string) (*migrate.Migrate, error) {
+	connConfig, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("parse database URL: %w", err)
+	}
+	db := stdlib.OpenDB(*connConfig)
+
+	driver, err := pgxmigrate.WithInstance(db, &pgxmigrate.Config{})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("open migration driver: %w", err)
+	}
+	source, err := iofs.New(FS, ".")
+	if err != nil {
+		driver.Close()
+		return nil, fmt.Errorf("open embedded migrations: %w", err)
+	}
+	return migrate.NewWithInstance("iofs", source, "pgx5", driver)
+}
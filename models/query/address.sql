@@ -0,0 +1,28 @@
+-- name: UpsertWarehouseAddress :exec
+-- Stores the address of a warehouse as it was sent. Parts left null keep
+-- their stored raw value, or the warehouse's when there is none yet.
+INSERT INTO warehouse_address (
+    warehouse_id, org_id, address, ward, district, city, country, validated_at
+)
+SELECT w.id, w.org_id,
+    COALESCE(sqlc.narg('address'), a.address, w.address),
+    COALESCE(sqlc.narg('ward'), a.ward, w.ward),
+    COALESCE(sqlc.narg('district'), a.district, w.district),
+    COALESCE(sqlc.narg('city'), a.city, w.city),
+    COALESCE(sqlc.narg('country'), a.country, w.country),
+    CASE WHEN sqlc.arg('validated')::bool THEN now() END
+FROM warehouse w
+LEFT JOIN warehouse_address a ON a.warehouse_id = w.id
+WHERE w.id = sqlc.arg('warehouse_id') AND w.org_id = sqlc.arg('org_id')
+ON CONFLICT (warehouse_id) DO UPDATE
+SET address = EXCLUDED.address,
+    ward = EXCLUDED.ward,
+    district = EXCLUDED.district,
+    city = EXCLUDED.city,
+    country = EXCLUDED.country,
+    validated_at = EXCLUDED.validated_at,
+    updated_at = now();
+
+-- name: GetWarehouseAddress :one
+SELECT * FROM warehouse_address
+WHERE warehouse_id = $1 AND org_id = $2;
@@ -0,0 +1,31 @@
+-- name: CreateAPIKey :one
+INSERT INTO api_key (
+    org_id, name, prefix, secret_hash, created_by, expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetAPIKeyByPrefix :one
+SELECT * FROM api_key
+WHERE prefix = $1;
+
+-- name: TouchAPIKey :exec
+UPDATE api_key
+SET last_used_at = now()
+WHERE id = $1;
+
+-- name: GetAPIKey :one
+SELECT * FROM api_key
+WHERE id = $1;
+
+-- name: ListAPIKeys :many
+SELECT * FROM api_key
+WHERE org_id = $1
+ORDER BY id DESC
+LIMIT $2 OFFSET $3;
+
+-- name: RevokeAPIKey :one
+UPDATE api_key
+SET revoked_at = now()
+WHERE id = $1 AND revoked_at IS NULL
+RETURNING *;
@@ -0,0 +1,21 @@
+-- name: CreateAttachment :one
+INSERT INTO attachment (
+    org_id, warehouse_id, kind, file_name, content_type, size_bytes, checksum, object_key, uploaded_by
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING *;
+
+-- name: GetAttachment :one
+SELECT * FROM attachment
+WHERE id = $1 AND warehouse_id = $2 AND org_id = $3;
+
+-- name: DeleteAttachment :one
+DELETE FROM attachment
+WHERE id = $1 AND warehouse_id = $2 AND org_id = $3
+RETURNING *;
+
+-- name: DeleteAttachmentsInWarehouse :many
+-- Returns the object keys, which the caller removes from the store
+DELETE FROM attachment
+WHERE warehouse_id = $1 AND org_id = $2
+RETURNING object_key;
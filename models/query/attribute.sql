@@ -0,0 +1,24 @@
+-- name: GetAttributeSchema :one
+SELECT * FROM attribute_schema
+WHERE org_id = $1 AND entity_type = $2;
+
+-- name: ListAttributeSchemas :many
+SELECT * FROM attribute_schema
+WHERE org_id = $1
+ORDER BY entity_type;
+
+-- name: UpsertAttributeSchema :one
+INSERT INTO attribute_schema (
+    org_id, entity_type, schema, updated_by
+) VALUES (
+    $1, $2, $3, $4
+)
+ON CONFLICT (org_id, entity_type) DO UPDATE
+SET schema = EXCLUDED.schema,
+    updated_by = EXCLUDED.updated_by,
+    updated_at = now()
+RETURNING *;
+
+-- name: DeleteAttributeSchema :execrows
+DELETE FROM attribute_schema
+WHERE org_id = $1 AND entity_type = $2;
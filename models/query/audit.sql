@@ -0,0 +1,26 @@
+-- name: CreateAuditLog :one
+INSERT INTO audit_log (
+    org_id, entity_type, entity_id, action, from_status, to_status, actor
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: ListAuditLogsForEntity :many
+SELECT * FROM audit_log
+WHERE org_id = $1 AND entity_type = $2 AND entity_id = $3
+ORDER BY id;
+
+-- name: DeleteAuditLogsBefore :execrows
+DELETE FROM audit_log
+WHERE created_at < $1;
+
+-- name: ListAuditLogsPage :many
+SELECT * FROM audit_log
+WHERE org_id = sqlc.arg('org_id')
+  AND (sqlc.narg('entity_type')::varchar IS NULL OR entity_type = sqlc.narg('entity_type')::varchar)
+  AND (
+    sqlc.narg('before_created_at')::timestamptz IS NULL
+    OR (created_at, id) < (sqlc.narg('before_created_at')::timestamptz, sqlc.arg('before_id')::bigint)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('page_limit');
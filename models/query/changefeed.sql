@@ -0,0 +1,26 @@
+-- name: GetChangeFeedHorizon :one
+-- Transactions below the horizon have all ended, their events are final
+SELECT pg_snapshot_xmin(pg_current_snapshot())::text::bigint;
+
+-- name: ListChangeEventsAfter :many
+SELECT * FROM change_event
+WHERE (txid, id) > (sqlc.arg('after_txid')::bigint, sqlc.arg('after_id')::bigint)
+  AND txid < pg_snapshot_xmin(pg_current_snapshot())::text::bigint
+ORDER BY txid, id
+LIMIT sqlc.arg('page_limit');
+
+-- name: DeleteChangeEventsBefore :execrows
+DELETE FROM change_event
+WHERE created_at < $1;
+
+-- name: GetChangeEvent :one
+SELECT * FROM change_event
+WHERE id = $1 AND org_id = $2;
+
+-- name: ListOrgChangeEventsAfter :many
+SELECT * FROM change_event
+WHERE org_id = sqlc.arg('org_id')
+  AND (txid, id) > (sqlc.arg('after_txid')::bigint, sqlc.arg('after_id')::bigint)
+  AND txid < pg_snapshot_xmin(pg_current_snapshot())::text::bigint
+ORDER BY txid, id
+LIMIT sqlc.arg('page_limit');
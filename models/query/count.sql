@@ -0,0 +1,75 @@
+-- name: CreateCountSession :one
+INSERT INTO count_session (
+    org_id, warehouse_id, storage_room_id
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: GetCountSession :one
+SELECT * FROM count_session
+WHERE id = $1 AND org_id = $2;
+
+-- name: GetCountSessionForUpdate :one
+SELECT * FROM count_session
+WHERE id = $1 AND org_id = $2
+FOR UPDATE;
+
+-- name: GetOpenCountSessionForRoom :one
+-- The newest open count covering a storage room, of its whole warehouse or
+-- of the room alone
+SELECT * FROM count_session
+WHERE org_id = $1 AND warehouse_id = $2 AND status = 'open'
+  AND (storage_room_id IS NULL OR storage_room_id = sqlc.arg('storage_room_id')::int)
+ORDER BY id DESC
+LIMIT 1
+FOR UPDATE;
+
+-- name: ListCountSessions :many
+SELECT * FROM count_session
+WHERE org_id = $1
+ORDER BY id DESC
+LIMIT $2 OFFSET $3;
+
+-- name: UpdateCountSessionStatus :one
+UPDATE count_session
+SET status = $3,
+    updated_at = now()
+WHERE id = $1 AND org_id = $2
+RETURNING *;
+
+-- name: SnapshotWarehouseCountLines :execrows
+INSERT INTO count_line (
+    count_session_id, storage_room_id, sku, book_quantity
+)
+SELECT $1, stock_level.storage_room_id, stock_level.sku, stock_level.quantity
+FROM stock_level
+JOIN storage_room ON storage_room.id = stock_level.storage_room_id
+WHERE stock_level.org_id = $2 AND storage_room.warehouse_id = $3;
+
+-- name: SnapshotRoomCountLines :execrows
+INSERT INTO count_line (
+    count_session_id, storage_room_id, sku, book_quantity
+)
+SELECT $1, stock_level.storage_room_id, stock_level.sku, stock_level.quantity
+FROM stock_level
+WHERE stock_level.org_id = $2 AND stock_level.storage_room_id = $3;
+
+-- name: RecordCountLine :one
+INSERT INTO count_line (
+    count_session_id, storage_room_id, sku, book_quantity, counted_quantity
+) VALUES (
+    $1, $2, $3, 0, $4
+)
+ON CONFLICT (count_session_id, storage_room_id, sku)
+DO UPDATE SET counted_quantity = EXCLUDED.counted_quantity
+RETURNING *;
+
+-- name: ListCountLines :many
+SELECT * FROM count_line
+WHERE count_session_id = $1
+ORDER BY storage_room_id, sku;
+
+-- name: ApproveCountLine :exec
+UPDATE count_line
+SET approved = true
+WHERE id = $1 AND count_session_id = $2;
@@ -0,0 +1,22 @@
+-- name: GetDashboardStats :one
+SELECT * FROM dashboard_stats
+WHERE org_id = $1;
+
+-- name: GetViewRefresh :one
+SELECT * FROM materialized_view_refresh
+WHERE name = $1;
+
+-- name: TryLockRefresh :one
+-- Returns false while another transaction holds the lock on key
+SELECT pg_try_advisory_xact_lock(hashtextextended(sqlc.arg('key')::text, 0));
+
+-- name: RefreshDashboardStats :exec
+REFRESH MATERIALIZED VIEW CONCURRENTLY dashboard_stats;
+
+-- name: RecordViewRefresh :one
+INSERT INTO materialized_view_refresh (name, refreshed_at, duration_ms)
+VALUES ($1, now(), $2)
+ON CONFLICT (name) DO UPDATE
+SET refreshed_at = EXCLUDED.refreshed_at,
+    duration_ms = EXCLUDED.duration_ms
+RETURNING *;
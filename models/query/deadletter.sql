@@ -0,0 +1,33 @@
+-- name: DeadLetterOutboxMessage :one
+-- Moves an outbox message that ran out of attempts to the dead letters
+WITH moved AS (
+    DELETE FROM outbox
+    WHERE outbox.id = $1
+    RETURNING *
+)
+INSERT INTO dead_letter (
+    org_id, source, source_id, topic, key, payload, attempts, last_error, created_at
+)
+SELECT org_id, 'outbox', id, topic, key, payload, attempts + 1, sqlc.arg('last_error')::varchar, created_at
+FROM moved
+RETURNING *;
+
+-- name: GetDeadLetter :one
+SELECT * FROM dead_letter
+WHERE id = $1 AND org_id = $2;
+
+-- name: MarkDeadLetterReplayed :one
+UPDATE dead_letter
+SET replayed_at = now(),
+    replayed_by = $3
+WHERE id = $1 AND org_id = $2 AND replayed_at IS NULL
+RETURNING *;
+
+-- name: RequeueOutboxMessage :exec
+-- Puts a dead letter back in the outbox under its original ID, so
+-- consumers that did receive it can still deduplicate
+INSERT INTO outbox (
+    id, org_id, topic, key, payload, created_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+);
@@ -0,0 +1,25 @@
+-- name: CreateEdiDocument :one
+INSERT INTO edi_document (
+    org_id, partner_id, direction, document_type, sender_id,
+    control_number, transaction_control, receipt_id, status, error
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+) RETURNING *;
+
+-- name: UpdateEdiDocumentStatus :one
+UPDATE edi_document
+SET status = $3,
+    error = $4,
+    updated_at = now()
+WHERE id = $1 AND org_id = $2
+RETURNING *;
+
+-- name: ListInventoryAdvice :many
+-- Stock of each SKU of an organization, what an 846 reports
+SELECT sku,
+       SUM(quantity)::bigint AS on_hand,
+       SUM(quantity - allocated_quantity)::bigint AS available
+FROM stock_level
+WHERE org_id = $1
+GROUP BY sku
+ORDER BY sku;
@@ -0,0 +1,91 @@
+-- name: CreateFileExchange :one
+INSERT INTO file_exchange (
+    org_id, name, inbound_url, outbound_url, host_key,
+    warehouse_id, supplier_id, extract_format, enabled
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING *;
+
+-- name: CreateFileExchangeContent :exec
+INSERT INTO file_exchange_content (
+    file_id, content
+) VALUES (
+    $1, $2
+);
+
+-- name: CreateFileExchangeFile :one
+-- Returns no row for an inbound file whose content the exchange already
+-- picked up
+INSERT INTO file_exchange_file (
+    org_id, exchange_id, run_id, direction, name, format, sha256, size, status, error
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+)
+ON CONFLICT (exchange_id, sha256) WHERE direction = 'inbound' DO NOTHING
+RETURNING *;
+
+-- name: CreateFileExchangeRun :one
+INSERT INTO file_exchange_run (
+    org_id, exchange_id, trigger
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: DeleteFileExchange :execrows
+DELETE FROM file_exchange
+WHERE id = $1 AND org_id = $2;
+
+-- name: FinishFileExchangeRun :one
+UPDATE file_exchange_run
+SET status = $2,
+    files_processed = $3,
+    files_failed = $4,
+    error = $5,
+    finished_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: GetFileExchange :one
+SELECT * FROM file_exchange
+WHERE id = $1 AND org_id = $2;
+
+-- name: GetFileExchangeContent :one
+SELECT content FROM file_exchange_content
+WHERE file_id = $1;
+
+-- name: GetFileExchangeFile :one
+SELECT * FROM file_exchange_file
+WHERE id = $1 AND exchange_id = $2 AND org_id = $3;
+
+-- name: ListEnabledFileExchanges :many
+-- Enabled exchanges of all organizations, for the scheduled runs
+SELECT * FROM file_exchange
+WHERE enabled
+ORDER BY id;
+
+-- name: ListFileExchanges :many
+SELECT * FROM file_exchange
+WHERE org_id = $1
+ORDER BY name;
+
+-- name: UpdateFileExchange :one
+UPDATE file_exchange
+SET name = $3,
+    inbound_url = $4,
+    outbound_url = $5,
+    host_key = $6,
+    warehouse_id = $7,
+    supplier_id = $8,
+    extract_format = $9,
+    enabled = $10,
+    updated_at = now()
+WHERE id = $1 AND org_id = $2
+RETURNING *;
+
+-- name: UpdateFileExchangeFileStatus :one
+UPDATE file_exchange_file
+SET status = $3,
+    error = $4,
+    updated_at = now()
+WHERE id = $1 AND org_id = $2
+RETURNING *;
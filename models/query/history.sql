@@ -0,0 +1,35 @@
+-- name: GetCurrentVersion :one
+SELECT id FROM row_history
+WHERE entity = sqlc.arg('entity') AND entity_id = sqlc.arg('entity_id') AND org_id = sqlc.arg('org_id') AND valid_to IS NULL
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: GetWarehouseVersion :one
+SELECT h.id AS version, h.operation, h.valid_from, h.valid_to, w.*
+FROM row_history h
+CROSS JOIN LATERAL jsonb_populate_record(NULL::warehouse, h.data) w
+WHERE h.id = sqlc.arg('version') AND h.entity = 'warehouse' AND h.entity_id = sqlc.arg('id') AND h.org_id = sqlc.arg('org_id');
+
+-- name: ListStorageRoomHistory :many
+SELECT h.id AS version, h.operation, h.valid_from, h.valid_to, r.*
+FROM row_history h
+CROSS JOIN LATERAL jsonb_populate_record(NULL::storage_room, h.data) r
+WHERE h.entity = 'storage_room' AND h.entity_id = sqlc.arg('id') AND h.org_id = sqlc.arg('org_id')
+  AND (
+    sqlc.narg('as_of')::timestamptz IS NULL
+    OR (h.valid_from <= sqlc.narg('as_of')::timestamptz AND (h.valid_to IS NULL OR h.valid_to > sqlc.narg('as_of')::timestamptz))
+  )
+ORDER BY h.id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListWarehouseHistory :many
+SELECT h.id AS version, h.operation, h.valid_from, h.valid_to, w.*
+FROM row_history h
+CROSS JOIN LATERAL jsonb_populate_record(NULL::warehouse, h.data) w
+WHERE h.entity = 'warehouse' AND h.entity_id = sqlc.arg('id') AND h.org_id = sqlc.arg('org_id')
+  AND (
+    sqlc.narg('as_of')::timestamptz IS NULL
+    OR (h.valid_from <= sqlc.narg('as_of')::timestamptz AND (h.valid_to IS NULL OR h.valid_to > sqlc.narg('as_of')::timestamptz))
+  )
+ORDER BY h.id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
@@ -0,0 +1,20 @@
+-- name: ClaimProcessedMessage :execrows
+-- Records a message as processed, no row is inserted for one processed
+-- before. A concurrent delivery of the same message waits for the claiming
+-- transaction.
+INSERT INTO processed_message (
+    topic, message_id, org_id
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (topic, message_id) DO NOTHING;
+
+-- name: DeleteProcessedMessagesBefore :execrows
+DELETE FROM processed_message
+WHERE processed_at < $1;
+
+-- name: RejectProcessedMessage :exec
+UPDATE processed_message
+SET status = 'rejected',
+    error = $3
+WHERE topic = $1 AND message_id = $2;
@@ -0,0 +1,109 @@
+-- name: CreateIntegration :one
+INSERT INTO integration (
+    org_id, name, kind, url, orders_url, token, location_id, warehouse_id, enabled
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING *;
+
+-- name: CreateIntegrationOrder :one
+INSERT INTO integration_order (
+    org_id, integration_id, external_id, reference, lines, status,
+    pick_list_id, error, external_updated_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING *;
+
+-- name: DeleteIntegration :execrows
+DELETE FROM integration
+WHERE id = $1 AND org_id = $2;
+
+-- name: FailIntegrationSync :exec
+UPDATE integration
+SET failures = failures + 1,
+    last_error = $2,
+    next_sync_at = $3
+WHERE id = $1;
+
+-- name: FinishIntegrationSync :exec
+UPDATE integration
+SET orders_synced_at = $2,
+    last_synced_at = now(),
+    failures = 0,
+    last_error = '',
+    next_sync_at = now()
+WHERE id = $1;
+
+-- name: GetIntegration :one
+SELECT * FROM integration
+WHERE id = $1 AND org_id = $2;
+
+-- name: GetIntegrationOrderForUpdate :one
+SELECT * FROM integration_order
+WHERE integration_id = $1 AND external_id = $2
+FOR UPDATE;
+
+-- name: ListAvailableStock :many
+-- Stock of each SKU of a warehouse that isn't allocated to pick lists
+SELECT stock_level.sku,
+       GREATEST(SUM(stock_level.quantity - stock_level.allocated_quantity), 0)::bigint AS available
+FROM stock_level
+JOIN storage_room ON storage_room.id = stock_level.storage_room_id
+WHERE stock_level.org_id = $1
+  AND storage_room.warehouse_id = $2
+GROUP BY stock_level.sku
+ORDER BY stock_level.sku;
+
+-- name: ListDueIntegrations :many
+SELECT * FROM integration
+WHERE enabled AND next_sync_at <= now()
+ORDER BY next_sync_at;
+
+-- name: ListIntegrationStock :many
+SELECT * FROM integration_stock
+WHERE integration_id = $1;
+
+-- name: ListIntegrations :many
+SELECT * FROM integration
+WHERE org_id = $1
+ORDER BY name;
+
+-- name: ListShortIntegrationOrders :many
+SELECT * FROM integration_order
+WHERE integration_id = $1 AND status = 'short'
+ORDER BY id;
+
+-- name: UpdateIntegration :one
+UPDATE integration
+SET name = $3,
+    kind = $4,
+    url = $5,
+    orders_url = $6,
+    token = $7,
+    location_id = $8,
+    warehouse_id = $9,
+    enabled = $10,
+    updated_at = now()
+WHERE id = $1 AND org_id = $2
+RETURNING *;
+
+-- name: UpdateIntegrationOrder :one
+UPDATE integration_order
+SET reference = $2,
+    lines = $3,
+    status = $4,
+    pick_list_id = $5,
+    error = $6,
+    external_updated_at = $7,
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: UpsertIntegrationStock :exec
+INSERT INTO integration_stock (
+    integration_id, sku, available
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (integration_id, sku)
+DO UPDATE SET available = EXCLUDED.available,
+    pushed_at = now();
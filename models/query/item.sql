@@ -0,0 +1,43 @@
+-- name: CreateItem :one
+INSERT INTO item (
+    org_id, sku, description, base_unit, length_cm, width_cm, height_cm, weight_kg
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING *;
+
+-- name: GetItem :one
+SELECT * FROM item
+WHERE id = $1 AND org_id = $2;
+
+-- name: GetItemBySku :one
+SELECT * FROM item
+WHERE org_id = $1 AND sku = $2;
+
+-- name: UpdateItem :one
+UPDATE item
+SET description = $3,
+    base_unit = $4,
+    length_cm = $5,
+    width_cm = $6,
+    height_cm = $7,
+    weight_kg = $8,
+    updated_at = now()
+WHERE id = $1 AND org_id = $2
+RETURNING *;
+
+-- name: DeleteItem :execrows
+DELETE FROM item
+WHERE id = $1 AND org_id = $2;
+
+-- name: ListItemUnits :many
+SELECT * FROM item_unit
+WHERE item_id = ANY(sqlc.arg('item_ids')::bigint[])
+ORDER BY item_id, factor;
+
+-- name: CreateItemUnits :exec
+INSERT INTO item_unit (item_id, unit, factor)
+SELECT sqlc.arg('item_id')::bigint, unnest(sqlc.arg('units')::varchar[]), unnest(sqlc.arg('factors')::int[]);
+
+-- name: DeleteItemUnits :exec
+DELETE FROM item_unit
+WHERE item_id = $1;
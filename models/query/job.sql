@@ -0,0 +1,69 @@
+-- name: EnqueueJob :one
+INSERT INTO job (
+    org_id, kind, payload, max_attempts, run_at
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: ClaimJob :one
+UPDATE job
+SET status = 'running',
+    attempts = attempts + 1,
+    locked_at = now(),
+    updated_at = now()
+WHERE id = (
+    SELECT id FROM job
+    WHERE status = 'queued' AND run_at <= now()
+    ORDER BY run_at, id
+    FOR UPDATE SKIP LOCKED
+    LIMIT 1
+)
+RETURNING *;
+
+-- name: CompleteJob :exec
+UPDATE job
+SET status = 'succeeded',
+    last_error = '',
+    locked_at = NULL,
+    updated_at = now()
+WHERE id = $1;
+
+-- name: RetryJob :exec
+UPDATE job
+SET status = 'queued',
+    last_error = $2,
+    run_at = $3,
+    locked_at = NULL,
+    updated_at = now()
+WHERE id = $1;
+
+-- name: FailJob :exec
+UPDATE job
+SET status = 'failed',
+    last_error = $2,
+    locked_at = NULL,
+    updated_at = now()
+WHERE id = $1;
+
+-- name: RequeueStaleJobs :execrows
+UPDATE job
+SET status = 'queued',
+    locked_at = NULL,
+    updated_at = now()
+WHERE status = 'running' AND locked_at < $1;
+
+-- name: GetJob :one
+SELECT * FROM job
+WHERE id = $1 AND org_id = $2;
+
+-- name: ListJobs :many
+SELECT * FROM job
+WHERE org_id = $1
+ORDER BY id DESC
+LIMIT $2 OFFSET $3;
+
+-- name: CountJobsByStatus :many
+SELECT kind, status, count(*)::bigint AS jobs
+FROM job
+GROUP BY kind, status
+ORDER BY kind, status;
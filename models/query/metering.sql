@@ -0,0 +1,63 @@
+-- name: AggregateMetering :execrows
+-- Upserts the usage on day of every tenant with calls, entities or
+-- deliveries. Events count when delivered in [day_start, day_end).
+WITH calls AS (
+  SELECT org_id, calls
+  FROM api_usage
+  WHERE day = sqlc.arg('day') AND user_id = ''
+), warehouses AS (
+  SELECT org_id, count(*) AS warehouses
+  FROM warehouse
+  GROUP BY org_id
+), rooms AS (
+  SELECT org_id, count(*) AS storage_rooms
+  FROM storage_room
+  GROUP BY org_id
+), items AS (
+  SELECT org_id, count(*) AS items
+  FROM item
+  GROUP BY org_id
+), events AS (
+  SELECT org_id, count(*) AS events_delivered
+  FROM outbox
+  WHERE delivered_at >= sqlc.arg('day_start') AND delivered_at < sqlc.arg('day_end')
+  GROUP BY org_id
+), tenants AS (
+  SELECT org_id FROM calls
+  UNION SELECT org_id FROM warehouses
+  UNION SELECT org_id FROM rooms
+  UNION SELECT org_id FROM items
+  UNION SELECT org_id FROM events
+)
+INSERT INTO tenant_metering (org_id, day, api_calls, warehouses, storage_rooms, items, events_delivered, aggregated_at)
+SELECT tenants.org_id,
+       sqlc.arg('day'),
+       COALESCE(calls.calls, 0),
+       COALESCE(warehouses.warehouses, 0),
+       COALESCE(rooms.storage_rooms, 0),
+       COALESCE(items.items, 0),
+       COALESCE(events.events_delivered, 0),
+       now()
+FROM tenants
+LEFT JOIN calls USING (org_id)
+LEFT JOIN warehouses USING (org_id)
+LEFT JOIN rooms USING (org_id)
+LEFT JOIN items USING (org_id)
+LEFT JOIN events USING (org_id)
+ON CONFLICT (org_id, day) DO UPDATE
+SET api_calls = EXCLUDED.api_calls,
+    warehouses = EXCLUDED.warehouses,
+    storage_rooms = EXCLUDED.storage_rooms,
+    items = EXCLUDED.items,
+    events_delivered = EXCLUDED.events_delivered,
+    aggregated_at = EXCLUDED.aggregated_at;
+
+-- name: ListTenantMetering :many
+SELECT * FROM tenant_metering
+WHERE org_id = sqlc.arg('org_id') AND day >= sqlc.arg('from_day') AND day < sqlc.arg('to_day')
+ORDER BY day;
+
+-- name: ListMetering :many
+SELECT * FROM tenant_metering
+WHERE day >= sqlc.arg('from_day') AND day < sqlc.arg('to_day')
+ORDER BY day, org_id;
@@ -0,0 +1,48 @@
+-- name: EnqueueOutboxMessage :one
+INSERT INTO outbox (
+    org_id, topic, key, payload
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: ClaimOutboxMessages :many
+-- Locks the due messages for the claiming transaction, concurrent relays
+-- skip them
+SELECT * FROM outbox
+WHERE delivered_at IS NULL AND next_attempt_at <= now()
+ORDER BY id
+LIMIT $1
+FOR UPDATE SKIP LOCKED;
+
+-- name: MarkOutboxDelivered :exec
+UPDATE outbox
+SET delivered_at = now(),
+    attempts = attempts + 1,
+    last_error = ''
+WHERE id = $1;
+
+-- name: RetryOutboxMessage :exec
+UPDATE outbox
+SET attempts = attempts + 1,
+    last_error = $2,
+    next_attempt_at = $3
+WHERE id = $1;
+
+-- name: GetOutboxBacklog :one
+SELECT count(*)::bigint AS pending,
+       COALESCE(EXTRACT(EPOCH FROM now() - min(created_at)), 0)::float8 AS lag_seconds
+FROM outbox
+WHERE delivered_at IS NULL;
+
+-- name: DeleteDeliveredOutboxBefore :execrows
+DELETE FROM outbox
+WHERE delivered_at < $1;
+
+-- name: ListPendingOutboxMessages :many
+-- Undelivered messages of every tenant, or of one with org_id, in the
+-- order the relay picks them up
+SELECT * FROM outbox
+WHERE delivered_at IS NULL
+  AND (sqlc.narg('org_id')::varchar IS NULL OR org_id = sqlc.narg('org_id')::varchar)
+ORDER BY next_attempt_at, id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
@@ -0,0 +1,46 @@
+-- name: CreatePartner :one
+INSERT INTO partner (
+    org_id, kind, name, contact_name, email, phone, lead_time_days,
+    edi_qualifier, edi_id, sftp_url, sftp_host_key
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+) RETURNING *;
+
+-- name: GetPartner :one
+SELECT * FROM partner
+WHERE id = $1 AND org_id = $2 AND kind = $3;
+
+-- name: GetPartnerByEdiID :one
+SELECT * FROM partner
+WHERE org_id = $1 AND edi_qualifier = $2 AND edi_id = $3;
+
+-- name: ListPartners :many
+SELECT * FROM partner
+WHERE org_id = $1 AND kind = $2
+ORDER BY name
+LIMIT $3 OFFSET $4;
+
+-- name: ListEdiDropPartners :many
+-- Suppliers of all organizations that inventory advice is uploaded to
+SELECT * FROM partner
+WHERE kind = 'supplier' AND edi_id <> '' AND sftp_url <> ''
+ORDER BY org_id, id;
+
+-- name: UpdatePartner :one
+UPDATE partner
+SET name = $4,
+    contact_name = $5,
+    email = $6,
+    phone = $7,
+    lead_time_days = $8,
+    edi_qualifier = $9,
+    edi_id = $10,
+    sftp_url = $11,
+    sftp_host_key = $12,
+    updated_at = now()
+WHERE id = $1 AND org_id = $2 AND kind = $3
+RETURNING *;
+
+-- name: DeletePartner :execrows
+DELETE FROM partner
+WHERE id = $1 AND org_id = $2 AND kind = $3;
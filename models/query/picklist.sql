@@ -0,0 +1,52 @@
+-- name: CreatePickList :one
+INSERT INTO pick_list (
+    org_id, warehouse_id, reference, strategy, carrier_id
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetPickList :one
+SELECT * FROM pick_list
+WHERE id = $1 AND org_id = $2;
+
+-- name: GetPickListForUpdate :one
+SELECT * FROM pick_list
+WHERE id = $1 AND org_id = $2
+FOR UPDATE;
+
+-- name: ListPickLists :many
+SELECT * FROM pick_list
+WHERE org_id = $1
+ORDER BY id DESC
+LIMIT $2 OFFSET $3;
+
+-- name: UpdatePickListStatus :one
+UPDATE pick_list
+SET status = $3,
+    updated_at = now()
+WHERE id = $1 AND org_id = $2
+RETURNING *;
+
+-- name: CreatePickListLine :one
+INSERT INTO pick_list_line (
+    pick_list_id, sku, storage_room_id, quantity
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: ListPickListLines :many
+SELECT * FROM pick_list_line
+WHERE pick_list_id = $1
+ORDER BY id;
+
+-- name: ConfirmPickListLine :one
+UPDATE pick_list_line
+SET picked_quantity = $3
+WHERE id = $1 AND pick_list_id = $2
+RETURNING *;
+
+-- name: ListStalePickLists :many
+SELECT * FROM pick_list
+WHERE status = 'allocated' AND updated_at < $1
+ORDER BY updated_at
+LIMIT $2;
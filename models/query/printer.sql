@@ -0,0 +1,39 @@
+-- name: CreatePrinter :one
+INSERT INTO printer (
+    org_id, name, address, dpi
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: DeletePrinter :execrows
+DELETE FROM printer
+WHERE id = $1 AND org_id = $2;
+
+-- name: GetPrinter :one
+SELECT * FROM printer
+WHERE id = $1 AND org_id = $2;
+
+-- name: GetShipmentLabel :one
+-- What a shipping label of a pick list shows: where it ships from and with
+-- which carrier
+SELECT pick_list.id, pick_list.reference, warehouse.name AS warehouse_name,
+       warehouse.address, warehouse.city, warehouse.country,
+       COALESCE(partner.name, '')::varchar AS carrier_name
+FROM pick_list
+JOIN warehouse ON warehouse.id = pick_list.warehouse_id
+LEFT JOIN partner ON partner.id = pick_list.carrier_id
+WHERE pick_list.id = $1 AND pick_list.org_id = $2;
+
+-- name: ListPrinters :many
+SELECT * FROM printer
+WHERE org_id = $1
+ORDER BY name;
+
+-- name: UpdatePrinter :one
+UPDATE printer
+SET name = $3,
+    address = $4,
+    dpi = $5,
+    updated_at = now()
+WHERE id = $1 AND org_id = $2
+RETURNING *;
@@ -0,0 +1,47 @@
+-- name: CreateReceipt :one
+INSERT INTO receipt (
+    org_id, warehouse_id, reference, supplier_id
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetReceipt :one
+SELECT * FROM receipt
+WHERE id = $1 AND org_id = $2;
+
+-- name: GetReceiptForUpdate :one
+SELECT * FROM receipt
+WHERE id = $1 AND org_id = $2
+FOR UPDATE;
+
+-- name: ListReceipts :many
+SELECT * FROM receipt
+WHERE org_id = $1
+ORDER BY id DESC
+LIMIT $2 OFFSET $3;
+
+-- name: UpdateReceiptStatus :one
+UPDATE receipt
+SET status = $3,
+    updated_at = now()
+WHERE id = $1 AND org_id = $2
+RETURNING *;
+
+-- name: CreateReceiptLine :one
+INSERT INTO receipt_line (
+    receipt_id, sku, expected_quantity, unit_cost_cents
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: ListReceiptLines :many
+SELECT * FROM receipt_line
+WHERE receipt_id = $1
+ORDER BY id;
+
+-- name: ReceiveReceiptLine :one
+UPDATE receipt_line
+SET received_quantity = received_quantity + $3,
+    expires_at = LEAST(expires_at, $4)
+WHERE id = $1 AND receipt_id = $2
+RETURNING *;
@@ -0,0 +1,38 @@
+-- name: ListReorderPositions :many
+-- Shipped, on-hand and in-transit quantity of a SKU in every warehouse of
+-- the tenant that shipped, holds or awaits it
+WITH on_hand AS (
+  SELECT storage_room.warehouse_id::bigint AS warehouse_id,
+         sum(stock_level.quantity) AS quantity,
+         sum(stock_level.allocated_quantity) AS allocated_quantity
+  FROM stock_level
+  JOIN storage_room ON storage_room.id = stock_level.storage_room_id
+  WHERE stock_level.org_id = sqlc.arg('org_id') AND stock_level.sku = sqlc.arg('sku')
+  GROUP BY storage_room.warehouse_id
+), in_transit AS (
+  SELECT transfer_order.destination_warehouse_id AS warehouse_id,
+         sum(transfer_order_line.shipped_quantity - transfer_order_line.received_quantity) AS quantity
+  FROM transfer_order
+  JOIN transfer_order_line ON transfer_order_line.transfer_order_id = transfer_order.id
+  WHERE transfer_order.org_id = sqlc.arg('org_id') AND transfer_order.status = 'in_transit'
+    AND transfer_order_line.sku = sqlc.arg('sku')
+  GROUP BY transfer_order.destination_warehouse_id
+)
+SELECT warehouse.id AS warehouse_id,
+       COALESCE(sku_consumption.shipped_quantity, 0)::bigint AS shipped_quantity,
+       COALESCE(on_hand.quantity, 0)::bigint AS on_hand_quantity,
+       COALESCE(on_hand.allocated_quantity, 0)::bigint AS allocated_quantity,
+       COALESCE(in_transit.quantity, 0)::bigint AS in_transit_quantity
+FROM warehouse
+LEFT JOIN sku_consumption ON sku_consumption.org_id = warehouse.org_id
+  AND sku_consumption.warehouse_id = warehouse.id
+  AND sku_consumption.sku = sqlc.arg('sku')
+LEFT JOIN on_hand ON on_hand.warehouse_id = warehouse.id
+LEFT JOIN in_transit ON in_transit.warehouse_id = warehouse.id
+WHERE warehouse.org_id = sqlc.arg('org_id')
+  AND (sqlc.narg('warehouse_id')::bigint IS NULL OR warehouse.id = sqlc.narg('warehouse_id')::bigint)
+  AND (sku_consumption.sku IS NOT NULL OR on_hand.warehouse_id IS NOT NULL OR in_transit.warehouse_id IS NOT NULL)
+ORDER BY warehouse.id;
+
+-- name: RefreshSkuConsumption :exec
+REFRESH MATERIALIZED VIEW CONCURRENTLY sku_consumption;
@@ -0,0 +1,44 @@
+-- name: WarehouseSummary :many
+SELECT country, city, status, count(*)::bigint AS warehouses
+FROM warehouse
+WHERE org_id = $1
+GROUP BY country, city, status
+ORDER BY country, city, status;
+
+-- name: StockByWarehouse :many
+-- Every warehouse of the tenant, those without stock with zero totals
+SELECT warehouse.id AS warehouse_id,
+       warehouse.name AS warehouse_name,
+       count(DISTINCT storage_room.id)::bigint AS storage_rooms,
+       count(DISTINCT stock_level.sku)::bigint AS skus,
+       COALESCE(sum(stock_level.quantity), 0)::bigint AS quantity,
+       COALESCE(sum(stock_level.allocated_quantity), 0)::bigint AS allocated_quantity
+FROM warehouse
+LEFT JOIN storage_room ON storage_room.warehouse_id = warehouse.id
+  AND storage_room.org_id = warehouse.org_id
+LEFT JOIN stock_level ON stock_level.storage_room_id = storage_room.id
+  AND stock_level.org_id = warehouse.org_id
+  AND (sqlc.narg('sku')::varchar IS NULL OR stock_level.sku = sqlc.narg('sku')::varchar)
+WHERE warehouse.org_id = sqlc.arg('org_id')
+  AND (sqlc.narg('status')::varchar IS NULL OR warehouse.status = sqlc.narg('status')::varchar)
+GROUP BY warehouse.id
+ORDER BY warehouse.id;
+
+-- name: MovementHistory :many
+-- Stock adjustments in [from_time, to_time) summed per reason and UTC
+-- period, granularity is day, week or month
+SELECT date_trunc(sqlc.arg('granularity')::text, stock_adjustment.created_at, 'UTC')::timestamptz AS period,
+       stock_adjustment.reason,
+       count(*)::bigint AS movements,
+       COALESCE(sum(stock_adjustment.quantity_delta) FILTER (WHERE stock_adjustment.quantity_delta > 0), 0)::bigint AS quantity_in,
+       COALESCE(-sum(stock_adjustment.quantity_delta) FILTER (WHERE stock_adjustment.quantity_delta < 0), 0)::bigint AS quantity_out,
+       sum(stock_adjustment.quantity_delta)::bigint AS net_quantity
+FROM stock_adjustment
+JOIN storage_room ON storage_room.id = stock_adjustment.storage_room_id
+WHERE stock_adjustment.org_id = sqlc.arg('org_id')
+  AND stock_adjustment.created_at >= sqlc.arg('from_time')::timestamptz
+  AND stock_adjustment.created_at < sqlc.arg('to_time')::timestamptz
+  AND (sqlc.narg('warehouse_id')::int IS NULL OR storage_room.warehouse_id = sqlc.narg('warehouse_id')::int)
+  AND (sqlc.narg('sku')::varchar IS NULL OR stock_adjustment.sku = sqlc.narg('sku')::varchar)
+GROUP BY period, stock_adjustment.reason
+ORDER BY period, stock_adjustment.reason;
@@ -0,0 +1,43 @@
+-- name: CreateSaga :one
+INSERT INTO saga (
+    org_id, kind, pick_list_id
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: CreateSagaStep :exec
+INSERT INTO saga_step (
+    saga_id, position, name
+) VALUES (
+    $1, $2, $3
+);
+
+-- name: GetSaga :one
+SELECT * FROM saga
+WHERE id = $1 AND org_id = $2;
+
+-- name: GetSagaForPickList :one
+SELECT * FROM saga
+WHERE pick_list_id = $1 AND kind = $2;
+
+-- name: ListSagaSteps :many
+SELECT * FROM saga_step
+WHERE saga_id = $1
+ORDER BY position;
+
+-- name: UpdateSagaStatus :one
+UPDATE saga
+SET status = $2,
+    error = $3,
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateSagaStep :exec
+-- Records the outcome of running a step or its compensation
+UPDATE saga_step
+SET status = $3,
+    error = $4,
+    attempts = attempts + 1,
+    updated_at = now()
+WHERE saga_id = $1 AND position = $2;
@@ -0,0 +1,31 @@
+-- name: ResolveScan :one
+-- The entity a scanned code stands for: a location code before a serial
+-- number before a SKU. One round trip, handhelds wait on it.
+SELECT kind, id, warehouse_id, storage_room_id, sku, name, status, on_hand
+FROM (
+  SELECT 1 AS rank, 'location'::text AS kind, storage_room.id::bigint AS id,
+         storage_room.warehouse_id::bigint AS warehouse_id, storage_room.id AS storage_room_id,
+         ''::varchar AS sku, storage_room.name, ''::varchar AS status,
+         (SELECT COALESCE(sum(stock_level.quantity), 0) FROM stock_level
+          WHERE stock_level.storage_room_id = storage_room.id)::bigint AS on_hand
+  FROM storage_room
+  WHERE storage_room.org_id = sqlc.arg('org_id')
+    AND storage_room.warehouse_id = sqlc.narg('warehouse_id')::int
+    AND storage_room.number = sqlc.narg('number')::varchar
+  UNION ALL
+  SELECT 2, 'serial', serial.id, storage_room.warehouse_id, serial.storage_room_id,
+         serial.sku, serial.serial_number, serial.status, (serial.status = 'in_stock')::int::bigint
+  FROM serial
+  LEFT JOIN storage_room ON storage_room.id = serial.storage_room_id
+  WHERE serial.org_id = sqlc.arg('org_id') AND serial.serial_number = sqlc.arg('code')::varchar
+  UNION ALL
+  SELECT 3, 'item', item.id, NULL, NULL, code.sku, COALESCE(item.description, ''), '',
+         (SELECT COALESCE(sum(stock_level.quantity), 0) FROM stock_level
+          WHERE stock_level.org_id = sqlc.arg('org_id') AND stock_level.sku = code.sku)::bigint
+  FROM (SELECT sqlc.arg('code')::varchar AS sku) AS code
+  LEFT JOIN item ON item.org_id = sqlc.arg('org_id') AND item.sku = code.sku
+  WHERE item.id IS NOT NULL
+     OR EXISTS (SELECT 1 FROM stock_level WHERE stock_level.org_id = sqlc.arg('org_id') AND stock_level.sku = code.sku)
+) AS matches
+ORDER BY rank
+LIMIT 1;
@@ -0,0 +1,34 @@
+-- name: SearchInventory :many
+SELECT kind, id, warehouse_id, name, detail, rank
+FROM (
+    SELECT 'warehouse' AS kind, w.id, w.id AS warehouse_id, w.name, w.address AS detail,
+        ts_rank(
+            to_tsvector('simple', w.name || ' ' || w.address || ' ' || w.ward || ' ' || w.district || ' ' || w.city || ' ' || w.country),
+            plainto_tsquery('simple', sqlc.arg('query')::text)
+        ) + greatest(similarity(w.name, sqlc.arg('query')::text), similarity(w.address, sqlc.arg('query')::text)) AS rank
+    FROM warehouse w
+    WHERE w.org_id = sqlc.arg('org_id')
+      AND sqlc.arg('include_warehouses')::boolean
+      AND (
+        to_tsvector('simple', w.name || ' ' || w.address || ' ' || w.ward || ' ' || w.district || ' ' || w.city || ' ' || w.country)
+            @@ plainto_tsquery('simple', sqlc.arg('query')::text)
+        OR w.name % sqlc.arg('query')::text
+        OR w.address % sqlc.arg('query')::text
+      )
+    UNION ALL
+    SELECT 'storage_room' AS kind, s.id::bigint, s.warehouse_id::bigint, s.name, s.number AS detail,
+        ts_rank(
+            to_tsvector('simple', s.name || ' ' || s.number),
+            plainto_tsquery('simple', sqlc.arg('query')::text)
+        ) + greatest(similarity(s.name, sqlc.arg('query')::text), similarity(s.number, sqlc.arg('query')::text)) AS rank
+    FROM storage_room s
+    WHERE s.org_id = sqlc.arg('org_id')
+      AND sqlc.arg('include_storage_rooms')::boolean
+      AND (
+        to_tsvector('simple', s.name || ' ' || s.number) @@ plainto_tsquery('simple', sqlc.arg('query')::text)
+        OR s.name % sqlc.arg('query')::text
+        OR s.number % sqlc.arg('query')::text
+      )
+) results
+ORDER BY rank DESC, kind, id
+LIMIT sqlc.arg('page_limit') OFFSET sqlc.arg('page_offset');
@@ -0,0 +1,51 @@
+-- Fixture rows carry fixed IDs so tests and demos can refer to them. A
+-- fixture ID already owned by another organization is left alone and
+-- reported as zero rows.
+
+-- name: SeedWarehouse :execrows
+INSERT INTO warehouse (
+    id, name, address, ward, district, city, country, org_id, latitude, longitude, time_zone, tags, slug
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+)
+ON CONFLICT (id) DO UPDATE
+SET name = EXCLUDED.name,
+    address = EXCLUDED.address,
+    ward = EXCLUDED.ward,
+    district = EXCLUDED.district,
+    city = EXCLUDED.city,
+    country = EXCLUDED.country,
+    latitude = EXCLUDED.latitude,
+    longitude = EXCLUDED.longitude,
+    time_zone = EXCLUDED.time_zone,
+    tags = EXCLUDED.tags
+WHERE warehouse.org_id = EXCLUDED.org_id;
+
+-- name: SeedStorageRoom :execrows
+INSERT INTO storage_room (
+    id, name, number, warehouse_id, org_id, zone_type
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (id) DO UPDATE
+SET name = EXCLUDED.name,
+    number = EXCLUDED.number,
+    warehouse_id = EXCLUDED.warehouse_id,
+    zone_type = EXCLUDED.zone_type
+WHERE storage_room.org_id = EXCLUDED.org_id;
+
+-- name: SeedStockLevel :exec
+INSERT INTO stock_level (
+    org_id, storage_room_id, sku, quantity, expires_at
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+ON CONFLICT (org_id, storage_room_id, sku) DO UPDATE
+SET quantity = EXCLUDED.quantity,
+    expires_at = EXCLUDED.expires_at,
+    updated_at = now();
+
+-- name: SyncWarehouseIDSequence :exec
+-- Moves the warehouse sequence past the fixture IDs so later creates do
+-- not collide with them
+SELECT setval(pg_get_serial_sequence('warehouse', 'id'), (SELECT COALESCE(max(id), 1) FROM warehouse));
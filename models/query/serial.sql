@@ -0,0 +1,42 @@
+-- name: CreateSerial :one
+INSERT INTO serial (
+    org_id, serial_number, sku, storage_room_id
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetSerialByNumber :one
+SELECT * FROM serial
+WHERE org_id = $1 AND serial_number = $2;
+
+-- name: LockSerials :many
+SELECT * FROM serial
+WHERE org_id = sqlc.arg('org_id') AND serial_number = ANY(sqlc.arg('serial_numbers')::varchar[])
+ORDER BY id
+FOR UPDATE;
+
+-- name: UpdateSerialLocation :one
+UPDATE serial
+SET storage_room_id = $2,
+    status = $3,
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: CreateSerialMovement :exec
+INSERT INTO serial_movement (
+    serial_id, action, from_storage_room_id, to_storage_room_id, reference, actor
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+);
+
+-- name: ListSerialMovements :many
+SELECT * FROM serial_movement
+WHERE serial_id = $1
+ORDER BY id;
+
+-- name: LockSerialsInStorageRoom :many
+SELECT * FROM serial
+WHERE org_id = $1 AND storage_room_id = $2 AND status = 'in_stock'
+ORDER BY id
+FOR UPDATE;
@@ -0,0 +1,17 @@
+-- name: GetTenantSetting :one
+SELECT * FROM tenant_setting
+WHERE org_id = $1;
+
+-- name: UpsertTenantSetting :one
+INSERT INTO tenant_setting (
+    org_id, valuation_method, edi_qualifier, edi_id, updated_by
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+ON CONFLICT (org_id) DO UPDATE
+SET valuation_method = EXCLUDED.valuation_method,
+    edi_qualifier = EXCLUDED.edi_qualifier,
+    edi_id = EXCLUDED.edi_id,
+    updated_by = EXCLUDED.updated_by,
+    updated_at = now()
+RETURNING *;
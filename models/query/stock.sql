@@ -0,0 +1,105 @@
+-- name: AdjustStockLevel :one
+-- A level expires with the earliest stock it holds; once emptied the
+-- expiry of the stock put in next replaces it
+INSERT INTO stock_level (
+    org_id, storage_room_id, sku, quantity, expires_at
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+ON CONFLICT (org_id, storage_room_id, sku)
+DO UPDATE SET quantity = stock_level.quantity + EXCLUDED.quantity,
+    expires_at = CASE WHEN stock_level.quantity = 0 THEN EXCLUDED.expires_at
+        ELSE LEAST(stock_level.expires_at, EXCLUDED.expires_at) END,
+    updated_at = now()
+RETURNING *;
+
+-- name: CreateStockAdjustment :one
+INSERT INTO stock_adjustment (
+    org_id, storage_room_id, sku, quantity_delta, reason, reference
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: ListStockForAllocationFIFO :many
+SELECT stock_level.*
+FROM stock_level
+JOIN storage_room ON storage_room.id = stock_level.storage_room_id
+WHERE stock_level.org_id = $1
+  AND storage_room.warehouse_id = $2
+  AND stock_level.sku = $3
+  AND stock_level.quantity > stock_level.allocated_quantity
+ORDER BY stock_level.received_at, stock_level.id
+FOR UPDATE OF stock_level;
+
+-- name: ListStockForAllocationFEFO :many
+SELECT stock_level.*
+FROM stock_level
+JOIN storage_room ON storage_room.id = stock_level.storage_room_id
+WHERE stock_level.org_id = $1
+  AND storage_room.warehouse_id = $2
+  AND stock_level.sku = $3
+  AND stock_level.quantity > stock_level.allocated_quantity
+ORDER BY stock_level.expires_at NULLS LAST, stock_level.received_at, stock_level.id
+FOR UPDATE OF stock_level;
+
+-- name: AllocateStockLevel :one
+UPDATE stock_level
+SET allocated_quantity = allocated_quantity + $2,
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: ReleaseStockAllocation :one
+UPDATE stock_level
+SET allocated_quantity = allocated_quantity - $4,
+    updated_at = now()
+WHERE org_id = $1 AND storage_room_id = $2 AND sku = $3
+RETURNING *;
+
+-- name: ApplyStockDelta :one
+UPDATE stock_level
+SET quantity = quantity + $4,
+    updated_at = now()
+WHERE org_id = $1 AND storage_room_id = $2 AND sku = $3
+RETURNING *;
+
+-- name: ListStockLevelsPage :many
+SELECT * FROM stock_level
+WHERE org_id = sqlc.arg('org_id')
+  AND (sqlc.narg('sku')::varchar IS NULL OR sku = sqlc.narg('sku')::varchar)
+  AND id > sqlc.arg('after_id')::bigint
+ORDER BY id
+LIMIT sqlc.arg('page_limit');
+
+-- name: ListStockAdjustmentsPage :many
+SELECT * FROM stock_adjustment
+WHERE org_id = sqlc.arg('org_id')
+  AND (sqlc.narg('sku')::varchar IS NULL OR sku = sqlc.narg('sku')::varchar)
+  AND (
+    sqlc.narg('before_created_at')::timestamptz IS NULL
+    OR (created_at, id) < (sqlc.narg('before_created_at')::timestamptz, sqlc.arg('before_id')::bigint)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('page_limit');
+
+-- name: GetStorageRoomOccupancy :one
+SELECT COALESCE(sum(quantity), 0)::bigint AS occupancy
+FROM stock_level
+WHERE org_id = $1 AND storage_room_id = $2;
+
+-- name: ListStorageRoomOccupancy :many
+SELECT storage_room_id, sum(quantity)::bigint AS occupancy
+FROM stock_level
+WHERE org_id = $1 AND storage_room_id = ANY($2::int[])
+GROUP BY storage_room_id;
+
+-- name: LockStorageRoomStock :many
+SELECT * FROM stock_level
+WHERE org_id = $1 AND storage_room_id = $2 AND quantity > 0
+ORDER BY sku
+FOR UPDATE;
+
+-- name: DeleteEmptyStockLevels :execrows
+DELETE FROM stock_level
+WHERE org_id = $1 AND storage_room_id = $2
+  AND quantity = 0 AND allocated_quantity = 0;
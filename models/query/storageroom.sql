@@ -1,8 +1,8 @@
 -- name: CreateStorageRoom :one
 INSERT INTO storage_room (
-    name, number, warehouse_id
+    name, number, warehouse_id, org_id
 ) VALUES (
-    $1, $2, $3
+    $1, $2, $3, $4
 ) RETURNING *;
 
 -- name: UpdateStorageRoom :one
@@ -10,18 +10,117 @@ UPDATE storage_room
 SET name = $2,
     number = $3,
     warehouse_id= $4
-WHERE id = $1
+WHERE id = $1 AND org_id = $5
 RETURNING *;
 
 -- name: GetStorageRoom :one
 SELECT * FROM storage_room
-WHERE id = $1;
+WHERE id = $1 AND org_id = $2;
 
 -- name: ListStorageRoom :many
-SELECT id, name, number, warehouse_id
-FROM storage_room
-LIMIT $1 OFFSET $2;
+SELECT * FROM storage_room
+WHERE org_id = sqlc.arg('org_id')
+  AND (sqlc.narg('warehouse_id')::int IS NULL OR warehouse_id = sqlc.narg('warehouse_id')::int)
+  AND (sqlc.narg('tags')::text[] IS NULL OR tags @> sqlc.narg('tags')::text[])
+ORDER BY id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
 
--- name: DeleteStorageRoom :exec
+-- name: DeleteStorageRoom :execrows
 DELETE FROM storage_room
-WHERE id = $1;
+WHERE id = $1 AND org_id = $2;
+
+-- name: GetStorageRoomLabel :one
+SELECT storage_room.id, storage_room.name, storage_room.number, storage_room.warehouse_id, warehouse.name AS warehouse_name
+FROM storage_room
+JOIN warehouse ON warehouse.id = storage_room.warehouse_id
+WHERE storage_room.id = $1 AND storage_room.org_id = $2;
+
+-- name: GetStorageRoomLabelByLocation :one
+SELECT storage_room.id, storage_room.name, storage_room.number, storage_room.warehouse_id, warehouse.name AS warehouse_name
+FROM storage_room
+JOIN warehouse ON warehouse.id = storage_room.warehouse_id
+WHERE storage_room.warehouse_id = $1 AND storage_room.number = $2 AND storage_room.org_id = $3;
+
+-- name: CountStorageRoomsByTenant :many
+SELECT org_id, count(*) AS count
+FROM storage_room
+GROUP BY org_id;
+
+-- name: PatchStorageRoom :one
+UPDATE storage_room
+SET name = COALESCE(sqlc.narg('name'), name),
+    number = COALESCE(sqlc.narg('number'), number),
+    warehouse_id = COALESCE(sqlc.narg('warehouse_id'), warehouse_id),
+    attributes = COALESCE(sqlc.narg('attributes'), attributes),
+    zone_type = COALESCE(sqlc.narg('zone_type'), zone_type),
+    tags = COALESCE(sqlc.narg('tags'), tags),
+    capacity = COALESCE(sqlc.narg('capacity'), capacity),
+    aisle = COALESCE(sqlc.narg('aisle'), aisle),
+    bay = COALESCE(sqlc.narg('bay'), bay)
+WHERE id = sqlc.arg('id') AND org_id = sqlc.arg('org_id')
+RETURNING *;
+
+-- name: GetStorageRoomsByIDs :many
+SELECT * FROM storage_room
+WHERE org_id = $1 AND id = ANY($2::int[]);
+
+-- name: CountStorageRoomsInWarehouse :one
+SELECT count(*) FROM storage_room
+WHERE warehouse_id = $1 AND org_id = $2;
+
+-- name: ListStorageRoomsInWarehouse :many
+SELECT * FROM storage_room
+WHERE warehouse_id = $1 AND org_id = $2
+ORDER BY id
+LIMIT $3;
+
+-- name: ListStorageRoomIDsInWarehouse :many
+SELECT id FROM storage_room
+WHERE warehouse_id = $1 AND org_id = $2
+ORDER BY id;
+
+-- name: GetFullestWarehouse :one
+SELECT warehouse_id, count(*)::bigint AS storage_rooms
+FROM storage_room
+WHERE org_id = $1
+GROUP BY warehouse_id
+ORDER BY storage_rooms DESC, warehouse_id
+LIMIT 1;
+
+-- name: AddStorageRoomTags :one
+UPDATE storage_room
+SET tags = tags || ARRAY(
+    SELECT t FROM unnest(sqlc.arg('tags')::text[]) WITH ORDINALITY AS n(t, i)
+    WHERE t <> ALL(storage_room.tags)
+    ORDER BY i
+)
+WHERE id = sqlc.arg('id') AND org_id = sqlc.arg('org_id')
+RETURNING *;
+
+-- name: RemoveStorageRoomTags :one
+UPDATE storage_room
+SET tags = ARRAY(
+    SELECT t FROM unnest(tags) WITH ORDINALITY AS n(t, i)
+    WHERE t <> ALL(sqlc.arg('tags')::text[])
+    ORDER BY i
+)
+WHERE id = sqlc.arg('id') AND org_id = sqlc.arg('org_id')
+RETURNING *;
+
+-- name: LockStorageRoomCapacity :one
+SELECT capacity FROM storage_room
+WHERE id = $1 AND org_id = $2
+FOR NO KEY UPDATE;
+
+-- name: ListOccupancyByStorageRoom :many
+SELECT storage_room.org_id, storage_room.id, storage_room.capacity,
+    COALESCE(sum(stock_level.quantity), 0)::bigint AS occupancy
+FROM storage_room
+LEFT JOIN stock_level ON stock_level.storage_room_id = storage_room.id
+GROUP BY storage_room.id;
+
+-- name: AssignUnownedStorageRooms :execrows
+UPDATE storage_room SET org_id = warehouse.org_id
+FROM warehouse
+WHERE storage_room.warehouse_id = warehouse.id
+    AND storage_room.org_id = '' AND warehouse.org_id <> '';
@@ -0,0 +1,41 @@
+-- name: InsertTemperatureReadings :execrows
+INSERT INTO temperature_reading (
+    org_id, storage_room_id, sensor_id, celsius, recorded_at
+)
+SELECT sqlc.arg('org_id'),
+    unnest(sqlc.arg('storage_room_ids')::int[]),
+    unnest(sqlc.arg('sensor_ids')::text[]),
+    unnest(sqlc.arg('celsius')::float8[]),
+    unnest(sqlc.arg('recorded_at')::timestamptz[])
+ON CONFLICT DO NOTHING;
+
+-- name: GetOpenTemperatureBreach :one
+SELECT * FROM temperature_breach
+WHERE storage_room_id = $1 AND org_id = $2 AND resolved_at IS NULL;
+
+-- name: CreateTemperatureBreach :one
+INSERT INTO temperature_breach (
+    org_id, storage_room_id, zone_type, min_celsius, max_celsius, peak_celsius, started_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+)
+ON CONFLICT (storage_room_id) WHERE resolved_at IS NULL DO NOTHING
+RETURNING *;
+
+-- name: UpdateTemperatureBreachPeak :exec
+UPDATE temperature_breach
+SET peak_celsius = $2
+WHERE id = $1;
+
+-- name: ResolveTemperatureBreach :exec
+UPDATE temperature_breach
+SET resolved_at = $2
+WHERE id = $1 AND resolved_at IS NULL;
+
+-- name: ListTemperaturePartitions :many
+SELECT child.relname::text AS name
+FROM pg_inherits
+JOIN pg_class parent ON parent.oid = pg_inherits.inhparent
+JOIN pg_class child ON child.oid = pg_inherits.inhrelid
+WHERE parent.relname = 'temperature_reading'
+ORDER BY child.relname;
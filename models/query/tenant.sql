@@ -0,0 +1,14 @@
+-- name: ListTenants :many
+-- Organizations known to the service, from their warehouses and API keys
+SELECT t.org_id,
+       (SELECT count(*) FROM warehouse w WHERE w.org_id = t.org_id)::bigint AS warehouses,
+       (SELECT count(*) FROM api_key k WHERE k.org_id = t.org_id AND k.revoked_at IS NULL)::bigint AS active_api_keys,
+       (SELECT count(*) FROM job j WHERE j.org_id = t.org_id AND j.status IN ('queued', 'running'))::bigint AS pending_jobs,
+       (SELECT count(*) FROM outbox o WHERE o.org_id = t.org_id AND o.delivered_at IS NULL)::bigint AS pending_outbox_messages
+FROM (
+    SELECT warehouse.org_id FROM warehouse
+    UNION
+    SELECT api_key.org_id FROM api_key
+) t
+ORDER BY t.org_id
+LIMIT $1 OFFSET $2;
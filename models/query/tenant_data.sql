@@ -0,0 +1,76 @@
+-- name: CancelTenantDeletion :one
+UPDATE tenant_deletion
+SET status = 'cancelled',
+    completed_at = now()
+WHERE org_id = $1 AND status = 'scheduled'
+RETURNING *;
+
+-- name: CompleteTenantExport :one
+UPDATE tenant_export
+SET status = 'completed',
+    object_key = $2,
+    size_bytes = $3,
+    checksum = $4,
+    tables = $5,
+    completed_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: CreateTenantDeletion :one
+INSERT INTO tenant_deletion (
+    org_id, mode, run_after, requested_by
+) VALUES (
+    $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: CreateTenantExport :one
+INSERT INTO tenant_export (
+    org_id, format, requested_by
+) VALUES (
+    $1, $2, $3
+)
+RETURNING *;
+
+-- name: FailTenantExport :exec
+UPDATE tenant_export
+SET status = 'failed',
+    error = $2,
+    completed_at = now()
+WHERE id = $1;
+
+-- name: FinishTenantDeletion :one
+UPDATE tenant_deletion
+SET status = $2,
+    report = $3,
+    error = $4,
+    completed_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: GetLatestTenantDeletion :one
+SELECT * FROM tenant_deletion
+WHERE org_id = $1
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: GetTenantExport :one
+SELECT * FROM tenant_export
+WHERE id = $1 AND org_id = $2;
+
+-- name: ListTenantObjectKeys :many
+-- Objects holding the tenant's files: attachments and export archives
+SELECT object_key FROM attachment
+WHERE org_id = $1
+UNION ALL
+SELECT object_key FROM tenant_export
+WHERE org_id = $1 AND object_key <> '';
+
+-- name: StartTenantDeletion :one
+-- Claims a deletion for its job, none is returned once it was cancelled or
+-- finished. An anonymization keeps the pseudonym of its first run.
+UPDATE tenant_deletion
+SET status = 'running',
+    pseudonym = CASE WHEN mode = 'anonymize' AND pseudonym = '' THEN $2 ELSE pseudonym END
+WHERE id = $1 AND status IN ('scheduled', 'running')
+RETURNING *;
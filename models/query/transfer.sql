@@ -0,0 +1,59 @@
+-- name: CreateTransferOrder :one
+INSERT INTO transfer_order (
+    org_id, source_warehouse_id, destination_warehouse_id, reference
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetTransferOrder :one
+SELECT * FROM transfer_order
+WHERE id = $1 AND org_id = $2;
+
+-- name: GetTransferOrderForUpdate :one
+SELECT * FROM transfer_order
+WHERE id = $1 AND org_id = $2
+FOR UPDATE;
+
+-- name: UpdateTransferOrderStatus :one
+UPDATE transfer_order
+SET status = sqlc.arg('status'),
+    updated_at = now(),
+    shipped_at = CASE WHEN sqlc.arg('status') = 'in_transit' THEN now() ELSE shipped_at END,
+    received_at = CASE WHEN sqlc.arg('status') = 'received' THEN now() ELSE received_at END
+WHERE id = sqlc.arg('id') AND org_id = sqlc.arg('org_id')
+RETURNING *;
+
+-- name: CreateTransferOrderLine :one
+INSERT INTO transfer_order_line (
+    transfer_order_id, sku, quantity
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: ListTransferOrderLines :many
+SELECT * FROM transfer_order_line
+WHERE transfer_order_id = $1
+ORDER BY id;
+
+-- name: ShipTransferOrderLine :one
+UPDATE transfer_order_line
+SET source_storage_room_id = $3,
+    shipped_quantity = quantity
+WHERE id = $1 AND transfer_order_id = $2
+RETURNING *;
+
+-- name: ReceiveTransferOrderLine :one
+UPDATE transfer_order_line
+SET received_quantity = received_quantity + $3
+WHERE id = $1 AND transfer_order_id = $2
+RETURNING *;
+
+-- name: ListInTransitStock :many
+SELECT transfer_order.destination_warehouse_id, transfer_order_line.sku,
+    sum(transfer_order_line.shipped_quantity - transfer_order_line.received_quantity)::bigint AS quantity
+FROM transfer_order
+JOIN transfer_order_line ON transfer_order_line.transfer_order_id = transfer_order.id
+WHERE transfer_order.org_id = $1 AND transfer_order.status = 'in_transit'
+GROUP BY transfer_order.destination_warehouse_id, transfer_order_line.sku
+HAVING sum(transfer_order_line.shipped_quantity - transfer_order_line.received_quantity) > 0
+ORDER BY transfer_order.destination_warehouse_id, transfer_order_line.sku;
@@ -0,0 +1,22 @@
+-- name: RecordAPICall :many
+-- Counts a call for the tenant and for the caller, returning both totals
+INSERT INTO api_usage (org_id, day, user_id, calls)
+VALUES (sqlc.arg('org_id'), sqlc.arg('day'), '', 1),
+       (sqlc.arg('org_id'), sqlc.arg('day'), sqlc.arg('user_id'), 1)
+ON CONFLICT (org_id, day, user_id) DO UPDATE
+SET calls = api_usage.calls + 1
+RETURNING user_id, calls;
+
+-- name: GetAPIUsage :one
+SELECT COALESCE(sum(calls) FILTER (WHERE user_id = ''), 0)::bigint AS tenant_calls,
+       COALESCE(sum(calls) FILTER (WHERE user_id = sqlc.arg('user_id')), 0)::bigint AS user_calls
+FROM api_usage
+WHERE org_id = sqlc.arg('org_id') AND day = sqlc.arg('day');
+
+-- name: DeleteAPIUsageBefore :execrows
+DELETE FROM api_usage
+WHERE day < $1;
+
+-- name: LockQuota :exec
+-- Serializes quota checks on key until the transaction ends
+SELECT pg_advisory_xact_lock(hashtextextended(sqlc.arg('key')::text, 0));
@@ -0,0 +1,26 @@
+-- name: ListCostLayers :many
+-- Received quantities of the costed receipt lines per warehouse and SKU,
+-- oldest receipt first
+SELECT receipt.warehouse_id, receipt_line.sku,
+       receipt_line.received_quantity::bigint AS quantity,
+       receipt_line.unit_cost_cents::bigint AS unit_cost_cents
+FROM receipt_line
+JOIN receipt ON receipt.id = receipt_line.receipt_id
+WHERE receipt.org_id = sqlc.arg('org_id')
+  AND receipt_line.unit_cost_cents IS NOT NULL
+  AND receipt_line.received_quantity > 0
+  AND (sqlc.narg('warehouse_id')::bigint IS NULL OR receipt.warehouse_id = sqlc.narg('warehouse_id')::bigint)
+ORDER BY receipt.warehouse_id, receipt_line.sku, receipt.id, receipt_line.id;
+
+-- name: ListStockOnHand :many
+-- On-hand quantity per warehouse and SKU
+SELECT warehouse.id AS warehouse_id, warehouse.name AS warehouse_name, stock_level.sku,
+       sum(stock_level.quantity)::bigint AS quantity
+FROM stock_level
+JOIN storage_room ON storage_room.id = stock_level.storage_room_id
+JOIN warehouse ON warehouse.id = storage_room.warehouse_id
+WHERE stock_level.org_id = sqlc.arg('org_id')
+  AND stock_level.quantity > 0
+  AND (sqlc.narg('warehouse_id')::bigint IS NULL OR warehouse.id = sqlc.narg('warehouse_id')::bigint)
+GROUP BY warehouse.id, stock_level.sku
+ORDER BY warehouse.id, stock_level.sku;
@@ -1,8 +1,10 @@
 -- name: CreateWarehouse :one
 INSERT INTO warehouse (
-    name, address, ward, district, city, country
+    name, address, ward, district, city, country, org_id, latitude, longitude,
+    time_zone, operating_hours, contact_email, contact_phone, tags, attributes, status, slug
 ) VALUES (
-    $1, $2, $3, $4, $5, $6
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15,
+    COALESCE(sqlc.narg('status'), 'active'), sqlc.arg('slug')
 ) RETURNING *;
 
 -- name: UpdateWarehouse :one
@@ -12,19 +14,140 @@ SET name = $2,
     ward = $4,
     district = $5,
     city = $6,
-    country = $7
-WHERE id = $1
+    country = $7,
+    latitude = $9,
+    longitude = $10,
+    time_zone = $11,
+    operating_hours = $12,
+    contact_email = $13,
+    contact_phone = $14,
+    tags = $15,
+    attributes = $16
+WHERE id = $1 AND org_id = $8
 RETURNING *;
 
 -- name: GetWarehouse :one
 SELECT * FROM warehouse
-WHERE id = $1;
+WHERE id = $1 AND org_id = $2;
+
+-- name: GetWarehouseIDBySlug :one
+SELECT id FROM warehouse
+WHERE org_id = $1 AND slug = $2;
+
+-- name: ListWarehouseSlugs :many
+-- Slugs of the tenant's warehouses that are base or base with a suffix
+SELECT slug FROM warehouse
+WHERE org_id = sqlc.arg('org_id')
+  AND (slug = sqlc.arg('base')::text OR slug LIKE sqlc.arg('base')::text || '-%');
+
+-- name: GetWarehouseForUpdate :one
+SELECT * FROM warehouse
+WHERE id = $1 AND org_id = $2
+FOR UPDATE;
 
 -- name: ListWarehouse :many
-SELECT id, name, address, ward, district, city, country
-FROM warehouse
-LIMIT $1 OFFSET $2;
+SELECT * FROM warehouse
+WHERE org_id = sqlc.arg('org_id')
+  AND (sqlc.narg('tags')::text[] IS NULL OR tags @> sqlc.narg('tags')::text[])
+  AND (sqlc.narg('time_zone')::text IS NULL OR time_zone = sqlc.narg('time_zone')::text)
+  AND (sqlc.narg('attributes')::jsonb IS NULL OR attributes @> sqlc.narg('attributes')::jsonb)
+  AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status')::text)
+ORDER BY id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
 
--- name: DeleteWarehouse :exec
+-- name: DeleteWarehouse :execrows
 DELETE FROM warehouse
-WHERE id = $1;
+WHERE id = $1 AND org_id = $2;
+
+-- name: CountWarehousesByTenant :many
+SELECT org_id, count(*) AS count
+FROM warehouse
+GROUP BY org_id;
+
+-- name: CountWarehousesForTenant :one
+SELECT count(*) FROM warehouse
+WHERE org_id = $1;
+
+-- name: PatchWarehouse :one
+UPDATE warehouse
+SET name = COALESCE(sqlc.narg('name'), name),
+    address = COALESCE(sqlc.narg('address'), address),
+    ward = COALESCE(sqlc.narg('ward'), ward),
+    district = COALESCE(sqlc.narg('district'), district),
+    city = COALESCE(sqlc.narg('city'), city),
+    country = COALESCE(sqlc.narg('country'), country),
+    latitude = COALESCE(sqlc.narg('latitude'), latitude),
+    longitude = COALESCE(sqlc.narg('longitude'), longitude),
+    time_zone = COALESCE(sqlc.narg('time_zone'), time_zone),
+    operating_hours = COALESCE(sqlc.narg('operating_hours'), operating_hours),
+    contact_email = NULLIF(COALESCE(sqlc.narg('contact_email'), contact_email), ''),
+    contact_phone = NULLIF(COALESCE(sqlc.narg('contact_phone'), contact_phone), ''),
+    tags = COALESCE(sqlc.narg('tags'), tags),
+    attributes = COALESCE(sqlc.narg('attributes'), attributes)
+WHERE id = sqlc.arg('id') AND org_id = sqlc.arg('org_id')
+RETURNING *;
+
+-- name: ListNearbyWarehouses :many
+SELECT w.*,
+    earth_distance(
+        ll_to_earth(w.latitude, w.longitude),
+        ll_to_earth(sqlc.arg('lat')::float8, sqlc.arg('lng')::float8)
+    )::float8 AS distance
+FROM warehouse w
+WHERE w.org_id = sqlc.arg('org_id')
+  AND w.latitude IS NOT NULL
+  AND earth_box(ll_to_earth(sqlc.arg('lat')::float8, sqlc.arg('lng')::float8), sqlc.arg('radius')::float8)
+      @> ll_to_earth(w.latitude, w.longitude)
+  AND earth_distance(
+        ll_to_earth(w.latitude, w.longitude),
+        ll_to_earth(sqlc.arg('lat')::float8, sqlc.arg('lng')::float8)
+    ) <= sqlc.arg('radius')::float8
+ORDER BY distance
+LIMIT sqlc.arg('page_limit');
+
+-- name: FindDuplicateWarehouses :many
+-- Warehouses of the tenant whose name and full address are both similar to
+-- those of a new warehouse, by trigram similarity
+SELECT w.*,
+    similarity(lower(w.name), lower(sqlc.arg('name')::text))::float8 AS name_similarity,
+    similarity(lower(concat_ws(' ', w.address, w.ward, w.district, w.city, w.country)), lower(sqlc.arg('address')::text))::float8 AS address_similarity
+FROM warehouse w
+WHERE w.org_id = sqlc.arg('org_id')
+  AND similarity(lower(w.name), lower(sqlc.arg('name')::text)) >= sqlc.arg('name_threshold')::float8
+  AND similarity(lower(concat_ws(' ', w.address, w.ward, w.district, w.city, w.country)), lower(sqlc.arg('address')::text)) >= sqlc.arg('address_threshold')::float8
+ORDER BY name_similarity + address_similarity DESC, w.id
+LIMIT sqlc.arg('page_limit');
+
+-- name: GetWarehousesByIDs :many
+SELECT * FROM warehouse
+WHERE org_id = $1 AND id = ANY($2::bigint[]);
+
+-- name: UpdateWarehouseStatus :one
+UPDATE warehouse
+SET status = $3
+WHERE id = $1 AND org_id = $2
+RETURNING *;
+
+-- name: AddWarehouseTags :one
+UPDATE warehouse
+SET tags = tags || ARRAY(
+    SELECT t FROM unnest(sqlc.arg('tags')::text[]) WITH ORDINALITY AS n(t, i)
+    WHERE t <> ALL(warehouse.tags)
+    ORDER BY i
+)
+WHERE id = sqlc.arg('id') AND org_id = sqlc.arg('org_id')
+RETURNING *;
+
+-- name: RemoveWarehouseTags :one
+UPDATE warehouse
+SET tags = ARRAY(
+    SELECT t FROM unnest(tags) WITH ORDINALITY AS n(t, i)
+    WHERE t <> ALL(sqlc.arg('tags')::text[])
+    ORDER BY i
+)
+WHERE id = sqlc.arg('id') AND org_id = sqlc.arg('org_id')
+RETURNING *;
+
+-- name: AssignUnownedWarehouses :execrows
+UPDATE warehouse SET org_id = $1
+WHERE org_id = '';
@@ -0,0 +1,39 @@
+-- name: CreateWave :one
+INSERT INTO wave (
+    org_id, warehouse_id, reference
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: GetWave :one
+SELECT * FROM wave
+WHERE id = $1 AND org_id = $2;
+
+-- name: LockPickLists :many
+SELECT * FROM pick_list
+WHERE org_id = sqlc.arg('org_id') AND id = ANY(sqlc.arg('ids')::bigint[])
+ORDER BY id
+FOR UPDATE;
+
+-- name: AddPickListsToWave :exec
+INSERT INTO wave_pick_list (pick_list_id, wave_id)
+SELECT unnest(sqlc.arg('pick_list_ids')::bigint[]), sqlc.arg('wave_id')::bigint;
+
+-- name: ListWavePickLists :many
+SELECT pick_list.* FROM pick_list
+JOIN wave_pick_list ON wave_pick_list.pick_list_id = pick_list.id
+WHERE wave_pick_list.wave_id = $1
+ORDER BY pick_list.id;
+
+-- name: ListWavePicks :many
+SELECT pick_list_line.id, pick_list_line.pick_list_id, pick_list_line.sku, pick_list_line.storage_room_id,
+    pick_list_line.quantity, pick_list_line.picked_quantity,
+    storage_room.number, storage_room.zone_type, storage_room.aisle, storage_room.bay
+FROM wave_pick_list
+JOIN pick_list ON pick_list.id = wave_pick_list.pick_list_id
+JOIN pick_list_line ON pick_list_line.pick_list_id = pick_list.id
+JOIN storage_room ON storage_room.id = pick_list_line.storage_room_id
+WHERE wave_pick_list.wave_id = $1
+  AND pick_list.status = 'allocated'
+  AND pick_list_line.picked_quantity < pick_list_line.quantity
+ORDER BY pick_list_line.id;
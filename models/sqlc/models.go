@@ -4,19 +4,597 @@
 
 package models
 
+import (
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type ApiKey struct {
+	ID         int64
+	OrgID      string
+	Name       string
+	Prefix     string
+	SecretHash []byte
+	CreatedBy  string
+	ExpiresAt  pgtype.Timestamptz
+	RevokedAt  pgtype.Timestamptz
+	LastUsedAt pgtype.Timestamptz
+	CreatedAt  pgtype.Timestamptz
+}
+
+type ApiUsage struct {
+	OrgID  string
+	Day    pgtype.Date
+	UserID string
+	Calls  int64
+}
+
+type Attachment struct {
+	ID          int64
+	OrgID       string
+	WarehouseID int64
+	Kind        string
+	FileName    string
+	ContentType string
+	SizeBytes   int64
+	Checksum    string
+	ObjectKey   string
+	UploadedBy  string
+	CreatedAt   pgtype.Timestamptz
+}
+
+type AttributeSchema struct {
+	OrgID      string
+	EntityType string
+	Schema     []byte
+	UpdatedBy  string
+	UpdatedAt  pgtype.Timestamptz
+}
+
+type AuditLog struct {
+	ID         int64
+	OrgID      string
+	EntityType string
+	EntityID   int64
+	Action     string
+	FromStatus string
+	ToStatus   string
+	Actor      string
+	CreatedAt  pgtype.Timestamptz
+}
+
+type ChangeEvent struct {
+	ID        int64
+	OrgID     string
+	Entity    string
+	EntityID  int64
+	Operation string
+	Data      []byte
+	Txid      int64
+	CreatedAt pgtype.Timestamptz
+}
+
+type CountLine struct {
+	ID              int64
+	CountSessionID  int64
+	StorageRoomID   int32
+	Sku             string
+	BookQuantity    int32
+	CountedQuantity pgtype.Int4
+	Approved        bool
+}
+
+type CountSession struct {
+	ID            int64
+	OrgID         string
+	WarehouseID   int64
+	StorageRoomID pgtype.Int4
+	Status        string
+	CreatedAt     pgtype.Timestamptz
+	UpdatedAt     pgtype.Timestamptz
+}
+
+type DashboardStat struct {
+	OrgID               string
+	Warehouses          int64
+	ActiveWarehouses    int64
+	StorageRooms        int64
+	Skus                int64
+	OnHandQuantity      int64
+	AllocatedQuantity   int64
+	OpenReceipts        int64
+	OpenPickLists       int64
+	MovementsLast30Days int64
+}
+
+type DeadLetter struct {
+	ID         int64
+	OrgID      string
+	Source     string
+	SourceID   int64
+	Topic      string
+	Key        string
+	Payload    []byte
+	Attempts   int32
+	LastError  string
+	CreatedAt  pgtype.Timestamptz
+	FailedAt   pgtype.Timestamptz
+	ReplayedAt pgtype.Timestamptz
+	ReplayedBy string
+}
+
+type EdiDocument struct {
+	ID                 int64
+	OrgID              string
+	PartnerID          pgtype.Int8
+	Direction          string
+	DocumentType       string
+	SenderID           string
+	ControlNumber      string
+	TransactionControl string
+	ReceiptID          pgtype.Int8
+	Status             string
+	Error              string
+	CreatedAt          pgtype.Timestamptz
+	UpdatedAt          pgtype.Timestamptz
+}
+
+type FileExchange struct {
+	ID            int64
+	OrgID         string
+	Name          string
+	InboundUrl    string
+	OutboundUrl   string
+	HostKey       string
+	WarehouseID   int64
+	SupplierID    pgtype.Int8
+	ExtractFormat string
+	Enabled       bool
+	CreatedAt     pgtype.Timestamptz
+	UpdatedAt     pgtype.Timestamptz
+}
+
+type FileExchangeContent struct {
+	FileID  int64
+	Content []byte
+}
+
+type FileExchangeFile struct {
+	ID         int64
+	OrgID      string
+	ExchangeID int64
+	RunID      int64
+	Direction  string
+	Name       string
+	Format     string
+	Sha256     string
+	Size       int32
+	Status     string
+	Error      string
+	CreatedAt  pgtype.Timestamptz
+	UpdatedAt  pgtype.Timestamptz
+}
+
+type FileExchangeRun struct {
+	ID             int64
+	OrgID          string
+	ExchangeID     int64
+	Trigger        string
+	Status         string
+	FilesProcessed int32
+	FilesFailed    int32
+	Error          string
+	StartedAt      pgtype.Timestamptz
+	FinishedAt     pgtype.Timestamptz
+}
+
+type Integration struct {
+	ID             int64
+	OrgID          string
+	Name           string
+	Kind           string
+	Url            string
+	OrdersUrl      string
+	Token          string
+	LocationID     string
+	WarehouseID    int64
+	Enabled        bool
+	OrdersSyncedAt pgtype.Timestamptz
+	LastSyncedAt   pgtype.Timestamptz
+	Failures       int32
+	LastError      string
+	NextSyncAt     pgtype.Timestamptz
+	CreatedAt      pgtype.Timestamptz
+	UpdatedAt      pgtype.Timestamptz
+}
+
+type IntegrationOrder struct {
+	ID                int64
+	OrgID             string
+	IntegrationID     int64
+	ExternalID        string
+	Reference         string
+	Lines             []byte
+	Status            string
+	PickListID        pgtype.Int8
+	Error             string
+	ExternalUpdatedAt pgtype.Timestamptz
+	CreatedAt         pgtype.Timestamptz
+	UpdatedAt         pgtype.Timestamptz
+}
+
+type IntegrationStock struct {
+	IntegrationID int64
+	Sku           string
+	Available     int64
+	PushedAt      pgtype.Timestamptz
+}
+
+type Item struct {
+	ID          int64
+	OrgID       string
+	Sku         string
+	Description string
+	BaseUnit    string
+	LengthCm    pgtype.Float8
+	WidthCm     pgtype.Float8
+	HeightCm    pgtype.Float8
+	WeightKg    pgtype.Float8
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+}
+
+type ItemUnit struct {
+	ItemID int64
+	Unit   string
+	Factor int32
+}
+
+type Job struct {
+	ID          int64
+	OrgID       string
+	Kind        string
+	Payload     []byte
+	Status      string
+	Attempts    int32
+	MaxAttempts int32
+	LastError   string
+	RunAt       pgtype.Timestamptz
+	LockedAt    pgtype.Timestamptz
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+}
+
+type MaterializedViewRefresh struct {
+	Name        string
+	RefreshedAt pgtype.Timestamptz
+	DurationMs  int64
+}
+
+type Outbox struct {
+	ID            int64
+	OrgID         string
+	Topic         string
+	Key           string
+	Payload       []byte
+	Attempts      int32
+	LastError     string
+	NextAttemptAt pgtype.Timestamptz
+	DeliveredAt   pgtype.Timestamptz
+	CreatedAt     pgtype.Timestamptz
+}
+
+type Partner struct {
+	ID           int64
+	OrgID        string
+	Kind         string
+	Name         string
+	ContactName  string
+	Email        string
+	Phone        string
+	LeadTimeDays int32
+	CreatedAt    pgtype.Timestamptz
+	UpdatedAt    pgtype.Timestamptz
+	EdiQualifier string
+	EdiID        string
+	SftpUrl      string
+	SftpHostKey  string
+}
+
+type PickList struct {
+	ID          int64
+	OrgID       string
+	WarehouseID int64
+	Reference   string
+	Strategy    string
+	Status      string
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+	CarrierID   pgtype.Int8
+}
+
+type PickListLine struct {
+	ID             int64
+	PickListID     int64
+	Sku            string
+	StorageRoomID  int32
+	Quantity       int32
+	PickedQuantity int32
+}
+
+type Printer struct {
+	ID        int64
+	OrgID     string
+	Name      string
+	Address   string
+	Dpi       int32
+	CreatedAt pgtype.Timestamptz
+	UpdatedAt pgtype.Timestamptz
+}
+
+type ProcessedMessage struct {
+	Topic       string
+	MessageID   string
+	OrgID       string
+	Status      string
+	Error       string
+	ProcessedAt pgtype.Timestamptz
+}
+
+type Receipt struct {
+	ID          int64
+	OrgID       string
+	WarehouseID int64
+	Reference   string
+	Status      string
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+	SupplierID  pgtype.Int8
+}
+
+type ReceiptLine struct {
+	ID               int64
+	ReceiptID        int64
+	Sku              string
+	ExpectedQuantity int32
+	ReceivedQuantity int32
+	ExpiresAt        pgtype.Timestamptz
+	UnitCostCents    pgtype.Int8
+}
+
+type RowHistory struct {
+	ID        int64
+	OrgID     string
+	Entity    string
+	EntityID  int64
+	Operation string
+	Data      []byte
+	ValidFrom pgtype.Timestamptz
+	ValidTo   pgtype.Timestamptz
+}
+
+type Saga struct {
+	ID         int64
+	OrgID      string
+	Kind       string
+	PickListID int64
+	Status     string
+	Error      string
+	CreatedAt  pgtype.Timestamptz
+	UpdatedAt  pgtype.Timestamptz
+}
+
+type SagaStep struct {
+	SagaID    int64
+	Position  int32
+	Name      string
+	Status    string
+	Attempts  int32
+	Error     string
+	UpdatedAt pgtype.Timestamptz
+}
+
+type Serial struct {
+	ID            int64
+	OrgID         string
+	SerialNumber  string
+	Sku           string
+	StorageRoomID pgtype.Int4
+	Status        string
+	CreatedAt     pgtype.Timestamptz
+	UpdatedAt     pgtype.Timestamptz
+}
+
+type SerialMovement struct {
+	ID                int64
+	SerialID          int64
+	Action            string
+	FromStorageRoomID pgtype.Int4
+	ToStorageRoomID   pgtype.Int4
+	Reference         string
+	Actor             string
+	CreatedAt         pgtype.Timestamptz
+}
+
+type SkuConsumption struct {
+	OrgID           string
+	WarehouseID     int64
+	Sku             string
+	ShippedQuantity int64
+}
+
+type StockAdjustment struct {
+	ID            int64
+	OrgID         string
+	StorageRoomID int32
+	Sku           string
+	QuantityDelta int32
+	Reason        string
+	Reference     string
+	CreatedAt     pgtype.Timestamptz
+}
+
+type StockLevel struct {
+	ID                int64
+	OrgID             string
+	StorageRoomID     int32
+	Sku               string
+	Quantity          int32
+	UpdatedAt         pgtype.Timestamptz
+	AllocatedQuantity int32
+	ReceivedAt        pgtype.Timestamptz
+	ExpiresAt         pgtype.Timestamptz
+}
+
 type StorageRoom struct {
 	ID          int32
 	Name        string
 	Number      string
 	WarehouseID int32
+	OrgID       string
+	Attributes  []byte
+	ZoneType    string
+	Tags        []string
+	Capacity    int32
+	Aisle       int32
+	Bay         int32
+}
+
+type TemperatureBreach struct {
+	ID            int64
+	OrgID         string
+	StorageRoomID int32
+	ZoneType      string
+	MinCelsius    float64
+	MaxCelsius    float64
+	PeakCelsius   float64
+	StartedAt     pgtype.Timestamptz
+	ResolvedAt    pgtype.Timestamptz
+	CreatedAt     pgtype.Timestamptz
+}
+
+type TemperatureReading struct {
+	OrgID         string
+	StorageRoomID int32
+	SensorID      string
+	Celsius       float64
+	RecordedAt    pgtype.Timestamptz
+	ReceivedAt    pgtype.Timestamptz
+}
+
+type TenantDeletion struct {
+	ID          int64
+	OrgID       string
+	Mode        string
+	Status      string
+	RunAfter    pgtype.Timestamptz
+	Pseudonym   string
+	Report      []byte
+	Error       string
+	RequestedBy string
+	CreatedAt   pgtype.Timestamptz
+	CompletedAt pgtype.Timestamptz
+}
+
+type TenantExport struct {
+	ID          int64
+	OrgID       string
+	Format      string
+	Status      string
+	ObjectKey   string
+	SizeBytes   int64
+	Checksum    string
+	Tables      []byte
+	Error       string
+	RequestedBy string
+	CreatedAt   pgtype.Timestamptz
+	CompletedAt pgtype.Timestamptz
+}
+
+type TenantMetering struct {
+	OrgID           string
+	Day             pgtype.Date
+	ApiCalls        int64
+	Warehouses      int64
+	StorageRooms    int64
+	Items           int64
+	EventsDelivered int64
+	AggregatedAt    pgtype.Timestamptz
+}
+
+type TenantSetting struct {
+	OrgID           string
+	ValuationMethod string
+	UpdatedBy       string
+	UpdatedAt       pgtype.Timestamptz
+	EdiQualifier    string
+	EdiID           string
+}
+
+type TransferOrder struct {
+	ID                     int64
+	OrgID                  string
+	SourceWarehouseID      int64
+	DestinationWarehouseID int64
+	Reference              string
+	Status                 string
+	CreatedAt              pgtype.Timestamptz
+	UpdatedAt              pgtype.Timestamptz
+	ShippedAt              pgtype.Timestamptz
+	ReceivedAt             pgtype.Timestamptz
+}
+
+type TransferOrderLine struct {
+	ID                  int64
+	TransferOrderID     int64
+	Sku                 string
+	Quantity            int32
+	SourceStorageRoomID pgtype.Int4
+	ShippedQuantity     int32
+	ReceivedQuantity    int32
 }
 
 type Warehouse struct {
-	ID       int64
-	Name     string
-	Address  string
-	Ward     string
-	District string
-	City     string
-	Country  string
+	ID             int64
+	Name           string
+	Address        string
+	Ward           string
+	District       string
+	City           string
+	Country        string
+	OrgID          string
+	Latitude       pgtype.Float8
+	Longitude      pgtype.Float8
+	TimeZone       string
+	OperatingHours []byte
+	ContactEmail   pgtype.Text
+	ContactPhone   pgtype.Text
+	Tags           []string
+	Attributes     []byte
+	Status         string
+	Slug           string
+}
+
+type WarehouseAddress struct {
+	WarehouseID int64
+	OrgID       string
+	Address     string
+	Ward        string
+	District    string
+	City        string
+	Country     string
+	ValidatedAt pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+}
+
+type Wave struct {
+	ID          int64
+	OrgID       string
+	WarehouseID int64
+	Reference   string
+	CreatedAt   pgtype.Timestamptz
+}
+
+type WavePickList struct {
+	PickListID int64
+	WaveID     int64
 }
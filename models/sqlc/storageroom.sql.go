@@ -7,74 +7,473 @@ package models
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const AddStorageRoomTags = `-- name: AddStorageRoomTags :one
+UPDATE storage_room
+SET tags = tags || ARRAY(
+    SELECT t FROM unnest($1::text[]) WITH ORDINALITY AS n(t, i)
+    WHERE t <> ALL(storage_room.tags)
+    ORDER BY i
 )
+WHERE id = $2 AND org_id = $3
+RETURNING id, name, number, warehouse_id, org_id, attributes, zone_type, tags, capacity, aisle, bay
+`
+
+type AddStorageRoomTagsParams struct {
+	Tags  []string
+	ID    int32
+	OrgID string
+}
+
+func (q *Queries) AddStorageRoomTags(ctx context.Context, arg AddStorageRoomTagsParams) (StorageRoom, error) {
+	row := q.db.QueryRow(ctx, AddStorageRoomTags,
+		arg.Tags,
+		arg.ID,
+		arg.OrgID,
+	)
+	var i StorageRoom
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Number,
+		&i.WarehouseID,
+		&i.OrgID,
+		&i.Attributes,
+		&i.ZoneType,
+		&i.Tags,
+		&i.Capacity,
+		&i.Aisle,
+		&i.Bay,
+	)
+	return i, err
+}
+
+const AssignUnownedStorageRooms = `-- name: AssignUnownedStorageRooms :execrows
+UPDATE storage_room SET org_id = warehouse.org_id
+FROM warehouse
+WHERE storage_room.warehouse_id = warehouse.id
+    AND storage_room.org_id = '' AND warehouse.org_id <> ''
+`
+
+func (q *Queries) AssignUnownedStorageRooms(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, AssignUnownedStorageRooms)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const CountStorageRoomsByTenant = `-- name: CountStorageRoomsByTenant :many
+SELECT org_id, count(*) AS count
+FROM storage_room
+GROUP BY org_id
+`
 
-const createStorageRoom = `-- name: CreateStorageRoom :one
+type CountStorageRoomsByTenantRow struct {
+	OrgID string
+	Count int64
+}
+
+func (q *Queries) CountStorageRoomsByTenant(ctx context.Context) ([]CountStorageRoomsByTenantRow, error) {
+	rows, err := q.db.Query(ctx, CountStorageRoomsByTenant)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountStorageRoomsByTenantRow
+	for rows.Next() {
+		var i CountStorageRoomsByTenantRow
+		if err := rows.Scan(
+			&i.OrgID,
+			&i.Count,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const CountStorageRoomsInWarehouse = `-- name: CountStorageRoomsInWarehouse :one
+SELECT count(*) FROM storage_room
+WHERE warehouse_id = $1 AND org_id = $2
+`
+
+type CountStorageRoomsInWarehouseParams struct {
+	WarehouseID int32
+	OrgID       string
+}
+
+func (q *Queries) CountStorageRoomsInWarehouse(ctx context.Context, arg CountStorageRoomsInWarehouseParams) (int64, error) {
+	row := q.db.QueryRow(ctx, CountStorageRoomsInWarehouse, arg.WarehouseID, arg.OrgID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const CreateStorageRoom = `-- name: CreateStorageRoom :one
 INSERT INTO storage_room (
-    name, number, warehouse_id
+    name, number, warehouse_id, org_id
 ) VALUES (
-    $1, $2, $3
-) RETURNING id, name, number, warehouse_id
+    $1, $2, $3, $4
+) RETURNING id, name, number, warehouse_id, org_id, attributes, zone_type, tags, capacity, aisle, bay
 `
 
 type CreateStorageRoomParams struct {
 	Name        string
 	Number      string
 	WarehouseID int32
+	OrgID       string
 }
 
 func (q *Queries) CreateStorageRoom(ctx context.Context, arg CreateStorageRoomParams) (StorageRoom, error) {
-	row := q.db.QueryRow(ctx, createStorageRoom, arg.Name, arg.Number, arg.WarehouseID)
+	row := q.db.QueryRow(ctx, CreateStorageRoom,
+		arg.Name,
+		arg.Number,
+		arg.WarehouseID,
+		arg.OrgID,
+	)
 	var i StorageRoom
 	err := row.Scan(
 		&i.ID,
 		&i.Name,
 		&i.Number,
 		&i.WarehouseID,
+		&i.OrgID,
+		&i.Attributes,
+		&i.ZoneType,
+		&i.Tags,
+		&i.Capacity,
+		&i.Aisle,
+		&i.Bay,
 	)
 	return i, err
 }
 
-const deleteStorageRoom = `-- name: DeleteStorageRoom :exec
+const DeleteStorageRoom = `-- name: DeleteStorageRoom :execrows
 DELETE FROM storage_room
-WHERE id = $1
+WHERE id = $1 AND org_id = $2
 `
 
-func (q *Queries) DeleteStorageRoom(ctx context.Context, id int32) error {
-	_, err := q.db.Exec(ctx, deleteStorageRoom, id)
-	return err
+type DeleteStorageRoomParams struct {
+	ID    int32
+	OrgID string
 }
 
-const getStorageRoom = `-- name: GetStorageRoom :one
-SELECT id, name, number, warehouse_id FROM storage_room
-WHERE id = $1
+func (q *Queries) DeleteStorageRoom(ctx context.Context, arg DeleteStorageRoomParams) (int64, error) {
+	result, err := q.db.Exec(ctx, DeleteStorageRoom, arg.ID, arg.OrgID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const GetFullestWarehouse = `-- name: GetFullestWarehouse :one
+SELECT warehouse_id, count(*)::bigint AS storage_rooms
+FROM storage_room
+WHERE org_id = $1
+GROUP BY warehouse_id
+ORDER BY storage_rooms DESC, warehouse_id
+LIMIT 1
 `
 
-func (q *Queries) GetStorageRoom(ctx context.Context, id int32) (StorageRoom, error) {
-	row := q.db.QueryRow(ctx, getStorageRoom, id)
+type GetFullestWarehouseRow struct {
+	WarehouseID  int32
+	StorageRooms int64
+}
+
+func (q *Queries) GetFullestWarehouse(ctx context.Context, orgID string) (GetFullestWarehouseRow, error) {
+	row := q.db.QueryRow(ctx, GetFullestWarehouse, orgID)
+	var i GetFullestWarehouseRow
+	err := row.Scan(&i.WarehouseID, &i.StorageRooms)
+	return i, err
+}
+
+const GetStorageRoom = `-- name: GetStorageRoom :one
+SELECT id, name, number, warehouse_id, org_id, attributes, zone_type, tags, capacity, aisle, bay FROM storage_room
+WHERE id = $1 AND org_id = $2
+`
+
+type GetStorageRoomParams struct {
+	ID    int32
+	OrgID string
+}
+
+func (q *Queries) GetStorageRoom(ctx context.Context, arg GetStorageRoomParams) (StorageRoom, error) {
+	row := q.db.QueryRow(ctx, GetStorageRoom, arg.ID, arg.OrgID)
 	var i StorageRoom
 	err := row.Scan(
 		&i.ID,
 		&i.Name,
 		&i.Number,
 		&i.WarehouseID,
+		&i.OrgID,
+		&i.Attributes,
+		&i.ZoneType,
+		&i.Tags,
+		&i.Capacity,
+		&i.Aisle,
+		&i.Bay,
 	)
 	return i, err
 }
 
-const listStorageRoom = `-- name: ListStorageRoom :many
-SELECT id, name, number, warehouse_id
+const GetStorageRoomLabel = `-- name: GetStorageRoomLabel :one
+SELECT storage_room.id, storage_room.name, storage_room.number, storage_room.warehouse_id, warehouse.name AS warehouse_name
 FROM storage_room
-LIMIT $1 OFFSET $2
+JOIN warehouse ON warehouse.id = storage_room.warehouse_id
+WHERE storage_room.id = $1 AND storage_room.org_id = $2
+`
+
+type GetStorageRoomLabelParams struct {
+	ID    int32
+	OrgID string
+}
+
+type GetStorageRoomLabelRow struct {
+	ID            int32
+	Name          string
+	Number        string
+	WarehouseID   int32
+	WarehouseName string
+}
+
+func (q *Queries) GetStorageRoomLabel(ctx context.Context, arg GetStorageRoomLabelParams) (GetStorageRoomLabelRow, error) {
+	row := q.db.QueryRow(ctx, GetStorageRoomLabel, arg.ID, arg.OrgID)
+	var i GetStorageRoomLabelRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Number,
+		&i.WarehouseID,
+		&i.WarehouseName,
+	)
+	return i, err
+}
+
+const GetStorageRoomLabelByLocation = `-- name: GetStorageRoomLabelByLocation :one
+SELECT storage_room.id, storage_room.name, storage_room.number, storage_room.warehouse_id, warehouse.name AS warehouse_name
+FROM storage_room
+JOIN warehouse ON warehouse.id = storage_room.warehouse_id
+WHERE storage_room.warehouse_id = $1 AND storage_room.number = $2 AND storage_room.org_id = $3
+`
+
+type GetStorageRoomLabelByLocationParams struct {
+	WarehouseID int32
+	Number      string
+	OrgID       string
+}
+
+type GetStorageRoomLabelByLocationRow struct {
+	ID            int32
+	Name          string
+	Number        string
+	WarehouseID   int32
+	WarehouseName string
+}
+
+func (q *Queries) GetStorageRoomLabelByLocation(ctx context.Context, arg GetStorageRoomLabelByLocationParams) (GetStorageRoomLabelByLocationRow, error) {
+	row := q.db.QueryRow(ctx, GetStorageRoomLabelByLocation, arg.WarehouseID, arg.Number, arg.OrgID)
+	var i GetStorageRoomLabelByLocationRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Number,
+		&i.WarehouseID,
+		&i.WarehouseName,
+	)
+	return i, err
+}
+
+const GetStorageRoomsByIDs = `-- name: GetStorageRoomsByIDs :many
+SELECT id, name, number, warehouse_id, org_id, attributes, zone_type, tags, capacity, aisle, bay FROM storage_room
+WHERE org_id = $1 AND id = ANY($2::int[])
+`
+
+type GetStorageRoomsByIDsParams struct {
+	OrgID string
+	ID    []int32
+}
+
+func (q *Queries) GetStorageRoomsByIDs(ctx context.Context, arg GetStorageRoomsByIDsParams) ([]StorageRoom, error) {
+	rows, err := q.db.Query(ctx, GetStorageRoomsByIDs, arg.OrgID, arg.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []StorageRoom
+	for rows.Next() {
+		var i StorageRoom
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Number,
+			&i.WarehouseID,
+			&i.OrgID,
+			&i.Attributes,
+			&i.ZoneType,
+			&i.Tags,
+			&i.Capacity,
+			&i.Aisle,
+			&i.Bay,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListOccupancyByStorageRoom = `-- name: ListOccupancyByStorageRoom :many
+SELECT storage_room.org_id, storage_room.id, storage_room.capacity,
+    COALESCE(sum(stock_level.quantity), 0)::bigint AS occupancy
+FROM storage_room
+LEFT JOIN stock_level ON stock_level.storage_room_id = storage_room.id
+GROUP BY storage_room.id
+`
+
+type ListOccupancyByStorageRoomRow struct {
+	OrgID     string
+	ID        int32
+	Capacity  int32
+	Occupancy int64
+}
+
+func (q *Queries) ListOccupancyByStorageRoom(ctx context.Context) ([]ListOccupancyByStorageRoomRow, error) {
+	rows, err := q.db.Query(ctx, ListOccupancyByStorageRoom)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListOccupancyByStorageRoomRow
+	for rows.Next() {
+		var i ListOccupancyByStorageRoomRow
+		if err := rows.Scan(
+			&i.OrgID,
+			&i.ID,
+			&i.Capacity,
+			&i.Occupancy,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListStorageRoom = `-- name: ListStorageRoom :many
+SELECT id, name, number, warehouse_id, org_id, attributes, zone_type, tags, capacity, aisle, bay FROM storage_room
+WHERE org_id = $1
+  AND ($2::int IS NULL OR warehouse_id = $2::int)
+  AND ($3::text[] IS NULL OR tags @> $3::text[])
+ORDER BY id
+LIMIT $4 OFFSET $5
 `
 
 type ListStorageRoomParams struct {
-	Limit  int32
-	Offset int32
+	OrgID       string
+	WarehouseID pgtype.Int4
+	Tags        []string
+	Limit       int32
+	Offset      int32
 }
 
 func (q *Queries) ListStorageRoom(ctx context.Context, arg ListStorageRoomParams) ([]StorageRoom, error) {
-	rows, err := q.db.Query(ctx, listStorageRoom, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, ListStorageRoom,
+		arg.OrgID,
+		arg.WarehouseID,
+		arg.Tags,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []StorageRoom
+	for rows.Next() {
+		var i StorageRoom
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Number,
+			&i.WarehouseID,
+			&i.OrgID,
+			&i.Attributes,
+			&i.ZoneType,
+			&i.Tags,
+			&i.Capacity,
+			&i.Aisle,
+			&i.Bay,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListStorageRoomIDsInWarehouse = `-- name: ListStorageRoomIDsInWarehouse :many
+SELECT id FROM storage_room
+WHERE warehouse_id = $1 AND org_id = $2
+ORDER BY id
+`
+
+type ListStorageRoomIDsInWarehouseParams struct {
+	WarehouseID int32
+	OrgID       string
+}
+
+func (q *Queries) ListStorageRoomIDsInWarehouse(ctx context.Context, arg ListStorageRoomIDsInWarehouseParams) ([]int32, error) {
+	rows, err := q.db.Query(ctx, ListStorageRoomIDsInWarehouse, arg.WarehouseID, arg.OrgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListStorageRoomsInWarehouse = `-- name: ListStorageRoomsInWarehouse :many
+SELECT id, name, number, warehouse_id, org_id, attributes, zone_type, tags, capacity, aisle, bay FROM storage_room
+WHERE warehouse_id = $1 AND org_id = $2
+ORDER BY id
+LIMIT $3
+`
+
+type ListStorageRoomsInWarehouseParams struct {
+	WarehouseID int32
+	OrgID       string
+	Limit       int32
+}
+
+func (q *Queries) ListStorageRoomsInWarehouse(ctx context.Context, arg ListStorageRoomsInWarehouseParams) ([]StorageRoom, error) {
+	rows, err := q.db.Query(ctx, ListStorageRoomsInWarehouse, arg.WarehouseID, arg.OrgID, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
@@ -87,6 +486,13 @@ func (q *Queries) ListStorageRoom(ctx context.Context, arg ListStorageRoomParams
 			&i.Name,
 			&i.Number,
 			&i.WarehouseID,
+			&i.OrgID,
+			&i.Attributes,
+			&i.ZoneType,
+			&i.Tags,
+			&i.Capacity,
+			&i.Aisle,
+			&i.Bay,
 		); err != nil {
 			return nil, err
 		}
@@ -98,13 +504,131 @@ func (q *Queries) ListStorageRoom(ctx context.Context, arg ListStorageRoomParams
 	return items, nil
 }
 
-const updateStorageRoom = `-- name: UpdateStorageRoom :one
+const LockStorageRoomCapacity = `-- name: LockStorageRoomCapacity :one
+SELECT capacity FROM storage_room
+WHERE id = $1 AND org_id = $2
+FOR NO KEY UPDATE
+`
+
+type LockStorageRoomCapacityParams struct {
+	ID    int32
+	OrgID string
+}
+
+func (q *Queries) LockStorageRoomCapacity(ctx context.Context, arg LockStorageRoomCapacityParams) (int32, error) {
+	row := q.db.QueryRow(ctx, LockStorageRoomCapacity, arg.ID, arg.OrgID)
+	var capacity int32
+	err := row.Scan(&capacity)
+	return capacity, err
+}
+
+const PatchStorageRoom = `-- name: PatchStorageRoom :one
+UPDATE storage_room
+SET name = COALESCE($1, name),
+    number = COALESCE($2, number),
+    warehouse_id = COALESCE($3, warehouse_id),
+    attributes = COALESCE($4, attributes),
+    zone_type = COALESCE($5, zone_type),
+    tags = COALESCE($6, tags),
+    capacity = COALESCE($7, capacity),
+    aisle = COALESCE($8, aisle),
+    bay = COALESCE($9, bay)
+WHERE id = $10 AND org_id = $11
+RETURNING id, name, number, warehouse_id, org_id, attributes, zone_type, tags, capacity, aisle, bay
+`
+
+type PatchStorageRoomParams struct {
+	Name        pgtype.Text
+	Number      pgtype.Text
+	WarehouseID pgtype.Int4
+	Attributes  []byte
+	ZoneType    pgtype.Text
+	Tags        []string
+	Capacity    pgtype.Int4
+	Aisle       pgtype.Int4
+	Bay         pgtype.Int4
+	ID          int32
+	OrgID       string
+}
+
+func (q *Queries) PatchStorageRoom(ctx context.Context, arg PatchStorageRoomParams) (StorageRoom, error) {
+	row := q.db.QueryRow(ctx, PatchStorageRoom,
+		arg.Name,
+		arg.Number,
+		arg.WarehouseID,
+		arg.Attributes,
+		arg.ZoneType,
+		arg.Tags,
+		arg.Capacity,
+		arg.Aisle,
+		arg.Bay,
+		arg.ID,
+		arg.OrgID,
+	)
+	var i StorageRoom
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Number,
+		&i.WarehouseID,
+		&i.OrgID,
+		&i.Attributes,
+		&i.ZoneType,
+		&i.Tags,
+		&i.Capacity,
+		&i.Aisle,
+		&i.Bay,
+	)
+	return i, err
+}
+
+const RemoveStorageRoomTags = `-- name: RemoveStorageRoomTags :one
+UPDATE storage_room
+SET tags = ARRAY(
+    SELECT t FROM unnest(tags) WITH ORDINALITY AS n(t, i)
+    WHERE t <> ALL($1::text[])
+    ORDER BY i
+)
+WHERE id = $2 AND org_id = $3
+RETURNING id, name, number, warehouse_id, org_id, attributes, zone_type, tags, capacity, aisle, bay
+`
+
+type RemoveStorageRoomTagsParams struct {
+	Tags  []string
+	ID    int32
+	OrgID string
+}
+
+func (q *Queries) RemoveStorageRoomTags(ctx context.Context, arg RemoveStorageRoomTagsParams) (StorageRoom, error) {
+	row := q.db.QueryRow(ctx, RemoveStorageRoomTags,
+		arg.Tags,
+		arg.ID,
+		arg.OrgID,
+	)
+	var i StorageRoom
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Number,
+		&i.WarehouseID,
+		&i.OrgID,
+		&i.Attributes,
+		&i.ZoneType,
+		&i.Tags,
+		&i.Capacity,
+		&i.Aisle,
+		&i.Bay,
+	)
+	return i, err
+}
+
+const UpdateStorageRoom = `-- name: UpdateStorageRoom :one
 UPDATE storage_room
 SET name = $2,
     number = $3,
     warehouse_id= $4
-WHERE id = $1
-RETURNING id, name, number, warehouse_id
+WHERE id = $1 AND org_id = $5
+RETURNING id, name, number, warehouse_id, org_id, attributes, zone_type, tags, capacity, aisle, bay
 `
 
 type UpdateStorageRoomParams struct {
@@ -112,14 +636,16 @@ type UpdateStorageRoomParams struct {
 	Name        string
 	Number      string
 	WarehouseID int32
+	OrgID       string
 }
 
 func (q *Queries) UpdateStorageRoom(ctx context.Context, arg UpdateStorageRoomParams) (StorageRoom, error) {
-	row := q.db.QueryRow(ctx, updateStorageRoom,
+	row := q.db.QueryRow(ctx, UpdateStorageRoom,
 		arg.ID,
 		arg.Name,
 		arg.Number,
 		arg.WarehouseID,
+		arg.OrgID,
 	)
 	var i StorageRoom
 	err := row.Scan(
@@ -127,6 +653,13 @@ func (q *Queries) UpdateStorageRoom(ctx context.Context, arg UpdateStorageRoomPa
 		&i.Name,
 		&i.Number,
 		&i.WarehouseID,
+		&i.OrgID,
+		&i.Attributes,
+		&i.ZoneType,
+		&i.Tags,
+		&i.Capacity,
+		&i.Aisle,
+		&i.Bay,
 	)
 	return i, err
 }
@@ -7,33 +7,165 @@ package models
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const createWarehouse = `-- name: CreateWarehouse :one
+const AddWarehouseTags = `-- name: AddWarehouseTags :one
+UPDATE warehouse
+SET tags = tags || ARRAY(
+    SELECT t FROM unnest($1::text[]) WITH ORDINALITY AS n(t, i)
+    WHERE t <> ALL(warehouse.tags)
+    ORDER BY i
+)
+WHERE id = $2 AND org_id = $3
+RETURNING id, name, address, ward, district, city, country, org_id, latitude, longitude, time_zone, operating_hours, contact_email, contact_phone, tags, attributes, status, slug
+`
+
+type AddWarehouseTagsParams struct {
+	Tags  []string
+	ID    int64
+	OrgID string
+}
+
+func (q *Queries) AddWarehouseTags(ctx context.Context, arg AddWarehouseTagsParams) (Warehouse, error) {
+	row := q.db.QueryRow(ctx, AddWarehouseTags,
+		arg.Tags,
+		arg.ID,
+		arg.OrgID,
+	)
+	var i Warehouse
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Address,
+		&i.Ward,
+		&i.District,
+		&i.City,
+		&i.Country,
+		&i.OrgID,
+		&i.Latitude,
+		&i.Longitude,
+		&i.TimeZone,
+		&i.OperatingHours,
+		&i.ContactEmail,
+		&i.ContactPhone,
+		&i.Tags,
+		&i.Attributes,
+		&i.Status,
+		&i.Slug,
+	)
+	return i, err
+}
+
+const AssignUnownedWarehouses = `-- name: AssignUnownedWarehouses :execrows
+UPDATE warehouse SET org_id = $1
+WHERE org_id = ''
+`
+
+func (q *Queries) AssignUnownedWarehouses(ctx context.Context, orgID string) (int64, error) {
+	result, err := q.db.Exec(ctx, AssignUnownedWarehouses, orgID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const CountWarehousesByTenant = `-- name: CountWarehousesByTenant :many
+SELECT org_id, count(*) AS count
+FROM warehouse
+GROUP BY org_id
+`
+
+type CountWarehousesByTenantRow struct {
+	OrgID string
+	Count int64
+}
+
+func (q *Queries) CountWarehousesByTenant(ctx context.Context) ([]CountWarehousesByTenantRow, error) {
+	rows, err := q.db.Query(ctx, CountWarehousesByTenant)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountWarehousesByTenantRow
+	for rows.Next() {
+		var i CountWarehousesByTenantRow
+		if err := rows.Scan(
+			&i.OrgID,
+			&i.Count,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const CountWarehousesForTenant = `-- name: CountWarehousesForTenant :one
+SELECT count(*) FROM warehouse
+WHERE org_id = $1
+`
+
+func (q *Queries) CountWarehousesForTenant(ctx context.Context, orgID string) (int64, error) {
+	row := q.db.QueryRow(ctx, CountWarehousesForTenant, orgID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const CreateWarehouse = `-- name: CreateWarehouse :one
 INSERT INTO warehouse (
-    name, address, ward, district, city, country
+    name, address, ward, district, city, country, org_id, latitude, longitude,
+    time_zone, operating_hours, contact_email, contact_phone, tags, attributes, status, slug
 ) VALUES (
-    $1, $2, $3, $4, $5, $6
-) RETURNING id, name, address, ward, district, city, country
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15,
+    COALESCE($16, 'active'), $17
+) RETURNING id, name, address, ward, district, city, country, org_id, latitude, longitude, time_zone, operating_hours, contact_email, contact_phone, tags, attributes, status, slug
 `
 
 type CreateWarehouseParams struct {
-	Name     string
-	Address  string
-	Ward     string
-	District string
-	City     string
-	Country  string
+	Name           string
+	Address        string
+	Ward           string
+	District       string
+	City           string
+	Country        string
+	OrgID          string
+	Latitude       pgtype.Float8
+	Longitude      pgtype.Float8
+	TimeZone       string
+	OperatingHours []byte
+	ContactEmail   pgtype.Text
+	ContactPhone   pgtype.Text
+	Tags           []string
+	Attributes     []byte
+	Status         pgtype.Text
+	Slug           string
 }
 
 func (q *Queries) CreateWarehouse(ctx context.Context, arg CreateWarehouseParams) (Warehouse, error) {
-	row := q.db.QueryRow(ctx, createWarehouse,
+	row := q.db.QueryRow(ctx, CreateWarehouse,
 		arg.Name,
 		arg.Address,
 		arg.Ward,
 		arg.District,
 		arg.City,
 		arg.Country,
+		arg.OrgID,
+		arg.Latitude,
+		arg.Longitude,
+		arg.TimeZone,
+		arg.OperatingHours,
+		arg.ContactEmail,
+		arg.ContactPhone,
+		arg.Tags,
+		arg.Attributes,
+		arg.Status,
+		arg.Slug,
 	)
 	var i Warehouse
 	err := row.Scan(
@@ -44,27 +176,145 @@ func (q *Queries) CreateWarehouse(ctx context.Context, arg CreateWarehouseParams
 		&i.District,
 		&i.City,
 		&i.Country,
+		&i.OrgID,
+		&i.Latitude,
+		&i.Longitude,
+		&i.TimeZone,
+		&i.OperatingHours,
+		&i.ContactEmail,
+		&i.ContactPhone,
+		&i.Tags,
+		&i.Attributes,
+		&i.Status,
+		&i.Slug,
 	)
 	return i, err
 }
 
-const deleteWarehouse = `-- name: DeleteWarehouse :exec
+const DeleteWarehouse = `-- name: DeleteWarehouse :execrows
 DELETE FROM warehouse
-WHERE id = $1
+WHERE id = $1 AND org_id = $2
 `
 
-func (q *Queries) DeleteWarehouse(ctx context.Context, id int64) error {
-	_, err := q.db.Exec(ctx, deleteWarehouse, id)
-	return err
+type DeleteWarehouseParams struct {
+	ID    int64
+	OrgID string
+}
+
+func (q *Queries) DeleteWarehouse(ctx context.Context, arg DeleteWarehouseParams) (int64, error) {
+	result, err := q.db.Exec(ctx, DeleteWarehouse, arg.ID, arg.OrgID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
-const getWarehouse = `-- name: GetWarehouse :one
-SELECT id, name, address, ward, district, city, country FROM warehouse
-WHERE id = $1
+const FindDuplicateWarehouses = `-- name: FindDuplicateWarehouses :many
+SELECT w.id, w.name, w.address, w.ward, w.district, w.city, w.country, w.org_id, w.latitude, w.longitude, w.time_zone, w.operating_hours, w.contact_email, w.contact_phone, w.tags, w.attributes, w.status, w.slug,
+    similarity(lower(w.name), lower($1::text))::float8 AS name_similarity,
+    similarity(lower(concat_ws(' ', w.address, w.ward, w.district, w.city, w.country)), lower($2::text))::float8 AS address_similarity
+FROM warehouse w
+WHERE w.org_id = $3
+  AND similarity(lower(w.name), lower($1::text)) >= $4::float8
+  AND similarity(lower(concat_ws(' ', w.address, w.ward, w.district, w.city, w.country)), lower($2::text)) >= $5::float8
+ORDER BY name_similarity + address_similarity DESC, w.id
+LIMIT $6
 `
 
-func (q *Queries) GetWarehouse(ctx context.Context, id int64) (Warehouse, error) {
-	row := q.db.QueryRow(ctx, getWarehouse, id)
+type FindDuplicateWarehousesParams struct {
+	Name             string
+	Address          string
+	OrgID            string
+	NameThreshold    float64
+	AddressThreshold float64
+	PageLimit        int32
+}
+
+type FindDuplicateWarehousesRow struct {
+	ID                int64
+	Name              string
+	Address           string
+	Ward              string
+	District          string
+	City              string
+	Country           string
+	OrgID             string
+	Latitude          pgtype.Float8
+	Longitude         pgtype.Float8
+	TimeZone          string
+	OperatingHours    []byte
+	ContactEmail      pgtype.Text
+	ContactPhone      pgtype.Text
+	Tags              []string
+	Attributes        []byte
+	Status            string
+	Slug              string
+	NameSimilarity    float64
+	AddressSimilarity float64
+}
+
+// Warehouses of the tenant whose name and full address are both similar to
+// those of a new warehouse, by trigram similarity
+func (q *Queries) FindDuplicateWarehouses(ctx context.Context, arg FindDuplicateWarehousesParams) ([]FindDuplicateWarehousesRow, error) {
+	rows, err := q.db.Query(ctx, FindDuplicateWarehouses,
+		arg.Name,
+		arg.Address,
+		arg.OrgID,
+		arg.NameThreshold,
+		arg.AddressThreshold,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FindDuplicateWarehousesRow
+	for rows.Next() {
+		var i FindDuplicateWarehousesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Address,
+			&i.Ward,
+			&i.District,
+			&i.City,
+			&i.Country,
+			&i.OrgID,
+			&i.Latitude,
+			&i.Longitude,
+			&i.TimeZone,
+			&i.OperatingHours,
+			&i.ContactEmail,
+			&i.ContactPhone,
+			&i.Tags,
+			&i.Attributes,
+			&i.Status,
+			&i.Slug,
+			&i.NameSimilarity,
+			&i.AddressSimilarity,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const GetWarehouse = `-- name: GetWarehouse :one
+SELECT id, name, address, ward, district, city, country, org_id, latitude, longitude, time_zone, operating_hours, contact_email, contact_phone, tags, attributes, status, slug FROM warehouse
+WHERE id = $1 AND org_id = $2
+`
+
+type GetWarehouseParams struct {
+	ID    int64
+	OrgID string
+}
+
+func (q *Queries) GetWarehouse(ctx context.Context, arg GetWarehouseParams) (Warehouse, error) {
+	row := q.db.QueryRow(ctx, GetWarehouse, arg.ID, arg.OrgID)
 	var i Warehouse
 	err := row.Scan(
 		&i.ID,
@@ -74,23 +324,250 @@ func (q *Queries) GetWarehouse(ctx context.Context, id int64) (Warehouse, error)
 		&i.District,
 		&i.City,
 		&i.Country,
+		&i.OrgID,
+		&i.Latitude,
+		&i.Longitude,
+		&i.TimeZone,
+		&i.OperatingHours,
+		&i.ContactEmail,
+		&i.ContactPhone,
+		&i.Tags,
+		&i.Attributes,
+		&i.Status,
+		&i.Slug,
 	)
 	return i, err
 }
 
-const listWarehouse = `-- name: ListWarehouse :many
-SELECT id, name, address, ward, district, city, country
-FROM warehouse
-LIMIT $1 OFFSET $2
+const GetWarehouseForUpdate = `-- name: GetWarehouseForUpdate :one
+SELECT id, name, address, ward, district, city, country, org_id, latitude, longitude, time_zone, operating_hours, contact_email, contact_phone, tags, attributes, status, slug FROM warehouse
+WHERE id = $1 AND org_id = $2
+FOR UPDATE
+`
+
+type GetWarehouseForUpdateParams struct {
+	ID    int64
+	OrgID string
+}
+
+func (q *Queries) GetWarehouseForUpdate(ctx context.Context, arg GetWarehouseForUpdateParams) (Warehouse, error) {
+	row := q.db.QueryRow(ctx, GetWarehouseForUpdate, arg.ID, arg.OrgID)
+	var i Warehouse
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Address,
+		&i.Ward,
+		&i.District,
+		&i.City,
+		&i.Country,
+		&i.OrgID,
+		&i.Latitude,
+		&i.Longitude,
+		&i.TimeZone,
+		&i.OperatingHours,
+		&i.ContactEmail,
+		&i.ContactPhone,
+		&i.Tags,
+		&i.Attributes,
+		&i.Status,
+		&i.Slug,
+	)
+	return i, err
+}
+
+const GetWarehouseIDBySlug = `-- name: GetWarehouseIDBySlug :one
+SELECT id FROM warehouse
+WHERE org_id = $1 AND slug = $2
+`
+
+type GetWarehouseIDBySlugParams struct {
+	OrgID string
+	Slug  string
+}
+
+func (q *Queries) GetWarehouseIDBySlug(ctx context.Context, arg GetWarehouseIDBySlugParams) (int64, error) {
+	row := q.db.QueryRow(ctx, GetWarehouseIDBySlug, arg.OrgID, arg.Slug)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const GetWarehousesByIDs = `-- name: GetWarehousesByIDs :many
+SELECT id, name, address, ward, district, city, country, org_id, latitude, longitude, time_zone, operating_hours, contact_email, contact_phone, tags, attributes, status, slug FROM warehouse
+WHERE org_id = $1 AND id = ANY($2::bigint[])
+`
+
+type GetWarehousesByIDsParams struct {
+	OrgID string
+	ID    []int64
+}
+
+func (q *Queries) GetWarehousesByIDs(ctx context.Context, arg GetWarehousesByIDsParams) ([]Warehouse, error) {
+	rows, err := q.db.Query(ctx, GetWarehousesByIDs, arg.OrgID, arg.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Warehouse
+	for rows.Next() {
+		var i Warehouse
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Address,
+			&i.Ward,
+			&i.District,
+			&i.City,
+			&i.Country,
+			&i.OrgID,
+			&i.Latitude,
+			&i.Longitude,
+			&i.TimeZone,
+			&i.OperatingHours,
+			&i.ContactEmail,
+			&i.ContactPhone,
+			&i.Tags,
+			&i.Attributes,
+			&i.Status,
+			&i.Slug,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListNearbyWarehouses = `-- name: ListNearbyWarehouses :many
+SELECT w.id, w.name, w.address, w.ward, w.district, w.city, w.country, w.org_id, w.latitude, w.longitude, w.time_zone, w.operating_hours, w.contact_email, w.contact_phone, w.tags, w.attributes, w.status, w.slug,
+    earth_distance(
+        ll_to_earth(w.latitude, w.longitude),
+        ll_to_earth($1::float8, $2::float8)
+    )::float8 AS distance
+FROM warehouse w
+WHERE w.org_id = $3
+  AND w.latitude IS NOT NULL
+  AND earth_box(ll_to_earth($1::float8, $2::float8), $4::float8)
+      @> ll_to_earth(w.latitude, w.longitude)
+  AND earth_distance(
+        ll_to_earth(w.latitude, w.longitude),
+        ll_to_earth($1::float8, $2::float8)
+    ) <= $4::float8
+ORDER BY distance
+LIMIT $5
+`
+
+type ListNearbyWarehousesParams struct {
+	Lat       float64
+	Lng       float64
+	OrgID     string
+	Radius    float64
+	PageLimit int32
+}
+
+type ListNearbyWarehousesRow struct {
+	ID             int64
+	Name           string
+	Address        string
+	Ward           string
+	District       string
+	City           string
+	Country        string
+	OrgID          string
+	Latitude       pgtype.Float8
+	Longitude      pgtype.Float8
+	TimeZone       string
+	OperatingHours []byte
+	ContactEmail   pgtype.Text
+	ContactPhone   pgtype.Text
+	Tags           []string
+	Attributes     []byte
+	Status         string
+	Slug           string
+	Distance       float64
+}
+
+func (q *Queries) ListNearbyWarehouses(ctx context.Context, arg ListNearbyWarehousesParams) ([]ListNearbyWarehousesRow, error) {
+	rows, err := q.db.Query(ctx, ListNearbyWarehouses,
+		arg.Lat,
+		arg.Lng,
+		arg.OrgID,
+		arg.Radius,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListNearbyWarehousesRow
+	for rows.Next() {
+		var i ListNearbyWarehousesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Address,
+			&i.Ward,
+			&i.District,
+			&i.City,
+			&i.Country,
+			&i.OrgID,
+			&i.Latitude,
+			&i.Longitude,
+			&i.TimeZone,
+			&i.OperatingHours,
+			&i.ContactEmail,
+			&i.ContactPhone,
+			&i.Tags,
+			&i.Attributes,
+			&i.Status,
+			&i.Slug,
+			&i.Distance,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListWarehouse = `-- name: ListWarehouse :many
+SELECT id, name, address, ward, district, city, country, org_id, latitude, longitude, time_zone, operating_hours, contact_email, contact_phone, tags, attributes, status, slug FROM warehouse
+WHERE org_id = $1
+  AND ($2::text[] IS NULL OR tags @> $2::text[])
+  AND ($3::text IS NULL OR time_zone = $3::text)
+  AND ($4::jsonb IS NULL OR attributes @> $4::jsonb)
+  AND ($5::text IS NULL OR status = $5::text)
+ORDER BY id
+LIMIT $6 OFFSET $7
 `
 
 type ListWarehouseParams struct {
-	Limit  int32
-	Offset int32
+	OrgID      string
+	Tags       []string
+	TimeZone   pgtype.Text
+	Attributes []byte
+	Status     pgtype.Text
+	Limit      int32
+	Offset     int32
 }
 
 func (q *Queries) ListWarehouse(ctx context.Context, arg ListWarehouseParams) ([]Warehouse, error) {
-	rows, err := q.db.Query(ctx, listWarehouse, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, ListWarehouse,
+		arg.OrgID,
+		arg.Tags,
+		arg.TimeZone,
+		arg.Attributes,
+		arg.Status,
+		arg.Limit,
+		arg.Offset,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -106,6 +583,17 @@ func (q *Queries) ListWarehouse(ctx context.Context, arg ListWarehouseParams) ([
 			&i.District,
 			&i.City,
 			&i.Country,
+			&i.OrgID,
+			&i.Latitude,
+			&i.Longitude,
+			&i.TimeZone,
+			&i.OperatingHours,
+			&i.ContactEmail,
+			&i.ContactPhone,
+			&i.Tags,
+			&i.Attributes,
+			&i.Status,
+			&i.Slug,
 		); err != nil {
 			return nil, err
 		}
@@ -117,30 +605,208 @@ func (q *Queries) ListWarehouse(ctx context.Context, arg ListWarehouseParams) ([
 	return items, nil
 }
 
-const updateWarehouse = `-- name: UpdateWarehouse :one
+const ListWarehouseSlugs = `-- name: ListWarehouseSlugs :many
+SELECT slug FROM warehouse
+WHERE org_id = $1
+  AND (slug = $2::text OR slug LIKE $2::text || '-%')
+`
+
+type ListWarehouseSlugsParams struct {
+	OrgID string
+	Base  string
+}
+
+// Slugs of the tenant's warehouses that are base or base with a suffix
+func (q *Queries) ListWarehouseSlugs(ctx context.Context, arg ListWarehouseSlugsParams) ([]string, error) {
+	rows, err := q.db.Query(ctx, ListWarehouseSlugs, arg.OrgID, arg.Base)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var slug string
+		if err := rows.Scan(&slug); err != nil {
+			return nil, err
+		}
+		items = append(items, slug)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const PatchWarehouse = `-- name: PatchWarehouse :one
+UPDATE warehouse
+SET name = COALESCE($1, name),
+    address = COALESCE($2, address),
+    ward = COALESCE($3, ward),
+    district = COALESCE($4, district),
+    city = COALESCE($5, city),
+    country = COALESCE($6, country),
+    latitude = COALESCE($7, latitude),
+    longitude = COALESCE($8, longitude),
+    time_zone = COALESCE($9, time_zone),
+    operating_hours = COALESCE($10, operating_hours),
+    contact_email = NULLIF(COALESCE($11, contact_email), ''),
+    contact_phone = NULLIF(COALESCE($12, contact_phone), ''),
+    tags = COALESCE($13, tags),
+    attributes = COALESCE($14, attributes)
+WHERE id = $15 AND org_id = $16
+RETURNING id, name, address, ward, district, city, country, org_id, latitude, longitude, time_zone, operating_hours, contact_email, contact_phone, tags, attributes, status, slug
+`
+
+type PatchWarehouseParams struct {
+	Name           pgtype.Text
+	Address        pgtype.Text
+	Ward           pgtype.Text
+	District       pgtype.Text
+	City           pgtype.Text
+	Country        pgtype.Text
+	Latitude       pgtype.Float8
+	Longitude      pgtype.Float8
+	TimeZone       pgtype.Text
+	OperatingHours []byte
+	ContactEmail   pgtype.Text
+	ContactPhone   pgtype.Text
+	Tags           []string
+	Attributes     []byte
+	ID             int64
+	OrgID          string
+}
+
+func (q *Queries) PatchWarehouse(ctx context.Context, arg PatchWarehouseParams) (Warehouse, error) {
+	row := q.db.QueryRow(ctx, PatchWarehouse,
+		arg.Name,
+		arg.Address,
+		arg.Ward,
+		arg.District,
+		arg.City,
+		arg.Country,
+		arg.Latitude,
+		arg.Longitude,
+		arg.TimeZone,
+		arg.OperatingHours,
+		arg.ContactEmail,
+		arg.ContactPhone,
+		arg.Tags,
+		arg.Attributes,
+		arg.ID,
+		arg.OrgID,
+	)
+	var i Warehouse
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Address,
+		&i.Ward,
+		&i.District,
+		&i.City,
+		&i.Country,
+		&i.OrgID,
+		&i.Latitude,
+		&i.Longitude,
+		&i.TimeZone,
+		&i.OperatingHours,
+		&i.ContactEmail,
+		&i.ContactPhone,
+		&i.Tags,
+		&i.Attributes,
+		&i.Status,
+		&i.Slug,
+	)
+	return i, err
+}
+
+const RemoveWarehouseTags = `-- name: RemoveWarehouseTags :one
+UPDATE warehouse
+SET tags = ARRAY(
+    SELECT t FROM unnest(tags) WITH ORDINALITY AS n(t, i)
+    WHERE t <> ALL($1::text[])
+    ORDER BY i
+)
+WHERE id = $2 AND org_id = $3
+RETURNING id, name, address, ward, district, city, country, org_id, latitude, longitude, time_zone, operating_hours, contact_email, contact_phone, tags, attributes, status, slug
+`
+
+type RemoveWarehouseTagsParams struct {
+	Tags  []string
+	ID    int64
+	OrgID string
+}
+
+func (q *Queries) RemoveWarehouseTags(ctx context.Context, arg RemoveWarehouseTagsParams) (Warehouse, error) {
+	row := q.db.QueryRow(ctx, RemoveWarehouseTags,
+		arg.Tags,
+		arg.ID,
+		arg.OrgID,
+	)
+	var i Warehouse
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Address,
+		&i.Ward,
+		&i.District,
+		&i.City,
+		&i.Country,
+		&i.OrgID,
+		&i.Latitude,
+		&i.Longitude,
+		&i.TimeZone,
+		&i.OperatingHours,
+		&i.ContactEmail,
+		&i.ContactPhone,
+		&i.Tags,
+		&i.Attributes,
+		&i.Status,
+		&i.Slug,
+	)
+	return i, err
+}
+
+const UpdateWarehouse = `-- name: UpdateWarehouse :one
 UPDATE warehouse
 SET name = $2,
     address = $3,
     ward = $4,
     district = $5,
     city = $6,
-    country = $7
-WHERE id = $1
-RETURNING id, name, address, ward, district, city, country
+    country = $7,
+    latitude = $9,
+    longitude = $10,
+    time_zone = $11,
+    operating_hours = $12,
+    contact_email = $13,
+    contact_phone = $14,
+    tags = $15,
+    attributes = $16
+WHERE id = $1 AND org_id = $8
+RETURNING id, name, address, ward, district, city, country, org_id, latitude, longitude, time_zone, operating_hours, contact_email, contact_phone, tags, attributes, status, slug
 `
 
 type UpdateWarehouseParams struct {
-	ID       int64
-	Name     string
-	Address  string
-	Ward     string
-	District string
-	City     string
-	Country  string
+	ID             int64
+	Name           string
+	Address        string
+	Ward           string
+	District       string
+	City           string
+	Country        string
+	OrgID          string
+	Latitude       pgtype.Float8
+	Longitude      pgtype.Float8
+	TimeZone       string
+	OperatingHours []byte
+	ContactEmail   pgtype.Text
+	ContactPhone   pgtype.Text
+	Tags           []string
+	Attributes     []byte
 }
 
 func (q *Queries) UpdateWarehouse(ctx context.Context, arg UpdateWarehouseParams) (Warehouse, error) {
-	row := q.db.QueryRow(ctx, updateWarehouse,
+	row := q.db.QueryRow(ctx, UpdateWarehouse,
 		arg.ID,
 		arg.Name,
 		arg.Address,
@@ -148,7 +814,55 @@ func (q *Queries) UpdateWarehouse(ctx context.Context, arg UpdateWarehouseParams
 		arg.District,
 		arg.City,
 		arg.Country,
+		arg.OrgID,
+		arg.Latitude,
+		arg.Longitude,
+		arg.TimeZone,
+		arg.OperatingHours,
+		arg.ContactEmail,
+		arg.ContactPhone,
+		arg.Tags,
+		arg.Attributes,
+	)
+	var i Warehouse
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Address,
+		&i.Ward,
+		&i.District,
+		&i.City,
+		&i.Country,
+		&i.OrgID,
+		&i.Latitude,
+		&i.Longitude,
+		&i.TimeZone,
+		&i.OperatingHours,
+		&i.ContactEmail,
+		&i.ContactPhone,
+		&i.Tags,
+		&i.Attributes,
+		&i.Status,
+		&i.Slug,
 	)
+	return i, err
+}
+
+const UpdateWarehouseStatus = `-- name: UpdateWarehouseStatus :one
+UPDATE warehouse
+SET status = $3
+WHERE id = $1 AND org_id = $2
+RETURNING id, name, address, ward, district, city, country, org_id, latitude, longitude, time_zone, operating_hours, contact_email, contact_phone, tags, attributes, status, slug
+`
+
+type UpdateWarehouseStatusParams struct {
+	ID     int64
+	OrgID  string
+	Status string
+}
+
+func (q *Queries) UpdateWarehouseStatus(ctx context.Context, arg UpdateWarehouseStatusParams) (Warehouse, error) {
+	row := q.db.QueryRow(ctx, UpdateWarehouseStatus, arg.ID, arg.OrgID, arg.Status)
 	var i Warehouse
 	err := row.Scan(
 		&i.ID,
@@ -158,6 +872,17 @@ func (q *Queries) UpdateWarehouse(ctx context.Context, arg UpdateWarehouseParams
 		&i.District,
 		&i.City,
 		&i.Country,
+		&i.OrgID,
+		&i.Latitude,
+		&i.Longitude,
+		&i.TimeZone,
+		&i.OperatingHours,
+		&i.ContactEmail,
+		&i.ContactPhone,
+		&i.Tags,
+		&i.Attributes,
+		&i.Status,
+		&i.Slug,
 	)
 	return i, err
 }
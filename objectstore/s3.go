@@ -0,0 +1,164 @@
+// Package objectstore keeps files such as warehouse documents in an S3
+// compatible object store, AWS S3 or MinIO. Downloads go straight to the
+// store through presigned URLs.
+package objectstore
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Store keeps objects under keys chosen by the caller
+type Store interface {
+	// Put stores size bytes of body under key. sha256 is the hex encoded
+	// SHA-256 of the body, which the store verifies.
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType, sha256 string) error
+	// Delete removes the object, deleting a missing object succeeds
+	Delete(ctx context.Context, key string) error
+	// PresignGet returns a URL that downloads the object as fileName until
+	// ttl has passed
+	PresignGet(ctx context.Context, key, fileName string, ttl time.Duration) (string, error)
+}
+
+// S3Config locates a bucket. MinIO and most other S3 compatible stores
+// need an Endpoint and PathStyle.
+type S3Config struct {
+	Bucket string
+	Region string
+	// Endpoint is the store's base URL, empty for AWS S3 in Region
+	Endpoint string
+	// PathStyle addresses the bucket as endpoint/bucket rather than as
+	// bucket.endpoint
+	PathStyle bool
+	// Credentials defaults to the AWS default chain: environment, shared
+	// config, IRSA or the instance role
+	Credentials aws.CredentialsProvider
+}
+
+// S3 is a Store on an S3 compatible bucket
+type S3 struct {
+	bucket    string
+	client    *s3.Client
+	presigner *s3.PresignClient
+}
+
+// NewS3 returns a store for the bucket in cfg
+func NewS3(ctx context.Context, cfg S3Config) (*S3, error) {
+	opts := []func(*awsconfig.LoadOptions) error{awsconfig.WithRegion(cfg.Region)}
+	if cfg.Credentials != nil {
+		opts = append(opts, awsconfig.WithCredentialsProvider(cfg.Credentials))
+	}
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("objectstore: load AWS config: %w", err)
+	}
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		o.UsePathStyle = cfg.PathStyle
+		// Objects are checked on upload, and stores other than S3 seldom
+		// return a checksum to validate downloads against
+		o.ResponseChecksumValidation = aws.ResponseChecksumValidationWhenRequired
+		if cfg.Endpoint != "" {
+			o.BaseEndpoint = aws.String(cfg.Endpoint)
+		}
+	})
+	return &S3{
+		bucket:    cfg.Bucket,
+		client:    client,
+		presigner: s3.NewPresignClient(client),
+	}, nil
+}
+
+func (s *S3) Put(ctx context.Context, key string, body io.Reader, size int64, contentType, sha256 string) error {
+	sum, err := hex.DecodeString(sha256)
+	if err != nil {
+		return fmt.Errorf("objectstore: put %s: invalid SHA-256 %q", key, sha256)
+	}
+	_, err = s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:         aws.String(s.bucket),
+		Key:            aws.String(key),
+		Body:           body,
+		ContentLength:  aws.Int64(size),
+		ContentType:    aws.String(contentType),
+		ChecksumSHA256: aws.String(base64.StdEncoding.EncodeToString(sum)),
+	})
+	if err != nil {
+		return fmt.Errorf("objectstore: put %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	var resp *awshttp.ResponseError
+	if err != nil && !(errors.As(err, &resp) && resp.HTTPStatusCode() == http.StatusNotFound) {
+		return fmt.Errorf("objectstore: delete %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *S3) PresignGet(ctx context.Context, key, fileName string, ttl time.Duration) (string, error) {
+	req, err := s.presigner.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+		// Browsers save the file under its uploaded name instead of the key
+		ResponseContentDisposition: aws.String(mime.FormatMediaType("attachment", map[string]string{"filename": fileName})),
+	}, s3.WithPresignExpires(ttl))
+	if err != nil {
+		return "", fmt.Errorf("objectstore: presign %s: %w", key, err)
+	}
+	return req.URL, nil
+}
+
+// List returns the keys of the objects under prefix, in key order
+func (s *S3) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	pages := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	})
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("objectstore: list %s: %w", prefix, err)
+		}
+		for _, object := range page.Contents {
+			keys = append(keys, aws.ToString(object.Key))
+		}
+	}
+	return keys, nil
+}
+
+// Get reads an object of at most maxSize bytes
+func (s *S3) Get(ctx context.Context, key string, maxSize int64) ([]byte, error) {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("objectstore: get %s: %w", key, err)
+	}
+	defer out.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(out.Body, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("objectstore: get %s: %w", key, err)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("objectstore: %s is larger than %d bytes", key, maxSize)
+	}
+	return data, nil
+}
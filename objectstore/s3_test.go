@@ -0,0 +1,190 @@
+package objectstore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+var testCredentials = aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+	return aws.Credentials{AccessKeyID: "minio", SecretAccessKey: "minio-secret"}, nil
+})
+
+func newTestStore(t *testing.T, handler http.HandlerFunc) *S3 {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	store, err := NewS3(context.Background(), S3Config{
+		Bucket:      "docs",
+		Region:      "us-east-1",
+		Endpoint:    srv.URL,
+		PathStyle:   true,
+		Credentials: testCredentials,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestS3Put(t *testing.T) {
+	var got *http.Request
+	var body string
+	store := newTestStore(t, func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+	})
+
+	sum := sha256.Sum256([]byte("%PDF"))
+	err := store.Put(context.Background(), "org_1/warehouses/4/plan v2.pdf", strings.NewReader("%PDF"), 4, "application/pdf", hex.EncodeToString(sum[:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Method != http.MethodPut || got.URL.EscapedPath() != "/docs/org_1/warehouses/4/plan%20v2.pdf" {
+		t.Fatalf("request %s %s", got.Method, got.URL.EscapedPath())
+	}
+	if !strings.HasPrefix(got.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=minio/") {
+		t.Fatalf("authorization %q", got.Header.Get("Authorization"))
+	}
+	if got.Header.Get("X-Amz-Checksum-Sha256") != base64.StdEncoding.EncodeToString(sum[:]) || got.Header.Get("Content-Type") != "application/pdf" || body != "%PDF" {
+		t.Fatalf("headers %v, body %q", got.Header, body)
+	}
+}
+
+func TestS3Errors(t *testing.T) {
+	store := newTestStore(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		http.Error(w, "AccessDenied", http.StatusForbidden)
+	})
+
+	if err := store.Delete(context.Background(), "gone"); err != nil {
+		t.Fatalf("deleting a missing object: %v", err)
+	}
+	err := store.Put(context.Background(), "key", strings.NewReader("x"), 1, "text/plain", "2d711642b726b04401627ca9fbac32f5c8530fb1903cc4db02258717921a4881")
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Fatalf("err = %v, want the status", err)
+	}
+}
+
+func TestS3PresignGet(t *testing.T) {
+	store := newTestStore(t, func(http.ResponseWriter, *http.Request) {})
+
+	signed, err := store.PresignGet(context.Background(), "org_1/a.pdf", "Lease 2024.pdf", 15*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := u.Query()
+	if u.Path != "/docs/org_1/a.pdf" || query.Get("X-Amz-Expires") != "900" || query.Get("X-Amz-Signature") == "" {
+		t.Fatalf("presigned %s", signed)
+	}
+	if query.Get("response-content-disposition") != `attachment; filename="Lease 2024.pdf"` {
+		t.Fatalf("disposition %q", query.Get("response-content-disposition"))
+	}
+}
+
+func TestS3VirtualHostedURL(t *testing.T) {
+	store, err := NewS3(context.Background(), S3Config{Bucket: "docs", Region: "eu-west-1", Credentials: testCredentials})
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err := store.PresignGet(context.Background(), "org_1/a.pdf", "a.pdf", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u, _ := url.Parse(signed); u.Host != "docs.s3.eu-west-1.amazonaws.com" || u.Path != "/org_1/a.pdf" {
+		t.Fatalf("presigned %s", signed)
+	}
+}
+
+func TestHTTPScanner(t *testing.T) {
+	tests := []struct {
+		status   int
+		rejected bool
+		fails    bool
+	}{
+		{http.StatusOK, false, false},
+		{http.StatusNoContent, false, false},
+		{http.StatusUnprocessableEntity, true, true},
+		{http.StatusNotAcceptable, true, true},
+		{http.StatusInternalServerError, false, true},
+	}
+	for _, tt := range tests {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-File-Name") != "plan.pdf" {
+				t.Errorf("file name %q", r.Header.Get("X-File-Name"))
+			}
+			w.WriteHeader(tt.status)
+		}))
+		err := NewHTTPScanner(srv.URL).Scan(context.Background(), "plan.pdf", strings.NewReader("%PDF"))
+		srv.Close()
+		if (err != nil) != tt.fails || errors.Is(err, ErrRejected) != tt.rejected {
+			t.Errorf("status %d: err = %v", tt.status, err)
+		}
+	}
+}
+
+func TestS3List(t *testing.T) {
+	var queries []url.Values
+	store := newTestStore(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/docs" {
+			t.Errorf("path %s", r.URL.Path)
+		}
+		queries = append(queries, r.URL.Query())
+		if r.URL.Query().Get("continuation-token") == "" {
+			io.WriteString(w, `<ListBucketResult><Contents><Key>in/a.csv</Key></Contents><IsTruncated>true</IsTruncated><NextContinuationToken>next page</NextContinuationToken></ListBucketResult>`)
+			return
+		}
+		io.WriteString(w, `<ListBucketResult><Contents><Key>in/b.edi</Key></Contents><IsTruncated>false</IsTruncated></ListBucketResult>`)
+	})
+
+	keys, err := store.List(context.Background(), "in/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(keys, ",") != "in/a.csv,in/b.edi" {
+		t.Fatalf("keys %v", keys)
+	}
+	if len(queries) != 2 || queries[0].Get("prefix") != "in/" || queries[1].Get("continuation-token") != "next page" {
+		t.Fatalf("queries %v", queries)
+	}
+}
+
+func TestS3Get(t *testing.T) {
+	store := newTestStore(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/docs/in/a.csv" {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, "sku,quantity\n")
+	})
+
+	data, err := store.Get(context.Background(), "in/a.csv", 64)
+	if err != nil || string(data) != "sku,quantity\n" {
+		t.Fatalf("got %q, %v", data, err)
+	}
+	if _, err := store.Get(context.Background(), "in/a.csv", 4); err == nil {
+		t.Error("read an object over the size limit")
+	}
+	if _, err := store.Get(context.Background(), "in/missing.csv", 64); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("err = %v, want the status", err)
+	}
+}
@@ -0,0 +1,61 @@
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrRejected is returned by a Scanner for a file that must not be stored
+var ErrRejected = errors.New("objectstore: file rejected by scanner")
+
+// Scanner checks an uploaded file, e.g. for malware, before it is stored
+type Scanner interface {
+	Scan(ctx context.Context, fileName string, body io.Reader) error
+}
+
+// HTTPScanner hands files to a scanning service over HTTP. The file is
+// POSTed as the request body with its name in X-File-Name; 200 or 204
+// means clean, 406 or 422 rejects the file and any other answer fails the
+// upload.
+type HTTPScanner struct {
+	url    string
+	client *http.Client
+}
+
+func NewHTTPScanner(url string) *HTTPScanner {
+	return &HTTPScanner{
+		url:    url,
+		client: &http.Client{Timeout: time.Minute},
+	}
+}
+
+func (s *HTTPScanner) Scan(ctx context.Context, fileName string, body io.Reader) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("X-File-Name", fileName)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("objectstore: scan: %w", err)
+	}
+	defer resp.Body.Close()
+	reason, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent:
+		return nil
+	case http.StatusNotAcceptable, http.StatusUnprocessableEntity:
+		if msg := strings.TrimSpace(string(reason)); msg != "" {
+			return fmt.Errorf("%w: %s", ErrRejected, msg)
+		}
+		return ErrRejected
+	}
+	return fmt.Errorf("objectstore: scan: unexpected status %d", resp.StatusCode)
+}
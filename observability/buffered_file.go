@@ -0,0 +1,182 @@
+package observability
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// When the log file is synced to disk, as in LOG_FILE_FSYNC
+const (
+	// FsyncNever leaves writing the file to disk to the OS
+	FsyncNever = "never"
+	// FsyncInterval syncs after each periodic flush
+	FsyncInterval = "interval"
+	// FsyncAlways flushes and syncs every record, as slow as unbuffered
+	// writes and then some
+	FsyncAlways = "always"
+)
+
+// bufferedFile batches the records written to the log file in memory,
+// writing them out every flush interval or once the buffer is full, so that
+// logging a record rarely waits on the file
+type bufferedFile struct {
+	mu     sync.Mutex
+	file   *os.File
+	buf    *bufio.Writer
+	fsync  string
+	closed bool
+	// Records in buf, each Write being one
+	pending int
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newBufferedFile(file *os.File, size int, interval time.Duration, fsync string) *bufferedFile {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	f := &bufferedFile{
+		file:  file,
+		buf:   bufio.NewWriterSize(file, size),
+		fsync: fsync,
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go f.flushEvery(interval)
+	registerLogCloser(f.close)
+	registerLogQueue("file", f.queued)
+	return f
+}
+
+func (f *bufferedFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	// Records logged after shutdown go straight to the file
+	if f.closed {
+		return f.file.Write(p)
+	}
+	before := f.buf.Buffered()
+	n, err := f.buf.Write(p)
+	if err != nil {
+		// The record is lost with the buffer
+		f.pending++
+		f.dropLocked()
+		return n, err
+	}
+	if f.buf.Buffered() < before+len(p) {
+		// The buffer filled up and was written out
+		f.pending = 0
+	}
+	f.pending++
+	if f.fsync == FsyncAlways {
+		return n, f.flushLocked()
+	}
+	return n, nil
+}
+
+// flushLocked writes out the buffer, syncing unless fsync is never. A
+// failed write drops what was buffered, so that the buffer takes records
+// again once the file can be written.
+func (f *bufferedFile) flushLocked() error {
+	if f.pending == 0 {
+		return nil
+	}
+	start := time.Now()
+	defer func() {
+		logSinkSendDuration.WithLabelValues("file").Observe(time.Since(start).Seconds())
+	}()
+	if err := f.buf.Flush(); err != nil {
+		f.dropLocked()
+		return err
+	}
+	f.pending = 0
+	if f.fsync == FsyncNever {
+		return nil
+	}
+	return f.file.Sync()
+}
+
+// dropLocked empties the buffer after a failed write, counting the records
+// it held
+func (f *bufferedFile) dropLocked() {
+	logRecordsDropped.WithLabelValues("file", LogDropWriteFailed).Add(float64(f.pending))
+	f.pending = 0
+	f.buf.Reset(f.file)
+}
+
+// queued returns the number of records in the buffer
+func (f *bufferedFile) queued() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.pending
+}
+
+func (f *bufferedFile) flushEvery(interval time.Duration) {
+	defer close(f.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			f.mu.Lock()
+			err := f.flushLocked()
+			f.mu.Unlock()
+			if err != nil {
+				// Logging it would only buffer it again
+				fmt.Fprintf(os.Stderr, "flush log file %s: %v\n", f.file.Name(), err)
+			}
+		case <-f.stop:
+			return
+		}
+	}
+}
+
+// close stops the periodic flush and writes out the buffer
+func (f *bufferedFile) close(context.Context) error {
+	f.closeOnce.Do(func() { close(f.stop) })
+	<-f.done
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+	if err := f.flushLocked(); err != nil {
+		return fmt.Errorf("flush log file %s: %w", f.file.Name(), err)
+	}
+	return nil
+}
+
+// logClosers flush the log writers that buffer records, see FlushLogs
+var logClosers struct {
+	mu      sync.Mutex
+	closers []func(context.Context) error
+}
+
+func registerLogCloser(flush func(context.Context) error) {
+	logClosers.mu.Lock()
+	defer logClosers.mu.Unlock()
+	logClosers.closers = append(logClosers.closers, flush)
+}
+
+// FlushLogs writes out the records buffered for the log file and the syslog
+// receiver, waiting up to ctx. Called on shutdown, once nothing else is
+// logged.
+func FlushLogs(ctx context.Context) error {
+	logClosers.mu.Lock()
+	defer logClosers.mu.Unlock()
+	var errs []error
+	for _, flush := range logClosers.closers {
+		if err := flush(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
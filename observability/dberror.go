@@ -0,0 +1,64 @@
+package observability
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Categories returned by ClassifyDBError, used as the error_type label of
+// database_operation_errors_total
+const (
+	DBErrNotFound        = "not_found"
+	DBErrUniqueViolation = "unique_violation"
+	DBErrFKViolation     = "fk_violation"
+	DBErrCheckViolation  = "check_violation"
+	DBErrTimeout         = "timeout"
+	DBErrConnection      = "connection"
+	DBErrUnknown         = "unknown"
+)
+
+// ClassifyDBError maps a pgx error to a small fixed set of categories. It
+// returns an empty string for a nil error.
+func ClassifyDBError(err error) string {
+	if err == nil {
+		return ""
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return DBErrNotFound
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch {
+		case pgErr.Code == "23505":
+			return DBErrUniqueViolation
+		case pgErr.Code == "23503":
+			return DBErrFKViolation
+		case pgErr.Code == "23514":
+			return DBErrCheckViolation
+		// query_canceled is what statement_timeout raises
+		case pgErr.Code == "57014":
+			return DBErrTimeout
+		// Class 08 covers connection exceptions, 57P01-57P03 a server
+		// shutting down or not accepting connections yet
+		case strings.HasPrefix(pgErr.Code, "08"), pgErr.Code == "57P01", pgErr.Code == "57P02", pgErr.Code == "57P03":
+			return DBErrConnection
+		}
+		return DBErrUnknown
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || pgconn.Timeout(err) {
+		return DBErrTimeout
+	}
+	var connectErr *pgconn.ConnectError
+	var netErr net.Error
+	if errors.As(err, &connectErr) || errors.As(err, &netErr) || pgconn.SafeToRetry(err) {
+		return DBErrConnection
+	}
+	return DBErrUnknown
+}
@@ -0,0 +1,51 @@
+package observability
+
+import (
+	"log/slog"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// LogLevel is shared by every log handler so the level can change at runtime
+var LogLevel = new(slog.LevelVar)
+
+// SetLogLevel updates the level of the configured handlers and of the
+// default stdout logger used before a handler is installed
+func SetLogLevel(level slog.Level) {
+	LogLevel.Set(level)
+	slog.SetLogLoggerLevel(level)
+}
+
+// traceSampler is installed on the tracer provider, its ratio can be changed
+// without rebuilding the provider
+var traceSampler = newDynamicSampler(1)
+
+// SetTraceSampleRatio changes the fraction of new traces that are sampled,
+// child spans keep following their parent's decision
+func SetTraceSampleRatio(ratio float64) {
+	traceSampler.set(ratio)
+}
+
+type dynamicSampler struct {
+	current atomic.Pointer[trace.Sampler]
+}
+
+func newDynamicSampler(ratio float64) *dynamicSampler {
+	s := &dynamicSampler{}
+	s.set(ratio)
+	return s
+}
+
+func (s *dynamicSampler) set(ratio float64) {
+	sampler := trace.ParentBased(trace.TraceIDRatioBased(ratio))
+	s.current.Store(&sampler)
+}
+
+func (s *dynamicSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	return (*s.current.Load()).ShouldSample(p)
+}
+
+func (s *dynamicSampler) Description() string {
+	return "Dynamic{" + (*s.current.Load()).Description() + "}"
+}
@@ -0,0 +1,78 @@
+package observability
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+// Aggregations of OTel histograms, as in
+// OTEL_EXPORTER_OTLP_METRICS_DEFAULT_HISTOGRAM_AGGREGATION
+const (
+	HistogramExplicitBuckets = "explicit_bucket_histogram"
+	HistogramExponential     = "base2_exponential_bucket_histogram"
+)
+
+// Resolution of native and exponential histograms: each bucket is at most
+// 1.1 times as wide as the one before, from up to 160 buckets
+const (
+	nativeHistogramBucketFactor = 1.1
+	nativeHistogramMaxBuckets   = 160
+)
+
+// HistogramConfig sets the buckets of the latency histograms. Slow report
+// queries and tenant exports need wider ones than the defaults.
+type HistogramConfig struct {
+	// Upper bounds in seconds of http_request_duration_seconds,
+	// database_operation_duration_seconds and operation_duration_seconds,
+	// nil for the Prometheus defaults
+	HTTPBuckets      []float64
+	DBBuckets        []float64
+	OperationBuckets []float64
+	// Native exposes the latency histograms as Prometheus native histograms
+	// as well, which scrapers negotiating protobuf read instead of the
+	// buckets
+	Native bool
+	// OTelAggregation is HistogramExplicitBuckets, using the buckets above,
+	// or HistogramExponential
+	OTelAggregation string
+}
+
+// opts returns the options of a latency histogram with buckets, native as
+// well when c says so
+func (c HistogramConfig) opts(name, help string, buckets []float64) prometheus.HistogramOpts {
+	opts := prometheus.HistogramOpts{Name: name, Help: help, Buckets: buckets}
+	if c.Native {
+		opts.NativeHistogramBucketFactor = nativeHistogramBucketFactor
+		opts.NativeHistogramMaxBucketNumber = nativeHistogramMaxBuckets
+		// Halves the resolution rather than resetting more often than hourly
+		// when the buckets run out
+		opts.NativeHistogramMinResetDuration = time.Hour
+	}
+	return opts
+}
+
+// views returns the OTel views giving the histograms of CreateMetrics and
+// CreateBusinessMetrics their buckets, or an exponential aggregation
+func (c HistogramConfig) views() []sdkmetric.View {
+	if c.OTelAggregation == HistogramExponential {
+		return []sdkmetric.View{sdkmetric.NewView(
+			sdkmetric.Instrument{Kind: sdkmetric.InstrumentKindHistogram},
+			sdkmetric.Stream{Aggregation: sdkmetric.AggregationBase2ExponentialHistogram{
+				MaxSize:  nativeHistogramMaxBuckets,
+				MaxScale: 20,
+			}},
+		)}
+	}
+	return []sdkmetric.View{
+		sdkmetric.NewView(
+			sdkmetric.Instrument{Name: "http_request_duration_seconds"},
+			sdkmetric.Stream{Aggregation: sdkmetric.AggregationExplicitBucketHistogram{Boundaries: c.HTTPBuckets}},
+		),
+		sdkmetric.NewView(
+			sdkmetric.Instrument{Name: "database_operation_duration_seconds"},
+			sdkmetric.Stream{Aggregation: sdkmetric.AggregationExplicitBucketHistogram{Boundaries: c.DBBuckets}},
+		),
+	}
+}
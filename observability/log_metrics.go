@@ -0,0 +1,138 @@
+package observability
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Why a sink dropped a record, the reason label of log_records_dropped_total
+const (
+	// The send failed, the record is in the failover file when there is one
+	LogDropSendFailed = "send_failed"
+	// The sink was down, the record went to the failover file when there is
+	// one
+	LogDropSinkDown = "sink_down"
+	// The queue of the sink was full
+	LogDropQueueFull = "queue_full"
+	// Writing out the buffer failed
+	LogDropWriteFailed = "write_failed"
+)
+
+// The logging pipeline is set up before the server and its metrics, so its
+// own metrics are package level and exposed by NewLoggingCollector
+var (
+	logRecordsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "log_records_total",
+		Help: "Log records produced, by level.",
+	}, []string{"level"})
+	logRecordsDropped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "log_records_dropped_total",
+		Help: "Log records a sink did not receive or write, by reason.",
+	}, []string{"sink", "reason"})
+	logSinkSendDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "log_sink_send_duration_seconds",
+		Help:    "Time taken to send a log record to a sink, or to write out the buffer of the log file.",
+		Buckets: []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+	}, []string{"sink"})
+)
+
+// logQueues report the records waiting in the async log sinks, by sink
+var logQueues struct {
+	mu     sync.Mutex
+	depths map[string]func() int
+}
+
+// registerLogQueue reports depth as the queue of sink, replacing the one of
+// a sink set up before
+func registerLogQueue(sink string, depth func() int) {
+	logQueues.mu.Lock()
+	defer logQueues.mu.Unlock()
+	if logQueues.depths == nil {
+		logQueues.depths = map[string]func() int{}
+	}
+	logQueues.depths[sink] = depth
+}
+
+// countingHandler counts the records handled by their level
+type countingHandler struct {
+	next slog.Handler
+}
+
+func (h countingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h countingHandler) Handle(ctx context.Context, record slog.Record) error {
+	logRecordsTotal.WithLabelValues(strings.ToLower(record.Level.String())).Inc()
+	return h.next.Handle(ctx, record)
+}
+
+func (h countingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return countingHandler{next: h.next.WithAttrs(attrs)}
+}
+
+func (h countingHandler) WithGroup(name string) slog.Handler {
+	return countingHandler{next: h.next.WithGroup(name)}
+}
+
+// loggingCollector exposes the health of the remote log sinks, read from
+// LogSinks, the queue depths and the metrics of the logging pipeline
+type loggingCollector struct {
+	up          *prometheus.Desc
+	consecutive *prometheus.Desc
+	failures    *prometheus.Desc
+	lastSuccess *prometheus.Desc
+	queueDepth  *prometheus.Desc
+}
+
+// NewLoggingCollector returns the collector of the log_* metrics
+func NewLoggingCollector() prometheus.Collector {
+	labels := []string{"sink"}
+	return &loggingCollector{
+		up:          prometheus.NewDesc("log_sink_up", "Whether the remote log sink receives the logs, 0 while they fail over.", labels, nil),
+		consecutive: prometheus.NewDesc("log_sink_consecutive_failures", "Failed sends to the remote log sink since its last success.", labels, nil),
+		failures:    prometheus.NewDesc("log_sink_failures_total", "Failed sends to the remote log sink.", labels, nil),
+		lastSuccess: prometheus.NewDesc("log_sink_last_success_timestamp_seconds", "Time of the last successful send or probe of the remote log sink.", labels, nil),
+		queueDepth:  prometheus.NewDesc("log_queue_depth", "Log records waiting to be sent or written by an async sink.", labels, nil),
+	}
+}
+
+func (c *loggingCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.up
+	ch <- c.consecutive
+	ch <- c.failures
+	ch <- c.lastSuccess
+	ch <- c.queueDepth
+	logRecordsTotal.Describe(ch)
+	logRecordsDropped.Describe(ch)
+	logSinkSendDuration.Describe(ch)
+}
+
+func (c *loggingCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, s := range LogSinks() {
+		up := 1.0
+		if s.State != LogSinkUp {
+			up = 0
+		}
+		ch <- prometheus.MustNewConstMetric(c.up, prometheus.GaugeValue, up, s.Sink)
+		ch <- prometheus.MustNewConstMetric(c.consecutive, prometheus.GaugeValue, float64(s.ConsecutiveFailures), s.Sink)
+		ch <- prometheus.MustNewConstMetric(c.failures, prometheus.CounterValue, float64(s.Failures), s.Sink)
+		var lastSuccess float64
+		if !s.LastSuccess.IsZero() {
+			lastSuccess = float64(s.LastSuccess.UnixNano()) / 1e9
+		}
+		ch <- prometheus.MustNewConstMetric(c.lastSuccess, prometheus.GaugeValue, lastSuccess, s.Sink)
+	}
+	logQueues.mu.Lock()
+	for sink, depth := range logQueues.depths {
+		ch <- prometheus.MustNewConstMetric(c.queueDepth, prometheus.GaugeValue, float64(depth()), sink)
+	}
+	logQueues.mu.Unlock()
+	logRecordsTotal.Collect(ch)
+	logRecordsDropped.Collect(ch)
+	logSinkSendDuration.Collect(ch)
+}
@@ -0,0 +1,236 @@
+package observability
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// States of a remote log sink
+const (
+	LogSinkUp         = "up"
+	LogSinkFailedOver = "failed_over"
+)
+
+// LogFailover is what a remote log sink does when it can't be reached
+type LogFailover struct {
+	// Consecutive failed sends that take the sink down
+	FailureThreshold int
+	// Time between the recovery probes of a sink that is down
+	ProbeInterval time.Duration
+	// File receiving the records the sink failed to send and all records
+	// while it is down. Remote sinks copy every record to stdout anyway, so
+	// without a file they lose nothing there.
+	FilePath string
+}
+
+// LogSinkStatus is the health of a remote log sink
+type LogSinkStatus struct {
+	Sink                string    `json:"sink"`
+	State               string    `json:"state"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	Failures            int64     `json:"failures"`
+	LastSuccess         time.Time `json:"last_success,omitzero"`
+	LastError           string    `json:"last_error,omitempty"`
+	FailoverFile        string    `json:"failover_file,omitempty"`
+}
+
+// logSink tracks the sends of a remote sink, shared by the handlers derived
+// from its handler with WithAttrs and WithGroup
+type logSink struct {
+	name     string
+	failover LogFailover
+	// probe checks the sink is back without sending a record
+	probe func(context.Context) error
+
+	mu          sync.Mutex
+	down        bool
+	consecutive int
+	lastSuccess time.Time
+	lastError   string
+	failures    atomic.Int64
+	// Records being sent
+	inflight atomic.Int64
+}
+
+// logSinks are the remote sinks set up, reported by LogSinks
+var logSinks struct {
+	mu    sync.Mutex
+	sinks []*logSink
+}
+
+func newLogSink(name string, failover LogFailover, probe func(context.Context) error) *logSink {
+	if failover.FailureThreshold < 1 {
+		failover.FailureThreshold = 1
+	}
+	if failover.ProbeInterval <= 0 {
+		failover.ProbeInterval = 30 * time.Second
+	}
+	s := &logSink{name: name, failover: failover, probe: probe}
+	logSinks.mu.Lock()
+	defer logSinks.mu.Unlock()
+	// Setting a sink up again replaces it
+	logSinks.sinks = slices.DeleteFunc(logSinks.sinks, func(other *logSink) bool { return other.name == name })
+	logSinks.sinks = append(logSinks.sinks, s)
+	registerLogQueue(name, func() int { return int(s.inflight.Load()) })
+	return s
+}
+
+// send pushes record in the background, writing it to failover when the
+// push fails
+func (s *logSink) send(record slog.Record, push func(slog.Record) error, failover slog.Handler) {
+	s.inflight.Add(1)
+	go func() {
+		defer s.inflight.Add(-1)
+		start := time.Now()
+		err := push(record)
+		logSinkSendDuration.WithLabelValues(s.name).Observe(time.Since(start).Seconds())
+		if err != nil {
+			if failover != nil {
+				_ = failover.Handle(context.Background(), record)
+			}
+			logRecordsDropped.WithLabelValues(s.name, LogDropSendFailed).Inc()
+			s.failed(err)
+			return
+		}
+		s.succeeded()
+	}()
+}
+
+// skip writes record to failover instead of the sink while it is down
+func (s *logSink) skip(ctx context.Context, record slog.Record, failover slog.Handler) error {
+	logRecordsDropped.WithLabelValues(s.name, LogDropSinkDown).Inc()
+	if failover != nil {
+		return failover.Handle(ctx, record)
+	}
+	return nil
+}
+
+// isDown reports whether records go to the failover instead of the sink
+func (s *logSink) isDown() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.down
+}
+
+func (s *logSink) succeeded() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.consecutive = 0
+	s.lastSuccess = time.Now()
+	s.lastError = ""
+}
+
+// failed counts a failed send, taking the sink down and starting its
+// recovery probe at the threshold
+func (s *logSink) failed(err error) {
+	s.failures.Add(1)
+	s.mu.Lock()
+	s.consecutive++
+	s.lastError = err.Error()
+	goingDown := !s.down && s.consecutive >= s.failover.FailureThreshold
+	if goingDown {
+		s.down = true
+	}
+	consecutive := s.consecutive
+	s.mu.Unlock()
+
+	if goingDown {
+		// Logged once the sink is down, so the record itself fails over
+		slog.Warn("Log sink is down, failing over",
+			slog.String("sink", s.name),
+			slog.Int("consecutive_failures", consecutive),
+			slog.String("failover_file", s.failover.FilePath),
+			slog.Any("error", err))
+		go s.probeUntilUp()
+	}
+}
+
+// probeUntilUp probes the sink every ProbeInterval and brings it back up on
+// the first success
+func (s *logSink) probeUntilUp() {
+	ticker := time.NewTicker(s.failover.ProbeInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		err := s.probe(ctx)
+		cancel()
+		if err != nil {
+			s.mu.Lock()
+			s.lastError = err.Error()
+			s.mu.Unlock()
+			continue
+		}
+		s.mu.Lock()
+		s.down = false
+		s.consecutive = 0
+		s.lastSuccess = time.Now()
+		s.lastError = ""
+		s.mu.Unlock()
+		slog.Info("Log sink recovered", slog.String("sink", s.name))
+		return
+	}
+}
+
+func (s *logSink) status() LogSinkStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := LogSinkUp
+	if s.down {
+		state = LogSinkFailedOver
+	}
+	return LogSinkStatus{
+		Sink:                s.name,
+		State:               state,
+		ConsecutiveFailures: s.consecutive,
+		Failures:            s.failures.Load(),
+		LastSuccess:         s.lastSuccess,
+		LastError:           s.lastError,
+		FailoverFile:        s.failover.FilePath,
+	}
+}
+
+// LogSinks returns the health of the remote log sinks set up, none when
+// logs only go to a file or stdout
+func LogSinks() []LogSinkStatus {
+	logSinks.mu.Lock()
+	defer logSinks.mu.Unlock()
+	statuses := make([]LogSinkStatus, len(logSinks.sinks))
+	for i, s := range logSinks.sinks {
+		statuses[i] = s.status()
+	}
+	return statuses
+}
+
+// handler opens the failover file of f, nil without one
+func (f LogFailover) handler(level slog.Leveler) (slog.Handler, error) {
+	if f.FilePath == "" {
+		return nil, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(f.FilePath), 0755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(f.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("open log failover file: %w", err)
+	}
+	return slog.NewJSONHandler(file, &slog.HandlerOptions{Level: level}), nil
+}
+
+// checkResponse turns a response of a sink other than 2xx into an error
+func checkResponse(resp *http.Response) error {
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return fmt.Errorf("status %d: %s", resp.StatusCode, body)
+	}
+	return nil
+}
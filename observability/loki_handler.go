@@ -16,19 +16,23 @@ type LokiHandler struct {
 	client   *http.Client
 	lokiURL  string
 	labels   map[string]string
-	level    slog.Level
+	level    slog.Leveler
 	fallback slog.Handler // Fallback to stdout if Loki is unavailable
+	// Receives the records while Loki is down, nil without a failover file
+	failover slog.Handler
+	sink     *logSink
 }
 
 // LokiConfig holds configuration for Loki handler
 type LokiConfig struct {
-	URL    string
-	Labels map[string]string
-	Level  slog.Level
+	URL      string
+	Labels   map[string]string
+	Level    slog.Leveler
+	Failover LogFailover
 }
 
 // NewLokiHandler creates a new Loki handler
-func NewLokiHandler(config LokiConfig) *LokiHandler {
+func NewLokiHandler(config LokiConfig) (*LokiHandler, error) {
 	if config.Labels == nil {
 		config.Labels = make(map[string]string)
 	}
@@ -41,18 +45,37 @@ func NewLokiHandler(config LokiConfig) *LokiHandler {
 		config.Labels["job"] = "go-app"
 	}
 
+	failover, err := config.Failover.handler(config.Level)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: 5 * time.Second}
+	// Loki answers /ready once it accepts pushes again
+	probe := func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, config.URL+"/ready", nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		return checkResponse(resp)
+	}
 	return &LokiHandler{
-		client:   &http.Client{Timeout: 5 * time.Second},
+		client:   client,
 		lokiURL:  config.URL + "/loki/api/v1/push",
 		labels:   config.Labels,
 		level:    config.Level,
 		fallback: slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: config.Level}),
-	}
+		failover: failover,
+		sink:     newLogSink("loki", config.Failover, probe),
+	}, nil
 }
 
 // Enabled reports whether the handler handles records at the given level
 func (h *LokiHandler) Enabled(_ context.Context, level slog.Level) bool {
-	return level >= h.level
+	return level >= h.level.Level()
 }
 
 // Handle processes a log record
@@ -62,8 +85,12 @@ func (h *LokiHandler) Handle(ctx context.Context, record slog.Record) error {
 		return err
 	}
 
+	if h.sink.isDown() {
+		return h.sink.skip(ctx, record, h.failover)
+	}
+
 	// Send to Loki asynchronously to avoid blocking
-	go h.sendToLoki(record)
+	h.sink.send(record, h.sendToLoki, h.failover)
 	return nil
 }
 
@@ -81,24 +108,34 @@ func (h *LokiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
 		}
 	}
 
-	return &LokiHandler{
+	handler := &LokiHandler{
 		client:   h.client,
 		lokiURL:  h.lokiURL,
 		labels:   newLabels,
 		level:    h.level,
 		fallback: h.fallback.WithAttrs(attrs),
+		sink:     h.sink,
+	}
+	if h.failover != nil {
+		handler.failover = h.failover.WithAttrs(attrs)
 	}
+	return handler
 }
 
 // WithGroup returns a new handler with a group
 func (h *LokiHandler) WithGroup(name string) slog.Handler {
-	return &LokiHandler{
+	handler := &LokiHandler{
 		client:   h.client,
 		lokiURL:  h.lokiURL,
 		labels:   h.labels,
 		level:    h.level,
 		fallback: h.fallback.WithGroup(name),
+		sink:     h.sink,
+	}
+	if h.failover != nil {
+		handler.failover = h.failover.WithGroup(name)
 	}
+	return handler
 }
 
 // LokiPayload represents the Loki push API payload
@@ -113,7 +150,7 @@ type LokiStream struct {
 }
 
 // sendToLoki sends the log record to Loki
-func (h *LokiHandler) sendToLoki(record slog.Record) {
+func (h *LokiHandler) sendToLoki(record slog.Record) error {
 	// Build the log entry
 	logEntry := map[string]interface{}{
 		"timestamp": record.Time.Format(time.RFC3339Nano),
@@ -130,7 +167,7 @@ func (h *LokiHandler) sendToLoki(record slog.Record) {
 	// Convert to JSON
 	logJSON, err := json.Marshal(logEntry)
 	if err != nil {
-		return // Silently fail to avoid log loops
+		return err
 	}
 
 	// Create Loki payload
@@ -151,25 +188,26 @@ func (h *LokiHandler) sendToLoki(record slog.Record) {
 	// Send to Loki
 	payloadJSON, err := json.Marshal(payload)
 	if err != nil {
-		return
+		return err
 	}
 
 	req, err := http.NewRequest("POST", h.lokiURL, bytes.NewBuffer(payloadJSON))
 	if err != nil {
-		return
+		return err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := h.client.Do(req)
 	if err != nil {
-		return // Silently fail
+		return err
 	}
-	defer resp.Body.Close()
+	return checkResponse(resp)
 }
 
-// SetupDirectLokiLogging configures slog to send logs directly to Loki
-func SetupDirectLokiLogging(lokiURL string, serviceName string) error {
+// SetupDirectLokiLogging configures slog to send logs directly to Loki,
+// failing over as failover says while it is down
+func SetupDirectLokiLogging(lokiURL string, serviceName string, failover LogFailover) error {
 	config := LokiConfig{
 		URL: lokiURL,
 		Labels: map[string]string{
@@ -177,11 +215,15 @@ func SetupDirectLokiLogging(lokiURL string, serviceName string) error {
 			"job":     "go-direct",
 			"source":  "application",
 		},
-		Level: slog.LevelInfo,
+		Level:    LogLevel,
+		Failover: failover,
 	}
 
-	handler := NewLokiHandler(config)
-	logger := slog.New(handler)
+	handler, err := NewLokiHandler(config)
+	if err != nil {
+		return err
+	}
+	logger := newLogger(handler)
 	slog.SetDefault(logger)
 
 	slog.Info("Direct Loki logging configured",
@@ -10,20 +10,21 @@ import (
 	"strings"
 	"time"
 
+	"go.opentelemetry.io/contrib/instrumentation/runtime"
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/propagation"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
-	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	"go.opentelemetry.io/otel/sdk/resource"
 	"go.opentelemetry.io/otel/sdk/trace"
-	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 )
 
+// metricsExportInterval is how often the meter provider exports
+const metricsExportInterval = 30 * time.Second
+
 // SetupOTelSDK bootstraps the OpenTelemetry pipeline for shipping to otel-collector.
 // If it does not return an error, make sure to call shutdown for proper cleanup.
-func SetupOTelSDK(ctx context.Context, serviceName, serviceVersion, otelCollectorEndpoint, otelHeaders string) (func(context.Context) error, error) {
+func SetupOTelSDK(ctx context.Context, serviceName, serviceVersion string, exporter OTLPExporterConfig, resourceAttributes string, histograms HistogramConfig) (func(context.Context) error, error) {
 	var shutdownFuncs []func(context.Context) error
 
 	// shutdown calls cleanup functions registered via shutdownFuncs.
@@ -42,7 +43,7 @@ func SetupOTelSDK(ctx context.Context, serviceName, serviceVersion, otelCollecto
 	}
 
 	// Create resource with service information
-	res, err := newResource(serviceName, serviceVersion)
+	res, err := newResource(serviceName, serviceVersion, resourceAttributes)
 	if err != nil {
 		return shutdown, handleErr(err)
 	}
@@ -52,7 +53,7 @@ func SetupOTelSDK(ctx context.Context, serviceName, serviceVersion, otelCollecto
 	otel.SetTextMapPropagator(prop)
 
 	// Set up trace provider
-	tracerProvider, err := newTracerProvider(ctx, res, otelCollectorEndpoint, otelHeaders)
+	tracerProvider, err := newTracerProvider(ctx, res, exporter)
 	if err != nil {
 		return shutdown, handleErr(err)
 	}
@@ -60,24 +61,19 @@ func SetupOTelSDK(ctx context.Context, serviceName, serviceVersion, otelCollecto
 	otel.SetTracerProvider(tracerProvider)
 
 	// Set up meter provider
-	meterProvider, err := newMeterProvider(ctx, res, otelCollectorEndpoint, otelHeaders)
+	meterProvider, err := newMeterProvider(ctx, res, exporter, histograms)
 	if err != nil {
 		return shutdown, handleErr(err)
 	}
 	shutdownFuncs = append(shutdownFuncs, meterProvider.Shutdown)
 	otel.SetMeterProvider(meterProvider)
 
-	return shutdown, nil
-}
+	// The go.* runtime metrics, /metrics has the Prometheus go_* equivalents
+	if err := runtime.Start(runtime.WithMeterProvider(meterProvider)); err != nil {
+		return shutdown, handleErr(err)
+	}
 
-func newResource(serviceName, serviceVersion string) (*resource.Resource, error) {
-	// Create resource without merging to avoid schema conflicts
-	return resource.NewWithAttributes(
-		semconv.SchemaURL,
-		semconv.ServiceName(serviceName),
-		semconv.ServiceVersion(serviceVersion),
-		semconv.ServiceInstanceID("warehouse-service"),
-	), nil
+	return shutdown, nil
 }
 
 func newPropagator() propagation.TextMapPropagator {
@@ -87,42 +83,15 @@ func newPropagator() propagation.TextMapPropagator {
 	)
 }
 
-func newTracerProvider(ctx context.Context, res *resource.Resource, endpoint, headers string) (*trace.TracerProvider, error) {
-	// Debug logging
+func newTracerProvider(ctx context.Context, res *resource.Resource, exporter OTLPExporterConfig) (*trace.TracerProvider, error) {
 	slog.Info("Configuring OTLP tracer",
-		slog.String("endpoint", endpoint),
-		slog.String("headers_raw", headers))
+		slog.String("endpoint", exporter.Endpoint),
+		slog.String("protocol", exporter.Protocol),
+		slog.Bool("insecure", exporter.Insecure),
+		slog.String("compression", exporter.Compression),
+		slog.Int("header_count", len(parseHeaders(exporter.Headers))))
 
-	// Parse headers from the environment variable format
-	headerMap := make(map[string]string)
-	if headers != "" {
-		// Headers are in format "key1=value1,key2=value2"
-		pairs := strings.Split(headers, ",")
-		for _, pair := range pairs {
-			if kv := strings.SplitN(pair, "=", 2); len(kv) == 2 {
-				headerMap[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
-			}
-		}
-		slog.Info("OTLP headers configured", slog.Int("header_count", len(headerMap)))
-	}
-
-	slog.Info("Using OTLP endpoint", slog.String("endpoint", endpoint))
-
-	// For local collector, use simple endpoint configuration
-	var err error
-
-	// Configure the exporter with proper URL path for Grafana Cloud
-	options := []otlptracehttp.Option{
-		otlptracehttp.WithInsecure(),
-		otlptracehttp.WithEndpoint(endpoint),
-		// otlptracehttp.WithURLPath("/otlp/v1/traces"), // Grafana Cloud specific path
-	}
-
-	if len(headerMap) > 0 {
-		options = append(options, otlptracehttp.WithHeaders(headerMap))
-	}
-
-	traceExporter, err := otlptracehttp.New(ctx, options...)
+	traceExporter, err := newTraceExporter(ctx, exporter)
 	if err != nil {
 		return nil, err
 	}
@@ -133,70 +102,57 @@ func newTracerProvider(ctx context.Context, res *resource.Resource, endpoint, he
 			trace.WithMaxExportBatchSize(512),
 		),
 		trace.WithResource(res),
-		trace.WithSampler(trace.AlwaysSample()),
+		trace.WithSampler(traceSampler),
 	)
 	return tracerProvider, nil
 }
 
-func newMeterProvider(ctx context.Context, res *resource.Resource, endpoint, headers string) (*sdkmetric.MeterProvider, error) {
-	// Debug logging
-	slog.Info("Configuring OTLP metrics exporter", slog.String("endpoint", endpoint))
+func newMeterProvider(ctx context.Context, res *resource.Resource, exporter OTLPExporterConfig, histograms HistogramConfig) (*sdkmetric.MeterProvider, error) {
+	slog.Info("Configuring OTLP metrics exporter",
+		slog.String("endpoint", exporter.Endpoint),
+		slog.String("protocol", exporter.Protocol))
 
-	// Parse headers from the environment variable format (if any)
-	headerMap := make(map[string]string)
-	if headers != "" {
-		pairs := strings.Split(headers, ",")
-		for _, pair := range pairs {
-			if kv := strings.SplitN(pair, "=", 2); len(kv) == 2 {
-				headerMap[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
-			}
-		}
+	metricExporter, err := newMetricExporter(ctx, exporter)
+	if err != nil {
+		return nil, err
 	}
 
-	// We need to add the OTLP metrics HTTP exporter
-	// For now, let's create a basic meter provider that will work locally
-	// TODO: Add OTLP metrics exporter when the import is available
 	meterProvider := sdkmetric.NewMeterProvider(
 		sdkmetric.WithResource(res),
-		// Add a periodic reader that exports every 30 seconds
+		sdkmetric.WithView(histograms.views()...),
 		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(
-			// For now, we'll use a no-op exporter until we add the OTLP metrics exporter
-			&noOpMetricExporter{},
-			sdkmetric.WithInterval(30*time.Second),
+			metricExporter,
+			sdkmetric.WithInterval(metricsExportInterval),
 		)),
 	)
-
-	slog.Info("Metrics provider configured (local only for now)")
 	return meterProvider, nil
 }
 
-// Temporary no-op exporter until we add OTLP metrics support
-type noOpMetricExporter struct{}
-
-func (e *noOpMetricExporter) Temporality(sdkmetric.InstrumentKind) metricdata.Temporality {
-	return metricdata.CumulativeTemporality
-}
-
-func (e *noOpMetricExporter) Aggregation(kind sdkmetric.InstrumentKind) sdkmetric.Aggregation {
-	return sdkmetric.DefaultAggregationSelector(kind)
-}
-
-func (e *noOpMetricExporter) Export(context.Context, *metricdata.ResourceMetrics) error {
-	// Log that metrics are being generated
-	slog.Debug("Metrics exported (no-op)")
-	return nil
+// parseHeaders reads headers in the "key1=value1,key2=value2" format
+func parseHeaders(headers string) map[string]string {
+	headerMap := make(map[string]string)
+	for _, pair := range strings.Split(headers, ",") {
+		if kv := strings.SplitN(pair, "=", 2); len(kv) == 2 {
+			headerMap[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+		}
+	}
+	return headerMap
 }
 
-func (e *noOpMetricExporter) ForceFlush(context.Context) error { return nil }
-func (e *noOpMetricExporter) Shutdown(context.Context) error   { return nil }
-
 // GetLogger returns a structured logger that integrates with OpenTelemetry
 func GetLogger(name string) *slog.Logger {
-	return slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelInfo,
+	return newLogger(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: LogLevel,
 	})).With("service", name)
 }
 
+// SetupStdoutLogging configures slog to write JSON logs to stdout
+func SetupStdoutLogging() {
+	slog.SetDefault(newLogger(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: LogLevel,
+	})))
+}
+
 // SetupFileLogger configures slog to write JSON logs to a file
 func SetupFileLogger(logFilePath string) error {
 	// Create logs directory if it doesn't exist
@@ -216,12 +172,12 @@ func SetupFileLogger(logFilePath string) error {
 
 	// Create JSON handler that writes to both stdout and file
 	jsonHandler := slog.NewJSONHandler(multiWriter, &slog.HandlerOptions{
-		Level:     slog.LevelInfo,
+		Level:     LogLevel,
 		AddSource: true,
 	})
 
 	// Set the default logger
-	logger := slog.New(jsonHandler)
+	logger := newLogger(jsonHandler)
 	slog.SetDefault(logger)
 
 	slog.Info("File logging configured",
@@ -458,6 +414,13 @@ type LogConfig struct {
 	MaxBackups int
 	MaxAgeDays int
 	Compress   bool
+	// Bytes of records buffered before the file is written, 0 writes every
+	// record as it is logged. The buffer is also written out every
+	// FlushInterval and by FlushLogs.
+	BufferSize    int
+	FlushInterval time.Duration
+	// FsyncNever, FsyncInterval or FsyncAlways
+	Fsync string
 }
 
 // DefaultLogConfig returns a default logging configuration
@@ -468,6 +431,10 @@ func DefaultLogConfig() LogConfig {
 		MaxBackups: 5,   // Keep 5 backup files
 		MaxAgeDays: 30,  // Keep logs for 30 days
 		Compress:   true,
+		// Written out at least every second
+		BufferSize:    256 * 1024,
+		FlushInterval: time.Second,
+		Fsync:         FsyncNever,
 	}
 }
 
@@ -485,12 +452,18 @@ func SetupAdvancedFileLogger(config LogConfig) error {
 		return err
 	}
 
+	// Buffer the file, stdout is left unbuffered so that it is not behind
+	var fileWriter io.Writer = logFile
+	if config.BufferSize > 0 {
+		fileWriter = newBufferedFile(logFile, config.BufferSize, config.FlushInterval, config.Fsync)
+	}
+
 	// Create a multi-writer to write to both stdout and file
-	multiWriter := io.MultiWriter(os.Stdout, logFile)
+	multiWriter := io.MultiWriter(os.Stdout, fileWriter)
 
 	// Create JSON handler with enhanced options
 	jsonHandler := slog.NewJSONHandler(multiWriter, &slog.HandlerOptions{
-		Level:     slog.LevelInfo,
+		Level:     LogLevel,
 		AddSource: true,
 		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
 			// Add timestamp in ISO format
@@ -505,7 +478,7 @@ func SetupAdvancedFileLogger(config LogConfig) error {
 	})
 
 	// Set the default logger
-	logger := slog.New(jsonHandler)
+	logger := newLogger(jsonHandler)
 	slog.SetDefault(logger)
 
 	slog.Info("Advanced file logging configured",
@@ -513,7 +486,10 @@ func SetupAdvancedFileLogger(config LogConfig) error {
 		slog.String("log_dir", logDir),
 		slog.Int64("max_size_mb", config.MaxSizeMB),
 		slog.Int("max_backups", config.MaxBackups),
-		slog.Int("max_age_days", config.MaxAgeDays))
+		slog.Int("max_age_days", config.MaxAgeDays),
+		slog.Int("buffer_size", config.BufferSize),
+		slog.Duration("flush_interval", config.FlushInterval),
+		slog.String("fsync", config.Fsync))
 
 	return nil
 }
@@ -0,0 +1,174 @@
+package observability
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/encoding/gzip"
+)
+
+// OTLP transports, as in OTEL_EXPORTER_OTLP_PROTOCOL
+const (
+	OTLPProtocolHTTP = "http/protobuf"
+	OTLPProtocolGRPC = "grpc"
+)
+
+// OTLPExporterConfig says where and how traces are exported. Endpoint is
+// host:port, 4318 for http/protobuf and 4317 for grpc on a collector.
+type OTLPExporterConfig struct {
+	Endpoint string
+	// Comma separated key=value pairs sent with every export
+	Headers  string
+	Protocol string
+	// Plain text when set, otherwise TLS trusting the system roots or
+	// CAFile, presenting CertFile and KeyFile when set
+	Insecure bool
+	CAFile   string
+	CertFile string
+	KeyFile  string
+	// none or gzip
+	Compression string
+	// Replaces /v1/traces over http/protobuf
+	URLPath string
+}
+
+func (c OTLPExporterConfig) tlsConfig() (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read OTLP CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in %s", c.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+	if c.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load OTLP client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// newTraceExporter connects the exporter of the configured transport
+func newTraceExporter(ctx context.Context, c OTLPExporterConfig) (trace.SpanExporter, error) {
+	headers := parseHeaders(c.Headers)
+	var tlsConfig *tls.Config
+	if !c.Insecure {
+		var err error
+		if tlsConfig, err = c.tlsConfig(); err != nil {
+			return nil, err
+		}
+	}
+
+	switch c.Protocol {
+	case OTLPProtocolGRPC:
+		options := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(c.Endpoint)}
+		if tlsConfig != nil {
+			options = append(options, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+		} else {
+			options = append(options, otlptracegrpc.WithInsecure())
+		}
+		if c.Compression == "gzip" {
+			options = append(options, otlptracegrpc.WithCompressor(gzip.Name))
+		}
+		if len(headers) > 0 {
+			options = append(options, otlptracegrpc.WithHeaders(headers))
+		}
+		return otlptrace.New(ctx, otlptracegrpc.NewClient(options...))
+	case OTLPProtocolHTTP, "":
+		options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(c.Endpoint)}
+		if tlsConfig != nil {
+			options = append(options, otlptracehttp.WithTLSClientConfig(tlsConfig))
+		} else {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		if c.URLPath != "" {
+			options = append(options, otlptracehttp.WithURLPath(c.URLPath))
+		}
+		if c.Compression == "gzip" {
+			options = append(options, otlptracehttp.WithCompression(otlptracehttp.GzipCompression))
+		}
+		if len(headers) > 0 {
+			options = append(options, otlptracehttp.WithHeaders(headers))
+		}
+		return otlptracehttp.New(ctx, options...)
+	default:
+		return nil, fmt.Errorf("unknown OTLP protocol %q", c.Protocol)
+	}
+}
+
+// newMetricExporter connects the metrics exporter of the configured
+// transport to the same collector as the traces
+func newMetricExporter(ctx context.Context, c OTLPExporterConfig) (sdkmetric.Exporter, error) {
+	headers := parseHeaders(c.Headers)
+	var tlsConfig *tls.Config
+	if !c.Insecure {
+		var err error
+		if tlsConfig, err = c.tlsConfig(); err != nil {
+			return nil, err
+		}
+	}
+
+	switch c.Protocol {
+	case OTLPProtocolGRPC:
+		options := []otlpmetricgrpc.Option{otlpmetricgrpc.WithEndpoint(c.Endpoint)}
+		if tlsConfig != nil {
+			options = append(options, otlpmetricgrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+		} else {
+			options = append(options, otlpmetricgrpc.WithInsecure())
+		}
+		if c.Compression == "gzip" {
+			options = append(options, otlpmetricgrpc.WithCompressor(gzip.Name))
+		}
+		if len(headers) > 0 {
+			options = append(options, otlpmetricgrpc.WithHeaders(headers))
+		}
+		return otlpmetricgrpc.New(ctx, options...)
+	case OTLPProtocolHTTP, "":
+		options := []otlpmetrichttp.Option{
+			otlpmetrichttp.WithEndpoint(c.Endpoint),
+			otlpmetrichttp.WithURLPath(metricsURLPath(c.URLPath)),
+		}
+		if tlsConfig != nil {
+			options = append(options, otlpmetrichttp.WithTLSClientConfig(tlsConfig))
+		} else {
+			options = append(options, otlpmetrichttp.WithInsecure())
+		}
+		if c.Compression == "gzip" {
+			options = append(options, otlpmetrichttp.WithCompression(otlpmetrichttp.GzipCompression))
+		}
+		if len(headers) > 0 {
+			options = append(options, otlpmetrichttp.WithHeaders(headers))
+		}
+		return otlpmetrichttp.New(ctx, options...)
+	default:
+		return nil, fmt.Errorf("unknown OTLP protocol %q", c.Protocol)
+	}
+}
+
+// metricsURLPath returns the path metrics are posted to, next to a custom
+// traces path such as Grafana Cloud's /otlp/v1/traces
+func metricsURLPath(tracesPath string) string {
+	if prefix, ok := strings.CutSuffix(tracesPath, "/v1/traces"); ok {
+		return prefix + "/v1/metrics"
+	}
+	return "/v1/metrics"
+}
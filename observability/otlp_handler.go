@@ -8,6 +8,7 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 )
 
@@ -16,32 +17,58 @@ type OTLPHandler struct {
 	client      *http.Client
 	otlpURL     string
 	serviceName string
-	level       slog.Level
+	level       slog.Leveler
 	fallback    slog.Handler
+	// Receives the records while the collector is down, nil without a
+	// failover file
+	failover slog.Handler
+	sink     *logSink
 }
 
 // OTLPConfig holds configuration for OTLP handler
 type OTLPConfig struct {
 	Endpoint    string
 	ServiceName string
-	Level       slog.Level
+	Level       slog.Leveler
 	Headers     map[string]string
+	Failover    LogFailover
 }
 
 // NewOTLPHandler creates a new OTLP handler
-func NewOTLPHandler(config OTLPConfig) *OTLPHandler {
+func NewOTLPHandler(config OTLPConfig) (*OTLPHandler, error) {
+	failover, err := config.Failover.handler(config.Level)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: 5 * time.Second}
+	otlpURL := config.Endpoint + "/v1/logs"
+	// An export without records is accepted once the collector is back
+	probe := func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, otlpURL, strings.NewReader(`{"resourceLogs":[]}`))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		return checkResponse(resp)
+	}
 	return &OTLPHandler{
-		client:      &http.Client{Timeout: 5 * time.Second},
-		otlpURL:     config.Endpoint + "/v1/logs",
+		client:      client,
+		otlpURL:     otlpURL,
 		serviceName: config.ServiceName,
 		level:       config.Level,
 		fallback:    slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: config.Level}),
-	}
+		failover:    failover,
+		sink:        newLogSink("otlp", config.Failover, probe),
+	}, nil
 }
 
 // Enabled reports whether the handler handles records at the given level
 func (h *OTLPHandler) Enabled(_ context.Context, level slog.Level) bool {
-	return level >= h.level
+	return level >= h.level.Level()
 }
 
 // Handle processes a log record
@@ -51,31 +78,45 @@ func (h *OTLPHandler) Handle(ctx context.Context, record slog.Record) error {
 		return err
 	}
 
+	if h.sink.isDown() {
+		return h.sink.skip(ctx, record, h.failover)
+	}
+
 	// Send to OTLP asynchronously
-	go h.sendToOTLP(record)
+	h.sink.send(record, h.sendToOTLP, h.failover)
 	return nil
 }
 
 // WithAttrs returns a new handler with additional attributes
 func (h *OTLPHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	return &OTLPHandler{
+	handler := &OTLPHandler{
 		client:      h.client,
 		otlpURL:     h.otlpURL,
 		serviceName: h.serviceName,
 		level:       h.level,
 		fallback:    h.fallback.WithAttrs(attrs),
+		sink:        h.sink,
 	}
+	if h.failover != nil {
+		handler.failover = h.failover.WithAttrs(attrs)
+	}
+	return handler
 }
 
 // WithGroup returns a new handler with a group
 func (h *OTLPHandler) WithGroup(name string) slog.Handler {
-	return &OTLPHandler{
+	handler := &OTLPHandler{
 		client:      h.client,
 		otlpURL:     h.otlpURL,
 		serviceName: h.serviceName,
 		level:       h.level,
 		fallback:    h.fallback.WithGroup(name),
+		sink:        h.sink,
 	}
+	if h.failover != nil {
+		handler.failover = h.failover.WithGroup(name)
+	}
+	return handler
 }
 
 // OTLP Log structures (simplified)
@@ -120,7 +161,7 @@ type Attribute struct {
 }
 
 // sendToOTLP sends the log record via OTLP
-func (h *OTLPHandler) sendToOTLP(record slog.Record) {
+func (h *OTLPHandler) sendToOTLP(record slog.Record) error {
 	// Convert slog level to OTLP severity
 	severityNumber := h.slogLevelToOTLP(record.Level)
 
@@ -144,6 +185,10 @@ func (h *OTLPHandler) sendToOTLP(record slog.Record) {
 							Key:   "service.name",
 							Value: map[string]interface{}{"stringValue": h.serviceName},
 						},
+						{
+							Key:   "service.instance.id",
+							Value: map[string]interface{}{"stringValue": InstanceID},
+						},
 					},
 				},
 				ScopeLogs: []ScopeLogs{
@@ -172,21 +217,21 @@ func (h *OTLPHandler) sendToOTLP(record slog.Record) {
 	// Send to OTLP endpoint
 	payloadJSON, err := json.Marshal(payload)
 	if err != nil {
-		return
+		return err
 	}
 
 	req, err := http.NewRequest("POST", h.otlpURL, bytes.NewBuffer(payloadJSON))
 	if err != nil {
-		return
+		return err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := h.client.Do(req)
 	if err != nil {
-		return
+		return err
 	}
-	defer resp.Body.Close()
+	return checkResponse(resp)
 }
 
 // slogLevelToOTLP converts slog level to OTLP severity number
@@ -205,16 +250,21 @@ func (h *OTLPHandler) slogLevelToOTLP(level slog.Level) int {
 	}
 }
 
-// SetupOTLPLogging configures slog to send logs via OTLP
-func SetupOTLPLogging(endpoint string, serviceName string) error {
+// SetupOTLPLogging configures slog to send logs via OTLP, failing over as
+// failover says while the collector is down
+func SetupOTLPLogging(endpoint string, serviceName string, failover LogFailover) error {
 	config := OTLPConfig{
 		Endpoint:    endpoint,
 		ServiceName: serviceName,
-		Level:       slog.LevelInfo,
+		Level:       LogLevel,
+		Failover:    failover,
 	}
 
-	handler := NewOTLPHandler(config)
-	logger := slog.New(handler)
+	handler, err := NewOTLPHandler(config)
+	if err != nil {
+		return err
+	}
+	logger := newLogger(handler)
 	slog.SetDefault(logger)
 
 	slog.Info("OTLP logging configured",
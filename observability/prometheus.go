@@ -1,12 +1,15 @@
 package observability
 
 import (
+	"context"
 	"log/slog"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // PrometheusMetrics holds all Prometheus metrics for the inventory service
@@ -16,24 +19,73 @@ type PrometheusMetrics struct {
 	HTTPRequestDuration     *prometheus.HistogramVec
 	HTTPRequestsInFlight    prometheus.Gauge
 	HTTPResponseStatusTotal *prometheus.CounterVec // New: HTTP status code metrics
+	PanicsTotal             *prometheus.CounterVec
 
 	// Database metrics
 	DBConnectionsActive prometheus.Gauge
 	DBOperationDuration *prometheus.HistogramVec
 	DBOperationErrors   *prometheus.CounterVec
+	// Per target, primary or replica_N
+	DBPoolConnections *prometheus.GaugeVec
+	DBQueriesRouted   *prometheus.CounterVec
+	DBReplicaLag      *prometheus.GaugeVec
 
 	// Business metrics
-	WarehouseOperationsTotal *prometheus.CounterVec
-	WarehouseActive          prometheus.Gauge
+	InventoryOperationsTotal *prometheus.CounterVec
+	OperationDuration        *prometheus.HistogramVec
+	WarehouseActive          *prometheus.GaugeVec
+	StorageRoomActive        *prometheus.GaugeVec
 	AuthenticationAttempts   *prometheus.CounterVec
 
+	// Auth metrics
+	AuthVerificationDuration *prometheus.HistogramVec
+	JWKSRefreshesTotal       *prometheus.CounterVec
+	JWKSCircuitOpen          prometheus.Gauge
+	JWKSKeys                 prometheus.Gauge
+	AuthorizationDenials     *prometheus.CounterVec
+	PolicyDecisions          *prometheus.CounterVec
+	PolicyDecisionDuration   *prometheus.HistogramVec
+
+	// Cold chain metrics
+	StorageRoomTemperature       *prometheus.GaugeVec
+	StorageRoomTemperatureBreach *prometheus.GaugeVec
+	TemperatureBreachesTotal     *prometheus.CounterVec
+
+	// Storage room capacity metrics
+	StorageRoomOccupancy *prometheus.GaugeVec
+	StorageRoomCapacity  *prometheus.GaugeVec
+
+	// Background job metrics
+	JobsProcessedTotal *prometheus.CounterVec
+	JobDuration        *prometheus.HistogramVec
+
+	// Change feed metrics
+	ChangeEventsTotal *prometheus.CounterVec
+	StreamClients     *prometheus.GaugeVec
+	StreamRateLimited *prometheus.CounterVec
+
+	// Outbox metrics
+	OutboxPublishedTotal  *prometheus.CounterVec
+	OutboxPublishDuration *prometheus.HistogramVec
+	OutboxPendingMessages prometheus.Gauge
+	OutboxLagSeconds      prometheus.Gauge
+
+	// Inbox metrics
+	InboxConsumedTotal      *prometheus.CounterVec
+	InboxHandleDuration     *prometheus.HistogramVec
+	InboxLagSeconds         *prometheus.HistogramVec
+	InboxConsumerLagSeconds *prometheus.GaugeVec
+
+	// Quota metrics
+	QuotaRejectionsTotal *prometheus.CounterVec
+
 	// System metrics (automatically collected by Prometheus client)
 	// - go_* metrics (goroutines, memory, GC, etc.)
 	// - process_* metrics (CPU, memory, file descriptors, etc.)
 }
 
 // NewPrometheusMetrics creates and registers all Prometheus metrics
-func NewPrometheusMetrics(serviceName string) *PrometheusMetrics {
+func NewPrometheusMetrics(serviceName string, histograms HistogramConfig) *PrometheusMetrics {
 	metrics := &PrometheusMetrics{
 		// HTTP metrics following Prometheus naming conventions
 		HTTPRequestsTotal: prometheus.NewCounterVec(
@@ -44,11 +96,7 @@ func NewPrometheusMetrics(serviceName string) *PrometheusMetrics {
 			[]string{"method", "endpoint", "status_code"},
 		),
 		HTTPRequestDuration: prometheus.NewHistogramVec(
-			prometheus.HistogramOpts{
-				Name:    "http_request_duration_seconds",
-				Help:    "HTTP request duration in seconds",
-				Buckets: prometheus.DefBuckets, // Default buckets: .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10
-			},
+			histograms.opts("http_request_duration_seconds", "HTTP request duration in seconds", histograms.HTTPBuckets),
 			[]string{"method", "endpoint"},
 		),
 		HTTPRequestsInFlight: prometheus.NewGauge(
@@ -65,6 +113,13 @@ func NewPrometheusMetrics(serviceName string) *PrometheusMetrics {
 			},
 			[]string{"method", "endpoint", "status_class"},
 		),
+		PanicsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "panics_total",
+				Help: "Total number of panics recovered while handling HTTP requests",
+			},
+			[]string{"method", "endpoint"},
+		),
 
 		// Database metrics
 		DBConnectionsActive: prometheus.NewGauge(
@@ -74,11 +129,7 @@ func NewPrometheusMetrics(serviceName string) *PrometheusMetrics {
 			},
 		),
 		DBOperationDuration: prometheus.NewHistogramVec(
-			prometheus.HistogramOpts{
-				Name:    "database_operation_duration_seconds",
-				Help:    "Database operation duration in seconds",
-				Buckets: []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5}, // Smaller buckets for DB ops
-			},
+			histograms.opts("database_operation_duration_seconds", "Database operation duration in seconds", histograms.DBBuckets),
 			[]string{"operation", "table"},
 		),
 		DBOperationErrors: prometheus.NewCounterVec(
@@ -88,20 +139,57 @@ func NewPrometheusMetrics(serviceName string) *PrometheusMetrics {
 			},
 			[]string{"operation", "table", "error_type"},
 		),
+		DBPoolConnections: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "database_pool_connections",
+				Help: "Connections of a database pool by state: acquired, idle, total or max",
+			},
+			[]string{"target", "state"},
+		),
+		DBQueriesRouted: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "database_queries_routed_total",
+				Help: "Total number of read queries routed to each database target",
+			},
+			[]string{"target"},
+		),
+		DBReplicaLag: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "database_replica_lag_seconds",
+				Help: "Replication lag of a read replica in seconds, -1 while unknown or unreachable",
+			},
+			[]string{"target"},
+		),
 
-		// Business metrics specific to inventory service
-		WarehouseOperationsTotal: prometheus.NewCounterVec(
+		// Business metrics specific to inventory service. Labels must stay
+		// bounded, never use names, addresses or IDs as label values.
+		InventoryOperationsTotal: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
-				Name: "warehouse_operations_total",
-				Help: "Total number of warehouse operations",
+				Name: "inventory_operations_total",
+				Help: "Total number of inventory operations by entity type and outcome",
 			},
-			[]string{"operation", "category", "location"},
+			[]string{"tenant", "entity_type", "operation", "status"},
+		),
+		// Timed from the start of the operation, whether a request, a job, an
+		// event or a file exchange runs it. No tenant label, histograms
+		// multiply the series by their buckets.
+		OperationDuration: prometheus.NewHistogramVec(
+			histograms.opts("operation_duration_seconds", "Business operation duration in seconds by entity type and outcome", histograms.OperationBuckets),
+			[]string{"entity_type", "operation", "status"},
 		),
-		WarehouseActive: prometheus.NewGauge(
+		WarehouseActive: prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Name: "warehouse_active",
 				Help: "Current number of active warehouse",
 			},
+			[]string{"tenant"},
+		),
+		StorageRoomActive: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "storage_room_active",
+				Help: "Current number of active storage rooms",
+			},
+			[]string{"tenant"},
 		),
 		AuthenticationAttempts: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
@@ -110,6 +198,208 @@ func NewPrometheusMetrics(serviceName string) *PrometheusMetrics {
 			},
 			[]string{"status", "method"},
 		),
+
+		// Auth metrics
+		AuthVerificationDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "auth_verification_duration_seconds",
+				Help:    "Duration of verifying a credential in seconds by method and status",
+				Buckets: []float64{.0005, .001, .005, .01, .05, .1, .5, 1, 5},
+			},
+			[]string{"method", "status"},
+		),
+		JWKSRefreshesTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "clerk_jwks_refreshes_total",
+				Help: "Total number of Clerk JWKS fetches by status, skipped while the circuit is open",
+			},
+			[]string{"status"},
+		),
+		JWKSCircuitOpen: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "clerk_jwks_circuit_open",
+				Help: "1 while Clerk JWKS fetches are paused after repeated failures",
+			},
+		),
+		JWKSKeys: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "clerk_jwks_keys",
+				Help: "Number of Clerk signing keys in the cache",
+			},
+		),
+		AuthorizationDenials: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "authorization_denials_total",
+				Help: "Total number of entities withheld because they belong to another organization",
+			},
+			[]string{"entity_type", "action"},
+		),
+		PolicyDecisions: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "policy_decisions_total",
+				Help: "Total number of authorization policy decisions by engine and decision",
+			},
+			[]string{"engine", "decision"},
+		),
+		PolicyDecisionDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "policy_decision_duration_seconds",
+				Help:    "Duration of evaluating the authorization policy in seconds",
+				Buckets: []float64{.0001, .0005, .001, .005, .01, .05, .1, .5, 1, 2},
+			},
+			[]string{"engine"},
+		),
+
+		// Cold chain metrics. The per room gauges are the one exception to
+		// bounded labels, alert rules need to name the room.
+		StorageRoomTemperature: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "storage_room_temperature_celsius",
+				Help: "Latest temperature reported for a storage room",
+			},
+			[]string{"tenant", "storage_room_id"},
+		),
+		StorageRoomTemperatureBreach: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "storage_room_temperature_breach",
+				Help: "1 while the storage room temperature is outside its zone thresholds",
+			},
+			[]string{"tenant", "storage_room_id"},
+		),
+		TemperatureBreachesTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "temperature_breaches_total",
+				Help: "Total number of temperature threshold breaches by zone type",
+			},
+			[]string{"tenant", "zone_type"},
+		),
+
+		// Capacity metrics, per room like the cold chain gauges so alerts
+		// can name the full room
+		StorageRoomOccupancy: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "storage_room_occupancy_units",
+				Help: "Units of stock held in a storage room",
+			},
+			[]string{"tenant", "storage_room_id"},
+		),
+		StorageRoomCapacity: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "storage_room_capacity_units",
+				Help: "Units of stock a storage room holds, only set for rooms with a capacity",
+			},
+			[]string{"tenant", "storage_room_id"},
+		),
+
+		// Background job metrics
+		JobsProcessedTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "jobs_processed_total",
+				Help: "Total number of background jobs processed by outcome",
+			},
+			[]string{"kind", "status"},
+		),
+		JobDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "job_duration_seconds",
+				Help:    "Background job run duration in seconds",
+				Buckets: []float64{.01, .05, .1, .5, 1, 5, 10, 30, 60, 300},
+			},
+			[]string{"kind"},
+		),
+
+		// Change feed metrics
+		ChangeEventsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "change_events_total",
+				Help: "Total number of change feed events received by entity and operation",
+			},
+			[]string{"entity", "operation"},
+		),
+		StreamClients: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "stream_clients_connected",
+				Help: "Current number of clients connected to a change stream by transport",
+			},
+			[]string{"transport"},
+		),
+		StreamRateLimited: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "stream_messages_rate_limited_total",
+				Help: "Total number of client messages on a stream rejected by the per-connection rate limit",
+			},
+			[]string{"transport"},
+		),
+
+		// Outbox metrics
+		OutboxPublishedTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "outbox_messages_published_total",
+				Help: "Total number of outbox publish attempts by topic and status",
+			},
+			[]string{"topic", "status"},
+		),
+		OutboxPublishDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "outbox_publish_duration_seconds",
+				Help:    "Duration of publishing an outbox message to the broker in seconds",
+				Buckets: []float64{.005, .01, .05, .1, .5, 1, 5, 10},
+			},
+			[]string{"topic"},
+		),
+		OutboxPendingMessages: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "outbox_pending_messages",
+				Help: "Current number of outbox messages not yet delivered",
+			},
+		),
+		OutboxLagSeconds: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "outbox_lag_seconds",
+				Help: "Age of the oldest undelivered outbox message in seconds",
+			},
+		),
+
+		// Inbox metrics
+		InboxConsumedTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "inbox_messages_consumed_total",
+				Help: "Total number of messages consumed from the broker by topic and outcome",
+			},
+			[]string{"topic", "outcome"},
+		),
+		InboxHandleDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "inbox_handle_duration_seconds",
+				Help:    "Duration of handling a consumed message in seconds",
+				Buckets: []float64{.005, .01, .05, .1, .5, 1, 5, 10},
+			},
+			[]string{"topic"},
+		),
+		InboxLagSeconds: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "inbox_lag_seconds",
+				Help:    "Time from a message being produced to being consumed in seconds",
+				Buckets: []float64{.1, .5, 1, 5, 15, 60, 300, 900, 3600},
+			},
+			[]string{"topic"},
+		),
+		InboxConsumerLagSeconds: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "inbox_consumer_lag_seconds",
+				Help: "Age of the last message consumed by topic in seconds",
+			},
+			[]string{"topic"},
+		),
+
+		// Quota metrics
+		QuotaRejectionsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "quota_rejections_total",
+				Help: "Total number of requests rejected for exceeding a tenant quota by resource",
+			},
+			[]string{"resource"},
+		),
 	}
 
 	// Register all metrics with Prometheus
@@ -118,18 +408,63 @@ func NewPrometheusMetrics(serviceName string) *PrometheusMetrics {
 		metrics.HTTPRequestDuration,
 		metrics.HTTPRequestsInFlight,
 		metrics.HTTPResponseStatusTotal, // Register the new status code metric
+		metrics.PanicsTotal,
 		metrics.DBConnectionsActive,
 		metrics.DBOperationDuration,
 		metrics.DBOperationErrors,
-		metrics.WarehouseOperationsTotal,
+		metrics.DBPoolConnections,
+		metrics.DBQueriesRouted,
+		metrics.DBReplicaLag,
+		metrics.InventoryOperationsTotal,
+		metrics.OperationDuration,
 		metrics.WarehouseActive,
+		metrics.StorageRoomActive,
 		metrics.AuthenticationAttempts,
+		metrics.AuthVerificationDuration,
+		metrics.JWKSRefreshesTotal,
+		metrics.JWKSCircuitOpen,
+		metrics.JWKSKeys,
+		metrics.AuthorizationDenials,
+		metrics.PolicyDecisions,
+		metrics.PolicyDecisionDuration,
+		metrics.StorageRoomTemperature,
+		metrics.StorageRoomTemperatureBreach,
+		metrics.TemperatureBreachesTotal,
+		metrics.StorageRoomOccupancy,
+		metrics.StorageRoomCapacity,
+		metrics.JobsProcessedTotal,
+		metrics.JobDuration,
+		metrics.ChangeEventsTotal,
+		metrics.StreamClients,
+		metrics.StreamRateLimited,
+		metrics.OutboxPublishedTotal,
+		metrics.OutboxPublishDuration,
+		metrics.OutboxPendingMessages,
+		metrics.OutboxLagSeconds,
+		metrics.InboxConsumedTotal,
+		metrics.InboxHandleDuration,
+		metrics.InboxLagSeconds,
+		metrics.InboxConsumerLagSeconds,
+		metrics.QuotaRejectionsTotal,
 	)
 
 	slog.Info("Prometheus metrics registered", slog.String("service", serviceName))
 	return metrics
 }
 
+// observeWithTrace records value on o. When ctx carries a sampled span its
+// trace ID is attached as exemplar, so a dashboard can jump from a latency
+// bucket to a trace that landed in it. Unsampled traces are never exported
+// and would make dead links.
+func observeWithTrace(ctx context.Context, o prometheus.Observer, value float64) {
+	sc := trace.SpanContextFromContext(ctx)
+	if eo, ok := o.(prometheus.ExemplarObserver); ok && sc.IsSampled() {
+		eo.ObserveWithExemplar(value, prometheus.Labels{"trace_id": sc.TraceID().String()})
+		return
+	}
+	o.Observe(value)
+}
+
 // getStatusClass converts HTTP status code to status class (2xx, 4xx, 5xx, etc.)
 func getStatusClass(statusCode int) string {
 	switch {
@@ -180,13 +515,13 @@ func (m *PrometheusMetrics) PrometheusMiddleware() gin.HandlerFunc {
 		m.HTTPRequestsTotal.WithLabelValues(
 			c.Request.Method,
 			route,
-			string(rune(statusCode)),
+			strconv.Itoa(statusCode),
 		).Inc()
 
-		m.HTTPRequestDuration.WithLabelValues(
+		observeWithTrace(c.Request.Context(), m.HTTPRequestDuration.WithLabelValues(
 			c.Request.Method,
 			route,
-		).Observe(duration)
+		), duration)
 
 		// Record status class metrics (2xx, 4xx, 5xx)
 		m.HTTPResponseStatusTotal.WithLabelValues(
@@ -197,26 +532,189 @@ func (m *PrometheusMetrics) PrometheusMiddleware() gin.HandlerFunc {
 	}
 }
 
-// RecordDBOperation records database operation metrics
-func (m *PrometheusMetrics) RecordDBOperation(operation, table string, duration time.Duration, err error) {
-	m.DBOperationDuration.WithLabelValues(operation, table).Observe(duration.Seconds())
+// RecordDBOperation records database operation metrics, with the trace of
+// ctx as exemplar
+func (m *PrometheusMetrics) RecordDBOperation(ctx context.Context, operation, table string, duration time.Duration, err error) {
+	observeWithTrace(ctx, m.DBOperationDuration.WithLabelValues(operation, table), duration.Seconds())
 
 	if err != nil {
-		errorType := "unknown"
-		// You can categorize errors here based on your needs
-		// For example: "connection_error", "timeout", "constraint_violation", etc.
-		m.DBOperationErrors.WithLabelValues(operation, table, errorType).Inc()
+		m.DBOperationErrors.WithLabelValues(operation, table, ClassifyDBError(err)).Inc()
+	}
+}
+
+// UpdateDBPoolStats sets the connection gauges of a database target
+func (m *PrometheusMetrics) UpdateDBPoolStats(target string, acquired, idle, total, max int32) {
+	m.DBPoolConnections.WithLabelValues(target, "acquired").Set(float64(acquired))
+	m.DBPoolConnections.WithLabelValues(target, "idle").Set(float64(idle))
+	m.DBPoolConnections.WithLabelValues(target, "total").Set(float64(total))
+	m.DBPoolConnections.WithLabelValues(target, "max").Set(float64(max))
+}
+
+// RecordDBRoute counts a read query sent to target
+func (m *PrometheusMetrics) RecordDBRoute(target string) {
+	m.DBQueriesRouted.WithLabelValues(target).Inc()
+}
+
+// UpdateReplicaLag sets the replication lag of a read replica, -1 when it
+// is unknown
+func (m *PrometheusMetrics) UpdateReplicaLag(target string, seconds float64) {
+	m.DBReplicaLag.WithLabelValues(target).Set(seconds)
+}
+
+// Entity types used as the entity_type label of inventory_operations_total
+const (
+	EntityWarehouse    = "warehouse"
+	EntityStorageRoom  = "storage_room"
+	EntityReceipt      = "receipt"
+	EntityPickList     = "pick_list"
+	EntityCountSession = "count_session"
+	EntityLabel        = "label"
+	EntityAttachment   = "attachment"
+	EntityItem         = "item"
+	EntitySerial       = "serial"
+	EntityWave         = "wave"
+	EntityTransfer     = "transfer"
+	EntitySupplier     = "supplier"
+	EntityCarrier      = "carrier"
+	EntityScan         = "scan"
+	EntityPrinter      = "printer"
+	EntityEDI          = "edi"
+	EntityFileExchange = "file_exchange"
+	EntityIntegration  = "integration"
+	EntitySaga         = "saga"
+	EntityTenant       = "tenant"
+)
+
+// Outcomes used as the status label of inventory_operations_total
+const (
+	StatusSuccess  = "success"
+	StatusNotFound = "not_found"
+	StatusError    = "error"
+)
+
+// RecordOperation counts one business operation for a tenant and records
+// its duration. entityType is one of the Entity constants, operation a fixed
+// verb such as "create" or "receive" and status one of the Status constants.
+func (m *PrometheusMetrics) RecordOperation(tenant, entityType, operation, status string, duration time.Duration) {
+	m.InventoryOperationsTotal.WithLabelValues(tenant, entityType, operation, status).Inc()
+	m.OperationDuration.WithLabelValues(entityType, operation, status).Observe(duration.Seconds())
+}
+
+// UpdateInventoryCounts replaces the active warehouse and storage room gauges
+// with per tenant row counts. Tenants missing from the maps are dropped.
+func (m *PrometheusMetrics) UpdateInventoryCounts(warehouses, storageRooms map[string]int64) {
+	m.WarehouseActive.Reset()
+	for tenant, count := range warehouses {
+		m.WarehouseActive.WithLabelValues(tenant).Set(float64(count))
+	}
+	m.StorageRoomActive.Reset()
+	for tenant, count := range storageRooms {
+		m.StorageRoomActive.WithLabelValues(tenant).Set(float64(count))
 	}
 }
 
-// RecordInventoryOperation records business-specific inventory operations
-func (m *PrometheusMetrics) RecordInventoryOperation(operation, category, location string) {
-	m.WarehouseOperationsTotal.WithLabelValues(operation, category, location).Inc()
+// UpdateWarehouseCount sets the active warehouse gauge for a single tenant
+func (m *PrometheusMetrics) UpdateWarehouseCount(tenant string, count int64) {
+	m.WarehouseActive.WithLabelValues(tenant).Set(float64(count))
 }
 
-// UpdateInventoryCount updates the current count of active inventory items
-func (m *PrometheusMetrics) UpdateInventoryCount(count float64) {
-	m.WarehouseActive.Set(count)
+// RecordTemperature sets the latest temperature of a storage room and
+// whether it is in breach of its zone thresholds
+func (m *PrometheusMetrics) RecordTemperature(tenant string, storageRoomID int32, celsius float64, breached bool) {
+	room := strconv.FormatInt(int64(storageRoomID), 10)
+	m.StorageRoomTemperature.WithLabelValues(tenant, room).Set(celsius)
+	breach := 0.0
+	if breached {
+		breach = 1
+	}
+	m.StorageRoomTemperatureBreach.WithLabelValues(tenant, room).Set(breach)
+}
+
+// StorageRoomOccupancy is the occupancy of one storage room, Capacity zero
+// when the room is unlimited
+type StorageRoomOccupancy struct {
+	Tenant        string
+	StorageRoomID int32
+	Capacity      int32
+	Occupancy     int64
+}
+
+// UpdateStorageRoomOccupancy replaces the occupancy and capacity gauges.
+// Rooms missing from rooms are dropped.
+func (m *PrometheusMetrics) UpdateStorageRoomOccupancy(rooms []StorageRoomOccupancy) {
+	m.StorageRoomOccupancy.Reset()
+	m.StorageRoomCapacity.Reset()
+	for _, r := range rooms {
+		room := strconv.FormatInt(int64(r.StorageRoomID), 10)
+		m.StorageRoomOccupancy.WithLabelValues(r.Tenant, room).Set(float64(r.Occupancy))
+		if r.Capacity > 0 {
+			m.StorageRoomCapacity.WithLabelValues(r.Tenant, room).Set(float64(r.Capacity))
+		}
+	}
+}
+
+// RecordTemperatureBreach counts a storage room leaving its zone thresholds
+func (m *PrometheusMetrics) RecordTemperatureBreach(tenant, zoneType string) {
+	m.TemperatureBreachesTotal.WithLabelValues(tenant, zoneType).Inc()
+}
+
+// RecordPanic records a panic recovered by the recovery middleware
+func (m *PrometheusMetrics) RecordPanic(method, endpoint string) {
+	m.PanicsTotal.WithLabelValues(method, endpoint).Inc()
+}
+
+// RecordJob records the outcome and duration of a background job run
+func (m *PrometheusMetrics) RecordJob(kind, status string, duration time.Duration) {
+	m.JobsProcessedTotal.WithLabelValues(kind, status).Inc()
+	m.JobDuration.WithLabelValues(kind).Observe(duration.Seconds())
+}
+
+// RecordChangeEvent counts a change feed event delivered to this instance
+func (m *PrometheusMetrics) RecordChangeEvent(entity, operation string) {
+	m.ChangeEventsTotal.WithLabelValues(entity, operation).Inc()
+}
+
+// StreamClientConnected counts a client connecting to a change stream,
+// transport is "sse" or "websocket". Call the returned func on disconnect.
+func (m *PrometheusMetrics) StreamClientConnected(transport string) func() {
+	gauge := m.StreamClients.WithLabelValues(transport)
+	gauge.Inc()
+	return gauge.Dec
+}
+
+// RecordOutboxPublish records one attempt to publish an outbox message,
+// status is "success", "error" or "dead_lettered"
+func (m *PrometheusMetrics) RecordOutboxPublish(topic, status string, duration time.Duration) {
+	m.OutboxPublishedTotal.WithLabelValues(topic, status).Inc()
+	m.OutboxPublishDuration.WithLabelValues(topic).Observe(duration.Seconds())
+}
+
+// UpdateOutboxBacklog sets the undelivered message count and the age of
+// the oldest one
+func (m *PrometheusMetrics) UpdateOutboxBacklog(pending int64, lagSeconds float64) {
+	m.OutboxPendingMessages.Set(float64(pending))
+	m.OutboxLagSeconds.Set(lagSeconds)
+}
+
+// RecordInboxMessage records a consumed message, outcome is "processed",
+// "duplicate", "rejected", "ignored" or "error". lag is how long ago it was
+// produced.
+func (m *PrometheusMetrics) RecordInboxMessage(topic, outcome string, duration, lag time.Duration) {
+	m.InboxConsumedTotal.WithLabelValues(topic, outcome).Inc()
+	m.InboxHandleDuration.WithLabelValues(topic).Observe(duration.Seconds())
+	m.InboxLagSeconds.WithLabelValues(topic).Observe(lag.Seconds())
+	m.InboxConsumerLagSeconds.WithLabelValues(topic).Set(lag.Seconds())
+}
+
+// RecordStreamRateLimited records a client message rejected by the
+// connection's rate limit
+func (m *PrometheusMetrics) RecordStreamRateLimited(transport string) {
+	m.StreamRateLimited.WithLabelValues(transport).Inc()
+}
+
+// RecordQuotaRejection records a request rejected by the quota on resource
+func (m *PrometheusMetrics) RecordQuotaRejection(resource string) {
+	m.QuotaRejectionsTotal.WithLabelValues(resource).Inc()
 }
 
 // RecordAuthAttempt records authentication attempts
@@ -224,6 +722,43 @@ func (m *PrometheusMetrics) RecordAuthAttempt(status, method string) {
 	m.AuthenticationAttempts.WithLabelValues(status, method).Inc()
 }
 
+// RecordAuthVerification records how long verifying a credential took
+func (m *PrometheusMetrics) RecordAuthVerification(ctx context.Context, method, status string, duration time.Duration) {
+	observeWithTrace(ctx, m.AuthVerificationDuration.WithLabelValues(method, status), duration.Seconds())
+}
+
+// RecordJWKSRefresh records a Clerk JWKS fetch
+func (m *PrometheusMetrics) RecordJWKSRefresh(status string) {
+	m.JWKSRefreshesTotal.WithLabelValues(status).Inc()
+}
+
+// SetJWKSCircuitOpen records whether Clerk JWKS fetches are paused
+func (m *PrometheusMetrics) SetJWKSCircuitOpen(open bool) {
+	if open {
+		m.JWKSCircuitOpen.Set(1)
+		return
+	}
+	m.JWKSCircuitOpen.Set(0)
+}
+
+// RecordAuthorizationDenials records n entities withheld from a caller of
+// another organization
+func (m *PrometheusMetrics) RecordAuthorizationDenials(entityType, action string, n int) {
+	m.AuthorizationDenials.WithLabelValues(entityType, action).Add(float64(n))
+}
+
+// RecordPolicyDecision records an authorization policy decision, allow,
+// deny or error, and how long the engine took
+func (m *PrometheusMetrics) RecordPolicyDecision(engine, decision string, duration time.Duration) {
+	m.PolicyDecisions.WithLabelValues(engine, decision).Inc()
+	m.PolicyDecisionDuration.WithLabelValues(engine).Observe(duration.Seconds())
+}
+
+// SetJWKSKeys records the number of cached Clerk signing keys
+func (m *PrometheusMetrics) SetJWKSKeys(n int) {
+	m.JWKSKeys.Set(float64(n))
+}
+
 // UpdateDBConnections updates the database connections gauge
 func (m *PrometheusMetrics) UpdateDBConnections(count float64) {
 	m.DBConnectionsActive.Set(count)
@@ -233,7 +768,7 @@ func (m *PrometheusMetrics) UpdateDBConnections(count float64) {
 func (m *PrometheusMetrics) RecordHTTPResponse(method, endpoint string, statusCode int, duration time.Duration) {
 	statusClass := getStatusClass(statusCode)
 
-	m.HTTPRequestsTotal.WithLabelValues(method, endpoint, string(rune(statusCode))).Inc()
+	m.HTTPRequestsTotal.WithLabelValues(method, endpoint, strconv.Itoa(statusCode)).Inc()
 	m.HTTPRequestDuration.WithLabelValues(method, endpoint).Observe(duration.Seconds())
 	m.HTTPResponseStatusTotal.WithLabelValues(method, endpoint, statusClass).Inc()
 }
@@ -252,25 +787,34 @@ func (m *PrometheusMetrics) GetStatusCodeMetrics() map[string]float64 {
 // SetupPrometheusEndpoint adds the /metrics endpoint to the Gin router
 func SetupPrometheusEndpoint(router *gin.Engine) {
 	// Add the /metrics endpoint
-	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	// Exemplars are only part of the OpenMetrics format, which Prometheus
+	// negotiates when exemplar storage is enabled
+	router.GET("/metrics", gin.WrapH(promhttp.InstrumentMetricHandler(
+		prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{EnableOpenMetrics: true}),
+	)))
 	slog.Info("Prometheus metrics endpoint configured at /metrics")
 }
 
 // Example usage functions for common patterns
 
 // WithDBMetrics wraps a database operation with automatic metrics collection
-func (m *PrometheusMetrics) WithDBMetrics(operation, table string, fn func() error) error {
+func (m *PrometheusMetrics) WithDBMetrics(ctx context.Context, operation, table string, fn func() error) error {
 	start := time.Now()
 	err := fn()
-	m.RecordDBOperation(operation, table, time.Since(start), err)
+	m.RecordDBOperation(ctx, operation, table, time.Since(start), err)
 	return err
 }
 
-// WithInventoryMetrics wraps an inventory operation with automatic metrics collection
-func (m *PrometheusMetrics) WithInventoryMetrics(operation, category, location string, fn func() error) error {
+// WithOperationMetrics wraps an inventory operation and records it and its
+// duration as a success or error depending on the returned error
+func (m *PrometheusMetrics) WithOperationMetrics(tenant, entityType, operation string, fn func() error) error {
+	start := time.Now()
 	err := fn()
-	if err == nil {
-		m.RecordInventoryOperation(operation, category, location)
+	status := StatusSuccess
+	if err != nil {
+		status = StatusError
 	}
+	m.RecordOperation(tenant, entityType, operation, status, time.Since(start))
 	return err
 }
@@ -0,0 +1,183 @@
+package observability
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"sync/atomic"
+)
+
+// Redacted replaces the values the log redaction removes
+const Redacted = "[REDACTED]"
+
+// redactPattern scrubs one kind of sensitive text from log values
+type redactPattern struct {
+	re          *regexp.Regexp
+	replacement string
+}
+
+// RedactPatterns are the value patterns LOG_REDACT_PATTERNS can enable,
+// applied to messages, string attributes and errors of every log record
+var RedactPatterns = map[string][]redactPattern{
+	// Passwords in URLs and in key=value connection strings
+	"dsn": {
+		{regexp.MustCompile(`(?i)\b([a-z][a-z0-9+.-]*://[^:/@\s]*):[^@\s]*@`), "${1}:" + Redacted + "@"},
+		{regexp.MustCompile(`(?i)\b(password|sslpassword)=('[^']*'|[^\s&]+)`), "${1}=" + Redacted},
+	},
+	// Bearer and basic credentials, JWTs and API keys, whose public prefix
+	// is kept
+	"token": {
+		{regexp.MustCompile(`(?i)\b(bearer|basic) [A-Za-z0-9._~+/=-]+`), "${1} " + Redacted},
+		{regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`), Redacted},
+		{regexp.MustCompile(`\b(whs_[0-9a-f]+_)[A-Za-z0-9_-]+`), "${1}" + Redacted},
+	},
+	// Values of secret="..." and similar assignments in free text
+	"secret": {
+		{regexp.MustCompile(`(?i)\b((?:client_)?secret|api_?key|access_?token|password|passwd)(["']?\s*[:=]\s*["']?)[^\s"',&]+`), "${1}${2}" + Redacted},
+	},
+	"email": {
+		{regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`), "[EMAIL]"},
+	},
+}
+
+// redactPatternOrder applies the DSN patterns before the email one, which
+// would take user@host for an address
+var redactPatternOrder = []string{"dsn", "token", "secret", "email"}
+
+// Redactor removes sensitive data from log records: attributes whose key
+// matches a field pattern lose their value, and the enabled value patterns
+// are scrubbed from the rest
+type Redactor struct {
+	fields []*regexp.Regexp
+	values []redactPattern
+}
+
+// NewRedactor compiles the field patterns, regular expressions matched
+// case-insensitively against attribute keys, and enables the named value
+// patterns of RedactPatterns
+func NewRedactor(fields, patterns []string) (*Redactor, error) {
+	r := &Redactor{}
+	for _, field := range fields {
+		re, err := regexp.Compile("(?i)" + field)
+		if err != nil {
+			return nil, fmt.Errorf("field pattern %q: %w", field, err)
+		}
+		r.fields = append(r.fields, re)
+	}
+	for _, name := range patterns {
+		if _, ok := RedactPatterns[name]; !ok {
+			return nil, fmt.Errorf("unknown redact pattern %q", name)
+		}
+	}
+	for _, name := range redactPatternOrder {
+		if slices.Contains(patterns, name) {
+			r.values = append(r.values, RedactPatterns[name]...)
+		}
+	}
+	return r, nil
+}
+
+// String scrubs the enabled value patterns from s
+func (r *Redactor) String(s string) string {
+	for _, p := range r.values {
+		s = p.re.ReplaceAllString(s, p.replacement)
+	}
+	return s
+}
+
+func (r *Redactor) sensitiveKey(key string) bool {
+	for _, re := range r.fields {
+		if re.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// Attr redacts an attribute, the attributes of a group included. Numbers,
+// booleans and times are kept whatever their key, such as api_key=true.
+func (r *Redactor) Attr(a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+	switch a.Value.Kind() {
+	case slog.KindString, slog.KindGroup, slog.KindAny:
+		if r.sensitiveKey(a.Key) {
+			return slog.String(a.Key, Redacted)
+		}
+	}
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(r.String(a.Value.String()))
+	case slog.KindGroup:
+		group := a.Value.Group()
+		attrs := make([]slog.Attr, len(group))
+		for i, attr := range group {
+			attrs[i] = r.Attr(attr)
+		}
+		a.Value = slog.GroupValue(attrs...)
+	case slog.KindAny:
+		switch v := a.Value.Any().(type) {
+		case error:
+			a.Value = slog.StringValue(r.String(v.Error()))
+		case fmt.Stringer:
+			a.Value = slog.StringValue(r.String(v.String()))
+		}
+	}
+	return a
+}
+
+// RedactHandler redacts the records it passes to the next handler
+type RedactHandler struct {
+	next     slog.Handler
+	redactor *Redactor
+}
+
+// NewRedactHandler wraps next, passing it every record redacted by r
+func NewRedactHandler(next slog.Handler, r *Redactor) *RedactHandler {
+	return &RedactHandler{next: next, redactor: r}
+}
+
+func (h *RedactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *RedactHandler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, h.redactor.String(record.Message), record.PC)
+	record.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(h.redactor.Attr(a))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+func (h *RedactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = h.redactor.Attr(a)
+	}
+	return &RedactHandler{next: h.next.WithAttrs(redacted), redactor: h.redactor}
+}
+
+func (h *RedactHandler) WithGroup(name string) slog.Handler {
+	return &RedactHandler{next: h.next.WithGroup(name), redactor: h.redactor}
+}
+
+// logRedactor is applied to the handlers of every log sink, nil while
+// redaction is off
+var logRedactor atomic.Pointer[Redactor]
+
+// SetLogRedaction installs r on the log sinks set up from now on, nil
+// turns redaction off. Call it before setting up logging.
+func SetLogRedaction(r *Redactor) {
+	logRedactor.Store(r)
+}
+
+// newLogger returns a logger writing to handler through the log redaction,
+// counting its records in log_records_total
+func newLogger(handler slog.Handler) *slog.Logger {
+	if r := logRedactor.Load(); r != nil {
+		handler = NewRedactHandler(handler, r)
+	}
+	return slog.New(countingHandler{next: handler})
+}
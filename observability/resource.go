@@ -0,0 +1,88 @@
+package observability
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// InstanceID tells the replicas of the service apart in traces and logs. It
+// is the hostname, the pod name on Kubernetes, followed by a random UUID,
+// so a restarted pod is a new instance too.
+var InstanceID = newInstanceID()
+
+func newInstanceID() string {
+	id := uuid.NewString()
+	if host, err := os.Hostname(); err == nil && host != "" {
+		return host + "-" + id
+	}
+	return id
+}
+
+// podEnv maps the environment variables the Kubernetes downward API is
+// expected to set to resource attributes
+var podEnv = []struct {
+	env string
+	key attribute.Key
+}{
+	{"POD_NAME", semconv.K8SPodNameKey},
+	{"POD_NAMESPACE", semconv.K8SNamespaceNameKey},
+	{"POD_UID", semconv.K8SPodUIDKey},
+	{"NODE_NAME", semconv.K8SNodeNameKey},
+}
+
+// ParseResourceAttributes reads the OTEL_RESOURCE_ATTRIBUTES format:
+// comma separated key=value pairs with percent-encoded values
+func ParseResourceAttributes(s string) ([]attribute.KeyValue, error) {
+	var attrs []attribute.KeyValue
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("must hold key=value pairs, got %q", pair)
+		}
+		decoded, err := url.PathUnescape(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("has an invalid value for %s: %w", key, err)
+		}
+		attrs = append(attrs, attribute.String(key, decoded))
+	}
+	return attrs, nil
+}
+
+// newResource describes this process. Later attributes win, so
+// resourceAttributes can override the detected host and pod. The service name
+// and version come from the configuration and the instance is InstanceID,
+// which the logs carry as well.
+func newResource(serviceName, serviceVersion, resourceAttributes string) (*resource.Resource, error) {
+	var attrs []attribute.KeyValue
+	if host, err := os.Hostname(); err == nil {
+		attrs = append(attrs, semconv.HostName(host))
+	}
+	for _, p := range podEnv {
+		if value := os.Getenv(p.env); value != "" {
+			attrs = append(attrs, p.key.String(value))
+		}
+	}
+	configured, err := ParseResourceAttributes(resourceAttributes)
+	if err != nil {
+		return nil, fmt.Errorf("OTEL_RESOURCE_ATTRIBUTES %w", err)
+	}
+	attrs = append(attrs, configured...)
+	attrs = append(attrs,
+		semconv.ServiceName(serviceName),
+		semconv.ServiceVersion(serviceVersion),
+		semconv.ServiceInstanceID(InstanceID),
+	)
+	// Create resource without merging to avoid schema conflicts
+	return resource.NewWithAttributes(semconv.SchemaURL, attrs...), nil
+}
@@ -2,45 +2,137 @@ package observability
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"log/slog"
 	"log/syslog"
+	"net"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Syslog transports, as in SYSLOG_NETWORK
+const (
+	SyslogUDP = "udp"
+	SyslogTCP = "tcp"
+	// SyslogTLS is syslog over TLS, RFC 5425
+	SyslogTLS = "tls"
 )
 
-// SyslogHandler implements slog.Handler to send logs via syslog
+// syslogWriteTimeout bounds one write, a slower receiver counts as down
+const syslogWriteTimeout = 5 * time.Second
+
+// Backoff between reconnects to a syslog receiver that is down
+const (
+	syslogMinBackoff = time.Second
+	syslogMaxBackoff = 30 * time.Second
+)
+
+// SyslogHandler implements slog.Handler to send RFC 5424 messages to a
+// syslog receiver. Messages are queued and written in the background, so a
+// receiver that is slow or down does not block logging.
 type SyslogHandler struct {
-	writer   *syslog.Writer
-	level    slog.Level
+	writer   *syslogWriter
+	level    slog.Leveler
 	fallback slog.Handler
 }
 
 // SyslogConfig holds configuration for syslog handler
 type SyslogConfig struct {
-	Network  string // "tcp", "udp", or "" for local
-	Address  string // "localhost:514" or "" for local
-	Priority syslog.Priority
+	Network string // "udp", "tcp" or "tls"
+	Address string // "localhost:514"
+	// Facility of the messages, such as syslog.LOG_LOCAL0. Their severity
+	// follows the level of the record.
+	Facility syslog.Priority
 	Tag      string
-	Level    slog.Level
+	Level    slog.Leveler
+	// Over tls, trusting the system roots or CAFile, presenting CertFile
+	// and KeyFile when set
+	CAFile   string
+	CertFile string
+	KeyFile  string
+	// Private enterprise number of the structured data IDs, such as
+	// service@32473
+	EnterpriseID string
+	// Messages queued while the receiver is slow or down, newer ones are
+	// dropped when it is full
+	BufferSize int
 }
 
-// NewSyslogHandler creates a new syslog handler
-func NewSyslogHandler(config SyslogConfig) (*SyslogHandler, error) {
-	var writer *syslog.Writer
-	var err error
+func (c SyslogConfig) tlsConfig() (*tls.Config, error) {
+	host, _, err := net.SplitHostPort(c.Address)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12, ServerName: host}
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read syslog CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in %s", c.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+	if c.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load syslog client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
 
-	if config.Network == "" && config.Address == "" {
-		// Local syslog
-		writer, err = syslog.New(config.Priority, config.Tag)
-	} else {
-		// Remote syslog
-		writer, err = syslog.Dial(config.Network, config.Address, config.Priority, config.Tag)
+// NewSyslogHandler creates a new syslog handler, failing when the receiver
+// can't be reached
+func NewSyslogHandler(config SyslogConfig) (*SyslogHandler, error) {
+	var tlsConfig *tls.Config
+	switch config.Network {
+	case SyslogUDP, SyslogTCP:
+	case SyslogTLS:
+		var err error
+		if tlsConfig, err = config.tlsConfig(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown syslog network %q", config.Network)
 	}
+	if config.BufferSize < 1 {
+		config.BufferSize = 1
+	}
+	// Without a hostname the header carries the nil value
+	hostname, _ := os.Hostname()
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to create syslog writer: %w", err)
+	writer := &syslogWriter{
+		network:   config.Network,
+		address:   config.Address,
+		tlsConfig: tlsConfig,
+		queue:     make(chan []byte, config.BufferSize),
+		closed:    make(chan struct{}),
+		done:      make(chan struct{}),
+		facility:  int(config.Facility & 0xf8),
+		hostname:  headerField(hostname, 255),
+		appName:   headerField(config.Tag, 48),
+		procID:    strconv.Itoa(os.Getpid()),
+		sdSuffix:  "@" + config.EnterpriseID,
+		service:   config.Tag,
 	}
+	if err := writer.connect(); err != nil {
+		return nil, fmt.Errorf("failed to connect to syslog: %w", err)
+	}
+	go writer.run()
+	registerLogQueue("syslog", func() int { return len(writer.queue) })
 
 	return &SyslogHandler{
 		writer:   writer,
@@ -51,7 +143,7 @@ func NewSyslogHandler(config SyslogConfig) (*SyslogHandler, error) {
 
 // Enabled reports whether the handler handles records at the given level
 func (h *SyslogHandler) Enabled(_ context.Context, level slog.Level) bool {
-	return level >= h.level
+	return level >= h.level.Level()
 }
 
 // Handle processes a log record
@@ -80,19 +172,8 @@ func (h *SyslogHandler) Handle(ctx context.Context, record slog.Record) error {
 		return err
 	}
 
-	// Send to syslog based on level
-	switch record.Level {
-	case slog.LevelDebug:
-		return h.writer.Debug(string(logJSON))
-	case slog.LevelInfo:
-		return h.writer.Info(string(logJSON))
-	case slog.LevelWarn:
-		return h.writer.Warning(string(logJSON))
-	case slog.LevelError:
-		return h.writer.Err(string(logJSON))
-	default:
-		return h.writer.Info(string(logJSON))
-	}
+	h.writer.write(h.writer.format(record.Time, record.Level, trace.SpanContextFromContext(ctx), logJSON))
+	return nil
 }
 
 // WithAttrs returns a new handler with additional attributes
@@ -113,33 +194,231 @@ func (h *SyslogHandler) WithGroup(name string) slog.Handler {
 	}
 }
 
-// Close closes the syslog writer
-func (h *SyslogHandler) Close() error {
-	return h.writer.Close()
+// Close writes the queued messages, waiting up to ctx, and closes the
+// connection
+func (h *SyslogHandler) Close(ctx context.Context) error {
+	return h.writer.close(ctx)
 }
 
-// SetupSyslogLogging configures slog to send logs via syslog
-func SetupSyslogLogging(network, address, tag string) error {
-	config := SyslogConfig{
-		Network:  network,
-		Address:  address,
-		Priority: syslog.LOG_INFO | syslog.LOG_LOCAL0,
-		Tag:      tag,
-		Level:    slog.LevelInfo,
+// syslogWriter owns the connection to the receiver, writing the queued
+// messages and reconnecting when a write fails
+type syslogWriter struct {
+	network   string
+	address   string
+	tlsConfig *tls.Config
+	queue     chan []byte
+	closed    chan struct{}
+	closeOnce sync.Once
+	// done is closed when run returns
+	done chan struct{}
+	// conn is only used by connect and run
+	conn net.Conn
+
+	facility int
+	hostname string
+	appName  string
+	procID   string
+	sdSuffix string
+	service  string
+	sequence atomic.Uint32
+	dropped  atomic.Int64
+}
+
+// format renders an RFC 5424 message with the service and the trace of the
+// record as structured data, and a sequence ID receivers can spot gaps by
+func (w *syslogWriter) format(t time.Time, level slog.Level, sc trace.SpanContext, msg []byte) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<%d>1 %s %s %s %s - ",
+		w.facility|syslogSeverity(level),
+		t.UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		w.hostname, w.appName, w.procID)
+	// sequenceId runs from 1 to 2147483647 and wraps
+	seq := (w.sequence.Add(1)-1)%2147483647 + 1
+	fmt.Fprintf(&b, `[meta sequenceId="%d"]`, seq)
+	fmt.Fprintf(&b, `[service%s name="%s"]`, w.sdSuffix, sdValue(w.service))
+	if sc.IsValid() {
+		fmt.Fprintf(&b, `[trace%s trace_id="%s" span_id="%s"]`, w.sdSuffix, sc.TraceID(), sc.SpanID())
 	}
+	b.WriteByte(' ')
+	b.Write(msg)
+	return []byte(b.String())
+}
+
+// syslogSeverity maps a level to the RFC 5424 severity
+func syslogSeverity(level slog.Level) int {
+	switch {
+	case level >= slog.LevelError:
+		return int(syslog.LOG_ERR)
+	case level >= slog.LevelWarn:
+		return int(syslog.LOG_WARNING)
+	case level >= slog.LevelInfo:
+		return int(syslog.LOG_INFO)
+	default:
+		return int(syslog.LOG_DEBUG)
+	}
+}
+
+// headerField keeps the printable ASCII of s that a header field allows, up
+// to limit characters, or the nil value -
+func headerField(s string, limit int) string {
+	field := strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return -1
+		}
+		return r
+	}, s)
+	if len(field) > limit {
+		field = field[:limit]
+	}
+	if field == "" {
+		return "-"
+	}
+	return field
+}
+
+// sdValue escapes the characters a structured data value can't hold
+func sdValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(s)
+}
+
+func (w *syslogWriter) connect() error {
+	dialer := &net.Dialer{Timeout: syslogWriteTimeout}
+	var conn net.Conn
+	var err error
+	if w.network == SyslogTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", w.address, w.tlsConfig)
+	} else {
+		conn, err = dialer.Dial(w.network, w.address)
+	}
+	if err != nil {
+		return err
+	}
+	w.conn = conn
+	return nil
+}
+
+// write queues msg without blocking, dropping it when the queue is full
+func (w *syslogWriter) write(msg []byte) {
+	select {
+	case <-w.closed:
+		return
+	default:
+	}
+	select {
+	case w.queue <- msg:
+	default:
+		w.dropped.Add(1)
+		logRecordsDropped.WithLabelValues("syslog", LogDropQueueFull).Inc()
+	}
+}
+
+// run writes the queued messages until the writer is closed and the queue
+// drained. A message whose write fails is retried once reconnected, while
+// newer messages wait in the queue.
+func (w *syslogWriter) run() {
+	defer close(w.done)
+	for {
+		select {
+		case msg := <-w.queue:
+			if !w.send(msg) {
+				return
+			}
+		case <-w.closed:
+			for {
+				select {
+				case msg := <-w.queue:
+					if w.conn == nil || w.sendOnce(msg) != nil {
+						return
+					}
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// send writes msg, reconnecting with backoff until it is written. It
+// reports false when the writer is closed first.
+func (w *syslogWriter) send(msg []byte) bool {
+	backoff := syslogMinBackoff
+	var lastErr error
+	for {
+		if w.conn != nil {
+			err := w.sendOnce(msg)
+			if err == nil {
+				return true
+			}
+			lastErr = err
+			w.conn.Close()
+			w.conn = nil
+		} else if err := w.connect(); err != nil {
+			lastErr = err
+		} else {
+			// Queued behind msg, so it follows the records written before
+			// the receiver went down
+			slog.Warn("Reconnected to syslog",
+				slog.String("address", w.address),
+				slog.Any("error", lastErr),
+				slog.Int64("dropped", w.dropped.Swap(0)))
+			continue
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-w.closed:
+			return false
+		}
+		backoff = min(backoff*2, syslogMaxBackoff)
+	}
+}
+
+// sendOnce writes msg on the connection, framed by its length over tcp and
+// tls as RFC 5425 and RFC 6587 octet counting do, one datagram over udp
+func (w *syslogWriter) sendOnce(msg []byte) error {
+	if err := w.conn.SetWriteDeadline(time.Now().Add(syslogWriteTimeout)); err != nil {
+		return err
+	}
+	if w.network != SyslogUDP {
+		msg = append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+	}
+	start := time.Now()
+	_, err := w.conn.Write(msg)
+	logSinkSendDuration.WithLabelValues("syslog").Observe(time.Since(start).Seconds())
+	return err
+}
+
+func (w *syslogWriter) close(ctx context.Context) error {
+	w.closeOnce.Do(func() { close(w.closed) })
+	select {
+	case <-w.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if w.conn != nil {
+		return w.conn.Close()
+	}
+	return nil
+}
+
+// SetupSyslogLogging configures slog to send logs via syslog, at the level
+// of LogLevel
+func SetupSyslogLogging(config SyslogConfig) error {
+	config.Level = LogLevel
 
 	handler, err := NewSyslogHandler(config)
 	if err != nil {
 		return err
 	}
+	registerLogCloser(handler.Close)
 
-	logger := slog.New(handler)
+	logger := newLogger(handler)
 	slog.SetDefault(logger)
 
 	slog.Info("Syslog logging configured",
-		slog.String("network", network),
-		slog.String("address", address),
-		slog.String("tag", tag))
+		slog.String("network", config.Network),
+		slog.String("address", config.Address),
+		slog.String("tag", config.Tag))
 
 	return nil
 }
@@ -0,0 +1,144 @@
+// Package outbox publishes domain events to the message broker through a
+// transactional outbox. Handlers write the event with Enqueue in the
+// transaction of the change it describes, so an event exists exactly when
+// the change committed. The Relay publishes stored events and marks them
+// delivered; delivery is at least once, consumers deduplicate on the
+// message ID. Messages that keep failing are moved to the dead_letter
+// table, from where admins replay them.
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	models "warehouse-service/models/sqlc"
+)
+
+// Topics of the published events
+const (
+	TopicWarehouseCreated = "warehouse.created"
+	TopicWarehouseUpdated = "warehouse.updated"
+	TopicWarehouseDeleted = "warehouse.deleted"
+
+	TopicWarehouseStatusChanged = "warehouse.status_changed"
+
+	TopicTransferCreated   = "transfer.created"
+	TopicTransferShipped   = "transfer.shipped"
+	TopicTransferReceived  = "transfer.received"
+	TopicTransferCancelled = "transfer.cancelled"
+)
+
+// Message is a stored event. Key identifies the entity, brokers that
+// partition by key keep an entity's events in order.
+type Message struct {
+	ID        int64           `json:"id"`
+	OrgID     string          `json:"org_id"`
+	Topic     string          `json:"topic"`
+	Key       string          `json:"key"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// FromModel converts an outbox row
+func FromModel(m models.Outbox) Message {
+	return Message{
+		ID:        m.ID,
+		OrgID:     m.OrgID,
+		Topic:     m.Topic,
+		Key:       m.Key,
+		Payload:   m.Payload,
+		CreatedAt: m.CreatedAt.Time,
+	}
+}
+
+// Enqueue stores an event for orgID using q, which must belong to the
+// transaction making the change. The payload is marshalled to JSON.
+func Enqueue(ctx context.Context, q *models.Queries, orgID, topic string, key int64, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal %s payload: %w", topic, err)
+	}
+	_, err = q.EnqueueOutboxMessage(ctx, models.EnqueueOutboxMessageParams{
+		OrgID:   orgID,
+		Topic:   topic,
+		Key:     strconv.FormatInt(key, 10),
+		Payload: data,
+	})
+	return err
+}
+
+// Publisher hands a message to the broker. A nil error means the broker
+// accepted it.
+type Publisher interface {
+	Publish(ctx context.Context, msg Message) error
+}
+
+// LogPublisher logs messages instead of publishing them, for development
+// and deployments without a broker
+type LogPublisher struct{}
+
+func (LogPublisher) Publish(_ context.Context, msg Message) error {
+	slog.Info("Outbox message",
+		slog.Int64("id", msg.ID),
+		slog.String("topic", msg.Topic),
+		slog.String("key", msg.Key),
+		slog.String("tenant_id", msg.OrgID),
+	)
+	return nil
+}
+
+// HTTPPublisher POSTs each message as JSON to a broker's HTTP endpoint,
+// such as a REST proxy or a bridge. Topic, key and ID are also sent as
+// headers so the endpoint can route without reading the body, and the
+// registry ID of the payload's schema once it is known.
+type HTTPPublisher struct {
+	url       string
+	client    *http.Client
+	schemaIDs map[string]int
+}
+
+func NewHTTPPublisher(url string) *HTTPPublisher {
+	return &HTTPPublisher{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// UseSchemaIDs sets the registry IDs of the topics' schemas, see
+// RegisterSchemas. It must be called before publishing.
+func (p *HTTPPublisher) UseSchemaIDs(ids map[string]int) {
+	p.schemaIDs = ids
+}
+
+func (p *HTTPPublisher) Publish(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Outbox-Topic", msg.Topic)
+	req.Header.Set("X-Outbox-Key", msg.Key)
+	req.Header.Set("Idempotency-Key", strconv.FormatInt(msg.ID, 10))
+	if id, ok := p.schemaIDs[msg.Topic]; ok {
+		req.Header.Set("X-Schema-Id", strconv.Itoa(id))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("publish: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("publish: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrIncompatible is returned for a schema breaking the compatibility
+// level of its subject
+var ErrIncompatible = errors.New("schema is incompatible")
+
+const registryContentType = "application/vnd.schemaregistry.v1+json"
+
+// Registry is a client of a schema registry speaking the Confluent REST
+// API, Confluent Schema Registry itself or Apicurio's ccompat API. The
+// registry checks every new version of a subject against its compatibility
+// level.
+type Registry struct {
+	url      string
+	username string
+	password string
+	client   *http.Client
+}
+
+// NewRegistry connects to the registry at rawURL, credentials in the URL
+// are sent with basic auth
+func NewRegistry(rawURL string) (*Registry, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, errors.New("invalid schema registry URL")
+	}
+	r := &Registry{client: &http.Client{Timeout: 10 * time.Second}}
+	if u.User != nil {
+		r.username = u.User.Username()
+		r.password, _ = u.User.Password()
+		u.User = nil
+	}
+	r.url = strings.TrimSuffix(u.String(), "/")
+	return r, nil
+}
+
+// registryError is an error response of the registry
+type registryError struct {
+	Status    int    `json:"-"`
+	ErrorCode int    `json:"error_code"`
+	Message   string `json:"message"`
+}
+
+// subjectNotFound is the error code of an unknown subject
+const subjectNotFound = 40401
+
+// do sends body as JSON and decodes the response into out. An error
+// response is returned as a wrapped *registryError.
+func (r *Registry) do(ctx context.Context, method, path string, body, out any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, r.url+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", registryContentType)
+	req.Header.Set("Accept", registryContentType)
+	if r.username != "" {
+		req.SetBasicAuth(r.username, r.password)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("schema registry: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("schema registry: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		regErr := &registryError{}
+		if json.Unmarshal(respBody, regErr) != nil || regErr.Message == "" {
+			regErr.Message = strings.TrimSpace(string(respBody))
+		}
+		regErr.Status = resp.StatusCode
+		return fmt.Errorf("schema registry: %s %s: %w", method, path, regErr)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(respBody, out)
+}
+
+func (e *registryError) Error() string {
+	return fmt.Sprintf("status %d: %s", e.Status, e.Message)
+}
+
+type registrySchema struct {
+	SchemaType string `json:"schemaType"`
+	Schema     string `json:"schema"`
+}
+
+func newRegistrySchema(s Schema) registrySchema {
+	return registrySchema{SchemaType: "JSON", Schema: string(s.Raw)}
+}
+
+// Check returns why s is incompatible with the versions of its subject, or
+// nothing when it is compatible or the subject is new
+func (r *Registry) Check(ctx context.Context, s Schema) ([]string, error) {
+	var result struct {
+		IsCompatible bool     `json:"is_compatible"`
+		Messages     []string `json:"messages"`
+	}
+	path := "/compatibility/subjects/" + url.PathEscape(s.Subject) + "/versions/latest?verbose=true"
+	err := r.do(ctx, http.MethodPost, path, newRegistrySchema(s), &result)
+	var regErr *registryError
+	if errors.As(err, &regErr) && regErr.ErrorCode == subjectNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if result.IsCompatible {
+		return nil, nil
+	}
+	if len(result.Messages) == 0 {
+		result.Messages = []string{"incompatible with the latest version"}
+	}
+	return result.Messages, nil
+}
+
+// SetCompatibility sets the compatibility level of a subject
+func (r *Registry) SetCompatibility(ctx context.Context, subject, level string) error {
+	return r.do(ctx, http.MethodPut, "/config/"+url.PathEscape(subject), map[string]string{"compatibility": level}, nil)
+}
+
+// Register adds s as a version of its subject and returns its ID.
+// Registering the latest version again returns its ID and adds nothing.
+func (r *Registry) Register(ctx context.Context, s Schema) (int, error) {
+	var result struct {
+		ID int `json:"id"`
+	}
+	err := r.do(ctx, http.MethodPost, "/subjects/"+url.PathEscape(s.Subject)+"/versions", newRegistrySchema(s), &result)
+	var regErr *registryError
+	if errors.As(err, &regErr) && regErr.Status == http.StatusConflict {
+		return 0, fmt.Errorf("%s: %w: %s", s.Subject, ErrIncompatible, regErr.Message)
+	}
+	return result.ID, err
+}
+
+// RegisterSchemas sets the compatibility level of every topic's subject
+// and registers its schema, returning the schema IDs by topic. It stops at
+// the first schema the registry refuses, which is ErrIncompatible when it
+// breaks the compatibility level.
+func RegisterSchemas(ctx context.Context, r *Registry, compatibility string) (map[string]int, error) {
+	schemas, err := Schemas()
+	if err != nil {
+		return nil, err
+	}
+	ids := map[string]int{}
+	for _, s := range schemas {
+		if err := r.SetCompatibility(ctx, s.Subject, compatibility); err != nil {
+			return ids, err
+		}
+		if ids[s.Topic], err = r.Register(ctx, s); err != nil {
+			return ids, err
+		}
+	}
+	return ids, nil
+}
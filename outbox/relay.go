@@ -0,0 +1,219 @@
+package outbox
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	models "warehouse-service/models/sqlc"
+	"warehouse-service/observability"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Config controls the relay
+type Config struct {
+	PollInterval time.Duration
+	BatchSize    int
+	// A failed message is retried after BaseBackoff, doubling per attempt
+	// up to MaxBackoff
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// A message failing this many times is moved to the dead letters
+	MaxAttempts int32
+}
+
+func (c Config) withDefaults() Config {
+	if c.PollInterval <= 0 {
+		c.PollInterval = time.Second
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 100
+	}
+	if c.BaseBackoff <= 0 {
+		c.BaseBackoff = time.Second
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = 10 * time.Minute
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = 10
+	}
+	return c
+}
+
+// Relay polls the outbox and publishes due messages. Several instances may
+// run at once, each claims its batch with row locks.
+type Relay struct {
+	db                *pgxpool.Pool
+	queries           *models.Queries
+	publisher         Publisher
+	tracer            trace.Tracer
+	prometheusMetrics *observability.PrometheusMetrics
+	config            Config
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewRelay(db *pgxpool.Pool, publisher Publisher, prometheusMetrics *observability.PrometheusMetrics, config Config) *Relay {
+	return &Relay{
+		db:                db,
+		queries:           models.New(db),
+		publisher:         publisher,
+		tracer:            otel.Tracer("warehouse-service/outbox"),
+		prometheusMetrics: prometheusMetrics,
+		config:            config.withDefaults(),
+	}
+}
+
+// Start relays messages until ctx is cancelled or Stop is called
+func (r *Relay) Start(ctx context.Context) {
+	ctx, r.cancel = context.WithCancel(ctx)
+	r.done = make(chan struct{})
+	slog.Info("Starting outbox relay",
+		slog.Duration("poll_interval", r.config.PollInterval),
+		slog.Int("batch_size", r.config.BatchSize),
+	)
+	go r.run(ctx)
+}
+
+// Stop waits for the batch in flight and stops the relay
+func (r *Relay) Stop() {
+	if r.cancel == nil {
+		return
+	}
+	r.cancel()
+	<-r.done
+	slog.Info("Outbox relay stopped")
+}
+
+func (r *Relay) run(ctx context.Context) {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		// Drain the due messages before going back to sleep
+		for ctx.Err() == nil {
+			claimed, err := r.RelayBatch(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					slog.Error("Got an error while relaying outbox messages: ", slog.Any("err", err.Error()))
+				}
+				break
+			}
+			if claimed < r.config.BatchSize {
+				break
+			}
+		}
+		r.recordBacklog(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayBatch publishes one batch of due messages and returns how many it
+// claimed. Cancelling ctx stops before the next message; the batch is
+// still committed so published messages are not sent again.
+func (r *Relay) RelayBatch(ctx context.Context) (int, error) {
+	spanCtx, span := r.tracer.Start(ctx, "outbox relay", trace.WithSpanKind(trace.SpanKindProducer))
+	defer span.End()
+	bgCtx, cancel := context.WithTimeout(context.WithoutCancel(spanCtx), time.Minute)
+	defer cancel()
+
+	tx, err := r.db.Begin(bgCtx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(bgCtx) // This will be ignored if tx.Commit() succeeds
+	qtx := r.queries.WithTx(tx)
+
+	rows, err := qtx.ClaimOutboxMessages(bgCtx, int32(r.config.BatchSize))
+	if err != nil {
+		span.RecordError(err)
+		return 0, err
+	}
+	published := 0
+	for _, row := range rows {
+		if ctx.Err() != nil {
+			break
+		}
+		msg := FromModel(row)
+		start := time.Now()
+		err := r.publisher.Publish(bgCtx, msg)
+		status := "success"
+		if err == nil {
+			published++
+			err = qtx.MarkOutboxDelivered(bgCtx, msg.ID)
+		} else if row.Attempts+1 >= r.config.MaxAttempts {
+			status = "dead_lettered"
+			slog.Error("Outbox message failed permanently, moved to the dead letters",
+				slog.Int64("id", msg.ID),
+				slog.String("topic", msg.Topic),
+				slog.Int("attempts", int(row.Attempts+1)),
+				slog.Any("err", err.Error()),
+			)
+			_, err = qtx.DeadLetterOutboxMessage(bgCtx, models.DeadLetterOutboxMessageParams{
+				ID:        msg.ID,
+				LastError: err.Error(),
+			})
+		} else {
+			status = "error"
+			delay := r.backoff(row.Attempts + 1)
+			slog.Warn("Outbox message not published, retrying",
+				slog.Int64("id", msg.ID),
+				slog.String("topic", msg.Topic),
+				slog.Duration("backoff", delay),
+				slog.Any("err", err.Error()),
+			)
+			err = qtx.RetryOutboxMessage(bgCtx, models.RetryOutboxMessageParams{
+				ID:            msg.ID,
+				LastError:     err.Error(),
+				NextAttemptAt: pgtype.Timestamptz{Time: time.Now().Add(delay), Valid: true},
+			})
+		}
+		if r.prometheusMetrics != nil {
+			r.prometheusMetrics.RecordOutboxPublish(msg.Topic, status, time.Since(start))
+		}
+		if err != nil {
+			span.RecordError(err)
+			return len(rows), err
+		}
+	}
+	span.SetAttributes(
+		attribute.Int("outbox.claimed", len(rows)),
+		attribute.Int("outbox.published", published),
+	)
+	return len(rows), tx.Commit(bgCtx)
+}
+
+// backoff returns the delay before attempt
+func (r *Relay) backoff(attempt int32) time.Duration {
+	delay := r.config.BaseBackoff
+	for i := int32(1); i < attempt && delay < r.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, r.config.MaxBackoff)
+}
+
+func (r *Relay) recordBacklog(ctx context.Context) {
+	if r.prometheusMetrics == nil {
+		return
+	}
+	backlog, err := r.queries.GetOutboxBacklog(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Error("Got an error while reading the outbox backlog: ", slog.Any("err", err.Error()))
+		}
+		return
+	}
+	r.prometheusMetrics.UpdateOutboxBacklog(backlog.Pending, backlog.LagSeconds)
+}
@@ -0,0 +1,143 @@
+package outbox
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// Payloads are described by the JSON Schemas of the schemas directory,
+// named <name>.v<major>.json. A compatible change, such as a new optional
+// property, edits the schema in place; a breaking one is a new major
+// version published to new topics. See docs/events.md.
+//
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+// topicSchemas names the schema of each topic's payload
+var topicSchemas = map[string]string{
+	TopicWarehouseCreated:       "warehouse.v1.json",
+	TopicWarehouseUpdated:       "warehouse.v1.json",
+	TopicWarehouseDeleted:       "warehouse_deleted.v1.json",
+	TopicWarehouseStatusChanged: "warehouse.v1.json",
+
+	TopicTransferCreated:   "transfer.v1.json",
+	TopicTransferShipped:   "transfer.v1.json",
+	TopicTransferReceived:  "transfer.v1.json",
+	TopicTransferCancelled: "transfer.v1.json",
+}
+
+// Schema is the JSON Schema of a topic's payloads
+type Schema struct {
+	Topic string
+	// Subject of the schema registry, the topic's value subject as in the
+	// TopicNameStrategy
+	Subject string
+	// Major version, from the file name
+	Version int
+	File    string
+	Raw     []byte
+
+	compiled *jsonschema.Schema
+}
+
+// Validate checks payload against the schema
+func (s Schema) Validate(payload []byte) error {
+	value, err := jsonschema.UnmarshalJSON(bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("%s payload: %w", s.Topic, err)
+	}
+	if err := s.compiled.Validate(value); err != nil {
+		return fmt.Errorf("%s payload: %w", s.Topic, err)
+	}
+	return nil
+}
+
+// schemaVersion reads the major version of a schema file name
+func schemaVersion(file string) (int, error) {
+	name := strings.TrimSuffix(file, ".json")
+	i := strings.LastIndex(name, ".v")
+	if i < 0 {
+		return 0, fmt.Errorf("schema %s has no version", file)
+	}
+	version, err := strconv.Atoi(name[i+2:])
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("schema %s has no version", file)
+	}
+	return version, nil
+}
+
+var loadSchemas = sync.OnceValues(func() (map[string]Schema, error) {
+	compiler := jsonschema.NewCompiler()
+	// Nothing is loaded from files or the network
+	compiler.UseLoader(jsonschema.SchemeURLLoader{})
+	compiler.AssertFormat()
+	compiled := map[string]*jsonschema.Schema{}
+	schemas := map[string]Schema{}
+	for topic, file := range topicSchemas {
+		raw, err := schemaFiles.ReadFile(path.Join("schemas", file))
+		if err != nil {
+			return nil, err
+		}
+		version, err := schemaVersion(file)
+		if err != nil {
+			return nil, err
+		}
+		if compiled[file] == nil {
+			doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+			if err != nil {
+				return nil, fmt.Errorf("schema %s: %w", file, err)
+			}
+			url := "urn:warehouse-service:schemas:" + file
+			if err := compiler.AddResource(url, doc); err != nil {
+				return nil, fmt.Errorf("schema %s: %w", file, err)
+			}
+			if compiled[file], err = compiler.Compile(url); err != nil {
+				return nil, fmt.Errorf("schema %s: %w", file, err)
+			}
+		}
+		schemas[topic] = Schema{
+			Topic:    topic,
+			Subject:  topic + "-value",
+			Version:  version,
+			File:     file,
+			Raw:      raw,
+			compiled: compiled[file],
+		}
+	}
+	return schemas, nil
+})
+
+// Schemas returns the schema of every published topic, ordered by topic
+func Schemas() ([]Schema, error) {
+	schemas, err := loadSchemas()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Schema, 0, len(schemas))
+	for _, s := range schemas {
+		out = append(out, s)
+	}
+	slices.SortFunc(out, func(a, b Schema) int { return strings.Compare(a.Topic, b.Topic) })
+	return out, nil
+}
+
+// SchemaOf returns the schema of topic's payloads
+func SchemaOf(topic string) (Schema, error) {
+	schemas, err := loadSchemas()
+	if err != nil {
+		return Schema{}, err
+	}
+	s, ok := schemas[topic]
+	if !ok {
+		return Schema{}, fmt.Errorf("no schema for topic %s", topic)
+	}
+	return s, nil
+}
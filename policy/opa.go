@@ -0,0 +1,78 @@
+package policy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// OPA asks an Open Policy Agent, usually a sidecar, through its data API.
+// The document at path is either a boolean or an object with allow and
+// reason, e.g. for package warehouse.authz:
+//
+//	default allow := false
+//	allow if input.subject.role == "org:admin"
+type OPA struct {
+	url    string
+	client *http.Client
+}
+
+// NewOPA returns an engine querying the document at path, e.g.
+// warehouse/authz, of the OPA server at baseURL
+func NewOPA(baseURL, path string) *OPA {
+	return &OPA{
+		url:    strings.TrimRight(baseURL, "/") + "/v1/data/" + strings.Trim(path, "/"),
+		client: &http.Client{Timeout: 2 * time.Second},
+	}
+}
+
+func (o *OPA) Name() string {
+	return "opa"
+}
+
+func (o *OPA) Evaluate(ctx context.Context, in Input) (Decision, error) {
+	body, err := json.Marshal(struct {
+		Input Input `json:"input"`
+	}{in})
+	if err != nil {
+		return Decision{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.url, bytes.NewReader(body))
+	if err != nil {
+		return Decision{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return Decision{}, fmt.Errorf("opa: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Decision{}, fmt.Errorf("opa: unexpected status %d", resp.StatusCode)
+	}
+
+	var out struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return Decision{}, fmt.Errorf("opa: decode response: %w", err)
+	}
+	// An undefined document has no result, the policy is missing
+	if len(out.Result) == 0 {
+		return Decision{}, fmt.Errorf("opa: %s is undefined", o.url)
+	}
+	var allow bool
+	if err := json.Unmarshal(out.Result, &allow); err == nil {
+		return Decision{Allow: allow, Reason: "opa"}, nil
+	}
+	var decision Decision
+	if err := json.Unmarshal(out.Result, &decision); err != nil {
+		return Decision{}, fmt.Errorf("opa: result is neither a boolean nor a decision: %w", err)
+	}
+	return decision, nil
+}
@@ -0,0 +1,163 @@
+// Package policy decides whether a request may reach its handler. Policies
+// are evaluated by an Engine: Rules, a list of allow and deny rules kept in
+// a JSON document, or OPA, a policy in Rego served by an Open Policy Agent.
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Input is what a policy decides on
+type Input struct {
+	Method string `json:"method"`
+	// Gin route pattern, e.g. /v1/warehouse/:id
+	Route string `json:"route"`
+	Path  string `json:"path"`
+	// Resource is the first segment of the route after the API version,
+	// e.g. warehouse, and ResourceID its :id parameter
+	Resource   string  `json:"resource"`
+	ResourceID string  `json:"resource_id,omitempty"`
+	Subject    Subject `json:"subject"`
+}
+
+// Subject is the caller, from the RequestContext
+type Subject struct {
+	UserID         string   `json:"user_id"`
+	OrgID          string   `json:"org_id"`
+	Role           string   `json:"role,omitempty"`
+	Permissions    []string `json:"permissions,omitempty"`
+	APIKey         bool     `json:"api_key"`
+	ServiceAccount bool     `json:"service_account"`
+}
+
+// Decision is the outcome of a policy, Reason says why for the decision log
+type Decision struct {
+	Allow  bool   `json:"allow"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Engine evaluates the policies. An error means no decision could be made.
+type Engine interface {
+	Evaluate(ctx context.Context, input Input) (Decision, error)
+	// Name labels decisions in logs and metrics
+	Name() string
+}
+
+// ResourceOf returns the resource of a route: the segment after the API
+// version, or the first one for unversioned routes
+func ResourceOf(route string) string {
+	segments := strings.Split(strings.Trim(route, "/"), "/")
+	if len(segments) > 1 && len(segments[0]) > 1 && segments[0][0] == 'v' && strings.Trim(segments[0][1:], "0123456789") == "" {
+		segments = segments[1:]
+	}
+	return segments[0]
+}
+
+const (
+	EffectAllow = "allow"
+	EffectDeny  = "deny"
+)
+
+// Rule matches requests on every field it sets. Routes end in * to match
+// every route below a prefix. Roles and Permissions match a subject holding
+// any of them.
+type Rule struct {
+	Effect      string   `json:"effect"`
+	Methods     []string `json:"methods,omitempty"`
+	Routes      []string `json:"routes,omitempty"`
+	Roles       []string `json:"roles,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+	// Limits the rule to API key requests when true, or to Clerk sessions
+	// when false
+	APIKey *bool  `json:"api_key,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+func (r Rule) matches(in Input) bool {
+	if len(r.Methods) > 0 && !slices.Contains(r.Methods, in.Method) {
+		return false
+	}
+	if len(r.Routes) > 0 && !slices.ContainsFunc(r.Routes, func(route string) bool { return routeMatches(route, in.Route) }) {
+		return false
+	}
+	if len(r.Roles) > 0 && !slices.Contains(r.Roles, in.Subject.Role) {
+		return false
+	}
+	if len(r.Permissions) > 0 && !slices.ContainsFunc(r.Permissions, func(p string) bool { return slices.Contains(in.Subject.Permissions, p) }) {
+		return false
+	}
+	if r.APIKey != nil && *r.APIKey != in.Subject.APIKey {
+		return false
+	}
+	return true
+}
+
+func routeMatches(pattern, route string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(route, prefix)
+	}
+	return pattern == route
+}
+
+// Rules applies the first matching rule, or Default when none matches
+type Rules struct {
+	Default string `json:"default"`
+	Rules   []Rule `json:"rules"`
+}
+
+// AllowAll lets every authenticated request through, which is how the
+// service behaves without a policy
+var AllowAll = &Rules{Default: EffectAllow}
+
+// LoadRules reads a Rules document from path
+func LoadRules(path string) (*Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRules(data)
+}
+
+// ParseRules reads a Rules document and checks every effect is allow or
+// deny
+func ParseRules(data []byte) (*Rules, error) {
+	var rules Rules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("policy: %w", err)
+	}
+	if rules.Default != EffectAllow && rules.Default != EffectDeny {
+		return nil, fmt.Errorf("policy: default must be allow or deny, got %q", rules.Default)
+	}
+	for i, rule := range rules.Rules {
+		if rule.Effect != EffectAllow && rule.Effect != EffectDeny {
+			return nil, fmt.Errorf("policy: rule %d effect must be allow or deny, got %q", i, rule.Effect)
+		}
+	}
+	return &rules, nil
+}
+
+func (r *Rules) Name() string {
+	return "rules"
+}
+
+func (r *Rules) Evaluate(_ context.Context, in Input) (Decision, error) {
+	for i, rule := range r.Rules {
+		if rule.matches(in) {
+			reason := rule.Reason
+			if reason == "" {
+				reason = fmt.Sprintf("rule %d", i)
+			}
+			return Decision{Allow: rule.Effect == EffectAllow, Reason: reason}, nil
+		}
+	}
+	if r.Default == "" {
+		return Decision{}, errors.New("policy: no default effect")
+	}
+	return Decision{Allow: r.Default == EffectAllow, Reason: "default"}, nil
+}
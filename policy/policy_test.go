@@ -0,0 +1,118 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResourceOf(t *testing.T) {
+	tests := map[string]string{
+		"/v1/warehouse/:id":           "warehouse",
+		"/v2/warehouses":              "warehouses",
+		"/v1/admin/api-keys/:id":      "admin",
+		"/ws":                         "ws",
+		"/admin/debug/pprof/*profile": "admin",
+		"/v1/storageroom/:id/history": "storageroom",
+		"/version/warehouse":          "version",
+	}
+	for route, want := range tests {
+		if got := ResourceOf(route); got != want {
+			t.Errorf("ResourceOf(%q) = %q, want %q", route, got, want)
+		}
+	}
+}
+
+const testPolicy = `{
+	"default": "allow",
+	"rules": [
+		{"effect": "allow", "routes": ["/v1/warehouse/*"], "permissions": ["org:warehouse:manage"]},
+		{"effect": "deny", "methods": ["POST", "PUT", "PATCH", "DELETE"], "roles": ["org:viewer"], "reason": "viewers are read only"},
+		{"effect": "deny", "routes": ["/v1/admin/*"], "api_key": true, "reason": "no API keys on admin routes"}
+	]
+}`
+
+func TestRules(t *testing.T) {
+	rules, err := ParseRules([]byte(testPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		input  Input
+		allow  bool
+		reason string
+	}{
+		{"viewer reads", Input{Method: "GET", Route: "/v1/storageroom/:id", Subject: Subject{Role: "org:viewer"}}, true, "default"},
+		{"viewer writes", Input{Method: "PATCH", Route: "/v1/storageroom/:id", Subject: Subject{Role: "org:viewer"}}, false, "viewers are read only"},
+		{"viewer with permission writes", Input{Method: "PATCH", Route: "/v1/warehouse/:id", Subject: Subject{Role: "org:viewer", Permissions: []string{"org:warehouse:manage"}}}, true, "rule 0"},
+		{"API key on admin route", Input{Method: "GET", Route: "/v1/admin/api-keys", Subject: Subject{APIKey: true}}, false, "no API keys on admin routes"},
+		{"session on admin route", Input{Method: "GET", Route: "/v1/admin/api-keys", Subject: Subject{Role: "org:admin"}}, true, "default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rules.Evaluate(context.Background(), tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Allow != tt.allow || got.Reason != tt.reason {
+				t.Errorf("Evaluate = %+v, want allow %v because %q", got, tt.allow, tt.reason)
+			}
+		})
+	}
+}
+
+func TestParseRulesRejectsUnknownEffects(t *testing.T) {
+	for _, doc := range []string{
+		`{"rules": []}`,
+		`{"default": "permit"}`,
+		`{"default": "deny", "rules": [{"effect": "maybe"}]}`,
+	} {
+		if _, err := ParseRules([]byte(doc)); err == nil {
+			t.Errorf("ParseRules(%s) accepted the document", doc)
+		}
+	}
+}
+
+func TestOPA(t *testing.T) {
+	var got Input
+	result := `true`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/data/warehouse/authz" {
+			http.NotFound(w, r)
+			return
+		}
+		var body struct {
+			Input Input `json:"input"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		got = body.Input
+		_, _ = w.Write([]byte(`{"result": ` + result + `}`))
+	}))
+	defer srv.Close()
+	opa := NewOPA(srv.URL+"/", "/warehouse/authz")
+
+	input := Input{Method: "GET", Route: "/v1/warehouse/:id", Resource: "warehouse", ResourceID: "7", Subject: Subject{OrgID: "org_1"}}
+	decision, err := opa.Evaluate(context.Background(), input)
+	if err != nil || !decision.Allow {
+		t.Fatalf("Evaluate = %+v, %v, want allowed", decision, err)
+	}
+	if got.ResourceID != "7" || got.Subject.OrgID != "org_1" {
+		t.Errorf("OPA got input %+v", got)
+	}
+
+	result = `{"allow": false, "reason": "outside business hours"}`
+	if decision, err = opa.Evaluate(context.Background(), input); err != nil || decision.Allow || decision.Reason != "outside business hours" {
+		t.Errorf("Evaluate = %+v, %v, want denied with the policy's reason", decision, err)
+	}
+
+	// A missing policy leaves the document undefined
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	})
+	if _, err := opa.Evaluate(context.Background(), input); err == nil {
+		t.Error("Evaluate of an undefined document succeeded")
+	}
+}
@@ -0,0 +1,56 @@
+package printing
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+)
+
+// RawPort is the raw TCP port network label printers accept documents on,
+// also known as JetDirect or port 9100 printing
+const RawPort = 9100
+
+// How long a printer may take to accept the connection and the document
+const sendTimeout = 10 * time.Second
+
+// Address normalizes the address of a printer to host:port, adding RawPort
+// when it names no port
+func Address(address string) (string, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		// No port, or an IPv6 address without brackets
+		host, port = address, strconv.Itoa(RawPort)
+	}
+	if host == "" {
+		return "", fmt.Errorf("printer address %q has no host", address)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return "", fmt.Errorf("printer address %q has an invalid port", address)
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// Send writes a document, e.g. a ZPL label, to the printer at address. The
+// printer prints what it reads from the connection, so a document it
+// accepted in full is as far as delivery can be confirmed.
+func Send(ctx context.Context, address string, document []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return fmt.Errorf("connect to printer %s: %w", address, err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetWriteDeadline(deadline)
+	}
+	if _, err := conn.Write(document); err != nil {
+		return fmt.Errorf("send to printer %s: %w", address, err)
+	}
+	return nil
+}
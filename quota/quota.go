@@ -0,0 +1,50 @@
+// Package quota holds the limits tenants are held to. Resource quotas are
+// checked in the transaction that adds the resource, API calls are counted
+// per UTC day. A zero limit is unlimited.
+package quota
+
+import (
+	"fmt"
+	"time"
+)
+
+// Resources limited by Limits, as reported in errors and metrics
+const (
+	ResourceWarehouses      = "warehouses"
+	ResourceStorageRooms    = "storage_rooms"
+	ResourceAPICalls        = "api_calls"
+	ResourceAPICallsPerUser = "api_calls_per_user"
+)
+
+type Limits struct {
+	Warehouses               int64
+	StorageRoomsPerWarehouse int64
+	APICallsPerDay           int64
+	// Calls of one Clerk user or API key across the tenant per day
+	APICallsPerUserPerDay int64
+}
+
+// ExceededError is returned when a request would take a tenant over Limit
+type ExceededError struct {
+	Resource string
+	Limit    int64
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("%s quota of %d exceeded", e.Resource, e.Limit)
+}
+
+// Check returns an *ExceededError when used is over limit
+func Check(resource string, limit, used int64) error {
+	if limit > 0 && used > limit {
+		return &ExceededError{Resource: resource, Limit: limit}
+	}
+	return nil
+}
+
+// Day returns the UTC day calls made at now count towards and when the
+// count starts over
+func Day(now time.Time) (day, resetsAt time.Time) {
+	day = now.UTC().Truncate(24 * time.Hour)
+	return day, day.Add(24 * time.Hour)
+}
@@ -0,0 +1,40 @@
+package quota
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		limit, used int64
+		exceeded    bool
+	}{
+		{limit: 0, used: 1000, exceeded: false},
+		{limit: 3, used: 3, exceeded: false},
+		{limit: 3, used: 4, exceeded: true},
+	}
+	for _, tt := range tests {
+		err := Check(ResourceWarehouses, tt.limit, tt.used)
+		var exceeded *ExceededError
+		if errors.As(err, &exceeded) != tt.exceeded {
+			t.Errorf("Check(%d, %d) = %v, want exceeded %v", tt.limit, tt.used, err, tt.exceeded)
+		}
+		if tt.exceeded && (exceeded.Resource != ResourceWarehouses || exceeded.Limit != tt.limit) {
+			t.Errorf("Check(%d, %d) = %+v", tt.limit, tt.used, exceeded)
+		}
+	}
+}
+
+func TestDay(t *testing.T) {
+	// 23:30 in UTC-5 is already the next day in UTC
+	now := time.Date(2024, 3, 9, 23, 30, 0, 0, time.FixedZone("EST", -5*3600))
+	day, resetsAt := Day(now)
+	if want := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC); !day.Equal(want) {
+		t.Errorf("day = %s, want %s", day, want)
+	}
+	if want := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC); !resetsAt.Equal(want) {
+		t.Errorf("resetsAt = %s, want %s", resetsAt, want)
+	}
+}
@@ -1,45 +1,520 @@
 package routes
 
 import (
+	"net/http"
+	"time"
+	"warehouse-service/address"
+	"warehouse-service/changefeed"
+	"warehouse-service/dbroute"
+	"warehouse-service/geocode"
 	handlers "warehouse-service/handlers"
-	// "warehouse-service/middlewares"
+	"warehouse-service/middlewares"
 	"warehouse-service/observability"
+	"warehouse-service/policy"
+	"warehouse-service/quota"
+	"warehouse-service/scheduler"
 
 	"github.com/gin-gonic/gin"
-	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// How far behind the primary a read replica may be for an endpoint's
+// reads. Detail reads follow up on writes closely, lists and reports are
+// refreshed by polling anyway.
+const (
+	staleDetail = 2 * time.Second
+	staleList   = 10 * time.Second
+	staleReport = 60 * time.Second
+)
+
+// How long a scan request may take. Handheld operators rescan rather than
+// wait, so a slow request fails fast with 504.
+const scanDeadline = 2 * time.Second
+
+// readMethods are the methods of the routes get registers
+var readMethods = []string{http.MethodGet, http.MethodHead}
+
+// get registers the handlers of a read for GET and HEAD, answering both
+// with the ETag and Content-Length of the body and HEAD without it
+func get(routes gin.IRoutes, path string, handlers ...gin.HandlerFunc) {
+	routes.Match(readMethods, path, append([]gin.HandlerFunc{middlewares.Representation()}, handlers...)...)
+}
+
 type Route struct {
-	db                *pgx.Conn
+	db                *pgxpool.Pool
 	handlers          *handlers.Handlers
 	prometheusMetrics *observability.PrometheusMetrics
+	// Resolves the RequestContext, runs right after ClerkAuth
+	identify gin.HandlerFunc
+	// Evaluates the authorization policy, runs after identify
+	authorize gin.HandlerFunc
+	// Counts tenant requests towards the API call quotas, runs after
+	// RequireTenant
+	meter gin.HandlerFunc
 }
 
-func NewRoute(db *pgx.Conn, prometheusMetrics *observability.PrometheusMetrics) *Route {
+// NewRoute builds the routes. serviceAccounts are the Clerk user IDs of
+// internal service accounts. authorize may be nil to allow every
+// authenticated request.
+func NewRoute(db *dbroute.Router, prometheusMetrics *observability.PrometheusMetrics, scheduler *scheduler.Scheduler, geocoder geocode.Geocoder, addressValidator address.Validator, changes *changefeed.Feed, quotas quota.Limits, attachments handlers.Attachments, services handlers.Services, serviceAccounts []string, authorize gin.HandlerFunc) *Route {
+	if authorize == nil {
+		authorize = middlewares.Authorize(policy.AllowAll, middlewares.DecisionLogNone, prometheusMetrics)
+	}
 	return &Route{
-		db:                db,
-		handlers:          handlers.NewHandlers(db, prometheusMetrics),
+		db:                db.Primary(),
+		handlers:          handlers.NewHandlers(db, prometheusMetrics, scheduler, geocoder, addressValidator, changes, quotas, attachments, services),
 		prometheusMetrics: prometheusMetrics,
+		identify:          middlewares.Identify(middlewares.NewProfileCache(), serviceAccounts),
+		authorize:         authorize,
+		meter:             middlewares.MeterAPICalls(db.Primary(), quotas, prometheusMetrics),
 	}
 }
 
+// Handlers exposes the handlers so background tasks can share their logic
+func (r *Route) Handlers() *handlers.Handlers {
+	return r.handlers
+}
+
 func (r *Route) AddWarehouseRoutes(router *gin.Engine) {
 	v1 := router.Group("/v1")
 	{
 		inventory := v1.Group("/warehouse")
-		// inventory.Use(middlewares.ClerkAuth(r.db))
+		inventory.Use(middlewares.ClerkAuth(r.db), r.identify, r.authorize, middlewares.RequireTenant(), r.meter, middlewares.WarehouseSlugs(r.db, "id"))
 		{
-			inventory.GET("/:id", r.handlers.GetWarehouse)
-			inventory.GET("/list", r.handlers.ListWarehouse)
+			get(inventory, "/:id", middlewares.AllowStaleReads(staleDetail), r.handlers.GetWarehouse)
+			get(inventory, "/by-slug/:slug", middlewares.AllowStaleReads(staleDetail), r.handlers.GetWarehouseBySlug)
+			get(inventory, "/list", middlewares.AllowStaleReads(staleList), r.handlers.ListWarehouse)
+			get(inventory, "/nearby", middlewares.AllowStaleReads(staleList), r.handlers.NearbyWarehouses)
+			get(inventory, "/:id/history", middlewares.AllowStaleReads(staleDetail), r.handlers.GetWarehouseHistory)
+			get(inventory, "/:id/address", middlewares.AllowStaleReads(staleDetail), r.handlers.GetWarehouseAddress)
+			inventory.POST("/batch-get", middlewares.AllowStaleReads(staleDetail), r.handlers.BatchGetWarehouses)
 			inventory.POST("/create", r.handlers.CreateWarehouse)
 			inventory.PUT("/:id", r.handlers.UpdateWarehouse)
+			inventory.PATCH("/:id", r.handlers.PatchWarehouse)
 			inventory.DELETE("/:id", r.handlers.DeleteWarehouse)
+			inventory.POST("/:id/activate", r.handlers.ActivateWarehouse)
+			inventory.POST("/:id/maintenance", r.handlers.StartWarehouseMaintenance)
+			inventory.POST("/:id/archive", r.handlers.ArchiveWarehouse)
+			inventory.POST("/:id/revert", r.handlers.RevertWarehouse)
+			inventory.POST("/:id/tags", r.handlers.AddWarehouseTags)
+			inventory.DELETE("/:id/tags/:tag", r.handlers.RemoveWarehouseTag)
+		}
+	}
+}
+
+// AddReportRoutes registers the aggregate reports, read from a replica
+// like the other reports
+func (r *Route) AddReportRoutes(router *gin.Engine) {
+	reports := router.Group("/v1/reports")
+	reports.Use(middlewares.ClerkAuth(r.db), r.identify, r.authorize, middlewares.RequireTenant(), r.meter, middlewares.WarehouseSlugs(r.db), middlewares.AllowStaleReads(staleReport))
+	{
+		get(reports, "/warehouse-summary", r.handlers.GetWarehouseSummary)
+		get(reports, "/stock-by-warehouse", r.handlers.GetStockByWarehouse)
+		get(reports, "/movement-history", r.handlers.GetMovementHistory)
+		get(reports, "/dashboard", r.handlers.GetDashboardStats)
+		get(reports, "/valuation", r.handlers.GetInventoryValuation)
+	}
+}
+
+// AddAttachmentRoutes registers the warehouse document endpoints. The
+// server only calls it when an attachment bucket is configured.
+func (r *Route) AddAttachmentRoutes(router *gin.Engine) {
+	attachments := router.Group("/v1/warehouse/:id/attachments")
+	attachments.Use(middlewares.ClerkAuth(r.db), r.identify, r.authorize, middlewares.RequireTenant(), r.meter, middlewares.WarehouseSlugs(r.db, "id"))
+	{
+		attachments.POST("", r.handlers.UploadAttachment)
+		get(attachments, "", middlewares.AllowStaleReads(staleList), r.handlers.ListAttachments)
+		get(attachments, "/:attachment_id", middlewares.AllowStaleReads(staleDetail), r.handlers.GetAttachment)
+		attachments.DELETE("/:attachment_id", r.handlers.DeleteAttachment)
+	}
+}
+
+// AddV2Routes registers the v2 API, which wraps every response in the
+// standard data/meta/errors envelope. v1 routes keep their original shape.
+func (r *Route) AddV2Routes(router *gin.Engine) {
+	v2 := router.Group("/v2")
+	v2.Use(middlewares.ClerkAuth(r.db), r.identify, r.authorize, middlewares.RequireTenant(), r.meter)
+	{
+		warehouses := v2.Group("/warehouses")
+		warehouses.Use(middlewares.WarehouseSlugs(r.db, "id"))
+		{
+			get(warehouses, "", middlewares.AllowStaleReads(staleList), r.handlers.ListWarehousesV2)
+			warehouses.POST("", r.handlers.CreateWarehouseV2)
+			get(warehouses, "/:id", middlewares.AllowStaleReads(staleDetail), r.handlers.GetWarehouseV2)
+			warehouses.PUT("/:id", r.handlers.UpdateWarehouseV2)
+			warehouses.DELETE("/:id", r.handlers.DeleteWarehouseV2)
+		}
+	}
+}
+
+func (r *Route) AddStorageRoomRoutes(router *gin.Engine) {
+	v1 := router.Group("/v1")
+	{
+		storageRoom := v1.Group("/storageroom")
+		storageRoom.Use(middlewares.ClerkAuth(r.db), r.identify, r.authorize, middlewares.RequireTenant(), r.meter, middlewares.WarehouseSlugs(r.db))
+		{
+			get(storageRoom, "/list", middlewares.AllowStaleReads(staleList), r.handlers.ListStorageRooms)
+			storageRoom.POST("/batch-get", middlewares.AllowStaleReads(staleDetail), r.handlers.BatchGetStorageRooms)
+			storageRoom.PATCH("/:id", r.handlers.PatchStorageRoom)
+			storageRoom.DELETE("/:id", r.handlers.DeleteStorageRoom)
+			get(storageRoom, "/:id/history", middlewares.AllowStaleReads(staleDetail), r.handlers.GetStorageRoomHistory)
+			storageRoom.POST("/:id/tags", r.handlers.AddStorageRoomTags)
+			storageRoom.DELETE("/:id/tags/:tag", r.handlers.RemoveStorageRoomTag)
+		}
+	}
+}
+
+func (r *Route) AddSearchRoutes(router *gin.Engine) {
+	v1 := router.Group("/v1")
+	v1.Use(middlewares.ClerkAuth(r.db), r.identify, r.authorize, middlewares.RequireTenant(), r.meter)
+	{
+		get(v1, "/search", middlewares.AllowStaleReads(staleList), r.handlers.Search)
+	}
+}
+
+func (r *Route) AddLabelRoutes(router *gin.Engine) {
+	v1 := router.Group("/v1")
+	v1.Use(middlewares.ClerkAuth(r.db), r.identify, r.authorize, middlewares.RequireTenant(), r.meter)
+	{
+		get(v1, "/storageroom/:id/label", r.handlers.GetStorageRoomLabel)
+		get(v1, "/location/:code/label", r.handlers.GetLocationLabel)
+	}
+}
+
+func (r *Route) AddLedgerRoutes(router *gin.Engine) {
+	v1 := router.Group("/v1")
+	v1.Use(middlewares.ClerkAuth(r.db), r.identify, r.authorize, middlewares.RequireTenant(), r.meter)
+	{
+		get(v1, "/stock", middlewares.AllowStaleReads(staleList), r.handlers.ListStockLevels)
+		get(v1, "/stock/movements", middlewares.AllowStaleReads(staleReport), r.handlers.ListStockMovements)
+		get(v1, "/audit", middlewares.AllowStaleReads(staleReport), r.handlers.ListAuditLogs)
+	}
+}
+
+// AddEventRoutes registers the Server-Sent Events stream of changes and
+// the stock level WebSocket
+func (r *Route) AddEventRoutes(router *gin.Engine) {
+	router.GET("/ws", middlewares.BearerFromQuery("access_token"), middlewares.ClerkAuth(r.db), r.identify, r.authorize, middlewares.RequireTenant(), r.meter, r.handlers.StockSocket)
+
+	v1 := router.Group("/v1")
+	{
+		events := v1.Group("/events")
+		events.Use(middlewares.ClerkAuth(r.db), r.identify, r.authorize, middlewares.RequireTenant(), r.meter)
+		{
+			events.GET("/stream", r.handlers.StreamEvents)
+		}
+	}
+}
+
+func (r *Route) AddReceivingRoutes(router *gin.Engine) {
+	v1 := router.Group("/v1")
+	{
+		receipts := v1.Group("/receipts")
+		receipts.Use(middlewares.ClerkAuth(r.db), r.identify, r.authorize, middlewares.RequireTenant(), r.meter, middlewares.WarehouseSlugs(r.db))
+		{
+			get(receipts, "", r.handlers.ListReceipts)
+			receipts.POST("", r.handlers.CreateReceipt)
+			get(receipts, "/:id", r.handlers.GetReceipt)
+			receipts.POST("/:id/receive", r.handlers.ReceiveReceipt)
+			receipts.POST("/:id/close", r.handlers.CloseReceipt)
+		}
+	}
+}
+
+// AddItemRoutes registers the item catalog and its units of measure
+func (r *Route) AddItemRoutes(router *gin.Engine) {
+	items := router.Group("/v1/items")
+	items.Use(middlewares.ClerkAuth(r.db), r.identify, r.authorize, middlewares.RequireTenant(), r.meter, middlewares.WarehouseSlugs(r.db))
+	{
+		get(items, "", middlewares.AllowStaleReads(staleList), r.handlers.ListItems)
+		items.POST("", r.handlers.CreateItem)
+		get(items, "/:id", middlewares.AllowStaleReads(staleDetail), r.handlers.GetItem)
+		get(items, "/:id/reorder-suggestion", middlewares.AllowStaleReads(staleDetail), r.handlers.GetReorderSuggestion)
+		items.PUT("/:id", r.handlers.UpdateItem)
+		items.DELETE("/:id", r.handlers.DeleteItem)
+	}
+}
+
+// AddPartnerRoutes registers the supplier and carrier directory
+func (r *Route) AddPartnerRoutes(router *gin.Engine) {
+	v1 := router.Group("/v1")
+	{
+		suppliers := v1.Group("/suppliers")
+		suppliers.Use(middlewares.ClerkAuth(r.db), r.identify, r.authorize, middlewares.RequireTenant(), r.meter)
+		{
+			get(suppliers, "", middlewares.AllowStaleReads(staleList), r.handlers.ListSuppliers)
+			suppliers.POST("", r.handlers.CreateSupplier)
+			get(suppliers, "/:id", middlewares.AllowStaleReads(staleDetail), r.handlers.GetSupplier)
+			suppliers.PUT("/:id", r.handlers.UpdateSupplier)
+			suppliers.DELETE("/:id", r.handlers.DeleteSupplier)
+		}
+
+		carriers := v1.Group("/carriers")
+		carriers.Use(middlewares.ClerkAuth(r.db), r.identify, r.authorize, middlewares.RequireTenant(), r.meter)
+		{
+			get(carriers, "", middlewares.AllowStaleReads(staleList), r.handlers.ListCarriers)
+			carriers.POST("", r.handlers.CreateCarrier)
+			get(carriers, "/:id", middlewares.AllowStaleReads(staleDetail), r.handlers.GetCarrier)
+			carriers.PUT("/:id", r.handlers.UpdateCarrier)
+			carriers.DELETE("/:id", r.handlers.DeleteCarrier)
+		}
+	}
+}
+
+// AddSerialRoutes registers serialized inventory, moved and looked up by
+// serial number
+func (r *Route) AddSerialRoutes(router *gin.Engine) {
+	serials := router.Group("/v1/serials")
+	serials.Use(middlewares.ClerkAuth(r.db), r.identify, r.authorize, middlewares.RequireTenant(), r.meter)
+	{
+		get(serials, "/:sn", middlewares.AllowStaleReads(staleDetail), r.handlers.GetSerial)
+		serials.POST("/receive", r.handlers.ReceiveSerials)
+		serials.POST("/move", r.handlers.MoveSerials)
+		serials.POST("/ship", r.handlers.ShipSerials)
+	}
+}
+
+func (r *Route) AddPickListRoutes(router *gin.Engine) {
+	v1 := router.Group("/v1")
+	{
+		picklists := v1.Group("/picklists")
+		picklists.Use(middlewares.ClerkAuth(r.db), r.identify, r.authorize, middlewares.RequireTenant(), r.meter, middlewares.WarehouseSlugs(r.db))
+		{
+			get(picklists, "", r.handlers.ListPickLists)
+			picklists.POST("", r.handlers.CreatePickList)
+			get(picklists, "/:id", r.handlers.GetPickList)
+			picklists.POST("/:id/pick", r.handlers.ConfirmPick)
+			picklists.POST("/:id/ship", r.handlers.ShipPickList)
+			picklists.POST("/:id/cancel", r.handlers.CancelPickList)
+		}
+
+		waves := v1.Group("/waves")
+		waves.Use(middlewares.ClerkAuth(r.db), r.identify, r.authorize, middlewares.RequireTenant(), r.meter, middlewares.WarehouseSlugs(r.db))
+		{
+			get(waves, "", r.handlers.ListWaves)
+			waves.POST("", r.handlers.CreateWave)
+			get(waves, "/:id", r.handlers.GetWave)
+		}
+
+		sagas := v1.Group("/sagas")
+		sagas.Use(middlewares.ClerkAuth(r.db), r.identify, r.authorize, middlewares.RequireTenant(), r.meter)
+		{
+			get(sagas, "", r.handlers.ListSagas)
+			get(sagas, "/:id", r.handlers.GetSaga)
+		}
+	}
+}
+
+// AddTransferRoutes registers transfer orders moving stock between
+// warehouses
+func (r *Route) AddTransferRoutes(router *gin.Engine) {
+	transfers := router.Group("/v1/transfers")
+	transfers.Use(middlewares.ClerkAuth(r.db), r.identify, r.authorize, middlewares.RequireTenant(), r.meter, middlewares.WarehouseSlugs(r.db))
+	{
+		get(transfers, "", r.handlers.ListTransferOrders)
+		transfers.POST("", r.handlers.CreateTransferOrder)
+		get(transfers, "/in-transit", r.handlers.ListInTransitStock)
+		get(transfers, "/:id", r.handlers.GetTransferOrder)
+		transfers.POST("/:id/ship", r.handlers.ShipTransferOrder)
+		transfers.POST("/:id/receive", r.handlers.ReceiveTransferOrder)
+		transfers.POST("/:id/cancel", r.handlers.CancelTransferOrder)
+	}
+}
+
+// AddScanRoutes registers the compact API of handheld scanners: resolving a
+// scanned code and quick actions on what was scanned
+func (r *Route) AddScanRoutes(router *gin.Engine) {
+	scan := router.Group("/v1/scan")
+	scan.Use(middlewares.ClerkAuth(r.db), r.identify, r.authorize, middlewares.RequireTenant(), r.meter, middlewares.Deadline(scanDeadline))
+	{
+		scan.POST("/move", r.handlers.ScanMove)
+		scan.POST("/count", r.handlers.ScanCount)
+		scan.POST("/pick/:id", r.handlers.ScanPick)
+		get(scan, "/:code", middlewares.AllowStaleReads(staleDetail), r.handlers.ResolveScan)
+	}
+}
+
+// AddPrintRoutes registers the printer registry and print jobs sending
+// labels to its printers
+func (r *Route) AddPrintRoutes(router *gin.Engine) {
+	v1 := router.Group("/v1")
+	v1.Use(middlewares.ClerkAuth(r.db), r.identify, r.authorize, middlewares.RequireTenant(), r.meter)
+	{
+		printers := v1.Group("/printers")
+		{
+			get(printers, "", r.handlers.ListPrinters)
+			printers.POST("", r.handlers.CreatePrinter)
+			get(printers, "/:id", r.handlers.GetPrinter)
+			printers.PUT("/:id", r.handlers.UpdatePrinter)
+			printers.DELETE("/:id", r.handlers.DeletePrinter)
+		}
+		v1.POST("/print", r.handlers.PrintLabel)
+	}
+}
+
+// AddEDIRoutes registers EDI interchange with partners: 856 advanced ship
+// notices are imported as receipts and 846 inventory advice is exported
+func (r *Route) AddEDIRoutes(router *gin.Engine) {
+	ediGroup := router.Group("/v1/edi")
+	ediGroup.Use(middlewares.ClerkAuth(r.db), r.identify, r.authorize, middlewares.RequireTenant(), r.meter, middlewares.WarehouseSlugs(r.db))
+	{
+		ediGroup.POST("/asn", r.handlers.ImportASN)
+		get(ediGroup, "/inventory-advice", r.handlers.ExportInventoryAdvice)
+		get(ediGroup, "/documents", r.handlers.ListEdiDocuments)
+	}
+}
+
+func (r *Route) AddCountRoutes(router *gin.Engine) {
+	v1 := router.Group("/v1")
+	{
+		counts := v1.Group("/counts")
+		counts.Use(middlewares.ClerkAuth(r.db), r.identify, r.authorize, middlewares.RequireTenant(), r.meter, middlewares.WarehouseSlugs(r.db))
+		{
+			get(counts, "", r.handlers.ListCountSessions)
+			counts.POST("", r.handlers.OpenCountSession)
+			get(counts, "/:id", r.handlers.GetCountSession)
+			counts.POST("/:id/lines", r.handlers.RecordCounts)
+			get(counts, "/:id/variance", r.handlers.GetCountVariance)
+			counts.POST("/:id/post", r.handlers.PostCountSession)
+		}
+	}
+}
+
+func (r *Route) AddJobRoutes(router *gin.Engine) {
+	v1 := router.Group("/v1")
+	{
+		jobs := v1.Group("/jobs")
+		jobs.Use(middlewares.ClerkAuth(r.db), r.identify, r.authorize, middlewares.RequireTenant(), r.meter)
+		{
+			get(jobs, "", r.handlers.ListJobs)
+			get(jobs, "/:id", r.handlers.GetJob)
+		}
+	}
+}
+
+// AddTelemetryRoutes registers the sensor ingestion endpoints. Sensor
+// gateways authenticate like any other client of the tenant.
+func (r *Route) AddTelemetryRoutes(router *gin.Engine) {
+	v1 := router.Group("/v1")
+	{
+		telemetry := v1.Group("/telemetry")
+		telemetry.Use(middlewares.ClerkAuth(r.db), r.identify, r.authorize, middlewares.RequireTenant(), r.meter)
+		{
+			telemetry.POST("/temperature", r.handlers.IngestTemperature)
+			get(telemetry, "/breaches", r.handlers.ListTemperatureBreaches)
+		}
+	}
+}
+
+// AddInboxRoutes registers the endpoint the broker bridge pushes consumed
+// messages to. Only the internal listener serves it; the message names its
+// tenant, so the service account needs none.
+func (r *Route) AddInboxRoutes(router *gin.Engine) {
+	v1 := router.Group("/v1")
+	{
+		inbox := v1.Group("/inbox")
+		inbox.Use(middlewares.ClerkAuth(r.db), r.identify, r.authorize)
+		{
+			inbox.POST("", r.handlers.ReceiveInboxMessage)
+		}
+	}
+}
+
+func (r *Route) AddAdminRoutes(router *gin.Engine) {
+	v1 := router.Group("/v1")
+	{
+		admin := v1.Group("/admin")
+		admin.Use(middlewares.ClerkAuth(r.db), r.identify, r.authorize, middlewares.RequireTenant(), r.meter, middlewares.RequireOrgRole("org:admin"))
+		{
+			get(admin, "/scheduler", r.handlers.GetSchedulerStatus)
+			get(admin, "/settings", r.handlers.GetTenantSettings)
+			admin.PUT("/settings", r.handlers.PutTenantSettings)
+			get(admin, "/attribute-schemas", r.handlers.ListAttributeSchemas)
+			get(admin, "/attribute-schemas/:entity", r.handlers.GetAttributeSchema)
+			admin.PUT("/attribute-schemas/:entity", r.handlers.PutAttributeSchema)
+			admin.DELETE("/attribute-schemas/:entity", r.handlers.DeleteAttributeSchema)
+			get(admin, "/deadletters", r.handlers.ListDeadLetters)
+			get(admin, "/deadletters/:id", r.handlers.GetDeadLetter)
+			admin.POST("/deadletters/:id/replay", r.handlers.ReplayDeadLetter)
+			get(admin, "/exchanges", r.handlers.ListFileExchanges)
+			admin.POST("/exchanges", r.handlers.CreateFileExchange)
+			get(admin, "/exchanges/:id", r.handlers.GetFileExchange)
+			admin.PUT("/exchanges/:id", r.handlers.UpdateFileExchange)
+			admin.DELETE("/exchanges/:id", r.handlers.DeleteFileExchange)
+			admin.POST("/exchanges/:id/run", r.handlers.RunFileExchange)
+			get(admin, "/exchanges/:id/runs", r.handlers.ListFileExchangeRuns)
+			get(admin, "/exchanges/:id/files", r.handlers.ListFileExchangeFiles)
+			admin.POST("/exchanges/:id/files/:file_id/reprocess", r.handlers.ReprocessFileExchangeFile)
+			get(admin, "/integrations", r.handlers.ListIntegrations)
+			admin.POST("/integrations", r.handlers.CreateIntegration)
+			get(admin, "/integrations/:id", r.handlers.GetIntegration)
+			admin.PUT("/integrations/:id", r.handlers.UpdateIntegration)
+			admin.DELETE("/integrations/:id", r.handlers.DeleteIntegration)
+			admin.POST("/integrations/:id/sync", r.handlers.SyncIntegration)
+			get(admin, "/integrations/:id/orders", r.handlers.ListIntegrationOrders)
+			admin.POST("/sagas/:id/retry", r.handlers.RetrySaga)
+			admin.POST("/sagas/:id/compensate", r.handlers.CompensateSaga)
+			admin.POST("/tenants/:id/export", r.handlers.ExportTenant)
+			get(admin, "/tenants/:id/exports", r.handlers.ListTenantExports)
+			get(admin, "/tenants/:id/exports/:export_id", r.handlers.GetTenantExport)
+			admin.POST("/tenants/:id/deletion", r.handlers.RequestTenantDeletion)
+			get(admin, "/tenants/:id/deletion", r.handlers.GetTenantDeletion)
+			admin.DELETE("/tenants/:id/deletion", r.handlers.CancelTenantDeletion)
+			get(admin, "/metering", r.handlers.GetTenantMetering)
+		}
+	}
+}
+
+// AddUsageRoutes registers the tenant's quota consumption
+func (r *Route) AddUsageRoutes(router *gin.Engine) {
+	v1 := router.Group("/v1")
+	v1.Use(middlewares.ClerkAuth(r.db), r.identify, r.authorize, middlewares.RequireTenant(), r.meter)
+	{
+		get(v1, "/usage", r.handlers.GetUsage)
+	}
+}
+
+// AddOperatorRoutes registers the operator endpoints under /admin, which
+// act across tenants and are open to the Clerk users in userIDs only. The
+// server only calls it when ADMIN_USER_IDS is set.
+func (r *Route) AddOperatorRoutes(router *gin.Engine, userIDs []string) {
+	admin := router.Group("/admin")
+	admin.Use(middlewares.ClerkAuth(r.db), r.identify, r.authorize, middlewares.RequireOperator(userIDs))
+	{
+		get(admin, "/tenants", r.handlers.ListTenants)
+		get(admin, "/tenants/:org_id/api-keys", r.handlers.ListTenantAPIKeys)
+		admin.POST("/tenants/:org_id/api-keys", r.handlers.CreateTenantAPIKey)
+		admin.DELETE("/api-keys/:id", r.handlers.RevokeAPIKey)
+		get(admin, "/outbox", r.handlers.GetOutboxStatus)
+		get(admin, "/log-level", r.handlers.GetLogLevel)
+		admin.PUT("/log-level", r.handlers.SetLogLevel)
+		admin.POST("/cache/flush", r.handlers.FlushCaches)
+		get(admin, "/jobs", r.handlers.GetJobStatus)
+		get(admin, "/services", r.handlers.GetServiceStatus)
+		admin.POST("/stats/refresh", r.handlers.RefreshDashboardStatsNow)
+		get(admin, "/metering", r.handlers.GetMetering)
+		admin.POST("/metering/aggregate", r.handlers.AggregateMeteringNow)
+		get(admin, "/runtime", r.handlers.GetRuntimeStats)
+		admin.GET("/debug/pprof/*profile", r.handlers.Pprof)
+		admin.POST("/debug/pprof/*profile", r.handlers.Pprof)
+	}
+}
+
+// AddSeedRoutes registers the fixture loader. The server only calls it when
+// SEED_ENDPOINT_ENABLED is set.
+func (r *Route) AddSeedRoutes(router *gin.Engine) {
+	v1 := router.Group("/v1")
+	{
+		admin := v1.Group("/admin")
+		admin.Use(middlewares.ClerkAuth(r.db), r.identify, r.authorize, middlewares.RequireTenant(), r.meter, middlewares.RequireOrgRole("org:admin"))
+		{
+			admin.POST("/seed", r.handlers.SeedFixtures)
 		}
 	}
 }
 
 func (r *Route) AddHealthRoutes(router *gin.Engine) {
 	// Health check endpoints (no authentication required)
-	router.GET("/healthz", r.handlers.HealthzHandler)
-	router.GET("/readyz", r.handlers.ReadyzHandler)
+	get(router, "/healthz", r.handlers.HealthzHandler)
+	get(router, "/readyz", r.handlers.ReadyzHandler)
 }
@@ -0,0 +1,171 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TaskFunc is the body of a periodic task
+type TaskFunc func(ctx context.Context) error
+
+// TaskStatus is the last known state of a registered task
+type TaskStatus struct {
+	Name         string     `json:"name"`
+	Schedule     string     `json:"schedule"`
+	Running      bool       `json:"running"`
+	NextRunAt    *time.Time `json:"next_run_at"`
+	LastRunAt    *time.Time `json:"last_run_at"`
+	LastDuration string     `json:"last_duration"`
+	LastError    string     `json:"last_error"`
+	LastSuccess  bool       `json:"last_success"`
+	RunCount     int64      `json:"run_count"`
+	FailureCount int64      `json:"failure_count"`
+}
+
+type task struct {
+	entryID cron.EntryID
+	status  TaskStatus
+}
+
+// Scheduler runs named tasks on cron schedules and keeps track of how each
+// run went. Runs of the same task never overlap.
+type Scheduler struct {
+	cron   *cron.Cron
+	tracer trace.Tracer
+
+	mu    sync.Mutex
+	tasks map[string]*task
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func New() *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		cron:   cron.New(cron.WithChain(cron.SkipIfStillRunning(cron.DiscardLogger))),
+		tracer: otel.Tracer("warehouse-service/scheduler"),
+		tasks:  make(map[string]*task),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Add registers fn under name using a standard 5 field cron spec or a
+// descriptor such as "@hourly" or "@every 15m". An empty spec disables the
+// task.
+func (s *Scheduler) Add(name, spec string, fn TaskFunc) error {
+	if spec == "" {
+		slog.Info("Scheduled task disabled", slog.String("task", name))
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.tasks[name]; exists {
+		return fmt.Errorf("task %q is already scheduled", name)
+	}
+
+	t := &task{status: TaskStatus{Name: name, Schedule: spec}}
+	entryID, err := s.cron.AddFunc(spec, func() { s.run(t, fn) })
+	if err != nil {
+		return fmt.Errorf("invalid schedule %q for task %q: %w", spec, name, err)
+	}
+	t.entryID = entryID
+	s.tasks[name] = t
+	return nil
+}
+
+// Start begins running tasks in the background
+func (s *Scheduler) Start() {
+	slog.Info("Starting scheduler", slog.Int("tasks", len(s.tasks)))
+	s.cron.Start()
+}
+
+// Stop stops scheduling new runs and waits for running tasks to return
+func (s *Scheduler) Stop() {
+	s.cancel()
+	<-s.cron.Stop().Done()
+	slog.Info("Scheduler stopped")
+}
+
+// Status returns the state of every registered task sorted by name
+func (s *Scheduler) Status() []TaskStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]TaskStatus, 0, len(s.tasks))
+	for _, t := range s.tasks {
+		status := t.status
+		if next := s.cron.Entry(t.entryID).Next; !next.IsZero() {
+			status.NextRunAt = &next
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
+func (s *Scheduler) run(t *task, fn TaskFunc) {
+	spanCtx, span := s.tracer.Start(s.ctx, "task "+t.status.Name)
+	defer span.End()
+	span.SetAttributes(attribute.String("task.name", t.status.Name))
+
+	start := time.Now()
+	s.mu.Lock()
+	t.status.Running = true
+	s.mu.Unlock()
+
+	err := s.call(spanCtx, fn)
+	duration := time.Since(start)
+
+	s.mu.Lock()
+	t.status.Running = false
+	t.status.LastRunAt = &start
+	t.status.LastDuration = duration.String()
+	t.status.LastSuccess = err == nil
+	t.status.RunCount++
+	t.status.LastError = ""
+	if err != nil {
+		t.status.LastError = err.Error()
+		t.status.FailureCount++
+	}
+	s.mu.Unlock()
+
+	if err != nil {
+		slog.Error("Scheduled task failed",
+			slog.String("task", t.status.Name),
+			slog.Duration("duration", duration),
+			slog.Any("err", err.Error()),
+		)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+	slog.Info("Scheduled task finished",
+		slog.String("task", t.status.Name),
+		slog.Duration("duration", duration),
+	)
+}
+
+// call runs fn, turning a panic into an error so the task keeps its schedule
+func (s *Scheduler) call(ctx context.Context, fn TaskFunc) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("task panicked: %v", rec)
+		}
+	}()
+	return fn(ctx)
+}
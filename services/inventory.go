@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	inventoryv1 "warehouse-service/services/proto/inventory/v1"
+)
+
+// inventoryService is the gRPC service of inventory-service
+const inventoryService = "inventory.v1.InventoryService"
+
+// Line is a quantity of a SKU
+type Line struct {
+	Sku      string
+	Quantity int64
+}
+
+func linesProto(lines []Line) []*inventoryv1.Line {
+	out := make([]*inventoryv1.Line, len(lines))
+	for i, line := range lines {
+		out[i] = &inventoryv1.Line{Sku: line.Sku, Quantity: line.Quantity}
+	}
+	return out
+}
+
+// Reservation holds stock of a warehouse for a reference, e.g. the pick
+// list it was allocated to
+type Reservation struct {
+	OrgID string
+	// Identifies the reservation, reserving again for a reference replaces
+	// its lines
+	Reference   string
+	WarehouseID int64
+	Lines       []Line
+}
+
+// Inventory is what warehouse workflows need of inventory-service
+type Inventory interface {
+	// Reserve holds stock for r.Reference and returns the ID
+	// inventory-service knows the reservation by
+	Reserve(ctx context.Context, r Reservation) (string, error)
+	// Release gives up the reservation of a reference, releasing one that
+	// doesn't exist succeeds
+	Release(ctx context.Context, orgID, reference string) error
+	Check(ctx context.Context) error
+}
+
+// InventoryClient calls inventory-service
+type InventoryClient struct {
+	*conn
+	client inventoryv1.InventoryServiceClient
+}
+
+// NewInventoryClient connects to inventory-service
+func NewInventoryClient(c Config) (*InventoryClient, error) {
+	conn, err := dial(inventoryService, c)
+	if err != nil {
+		return nil, err
+	}
+	return newInventoryClient(conn), nil
+}
+
+func newInventoryClient(conn *conn) *InventoryClient {
+	return &InventoryClient{conn: conn, client: inventoryv1.NewInventoryServiceClient(conn.cc)}
+}
+
+func (c *InventoryClient) Reserve(ctx context.Context, r Reservation) (string, error) {
+	req := &inventoryv1.ReserveStockRequest{
+		OrgId:       r.OrgID,
+		Reference:   r.Reference,
+		WarehouseId: r.WarehouseID,
+		Lines:       linesProto(r.Lines),
+	}
+	var resp *inventoryv1.ReserveStockResponse
+	err := c.invoke(ctx, "ReserveStock", func(ctx context.Context) (err error) {
+		resp, err = c.client.ReserveStock(ctx, req)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return resp.GetReservationId(), nil
+}
+
+func (c *InventoryClient) Release(ctx context.Context, orgID, reference string) error {
+	req := &inventoryv1.ReleaseStockRequest{OrgId: orgID, Reference: reference}
+	return c.invoke(ctx, "ReleaseStock", func(ctx context.Context) error {
+		_, err := c.client.ReleaseStock(ctx, req)
+		return err
+	})
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	orderv1 "warehouse-service/services/proto/order/v1"
+)
+
+// orderService is the gRPC service of order-service
+const orderService = "order.v1.OrderService"
+
+// Shipment is what left the warehouse for an order
+type Shipment struct {
+	OrgID string
+	// Order the shipment fulfils, as order-service knows it
+	OrderReference string
+	// Identifies the shipment, confirming it again is a no-op
+	Reference   string
+	WarehouseID int64
+	Lines       []Line
+}
+
+// Orders is what warehouse workflows need of order-service
+type Orders interface {
+	// ConfirmShipment tells order-service an order, or part of it, shipped
+	ConfirmShipment(ctx context.Context, s Shipment) error
+	Check(ctx context.Context) error
+}
+
+// OrderClient calls order-service
+type OrderClient struct {
+	*conn
+	client orderv1.OrderServiceClient
+}
+
+// NewOrderClient connects to order-service
+func NewOrderClient(c Config) (*OrderClient, error) {
+	conn, err := dial(orderService, c)
+	if err != nil {
+		return nil, err
+	}
+	return newOrderClient(conn), nil
+}
+
+func newOrderClient(conn *conn) *OrderClient {
+	return &OrderClient{conn: conn, client: orderv1.NewOrderServiceClient(conn.cc)}
+}
+
+func (c *OrderClient) ConfirmShipment(ctx context.Context, s Shipment) error {
+	req := &orderv1.ConfirmShipmentRequest{
+		OrgId:          s.OrgID,
+		OrderReference: s.OrderReference,
+		Reference:      s.Reference,
+		WarehouseId:    s.WarehouseID,
+		Lines:          linesProto(s.Lines),
+	}
+	return c.invoke(ctx, "ConfirmShipment", func(ctx context.Context) error {
+		_, err := c.client.ConfirmShipment(ctx, req)
+		return err
+	})
+}
@@ -0,0 +1,273 @@
+// Package services holds the gRPC clients of the sibling services the
+// warehouse works with: inventory-service, which keeps the company wide
+// reservations, and order-service, which owns the orders the warehouse
+// ships. Calls carry the trace context of the caller, are retried while the
+// service is unavailable and fail fast behind a circuit breaker once it
+// keeps failing. Stubs stand in for the services in tests.
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Config says where a service is and how it is called. Zero values fall
+// back to the defaults.
+type Config struct {
+	// host:port, or any target grpc.NewClient understands
+	Addr string
+	// Plain text when set, otherwise TLS trusting the system roots or
+	// CAFile, presenting CertFile and KeyFile when set
+	Insecure bool
+	CAFile   string
+	CertFile string
+	KeyFile  string
+	// Deadline of one attempt
+	Timeout time.Duration
+	// Attempts of a call while the service is unavailable, the first
+	// included
+	MaxAttempts int
+	// Consecutive failed calls that open the circuit, calls fail fast with
+	// ErrCircuitOpen until Cooldown has passed
+	FailureThreshold int
+	Cooldown         time.Duration
+}
+
+func (c Config) withDefaults() Config {
+	if c.Timeout <= 0 {
+		c.Timeout = 5 * time.Second
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = 3
+	}
+	if c.FailureThreshold <= 0 {
+		c.FailureThreshold = 5
+	}
+	if c.Cooldown <= 0 {
+		c.Cooldown = 30 * time.Second
+	}
+	return c
+}
+
+func (c Config) tlsConfig() (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in %s", c.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+	if c.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// retryBackoff is the wait before the second attempt, doubling for each
+// attempt after it
+const retryBackoff = 100 * time.Millisecond
+
+// ErrCircuitOpen is returned without calling a service that keeps failing
+var ErrCircuitOpen = status.Error(grpccodes.Unavailable, "circuit open")
+
+// conn calls the methods of one service
+type conn struct {
+	service string
+	config  Config
+	cc      *grpc.ClientConn
+	health  healthpb.HealthClient
+	breaker *breaker
+	tracer  trace.Tracer
+}
+
+// dial creates the connection to service, the fully qualified name of the
+// gRPC service, which is established on the first call
+func dial(service string, c Config) (*conn, error) {
+	c = c.withDefaults()
+	if c.Addr == "" {
+		return nil, errors.New("no address")
+	}
+	creds := insecure.NewCredentials()
+	if !c.Insecure {
+		tlsConfig, err := c.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	}
+	cc, err := grpc.NewClient(c.Addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+	return newConn(service, c, cc), nil
+}
+
+func newConn(service string, c Config, cc *grpc.ClientConn) *conn {
+	return &conn{
+		service: service,
+		config:  c,
+		cc:      cc,
+		health:  healthpb.NewHealthClient(cc),
+		breaker: &breaker{threshold: c.FailureThreshold, cooldown: c.Cooldown},
+		tracer:  otel.Tracer("warehouse-service/services"),
+	}
+}
+
+// Close closes the connection
+func (c *conn) Close() error {
+	return c.cc.Close()
+}
+
+// Check asks the service's health endpoint whether it is serving
+func (c *conn) Check(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+	resp, err := c.health.Check(ctx, &healthpb.HealthCheckRequest{Service: c.service})
+	if err != nil {
+		return err
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("%s is %s", c.service, resp.GetStatus())
+	}
+	return nil
+}
+
+// invoke makes a call to method of the service in a client span, retrying
+// while the service is unavailable. Methods must be idempotent, an attempt
+// that timed out may have taken effect.
+func (c *conn) invoke(ctx context.Context, method string, call func(ctx context.Context) error) error {
+	ctx, span := c.tracer.Start(ctx, c.service+"/"+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "grpc"),
+			attribute.String("rpc.service", c.service),
+			attribute.String("rpc.method", method),
+		))
+	defer span.End()
+
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	otel.GetTextMapPropagator().Inject(ctx, metadataCarrier(md))
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	var err error
+	for attempt := 1; attempt <= c.config.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			wait := retryBackoff << (attempt - 2)
+			wait += rand.N(wait / 2)
+			select {
+			case <-ctx.Done():
+				return c.fail(span, err)
+			case <-time.After(wait):
+			}
+		}
+		if !c.breaker.allow() {
+			return c.fail(span, ErrCircuitOpen)
+		}
+		err = c.attempt(ctx, call)
+		c.breaker.record(unhealthy(err))
+		if err == nil || !retryable(err) || ctx.Err() != nil {
+			break
+		}
+		span.AddEvent("retry", trace.WithAttributes(attribute.Int("rpc.attempt", attempt)))
+	}
+	if err != nil {
+		return c.fail(span, err)
+	}
+	span.SetAttributes(attribute.Int("rpc.grpc.status_code", int(grpccodes.OK)))
+	return nil
+}
+
+func (c *conn) attempt(ctx context.Context, call func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+	return call(ctx)
+}
+
+func (c *conn) fail(span trace.Span, err error) error {
+	span.SetAttributes(attribute.Int("rpc.grpc.status_code", int(status.Code(err))))
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+	return fmt.Errorf("%s: %w", c.service, err)
+}
+
+// metadataCarrier carries the trace context in gRPC metadata, whose keys
+// are lower case
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if v := metadata.MD(c).Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// retryable tells whether another attempt may succeed. A timed out attempt
+// is retried while the caller's context lives.
+func retryable(err error) bool {
+	switch status.Code(err) {
+	case grpccodes.Unavailable, grpccodes.DeadlineExceeded, grpccodes.ResourceExhausted, grpccodes.Aborted:
+		return true
+	}
+	return false
+}
+
+// unhealthy tells whether err counts against the service. Errors about the
+// request, such as InvalidArgument or NotFound, come from a working
+// service.
+func unhealthy(err error) bool {
+	switch status.Code(err) {
+	case grpccodes.Unavailable, grpccodes.DeadlineExceeded, grpccodes.ResourceExhausted, grpccodes.Internal, grpccodes.Unknown:
+		return true
+	}
+	return false
+}
+
+// Permanent tells whether err is the service refusing the request, which
+// fails again however often it is retried
+func Permanent(err error) bool {
+	switch status.Code(err) {
+	case grpccodes.InvalidArgument, grpccodes.NotFound, grpccodes.AlreadyExists, grpccodes.FailedPrecondition, grpccodes.OutOfRange:
+		return true
+	}
+	return false
+}
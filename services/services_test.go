@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+	inventoryv1 "warehouse-service/services/proto/inventory/v1"
+	orderv1 "warehouse-service/services/proto/order/v1"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+// fakeInventory serves InventoryService, failing the first calls with the
+// errors in fail
+type fakeInventory struct {
+	inventoryv1.UnimplementedInventoryServiceServer
+	mu          sync.Mutex
+	calls       int
+	fail        []error
+	reservation *inventoryv1.ReserveStockRequest
+	traceparent string
+}
+
+func (f *fakeInventory) ReserveStock(ctx context.Context, req *inventoryv1.ReserveStockRequest) (*inventoryv1.ReserveStockResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if len(f.fail) > 0 {
+		err := f.fail[0]
+		f.fail = f.fail[1:]
+		return nil, err
+	}
+	f.reservation = req
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get("traceparent")) > 0 {
+		f.traceparent = md.Get("traceparent")[0]
+	}
+	return &inventoryv1.ReserveStockResponse{ReservationId: "res-1"}, nil
+}
+
+// fakeOrders serves OrderService, keeping the last shipment
+type fakeOrders struct {
+	orderv1.UnimplementedOrderServiceServer
+	shipment *orderv1.ConfirmShipmentRequest
+}
+
+func (f *fakeOrders) ConfirmShipment(_ context.Context, req *orderv1.ConfirmShipmentRequest) (*orderv1.ConfirmShipmentResponse, error) {
+	f.shipment = req
+	return &orderv1.ConfirmShipmentResponse{}, nil
+}
+
+func newTestConn(t *testing.T, service string, register func(*grpc.Server), c Config) *conn {
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	register(server)
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(service, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	cc, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cc.Close() })
+	return newConn(service, c.withDefaults(), cc)
+}
+
+func newTestInventory(t *testing.T, f *fakeInventory, c Config) *InventoryClient {
+	return newInventoryClient(newTestConn(t, inventoryService, func(s *grpc.Server) {
+		inventoryv1.RegisterInventoryServiceServer(s, f)
+	}, c))
+}
+
+func TestInventoryClient(t *testing.T) {
+	otel.SetTracerProvider(sdktrace.NewTracerProvider())
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	f := &fakeInventory{fail: []error{status.Error(codes.Unavailable, "starting")}}
+	client := newTestInventory(t, f, Config{})
+
+	want := Reservation{
+		OrgID:       "org_1",
+		Reference:   "pick_list:7",
+		WarehouseID: 3,
+		Lines:       []Line{{Sku: "A", Quantity: 2}, {Sku: "B", Quantity: 40}},
+	}
+	id, err := client.Reserve(context.Background(), want)
+	if err != nil || id != "res-1" {
+		t.Fatalf("reserved %q, %v", id, err)
+	}
+	wantReq := &inventoryv1.ReserveStockRequest{
+		OrgId:       "org_1",
+		Reference:   "pick_list:7",
+		WarehouseId: 3,
+		Lines:       []*inventoryv1.Line{{Sku: "A", Quantity: 2}, {Sku: "B", Quantity: 40}},
+	}
+	if f.calls != 2 || !proto.Equal(f.reservation, wantReq) {
+		t.Fatalf("%d calls, server got %v", f.calls, f.reservation)
+	}
+	if f.traceparent == "" {
+		t.Error("trace context not propagated")
+	}
+	if err := client.Check(context.Background()); err != nil {
+		t.Errorf("health check: %v", err)
+	}
+
+	f.fail = []error{status.Error(codes.InvalidArgument, "unknown SKU")}
+	f.calls = 0
+	if _, err := client.Reserve(context.Background(), want); status.Code(err) != codes.InvalidArgument || f.calls != 1 {
+		t.Errorf("%d calls, %v", f.calls, err)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "down")
+	f := &fakeInventory{fail: []error{unavailable, unavailable}}
+	client := newTestInventory(t, f, Config{MaxAttempts: 1, FailureThreshold: 2, Cooldown: 50 * time.Millisecond})
+	ctx := context.Background()
+
+	for range 2 {
+		if _, err := client.Reserve(ctx, Reservation{Reference: "r"}); status.Code(err) != codes.Unavailable {
+			t.Fatalf("got %v", err)
+		}
+	}
+	if _, err := client.Reserve(ctx, Reservation{Reference: "r"}); !errors.Is(err, ErrCircuitOpen) || f.calls != 2 {
+		t.Fatalf("%d calls, %v", f.calls, err)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if _, err := client.Reserve(ctx, Reservation{Reference: "r"}); err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	if _, err := client.Reserve(ctx, Reservation{Reference: "r"}); err != nil || f.calls != 4 {
+		t.Fatalf("%d calls, %v", f.calls, err)
+	}
+}
+
+func TestPermanent(t *testing.T) {
+	for err, want := range map[error]bool{
+		status.Error(codes.FailedPrecondition, "order cancelled"): true,
+		status.Error(codes.NotFound, "no such order"):             true,
+		status.Error(codes.Unavailable, "down"):                   false,
+		ErrCircuitOpen:                                            false,
+		errors.New("local"):                                       false,
+	} {
+		if got := Permanent(fmt.Errorf("wrapped: %w", err)); got != want {
+			t.Errorf("Permanent(%v) = %v", err, got)
+		}
+	}
+}
+
+func TestOrderClient(t *testing.T) {
+	f := &fakeOrders{}
+	client := newOrderClient(newTestConn(t, orderService, func(s *grpc.Server) {
+		orderv1.RegisterOrderServiceServer(s, f)
+	}, Config{}))
+
+	err := client.ConfirmShipment(context.Background(), Shipment{
+		OrgID:          "org_1",
+		OrderReference: "SO-1",
+		Reference:      "pick_list:1",
+		WarehouseID:    3,
+		Lines:          []Line{{Sku: "A", Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &orderv1.ConfirmShipmentRequest{
+		OrgId:          "org_1",
+		OrderReference: "SO-1",
+		Reference:      "pick_list:1",
+		WarehouseId:    3,
+		Lines:          []*inventoryv1.Line{{Sku: "A", Quantity: 1}},
+	}
+	if !proto.Equal(f.shipment, want) {
+		t.Errorf("server got %v", f.shipment)
+	}
+	if err := client.Check(context.Background()); err != nil {
+		t.Errorf("health check: %v", err)
+	}
+}
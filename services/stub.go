@@ -0,0 +1,111 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+)
+
+// StubInventory is an in-memory Inventory for tests. Reservations are kept
+// by organization and reference like inventory-service keeps them.
+type StubInventory struct {
+	mu           sync.Mutex
+	err          error
+	reservations map[[2]string]Reservation
+}
+
+// Fail makes every call return err, nil makes them succeed again
+func (s *StubInventory) Fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+func (s *StubInventory) Reserve(_ context.Context, r Reservation) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return "", s.err
+	}
+	if s.reservations == nil {
+		s.reservations = map[[2]string]Reservation{}
+	}
+	r.Lines = slices.Clone(r.Lines)
+	s.reservations[[2]string{r.OrgID, r.Reference}] = r
+	return r.Reference, nil
+}
+
+func (s *StubInventory) Release(_ context.Context, orgID, reference string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	delete(s.reservations, [2]string{orgID, reference})
+	return nil
+}
+
+func (s *StubInventory) Check(context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Reservations returns the reservations held, ordered by reference
+func (s *StubInventory) Reservations() []Reservation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Reservation
+	for _, r := range s.reservations {
+		out = append(out, r)
+	}
+	slices.SortFunc(out, func(a, b Reservation) int {
+		return cmp.Or(cmp.Compare(a.OrgID, b.OrgID), cmp.Compare(a.Reference, b.Reference))
+	})
+	return out
+}
+
+// StubOrders is an in-memory Orders for tests. A shipment confirmed again
+// is recorded once, like order-service does.
+type StubOrders struct {
+	mu        sync.Mutex
+	err       error
+	shipments []Shipment
+}
+
+// Fail makes every call return err, nil makes them succeed again
+func (s *StubOrders) Fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+func (s *StubOrders) ConfirmShipment(_ context.Context, shipment Shipment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	for _, confirmed := range s.shipments {
+		if confirmed.OrgID == shipment.OrgID && confirmed.Reference == shipment.Reference {
+			return nil
+		}
+	}
+	shipment.Lines = slices.Clone(shipment.Lines)
+	s.shipments = append(s.shipments, shipment)
+	return nil
+}
+
+func (s *StubOrders) Check(context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Shipments returns the confirmed shipments in the order they came in
+func (s *StubOrders) Shipments() []Shipment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.shipments)
+}
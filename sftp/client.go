@@ -0,0 +1,196 @@
+// Package sftp exchanges files with partners over SFTP: listing, reading,
+// writing and removing the files of one directory. It authenticates with a
+// key and only talks to servers presenting a known host key.
+package sftp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"time"
+
+	pkgsftp "github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// dialTimeout bounds connecting to a server, transfers are bounded by the
+// context given to Dial
+const dialTimeout = 10 * time.Second
+
+// MaxFileSize is the largest file ReadFile reads
+const MaxFileSize = 32 << 20
+
+// ParseURL validates an sftp://user@host[:port]/directory URL
+func ParseURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "sftp" || u.Hostname() == "" || u.User.Username() == "" {
+		return nil, errors.New("SFTP URL must look like sftp://user@host/directory")
+	}
+	if _, ok := u.User.Password(); ok {
+		return nil, errors.New("SFTP URL must not hold a password, the service authenticates with its key")
+	}
+	if u.Port() == "" {
+		u.Host = net.JoinHostPort(u.Hostname(), "22")
+	}
+	return u, nil
+}
+
+// LoadKey reads the PEM encoded private key the service authenticates with
+func LoadKey(file string) (ssh.Signer, error) {
+	key, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := ssh.ParsePrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("parse SFTP private key: %w", err)
+	}
+	return signer, nil
+}
+
+// ParseHostKey reads a host key in authorized_keys format, e.g.
+// "ssh-ed25519 AAAA..."
+func ParseHostKey(key string) (ssh.PublicKey, error) {
+	pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(key))
+	return pub, err
+}
+
+// Client works on the directory of the URL it was dialed with. It is not
+// safe for concurrent use.
+type Client struct {
+	dir    string
+	conn   net.Conn
+	client *ssh.Client
+	stop   func() bool
+	sftp   *pkgsftp.Client
+}
+
+// Dial connects to the server of an sftp:// URL, which must present
+// hostKey, as the URL's user authenticated with signer. Canceling ctx
+// aborts the connection.
+func Dial(ctx context.Context, rawURL, hostKey string, signer ssh.Signer) (*Client, error) {
+	u, err := ParseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := ParseHostKey(hostKey)
+	if err != nil {
+		return nil, fmt.Errorf("parse host key: %w", err)
+	}
+
+	dialer := net.Dialer{Timeout: dialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", u.Host)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	c := &Client{dir: path.Join("/", u.Path), conn: conn}
+	c.stop = context.AfterFunc(ctx, func() { conn.Close() })
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, u.Host, &ssh.ClientConfig{
+		User:            u.User.Username(),
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: ssh.FixedHostKey(pub),
+		Timeout:         dialTimeout,
+	})
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	c.client = ssh.NewClient(sshConn, chans, reqs)
+	if c.sftp, err = pkgsftp.NewClient(c.client); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// Close ends the session and the connection
+func (c *Client) Close() error {
+	if c.sftp != nil {
+		c.sftp.Close()
+	}
+	if c.client != nil {
+		c.client.Close()
+	}
+	c.stop()
+	return c.conn.Close()
+}
+
+// List returns the names of the regular files in the directory
+func (c *Client) List() ([]string, error) {
+	entries, err := c.sftp.ReadDir(c.dir)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", c.dir, err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.Mode().IsRegular() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+// ReadFile reads a file of the directory, at most MaxFileSize bytes
+func (c *Client) ReadFile(name string) ([]byte, error) {
+	p := path.Join(c.dir, name)
+	f, err := c.sftp.Open(p)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", p, err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, MaxFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", p, err)
+	}
+	if len(data) > MaxFileSize {
+		return nil, fmt.Errorf("%s is larger than %d bytes", p, MaxFileSize)
+	}
+	return data, nil
+}
+
+// WriteFile writes data as a file of the directory. The file is written
+// under a .part name and renamed when complete, so the other side never
+// picks up half a file; an existing file of that name is replaced.
+func (c *Client) WriteFile(name string, data []byte) error {
+	target := path.Join(c.dir, name)
+	part := target + ".part"
+	f, err := c.sftp.OpenFile(part, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", part, err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("write %s: %w", part, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close %s: %w", part, err)
+	}
+
+	// Version 3 renames fail when the target exists
+	_ = c.sftp.Remove(target)
+	if err := c.sftp.Rename(part, target); err != nil {
+		return fmt.Errorf("rename %s: %w", part, err)
+	}
+	return nil
+}
+
+// Remove deletes a file of the directory
+func (c *Client) Remove(name string) error {
+	p := path.Join(c.dir, name)
+	if err := c.sftp.Remove(p); err != nil {
+		return fmt.Errorf("remove %s: %w", p, err)
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+// Package slo tracks per-route service level indicators. Availability is the
+// share of requests answered without a 5xx, latency the share of those
+// answered within a threshold. Both are exposed as ratios and error budget
+// burn rates over rolling windows, so alerts compare a ready-made gauge
+// with a threshold instead of assembling the ratio in PromQL.
+package slo
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Indicators, as reported in the sli label
+const (
+	SLIAvailability = "availability"
+	SLILatency      = "latency"
+)
+
+// Windows are the rolling windows ratios and burn rates are computed over,
+// the pairs multiwindow burn rate alerts use
+var Windows = []struct {
+	Label    string
+	Duration time.Duration
+}{
+	{"5m", 5 * time.Minute},
+	{"30m", 30 * time.Minute},
+	{"1h", time.Hour},
+	{"6h", 6 * time.Hour},
+}
+
+// Requests are counted per minute, enough buckets for the longest window
+const bucketCount = 6 * 60
+
+type Objectives struct {
+	// Target share of requests without a 5xx, e.g. 0.999
+	Availability float64
+	// Target share of requests without a 5xx answered within
+	// LatencyThreshold
+	Latency          float64
+	LatencyThreshold time.Duration
+	// Route patterns left out, such as streams whose duration is the
+	// length of the connection
+	ExcludedRoutes []string
+}
+
+type bucket struct {
+	minute int64
+	total  int64
+	failed int64
+	slow   int64
+}
+
+// series holds the last bucketCount minutes of one route
+type series struct {
+	buckets [bucketCount]bucket
+}
+
+func (s *series) add(minute int64, failed, slow bool) {
+	b := &s.buckets[minute%bucketCount]
+	if b.minute != minute {
+		*b = bucket{minute: minute}
+	}
+	b.total++
+	if failed {
+		b.failed++
+	} else if slow {
+		b.slow++
+	}
+}
+
+// sum adds up the buckets of the minutes in (now-minutes, now]
+func (s *series) sum(now, minutes int64) (total, failed, slow int64) {
+	for _, b := range s.buckets {
+		if b.total > 0 && b.minute > now-minutes && b.minute <= now {
+			total += b.total
+			failed += b.failed
+			slow += b.slow
+		}
+	}
+	return total, failed, slow
+}
+
+type routeKey struct {
+	method string
+	route  string
+}
+
+// Tracker records requests and exposes the indicators as a
+// prometheus.Collector. Figures are per instance; the slo_requests_total
+// counters add up across instances.
+type Tracker struct {
+	objectives Objectives
+	excluded   map[string]bool
+	now        func() time.Time
+
+	mu     sync.Mutex
+	routes map[routeKey]*series
+
+	requests  *prometheus.CounterVec
+	ratio     *prometheus.Desc
+	burnRate  *prometheus.Desc
+	objective *prometheus.Desc
+}
+
+func New(objectives Objectives) *Tracker {
+	excluded := make(map[string]bool, len(objectives.ExcludedRoutes))
+	for _, route := range objectives.ExcludedRoutes {
+		excluded[route] = true
+	}
+	return &Tracker{
+		objectives: objectives,
+		excluded:   excluded,
+		now:        time.Now,
+		routes:     make(map[routeKey]*series),
+		requests: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "slo_requests_total",
+				Help: "Requests counted towards a service level indicator by route and result, good or bad",
+			},
+			[]string{"method", "endpoint", "sli", "result"},
+		),
+		ratio: prometheus.NewDesc(
+			"slo_sli_ratio",
+			"Share of good requests of a route over a rolling window",
+			[]string{"method", "endpoint", "sli", "window"}, nil,
+		),
+		burnRate: prometheus.NewDesc(
+			"slo_error_budget_burn_rate",
+			"How many times faster than sustainable a route consumes its error budget over a rolling window",
+			[]string{"method", "endpoint", "sli", "window"}, nil,
+		),
+		objective: prometheus.NewDesc(
+			"slo_objective_ratio",
+			"Target share of good requests",
+			[]string{"sli"}, nil,
+		),
+	}
+}
+
+// Record counts a request answered with status after duration. Excluded
+// and unmatched routes are ignored.
+func (t *Tracker) Record(method, route string, status int, duration time.Duration) {
+	if route == "" || t.excluded[route] {
+		return
+	}
+	failed := status >= 500
+	slow := duration > t.objectives.LatencyThreshold
+	t.requests.WithLabelValues(method, route, SLIAvailability, result(!failed)).Inc()
+	if !failed {
+		t.requests.WithLabelValues(method, route, SLILatency, result(!slow)).Inc()
+	}
+
+	key := routeKey{method: method, route: route}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s, ok := t.routes[key]
+	if !ok {
+		s = &series{}
+		t.routes[key] = s
+	}
+	s.add(t.now().Unix()/60, failed, slow)
+}
+
+func result(good bool) string {
+	if good {
+		return "good"
+	}
+	return "bad"
+}
+
+// Middleware records every request after the handlers ran
+func (t *Tracker) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		t.Record(c.Request.Method, c.FullPath(), c.Writer.Status(), time.Since(start))
+	}
+}
+
+func (t *Tracker) Describe(ch chan<- *prometheus.Desc) {
+	t.requests.Describe(ch)
+	ch <- t.ratio
+	ch <- t.burnRate
+	ch <- t.objective
+}
+
+// Collect computes the ratios at scrape time. A window without requests
+// has no ratio rather than a perfect one.
+func (t *Tracker) Collect(ch chan<- prometheus.Metric) {
+	t.requests.Collect(ch)
+	ch <- prometheus.MustNewConstMetric(t.objective, prometheus.GaugeValue, t.objectives.Availability, SLIAvailability)
+	ch <- prometheus.MustNewConstMetric(t.objective, prometheus.GaugeValue, t.objectives.Latency, SLILatency)
+
+	now := t.now().Unix() / 60
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key, s := range t.routes {
+		for _, w := range Windows {
+			total, failed, slow := s.sum(now, int64(w.Duration/time.Minute))
+			t.collectRatio(ch, key, SLIAvailability, w.Label, total-failed, total, t.objectives.Availability)
+			t.collectRatio(ch, key, SLILatency, w.Label, total-failed-slow, total-failed, t.objectives.Latency)
+		}
+	}
+}
+
+func (t *Tracker) collectRatio(ch chan<- prometheus.Metric, key routeKey, sli, window string, good, total int64, target float64) {
+	if total == 0 {
+		return
+	}
+	ratio := float64(good) / float64(total)
+	ch <- prometheus.MustNewConstMetric(t.ratio, prometheus.GaugeValue, ratio, key.method, key.route, sli, window)
+	ch <- prometheus.MustNewConstMetric(t.burnRate, prometheus.GaugeValue, BurnRate(ratio, target), key.method, key.route, sli, window)
+}
+
+// BurnRate is how many times faster than sustainable a ratio of good
+// requests consumes the error budget of target. At 1 the budget lasts
+// exactly the SLO period.
+func BurnRate(ratio, target float64) float64 {
+	return (1 - ratio) / (1 - target)
+}
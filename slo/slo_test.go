@@ -0,0 +1,93 @@
+package slo
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestTracker(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 30, 0, time.UTC)
+	tracker := New(Objectives{
+		Availability:     0.99,
+		Latency:          0.9,
+		LatencyThreshold: 100 * time.Millisecond,
+		ExcludedRoutes:   []string{"/v1/events/stream"},
+	})
+	tracker.now = func() time.Time { return now.Add(-20 * time.Minute) }
+	tracker.Record("GET", "/v1/warehouse/:id", 500, time.Millisecond)
+	tracker.Record("GET", "/v1/warehouse/:id", 500, time.Millisecond)
+
+	tracker.now = func() time.Time { return now }
+	for range 7 {
+		tracker.Record("GET", "/v1/warehouse/:id", 200, 10*time.Millisecond)
+	}
+	tracker.Record("GET", "/v1/warehouse/:id", 404, time.Second)
+	tracker.Record("GET", "/v1/warehouse/:id", 503, time.Second)
+	tracker.Record("GET", "/v1/warehouse/:id", 200, time.Second)
+	tracker.Record("GET", "/v1/events/stream", 500, time.Hour)
+	tracker.Record("GET", "", 404, time.Millisecond)
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(tracker)
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := map[string]float64{}
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			// Labels come sorted by name
+			key := family.GetName()
+			for _, label := range m.GetLabel() {
+				key += " " + label.GetValue()
+			}
+			if m.GetGauge() != nil {
+				values[key] = m.GetGauge().GetValue()
+			} else {
+				values[key] = m.GetCounter().GetValue()
+			}
+		}
+	}
+
+	want := map[string]float64{
+		// 9 of 10 requests in the last 5 minutes without a 5xx, 12 with the
+		// two failures 20 minutes ago
+		"slo_sli_ratio /v1/warehouse/:id GET availability 5m":              0.9,
+		"slo_sli_ratio /v1/warehouse/:id GET availability 30m":             0.75,
+		"slo_error_budget_burn_rate /v1/warehouse/:id GET availability 5m": 10,
+		// 7 of the 9 answered requests were fast
+		"slo_sli_ratio /v1/warehouse/:id GET latency 5m":              7.0 / 9,
+		"slo_error_budget_burn_rate /v1/warehouse/:id GET latency 5m": (2.0 / 9) / 0.1,
+		"slo_requests_total /v1/warehouse/:id GET bad availability":   3,
+		"slo_requests_total /v1/warehouse/:id GET good availability":  9,
+		"slo_requests_total /v1/warehouse/:id GET bad latency":        2,
+		"slo_objective_ratio availability":                            0.99,
+	}
+	for key, value := range want {
+		got, ok := values[key]
+		if !ok {
+			t.Errorf("%s missing", key)
+			continue
+		}
+		if math.Abs(got-value) > 1e-9 {
+			t.Errorf("%s = %g, want %g", key, got, value)
+		}
+	}
+	if _, ok := values["slo_sli_ratio /v1/events/stream GET availability 5m"]; ok {
+		t.Error("excluded route recorded")
+	}
+}
+
+func TestSeriesExpiresOldBuckets(t *testing.T) {
+	var s series
+	s.add(100, true, false)
+	// Same ring slot, six hours later
+	s.add(100+bucketCount, false, true)
+	total, failed, slow := s.sum(100+bucketCount, 360)
+	if total != 1 || failed != 0 || slow != 1 {
+		t.Fatalf("sum %d %d %d, want the newer minute only", total, failed, slow)
+	}
+}
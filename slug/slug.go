@@ -0,0 +1,92 @@
+// Package slug makes the URL-friendly handles warehouses are referenced by
+// besides their ID. A slug is made from the name once and kept when the
+// name changes, so links and integrations holding it don't break.
+package slug
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxLength caps a slug made from a name, before a collision suffix
+const MaxLength = 60
+
+// Fallback is the slug of a name without a letter or digit to keep
+const Fallback = "warehouse"
+
+// Reserved are the path segments of the warehouse routes beside /:id. A
+// slug equal to one could not be used in their place, Next skips them.
+var Reserved = []string{"batch-get", "by-slug", "create", "list", "nearby"}
+
+// Make returns the slug of name: lowercase ASCII letters and digits, runs
+// of anything else a single hyphen. Accents are dropped, "Kho Hà Nội"
+// is "kho-ha-noi". A slug is never only digits, those are IDs.
+func Make(name string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range norm.NFD.String(name) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		switch r {
+		case 'đ', 'Đ':
+			r = 'd'
+		default:
+			r = unicode.ToLower(r)
+		}
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			hyphen = b.Len() > 0
+			continue
+		}
+		if hyphen {
+			b.WriteByte('-')
+			hyphen = false
+		}
+		b.WriteRune(r)
+	}
+	s := b.String()
+	if len(s) > MaxLength {
+		s = strings.TrimRight(s[:MaxLength], "-")
+	}
+	if s == "" {
+		return Fallback
+	}
+	if IsID(s) {
+		return Fallback + "-" + s
+	}
+	return s
+}
+
+// Next returns base, or base with the smallest suffix from -2 up, that
+// is neither in taken nor reserved
+func Next(base string, taken []string) string {
+	used := make(map[string]bool, len(taken)+len(Reserved))
+	for _, s := range Reserved {
+		used[s] = true
+	}
+	for _, s := range taken {
+		used[s] = true
+	}
+	candidate := base
+	for n := 2; used[candidate]; n++ {
+		candidate = base + "-" + strconv.Itoa(n)
+	}
+	return candidate
+}
+
+// IsID tells whether s is made of digits only, which makes it an ID
+// rather than a slug where either is accepted
+func IsID(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
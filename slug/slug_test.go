@@ -0,0 +1,59 @@
+package slug
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{name: "Main Warehouse", want: "main-warehouse"},
+		{name: "  Kho Hà Nội  ", want: "kho-ha-noi"},
+		{name: "Đà Nẵng / Cold #2", want: "da-nang-cold-2"},
+		{name: "Crème Brûlée's DC", want: "creme-brulee-s-dc"},
+		{name: "---", want: Fallback},
+		{name: "倉庫", want: Fallback},
+		{name: "42", want: "warehouse-42"},
+		{name: "42 Rue", want: "42-rue"},
+	}
+	for _, tt := range tests {
+		if got := Make(tt.name); got != tt.want {
+			t.Errorf("Make(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	long := Make(strings.Repeat("ab ", 40))
+	if len(long) > MaxLength || strings.HasSuffix(long, "-") {
+		t.Errorf("Make of a long name = %q, want at most %d characters without a trailing hyphen", long, MaxLength)
+	}
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		taken []string
+		want  string
+	}{
+		{taken: nil, want: "main"},
+		{taken: []string{"main-2"}, want: "main"},
+		{taken: []string{"main"}, want: "main-2"},
+		{taken: []string{"main", "main-2", "main-4"}, want: "main-3"},
+	}
+	for _, tt := range tests {
+		if got := Next("main", tt.taken); got != tt.want {
+			t.Errorf("Next(%q, %v) = %q, want %q", "main", tt.taken, got, tt.want)
+		}
+	}
+	if got := Next("list", nil); got != "list-2" {
+		t.Errorf("Next(%q, nil) = %q, want %q", "list", got, "list-2")
+	}
+}
+
+func TestIsID(t *testing.T) {
+	for s, want := range map[string]bool{"17": true, "": false, "main": false, "17a": false, "-17": false} {
+		if got := IsID(s); got != want {
+			t.Errorf("IsID(%q) = %v, want %v", s, got, want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// Package tracing records the business context of an operation on its span
+// under the same attribute names everywhere: who acted for which tenant, on
+// which entity, how many rows changed and which state changes happened. A
+// trace query for entity.id or tenant.id then finds every handler that
+// touched the entity or tenant.
+package tracing
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Attribute keys of business operations
+const (
+	TenantKey       = attribute.Key("tenant.id")
+	UserKey         = attribute.Key("user.id")
+	EntityTypeKey   = attribute.Key("entity.type")
+	EntityIDKey     = attribute.Key("entity.id")
+	RowsAffectedKey = attribute.Key("db.rows_affected")
+	StatusKey       = attribute.Key("operation.status")
+	FromStateKey    = attribute.Key("state.from")
+	ToStateKey      = attribute.Key("state.to")
+)
+
+// Span event names
+const (
+	TransitionEvent    = "state.transition"
+	StockAdjustedEvent = "stock.adjusted"
+)
+
+// Actor records the tenant and the user or API key acting for it. An
+// empty user is left out.
+func Actor(span trace.Span, tenant, userID string) {
+	span.SetAttributes(TenantKey.String(tenant))
+	if userID != "" {
+		span.SetAttributes(UserKey.String(userID))
+	}
+}
+
+// Entity records the entity an operation works on. The entity's own key,
+// e.g. warehouse.id, is kept alongside entity.id for existing queries.
+func Entity(span trace.Span, entityType string, id int64) {
+	span.SetAttributes(
+		EntityTypeKey.String(entityType),
+		EntityIDKey.Int64(id),
+		attribute.Int64(entityType+".id", id),
+	)
+}
+
+// RowsAffected records how many rows a write changed
+func RowsAffected(span trace.Span, n int64) {
+	span.SetAttributes(RowsAffectedKey.Int64(n))
+}
+
+// Transition adds a span event for an entity moving from one state to
+// another. A created entity comes from the empty state.
+func Transition(span trace.Span, entityType string, id int64, from, to string) {
+	span.AddEvent(TransitionEvent, trace.WithAttributes(
+		EntityTypeKey.String(entityType),
+		EntityIDKey.Int64(id),
+		FromStateKey.String(from),
+		ToStateKey.String(to),
+	))
+}
+
+// StockAdjusted adds a span event for a change of on-hand quantity of a SKU
+// in a storage room, with the quantity after the change
+func StockAdjusted(span trace.Span, storageRoomID int64, sku string, delta, quantity int64, reason string) {
+	span.AddEvent(StockAdjustedEvent, trace.WithAttributes(
+		attribute.Int64("storage_room.id", storageRoomID),
+		attribute.String("stock.sku", sku),
+		attribute.Int64("stock.delta", delta),
+		attribute.Int64("stock.quantity", quantity),
+		attribute.String("stock.reason", reason),
+	))
+}
+
+// Result records how the operation ended, e.g. success or not_found
+func Result(span trace.Span, status string) {
+	span.SetAttributes(StatusKey.String(status))
+}
+
+// Failed records err and marks the span and the operation as failed
+func Failed(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+	span.SetAttributes(StatusKey.String("error"))
+}
@@ -0,0 +1,60 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestBusinessAttributes(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	_, span := tracer.Start(context.Background(), "archiveWarehouse")
+	Actor(span, "org_1", "")
+	Entity(span, "warehouse", 42)
+	RowsAffected(span, 1)
+	Transition(span, "warehouse", 42, "active", "archived")
+	Failed(span, errors.New("outbox unavailable"))
+	span.End()
+
+	got := recorder.Ended()[0]
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range got.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	want := map[attribute.Key]attribute.Value{
+		TenantKey:       attribute.StringValue("org_1"),
+		EntityTypeKey:   attribute.StringValue("warehouse"),
+		EntityIDKey:     attribute.Int64Value(42),
+		"warehouse.id":  attribute.Int64Value(42),
+		RowsAffectedKey: attribute.Int64Value(1),
+		StatusKey:       attribute.StringValue("error"),
+	}
+	for key, value := range want {
+		if attrs[key] != value {
+			t.Errorf("%s = %v, want %v", key, attrs[key].Emit(), value.Emit())
+		}
+	}
+	if _, ok := attrs[UserKey]; ok {
+		t.Errorf("empty user recorded as %s", UserKey)
+	}
+	if got.Status().Code != codes.Error {
+		t.Errorf("status = %v, want error", got.Status().Code)
+	}
+
+	var transition sdktrace.Event
+	for _, event := range got.Events() {
+		if event.Name == TransitionEvent {
+			transition = event
+		}
+	}
+	if len(transition.Attributes) != 4 || transition.Attributes[2] != FromStateKey.String("active") || transition.Attributes[3] != ToStateKey.String("archived") {
+		t.Errorf("transition event attributes = %v", transition.Attributes)
+	}
+}